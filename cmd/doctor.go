@@ -16,10 +16,12 @@ import (
 	"path/filepath"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/migrations"
 	"code.gitea.io/gitea/modules/git"
+	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/options"
 	"code.gitea.io/gitea/modules/repository"
@@ -56,6 +58,10 @@ var CmdDoctor = cli.Command{
 			Name:  "fix",
 			Usage: "Automatically fix what we can",
 		},
+		cli.StringFlag{
+			Name:  "quarantine-path",
+			Usage: `Directory the orphaned files are moved to by --fix (default: "doctor-quarantine" in the data path)`,
+		},
 		cli.StringFlag{
 			Name:  "log-file",
 			Usage: `Name of the log file (default: "doctor.log"). Set to "-" to output to stdout, set to "" to disable`,
@@ -126,6 +132,24 @@ var checklist = []check{
 		isDefault: false,
 		f:         runDoctorUserStarNum,
 	},
+	{
+		title:     "Check if the issue indexer is consistent with the database",
+		name:      "check-issue-indexer",
+		isDefault: false,
+		f:         runDoctorIssueIndexer,
+	},
+	{
+		title:     "Check for orphaned LFS objects and LFS objects with missing content",
+		name:      "check-lfs-orphans",
+		isDefault: false,
+		f:         runDoctorLFSOrphans,
+	},
+	{
+		title:     "Check for attachment files without database rows and attachments with missing files",
+		name:      "check-attachment-orphans",
+		isDefault: false,
+		f:         runDoctorAttachmentOrphans,
+	},
 	// more checks please append here
 }
 
@@ -604,3 +628,152 @@ func runDoctorCheckDBConsistency(ctx *cli.Context) ([]string, error) {
 
 	return results, nil
 }
+
+func runDoctorIssueIndexer(ctx *cli.Context) ([]string, error) {
+	setting.NewServices()
+	if setting.Indexer.IssueType == "db" {
+		return []string{"The db issue indexer searches the database directly and needs no check"}, nil
+	}
+
+	// The bleve index can only be opened while Gitea is stopped
+	indexer, err := issue_indexer.OpenIndexer()
+	if err != nil {
+		return nil, err
+	}
+	defer indexer.Close()
+
+	status, err := issue_indexer.CheckIndexer(context.Background(), indexer, ctx.Bool("fix"))
+	if err != nil {
+		return nil, err
+	}
+	results := []string{fmt.Sprintf("%d issues checked", status.Checked)}
+	if status.Repaired() == 0 {
+		return results, nil
+	}
+	if ctx.Bool("fix") {
+		return append(results, fmt.Sprintf("%d missing, %d stale and %d orphaned documents repaired", status.Missing, status.Stale, status.Orphaned)), nil
+	}
+	return append(results, fmt.Sprintf("%d missing, %d stale and %d orphaned documents", status.Missing, status.Stale, status.Orphaned)), nil
+}
+
+// doctorReportLimit is the number of orphans listed in the output of a check, all of them are logged
+const doctorReportLimit = 20
+
+// reportOrphans adds the orphans to the results of a check and logs them
+func reportOrphans(results []string, format string, orphans []string) []string {
+	if len(orphans) == 0 {
+		return results
+	}
+	results = append(results, fmt.Sprintf(format, len(orphans)))
+	for i, orphan := range orphans {
+		log.Info(format+": %s", len(orphans), orphan)
+		if i < doctorReportLimit {
+			results = append(results, "    "+orphan)
+		}
+	}
+	if len(orphans) > doctorReportLimit {
+		results = append(results, fmt.Sprintf("    ... and %d more, see the log file", len(orphans)-doctorReportLimit))
+	}
+	return results
+}
+
+// quarantineFiles moves orphaned files of a storage to the quarantine directory instead of
+// deleting them, so that they can be restored if they were still needed
+func quarantineFiles(ctx *cli.Context, storage, root string, files []string) (string, error) {
+	quarantine := ctx.String("quarantine-path")
+	if quarantine == "" {
+		quarantine = filepath.Join(setting.AppDataPath, "doctor-quarantine")
+	}
+	quarantine = filepath.Join(quarantine, time.Now().Format("20060102150405"), storage)
+	for _, file := range files {
+		dest := filepath.Join(quarantine, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+			return quarantine, err
+		}
+		if err := os.Rename(filepath.Join(root, filepath.FromSlash(file)), dest); err != nil {
+			return quarantine, err
+		}
+		log.Info("Moved %s to %s", file, dest)
+	}
+	return quarantine, nil
+}
+
+func runDoctorLFSOrphans(ctx *cli.Context) ([]string, error) {
+	if !setting.LFS.StartServer {
+		return []string{"LFS is disabled"}, nil
+	}
+
+	var results []string
+
+	// LFS objects of deleted repositories
+	count, err := models.CountOrphanedObjects("lfs_meta_object", "repository", "lfs_meta_object.repository_id=repository.id")
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		if ctx.Bool("fix") {
+			if err = models.DeleteOrphanedObjects("lfs_meta_object", "repository", "lfs_meta_object.repository_id=repository.id"); err != nil {
+				return nil, err
+			}
+			results = append(results, fmt.Sprintf("%d LFS objects without existing repository deleted", count))
+		} else {
+			results = append(results, fmt.Sprintf("%d LFS objects without existing repository", count))
+		}
+	}
+
+	orphans, err := models.FindOrphanedLFSObjects(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if len(orphans.MissingIDs) > 0 {
+		if ctx.Bool("fix") {
+			if err = models.DeleteLFSMetaObjectsByIDs(orphans.MissingIDs); err != nil {
+				return nil, err
+			}
+			results = append(results, fmt.Sprintf("%d LFS objects with missing content deleted, their content will be uploaded again by the next push", len(orphans.MissingIDs)))
+		} else {
+			results = append(results, fmt.Sprintf("%d LFS objects with missing content", len(orphans.MissingIDs)))
+		}
+	}
+	if ctx.Bool("fix") && len(orphans.Files) > 0 {
+		results = reportOrphans(results, "%d LFS files not referenced by any repository quarantined", orphans.Files)
+		quarantine, err := quarantineFiles(ctx, "lfs", setting.LFS.ContentPath, orphans.Files)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, fmt.Sprintf("The quarantined files are in '%s'", quarantine))
+	} else {
+		results = reportOrphans(results, "%d LFS files not referenced by any repository", orphans.Files)
+	}
+	return results, nil
+}
+
+func runDoctorAttachmentOrphans(ctx *cli.Context) ([]string, error) {
+	var results []string
+
+	orphans, err := models.FindOrphanedAttachments(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if len(orphans.MissingIDs) > 0 {
+		if ctx.Bool("fix") {
+			if err = models.DeleteAttachmentsByIDs(orphans.MissingIDs); err != nil {
+				return nil, err
+			}
+			results = append(results, fmt.Sprintf("%d attachments with missing file deleted", len(orphans.MissingIDs)))
+		} else {
+			results = append(results, fmt.Sprintf("%d attachments with missing file", len(orphans.MissingIDs)))
+		}
+	}
+	if ctx.Bool("fix") && len(orphans.Files) > 0 {
+		results = reportOrphans(results, "%d attachment files without database row quarantined", orphans.Files)
+		quarantine, err := quarantineFiles(ctx, "attachments", setting.AttachmentPath, orphans.Files)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, fmt.Sprintf("The quarantined files are in '%s'", quarantine))
+	} else {
+		results = reportOrphans(results, "%d attachment files without database row", orphans.Files)
+	}
+	return results, nil
+}
@@ -23,6 +23,7 @@ import (
 	archiver "github.com/mholt/archiver/v3"
 	"github.com/unknwon/com"
 	"github.com/urfave/cli"
+	"xorm.io/builder"
 )
 
 func addFile(w archiver.Writer, filePath string, absPath string, verbose bool) error {
@@ -154,6 +155,26 @@ It can be used for backup and capture Gitea server image to send to maintainer`,
 			Name:  "skip-log, L",
 			Usage: "Skip the log dumping",
 		},
+		cli.StringSliceFlag{
+			Name:  "repository, r",
+			Usage: "Only dump the repository given as owner/name, with its LFS objects and attachments. Can be repeated",
+		},
+		cli.StringSliceFlag{
+			Name:  "exclude-repository",
+			Usage: "Skip dumping the repository given as owner/name. Can be repeated",
+		},
+		cli.BoolFlag{
+			Name:  "skip-lfs",
+			Usage: "Skip the LFS objects dumping",
+		},
+		cli.BoolFlag{
+			Name:  "skip-attachments",
+			Usage: "Skip the attachments dumping",
+		},
+		cli.BoolFlag{
+			Name:  "skip-repository-rows",
+			Usage: "Skip dumping the database rows of each repository, which restore-repo restores",
+		},
 		cli.GenericFlag{
 			Name:  "type",
 			Value: outputTypeEnum,
@@ -218,25 +239,37 @@ func runDump(ctx *cli.Context) error {
 	}
 	defer w.Close()
 
+	tmpDir := ctx.String("tempdir")
+	if _, err := os.Stat(tmpDir); os.IsNotExist(err) {
+		fatal("Path does not exist: %s", tmpDir)
+	}
+
+	selective := len(ctx.StringSlice("repository")) > 0 || len(ctx.StringSlice("exclude-repository")) > 0
+	var attachments []string
 	if ctx.IsSet("skip-repository") && ctx.Bool("skip-repository") {
 		log.Info("Skip dumping local repositories")
 	} else {
-		log.Info("Dumping local repositories... %s", setting.RepoRootPath)
-		if err := addRecursive(w, "repos", setting.RepoRootPath, verbose); err != nil {
-			fatal("Failed to include repositories: %v", err)
-		}
+		if !selective {
+			log.Info("Dumping local repositories... %s", setting.RepoRootPath)
+			if err := addRecursive(w, "repos", setting.RepoRootPath, verbose); err != nil {
+				fatal("Failed to include repositories: %v", err)
+			}
 
-		if _, err := os.Stat(setting.LFS.ContentPath); !os.IsNotExist(err) {
-			log.Info("Dumping lfs... %s", setting.LFS.ContentPath)
-			if err := addRecursive(w, "lfs", setting.LFS.ContentPath, verbose); err != nil {
-				fatal("Failed to include lfs: %v", err)
+			if ctx.Bool("skip-lfs") {
+				log.Info("Skip dumping LFS objects")
+			} else if _, err := os.Stat(setting.LFS.ContentPath); !os.IsNotExist(err) {
+				log.Info("Dumping lfs... %s", setting.LFS.ContentPath)
+				if err := addRecursive(w, "lfs", setting.LFS.ContentPath, verbose); err != nil {
+					fatal("Failed to include lfs: %v", err)
+				}
 			}
 		}
-	}
 
-	tmpDir := ctx.String("tempdir")
-	if _, err := os.Stat(tmpDir); os.IsNotExist(err) {
-		fatal("Path does not exist: %s", tmpDir)
+		if selective || !ctx.Bool("skip-repository-rows") {
+			if attachments, err = dumpRepositories(ctx, w, tmpDir, selective, verbose); err != nil {
+				fatal("Failed to include repositories: %v", err)
+			}
+		}
 	}
 
 	dbDump, err := ioutil.TempFile(tmpDir, "gitea-db.sql")
@@ -295,9 +328,19 @@ func runDump(ctx *cli.Context) error {
 		excludes = append(excludes, setting.RepoRootPath)
 		excludes = append(excludes, setting.LFS.ContentPath)
 		excludes = append(excludes, setting.LogRootPath)
+		if selective || ctx.Bool("skip-attachments") {
+			excludes = append(excludes, setting.AttachmentPath)
+		}
 		if err := addRecursiveExclude(w, "data", setting.AppDataPath, excludes, verbose); err != nil {
 			fatal("Failed to include data directory: %v", err)
 		}
+
+		// only the attachments of the dumped repositories are
+		if selective && !ctx.Bool("skip-attachments") {
+			if err := addAttachments(w, attachments, verbose); err != nil {
+				fatal("Failed to include attachments: %v", err)
+			}
+		}
 	}
 
 	// Doesn't check if LogRootPath exists before processing --skip-log intentionally,
@@ -331,6 +374,155 @@ func runDump(ctx *cli.Context) error {
 	return nil
 }
 
+// repoArchivePath returns the path of a file of the repositories in the dumps
+func repoArchivePath(repoPath string) (string, error) {
+	relPath, err := filepath.Rel(setting.RepoRootPath, repoPath)
+	if err != nil {
+		return "", err
+	}
+	return path.Join("repos", filepath.ToSlash(relPath)), nil
+}
+
+// repoRowsArchivePath returns the path of the database rows of a repository in the dumps
+func repoRowsArchivePath(ownerName, repoName string) string {
+	return path.Join("repo-rows", strings.ToLower(ownerName), strings.ToLower(repoName)+".json")
+}
+
+// attachmentsArchivePath returns the path of the attachments in the dumps, they are dumped with
+// the data directory
+func attachmentsArchivePath() (string, error) {
+	if is, _ := isSubdir(setting.AppDataPath, setting.AttachmentPath); !is {
+		return "", fmt.Errorf("attachments dir %s is not inside data dir %s", setting.AttachmentPath, setting.AppDataPath)
+	}
+	relPath, err := filepath.Rel(setting.AppDataPath, setting.AttachmentPath)
+	if err != nil {
+		return "", err
+	}
+	return path.Join("data", filepath.ToSlash(relPath)), nil
+}
+
+// dumpRepositories dumps the database rows of the repositories, and for a selective dump their
+// git data and LFS objects. It returns the UUIDs of the attachments of the dumped repositories.
+func dumpRepositories(ctx *cli.Context, w archiver.Writer, tmpDir string, selective, verbose bool) ([]string, error) {
+	includes := make(map[string]bool)
+	for _, name := range ctx.StringSlice("repository") {
+		includes[strings.ToLower(name)] = false
+	}
+	excludes := make(map[string]bool)
+	for _, name := range ctx.StringSlice("exclude-repository") {
+		excludes[strings.ToLower(name)] = true
+	}
+
+	var attachments []string
+	if err := models.Iterate(
+		models.DefaultDBContext(),
+		new(models.Repository),
+		builder.Gt{"id": 0},
+		func(idx int, bean interface{}) error {
+			repo := bean.(*models.Repository)
+			fullName := strings.ToLower(repo.FullName())
+			if _, ok := includes[fullName]; ok {
+				includes[fullName] = true
+			} else if len(includes) > 0 || excludes[fullName] {
+				return nil
+			}
+
+			rows, err := models.DumpRepository(repo)
+			if err != nil {
+				return fmt.Errorf("dump %s: %v", repo.FullName(), err)
+			}
+			attachments = append(attachments, rows.AttachmentUUIDs()...)
+			if !ctx.Bool("skip-repository-rows") {
+				if err := addRepoRows(w, tmpDir, rows, verbose); err != nil {
+					return fmt.Errorf("dump %s: %v", repo.FullName(), err)
+				}
+			}
+			if !selective {
+				return nil
+			}
+
+			log.Info("Dumping repository %s...", repo.FullName())
+			for _, repoPath := range []string{repo.RepoPath(), repo.WikiPath()} {
+				if !com.IsDir(repoPath) {
+					continue
+				}
+				archivePath, err := repoArchivePath(repoPath)
+				if err != nil {
+					return err
+				}
+				if err := addRecursive(w, archivePath, repoPath, verbose); err != nil {
+					return err
+				}
+			}
+			if ctx.Bool("skip-lfs") {
+				return nil
+			}
+			for _, oid := range rows.LFSOids() {
+				relPath := path.Join(oid[0:2], oid[2:4], oid[4:])
+				absPath := filepath.Join(setting.LFS.ContentPath, relPath)
+				if !com.IsFile(absPath) {
+					log.Warn("LFS object %s of %s is missing, skipped", oid, repo.FullName())
+					continue
+				}
+				if err := addFile(w, path.Join("lfs", relPath), absPath, verbose); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	for name, found := range includes {
+		if !found {
+			return nil, fmt.Errorf("repository %s does not exist", name)
+		}
+	}
+	return attachments, nil
+}
+
+// addRepoRows adds the database rows of a repository to the dump
+func addRepoRows(w archiver.Writer, tmpDir string, rows *models.RepoDump, verbose bool) error {
+	file, err := ioutil.TempFile(tmpDir, "gitea-repo-rows.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if err := rows.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return addFile(w, repoRowsArchivePath(rows.OwnerName, rows.Name), file.Name(), verbose)
+}
+
+// addAttachments adds the files of attachments to the dump
+func addAttachments(w archiver.Writer, uuids []string, verbose bool) error {
+	archivePath, err := attachmentsArchivePath()
+	if err != nil {
+		log.Info("%v, skipped", err)
+		return nil
+	}
+	for _, uuid := range uuids {
+		absPath := models.AttachmentLocalPath(uuid)
+		if !com.IsFile(absPath) {
+			log.Warn("Attachment %s is missing, skipped", uuid)
+			continue
+		}
+		relPath, err := filepath.Rel(setting.AttachmentPath, absPath)
+		if err != nil {
+			return err
+		}
+		if err := addFile(w, path.Join(archivePath, filepath.ToSlash(relPath)), absPath, verbose); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func contains(slice []string, s string) bool {
 	for _, v := range slice {
 		if v == s {
@@ -0,0 +1,137 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/urfave/cli"
+)
+
+var passphraseFlag = cli.StringFlag{
+	Name:   "passphrase",
+	EnvVar: "GITEA_DUMP_PASSPHRASE",
+	Usage:  "Passphrase encrypting the passwords, two-factor secrets and access tokens of the users",
+}
+
+// CmdDumpUsers represents the available dump-users sub-command.
+var CmdDumpUsers = cli.Command{
+	Name:  "dump-users",
+	Usage: "Dump the users, organizations and teams to a portable file",
+	Description: `Dump the users, organizations, teams, SSH keys and access tokens to a file which can be
+restored on another instance with restore-users. The secrets of the users are encrypted with the passphrase.`,
+	Action: runDumpUsers,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "file, f",
+			Value: "gitea-users.json",
+			Usage: `Name of the dump file which will be created. Supply '-' for stdout.`,
+		},
+		passphraseFlag,
+	},
+}
+
+// CmdRestoreUsers represents the available restore-users sub-command.
+var CmdRestoreUsers = cli.Command{
+	Name:  "restore-users",
+	Usage: "Restore the users, organizations and teams of a file created by dump-users",
+	Description: `Restore the users, organizations, teams, SSH keys and access tokens of a dump which do not
+exist yet. The users whose name or email address is already used are skipped.`,
+	Action: runRestoreUsers,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "file, f",
+			Value: "gitea-users.json",
+			Usage: `Name of the dump file to restore. Supply '-' for stdin.`,
+		},
+		passphraseFlag,
+		cli.BoolFlag{
+			Name:  "link-existing-users",
+			Usage: "Restore the memberships of the users and organizations already existing with the same name",
+		},
+	},
+}
+
+func runDumpUsers(ctx *cli.Context) error {
+	passphrase := ctx.String("passphrase")
+	if passphrase == "" {
+		return errors.New("a passphrase is required")
+	}
+
+	var w io.Writer
+	fileName := ctx.String("file")
+	if fileName == "-" {
+		w = os.Stdout
+		if err := log.DelLogger("console"); err != nil {
+			fatal("Deleting default logger failed. Can not write to stdout: %v", err)
+		}
+	}
+	if err := initDBDisableConsole(fileName == "-"); err != nil {
+		return err
+	}
+
+	dump, err := models.NewUserDump(passphrase)
+	if err != nil {
+		return err
+	}
+	if err := models.DumpUsers(context.Background(), dump); err != nil {
+		return fmt.Errorf("dump users: %v", err)
+	}
+
+	if w == nil {
+		file, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := dump.Write(w); err != nil {
+		return err
+	}
+	if fileName != "-" {
+		fmt.Printf("%d users and organizations and %d teams dumped to %s\n", len(dump.Users), len(dump.Teams), fileName)
+	}
+	return nil
+}
+
+func runRestoreUsers(ctx *cli.Context) error {
+	passphrase := ctx.String("passphrase")
+	if passphrase == "" {
+		return errors.New("a passphrase is required")
+	}
+
+	r := io.Reader(os.Stdin)
+	if fileName := ctx.String("file"); fileName != "-" {
+		file, err := os.Open(fileName)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+	dump, err := models.ReadUserDump(r, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if err := initDB(); err != nil {
+		return err
+	}
+	report, err := models.RestoreUsers(context.Background(), dump, models.RestoreUsersOptions{
+		LinkExisting: ctx.Bool("link-existing-users"),
+	})
+	for _, line := range report {
+		fmt.Println(line)
+	}
+	return err
+}
@@ -27,6 +27,7 @@ var (
 			subcmdRestart,
 			subcmdFlushQueues,
 			subcmdLogging,
+			subcmdMaintenance,
 		},
 	}
 	subcmdShutdown = cli.Command{
@@ -67,6 +68,53 @@ var (
 			},
 		},
 	}
+	subcmdMaintenance = cli.Command{
+		Name:  "maintenance",
+		Usage: "Decline new writes to the running process whilst requests and queues drain",
+		Subcommands: []cli.Command{
+			{
+				Name:   "enable",
+				Usage:  "Enable maintenance mode",
+				Action: runEnableMaintenance,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "message, m",
+						Usage: "Message shown to users whose writes are declined",
+					},
+					cli.BoolFlag{
+						Name:  "wait",
+						Usage: "Wait for in-flight requests and queued items to drain before returning",
+					},
+					cli.DurationFlag{
+						Name:  "timeout",
+						Value: 5 * time.Minute,
+						Usage: "Timeout for waiting to drain",
+					},
+					cli.BoolFlag{
+						Name: "debug",
+					},
+				},
+			}, {
+				Name:   "disable",
+				Usage:  "Disable maintenance mode",
+				Action: runDisableMaintenance,
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name: "debug",
+					},
+				},
+			}, {
+				Name:   "status",
+				Usage:  "Show the maintenance mode and the work which is still draining",
+				Action: runMaintenanceStatus,
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name: "debug",
+					},
+				},
+			},
+		},
+	}
 	defaultLoggingFlags = []cli.Flag{
 		cli.StringFlag{
 			Name:  "group, g",
@@ -452,3 +500,62 @@ func runReleaseReopenLogging(c *cli.Context) error {
 	fmt.Fprintln(os.Stdout, msg)
 	return nil
 }
+
+func runEnableMaintenance(c *cli.Context) error {
+	setup("manager", c.Bool("debug"))
+	statusCode, msg := private.SetMaintenance(true, c.String("message"))
+	switch statusCode {
+	case http.StatusInternalServerError:
+		fail("InternalServerError", msg)
+	}
+	fmt.Fprintln(os.Stdout, msg)
+
+	if !c.Bool("wait") {
+		return nil
+	}
+	deadline := time.Now().Add(c.Duration("timeout"))
+	for {
+		status, statusCode, msg := private.GetMaintenanceStatus()
+		if statusCode != http.StatusOK {
+			fail("InternalServerError", msg)
+		}
+		if status.IsDrained() {
+			fmt.Fprintln(os.Stdout, "Drained")
+			return nil
+		}
+		if time.Now().After(deadline) {
+			fail(fmt.Sprintf("Timed out waiting to drain: %d requests in flight, %d items queued, %d items in progress",
+				status.InFlightRequests, status.QueuedItems, status.InProgressItems), "")
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func runDisableMaintenance(c *cli.Context) error {
+	setup("manager", c.Bool("debug"))
+	statusCode, msg := private.SetMaintenance(false, "")
+	switch statusCode {
+	case http.StatusInternalServerError:
+		fail("InternalServerError", msg)
+	}
+
+	fmt.Fprintln(os.Stdout, msg)
+	return nil
+}
+
+func runMaintenanceStatus(c *cli.Context) error {
+	setup("manager", c.Bool("debug"))
+	status, statusCode, msg := private.GetMaintenanceStatus()
+	if statusCode != http.StatusOK {
+		fail("InternalServerError", msg)
+	}
+
+	if status.Enabled {
+		fmt.Fprintf(os.Stdout, "Maintenance mode enabled since %s\nMessage: %s\n", status.Since.Format(time.RFC3339), status.Message)
+	} else {
+		fmt.Fprintln(os.Stdout, "Maintenance mode disabled")
+	}
+	fmt.Fprintf(os.Stdout, "Requests in flight: %d\nItems queued: %d\nItems in progress: %d\n",
+		status.InFlightRequests, status.QueuedItems, status.InProgressItems)
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+
+	archiver "github.com/mholt/archiver/v3"
+	"github.com/unknwon/com"
+	"github.com/urfave/cli"
+)
+
+// CmdRestoreRepo represents the available restore-repo sub-command.
+var CmdRestoreRepo = cli.Command{
+	Name:  "restore-repo",
+	Usage: "Restore a repository from a dump",
+	Description: `Restore the git data, database rows, LFS objects and attachments of a single repository
+from a file created by dump, e.g. after the repository was deleted. The owner must exist.`,
+	Action: runRestoreRepo,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "file, f",
+			Usage: "Name of the dump file",
+		},
+		cli.StringFlag{
+			Name:  "repository, r",
+			Usage: "Repository to restore, given as owner/name",
+		},
+	},
+}
+
+// archiveFilePath returns the path of a file in an archive
+func archiveFilePath(f archiver.File) string {
+	switch h := f.Header.(type) {
+	case zip.FileHeader:
+		return h.Name
+	case *tar.Header:
+		return h.Name
+	}
+	return f.Name()
+}
+
+// extractFile writes a file of an archive
+func extractFile(f archiver.File, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode().Perm()|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, f); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func runRestoreRepo(ctx *cli.Context) error {
+	fileName := ctx.String("file")
+	if fileName == "" {
+		return errors.New("the dump file is required")
+	}
+	fullName := strings.ToLower(ctx.String("repository"))
+	parts := strings.SplitN(fullName, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return errors.New("the repository must be given as owner/name")
+	}
+
+	if err := initDB(); err != nil {
+		return err
+	}
+
+	// the rows tell which LFS objects and attachments belong to the repository
+	rowsPath := repoRowsArchivePath(parts[0], parts[1])
+	var rows *models.RepoDump
+	if err := archiver.Walk(fileName, func(f archiver.File) error {
+		if archiveFilePath(f) != rowsPath {
+			return nil
+		}
+		var err error
+		rows, err = models.ReadRepoDump(f)
+		return err
+	}); err != nil {
+		return err
+	}
+	if rows == nil {
+		return fmt.Errorf("the dump has no database rows of %s, it was created with --skip-repository-rows or by an older version", fullName)
+	}
+
+	repoPath := models.RepoPath(parts[0], parts[1])
+	wikiPath := models.WikiPath(parts[0], parts[1])
+	if com.IsExist(repoPath) {
+		return fmt.Errorf("the repository directory %s already exists", repoPath)
+	}
+	repoArchive, err := repoArchivePath(repoPath)
+	if err != nil {
+		return err
+	}
+	wikiArchive, err := repoArchivePath(wikiPath)
+	if err != nil {
+		return err
+	}
+	files := make(map[string]string)
+	for _, oid := range rows.LFSOids() {
+		relPath := path.Join(oid[0:2], oid[2:4], oid[4:])
+		files[path.Join("lfs", relPath)] = filepath.Join(setting.LFS.ContentPath, relPath)
+	}
+	if attachmentsArchive, err := attachmentsArchivePath(); err == nil {
+		for _, uuid := range rows.AttachmentUUIDs() {
+			absPath := models.AttachmentLocalPath(uuid)
+			relPath, err := filepath.Rel(setting.AttachmentPath, absPath)
+			if err != nil {
+				return err
+			}
+			files[path.Join(attachmentsArchive, filepath.ToSlash(relPath))] = absPath
+		}
+	} else {
+		log.Warn("%v, the attachments are not restored", err)
+	}
+
+	log.Info("Restoring the files of %s...", fullName)
+	restored := 0
+	if err := archiver.Walk(fileName, func(f archiver.File) error {
+		if f.IsDir() {
+			return nil
+		}
+		name := archiveFilePath(f)
+		var dest string
+		switch {
+		case strings.HasPrefix(name, repoArchive+"/"):
+			dest = filepath.Join(repoPath, filepath.FromSlash(strings.TrimPrefix(name, repoArchive+"/")))
+		case strings.HasPrefix(name, wikiArchive+"/"):
+			dest = filepath.Join(wikiPath, filepath.FromSlash(strings.TrimPrefix(name, wikiArchive+"/")))
+		default:
+			// the LFS objects and attachments may be shared with other repositories
+			if dest = files[name]; dest == "" || com.IsFile(dest) {
+				return nil
+			}
+		}
+		restored++
+		return extractFile(f, dest)
+	}); err != nil {
+		return err
+	}
+	if !com.IsDir(repoPath) {
+		return fmt.Errorf("the dump has no git data of %s", fullName)
+	}
+
+	log.Info("Restoring the database rows of %s...", fullName)
+	repo, err := models.RestoreRepository(rows)
+	if err != nil {
+		_ = os.RemoveAll(repoPath)
+		_ = os.RemoveAll(wikiPath)
+		return err
+	}
+
+	// the hooks of the dump may call the binary at another path
+	if err := repo_module.CreateDelegateHooks(repoPath); err != nil {
+		return err
+	}
+	if com.IsDir(wikiPath) {
+		if err := repo_module.CreateDelegateHooks(wikiPath); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Restored %s with %d files\n", repo.FullName(), restored)
+	return nil
+}
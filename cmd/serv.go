@@ -93,6 +93,11 @@ func runServ(c *cli.Context) error {
 	// FIXME: This needs to internationalised
 	setup("serv.log", c.Bool("debug"))
 
+	// The internal requests of this session and of the hooks it runs share one request ID
+	if !log.IsValidRequestID(os.Getenv(log.EnvRequestID)) {
+		os.Setenv(log.EnvRequestID, log.NewRequestID())
+	}
+
 	if setting.SSH.Disabled {
 		println("Gitea: SSH has been disabled")
 		return nil
@@ -191,7 +196,9 @@ func runServ(c *cli.Context) error {
 	if err != nil {
 		if private.IsErrServCommand(err) {
 			errServCommand := err.(private.ErrServCommand)
-			if errServCommand.StatusCode != http.StatusInternalServerError {
+			if errServCommand.StatusCode == http.StatusServiceUnavailable {
+				fail(errServCommand.Error(), "")
+			} else if errServCommand.StatusCode != http.StatusInternalServerError {
 				fail("Unauthorized", "%s", errServCommand.Error())
 			} else {
 				fail("Internal Server Error", "%s", errServCommand.Error())
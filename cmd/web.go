@@ -17,6 +17,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/routers"
 	"code.gitea.io/gitea/routers/routes"
+	pages_service "code.gitea.io/gitea/services/pages"
 
 	context2 "github.com/gorilla/context"
 	"github.com/unknwon/com"
@@ -69,7 +70,7 @@ func runHTTPRedirector() {
 func runLetsEncrypt(listenAddr, domain, directory, email string, m http.Handler) error {
 	certManager := autocert.Manager{
 		Prompt:     autocert.AcceptTOS,
-		HostPolicy: autocert.HostWhitelist(domain),
+		HostPolicy: letsEncryptHostPolicy(domain),
 		Cache:      autocert.DirCache(directory),
 		Email:      email,
 	}
@@ -84,11 +85,35 @@ func runLetsEncrypt(listenAddr, domain, directory, email string, m http.Handler)
 	return runHTTPSWithTLSConfig("tcp", listenAddr, certManager.TLSConfig(), context2.ClearHandler(m))
 }
 
+// letsEncryptHostPolicy allows the certificates of the domain and, when the pages are enabled,
+// of the hosts serving static sites
+func letsEncryptHostPolicy(domain string) autocert.HostPolicy {
+	whitelist := autocert.HostWhitelist(domain)
+	return func(ctx context.Context, host string) error {
+		if err := whitelist(ctx, host); err == nil || !setting.Pages.Enabled {
+			return err
+		}
+		isPagesHost, err := pages_service.IsPagesHost(host)
+		if err != nil {
+			return err
+		} else if !isPagesHost {
+			return fmt.Errorf("acme/autocert: host %q not configured", host)
+		}
+		return nil
+	}
+}
+
 func runLetsEncryptFallbackHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" && r.Method != "HEAD" {
 		http.Error(w, "Use HTTPS", http.StatusBadRequest)
 		return
 	}
+	if setting.Pages.Enabled && !strings.EqualFold(r.Host, setting.Domain) {
+		if isPagesHost, err := pages_service.IsPagesHost(r.Host); err == nil && isPagesHost {
+			http.Redirect(w, r, "https://"+r.Host+r.URL.RequestURI(), http.StatusFound)
+			return
+		}
+	}
 	// Remove the trailing slash at the end of setting.AppURL, the request
 	// URI always contains a leading slash, which would result in a double
 	// slash
@@ -0,0 +1,100 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminReloadConfig(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user1")
+
+	req := NewRequest(t, "GET", "/admin/config")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.Contains(t, htmlDoc.doc.Find("form[action$='/admin/config/reload']").Parent().Text(), "git.http")
+
+	req = NewRequestWithValues(t, "POST", "/admin/config/reload", map[string]string{
+		"_csrf": htmlDoc.GetCSRF(),
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	flash := session.GetCookie("macaron_flash")
+	assert.NotNil(t, flash)
+	assert.Contains(t, flash.Value, "info")
+}
+
+func TestAdminConfigEditor(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user1")
+
+	req := NewRequest(t, "GET", "/admin/config/edit")
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	oldConf := setting.CustomConf
+	setting.Admin.EnableConfigEditor = true
+	defer func() {
+		setting.CustomConf = oldConf
+		setting.Admin.EnableConfigEditor = false
+		_, err := setting.ReloadSettings()
+		assert.NoError(t, err)
+	}()
+	content, err := ioutil.ReadFile(oldConf)
+	assert.NoError(t, err)
+	dir, err := ioutil.TempDir("", "config-editor")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	setting.CustomConf = filepath.Join(dir, "app.ini")
+	assert.NoError(t, ioutil.WriteFile(setting.CustomConf, content, 0600))
+	_, err = setting.ReloadSettings()
+	assert.NoError(t, err)
+
+	req = NewRequest(t, "GET", "/admin/config/edit")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "[server]")
+
+	req = NewRequest(t, "GET", "/admin/config/edit/security")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	value, _ := htmlDoc.doc.Find("input[name='value_SECRET_KEY']").Attr("value")
+	assert.Empty(t, value)
+
+	req = NewRequest(t, "GET", "/admin/config/edit/webhook")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	req = NewRequestWithValues(t, "POST", "/admin/config/edit/webhook", map[string]string{
+		"_csrf":     htmlDoc.GetCSRF(),
+		"new_key":   "DELIVER_TIMEOUT",
+		"new_value": "7",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	assert.Equal(t, 7, setting.Webhook.DeliverTimeout)
+
+	req = NewRequestWithValues(t, "POST", "/admin/config/edit/webhook", map[string]string{
+		"_csrf":                 htmlDoc.GetCSRF(),
+		"value_DELIVER_TIMEOUT": "soon",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	assert.Equal(t, 7, setting.Webhook.DeliverTimeout)
+
+	req = NewRequestWithValues(t, "POST", "/admin/config/edit/webhook", map[string]string{
+		"_csrf":                  htmlDoc.GetCSRF(),
+		"delete_DELIVER_TIMEOUT": "on",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	assert.Equal(t, 5, setting.Webhook.DeliverTimeout)
+
+	session = loginUser(t, "user2")
+	req = NewRequest(t, "GET", "/admin/config/edit")
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminGitTransfers(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	pktLines := func(lines ...string) string {
+		var sb strings.Builder
+		for _, line := range lines {
+			if line == "0000" {
+				sb.WriteString(line)
+				continue
+			}
+			fmt.Fprintf(&sb, "%04x%s\n", len(line)+5, line)
+		}
+		return sb.String()
+	}
+	uploadPack := func(body string) {
+		req := NewRequestWithBody(t, "POST", "/user2/repo1.git/git-upload-pack", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-git-upload-pack-request")
+		MakeRequest(t, req, http.StatusOK)
+	}
+
+	uploadPack(pktLines("want 65f1bf27bc3bf70f64657658635e66094edbcb4d side-band-64k", "0000", "done"))
+	uploadPack(pktLines("want 65f1bf27bc3bf70f64657658635e66094edbcb4d side-band-64k shallow", "deepen 1", "0000", "done"))
+
+	stats, err := models.GetGitTransportStats(time.Now())
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 2) {
+		for _, stat := range stats {
+			assert.Equal(t, models.GitTransferClone, stat.Kind)
+			assert.Contains(t, []int{0, 1}, stat.Depth)
+			assert.EqualValues(t, 1, stat.Requests)
+			assert.True(t, stat.RequestBytes > 0)
+			assert.True(t, stat.ResponseBytes > 0)
+		}
+	}
+
+	session := loginUser(t, "user1")
+	req := NewRequest(t, "GET", "/admin/git-transfers?days=7")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	doc := NewHTMLParser(t, resp.Body)
+	assert.Contains(t, doc.doc.Find(".admin.git-transfers").Text(), "Full history")
+
+	session = loginUser(t, "user2")
+	req = NewRequest(t, "GET", "/admin/git-transfers")
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/templates"
+	"code.gitea.io/gitea/services/mailer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminMailTemplates(t *testing.T) {
+	defer prepareTestEnv(t)()
+	defer func() {
+		assert.NoError(t, templates.ReloadMailer())
+	}()
+	// user1 is an admin user
+	session := loginUser(t, "user1")
+
+	resp := session.MakeRequest(t, NewRequest(t, "GET", "/admin/mail-templates"), http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "<code>auth/activate</code>")
+	resp = session.MakeRequest(t, NewRequest(t, "GET", "/admin/mail-templates/edit?name=auth/activate"), http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	csrf := htmlDoc.GetCSRF()
+	baseSHA256, _ := htmlDoc.doc.Find(`input[name="base_sha256"]`).Attr("value")
+	assert.Len(t, baseSHA256, 64)
+	session.MakeRequest(t, NewRequest(t, "GET", "/admin/mail-templates/edit?name=unknown"), http.StatusNotFound)
+
+	// the preview renders the template with sample data
+	req := NewRequestWithValues(t, "POST", "/admin/mail-templates/preview", map[string]string{
+		"_csrf":   csrf,
+		"name":    "auth/activate",
+		"content": "<p>Welcome {{.DisplayName}}</p>",
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var preview mailer.MailTemplatePreview
+	DecodeJSON(t, resp, &preview)
+	assert.Equal(t, "<p>Welcome User One</p>", preview.Body)
+
+	req = NewRequestWithValues(t, "POST", "/admin/mail-templates/preview", map[string]string{
+		"_csrf":   csrf,
+		"name":    "auth/activate",
+		"content": "<p>{{.DisplayName</p>",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// saving uses the template for the mails
+	req = NewRequestWithValues(t, "POST", "/admin/mail-templates/edit", map[string]string{
+		"_csrf":        csrf,
+		"name":         "auth/activate",
+		"content":      "<p>Welcome {{.DisplayName}}</p>",
+		"text_content": "Welcome {{.DisplayName}}",
+		"base_sha256":  baseSHA256,
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	tmpl := models.AssertExistsAndLoadBean(t, &models.MailTemplate{Name: "auth/activate"}).(*models.MailTemplate)
+	assert.Equal(t, 1, tmpl.Version)
+	models.AssertExistsAndLoadBean(t, &models.MailTemplateVersion{MailTemplateID: tmpl.ID, Version: 1, BaseSHA256: baseSHA256})
+
+	resp = session.MakeRequest(t, NewRequest(t, "GET", "/admin/mail-templates"), http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "Customized (version 1)")
+
+	// an invalid template is not saved
+	req = NewRequestWithValues(t, "POST", "/admin/mail-templates/edit", map[string]string{
+		"_csrf":   csrf,
+		"name":    "auth/activate",
+		"content": "{{if}}",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.MailTemplateVersion{MailTemplateID: tmpl.ID, Version: 2})
+
+	req = NewRequestWithValues(t, "POST", "/admin/mail-templates/use", map[string]string{
+		"_csrf":   csrf,
+		"name":    "auth/activate",
+		"version": "0",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.MailTemplate{Name: "auth/activate", Version: 0})
+
+	// only the administrators can customize the templates
+	session = loginUser(t, "user2")
+	session.MakeRequest(t, NewRequest(t, "GET", "/admin/mail-templates"), http.StatusForbidden)
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoActivity(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/issues?token="+token, &api.CreateIssueOption{
+		Title: "activity",
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/activity/heatmap")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var heatmap []*models.UserHeatmapData
+	DecodeJSON(t, resp, &heatmap)
+	if assert.Len(t, heatmap, 1) {
+		assert.EqualValues(t, 1, heatmap[0].Contributions)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/activity/contributors")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var contributors []*api.ActivityContributor
+	DecodeJSON(t, resp, &contributors)
+	if assert.Len(t, contributors, 1) {
+		assert.EqualValues(t, "user2", contributors[0].User.UserName)
+		assert.EqualValues(t, 1, contributors[0].Contributions)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/activity/heatmap?since=2000-01-01")
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/activity/heatmap?before=2000-01-01T00:00:00Z")
+	resp = MakeRequest(t, req, http.StatusOK)
+	heatmap = nil
+	DecodeJSON(t, resp, &heatmap)
+	assert.Len(t, heatmap, 0)
+
+	// the home page shows the activity
+	req = NewRequest(t, "GET", "/user2/repo1")
+	resp = MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(".activity-contributors a[href='/user2']").Length())
+}
+
+func TestAPIOrgActivity(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// repo3 is a private repository of the organization user3
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user3/repo3/issues?token="+token, &api.CreateIssueOption{
+		Title: "activity",
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequest(t, "GET", "/api/v1/orgs/user3/activity/contributors?token="+token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var contributors []*api.ActivityContributor
+	DecodeJSON(t, resp, &contributors)
+	if assert.Len(t, contributors, 1) {
+		assert.EqualValues(t, "user2", contributors[0].User.UserName)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/orgs/user3/activity/heatmap")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var heatmap []*models.UserHeatmapData
+	DecodeJSON(t, resp, &heatmap)
+	assert.Len(t, heatmap, 0)
+}
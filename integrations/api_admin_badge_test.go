@@ -0,0 +1,161 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIAdminBadges(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// user1 is an admin user
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/admin/badges?token="+token, &api.CreateBadgeOption{
+		Slug:        "triager",
+		Name:        "Triager",
+		Description: "Helps to keep the issues tidy",
+		Icon:        "octicon-tasklist",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var badge api.Badge
+	DecodeJSON(t, resp, &badge)
+	assert.EqualValues(t, "triager", badge.Slug)
+	assert.EqualValues(t, "manual", badge.Type)
+
+	// the identifier is unique
+	req = NewRequestWithJSON(t, "POST", "/api/v1/admin/badges?token="+token, &api.CreateBadgeOption{
+		Slug: "triager",
+		Name: "Another Triager",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// member_years badges need a threshold
+	memberYears := "member_years"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/admin/badges/%d?token=%s", badge.ID, token), &api.EditBadgeOption{
+		Type: &memberYears,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	name := "Issue Triager"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/admin/badges/%d?token=%s", badge.ID, token), &api.EditBadgeOption{
+		Name: &name,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &badge)
+	assert.EqualValues(t, "Issue Triager", badge.Name)
+
+	req = NewRequestf(t, "PUT", "/api/v1/admin/badges/%d/users/user4?token=%s", badge.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequestf(t, "PUT", "/api/v1/admin/badges/%d/users/user3?token=%s", badge.ID, token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequest(t, "GET", "/api/v1/users/user4/badges")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var badges []*api.Badge
+	DecodeJSON(t, resp, &badges)
+	if assert.Len(t, badges, 1) {
+		assert.EqualValues(t, badge.ID, badges[0].ID)
+	}
+
+	req = NewRequest(t, "GET", "/user4")
+	resp = MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(".user-badges li").Length())
+	assert.EqualValues(t, "Issue Triager", htmlDoc.doc.Find(".user-badges li").AttrOr("data-title", ""))
+
+	req = NewRequestf(t, "GET", "/api/v1/admin/badges/%d/users?token=%s", badge.ID, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var users []*api.User
+	DecodeJSON(t, resp, &users)
+	if assert.Len(t, users, 1) {
+		assert.EqualValues(t, "user4", users[0].UserName)
+	}
+
+	req = NewRequestf(t, "DELETE", "/api/v1/admin/badges/%d/users/user4?token=%s", badge.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.UserBadge{BadgeID: badge.ID, UserID: 4})
+
+	// built-in badges cannot be deleted
+	req = NewRequestf(t, "DELETE", "/api/v1/admin/badges/1?token=%s", token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/admin/badges/%d?token=%s", badge.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.Badge{ID: badge.ID})
+}
+
+func TestAPIAdminBadgesForbidden(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequest(t, "GET", "/api/v1/admin/badges?token="+token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAdminBadges(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user1")
+
+	req := NewRequestWithValues(t, "POST", "/admin/badges/new", map[string]string{
+		"_csrf": GetCSRF(t, session, "/admin/badges/new"),
+		"slug":  "veteran",
+		"name":  "Veteran",
+		"icon":  "octicon-clock",
+		"type":  "member_years",
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(".field.error #threshold").Length())
+
+	req = NewRequestWithValues(t, "POST", "/admin/badges/new", map[string]string{
+		"_csrf":     GetCSRF(t, session, "/admin/badges/new"),
+		"slug":      "veteran",
+		"name":      "Veteran",
+		"icon":      "octicon-clock",
+		"type":      "member_years",
+		"threshold": "10",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	badge := models.AssertExistsAndLoadBean(t, &models.Badge{Slug: "veteran"}).(*models.Badge)
+	assert.EqualValues(t, models.BadgeTypeMemberYears, badge.Type)
+	assert.EqualValues(t, 10, badge.Threshold)
+
+	link := fmt.Sprintf("/admin/badges/%d", badge.ID)
+	req = NewRequestWithValues(t, "POST", link+"/grant", map[string]string{
+		"_csrf":     GetCSRF(t, session, link),
+		"user_name": "user5",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.UserBadge{BadgeID: badge.ID, UserID: 5})
+
+	req = NewRequest(t, "GET", link)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.Contains(t, htmlDoc.doc.Find("table").Text(), "user5")
+
+	req = NewRequestWithValues(t, "POST", link+"/revoke", map[string]string{
+		"_csrf": GetCSRF(t, session, link),
+		"id":    "5",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.UserBadge{BadgeID: badge.ID, UserID: 5})
+
+	req = NewRequestWithValues(t, "POST", "/admin/badges/delete", map[string]string{
+		"_csrf": GetCSRF(t, session, link),
+		"id":    fmt.Sprint(badge.ID),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.Badge{ID: badge.ID})
+}
@@ -144,3 +144,92 @@ func TestAPIListUsersNonAdmin(t *testing.T) {
 	req := NewRequestf(t, "GET", "/api/v1/admin/users?token=%s", token)
 	session.MakeRequest(t, req, http.StatusForbidden)
 }
+
+func TestAPIAdminStats(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/admin/stats?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var stats api.InstanceStats
+	DecodeJSON(t, resp, &stats)
+	assert.EqualValues(t, models.CountUsers(), stats.Counts.Users)
+	assert.EqualValues(t, models.CountRepositories(true), stats.Counts.Repos)
+	assert.NotNil(t, stats.Storage)
+	assert.Len(t, stats.Indexers, 2)
+
+	req = NewRequestf(t, "GET", "/api/v1/admin/health?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var health api.InstanceHealth
+	DecodeJSON(t, resp, &health)
+	assert.Equal(t, "pass", health.Status)
+	assert.Len(t, health.Checks, 2)
+}
+
+func TestAPIAdminStatsNonAdmin(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestf(t, "GET", "/api/v1/admin/stats?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestf(t, "GET", "/api/v1/admin/health?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIAdminMaintenance(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "PATCH", "/api/v1/admin/maintenance?token="+token, &api.EditMaintenanceOption{
+		Enabled: true,
+		Message: "Upgrading",
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var status api.MaintenanceStatus
+	DecodeJSON(t, resp, &status)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, "Upgrading", status.Message)
+	assert.NotNil(t, status.Since)
+
+	// writes are declined, reads are not
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/repos?token="+token, &api.CreateRepoOption{
+		Name: "maintenance-repo",
+	})
+	resp = session.MakeRequest(t, req, http.StatusServiceUnavailable)
+	assert.Contains(t, resp.Body.String(), "Upgrading")
+	req = NewRequestf(t, "GET", "/api/v1/user/repos?token=%s", token)
+	session.MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/admin/maintenance?token="+token, &api.EditMaintenanceOption{
+		Enabled: false,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &status)
+	assert.False(t, status.Enabled)
+	assert.Nil(t, status.Since)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/repos?token="+token, &api.CreateRepoOption{
+		Name: "maintenance-repo",
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+}
+
+func TestAPIAdminReloadConfig(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "POST", "/api/v1/admin/config/reload?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var result api.ConfigReloadResult
+	DecodeJSON(t, resp, &result)
+	assert.Empty(t, result.Reloaded)
+	assert.Empty(t, result.RestartRequired)
+
+	session = loginUser(t, "user2")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "POST", "/api/v1/admin/config/reload?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
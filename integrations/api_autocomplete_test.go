@@ -0,0 +1,74 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIAutocompleteMentions(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user3/repo3/autocomplete/mentions?q=@User&token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var users []*api.AutocompleteUser
+	DecodeJSON(t, resp, &users)
+	if assert.Len(t, users, 2) {
+		assert.Equal(t, "user2", users[0].Username)
+		assert.Equal(t, "user4", users[1].Username)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user3/repo3/autocomplete/mentions?q=user&limit=1&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &users)
+	assert.Len(t, users, 1)
+
+	// user5 cannot read the private repository
+	session = loginUser(t, "user5")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user3/repo3/autocomplete/mentions?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIAutocompleteIssues(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/autocomplete/issues?q=%%232&token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var issues []*api.AutocompleteIssue
+	DecodeJSON(t, resp, &issues)
+	if assert.Len(t, issues, 1) {
+		issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: 1, Index: 2}).(*models.Issue)
+		assert.EqualValues(t, 2, issues[0].Index)
+		assert.Equal(t, issue.Title, issues[0].Title)
+		assert.Equal(t, issue.IsPull, issues[0].IsPull)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/autocomplete/issues?limit=2&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &issues)
+	assert.Len(t, issues, 2)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/autocomplete/issues?q=99&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &issues)
+	assert.Empty(t, issues)
+
+	// a token is required
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/autocomplete/issues")
+	MakeRequest(t, req, http.StatusUnauthorized)
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIAutoLinkRules(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// user2 is the owner of the organization user3 and of its repository repo3
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequest(t, "GET", "/api/v1/orgs/user3/autolinks")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var rules []*api.AutoLinkRule
+	DecodeJSON(t, resp, &rules)
+	assert.Len(t, rules, 2)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user3/repo3/autolinks?token="+token, &api.CreateAutoLinkRuleOption{
+		Pattern: `\bDOC-\d+\b`,
+		URL:     "https://docs.example.com/${0}",
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var rule api.AutoLinkRule
+	DecodeJSON(t, resp, &rule)
+	assert.EqualValues(t, 0, rule.OrgID)
+	models.AssertExistsAndLoadBean(t, &models.AutoLinkRule{ID: rule.ID, RepoID: 3})
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user3/repo3/autolinks?token="+token, &api.CreateAutoLinkRuleOption{
+		Pattern: `DOC-(\d+`,
+		URL:     "https://docs.example.com/$0",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// only the owners of the organization manage its rules
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/autolinks?token="+token4, &api.CreateAutoLinkRuleOption{
+		Pattern: `\bSEC-\d+\b`,
+		URL:     "https://sec.example.com/$0",
+	})
+	session4.MakeRequest(t, req, http.StatusForbidden)
+
+	// the rules of the repository come first, the rule of the organization with the same pattern is replaced
+	req = NewRequest(t, "GET", "/api/v1/repos/user3/repo3/autolinks?effective=true&token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &rules)
+	if assert.Len(t, rules, 3) {
+		assert.EqualValues(t, 3, rules[0].ID)
+		assert.EqualValues(t, rule.ID, rules[1].ID)
+		assert.EqualValues(t, 1, rules[2].ID)
+		assert.EqualValues(t, 3, rules[2].OrgID)
+	}
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user3/repo3/issues?token="+token, &api.CreateIssueOption{
+		Title: "auto-links",
+		Body:  "JIRA-12, OPS-34 and DOC-56",
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var issue api.Issue
+	DecodeJSON(t, resp, &issue)
+
+	req = NewRequest(t, "GET", fmt.Sprintf("/user3/repo3/issues/%d", issue.Index))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	body := resp.Body.String()
+	assert.Contains(t, body, `<a href="https://jira.example.com/browse/JIRA-12" rel="nofollow">JIRA-12</a>`)
+	assert.Contains(t, body, `<a href="https://ops.example.com/repo3/tickets/34" rel="nofollow">OPS-34</a>`)
+	assert.Contains(t, body, `<a href="https://docs.example.com/DOC-56" rel="nofollow">DOC-56</a>`)
+
+	url := "https://jira.example.com/issues/$0"
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/orgs/user3/autolinks/1?token="+token, &api.EditAutoLinkRuleOption{
+		URL: &url,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &rule)
+	assert.EqualValues(t, url, rule.URL)
+
+	// the rules of the organization cannot be changed through its repositories
+	req = NewRequest(t, "DELETE", "/api/v1/repos/user3/repo3/autolinks/1?token="+token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequest(t, "DELETE", "/api/v1/orgs/user3/autolinks/1?token="+token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.AutoLinkRule{ID: 1})
+}
+
+func TestAutoLinkRulesSettings(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user3/repo3/settings/autolinks")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	req = NewRequestWithValues(t, "POST", "/user3/repo3/settings/autolinks", map[string]string{
+		"_csrf":   htmlDoc.GetCSRF(),
+		"pattern": `\bDOC-\d+\b`,
+		"url":     "https://docs.example.com/$0",
+		"sort":    "1",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.AutoLinkRule{RepoID: 3, Pattern: `\bDOC-\d+\b`, Sort: 1})
+
+	req = NewRequest(t, "GET", "/org/user3/settings/autolinks")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	req = NewRequestWithValues(t, "POST", "/org/user3/settings/autolinks/2", map[string]string{
+		"_csrf":   htmlDoc.GetCSRF(),
+		"pattern": `\bOPS-(\d+)\b`,
+		"url":     "javascript:alert($1)",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertExistsAndLoadBean(t, &models.AutoLinkRule{ID: 2, URL: "https://ops.example.com/tickets/$1"})
+}
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"testing"
 
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 
 	"github.com/stretchr/testify/assert"
@@ -193,3 +194,50 @@ func TestAPIBranchProtection(t *testing.T) {
 	testAPIDeleteBranch(t, "master", http.StatusForbidden)
 	testAPIDeleteBranch(t, "branch2", http.StatusNoContent)
 }
+
+func TestAPIListBranchDivergences(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/branch_divergences?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var branches []*api.BranchDivergence
+	DecodeJSON(t, resp, &branches)
+
+	divergences := make(map[string]*api.BranchDivergence, len(branches))
+	for _, branch := range branches {
+		divergences[branch.Name] = branch
+	}
+	assert.NotContains(t, divergences, "master")
+	if assert.Contains(t, divergences, "develop") {
+		assert.EqualValues(t, "master", divergences["develop"].BaseBranch)
+		assert.EqualValues(t, 0, divergences["develop"].CommitsAhead)
+		assert.True(t, divergences["develop"].Merged)
+		assert.True(t, divergences["develop"].Stale)
+	}
+	if assert.Contains(t, divergences, "branch2") {
+		assert.EqualValues(t, 2, divergences["branch2"].CommitsAhead)
+		assert.False(t, divergences["branch2"].Merged)
+		assert.True(t, divergences["branch2"].Inactive)
+		assert.True(t, divergences["branch2"].Stale)
+	}
+
+	// Without inactive branches only the merged ones are stale
+	defer func(days int) {
+		setting.Repository.StaleBranchDays = days
+	}(setting.Repository.StaleBranchDays)
+	setting.Repository.StaleBranchDays = 0
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/branch_divergences?stale=true&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	branches = nil
+	DecodeJSON(t, resp, &branches)
+	assert.NotEmpty(t, branches)
+	for _, branch := range branches {
+		assert.NotEqual(t, "branch2", branch.Name)
+		assert.True(t, branch.Merged)
+		assert.False(t, branch.Inactive)
+	}
+}
@@ -106,6 +106,49 @@ func TestAPICreateComment(t *testing.T) {
 	models.AssertExistsAndLoadBean(t, &models.Comment{ID: updatedComment.ID, IssueID: issue.ID, Content: commentBody})
 }
 
+func TestAPICreateCommentWithSlashCommands(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: issue.RepoID}).(*models.Repository)
+	repoOwner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+
+	session := loginUser(t, repoOwner.Name)
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/%s/%s/issues/%d/comments?token=%s",
+		repoOwner.Name, repo.Name, issue.Index, token)
+
+	req := NewRequestWithValues(t, "POST", urlStr, map[string]string{
+		"body": "Assigning\n/assign @user2\n/label label2",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var comment api.Comment
+	DecodeJSON(t, resp, &comment)
+	assert.EqualValues(t, "Assigning", comment.Body)
+	models.AssertExistsAndLoadBean(t, &models.IssueAssignees{IssueID: issue.ID, AssigneeID: 2})
+	models.AssertExistsAndLoadBean(t, &models.IssueLabel{IssueID: issue.ID, LabelID: 2})
+
+	req = NewRequestWithValues(t, "POST", urlStr, map[string]string{
+		"body": "/milestone nonexistent",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithValues(t, "POST", urlStr, map[string]string{
+		"body": "/close",
+	})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: issue.ID, IsClosed: true})
+
+	// a user who cannot write issues cannot use commands
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithValues(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/issues/%d/comments?token=%s",
+		repoOwner.Name, repo.Name, issue.Index, token), map[string]string{
+		"body": "/label label1",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
+
 func TestAPIGetComment(t *testing.T) {
 	defer prepareTestEnv(t)()
 
@@ -0,0 +1,52 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIIssueGraph(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/issues?token="+token, &api.CreateIssueOption{
+		Title: "mentions an issue",
+		Body:  "related to #1",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var issue api.Issue
+	DecodeJSON(t, resp, &issue)
+
+	root := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: 1, Index: 1}).(*models.Issue)
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/issues/1/graph?depth=1")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var graph api.IssueGraph
+	DecodeJSON(t, resp, &graph)
+	assert.EqualValues(t, root.ID, graph.Root)
+	assert.Len(t, graph.Nodes, 2)
+	assert.False(t, graph.Truncated)
+	if assert.Len(t, graph.Edges, 1) {
+		assert.EqualValues(t, issue.ID, graph.Edges[0].From)
+		assert.EqualValues(t, root.ID, graph.Edges[0].To)
+		assert.EqualValues(t, "references", graph.Edges[0].Type)
+	}
+	for _, node := range graph.Nodes {
+		assert.EqualValues(t, "user2/repo1", node.Repository.FullName)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/issues/9999/graph")
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "GET", "/user2/repo1/issues/1/graph")
+	MakeRequest(t, req, http.StatusOK)
+}
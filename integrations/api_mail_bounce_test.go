@@ -0,0 +1,62 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIMailBounce(t *testing.T) {
+	defer prepareTestEnv(t)()
+	defer func(token string) {
+		setting.MailService.BounceWebhookToken = token
+	}(setting.MailService.BounceWebhookToken)
+
+	bounce := &api.MailBounceOption{Email: "user2@example.com", Reason: "5.1.1 unknown user"}
+
+	// the webhook is disabled without a token
+	setting.MailService.BounceWebhookToken = ""
+	MakeRequest(t, NewRequestWithJSON(t, "POST", "/api/v1/mail/bounces", bounce), http.StatusNotFound)
+
+	setting.MailService.BounceWebhookToken = "secret"
+	req := NewRequestWithJSON(t, "POST", "/api/v1/mail/bounces", bounce)
+	req.Header.Set("X-Gitea-Bounce-Token", "wrong")
+	MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/mail/bounces", &api.MailBounceOption{Email: "user2@example.com", Type: "unknown"})
+	req.Header.Set("X-Gitea-Bounce-Token", "secret")
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/mail/bounces", bounce)
+	req.Header.Set("X-Gitea-Bounce-Token", "secret")
+	MakeRequest(t, req, http.StatusNoContent)
+	stored := models.AssertExistsAndLoadBean(t, &models.EmailBounce{Email: "user2@example.com"}).(*models.EmailBounce)
+	assert.True(t, stored.IsSuppressed)
+	assert.Equal(t, models.EmailBounceHard, stored.Type)
+	assert.Equal(t, "webhook", stored.Source)
+
+	// the suppressed address is listed in the admin panel and can be allowed again
+	session := loginUser(t, "user1")
+	resp := session.MakeRequest(t, NewRequest(t, "GET", "/admin/emails/bounces"), http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "user2@example.com")
+	assert.Contains(t, resp.Body.String(), "5.1.1 unknown user")
+
+	req = NewRequestWithValues(t, "POST", "/admin/emails/bounces/delete", map[string]string{
+		"_csrf": GetCSRF(t, session, "/admin/emails/bounces"),
+		"id":    fmt.Sprint(stored.ID),
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertNotExistsBean(t, &models.EmailBounce{ID: stored.ID})
+
+	loginUser(t, "user2").MakeRequest(t, NewRequest(t, "GET", "/admin/emails/bounces"), http.StatusForbidden)
+}
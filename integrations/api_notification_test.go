@@ -120,3 +120,69 @@ func TestAPINotification(t *testing.T) {
 	DecodeJSON(t, resp, &new)
 	assert.True(t, new.New == 0)
 }
+
+func TestAPINotificationFilters(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo1 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	session := loginUser(t, user2.Name)
+	token := getTokenForLoggedInUser(t, session)
+
+	// user2 is mentioned in issue 5 of notification 4
+	assert.NoError(t, models.CreateOrUpdateIssueNotifications(5, 0, 1, user2.ID, models.NotificationReasonMention))
+
+	// -- filter by reason --
+	req := NewRequest(t, "GET", fmt.Sprintf("/api/v1/notifications?all=true&reasons=mention&reasons=assign&token=%s", token))
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiNL []api.NotificationThread
+	DecodeJSON(t, resp, &apiNL)
+	if assert.Len(t, apiNL, 1) {
+		assert.EqualValues(t, 4, apiNL[0].ID)
+		assert.Equal(t, "mention", apiNL[0].Reason)
+	}
+
+	// -- PUT/DELETE /notifications/threads/{id}/saved --
+	req = NewRequest(t, "PUT", fmt.Sprintf("/api/v1/notifications/threads/%d/saved?token=%s", 5, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "PUT", fmt.Sprintf("/api/v1/notifications/threads/%d/saved?token=%s", 1, token))
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/notifications?all=true&saved=true&token=%s", token))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiNL)
+	if assert.Len(t, apiNL, 1) {
+		assert.EqualValues(t, 5, apiNL[0].ID)
+		assert.True(t, apiNL[0].Saved)
+		assert.Equal(t, "watch", apiNL[0].Reason)
+	}
+
+	// -- mark the filtered notifications of a repository as read --
+	req = NewRequest(t, "PUT", fmt.Sprintf("/api/v1/repos/%s/%s/notifications?reasons=mention&token=%s", user2.Name, repo1.Name, token))
+	session.MakeRequest(t, req, http.StatusResetContent)
+	models.AssertExistsAndLoadBean(t, &models.Notification{ID: 4, Status: models.NotificationStatusRead})
+
+	req = NewRequest(t, "PUT", fmt.Sprintf("/api/v1/notifications?saved=false&token=%s", token))
+	session.MakeRequest(t, req, http.StatusResetContent)
+	models.AssertExistsAndLoadBean(t, &models.Notification{ID: 5, Status: models.NotificationStatusUnread})
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/notifications/threads/%d/saved?token=%s", 5, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+	thread5 := models.AssertExistsAndLoadBean(t, &models.Notification{ID: 5}).(*models.Notification)
+	assert.False(t, thread5.IsSaved)
+
+	// -- the notifications page --
+	req = NewRequest(t, "GET", "/notifications?q=unread&repo=2")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, "#notification_5", true)
+	htmlDoc.AssertElement(t, "#notification_4", false)
+
+	req = NewRequestWithValues(t, "POST", "/notifications/purge", map[string]string{
+		"_csrf": htmlDoc.GetCSRF(),
+		"repo":  "2",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	models.AssertExistsAndLoadBean(t, &models.Notification{ID: 5, Status: models.NotificationStatusRead})
+	models.AssertExistsAndLoadBean(t, &models.Notification{ID: 1, Status: models.NotificationStatusUnread})
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOrgBots(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// user2 is the owner of the organization user3
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/bots?token="+token, &api.CreateOrgBotOption{
+		UserName:      "ci-bot",
+		FullName:      "CI",
+		MaxPermission: "read",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var bot api.OrgBot
+	DecodeJSON(t, resp, &bot)
+	assert.EqualValues(t, "ci-bot", bot.Bot.UserName)
+	assert.True(t, bot.Bot.IsBot)
+	assert.EqualValues(t, "read", bot.MaxPermission)
+	assert.EqualValues(t, "user2", bot.Creator.UserName)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/bots?token="+token, &api.CreateOrgBotOption{
+		UserName:      "owner-bot",
+		MaxPermission: "owner",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// only the owners of the organization manage its bots
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequest(t, "GET", "/api/v1/orgs/user3/bots?token="+token4)
+	session4.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequest(t, "GET", "/api/v1/orgs/user3/bots?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var bots []*api.OrgBot
+	DecodeJSON(t, resp, &bots)
+	assert.Len(t, bots, 1)
+
+	// the bot joins team1, which has write access to repo3, and the owners team is out of scope
+	req = NewRequest(t, "PUT", fmt.Sprintf("/api/v1/teams/%d/members/ci-bot?token=%s", 2, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "PUT", fmt.Sprintf("/api/v1/teams/%d/members/ci-bot?token=%s", 1, token))
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/collaborators/ci-bot?token="+token, &api.AddCollaboratorOption{})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// the bot cannot sign in, it uses its token
+	req = NewRequest(t, "GET", "/api/v1/user")
+	req.SetBasicAuth("ci-bot", "")
+	MakeRequest(t, req, http.StatusUnauthorized)
+
+	req = NewRequest(t, "POST", "/api/v1/orgs/user3/bots/ci-bot/token?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var first api.AccessToken
+	DecodeJSON(t, resp, &first)
+	req = NewRequest(t, "POST", "/api/v1/orgs/user3/bots/ci-bot/token?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var second api.AccessToken
+	DecodeJSON(t, resp, &second)
+
+	req = NewRequest(t, "GET", "/api/v1/user?token="+first.Token)
+	MakeRequest(t, req, http.StatusUnauthorized)
+	req = NewRequest(t, "GET", "/api/v1/user?token="+second.Token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var user api.User
+	DecodeJSON(t, resp, &user)
+	assert.EqualValues(t, "ci-bot", user.UserName)
+
+	// the permission of the bot is capped to read
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user3/repo3/labels?token="+second.Token, &api.CreateLabelOption{
+		Name:  "bot",
+		Color: "#00aabb",
+	})
+	MakeRequest(t, req, http.StatusForbidden)
+
+	write := "write"
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/orgs/user3/bots/ci-bot?token="+token, &api.EditOrgBotOption{
+		MaxPermission: &write,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &bot)
+	assert.EqualValues(t, "write", bot.MaxPermission)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user3/repo3/labels?token="+second.Token, &api.CreateLabelOption{
+		Name:  "bot",
+		Color: "#00aabb",
+	})
+	MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequest(t, "DELETE", "/api/v1/orgs/user3/bots/ci-bot?token="+token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.User{LowerName: "ci-bot"})
+	req = NewRequest(t, "GET", "/api/v1/user?token="+second.Token)
+	MakeRequest(t, req, http.StatusUnauthorized)
+}
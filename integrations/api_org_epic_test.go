@@ -0,0 +1,43 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOrgEpics(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// user4 is a member of the organization user3 but not an owner
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req := NewRequest(t, "GET", "/api/v1/orgs/user3/epics/2?token="+token4)
+	session4.MakeRequest(t, req, http.StatusOK)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/epics?token="+token4, &api.CreateEpicOption{Title: "Member epic"})
+	session4.MakeRequest(t, req, http.StatusForbidden)
+	title := "Renamed by a member"
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/orgs/user3/epics/2?token="+token4, &api.EditEpicOption{Title: &title})
+	session4.MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "DELETE", "/api/v1/orgs/user3/epics/2?token="+token4)
+	session4.MakeRequest(t, req, http.StatusForbidden)
+
+	// user2 is an owner of the organization
+	session2 := loginUser(t, "user2")
+	token2 := getTokenForLoggedInUser(t, session2)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/epics?token="+token2, &api.CreateEpicOption{Title: "Owner epic"})
+	resp := session2.MakeRequest(t, req, http.StatusCreated)
+	var epic api.Epic
+	DecodeJSON(t, resp, &epic)
+	assert.Equal(t, "Owner epic", epic.Title)
+	assert.EqualValues(t, 3, epic.OwnerID)
+	req = NewRequestf(t, "DELETE", "/api/v1/orgs/user3/epics/%d?token=%s", epic.ID, token2)
+	session2.MakeRequest(t, req, http.StatusNoContent)
+}
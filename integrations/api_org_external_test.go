@@ -0,0 +1,91 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOrgMembershipExpiration(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// user2 is the owner of the organization user3
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	expires := time.Now().AddDate(0, 1, 0).Truncate(time.Second)
+	req := NewRequestWithJSON(t, "PATCH", "/api/v1/orgs/user3/members/user4?token="+token, &api.EditOrgMembershipOption{
+		ExpiresAt: &expires,
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var membership api.OrgMembership
+	DecodeJSON(t, resp, &membership)
+	assert.EqualValues(t, "user4", membership.User.UserName)
+	if assert.NotNil(t, membership.ExpiresAt) {
+		assert.EqualValues(t, expires.Unix(), membership.ExpiresAt.Unix())
+	}
+
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/orgs/user3/members/user4?token="+token, &api.EditOrgMembershipOption{
+		RemoveExpiration: true,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &membership)
+	assert.Nil(t, membership.ExpiresAt)
+
+	// user5 is not a member
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/orgs/user3/members/user5?token="+token, &api.EditOrgMembershipOption{
+		ExpiresAt: &expires,
+	})
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// only owners can change memberships
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/orgs/user3/members/user4?token="+token4, &api.EditOrgMembershipOption{
+		RemoveExpiration: true,
+	})
+	session4.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIOrgExternalCollaborators(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequest(t, "GET", "/api/v1/orgs/user3/external_collaborators?token="+token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var externals []*api.OrgExternalCollaborator
+	DecodeJSON(t, resp, &externals)
+	assert.Len(t, externals, 0)
+
+	// owners cannot become external collaborators
+	req = NewRequest(t, "PUT", "/api/v1/orgs/user3/external_collaborators/user2?token="+token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequest(t, "PUT", "/api/v1/orgs/user3/external_collaborators/user4?token="+token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.OrgUser{OrgID: 3, UID: 4})
+
+	req = NewRequest(t, "GET", "/api/v1/orgs/user3/external_collaborators?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &externals)
+	if assert.Len(t, externals, 1) {
+		assert.EqualValues(t, "user4", externals[0].User.UserName)
+		assert.EqualValues(t, []string{"user3/repo3"}, externals[0].Repositories)
+	}
+
+	req = NewRequest(t, "DELETE", "/api/v1/orgs/user3/external_collaborators/user4?token="+token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.Collaboration{RepoID: 3, UserID: 4})
+
+	req = NewRequest(t, "DELETE", "/api/v1/orgs/user3/external_collaborators/user4?token="+token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOrgProjects(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// user2 is the owner of the organization user3
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/projects?token="+token, &api.CreateProjectOption{
+		Title: "Q3 Roadmap",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var project api.Project
+	DecodeJSON(t, resp, &project)
+	assert.EqualValues(t, "Q3 Roadmap", project.Title)
+	assert.EqualValues(t, api.StateOpen, project.State)
+	projectURL := fmt.Sprintf("/api/v1/orgs/user3/projects/%d", project.ID)
+
+	req = NewRequestWithJSON(t, "POST", projectURL+"/boards?token="+token, &api.CreateProjectBoardOption{
+		Title: "Planned",
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var board api.ProjectBoard
+	DecodeJSON(t, resp, &board)
+	assert.EqualValues(t, 1, board.Sorting)
+
+	// a query needs a label or a keyword
+	req = NewRequestWithJSON(t, "POST", projectURL+"/issues?token="+token, &api.AddProjectIssuesOption{})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", projectURL+"/issues?token="+token, &api.AddProjectIssuesOption{
+		BoardID: board.ID,
+		Keyword: "issue6",
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var issues []*api.Issue
+	DecodeJSON(t, resp, &issues)
+	if assert.Len(t, issues, 1) {
+		assert.EqualValues(t, 6, issues[0].ID)
+	}
+
+	// new issues with the label of a rule are added automatically
+	req = NewRequestWithJSON(t, "POST", projectURL+"/rules?token="+token, &api.CreateProjectRuleOption{
+		Label: "orglabel3",
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user3/repo3/issues?token="+token, &api.CreateIssueOption{
+		Title:  "roadmap item",
+		Labels: []int64{3},
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var issue api.Issue
+	DecodeJSON(t, resp, &issue)
+
+	req = NewRequest(t, "GET", projectURL+"/issues?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var projectIssues []*api.ProjectIssue
+	DecodeJSON(t, resp, &projectIssues)
+	assert.Len(t, projectIssues, 2)
+
+	req = NewRequestf(t, "GET", "%s/issues?board=0&token=%s", projectURL, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &projectIssues)
+	if assert.Len(t, projectIssues, 1) {
+		assert.EqualValues(t, issue.ID, projectIssues[0].Issue.ID)
+	}
+
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("%s/issues/%d?token=%s", projectURL, issue.ID, token), &api.MoveProjectIssueOption{
+		BoardID: board.ID,
+	})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequestf(t, "GET", "%s/issues?board=%d&token=%s", projectURL, board.ID, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &projectIssues)
+	assert.Len(t, projectIssues, 2)
+
+	// the board can be filtered by repository
+	req = NewRequest(t, "GET", fmt.Sprintf("/org/user3/projects/%d?repo=3", project.ID))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 2, htmlDoc.doc.Find(".project-boards .card").Length())
+	req = NewRequest(t, "GET", fmt.Sprintf("/org/user3/projects/%d?repo=32", project.ID))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 0, htmlDoc.doc.Find(".project-boards .card").Length())
+
+	req = NewRequestf(t, "DELETE", "%s/issues/%d?token=%s", projectURL, issue.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequestf(t, "DELETE", "%s/issues/%d?token=%s", projectURL, issue.ID, token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// projects are only visible to the members of the organization
+	session5 := loginUser(t, "user5")
+	token5 := getTokenForLoggedInUser(t, session5)
+	req = NewRequest(t, "GET", projectURL+"?token="+token5)
+	session5.MakeRequest(t, req, http.StatusForbidden)
+
+	// the members who are not owners cannot change the projects
+	hijacked := "Hijacked"
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequest(t, "GET", projectURL+"?token="+token4)
+	session4.MakeRequest(t, req, http.StatusOK)
+	req = NewRequestWithJSON(t, "PATCH", projectURL+"?token="+token4, &api.EditProjectOption{Title: &hijacked})
+	session4.MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "DELETE", projectURL+"?token="+token4)
+	session4.MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/projects?token="+token4, &api.CreateProjectOption{Title: "Other"})
+	session4.MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "GET", fmt.Sprintf("/org/user3/projects/%d", project.ID))
+	resp = session4.MakeRequest(t, req, http.StatusOK)
+	assert.EqualValues(t, 0, NewHTMLParser(t, resp.Body).doc.Find(`form[action$="/boards/new"]`).Length())
+	req = NewRequest(t, "GET", fmt.Sprintf("/org/user3/projects/%d/edit", project.ID))
+	session4.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "DELETE", projectURL+"?token="+token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "GET", projectURL+"?token="+token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
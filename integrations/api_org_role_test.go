@@ -0,0 +1,106 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOrgRoles(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// user2 is the owner of the organization user3
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/roles?token="+token, &api.CreateOrgRoleOption{
+		Name:        "Triage",
+		Permissions: map[string]string{"repo.code": "read", "repo.issues": "write"},
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var role api.OrgRole
+	DecodeJSON(t, resp, &role)
+	assert.EqualValues(t, "Triage", role.Name)
+	assert.EqualValues(t, map[string]string{"repo.code": "read", "repo.issues": "write"}, role.Permissions)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/roles?token="+token, &api.CreateOrgRoleOption{
+		Name:        "Admin",
+		Permissions: map[string]string{"repo.code": "admin"},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// user4 is a member of team1, which has write access to repo3
+	roleID := role.ID
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/teams/%d?token=%s", 2, token), &api.EditTeamOption{
+		RoleID: &roleID,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var team api.Team
+	DecodeJSON(t, resp, &team)
+	assert.EqualValues(t, role.ID, team.RoleID)
+
+	// the role is enforced on git access: user4 cannot push to repo3 anymore
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 3}).(*models.Repository)
+	user4 := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	perm, err := models.GetUserRepoPermission(repo, user4)
+	assert.NoError(t, err)
+	assert.False(t, perm.CanWrite(models.UnitTypeCode))
+	assert.True(t, perm.CanWrite(models.UnitTypeIssues))
+
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user3/repo3/labels?token="+token4, &api.CreateLabelOption{
+		Name:  "triaged",
+		Color: "#00aabb",
+	})
+	session4.MakeRequest(t, req, http.StatusCreated)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user3/repo3/releases?token="+token4, &api.CreateReleaseOption{
+		TagName: "v9.9",
+		Title:   "not allowed",
+	})
+	session4.MakeRequest(t, req, http.StatusForbidden)
+
+	// members can list the roles but only owners can change them
+	req = NewRequest(t, "GET", "/api/v1/orgs/user3/roles?token="+token4)
+	resp = session4.MakeRequest(t, req, http.StatusOK)
+	var roles []*api.OrgRole
+	DecodeJSON(t, resp, &roles)
+	assert.Len(t, roles, 1)
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/orgs/user3/roles/%d?token=%s", role.ID, token4))
+	session4.MakeRequest(t, req, http.StatusForbidden)
+
+	name := "Wiki"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/orgs/user3/roles/%d?token=%s", role.ID, token), &api.EditOrgRoleOption{
+		Name:        &name,
+		Permissions: map[string]string{"repo.code": "read", "repo.wiki": "write"},
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &role)
+	assert.EqualValues(t, "Wiki", role.Name)
+	perm, err = models.GetUserRepoPermission(repo, user4)
+	assert.NoError(t, err)
+	assert.False(t, perm.CanWrite(models.UnitTypeIssues))
+	assert.True(t, perm.CanWrite(models.UnitTypeWiki))
+
+	// the role is assigned to team1
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/orgs/user3/roles/%d?token=%s", role.ID, token))
+	session.MakeRequest(t, req, http.StatusConflict)
+
+	roleID = 0
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/teams/%d?token=%s", 2, token), &api.EditTeamOption{
+		RoleID: &roleID,
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/orgs/user3/roles/%d?token=%s", role.ID, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/orgs/user3/roles/%d?token=%s", role.ID, token))
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
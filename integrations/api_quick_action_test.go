@@ -0,0 +1,85 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIQuickActions(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	search := func(query string) []*api.QuickAction {
+		req := NewRequest(t, "GET", "/api/v1/user/quick_actions?token="+token+"&"+query)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var items []*api.QuickAction
+		DecodeJSON(t, resp, &items)
+		return items
+	}
+	find := func(items []*api.QuickAction, key string) *api.QuickAction {
+		for _, item := range items {
+			if item.Key == key {
+				return item
+			}
+		}
+		return nil
+	}
+
+	// without a keyword the actions are listed
+	items := search("")
+	assert.NotNil(t, find(items, "action:new_repo"))
+	assert.Nil(t, find(items, "action:admin"))
+
+	items = search("q=repo1")
+	repo1 := find(items, "repo:1")
+	if assert.NotNil(t, repo1) {
+		assert.Equal(t, "user2/repo1", repo1.Title)
+		assert.Equal(t, "/user2/repo1", repo1.URL)
+	}
+
+	// the actions of the repository are added, issues are found by reference
+	items = search("q=%231&repo=user2/repo1")
+	assert.NotNil(t, find(items, "issue:1"))
+	items = search("q=new&repo=user2/repo1")
+	assert.NotNil(t, find(items, "action:new_issue:1"))
+
+	// private repositories of others are not found
+	assert.Nil(t, find(search("q=test_repo_13"), "repo:13"))
+
+	// the chosen results come first
+	req := NewRequestWithJSON(t, "POST", "/api/v1/user/quick_actions/recent?token="+token, &api.RecordQuickActionOption{
+		Key: "action:explore",
+	})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	items = search("")
+	if assert.NotEmpty(t, items) {
+		assert.Equal(t, "action:explore", items[0].Key)
+		assert.True(t, items[0].Recent)
+	}
+
+	for _, key := range []string{"javascript:alert(1)", "repo:13", "action:unknown"} {
+		req = NewRequestWithJSON(t, "POST", "/api/v1/user/quick_actions/recent?token="+token, &api.RecordQuickActionOption{
+			Key: key,
+		})
+		session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	}
+
+	// the command palette is only rendered for signed in users
+	req = NewRequest(t, "GET", "/user2/repo1")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), `id="command-palette"`)
+	assert.Contains(t, resp.Body.String(), `data-repo="user2/repo1"`)
+	req = NewRequest(t, "GET", "/user2/repo1")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.NotContains(t, resp.Body.String(), `id="command-palette"`)
+}
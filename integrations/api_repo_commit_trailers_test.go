@@ -0,0 +1,61 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPICommitTrailers(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+		user4 := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+		session := loginUser(t, user2.Name)
+		token := getTokenForLoggedInUser(t, session)
+
+		createFileOptions := getCreateFileOptions()
+		createFileOptions.Message = "Add a file with trailers\n\nSome details.\n\n" +
+			"Co-authored-by: " + user4.Name + " <" + user4.Email + ">\n" +
+			"Co-authored-by: Someone Else <someone@example.com>\n" +
+			"Reviewed-by: Jane Doe <janedoe@example.com>\n"
+		req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/repo1/contents/trailers.txt?token=%s", user2.Name, token), &createFileOptions)
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var fileResponse api.FileResponse
+		DecodeJSON(t, resp, &fileResponse)
+		sha := fileResponse.Commit.SHA
+
+		req = NewRequestf(t, "GET", "/api/v1/repos/%s/repo1/git/commits/%s?token=%s", user2.Name, sha, token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var commit api.Commit
+		DecodeJSON(t, resp, &commit)
+		assert.Len(t, commit.RepoCommit.Trailers, 3)
+		assert.Equal(t, "Reviewed-by", commit.RepoCommit.Trailers[2].Key)
+		assert.Equal(t, "Jane Doe <janedoe@example.com>", commit.RepoCommit.Trailers[2].Value)
+		assert.Equal(t, []*api.Identity{
+			{Name: user4.Name, Email: user4.Email},
+			{Name: "Someone Else", Email: "someone@example.com"},
+		}, commit.RepoCommit.CoAuthors)
+		assert.Equal(t, []*api.Identity{{Name: "Jane Doe", Email: "janedoe@example.com"}}, commit.RepoCommit.Reviewers)
+
+		// the co-author who is a user is linked on the commit page
+		req = NewRequestf(t, "GET", "/%s/repo1/commit/%s", user2.Name, sha)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		users := htmlDoc.doc.Find(".commit-trailer-user")
+		assert.Equal(t, 3, users.Length())
+		href, _ := users.First().Find("a").Attr("href")
+		assert.Equal(t, "/"+user4.Name, href)
+		assert.Equal(t, 0, users.Eq(1).Find("a").Length())
+		assert.Contains(t, users.Eq(2).Text(), "Jane Doe")
+	})
+}
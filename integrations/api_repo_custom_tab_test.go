@@ -0,0 +1,132 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoCustomTabs(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// the tabs are listed in their order
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/tabs")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var tabs []*api.RepoCustomTab
+	DecodeJSON(t, resp, &tabs)
+	if assert.Len(t, tabs, 2) {
+		assert.Equal(t, "CI", tabs[0].Name)
+		assert.Equal(t, "Documentation", tabs[1].Name)
+	}
+
+	// only the administrators of the repository can change the tabs
+	token4 := getTokenForLoggedInUser(t, loginUser(t, "user4"))
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/tabs?token="+token4, &api.CreateRepoCustomTabOption{
+		Name: "Tracker",
+		URL:  "https://tracker.example.com",
+	})
+	MakeRequest(t, req, http.StatusForbidden)
+
+	token := getTokenForLoggedInUser(t, loginUser(t, "user2"))
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/tabs?token="+token, &api.CreateRepoCustomTabOption{
+		Name: "Tracker",
+		URL:  "https://tracker.example.com",
+		Icon: "octicon-bug",
+		Sort: 3,
+	})
+	resp = MakeRequest(t, req, http.StatusCreated)
+	var tab *api.RepoCustomTab
+	DecodeJSON(t, resp, &tab)
+	assert.Equal(t, "Tracker", tab.Name)
+	assert.Equal(t, "octicon-bug", tab.Icon)
+	models.AssertExistsAndLoadBean(t, &models.RepoCustomTab{ID: tab.ID, RepoID: 1, URL: "https://tracker.example.com"})
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/tabs?token="+token, &api.CreateRepoCustomTabOption{
+		Name: "Unknown",
+		URL:  "https://example.com",
+		Icon: "octicon-does-not-exist",
+	})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// the tabs are shown in the header of the repository
+	htmlDoc := NewHTMLParser(t, MakeRequest(t, NewRequest(t, "GET", "/user2/repo1"), http.StatusOK).Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(`.navbar a[href="https://tracker.example.com"]`).Length())
+
+	sort := 0
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user2/repo1/tabs/%d?token=%s", tab.ID, token), &api.EditRepoCustomTabOption{
+		Sort: &sort,
+	})
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &tab)
+	assert.Equal(t, 0, tab.Sort)
+	assert.Equal(t, "Tracker", tab.Name)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/tabs")
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &tabs)
+	if assert.Len(t, tabs, 3) {
+		assert.Equal(t, "Tracker", tabs[0].Name)
+	}
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/repos/user2/repo1/tabs/%d?token=%s", tab.ID, token))
+	MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.RepoCustomTab{ID: tab.ID})
+
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/user2/repo1/tabs/%d", tab.ID))
+	MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestRepoSettingsCustomTabs(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	csrf := GetCSRF(t, session, "/user2/repo1/settings/tabs")
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/settings/tabs", map[string]string{
+		"_csrf": csrf,
+		"name":  "Dashboard",
+		"url":   "https://ci.example.com/dashboard",
+		"icon":  "octicon-graph",
+		"sort":  "5",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	tab := models.AssertExistsAndLoadBean(t, &models.RepoCustomTab{RepoID: 1, Name: "Dashboard"}).(*models.RepoCustomTab)
+	assert.Equal(t, "octicon-graph", tab.Icon)
+	assert.Equal(t, 5, tab.Sort)
+
+	session.MakeRequest(t, NewRequest(t, "GET", fmt.Sprintf("/user2/repo1/settings/tabs/%d", tab.ID)), http.StatusOK)
+	session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1/settings/tabs/9999"), http.StatusNotFound)
+
+	// an URL which is not http or https is refused
+	req = NewRequestWithValues(t, "POST", fmt.Sprintf("/user2/repo1/settings/tabs/%d", tab.ID), map[string]string{
+		"_csrf": csrf,
+		"name":  "Dashboard",
+		"url":   "javascript:alert(1)",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertExistsAndLoadBean(t, &models.RepoCustomTab{ID: tab.ID, URL: "https://ci.example.com/dashboard"})
+
+	req = NewRequestWithValues(t, "POST", fmt.Sprintf("/user2/repo1/settings/tabs/%d", tab.ID), map[string]string{
+		"_csrf": csrf,
+		"name":  "Status",
+		"url":   "https://status.example.com",
+		"icon":  "octicon-pulse",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.RepoCustomTab{ID: tab.ID, Name: "Status", URL: "https://status.example.com", Sort: 0})
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/settings/tabs/delete", map[string]string{
+		"_csrf": csrf,
+		"id":    fmt.Sprint(tab.ID),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.RepoCustomTab{ID: tab.ID})
+}
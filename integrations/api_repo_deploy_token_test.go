@@ -0,0 +1,173 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createDeployToken(t *testing.T, token, repo string, scopes ...string) *api.DeployToken {
+	req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/deploy_tokens?token=%s", repo, token), &api.CreateDeployTokenOption{
+		Name:   "ci",
+		Scopes: scopes,
+	})
+	resp := MakeRequest(t, req, http.StatusCreated)
+	var deployToken *api.DeployToken
+	DecodeJSON(t, resp, &deployToken)
+	return deployToken
+}
+
+func TestAPIRepoDeployTokens(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// only the administrators of the repository can create the tokens
+	token4 := getTokenForLoggedInUser(t, loginUser(t, "user4"))
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/deploy_tokens?token="+token4, &api.CreateDeployTokenOption{
+		Name:   "ci",
+		Scopes: []string{"git:pull"},
+	})
+	MakeRequest(t, req, http.StatusForbidden)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/deploy_tokens?token="+token, &api.CreateDeployTokenOption{
+		Name:   "ci",
+		Scopes: []string{"repo:admin"},
+	})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/deploy_tokens?token="+token, &api.CreateDeployTokenOption{
+		Name:      "ci",
+		Scopes:    []string{"git:pull"},
+		ExpiresIn: 365 * 24 * 3600,
+	})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	deployToken := createDeployToken(t, token, "user2/repo1", "release:upload")
+	assert.True(t, models.IsDeployToken(deployToken.Token))
+	assert.Equal(t, "user2", deployToken.Creator.UserName)
+	assert.EqualValues(t, []string{"release:upload"}, deployToken.Scopes)
+
+	// the secret is not listed
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/deploy_tokens?token="+token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	var deployTokens []*api.DeployToken
+	DecodeJSON(t, resp, &deployTokens)
+	if assert.Len(t, deployTokens, 1) {
+		assert.Equal(t, deployToken.ID, deployTokens[0].ID)
+		assert.Empty(t, deployTokens[0].Token)
+		assert.Nil(t, deployTokens[0].LastUsed)
+	}
+
+	// the tokens are shown with the deploy keys
+	resp = session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1/settings/keys"), http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "release:upload")
+
+	// the deploy tokens can only upload release assets
+	uploadAsset := func(repo string, expectedStatus int) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("attachment", "image.png")
+		assert.NoError(t, err)
+		buff := generateImg()
+		_, err = part.Write(buff.Bytes())
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+		req := NewRequestWithBody(t, "POST", fmt.Sprintf("/api/v1/repos/%s/releases/1/assets", repo), body)
+		req.Header.Add("Content-Type", writer.FormDataContentType())
+		req.Header.Add("Authorization", "token "+deployToken.Token)
+		MakeRequest(t, req, expectedStatus)
+	}
+	uploadAsset("user2/repo1", http.StatusCreated)
+	uploadAsset("user2/repo2", http.StatusNotFound)
+	req = NewRequest(t, "GET", "/api/v1/user?token="+deployToken.Token)
+	MakeRequest(t, req, http.StatusUnauthorized)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/deploy_tokens?token="+deployToken.Token, &api.CreateDeployTokenOption{
+		Name:   "ci",
+		Scopes: []string{"git:push"},
+	})
+	MakeRequest(t, req, http.StatusUnauthorized)
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/repos/user2/repo1/deploy_tokens/%d?token=%s", deployToken.ID, token))
+	MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.DeployToken{ID: deployToken.ID})
+	uploadAsset("user2/repo1", http.StatusUnauthorized)
+}
+
+func TestGitDeployToken(t *testing.T) {
+	onGiteaRun(t, testGitDeployToken)
+}
+
+func testGitDeployToken(t *testing.T, u *url.URL) {
+	token := getTokenForLoggedInUser(t, loginUser(t, "user2"))
+	pullToken := createDeployToken(t, token, "user2/repo16", "git:pull")
+	pushToken := createDeployToken(t, token, "user2/repo16", "git:pull", "git:push")
+
+	// the private repository can be cloned with a token allowing to pull
+	cloneURL, _ := url.Parse(u.String())
+	cloneURL.Path = "user2/repo16.git"
+	cloneURL.User = url.UserPassword("ci", pullToken.Token)
+	dstPath, err := ioutil.TempDir("", "deploy-token")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dstPath)
+	assert.NoError(t, git.Clone(cloneURL.String(), dstPath, git.CloneRepoOptions{}))
+
+	// but not pushed to
+	t.Run("CreateBranch", doGitCreateBranch(dstPath, "deploy-token"))
+	t.Run("PushFail", doGitPushTestRepositoryFail(dstPath, "origin", "deploy-token"))
+
+	cloneURL.User = url.UserPassword("ci", pushToken.Token)
+	t.Run("AddRemote", doGitAddRemote(dstPath, "deploy", cloneURL))
+	t.Run("Push", doGitPushTestRepository(dstPath, "deploy", "deploy-token"))
+
+	// the tokens can not push to other repositories
+	otherURL, _ := url.Parse(u.String())
+	otherURL.Path = "user2/repo1.git"
+	otherURL.User = url.UserPassword("ci", pushToken.Token)
+	t.Run("AddOtherRemote", doGitAddRemote(dstPath, "other", otherURL))
+	t.Run("PushOtherFail", doGitPushTestRepositoryFail(dstPath, "other", "deploy-token"))
+}
+
+func TestLFSDeployToken(t *testing.T) {
+	defer prepareTestEnv(t)()
+	setting.CheckLFSVersion()
+	if !setting.LFS.StartServer {
+		t.Skip()
+		return
+	}
+
+	repo, err := models.GetRepositoryByOwnerAndName("user2", "repo16")
+	assert.NoError(t, err)
+	content := []byte("A deployed LFS object")
+	oid := storeObjectInRepo(t, repo.ID, &content)
+	defer repo.RemoveLFSMetaObjectByOid(oid)
+
+	token := getTokenForLoggedInUser(t, loginUser(t, "user2"))
+	gitToken := createDeployToken(t, token, "user2/repo16", "git:pull")
+	lfsToken := createDeployToken(t, token, "user2/repo16", "lfs:pull")
+
+	// the objects of the private repository can only be downloaded with the lfs:pull scope
+	req := NewRequest(t, "GET", "/user2/repo16.git/info/lfs/objects/"+oid+"/test")
+	req.SetBasicAuth("ci", gitToken.Token)
+	MakeRequest(t, req, http.StatusUnauthorized)
+
+	req = NewRequest(t, "GET", "/user2/repo16.git/info/lfs/objects/"+oid+"/test")
+	req.SetBasicAuth("ci", lfsToken.Token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, content, resp.Body.Bytes())
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoDevEnvironment(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		defer func(enabled bool, integrations []*setting.IDEIntegration) {
+			setting.IDE.Enabled = enabled
+			setting.IDE.Integrations = integrations
+		}(setting.IDE.Enabled, setting.IDE.Integrations)
+		setting.IDE.Enabled = true
+		setting.IDE.Integrations = []*setting.IDEIntegration{
+			{Name: "gitpod", DisplayName: "Gitpod", URL: "https://gitpod.io/#{web_url}"},
+			{Name: "vscode", DisplayName: "VS Code", URL: "vscode://ms-vscode-remote.remote-containers/cloneInVolume?url={clone_url_escaped}", RequireDevContainer: true},
+		}
+
+		session := loginUser(t, "user2")
+
+		req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/dev_environment")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var env api.DevEnvironment
+		DecodeJSON(t, resp, &env)
+		assert.EqualValues(t, "master", env.Ref)
+		assert.Nil(t, env.DevContainer)
+		if assert.Len(t, env.Launchers, 1) {
+			assert.EqualValues(t, "https://gitpod.io/#"+setting.AppURL+"user2/repo1/src/branch/master", env.Launchers[0].URL)
+		}
+
+		req = NewRequest(t, "GET", "/user2/repo1")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.Contains(t, resp.Body.String(), "Open in Gitpod")
+		assert.NotContains(t, resp.Body.String(), "Open in VS Code")
+
+		// add a dev container configuration
+		req = NewRequest(t, "GET", "/user2/repo1/_new/master/")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		doc := NewHTMLParser(t, resp.Body)
+		req = NewRequestWithValues(t, "POST", "/user2/repo1/_new/master/", map[string]string{
+			"_csrf":         doc.GetCSRF(),
+			"last_commit":   doc.GetInputValueByName("last_commit"),
+			"tree_path":     ".devcontainer/devcontainer.json",
+			"content":       "{\n  // development image\n  \"name\": \"Repo1\",\n  \"image\": \"golang:1.15\",\n  \"forwardPorts\": [3000],\n}\n",
+			"commit_choice": "direct",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/dev_environment?ref=master")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		env = api.DevEnvironment{}
+		DecodeJSON(t, resp, &env)
+		if assert.NotNil(t, env.DevContainer) {
+			assert.EqualValues(t, ".devcontainer/devcontainer.json", env.DevContainer.Path)
+			assert.EqualValues(t, "Repo1", env.DevContainer.Name)
+			assert.EqualValues(t, "golang:1.15", env.DevContainer.Image)
+			assert.EqualValues(t, []string{"3000"}, env.DevContainer.ForwardPorts)
+		}
+		assert.Len(t, env.Launchers, 2)
+
+		req = NewRequest(t, "GET", "/user2/repo1")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.Contains(t, resp.Body.String(), "vscode://ms-vscode-remote.remote-containers/cloneInVolume?url=")
+
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/dev_environment?ref=unknown")
+		session.MakeRequest(t, req, http.StatusNotFound)
+	})
+}
@@ -48,3 +48,31 @@ func TestRepoLanguages(t *testing.T) {
 		assert.InDeltaMapValues(t, map[string]int64{"Go": 12}, languages, 0)
 	})
 }
+
+func TestAPIRepoLanguagesByRef(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+
+		req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/languages?ref=master")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var languages map[string]int64
+		DecodeJSON(t, resp, &languages)
+		assert.Empty(t, languages)
+
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/languages?ref=does-not-exist")
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/languages/directories")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var dirs map[string]map[string]int64
+		DecodeJSON(t, resp, &dirs)
+		assert.Empty(t, dirs)
+
+		req = NewRequest(t, "POST", "/api/v1/repos/user2/repo1/languages/recalculate")
+		session.MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "POST", "/api/v1/repos/user2/repo1/languages/recalculate?token="+token)
+		session.MakeRequest(t, req, http.StatusAccepted)
+	})
+}
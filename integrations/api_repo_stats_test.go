@@ -0,0 +1,102 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoStats(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+
+		// Request editor page
+		req := NewRequest(t, "GET", "/user2/repo1/_new/master/")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+
+		doc := NewHTMLParser(t, resp.Body)
+		lastCommit := doc.GetInputValueByName("last_commit")
+		assert.NotEmpty(t, lastCommit)
+
+		// Save new file to master branch
+		req = NewRequestWithValues(t, "POST", "/user2/repo1/_new/master/", map[string]string{
+			"_csrf":         doc.GetCSRF(),
+			"last_commit":   lastCommit,
+			"tree_path":     "stats.txt",
+			"content":       "one\ntwo\n",
+			"commit_choice": "direct",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+
+		// The insights are computed in the background
+		var contributors []*api.ContributorStats
+		for i := 0; i < 10; i++ {
+			req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stats/contributors")
+			resp = session.MakeRequest(t, req, NoExpectedStatus)
+			if resp.Code == http.StatusOK {
+				DecodeJSON(t, resp, &contributors)
+				if len(contributors) > 0 && contributors[0].Weeks[len(contributors[0].Weeks)-1].Commits > 0 {
+					break
+				}
+			} else {
+				assert.EqualValues(t, http.StatusAccepted, resp.Code)
+			}
+			time.Sleep(time.Second)
+		}
+
+		var user2 *api.ContributorStats
+		for _, contributor := range contributors {
+			if contributor.Author.UserName == "user2" {
+				user2 = contributor
+			}
+		}
+		if assert.NotNil(t, user2) {
+			lastWeek := user2.Weeks[len(user2.Weeks)-1]
+			assert.EqualValues(t, 1, lastWeek.Commits)
+			assert.EqualValues(t, 2, lastWeek.Additions)
+		}
+
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stats/commit_activity")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var activity []*api.CommitActivity
+		DecodeJSON(t, resp, &activity)
+		assert.Len(t, activity, 52)
+		assert.EqualValues(t, 1, activity[51].Total)
+
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stats/code_frequency")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var frequency [][]int64
+		DecodeJSON(t, resp, &frequency)
+		assert.NotEmpty(t, frequency)
+		assert.Len(t, frequency[0], 3)
+
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stats/participation")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var participation api.ParticipationStats
+		DecodeJSON(t, resp, &participation)
+		assert.Len(t, participation.All, 52)
+		assert.EqualValues(t, 1, participation.Owner[51])
+
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stats/punch_card")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var punchCard [][]int64
+		DecodeJSON(t, resp, &punchCard)
+		assert.Len(t, punchCard, 7*24)
+
+		req = NewRequest(t, "GET", "/user2/repo1/insights")
+		session.MakeRequest(t, req, http.StatusOK)
+
+		// Empty repositories have no statistics
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo15/stats/contributors")
+		session.MakeRequest(t, req, http.StatusNoContent)
+	})
+}
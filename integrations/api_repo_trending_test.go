@@ -0,0 +1,58 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoTrending(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	testNewIssue(t, session, "user2", "repo1", "Trending issue", "description")
+	assert.NoError(t, models.UpdateRepoTrending(context.Background()))
+
+	req := NewRequest(t, "GET", "/api/v1/repos/trending?period=weekly")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var trendings []*api.TrendingRepository
+	DecodeJSON(t, resp, &trendings)
+	if assert.Len(t, trendings, 1) {
+		assert.EqualValues(t, "repo1", trendings[0].Repository.Name)
+		assert.EqualValues(t, 1, trendings[0].Activity)
+		assert.EqualValues(t, 1, trendings[0].Score)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/repos/trending?topic=graphql")
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &trendings)
+	assert.Empty(t, trendings)
+
+	req = NewRequest(t, "GET", "/api/v1/topics/trending")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var topics []*api.TrendingTopic
+	DecodeJSON(t, resp, &topics)
+	names := make([]string, len(topics))
+	for i, topic := range topics {
+		names[i] = topic.Name
+	}
+	assert.Contains(t, names, "golang")
+
+	req = NewRequest(t, "GET", "/api/v1/repos/trending?period=yearly")
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequest(t, "GET", "/explore/trending?period=monthly")
+	resp = MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.Equal(t, 1, htmlDoc.doc.Find(".repository.list > .item").Length())
+	assert.Contains(t, htmlDoc.doc.Find(".ui.tags").Text(), "golang")
+}
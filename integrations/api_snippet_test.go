@@ -0,0 +1,69 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPISnippets(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	token := getTokenForLoggedInUser(t, loginUser(t, "user2"))
+	otherToken := getTokenForLoggedInUser(t, loginUser(t, "user4"))
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/snippets?token="+token, &api.CreateSnippetOption{
+		Title:      "Hello",
+		Visibility: "private",
+		Files:      []*api.SnippetFileOption{{Name: "hello.go", Content: "package main\n"}},
+	})
+	resp := MakeRequest(t, req, http.StatusCreated)
+	var snippet api.Snippet
+	DecodeJSON(t, resp, &snippet)
+	assert.Equal(t, "private", snippet.Visibility)
+	if assert.Len(t, snippet.Files, 1) {
+		assert.Equal(t, "package main\n", snippet.Files[0].Content)
+	}
+	link := fmt.Sprintf("/api/v1/snippets/%d", snippet.ID)
+
+	req = NewRequest(t, "GET", link+"?token="+otherToken)
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestWithJSON(t, "PATCH", link+"?token="+otherToken, &api.EditSnippetOption{Visibility: "public"})
+	MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestWithJSON(t, "PATCH", link+"?token="+token, &api.EditSnippetOption{
+		Visibility: "public",
+		Files:      []*api.SnippetFileOption{{Name: "hello.go", Content: "package hello\n"}},
+	})
+	MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequest(t, "GET", link+"/revisions")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var revisions []*api.SnippetRevision
+	DecodeJSON(t, resp, &revisions)
+	if assert.Len(t, revisions, 2) {
+		req = NewRequest(t, "GET", link+"?revision="+revisions[1].SHA)
+		resp = MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &snippet)
+		assert.Equal(t, "package main\n", snippet.Files[0].Content)
+	}
+
+	req = NewRequestWithJSON(t, "POST", link+"/comments?token="+otherToken, &api.CreateSnippetCommentOption{Body: "Nice"})
+	resp = MakeRequest(t, req, http.StatusCreated)
+	var comment api.SnippetComment
+	DecodeJSON(t, resp, &comment)
+	assert.Equal(t, "user4", comment.Poster.UserName)
+
+	req = NewRequest(t, "DELETE", link+"?token="+token)
+	MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "GET", link)
+	MakeRequest(t, req, http.StatusNotFound)
+}
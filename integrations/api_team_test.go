@@ -100,6 +100,25 @@ func TestAPITeam(t *testing.T) {
 	checkTeamBean(t, apiTeam.ID, teamToEdit.Name, *teamToEditDesc.Description, *teamToEdit.IncludesAllRepositories,
 		teamToEdit.Permission, teamToEdit.Units)
 
+	// Edit the review assignment only
+	roundRobin := "round_robin"
+	reviewAssignCount := 2
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/teams/%d?token=%s", teamID, token), &api.EditTeamOption{
+		ReviewAssignAlgorithm: &roundRobin,
+		ReviewAssignCount:     &reviewAssignCount,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiTeam)
+	assert.EqualValues(t, "round_robin", apiTeam.ReviewAssignAlgorithm)
+	assert.EqualValues(t, 2, apiTeam.ReviewAssignCount)
+	models.AssertExistsAndLoadBean(t, &models.Team{ID: teamID, ReviewAssignAlgorithm: models.ReviewAssignRoundRobin, ReviewAssignCount: 2})
+
+	unknown := "random"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/teams/%d?token=%s", teamID, token), &api.EditTeamOption{
+		ReviewAssignAlgorithm: &unknown,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
 	// Read team.
 	teamRead := models.AssertExistsAndLoadBean(t, &models.Team{ID: teamID}).(*models.Team)
 	req = NewRequestf(t, "GET", "/api/v1/teams/%d?token="+token, teamID)
@@ -158,3 +177,47 @@ func TestAPITeamSearch(t *testing.T) {
 	resp = session.MakeRequest(t, req, http.StatusForbidden)
 
 }
+
+func TestAPITeamHierarchy(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// user2 is the owner of the organization user3
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/teams?token="+token, &api.CreateTeamOption{
+		Name:       "child",
+		Permission: "read",
+		Units:      []string{"repo.code"},
+		ParentID:   2,
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var child api.Team
+	DecodeJSON(t, resp, &child)
+	assert.EqualValues(t, 2, child.ParentID)
+
+	req = NewRequestf(t, "GET", "/api/v1/teams/%d/teams?token=%s", 2, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var children []*api.Team
+	DecodeJSON(t, resp, &children)
+	if assert.Len(t, children, 1) {
+		assert.EqualValues(t, child.ID, children[0].ID)
+	}
+
+	// a team cannot be a child of its child team
+	parentID := child.ID
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/teams/%d?token=%s", 2, token), &api.EditTeamOption{
+		ParentID: &parentID,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	models.AssertExistsAndLoadBean(t, &models.Team{ID: 2, ParentID: 0})
+
+	parentID = 0
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/teams/%d?token=%s", child.ID, token), &api.EditTeamOption{
+		ParentID: &parentID,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &child)
+	assert.EqualValues(t, 0, child.ParentID)
+	models.AssertExistsAndLoadBean(t, &models.Team{ID: child.ID, ParentID: 0})
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"archive/zip"
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func uploadThemeBundle(t *testing.T, session *TestSession, token string, files map[string]string, expectedStatus int) *api.Theme {
+	var bundle bytes.Buffer
+	zw := zip.NewWriter(&bundle)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("bundle", "theme.zip")
+	assert.NoError(t, err)
+	_, err = part.Write(bundle.Bytes())
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	req := NewRequestWithBody(t, "POST", "/api/v1/admin/themes?token="+token, body)
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	resp := session.MakeRequest(t, req, expectedStatus)
+	if expectedStatus != http.StatusCreated {
+		return nil
+	}
+	var theme api.Theme
+	DecodeJSON(t, resp, &theme)
+	return &theme
+}
+
+func TestAPIThemes(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// user1 is an admin user
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	theme := uploadThemeBundle(t, session, token, map[string]string{
+		models.ThemeBundleManifest: "NAME = ocean\nDISPLAY_NAME = Ocean",
+		models.ThemeBundleCSS:      "body { color: blue; }",
+		models.ThemeBundleFooter:   `<p class="ocean-footer">{{.Title}}</p>`,
+	}, http.StatusCreated)
+	assert.Equal(t, "ocean", theme.Name)
+	assert.Equal(t, 1, theme.Version)
+
+	// scripts are not allowed in bundles
+	uploadThemeBundle(t, session, token, map[string]string{
+		models.ThemeBundleManifest: "NAME = ocean",
+		models.ThemeBundleCSS:      "body {}",
+		"theme.js":                 "alert(1)",
+	}, http.StatusUnprocessableEntity)
+
+	req := NewRequest(t, "GET", "/api/v1/themes")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var themes []*api.Theme
+	DecodeJSON(t, resp, &themes)
+	assert.True(t, themes[0].Builtin)
+	assert.Equal(t, "ocean", themes[len(themes)-1].Name)
+
+	resp = session.MakeRequest(t, NewRequest(t, "GET", "/admin/themes"), http.StatusOK)
+	assert.Contains(t, resp.Body.String(), `href="/admin/themes/ocean"`)
+	session.MakeRequest(t, NewRequest(t, "GET", "/admin/themes/ocean"), http.StatusOK)
+
+	req = NewRequest(t, "GET", "/themes/ocean/1/theme.css")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "body { color: blue; }", resp.Body.String())
+	assert.Contains(t, resp.Header().Get("Content-Type"), "text/css")
+	MakeRequest(t, NewRequest(t, "GET", "/themes/ocean/2/theme.css"), http.StatusNotFound)
+
+	// user2 selects the uploaded theme
+	session2 := loginUser(t, "user2")
+	req = NewRequestWithValues(t, "POST", "/user/settings/account/theme", map[string]string{
+		"_csrf": GetCSRF(t, session2, "/user/settings/account"),
+		"theme": "ocean",
+	})
+	session2.MakeRequest(t, req, http.StatusFound)
+	resp = session2.MakeRequest(t, NewRequest(t, "GET", "/"), http.StatusOK)
+	assert.Contains(t, resp.Body.String(), `href="/themes/ocean/1/theme.css"`)
+	assert.Contains(t, resp.Body.String(), `<p class="ocean-footer">`)
+
+	req = NewRequest(t, "DELETE", "/api/v1/admin/themes/ocean?token="+token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.Theme{Name: "ocean"})
+	user := models.AssertExistsAndLoadBean(t, &models.User{Name: "user2"}).(*models.User)
+	assert.NotEqual(t, "ocean", user.Theme)
+
+	req = NewRequest(t, "DELETE", "/api/v1/admin/themes/ocean?token="+token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITopicCuration(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	adminToken := getTokenForLoggedInUser(t, loginUser(t, "user1"))
+	userToken := getTokenForLoggedInUser(t, loginUser(t, "user2"))
+
+	req := NewRequest(t, "GET", "/api/v1/topics/golang")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var topic api.TopicResponse
+	DecodeJSON(t, resp, &topic)
+	assert.EqualValues(t, 1, topic.ID)
+	assert.False(t, topic.Featured)
+
+	req = NewRequest(t, "GET", "/api/v1/topics/unknown")
+	MakeRequest(t, req, http.StatusNotFound)
+
+	description := "The Go programming language"
+	featured := true
+	option := &api.EditTopicOption{Description: &description, Featured: &featured}
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/topics/golang?token="+userToken, option)
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/topics/golang?token="+adminToken, option)
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &topic)
+	assert.Equal(t, description, topic.Description)
+	assert.True(t, topic.Featured)
+
+	req = NewRequest(t, "GET", "/api/v1/topics/featured")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var topics []*api.TopicResponse
+	DecodeJSON(t, resp, &topics)
+	if assert.Len(t, topics, 1) {
+		assert.Equal(t, "golang", topics[0].Name)
+	}
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/topics/graphql/merge?token="+adminToken, &api.MergeTopicOption{Target: "graphql"})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/topics/graphql/merge?token="+adminToken, &api.MergeTopicOption{Target: "golang"})
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/api/v1/topics/graphql")
+	MakeRequest(t, req, http.StatusNotFound)
+	models.AssertNotExistsBean(t, &models.RepoTopic{TopicID: 4})
+}
+
+func TestAPIOrgTopics(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// repository 32 is a public repository of organization user3 which is owned by user2
+	assert.NoError(t, models.SaveTopics(32, "golang", "go"))
+	ownerToken := getTokenForLoggedInUser(t, loginUser(t, "user2"))
+	memberToken := getTokenForLoggedInUser(t, loginUser(t, "user4"))
+
+	req := NewRequest(t, "GET", "/api/v1/orgs/user3/topics")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var topics []*api.OrgTopic
+	DecodeJSON(t, resp, &topics)
+	assert.Len(t, topics, 2)
+
+	logo := "https://example.com/go.png"
+	featured := true
+	option := &api.EditTopicOption{Logo: &logo, Featured: &featured}
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/orgs/user3/topics/go?token="+memberToken, option)
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/orgs/user3/topics/go?token="+ownerToken, option)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var topic api.OrgTopic
+	DecodeJSON(t, resp, &topic)
+	assert.Equal(t, "go", topic.Topic.Name)
+	assert.Equal(t, logo, topic.Logo)
+	assert.True(t, topic.Featured)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/topics/go/merge?token="+ownerToken, &api.MergeTopicOption{Target: "golang"})
+	MakeRequest(t, req, http.StatusNoContent)
+
+	req = NewRequest(t, "GET", "/api/v1/orgs/user3/topics")
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &topics)
+	if assert.Len(t, topics, 1) {
+		assert.Equal(t, "golang", topics[0].Topic.Name)
+		assert.False(t, topics[0].Featured)
+	}
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 32}).(*models.Repository)
+	assert.Equal(t, []string{"golang"}, repo.Topics)
+}
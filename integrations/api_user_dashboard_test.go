@@ -0,0 +1,80 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIUserDashboard(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequest(t, "GET", "/api/v1/user/dashboard?token="+token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var layout api.DashboardLayout
+	DecodeJSON(t, resp, &layout)
+	assert.Equal(t, models.DefaultDashboardWidgets, layout.Widgets)
+	assert.Equal(t, models.DashboardWidgets, layout.Available)
+
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/user/dashboard?token="+token, &api.EditDashboardLayoutOption{
+		Widgets: []string{models.DashboardWidgetWatchedReleases, models.DashboardWidgetAssignedIssues},
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &layout)
+	assert.Equal(t, []string{models.DashboardWidgetWatchedReleases, models.DashboardWidgetAssignedIssues}, layout.Widgets)
+
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/user/dashboard?token="+token, &api.EditDashboardLayoutOption{
+		Widgets: []string{"unknown"},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	widget := func(name string) *api.DashboardWidget {
+		req := NewRequest(t, "GET", "/api/v1/user/dashboard/widgets/"+name+"?token="+token)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var widget api.DashboardWidget
+		DecodeJSON(t, resp, &widget)
+		return &widget
+	}
+
+	// issue 1 of repo1 and issue 1 of repo3 are assigned to user1
+	assigned := widget(models.DashboardWidgetAssignedIssues)
+	urls := make([]string, 0, len(assigned.Entries))
+	for _, entry := range assigned.Entries {
+		urls = append(urls, entry.URL)
+	}
+	assert.Contains(t, urls, "/user2/repo1/issues/1")
+
+	// user1 watches repo1, which has the release v1.1
+	releases := widget(models.DashboardWidgetWatchedReleases)
+	if assert.NotEmpty(t, releases.Entries) {
+		assert.Equal(t, "/user2/repo1/releases/tag/v1.1", releases.Entries[0].URL)
+	}
+
+	widget(models.DashboardWidgetReviewRequests)
+	widget(models.DashboardWidgetCIFailures)
+	widget(models.DashboardWidgetOrgActivity)
+
+	req = NewRequest(t, "GET", "/api/v1/user/dashboard/widgets/unknown?token="+token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// the dashboard shows the widgets in their order and offers the others
+	req = NewRequest(t, "GET", "/")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	doc := NewHTMLParser(t, resp.Body)
+	widgets := doc.doc.Find("#dashboard-widgets .dashboard-widget")
+	if assert.Equal(t, 2, widgets.Length()) {
+		assert.Equal(t, models.DashboardWidgetWatchedReleases, widgets.First().AttrOr("data-widget", ""))
+	}
+	assert.Equal(t, 3, doc.doc.Find("#dashboard-widgets-add-select option").Length())
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIPinnedRepos(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repoNames := func(resp *httptest.ResponseRecorder) []string {
+		var repos []*api.Repository
+		DecodeJSON(t, resp, &repos)
+		names := make([]string, len(repos))
+		for i, repo := range repos {
+			names[i] = repo.Name
+		}
+		return names
+	}
+
+	// the private repo2 is only listed for users with access
+	req := NewRequest(t, "GET", "/api/v1/users/user2/pinned_repos")
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, []string{"repo1"}, repoNames(resp))
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req = NewRequest(t, "GET", "/api/v1/user/pinned_repos?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, []string{"repo2", "repo1"}, repoNames(resp))
+
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/user/pinned_repos?token="+token, &api.EditPinnedReposOption{
+		RepoIDs: []int64{1, 16},
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, []string{"repo1", "repo16"}, repoNames(resp))
+
+	// repo3 is owned by user3
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/user/pinned_repos?token="+token, &api.EditPinnedReposOption{
+		RepoIDs: []int64{1, 3},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/user/pinned_repos?token="+token, &api.EditPinnedReposOption{
+		RepoIDs: []int64{1, 2, 15, 16, 31, 32, 33},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// only owners can change the repositories pinned by an organization
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/orgs/user3/pinned_repos?token="+token, &api.EditPinnedReposOption{
+		RepoIDs: []int64{3},
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	session = loginUser(t, "user2")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/orgs/user3/pinned_repos?token="+token, &api.EditPinnedReposOption{
+		RepoIDs: []int64{3},
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, []string{"repo3"}, repoNames(resp))
+}
+
+func TestProfileReadmeAndPinnedRepos(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestWithJSON(t, "POST", "/api/v1/user/repos?token="+token, &api.CreateRepoOption{
+		Name:        ".profile",
+		Description: "Hello from user2",
+		Readme:      "Default",
+		AutoInit:    true,
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequest(t, "GET", "/user2")
+	resp := MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.Contains(t, htmlDoc.doc.Find(".profile-readme").Text(), "Hello from user2")
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(".pinned-repos .column").Length())
+	assert.EqualValues(t, "repo1", htmlDoc.doc.Find(".pinned-repos .column a.name").Text())
+
+	req = NewRequest(t, "GET", "/user2")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 2, htmlDoc.doc.Find(".pinned-repos .column").Length())
+
+	// unpin the private repository from the settings
+	req = NewRequestWithValues(t, "POST", "/user/settings/repos/pinned", map[string]string{
+		"_csrf":   GetCSRF(t, session, "/user/settings/repos"),
+		"action":  "unpin",
+		"repo_id": "2",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+
+	req = NewRequestWithValues(t, "POST", "/user/settings/repos/pinned", map[string]string{
+		"_csrf":     GetCSRF(t, session, "/user/settings/repos"),
+		"action":    "pin",
+		"repo_name": "repo16",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/user/pinned_repos?token=%s", token))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var repos []*api.Repository
+	DecodeJSON(t, resp, &repos)
+	if assert.Len(t, repos, 2) {
+		assert.EqualValues(t, 1, repos[0].ID)
+		assert.EqualValues(t, 16, repos[1].ID)
+	}
+}
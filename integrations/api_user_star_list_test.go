@@ -0,0 +1,106 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIStarLists(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/user/star_lists?token="+token, &api.CreateStarListOption{
+		Name:        "Tools",
+		Description: "useful tools",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var tools api.StarList
+	DecodeJSON(t, resp, &tools)
+	assert.EqualValues(t, "Tools", tools.Name)
+	assert.False(t, tools.Private)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/star_lists?token="+token, &api.CreateStarListOption{
+		Name: "tools",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/star_lists?token="+token, &api.CreateStarListOption{
+		Name:    "Secret",
+		Private: true,
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var secret api.StarList
+	DecodeJSON(t, resp, &secret)
+
+	// adding a repository stars it
+	req = NewRequestf(t, "PUT", "/api/v1/user/star_lists/%d/repos/user2/repo1?token=%s", tools.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequestf(t, "PUT", "/api/v1/user/star_lists/%d/repos/user2/repo1?token=%s", secret.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertExistsAndLoadBean(t, &models.Star{UID: 2, RepoID: 1})
+
+	req = NewRequestf(t, "GET", "/api/v1/user/star_lists/%d/repos?token=%s", tools.ID, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var repos []*api.Repository
+	DecodeJSON(t, resp, &repos)
+	if assert.Len(t, repos, 1) {
+		assert.EqualValues(t, "repo1", repos[0].Name)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/user/star_lists?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var lists []*api.StarList
+	DecodeJSON(t, resp, &lists)
+	if assert.Len(t, lists, 2) {
+		assert.EqualValues(t, "Secret", lists[0].Name)
+		assert.EqualValues(t, 1, lists[0].NumRepos)
+	}
+
+	// the private lists are only visible to their owner
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequest(t, "GET", "/api/v1/users/user2/star_lists?token="+token4)
+	resp = session4.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &lists)
+	if assert.Len(t, lists, 1) {
+		assert.EqualValues(t, "Tools", lists[0].Name)
+	}
+	req = NewRequestf(t, "GET", "/api/v1/users/user2/star_lists/%d/repos?token=%s", tools.ID, token4)
+	session4.MakeRequest(t, req, http.StatusOK)
+	req = NewRequestf(t, "GET", "/api/v1/users/user2/star_lists/%d/repos?token=%s", secret.ID, token4)
+	session4.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestf(t, "PUT", "/api/v1/user/star_lists/%d/repos/user2/repo1?token=%s", tools.ID, token4)
+	session4.MakeRequest(t, req, http.StatusNotFound)
+
+	private := true
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/user/star_lists/%d?token=%s", tools.ID, token), &api.EditStarListOption{
+		Private: &private,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &tools)
+	assert.True(t, tools.Private)
+	assert.EqualValues(t, "Tools", tools.Name)
+
+	// removing a repository keeps it starred
+	req = NewRequestf(t, "DELETE", "/api/v1/user/star_lists/%d/repos/user2/repo1?token=%s", tools.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.StarListRepo{StarListID: tools.ID, RepoID: 1})
+	models.AssertExistsAndLoadBean(t, &models.Star{UID: 2, RepoID: 1})
+
+	req = NewRequestf(t, "DELETE", "/api/v1/user/star_lists/%d?token=%s", secret.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.StarList{ID: secret.ID})
+	models.AssertExistsAndLoadBean(t, &models.Star{UID: 2, RepoID: 1})
+}
@@ -10,6 +10,7 @@ import (
 	"image/png"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"mime/multipart"
 	"net/http"
 	"os"
@@ -135,3 +136,93 @@ func TestGetAttachment(t *testing.T) {
 		})
 	}
 }
+
+func TestAttachmentThumbnail(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	var buff bytes.Buffer
+	assert.NoError(t, png.Encode(&buff, image.NewRGBA(image.Rect(0, 0, 600, 400))))
+	uuid := createAttachment(t, session, "user2/repo1", "image.png", buff, http.StatusOK)
+
+	resp := session.MakeRequest(t, NewRequest(t, "GET", "/attachments/"+uuid+"/thumbnail"), http.StatusOK)
+	config, format, err := image.DecodeConfig(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, 300, config.Width)
+	assert.Equal(t, 200, config.Height)
+	attach := models.AssertExistsAndLoadBean(t, &models.Attachment{UUID: uuid}).(*models.Attachment)
+	assert.FileExists(t, attach.ThumbnailPath())
+
+	// only the uploader can read an attachment which is not linked
+	loginUser(t, "user8").MakeRequest(t, NewRequest(t, "GET", "/attachments/"+uuid+"/thumbnail"), http.StatusNotFound)
+}
+
+func TestAttachmentVideoRange(t *testing.T) {
+	defer prepareTestEnv(t)()
+	const uuid = "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"
+	// the start of an MP4 file
+	video := append([]byte{0, 0, 0, 0x18}, "ftypmp42\x00\x00\x00\x00mp42isom"...)
+	video = append(video, bytes.Repeat([]byte{1}, 100)...)
+	localPath := models.AttachmentLocalPath(uuid)
+	assert.NoError(t, os.MkdirAll(path.Dir(localPath), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(localPath, video, 0644))
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/attachments/"+uuid)
+	req.Header.Set("Range", "bytes=0-9")
+	resp := session.MakeRequest(t, req, http.StatusPartialContent)
+	assert.Equal(t, "video/mp4", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "bytes 0-9/124", resp.Header().Get("Content-Range"))
+	assert.Equal(t, video[:10], resp.Body.Bytes())
+	count := models.AssertExistsAndLoadBean(t, &models.Attachment{UUID: uuid}).(*models.Attachment).DownloadCount
+
+	// the next ranges of the video are not counted as downloads
+	req = NewRequest(t, "GET", "/attachments/"+uuid)
+	req.Header.Set("Range", "bytes=100-")
+	resp = session.MakeRequest(t, req, http.StatusPartialContent)
+	assert.Equal(t, video[100:], resp.Body.Bytes())
+	models.AssertExistsAndLoadBean(t, &models.Attachment{UUID: uuid, DownloadCount: count})
+}
+
+func createIssueAttachment(t *testing.T, session *TestSession, repoURL, filename string, buff bytes.Buffer, expectedStatus int) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", filename)
+	assert.NoError(t, err)
+	_, err = io.Copy(part, &buff)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	req := NewRequestWithBody(t, "POST", repoURL+"/issues/attachments", body)
+	req.Header.Add("X-Csrf-Token", GetCSRF(t, session, repoURL))
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	session.MakeRequest(t, req, expectedStatus)
+}
+
+func TestOrgAttachmentPolicy(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	req := NewRequestWithValues(t, "POST", "/org/user3/settings/attachments", map[string]string{
+		"_csrf":                  GetCSRF(t, session, "/org/user3/settings/attachments"),
+		"max_size":               "0",
+		"max_size_by_type":       "image/png:1",
+		"max_issue_size":         "0",
+		"closed_issue_retention": "30",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.AttachmentPolicy{OwnerID: 3, MaxSizeByType: "image/png:1", ClosedIssueRetention: 30})
+
+	// a noisy image does not compress below 1 MB
+	noise := image.NewRGBA(image.Rect(0, 0, 600, 600))
+	rand.New(rand.NewSource(1)).Read(noise.Pix)
+	var large bytes.Buffer
+	assert.NoError(t, png.Encode(&large, noise))
+	assert.Greater(t, large.Len(), 1024*1024)
+
+	createIssueAttachment(t, session, "/user3/repo3", "large.png", large, http.StatusRequestEntityTooLarge)
+	createIssueAttachment(t, session, "/user3/repo3", "small.png", generateImg(), http.StatusOK)
+	// the repositories of other owners keep the limits of the instance
+	createIssueAttachment(t, session, "/user2/repo1", "large.png", large, http.StatusOK)
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackportMergedPullRequest(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "backport-me", "README.md", "Hello, World (Backported)\n")
+		req := NewRequest(t, "GET", "/user2/repo1/compare/master...backport-me")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		req = NewRequestWithValues(t, "POST", "/user2/repo1/compare/master...backport-me", map[string]string{
+			"_csrf": htmlDoc.GetCSRF(),
+			"title": "This is a fix to backport",
+		})
+		resp = session.MakeRequest(t, req, http.StatusFound)
+
+		elem := strings.Split(test.RedirectURL(resp), "/")
+		assert.EqualValues(t, "pulls", elem[3])
+		testPullMerge(t, session, elem[1], elem[2], elem[4], models.MergeStyleSquash)
+
+		label := &models.Label{RepoID: 1, Name: "backport/develop", Color: "#ffffff"}
+		assert.NoError(t, models.NewLabel(label))
+		token := getTokenForLoggedInUser(t, session)
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/%s/labels?token=%s", elem[4], token), &api.IssueLabelsOption{
+			Labels: []int64{label.ID},
+		})
+		session.MakeRequest(t, req, http.StatusOK)
+
+		// the backport is run in the background
+		backport := &models.PullRequest{BaseRepoID: 1, HeadBranch: "backport-" + elem[4] + "-develop", BaseBranch: "develop"}
+		for i := 0; i < 50 && models.GetCount(t, backport) == 0; i++ {
+			time.Sleep(100 * time.Millisecond)
+		}
+		pr := models.AssertExistsAndLoadBean(t, backport).(*models.PullRequest)
+		issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: pr.IssueID}).(*models.Issue)
+		assert.EqualValues(t, "This is a fix to backport (#"+elem[4]+")", issue.Title)
+		assert.EqualValues(t, "Backport #"+elem[4], issue.Content)
+
+		// the author of the original pull request is assigned once it is opened
+		assignee := &models.IssueAssignees{IssueID: issue.ID, AssigneeID: 2}
+		for i := 0; i < 50 && models.GetCount(t, assignee) == 0; i++ {
+			time.Sleep(100 * time.Millisecond)
+		}
+		models.AssertExistsAndLoadBean(t, assignee)
+	})
+}
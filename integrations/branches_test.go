@@ -7,8 +7,11 @@ package integrations
 import (
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
+	"code.gitea.io/gitea/modules/test"
+
 	"github.com/PuerkitoBio/goquery"
 	"github.com/stretchr/testify/assert"
 	"github.com/unknwon/i18n"
@@ -42,6 +45,39 @@ func TestUndoDeleteBranch(t *testing.T) {
 	})
 }
 
+func TestBulkDeleteStaleBranches(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		req := NewRequest(t, "GET", "/user2/repo1/branches?state=stale")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.doc.Find("input[name=branch][value=develop]").Length())
+		assert.EqualValues(t, 0, htmlDoc.doc.Find("input[name=branch][value=master]").Length())
+
+		req = NewRequestWithBody(t, "POST", "/user2/repo1/branches/bulk_delete", strings.NewReader(url.Values{
+			"_csrf":  []string{htmlDoc.GetCSRF()},
+			"branch": []string{"develop", "master"},
+		}.Encode()))
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		resp = session.MakeRequest(t, req, http.StatusFound)
+		assert.EqualValues(t, "/user2/repo1/branches?state=stale", test.RedirectURL(resp))
+
+		req = NewRequest(t, "GET", "/user2/repo1/branches?state=stale")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.Contains(t,
+			htmlDoc.doc.Find(".ui.positive.message").Text(),
+			i18n.Tr("en", "repo.branch.bulk_deletion_success", 1),
+		)
+		assert.Contains(t,
+			htmlDoc.doc.Find(".ui.negative.message").Text(),
+			i18n.Tr("en", "repo.branch.bulk_deletion_skipped", "master"),
+		)
+		assert.EqualValues(t, 0, htmlDoc.doc.Find("input[name=branch][value=develop]").Length())
+	})
+}
+
 func deleteBranch(t *testing.T) {
 	htmlDoc, name := branchAction(t, ".delete-branch-button")
 	assert.Contains(t,
@@ -0,0 +1,77 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testCherryPick(t *testing.T, session *TestSession, sha, baseBranch, newBranch string, revert bool, expectedStatus int) *httptest.ResponseRecorder {
+	link := "/user2/repo1/_cherrypick/" + sha
+	if revert {
+		link += "?revert=true"
+	}
+	req := NewRequest(t, "GET", link)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+
+	doc := NewHTMLParser(t, resp.Body)
+	message := doc.doc.Find("textarea[name=commit_message]").Text()
+	assert.NotEmpty(t, message)
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/_cherrypick/"+sha, map[string]string{
+		"_csrf":           doc.GetCSRF(),
+		"base_branch":     baseBranch,
+		"new_branch_name": newBranch,
+		"commit_message":  message,
+		"revert":          doc.GetInputValueByName("revert"),
+	})
+	return session.MakeRequest(t, req, expectedStatus)
+}
+
+func TestCherryPickCommit(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+
+		resp := testCherryPick(t, session, "5c050d3b6d2db231ab1f64e324f1b6b9a0b181c2", "master", "cherry-pick-5c050d3", false, http.StatusFound)
+		assert.True(t, strings.HasPrefix(test.RedirectURL(resp), "/user2/repo1/pulls/"))
+
+		pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{BaseRepoID: 1, HeadBranch: "cherry-pick-5c050d3"}).(*models.PullRequest)
+		assert.EqualValues(t, "master", pr.BaseBranch)
+		issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: pr.IssueID}).(*models.Issue)
+		assert.Contains(t, issue.Content, "(cherry picked from commit 5c050d3b6d2db231ab1f64e324f1b6b9a0b181c2)")
+	})
+}
+
+func TestRevertCommit(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+
+		testCherryPick(t, session, "985f0301dba5e7b34be866819cd15ad3d8f508ee", "branch2", "revert-985f030", true, http.StatusFound)
+
+		pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{BaseRepoID: 1, HeadBranch: "revert-985f030"}).(*models.PullRequest)
+		assert.EqualValues(t, "branch2", pr.BaseBranch)
+		issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: pr.IssueID}).(*models.Issue)
+		assert.True(t, strings.HasPrefix(issue.Title, "Revert \""))
+	})
+}
+
+func TestCherryPickCommitConflict(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+
+		resp := testCherryPick(t, session, "985f0301dba5e7b34be866819cd15ad3d8f508ee", "master", "cherry-pick-985f030", false, http.StatusOK)
+		assert.Contains(t, resp.Body.String(), "Conflicting files: README.md")
+		models.AssertNotExistsBean(t, &models.PullRequest{BaseRepoID: 1, HeadBranch: "cherry-pick-985f030"})
+	})
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cron"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterLeader(t *testing.T) {
+	defer prepareTestEnv(t)()
+	oldCluster := setting.Cluster
+	defer func() {
+		setting.Cluster = oldCluster
+	}()
+	setting.Cluster.Enabled = true
+	setting.Cluster.NodeName = "node-a"
+
+	// another node is the leader, so scheduled tasks do not run on this one
+	acquired, err := models.AcquireClusterLease("leader", "node-b", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	task := cron.GetTask("deleted_branches_cleanup")
+	execTimes := task.ExecTimes
+	task.Run()
+	assert.Equal(t, execTimes, task.ExecTimes)
+
+	session := loginUser(t, "user1")
+	req := NewRequest(t, "GET", "/admin/monitor")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "<strong>node-b</strong>")
+	assert.NoError(t, models.ReleaseClusterLease("leader", "node-b"))
+}
@@ -34,3 +34,45 @@ func TestDownloadByIDMedia(t *testing.T) {
 
 	assert.Equal(t, "# repo1\n\nDescription for repo1", resp.Body.String())
 }
+
+func TestDownloadRawHeaders(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	link := "/user2/repo1/settings/raw_headers"
+	req := NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf":           GetCSRF(t, session, link),
+		"allowed_origins": "https://example.com",
+		"cache_max_age":   "60",
+		"robots_policy":   "noindex",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+
+	for _, raw := range []string{"raw", "media"} {
+		req = NewRequest(t, "GET", "/user2/repo1/"+raw+"/branch/master/README.md")
+		req.Header.Set("Origin", "https://example.com")
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, "https://example.com", resp.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "public,max-age=60", resp.Header().Get("Cache-Control"))
+		assert.Equal(t, "noindex", resp.Header().Get("X-Robots-Tag"))
+
+		req = NewRequest(t, "OPTIONS", "/user2/repo1/"+raw+"/branch/master/README.md")
+		req.Header.Set("Origin", "https://example.com")
+		resp = MakeRequest(t, req, http.StatusNoContent)
+		assert.Equal(t, "https://example.com", resp.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, resp.Header().Get("Access-Control-Allow-Methods"), "GET")
+
+		req = NewRequest(t, "GET", "/user2/repo1/"+raw+"/branch/master/README.md")
+		req.Header.Set("Origin", "https://example.org")
+		resp = MakeRequest(t, req, http.StatusOK)
+		assert.Empty(t, resp.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "Origin", resp.Header().Get("Vary"))
+	}
+
+	req = NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf":           GetCSRF(t, session, link),
+		"allowed_origins": "example.com",
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "is not a valid origin")
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/packcache"
+	"code.gitea.io/gitea/modules/repofiles"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitPackCache(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	dir, err := ioutil.TempDir("", "pack-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	oldPackCache := setting.Git.PackCache
+	defer func() {
+		setting.Git.PackCache = oldPackCache
+	}()
+	setting.Git.PackCache.Enabled = true
+	setting.Git.PackCache.Path = dir
+	setting.Git.PackCache.MinRequests = 2
+
+	body := "0032want 65f1bf27bc3bf70f64657658635e66094edbcb4d\n00000009done\n"
+	key := packcache.RequestKey(1, "", []byte(body))
+	assert.NotEmpty(t, key)
+
+	clone := func() string {
+		req := NewRequestWithBody(t, "POST", "/user2/repo1.git/git-upload-pack", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-git-upload-pack-request")
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.True(t, strings.HasPrefix(resp.Body.String(), "0008NAK\nPACK"))
+		return resp.Body.String()
+	}
+
+	pack := clone()
+	assert.Nil(t, packcache.Get(1, key))
+
+	// the second clone is cached
+	assert.Equal(t, pack, clone())
+	f := packcache.Get(1, key)
+	if assert.NotNil(t, f) {
+		f.Close()
+	}
+	assert.Equal(t, pack, clone())
+
+	// a push invalidates the cache of the repository
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.NoError(t, repofiles.PushUpdates(repo, []*repofiles.PushUpdateOptions{{
+		PusherID:     2,
+		PusherName:   "user2",
+		RepoUserName: "user2",
+		RepoName:     "repo1",
+		RefFullName:  git.BranchPrefix + "master",
+		OldCommitID:  "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		NewCommitID:  "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+	}}))
+	assert.Nil(t, packcache.Get(1, key))
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/issuetracker"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueTrackerSyncSetting(t *testing.T) {
+	onGiteaRun(t, testIssueTrackerSyncSetting)
+}
+
+func testIssueTrackerSyncSetting(t *testing.T, u *url.URL) {
+	session := loginUser(t, "user2")
+
+	link := "/user2/repo1/settings/issue_tracker_sync"
+	session.MakeRequest(t, NewRequest(t, "GET", link), http.StatusOK)
+
+	// a token is required to create the sync
+	req := NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf": GetCSRF(t, session, link),
+		"type":  "jira",
+		"url":   "https://jira.example.com",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.IssueTrackerSync{RepoID: 1})
+
+	// invalid project keys are rejected
+	req = NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf":       GetCSRF(t, session, link),
+		"type":        "jira",
+		"url":         "https://jira.example.com",
+		"project_key": "proj",
+		"token":       "secret",
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "The project key must be made of uppercase letters")
+
+	req = NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf":              GetCSRF(t, session, link),
+		"type":               "jira",
+		"url":                "https://jira.example.com/",
+		"project_key":        "PROJ",
+		"token":              "secret",
+		"comment_on_commits": "on",
+		"require_reference":  "on",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	trackerSync := models.AssertExistsAndLoadBean(t, &models.IssueTrackerSync{RepoID: 1}).(*models.IssueTrackerSync)
+	assert.Equal(t, issuetracker.TypeJira, trackerSync.Type)
+	assert.Equal(t, "https://jira.example.com", trackerSync.URL)
+	assert.Equal(t, "secret", trackerSync.Token)
+	assert.True(t, trackerSync.CommentOnCommits)
+	assert.False(t, trackerSync.CommentOnPullRequests)
+	assert.True(t, trackerSync.RequireReference)
+
+	// the token is not shown and kept when it is left empty
+	resp = session.MakeRequest(t, NewRequest(t, "GET", link), http.StatusOK)
+	assert.NotContains(t, resp.Body.String(), "secret")
+	req = NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf":             GetCSRF(t, session, link),
+		"type":              "jira",
+		"url":               "https://jira.example.com",
+		"project_key":       "PROJ",
+		"require_reference": "on",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	trackerSync = models.AssertExistsAndLoadBean(t, &models.IssueTrackerSync{RepoID: 1}).(*models.IssueTrackerSync)
+	assert.Equal(t, "secret", trackerSync.Token)
+	assert.False(t, trackerSync.CommentOnCommits)
+
+	// the commits created on the web must reference an issue of the project
+	editLink := path.Join("user2", "repo1", "_edit", "master", "README.md")
+	resp = session.MakeRequest(t, NewRequest(t, "GET", editLink), http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	lastCommit := htmlDoc.GetInputValueByName("last_commit")
+	req = NewRequestWithValues(t, "POST", editLink, map[string]string{
+		"_csrf":          htmlDoc.GetCSRF(),
+		"last_commit":    lastCommit,
+		"tree_path":      "README.md",
+		"content":        "Hello, World (Edited)\n",
+		"commit_summary": "Update README",
+		"commit_choice":  "direct",
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "the message does not reference an issue of the Jira project PROJ")
+
+	req = NewRequestWithValues(t, "POST", editLink, map[string]string{
+		"_csrf":          htmlDoc.GetCSRF(),
+		"last_commit":    lastCommit,
+		"tree_path":      "README.md",
+		"content":        "Hello, World (Edited)\n",
+		"commit_summary": "PROJ-1 Update README",
+		"commit_choice":  "direct",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+
+	req = NewRequestWithValues(t, "POST", link+"/delete", map[string]string{
+		"_csrf": GetCSRF(t, session, link),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.IssueTrackerSync{RepoID: 1})
+}
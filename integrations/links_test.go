@@ -27,6 +27,9 @@ func TestLinksNoLogin(t *testing.T) {
 		"/explore/users?q=test&tab=",
 		"/explore/organizations",
 		"/explore/organizations?q=test&tab=",
+		"/explore/topics",
+		"/explore/topics/golang",
+		"/-/snippets",
 		"/",
 		"/user/sign_up",
 		"/user/login",
@@ -66,6 +69,9 @@ func testLinksAsUser(userName string, t *testing.T) {
 		"/explore/users?q=test&tab=",
 		"/explore/organizations",
 		"/explore/organizations?q=test&tab=",
+		"/explore/topics",
+		"/explore/topics/golang",
+		"/-/snippets",
 		"/",
 		"/user/forgot_password",
 		"/api/swagger",
@@ -125,7 +131,10 @@ func testLinksAsUser(userName string, t *testing.T) {
 		"/pulls",
 		"/commits/branch/master",
 		"/graph",
+		"/network",
+		"/network/graph",
 		"/settings",
+		"/settings/visibility",
 		"/settings/collaboration",
 		"/settings/branches",
 		"/settings/hooks",
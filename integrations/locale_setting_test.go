@@ -0,0 +1,90 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgLocaleSetting(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// limited_org has a German default language and date format, it is managed by the site admin
+	session := loginUser(t, "user1")
+
+	resp := session.MakeRequest(t, NewRequest(t, "GET", "/org/limited_org/settings/locale"), http.StatusOK)
+	assert.Contains(t, resp.Body.String(), `value="dd.MM.yyyy"`)
+
+	req := NewRequestWithValues(t, "POST", "/org/limited_org/settings/locale", map[string]string{
+		"_csrf":         GetCSRF(t, session, "/org/limited_org/settings/locale"),
+		"language":      "en-US",
+		"date_format":   "yyyy-MM-dd",
+		"number_format": "#,##0",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	setting := models.AssertExistsAndLoadBean(t, &models.LocaleSetting{OrgID: 22}).(*models.LocaleSetting)
+	assert.Equal(t, "en-US", setting.Language)
+	assert.Equal(t, "yyyy-MM-dd", setting.DateFormat)
+
+	// invalid patterns are rejected
+	req = NewRequestWithValues(t, "POST", "/org/limited_org/settings/locale", map[string]string{
+		"_csrf":       GetCSRF(t, session, "/org/limited_org/settings/locale"),
+		"date_format": "'unterminated",
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "The date format is not a valid ICU date pattern.")
+}
+
+func TestRepoLocaleSetting(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user1")
+
+	// the organization settings are shown as the defaults of its repositories
+	resp := session.MakeRequest(t, NewRequest(t, "GET", "/limited_org/public_repo_on_limited_org/settings/locale"), http.StatusOK)
+	assert.Contains(t, resp.Body.String(), `placeholder="dd.MM.yyyy"`)
+
+	req := NewRequestWithValues(t, "POST", "/limited_org/public_repo_on_limited_org/settings/locale", map[string]string{
+		"_csrf":       GetCSRF(t, session, "/limited_org/public_repo_on_limited_org/settings/locale"),
+		"date_format": "d MMMM yyyy",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.LocaleSetting{RepoID: 38, DateFormat: "d MMMM yyyy"})
+
+	// clearing all the fields removes the setting
+	req = NewRequestWithValues(t, "POST", "/limited_org/public_repo_on_limited_org/settings/locale", map[string]string{
+		"_csrf": GetCSRF(t, session, "/limited_org/public_repo_on_limited_org/settings/locale"),
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertNotExistsBean(t, &models.LocaleSetting{RepoID: 38})
+
+	// the format is used for the dates shown to the viewers without a language
+	session = loginUser(t, "user2")
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/settings/locale", map[string]string{
+		"_csrf":       GetCSRF(t, session, "/user2/repo1/settings/locale"),
+		"date_format": "yyyy/MM/dd",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: 1, Index: 1}).(*models.Issue)
+	deadline := time.Date(2021, time.March, 7, 0, 0, 0, 0, setting.DefaultUILocation)
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{Name: "user2"}).(*models.User)
+	assert.NoError(t, models.UpdateIssueDeadline(issue, timeutil.TimeStamp(deadline.Unix()), user2))
+	resp = MakeRequest(t, NewRequest(t, "GET", "/user2/repo1/issues"), http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "2021/03/07")
+	// the time since strings keep the full time in their title
+	assert.NotRegexp(t, regexp.MustCompile(`class="time-since" title="\d{4}/\d\d/\d\d"`), resp.Body.String())
+	user2.Language = "en-US"
+	assert.NoError(t, models.UpdateUserCols(user2, "language"))
+	resp = session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1/issues"), http.StatusOK)
+	assert.NotContains(t, resp.Body.String(), "2021/03/07")
+	assert.Contains(t, resp.Body.String(), "Mar 07, 2021")
+}
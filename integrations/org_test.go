@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 
+	"code.gitea.io/gitea/models"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -110,3 +112,98 @@ func TestPrivateOrg(t *testing.T) {
 	req = NewRequest(t, "GET", "/privated_org/private_repo_on_private_org")
 	session.MakeRequest(t, req, http.StatusOK)
 }
+
+func TestOrgMembersExternalCollaborators(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	csrf := GetCSRF(t, session, "/org/user3/members")
+	req := NewRequestWithValues(t, "POST", "/org/user3/members/action/expire", map[string]string{
+		"_csrf":   csrf,
+		"uid":     "4",
+		"expires": "2099-12-31",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+
+	req = NewRequest(t, "GET", "/org/user3/members")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), `value="2099-12-31"`)
+
+	req = NewRequestWithValues(t, "POST", "/org/user3/members/action/convert", map[string]string{
+		"_csrf": csrf,
+		"uid":   "4",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+
+	req = NewRequest(t, "GET", "/org/user3/members")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, `form[action="/org/user3/members/action/remove_external"] button[value="4"]`, true)
+	htmlDoc.AssertElement(t, `form[action="/org/user3/members/action/remove"] button[value="4"]`, false)
+}
+
+func TestOrgTeamHierarchy(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	csrf := GetCSRF(t, session, "/org/user3/teams/team12creators/edit")
+	req := NewRequestWithValues(t, "POST", "/org/user3/teams/team12creators/edit", map[string]string{
+		"_csrf":                   csrf,
+		"team_name":               "team12creators",
+		"permission":              "admin",
+		"repo_access":             "specific",
+		"review_assign_algorithm": "all",
+		"review_assign_count":     "1",
+		"parent_id":               "2",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.Team{ID: 12, ParentID: 2})
+
+	req = NewRequest(t, "GET", "/org/user3/teams")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, `.sub.header a[href="/org/user3/teams/team1"]`, true)
+
+	req = NewRequest(t, "GET", "/org/user3/teams/team1")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, `a[href="/org/user3/teams/team12creators"]`, true)
+
+	// a team cannot be a child of its child team
+	req = NewRequestWithValues(t, "POST", "/org/user3/teams/team1/edit", map[string]string{
+		"_csrf":                   csrf,
+		"team_name":               "team1",
+		"permission":              "write",
+		"units":                   "1",
+		"repo_access":             "specific",
+		"review_assign_algorithm": "all",
+		"review_assign_count":     "1",
+		"parent_id":               "12",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertExistsAndLoadBean(t, &models.Team{ID: 2, ParentID: 0})
+}
+
+func TestOrgRolesSettings(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/org/user3/settings/roles/new?preset=triage")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, `select[name="unit_2"] option[value="write"][selected]`, true)
+
+	req = NewRequestWithValues(t, "POST", "/org/user3/settings/roles/new", map[string]string{
+		"_csrf":  htmlDoc.GetCSRF(),
+		"name":   "Wiki Maintainer",
+		"unit_1": "read",
+		"unit_5": "write",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	role := models.AssertExistsAndLoadBean(t, &models.OrgRole{OrgID: 3, LowerName: "wiki maintainer"}).(*models.OrgRole)
+	models.AssertExistsAndLoadBean(t, &models.OrgRolePermission{RoleID: role.ID, Type: models.UnitTypeWiki, AccessMode: models.AccessModeWrite})
+
+	req = NewRequest(t, "GET", "/org/user3/settings/roles")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "Wiki Maintainer")
+}
@@ -6,6 +6,8 @@ package integrations
 
 import (
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,3 +27,54 @@ func TestPullCompare(t *testing.T) {
 	resp = session.MakeRequest(t, req, http.StatusOK)
 	assert.EqualValues(t, http.StatusOK, resp.Code)
 }
+
+func TestCompareAcrossForks(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testRepoFork(t, loginUser(t, "user2"), "user2", "repo1", "user3", "repo1")
+		testEditFileToNewBranch(t, session, "user1", "repo1", "master", "compare-feature", "README.md", "Hello, World (Edited)\n")
+
+		// compare the branch of a fork with the sibling fork
+		req := NewRequest(t, "GET", "/user3/repo1/compare/master...user1/repo1:compare-feature")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.doc.Find("#diff-files li").Length())
+		for _, base := range []string{"user1", "user2", "user3"} {
+			link := "/" + base + "/repo1/compare/master...user1/repo1:compare-feature"
+			assert.EqualValues(t, 1, htmlDoc.doc.Find(".choose.branch .fork-network .item[data-url=\""+link+"\"]").Length(), link)
+		}
+		assert.EqualValues(t, 1, htmlDoc.doc.Find(".choose.branch .fork-network .item[data-url=\"/user3/repo1/compare/master...user2/repo1:master\"]").Length())
+
+		// filter the changed files
+		req = NewRequest(t, "GET", "/user3/repo1/compare/master...user1/repo1:compare-feature?file=*.md")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.doc.Find("#diff-files li").Length())
+		assert.EqualValues(t, "*.md", htmlDoc.GetInputValueByName("file"))
+
+		req = NewRequest(t, "GET", "/user3/repo1/compare/master...user1/repo1:compare-feature?file=*.go")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 0, htmlDoc.doc.Find("#diff-files li").Length())
+
+		// download the comparison
+		req = NewRequest(t, "GET", "/user3/repo1/compare/master...user1/repo1:compare-feature.diff")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.True(t, strings.HasPrefix(resp.Body.String(), "diff --git a/README.md b/README.md"))
+		assert.Contains(t, resp.Body.String(), "+Hello, World (Edited)")
+
+		req = NewRequest(t, "GET", "/user3/repo1/compare/master...user1:compare-feature.patch")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.Contains(t, resp.Body.String(), "Subject: [PATCH] Update 'README.md'")
+		assert.Contains(t, resp.Body.String(), "+Hello, World (Edited)")
+
+		// repositories outside of the fork network cannot be compared
+		req = NewRequest(t, "GET", "/user3/repo1/compare/master...user2/repo16:master")
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		// the head owner has no repository in the fork network
+		req = NewRequest(t, "GET", "/user3/repo1/compare/master...user4:master")
+		session.MakeRequest(t, req, http.StatusNotFound)
+	})
+}
@@ -6,6 +6,7 @@ package integrations
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -17,6 +18,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/git"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/test"
@@ -148,6 +150,107 @@ func TestPullSquash(t *testing.T) {
 	})
 }
 
+func TestPullFastForwardOnly(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testEditFile(t, session, "user1", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+
+		resp := testPullCreate(t, session, "user1", "repo1", "master", "This is a pull title")
+		elem := strings.Split(test.RedirectURL(resp), "/")
+		assert.EqualValues(t, "pulls", elem[3])
+
+		ownerSession := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, ownerSession)
+		enabled := true
+		req := NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1?token="+token, &api.EditRepoOption{
+			HasPullRequests:      &enabled,
+			AllowFastForwardOnly: &enabled,
+		})
+		ownerSession.MakeRequest(t, req, http.StatusOK)
+
+		// the branch protection of master only allows squashing
+		req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+			BranchName:  "master",
+			MergeStyles: []string{string(models.MergeStyleSquash)},
+		})
+		ownerSession.MakeRequest(t, req, http.StatusCreated)
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%s/merge?token=%s", elem[4], token), &auth.MergePullRequestForm{
+			Do: string(models.MergeStyleFastForwardOnly),
+		})
+		ownerSession.MakeRequest(t, req, http.StatusMethodNotAllowed)
+
+		req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/branch_protections/master?token="+token, &api.EditBranchProtectionOption{
+			MergeStyles: []string{"unknown"},
+		})
+		ownerSession.MakeRequest(t, req, http.StatusUnprocessableEntity)
+		req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/branch_protections/master?token="+token, &api.EditBranchProtectionOption{
+			MergeStyles: []string{string(models.MergeStyleFastForwardOnly)},
+		})
+		ownerSession.MakeRequest(t, req, http.StatusOK)
+
+		testPullMerge(t, session, elem[1], elem[2], elem[4], models.MergeStyleFastForwardOnly)
+
+		// the base branch now points to the head commit, without any merge commit
+		var base, head api.Branch
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/branches/master")
+		DecodeJSON(t, MakeRequest(t, req, http.StatusOK), &base)
+		req = NewRequest(t, "GET", "/api/v1/repos/user1/repo1/branches/master")
+		DecodeJSON(t, MakeRequest(t, req, http.StatusOK), &head)
+		assert.Equal(t, head.Commit.ID, base.Commit.ID)
+	})
+}
+
+func TestPullRebaseAutosquash(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		session := loginUser(t, "user1")
+		token := getTokenForLoggedInUser(t, session)
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testEditFile(t, session, "user1", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user1/repo1/contents/fixup.txt?token="+token, &api.CreateFileOptions{
+			FileOptions: api.FileOptions{
+				BranchName: "master",
+				Message:    "fixup! Update 'README.md'",
+			},
+			Content: base64.StdEncoding.EncodeToString([]byte("fixup\n")),
+		})
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		resp := testPullCreate(t, session, "user1", "repo1", "master", "This is a pull title")
+		elem := strings.Split(test.RedirectURL(resp), "/")
+		assert.EqualValues(t, "pulls", elem[3])
+
+		// folding the fixup! commit is suggested
+		req = NewRequest(t, "GET", path.Join(elem[1], elem[2], "pulls", elem[4]))
+		htmlDoc := NewHTMLParser(t, session.MakeRequest(t, req, http.StatusOK).Body)
+		_, checked := htmlDoc.doc.Find(".rebase-fields input[name=autosquash]").Attr("checked")
+		assert.True(t, checked)
+
+		baseRepo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerName: "user2", Name: "repo1"}).(*models.Repository)
+		gitRepo, err := git.OpenRepository(baseRepo.RepoPath())
+		assert.NoError(t, err)
+		defer gitRepo.Close()
+		oldHead, err := gitRepo.GetBranchCommitID("master")
+		assert.NoError(t, err)
+
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%s/merge?token=%s", elem[1], elem[2], elem[4], token), &auth.MergePullRequestForm{
+			Do:         string(models.MergeStyleRebase),
+			Autosquash: true,
+		})
+		session.MakeRequest(t, req, http.StatusOK)
+
+		// the fixup! commit has been folded into the commit it amends
+		commit, err := gitRepo.GetBranchCommit("master")
+		assert.NoError(t, err)
+		assert.Equal(t, "Update 'README.md'", commit.Summary())
+		parentID, err := commit.ParentID(0)
+		assert.NoError(t, err)
+		assert.Equal(t, oldHead, parentID.String())
+		_, err = commit.GetTreeEntryByPath("fixup.txt")
+		assert.NoError(t, err)
+	})
+}
+
 func TestPullCleanUpAfterMerge(t *testing.T) {
 	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
 		session := loginUser(t, "user1")
@@ -239,11 +342,11 @@ func TestCantMergeConflict(t *testing.T) {
 		gitRepo, err := git.OpenRepository(models.RepoPath(user1.Name, repo1.Name))
 		assert.NoError(t, err)
 
-		err = pull.Merge(pr, user1, gitRepo, models.MergeStyleMerge, "CONFLICT")
+		err = pull.Merge(pr, user1, gitRepo, models.MergeStyleMerge, "CONFLICT", false)
 		assert.Error(t, err, "Merge should return an error due to conflict")
 		assert.True(t, models.IsErrMergeConflicts(err), "Merge error is not a conflict error")
 
-		err = pull.Merge(pr, user1, gitRepo, models.MergeStyleRebase, "CONFLICT")
+		err = pull.Merge(pr, user1, gitRepo, models.MergeStyleRebase, "CONFLICT", false)
 		assert.Error(t, err, "Merge should return an error due to conflict")
 		assert.True(t, models.IsErrRebaseConflicts(err), "Merge error is not a conflict error")
 	})
@@ -326,7 +429,7 @@ func TestCantMergeUnrelated(t *testing.T) {
 			BaseBranch: "base",
 		}).(*models.PullRequest)
 
-		err = pull.Merge(pr, user1, gitRepo, models.MergeStyleMerge, "UNRELATED")
+		err = pull.Merge(pr, user1, gitRepo, models.MergeStyleMerge, "UNRELATED", false)
 		assert.Error(t, err, "Merge should return an error due to unrelated")
 		assert.True(t, models.IsErrMergeUnrelatedHistories(err), "Merge error is not a unrelated histories error")
 	})
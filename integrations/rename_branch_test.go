@@ -0,0 +1,61 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unknwon/i18n"
+)
+
+func TestRenameDefaultBranch(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		req := NewRequest(t, "GET", "/user2/repo1/settings/branches/_rename")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.doc.Find(`a[href="/user2/repo1/pulls/3"]`).Length())
+
+		// the new name must not be taken
+		req = NewRequestWithValues(t, "POST", "/user2/repo1/settings/branches/_rename", map[string]string{
+			"_csrf":           htmlDoc.GetCSRF(),
+			"new_branch_name": "develop",
+		})
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.Contains(t,
+			NewHTMLParser(t, resp.Body).doc.Find(".ui.negative.message").Text(),
+			i18n.Tr("en", "repo.branch.branch_already_exists", "develop"),
+		)
+
+		req = NewRequestWithValues(t, "POST", "/user2/repo1/settings/branches/_rename", map[string]string{
+			"_csrf":           htmlDoc.GetCSRF(),
+			"new_branch_name": "main",
+		})
+		resp = session.MakeRequest(t, req, http.StatusFound)
+		assert.EqualValues(t, "/user2/repo1/settings/branches", test.RedirectURL(resp))
+
+		repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+		assert.EqualValues(t, "main", repo.DefaultBranch)
+		pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 2}).(*models.PullRequest)
+		assert.EqualValues(t, "main", pr.BaseBranch)
+
+		req = NewRequest(t, "GET", "/user2/repo1/src/branch/main")
+		session.MakeRequest(t, req, http.StatusOK)
+		req = NewRequest(t, "GET", "/user2/repo1/src/branch/master")
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		// visitors are told how to update their clones
+		req = NewRequest(t, "GET", "/user2/repo1")
+		resp = MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.Contains(t, htmlDoc.doc.Find(".ui.info.message pre").Text(), "git branch -m master main")
+	})
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoNetwork(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testEditFileToNewBranch(t, session, "user1", "repo1", "master", "network-feature", "README.md", "Hello, World (Edited)\n")
+
+		req := NewRequest(t, "GET", "/user2/repo1/network")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		link, exists := htmlDoc.doc.Find("#network-graph").Attr("data-url")
+		assert.True(t, exists, "The template has changed")
+		assert.EqualValues(t, "/user2/repo1/network/graph", link)
+
+		req = NewRequest(t, "GET", link)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 2, htmlDoc.doc.Find(".network-repos .label").Length())
+		assert.EqualValues(t, 0, htmlDoc.doc.Find(".load-more").Length())
+		tip := htmlDoc.doc.Find("#rev-list li").FilterFunction(func(i int, li *goquery.Selection) bool {
+			return strings.Contains(li.Find("strong").Text(), "user1/repo1/network-feature")
+		})
+		if assert.EqualValues(t, 1, tip.Length()) {
+			href, _ := tip.Find("code a").Attr("href")
+			assert.True(t, strings.HasPrefix(href, "/user1/repo1/commit/"), href)
+		}
+
+		// the forks beyond the limit are loaded on demand
+		req = NewRequest(t, "GET", "/user1/repo1/network/graph?forks=1")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 2, htmlDoc.doc.Find(".network-repos .label").Length())
+		link, exists = htmlDoc.doc.Find(".network-repos .load-more").Attr("data-url")
+		assert.True(t, exists)
+		assert.EqualValues(t, "/user1/repo1/network/graph?forks=21", link)
+
+		// the API lists the tips of the branches of the network
+		token := getTokenForLoggedInUser(t, session)
+		req = NewRequestf(t, "GET", "/api/v1/repos/user1/repo1/network?token=%s", token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.EqualValues(t, "2", resp.Header().Get("X-Total-Count"))
+		var network []*api.NetworkRepository
+		DecodeJSON(t, resp, &network)
+		if assert.Len(t, network, 2) {
+			assert.EqualValues(t, "user2/repo1", network[0].Repository.FullName)
+			assert.EqualValues(t, "user1/repo1", network[1].Repository.FullName)
+			names := make([]string, 0, len(network[1].Branches))
+			for _, branch := range network[1].Branches {
+				names = append(names, branch.Name)
+				assert.Len(t, branch.CommitID, 40)
+			}
+			assert.Contains(t, names, "network-feature")
+		}
+
+		req = NewRequestf(t, "GET", "/api/v1/repos/user1/repo1/network?limit=1&page=2&token=%s", token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &network)
+		if assert.Len(t, network, 1) {
+			assert.EqualValues(t, "user1/repo1", network[0].Repository.FullName)
+		}
+	})
+}
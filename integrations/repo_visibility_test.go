@@ -0,0 +1,107 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoVisibilityChange(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	// changing the visibility from the settings asks for a confirmation
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/settings", map[string]string{
+		"_csrf":     GetCSRF(t, session, "/user2/repo1/settings"),
+		"action":    "update",
+		"repo_name": "repo1",
+		"private":   "on",
+	})
+	resp := session.MakeRequest(t, req, http.StatusFound)
+	assert.EqualValues(t, "/user2/repo1/settings/visibility?private=true", resp.HeaderMap.Get("Location"))
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.False(t, repo.IsPrivate)
+
+	link := "/user2/repo1/settings/visibility?private=true"
+	req = NewRequest(t, "GET", link)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 5, htmlDoc.doc.Find(".visibility-impact li").Length())
+	assert.Contains(t, htmlDoc.doc.Find(".visibility-impact").Text(), "3 watcher(s)")
+
+	// the repository name must be confirmed
+	req = NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf":     GetCSRF(t, session, link),
+		"action":    "now",
+		"private":   "true",
+		"repo_name": "repo2",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	repo = models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.False(t, repo.IsPrivate)
+
+	// the change can only be scheduled in the future
+	req = NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf":        GetCSRF(t, session, link),
+		"action":       "schedule",
+		"private":      "true",
+		"repo_name":    "repo1",
+		"scheduled_at": time.Now().Add(-time.Hour).Format("2006-01-02T15:04"),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.ScheduledVisibilityChange{RepoID: 1})
+
+	req = NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf":        GetCSRF(t, session, link),
+		"action":       "schedule",
+		"private":      "true",
+		"repo_name":    "repo1",
+		"scheduled_at": time.Now().Add(24 * time.Hour).Format("2006-01-02T15:04"),
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	change := models.AssertExistsAndLoadBean(t, &models.ScheduledVisibilityChange{RepoID: 1}).(*models.ScheduledVisibilityChange)
+	assert.True(t, change.IsPrivate)
+	assert.EqualValues(t, 2, change.DoerID)
+
+	req = NewRequest(t, "GET", "/user2/repo1/settings")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.True(t, strings.Contains(resp.Body.String(), "/user2/repo1/settings/visibility"))
+
+	req = NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf":   GetCSRF(t, session, link),
+		"action":  "cancel",
+		"private": "true",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertNotExistsBean(t, &models.ScheduledVisibilityChange{RepoID: 1})
+
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	assert.NoError(t, models.ScheduleVisibilityChange(user2, repo, true, change.ScheduledUnix))
+
+	// changing the visibility right away cancels the scheduled change
+	req = NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf":     GetCSRF(t, session, link),
+		"action":    "now",
+		"private":   "true",
+		"repo_name": "repo1",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	repo = models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.True(t, repo.IsPrivate)
+	models.AssertNotExistsBean(t, &models.ScheduledVisibilityChange{RepoID: 1})
+
+	// forks follow the visibility of their base repository
+	session = loginUser(t, "user1")
+	testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+	req = NewRequest(t, "GET", "/user1/repo1/settings/visibility")
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/repofiles"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceCommit(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+
+		req := NewRequest(t, "GET", "/user2/repo1/_ide/master")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		csrf := NewHTMLParser(t, resp.Body).GetCSRF()
+
+		// save a new file in the workspace
+		req = NewRequestWithValues(t, "POST", "/user2/repo1/_ide_file/master/docs/workspace.md", map[string]string{
+			"_csrf":   csrf,
+			"content": "Workspace content",
+		})
+		session.MakeRequest(t, req, http.StatusOK)
+
+		req = NewRequest(t, "GET", "/user2/repo1/_ide_files/master")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var files []struct {
+			Path   string `json:"path"`
+			Status string `json:"status"`
+		}
+		DecodeJSON(t, resp, &files)
+		statuses := make(map[string]string, len(files))
+		for _, file := range files {
+			statuses[file.Path] = file.Status
+		}
+		assert.Equal(t, "added", statuses["docs/workspace.md"])
+		assert.Equal(t, "modified", statuses["README.md"])
+
+		// the file is not in the branch before the commit
+		req = NewRequest(t, "GET", "/user2/repo1/raw/branch/master/docs/workspace.md")
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequestWithValues(t, "POST", "/user2/repo1/_ide_commit/master", map[string]string{
+			"_csrf":         csrf,
+			"commit_choice": "direct",
+		})
+		resp = session.MakeRequest(t, req, http.StatusFound)
+		assert.EqualValues(t, "/user2/repo1/src/branch/master", resp.Header().Get("Location"))
+
+		req = NewRequest(t, "GET", "/user2/repo1/raw/branch/master/docs/workspace.md")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.EqualValues(t, "Workspace content", resp.Body.String())
+
+		// the workspace is empty after the commit
+		req = NewRequest(t, "GET", "/user2/repo1/_ide_files/master")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		files = nil
+		DecodeJSON(t, resp, &files)
+		for _, file := range files {
+			assert.Empty(t, file.Status, file.Path)
+		}
+	})
+}
+
+func TestWorkspaceCommitLFS(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+		doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+		content := "LFS content from the workspace"
+
+		commitID, err := repofiles.CommitWorkspaceChanges(repo, doer, &repofiles.CommitWorkspaceOptions{
+			OldBranch: "master",
+			Message:   "Add an LFS file",
+			Changes: []*models.WorkspaceChange{
+				{TreePath: "data/file.bin", Content: content},
+				{TreePath: ".gitattributes", Content: "*.bin filter=lfs diff=lfs merge=lfs -text\n"},
+			},
+		})
+		assert.NoError(t, err)
+
+		// the commit holds a pointer to the content stored by LFS
+		gitRepo, err := git.OpenRepository(repo.RepoPath())
+		assert.NoError(t, err)
+		defer gitRepo.Close()
+		commit, err := gitRepo.GetCommit(commitID)
+		assert.NoError(t, err)
+		blob, err := commit.GetBlobByPath("data/file.bin")
+		assert.NoError(t, err)
+		pointer, err := blob.GetBlobContent()
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(pointer, models.LFSMetaFileIdentifier), pointer)
+
+		buf := []byte(pointer)
+		meta := lfs.IsPointerFile(&buf)
+		if assert.NotNil(t, meta) {
+			assert.EqualValues(t, len(content), meta.Size)
+			meta = models.AssertExistsAndLoadBean(t, &models.LFSMetaObject{Oid: meta.Oid, RepositoryID: repo.ID}).(*models.LFSMetaObject)
+		}
+	})
+}
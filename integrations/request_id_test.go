@@ -0,0 +1,33 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	req := NewRequest(t, "GET", "/")
+	resp := MakeRequest(t, req, http.StatusOK)
+	id := resp.Header().Get("X-Request-ID")
+	assert.True(t, log.IsValidRequestID(id), id)
+
+	req = NewRequest(t, "GET", "/")
+	req.Header.Set("X-Request-ID", "proxy-1234")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "proxy-1234", resp.Header().Get("X-Request-ID"))
+
+	req = NewRequest(t, "GET", "/")
+	req.Header.Set("X-Request-ID", "forged id")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.NotEqual(t, "forged id", resp.Header().Get("X-Request-ID"))
+}
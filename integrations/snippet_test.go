@@ -0,0 +1,51 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnippetCreateAndView(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	req := NewRequestWithValues(t, "POST", "/-/snippets/new", map[string]string{
+		"_csrf":        GetCSRF(t, session, "/-/snippets/new"),
+		"title":        "Greetings",
+		"visibility":   "limited",
+		"file_name":    "hello.py",
+		"file_content": "print('hello')\n",
+	})
+	resp := session.MakeRequest(t, req, http.StatusFound)
+	link := test.RedirectURL(resp)
+
+	req = NewRequest(t, "GET", link)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.Contains(t, htmlDoc.doc.Find(".snippet.view h2").Text(), "Greetings")
+	assert.Contains(t, htmlDoc.doc.Find(".file-view code").Text(), "print")
+
+	req = NewRequest(t, "GET", link+"/raw/hello.py")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "print('hello')\n", resp.Body.String())
+
+	req = NewRequest(t, "GET", link+"/revisions")
+	session.MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequest(t, "GET", link+"/edit")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.Equal(t, "hello.py", htmlDoc.GetInputValueByName("file_name"))
+
+	// limited snippets are hidden from anonymous users
+	req = NewRequest(t, "GET", link)
+	MakeRequest(t, req, http.StatusNotFound)
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStarLists(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	req := NewRequest(t, "GET", "/user2?tab=stars")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+
+	req = NewRequestWithValues(t, "POST", "/user/star_lists", map[string]string{
+		"_csrf":       htmlDoc.GetCSRF(),
+		"name":        "Tools",
+		"description": "useful tools",
+	})
+	resp = session.MakeRequest(t, req, http.StatusFound)
+	list := models.AssertExistsAndLoadBean(t, &models.StarList{UserID: 2, Name: "Tools"}).(*models.StarList)
+	assert.EqualValues(t, fmt.Sprintf("/user2?tab=stars&list=%d", list.ID), test.RedirectURL(resp))
+
+	// user2 stars repo2 and repo4 in the fixtures
+	req = NewRequestWithValues(t, "POST", fmt.Sprintf("/user/star_lists/%d/repos?repo_id=4&action=add", list.ID), map[string]string{
+		"_csrf": htmlDoc.GetCSRF(),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertExistsAndLoadBean(t, &models.StarListRepo{StarListID: list.ID, RepoID: 4})
+
+	req = NewRequestf(t, "GET", "/user2?tab=stars&list=%d", list.ID)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.Equal(t, 1, htmlDoc.doc.Find(".repository.list > .item").Length())
+	assert.Contains(t, htmlDoc.doc.Find(".star-lists").Text(), "useful tools")
+
+	req = NewRequest(t, "GET", "/user2?tab=stars")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.Equal(t, 2, htmlDoc.doc.Find(".repository.list > .item").Length())
+
+	// private lists are hidden from the other users
+	req = NewRequestWithValues(t, "POST", fmt.Sprintf("/user/star_lists/%d/edit", list.ID), map[string]string{
+		"_csrf":      htmlDoc.GetCSRF(),
+		"name":       "Tools",
+		"is_private": "on",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	list = models.AssertExistsAndLoadBean(t, &models.StarList{ID: list.ID}).(*models.StarList)
+	assert.True(t, list.IsPrivate)
+
+	session4 := loginUser(t, "user4")
+	req = NewRequestf(t, "GET", "/user2?tab=stars&list=%d", list.ID)
+	session4.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequest(t, "GET", "/user2?tab=stars")
+	resp = session4.MakeRequest(t, req, http.StatusOK)
+	assert.False(t, strings.Contains(NewHTMLParser(t, resp.Body).doc.Find(".star-lists").Text(), "Tools"))
+
+	req = NewRequestWithValues(t, "POST", fmt.Sprintf("/user/star_lists/%d/delete", list.ID), map[string]string{
+		"_csrf": htmlDoc.GetCSRF(),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.StarList{ID: list.ID})
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminEditTopic(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user1")
+	req := NewRequest(t, "GET", "/admin/topics?q=golang")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(`a[href="/admin/topics/1"]`).Length())
+
+	req = NewRequest(t, "GET", "/admin/topics/1")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	req = NewRequestWithValues(t, "POST", "/admin/topics/1", map[string]string{
+		"_csrf":       htmlDoc.GetCSRF(),
+		"description": "The Go programming language",
+		"logo":        "https://example.com/go.png",
+		"is_featured": "on",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	topic := models.AssertExistsAndLoadBean(t, &models.Topic{ID: 1}).(*models.Topic)
+	assert.True(t, topic.IsFeatured)
+
+	req = NewRequest(t, "GET", "/explore/topics/golang")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.True(t, strings.Contains(resp.Body.String(), "The Go programming language"))
+
+	req = NewRequestWithValues(t, "POST", "/admin/topics/4/merge", map[string]string{
+		"_csrf":  htmlDoc.GetCSRF(),
+		"target": "golang",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertNotExistsBean(t, &models.Topic{ID: 4})
+}
+
+func TestOrgFeaturedTopics(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	assert.NoError(t, models.SaveTopics(32, "golang"))
+	session := loginUser(t, "user2")
+	req := NewRequest(t, "GET", "/org/user3/settings/topics/golang")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	req = NewRequestWithValues(t, "POST", "/org/user3/settings/topics/golang", map[string]string{
+		"_csrf":       htmlDoc.GetCSRF(),
+		"description": "Our Go services",
+		"is_featured": "on",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+
+	req = NewRequest(t, "GET", "/user3")
+	resp = MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(`a[href="/user3?q=golang&topic=1"]`).Length())
+
+	req = NewRequest(t, "GET", "/user3?q=golang&topic=1")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.True(t, strings.Contains(resp.Body.String(), "Our Go services"))
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserAvatarVariant(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	img := generateImg()
+	assert.NoError(t, user2.UploadAvatar(img.Bytes()))
+	defer func() {
+		assert.NoError(t, user2.DeleteAvatar())
+	}()
+
+	req := NewRequest(t, "GET", "/user/avatar/user2/40")
+	resp := MakeRequest(t, req, http.StatusFound)
+	link := resp.Header().Get("Location")
+	assert.Equal(t, setting.AppSubURL+"/avatars/"+user2.Avatar+"/48", link)
+
+	req = NewRequest(t, "GET", strings.TrimPrefix(link, setting.AppSubURL))
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "image/png", resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Header().Get("Cache-Control"), "immutable")
+	assert.Equal(t, "Accept", resp.Header().Get("Vary"))
+
+	// browsers accepting WebP get a WebP variant
+	req = NewRequest(t, "GET", strings.TrimPrefix(link, setting.AppSubURL))
+	req.Header.Set("Accept", "image/avif,image/webp,image/apng,image/*,*/*;q=0.8")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "image/webp", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "RIFF", resp.Body.String()[:4])
+
+	req = NewRequest(t, "GET", "/avatars/"+user2.Avatar+"/40")
+	MakeRequest(t, req, http.StatusNotFound)
+
+	// the avatar itself is still served by the static handler
+	req = NewRequest(t, "GET", "/user/avatar/user2/290")
+	resp = MakeRequest(t, req, http.StatusFound)
+	assert.Equal(t, setting.AppSubURL+"/avatars/"+user2.Avatar, resp.Header().Get("Location"))
+	req = NewRequest(t, "GET", "/avatars/"+user2.Avatar)
+	MakeRequest(t, req, http.StatusOK)
+}
@@ -18,8 +18,12 @@ import (
 
 	// register supported doc types
 	_ "code.gitea.io/gitea/modules/markup/csv"
+	_ "code.gitea.io/gitea/modules/markup/geojson"
+	_ "code.gitea.io/gitea/modules/markup/ipynb"
 	_ "code.gitea.io/gitea/modules/markup/markdown"
+	_ "code.gitea.io/gitea/modules/markup/model3d"
 	_ "code.gitea.io/gitea/modules/markup/orgmode"
+	_ "code.gitea.io/gitea/modules/markup/pdf"
 
 	"github.com/urfave/cli"
 )
@@ -59,6 +63,9 @@ arguments - which can alternatively be run by running the subcommand web.`
 		cmd.CmdServ,
 		cmd.CmdHook,
 		cmd.CmdDump,
+		cmd.CmdDumpUsers,
+		cmd.CmdRestoreUsers,
+		cmd.CmdRestoreRepo,
 		cmd.CmdCert,
 		cmd.CmdAdmin,
 		cmd.CmdGenerate,
@@ -204,18 +204,29 @@ func (repo *Repository) refreshAccesses(e Engine, accessMap map[int64]*userAcces
 		})
 	}
 
-	// Delete old accesses and insert new ones for repository.
-	if _, err = e.Delete(&Access{RepoID: repo.ID}); err != nil {
-		return fmt.Errorf("delete old accesses: %v", err)
+	// Forget the cached repositories of the users who lose or gain access.
+	userIDs, err := getRepoAccessUserIDs(e, repo.ID)
+	if err != nil {
+		return fmt.Errorf("getRepoAccessUserIDs: %v", err)
 	}
-	if len(newAccesses) == 0 {
-		return nil
+	for userID := range accessMap {
+		userIDs = append(userIDs, userID)
 	}
 
-	if _, err = e.Insert(newAccesses); err != nil {
-		return fmt.Errorf("insert new accesses: %v", err)
-	}
-	return nil
+	return invalidateAccessibleRepoIDsOnCommit(e, userIDs, func(e Engine) error {
+		// Delete old accesses and insert new ones for repository.
+		if _, err := e.Delete(&Access{RepoID: repo.ID}); err != nil {
+			return fmt.Errorf("delete old accesses: %v", err)
+		}
+		if len(newAccesses) == 0 {
+			return nil
+		}
+
+		if _, err := e.Insert(newAccesses); err != nil {
+			return fmt.Errorf("insert new accesses: %v", err)
+		}
+		return nil
+	})
 }
 
 // refreshCollaboratorAccesses retrieves repository collaborations with their access modes.
@@ -269,6 +280,15 @@ func (repo *Repository) recalculateTeamAccesses(e Engine, ignTeamID int64) (err
 		for _, m := range t.Members {
 			updateUserAccess(accessMap, m, t.Authorize)
 		}
+
+		// The members of the child teams inherit the access of the team
+		inherited, err := t.getInheritedMembers(e)
+		if err != nil {
+			return fmt.Errorf("getInheritedMembers '%d': %v", t.ID, err)
+		}
+		for _, m := range inherited {
+			updateUserAccess(accessMap, m, t.Authorize)
+		}
 	}
 
 	return repo.refreshAccesses(e, accessMap)
@@ -293,13 +313,8 @@ func (repo *Repository) recalculateUserAccess(e Engine, uid int64) (err error) {
 	if err = repo.getOwner(e); err != nil {
 		return err
 	} else if repo.Owner.IsOrganization() {
-		var teams []Team
-		if err := e.Join("INNER", "team_repo", "team_repo.team_id = team.id").
-			Join("INNER", "team_user", "team_user.team_id = team.id").
-			Where("team.org_id = ?", repo.OwnerID).
-			And("team_repo.repo_id=?", repo.ID).
-			And("team_user.uid=?", uid).
-			Find(&teams); err != nil {
+		teams, err := getUserRepoTeams(e, repo.OwnerID, uid, repo.ID)
+		if err != nil {
 			return err
 		}
 
@@ -312,15 +327,17 @@ func (repo *Repository) recalculateUserAccess(e Engine, uid int64) (err error) {
 		}
 	}
 
-	// Delete old user accesses and insert new one for repository.
-	if _, err = e.Delete(&Access{RepoID: repo.ID, UserID: uid}); err != nil {
-		return fmt.Errorf("delete old user accesses: %v", err)
-	} else if accessMode >= minMode {
-		if _, err = e.Insert(&Access{RepoID: repo.ID, UserID: uid, Mode: accessMode}); err != nil {
-			return fmt.Errorf("insert new user accesses: %v", err)
+	return invalidateAccessibleRepoIDsOnCommit(e, []int64{uid}, func(e Engine) error {
+		// Delete old user accesses and insert new one for repository.
+		if _, err := e.Delete(&Access{RepoID: repo.ID, UserID: uid}); err != nil {
+			return fmt.Errorf("delete old user accesses: %v", err)
+		} else if accessMode >= minMode {
+			if _, err = e.Insert(&Access{RepoID: repo.ID, UserID: uid, Mode: accessMode}); err != nil {
+				return fmt.Errorf("insert new user accesses: %v", err)
+			}
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 func (repo *Repository) recalculateAccesses(e Engine) error {
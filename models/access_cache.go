@@ -0,0 +1,184 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// tooManyAccessibleRepoIDs is cached instead of the IDs if a user can access
+// too many repositories to list them as query parameters
+const tooManyAccessibleRepoIDs = "-"
+
+func accessibleRepoIDsCacheKey(userID int64) string {
+	return fmt.Sprintf("AccessibleRepoIDs:%d", userID)
+}
+
+// getAccessibleRepoIDs returns the IDs of the repositories the user can access
+// as collaborator or team member
+func getAccessibleRepoIDs(e Engine, userID int64) ([]int64, error) {
+	accessIDs := make([]int64, 0, 10)
+	if err := e.Table("access").
+		Where("user_id = ? AND mode > ?", userID, AccessModeNone).
+		Cols("repo_id").
+		Find(&accessIDs); err != nil {
+		return nil, err
+	}
+	teamIDs := make([]int64, 0, 10)
+	if err := e.Table("team_repo").
+		Join("INNER", "team_user", "`team_user`.team_id = `team_repo`.team_id").
+		Where("`team_user`.uid = ?", userID).
+		Cols("`team_repo`.repo_id").
+		Find(&teamIDs); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool, len(accessIDs)+len(teamIDs))
+	repoIDs := make([]int64, 0, len(accessIDs)+len(teamIDs))
+	for _, id := range append(accessIDs, teamIDs...) {
+		if !seen[id] {
+			seen[id] = true
+			repoIDs = append(repoIDs, id)
+		}
+	}
+	return repoIDs, nil
+}
+
+// cachedAccessibleRepoIDs returns the IDs of the repositories the user can
+// access as collaborator or team member from the cache. It returns false if
+// the cache is disabled or there are too many IDs to use them in a query.
+func cachedAccessibleRepoIDs(userID int64) ([]int64, bool) {
+	if !setting.CacheService.Enabled {
+		return nil, false
+	}
+
+	value, err := cache.GetString(accessibleRepoIDsCacheKey(userID), func() (string, error) {
+		repoIDs, err := getAccessibleRepoIDs(x, userID)
+		if err != nil {
+			return "", err
+		}
+		if len(repoIDs) > maxQueryParameters {
+			return tooManyAccessibleRepoIDs, nil
+		}
+		ids := make([]string, len(repoIDs))
+		for i, id := range repoIDs {
+			ids[i] = strconv.FormatInt(id, 10)
+		}
+		return strings.Join(ids, ","), nil
+	})
+	if err != nil {
+		log.Error("Unable to get the accessible repositories of user %d: %v", userID, err)
+		return nil, false
+	}
+	if value == tooManyAccessibleRepoIDs {
+		return nil, false
+	}
+
+	repoIDs := make([]int64, 0, strings.Count(value, ",")+1)
+	for _, id := range strings.Split(value, ",") {
+		if len(id) == 0 {
+			continue
+		}
+		repoID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			log.Error("Invalid cached accessible repository ID of user %d: %q", userID, id)
+			return nil, false
+		}
+		repoIDs = append(repoIDs, repoID)
+	}
+	return repoIDs, true
+}
+
+// accessibleRepoIDsCondition returns a condition matching the repositories the
+// user can access as collaborator or team member. It lists the cached IDs of
+// these repositories if possible, so that the database does not need to look
+// them up for every repository.
+func accessibleRepoIDsCondition(userID int64) builder.Cond {
+	if repoIDs, ok := cachedAccessibleRepoIDs(userID); ok {
+		return builder.In("`repository`.id", repoIDs)
+	}
+	return builder.Or(
+		builder.In("`repository`.id", builder.Select("repo_id").
+			From("`access`").
+			Where(builder.And(
+				builder.Eq{"user_id": userID},
+				builder.Gt{"mode": int(AccessModeNone)}))),
+		builder.In("`repository`.id", builder.Select("`team_repo`.repo_id").
+			From("team_repo").
+			Where(builder.Eq{"`team_user`.uid": userID}).
+			Join("INNER", "team_user", "`team_user`.team_id = `team_repo`.team_id")),
+	)
+}
+
+// invalidateAccessibleRepoIDs removes the cached accessible repositories of
+// the users, whose permissions changed
+func invalidateAccessibleRepoIDs(userIDs ...int64) {
+	if !setting.CacheService.Enabled {
+		return
+	}
+	for _, userID := range userIDs {
+		cache.Remove(accessibleRepoIDsCacheKey(userID))
+	}
+}
+
+// invalidateAccessibleRepoIDsOnCommit changes the permissions of the users
+// and removes their cached accessible repositories once the change is
+// committed. Removing them earlier would let other requests cache the old
+// repositories again until the transaction ends.
+func invalidateAccessibleRepoIDsOnCommit(e Engine, userIDs []int64, change func(e Engine) error) error {
+	if sess, ok := e.(*xorm.Session); ok && len(userIDs) > 0 {
+		// The closures of a session run after the commit of its next write
+		sess.After(func(interface{}) {
+			invalidateAccessibleRepoIDs(userIDs...)
+		})
+		return change(sess)
+	}
+	if err := change(e); err != nil {
+		return err
+	}
+	invalidateAccessibleRepoIDs(userIDs...)
+	return nil
+}
+
+// getRepoAccessUserIDs returns the users with access to the repository, whose
+// cached accessible repositories have to be removed
+func getRepoAccessUserIDs(e Engine, repoID int64) ([]int64, error) {
+	if !setting.CacheService.Enabled {
+		return nil, nil
+	}
+	userIDs := make([]int64, 0, 10)
+	if err := e.Table("access").
+		Where("repo_id = ?", repoID).
+		Cols("user_id").
+		Find(&userIDs); err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// getTeamMemberIDs returns the members of the team, whose cached accessible
+// repositories have to be removed
+func getTeamMemberIDs(e Engine, teamID int64) ([]int64, error) {
+	if !setting.CacheService.Enabled {
+		return nil, nil
+	}
+	userIDs := make([]int64, 0, 10)
+	if err := e.Table("team_user").
+		Where("team_id = ?", teamID).
+		Cols("uid").
+		Find(&userIDs); err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
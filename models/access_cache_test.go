@@ -0,0 +1,104 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedAccessibleRepoIDs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	setting.CacheService.Enabled = true
+	setting.CacheService.Adapter = "memory"
+	setting.CacheService.TTL = time.Minute
+	defer func() {
+		// Stop caching for the other tests, which reset the database
+		setting.CacheService.TTL = 0
+	}()
+	assert.NoError(t, cache.NewContext())
+
+	assertCachedIDs := func(userID int64, contains, notContains int64) {
+		repoIDs, ok := cachedAccessibleRepoIDs(userID)
+		assert.True(t, ok)
+		expected, err := getAccessibleRepoIDs(x, userID)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, expected, repoIDs)
+		if contains > 0 {
+			assert.Contains(t, repoIDs, contains)
+		}
+		if notContains > 0 {
+			assert.NotContains(t, repoIDs, notContains)
+		}
+	}
+
+	// Collaboration
+	repo2 := AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	assertCachedIDs(4, 0, 2)
+	assert.NoError(t, repo2.AddCollaborator(user4))
+	assertCachedIDs(4, 2, 0)
+	assert.NoError(t, repo2.DeleteCollaboration(4))
+	assertCachedIDs(4, 0, 2)
+
+	// Team repository
+	team := AssertExistsAndLoadBean(t, &Team{ID: 2}).(*Team)
+	repo5 := AssertExistsAndLoadBean(t, &Repository{ID: 5}).(*Repository)
+	assertCachedIDs(4, 3, 5)
+	assert.NoError(t, team.AddRepository(repo5))
+	assertCachedIDs(4, 5, 0)
+	assert.NoError(t, team.RemoveRepository(5))
+	assertCachedIDs(4, 3, 5)
+
+	// Team membership
+	assert.NoError(t, RemoveTeamMember(team, 4))
+	assertCachedIDs(4, 0, 3)
+	assert.NoError(t, AddTeamMember(team, 4))
+	assertCachedIDs(4, 3, 0)
+}
+
+func TestCachedAccessibleRepoIDsOnCommit(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	setting.CacheService.Enabled = true
+	setting.CacheService.Adapter = "memory"
+	setting.CacheService.TTL = time.Minute
+	defer func() {
+		setting.CacheService.TTL = 0
+	}()
+	assert.NoError(t, cache.NewContext())
+
+	before, ok := cachedAccessibleRepoIDs(4)
+	assert.True(t, ok)
+	assert.NotContains(t, before, int64(2))
+
+	repo2 := AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	sess := x.NewSession()
+	defer sess.Close()
+	assert.NoError(t, sess.Begin())
+	assert.NoError(t, repo2.addCollaborator(sess, user4))
+
+	// A request reading between the change and the commit caches the
+	// committed repositories, the test database cannot be read from another
+	// connection during the transaction though.
+	committed, err := cache.GetString(accessibleRepoIDsCacheKey(4), func() (string, error) {
+		return "3", nil
+	})
+	assert.NoError(t, err)
+	assert.NotContains(t, strings.Split(committed, ","), "2")
+
+	assert.NoError(t, sess.Commit())
+	repoIDs, ok := cachedAccessibleRepoIDs(4)
+	assert.True(t, ok)
+	assert.Contains(t, repoIDs, int64(2))
+}
@@ -5,14 +5,20 @@
 package models
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
 	"path"
+	"strings"
 
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/upload"
 
 	gouuid "github.com/google/uuid"
 	"xorm.io/xorm"
@@ -30,6 +36,9 @@ type Attachment struct {
 	DownloadCount int64              `xorm:"DEFAULT 0"`
 	Size          int64              `xorm:"DEFAULT 0"`
 	CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+	// RetentionNoticeUnix is when the uploader was notified of the deletion of the attachment
+	// of a closed issue
+	RetentionNoticeUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
 }
 
 // IncreaseDownloadCount is update download count + 1
@@ -66,6 +75,38 @@ func (a *Attachment) LocalPath() string {
 	return AttachmentLocalPath(a.UUID)
 }
 
+// ThumbnailPath returns where the thumbnail of an image attachment is stored.
+func (a *Attachment) ThumbnailPath() string {
+	return path.Join(setting.AttachmentThumbnailPath, a.UUID[0:1], a.UUID[1:2], a.UUID)
+}
+
+// HasThumbnail returns true if a thumbnail is shown for the attachment, that is an image
+// which can be resized.
+func (a *Attachment) HasThumbnail() bool {
+	if setting.AttachmentThumbnailSize <= 0 {
+		return false
+	}
+	switch strings.ToLower(path.Ext(a.Name)) {
+	case ".jpg", ".jpeg", ".png", ".gif":
+		return true
+	}
+	return false
+}
+
+// ThumbnailURL returns the url of the thumbnail of an image attachment
+func (a *Attachment) ThumbnailURL() string {
+	return a.DownloadURL() + "/thumbnail"
+}
+
+// IsVideo returns true if the attachment is a video which browsers can play
+func (a *Attachment) IsVideo() bool {
+	switch strings.ToLower(path.Ext(a.Name)) {
+	case ".mp4", ".m4v", ".webm":
+		return true
+	}
+	return false
+}
+
 // DownloadURL returns the download url of the attached file
 func (a *Attachment) DownloadURL() string {
 	return fmt.Sprintf("%sattachments/%s", setting.AppURL, a.UUID)
@@ -99,6 +140,20 @@ func (a *Attachment) LinkedRepository() (*Repository, UnitType, error) {
 func NewAttachment(attach *Attachment, buf []byte, file io.Reader) (_ *Attachment, err error) {
 	attach.UUID = gouuid.New().String()
 
+	if setting.AttachmentStripMetadata {
+		if contentType := http.DetectContentType(buf); contentType == "image/jpeg" || contentType == "image/png" {
+			data, err := ioutil.ReadAll(io.MultiReader(bytes.NewReader(buf), file))
+			if err != nil {
+				return nil, fmt.Errorf("ReadAll: %v", err)
+			}
+			// the image is refused rather than stored with its metadata
+			if buf, err = upload.StripImageMetadata(data); err != nil {
+				return nil, err
+			}
+			file = bytes.NewReader(nil)
+		}
+	}
+
 	localPath := attach.LocalPath()
 	if err = os.MkdirAll(path.Dir(localPath), os.ModePerm); err != nil {
 		return nil, fmt.Errorf("MkdirAll: %v", err)
@@ -240,6 +295,9 @@ func DeleteAttachments(attachments []*Attachment, remove bool) (int, error) {
 			if err := os.Remove(a.LocalPath()); err != nil {
 				return i, err
 			}
+			if err := os.Remove(a.ThumbnailPath()); err != nil && !os.IsNotExist(err) {
+				log.Error("Remove thumbnail of attachment %s: %v", a.UUID, err)
+			}
 		}
 	}
 	return int(cnt), nil
@@ -0,0 +1,247 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/upload"
+
+	"xorm.io/builder"
+)
+
+// AttachmentPolicy are the limits an owner sets on the attachments of the issues and pull
+// requests of its repositories, they can only be stricter than the limits of the instance.
+type AttachmentPolicy struct {
+	ID      int64 `xorm:"pk autoincr"`
+	OwnerID int64 `xorm:"UNIQUE NOT NULL"`
+	// The sizes are in MB, 0 keeps the limit of the instance
+	MaxSize       int64  `xorm:"NOT NULL DEFAULT 0"`
+	MaxSizeByType string `xorm:"TEXT"`
+	MaxIssueSize  int64  `xorm:"NOT NULL DEFAULT 0"`
+	// ClosedIssueRetention is the number of days the attachments of a closed issue are kept
+	ClosedIssueRetention int `xorm:"NOT NULL DEFAULT 0"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+
+	typeLimits upload.TypeSizeLimits `xorm:"-"`
+}
+
+// ErrAttachmentPolicyInvalid represents an invalid size limit by type of an attachment policy.
+type ErrAttachmentPolicyInvalid struct {
+	Err error
+}
+
+// IsErrAttachmentPolicyInvalid checks if an error is a ErrAttachmentPolicyInvalid.
+func IsErrAttachmentPolicyInvalid(err error) bool {
+	_, ok := err.(ErrAttachmentPolicyInvalid)
+	return ok
+}
+
+func (err ErrAttachmentPolicyInvalid) Error() string {
+	return fmt.Sprintf("invalid attachment policy: %v", err.Err)
+}
+
+// ErrIssueAttachmentsTooLarge represents attachments exceeding the total size allowed for an issue.
+type ErrIssueAttachmentsTooLarge struct {
+	IssueID int64
+	Size    int64
+	MaxSize int64
+}
+
+// IsErrIssueAttachmentsTooLarge checks if an error is a ErrIssueAttachmentsTooLarge.
+func IsErrIssueAttachmentsTooLarge(err error) bool {
+	_, ok := err.(ErrIssueAttachmentsTooLarge)
+	return ok
+}
+
+func (err ErrIssueAttachmentsTooLarge) Error() string {
+	return fmt.Sprintf("attachments of the issue are too large [issue_id: %d, size: %d, max_size: %d]", err.IssueID, err.Size, err.MaxSize)
+}
+
+const megabyte = 1024 * 1024
+
+// CheckFile checks a file of the content type and size in bytes can be attached.
+func (p *AttachmentPolicy) CheckFile(contentType string, size int64) error {
+	maxSize := stricterLimit(p.MaxSize, p.typeLimits.Limit(contentType))
+	if maxSize > 0 && size > maxSize*megabyte {
+		return upload.ErrFileTooLarge{Type: contentType, Size: size, MaxSize: maxSize * megabyte}
+	}
+	return nil
+}
+
+// RetentionDeadline returns when the attachments of an issue closed at closedUnix are deleted,
+// 0 if they are kept.
+func (p *AttachmentPolicy) RetentionDeadline(closedUnix timeutil.TimeStamp) timeutil.TimeStamp {
+	if p.ClosedIssueRetention <= 0 || closedUnix == 0 {
+		return 0
+	}
+	return closedUnix.AddDuration(time.Duration(p.ClosedIssueRetention) * 24 * time.Hour)
+}
+
+// stricterLimit returns the lowest of two limits, a limit of 0 or less is no limit
+func stricterLimit(a, b int64) int64 {
+	if a <= 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// GetAttachmentPolicy returns the attachment policy of the owner, it is empty if the owner
+// did not define any.
+func GetAttachmentPolicy(ownerID int64) (*AttachmentPolicy, error) {
+	p := &AttachmentPolicy{OwnerID: ownerID}
+	if _, err := x.Where("owner_id = ?", ownerID).Get(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SaveAttachmentPolicy creates or updates the attachment policy of the owner.
+func SaveAttachmentPolicy(p *AttachmentPolicy) error {
+	limits, err := upload.ParseTypeSizeLimits(p.MaxSizeByType)
+	if err != nil {
+		return ErrAttachmentPolicyInvalid{err}
+	}
+	p.MaxSizeByType = limits.String()
+	if p.MaxSize < 0 {
+		p.MaxSize = 0
+	}
+	if p.MaxIssueSize < 0 {
+		p.MaxIssueSize = 0
+	}
+	if p.ClosedIssueRetention < 0 {
+		p.ClosedIssueRetention = 0
+	}
+
+	if p.ID == 0 {
+		_, err = x.Insert(p)
+		return err
+	}
+	_, err = x.ID(p.ID).AllCols().Update(p)
+	return err
+}
+
+func deleteAttachmentPolicy(e Engine, ownerID int64) error {
+	_, err := e.Where("owner_id = ?", ownerID).Delete(new(AttachmentPolicy))
+	return err
+}
+
+// GetEffectiveAttachmentPolicy returns the limits applied to the attachments of the owner, the
+// stricter of the limits of the instance and of the owner.
+func GetEffectiveAttachmentPolicy(ownerID int64) (*AttachmentPolicy, error) {
+	return getEffectiveAttachmentPolicy(x, ownerID)
+}
+
+func getEffectiveAttachmentPolicy(e Engine, ownerID int64) (*AttachmentPolicy, error) {
+	owned := new(AttachmentPolicy)
+	if ownerID > 0 {
+		if _, err := e.Where("owner_id = ?", ownerID).Get(owned); err != nil {
+			return nil, err
+		}
+	}
+
+	limits, err := upload.ParseTypeSizeLimits(setting.AttachmentMaxSizeByType)
+	if err != nil {
+		log.Error("Invalid [attachment] MAX_SIZE_BY_TYPE: %v", err)
+		limits = make(upload.TypeSizeLimits)
+	}
+	ownedLimits, err := upload.ParseTypeSizeLimits(owned.MaxSizeByType)
+	if err != nil {
+		log.Error("Invalid attachment size limits of owner %d: %v", ownerID, err)
+	}
+	for typ, size := range ownedLimits {
+		limits[typ] = stricterLimit(limits[typ], size)
+	}
+
+	return &AttachmentPolicy{
+		OwnerID:              ownerID,
+		MaxSize:              stricterLimit(setting.AttachmentMaxSize, owned.MaxSize),
+		MaxSizeByType:        limits.String(),
+		MaxIssueSize:         stricterLimit(setting.AttachmentMaxIssueSize, owned.MaxIssueSize),
+		ClosedIssueRetention: int(stricterLimit(int64(setting.AttachmentClosedIssueRetention), int64(owned.ClosedIssueRetention))),
+		typeLimits:           limits,
+	}, nil
+}
+
+// checkIssueAttachmentsSize checks the total size of the attachments of the issue stays
+// within the limit of its owner once the attachments are linked to it.
+func checkIssueAttachmentsSize(e Engine, issue *Issue, attachments []*Attachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+	if err := issue.loadRepo(e); err != nil {
+		return err
+	}
+	policy, err := getEffectiveAttachmentPolicy(e, issue.Repo.OwnerID)
+	if err != nil {
+		return err
+	}
+	if policy.MaxIssueSize <= 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(attachments))
+	var size int64
+	for _, a := range attachments {
+		ids = append(ids, a.ID)
+		size += a.Size
+	}
+	linked, err := e.Where("issue_id = ?", issue.ID).NotIn("id", ids).SumInt(new(Attachment), "size")
+	if err != nil {
+		return err
+	}
+	if size += linked; size > policy.MaxIssueSize*megabyte {
+		return ErrIssueAttachmentsTooLarge{IssueID: issue.ID, Size: size, MaxSize: policy.MaxIssueSize * megabyte}
+	}
+	return nil
+}
+
+// GetMinAttachmentRetention returns the shortest retention in days of the attachments of
+// closed issues among the instance and the owners, 0 if their attachments are all kept.
+func GetMinAttachmentRetention() (int, error) {
+	var retentions []int
+	if err := x.Table("attachment_policy").Where("closed_issue_retention > 0").
+		Cols("closed_issue_retention").Find(&retentions); err != nil {
+		return 0, err
+	}
+	min := int64(setting.AttachmentClosedIssueRetention)
+	for _, retention := range retentions {
+		min = stricterLimit(min, int64(retention))
+	}
+	return int(min), nil
+}
+
+// FindClosedIssueAttachments returns the attachments of the issues closed before the time,
+// by issue.
+func FindClosedIssueAttachments(closedBefore timeutil.TimeStamp) ([]*Attachment, error) {
+	attachments := make([]*Attachment, 0, 10)
+	return attachments, x.Join("INNER", "issue", "issue.id = attachment.issue_id").
+		Where(builder.Eq{"issue.is_closed": true}.
+			And(builder.Gt{"issue.closed_unix": 0}).
+			And(builder.Lt{"issue.closed_unix": closedBefore})).
+		OrderBy("attachment.issue_id, attachment.id").
+		Find(&attachments)
+}
+
+// SetAttachmentsRetentionNotice records the uploaders of the attachments have been notified
+// of their deletion.
+func SetAttachmentsRetentionNotice(attachments []*Attachment, notified timeutil.TimeStamp) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(attachments))
+	for _, a := range attachments {
+		a.RetentionNoticeUnix = notified
+		ids = append(ids, a.ID)
+	}
+	_, err := x.In("id", ids).Cols("retention_notice_unix").Update(&Attachment{RetentionNoticeUnix: notified})
+	return err
+}
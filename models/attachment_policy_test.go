@@ -0,0 +1,125 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/upload"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEffectiveAttachmentPolicy(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(maxSize int64, byType string, issueSize int64, retention int) {
+		setting.AttachmentMaxSize = maxSize
+		setting.AttachmentMaxSizeByType = byType
+		setting.AttachmentMaxIssueSize = issueSize
+		setting.AttachmentClosedIssueRetention = retention
+	}(setting.AttachmentMaxSize, setting.AttachmentMaxSizeByType, setting.AttachmentMaxIssueSize, setting.AttachmentClosedIssueRetention)
+	setting.AttachmentMaxSize = 4
+	setting.AttachmentMaxSizeByType = "image/*:2,video/mp4:3"
+	setting.AttachmentMaxIssueSize = 10
+	setting.AttachmentClosedIssueRetention = 0
+
+	policy, err := GetEffectiveAttachmentPolicy(3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, policy.MaxSize)
+	assert.Equal(t, "image/*:2,video/mp4:3", policy.MaxSizeByType)
+	assert.EqualValues(t, 10, policy.MaxIssueSize)
+	assert.EqualValues(t, 0, policy.ClosedIssueRetention)
+
+	// the policy of the owner can only be stricter
+	assert.NoError(t, SaveAttachmentPolicy(&AttachmentPolicy{
+		OwnerID:              3,
+		MaxSize:              8,
+		MaxSizeByType:        " image/png:1, image/*:5 ,text/plain:1",
+		MaxIssueSize:         6,
+		ClosedIssueRetention: 30,
+	}))
+	saved, err := GetAttachmentPolicy(3)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/*:5,image/png:1,text/plain:1", saved.MaxSizeByType)
+
+	policy, err = GetEffectiveAttachmentPolicy(3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, policy.MaxSize)
+	assert.Equal(t, "image/*:2,image/png:1,text/plain:1,video/mp4:3", policy.MaxSizeByType)
+	assert.EqualValues(t, 6, policy.MaxIssueSize)
+	assert.EqualValues(t, 30, policy.ClosedIssueRetention)
+
+	assert.NoError(t, policy.CheckFile("image/png", 1024*1024))
+	assert.True(t, upload.IsErrFileTooLarge(policy.CheckFile("image/png", 1024*1024+1)))
+	assert.True(t, upload.IsErrFileTooLarge(policy.CheckFile("image/jpeg", 3*1024*1024)))
+	assert.NoError(t, policy.CheckFile("application/zip", 4*1024*1024))
+	assert.True(t, upload.IsErrFileTooLarge(policy.CheckFile("application/zip", 4*1024*1024+1)))
+
+	assert.EqualValues(t, 0, policy.RetentionDeadline(0))
+	assert.EqualValues(t, 1000+30*24*3600, policy.RetentionDeadline(1000))
+
+	// the other owners keep the limits of the instance
+	retention, err := GetMinAttachmentRetention()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 30, retention)
+	setting.AttachmentClosedIssueRetention = 60
+	policy, err = GetEffectiveAttachmentPolicy(2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 60, policy.ClosedIssueRetention)
+	retention, err = GetMinAttachmentRetention()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 30, retention)
+
+	assert.True(t, IsErrAttachmentPolicyInvalid(SaveAttachmentPolicy(&AttachmentPolicy{OwnerID: 3, MaxSizeByType: "image/png"})))
+}
+
+func TestIssueAttachmentsTotalSize(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(issueSize int64) {
+		setting.AttachmentMaxIssueSize = issueSize
+	}(setting.AttachmentMaxIssueSize)
+	setting.AttachmentMaxIssueSize = 1
+
+	// attachment 1 is on issue 1, 8 is on issue 6 and 9 on a release
+	_, err := x.In("id", []int64{1, 8, 9}).Cols("size").Update(&Attachment{Size: 400 * 1024})
+	assert.NoError(t, err)
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	// attachments already linked to the issue are not counted twice
+	assert.NoError(t, issue.UpdateAttachments([]string{"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a18"}))
+	AssertExistsAndLoadBean(t, &Attachment{ID: 8, IssueID: 1})
+
+	err = issue.UpdateAttachments([]string{"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a19"})
+	assert.True(t, IsErrIssueAttachmentsTooLarge(err))
+	assert.EqualValues(t, 0, AssertExistsAndLoadBean(t, &Attachment{ID: 9}).(*Attachment).IssueID)
+
+	setting.AttachmentMaxIssueSize = 0
+	assert.NoError(t, issue.UpdateAttachments([]string{"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a19"}))
+}
+
+func TestFindClosedIssueAttachments(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// issue 5 is closed with attachments 6 and 7
+	_, err := x.ID(5).Cols("closed_unix").Update(&Issue{ClosedUnix: 1000})
+	assert.NoError(t, err)
+
+	attachments, err := FindClosedIssueAttachments(1000)
+	assert.NoError(t, err)
+	assert.Len(t, attachments, 0)
+
+	attachments, err = FindClosedIssueAttachments(1001)
+	assert.NoError(t, err)
+	if assert.Len(t, attachments, 2) {
+		assert.EqualValues(t, 6, attachments[0].ID)
+		assert.EqualValues(t, 7, attachments[1].ID)
+	}
+
+	assert.NoError(t, SetAttachmentsRetentionNotice(attachments, timeutil.TimeStamp(2000)))
+	AssertExistsAndLoadBean(t, &Attachment{ID: 6, RetentionNoticeUnix: 2000})
+	AssertExistsAndLoadBean(t, &Attachment{ID: 7, RetentionNoticeUnix: 2000})
+}
@@ -5,10 +5,14 @@
 package models
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/upload"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,6 +41,15 @@ func TestUploadAttachment(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, user.ID, attachment.UploaderID)
 	assert.Equal(t, int64(0), attachment.DownloadCount)
+
+	// an image whose metadata cannot be removed is refused
+	defer func(strip bool) { setting.AttachmentStripMetadata = strip }(setting.AttachmentStripMetadata)
+	setting.AttachmentStripMetadata = true
+	_, err = NewAttachment(&Attachment{
+		UploaderID: user.ID,
+		Name:       "photo.jpg",
+	}, []byte{0xff, 0xd8, 0xff, 0xe1, 0x40, 0x00, 'E', 'x', 'i', 'f'}, bytes.NewReader(nil))
+	assert.True(t, upload.IsErrMalformedImage(err))
 }
 
 func TestIncreaseDownloadCount(t *testing.T) {
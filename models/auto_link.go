@@ -0,0 +1,237 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MaxAutoLinkRules is the maximum number of auto-link rules of an organization or a repository
+const MaxAutoLinkRules = 20
+
+// AutoLinkRule links the text matching a pattern, e.g. JIRA-\d+, to an URL in the
+// rendered issues, pull requests and commit messages of an organization or a
+// repository. The rules of a repository take precedence over the ones of its
+// organization, and the rules of a same owner are applied by Sort then ID.
+type AutoLinkRule struct {
+	ID     int64 `xorm:"pk autoincr"`
+	OrgID  int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	RepoID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	// Pattern is a regular expression
+	Pattern string `xorm:"VARCHAR(255) NOT NULL"`
+	// URL is the link of the matched text, see markup.AutoLinkRule for its variables
+	URL  string `xorm:"TEXT NOT NULL"`
+	Sort int    `xorm:"NOT NULL DEFAULT 0"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// ErrAutoLinkRuleNotExist represents a "AutoLinkRuleNotExist" kind of error.
+type ErrAutoLinkRuleNotExist struct {
+	ID int64
+}
+
+// IsErrAutoLinkRuleNotExist checks if an error is a ErrAutoLinkRuleNotExist.
+func IsErrAutoLinkRuleNotExist(err error) bool {
+	_, ok := err.(ErrAutoLinkRuleNotExist)
+	return ok
+}
+
+func (err ErrAutoLinkRuleNotExist) Error() string {
+	return fmt.Sprintf("auto-link rule does not exist [id: %d]", err.ID)
+}
+
+// ErrAutoLinkRuleInvalid represents an auto-link rule with an invalid pattern or URL.
+type ErrAutoLinkRuleInvalid struct {
+	Field string
+	Value string
+	Err   error
+}
+
+// IsErrAutoLinkRuleInvalid checks if an error is a ErrAutoLinkRuleInvalid.
+func IsErrAutoLinkRuleInvalid(err error) bool {
+	_, ok := err.(ErrAutoLinkRuleInvalid)
+	return ok
+}
+
+func (err ErrAutoLinkRuleInvalid) Error() string {
+	return fmt.Sprintf("invalid auto-link rule [%s: %s]: %v", err.Field, err.Value, err.Err)
+}
+
+// ErrAutoLinkRulesLimit represents an owner having the maximum number of auto-link rules.
+type ErrAutoLinkRulesLimit struct {
+	OrgID  int64
+	RepoID int64
+}
+
+// IsErrAutoLinkRulesLimit checks if an error is a ErrAutoLinkRulesLimit.
+func IsErrAutoLinkRulesLimit(err error) bool {
+	_, ok := err.(ErrAutoLinkRulesLimit)
+	return ok
+}
+
+func (err ErrAutoLinkRulesLimit) Error() string {
+	return fmt.Sprintf("reached the limit of %d auto-link rules [org_id: %d, repo_id: %d]", MaxAutoLinkRules, err.OrgID, err.RepoID)
+}
+
+// IsOrgRule returns true if the rule belongs to an organization.
+func (r *AutoLinkRule) IsOrgRule() bool {
+	return r.OrgID > 0
+}
+
+func (r *AutoLinkRule) toMarkup() markup.AutoLinkRule {
+	return markup.AutoLinkRule{Pattern: r.Pattern, URL: r.URL}
+}
+
+// normalize trims the fields of the rule and checks they are valid
+func (r *AutoLinkRule) normalize() error {
+	r.Pattern = strings.TrimSpace(r.Pattern)
+	r.URL = strings.TrimSpace(r.URL)
+	if r.Pattern == "" || len(r.Pattern) > 255 {
+		return ErrAutoLinkRuleInvalid{Field: "pattern", Value: r.Pattern, Err: fmt.Errorf("length must be between 1 and 255")}
+	}
+	if err := markup.ValidateAutoLinkRule(markup.AutoLinkRule{Pattern: r.Pattern, URL: "https://example.com"}); err != nil {
+		return ErrAutoLinkRuleInvalid{Field: "pattern", Value: r.Pattern, Err: err}
+	}
+	if err := markup.ValidateAutoLinkRule(r.toMarkup()); err != nil {
+		return ErrAutoLinkRuleInvalid{Field: "url", Value: r.URL, Err: err}
+	}
+	return nil
+}
+
+// NewAutoLinkRule adds an auto-link rule to an organization or a repository.
+func NewAutoLinkRule(r *AutoLinkRule) error {
+	if err := r.normalize(); err != nil {
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	count, err := sess.Where("org_id = ? AND repo_id = ?", r.OrgID, r.RepoID).Count(new(AutoLinkRule))
+	if err != nil {
+		return err
+	} else if count >= MaxAutoLinkRules {
+		return ErrAutoLinkRulesLimit{OrgID: r.OrgID, RepoID: r.RepoID}
+	}
+	if _, err = sess.Insert(r); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func getAutoLinkRule(orgID, repoID, id int64) (*AutoLinkRule, error) {
+	r := new(AutoLinkRule)
+	has, err := x.ID(id).Where("org_id = ? AND repo_id = ?", orgID, repoID).Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrAutoLinkRuleNotExist{ID: id}
+	}
+	return r, nil
+}
+
+// GetOrgAutoLinkRule returns an auto-link rule of an organization.
+func GetOrgAutoLinkRule(orgID, id int64) (*AutoLinkRule, error) {
+	return getAutoLinkRule(orgID, 0, id)
+}
+
+// GetRepoAutoLinkRule returns an auto-link rule of a repository.
+func GetRepoAutoLinkRule(repoID, id int64) (*AutoLinkRule, error) {
+	return getAutoLinkRule(0, repoID, id)
+}
+
+func getAutoLinkRules(e Engine, orgID, repoID int64) ([]*AutoLinkRule, error) {
+	rules := make([]*AutoLinkRule, 0, 5)
+	return rules, e.Where("org_id = ? AND repo_id = ?", orgID, repoID).Asc("sort", "id").Find(&rules)
+}
+
+// GetOrgAutoLinkRules returns the auto-link rules of an organization in their order.
+func GetOrgAutoLinkRules(orgID int64) ([]*AutoLinkRule, error) {
+	return getAutoLinkRules(x, orgID, 0)
+}
+
+// GetRepoAutoLinkRules returns the auto-link rules of a repository in their order,
+// without the ones of its organization.
+func GetRepoAutoLinkRules(repoID int64) ([]*AutoLinkRule, error) {
+	return getAutoLinkRules(x, 0, repoID)
+}
+
+func getEffectiveAutoLinkRules(e Engine, repo *Repository) ([]*AutoLinkRule, error) {
+	rules, err := getAutoLinkRules(e, 0, repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.getOwner(e); err != nil {
+		return nil, err
+	}
+	if !repo.Owner.IsOrganization() {
+		return rules, nil
+	}
+	orgRules, err := getAutoLinkRules(e, repo.OwnerID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// a rule of the repository replaces the rule of the organization with the same pattern
+	patterns := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		patterns[r.Pattern] = true
+	}
+	for _, r := range orgRules {
+		if !patterns[r.Pattern] {
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+// GetEffectiveAutoLinkRules returns the auto-link rules applied in a repository
+// by precedence: the rules of the repository, then the ones of its organization
+// whose pattern is not used by a rule of the repository.
+func GetEffectiveAutoLinkRules(repo *Repository) ([]*AutoLinkRule, error) {
+	return getEffectiveAutoLinkRules(x, repo)
+}
+
+// composeAutoLinkMetas returns the auto-link rules of a repository encoded for the metas
+func composeAutoLinkMetas(e Engine, repo *Repository) (string, error) {
+	rules, err := getEffectiveAutoLinkRules(e, repo)
+	if err != nil {
+		return "", err
+	}
+	markupRules := make([]markup.AutoLinkRule, len(rules))
+	for i, r := range rules {
+		markupRules[i] = r.toMarkup()
+	}
+	return markup.EncodeAutoLinkRules(markupRules), nil
+}
+
+// UpdateAutoLinkRule updates an auto-link rule.
+func UpdateAutoLinkRule(r *AutoLinkRule) error {
+	if err := r.normalize(); err != nil {
+		return err
+	}
+	_, err := x.ID(r.ID).Cols("pattern", "url", "sort").Update(r)
+	return err
+}
+
+// DeleteOrgAutoLinkRule deletes an auto-link rule of an organization.
+func DeleteOrgAutoLinkRule(orgID, id int64) error {
+	_, err := x.Where("id = ? AND org_id = ? AND repo_id = 0", id, orgID).Delete(new(AutoLinkRule))
+	return err
+}
+
+// DeleteRepoAutoLinkRule deletes an auto-link rule of a repository.
+func DeleteRepoAutoLinkRule(repoID, id int64) error {
+	_, err := x.Where("id = ? AND org_id = 0 AND repo_id = ?", id, repoID).Delete(new(AutoLinkRule))
+	return err
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEffectiveAutoLinkRules(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// the rule of repo3 replaces the rule of its organization with the same pattern
+	repo3 := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	rules, err := GetEffectiveAutoLinkRules(repo3)
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 2) {
+		assert.EqualValues(t, 3, rules[0].ID)
+		assert.EqualValues(t, 1, rules[1].ID)
+		assert.True(t, rules[1].IsOrgRule())
+	}
+
+	repo5 := AssertExistsAndLoadBean(t, &Repository{ID: 5}).(*Repository)
+	rules, err = GetEffectiveAutoLinkRules(repo5)
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 2) {
+		assert.EqualValues(t, 1, rules[0].ID)
+		assert.EqualValues(t, 2, rules[1].ID)
+	}
+
+	// the rules of users apply to repositories of organizations only
+	repo1 := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	rules, err = GetEffectiveAutoLinkRules(repo1)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 0)
+	_, has := repo1.ComposeMetas()["autolinks"]
+	assert.False(t, has)
+
+	assert.Contains(t, repo3.ComposeMetas()["autolinks"], "https://ops.example.com/repo3/tickets/$1")
+}
+
+func TestNewAutoLinkRule(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	r := &AutoLinkRule{RepoID: 1, Pattern: ` \bBUG-(\d+)\b `, URL: "https://bugs.example.com/show?id=$1"}
+	assert.NoError(t, NewAutoLinkRule(r))
+	r = AssertExistsAndLoadBean(t, &AutoLinkRule{ID: r.ID, RepoID: 1, Pattern: `\bBUG-(\d+)\b`}).(*AutoLinkRule)
+
+	r.Sort = 2
+	r.URL = "https://bugs.example.com/$1"
+	assert.NoError(t, UpdateAutoLinkRule(r))
+	AssertExistsAndLoadBean(t, &AutoLinkRule{ID: r.ID, URL: "https://bugs.example.com/$1", Sort: 2})
+
+	for _, invalid := range []*AutoLinkRule{
+		{RepoID: 1, Pattern: " ", URL: "https://bugs.example.com/$0"},
+		{RepoID: 1, Pattern: `BUG-(\d+`, URL: "https://bugs.example.com/$0"},
+		{RepoID: 1, Pattern: `\d*`, URL: "https://bugs.example.com/$0"},
+		{RepoID: 1, Pattern: `BUG-\d+`, URL: "javascript:alert('$0')"},
+	} {
+		assert.True(t, IsErrAutoLinkRuleInvalid(NewAutoLinkRule(invalid)))
+	}
+
+	for i := 1; i < MaxAutoLinkRules; i++ {
+		assert.NoError(t, NewAutoLinkRule(&AutoLinkRule{RepoID: 1, Pattern: fmt.Sprintf(`P%d-\d+`, i), URL: "https://example.com/$0"}))
+	}
+	assert.True(t, IsErrAutoLinkRulesLimit(NewAutoLinkRule(&AutoLinkRule{RepoID: 1, Pattern: `TOO-\d+`, URL: "https://example.com/$0"})))
+	// the limit is per owner
+	assert.NoError(t, NewAutoLinkRule(&AutoLinkRule{RepoID: 2, Pattern: `TOO-\d+`, URL: "https://example.com/$0"}))
+
+	assert.NoError(t, DeleteRepoAutoLinkRule(1, r.ID))
+	AssertNotExistsBean(t, &AutoLinkRule{ID: r.ID})
+	// the rules of organizations are not deleted through a repository
+	assert.NoError(t, DeleteRepoAutoLinkRule(3, 1))
+	AssertExistsAndLoadBean(t, &AutoLinkRule{ID: 1})
+	_, err := GetRepoAutoLinkRule(3, 1)
+	assert.True(t, IsErrAutoLinkRuleNotExist(err))
+	_, err = GetOrgAutoLinkRule(3, 1)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// likePrefixEscaper escapes the wildcards of a LIKE pattern with the escape character of
+// likePrefix, which every database accepts unlike the backslash
+var likePrefixEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// likePrefix returns the condition matching the values of the column starting with the
+// prefix, the wildcards of the prefix are escaped so that they match themselves.
+func likePrefix(col, prefix string) builder.Cond {
+	return builder.Expr(col+" LIKE ? ESCAPE '!'", likePrefixEscaper.Replace(prefix)+"%")
+}
+
+// SearchMentionableUsers returns the users whose name starts with the prefix and who can be
+// mentioned in the repository, that is its owner and the users with access to it. The
+// prefix is matched against the unique index of the lower names, so large instances only
+// read the matching users.
+func SearchMentionableUsers(repo *Repository, prefix string, limit int) ([]*User, error) {
+	prefix = strings.ToLower(prefix)
+	cond := builder.NewCond().
+		And(builder.Eq{"type": UserTypeIndividual}).
+		And(builder.Eq{"is_active": true}).
+		And(builder.Or(
+			builder.Eq{"id": repo.OwnerID},
+			builder.In("id", builder.Select("user_id").From("access").
+				Where(builder.Eq{"repo_id": repo.ID}.And(builder.Gte{"mode": AccessModeRead}))),
+		))
+	if len(prefix) > 0 {
+		cond = cond.And(likePrefix("lower_name", prefix))
+	}
+
+	users := make([]*User, 0, limit)
+	if err := x.Where(cond).OrderBy("lower_name").Limit(limit).Find(&users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// indexPrefixConds returns the conditions matching the issue indexes starting with the digits
+// of the prefix, like 12, 120 to 129 and 1200 to 1299 for 12, up to the last index. Each range
+// is read from the unique index of the repository and issue index.
+func indexPrefixConds(prefix int64, maxIndex int64) builder.Cond {
+	cond := builder.NewCond()
+	for low, width := prefix, int64(1); low > 0 && low <= maxIndex; low, width = low*10, width*10 {
+		cond = cond.Or(builder.Between{Col: "`index`", LessVal: low, MoreVal: low + width - 1})
+	}
+	return cond
+}
+
+// SearchIssuesByIndexPrefix returns the issues of the repository whose index starts with the
+// digits of the prefix, smallest indexes first. An empty prefix returns the issues updated last.
+// isPull limits the results to the issues or pull requests.
+func SearchIssuesByIndexPrefix(repoID int64, prefix string, isPull util.OptionalBool, limit int) ([]*Issue, error) {
+	cond := builder.NewCond().And(builder.Eq{"repo_id": repoID})
+	if !isPull.IsNone() {
+		cond = cond.And(builder.Eq{"is_pull": isPull.IsTrue()})
+	}
+
+	issues := make([]*Issue, 0, limit)
+	if len(prefix) == 0 {
+		return issues, x.Where(cond).OrderBy("updated_unix DESC").Limit(limit).Find(&issues)
+	}
+
+	index, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil || index <= 0 || prefix[0] == '0' {
+		return issues, nil
+	}
+	var maxIndex int64
+	if _, err := x.Table("issue").Where("repo_id = ?", repoID).Select("coalesce(MAX(`index`),0)").Get(&maxIndex); err != nil {
+		return nil, err
+	}
+	if index > maxIndex {
+		return issues, nil
+	}
+
+	return issues, x.Where(cond.And(indexPrefixConds(index, maxIndex))).OrderBy("`index`").Limit(limit).Find(&issues)
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+)
+
+func TestSearchMentionableUsers(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	// user2 and user4 have access to repo3 of the organization user3
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+
+	users, err := SearchMentionableUsers(repo, "", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, users, 2) {
+		assert.EqualValues(t, 2, users[0].ID)
+		assert.EqualValues(t, 4, users[1].ID)
+	}
+
+	users, err = SearchMentionableUsers(repo, "User4", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, users, 1) {
+		assert.EqualValues(t, 4, users[0].ID)
+	}
+
+	users, err = SearchMentionableUsers(repo, "user5", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+
+	// the owner of a repository of a user can be mentioned
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	users, err = SearchMentionableUsers(repo, "us", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, users, 1) {
+		assert.EqualValues(t, 2, users[0].ID)
+	}
+
+	// the wildcards of the prefix match themselves
+	user := &User{Name: "user_2", LowerName: "user_2", Email: "user_2@example.com", IsActive: true}
+	_, err = x.Insert(user)
+	assert.NoError(t, err)
+	_, err = x.Insert(&Access{UserID: user.ID, RepoID: repo.ID, Mode: AccessModeRead})
+	assert.NoError(t, err)
+	for _, prefix := range []string{"user_", "USER_2"} {
+		users, err = SearchMentionableUsers(repo, prefix, 10)
+		assert.NoError(t, err)
+		if assert.Len(t, users, 1, prefix) {
+			assert.EqualValues(t, user.ID, users[0].ID)
+		}
+	}
+	users, err = SearchMentionableUsers(repo, "user%", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestSearchIssuesByIndexPrefix(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issues, err := SearchIssuesByIndexPrefix(1, "2", util.OptionalBoolNone, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 1) {
+		assert.EqualValues(t, 2, issues[0].Index)
+	}
+
+	issues, err = SearchIssuesByIndexPrefix(1, "", util.OptionalBoolFalse, 10)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, issues)
+	for _, issue := range issues {
+		assert.False(t, issue.IsPull)
+	}
+
+	issues, err = SearchIssuesByIndexPrefix(1, "2", util.OptionalBoolFalse, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+
+	for _, prefix := range []string{"9", "0", "02", "abc"} {
+		issues, err = SearchIssuesByIndexPrefix(1, prefix, util.OptionalBoolNone, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, issues, prefix)
+	}
+}
+
+func TestIndexPrefixConds(t *testing.T) {
+	_, args, err := builder.ToSQL(indexPrefixConds(12, 1500))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []interface{}{int64(12), int64(12), int64(120), int64(129), int64(1200), int64(1299)}, args)
+}
@@ -12,11 +12,13 @@ import (
 
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 
 	"github.com/gobwas/glob"
 	"github.com/unknwon/com"
+	"xorm.io/builder"
 )
 
 const (
@@ -50,6 +52,13 @@ type ProtectedBranch struct {
 	DismissStaleApprovals     bool     `xorm:"NOT NULL DEFAULT false"`
 	RequireSignedCommits      bool     `xorm:"NOT NULL DEFAULT false"`
 	ProtectedFilePatterns     string   `xorm:"TEXT"`
+	SignedCommitsAllowedKeys  string   `xorm:"TEXT"`
+	// MergeStyles are the merge styles allowed into the branch, all the ones of the
+	// repository are allowed if empty
+	MergeStyles []string `xorm:"JSON TEXT"`
+
+	// Rulesets are the rulesets of the organization applied to the branch
+	Rulesets []*OrgRuleset `xorm:"-"`
 
 	CreatedUnix timeutil.TimeStamp `xorm:"created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
@@ -57,7 +66,17 @@ type ProtectedBranch struct {
 
 // IsProtected returns if the branch is protected
 func (protectBranch *ProtectedBranch) IsProtected() bool {
-	return protectBranch.ID > 0
+	return protectBranch.ID > 0 || len(protectBranch.Rulesets) > 0
+}
+
+// IsPushRestrictedByRulesets returns if a ruleset applied to the branch restricts pushes
+func (protectBranch *ProtectedBranch) IsPushRestrictedByRulesets() bool {
+	for _, r := range protectBranch.Rulesets {
+		if r.RestrictPush {
+			return true
+		}
+	}
+	return false
 }
 
 // CanUserPush returns if some user could push to this protected branch
@@ -66,6 +85,12 @@ func (protectBranch *ProtectedBranch) CanUserPush(userID int64) bool {
 		return false
 	}
 
+	for _, r := range protectBranch.Rulesets {
+		if !r.CanUserPush(userID) {
+			return false
+		}
+	}
+
 	if !protectBranch.EnableWhitelist {
 		if user, err := GetUserByID(userID); err != nil {
 			log.Error("GetUserByID: %v", err)
@@ -215,6 +240,87 @@ func (protectBranch *ProtectedBranch) GetProtectedFilePatterns() []glob.Glob {
 	return extarr
 }
 
+// GetSignedCommitsAllowedKeys parses a semicolon separated list of GPG key IDs and email addresses
+// of the signers allowed to sign the commits pushed to the branch
+func (protectBranch *ProtectedBranch) GetSignedCommitsAllowedKeys() []string {
+	keys := make([]string, 0, 5)
+	for _, key := range strings.Split(protectBranch.SignedCommitsAllowedKeys, ";") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if strings.Contains(key, "@") {
+			keys = append(keys, strings.ToLower(key))
+		} else {
+			keys = append(keys, strings.ToUpper(strings.TrimPrefix(strings.TrimPrefix(key, "0x"), "0X")))
+		}
+	}
+	return keys
+}
+
+// matchSigningKey reports whether the key ID or the email address is one of the allowed keys,
+// a key ID matches the long or the short ID of the key
+func matchSigningKey(allowedKeys []string, keyID, email string) bool {
+	keyID = strings.ToUpper(keyID)
+	email = strings.ToLower(email)
+	for _, key := range allowedKeys {
+		if strings.Contains(key, "@") {
+			if email != "" && key == email {
+				return true
+			}
+		} else if keyID != "" && strings.HasSuffix(keyID, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSigningKeyAllowed returns if the commits signed with the key can be pushed to the branch
+func (protectBranch *ProtectedBranch) IsSigningKeyAllowed(keyID, email string) bool {
+	allowedKeys := protectBranch.GetSignedCommitsAllowedKeys()
+	return len(allowedKeys) == 0 || matchSigningKey(allowedKeys, keyID, email)
+}
+
+// CheckSigningKey checks that the commits signed by the instance with the key can be pushed to the branch
+func (protectBranch *ProtectedBranch) CheckSigningKey(keyID string) error {
+	if !protectBranch.IsSigningKeyAllowed(keyID, setting.Repository.Signing.SigningEmail) {
+		return &ErrWontSign{keyNotAllowed}
+	}
+	return nil
+}
+
+// IsCommitSignatureAllowed returns if the verified signature of a commit was made
+// by one of the allowed keys of the branch
+func (protectBranch *ProtectedBranch) IsCommitSignatureAllowed(verification *CommitVerification) bool {
+	if verification == nil || !verification.Verified {
+		return false
+	}
+	allowedKeys := protectBranch.GetSignedCommitsAllowedKeys()
+	if len(allowedKeys) == 0 {
+		return true
+	}
+	if key := verification.SigningKey; key != nil {
+		if matchSigningKey(allowedKeys, key.KeyID, "") || matchSigningKey(allowedKeys, key.PrimaryKeyID, "") {
+			return true
+		}
+	}
+	return matchSigningKey(allowedKeys, "", verification.SigningEmail)
+}
+
+// IsMergeStyleAllowed returns if the branch protection and the rulesets applied to
+// the branch allow to merge pull requests into the branch with the merge style
+func (protectBranch *ProtectedBranch) IsMergeStyleAllowed(style MergeStyle) bool {
+	if len(protectBranch.MergeStyles) > 0 && !util.IsStringInSlice(string(style), protectBranch.MergeStyles) {
+		return false
+	}
+	for _, r := range protectBranch.Rulesets {
+		if len(r.MergeStyles) > 0 && !util.IsStringInSlice(string(style), r.MergeStyles) {
+			return false
+		}
+	}
+	return true
+}
+
 // GetProtectedBranchByRepoID getting protected branch by repo ID
 func GetProtectedBranchByRepoID(repoID int64) ([]*ProtectedBranch, error) {
 	protectedBranches := make([]*ProtectedBranch, 0)
@@ -335,22 +441,17 @@ func (repo *Repository) GetBranchProtection(branchName string) (*ProtectedBranch
 	return GetProtectedBranchBy(repo.ID, branchName)
 }
 
-// IsProtectedBranch checks if branch is protected
+// IsProtectedBranch checks if branch is protected by the repository or by a ruleset of its organization
 func (repo *Repository) IsProtectedBranch(branchName string, doer *User) (bool, error) {
 	if doer == nil {
 		return true, nil
 	}
 
-	protectedBranch := &ProtectedBranch{
-		RepoID:     repo.ID,
-		BranchName: branchName,
-	}
-
-	has, err := x.Exist(protectedBranch)
+	protectedBranch, err := GetEffectiveProtectedBranch(repo, branchName, doer.ID)
 	if err != nil {
 		return true, err
 	}
-	return has, nil
+	return protectedBranch != nil && protectedBranch.IsProtected(), nil
 }
 
 // IsProtectedBranchForPush checks if branch is protected for push
@@ -359,15 +460,10 @@ func (repo *Repository) IsProtectedBranchForPush(branchName string, doer *User)
 		return true, nil
 	}
 
-	protectedBranch := &ProtectedBranch{
-		RepoID:     repo.ID,
-		BranchName: branchName,
-	}
-
-	has, err := x.Get(protectedBranch)
+	protectedBranch, err := GetEffectiveProtectedBranch(repo, branchName, doer.ID)
 	if err != nil {
 		return true, err
-	} else if has {
+	} else if protectedBranch != nil {
 		return !protectedBranch.CanUserPush(doer.ID), nil
 	}
 
@@ -570,3 +666,112 @@ func RemoveOldDeletedBranches(ctx context.Context, olderThan time.Duration) {
 		log.Error("DeletedBranchesCleanup: %v", err)
 	}
 }
+
+// RenamedBranch records the rename of a branch of a repository
+type RenamedBranch struct {
+	ID          int64              `xorm:"pk autoincr"`
+	RepoID      int64              `xorm:"INDEX NOT NULL"`
+	From        string             `xorm:"NOT NULL"`
+	To          string             `xorm:"NOT NULL"`
+	RenamedByID int64              `xorm:"INDEX"`
+	RenamedBy   *User              `xorm:"-"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// LoadUser loads the user that renamed the branch
+// When there's no user found it returns a NewGhostUser
+func (renamedBranch *RenamedBranch) LoadUser() {
+	user, err := GetUserByID(renamedBranch.RenamedByID)
+	if err != nil {
+		user = NewGhostUser()
+	}
+	renamedBranch.RenamedBy = user
+}
+
+// GetLatestRenamedBranchTo returns the latest rename of a branch of a repository to the given
+// name, or nil if there is none
+func GetLatestRenamedBranchTo(repoID int64, to string) (*RenamedBranch, error) {
+	renamedBranch := new(RenamedBranch)
+	has, err := x.Where("repo_id=? AND `to`=?", repoID, to).Desc("id").Get(renamedBranch)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return renamedBranch, nil
+}
+
+// RenameBranch renames a branch of the repository in the database: its protection, the open
+// pull requests from or to it, the branch filters of the webhooks referencing it and the default
+// branch of the repository if it is the renamed one. The gitAction renaming the branch in the
+// git repository is run last so that the database changes are rolled back when it fails.
+func (repo *Repository) RenameBranch(from, to string, doerID int64, gitAction func(isDefault bool) error) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Where("repo_id=? AND branch_name=?", repo.ID, from).
+		Cols("branch_name").
+		Update(&ProtectedBranch{BranchName: to}); err != nil {
+		return err
+	}
+
+	openPulls := builder.In("issue_id", builder.Select("id").From("issue").Where(builder.Eq{"is_closed": false}))
+	if _, err := sess.Where("base_repo_id=? AND base_branch=? AND has_merged=?", repo.ID, from, false).
+		And(openPulls).
+		Cols("base_branch").
+		Update(&PullRequest{BaseBranch: to}); err != nil {
+		return err
+	}
+	if _, err := sess.Where("head_repo_id=? AND head_branch=? AND has_merged=?", repo.ID, from, false).
+		And(openPulls).
+		Cols("head_branch").
+		Update(&PullRequest{HeadBranch: to}); err != nil {
+		return err
+	}
+
+	webhooks := make([]*Webhook, 0, 5)
+	if err := sess.Find(&webhooks, &Webhook{RepoID: repo.ID}); err != nil {
+		return err
+	}
+	for _, w := range webhooks {
+		if !w.ReferencesBranch(from) {
+			continue
+		}
+		w.BranchFilter = renameBranchInFilter(w.BranchFilter, from, to)
+		if err := w.UpdateEvent(); err != nil {
+			return err
+		}
+		if _, err := sess.ID(w.ID).Cols("events").Update(w); err != nil {
+			return err
+		}
+	}
+
+	isDefault := repo.DefaultBranch == from
+	if isDefault {
+		repo.DefaultBranch = to
+		if _, err := sess.ID(repo.ID).Cols("default_branch").Update(repo); err != nil {
+			return err
+		}
+	}
+
+	if _, err := sess.Insert(&RenamedBranch{
+		RepoID:      repo.ID,
+		From:        from,
+		To:          to,
+		RenamedByID: doerID,
+	}); err != nil {
+		return err
+	}
+
+	if err := gitAction(isDefault); err != nil {
+		if isDefault {
+			repo.DefaultBranch = from
+		}
+		return err
+	}
+
+	return sess.Commit()
+}
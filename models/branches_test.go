@@ -5,6 +5,7 @@
 package models
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -78,3 +79,98 @@ func getDeletedBranch(t *testing.T, branch *DeletedBranch) *DeletedBranch {
 
 	return deletedBranch
 }
+
+func TestProtectedBranchIsCommitSignatureAllowed(t *testing.T) {
+	protectBranch := &ProtectedBranch{}
+	verification := &CommitVerification{
+		Verified:     true,
+		SigningEmail: "User2@example.com",
+		SigningKey:   &GPGKey{KeyID: "B2C2F0A1E0D4C3B9", PrimaryKeyID: "38EA3BCED732982C"},
+	}
+	assert.True(t, protectBranch.IsCommitSignatureAllowed(verification))
+	assert.False(t, protectBranch.IsCommitSignatureAllowed(&CommitVerification{Verified: false}))
+
+	protectBranch.SignedCommitsAllowedKeys = "0x38ea3bced732982c"
+	assert.True(t, protectBranch.IsCommitSignatureAllowed(verification))
+	protectBranch.SignedCommitsAllowedKeys = "E0D4C3B9; other@example.com"
+	assert.True(t, protectBranch.IsCommitSignatureAllowed(verification))
+	protectBranch.SignedCommitsAllowedKeys = "user2@example.com"
+	assert.True(t, protectBranch.IsCommitSignatureAllowed(verification))
+	protectBranch.SignedCommitsAllowedKeys = "0123456789ABCDEF;other@example.com"
+	assert.False(t, protectBranch.IsCommitSignatureAllowed(verification))
+
+	assert.True(t, protectBranch.IsSigningKeyAllowed("0123456789abcdef", ""))
+	assert.False(t, protectBranch.IsSigningKeyAllowed("B2C2F0A1E0D4C3B9", "user2@example.com"))
+}
+
+func TestProtectedBranchIsMergeStyleAllowed(t *testing.T) {
+	protectBranch := &ProtectedBranch{}
+	for _, style := range MergeStyles {
+		assert.True(t, protectBranch.IsMergeStyleAllowed(style))
+	}
+
+	protectBranch.MergeStyles = []string{"squash", "fast-forward-only"}
+	assert.True(t, protectBranch.IsMergeStyleAllowed(MergeStyleSquash))
+	assert.True(t, protectBranch.IsMergeStyleAllowed(MergeStyleFastForwardOnly))
+	assert.False(t, protectBranch.IsMergeStyleAllowed(MergeStyleMerge))
+
+	// a ruleset restricts the merge styles of the branch even more
+	protectBranch.Rulesets = []*OrgRuleset{{}, {MergeStyles: []string{"fast-forward-only", "rebase"}}}
+	assert.False(t, protectBranch.IsMergeStyleAllowed(MergeStyleSquash))
+	assert.True(t, protectBranch.IsMergeStyleAllowed(MergeStyleFastForwardOnly))
+	assert.False(t, protectBranch.IsMergeStyleAllowed(MergeStyleRebase))
+
+	protectBranch.MergeStyles = nil
+	assert.True(t, protectBranch.IsMergeStyleAllowed(MergeStyleRebase))
+}
+
+func TestRenameBranch(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	var calledIsDefault bool
+	assert.NoError(t, repo.RenameBranch("master", "main", 2, func(isDefault bool) error {
+		calledIsDefault = isDefault
+		return nil
+	}))
+	assert.True(t, calledIsDefault)
+	assert.EqualValues(t, "main", repo.DefaultBranch)
+
+	AssertExistsAndLoadBean(t, &Repository{ID: 1, DefaultBranch: "main"})
+	// open pull requests are retargeted, merged ones are left as they were
+	AssertExistsAndLoadBean(t, &PullRequest{ID: 2, BaseBranch: "main"})
+	AssertExistsAndLoadBean(t, &PullRequest{ID: 1, BaseBranch: "master"})
+
+	renamedBranch, err := GetLatestRenamedBranchTo(1, "main")
+	assert.NoError(t, err)
+	if assert.NotNil(t, renamedBranch) {
+		assert.EqualValues(t, "master", renamedBranch.From)
+		assert.EqualValues(t, 2, renamedBranch.RenamedByID)
+	}
+}
+
+func TestRenameBranchWebhooks(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+
+	assert.NoError(t, repo.RenameBranch("master", "main", 2, func(isDefault bool) error {
+		return nil
+	}))
+	webhook := AssertExistsAndLoadBean(t, &Webhook{ID: 4}).(*Webhook)
+	assert.EqualValues(t, "{main,feature*}", webhook.BranchFilter)
+}
+
+func TestRenameBranchGitActionFailure(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	assert.Error(t, repo.RenameBranch("master", "main", 2, func(isDefault bool) error {
+		return errors.New("git failure")
+	}))
+	assert.EqualValues(t, "master", repo.DefaultBranch)
+
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.EqualValues(t, "master", repo.DefaultBranch)
+	AssertExistsAndLoadBean(t, &PullRequest{ID: 2, BaseBranch: "master"})
+	AssertNotExistsBean(t, &RenamedBranch{RepoID: 1})
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ClusterLease represents a named lease held by one node of a cluster until it
+// expires, e.g. the right to run the cron tasks
+type ClusterLease struct {
+	Name        string             `xorm:"pk VARCHAR(255)"`
+	Holder      string             `xorm:"NOT NULL"`
+	ExpiresUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+}
+
+// AcquireClusterLease acquires or renews the named lease for holder until
+// duration from now. It returns false if another holder has the lease and it
+// did not expire yet.
+func AcquireClusterLease(name, holder string, duration time.Duration) (bool, error) {
+	now := timeutil.TimeStampNow()
+	lease := &ClusterLease{
+		Name:        name,
+		Holder:      holder,
+		ExpiresUnix: now.AddDuration(duration),
+	}
+
+	affected, err := x.
+		Where("name = ? AND (holder = ? OR expires_unix < ?)", name, holder, now).
+		Cols("holder", "expires_unix").
+		Update(lease)
+	if err != nil {
+		return false, err
+	}
+	if affected > 0 {
+		return true, nil
+	}
+
+	current, err := GetClusterLease(name)
+	if err != nil {
+		return false, err
+	}
+	if current != nil {
+		// Renewing within the same second leaves the row unchanged, which some
+		// databases do not count as affected
+		return current.Holder == holder && current.ExpiresUnix >= now, nil
+	}
+
+	if _, err := x.Insert(lease); err != nil {
+		// Another holder may have inserted the lease in the meantime
+		if current, getErr := GetClusterLease(name); getErr == nil && current != nil {
+			return current.Holder == holder, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// ReleaseClusterLease gives up the named lease if holder has it
+func ReleaseClusterLease(name, holder string) error {
+	_, err := x.Delete(&ClusterLease{Name: name, Holder: holder})
+	return err
+}
+
+// GetClusterLease returns the named lease, or nil if nobody acquired it yet
+func GetClusterLease(name string) (*ClusterLease, error) {
+	lease := new(ClusterLease)
+	has, err := x.ID(name).Get(lease)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return lease, nil
+}
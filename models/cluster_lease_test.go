@@ -0,0 +1,60 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireClusterLease(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	acquired, err := AcquireClusterLease("leader", "node-a", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	// renewing, also within the same second
+	acquired, err = AcquireClusterLease("leader", "node-a", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = AcquireClusterLease("leader", "node-b", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, acquired)
+
+	lease, err := GetClusterLease("leader")
+	assert.NoError(t, err)
+	assert.Equal(t, "node-a", lease.Holder)
+
+	// an expired lease is taken over
+	lease.ExpiresUnix = timeutil.TimeStampNow().Add(-1)
+	_, err = x.ID("leader").Cols("expires_unix").Update(lease)
+	assert.NoError(t, err)
+	acquired, err = AcquireClusterLease("leader", "node-b", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+	acquired, err = AcquireClusterLease("leader", "node-a", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, acquired)
+
+	// only the holder can release the lease
+	assert.NoError(t, ReleaseClusterLease("leader", "node-a"))
+	lease, err = GetClusterLease("leader")
+	assert.NoError(t, err)
+	assert.Equal(t, "node-b", lease.Holder)
+	assert.NoError(t, ReleaseClusterLease("leader", "node-b"))
+	lease, err = GetClusterLease("leader")
+	assert.NoError(t, err)
+	assert.Nil(t, lease)
+
+	acquired, err = AcquireClusterLease("leader", "node-a", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+}
@@ -0,0 +1,263 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// CommitMessagePolicy holds the rules the messages of the commits pushed to a repository,
+// created by editing files on the web or by merging pull requests must follow.
+type CommitMessagePolicy struct {
+	ID     int64 `xorm:"pk autoincr"`
+	RepoID int64 `xorm:"UNIQUE NOT NULL"`
+	// MaxSubjectLength is the maximum number of characters of the first line, 0 for no limit.
+	MaxSubjectLength int `xorm:"NOT NULL DEFAULT 0"`
+	// Patterns is a newline separated list of regular expressions every message must match.
+	Patterns string `xorm:"TEXT"`
+	// RequiredTrailers is a newline separated list of trailer keys like "Signed-off-by" every
+	// message must end with.
+	RequiredTrailers string  `xorm:"TEXT"`
+	BypassUserIDs    []int64 `xorm:"JSON TEXT"`
+	BypassTeamIDs    []int64 `xorm:"JSON TEXT"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+
+	// trackerSync requires the messages to reference an issue of the issue tracker of the repository
+	trackerSync *IssueTrackerSync `xorm:"-"`
+}
+
+// ErrCommitMessageRejected represents a "CommitMessageRejected" kind of error.
+type ErrCommitMessageRejected struct {
+	SHA    string
+	Reason string
+}
+
+// IsErrCommitMessageRejected checks if an error is a ErrCommitMessageRejected.
+func IsErrCommitMessageRejected(err error) bool {
+	_, ok := err.(ErrCommitMessageRejected)
+	return ok
+}
+
+func (err ErrCommitMessageRejected) Error() string {
+	if err.SHA != "" {
+		return fmt.Sprintf("commit %s does not follow the commit message policy: %s", err.SHA, err.Reason)
+	}
+	return fmt.Sprintf("commit message does not follow the commit message policy: %s", err.Reason)
+}
+
+func splitPolicyLines(s string) []string {
+	lines := make([]string, 0, 5)
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// GetPatterns returns the regular expressions of the policy.
+func (policy *CommitMessagePolicy) GetPatterns() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, 2)
+	for _, expr := range splitPolicyLines(policy.Patterns) {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			log.Info("Invalid commit message pattern '%s' (skipped): %v", expr, err)
+			continue
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns
+}
+
+// GetRequiredTrailers returns the trailer keys every message must contain.
+func (policy *CommitMessagePolicy) GetRequiredTrailers() []string {
+	return splitPolicyLines(policy.RequiredTrailers)
+}
+
+// IsEmpty returns true if the policy has no rules.
+func (policy *CommitMessagePolicy) IsEmpty() bool {
+	return policy.MaxSubjectLength <= 0 && len(splitPolicyLines(policy.Patterns)) == 0 &&
+		len(splitPolicyLines(policy.RequiredTrailers)) == 0 && policy.trackerSync == nil
+}
+
+// messageTrailers returns the keys of the trailers of the last paragraph of a commit message.
+func messageTrailers(message string) map[string]bool {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	trailers := make(map[string]bool)
+	if len(paragraphs) < 2 {
+		return trailers
+	}
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		i := strings.Index(line, ":")
+		if i <= 0 || strings.ContainsAny(line[:i], " \t") || strings.TrimSpace(line[i+1:]) == "" {
+			continue
+		}
+		trailers[strings.ToLower(line[:i])] = true
+	}
+	return trailers
+}
+
+// CheckMessage returns an ErrCommitMessageRejected if the commit message does not follow the
+// rules of the policy.
+func (policy *CommitMessagePolicy) CheckMessage(message string) error {
+	message = strings.TrimSpace(message)
+	subject := message
+	if i := strings.IndexByte(message, '\n'); i >= 0 {
+		subject = strings.TrimSpace(message[:i])
+	}
+
+	if policy.MaxSubjectLength > 0 && utf8.RuneCountInString(subject) > policy.MaxSubjectLength {
+		return ErrCommitMessageRejected{
+			Reason: fmt.Sprintf("the subject is longer than %d characters", policy.MaxSubjectLength),
+		}
+	}
+	for _, re := range policy.GetPatterns() {
+		if !re.MatchString(message) {
+			return ErrCommitMessageRejected{
+				Reason: fmt.Sprintf("the message does not match the pattern %s", re.String()),
+			}
+		}
+	}
+	if trailers := policy.GetRequiredTrailers(); len(trailers) > 0 {
+		found := messageTrailers(message)
+		for _, trailer := range trailers {
+			if !found[strings.ToLower(trailer)] {
+				return ErrCommitMessageRejected{
+					Reason: fmt.Sprintf("the message has no %s trailer", trailer),
+				}
+			}
+		}
+	}
+	if policy.trackerSync != nil {
+		return policy.trackerSync.CheckReference(message)
+	}
+	return nil
+}
+
+// CanBypass returns true if the user is allowed to create commits not following the policy.
+func (policy *CommitMessagePolicy) CanBypass(userID int64) bool {
+	if util.IsInt64InSlice(userID, policy.BypassUserIDs) {
+		return true
+	}
+	if len(policy.BypassTeamIDs) == 0 {
+		return false
+	}
+	in, err := IsUserInTeams(userID, policy.BypassTeamIDs)
+	if err != nil {
+		log.Error("IsUserInTeams: %v", err)
+		return false
+	}
+	return in
+}
+
+// GetCommitMessagePolicy returns the commit message policy of a repository, or nil if it has
+// none.
+func GetCommitMessagePolicy(repoID int64) (*CommitMessagePolicy, error) {
+	policy := &CommitMessagePolicy{RepoID: repoID}
+	has, err := x.Get(policy)
+	if err != nil || !has {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// GetCommitMessagePolicyForUser returns the commit message policy of a repository the commits
+// of the user must follow, or nil if there is none or the user may bypass it. The policy includes
+// the reference to an issue required by the issue tracker sync of the repository.
+func GetCommitMessagePolicyForUser(repoID, userID int64) (*CommitMessagePolicy, error) {
+	policy, err := GetCommitMessagePolicy(repoID)
+	if err != nil {
+		return nil, err
+	}
+	trackerSync, err := GetIssueTrackerSync(repoID)
+	if err != nil {
+		return nil, err
+	}
+	if trackerSync != nil && trackerSync.RequireReference {
+		if policy == nil {
+			policy = &CommitMessagePolicy{RepoID: repoID}
+		}
+		policy.trackerSync = trackerSync
+	}
+	if policy == nil {
+		return nil, nil
+	}
+	if policy.IsEmpty() || policy.CanBypass(userID) {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+// CheckCommitMessage checks a message of a commit the user is about to create in the repository
+// against its commit message policy.
+func CheckCommitMessage(repo *Repository, doer *User, message string) error {
+	policy, err := GetCommitMessagePolicyForUser(repo.ID, doer.ID)
+	if err != nil || policy == nil {
+		return err
+	}
+	return policy.CheckMessage(message)
+}
+
+// UpdateCommitMessagePolicy creates or updates the commit message policy of a repository, the
+// bypass lists are reduced to the users with write access and the teams of the repository.
+func UpdateCommitMessagePolicy(repo *Repository, policy *CommitMessagePolicy) error {
+	for _, expr := range splitPolicyLines(policy.Patterns) {
+		if _, err := regexp.Compile(expr); err != nil {
+			return ErrInvalidCommitMessagePattern{Pattern: expr, Err: err}
+		}
+	}
+
+	var err error
+	if policy.BypassUserIDs, err = updateUserWhitelist(repo, nil, policy.BypassUserIDs); err != nil {
+		return err
+	}
+	if policy.BypassTeamIDs, err = updateTeamWhitelist(repo, nil, policy.BypassTeamIDs); err != nil {
+		return err
+	}
+
+	policy.RepoID = repo.ID
+	existing, err := GetCommitMessagePolicy(repo.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		_, err = x.Insert(policy)
+		return err
+	}
+	policy.ID = existing.ID
+	_, err = x.ID(policy.ID).AllCols().Update(policy)
+	return err
+}
+
+// DeleteCommitMessagePolicy removes the commit message policy of a repository.
+func DeleteCommitMessagePolicy(repoID int64) error {
+	_, err := x.Delete(&CommitMessagePolicy{RepoID: repoID})
+	return err
+}
+
+// ErrInvalidCommitMessagePattern represents a "InvalidCommitMessagePattern" kind of error.
+type ErrInvalidCommitMessagePattern struct {
+	Pattern string
+	Err     error
+}
+
+// IsErrInvalidCommitMessagePattern checks if an error is a ErrInvalidCommitMessagePattern.
+func IsErrInvalidCommitMessagePattern(err error) bool {
+	_, ok := err.(ErrInvalidCommitMessagePattern)
+	return ok
+}
+
+func (err ErrInvalidCommitMessagePattern) Error() string {
+	return fmt.Sprintf("invalid commit message pattern [pattern: %s]: %v", err.Pattern, err.Err)
+}
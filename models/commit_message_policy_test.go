@@ -0,0 +1,72 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitMessagePolicy_CheckMessage(t *testing.T) {
+	policy := &CommitMessagePolicy{
+		MaxSubjectLength: 20,
+		Patterns:         "^(feat|fix): \n\n",
+		RequiredTrailers: "Signed-off-by\n",
+	}
+
+	assert.NoError(t, policy.CheckMessage("fix: typo\n\nSigned-off-by: A <a@example.com>\n"))
+	assert.NoError(t, policy.CheckMessage("feat: x\n\nSome details.\n\nReviewed-by: B\nsigned-off-by: A"))
+
+	for _, message := range []string{
+		"fix: a subject which is far too long\n\nSigned-off-by: A",
+		"typo\n\nSigned-off-by: A",
+		"fix: typo",
+		"fix: typo\n\nSigned-off-by: A\n\nmore text",
+		"fix: typo\n\nSigned-off-by:",
+		"Signed-off-by: A",
+	} {
+		err := policy.CheckMessage(message)
+		assert.True(t, IsErrCommitMessageRejected(err), message)
+	}
+
+	assert.True(t, (&CommitMessagePolicy{}).IsEmpty())
+	assert.NoError(t, (&CommitMessagePolicy{}).CheckMessage(""))
+}
+
+func TestUpdateCommitMessagePolicy(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+
+	policy, err := GetCommitMessagePolicy(repo.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, policy)
+	assert.NoError(t, CheckCommitMessage(repo, doer, "anything"))
+
+	err = UpdateCommitMessagePolicy(repo, &CommitMessagePolicy{Patterns: "("})
+	assert.True(t, IsErrInvalidCommitMessagePattern(err))
+
+	assert.NoError(t, UpdateCommitMessagePolicy(repo, &CommitMessagePolicy{
+		MaxSubjectLength: 10,
+		BypassTeamIDs:    []int64{2, 99},
+	}))
+	policy, err = GetCommitMessagePolicy(repo.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, policy.MaxSubjectLength)
+	assert.Equal(t, []int64{2}, policy.BypassTeamIDs)
+
+	assert.True(t, IsErrCommitMessageRejected(CheckCommitMessage(repo, doer, "a long subject")))
+	assert.NoError(t, CheckCommitMessage(repo, doer, "short"))
+	// user 4 is a member of the bypassing team
+	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	assert.NoError(t, CheckCommitMessage(repo, user4, "a long subject"))
+
+	assert.NoError(t, UpdateCommitMessagePolicy(repo, &CommitMessagePolicy{MaxSubjectLength: 20}))
+	AssertCount(t, &CommitMessagePolicy{RepoID: repo.ID}, 1)
+
+	assert.NoError(t, DeleteCommitMessagePolicy(repo.ID))
+	AssertNotExistsBean(t, &CommitMessagePolicy{RepoID: repo.ID})
+}
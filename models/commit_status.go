@@ -198,6 +198,45 @@ func FindRepoRecentCommitStatusContexts(repoID int64, before time.Duration) ([]s
 
 }
 
+// FindFailedCommitStatuses returns the failed or errored statuses of the repositories
+// updated since the given time or ever if it is 0, only the latest status of each context of a repository
+// counts so that a context is not reported anymore once it passes again.
+func FindFailedCommitStatuses(repoIDs []int64, since timeutil.TimeStamp, limit int) ([]*CommitStatus, error) {
+	statuses := make([]*CommitStatus, 0, limit)
+	if len(repoIDs) == 0 {
+		return statuses, nil
+	}
+
+	sess := x.Table("commit_status").In("repo_id", repoIDs)
+	if since > 0 {
+		sess.And("updated_unix >= ?", since)
+	}
+	ids := make([]int64, 0, 10)
+	if err := sess.
+		Select("max( id ) as id").
+		GroupBy("repo_id, context_hash").
+		Find(&ids); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return statuses, nil
+	}
+
+	if err := x.In("id", ids).
+		In("state", api.CommitStatusFailure, api.CommitStatusError).
+		Desc("id").
+		Limit(limit).
+		Find(&statuses); err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		if err := status.loadRepo(x); err != nil {
+			return nil, err
+		}
+	}
+	return statuses, nil
+}
+
 // NewCommitStatusOptions holds options for creating a CommitStatus
 type NewCommitStatusOptions struct {
 	Repo         *Repository
@@ -71,6 +71,9 @@ func assertCount(t *testing.T, bean interface{}, expected int) {
 
 func (user *User) checkForConsistency(t *testing.T) {
 	assertCount(t, &Repository{OwnerID: user.ID}, user.NumRepos)
+	numPrivateRepos, err := x.Where("owner_id = ? AND is_private = ?", user.ID, true).Count(new(Repository))
+	assert.NoError(t, err)
+	assert.EqualValues(t, user.NumPrivateRepos, numPrivateRepos)
 	assertCount(t, &Star{UID: user.ID}, user.NumStars)
 	assertCount(t, &OrgUser{OrgID: user.ID}, user.NumMembers)
 	assertCount(t, &Team{OrgID: user.ID}, user.NumTeams)
@@ -0,0 +1,161 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+var (
+	lfsOidPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	uuidPattern   = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+// storageOrphanGracePeriod is how long the files of a storage may have no database row, the
+// files are written before their rows are inserted
+const storageOrphanGracePeriod = time.Hour
+
+// StorageOrphans are the files of a storage without database rows, and the database rows
+// whose files are missing
+type StorageOrphans struct {
+	// Files are the paths of the files without rows, relative to the root of the storage
+	Files []string
+	// MissingIDs are the IDs of the rows whose files are missing
+	MissingIDs []int64
+}
+
+// walkStorage calls fn with the path relative to the root of every file of a storage older
+// than the grace period
+func walkStorage(ctx context.Context, root string, fn func(relPath string)) error {
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ErrCancelledf("before walking %s", p)
+		default:
+		}
+		if info.IsDir() || time.Since(info.ModTime()) < storageOrphanGracePeriod {
+			return nil
+		}
+		relPath, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		fn(filepath.ToSlash(relPath))
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func isFileMissing(p string) bool {
+	_, err := os.Stat(p)
+	return os.IsNotExist(err)
+}
+
+// lfsObjectPath returns the path of the content of an LFS object relative to the LFS root
+func lfsObjectPath(oid string) string {
+	if len(oid) < 5 {
+		return oid
+	}
+	return path.Join(oid[0:2], oid[2:4], oid[4:])
+}
+
+// FindOrphanedLFSObjects returns the LFS files no repository references and the LFS objects
+// whose content is missing
+func FindOrphanedLFSObjects(ctx context.Context) (*StorageOrphans, error) {
+	orphans := &StorageOrphans{}
+	oids := make(map[string]bool)
+	if err := x.Iterate(new(LFSMetaObject), func(idx int, bean interface{}) error {
+		meta := bean.(*LFSMetaObject)
+		if !oids[meta.Oid] && isFileMissing(filepath.Join(setting.LFS.ContentPath, lfsObjectPath(meta.Oid))) {
+			orphans.MissingIDs = append(orphans.MissingIDs, meta.ID)
+			return nil
+		}
+		oids[meta.Oid] = true
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// the other files than the LFS objects are ignored, the content path may contain other data
+	return orphans, walkStorage(ctx, setting.LFS.ContentPath, func(relPath string) {
+		oid := strings.TrimSuffix(strings.Replace(relPath, "/", "", -1), ".tmp")
+		if lfsOidPattern.MatchString(oid) && strings.HasPrefix(relPath, lfsObjectPath(oid)) && !oids[oid] {
+			orphans.Files = append(orphans.Files, relPath)
+		}
+	})
+}
+
+// DeleteLFSMetaObjectsByIDs deletes LFS objects, e.g. the ones whose content is missing so that
+// it is uploaded again by the next push
+func DeleteLFSMetaObjectsByIDs(ids []int64) error {
+	return deleteByIDs(ids, new(LFSMetaObject))
+}
+
+// attachmentPath returns the path of an attachment relative to the attachment root
+func attachmentPath(uuid string) string {
+	if len(uuid) < 2 {
+		return uuid
+	}
+	return path.Join(uuid[0:1], uuid[1:2], uuid)
+}
+
+// FindOrphanedAttachments returns the attachment files without database rows and the
+// attachments whose files are missing
+func FindOrphanedAttachments(ctx context.Context) (*StorageOrphans, error) {
+	orphans := &StorageOrphans{}
+	uuids := make(map[string]bool)
+	if err := x.Iterate(new(Attachment), func(idx int, bean interface{}) error {
+		attach := bean.(*Attachment)
+		uuids[attach.UUID] = true
+		if isFileMissing(filepath.Join(setting.AttachmentPath, attachmentPath(attach.UUID))) {
+			orphans.MissingIDs = append(orphans.MissingIDs, attach.ID)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// the other files than the attachments are ignored, the attachment path may contain other data
+	return orphans, walkStorage(ctx, setting.AttachmentPath, func(relPath string) {
+		uuid := path.Base(relPath)
+		if uuidPattern.MatchString(uuid) && attachmentPath(uuid) == relPath && !uuids[uuid] {
+			orphans.Files = append(orphans.Files, relPath)
+		}
+	})
+}
+
+// DeleteAttachmentsByIDs deletes the rows of attachments, e.g. the ones whose files are missing
+func DeleteAttachmentsByIDs(ids []int64) error {
+	return deleteByIDs(ids, new(Attachment))
+}
+
+// deleteByIDs deletes rows by batches of IDs, the databases limit the number of parameters
+func deleteByIDs(ids []int64, bean interface{}) error {
+	for len(ids) > 0 {
+		batch := ids
+		if len(batch) > 500 {
+			batch = batch[:500]
+		}
+		if _, err := x.In("id", batch).Delete(bean); err != nil {
+			return err
+		}
+		ids = ids[len(batch):]
+	}
+	return nil
+}
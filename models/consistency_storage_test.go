@@ -0,0 +1,97 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeStorageFile writes a file of a storage, old enough not to be an upload in progress
+func writeStorageFile(t *testing.T, root, relPath string) {
+	p := filepath.Join(root, filepath.FromSlash(relPath))
+	assert.NoError(t, os.MkdirAll(filepath.Dir(p), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(p, []byte("content"), 0644))
+	old := time.Now().Add(-2 * storageOrphanGracePeriod)
+	assert.NoError(t, os.Chtimes(p, old, old))
+}
+
+func TestFindOrphanedLFSObjects(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	dir, err := ioutil.TempDir("", "lfs")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	oldPath := setting.LFS.ContentPath
+	defer func() {
+		setting.LFS.ContentPath = oldPath
+	}()
+	setting.LFS.ContentPath = dir
+
+	const (
+		stored  = "2eccdb43825d2a49d99d542daa20075cff1d97d9d2349a8977efe9c03661737c"
+		missing = "7b6b2c88dba9f760a1a58469b67fee2b698ef7e9399c4ca4f34a14ccbe39f623"
+		orphan  = "d6f175817f886ec6fbbc1515326465fa96c3bfd54a4ea06cfd6dbbd8340e0152"
+	)
+	_, err = NewLFSMetaObject(&LFSMetaObject{Oid: stored, Size: 7, RepositoryID: 1})
+	assert.NoError(t, err)
+	missingMeta, err := NewLFSMetaObject(&LFSMetaObject{Oid: missing, Size: 7, RepositoryID: 1})
+	assert.NoError(t, err)
+	writeStorageFile(t, dir, lfsObjectPath(stored))
+	writeStorageFile(t, dir, lfsObjectPath(orphan))
+	writeStorageFile(t, dir, lfsObjectPath(orphan)+".tmp")
+	// the other data of the content path is ignored
+	writeStorageFile(t, dir, "queues/LOCK")
+	writeStorageFile(t, dir, "2e/cc/other")
+
+	// recent files may be uploads in progress
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "upload.tmp"), nil, 0644))
+
+	orphans, err := FindOrphanedLFSObjects(context.Background())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{lfsObjectPath(orphan), lfsObjectPath(orphan) + ".tmp"}, orphans.Files)
+	assert.Equal(t, []int64{missingMeta.ID}, orphans.MissingIDs)
+
+	assert.NoError(t, DeleteLFSMetaObjectsByIDs(orphans.MissingIDs))
+	AssertNotExistsBean(t, &LFSMetaObject{ID: missingMeta.ID})
+	AssertExistsAndLoadBean(t, &LFSMetaObject{Oid: stored})
+}
+
+func TestFindOrphanedAttachments(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	dir, err := ioutil.TempDir("", "attachments")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	oldPath := setting.AttachmentPath
+	defer func() {
+		setting.AttachmentPath = oldPath
+	}()
+	setting.AttachmentPath = dir
+
+	// only the first attachment of the fixtures has a file
+	attach := AssertExistsAndLoadBean(t, &Attachment{ID: 1}).(*Attachment)
+	writeStorageFile(t, dir, attachmentPath(attach.UUID))
+	writeStorageFile(t, dir, attachmentPath("b0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"))
+	writeStorageFile(t, dir, "avatars/33")
+	writeStorageFile(t, dir, "0/b/b0eebc99-9c0b-4ef8-bb6d-6bb9bd380a12")
+
+	orphans, err := FindOrphanedAttachments(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{attachmentPath("b0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11")}, orphans.Files)
+	assert.Len(t, orphans.MissingIDs, int(getCount(t, x, &Attachment{}))-1)
+	assert.NotContains(t, orphans.MissingIDs, attach.ID)
+
+	assert.NoError(t, DeleteAttachmentsByIDs(orphans.MissingIDs))
+	AssertCount(t, &Attachment{}, 1)
+}
@@ -0,0 +1,198 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// ContributorAgreement is a contributor license agreement (CLA) the authors of the commits of
+// pull requests must sign before they can be merged, for a repository or for all repositories
+// of an organization. Every change of its text makes a new version which must be signed again.
+type ContributorAgreement struct {
+	ID      int64 `xorm:"pk autoincr"`
+	OwnerID int64 `xorm:"UNIQUE(s) NOT NULL"`
+	// RepoID is 0 for the agreement of all repositories of the owner
+	RepoID  int64  `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	Title   string `xorm:"NOT NULL"`
+	Content string `xorm:"TEXT NOT NULL"`
+	Version int    `xorm:"NOT NULL DEFAULT 1"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// ContributorAgreementSignature records that a user accepted a version of an agreement.
+type ContributorAgreementSignature struct {
+	ID          int64              `xorm:"pk autoincr"`
+	AgreementID int64              `xorm:"UNIQUE(s) NOT NULL"`
+	UserID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Version     int                `xorm:"UNIQUE(s) NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// ErrContributorAgreementNotSigned represents a "ContributorAgreementNotSigned" kind of error.
+type ErrContributorAgreementNotSigned struct {
+	Authors []string
+}
+
+// IsErrContributorAgreementNotSigned checks if an error is a ErrContributorAgreementNotSigned.
+func IsErrContributorAgreementNotSigned(err error) bool {
+	_, ok := err.(ErrContributorAgreementNotSigned)
+	return ok
+}
+
+func (err ErrContributorAgreementNotSigned) Error() string {
+	return fmt.Sprintf("contributor license agreement is not signed by %s", strings.Join(err.Authors, ", "))
+}
+
+// GetContributorAgreement returns the agreement of a repository of the owner, or of all its
+// repositories if repoID is 0, or nil if there is none.
+func GetContributorAgreement(ownerID, repoID int64) (*ContributorAgreement, error) {
+	agreement := new(ContributorAgreement)
+	has, err := x.Where("owner_id = ? AND repo_id = ?", ownerID, repoID).Get(agreement)
+	if err != nil || !has {
+		return nil, err
+	}
+	return agreement, nil
+}
+
+// GetRepoContributorAgreement returns the agreement the contributors of a repository must sign,
+// the one of the repository or else the one of its owner, or nil if there is none.
+func GetRepoContributorAgreement(repo *Repository) (*ContributorAgreement, error) {
+	agreement, err := GetContributorAgreement(repo.OwnerID, repo.ID)
+	if err != nil || agreement != nil {
+		return agreement, err
+	}
+	return GetContributorAgreement(repo.OwnerID, 0)
+}
+
+// SaveContributorAgreement creates or updates the agreement of its owner and repository, a
+// changed title or text increases its version.
+func SaveContributorAgreement(agreement *ContributorAgreement) error {
+	existing, err := GetContributorAgreement(agreement.OwnerID, agreement.RepoID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		agreement.Version = 1
+		_, err = x.Insert(agreement)
+		return err
+	}
+
+	agreement.ID = existing.ID
+	agreement.Version = existing.Version
+	if agreement.Title != existing.Title || agreement.Content != existing.Content {
+		agreement.Version++
+	}
+	_, err = x.ID(agreement.ID).Cols("title", "content", "version").Update(agreement)
+	return err
+}
+
+func deleteContributorAgreements(e Engine, cond builder.Cond) error {
+	ids := make([]int64, 0, 1)
+	if err := e.Table("contributor_agreement").Where(cond).Cols("id").Find(&ids); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := e.In("agreement_id", ids).Delete(new(ContributorAgreementSignature)); err != nil {
+		return err
+	}
+	_, err := e.In("id", ids).Delete(new(ContributorAgreement))
+	return err
+}
+
+// DeleteContributorAgreement removes the agreement of a repository of the owner, or of all its
+// repositories if repoID is 0, with its signatures.
+func DeleteContributorAgreement(ownerID, repoID int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if err := deleteContributorAgreements(sess, builder.Eq{"owner_id": ownerID, "repo_id": repoID}); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// HasSigned returns true if the user signed the current version of the agreement.
+func (agreement *ContributorAgreement) HasSigned(userID int64) (bool, error) {
+	return x.Exist(&ContributorAgreementSignature{
+		AgreementID: agreement.ID,
+		UserID:      userID,
+		Version:     agreement.Version,
+	})
+}
+
+// Sign records that the user accepted the current version of the agreement.
+func (agreement *ContributorAgreement) Sign(userID int64) error {
+	signed, err := agreement.HasSigned(userID)
+	if err != nil || signed {
+		return err
+	}
+	_, err = x.Insert(&ContributorAgreementSignature{
+		AgreementID: agreement.ID,
+		UserID:      userID,
+		Version:     agreement.Version,
+	})
+	return err
+}
+
+// GetUnsignedUserIDs returns the IDs of the users who have not signed the current version of
+// the agreement.
+func (agreement *ContributorAgreement) GetUnsignedUserIDs(userIDs []int64) ([]int64, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	signedIDs := make([]int64, 0, len(userIDs))
+	if err := x.Table("contributor_agreement_signature").
+		Where("agreement_id = ? AND version = ?", agreement.ID, agreement.Version).
+		In("user_id", userIDs).
+		Cols("user_id").
+		Find(&signedIDs); err != nil {
+		return nil, err
+	}
+	signed := make(map[int64]bool, len(signedIDs))
+	for _, id := range signedIDs {
+		signed[id] = true
+	}
+
+	unsigned := make([]int64, 0, len(userIDs)-len(signedIDs))
+	for _, id := range userIDs {
+		if !signed[id] {
+			unsigned = append(unsigned, id)
+		}
+	}
+	return unsigned, nil
+}
+
+// GetUnmergedPullRequests returns the open pull requests of the repositories the agreement
+// applies to.
+func (agreement *ContributorAgreement) GetUnmergedPullRequests() ([]*PullRequest, error) {
+	var cond builder.Cond
+	if agreement.RepoID > 0 {
+		cond = builder.Eq{"pull_request.base_repo_id": agreement.RepoID}
+	} else {
+		// repositories with an agreement of their own do not use the one of their owner
+		cond = builder.In("pull_request.base_repo_id",
+			builder.Select("id").From("repository").Where(builder.Eq{"owner_id": agreement.OwnerID})).
+			And(builder.NotIn("pull_request.base_repo_id",
+				builder.Select("repo_id").From("contributor_agreement").Where(builder.Eq{"owner_id": agreement.OwnerID}.And(builder.Gt{"repo_id": 0}))))
+	}
+
+	prs := make([]*PullRequest, 0, 10)
+	return prs, x.
+		Join("INNER", "issue", "issue.id = pull_request.issue_id").
+		Where(cond.And(builder.Eq{"pull_request.has_merged": false, "issue.is_closed": false})).
+		Find(&prs)
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContributorAgreement(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+
+	agreement, err := GetRepoContributorAgreement(repo)
+	assert.NoError(t, err)
+	assert.Nil(t, agreement)
+
+	orgAgreement := &ContributorAgreement{OwnerID: repo.OwnerID, Title: "Org CLA", Content: "terms"}
+	assert.NoError(t, SaveContributorAgreement(orgAgreement))
+	assert.EqualValues(t, 1, orgAgreement.Version)
+
+	agreement, err = GetRepoContributorAgreement(repo)
+	assert.NoError(t, err)
+	if assert.NotNil(t, agreement) {
+		assert.EqualValues(t, orgAgreement.ID, agreement.ID)
+	}
+
+	// the agreement of the repository replaces the one of its owner
+	repoAgreement := &ContributorAgreement{OwnerID: repo.OwnerID, RepoID: repo.ID, Title: "Repo CLA", Content: "terms"}
+	assert.NoError(t, SaveContributorAgreement(repoAgreement))
+	agreement, err = GetRepoContributorAgreement(repo)
+	assert.NoError(t, err)
+	if assert.NotNil(t, agreement) {
+		assert.EqualValues(t, repoAgreement.ID, agreement.ID)
+	}
+
+	assert.NoError(t, agreement.Sign(2))
+	assert.NoError(t, agreement.Sign(2))
+	signed, err := agreement.HasSigned(2)
+	assert.NoError(t, err)
+	assert.True(t, signed)
+	unsigned, err := agreement.GetUnsignedUserIDs([]int64{2, 4})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int64{4}, unsigned)
+
+	// saving the same text keeps the version, a new text must be signed again
+	assert.NoError(t, SaveContributorAgreement(&ContributorAgreement{OwnerID: repo.OwnerID, RepoID: repo.ID, Title: "Repo CLA", Content: "terms"}))
+	agreement = AssertExistsAndLoadBean(t, &ContributorAgreement{ID: repoAgreement.ID}).(*ContributorAgreement)
+	assert.EqualValues(t, 1, agreement.Version)
+	assert.NoError(t, SaveContributorAgreement(&ContributorAgreement{OwnerID: repo.OwnerID, RepoID: repo.ID, Title: "Repo CLA", Content: "new terms"}))
+	agreement = AssertExistsAndLoadBean(t, &ContributorAgreement{ID: repoAgreement.ID}).(*ContributorAgreement)
+	assert.EqualValues(t, 2, agreement.Version)
+	signed, err = agreement.HasSigned(2)
+	assert.NoError(t, err)
+	assert.False(t, signed)
+
+	// deleting the agreement of the repository keeps the one of its owner
+	assert.NoError(t, DeleteContributorAgreement(repo.OwnerID, repo.ID))
+	AssertNotExistsBean(t, &ContributorAgreement{ID: repoAgreement.ID})
+	AssertNotExistsBean(t, &ContributorAgreementSignature{AgreementID: repoAgreement.ID})
+	AssertExistsAndLoadBean(t, &ContributorAgreement{ID: orgAgreement.ID})
+}
+
+func TestContributorAgreement_GetUnmergedPullRequests(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	agreement := &ContributorAgreement{OwnerID: 2, Title: "CLA", Content: "terms"}
+	assert.NoError(t, SaveContributorAgreement(agreement))
+	prs, err := agreement.GetUnmergedPullRequests()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, prs)
+	for _, pr := range prs {
+		assert.False(t, pr.HasMerged)
+	}
+
+	// repositories with an agreement of their own are skipped
+	assert.NoError(t, SaveContributorAgreement(&ContributorAgreement{OwnerID: 2, RepoID: 1, Title: "CLA", Content: "terms"}))
+	prs, err = agreement.GetUnmergedPullRequests()
+	assert.NoError(t, err)
+	for _, pr := range prs {
+		assert.NotEqualValues(t, 1, pr.BaseRepoID)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+
+	"code.gitea.io/gitea/modules/emoji"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	gouuid "github.com/google/uuid"
+)
+
+// CustomEmojiNamePattern matches the allowed names of custom emoji, which are
+// used as :name: short codes.
+var CustomEmojiNamePattern = regexp.MustCompile(`^[\w\+\-]+$`)
+
+// customEmojiExtensions maps the supported image types to file extensions.
+var customEmojiExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/jpeg": ".jpg",
+	"image/webp": ".webp",
+}
+
+// CustomEmoji represents an emoji image uploaded by a site administrator.
+type CustomEmoji struct {
+	ID          int64  `xorm:"pk autoincr"`
+	Name        string `xorm:"UNIQUE NOT NULL"`
+	FileName    string
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// ErrCustomEmojiAlreadyExist represents a "CustomEmojiAlreadyExist" kind of error.
+type ErrCustomEmojiAlreadyExist struct {
+	Name string
+}
+
+// IsErrCustomEmojiAlreadyExist checks if an error is a ErrCustomEmojiAlreadyExist.
+func IsErrCustomEmojiAlreadyExist(err error) bool {
+	_, ok := err.(ErrCustomEmojiAlreadyExist)
+	return ok
+}
+
+func (err ErrCustomEmojiAlreadyExist) Error() string {
+	return fmt.Sprintf("emoji already exists [name: %s]", err.Name)
+}
+
+// ErrCustomEmojiNotExist represents a "CustomEmojiNotExist" kind of error.
+type ErrCustomEmojiNotExist struct {
+	Name string
+}
+
+// IsErrCustomEmojiNotExist checks if an error is a ErrCustomEmojiNotExist.
+func IsErrCustomEmojiNotExist(err error) bool {
+	_, ok := err.(ErrCustomEmojiNotExist)
+	return ok
+}
+
+func (err ErrCustomEmojiNotExist) Error() string {
+	return fmt.Sprintf("custom emoji does not exist [name: %s]", err.Name)
+}
+
+// ErrCustomEmojiNameInvalid represents a "CustomEmojiNameInvalid" kind of error.
+type ErrCustomEmojiNameInvalid struct {
+	Name string
+}
+
+// IsErrCustomEmojiNameInvalid checks if an error is a ErrCustomEmojiNameInvalid.
+func IsErrCustomEmojiNameInvalid(err error) bool {
+	_, ok := err.(ErrCustomEmojiNameInvalid)
+	return ok
+}
+
+func (err ErrCustomEmojiNameInvalid) Error() string {
+	return fmt.Sprintf("custom emoji name is invalid [name: %s]", err.Name)
+}
+
+// ErrCustomEmojiInvalidImage represents a "CustomEmojiInvalidImage" kind of error.
+type ErrCustomEmojiInvalidImage struct {
+	Name        string
+	ContentType string
+}
+
+// IsErrCustomEmojiInvalidImage checks if an error is a ErrCustomEmojiInvalidImage.
+func IsErrCustomEmojiInvalidImage(err error) bool {
+	_, ok := err.(ErrCustomEmojiInvalidImage)
+	return ok
+}
+
+func (err ErrCustomEmojiInvalidImage) Error() string {
+	return fmt.Sprintf("custom emoji image type is not supported [name: %s, content_type: %s]", err.Name, err.ContentType)
+}
+
+// LocalPath returns where the emoji image is stored on the local file system.
+func (e *CustomEmoji) LocalPath() string {
+	return path.Join(setting.CustomEmojiPath, e.FileName)
+}
+
+// Link returns the URL of the emoji image.
+func (e *CustomEmoji) Link() string {
+	return setting.AppSubURL + "/custom-emoji/" + e.FileName
+}
+
+// HTMLURL returns the absolute URL of the emoji image.
+func (e *CustomEmoji) HTMLURL() string {
+	return setting.AppURL + "custom-emoji/" + e.FileName
+}
+
+// NewCustomEmoji registers a new custom emoji with the given image.
+func NewCustomEmoji(name string, data []byte) (*CustomEmoji, error) {
+	if !CustomEmojiNamePattern.MatchString(name) || name == "gitea" || emoji.FromAlias(name) != nil {
+		return nil, ErrCustomEmojiNameInvalid{name}
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := customEmojiExtensions[contentType]
+	if !ok {
+		return nil, ErrCustomEmojiInvalidImage{name, contentType}
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if has, err := sess.Exist(&CustomEmoji{Name: name}); err != nil {
+		return nil, err
+	} else if has {
+		return nil, ErrCustomEmojiAlreadyExist{name}
+	}
+
+	e := &CustomEmoji{
+		Name:     name,
+		FileName: gouuid.New().String() + ext,
+	}
+	if _, err := sess.Insert(e); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(setting.CustomEmojiPath, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("MkdirAll: %v", err)
+	}
+	if err := ioutil.WriteFile(e.LocalPath(), data, 0644); err != nil {
+		return nil, fmt.Errorf("WriteFile: %v", err)
+	}
+
+	if err := sess.Commit(); err != nil {
+		return nil, err
+	}
+	emoji.AddCustom(e.Name, e.Link())
+	return e, nil
+}
+
+// GetCustomEmojiByName returns the custom emoji with the given name.
+func GetCustomEmojiByName(name string) (*CustomEmoji, error) {
+	e := &CustomEmoji{Name: name}
+	has, err := x.Get(e)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrCustomEmojiNotExist{name}
+	}
+	return e, nil
+}
+
+// GetCustomEmojiByID returns the custom emoji with the given ID.
+func GetCustomEmojiByID(id int64) (*CustomEmoji, error) {
+	e := new(CustomEmoji)
+	has, err := x.ID(id).Get(e)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrCustomEmojiNotExist{}
+	}
+	return e, nil
+}
+
+// GetCustomEmojis returns all custom emoji ordered by name.
+func GetCustomEmojis() ([]*CustomEmoji, error) {
+	emojis := make([]*CustomEmoji, 0, 10)
+	return emojis, x.Asc("name").Find(&emojis)
+}
+
+// DeleteCustomEmoji deletes a custom emoji and its image. Reactions using
+// the emoji are kept.
+func DeleteCustomEmoji(e *CustomEmoji) error {
+	if _, err := x.ID(e.ID).Delete(new(CustomEmoji)); err != nil {
+		return err
+	}
+	emoji.RemoveCustom(e.Name)
+
+	if err := os.Remove(e.LocalPath()); err != nil && !os.IsNotExist(err) {
+		log.Error("Remove custom emoji image %s: %v", e.LocalPath(), err)
+	}
+	return nil
+}
+
+// LoadCustomEmojis registers all custom emoji stored in the database so they
+// are available to the markup renderer.
+func LoadCustomEmojis() error {
+	emojis, err := GetCustomEmojis()
+	if err != nil {
+		return err
+	}
+
+	links := make(map[string]string, len(emojis))
+	for _, e := range emojis {
+		links[e.Name] = e.Link()
+	}
+	emoji.SetCustom(links)
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/modules/emoji"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unknwon/com"
+)
+
+func customEmojiImage(t *testing.T) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 16, 16))))
+	return buf.Bytes()
+}
+
+func TestNewCustomEmoji(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	setting.CustomEmojiPath = filepath.Join(setting.AppDataPath, "custom-emoji")
+	defer emoji.SetCustom(nil)
+
+	e, err := NewCustomEmoji("party_parrot", customEmojiImage(t))
+	assert.NoError(t, err)
+	assert.True(t, com.IsFile(e.LocalPath()))
+	assert.Equal(t, ".png", filepath.Ext(e.FileName))
+	link, ok := emoji.FromCustom("party_parrot")
+	assert.True(t, ok)
+	assert.Equal(t, e.Link(), link)
+
+	_, err = NewCustomEmoji("party_parrot", customEmojiImage(t))
+	assert.True(t, IsErrCustomEmojiAlreadyExist(err))
+	_, err = NewCustomEmoji("smile", customEmojiImage(t))
+	assert.True(t, IsErrCustomEmojiNameInvalid(err))
+	_, err = NewCustomEmoji("not valid", customEmojiImage(t))
+	assert.True(t, IsErrCustomEmojiNameInvalid(err))
+	_, err = NewCustomEmoji("text", []byte("not an image"))
+	assert.True(t, IsErrCustomEmojiInvalidImage(err))
+
+	assert.NoError(t, DeleteCustomEmoji(e))
+	AssertNotExistsBean(t, &CustomEmoji{ID: e.ID})
+	assert.False(t, com.IsFile(e.LocalPath()))
+	_, ok = emoji.FromCustom("party_parrot")
+	assert.False(t, ok)
+}
+
+func TestCustomEmojiReaction(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	setting.CustomEmojiPath = filepath.Join(setting.AppDataPath, "custom-emoji")
+	defer emoji.SetCustom(nil)
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	_, err := CreateIssueReaction(user, issue, "party_parrot")
+	assert.True(t, IsErrForbiddenIssueReaction(err))
+
+	_, err = NewCustomEmoji("party_parrot", customEmojiImage(t))
+	assert.NoError(t, err)
+	assert.Contains(t, AllowedReactions(), "party_parrot")
+
+	_, err = CreateIssueReaction(user, issue, "party_parrot")
+	assert.NoError(t, err)
+	reactions, err := FindIssueReactions(issue, ListOptions{})
+	assert.NoError(t, err)
+	var found bool
+	for _, reaction := range reactions {
+		found = found || reaction.Type == "party_parrot"
+	}
+	assert.True(t, found)
+
+	stats, err := GetReactionStats()
+	assert.NoError(t, err)
+	counts := make(map[string]int64, len(stats))
+	for _, stat := range stats {
+		counts[stat.Type] = stat.Count
+	}
+	assert.EqualValues(t, 1, counts["party_parrot"])
+}
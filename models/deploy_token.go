@@ -0,0 +1,263 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/generate"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	gouuid "github.com/google/uuid"
+)
+
+// DeployTokenPrefix starts the deploy tokens to tell them from the access tokens
+const DeployTokenPrefix = "gdt_"
+
+// deployTokenLength is the length of the deploy tokens, their prefix followed by a SHA1
+const deployTokenLength = len(DeployTokenPrefix) + 40
+
+// DeployTokenScope is an operation allowed to a deploy token
+type DeployTokenScope string
+
+const (
+	// DeployTokenScopeGitPull allows to clone and fetch the repository over HTTP
+	DeployTokenScopeGitPull DeployTokenScope = "git:pull"
+	// DeployTokenScopeGitPush allows to push to the repository over HTTP
+	DeployTokenScopeGitPush DeployTokenScope = "git:push"
+	// DeployTokenScopeLFSPull allows to download the LFS objects of the repository
+	DeployTokenScopeLFSPull DeployTokenScope = "lfs:pull"
+	// DeployTokenScopeLFSPush allows to upload LFS objects to the repository
+	DeployTokenScopeLFSPush DeployTokenScope = "lfs:push"
+	// DeployTokenScopeReleaseUpload allows to upload the assets of the releases through the API
+	DeployTokenScopeReleaseUpload DeployTokenScope = "release:upload"
+)
+
+// DeployTokenScopes are the valid scopes of the deploy tokens
+var DeployTokenScopes = []DeployTokenScope{
+	DeployTokenScopeGitPull,
+	DeployTokenScopeGitPush,
+	DeployTokenScopeLFSPull,
+	DeployTokenScopeLFSPush,
+	DeployTokenScopeReleaseUpload,
+}
+
+// IsValid returns true if the scope is a valid deploy token scope
+func (s DeployTokenScope) IsValid() bool {
+	for _, scope := range DeployTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// DeployToken is a short-lived token of a repository, allowing a CI job to do some operations on
+// the repository on behalf of the user who created it. The token expires on its own and the
+// operations are also limited by the permissions of its creator.
+type DeployToken struct {
+	ID        int64              `xorm:"pk autoincr"`
+	RepoID    int64              `xorm:"INDEX NOT NULL"`
+	CreatorID int64              `xorm:"INDEX NOT NULL"`
+	Creator   *User              `xorm:"-"`
+	Name      string             `xorm:"NOT NULL"`
+	Scopes    []DeployTokenScope `xorm:"JSON TEXT"`
+
+	Token          string `xorm:"-"`
+	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
+	TokenSalt      string
+	TokenLastEight string `xorm:"INDEX token_last_eight"`
+
+	ExpiresUnix  timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+	LastUsedUnix timeutil.TimeStamp
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+}
+
+// ErrDeployTokenNotExist represents a "DeployTokenNotExist" kind of error.
+type ErrDeployTokenNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrDeployTokenNotExist checks if an error is a ErrDeployTokenNotExist.
+func IsErrDeployTokenNotExist(err error) bool {
+	_, ok := err.(ErrDeployTokenNotExist)
+	return ok
+}
+
+func (err ErrDeployTokenNotExist) Error() string {
+	return fmt.Sprintf("deploy token does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
+// ErrDeployTokenInvalid represents a deploy token with an invalid field.
+type ErrDeployTokenInvalid struct {
+	Field string
+	Value string
+}
+
+// IsErrDeployTokenInvalid checks if an error is a ErrDeployTokenInvalid.
+func IsErrDeployTokenInvalid(err error) bool {
+	_, ok := err.(ErrDeployTokenInvalid)
+	return ok
+}
+
+func (err ErrDeployTokenInvalid) Error() string {
+	return fmt.Sprintf("invalid deploy token [%s: %s]", err.Field, err.Value)
+}
+
+// IsDeployToken returns true if the secret looks like a deploy token
+func IsDeployToken(token string) bool {
+	return strings.HasPrefix(token, DeployTokenPrefix)
+}
+
+// HasScope returns true if the token allows an operation
+func (t *DeployToken) HasScope(scope DeployTokenScope) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired returns true if the token can not be used anymore
+func (t *DeployToken) IsExpired() bool {
+	return t.ExpiresUnix <= timeutil.TimeStampNow()
+}
+
+// LoadCreator loads the user who created the token
+func (t *DeployToken) LoadCreator() (err error) {
+	if t.Creator == nil {
+		t.Creator, err = GetUserByID(t.CreatorID)
+	}
+	return err
+}
+
+// NewDeployToken creates a deploy token valid for a duration, the default duration of the
+// settings is used if it is 0. The secret is set in the Token field.
+func NewDeployToken(t *DeployToken, ttl time.Duration) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return ErrDeployTokenInvalid{Field: "name", Value: t.Name}
+	}
+	if len(t.Scopes) == 0 {
+		return ErrDeployTokenInvalid{Field: "scopes"}
+	}
+	for _, scope := range t.Scopes {
+		if !scope.IsValid() {
+			return ErrDeployTokenInvalid{Field: "scopes", Value: string(scope)}
+		}
+	}
+	if ttl == 0 {
+		ttl = setting.DeployToken.DefaultTTL
+	}
+	if ttl < 0 || ttl > setting.DeployToken.MaxTTL {
+		return ErrDeployTokenInvalid{Field: "ttl", Value: ttl.String()}
+	}
+
+	salt, err := generate.GetRandomString(10)
+	if err != nil {
+		return err
+	}
+	t.TokenSalt = salt
+	t.Token = DeployTokenPrefix + base.EncodeSha1(gouuid.New().String())
+	t.TokenHash = hashToken(t.Token, t.TokenSalt)
+	t.TokenLastEight = t.Token[len(t.Token)-8:]
+	t.ExpiresUnix = timeutil.TimeStampNow().AddDuration(ttl)
+	_, err = x.Insert(t)
+	return err
+}
+
+// GetDeployTokenBySHA returns the deploy token of a secret if it has not expired, its last use
+// is updated.
+func GetDeployTokenBySHA(token string) (*DeployToken, error) {
+	if !IsDeployToken(token) || len(token) != deployTokenLength {
+		return nil, ErrDeployTokenNotExist{}
+	}
+	var tokens []*DeployToken
+	err := x.Where("token_last_eight = ? AND expires_unix > ?", token[len(token)-8:], timeutil.TimeStampNow()).Find(&tokens)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(hashToken(token, t.TokenSalt))) == 1 {
+			t.LastUsedUnix = timeutil.TimeStampNow()
+			if _, err = x.ID(t.ID).Cols("last_used_unix").Update(t); err != nil {
+				return nil, err
+			}
+			return t, nil
+		}
+	}
+	return nil, ErrDeployTokenNotExist{}
+}
+
+// AuthenticateDeployToken returns the creator of a deploy token if the token allows an operation
+// on a repository. The permissions of the creator on the repository must still be checked.
+func AuthenticateDeployToken(token string, repoID int64, scope DeployTokenScope) (*User, error) {
+	if !setting.DeployToken.Enabled {
+		return nil, ErrDeployTokenNotExist{}
+	}
+	t, err := GetDeployTokenBySHA(token)
+	if err != nil {
+		return nil, err
+	}
+	if t.RepoID != repoID || !t.HasScope(scope) {
+		log.Warn("Deploy token %d of repository %d used for %s on repository %d", t.ID, t.RepoID, scope, repoID)
+		return nil, ErrDeployTokenNotExist{ID: t.ID, RepoID: repoID}
+	}
+	if err = t.LoadCreator(); err != nil {
+		return nil, err
+	}
+	if !t.Creator.IsActive || t.Creator.ProhibitLogin {
+		return nil, ErrUserProhibitLogin{UID: t.Creator.ID, Name: t.Creator.Name}
+	}
+	return t.Creator, nil
+}
+
+// GetDeployToken returns a deploy token of a repository.
+func GetDeployToken(repoID, id int64) (*DeployToken, error) {
+	t := new(DeployToken)
+	has, err := x.ID(id).Where("repo_id = ?", repoID).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDeployTokenNotExist{ID: id, RepoID: repoID}
+	}
+	return t, nil
+}
+
+// GetDeployTokens returns the deploy tokens of a repository which have not expired.
+func GetDeployTokens(repoID int64) ([]*DeployToken, error) {
+	tokens := make([]*DeployToken, 0, 5)
+	return tokens, x.Where("repo_id = ? AND expires_unix > ?", repoID, timeutil.TimeStampNow()).Desc("id").Find(&tokens)
+}
+
+// DeleteDeployToken revokes a deploy token of a repository.
+func DeleteDeployToken(repoID, id int64) error {
+	cnt, err := x.Where("id = ? AND repo_id = ?", id, repoID).Delete(new(DeployToken))
+	if err != nil {
+		return err
+	} else if cnt != 1 {
+		return ErrDeployTokenNotExist{ID: id, RepoID: repoID}
+	}
+	return nil
+}
+
+// DeleteExpiredDeployTokens deletes the deploy tokens which have expired.
+func DeleteExpiredDeployTokens(ctx context.Context) error {
+	cnt, err := x.Where("expires_unix <= ?", timeutil.TimeStampNow()).Delete(new(DeployToken))
+	if err != nil {
+		return err
+	}
+	log.Trace("Deleted %d expired deploy tokens", cnt)
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDeployToken(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	token := &DeployToken{RepoID: 1, CreatorID: 2, Name: " ci ", Scopes: []DeployTokenScope{DeployTokenScopeGitPull}}
+	assert.NoError(t, NewDeployToken(token, 0))
+	assert.True(t, IsDeployToken(token.Token))
+	assert.Equal(t, "ci", token.Name)
+	assert.EqualValues(t, timeutil.TimeStampNow().AddDuration(setting.DeployToken.DefaultTTL), token.ExpiresUnix)
+	AssertExistsAndLoadBean(t, &DeployToken{ID: token.ID, TokenHash: token.TokenHash})
+
+	err := NewDeployToken(&DeployToken{RepoID: 1, CreatorID: 2, Name: "ci"}, 0)
+	assert.True(t, IsErrDeployTokenInvalid(err))
+	err = NewDeployToken(&DeployToken{RepoID: 1, CreatorID: 2, Name: "ci", Scopes: []DeployTokenScope{"repo:admin"}}, 0)
+	assert.True(t, IsErrDeployTokenInvalid(err))
+	err = NewDeployToken(&DeployToken{RepoID: 1, CreatorID: 2, Name: "ci", Scopes: []DeployTokenScope{DeployTokenScopeGitPull}}, setting.DeployToken.MaxTTL+time.Second)
+	assert.True(t, IsErrDeployTokenInvalid(err))
+}
+
+func TestAuthenticateDeployToken(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	token := &DeployToken{RepoID: 1, CreatorID: 2, Name: "ci", Scopes: []DeployTokenScope{DeployTokenScopeGitPull, DeployTokenScopeLFSPull}}
+	assert.NoError(t, NewDeployToken(token, time.Hour))
+
+	user, err := AuthenticateDeployToken(token.Token, 1, DeployTokenScopeLFSPull)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, user.ID)
+	secret := token.Token
+	token = AssertExistsAndLoadBean(t, &DeployToken{ID: token.ID}).(*DeployToken)
+	assert.NotZero(t, token.LastUsedUnix)
+
+	// the token is limited to its repository and scopes
+	_, err = AuthenticateDeployToken(secret, 1, DeployTokenScopeGitPush)
+	assert.True(t, IsErrDeployTokenNotExist(err))
+	_, err = AuthenticateDeployToken(secret, 2, DeployTokenScopeGitPull)
+	assert.True(t, IsErrDeployTokenNotExist(err))
+	wrong := secret[:len(secret)-1] + "0"
+	if wrong == secret {
+		wrong = secret[:len(secret)-1] + "1"
+	}
+	_, err = AuthenticateDeployToken(wrong, 1, DeployTokenScopeGitPull)
+	assert.True(t, IsErrDeployTokenNotExist(err))
+	for _, invalid := range []string{DeployTokenPrefix, DeployTokenPrefix + "abc", secret + "0"} {
+		_, err = AuthenticateDeployToken(invalid, 1, DeployTokenScopeGitPull)
+		assert.True(t, IsErrDeployTokenNotExist(err))
+	}
+
+	tokens, err := GetDeployTokens(1)
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 1)
+
+	// expired tokens are rejected and deleted
+	token.ExpiresUnix = timeutil.TimeStampNow() - 1
+	_, err = x.ID(token.ID).Cols("expires_unix").Update(token)
+	assert.NoError(t, err)
+	_, err = AuthenticateDeployToken(secret, 1, DeployTokenScopeGitPull)
+	assert.True(t, IsErrDeployTokenNotExist(err))
+	tokens, err = GetDeployTokens(1)
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 0)
+
+	assert.NoError(t, DeleteExpiredDeployTokens(nil))
+	AssertNotExistsBean(t, &DeployToken{ID: token.ID})
+	assert.True(t, IsErrDeployTokenNotExist(DeleteDeployToken(1, token.ID)))
+}
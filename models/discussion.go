@@ -0,0 +1,456 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// DiscussionCategory groups the discussions of a repository. Discussions in
+// an answerable category are questions whose replies can be accepted as
+// answer.
+type DiscussionCategory struct {
+	ID             int64 `xorm:"pk autoincr"`
+	RepoID         int64 `xorm:"INDEX"`
+	Name           string
+	Description    string
+	IsAnswerable   bool
+	NumDiscussions int
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// Discussion represents a conversation thread of a repository, which lives
+// outside of the issue tracker.
+type Discussion struct {
+	ID              int64               `xorm:"pk autoincr"`
+	RepoID          int64               `xorm:"INDEX UNIQUE(repo_index)"`
+	Repo            *Repository         `xorm:"-"`
+	Index           int64               `xorm:"UNIQUE(repo_index)"` // Index in one repository.
+	CategoryID      int64               `xorm:"INDEX"`
+	Category        *DiscussionCategory `xorm:"-"`
+	PosterID        int64               `xorm:"INDEX"`
+	Poster          *User               `xorm:"-"`
+	Title           string              `xorm:"name"`
+	Content         string              `xorm:"TEXT"`
+	RenderedContent string              `xorm:"-"`
+	NumReplies      int
+	NumUpvotes      int
+	AnswerID        int64
+	Answer          *DiscussionReply `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// ErrDiscussionCategoryNotExist represents a "DiscussionCategoryNotExist" kind of error.
+type ErrDiscussionCategoryNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrDiscussionCategoryNotExist checks if an error is a ErrDiscussionCategoryNotExist.
+func IsErrDiscussionCategoryNotExist(err error) bool {
+	_, ok := err.(ErrDiscussionCategoryNotExist)
+	return ok
+}
+
+func (err ErrDiscussionCategoryNotExist) Error() string {
+	return fmt.Sprintf("discussion category does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
+// ErrDiscussionCategoryNotEmpty represents a "DiscussionCategoryNotEmpty" kind of error.
+type ErrDiscussionCategoryNotEmpty struct {
+	ID int64
+}
+
+// IsErrDiscussionCategoryNotEmpty checks if an error is a ErrDiscussionCategoryNotEmpty.
+func IsErrDiscussionCategoryNotEmpty(err error) bool {
+	_, ok := err.(ErrDiscussionCategoryNotEmpty)
+	return ok
+}
+
+func (err ErrDiscussionCategoryNotEmpty) Error() string {
+	return fmt.Sprintf("discussion category still contains discussions [id: %d]", err.ID)
+}
+
+// ErrDiscussionNotExist represents a "DiscussionNotExist" kind of error.
+type ErrDiscussionNotExist struct {
+	ID     int64
+	RepoID int64
+	Index  int64
+}
+
+// IsErrDiscussionNotExist checks if an error is a ErrDiscussionNotExist.
+func IsErrDiscussionNotExist(err error) bool {
+	_, ok := err.(ErrDiscussionNotExist)
+	return ok
+}
+
+func (err ErrDiscussionNotExist) Error() string {
+	return fmt.Sprintf("discussion does not exist [id: %d, repo_id: %d, index: %d]", err.ID, err.RepoID, err.Index)
+}
+
+// NewDiscussionCategory creates a new discussion category.
+func NewDiscussionCategory(category *DiscussionCategory) error {
+	category.Name = strings.TrimSpace(category.Name)
+	_, err := x.Insert(category)
+	return err
+}
+
+func getDiscussionCategoryByID(e Engine, id int64) (*DiscussionCategory, error) {
+	category := new(DiscussionCategory)
+	has, err := e.ID(id).Get(category)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDiscussionCategoryNotExist{ID: id}
+	}
+	return category, nil
+}
+
+// GetDiscussionCategoryByRepoID returns a discussion category of a repository.
+func GetDiscussionCategoryByRepoID(repoID, id int64) (*DiscussionCategory, error) {
+	category := new(DiscussionCategory)
+	has, err := x.ID(id).Where("repo_id = ?", repoID).Get(category)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDiscussionCategoryNotExist{ID: id, RepoID: repoID}
+	}
+	return category, nil
+}
+
+// GetDiscussionCategoriesByRepoID returns all discussion categories of a repository.
+func GetDiscussionCategoriesByRepoID(repoID int64) ([]*DiscussionCategory, error) {
+	categories := make([]*DiscussionCategory, 0, 5)
+	return categories, x.Where("repo_id = ?", repoID).Asc("name").Find(&categories)
+}
+
+// DeleteDiscussionCategory deletes a discussion category which does not
+// contain any discussion.
+func DeleteDiscussionCategory(repoID, id int64) error {
+	category, err := GetDiscussionCategoryByRepoID(repoID, id)
+	if err != nil {
+		if IsErrDiscussionCategoryNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if count, err := x.Where("category_id = ?", category.ID).Count(new(Discussion)); err != nil {
+		return err
+	} else if count > 0 {
+		return ErrDiscussionCategoryNotEmpty{ID: category.ID}
+	}
+
+	_, err = x.ID(category.ID).Delete(new(DiscussionCategory))
+	return err
+}
+
+func updateDiscussionCategoryNums(e Engine, categoryID int64) error {
+	_, err := e.Exec("UPDATE `discussion_category` SET num_discussions=(SELECT count(*) FROM discussion WHERE category_id=?) WHERE id=?",
+		categoryID,
+		categoryID,
+	)
+	return err
+}
+
+func (d *Discussion) loadRepo(e Engine) (err error) {
+	if d.Repo == nil {
+		d.Repo, err = getRepositoryByID(e, d.RepoID)
+		if err != nil {
+			return fmt.Errorf("getRepositoryByID [%d]: %v", d.RepoID, err)
+		}
+	}
+	return nil
+}
+
+func (d *Discussion) loadPoster(e Engine) (err error) {
+	if d.Poster == nil {
+		d.Poster, err = getUserByID(e, d.PosterID)
+		if err != nil {
+			d.PosterID = -1
+			d.Poster = NewGhostUser()
+			if !IsErrUserNotExist(err) {
+				return fmt.Errorf("getUserByID.(poster) [%d]: %v", d.PosterID, err)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (d *Discussion) loadCategory(e Engine) (err error) {
+	if d.Category == nil {
+		d.Category, err = getDiscussionCategoryByID(e, d.CategoryID)
+		if IsErrDiscussionCategoryNotExist(err) {
+			return nil
+		}
+	}
+	return err
+}
+
+func (d *Discussion) loadAnswer(e Engine) (err error) {
+	if d.Answer == nil && d.AnswerID > 0 {
+		d.Answer, err = getDiscussionReplyByID(e, d.AnswerID)
+		if IsErrDiscussionReplyNotExist(err) {
+			d.AnswerID = 0
+			return nil
+		} else if err != nil {
+			return err
+		}
+		return d.Answer.loadPoster(e)
+	}
+	return nil
+}
+
+func (d *Discussion) loadAttributes(e Engine) error {
+	if err := d.loadRepo(e); err != nil {
+		return err
+	}
+	if err := d.loadPoster(e); err != nil {
+		return err
+	}
+	if err := d.loadCategory(e); err != nil {
+		return err
+	}
+	return d.loadAnswer(e)
+}
+
+// LoadAttributes loads the repository, poster, category and accepted answer
+// of the discussion.
+func (d *Discussion) LoadAttributes() error {
+	return d.loadAttributes(x)
+}
+
+// HTMLURL returns the absolute URL to the discussion.
+func (d *Discussion) HTMLURL() string {
+	return fmt.Sprintf("%s/discussions/%d", d.Repo.HTMLURL(), d.Index)
+}
+
+// Link returns the relative URL to the discussion.
+func (d *Discussion) Link() string {
+	return fmt.Sprintf("%s/discussions/%d", d.Repo.Link(), d.Index)
+}
+
+// APIURL returns the absolute APIURL to the discussion.
+func (d *Discussion) APIURL() string {
+	return fmt.Sprintf("%s/discussions/%d", d.Repo.APIURL(), d.Index)
+}
+
+// IsAnswerable returns true if replies to the discussion can be accepted as answer.
+func (d *Discussion) IsAnswerable() bool {
+	return d.Category != nil && d.Category.IsAnswerable
+}
+
+// IsPoster returns true if the given user started the discussion.
+func (d *Discussion) IsPoster(uid int64) bool {
+	return d.PosterID == uid
+}
+
+// NewDiscussion creates a new discussion in one of the categories of its repository.
+func NewDiscussion(d *Discussion) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	category, err := getDiscussionCategoryByID(sess, d.CategoryID)
+	if err != nil {
+		return err
+	} else if category.RepoID != d.RepoID {
+		return ErrDiscussionCategoryNotExist{ID: d.CategoryID, RepoID: d.RepoID}
+	}
+	d.Category = category
+	d.Title = strings.TrimSpace(d.Title)
+
+	// Index is calculated by the database to avoid races between concurrent
+	// posters, as it is done for issues.
+	if _, err = sess.SetExpr("`index`", "coalesce(MAX(`index`),0)+1").
+		Where("repo_id=?", d.RepoID).
+		Insert(d); err != nil {
+		return err
+	}
+	inserted, err := getDiscussionByID(sess, d.ID)
+	if err != nil {
+		return err
+	}
+	d.Index = inserted.Index
+
+	if err = updateDiscussionCategoryNums(sess, d.CategoryID); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func getDiscussionByID(e Engine, id int64) (*Discussion, error) {
+	d := new(Discussion)
+	has, err := e.ID(id).Get(d)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDiscussionNotExist{ID: id}
+	}
+	return d, nil
+}
+
+// GetDiscussionByID returns the discussion by given ID.
+func GetDiscussionByID(id int64) (*Discussion, error) {
+	return getDiscussionByID(x, id)
+}
+
+// GetDiscussionByIndex returns the discussion of a repository by its index.
+func GetDiscussionByIndex(repoID, index int64) (*Discussion, error) {
+	d := &Discussion{
+		RepoID: repoID,
+		Index:  index,
+	}
+	has, err := x.Get(d)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDiscussionNotExist{RepoID: repoID, Index: index}
+	}
+	return d, nil
+}
+
+// FindDiscussionsOptions represents the filters for listing discussions.
+type FindDiscussionsOptions struct {
+	ListOptions
+	RepoID     int64
+	CategoryID int64
+	PosterID   int64
+	// Answered filters discussions by whether they have an accepted answer.
+	Answered util.OptionalBool
+	Keyword  string
+}
+
+func (opts *FindDiscussionsOptions) toCond() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"discussion.repo_id": opts.RepoID})
+	if opts.CategoryID > 0 {
+		cond = cond.And(builder.Eq{"discussion.category_id": opts.CategoryID})
+	}
+	if opts.PosterID > 0 {
+		cond = cond.And(builder.Eq{"discussion.poster_id": opts.PosterID})
+	}
+	if opts.Answered.IsTrue() {
+		cond = cond.And(builder.Gt{"discussion.answer_id": 0})
+	} else if opts.Answered.IsFalse() {
+		cond = cond.And(builder.Eq{"discussion.answer_id": 0})
+	}
+	if opts.Keyword != "" {
+		cond = cond.And(builder.Like{"discussion.name", opts.Keyword})
+	}
+	return cond
+}
+
+// FindDiscussions returns the discussions of a repository, most recently
+// updated first.
+func FindDiscussions(opts FindDiscussionsOptions) ([]*Discussion, int64, error) {
+	count, err := x.Where(opts.toCond()).Count(new(Discussion))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sess := x.Where(opts.toCond())
+	if opts.Page != 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	discussions := make([]*Discussion, 0, opts.PageSize)
+	if err = sess.Desc("updated_unix").Desc("id").Find(&discussions); err != nil {
+		return nil, 0, err
+	}
+	return discussions, count, nil
+}
+
+// UpdateDiscussion updates the title and content of a discussion, and moves
+// it to another category.
+func UpdateDiscussion(d *Discussion) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	old, err := getDiscussionByID(sess, d.ID)
+	if err != nil {
+		return err
+	}
+	if old.CategoryID != d.CategoryID {
+		category, err := getDiscussionCategoryByID(sess, d.CategoryID)
+		if err != nil {
+			return err
+		} else if category.RepoID != d.RepoID {
+			return ErrDiscussionCategoryNotExist{ID: d.CategoryID, RepoID: d.RepoID}
+		}
+		d.Category = category
+		if !category.IsAnswerable {
+			d.AnswerID = 0
+			d.Answer = nil
+		}
+	}
+
+	d.Title = strings.TrimSpace(d.Title)
+	if _, err = sess.ID(d.ID).Cols("name", "content", "category_id", "answer_id").Update(d); err != nil {
+		return err
+	}
+	if old.CategoryID != d.CategoryID {
+		for _, id := range []int64{old.CategoryID, d.CategoryID} {
+			if err = updateDiscussionCategoryNums(sess, id); err != nil {
+				return err
+			}
+		}
+	}
+	return sess.Commit()
+}
+
+// DeleteDiscussion deletes a discussion with its replies, upvotes and notifications.
+func DeleteDiscussion(d *Discussion) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = deleteBeans(sess,
+		&DiscussionReply{DiscussionID: d.ID},
+		&DiscussionUpvote{DiscussionID: d.ID},
+		&Notification{DiscussionID: d.ID},
+	); err != nil {
+		return err
+	}
+	if _, err = sess.ID(d.ID).Delete(new(Discussion)); err != nil {
+		return err
+	}
+	if err = updateDiscussionCategoryNums(sess, d.CategoryID); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// deleteDiscussionsByRepoID deletes all discussions of a repository and the
+// objects depending on them.
+func deleteDiscussionsByRepoID(e Engine, repoID int64) error {
+	deleteCond := builder.Select("id").From("discussion").Where(builder.Eq{"discussion.repo_id": repoID})
+
+	if _, err := e.In("discussion_id", deleteCond).Delete(new(DiscussionReply)); err != nil {
+		return err
+	}
+	if _, err := e.In("discussion_id", deleteCond).Delete(new(DiscussionUpvote)); err != nil {
+		return err
+	}
+	return deleteBeans(e,
+		&Discussion{RepoID: repoID},
+		&DiscussionCategory{RepoID: repoID},
+	)
+}
@@ -0,0 +1,339 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// DiscussionReply represents a reply to a discussion. Replies with a parent
+// are nested below another reply of the same discussion.
+type DiscussionReply struct {
+	ID              int64  `xorm:"pk autoincr"`
+	DiscussionID    int64  `xorm:"INDEX"`
+	ParentID        int64  `xorm:"INDEX"`
+	PosterID        int64  `xorm:"INDEX"`
+	Poster          *User  `xorm:"-"`
+	Content         string `xorm:"TEXT"`
+	RenderedContent string `xorm:"-"`
+	NumUpvotes      int
+	Children        []*DiscussionReply `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// DiscussionUpvote represents an upvote of a user on a discussion, or on one
+// of its replies if ReplyID is set.
+type DiscussionUpvote struct {
+	ID           int64 `xorm:"pk autoincr"`
+	UserID       int64 `xorm:"UNIQUE(s)"`
+	DiscussionID int64 `xorm:"INDEX UNIQUE(s)"`
+	ReplyID      int64 `xorm:"UNIQUE(s)"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// ErrDiscussionReplyNotExist represents a "DiscussionReplyNotExist" kind of error.
+type ErrDiscussionReplyNotExist struct {
+	ID           int64
+	DiscussionID int64
+}
+
+// IsErrDiscussionReplyNotExist checks if an error is a ErrDiscussionReplyNotExist.
+func IsErrDiscussionReplyNotExist(err error) bool {
+	_, ok := err.(ErrDiscussionReplyNotExist)
+	return ok
+}
+
+func (err ErrDiscussionReplyNotExist) Error() string {
+	return fmt.Sprintf("discussion reply does not exist [id: %d, discussion_id: %d]", err.ID, err.DiscussionID)
+}
+
+// ErrDiscussionNotAnswerable represents a "DiscussionNotAnswerable" kind of error.
+type ErrDiscussionNotAnswerable struct {
+	DiscussionID int64
+}
+
+// IsErrDiscussionNotAnswerable checks if an error is a ErrDiscussionNotAnswerable.
+func IsErrDiscussionNotAnswerable(err error) bool {
+	_, ok := err.(ErrDiscussionNotAnswerable)
+	return ok
+}
+
+func (err ErrDiscussionNotAnswerable) Error() string {
+	return fmt.Sprintf("discussion is not in an answerable category [discussion_id: %d]", err.DiscussionID)
+}
+
+func (r *DiscussionReply) loadPoster(e Engine) (err error) {
+	if r.Poster == nil {
+		r.Poster, err = getUserByID(e, r.PosterID)
+		if err != nil {
+			if !IsErrUserNotExist(err) {
+				return fmt.Errorf("getUserByID [%d]: %v", r.PosterID, err)
+			}
+			r.PosterID = -1
+			r.Poster = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// LoadPoster loads the poster of the reply.
+func (r *DiscussionReply) LoadPoster() error {
+	return r.loadPoster(x)
+}
+
+// HashTag returns the anchor of the reply on the discussion page.
+func (r *DiscussionReply) HashTag() string {
+	return fmt.Sprintf("discussion-reply-%d", r.ID)
+}
+
+func getDiscussionReplyByID(e Engine, id int64) (*DiscussionReply, error) {
+	r := new(DiscussionReply)
+	has, err := e.ID(id).Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDiscussionReplyNotExist{ID: id}
+	}
+	return r, nil
+}
+
+// GetDiscussionReplyByID returns a reply of the given discussion.
+func GetDiscussionReplyByID(discussionID, id int64) (*DiscussionReply, error) {
+	r, err := getDiscussionReplyByID(x, id)
+	if err != nil {
+		return nil, err
+	} else if r.DiscussionID != discussionID {
+		return nil, ErrDiscussionReplyNotExist{ID: id, DiscussionID: discussionID}
+	}
+	return r, nil
+}
+
+// GetDiscussionReplies returns all replies of a discussion in the order they
+// were posted, with their posters loaded.
+func GetDiscussionReplies(discussionID int64) ([]*DiscussionReply, error) {
+	replies := make([]*DiscussionReply, 0, 10)
+	if err := x.Where("discussion_id = ?", discussionID).Asc("created_unix").Asc("id").Find(&replies); err != nil {
+		return nil, err
+	}
+	for _, r := range replies {
+		if err := r.loadPoster(x); err != nil {
+			return nil, err
+		}
+	}
+	return replies, nil
+}
+
+// BuildDiscussionReplyTree nests the replies below their parents and returns
+// the top level replies.
+func BuildDiscussionReplyTree(replies []*DiscussionReply) []*DiscussionReply {
+	byID := make(map[int64]*DiscussionReply, len(replies))
+	for _, r := range replies {
+		r.Children = nil
+		byID[r.ID] = r
+	}
+
+	roots := make([]*DiscussionReply, 0, len(replies))
+	for _, r := range replies {
+		if parent, ok := byID[r.ParentID]; ok && r.ParentID != r.ID {
+			parent.Children = append(parent.Children, r)
+		} else {
+			roots = append(roots, r)
+		}
+	}
+	return roots
+}
+
+func updateDiscussionNumReplies(e Engine, discussionID int64) error {
+	_, err := e.Exec("UPDATE `discussion` SET num_replies=(SELECT count(*) FROM discussion_reply WHERE discussion_id=?), updated_unix=? WHERE id=?",
+		discussionID,
+		timeutil.TimeStampNow(),
+		discussionID,
+	)
+	return err
+}
+
+// NewDiscussionReply posts a reply to a discussion, below the reply with the
+// given parentID if it is not zero.
+func NewDiscussionReply(doer *User, d *Discussion, parentID int64, content string) (*DiscussionReply, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if parentID > 0 {
+		parent, err := getDiscussionReplyByID(sess, parentID)
+		if err != nil {
+			return nil, err
+		} else if parent.DiscussionID != d.ID {
+			return nil, ErrDiscussionReplyNotExist{ID: parentID, DiscussionID: d.ID}
+		}
+	}
+
+	r := &DiscussionReply{
+		DiscussionID: d.ID,
+		ParentID:     parentID,
+		PosterID:     doer.ID,
+		Poster:       doer,
+		Content:      content,
+	}
+	if _, err := sess.Insert(r); err != nil {
+		return nil, err
+	}
+	if err := updateDiscussionNumReplies(sess, d.ID); err != nil {
+		return nil, err
+	}
+	d.NumReplies++
+	return r, sess.Commit()
+}
+
+// UpdateDiscussionReply updates the content of a reply.
+func UpdateDiscussionReply(r *DiscussionReply) error {
+	_, err := x.ID(r.ID).Cols("content").Update(r)
+	return err
+}
+
+// DeleteDiscussionReply deletes a reply. Its nested replies are moved up to
+// the parent of the deleted reply.
+func DeleteDiscussionReply(d *Discussion, r *DiscussionReply) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Exec("UPDATE `discussion_reply` SET parent_id=? WHERE parent_id=?", r.ParentID, r.ID); err != nil {
+		return err
+	}
+	if _, err := sess.ID(r.ID).Delete(new(DiscussionReply)); err != nil {
+		return err
+	}
+	if _, err := sess.Delete(&DiscussionUpvote{DiscussionID: d.ID, ReplyID: r.ID}); err != nil {
+		return err
+	}
+	if d.AnswerID == r.ID {
+		d.AnswerID = 0
+		d.Answer = nil
+		if _, err := sess.ID(d.ID).Cols("answer_id").Update(d); err != nil {
+			return err
+		}
+	}
+	if err := updateDiscussionNumReplies(sess, d.ID); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// SetAnswer accepts a reply as the answer of a discussion in an answerable
+// category. A replyID of 0 removes the accepted answer.
+func (d *Discussion) SetAnswer(replyID int64) error {
+	if err := d.loadCategory(x); err != nil {
+		return err
+	}
+	if !d.IsAnswerable() {
+		return ErrDiscussionNotAnswerable{DiscussionID: d.ID}
+	}
+
+	var answer *DiscussionReply
+	if replyID > 0 {
+		var err error
+		if answer, err = GetDiscussionReplyByID(d.ID, replyID); err != nil {
+			return err
+		}
+	}
+
+	d.AnswerID = replyID
+	d.Answer = answer
+	_, err := x.ID(d.ID).Cols("answer_id").Update(d)
+	return err
+}
+
+func updateDiscussionUpvotes(e Engine, discussionID, replyID int64) error {
+	if replyID > 0 {
+		_, err := e.Exec("UPDATE `discussion_reply` SET num_upvotes=(SELECT count(*) FROM discussion_upvote WHERE discussion_id=? AND reply_id=?) WHERE id=?",
+			discussionID, replyID, replyID)
+		return err
+	}
+	_, err := e.Exec("UPDATE `discussion` SET num_upvotes=(SELECT count(*) FROM discussion_upvote WHERE discussion_id=? AND reply_id=0) WHERE id=?",
+		discussionID, discussionID)
+	return err
+}
+
+// SetDiscussionUpvote adds or removes the upvote of a user on a discussion,
+// or on one of its replies if replyID is not zero.
+func SetDiscussionUpvote(userID int64, d *Discussion, replyID int64, upvote bool) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if replyID > 0 {
+		if r, err := getDiscussionReplyByID(sess, replyID); err != nil {
+			return err
+		} else if r.DiscussionID != d.ID {
+			return ErrDiscussionReplyNotExist{ID: replyID, DiscussionID: d.ID}
+		}
+	}
+
+	vote := &DiscussionUpvote{
+		UserID:       userID,
+		DiscussionID: d.ID,
+		ReplyID:      replyID,
+	}
+	has, err := sess.Exist(vote)
+	if err != nil {
+		return err
+	}
+	if upvote && !has {
+		_, err = sess.Insert(vote)
+	} else if !upvote && has {
+		_, err = sess.Delete(vote)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = updateDiscussionUpvotes(sess, d.ID, replyID); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetDiscussionUpvotes returns which parts of a discussion a user upvoted.
+// The result maps reply IDs to true, the discussion itself has key 0.
+func GetDiscussionUpvotes(userID, discussionID int64) (map[int64]bool, error) {
+	votes := make([]*DiscussionUpvote, 0, 10)
+	if err := x.Where("user_id = ? AND discussion_id = ?", userID, discussionID).Find(&votes); err != nil {
+		return nil, err
+	}
+
+	upvoted := make(map[int64]bool, len(votes))
+	for _, vote := range votes {
+		upvoted[vote.ReplyID] = true
+	}
+	return upvoted, nil
+}
+
+// getDiscussionParticipantIDs returns the IDs of the poster of a discussion
+// and everyone who replied to it.
+func getDiscussionParticipantIDs(e Engine, d *Discussion) ([]int64, error) {
+	userIDs := make([]int64, 0, 5)
+	if err := e.Table("discussion_reply").
+		Cols("poster_id").
+		Where(builder.Eq{"discussion_id": d.ID}).
+		Distinct("poster_id").
+		Find(&userIDs); err != nil {
+		return nil, err
+	}
+	return append(userIDs, d.PosterID), nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDiscussion(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	d := &Discussion{
+		RepoID:     1,
+		CategoryID: 3,
+		PosterID:   2,
+		Title:      " new idea ",
+		Content:    "content",
+	}
+	assert.NoError(t, NewDiscussion(d))
+	assert.EqualValues(t, 3, d.Index)
+	AssertExistsAndLoadBean(t, &Discussion{ID: d.ID, Index: 3, Title: "new idea"})
+	AssertExistsAndLoadBean(t, &DiscussionCategory{ID: 3, NumDiscussions: 1})
+
+	// category of another repository
+	err := NewDiscussion(&Discussion{RepoID: 2, CategoryID: 1, PosterID: 2, Title: "title"})
+	assert.True(t, IsErrDiscussionCategoryNotExist(err))
+}
+
+func TestFindDiscussions(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	discussions, count, err := FindDiscussions(FindDiscussionsOptions{RepoID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	if assert.Len(t, discussions, 2) {
+		// most recently updated first
+		assert.EqualValues(t, 1, discussions[0].ID)
+		assert.EqualValues(t, 2, discussions[1].ID)
+	}
+
+	discussions, count, err = FindDiscussions(FindDiscussionsOptions{RepoID: 1, CategoryID: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	assert.Len(t, discussions, 1)
+
+	_, count, err = FindDiscussions(FindDiscussionsOptions{RepoID: 1, Answered: util.OptionalBoolTrue})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+
+	_, count, err = FindDiscussions(FindDiscussionsOptions{RepoID: 1, Keyword: "build"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
+
+func TestDeleteDiscussionCategory(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	err := DeleteDiscussionCategory(1, 1)
+	assert.True(t, IsErrDiscussionCategoryNotEmpty(err))
+
+	assert.NoError(t, DeleteDiscussionCategory(1, 3))
+	AssertNotExistsBean(t, &DiscussionCategory{ID: 3})
+}
+
+func TestDiscussionReplies(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	d := AssertExistsAndLoadBean(t, &Discussion{ID: 2}).(*Discussion)
+
+	reply, err := NewDiscussionReply(doer, d, 3, "nested reply")
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &Discussion{ID: 2, NumReplies: 3})
+
+	_, err = NewDiscussionReply(doer, d, 1, "parent in another discussion")
+	assert.True(t, IsErrDiscussionReplyNotExist(err))
+
+	replies, err := GetDiscussionReplies(d.ID)
+	assert.NoError(t, err)
+	roots := BuildDiscussionReplyTree(replies)
+	if assert.Len(t, roots, 1) && assert.Len(t, roots[0].Children, 1) && assert.Len(t, roots[0].Children[0].Children, 1) {
+		assert.EqualValues(t, reply.ID, roots[0].Children[0].Children[0].ID)
+	}
+
+	// nested replies move up when their parent is deleted
+	parent := AssertExistsAndLoadBean(t, &DiscussionReply{ID: 3}).(*DiscussionReply)
+	assert.NoError(t, DeleteDiscussionReply(d, parent))
+	AssertExistsAndLoadBean(t, &DiscussionReply{ID: reply.ID, ParentID: 2})
+	AssertExistsAndLoadBean(t, &Discussion{ID: 2, NumReplies: 2})
+}
+
+func TestDiscussion_SetAnswer(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	d := AssertExistsAndLoadBean(t, &Discussion{ID: 2}).(*Discussion)
+	assert.NoError(t, d.SetAnswer(2))
+	AssertExistsAndLoadBean(t, &Discussion{ID: 2, AnswerID: 2})
+
+	err := d.SetAnswer(1)
+	assert.True(t, IsErrDiscussionReplyNotExist(err))
+
+	// deleting the answer unmarks it
+	assert.NoError(t, DeleteDiscussionReply(d, AssertExistsAndLoadBean(t, &DiscussionReply{ID: 2}).(*DiscussionReply)))
+	AssertExistsAndLoadBean(t, &Discussion{ID: 2, AnswerID: 0})
+
+	d = AssertExistsAndLoadBean(t, &Discussion{ID: 1}).(*Discussion)
+	err = d.SetAnswer(1)
+	assert.True(t, IsErrDiscussionNotAnswerable(err))
+}
+
+func TestSetDiscussionUpvote(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	d := AssertExistsAndLoadBean(t, &Discussion{ID: 1}).(*Discussion)
+
+	assert.NoError(t, SetDiscussionUpvote(2, d, 0, true))
+	assert.NoError(t, SetDiscussionUpvote(2, d, 0, true))
+	AssertExistsAndLoadBean(t, &Discussion{ID: 1, NumUpvotes: 2})
+
+	assert.NoError(t, SetDiscussionUpvote(2, d, 1, true))
+	AssertExistsAndLoadBean(t, &DiscussionReply{ID: 1, NumUpvotes: 1})
+
+	upvoted, err := GetDiscussionUpvotes(2, d.ID)
+	assert.NoError(t, err)
+	assert.True(t, upvoted[0])
+	assert.True(t, upvoted[1])
+
+	assert.NoError(t, SetDiscussionUpvote(4, d, 0, false))
+	AssertExistsAndLoadBean(t, &Discussion{ID: 1, NumUpvotes: 1})
+
+	err = SetDiscussionUpvote(2, d, 2, true)
+	assert.True(t, IsErrDiscussionReplyNotExist(err))
+}
+
+func TestDeleteDiscussion(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	d := AssertExistsAndLoadBean(t, &Discussion{ID: 1}).(*Discussion)
+	assert.NoError(t, DeleteDiscussion(d))
+	AssertNotExistsBean(t, &Discussion{ID: 1})
+	AssertNotExistsBean(t, &DiscussionReply{DiscussionID: 1})
+	AssertNotExistsBean(t, &DiscussionUpvote{DiscussionID: 1})
+	AssertExistsAndLoadBean(t, &DiscussionCategory{ID: 1, NumDiscussions: 0})
+}
+
+func TestCreateOrUpdateDiscussionNotifications(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, CreateOrUpdateDiscussionNotifications(1, 4))
+
+	// the poster of the discussion and the watchers of the repository are notified
+	notf := AssertExistsAndLoadBean(t, &Notification{UserID: 2, DiscussionID: 1}).(*Notification)
+	assert.Equal(t, NotificationStatusUnread, notf.Status)
+	assert.Equal(t, NotificationSourceDiscussion, notf.Source)
+	AssertExistsAndLoadBean(t, &Notification{UserID: 1, DiscussionID: 1})
+	AssertNotExistsBean(t, &Notification{UserID: 4, DiscussionID: 1})
+
+	assert.NoError(t, SetDiscussionNotificationStatusRead(2, 1))
+	AssertExistsAndLoadBean(t, &Notification{UserID: 2, DiscussionID: 1, Status: NotificationStatusRead})
+
+	notifications := NotificationList{notf}
+	failures, err := notifications.LoadDiscussions()
+	assert.NoError(t, err)
+	assert.Empty(t, failures)
+	assert.EqualValues(t, 1, notf.Discussion.ID)
+	assert.Contains(t, notf.HTMLURL(), "/user2/repo1/discussions/1")
+}
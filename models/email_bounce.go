@@ -0,0 +1,169 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// EmailBounceType is the kind of a bounce reported for an e-mail address
+type EmailBounceType int
+
+// The kinds of bounces
+const (
+	// EmailBounceSoft is a temporary failure like a full mailbox
+	EmailBounceSoft EmailBounceType = iota + 1
+	// EmailBounceHard is a permanent failure like an unknown address
+	EmailBounceHard
+	// EmailBounceComplaint is a recipient marking a mail as spam
+	EmailBounceComplaint
+)
+
+// ParseEmailBounceType returns the bounce type of its name, soft bounce is returned for unknown names
+func ParseEmailBounceType(name string) EmailBounceType {
+	switch strings.ToLower(name) {
+	case "hard", "permanent":
+		return EmailBounceHard
+	case "complaint", "spam":
+		return EmailBounceComplaint
+	}
+	return EmailBounceSoft
+}
+
+// Name returns the name of the bounce type
+func (t EmailBounceType) Name() string {
+	switch t {
+	case EmailBounceHard:
+		return "hard"
+	case EmailBounceComplaint:
+		return "complaint"
+	}
+	return "soft"
+}
+
+// EmailBounce records the bounces reported for an e-mail address, the mailer does not send
+// to a suppressed address anymore.
+type EmailBounce struct {
+	ID    int64  `xorm:"pk autoincr"`
+	Email string `xorm:"UNIQUE NOT NULL"`
+	// Type, Reason and Source describe the last bounce
+	Type   EmailBounceType `xorm:"NOT NULL DEFAULT 1"`
+	Reason string          `xorm:"TEXT"`
+	// Source is where the bounce was reported: smtp, webhook or imap
+	Source       string             `xorm:"VARCHAR(20)"`
+	Count        int                `xorm:"NOT NULL DEFAULT 0"`
+	IsSuppressed bool               `xorm:"INDEX NOT NULL DEFAULT false"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+}
+
+// ErrEmailBounceNotExist represents a "EmailBounceNotExist" kind of error.
+type ErrEmailBounceNotExist struct {
+	ID int64
+}
+
+// IsErrEmailBounceNotExist checks if an error is a ErrEmailBounceNotExist.
+func IsErrEmailBounceNotExist(err error) bool {
+	_, ok := err.(ErrEmailBounceNotExist)
+	return ok
+}
+
+func (err ErrEmailBounceNotExist) Error() string {
+	return fmt.Sprintf("email bounce does not exist [id: %d]", err.ID)
+}
+
+// RecordEmailBounce records a bounce of the address, the address is suppressed after a hard
+// bounce, a complaint or softLimit soft bounces. softLimit 0 never suppresses soft bounces.
+func RecordEmailBounce(email string, typ EmailBounceType, reason, source string, softLimit int) (*EmailBounce, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	bounce := &EmailBounce{Email: strings.ToLower(strings.TrimSpace(email))}
+	has, err := sess.Get(bounce)
+	if err != nil {
+		return nil, err
+	}
+	bounce.Type = typ
+	bounce.Reason = reason
+	bounce.Source = source
+	bounce.Count++
+	if typ != EmailBounceSoft || (softLimit > 0 && bounce.Count >= softLimit) {
+		bounce.IsSuppressed = true
+	}
+	if has {
+		_, err = sess.ID(bounce.ID).Cols("type", "reason", "source", "count", "is_suppressed").Update(bounce)
+	} else {
+		_, err = sess.Insert(bounce)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bounce, sess.Commit()
+}
+
+// GetSuppressedEmails returns the addresses among emails which are suppressed, in lower case.
+func GetSuppressedEmails(emails []string) (map[string]bool, error) {
+	lowered := make([]string, 0, len(emails))
+	for _, email := range emails {
+		lowered = append(lowered, strings.ToLower(email))
+	}
+	suppressed := make([]string, 0, len(lowered))
+	if err := x.Table("email_bounce").
+		Where(builder.In("email", lowered).And(builder.Eq{"is_suppressed": true})).
+		Cols("email").Find(&suppressed); err != nil {
+		return nil, err
+	}
+	set := make(map[string]bool, len(suppressed))
+	for _, email := range suppressed {
+		set[email] = true
+	}
+	return set, nil
+}
+
+// SearchEmailBounceOptions are the options to search the bounces
+type SearchEmailBounceOptions struct {
+	ListOptions
+	Keyword        string
+	SuppressedOnly bool
+}
+
+// SearchEmailBounces returns the bounces matching the options, last updated first, and their count.
+func SearchEmailBounces(opts *SearchEmailBounceOptions) ([]*EmailBounce, int64, error) {
+	cond := builder.NewCond()
+	if len(opts.Keyword) > 0 {
+		cond = cond.And(builder.Like{"email", strings.ToLower(opts.Keyword)})
+	}
+	if opts.SuppressedOnly {
+		cond = cond.And(builder.Eq{"is_suppressed": true})
+	}
+
+	count, err := x.Where(cond).Count(new(EmailBounce))
+	if err != nil {
+		return nil, 0, err
+	}
+	bounces := make([]*EmailBounce, 0, opts.PageSize)
+	sess := opts.setSessionPagination(x.Where(cond).OrderBy("updated_unix DESC, id DESC"))
+	return bounces, count, sess.Find(&bounces)
+}
+
+// DeleteEmailBounce removes the bounces of an address, so that mails are sent to it again.
+func DeleteEmailBounce(id int64) (*EmailBounce, error) {
+	bounce := new(EmailBounce)
+	if has, err := x.ID(id).Get(bounce); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrEmailBounceNotExist{ID: id}
+	}
+	_, err := x.ID(id).Delete(new(EmailBounce))
+	return bounce, err
+}
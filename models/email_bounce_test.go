@@ -0,0 +1,64 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordEmailBounce(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	bounce, err := RecordEmailBounce("User2@Example.com", EmailBounceSoft, "mailbox full", "imap", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "user2@example.com", bounce.Email)
+	assert.Equal(t, 1, bounce.Count)
+	assert.False(t, bounce.IsSuppressed)
+
+	suppressed, err := GetSuppressedEmails([]string{"user2@example.com"})
+	assert.NoError(t, err)
+	assert.Empty(t, suppressed)
+
+	bounce, err = RecordEmailBounce("user2@example.com", EmailBounceSoft, "mailbox still full", "imap", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, bounce.Count)
+	assert.True(t, bounce.IsSuppressed)
+	AssertExistsAndLoadBean(t, &EmailBounce{Email: "user2@example.com", Count: 2, Reason: "mailbox still full"})
+
+	bounce, err = RecordEmailBounce("user4@example.com", EmailBounceHard, "5.1.1 unknown user", "webhook", 0)
+	assert.NoError(t, err)
+	assert.True(t, bounce.IsSuppressed)
+	_, err = RecordEmailBounce("user5@example.com", EmailBounceSoft, "mailbox full", "smtp", 0)
+	assert.NoError(t, err)
+
+	suppressed, err = GetSuppressedEmails([]string{"USER2@example.com", "user4@example.com", "user5@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"user2@example.com": true, "user4@example.com": true}, suppressed)
+
+	bounces, count, err := SearchEmailBounces(&SearchEmailBounceOptions{SuppressedOnly: true})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	assert.Len(t, bounces, 2)
+	_, count, err = SearchEmailBounces(&SearchEmailBounceOptions{Keyword: "user5"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	deleted, err := DeleteEmailBounce(bounce.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "user4@example.com", deleted.Email)
+	AssertNotExistsBean(t, &EmailBounce{ID: bounce.ID})
+	_, err = DeleteEmailBounce(bounce.ID)
+	assert.True(t, IsErrEmailBounceNotExist(err))
+}
+
+func TestParseEmailBounceType(t *testing.T) {
+	assert.Equal(t, EmailBounceHard, ParseEmailBounceType("hard"))
+	assert.Equal(t, EmailBounceComplaint, ParseEmailBounceType("Complaint"))
+	assert.Equal(t, EmailBounceSoft, ParseEmailBounceType("soft"))
+	assert.Equal(t, EmailBounceSoft, ParseEmailBounceType("unknown"))
+	assert.Equal(t, "complaint", EmailBounceComplaint.Name())
+}
@@ -7,6 +7,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 
 	"code.gitea.io/gitea/modules/git"
 )
@@ -624,6 +625,22 @@ func (err ErrLastOrgOwner) Error() string {
 	return fmt.Sprintf("user is the last member of owner team [uid: %d]", err.UID)
 }
 
+// ErrOrgUserNotExist represents a "OrgUserNotExist" kind of error.
+type ErrOrgUserNotExist struct {
+	OrgID int64
+	UID   int64
+}
+
+// IsErrOrgUserNotExist checks if an error is a ErrOrgUserNotExist.
+func IsErrOrgUserNotExist(err error) bool {
+	_, ok := err.(ErrOrgUserNotExist)
+	return ok
+}
+
+func (err ErrOrgUserNotExist) Error() string {
+	return fmt.Sprintf("user is not a member of the organization [org_id: %d, uid: %d]", err.OrgID, err.UID)
+}
+
 //.____   ____________________
 //|    |  \_   _____/   _____/
 //|    |   |    __) \_____  \
@@ -776,6 +793,21 @@ func (err ErrRepoRedirectNotExist) Error() string {
 	return fmt.Sprintf("repository redirect does not exist [uid: %d, name: %s]", err.OwnerID, err.RepoName)
 }
 
+// ErrPagesDomainAlreadyUsed represents a "PagesDomainAlreadyUsed" kind of error.
+type ErrPagesDomainAlreadyUsed struct {
+	Domain string
+}
+
+// IsErrPagesDomainAlreadyUsed checks if an error is a ErrPagesDomainAlreadyUsed.
+func IsErrPagesDomainAlreadyUsed(err error) bool {
+	_, ok := err.(ErrPagesDomainAlreadyUsed)
+	return ok
+}
+
+func (err ErrPagesDomainAlreadyUsed) Error() string {
+	return fmt.Sprintf("pages domain is already used [domain: %s]", err.Domain)
+}
+
 // ErrInvalidCloneAddr represents a "InvalidCloneAddr" kind of error.
 type ErrInvalidCloneAddr struct {
 	IsURLError         bool
@@ -1147,6 +1179,52 @@ func (err ErrSHAOrCommitIDNotProvided) Error() string {
 	return "a SHA or commmit ID must be proved when updating a file"
 }
 
+// ErrCherryPickConflict represents a "CherryPickConflict" kind of error.
+type ErrCherryPickConflict struct {
+	SHA   string
+	Files []string
+}
+
+// IsErrCherryPickConflict checks if an error is a ErrCherryPickConflict.
+func IsErrCherryPickConflict(err error) bool {
+	_, ok := err.(ErrCherryPickConflict)
+	return ok
+}
+
+func (err ErrCherryPickConflict) Error() string {
+	return fmt.Sprintf("commit %s does not apply cleanly [files: %s]", err.SHA, strings.Join(err.Files, ", "))
+}
+
+// ErrCherryPickMergeCommit represents a "CherryPickMergeCommit" kind of error.
+type ErrCherryPickMergeCommit struct {
+	SHA string
+}
+
+// IsErrCherryPickMergeCommit checks if an error is a ErrCherryPickMergeCommit.
+func IsErrCherryPickMergeCommit(err error) bool {
+	_, ok := err.(ErrCherryPickMergeCommit)
+	return ok
+}
+
+func (err ErrCherryPickMergeCommit) Error() string {
+	return fmt.Sprintf("commit %s is a merge commit and cannot be cherry-picked or reverted", err.SHA)
+}
+
+// ErrCherryPickEmpty represents a "CherryPickEmpty" kind of error.
+type ErrCherryPickEmpty struct {
+	SHA string
+}
+
+// IsErrCherryPickEmpty checks if an error is a ErrCherryPickEmpty.
+func IsErrCherryPickEmpty(err error) bool {
+	_, ok := err.(ErrCherryPickEmpty)
+	return ok
+}
+
+func (err ErrCherryPickEmpty) Error() string {
+	return fmt.Sprintf("commit %s makes no changes to the branch", err.SHA)
+}
+
 //  __      __      ___.   .__                   __
 // /  \    /  \ ____\_ |__ |  |__   ____   ____ |  | __
 // \   \/\/   // __ \| __ \|  |  \ /  _ \ /  _ \|  |/ /
@@ -1169,6 +1247,22 @@ func (err ErrWebhookNotExist) Error() string {
 	return fmt.Sprintf("webhook does not exist [id: %d]", err.ID)
 }
 
+// ErrHookTaskNotExist represents a "HookTaskNotExist" kind of error.
+type ErrHookTaskNotExist struct {
+	ID     int64
+	HookID int64
+}
+
+// IsErrHookTaskNotExist checks if an error is a ErrHookTaskNotExist.
+func IsErrHookTaskNotExist(err error) bool {
+	_, ok := err.(ErrHookTaskNotExist)
+	return ok
+}
+
+func (err ErrHookTaskNotExist) Error() string {
+	return fmt.Sprintf("hook task does not exist [id: %d, hook_id: %d]", err.ID, err.HookID)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -1424,6 +1518,42 @@ func (err ErrMergeUnrelatedHistories) Error() string {
 	return fmt.Sprintf("Merge UnrelatedHistories Error: %v: %s\n%s", err.Err, err.StdErr, err.StdOut)
 }
 
+// ErrMergeDivergingFastForwardOnly represents an error if a fast-forward-only merge fails
+// because the base branch has diverged from the head branch
+type ErrMergeDivergingFastForwardOnly struct {
+	StdOut string
+	StdErr string
+	Err    error
+}
+
+// IsErrMergeDivergingFastForwardOnly checks if an error is a ErrMergeDivergingFastForwardOnly.
+func IsErrMergeDivergingFastForwardOnly(err error) bool {
+	_, ok := err.(ErrMergeDivergingFastForwardOnly)
+	return ok
+}
+
+func (err ErrMergeDivergingFastForwardOnly) Error() string {
+	return fmt.Sprintf("Merge DivergingFastForwardOnly Error: %v: %s\n%s", err.Err, err.StdErr, err.StdOut)
+}
+
+// ErrMergeStyleNotAllowedForBranch represents an error if the branch protection of the base
+// branch does not allow the merge strategy
+type ErrMergeStyleNotAllowedForBranch struct {
+	Branch string
+	Style  MergeStyle
+}
+
+// IsErrMergeStyleNotAllowedForBranch checks if an error is a ErrMergeStyleNotAllowedForBranch.
+func IsErrMergeStyleNotAllowedForBranch(err error) bool {
+	_, ok := err.(ErrMergeStyleNotAllowedForBranch)
+	return ok
+}
+
+func (err ErrMergeStyleNotAllowedForBranch) Error() string {
+	return fmt.Sprintf("merge strategy is not allowed by the branch protection [branch: %s, strategy: %s]",
+		err.Branch, err.Style)
+}
+
 // ErrRebaseConflicts represents an error if rebase fails with a conflict
 type ErrRebaseConflicts struct {
 	Style     MergeStyle
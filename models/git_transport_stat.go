@@ -0,0 +1,230 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// GitTransferKind is the kind of a git transfer
+type GitTransferKind string
+
+// The kinds of git transfers
+const (
+	GitTransferClone GitTransferKind = "clone"
+	GitTransferFetch GitTransferKind = "fetch"
+	// GitTransferNegotiation is a request of a fetch negotiating the commits in common, the
+	// fetches over HTTP send one until the client is done
+	GitTransferNegotiation GitTransferKind = "negotiation"
+	GitTransferPush        GitTransferKind = "push"
+)
+
+// GitTransportDepthDeepen is the depth of the shallow fetches limited by a date or a ref
+const GitTransportDepthDeepen = -1
+
+// gitTransportMaxDepth is the largest depth recorded as is, the larger ones are rounded up to a
+// power of ten so that the depths requested do not create too many statistics
+const gitTransportMaxDepth = 10
+
+// gitTransportFilters are the object filters recorded, the others are recorded as "other"
+var gitTransportFilters = []string{"blob:none", "blob:limit", "tree:0", "tree", "sparse:oid", "object:type", "combine"}
+
+// GitTransportStat aggregates the git transfers over HTTP of the same shape on a day, it does not
+// record who transferred what
+type GitTransportStat struct {
+	ID   int64              `xorm:"pk autoincr"`
+	Day  timeutil.TimeStamp `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Kind GitTransferKind    `xorm:"VARCHAR(20) UNIQUE(s) NOT NULL"`
+	// Protocol is the version of the git wire protocol
+	Protocol int `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	// Depth is the depth of a shallow fetch, 0 for the full history
+	Depth         int    `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	Filter        string `xorm:"VARCHAR(20) UNIQUE(s) NOT NULL DEFAULT ''"`
+	Requests      int64  `xorm:"NOT NULL DEFAULT 0"`
+	RequestBytes  int64  `xorm:"NOT NULL DEFAULT 0"`
+	ResponseBytes int64  `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// ParseGitProtocolVersion returns the version of the git wire protocol of the value of the
+// Git-Protocol header or the GIT_PROTOCOL environment variable
+func ParseGitProtocolVersion(protocol string) int {
+	for _, param := range strings.Split(protocol, ":") {
+		switch param {
+		case "version=1":
+			return 1
+		case "version=2":
+			return 2
+		}
+	}
+	return 0
+}
+
+// gitTransportDepth returns the recorded depth of a shallow fetch
+func gitTransportDepth(depth int) int {
+	if depth <= gitTransportMaxDepth {
+		return depth
+	}
+	rounded := gitTransportMaxDepth
+	for rounded < depth {
+		rounded *= 10
+	}
+	return rounded
+}
+
+// gitTransportFilter returns the recorded kind of an object filter
+func gitTransportFilter(filter string) string {
+	if filter == "" {
+		return ""
+	}
+	if strings.HasPrefix(filter, "combine:") {
+		return "combine"
+	}
+	if idx := strings.IndexByte(filter, '='); idx >= 0 {
+		filter = filter[:idx]
+	} else if strings.HasPrefix(filter, "tree:") && filter != "tree:0" {
+		filter = "tree"
+	}
+	for _, known := range gitTransportFilters {
+		if filter == known {
+			return filter
+		}
+	}
+	return "other"
+}
+
+// NewGitUploadPackStat returns the statistic of a request to git upload-pack, or nil if it does
+// not transfer objects, e.g. a protocol version 2 request listing the refs
+func NewGitUploadPackStat(protocol string, req *git.UploadPackRequest) *GitTransportStat {
+	if req.Wants == 0 || (req.Command != "" && req.Command != "fetch") {
+		return nil
+	}
+	stat := &GitTransportStat{
+		Kind:     GitTransferClone,
+		Protocol: ParseGitProtocolVersion(protocol),
+		Depth:    gitTransportDepth(req.Depth),
+		Filter:   gitTransportFilter(req.Filter),
+	}
+	if req.Deepen && req.Depth == 0 {
+		stat.Depth = GitTransportDepthDeepen
+	}
+	if req.Haves > 0 {
+		stat.Kind = GitTransferFetch
+		if !req.Done {
+			stat.Kind = GitTransferNegotiation
+		}
+	}
+	return stat
+}
+
+// FormatDay returns the day of the statistic, the days are in UTC
+func (s *GitTransportStat) FormatDay() string {
+	return s.Day.FormatInLocation("2006-01-02", time.UTC)
+}
+
+// IsShallow returns if the statistic is of shallow fetches
+func (s *GitTransportStat) IsShallow() bool {
+	return s.Depth != 0
+}
+
+// IsDepthRounded returns if the depth of the statistic is the upper bound of the depths recorded
+func (s *GitTransportStat) IsDepthRounded() bool {
+	return s.Depth > gitTransportMaxDepth
+}
+
+// AddGitTransportStat adds a transfer to the statistics of the day
+func AddGitTransportStat(stat *GitTransportStat) error {
+	stat.Day = timeutil.TimeStamp(startOfDay(time.Now()).Unix())
+	stat.Requests = 1
+
+	cond := "day = ? AND kind = ? AND protocol = ? AND depth = ? AND filter = ?"
+	args := []interface{}{stat.Day, stat.Kind, stat.Protocol, stat.Depth, stat.Filter}
+	update := func() (int64, error) {
+		return x.Where(cond, args...).
+			Incr("requests").
+			Incr("request_bytes", stat.RequestBytes).
+			Incr("response_bytes", stat.ResponseBytes).
+			Update(new(GitTransportStat))
+	}
+	if affected, err := update(); err != nil || affected > 0 {
+		return err
+	}
+	if _, err := x.Insert(stat); err != nil {
+		// the statistic of the day may have been inserted by a concurrent transfer
+		if affected, uerr := update(); uerr != nil || affected == 0 {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetGitTransportStats returns the statistics of the transfers since the day of the time
+func GetGitTransportStats(since time.Time) ([]*GitTransportStat, error) {
+	stats := make([]*GitTransportStat, 0, 50)
+	return stats, x.Where("day >= ?", startOfDay(since).Unix()).
+		OrderBy("day").
+		Find(&stats)
+}
+
+// DeleteOldGitTransportStats deletes the statistics of the transfers older than the duration
+func DeleteOldGitTransportStats(ctx context.Context, olderThan time.Duration) error {
+	if olderThan <= 0 {
+		return nil
+	}
+	_, err := x.Where("day < ?", startOfDay(time.Now().Add(-olderThan)).Unix()).
+		Delete(new(GitTransportStat))
+	return err
+}
+
+// GitTransportSummary sums the statistics of the transfers sharing a value
+type GitTransportSummary struct {
+	// Stat holds the value shared by the summed statistics
+	Stat          *GitTransportStat
+	Requests      int64
+	RequestBytes  int64
+	ResponseBytes int64
+}
+
+// AverageResponseBytes returns the average size of the responses to the transfers
+func (s *GitTransportSummary) AverageResponseBytes() int64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.ResponseBytes / s.Requests
+}
+
+// SumGitTransportStats sums the statistics by the key of their shared value, the sums are
+// sorted by descending number of requests
+func SumGitTransportStats(stats []*GitTransportStat, key func(*GitTransportStat) interface{}) []*GitTransportSummary {
+	summaries := make(map[interface{}]*GitTransportSummary)
+	for _, stat := range stats {
+		k := key(stat)
+		summary, ok := summaries[k]
+		if !ok {
+			summary = &GitTransportSummary{Stat: stat}
+			summaries[k] = summary
+		}
+		summary.Requests += stat.Requests
+		summary.RequestBytes += stat.RequestBytes
+		summary.ResponseBytes += stat.ResponseBytes
+	}
+
+	sums := make([]*GitTransportSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		sums = append(sums, summary)
+	}
+	sort.Slice(sums, func(i, j int) bool {
+		if sums[i].Requests != sums[j].Requests {
+			return sums[i].Requests > sums[j].Requests
+		}
+		return sums[i].ResponseBytes > sums[j].ResponseBytes
+	})
+	return sums
+}
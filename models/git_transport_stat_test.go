@@ -0,0 +1,65 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGitUploadPackStat(t *testing.T) {
+	stat := NewGitUploadPackStat("", &git.UploadPackRequest{Wants: 1, Done: true})
+	assert.Equal(t, &GitTransportStat{Kind: GitTransferClone}, stat)
+
+	stat = NewGitUploadPackStat("version=2", &git.UploadPackRequest{Command: "fetch", Wants: 2, Depth: 1, Filter: "blob:limit=1m"})
+	assert.Equal(t, &GitTransportStat{Kind: GitTransferClone, Protocol: 2, Depth: 1, Filter: "blob:limit"}, stat)
+
+	stat = NewGitUploadPackStat("version=1", &git.UploadPackRequest{Wants: 1, Haves: 3, Done: true, Depth: 42, Filter: "tree:3"})
+	assert.Equal(t, &GitTransportStat{Kind: GitTransferFetch, Protocol: 1, Depth: 100, Filter: "tree"}, stat)
+	assert.True(t, stat.IsShallow())
+	assert.True(t, stat.IsDepthRounded())
+
+	stat = NewGitUploadPackStat("", &git.UploadPackRequest{Wants: 1, Haves: 32, Deepen: true, Filter: "combine:blob:none+tree:0"})
+	assert.Equal(t, &GitTransportStat{Kind: GitTransferNegotiation, Depth: GitTransportDepthDeepen, Filter: "combine"}, stat)
+
+	assert.Equal(t, "other", NewGitUploadPackStat("", &git.UploadPackRequest{Wants: 1, Filter: "unknown"}).Filter)
+	assert.Nil(t, NewGitUploadPackStat("version=2", &git.UploadPackRequest{Command: "ls-refs"}))
+	assert.Nil(t, NewGitUploadPackStat("", &git.UploadPackRequest{Done: true}))
+}
+
+func TestAddGitTransportStat(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, AddGitTransportStat(&GitTransportStat{Kind: GitTransferClone, Depth: 1, RequestBytes: 100, ResponseBytes: 1000}))
+	assert.NoError(t, AddGitTransportStat(&GitTransportStat{Kind: GitTransferClone, Depth: 1, RequestBytes: 100, ResponseBytes: 3000}))
+	assert.NoError(t, AddGitTransportStat(&GitTransportStat{Kind: GitTransferPush, Protocol: 2, RequestBytes: 500, ResponseBytes: 50}))
+
+	stats, err := GetGitTransportStats(time.Now())
+	assert.NoError(t, err)
+	assert.Len(t, stats, 2)
+
+	sums := SumGitTransportStats(stats, func(s *GitTransportStat) interface{} { return s.Kind })
+	if assert.Len(t, sums, 2) {
+		assert.Equal(t, GitTransferClone, sums[0].Stat.Kind)
+		assert.EqualValues(t, 2, sums[0].Requests)
+		assert.EqualValues(t, 200, sums[0].RequestBytes)
+		assert.EqualValues(t, 4000, sums[0].ResponseBytes)
+		assert.EqualValues(t, 2000, sums[0].AverageResponseBytes())
+		assert.Equal(t, GitTransferPush, sums[1].Stat.Kind)
+		assert.EqualValues(t, 1, sums[1].Requests)
+	}
+
+	assert.NoError(t, DeleteOldGitTransportStats(context.Background(), 24*time.Hour))
+	AssertCount(t, &GitTransportStat{}, 2)
+	_, err = x.Exec("UPDATE git_transport_stat SET day = day - ?", 3*24*60*60)
+	assert.NoError(t, err)
+	assert.NoError(t, DeleteOldGitTransportStats(context.Background(), 24*time.Hour))
+	AssertCount(t, &GitTransportStat{}, 0)
+}
@@ -34,13 +34,19 @@ type Issue struct {
 	PosterID         int64       `xorm:"INDEX"`
 	Poster           *User       `xorm:"-"`
 	OriginalAuthor   string
-	OriginalAuthorID int64      `xorm:"index"`
-	Title            string     `xorm:"name"`
-	Content          string     `xorm:"TEXT"`
-	RenderedContent  string     `xorm:"-"`
-	Labels           []*Label   `xorm:"-"`
-	MilestoneID      int64      `xorm:"INDEX"`
-	Milestone        *Milestone `xorm:"-"`
+	OriginalAuthorID int64          `xorm:"index"`
+	Title            string         `xorm:"name"`
+	Content          string         `xorm:"TEXT"`
+	RenderedContent  string         `xorm:"-"`
+	Labels           []*Label       `xorm:"-"`
+	MilestoneID      int64          `xorm:"INDEX"`
+	Milestone        *Milestone     `xorm:"-"`
+	WorkflowStateID  int64          `xorm:"INDEX NOT NULL DEFAULT 0"`
+	WorkflowState    *WorkflowState `xorm:"-"`
+	EpicID           int64          `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Epic             *Epic          `xorm:"-"`
+	AnswerCommentID  int64          `xorm:"INDEX NOT NULL DEFAULT 0"`
+	AnswerComment    *Comment       `xorm:"-"`
 	Priority         int
 	AssigneeID       int64        `xorm:"-"`
 	Assignee         *User        `xorm:"-"`
@@ -274,6 +280,14 @@ func (issue *Issue) loadAttributes(e Engine) (err error) {
 		return
 	}
 
+	if err = issue.loadWorkflowState(e); err != nil {
+		return
+	}
+
+	if err = issue.loadEpic(e); err != nil {
+		return
+	}
+
 	if err = issue.loadAssignees(e); err != nil {
 		return
 	}
@@ -607,6 +621,10 @@ func (issue *Issue) doChangeStatus(e *xorm.Session, doer *User, isMergePull bool
 		return nil, err
 	}
 
+	if err := issue.syncWorkflowStateWithStatus(e); err != nil {
+		return nil, err
+	}
+
 	// Update issue count of labels
 	if err := issue.getLabels(e); err != nil {
 		return nil, err
@@ -622,6 +640,12 @@ func (issue *Issue) doChangeStatus(e *xorm.Session, doer *User, isMergePull bool
 		return nil, err
 	}
 
+	if issue.EpicID > 0 {
+		if err := updateEpicNums(e, issue.EpicID); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := issue.updateClosedNum(e); err != nil {
 		return nil, err
 	}
@@ -740,6 +764,9 @@ func (issue *Issue) UpdateAttachments(uuids []string) (err error) {
 	if err != nil {
 		return fmt.Errorf("getAttachmentsByUUIDs [uuids: %v]: %v", uuids, err)
 	}
+	if err := checkIssueAttachmentsSize(sess, issue, attachments); err != nil {
+		return err
+	}
 	for i := 0; i < len(attachments); i++ {
 		attachments[i].IssueID = issue.ID
 		if err := updateAttachment(sess, attachments[i]); err != nil {
@@ -925,6 +952,9 @@ func newIssue(e *xorm.Session, doer *User, opts NewIssueOptions) (err error) {
 		if err != nil {
 			return fmt.Errorf("getAttachmentsByUUIDs [uuids: %v]: %v", opts.Attachments, err)
 		}
+		if err := checkIssueAttachmentsSize(e, opts.Issue, attachments); err != nil {
+			return err
+		}
 
 		for i := 0; i < len(attachments); i++ {
 			attachments[i].IssueID = opts.Issue.ID
@@ -971,7 +1001,7 @@ func newIssueAttempt(repo *Repository, issue *Issue, labelIDs []int64, uuids []s
 		LabelIDs:    labelIDs,
 		Attachments: uuids,
 	}); err != nil {
-		if IsErrUserDoesNotHaveAccessToRepo(err) || IsErrNewIssueInsert(err) {
+		if IsErrUserDoesNotHaveAccessToRepo(err) || IsErrNewIssueInsert(err) || IsErrIssueAttachmentsTooLarge(err) {
 			return err
 		}
 		return fmt.Errorf("newIssue: %v", err)
@@ -1054,6 +1084,16 @@ func GetIssuesByIDs(issueIDs []int64) ([]*Issue, error) {
 	return getIssuesByIDs(x, issueIDs)
 }
 
+// GetIssuesAfterID returns at most limit issues with an ID greater than the
+// given one, ordered by ID
+func GetIssuesAfterID(id int64, limit int) (IssueList, error) {
+	issues := make([]*Issue, 0, limit)
+	return issues, x.Where("id > ?", id).
+		Asc("id").
+		Limit(limit).
+		Find(&issues)
+}
+
 // IssuesOptions represents options of an issue.
 type IssuesOptions struct {
 	ListOptions
@@ -1061,7 +1101,9 @@ type IssuesOptions struct {
 	AssigneeID         int64
 	PosterID           int64
 	MentionedID        int64
+	ReviewRequestedID  int64
 	MilestoneIDs       []int64
+	WorkflowStateID    int64 // -1 means issues without a workflow state
 	IsClosed           util.OptionalBool
 	IsPull             util.OptionalBool
 	LabelIDs           []int64
@@ -1143,10 +1185,26 @@ func (opts *IssuesOptions) setupSession(sess *xorm.Session) {
 			And("issue_user.uid = ?", opts.MentionedID)
 	}
 
+	if opts.ReviewRequestedID > 0 {
+		// the latest review of the user is still the request
+		sess.In("issue.id", builder.Select("issue_id").From("review").
+			Where(builder.Eq{"reviewer_id": opts.ReviewRequestedID, "type": ReviewTypeRequest}).
+			And(builder.In("id", builder.Select("max(id)").From("review").
+				Where(builder.Eq{"reviewer_id": opts.ReviewRequestedID}).
+				And(builder.In("type", ReviewTypeApprove, ReviewTypeReject, ReviewTypeRequest)).
+				GroupBy("issue_id"))))
+	}
+
 	if len(opts.MilestoneIDs) > 0 {
 		sess.In("issue.milestone_id", opts.MilestoneIDs)
 	}
 
+	if opts.WorkflowStateID > 0 {
+		sess.And("issue.workflow_state_id=?", opts.WorkflowStateID)
+	} else if opts.WorkflowStateID == -1 {
+		sess.And("issue.workflow_state_id=?", 0)
+	}
+
 	switch opts.IsPull {
 	case util.OptionalBoolTrue:
 		sess.And("issue.is_pull=?", true)
@@ -1220,8 +1278,16 @@ func GetRepoIDsForIssuesOptions(opts *IssuesOptions, user *User) ([]int64, error
 }
 
 // Issues returns a list of issues by given conditions.
-func Issues(opts *IssuesOptions) ([]*Issue, error) {
-	sess := x.NewSession()
+func Issues(opts *IssuesOptions) (issues []*Issue, err error) {
+	err = withReadEngine(func(e *xorm.Engine) error {
+		issues, err = getIssues(e, opts)
+		return err
+	})
+	return issues, err
+}
+
+func getIssues(e *xorm.Engine, opts *IssuesOptions) ([]*Issue, error) {
+	sess := e.NewSession()
 	defer sess.Close()
 
 	opts.setupSession(sess)
@@ -1233,7 +1299,7 @@ func Issues(opts *IssuesOptions) ([]*Issue, error) {
 	}
 	sess.Close()
 
-	if err := IssueList(issues).LoadAttributes(); err != nil {
+	if err := IssueList(issues).loadAttributes(e); err != nil {
 		return nil, fmt.Errorf("LoadAttributes: %v", err)
 	}
 
@@ -1307,20 +1373,29 @@ func parseCountResult(results []map[string][]byte) int64 {
 
 // IssueStatsOptions contains parameters accepted by GetIssueStats.
 type IssueStatsOptions struct {
-	RepoID      int64
-	Labels      string
-	MilestoneID int64
-	AssigneeID  int64
-	MentionedID int64
-	PosterID    int64
-	IsPull      util.OptionalBool
-	IssueIDs    []int64
+	RepoID          int64
+	Labels          string
+	MilestoneID     int64
+	WorkflowStateID int64
+	AssigneeID      int64
+	MentionedID     int64
+	PosterID        int64
+	IsPull          util.OptionalBool
+	IssueIDs        []int64
 }
 
 // GetIssueStats returns issue statistic information by given conditions.
-func GetIssueStats(opts *IssueStatsOptions) (*IssueStats, error) {
+func GetIssueStats(opts *IssueStatsOptions) (stats *IssueStats, err error) {
+	err = withReadEngine(func(e *xorm.Engine) error {
+		stats, err = getIssueStats(e, opts)
+		return err
+	})
+	return stats, err
+}
+
+func getIssueStats(e *xorm.Engine, opts *IssueStatsOptions) (*IssueStats, error) {
 	if len(opts.IssueIDs) <= maxQueryParameters {
-		return getIssueStatsChunk(opts, opts.IssueIDs)
+		return getIssueStatsChunk(e, opts, opts.IssueIDs)
 	}
 
 	// If too long a list of IDs is provided, we get the statistics in
@@ -1333,7 +1408,7 @@ func GetIssueStats(opts *IssueStatsOptions) (*IssueStats, error) {
 		if chunk > len(opts.IssueIDs) {
 			chunk = len(opts.IssueIDs)
 		}
-		stats, err := getIssueStatsChunk(opts, opts.IssueIDs[i:chunk])
+		stats, err := getIssueStatsChunk(e, opts, opts.IssueIDs[i:chunk])
 		if err != nil {
 			return nil, err
 		}
@@ -1348,11 +1423,11 @@ func GetIssueStats(opts *IssueStatsOptions) (*IssueStats, error) {
 	return accum, nil
 }
 
-func getIssueStatsChunk(opts *IssueStatsOptions, issueIDs []int64) (*IssueStats, error) {
+func getIssueStatsChunk(e *xorm.Engine, opts *IssueStatsOptions, issueIDs []int64) (*IssueStats, error) {
 	stats := &IssueStats{}
 
 	countSession := func(opts *IssueStatsOptions) *xorm.Session {
-		sess := x.
+		sess := e.
 			Where("issue.repo_id = ?", opts.RepoID)
 
 		if len(opts.IssueIDs) > 0 {
@@ -1379,6 +1454,12 @@ func getIssueStatsChunk(opts *IssueStatsOptions, issueIDs []int64) (*IssueStats,
 			sess.And("issue.milestone_id = ?", opts.MilestoneID)
 		}
 
+		if opts.WorkflowStateID > 0 {
+			sess.And("issue.workflow_state_id = ?", opts.WorkflowStateID)
+		} else if opts.WorkflowStateID == -1 {
+			sess.And("issue.workflow_state_id = ?", 0)
+		}
+
 		if opts.AssigneeID > 0 {
 			sess.Join("INNER", "issue_assignees", "issue.id = issue_assignees.issue_id").
 				And("issue_assignees.assignee_id = ?", opts.AssigneeID)
@@ -1953,13 +2034,18 @@ func deleteIssuesByRepoID(sess Engine, repoID int64) (attachmentPaths []string,
 		return
 	}
 
+	if _, err = sess.In("issue_id", deleteCond).
+		Delete(&ProjectIssue{}); err != nil {
+		return
+	}
+
 	var attachments []*Attachment
 	if err = sess.In("issue_id", deleteCond).
 		Find(&attachments); err != nil {
 		return
 	}
 	for j := range attachments {
-		attachmentPaths = append(attachmentPaths, attachments[j].LocalPath())
+		attachmentPaths = append(attachmentPaths, attachments[j].LocalPath(), attachments[j].ThumbnailPath())
 	}
 
 	if _, err = sess.In("issue_id", deleteCond).
@@ -1967,9 +2053,24 @@ func deleteIssuesByRepoID(sess Engine, repoID int64) (attachmentPaths []string,
 		return
 	}
 
+	// Epics of the repository owner may contain issues of this repository
+	epicIDs := make([]int64, 0, 5)
+	if err = sess.Table("issue").
+		Where("repo_id = ? AND epic_id > 0", repoID).
+		Distinct("epic_id").
+		Find(&epicIDs); err != nil {
+		return
+	}
+
 	if _, err = sess.Delete(&Issue{RepoID: repoID}); err != nil {
 		return
 	}
 
+	for _, epicID := range epicIDs {
+		if err = updateEpicNums(sess, epicID); err != nil {
+			return
+		}
+	}
+
 	return
 }
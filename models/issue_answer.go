@@ -0,0 +1,83 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import "fmt"
+
+// ErrIssueNotQuestion represents a "IssueNotQuestion" kind of error.
+type ErrIssueNotQuestion struct {
+	IssueID int64
+}
+
+// IsErrIssueNotQuestion checks if an error is a ErrIssueNotQuestion.
+func IsErrIssueNotQuestion(err error) bool {
+	_, ok := err.(ErrIssueNotQuestion)
+	return ok
+}
+
+func (err ErrIssueNotQuestion) Error() string {
+	return fmt.Sprintf("issue has no question label [issue_id: %d]", err.IssueID)
+}
+
+// IsQuestion returns true if the issue is not a pull request and carries at
+// least one label marked as question. The labels of the issue must be loaded.
+func (issue *Issue) IsQuestion() bool {
+	if issue.IsPull {
+		return false
+	}
+	for _, label := range issue.Labels {
+		if label.IsQuestion {
+			return true
+		}
+	}
+	return false
+}
+
+func (issue *Issue) loadAnswerComment(e Engine) (err error) {
+	if issue.AnswerComment != nil || issue.AnswerCommentID == 0 {
+		return nil
+	}
+	issue.AnswerComment, err = getCommentByID(e, issue.AnswerCommentID)
+	if err != nil {
+		if IsErrCommentNotExist(err) {
+			issue.AnswerCommentID = 0
+			return nil
+		}
+		return err
+	}
+	issue.AnswerComment.Issue = issue
+	return issue.AnswerComment.loadPoster(e)
+}
+
+// LoadAnswerComment loads the comment accepted as answer of the issue, if any.
+func (issue *Issue) LoadAnswerComment() error {
+	return issue.loadAnswerComment(x)
+}
+
+// SetAnswer accepts a comment as the answer of a question issue.
+// A commentID of 0 removes the accepted answer.
+func (issue *Issue) SetAnswer(commentID int64) error {
+	if err := issue.LoadLabels(); err != nil {
+		return err
+	}
+	if !issue.IsQuestion() {
+		return ErrIssueNotQuestion{IssueID: issue.ID}
+	}
+
+	var answer *Comment
+	if commentID > 0 {
+		var err error
+		if answer, err = GetCommentByID(commentID); err != nil {
+			return err
+		} else if answer.IssueID != issue.ID || answer.Type != CommentTypeComment {
+			return ErrCommentNotExist{ID: commentID, IssueID: issue.ID}
+		}
+	}
+
+	issue.AnswerCommentID = commentID
+	issue.AnswerComment = answer
+	_, err := x.ID(issue.ID).Cols("answer_comment_id").Update(issue)
+	return err
+}
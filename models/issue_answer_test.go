@@ -0,0 +1,72 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssue_IsQuestion(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue.LoadLabels())
+	assert.False(t, issue.IsQuestion())
+
+	issue.Labels[0].IsQuestion = true
+	assert.True(t, issue.IsQuestion())
+
+	issue.IsPull = true
+	assert.False(t, issue.IsQuestion())
+}
+
+func TestIssue_SetAnswer(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	err := issue.SetAnswer(2)
+	assert.True(t, IsErrIssueNotQuestion(err))
+
+	label := AssertExistsAndLoadBean(t, &Label{ID: 1}).(*Label)
+	label.IsQuestion = true
+	assert.NoError(t, UpdateLabel(label))
+
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue.SetAnswer(2))
+	assert.EqualValues(t, 2, issue.AnswerComment.ID)
+	AssertExistsAndLoadBean(t, &Issue{ID: 1, AnswerCommentID: 2})
+
+	// only plain comments of the issue can be accepted
+	err = issue.SetAnswer(1)
+	assert.True(t, IsErrCommentNotExist(err))
+	err = issue.SetAnswer(4)
+	assert.True(t, IsErrCommentNotExist(err))
+	AssertExistsAndLoadBean(t, &Issue{ID: 1, AnswerCommentID: 2})
+
+	assert.NoError(t, issue.SetAnswer(0))
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.EqualValues(t, 0, issue.AnswerCommentID)
+}
+
+func TestDeleteComment_ClearsAnswer(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	label := AssertExistsAndLoadBean(t, &Label{ID: 1}).(*Label)
+	label.IsQuestion = true
+	assert.NoError(t, UpdateLabel(label))
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue.SetAnswer(3))
+
+	comment := AssertExistsAndLoadBean(t, &Comment{ID: 3}).(*Comment)
+	assert.NoError(t, DeleteComment(comment, nil))
+
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.EqualValues(t, 0, issue.AnswerCommentID)
+	assert.NoError(t, issue.LoadAnswerComment())
+	assert.Nil(t, issue.AnswerComment)
+}
@@ -0,0 +1,180 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/gobwas/glob"
+)
+
+// IssueAssignRule automatically assigns newly opened or labeled issues and
+// pull requests of a repository to the members of a team in turn.
+type IssueAssignRule struct {
+	ID     int64 `xorm:"pk autoincr"`
+	RepoID int64 `xorm:"INDEX"`
+	Name   string
+	// LabelID restricts the rule to issues carrying this label, 0 matches any issue.
+	LabelID int64
+	// PathPatterns is a semicolon separated list of glob patterns, the rule
+	// only matches pull requests changing at least one matching file.
+	PathPatterns   string `xorm:"TEXT"`
+	TeamID         int64
+	LastAssigneeID int64
+
+	Label *Label `xorm:"-"`
+	Team  *Team  `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// ErrIssueAssignRuleNotExist represents a "IssueAssignRuleNotExist" kind of error.
+type ErrIssueAssignRuleNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrIssueAssignRuleNotExist checks if an error is a ErrIssueAssignRuleNotExist.
+func IsErrIssueAssignRuleNotExist(err error) bool {
+	_, ok := err.(ErrIssueAssignRuleNotExist)
+	return ok
+}
+
+func (err ErrIssueAssignRuleNotExist) Error() string {
+	return fmt.Sprintf("issue assign rule does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
+// GetPathPatterns parses the semicolon separated path patterns of the rule.
+func (rule *IssueAssignRule) GetPathPatterns() []glob.Glob {
+	globs := make([]glob.Glob, 0, 5)
+	for _, expr := range strings.Split(rule.PathPatterns, ";") {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		g, err := glob.Compile(expr, '/')
+		if err != nil {
+			log.Info("Invalid glob expression '%s' (skipped): %v", expr, err)
+			continue
+		}
+		globs = append(globs, g)
+	}
+	return globs
+}
+
+// Match returns true if the rule applies to an issue with the given labels.
+// changedFiles are the files changed by a pull request; rules with path
+// patterns never match plain issues.
+func (rule *IssueAssignRule) Match(labelIDs []int64, isPull bool, changedFiles []string) bool {
+	if rule.LabelID > 0 {
+		var hasLabel bool
+		for _, id := range labelIDs {
+			if id == rule.LabelID {
+				hasLabel = true
+				break
+			}
+		}
+		if !hasLabel {
+			return false
+		}
+	}
+
+	globs := rule.GetPathPatterns()
+	if len(globs) == 0 {
+		return true
+	}
+	if !isPull {
+		return false
+	}
+	for _, file := range changedFiles {
+		for _, g := range globs {
+			if g.Match(file) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NextAssignee picks the candidate following the last assignee of the rule,
+// in round-robin order by user ID. The poster is skipped unless they are
+// the only candidate.
+func (rule *IssueAssignRule) NextAssignee(candidates []*User, posterID int64) *User {
+	var first, next *User
+	for _, u := range candidates {
+		if u.ID == posterID && len(candidates) > 1 {
+			continue
+		}
+		if first == nil || u.ID < first.ID {
+			first = u
+		}
+		if u.ID > rule.LastAssigneeID && (next == nil || u.ID < next.ID) {
+			next = u
+		}
+	}
+	if next != nil {
+		return next
+	}
+	return first
+}
+
+// LoadAttributes loads the label and team of the rule.
+func (rule *IssueAssignRule) LoadAttributes() (err error) {
+	if rule.LabelID > 0 && rule.Label == nil {
+		if rule.Label, err = GetLabelByID(rule.LabelID); err != nil && !IsErrLabelNotExist(err) {
+			return err
+		}
+	}
+	if rule.TeamID > 0 && rule.Team == nil {
+		if rule.Team, err = GetTeamByID(rule.TeamID); err != nil && !IsErrTeamNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewIssueAssignRule creates a new assignment rule for a repository.
+func NewIssueAssignRule(rule *IssueAssignRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	_, err := x.Insert(rule)
+	return err
+}
+
+// GetIssueAssignRuleByRepoID returns an assignment rule of a repository.
+func GetIssueAssignRuleByRepoID(repoID, id int64) (*IssueAssignRule, error) {
+	rule := new(IssueAssignRule)
+	has, err := x.ID(id).Where("repo_id = ?", repoID).Get(rule)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrIssueAssignRuleNotExist{ID: id, RepoID: repoID}
+	}
+	return rule, nil
+}
+
+// GetIssueAssignRulesByRepoID returns all assignment rules of a repository.
+func GetIssueAssignRulesByRepoID(repoID int64) ([]*IssueAssignRule, error) {
+	rules := make([]*IssueAssignRule, 0, 5)
+	return rules, x.Where("repo_id = ?", repoID).Asc("id").Find(&rules)
+}
+
+// UpdateIssueAssignRuleLastAssignee remembers the user an issue was last
+// assigned to by the rule.
+func UpdateIssueAssignRuleLastAssignee(rule *IssueAssignRule, userID int64) error {
+	rule.LastAssigneeID = userID
+	_, err := x.ID(rule.ID).Cols("last_assignee_id").Update(rule)
+	return err
+}
+
+// DeleteIssueAssignRule deletes an assignment rule of a repository.
+func DeleteIssueAssignRule(repoID, id int64) error {
+	_, err := x.Where("id = ? AND repo_id = ?", id, repoID).Delete(new(IssueAssignRule))
+	return err
+}
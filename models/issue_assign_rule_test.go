@@ -0,0 +1,65 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueAssignRule_Match(t *testing.T) {
+	rule := &IssueAssignRule{}
+	assert.True(t, rule.Match(nil, false, nil))
+
+	rule.LabelID = 1
+	assert.False(t, rule.Match(nil, false, nil))
+	assert.True(t, rule.Match([]int64{2, 1}, false, nil))
+
+	rule.PathPatterns = "docs/**; *.md"
+	assert.False(t, rule.Match([]int64{1}, false, nil))
+	assert.False(t, rule.Match([]int64{1}, true, []string{"main.go"}))
+	assert.True(t, rule.Match([]int64{1}, true, []string{"main.go", "docs/dev/setup.txt"}))
+	assert.True(t, rule.Match([]int64{1}, true, []string{"README.md"}))
+}
+
+func TestIssueAssignRule_NextAssignee(t *testing.T) {
+	users := []*User{{ID: 4}, {ID: 2}, {ID: 9}}
+	rule := &IssueAssignRule{}
+
+	assert.EqualValues(t, 2, rule.NextAssignee(users, 0).ID)
+	rule.LastAssigneeID = 2
+	assert.EqualValues(t, 4, rule.NextAssignee(users, 0).ID)
+	assert.EqualValues(t, 9, rule.NextAssignee(users, 4).ID)
+	rule.LastAssigneeID = 9
+	assert.EqualValues(t, 2, rule.NextAssignee(users, 0).ID)
+	assert.EqualValues(t, 4, rule.NextAssignee(users, 2).ID)
+
+	assert.EqualValues(t, 2, rule.NextAssignee([]*User{{ID: 2}}, 2).ID)
+	assert.Nil(t, rule.NextAssignee(nil, 0))
+}
+
+func TestIssueAssignRules(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	rule := &IssueAssignRule{RepoID: 3, Name: " reviewers ", TeamID: 2}
+	assert.NoError(t, NewIssueAssignRule(rule))
+	assert.EqualValues(t, "reviewers", rule.Name)
+
+	rules, err := GetIssueAssignRulesByRepoID(3)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 1)
+
+	assert.NoError(t, UpdateIssueAssignRuleLastAssignee(rule, 4))
+	rule, err = GetIssueAssignRuleByRepoID(3, rule.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, rule.LastAssigneeID)
+
+	_, err = GetIssueAssignRuleByRepoID(1, rule.ID)
+	assert.True(t, IsErrIssueAssignRuleNotExist(err))
+
+	assert.NoError(t, DeleteIssueAssignRule(3, rule.ID))
+	AssertNotExistsBean(t, &IssueAssignRule{ID: rule.ID})
+}
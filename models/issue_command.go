@@ -0,0 +1,112 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import "fmt"
+
+// IssueCommands are the changes of an issue requested by the slash commands of a comment
+type IssueCommands struct {
+	Assignees   []*User
+	Labels      []*Label
+	Milestone   *Milestone
+	Close       bool
+	DuplicateOf *Issue
+}
+
+// IssueCommandsResult holds what changed when the commands were applied
+type IssueCommandsResult struct {
+	// Comment is the comment itself, nil if it only contained commands
+	Comment *Comment
+	// AssigneeComments are the comments of the new assignees, by assignee
+	AssigneeComments map[*User]*Comment
+	Labels           []*Label
+	MilestoneChanged bool
+	OldMilestoneID   int64
+	// StatusComment is the comment of the issue being closed, if it was
+	StatusComment *Comment
+}
+
+// CreateCommentWithCommands creates the comment, if it has content or attachments, and applies the
+// commands to the issue in the same transaction, so either everything is done or nothing is.
+func CreateCommentWithCommands(opts *CreateCommentOptions, cmds *IssueCommands) (*IssueCommandsResult, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	issue := opts.Issue
+	if err := issue.loadRepo(sess); err != nil {
+		return nil, err
+	}
+	if err := issue.loadPoster(sess); err != nil {
+		return nil, err
+	}
+
+	result := &IssueCommandsResult{AssigneeComments: make(map[*User]*Comment)}
+	if len(opts.Content) > 0 || len(opts.Attachments) > 0 {
+		comment, err := createComment(sess, opts)
+		if err != nil {
+			return nil, err
+		}
+		result.Comment = comment
+	}
+
+	for _, assignee := range cmds.Assignees {
+		isAssigned, err := isUserAssignedToIssue(sess, issue, assignee)
+		if err != nil {
+			return nil, err
+		}
+		if isAssigned {
+			continue
+		}
+		_, comment, err := issue.toggleAssignee(sess, opts.Doer, assignee.ID, false)
+		if err != nil {
+			return nil, err
+		}
+		result.AssigneeComments[assignee] = comment
+	}
+
+	if len(cmds.Labels) > 0 {
+		if err := newIssueLabels(sess, issue, cmds.Labels, opts.Doer); err != nil {
+			return nil, fmt.Errorf("newIssueLabels: %v", err)
+		}
+		result.Labels = cmds.Labels
+	}
+
+	if cmds.Milestone != nil && cmds.Milestone.ID != issue.MilestoneID {
+		result.OldMilestoneID = issue.MilestoneID
+		issue.MilestoneID = cmds.Milestone.ID
+		if err := changeMilestoneAssign(sess, opts.Doer, issue, result.OldMilestoneID); err != nil {
+			return nil, fmt.Errorf("changeMilestoneAssign: %v", err)
+		}
+		result.MilestoneChanged = true
+	}
+
+	if cmds.DuplicateOf != nil {
+		if _, err := createComment(sess, &CreateCommentOptions{
+			Type:             CommentTypeMarkDuplicate,
+			Doer:             opts.Doer,
+			Repo:             issue.Repo,
+			Issue:            issue,
+			DependentIssueID: cmds.DuplicateOf.ID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if (cmds.Close || cmds.DuplicateOf != nil) && !issue.IsClosed {
+		comment, err := issue.changeStatus(sess, opts.Doer, true, false)
+		if err != nil {
+			return nil, err
+		}
+		result.StatusComment = comment
+	}
+
+	if err := sess.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
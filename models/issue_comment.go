@@ -97,6 +97,12 @@ const (
 	CommentTypeMergePull
 	// push to PR head branch
 	CommentTypePullPush
+	// Change workflow state of an issue
+	CommentTypeChangeWorkflowState
+	// Automatic note of a mentioned user who is out of office
+	CommentTypeAwayNote
+	// Mark an issue as a duplicate of another one
+	CommentTypeMarkDuplicate
 )
 
 // CommentTag defines comment tag type
@@ -138,6 +144,11 @@ type Comment struct {
 	DependentIssueID int64
 	DependentIssue   *Issue `xorm:"-"`
 
+	OldWorkflowStateID int64
+	WorkflowStateID    int64
+	OldWorkflowState   *WorkflowState `xorm:"-"`
+	WorkflowState      *WorkflowState `xorm:"-"`
+
 	CommitID        int64
 	Line            int64 // - previous line / + proposed line
 	TreePath        string
@@ -413,6 +424,30 @@ func (c *Comment) LoadMilestone() error {
 	return nil
 }
 
+// LoadWorkflowStates if comment.Type is CommentTypeChangeWorkflowState, then load old and new workflow states
+func (c *Comment) LoadWorkflowStates() error {
+	if c.OldWorkflowStateID > 0 {
+		var oldState WorkflowState
+		has, err := x.ID(c.OldWorkflowStateID).Get(&oldState)
+		if err != nil {
+			return err
+		} else if has {
+			c.OldWorkflowState = &oldState
+		}
+	}
+
+	if c.WorkflowStateID > 0 {
+		var state WorkflowState
+		has, err := x.ID(c.WorkflowStateID).Get(&state)
+		if err != nil {
+			return err
+		} else if has {
+			c.WorkflowState = &state
+		}
+	}
+	return nil
+}
+
 // LoadPoster loads comment poster
 func (c *Comment) LoadPoster() error {
 	return c.loadPoster(x)
@@ -443,6 +478,12 @@ func (c *Comment) UpdateAttachments(uuids []string) error {
 	if err != nil {
 		return fmt.Errorf("getAttachmentsByUUIDs [uuids: %v]: %v", uuids, err)
 	}
+	if err := c.loadIssue(sess); err != nil {
+		return err
+	}
+	if err := checkIssueAttachmentsSize(sess, c.Issue, attachments); err != nil {
+		return err
+	}
 	for i := 0; i < len(attachments); i++ {
 		attachments[i].IssueID = c.IssueID
 		attachments[i].CommentID = c.ID
@@ -640,33 +681,35 @@ func createComment(e *xorm.Session, opts *CreateCommentOptions) (_ *Comment, err
 	}
 
 	comment := &Comment{
-		Type:             opts.Type,
-		PosterID:         opts.Doer.ID,
-		Poster:           opts.Doer,
-		IssueID:          opts.Issue.ID,
-		LabelID:          LabelID,
-		OldMilestoneID:   opts.OldMilestoneID,
-		MilestoneID:      opts.MilestoneID,
-		RemovedAssignee:  opts.RemovedAssignee,
-		AssigneeID:       opts.AssigneeID,
-		CommitID:         opts.CommitID,
-		CommitSHA:        opts.CommitSHA,
-		Line:             opts.LineNum,
-		Content:          opts.Content,
-		OldTitle:         opts.OldTitle,
-		NewTitle:         opts.NewTitle,
-		OldRef:           opts.OldRef,
-		NewRef:           opts.NewRef,
-		DependentIssueID: opts.DependentIssueID,
-		TreePath:         opts.TreePath,
-		ReviewID:         opts.ReviewID,
-		Patch:            opts.Patch,
-		RefRepoID:        opts.RefRepoID,
-		RefIssueID:       opts.RefIssueID,
-		RefCommentID:     opts.RefCommentID,
-		RefAction:        opts.RefAction,
-		RefIsPull:        opts.RefIsPull,
-		IsForcePush:      opts.IsForcePush,
+		Type:               opts.Type,
+		PosterID:           opts.Doer.ID,
+		Poster:             opts.Doer,
+		IssueID:            opts.Issue.ID,
+		LabelID:            LabelID,
+		OldMilestoneID:     opts.OldMilestoneID,
+		MilestoneID:        opts.MilestoneID,
+		OldWorkflowStateID: opts.OldWorkflowStateID,
+		WorkflowStateID:    opts.WorkflowStateID,
+		RemovedAssignee:    opts.RemovedAssignee,
+		AssigneeID:         opts.AssigneeID,
+		CommitID:           opts.CommitID,
+		CommitSHA:          opts.CommitSHA,
+		Line:               opts.LineNum,
+		Content:            opts.Content,
+		OldTitle:           opts.OldTitle,
+		NewTitle:           opts.NewTitle,
+		OldRef:             opts.OldRef,
+		NewRef:             opts.NewRef,
+		DependentIssueID:   opts.DependentIssueID,
+		TreePath:           opts.TreePath,
+		ReviewID:           opts.ReviewID,
+		Patch:              opts.Patch,
+		RefRepoID:          opts.RefRepoID,
+		RefIssueID:         opts.RefIssueID,
+		RefCommentID:       opts.RefCommentID,
+		RefAction:          opts.RefAction,
+		RefIsPull:          opts.RefIsPull,
+		IsForcePush:        opts.IsForcePush,
 	}
 	if _, err = e.Insert(comment); err != nil {
 		return nil, err
@@ -712,6 +755,9 @@ func updateCommentInfos(e *xorm.Session, opts *CreateCommentOptions, comment *Co
 		if err != nil {
 			return fmt.Errorf("getAttachmentsByUUIDs [uuids: %v]: %v", opts.Attachments, err)
 		}
+		if err := checkIssueAttachmentsSize(e, opts.Issue, attachments); err != nil {
+			return err
+		}
 
 		for i := range attachments {
 			attachments[i].IssueID = opts.Issue.ID
@@ -807,29 +853,31 @@ type CreateCommentOptions struct {
 	Issue *Issue
 	Label *Label
 
-	DependentIssueID int64
-	OldMilestoneID   int64
-	MilestoneID      int64
-	AssigneeID       int64
-	RemovedAssignee  bool
-	OldTitle         string
-	NewTitle         string
-	OldRef           string
-	NewRef           string
-	CommitID         int64
-	CommitSHA        string
-	Patch            string
-	LineNum          int64
-	TreePath         string
-	ReviewID         int64
-	Content          string
-	Attachments      []string // UUIDs of attachments
-	RefRepoID        int64
-	RefIssueID       int64
-	RefCommentID     int64
-	RefAction        references.XRefAction
-	RefIsPull        bool
-	IsForcePush      bool
+	DependentIssueID   int64
+	OldMilestoneID     int64
+	MilestoneID        int64
+	OldWorkflowStateID int64
+	WorkflowStateID    int64
+	AssigneeID         int64
+	RemovedAssignee    bool
+	OldTitle           string
+	NewTitle           string
+	OldRef             string
+	NewRef             string
+	CommitID           int64
+	CommitSHA          string
+	Patch              string
+	LineNum            int64
+	TreePath           string
+	ReviewID           int64
+	Content            string
+	Attachments        []string // UUIDs of attachments
+	RefRepoID          int64
+	RefIssueID         int64
+	RefCommentID       int64
+	RefAction          references.XRefAction
+	RefIsPull          bool
+	IsForcePush        bool
 }
 
 // CreateComment creates comment of issue or commit.
@@ -906,6 +954,8 @@ type FindCommentsOptions struct {
 	Since    int64
 	Before   int64
 	Type     CommentType
+	// Cursor switches to keyset pagination, oldest comments first
+	Cursor *CursorOptions
 }
 
 func (opts *FindCommentsOptions) toConds() builder.Cond {
@@ -938,6 +988,10 @@ func findComments(e Engine, opts FindCommentsOptions) ([]*Comment, error) {
 		sess.Join("INNER", "issue", "issue.id = comment.issue_id")
 	}
 
+	if opts.Cursor != nil {
+		return comments, opts.Cursor.setSessionCursor(sess, "comment.id", false).Find(&comments)
+	}
+
 	if opts.Page != 0 {
 		sess = opts.setSessionPagination(sess)
 	}
@@ -995,6 +1049,9 @@ func DeleteComment(comment *Comment, doer *User) error {
 		if _, err := sess.Exec("UPDATE `issue` SET num_comments = num_comments - 1 WHERE id = ?", comment.IssueID); err != nil {
 			return err
 		}
+		if _, err := sess.Exec("UPDATE `issue` SET answer_comment_id = 0 WHERE id = ? AND answer_comment_id = ?", comment.IssueID, comment.ID); err != nil {
+			return err
+		}
 	}
 	if _, err := sess.Where("comment_id = ?", comment.ID).Cols("is_deleted").Update(&Action{IsDeleted: true}); err != nil {
 		return err
@@ -0,0 +1,321 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// Epic groups issues above milestones. An epic belongs either to a single
+// repository or, when RepoID is zero, to an organization, in which case it
+// may group issues of every repository owned by that organization.
+type Epic struct {
+	ID              int64 `xorm:"pk autoincr"`
+	OwnerID         int64 `xorm:"INDEX"`
+	RepoID          int64 `xorm:"INDEX"`
+	Name            string
+	Content         string `xorm:"TEXT"`
+	RenderedContent string `xorm:"-"`
+	IsClosed        bool
+	NumIssues       int
+	NumClosedIssues int
+	NumOpenIssues   int `xorm:"-"`
+	Completeness    int // Percentage(1-100).
+
+	CreatedUnix    timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX updated"`
+	ClosedDateUnix timeutil.TimeStamp
+}
+
+// ErrEpicNotExist represents a "EpicNotExist" kind of error.
+type ErrEpicNotExist struct {
+	ID      int64
+	OwnerID int64
+	RepoID  int64
+}
+
+// IsErrEpicNotExist checks if an error is a ErrEpicNotExist.
+func IsErrEpicNotExist(err error) bool {
+	_, ok := err.(ErrEpicNotExist)
+	return ok
+}
+
+func (err ErrEpicNotExist) Error() string {
+	return fmt.Sprintf("epic does not exist [id: %d, owner_id: %d, repo_id: %d]", err.ID, err.OwnerID, err.RepoID)
+}
+
+// ErrEpicIssueOutOfScope represents an attempt to add an issue to an epic
+// that cannot contain it.
+type ErrEpicIssueOutOfScope struct {
+	EpicID  int64
+	IssueID int64
+}
+
+// IsErrEpicIssueOutOfScope checks if an error is a ErrEpicIssueOutOfScope.
+func IsErrEpicIssueOutOfScope(err error) bool {
+	_, ok := err.(ErrEpicIssueOutOfScope)
+	return ok
+}
+
+func (err ErrEpicIssueOutOfScope) Error() string {
+	return fmt.Sprintf("issue cannot be added to epic [epic_id: %d, issue_id: %d]", err.EpicID, err.IssueID)
+}
+
+// AfterLoad is invoked from XORM after setting the value of a field of
+// this object.
+func (epic *Epic) AfterLoad() {
+	epic.NumOpenIssues = epic.NumIssues - epic.NumClosedIssues
+}
+
+// State returns string representation of epic status.
+func (epic *Epic) State() api.StateType {
+	if epic.IsClosed {
+		return api.StateClosed
+	}
+	return api.StateOpen
+}
+
+// IsOrgEpic returns true if the epic is defined at organization level.
+func (epic *Epic) IsOrgEpic() bool {
+	return epic.RepoID == 0
+}
+
+// canContainIssue returns true if the issue lives in the scope of the epic:
+// the epic's repository, or any repository of the epic's owner.
+func (epic *Epic) canContainIssue(e Engine, issue *Issue) (bool, error) {
+	if !epic.IsOrgEpic() {
+		return issue.RepoID == epic.RepoID, nil
+	}
+	if err := issue.loadRepo(e); err != nil {
+		return false, err
+	}
+	return issue.Repo.OwnerID == epic.OwnerID, nil
+}
+
+// NewEpic creates a new epic.
+func NewEpic(epic *Epic) error {
+	epic.Name = strings.TrimSpace(epic.Name)
+	_, err := x.Insert(epic)
+	return err
+}
+
+func getEpicByID(e Engine, id int64) (*Epic, error) {
+	epic := new(Epic)
+	has, err := e.ID(id).Get(epic)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrEpicNotExist{ID: id}
+	}
+	return epic, nil
+}
+
+// GetEpicByID returns the epic by given ID.
+func GetEpicByID(id int64) (*Epic, error) {
+	return getEpicByID(x, id)
+}
+
+// GetEpicByRepoID returns an epic defined in the given repository.
+func GetEpicByRepoID(repoID, id int64) (*Epic, error) {
+	epic := new(Epic)
+	has, err := x.ID(id).Where("repo_id = ?", repoID).Get(epic)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrEpicNotExist{ID: id, RepoID: repoID}
+	}
+	return epic, nil
+}
+
+// GetEpicByOrgID returns an organization level epic.
+func GetEpicByOrgID(orgID, id int64) (*Epic, error) {
+	epic := new(Epic)
+	has, err := x.ID(id).Where("owner_id = ? AND repo_id = 0", orgID).Get(epic)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrEpicNotExist{ID: id, OwnerID: orgID}
+	}
+	return epic, nil
+}
+
+// GetEpicForRepo returns an epic usable by issues of the given repository,
+// which is either defined in the repository itself or by its owner.
+func GetEpicForRepo(repo *Repository, id int64) (*Epic, error) {
+	epic := new(Epic)
+	has, err := x.ID(id).
+		Where(builder.Eq{"repo_id": repo.ID}.Or(builder.Eq{"owner_id": repo.OwnerID, "repo_id": 0})).
+		Get(epic)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrEpicNotExist{ID: id, RepoID: repo.ID}
+	}
+	return epic, nil
+}
+
+func findEpics(cond builder.Cond, state api.StateType, listOptions ListOptions) ([]*Epic, error) {
+	switch state {
+	case api.StateClosed:
+		cond = cond.And(builder.Eq{"is_closed": true})
+	case api.StateAll:
+	default:
+		cond = cond.And(builder.Eq{"is_closed": false})
+	}
+
+	sess := x.Where(cond)
+	if listOptions.Page != 0 {
+		sess = listOptions.setSessionPagination(sess)
+	}
+
+	epics := make([]*Epic, 0, listOptions.PageSize)
+	return epics, sess.Asc("repo_id").Desc("id").Find(&epics)
+}
+
+// GetEpicsByRepoID returns the epics defined in a repository.
+func GetEpicsByRepoID(repoID int64, state api.StateType, listOptions ListOptions) ([]*Epic, error) {
+	return findEpics(builder.Eq{"repo_id": repoID}, state, listOptions)
+}
+
+// GetEpicsByOrgID returns the organization level epics of an organization.
+func GetEpicsByOrgID(orgID int64, state api.StateType, listOptions ListOptions) ([]*Epic, error) {
+	return findEpics(builder.Eq{"owner_id": orgID, "repo_id": 0}, state, listOptions)
+}
+
+// GetEpicsForRepo returns all epics issues of a repository can be added to,
+// organization level epics first.
+func GetEpicsForRepo(repo *Repository, state api.StateType) ([]*Epic, error) {
+	return findEpics(builder.Eq{"repo_id": repo.ID}.Or(builder.Eq{"owner_id": repo.OwnerID, "repo_id": 0}), state, ListOptions{})
+}
+
+// UpdateEpic updates information of the given epic.
+func UpdateEpic(epic *Epic) error {
+	epic.Name = strings.TrimSpace(epic.Name)
+	if epic.IsClosed && epic.ClosedDateUnix == 0 {
+		epic.ClosedDateUnix = timeutil.TimeStampNow()
+	} else if !epic.IsClosed {
+		epic.ClosedDateUnix = 0
+	}
+	_, err := x.ID(epic.ID).Cols("name", "content", "is_closed", "closed_date_unix").Update(epic)
+	return err
+}
+
+// DeleteEpic deletes an epic. Its issues are detached but otherwise kept.
+func DeleteEpic(epic *Epic) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Exec("UPDATE `issue` SET epic_id = 0 WHERE epic_id = ?", epic.ID); err != nil {
+		return err
+	}
+	if _, err := sess.ID(epic.ID).Delete(new(Epic)); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func updateEpicNums(e Engine, epicID int64) error {
+	if _, err := e.Exec("UPDATE `epic` SET num_issues=(SELECT count(*) FROM issue WHERE epic_id=?), num_closed_issues=(SELECT count(*) FROM issue WHERE epic_id=? AND is_closed=?) WHERE id=?",
+		epicID,
+		epicID,
+		true,
+		epicID,
+	); err != nil {
+		return err
+	}
+	_, err := e.Exec("UPDATE `epic` SET completeness=100*num_closed_issues/(CASE WHEN num_issues > 0 THEN num_issues ELSE 1 END) WHERE id=?",
+		epicID,
+	)
+	return err
+}
+
+func (issue *Issue) loadEpic(e Engine) (err error) {
+	if issue.Epic == nil && issue.EpicID > 0 {
+		issue.Epic, err = getEpicByID(e, issue.EpicID)
+		if IsErrEpicNotExist(err) {
+			issue.EpicID = 0
+			return nil
+		}
+	}
+	return err
+}
+
+// LoadEpic loads the epic of the issue, if any.
+func (issue *Issue) LoadEpic() error {
+	return issue.loadEpic(x)
+}
+
+// ChangeEpic moves the issue to another epic. An epicID of 0 removes the
+// issue from its epic.
+func (issue *Issue) ChangeEpic(epicID int64) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	oldEpicID := issue.EpicID
+	if oldEpicID == epicID {
+		return nil
+	}
+
+	var epic *Epic
+	if epicID > 0 {
+		if epic, err = getEpicByID(sess, epicID); err != nil {
+			return err
+		}
+		if ok, err := epic.canContainIssue(sess, issue); err != nil {
+			return err
+		} else if !ok {
+			return ErrEpicIssueOutOfScope{EpicID: epicID, IssueID: issue.ID}
+		}
+	}
+
+	issue.EpicID = epicID
+	issue.Epic = epic
+	if err = updateIssueCols(sess, issue, "epic_id"); err != nil {
+		return fmt.Errorf("updateIssueCols: %v", err)
+	}
+
+	for _, id := range []int64{oldEpicID, epicID} {
+		if id == 0 {
+			continue
+		}
+		if err = updateEpicNums(sess, id); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// GetEpicIssues returns the issues of an epic the doer is allowed to see,
+// grouped by repository, with their repositories loaded.
+func GetEpicIssues(epicID int64, doer *User) (IssueList, error) {
+	cond := builder.NewCond().And(builder.Eq{"epic_id": epicID})
+	if doer == nil || !doer.IsAdmin {
+		cond = cond.And(builder.In("repo_id", AccessibleRepoIDsQuery(doer)))
+	}
+
+	issues := make(IssueList, 0, 10)
+	if err := x.Where(cond).
+		Asc("repo_id").
+		Asc("`index`").
+		Find(&issues); err != nil {
+		return nil, err
+	}
+	if _, err := issues.LoadRepositories(); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEpic(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	epic := &Epic{
+		OwnerID: 2,
+		RepoID:  1,
+		Name:    " epicName ",
+		Content: "epicContent",
+	}
+	assert.NoError(t, NewEpic(epic))
+	epic = AssertExistsAndLoadBean(t, &Epic{ID: epic.ID}).(*Epic)
+	assert.EqualValues(t, "epicName", epic.Name)
+}
+
+func TestGetEpicForRepo(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo1 := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	repo3 := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+
+	epic, err := GetEpicForRepo(repo1, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "epic1", epic.Name)
+	_, err = GetEpicForRepo(repo1, 2)
+	assert.True(t, IsErrEpicNotExist(err))
+
+	epic, err = GetEpicForRepo(repo3, 2)
+	assert.NoError(t, err)
+	assert.True(t, epic.IsOrgEpic())
+	_, err = GetEpicForRepo(repo3, 1)
+	assert.True(t, IsErrEpicNotExist(err))
+
+	epics, err := GetEpicsForRepo(repo3, api.StateOpen)
+	assert.NoError(t, err)
+	assert.Len(t, epics, 1)
+
+	_, err = GetEpicByOrgID(3, 1)
+	assert.True(t, IsErrEpicNotExist(err))
+}
+
+func TestIssue_ChangeEpic(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue.ChangeEpic(1))
+	AssertExistsAndLoadBean(t, &Issue{ID: 1, EpicID: 1})
+	epic := AssertExistsAndLoadBean(t, &Epic{ID: 1}).(*Epic)
+	assert.EqualValues(t, 1, epic.NumIssues)
+	assert.EqualValues(t, 0, epic.Completeness)
+
+	// issues of other repositories are out of scope of a repository epic
+	issue6 := AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue)
+	assert.True(t, IsErrEpicIssueOutOfScope(issue6.ChangeEpic(1)))
+	assert.NoError(t, issue6.ChangeEpic(2))
+	assert.True(t, IsErrEpicIssueOutOfScope(issue.ChangeEpic(2)))
+
+	// closing an issue updates the progress of its epic
+	_, err := issue.ChangeStatus(doer, true)
+	assert.NoError(t, err)
+	epic = AssertExistsAndLoadBean(t, &Epic{ID: 1}).(*Epic)
+	assert.EqualValues(t, 1, epic.NumClosedIssues)
+	assert.EqualValues(t, 100, epic.Completeness)
+
+	assert.NoError(t, issue.ChangeEpic(0))
+	epic = AssertExistsAndLoadBean(t, &Epic{ID: 1}).(*Epic)
+	assert.EqualValues(t, 0, epic.NumIssues)
+	assert.EqualValues(t, 0, epic.NumClosedIssues)
+}
+
+func TestGetEpicIssues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue6 := AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue)
+	assert.NoError(t, issue6.ChangeEpic(2))
+
+	// repo3 is private and only visible to members of org3
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issues, err := GetEpicIssues(2, user2)
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 1) {
+		assert.EqualValues(t, 6, issues[0].ID)
+		assert.NotNil(t, issues[0].Repo)
+	}
+
+	issues, err = GetEpicIssues(2, nil)
+	assert.NoError(t, err)
+	assert.Len(t, issues, 0)
+}
+
+func TestDeleteEpic(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue.ChangeEpic(1))
+
+	epic := AssertExistsAndLoadBean(t, &Epic{ID: 1}).(*Epic)
+	assert.NoError(t, DeleteEpic(epic))
+	AssertNotExistsBean(t, &Epic{ID: 1})
+	AssertExistsAndLoadBean(t, &Issue{ID: 1}, "epic_id = 0")
+}
@@ -0,0 +1,167 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/references"
+
+	"xorm.io/builder"
+)
+
+// IssueGraphEdgeType is the kind of link between two issues of a reference graph
+type IssueGraphEdgeType string
+
+const (
+	// IssueGraphEdgeBlocks links an issue to the issue it blocks
+	IssueGraphEdgeBlocks IssueGraphEdgeType = "blocks"
+	// IssueGraphEdgeDuplicates links an issue to the issue it is a duplicate of
+	IssueGraphEdgeDuplicates IssueGraphEdgeType = "duplicates"
+	// IssueGraphEdgeReferences links an issue or a pull request to the issue it references
+	IssueGraphEdgeReferences IssueGraphEdgeType = "references"
+	// IssueGraphEdgeCloses links a pull request to the issue it closes when merged
+	IssueGraphEdgeCloses IssueGraphEdgeType = "closes"
+)
+
+const (
+	// IssueGraphMaxDepth is the maximum distance from the issue of the issues in its graph
+	IssueGraphMaxDepth = 3
+	// IssueGraphMaxIssues is the maximum number of issues in a graph
+	IssueGraphMaxIssues = 100
+)
+
+// IssueGraphEdge is a link from an issue to another one
+type IssueGraphEdge struct {
+	FromID int64
+	ToID   int64
+	Type   IssueGraphEdgeType
+}
+
+// IssueGraph is the reference graph around an issue: the issues it is linked
+// to by dependencies, duplicates and cross references, and the issues linked
+// to those, up to a depth.
+type IssueGraph struct {
+	Issue *Issue
+	// Issues are the issues of the graph, including Issue, with their repository loaded
+	Issues IssueList
+	Edges  []*IssueGraphEdge
+	// Truncated is true if some issues were left out to stay under IssueGraphMaxIssues
+	Truncated bool
+}
+
+// getIssueGraphEdges returns the links from or to the issues
+func getIssueGraphEdges(e Engine, issueIDs []int64) ([]*IssueGraphEdge, error) {
+	deps := make([]*IssueDependency, 0, 10)
+	if err := e.Where(builder.In("issue_id", issueIDs).Or(builder.In("dependency_id", issueIDs))).
+		Find(&deps); err != nil {
+		return nil, err
+	}
+	edges := make([]*IssueGraphEdge, 0, len(deps))
+	for _, dep := range deps {
+		edges = append(edges, &IssueGraphEdge{FromID: dep.DependencyID, ToID: dep.IssueID, Type: IssueGraphEdgeBlocks})
+	}
+
+	comments := make([]*Comment, 0, 10)
+	if err := e.Where(builder.Eq{"type": CommentTypeMarkDuplicate}.
+		And(builder.In("issue_id", issueIDs).Or(builder.In("dependent_issue_id", issueIDs)))).
+		Or(builder.In("type", CommentTypeIssueRef, CommentTypeCommentRef, CommentTypePullRef).
+			And(builder.Neq{"ref_action": references.XRefActionNeutered}).
+			And(builder.In("issue_id", issueIDs).Or(builder.In("ref_issue_id", issueIDs)))).
+		Find(&comments); err != nil {
+		return nil, err
+	}
+	for _, c := range comments {
+		switch {
+		case c.Type == CommentTypeMarkDuplicate:
+			edges = append(edges, &IssueGraphEdge{FromID: c.IssueID, ToID: c.DependentIssueID, Type: IssueGraphEdgeDuplicates})
+		case c.RefAction == references.XRefActionCloses && c.RefIsPull:
+			edges = append(edges, &IssueGraphEdge{FromID: c.RefIssueID, ToID: c.IssueID, Type: IssueGraphEdgeCloses})
+		default:
+			edges = append(edges, &IssueGraphEdge{FromID: c.RefIssueID, ToID: c.IssueID, Type: IssueGraphEdgeReferences})
+		}
+	}
+	return edges, nil
+}
+
+// GetIssueGraph returns the reference graph around the issue up to depth,
+// leaving out the issues the doer cannot read.
+func GetIssueGraph(issue *Issue, depth int, doer *User) (*IssueGraph, error) {
+	if depth <= 0 || depth > IssueGraphMaxDepth {
+		depth = IssueGraphMaxDepth
+	}
+	if err := issue.loadRepo(x); err != nil {
+		return nil, err
+	}
+
+	graph := &IssueGraph{Issue: issue, Issues: IssueList{issue}}
+	inGraph := map[int64]bool{issue.ID: true}
+	seen := map[int64]bool{issue.ID: true}
+	seenEdges := make(map[IssueGraphEdge]bool)
+	// permissions by repository, to only check each repository once
+	perms := map[int64]*Permission{}
+	canRead := func(linked *Issue) (bool, error) {
+		perm, ok := perms[linked.RepoID]
+		if !ok {
+			p, err := getUserRepoPermission(x, linked.Repo, doer)
+			if err != nil {
+				return false, err
+			}
+			perm = &p
+			perms[linked.RepoID] = perm
+		}
+		return perm.CanReadIssuesOrPulls(linked.IsPull), nil
+	}
+
+	frontier := []int64{issue.ID}
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		edges, err := getIssueGraphEdges(x, frontier)
+		if err != nil {
+			return nil, err
+		}
+
+		newIDs := make([]int64, 0, len(edges))
+		for _, edge := range edges {
+			for _, id := range []int64{edge.FromID, edge.ToID} {
+				if !seen[id] {
+					seen[id] = true
+					newIDs = append(newIDs, id)
+				}
+			}
+		}
+
+		frontier = frontier[:0]
+		if len(newIDs) > 0 {
+			issues := make(IssueList, 0, len(newIDs))
+			if err := x.In("id", newIDs).Asc("id").Find(&issues); err != nil {
+				return nil, err
+			}
+			if _, err := issues.loadRepositories(x); err != nil {
+				return nil, err
+			}
+			for _, linked := range issues {
+				if ok, err := canRead(linked); err != nil {
+					return nil, err
+				} else if !ok {
+					continue
+				}
+				if len(graph.Issues) >= IssueGraphMaxIssues {
+					graph.Truncated = true
+					break
+				}
+				inGraph[linked.ID] = true
+				graph.Issues = append(graph.Issues, linked)
+				frontier = append(frontier, linked.ID)
+			}
+		}
+
+		for _, edge := range edges {
+			if !inGraph[edge.FromID] || !inGraph[edge.ToID] || seenEdges[*edge] {
+				continue
+			}
+			seenEdges[*edge] = true
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+	return graph, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/references"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func prepareIssueGraph(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	_, err := x.Insert(&IssueDependency{UserID: 2, IssueID: 1, DependencyID: 5})
+	assert.NoError(t, err)
+	_, err = x.Insert([]*Comment{
+		// pull request #2 closes issue #1
+		{Type: CommentTypePullRef, PosterID: 2, IssueID: 1, RefRepoID: 1, RefIssueID: 2, RefIsPull: true, RefAction: references.XRefActionCloses},
+		// issue #1 of the private repo2 references issue #1
+		{Type: CommentTypeIssueRef, PosterID: 2, IssueID: 1, RefRepoID: 2, RefIssueID: 7},
+		// pull request #3 references issue #5
+		{Type: CommentTypePullRef, PosterID: 2, IssueID: 5, RefRepoID: 1, RefIssueID: 3, RefIsPull: true},
+		// issue #5 is a duplicate of issue #1
+		{Type: CommentTypeMarkDuplicate, PosterID: 2, IssueID: 5, DependentIssueID: 1},
+		// the reference from pull request #11 was removed
+		{Type: CommentTypePullRef, PosterID: 2, IssueID: 1, RefRepoID: 1, RefIssueID: 11, RefIsPull: true, RefAction: references.XRefActionNeutered},
+	})
+	assert.NoError(t, err)
+}
+
+func issueGraphIDs(graph *IssueGraph) []int64 {
+	ids := make([]int64, len(graph.Issues))
+	for i, issue := range graph.Issues {
+		ids[i] = issue.ID
+	}
+	return ids
+}
+
+func TestGetIssueGraph(t *testing.T) {
+	prepareIssueGraph(t)
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	graph, err := GetIssueGraph(issue, 0, user2)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{1, 2, 3, 5, 7}, issueGraphIDs(graph))
+	assert.False(t, graph.Truncated)
+	assert.ElementsMatch(t, []*IssueGraphEdge{
+		{FromID: 5, ToID: 1, Type: IssueGraphEdgeBlocks},
+		{FromID: 5, ToID: 1, Type: IssueGraphEdgeDuplicates},
+		{FromID: 2, ToID: 1, Type: IssueGraphEdgeCloses},
+		{FromID: 7, ToID: 1, Type: IssueGraphEdgeReferences},
+		{FromID: 3, ToID: 5, Type: IssueGraphEdgeReferences},
+	}, graph.Edges)
+
+	graph, err = GetIssueGraph(issue, 1, user2)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{1, 2, 5, 7}, issueGraphIDs(graph))
+	assert.Len(t, graph.Edges, 4)
+}
+
+func TestGetIssueGraphPermissions(t *testing.T) {
+	prepareIssueGraph(t)
+
+	// the issue of the private repo2 is left out with its edges
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	for _, doer := range []*User{nil, AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)} {
+		graph, err := GetIssueGraph(issue, 0, doer)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []int64{1, 2, 3, 5}, issueGraphIDs(graph))
+		assert.Len(t, graph.Edges, 4)
+	}
+}
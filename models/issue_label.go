@@ -27,6 +27,9 @@ type Label struct {
 	Name              string
 	Description       string
 	Color             string `xorm:"VARCHAR(7)"`
+	IsQuestion        bool   `xorm:"NOT NULL DEFAULT false"`
+	SyncToRepos       bool   `xorm:"NOT NULL DEFAULT false"`   // only for the labels of an organization
+	SyncedFromID      int64  `xorm:"INDEX NOT NULL DEFAULT 0"` // the label of the organization a repository label is synced from
 	NumIssues         int
 	NumClosedIssues   int
 	NumOpenIssues     int    `xorm:"-"`
@@ -246,7 +249,7 @@ func UpdateLabel(l *Label) error {
 	if !LabelColorPattern.MatchString(l.Color) {
 		return fmt.Errorf("bad color code: %s", l.Color)
 	}
-	return updateLabelCols(x, l, "name", "description", "color")
+	return updateLabelCols(x, l, "name", "description", "color", "is_question", "sync_to_repos")
 }
 
 // DeleteLabel delete a label
@@ -273,6 +276,12 @@ func DeleteLabel(id, labelID int64) error {
 		return nil
 	}
 
+	if label.BelongsToOrg() {
+		if err = unlinkSyncedLabels(sess, labelID); err != nil {
+			return err
+		}
+	}
+
 	if _, err = sess.ID(labelID).Delete(new(Label)); err != nil {
 		return err
 	} else if _, err = sess.
@@ -615,6 +624,30 @@ type IssueLabel struct {
 	ID      int64 `xorm:"pk autoincr"`
 	IssueID int64 `xorm:"UNIQUE(s)"`
 	LabelID int64 `xorm:"UNIQUE(s)"`
+	// AddedByLabeler is true if the label was added by the labeler of the
+	// repository, which removes it again when it does not match anymore.
+	AddedByLabeler bool `xorm:"NOT NULL DEFAULT false"`
+}
+
+// GetLabelerLabelIDs returns the IDs of the labels of the issue added by the labeler.
+func GetLabelerLabelIDs(issueID int64) ([]int64, error) {
+	labelIDs := make([]int64, 0, 5)
+	return labelIDs, x.Table("issue_label").
+		Where("issue_id = ? AND added_by_labeler = ?", issueID, true).
+		Cols("label_id").
+		Find(&labelIDs)
+}
+
+// MarkIssueLabelsAddedByLabeler marks the labels of the issue as added by the labeler.
+func MarkIssueLabelsAddedByLabeler(issueID int64, labelIDs []int64) error {
+	if len(labelIDs) == 0 {
+		return nil
+	}
+	_, err := x.Where("issue_id = ?", issueID).
+		In("label_id", labelIDs).
+		Cols("added_by_labeler").
+		Update(&IssueLabel{AddedByLabeler: true})
+	return err
 }
 
 func hasIssueLabel(e Engine, issueID, labelID int64) bool {
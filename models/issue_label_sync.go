@@ -0,0 +1,190 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// ErrLabelSyncConflict represents a "LabelSyncConflict" kind of error.
+type ErrLabelSyncConflict struct {
+	RepoID int64
+	Name   string
+}
+
+// IsErrLabelSyncConflict checks if an error is a ErrLabelSyncConflict.
+func IsErrLabelSyncConflict(err error) bool {
+	_, ok := err.(ErrLabelSyncConflict)
+	return ok
+}
+
+func (err ErrLabelSyncConflict) Error() string {
+	return fmt.Sprintf("repository already has another label with the name of the synced label [repo_id: %d, name: %s]", err.RepoID, err.Name)
+}
+
+// LabelSyncResult is the result of the synchronization of the labels of an organization.
+type LabelSyncResult struct {
+	Synced    int
+	Conflicts int
+}
+
+func (res *LabelSyncResult) add(err error) error {
+	if err == nil {
+		res.Synced++
+		return nil
+	} else if IsErrLabelSyncConflict(err) {
+		log.Warn("Unable to sync label: %v", err)
+		res.Conflicts++
+		return nil
+	}
+	return err
+}
+
+// getLabelSyncRepos returns the repositories of the organization which did not opt out of the synchronization.
+func getLabelSyncRepos(e Engine, orgID int64) ([]*Repository, error) {
+	repos := make([]*Repository, 0, 10)
+	return repos, e.
+		Where("owner_id = ?", orgID).
+		And("disable_org_label_sync = ?", false).
+		Find(&repos)
+}
+
+// syncOrgLabelToRepo creates or updates the copy of the label of the organization in the repository.
+// A label of the repository with the same name is adopted if it is not synced yet, renaming the
+// copy to the name of another label of the repository is a conflict.
+func syncOrgLabelToRepo(e Engine, orgLabel *Label, repoID int64) error {
+	synced := make([]*Label, 0, 1)
+	if err := e.Where("repo_id = ?", repoID).And("synced_from_id = ?", orgLabel.ID).Find(&synced); err != nil {
+		return err
+	}
+
+	sameName := make([]*Label, 0, 1)
+	if err := e.Where("repo_id = ?", repoID).And("lower(name) = ?", strings.ToLower(orgLabel.Name)).Find(&sameName); err != nil {
+		return err
+	}
+
+	var label *Label
+	if len(synced) > 0 {
+		label = synced[0]
+		for _, l := range sameName {
+			if l.ID != label.ID {
+				return ErrLabelSyncConflict{RepoID: repoID, Name: orgLabel.Name}
+			}
+		}
+	} else {
+		for _, l := range sameName {
+			if l.SyncedFromID == 0 {
+				label = l
+				break
+			}
+		}
+		if label == nil && len(sameName) > 0 {
+			return ErrLabelSyncConflict{RepoID: repoID, Name: orgLabel.Name}
+		}
+	}
+
+	if label == nil {
+		return newLabel(e, &Label{
+			RepoID:       repoID,
+			Name:         orgLabel.Name,
+			Description:  orgLabel.Description,
+			Color:        orgLabel.Color,
+			IsQuestion:   orgLabel.IsQuestion,
+			SyncedFromID: orgLabel.ID,
+		})
+	}
+
+	label.Name = orgLabel.Name
+	label.Description = orgLabel.Description
+	label.Color = orgLabel.Color
+	label.IsQuestion = orgLabel.IsQuestion
+	label.SyncedFromID = orgLabel.ID
+	return updateLabelCols(e, label, "name", "description", "color", "is_question", "synced_from_id")
+}
+
+// unlinkSyncedLabels keeps the copies of the label of the organization as labels of their repositories.
+func unlinkSyncedLabels(e Engine, orgLabelID int64) error {
+	_, err := e.Where("synced_from_id = ?", orgLabelID).Cols("synced_from_id").Update(&Label{SyncedFromID: 0})
+	return err
+}
+
+// SyncOrgLabel synchronizes the label of an organization to its repositories, or unlinks
+// its copies if the label is not synchronized anymore.
+func SyncOrgLabel(orgLabel *Label) (*LabelSyncResult, error) {
+	res := &LabelSyncResult{}
+	if !orgLabel.BelongsToOrg() {
+		return res, nil
+	}
+	if !orgLabel.SyncToRepos {
+		return res, unlinkSyncedLabels(x, orgLabel.ID)
+	}
+
+	repos, err := getLabelSyncRepos(x, orgLabel.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, repo := range repos {
+		if err := res.add(syncOrgLabelToRepo(x, orgLabel, repo.ID)); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// SyncOrgLabels synchronizes all the synced labels of an organization to its repositories.
+func SyncOrgLabels(orgID int64) (*LabelSyncResult, error) {
+	labels := make([]*Label, 0, 10)
+	if err := x.Where("org_id = ?", orgID).And("sync_to_repos = ?", true).Find(&labels); err != nil {
+		return nil, err
+	}
+
+	res := &LabelSyncResult{}
+	for _, label := range labels {
+		labelRes, err := SyncOrgLabel(label)
+		if err != nil {
+			return nil, err
+		}
+		res.Synced += labelRes.Synced
+		res.Conflicts += labelRes.Conflicts
+	}
+	return res, nil
+}
+
+// SyncOrgLabelsToRepo synchronizes the synced labels of the organization owning the repository to it.
+func SyncOrgLabelsToRepo(repo *Repository) (*LabelSyncResult, error) {
+	res := &LabelSyncResult{}
+	if repo.DisableOrgLabelSync {
+		return res, nil
+	}
+
+	labels := make([]*Label, 0, 10)
+	if err := x.Where("org_id = ?", repo.OwnerID).And("sync_to_repos = ?", true).Find(&labels); err != nil {
+		return nil, err
+	}
+	for _, label := range labels {
+		if err := res.add(syncOrgLabelToRepo(x, label, repo.ID)); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// UpdateRepoOrgLabelSync changes if the repository opts out of the synchronization of the labels of its
+// organization, the copies of the labels of the organization are kept as labels of the repository.
+func UpdateRepoOrgLabelSync(repo *Repository, disable bool) error {
+	repo.DisableOrgLabelSync = disable
+	if _, err := x.ID(repo.ID).Cols("disable_org_label_sync").Update(repo); err != nil {
+		return err
+	}
+	if disable {
+		_, err := x.Where("repo_id = ?", repo.ID).And("synced_from_id > 0").Cols("synced_from_id").Update(&Label{SyncedFromID: 0})
+		return err
+	}
+	_, err := SyncOrgLabelsToRepo(repo)
+	return err
+}
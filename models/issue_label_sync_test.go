@@ -0,0 +1,46 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncOrgLabel(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	numRepos := getCount(t, x.Where("owner_id = ?", 3), &Repository{})
+
+	orgLabel := AssertExistsAndLoadBean(t, &Label{ID: 3}).(*Label)
+	orgLabel.SyncToRepos = true
+	assert.NoError(t, UpdateLabel(orgLabel))
+	res, err := SyncOrgLabel(orgLabel)
+	assert.NoError(t, err)
+	assert.EqualValues(t, numRepos, res.Synced)
+	assert.EqualValues(t, 0, res.Conflicts)
+	AssertExistsAndLoadBean(t, &Label{RepoID: 3, Name: "orglabel3", SyncedFromID: 3})
+
+	// renaming the label onto another label of a repository is a conflict
+	assert.NoError(t, NewLabel(&Label{RepoID: 3, Name: "renamed", Color: "#123456"}))
+	orgLabel.Name = "renamed"
+	assert.NoError(t, UpdateLabel(orgLabel))
+	res, err = SyncOrgLabel(orgLabel)
+	assert.NoError(t, err)
+	assert.EqualValues(t, numRepos-1, res.Synced)
+	assert.EqualValues(t, 1, res.Conflicts)
+	AssertExistsAndLoadBean(t, &Label{RepoID: 5, Name: "renamed", SyncedFromID: 3})
+
+	// opting out keeps the copy as a label of the repository
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 5}).(*Repository)
+	assert.NoError(t, UpdateRepoOrgLabelSync(repo, true))
+	AssertExistsAndLoadBean(t, &Label{RepoID: 5, Name: "renamed", SyncedFromID: 0})
+	res, err = SyncOrgLabelsToRepo(repo)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, res.Synced)
+
+	assert.NoError(t, DeleteLabel(3, orgLabel.ID))
+	AssertNotExistsBean(t, &Label{SyncedFromID: 3})
+}
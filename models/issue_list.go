@@ -7,6 +7,8 @@ package models
 import (
 	"fmt"
 
+	"code.gitea.io/gitea/modules/timeutil"
+
 	"xorm.io/builder"
 )
 
@@ -18,6 +20,17 @@ const (
 	defaultMaxInSize = 50
 )
 
+// LastUpdatedUnix returns the most recent update time of the issues.
+func (issues IssueList) LastUpdatedUnix() timeutil.TimeStamp {
+	var updated timeutil.TimeStamp
+	for _, issue := range issues {
+		if issue.UpdatedUnix > updated {
+			updated = issue.UpdatedUnix
+		}
+	}
+	return updated
+}
+
 func (issues IssueList) getRepoIDs() []int64 {
 	repoIDs := make(map[int64]struct{}, len(issues))
 	for _, issue := range issues {
@@ -207,6 +220,48 @@ func (issues IssueList) loadMilestones(e Engine) error {
 	return nil
 }
 
+func (issues IssueList) getWorkflowStateIDs() []int64 {
+	var ids = make(map[int64]struct{}, len(issues))
+	for _, issue := range issues {
+		if issue.WorkflowStateID == 0 {
+			continue
+		}
+		if _, ok := ids[issue.WorkflowStateID]; !ok {
+			ids[issue.WorkflowStateID] = struct{}{}
+		}
+	}
+	return keysInt64(ids)
+}
+
+func (issues IssueList) loadWorkflowStates(e Engine) error {
+	stateIDs := issues.getWorkflowStateIDs()
+	if len(stateIDs) == 0 {
+		return nil
+	}
+
+	stateMaps := make(map[int64]*WorkflowState, len(stateIDs))
+	var left = len(stateIDs)
+	for left > 0 {
+		var limit = defaultMaxInSize
+		if left < limit {
+			limit = left
+		}
+		err := e.
+			In("id", stateIDs[:limit]).
+			Find(&stateMaps)
+		if err != nil {
+			return err
+		}
+		left -= limit
+		stateIDs = stateIDs[limit:]
+	}
+
+	for _, issue := range issues {
+		issue.WorkflowState = stateMaps[issue.WorkflowStateID]
+	}
+	return nil
+}
+
 func (issues IssueList) loadAssignees(e Engine) error {
 	if len(issues) == 0 {
 		return nil
@@ -480,6 +535,10 @@ func (issues IssueList) loadAttributes(e Engine) error {
 		return fmt.Errorf("issue.loadAttributes: loadMilestones: %v", err)
 	}
 
+	if err := issues.loadWorkflowStates(e); err != nil {
+		return fmt.Errorf("issue.loadAttributes: loadWorkflowStates: %v", err)
+	}
+
 	if err := issues.loadAssignees(e); err != nil {
 		return fmt.Errorf("issue.loadAttributes: loadAssignees: %v", err)
 	}
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 
+	"code.gitea.io/gitea/modules/emoji"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 
@@ -83,7 +84,7 @@ func FindIssueReactions(issue *Issue, listOptions ListOptions) (ReactionList, er
 func findReactions(e Engine, opts FindReactionsOptions) ([]*Reaction, error) {
 	e = e.
 		Where(opts.toConds()).
-		In("reaction.`type`", setting.UI.Reactions).
+		In("reaction.`type`", AllowedReactions()).
 		Asc("reaction.issue_id", "reaction.comment_id", "reaction.created_unix", "reaction.id")
 	if opts.Page != 0 {
 		e = opts.setEnginePagination(e)
@@ -128,6 +129,34 @@ func createReaction(e *xorm.Session, opts *ReactionOptions) (*Reaction, error) {
 	return reaction, nil
 }
 
+// AllowedReactions returns the reactions which can be used: the configured
+// ones followed by the custom emoji.
+func AllowedReactions() []string {
+	custom := emoji.CustomNames()
+	reactions := make([]string, 0, len(setting.UI.Reactions)+len(custom))
+	reactions = append(reactions, setting.UI.Reactions...)
+	return append(reactions, custom...)
+}
+
+// ReactionStat represents how often a reaction has been used.
+type ReactionStat struct {
+	Type  string
+	Count int64 `xorm:"num"`
+}
+
+// GetReactionStats returns the usage of all allowed reactions across the
+// instance, most used first.
+func GetReactionStats() ([]*ReactionStat, error) {
+	stats := make([]*ReactionStat, 0, len(setting.UI.Reactions))
+	return stats, x.Table("reaction").
+		Select("`type`, COUNT(*) AS num").
+		In("`type`", AllowedReactions()).
+		GroupBy("`type`").
+		Desc("num").
+		Asc("`type`").
+		Find(&stats)
+}
+
 // ReactionOptions defines options for creating or deleting reactions
 type ReactionOptions struct {
 	Type    string
@@ -138,7 +167,7 @@ type ReactionOptions struct {
 
 // CreateReaction creates reaction for issue or comment.
 func CreateReaction(opts *ReactionOptions) (*Reaction, error) {
-	if !setting.UI.ReactionsMap[opts.Type] {
+	if _, isCustom := emoji.FromCustom(opts.Type); !isCustom && !setting.UI.ReactionsMap[opts.Type] {
 		return nil, ErrForbiddenIssueReaction{opts.Type}
 	}
 
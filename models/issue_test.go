@@ -179,6 +179,33 @@ func TestIssues(t *testing.T) {
 	}
 }
 
+func TestIssues_ReviewRequested(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	reviewRequested := func(userID int64) []int64 {
+		issues, err := Issues(&IssuesOptions{ReviewRequestedID: userID, SortType: "oldest"})
+		assert.NoError(t, err)
+		ids := make([]int64, 0, len(issues))
+		for _, issue := range issues {
+			ids = append(ids, issue.ID)
+		}
+		return ids
+	}
+
+	assert.Empty(t, reviewRequested(5))
+
+	_, err := x.Insert(&Review{Type: ReviewTypeRequest, ReviewerID: 5, IssueID: 2})
+	assert.NoError(t, err)
+	_, err = x.Insert(&Review{Type: ReviewTypeRequest, ReviewerID: 5, IssueID: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, reviewRequested(5))
+
+	// the request is done once the user reviewed
+	_, err = x.Insert(&Review{Type: ReviewTypeApprove, ReviewerID: 5, IssueID: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, reviewRequested(5))
+}
+
 func TestGetUserIssueStats(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	for _, test := range []struct {
@@ -0,0 +1,246 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/issuetracker"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/validation"
+)
+
+// IssueTrackerSync is the integration of a repository with a Jira or Redmine issue tracker: the
+// commits and pull requests referencing its issues are commented on them, and the commit messages
+// can be required to reference an existing issue.
+type IssueTrackerSync struct {
+	ID         int64             `xorm:"pk autoincr"`
+	RepoID     int64             `xorm:"UNIQUE NOT NULL"`
+	Type       issuetracker.Type `xorm:"VARCHAR(20) NOT NULL"`
+	URL        string            `xorm:"TEXT NOT NULL"`
+	ProjectKey string
+	Username   string
+	Token      string `xorm:"TEXT"`
+
+	CommentOnCommits      bool `xorm:"NOT NULL DEFAULT false"`
+	CommentOnPullRequests bool `xorm:"NOT NULL DEFAULT false"`
+	// RequireReference rejects the commits whose message references no issue of the tracker
+	RequireReference bool `xorm:"NOT NULL DEFAULT false"`
+	// VerifyReference also requires one of the referenced issues to exist in the tracker
+	VerifyReference bool `xorm:"NOT NULL DEFAULT false"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+
+	// existingKeys caches the keys verified while checking the commits of a push
+	existingKeys map[string]bool `xorm:"-"`
+}
+
+// ErrIssueTrackerSyncInvalid represents an issue tracker sync with an invalid field.
+type ErrIssueTrackerSyncInvalid struct {
+	Field string
+	Value string
+}
+
+// IsErrIssueTrackerSyncInvalid checks if an error is a ErrIssueTrackerSyncInvalid.
+func IsErrIssueTrackerSyncInvalid(err error) bool {
+	_, ok := err.(ErrIssueTrackerSyncInvalid)
+	return ok
+}
+
+func (err ErrIssueTrackerSyncInvalid) Error() string {
+	return fmt.Sprintf("invalid issue tracker sync [%s: %s]", err.Field, err.Value)
+}
+
+// Config returns the connection to the issue tracker.
+func (s *IssueTrackerSync) Config() *issuetracker.Config {
+	return &issuetracker.Config{
+		Type:       s.Type,
+		URL:        s.URL,
+		ProjectKey: s.ProjectKey,
+		Username:   s.Username,
+		Token:      s.Token,
+	}
+}
+
+// CheckReference returns an ErrCommitMessageRejected if the commit message does not reference
+// an issue of the tracker, or none of the issues it references exists when they are verified.
+// The issues are considered to exist if the tracker can not be reached.
+func (s *IssueTrackerSync) CheckReference(message string) error {
+	cfg := s.Config()
+	keys := cfg.FindKeys(message)
+	if len(keys) == 0 {
+		reason := fmt.Sprintf("the message does not reference a %s issue", s.Type.DisplayName())
+		if s.Type == issuetracker.TypeJira && s.ProjectKey != "" {
+			reason = fmt.Sprintf("the message does not reference an issue of the %s project %s", s.Type.DisplayName(), s.ProjectKey)
+		}
+		return ErrCommitMessageRejected{Reason: reason}
+	}
+	if !s.VerifyReference {
+		return nil
+	}
+
+	if s.existingKeys == nil {
+		s.existingKeys = make(map[string]bool)
+	}
+	for _, key := range keys {
+		exists, checked := s.existingKeys[key]
+		if !checked {
+			var err error
+			if exists, err = cfg.IssueExists(key); err != nil {
+				log.Warn("Unable to verify the %s issue %s of repository %d: %v", s.Type.DisplayName(), key, s.RepoID, err)
+				return nil
+			}
+			s.existingKeys[key] = exists
+		}
+		if exists {
+			return nil
+		}
+	}
+	return ErrCommitMessageRejected{
+		Reason: fmt.Sprintf("the referenced %s issues %s do not exist", s.Type.DisplayName(), strings.Join(keys, ", ")),
+	}
+}
+
+// GetIssueTrackerSync returns the issue tracker sync of a repository, or nil if it has none or
+// the sync is disabled.
+func GetIssueTrackerSync(repoID int64) (*IssueTrackerSync, error) {
+	if !setting.IssueTrackerSync.Enabled {
+		return nil, nil
+	}
+	s := &IssueTrackerSync{RepoID: repoID}
+	has, err := x.Get(s)
+	if err != nil || !has {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SaveIssueTrackerSync creates or updates the issue tracker sync of a repository, the token is
+// kept if it is empty.
+func SaveIssueTrackerSync(s *IssueTrackerSync) error {
+	s.URL = strings.TrimSuffix(strings.TrimSpace(s.URL), "/")
+	s.ProjectKey = strings.TrimSpace(s.ProjectKey)
+	s.Username = strings.TrimSpace(s.Username)
+	if !s.Type.IsValid() {
+		return ErrIssueTrackerSyncInvalid{Field: "type", Value: string(s.Type)}
+	}
+	if !validation.IsValidURL(s.URL) {
+		return ErrIssueTrackerSyncInvalid{Field: "url", Value: s.URL}
+	}
+	if s.Type != issuetracker.TypeJira {
+		s.ProjectKey = ""
+		s.Username = ""
+	} else if s.ProjectKey != "" && !issuetracker.IsValidProjectKey(s.ProjectKey) {
+		return ErrIssueTrackerSyncInvalid{Field: "project_key", Value: s.ProjectKey}
+	}
+	if !s.RequireReference {
+		s.VerifyReference = false
+	}
+
+	existing := &IssueTrackerSync{RepoID: s.RepoID}
+	has, err := x.Get(existing)
+	if err != nil {
+		return err
+	}
+	if !has {
+		_, err = x.Insert(s)
+		return err
+	}
+	if s.Token == "" {
+		s.Token = existing.Token
+	}
+	s.ID = existing.ID
+	_, err = x.ID(s.ID).AllCols().Update(s)
+	return err
+}
+
+// DeleteIssueTrackerSync removes the issue tracker sync of a repository.
+func DeleteIssueTrackerSync(repoID int64) error {
+	_, err := x.Delete(&IssueTrackerSync{RepoID: repoID})
+	return err
+}
+
+// IssueTrackerEventKind is the kind of object referencing an issue of a tracker
+type IssueTrackerEventKind string
+
+const (
+	// IssueTrackerEventCommit is a commit referencing an issue, its ref is the commit ID
+	IssueTrackerEventCommit IssueTrackerEventKind = "commit"
+	// IssueTrackerEventPullRequest is a pull request referencing an issue, its ref is the index
+	IssueTrackerEventPullRequest IssueTrackerEventKind = "pull"
+)
+
+// IssueTrackerEvent is a comment posted to an issue of the tracker of a repository. The same
+// action of a commit or pull request is only posted once.
+type IssueTrackerEvent struct {
+	ID     int64                 `xorm:"pk autoincr"`
+	RepoID int64                 `xorm:"UNIQUE(s) NOT NULL"`
+	Key    string                `xorm:"UNIQUE(s) VARCHAR(50) NOT NULL"`
+	Kind   IssueTrackerEventKind `xorm:"UNIQUE(s) VARCHAR(20) NOT NULL"`
+	Ref    string                `xorm:"UNIQUE(s) VARCHAR(64) NOT NULL"`
+	Action string                `xorm:"UNIQUE(s) VARCHAR(20) NOT NULL"`
+	// Link and Title describe the commit or pull request
+	Link    string `xorm:"TEXT"`
+	Title   string `xorm:"TEXT"`
+	Comment string `xorm:"TEXT"`
+
+	IsDelivered   bool               `xorm:"NOT NULL DEFAULT false"`
+	Error         string             `xorm:"TEXT"`
+	CreatedUnix   timeutil.TimeStamp `xorm:"INDEX created"`
+	DeliveredUnix timeutil.TimeStamp
+}
+
+// CreateIssueTrackerEvent records an event to post, it returns false if the same event has
+// already been recorded.
+func CreateIssueTrackerEvent(event *IssueTrackerEvent) (bool, error) {
+	has, err := x.Exist(&IssueTrackerEvent{
+		RepoID: event.RepoID,
+		Key:    event.Key,
+		Kind:   event.Kind,
+		Ref:    event.Ref,
+		Action: event.Action,
+	})
+	if err != nil || has {
+		return false, err
+	}
+	if _, err = x.Insert(event); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetIssueTrackerEventByID returns an event of an issue tracker sync.
+func GetIssueTrackerEventByID(id int64) (*IssueTrackerEvent, error) {
+	event := new(IssueTrackerEvent)
+	has, err := x.ID(id).Get(event)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("issue tracker event %d does not exist", id)
+	}
+	return event, nil
+}
+
+// UpdateIssueTrackerEventDelivery records the result of the delivery of an event.
+func UpdateIssueTrackerEventDelivery(event *IssueTrackerEvent, deliveryErr error) error {
+	event.IsDelivered = deliveryErr == nil
+	event.Error = ""
+	if deliveryErr != nil {
+		event.Error = deliveryErr.Error()
+	}
+	event.DeliveredUnix = timeutil.TimeStampNow()
+	_, err := x.ID(event.ID).Cols("is_delivered", "error", "delivered_unix").Update(event)
+	return err
+}
+
+// GetRecentIssueTrackerEvents returns the latest events of the issue tracker sync of a repository.
+func GetRecentIssueTrackerEvents(repoID int64, limit int) ([]*IssueTrackerEvent, error) {
+	events := make([]*IssueTrackerEvent, 0, limit)
+	return events, x.Where("repo_id = ?", repoID).Desc("id").Limit(limit).Find(&events)
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.gitea.io/gitea/modules/issuetracker"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveIssueTrackerSync(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, SaveIssueTrackerSync(&IssueTrackerSync{
+		RepoID:     1,
+		Type:       issuetracker.TypeJira,
+		URL:        " https://jira.example.com/ ",
+		ProjectKey: "PROJ",
+		Username:   "bot@example.com",
+		Token:      "secret",
+	}))
+	s, err := GetIssueTrackerSync(1)
+	assert.NoError(t, err)
+	if assert.NotNil(t, s) {
+		assert.Equal(t, "https://jira.example.com", s.URL)
+		assert.Equal(t, "secret", s.Token)
+	}
+
+	// the token is kept when it is not changed, the Jira fields are dropped for Redmine
+	assert.NoError(t, SaveIssueTrackerSync(&IssueTrackerSync{
+		RepoID:          1,
+		Type:            issuetracker.TypeRedmine,
+		URL:             "https://redmine.example.com",
+		ProjectKey:      "PROJ",
+		Username:        "bot@example.com",
+		VerifyReference: true,
+	}))
+	s = AssertExistsAndLoadBean(t, &IssueTrackerSync{ID: s.ID, RepoID: 1, Type: issuetracker.TypeRedmine, Token: "secret"}).(*IssueTrackerSync)
+	assert.Empty(t, s.ProjectKey)
+	assert.Empty(t, s.Username)
+	// the issues are only verified when a reference is required
+	assert.False(t, s.VerifyReference)
+
+	assert.True(t, IsErrIssueTrackerSyncInvalid(SaveIssueTrackerSync(&IssueTrackerSync{RepoID: 1, Type: "github", URL: "https://github.com"})))
+	assert.True(t, IsErrIssueTrackerSyncInvalid(SaveIssueTrackerSync(&IssueTrackerSync{RepoID: 1, Type: issuetracker.TypeJira, URL: "ftp://jira.example.com"})))
+	assert.True(t, IsErrIssueTrackerSyncInvalid(SaveIssueTrackerSync(&IssueTrackerSync{RepoID: 1, Type: issuetracker.TypeJira, URL: "https://jira.example.com", ProjectKey: "proj"})))
+
+	defer func(enabled bool) {
+		setting.IssueTrackerSync.Enabled = enabled
+	}(setting.IssueTrackerSync.Enabled)
+	setting.IssueTrackerSync.Enabled = false
+	s, err = GetIssueTrackerSync(1)
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	setting.IssueTrackerSync.Enabled = true
+	assert.NoError(t, DeleteIssueTrackerSync(1))
+	AssertNotExistsBean(t, &IssueTrackerSync{RepoID: 1})
+}
+
+func TestIssueTrackerSyncCheckReference(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/rest/api/2/issue/PROJ-1" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	assert.NoError(t, SaveIssueTrackerSync(&IssueTrackerSync{
+		RepoID:           1,
+		Type:             issuetracker.TypeJira,
+		URL:              server.URL,
+		ProjectKey:       "PROJ",
+		RequireReference: true,
+	}))
+
+	// the reference is required by the commit message policy of the repository
+	policy, err := GetCommitMessagePolicyForUser(1, 2)
+	assert.NoError(t, err)
+	if assert.NotNil(t, policy) {
+		assert.NoError(t, policy.CheckMessage("PROJ-2: fix the build"))
+		err = policy.CheckMessage("OPS-1: fix the build")
+		assert.True(t, IsErrCommitMessageRejected(err))
+		assert.Contains(t, err.Error(), "the Jira project PROJ")
+	}
+
+	s, err := GetIssueTrackerSync(1)
+	assert.NoError(t, err)
+	s.VerifyReference = true
+	assert.NoError(t, SaveIssueTrackerSync(s))
+	policy, err = GetCommitMessagePolicyForUser(1, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, policy.CheckMessage("PROJ-1: fix the build"))
+	assert.NoError(t, policy.CheckMessage("PROJ-2 and PROJ-1"))
+	err = policy.CheckMessage("PROJ-2: fix the build")
+	assert.True(t, IsErrCommitMessageRejected(err))
+	assert.Contains(t, err.Error(), "PROJ-2 do not exist")
+	// the existence of the issues is only verified once per policy
+	assert.Equal(t, 2, requests)
+
+	// the issues are considered to exist if the tracker can not be reached
+	server.Close()
+	policy, err = GetCommitMessagePolicyForUser(1, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, policy.CheckMessage("PROJ-3: fix the build"))
+
+	assert.NoError(t, DeleteIssueTrackerSync(1))
+	policy, err = GetCommitMessagePolicyForUser(1, 2)
+	assert.NoError(t, err)
+	assert.Nil(t, policy)
+}
+
+func TestCreateIssueTrackerEvent(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	event := &IssueTrackerEvent{RepoID: 1, Key: "PROJ-1", Kind: IssueTrackerEventPullRequest, Ref: "2", Action: "opened"}
+	created, err := CreateIssueTrackerEvent(event)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	created, err = CreateIssueTrackerEvent(&IssueTrackerEvent{RepoID: 1, Key: "PROJ-1", Kind: IssueTrackerEventPullRequest, Ref: "2", Action: "opened"})
+	assert.NoError(t, err)
+	assert.False(t, created)
+	created, err = CreateIssueTrackerEvent(&IssueTrackerEvent{RepoID: 1, Key: "PROJ-1", Kind: IssueTrackerEventPullRequest, Ref: "2", Action: "merged"})
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	assert.NoError(t, UpdateIssueTrackerEventDelivery(event, nil))
+	assert.True(t, AssertExistsAndLoadBean(t, &IssueTrackerEvent{ID: event.ID}).(*IssueTrackerEvent).IsDelivered)
+
+	events, err := GetRecentIssueTrackerEvents(1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "merged", events[0].Action)
+	}
+}
@@ -0,0 +1,353 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// WorkflowState represents a custom issue state defined by a repository,
+// e.g. "Triage", "In Progress" or "Blocked". Every state maps onto the
+// classic open/closed status through IsClosed.
+type WorkflowState struct {
+	ID          int64 `xorm:"pk autoincr"`
+	RepoID      int64 `xorm:"INDEX"`
+	Name        string
+	Description string
+	Color       string `xorm:"VARCHAR(7)"`
+	IsClosed    bool
+	Sort        int
+
+	// AllowedTransitions holds the IDs of the states an issue in this state
+	// may move to. An empty list allows any transition.
+	AllowedTransitions []int64 `xorm:"-"`
+	NumIssues          int     `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// WorkflowTransition represents an allowed move between two workflow states.
+type WorkflowTransition struct {
+	ID          int64 `xorm:"pk autoincr"`
+	RepoID      int64 `xorm:"INDEX"`
+	FromStateID int64 `xorm:"UNIQUE(s)"`
+	ToStateID   int64 `xorm:"UNIQUE(s)"`
+}
+
+// ErrWorkflowStateNotExist represents a "WorkflowStateNotExist" kind of error.
+type ErrWorkflowStateNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrWorkflowStateNotExist checks if an error is a ErrWorkflowStateNotExist.
+func IsErrWorkflowStateNotExist(err error) bool {
+	_, ok := err.(ErrWorkflowStateNotExist)
+	return ok
+}
+
+func (err ErrWorkflowStateNotExist) Error() string {
+	return fmt.Sprintf("workflow state does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
+// ErrWorkflowTransitionNotAllowed represents a "WorkflowTransitionNotAllowed" kind of error.
+type ErrWorkflowTransitionNotAllowed struct {
+	FromStateID int64
+	ToStateID   int64
+}
+
+// IsErrWorkflowTransitionNotAllowed checks if an error is a ErrWorkflowTransitionNotAllowed.
+func IsErrWorkflowTransitionNotAllowed(err error) bool {
+	_, ok := err.(ErrWorkflowTransitionNotAllowed)
+	return ok
+}
+
+func (err ErrWorkflowTransitionNotAllowed) Error() string {
+	return fmt.Sprintf("workflow transition is not allowed [from: %d, to: %d]", err.FromStateID, err.ToStateID)
+}
+
+// ForegroundColor returns a HTML foreground color for the state chip.
+func (s *WorkflowState) ForegroundColor() template.CSS {
+	return (&Label{Color: s.Color}).ForegroundColor()
+}
+
+// CanTransitionTo returns true if an issue in this state may be moved to the given state.
+func (s *WorkflowState) CanTransitionTo(stateID int64) bool {
+	if len(s.AllowedTransitions) == 0 || stateID == s.ID {
+		return true
+	}
+	for _, id := range s.AllowedTransitions {
+		if id == stateID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *WorkflowState) loadAllowedTransitions(e Engine) error {
+	s.AllowedTransitions = make([]int64, 0, 5)
+	return e.Table("workflow_transition").
+		Where("from_state_id = ?", s.ID).
+		Asc("to_state_id").
+		Cols("to_state_id").
+		Find(&s.AllowedTransitions)
+}
+
+// NewWorkflowState creates a new workflow state for a repository.
+func NewWorkflowState(state *WorkflowState) error {
+	if !LabelColorPattern.MatchString(state.Color) {
+		return fmt.Errorf("bad color code: %s", state.Color)
+	}
+	state.Name = strings.TrimSpace(state.Name)
+	_, err := x.Insert(state)
+	return err
+}
+
+func getWorkflowStateInRepoByID(e Engine, repoID, id int64) (*WorkflowState, error) {
+	if id <= 0 {
+		return nil, ErrWorkflowStateNotExist{ID: id, RepoID: repoID}
+	}
+
+	state := new(WorkflowState)
+	has, err := e.ID(id).Where("repo_id = ?", repoID).Get(state)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrWorkflowStateNotExist{ID: id, RepoID: repoID}
+	}
+	return state, state.loadAllowedTransitions(e)
+}
+
+// GetWorkflowStateInRepoByID returns a workflow state by ID in given repository.
+func GetWorkflowStateInRepoByID(repoID, id int64) (*WorkflowState, error) {
+	return getWorkflowStateInRepoByID(x, repoID, id)
+}
+
+func getWorkflowStatesByRepoID(e Engine, repoID int64) ([]*WorkflowState, error) {
+	states := make([]*WorkflowState, 0, 5)
+	if err := e.Where("repo_id = ?", repoID).
+		Asc("sort").
+		Asc("id").
+		Find(&states); err != nil {
+		return nil, err
+	}
+	for _, state := range states {
+		if err := state.loadAllowedTransitions(e); err != nil {
+			return nil, err
+		}
+	}
+	return states, nil
+}
+
+// GetWorkflowStatesByRepoID returns all workflow states of a repository ordered by their sort value.
+func GetWorkflowStatesByRepoID(repoID int64) ([]*WorkflowState, error) {
+	return getWorkflowStatesByRepoID(x, repoID)
+}
+
+// UpdateWorkflowState updates the information of a workflow state.
+func UpdateWorkflowState(state *WorkflowState) error {
+	if !LabelColorPattern.MatchString(state.Color) {
+		return fmt.Errorf("bad color code: %s", state.Color)
+	}
+	state.Name = strings.TrimSpace(state.Name)
+	_, err := x.ID(state.ID).Cols("name", "description", "color", "is_closed", "sort").Update(state)
+	return err
+}
+
+// SetWorkflowStateTransitions replaces the allowed transitions going out of a workflow state.
+// All target states must belong to the same repository.
+func SetWorkflowStateTransitions(state *WorkflowState, toStateIDs []int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Delete(&WorkflowTransition{FromStateID: state.ID}); err != nil {
+		return err
+	}
+
+	for _, toID := range toStateIDs {
+		if toID == state.ID {
+			continue
+		}
+		if _, err := getWorkflowStateInRepoByID(sess, state.RepoID, toID); err != nil {
+			return err
+		}
+		if _, err := sess.Insert(&WorkflowTransition{
+			RepoID:      state.RepoID,
+			FromStateID: state.ID,
+			ToStateID:   toID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := state.loadAllowedTransitions(sess); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// DeleteWorkflowState deletes a workflow state of a repository. Issues in this
+// state fall back to having no workflow state.
+func DeleteWorkflowState(repoID, id int64) error {
+	state, err := GetWorkflowStateInRepoByID(repoID, id)
+	if err != nil {
+		if IsErrWorkflowStateNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.Where(builder.Eq{"from_state_id": state.ID}.Or(builder.Eq{"to_state_id": state.ID})).
+		Delete(new(WorkflowTransition)); err != nil {
+		return err
+	}
+	if _, err = sess.Exec("UPDATE `issue` SET workflow_state_id = 0 WHERE workflow_state_id = ?", state.ID); err != nil {
+		return err
+	}
+	if _, err = sess.ID(state.ID).Delete(new(WorkflowState)); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// CountIssuesByWorkflowState returns the number of issues of a repository, open and
+// closed together, by workflow state ID. Issues without workflow state are counted for ID 0.
+func CountIssuesByWorkflowState(repoID int64, isPull bool) (map[int64]int64, error) {
+	countsSlice := make([]*struct {
+		WorkflowStateID int64
+		Count           int64
+	}, 0, 10)
+	if err := x.Select("workflow_state_id, count(*) AS count").
+		Table("issue").
+		Where("repo_id = ? AND is_pull = ?", repoID, isPull).
+		GroupBy("workflow_state_id").
+		Find(&countsSlice); err != nil {
+		return nil, err
+	}
+
+	countMap := make(map[int64]int64, len(countsSlice))
+	for _, c := range countsSlice {
+		countMap[c.WorkflowStateID] = c.Count
+	}
+	return countMap, nil
+}
+
+func (issue *Issue) loadWorkflowState(e Engine) (err error) {
+	if issue.WorkflowState == nil && issue.WorkflowStateID > 0 {
+		issue.WorkflowState, err = getWorkflowStateInRepoByID(e, issue.RepoID, issue.WorkflowStateID)
+		if IsErrWorkflowStateNotExist(err) {
+			issue.WorkflowStateID = 0
+			return nil
+		}
+	}
+	return err
+}
+
+// LoadWorkflowState loads the workflow state of the issue, if any.
+func (issue *Issue) LoadWorkflowState() error {
+	return issue.loadWorkflowState(x)
+}
+
+// ChangeWorkflowState moves the issue to another workflow state, enforcing the
+// allowed transitions of the current state. If the target state maps to a
+// different open/closed status the issue is closed or reopened accordingly and
+// the resulting close/reopen comment is returned.
+// A stateID of 0 removes the workflow state from the issue.
+func (issue *Issue) ChangeWorkflowState(doer *User, stateID int64) (statusComment *Comment, err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if err = issue.loadRepo(sess); err != nil {
+		return nil, err
+	}
+	if err = issue.loadPoster(sess); err != nil {
+		return nil, err
+	}
+
+	oldStateID := issue.WorkflowStateID
+	if oldStateID == stateID {
+		return nil, nil
+	}
+
+	var state *WorkflowState
+	if stateID > 0 {
+		if state, err = getWorkflowStateInRepoByID(sess, issue.RepoID, stateID); err != nil {
+			return nil, err
+		}
+	}
+
+	if oldStateID > 0 && stateID > 0 {
+		oldState, err := getWorkflowStateInRepoByID(sess, issue.RepoID, oldStateID)
+		if err != nil && !IsErrWorkflowStateNotExist(err) {
+			return nil, err
+		}
+		if oldState != nil && !oldState.CanTransitionTo(stateID) {
+			return nil, ErrWorkflowTransitionNotAllowed{FromStateID: oldStateID, ToStateID: stateID}
+		}
+	}
+
+	issue.WorkflowStateID = stateID
+	issue.WorkflowState = state
+	if err = updateIssueCols(sess, issue, "workflow_state_id"); err != nil {
+		return nil, fmt.Errorf("updateIssueCols: %v", err)
+	}
+
+	if _, err = createComment(sess, &CreateCommentOptions{
+		Type:               CommentTypeChangeWorkflowState,
+		Doer:               doer,
+		Repo:               issue.Repo,
+		Issue:              issue,
+		OldWorkflowStateID: oldStateID,
+		WorkflowStateID:    stateID,
+	}); err != nil {
+		return nil, fmt.Errorf("createComment: %v", err)
+	}
+
+	if state != nil && state.IsClosed != issue.IsClosed {
+		issue.IsClosed = state.IsClosed
+		if statusComment, err = issue.doChangeStatus(sess, doer, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return statusComment, sess.Commit()
+}
+
+// syncWorkflowStateWithStatus drops the workflow state of an issue whose
+// open/closed status no longer matches the status its state maps to.
+func (issue *Issue) syncWorkflowStateWithStatus(e Engine) error {
+	if issue.WorkflowStateID == 0 {
+		return nil
+	}
+	issue.WorkflowState = nil
+	if err := issue.loadWorkflowState(e); err != nil {
+		return err
+	}
+	if issue.WorkflowState != nil && issue.WorkflowState.IsClosed == issue.IsClosed {
+		return nil
+	}
+	issue.WorkflowStateID = 0
+	issue.WorkflowState = nil
+	return updateIssueCols(e, issue, "workflow_state_id")
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowState_CanTransitionTo(t *testing.T) {
+	state := &WorkflowState{ID: 1}
+	assert.True(t, state.CanTransitionTo(2))
+
+	state.AllowedTransitions = []int64{2}
+	assert.True(t, state.CanTransitionTo(1))
+	assert.True(t, state.CanTransitionTo(2))
+	assert.False(t, state.CanTransitionTo(3))
+}
+
+func TestNewWorkflowState(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	state := &WorkflowState{
+		RepoID: 1,
+		Name:   " review ",
+		Color:  "#abcdef",
+	}
+	assert.NoError(t, NewWorkflowState(state))
+	state = AssertExistsAndLoadBean(t, &WorkflowState{ID: state.ID}).(*WorkflowState)
+	assert.EqualValues(t, "review", state.Name)
+
+	assert.Error(t, NewWorkflowState(&WorkflowState{RepoID: 1, Name: "bad", Color: "abcdef"}))
+}
+
+func TestGetWorkflowStatesByRepoID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	states, err := GetWorkflowStatesByRepoID(1)
+	assert.NoError(t, err)
+	if assert.Len(t, states, 3) {
+		assert.EqualValues(t, 1, states[0].ID)
+		assert.EqualValues(t, []int64{2}, states[0].AllowedTransitions)
+		assert.EqualValues(t, []int64{1, 3}, states[1].AllowedTransitions)
+		assert.Empty(t, states[2].AllowedTransitions)
+	}
+
+	states, err = GetWorkflowStatesByRepoID(NonexistentID)
+	assert.NoError(t, err)
+	assert.Len(t, states, 0)
+}
+
+func TestGetWorkflowStateInRepoByID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	state, err := GetWorkflowStateInRepoByID(1, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "in-progress", state.Name)
+
+	_, err = GetWorkflowStateInRepoByID(2, 2)
+	assert.True(t, IsErrWorkflowStateNotExist(err))
+}
+
+func TestSetWorkflowStateTransitions(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	state := AssertExistsAndLoadBean(t, &WorkflowState{ID: 3}).(*WorkflowState)
+	assert.NoError(t, SetWorkflowStateTransitions(state, []int64{1, 3}))
+	assert.EqualValues(t, []int64{1}, state.AllowedTransitions)
+	AssertExistsAndLoadBean(t, &WorkflowTransition{FromStateID: 3, ToStateID: 1})
+
+	err := SetWorkflowStateTransitions(state, []int64{NonexistentID})
+	assert.True(t, IsErrWorkflowStateNotExist(err))
+	AssertExistsAndLoadBean(t, &WorkflowTransition{FromStateID: 3, ToStateID: 1})
+}
+
+func TestIssue_ChangeWorkflowState(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	statusComment, err := issue.ChangeWorkflowState(doer, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, statusComment)
+	AssertExistsAndLoadBean(t, &Issue{ID: 1, WorkflowStateID: 1})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: 1, Type: CommentTypeChangeWorkflowState, WorkflowStateID: 1})
+
+	// triage -> done is not an allowed transition
+	_, err = issue.ChangeWorkflowState(doer, 3)
+	assert.True(t, IsErrWorkflowTransitionNotAllowed(err))
+
+	_, err = issue.ChangeWorkflowState(doer, 2)
+	assert.NoError(t, err)
+
+	// moving to a closed state closes the issue
+	statusComment, err = issue.ChangeWorkflowState(doer, 3)
+	assert.NoError(t, err)
+	assert.NotNil(t, statusComment)
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.True(t, issue.IsClosed)
+	assert.EqualValues(t, 3, issue.WorkflowStateID)
+
+	// reopening the issue drops its closed workflow state
+	_, err = issue.ChangeStatus(doer, false)
+	assert.NoError(t, err)
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.False(t, issue.IsClosed)
+	assert.EqualValues(t, 0, issue.WorkflowStateID)
+
+	CheckConsistencyFor(t, &Issue{ID: 1})
+}
+
+func TestDeleteWorkflowState(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	_, err := issue.ChangeWorkflowState(doer, 2)
+	assert.NoError(t, err)
+
+	assert.NoError(t, DeleteWorkflowState(1, 2))
+	AssertNotExistsBean(t, &WorkflowState{ID: 2})
+	AssertNotExistsBean(t, &WorkflowTransition{FromStateID: 2})
+	AssertNotExistsBean(t, &WorkflowTransition{ToStateID: 2})
+	AssertExistsAndLoadBean(t, &Issue{ID: 1}, "workflow_state_id = 0")
+
+	assert.NoError(t, DeleteWorkflowState(1, NonexistentID))
+}
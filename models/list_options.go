@@ -7,6 +7,7 @@ package models
 import (
 	"code.gitea.io/gitea/modules/setting"
 
+	"xorm.io/builder"
 	"xorm.io/xorm"
 )
 
@@ -48,3 +49,30 @@ func (opts ListOptions) setDefaultValues() {
 		opts.Page = 1
 	}
 }
+
+// CursorOptions options to paginate results by keyset: items are ordered by
+// their ID and a page starts right after the item with ID AfterID. Unlike
+// offset pagination, pages stay stable while items are added or removed.
+type CursorOptions struct {
+	AfterID  int64
+	PageSize int
+}
+
+// setSessionCursor limits the session to the page following the cursor,
+// ordered by the given ID column in ascending or descending order.
+func (opts CursorOptions) setSessionCursor(sess *xorm.Session, idColumn string, desc bool) *xorm.Session {
+	pageSize := opts.PageSize
+	if pageSize <= 0 || pageSize > setting.API.MaxResponseItems {
+		pageSize = setting.API.MaxResponseItems
+	}
+
+	if desc {
+		if opts.AfterID > 0 {
+			sess = sess.And(builder.Lt{idColumn: opts.AfterID})
+		}
+		sess = sess.Desc(idColumn)
+	} else {
+		sess = sess.And(builder.Gt{idColumn: opts.AfterID}).Asc(idColumn)
+	}
+	return sess.Limit(pageSize)
+}
@@ -0,0 +1,197 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// LocaleSetting holds the default language and formats of an organization or a repository.
+// They are used for the notification emails and the webhook texts sent to the recipients
+// without a language, and for the dates and numbers shown to the viewers without a
+// language preference. The settings of a repository override the ones of its organization.
+type LocaleSetting struct {
+	ID     int64 `xorm:"pk autoincr"`
+	OrgID  int64 `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	RepoID int64 `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	// Language is one of the [i18n] LANGS setting, like User.Language.
+	Language string `xorm:"VARCHAR(5)"`
+	// DateFormat is an ICU date pattern like "dd.MM.yyyy HH:mm".
+	DateFormat string `xorm:"VARCHAR(100)"`
+	// NumberFormat is an ICU decimal pattern like "#,##0.##".
+	NumberFormat string `xorm:"VARCHAR(50)"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// ErrInvalidLocaleSetting represents a "InvalidLocaleSetting" kind of error.
+type ErrInvalidLocaleSetting struct {
+	Field string
+	Value string
+}
+
+// IsErrInvalidLocaleSetting checks if an error is a ErrInvalidLocaleSetting.
+func IsErrInvalidLocaleSetting(err error) bool {
+	_, ok := err.(ErrInvalidLocaleSetting)
+	return ok
+}
+
+func (err ErrInvalidLocaleSetting) Error() string {
+	return fmt.Sprintf("invalid locale setting [field: %s, value: %s]", err.Field, err.Value)
+}
+
+// IsEmpty returns true if nothing is set.
+func (s *LocaleSetting) IsEmpty() bool {
+	return s.Language == "" && s.DateFormat == "" && s.NumberFormat == ""
+}
+
+// LanguageFor returns the language of the texts sent to a user, it is the language of the
+// user if it has one.
+func (s *LocaleSetting) LanguageFor(u *User) string {
+	if u != nil && u.Language != "" {
+		return u.Language
+	}
+	return s.Language
+}
+
+// GetDateFormat returns the ICU date pattern, it defaults to the one of the language.
+func (s *LocaleSetting) GetDateFormat() string {
+	if s.DateFormat != "" {
+		return s.DateFormat
+	}
+	return timeutil.GetLangDatePattern(s.Language)
+}
+
+func isSupportedLanguage(lang string) bool {
+	for _, l := range setting.Langs {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *LocaleSetting) validate() error {
+	s.Language = strings.TrimSpace(s.Language)
+	s.DateFormat = strings.TrimSpace(s.DateFormat)
+	s.NumberFormat = strings.TrimSpace(s.NumberFormat)
+	if s.Language != "" && !isSupportedLanguage(s.Language) {
+		return ErrInvalidLocaleSetting{"language", s.Language}
+	}
+	if err := timeutil.ValidateDatePattern(s.DateFormat); err != nil {
+		return ErrInvalidLocaleSetting{"date_format", s.DateFormat}
+	}
+	if s.NumberFormat != "" {
+		if err := base.ValidateNumberPattern(s.NumberFormat); err != nil {
+			return ErrInvalidLocaleSetting{"number_format", s.NumberFormat}
+		}
+	}
+	return nil
+}
+
+func getLocaleSetting(e Engine, orgID, repoID int64) (*LocaleSetting, error) {
+	s := new(LocaleSetting)
+	has, err := e.Where("org_id=? AND repo_id=?", orgID, repoID).Get(s)
+	if err != nil || !has {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetOrgLocaleSetting returns the locale setting of an organization, or nil if it has none.
+func GetOrgLocaleSetting(orgID int64) (*LocaleSetting, error) {
+	return getLocaleSetting(x, orgID, 0)
+}
+
+// GetRepoLocaleSetting returns the locale setting of a repository, or nil if it has none.
+func GetRepoLocaleSetting(repoID int64) (*LocaleSetting, error) {
+	return getLocaleSetting(x, 0, repoID)
+}
+
+func getEffectiveLocaleSetting(e Engine, repo *Repository) (*LocaleSetting, error) {
+	effective := &LocaleSetting{RepoID: repo.ID}
+	s, err := getLocaleSetting(e, 0, repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	if s != nil {
+		*effective = *s
+	}
+	if err := repo.getOwner(e); err != nil {
+		return nil, err
+	}
+	if repo.Owner.IsOrganization() {
+		org, err := getLocaleSetting(e, repo.OwnerID, 0)
+		if err != nil {
+			return nil, err
+		}
+		if org != nil {
+			effective.Merge(org)
+		}
+	}
+	return effective, nil
+}
+
+// Merge fills the fields which are not set with the ones of the other setting.
+func (s *LocaleSetting) Merge(other *LocaleSetting) {
+	if s.Language == "" {
+		s.Language = other.Language
+	}
+	if s.DateFormat == "" {
+		s.DateFormat = other.DateFormat
+	}
+	if s.NumberFormat == "" {
+		s.NumberFormat = other.NumberFormat
+	}
+}
+
+// GetEffectiveLocaleSetting returns the locale setting of a repository with the settings of
+// its organization filled in, the fields may still be empty.
+func GetEffectiveLocaleSetting(repo *Repository) (*LocaleSetting, error) {
+	return getEffectiveLocaleSetting(x, repo)
+}
+
+func updateLocaleSetting(orgID, repoID int64, s *LocaleSetting) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	existing, err := getLocaleSetting(x, orgID, repoID)
+	if err != nil {
+		return err
+	}
+	if s.IsEmpty() {
+		if existing != nil {
+			_, err = x.ID(existing.ID).Delete(new(LocaleSetting))
+		}
+		return err
+	}
+
+	s.OrgID, s.RepoID = orgID, repoID
+	if existing == nil {
+		_, err = x.Insert(s)
+		return err
+	}
+	s.ID = existing.ID
+	_, err = x.ID(s.ID).Cols("language", "date_format", "number_format").Update(s)
+	return err
+}
+
+// UpdateOrgLocaleSetting creates or updates the locale setting of an organization, it is
+// removed if nothing is set.
+func UpdateOrgLocaleSetting(orgID int64, s *LocaleSetting) error {
+	return updateLocaleSetting(orgID, 0, s)
+}
+
+// UpdateRepoLocaleSetting creates or updates the locale setting of a repository, it is
+// removed if nothing is set.
+func UpdateRepoLocaleSetting(repoID int64, s *LocaleSetting) error {
+	return updateLocaleSetting(0, repoID, s)
+}
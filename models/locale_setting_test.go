@@ -0,0 +1,67 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEffectiveLocaleSetting(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// the repository setting is merged with the one of its organization
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 38}).(*Repository)
+	s, err := GetEffectiveLocaleSetting(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, "de-DE", s.Language)
+	assert.Equal(t, "dd.MM.yyyy", s.GetDateFormat())
+	assert.Equal(t, "#,##0.00", s.NumberFormat)
+
+	// the date format defaults to the one of the language
+	s.DateFormat = ""
+	assert.Equal(t, "dd.MM.yyyy HH:mm:ss", s.GetDateFormat())
+
+	assert.Equal(t, "de-DE", s.LanguageFor(AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)))
+	assert.Equal(t, "fr-FR", s.LanguageFor(&User{Language: "fr-FR"}))
+
+	// user repositories have no organization setting
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	s, err = GetEffectiveLocaleSetting(repo)
+	assert.NoError(t, err)
+	assert.True(t, s.IsEmpty())
+}
+
+func TestUpdateLocaleSetting(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, UpdateRepoLocaleSetting(1, &LocaleSetting{Language: "fr-FR", DateFormat: " dd/MM/yyyy "}))
+	s, err := GetRepoLocaleSetting(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "fr-FR", s.Language)
+	assert.Equal(t, "dd/MM/yyyy", s.DateFormat)
+
+	assert.NoError(t, UpdateRepoLocaleSetting(1, &LocaleSetting{NumberFormat: "#,##0"}))
+	s, err = GetRepoLocaleSetting(1)
+	assert.NoError(t, err)
+	assert.Empty(t, s.Language)
+	assert.Equal(t, "#,##0", s.NumberFormat)
+
+	for _, invalid := range []*LocaleSetting{
+		{Language: "xx-XX"},
+		{DateFormat: "yyyy 'unterminated"},
+		{NumberFormat: "abc"},
+	} {
+		assert.True(t, IsErrInvalidLocaleSetting(UpdateOrgLocaleSetting(22, invalid)))
+	}
+
+	// an empty setting is removed
+	assert.NoError(t, UpdateOrgLocaleSetting(22, &LocaleSetting{}))
+	s, err = GetOrgLocaleSetting(22)
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+	AssertNotExistsBean(t, &LocaleSetting{ID: 1})
+}
@@ -761,6 +761,11 @@ func UserSignIn(username, password string) (*User, error) {
 	}
 
 	if hasUser {
+		// bots authenticate with their access token only
+		if user.IsBot() {
+			return nil, ErrUserProhibitLogin{user.ID, user.Name}
+		}
+
 		switch user.LoginType {
 		case LoginNoType, LoginPlain, LoginOAuth2:
 			if user.IsPasswordSet() && user.ValidatePassword(password) {
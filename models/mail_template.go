@@ -0,0 +1,182 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MailTemplate is a mail template customized by a site administrator, it replaces the
+// template file of the same name. The customizations are kept in the database so that
+// upgrades do not overwrite them.
+type MailTemplate struct {
+	ID   int64  `xorm:"pk autoincr"`
+	Name string `xorm:"UNIQUE NOT NULL"`
+	// Version is the version in use, 0 means that the default template is used. Older
+	// versions are kept so they can be used again.
+	Version     int                `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// MailTemplateVersion is a saved version of a customized mail template.
+type MailTemplateVersion struct {
+	ID             int64 `xorm:"pk autoincr"`
+	MailTemplateID int64 `xorm:"UNIQUE(s) NOT NULL"`
+	Version        int   `xorm:"UNIQUE(s) NOT NULL"`
+	// Content is the HTML template, with the subject before a "---" line like the files.
+	Content string `xorm:"LONGTEXT"`
+	// TextContent is the template of the plain text alternative, the HTML is converted
+	// when it is empty.
+	TextContent string `xorm:"LONGTEXT"`
+	// BaseSHA256 is the checksum of the default template the version was written from, a
+	// different checksum tells that an upgrade changed the default template since.
+	BaseSHA256  string             `xorm:"VARCHAR(64)"`
+	EditorID    int64              `xorm:"NOT NULL DEFAULT 0"`
+	Editor      *User              `xorm:"-"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// ErrMailTemplateNotExist represents a "MailTemplateNotExist" kind of error.
+type ErrMailTemplateNotExist struct {
+	Name    string
+	Version int
+}
+
+// IsErrMailTemplateNotExist checks if an error is a ErrMailTemplateNotExist.
+func IsErrMailTemplateNotExist(err error) bool {
+	_, ok := err.(ErrMailTemplateNotExist)
+	return ok
+}
+
+func (err ErrMailTemplateNotExist) Error() string {
+	if err.Version > 0 {
+		return fmt.Sprintf("mail template version does not exist [name: %s, version: %d]", err.Name, err.Version)
+	}
+	return fmt.Sprintf("mail template does not exist [name: %s]", err.Name)
+}
+
+// LoadEditor loads the user who saved the version.
+func (v *MailTemplateVersion) LoadEditor() error {
+	if v.Editor != nil {
+		return nil
+	}
+	var err error
+	v.Editor, err = GetUserByID(v.EditorID)
+	if IsErrUserNotExist(err) {
+		v.Editor = NewGhostUser()
+		return nil
+	}
+	return err
+}
+
+// SaveMailTemplate adds a version of the mail template with the name and uses it, the
+// template is created if it was not customized yet.
+func SaveMailTemplate(doer *User, name, content, textContent, baseSHA256 string) (*MailTemplate, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	tmpl := &MailTemplate{Name: name}
+	has, err := sess.Get(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		if _, err := sess.Insert(tmpl); err != nil {
+			return nil, err
+		}
+	}
+
+	latest := new(MailTemplateVersion)
+	if _, err := sess.Where("mail_template_id=?", tmpl.ID).Cols("version").Desc("version").Get(latest); err != nil {
+		return nil, err
+	}
+	version := &MailTemplateVersion{
+		MailTemplateID: tmpl.ID,
+		Version:        latest.Version + 1,
+		Content:        content,
+		TextContent:    textContent,
+		BaseSHA256:     baseSHA256,
+		EditorID:       doer.ID,
+	}
+	if _, err := sess.Insert(version); err != nil {
+		return nil, err
+	}
+	tmpl.Version = version.Version
+	if _, err := sess.ID(tmpl.ID).Cols("version").Update(tmpl); err != nil {
+		return nil, err
+	}
+	return tmpl, sess.Commit()
+}
+
+// GetMailTemplates returns the customized mail templates.
+func GetMailTemplates() ([]*MailTemplate, error) {
+	tmpls := make([]*MailTemplate, 0, 5)
+	return tmpls, x.OrderBy("name").Find(&tmpls)
+}
+
+// GetMailTemplateByName returns the customized mail template with the name.
+func GetMailTemplateByName(name string) (*MailTemplate, error) {
+	tmpl := &MailTemplate{Name: name}
+	if has, err := x.Get(tmpl); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrMailTemplateNotExist{Name: name}
+	}
+	return tmpl, nil
+}
+
+// GetMailTemplateVersions returns the saved versions of a mail template, the latest first.
+func GetMailTemplateVersions(tmplID int64) ([]*MailTemplateVersion, error) {
+	versions := make([]*MailTemplateVersion, 0, 5)
+	return versions, x.Where("mail_template_id=?", tmplID).Omit("content", "text_content").Desc("version").Find(&versions)
+}
+
+// GetMailTemplateVersion returns a version of a mail template.
+func GetMailTemplateVersion(tmpl *MailTemplate, version int) (*MailTemplateVersion, error) {
+	v := &MailTemplateVersion{MailTemplateID: tmpl.ID, Version: version}
+	if has, err := x.Get(v); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrMailTemplateNotExist{Name: tmpl.Name, Version: version}
+	}
+	return v, nil
+}
+
+// SetMailTemplateVersion changes the version of a mail template in use, version 0 uses the
+// default template again.
+func SetMailTemplateVersion(tmpl *MailTemplate, version int) error {
+	if version != 0 {
+		if _, err := GetMailTemplateVersion(tmpl, version); err != nil {
+			return err
+		}
+	}
+	tmpl.Version = version
+	_, err := x.ID(tmpl.ID).Cols("version").Update(tmpl)
+	return err
+}
+
+// GetMailTemplateOverrides returns the versions in use of the customized mail templates by
+// template name.
+func GetMailTemplateOverrides() (map[string]*MailTemplateVersion, error) {
+	tmpls := make([]*MailTemplate, 0, 5)
+	if err := x.Where("version > 0").Find(&tmpls); err != nil {
+		return nil, err
+	}
+	overrides := make(map[string]*MailTemplateVersion, len(tmpls))
+	for _, tmpl := range tmpls {
+		v, err := GetMailTemplateVersion(tmpl, tmpl.Version)
+		if err != nil {
+			return nil, err
+		}
+		overrides[tmpl.Name] = v
+	}
+	return overrides, nil
+}
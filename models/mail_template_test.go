@@ -0,0 +1,58 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveMailTemplate(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+
+	tmpl, err := SaveMailTemplate(doer, "issue/default", "<p>{{.Body}}</p>", "", "sha1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tmpl.Version)
+	tmpl, err = SaveMailTemplate(doer, "issue/default", "<p>{{.Body | Str2html}}</p>", "{{.Body}}", "sha2")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tmpl.Version)
+
+	versions, err := GetMailTemplateVersions(tmpl.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 2) {
+		assert.Equal(t, 2, versions[0].Version)
+		assert.Equal(t, "sha2", versions[0].BaseSHA256)
+		assert.NoError(t, versions[0].LoadEditor())
+		assert.Equal(t, doer.ID, versions[0].Editor.ID)
+	}
+
+	overrides, err := GetMailTemplateOverrides()
+	assert.NoError(t, err)
+	if assert.Contains(t, overrides, "issue/default") {
+		assert.Equal(t, "{{.Body}}", overrides["issue/default"].TextContent)
+	}
+
+	assert.NoError(t, SetMailTemplateVersion(tmpl, 1))
+	overrides, err = GetMailTemplateOverrides()
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>{{.Body}}</p>", overrides["issue/default"].Content)
+	assert.True(t, IsErrMailTemplateNotExist(SetMailTemplateVersion(tmpl, 3)))
+
+	// version 0 uses the default template and keeps the versions
+	assert.NoError(t, SetMailTemplateVersion(tmpl, 0))
+	overrides, err = GetMailTemplateOverrides()
+	assert.NoError(t, err)
+	assert.Empty(t, overrides)
+	tmpl, err = GetMailTemplateByName("issue/default")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, tmpl.Version)
+	_, err = GetMailTemplateVersion(tmpl, 2)
+	assert.NoError(t, err)
+
+	_, err = GetMailTemplateByName("issue/new")
+	assert.True(t, IsErrMailTemplateNotExist(err))
+}
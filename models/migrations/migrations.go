@@ -220,6 +220,110 @@ var migrations = []Migration{
 	NewMigration("Ensure Repository.IsArchived is not null", setIsArchivedToFalse),
 	// v143 -> v144
 	NewMigration("recalculate Stars number for all user", recalculateStars),
+	// v144 -> v145
+	NewMigration("Add issue workflow states and transitions", addWorkflowStates),
+	// v145 -> v146
+	NewMigration("Add epics", addEpics),
+	// v146 -> v147
+	NewMigration("Add issue assign rules", addIssueAssignRules),
+	// v147 -> v148
+	NewMigration("Add custom emoji", addCustomEmoji),
+	// v148 -> v149
+	NewMigration("Add repository discussions", addDiscussions),
+	// v149 -> v150
+	NewMigration("Add accepted answers to question issues", addIssueAnswers),
+	// v150 -> v151
+	NewMigration("Add cluster leases", addClusterLeases),
+	// v151 -> v152
+	NewMigration("Add private repository count to users", addUserNumPrivateRepos),
+	// v152 -> v153
+	NewMigration("Add repository insights", addRepoInsights),
+	// v153 -> v154
+	NewMigration("Add pinned repositories", addPinnedRepo),
+	// v154 -> v155
+	NewMigration("Add user badges", addUserBadges),
+	// v155 -> v156
+	NewMigration("Add organization projects", addOrgProjects),
+	// v156 -> v157
+	NewMigration("Add review assignment to teams and unavailability to users", addTeamReviewAssignment),
+	// v157 -> v158
+	NewMigration("Add out of office status to users", addUserAwayStatus),
+	// v158 -> v159
+	NewMigration("Add expiration to organization memberships", addOrgUserExpiration),
+	// v159 -> v160
+	NewMigration("Add parent team to teams", addTeamParent),
+	// v160 -> v161
+	NewMigration("Add custom roles to organizations", addOrgRoles),
+	// v161 -> v162
+	NewMigration("Add rulesets to organizations", addOrgRulesets),
+	// v162 -> v163
+	NewMigration("Add allowed signing keys to protected branches", addSignedCommitsAllowedKeys),
+	// v163 -> v164
+	NewMigration("Add default repository settings to organizations", addOrgRepoDefaults),
+	// v164 -> v165
+	NewMigration("Add synchronization of organization labels to repositories", addOrgLabelSync),
+	// v165 -> v166
+	NewMigration("Add automation of project boards", addProjectAutomation),
+	// v166 -> v167
+	NewMigration("Add labels added by the labeler to issue labels", addIssueLabelAddedByLabeler),
+	// v167 -> v168
+	NewMigration("Add size of pull requests", addPullRequestSize),
+	// v168 -> v169
+	NewMigration("Add watched paths of repositories", addWatchPath),
+	// v169 -> v170
+	NewMigration("Add commit message policies of repositories", addCommitMessagePolicy),
+	// v170 -> v171
+	NewMigration("Add contributor license agreements", addContributorAgreement),
+	// v171 -> v172
+	NewMigration("Add renamed branch table", addRenamedBranchTable),
+	// v172 -> v173
+	NewMigration("Add star list tables", addStarListTables),
+	// v173 -> v174
+	NewMigration("Add repository trending tables", addRepoTrendingTables),
+	// v174 -> v175
+	NewMigration("Add topic curation and organization topics", addTopicCuration),
+	// v175 -> v176
+	NewMigration("Add scheduled visibility change table", addScheduledVisibilityChangeTable),
+	// v176 -> v177
+	NewMigration("Add pages config table", addPagesConfigTable),
+	// v177 -> v178
+	NewMigration("Add snippet tables", addSnippetTables),
+	// v178 -> v179
+	NewMigration("Add workspace change table", addWorkspaceChangeTable),
+	// v179 -> v180
+	NewMigration("Add git transport statistic table", addGitTransportStatTable),
+	// v180 -> v181
+	NewMigration("Add repo raw headers table", addRepoRawHeadersTable),
+	// v181 -> v182
+	NewMigration("Add theme tables", addThemeTables),
+	// v182 -> v183
+	NewMigration("Add locale setting table", addLocaleSettingTable),
+	// v183 -> v184
+	NewMigration("Add recent item table", addRecentItemTable),
+	// v184 -> v185
+	NewMigration("Add dashboard widgets to user", addUserDashboardWidgets),
+	// v185 -> v186
+	NewMigration("Add reason and saved flag to notification", addNotificationReasonAndSaved),
+	// v186 -> v187
+	NewMigration("Add mail template tables", addMailTemplateTables),
+	// v187 -> v188
+	NewMigration("Add email bounce table", addEmailBounceTable),
+	// v188 -> v189
+	NewMigration("Add attachment policy table and retention notice of attachments", addAttachmentPolicy),
+	// v189 -> v190
+	NewMigration("Add custom tabs of repositories", addRepoCustomTabTable),
+	// v190 -> v191
+	NewMigration("Add issue tracker sync tables", addIssueTrackerSyncTables),
+	// v191 -> v192
+	NewMigration("Add deploy tokens", addDeployTokenTable),
+	// v192 -> v193
+	NewMigration("Add bots of organizations", addOrgBotTable),
+	// v193 -> v194
+	NewMigration("Add auto-link rules", addAutoLinkRuleTable),
+	// v194 -> v195
+	NewMigration("Add co-authored commits to the insights", addCoAuthoredToRepoContributorDay),
+	// v195 -> v196
+	NewMigration("Add allowed merge styles to branch protections and rulesets", addMergeStylesToBranchProtection),
 }
 
 // GetCurrentDBVersion returns the current db version
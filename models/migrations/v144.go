@@ -0,0 +1,52 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addWorkflowStates(x *xorm.Engine) error {
+	type WorkflowState struct {
+		ID          int64 `xorm:"pk autoincr"`
+		RepoID      int64 `xorm:"INDEX"`
+		Name        string
+		Description string
+		Color       string `xorm:"VARCHAR(7)"`
+		IsClosed    bool
+		Sort        int
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type WorkflowTransition struct {
+		ID          int64 `xorm:"pk autoincr"`
+		RepoID      int64 `xorm:"INDEX"`
+		FromStateID int64 `xorm:"UNIQUE(s)"`
+		ToStateID   int64 `xorm:"UNIQUE(s)"`
+	}
+
+	type Issue struct {
+		WorkflowStateID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	type Comment struct {
+		OldWorkflowStateID int64
+		WorkflowStateID    int64
+	}
+
+	if err := x.Sync2(new(WorkflowState), new(WorkflowTransition)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	if err := x.Sync2(new(Issue), new(Comment)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addEpics(x *xorm.Engine) error {
+	type Epic struct {
+		ID              int64 `xorm:"pk autoincr"`
+		OwnerID         int64 `xorm:"INDEX"`
+		RepoID          int64 `xorm:"INDEX"`
+		Name            string
+		Content         string `xorm:"TEXT"`
+		IsClosed        bool
+		NumIssues       int
+		NumClosedIssues int
+		Completeness    int
+
+		CreatedUnix    timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX updated"`
+		ClosedDateUnix timeutil.TimeStamp
+	}
+
+	type Issue struct {
+		EpicID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(Epic), new(Issue)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addIssueAssignRules(x *xorm.Engine) error {
+	type IssueAssignRule struct {
+		ID             int64 `xorm:"pk autoincr"`
+		RepoID         int64 `xorm:"INDEX"`
+		Name           string
+		LabelID        int64
+		PathPatterns   string `xorm:"TEXT"`
+		TeamID         int64
+		LastAssigneeID int64
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(IssueAssignRule)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addCustomEmoji(x *xorm.Engine) error {
+	type CustomEmoji struct {
+		ID          int64  `xorm:"pk autoincr"`
+		Name        string `xorm:"UNIQUE NOT NULL"`
+		FileName    string
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(CustomEmoji)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addDiscussions(x *xorm.Engine) error {
+	type DiscussionCategory struct {
+		ID             int64 `xorm:"pk autoincr"`
+		RepoID         int64 `xorm:"INDEX"`
+		Name           string
+		Description    string
+		IsAnswerable   bool
+		NumDiscussions int
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	type Discussion struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"INDEX UNIQUE(repo_index)"`
+		Index       int64  `xorm:"UNIQUE(repo_index)"`
+		CategoryID  int64  `xorm:"INDEX"`
+		PosterID    int64  `xorm:"INDEX"`
+		Title       string `xorm:"name"`
+		Content     string `xorm:"TEXT"`
+		NumReplies  int
+		NumUpvotes  int
+		AnswerID    int64
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type DiscussionReply struct {
+		ID           int64  `xorm:"pk autoincr"`
+		DiscussionID int64  `xorm:"INDEX"`
+		ParentID     int64  `xorm:"INDEX"`
+		PosterID     int64  `xorm:"INDEX"`
+		Content      string `xorm:"TEXT"`
+		NumUpvotes   int
+		CreatedUnix  timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type DiscussionUpvote struct {
+		ID           int64              `xorm:"pk autoincr"`
+		UserID       int64              `xorm:"UNIQUE(s)"`
+		DiscussionID int64              `xorm:"INDEX UNIQUE(s)"`
+		ReplyID      int64              `xorm:"UNIQUE(s)"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	}
+
+	type Notification struct {
+		DiscussionID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(DiscussionCategory), new(Discussion), new(DiscussionReply), new(DiscussionUpvote), new(Notification)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
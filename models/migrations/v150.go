@@ -0,0 +1,26 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addClusterLeases(x *xorm.Engine) error {
+	type ClusterLease struct {
+		Name        string             `xorm:"pk VARCHAR(255)"`
+		Holder      string             `xorm:"NOT NULL"`
+		ExpiresUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+	}
+
+	if err := x.Sync2(new(ClusterLease)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
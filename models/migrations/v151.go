@@ -0,0 +1,24 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addUserNumPrivateRepos(x *xorm.Engine) error {
+	type User struct {
+		NumPrivateRepos int `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(User)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	_, err := x.Exec("UPDATE `user` SET num_private_repos=(SELECT COUNT(*) FROM `repository` WHERE `repository`.owner_id=`user`.id AND `repository`.is_private=?)", true)
+	return err
+}
@@ -0,0 +1,39 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRepoInsights(x *xorm.Engine) error {
+	type RepoContributorDay struct {
+		ID        int64              `xorm:"pk autoincr"`
+		RepoID    int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Email     string             `xorm:"VARCHAR(255) UNIQUE(s) NOT NULL"`
+		Name      string             `xorm:"VARCHAR(255)"`
+		Day       timeutil.TimeStamp `xorm:"UNIQUE(s) NOT NULL"`
+		Commits   int64              `xorm:"NOT NULL DEFAULT 0"`
+		Additions int64              `xorm:"NOT NULL DEFAULT 0"`
+		Deletions int64              `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type RepoPunchCard struct {
+		ID      int64 `xorm:"pk autoincr"`
+		RepoID  int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Weekday int   `xorm:"UNIQUE(s) NOT NULL"`
+		Hour    int   `xorm:"UNIQUE(s) NOT NULL"`
+		Commits int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(RepoContributorDay), new(RepoPunchCard)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
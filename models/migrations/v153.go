@@ -0,0 +1,25 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addPinnedRepo(x *xorm.Engine) error {
+	type PinnedRepo struct {
+		ID       int64 `xorm:"pk autoincr"`
+		UID      int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		RepoID   int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Position int   `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(PinnedRepo)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
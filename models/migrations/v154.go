@@ -0,0 +1,41 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addUserBadges(x *xorm.Engine) error {
+	type Badge struct {
+		ID          int64  `xorm:"pk autoincr"`
+		Slug        string `xorm:"UNIQUE NOT NULL"`
+		Name        string `xorm:"NOT NULL"`
+		Description string `xorm:"TEXT"`
+		Icon        string `xorm:"VARCHAR(255)"`
+		Type        int    `xorm:"NOT NULL DEFAULT 0"`
+		Threshold   int    `xorm:"NOT NULL DEFAULT 0"`
+		IsBuiltin   bool   `xorm:"NOT NULL DEFAULT false"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type UserBadge struct {
+		ID          int64              `xorm:"pk autoincr"`
+		BadgeID     int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		UserID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(Badge), new(UserBadge)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addOrgProjects(x *xorm.Engine) error {
+	type Project struct {
+		ID          int64 `xorm:"pk autoincr"`
+		OwnerID     int64 `xorm:"INDEX"`
+		CreatorID   int64
+		Title       string
+		Description string `xorm:"TEXT"`
+		IsClosed    bool   `xorm:"INDEX"`
+
+		CreatedUnix    timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX updated"`
+		ClosedDateUnix timeutil.TimeStamp
+	}
+
+	type ProjectBoard struct {
+		ID        int64 `xorm:"pk autoincr"`
+		ProjectID int64 `xorm:"INDEX NOT NULL"`
+		Title     string
+		Sorting   int
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type ProjectIssue struct {
+		ID             int64 `xorm:"pk autoincr"`
+		ProjectID      int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		IssueID        int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		ProjectBoardID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	type ProjectRule struct {
+		ID             int64 `xorm:"pk autoincr"`
+		ProjectID      int64 `xorm:"INDEX NOT NULL"`
+		ProjectBoardID int64 `xorm:"NOT NULL DEFAULT 0"`
+		RepoID         int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+		Label          string
+		Keyword        string
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(Project), new(ProjectBoard), new(ProjectIssue), new(ProjectRule)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
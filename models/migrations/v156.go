@@ -0,0 +1,31 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addTeamReviewAssignment(x *xorm.Engine) error {
+	type Team struct {
+		ReviewAssignAlgorithm  int   `xorm:"NOT NULL DEFAULT 0"`
+		ReviewAssignCount      int   `xorm:"NOT NULL DEFAULT 1"`
+		ReviewAssignLastUserID int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type User struct {
+		IsUnavailable bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(Team)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	if err := x.Sync2(new(User)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addUserAwayStatus(x *xorm.Engine) error {
+	type User struct {
+		AwayFromUnix  timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		AwayUntilUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		AwayMessage   string             `xorm:"NOT NULL DEFAULT ''"`
+		AwayAutoReply bool               `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(User)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
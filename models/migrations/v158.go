@@ -0,0 +1,24 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addOrgUserExpiration(x *xorm.Engine) error {
+	type OrgUser struct {
+		ExpiresUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(OrgUser)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
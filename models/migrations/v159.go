@@ -0,0 +1,22 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addTeamParent(x *xorm.Engine) error {
+	type Team struct {
+		ParentID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(Team)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addOrgRoles(x *xorm.Engine) error {
+	type OrgRole struct {
+		ID          int64  `xorm:"pk autoincr"`
+		OrgID       int64  `xorm:"INDEX UNIQUE(s)"`
+		LowerName   string `xorm:"UNIQUE(s) NOT NULL"`
+		Name        string `xorm:"NOT NULL"`
+		Description string `xorm:"TEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type OrgRolePermission struct {
+		ID         int64 `xorm:"pk autoincr"`
+		RoleID     int64 `xorm:"INDEX UNIQUE(s)"`
+		Type       int   `xorm:"UNIQUE(s)"`
+		AccessMode int   `xorm:"NOT NULL DEFAULT 1"`
+	}
+
+	type Team struct {
+		RoleID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(OrgRole), new(OrgRolePermission), new(Team)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
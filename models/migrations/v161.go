@@ -0,0 +1,46 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addOrgRulesets(x *xorm.Engine) error {
+	type OrgRuleset struct {
+		ID           int64  `xorm:"pk autoincr"`
+		OrgID        int64  `xorm:"INDEX UNIQUE(s)"`
+		LowerName    string `xorm:"UNIQUE(s) NOT NULL"`
+		Name         string `xorm:"NOT NULL"`
+		Priority     int64  `xorm:"NOT NULL DEFAULT 0"`
+		IsActive     bool   `xorm:"NOT NULL DEFAULT true"`
+		Target       int    `xorm:"NOT NULL DEFAULT 0"`
+		Mode         int    `xorm:"NOT NULL DEFAULT 0"`
+		RepoPatterns string `xorm:"TEXT"`
+		RefPatterns  string `xorm:"TEXT"`
+
+		RequiredApprovals    int64    `xorm:"NOT NULL DEFAULT 0"`
+		RequireSignedCommits bool     `xorm:"NOT NULL DEFAULT false"`
+		EnableStatusCheck    bool     `xorm:"NOT NULL DEFAULT false"`
+		StatusCheckContexts  []string `xorm:"JSON TEXT"`
+		RestrictPush         bool     `xorm:"NOT NULL DEFAULT false"`
+		PushWhitelistUserIDs []int64  `xorm:"JSON TEXT"`
+		PushWhitelistTeamIDs []int64  `xorm:"JSON TEXT"`
+		BypassUserIDs        []int64  `xorm:"JSON TEXT"`
+		BypassTeamIDs        []int64  `xorm:"JSON TEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(OrgRuleset)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addOrgRepoDefaults(x *xorm.Engine) error {
+	type OrgRepoDefaults struct {
+		ID    int64 `xorm:"pk autoincr"`
+		OrgID int64 `xorm:"UNIQUE NOT NULL"`
+		Units []int `xorm:"JSON TEXT"`
+
+		ProtectedBranches      string `xorm:"TEXT"`
+		CanPush                bool   `xorm:"NOT NULL DEFAULT false"`
+		RequiredApprovals      int64  `xorm:"NOT NULL DEFAULT 0"`
+		BlockOnRejectedReviews bool   `xorm:"NOT NULL DEFAULT false"`
+		DismissStaleApprovals  bool   `xorm:"NOT NULL DEFAULT false"`
+		RequireSignedCommits   bool   `xorm:"NOT NULL DEFAULT false"`
+
+		LabelTemplate string
+		WebhookURLs   string `xorm:"TEXT"`
+		IssueTemplate string `xorm:"TEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(OrgRepoDefaults)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addOrgLabelSync(x *xorm.Engine) error {
+	type Label struct {
+		SyncToRepos  bool  `xorm:"NOT NULL DEFAULT false"`
+		SyncedFromID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	type Repository struct {
+		DisableOrgLabelSync bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(Label), new(Repository)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addProjectAutomation(x *xorm.Engine) error {
+	type Project struct {
+		InProgressBoardID    int64 `xorm:"NOT NULL DEFAULT 0"`
+		DoneBoardID          int64 `xorm:"NOT NULL DEFAULT 0"`
+		ArchiveDoneAfterDays int   `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type ProjectIssue struct {
+		IsArchived bool `xorm:"INDEX NOT NULL DEFAULT false"`
+		MovedUnix  timeutil.TimeStamp
+	}
+
+	if err := x.Sync2(new(Project), new(ProjectIssue)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	_, err := x.Exec("UPDATE `project_issue` SET moved_unix = created_unix")
+	return err
+}
@@ -0,0 +1,23 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addPullRequestSize(x *xorm.Engine) error {
+	type PullRequest struct {
+		NumChangedFiles int `xorm:"NOT NULL DEFAULT 0"`
+		NumChangedLines int `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(PullRequest)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addWatchPath(x *xorm.Engine) error {
+	type WatchPath struct {
+		ID          int64              `xorm:"pk autoincr"`
+		UserID      int64              `xorm:"UNIQUE(watch_path) NOT NULL"`
+		RepoID      int64              `xorm:"UNIQUE(watch_path) INDEX NOT NULL"`
+		Path        string             `xorm:"UNIQUE(watch_path) VARCHAR(255) NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(WatchPath)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
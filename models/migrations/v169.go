@@ -0,0 +1,33 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addCommitMessagePolicy(x *xorm.Engine) error {
+	type CommitMessagePolicy struct {
+		ID               int64   `xorm:"pk autoincr"`
+		RepoID           int64   `xorm:"UNIQUE NOT NULL"`
+		MaxSubjectLength int     `xorm:"NOT NULL DEFAULT 0"`
+		Patterns         string  `xorm:"TEXT"`
+		RequiredTrailers string  `xorm:"TEXT"`
+		BypassUserIDs    []int64 `xorm:"JSON TEXT"`
+		BypassTeamIDs    []int64 `xorm:"JSON TEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(CommitMessagePolicy)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addContributorAgreement(x *xorm.Engine) error {
+	type ContributorAgreement struct {
+		ID      int64  `xorm:"pk autoincr"`
+		OwnerID int64  `xorm:"UNIQUE(s) NOT NULL"`
+		RepoID  int64  `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		Title   string `xorm:"NOT NULL"`
+		Content string `xorm:"TEXT NOT NULL"`
+		Version int    `xorm:"NOT NULL DEFAULT 1"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type ContributorAgreementSignature struct {
+		ID          int64              `xorm:"pk autoincr"`
+		AgreementID int64              `xorm:"UNIQUE(s) NOT NULL"`
+		UserID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Version     int                `xorm:"UNIQUE(s) NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(ContributorAgreement), new(ContributorAgreementSignature)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addStarListTables(x *xorm.Engine) error {
+	type StarList struct {
+		ID          int64              `xorm:"pk autoincr"`
+		UserID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Name        string             `xorm:"VARCHAR(100) UNIQUE(s) NOT NULL"`
+		Description string             `xorm:"TEXT"`
+		IsPrivate   bool               `xorm:"NOT NULL DEFAULT false"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type StarListRepo struct {
+		ID          int64              `xorm:"pk autoincr"`
+		StarListID  int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		RepoID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(StarList), new(StarListRepo)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRepoTrendingTables(x *xorm.Engine) error {
+	type RepoStatsSnapshot struct {
+		ID       int64              `xorm:"pk autoincr"`
+		RepoID   int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Day      timeutil.TimeStamp `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		NumStars int                `xorm:"NOT NULL DEFAULT 0"`
+		NumForks int                `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type RepoTrending struct {
+		ID          int64              `xorm:"pk autoincr"`
+		RepoID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Period      int                `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Stars       int                `xorm:"NOT NULL DEFAULT 0"`
+		Forks       int                `xorm:"NOT NULL DEFAULT 0"`
+		Activity    int                `xorm:"NOT NULL DEFAULT 0"`
+		Score       int                `xorm:"INDEX NOT NULL DEFAULT 0"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(RepoStatsSnapshot), new(RepoTrending)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
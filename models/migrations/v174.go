@@ -0,0 +1,37 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addTopicCuration(x *xorm.Engine) error {
+	type Topic struct {
+		Description string `xorm:"TEXT"`
+		Logo        string `xorm:"VARCHAR(255)"`
+		IsFeatured  bool   `xorm:"INDEX NOT NULL DEFAULT false"`
+	}
+
+	type OrgTopic struct {
+		ID          int64              `xorm:"pk autoincr"`
+		OrgID       int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		TopicID     int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Description string             `xorm:"TEXT"`
+		Logo        string             `xorm:"VARCHAR(255)"`
+		IsFeatured  bool               `xorm:"NOT NULL DEFAULT false"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(Topic), new(OrgTopic)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addScheduledVisibilityChangeTable(x *xorm.Engine) error {
+	type ScheduledVisibilityChange struct {
+		ID            int64              `xorm:"pk autoincr"`
+		RepoID        int64              `xorm:"UNIQUE NOT NULL"`
+		DoerID        int64              `xorm:"NOT NULL"`
+		IsPrivate     bool               `xorm:"NOT NULL DEFAULT false"`
+		ScheduledUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+		CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(ScheduledVisibilityChange)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
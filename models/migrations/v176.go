@@ -0,0 +1,35 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addPagesConfigTable(x *xorm.Engine) error {
+	type PagesConfig struct {
+		ID                int64  `xorm:"pk autoincr"`
+		RepoID            int64  `xorm:"UNIQUE NOT NULL"`
+		Source            int    `xorm:"NOT NULL DEFAULT 0"`
+		Branch            string `xorm:"VARCHAR(255)"`
+		Dir               string `xorm:"VARCHAR(255)"`
+		ArtifactName      string `xorm:"VARCHAR(255)"`
+		CustomDomain      string `xorm:"VARCHAR(255) INDEX"`
+		DomainVerifyToken string `xorm:"VARCHAR(64)"`
+		IsDomainVerified  bool   `xorm:"NOT NULL DEFAULT false"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(PagesConfig)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
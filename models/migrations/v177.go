@@ -0,0 +1,42 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addSnippetTables(x *xorm.Engine) error {
+	type Snippet struct {
+		ID          int64  `xorm:"pk autoincr"`
+		OwnerID     int64  `xorm:"INDEX"`
+		Title       string `xorm:"name"`
+		Description string `xorm:"TEXT"`
+		Visibility  int    `xorm:"INDEX NOT NULL DEFAULT 0"`
+		NumComments int
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type SnippetComment struct {
+		ID        int64  `xorm:"pk autoincr"`
+		SnippetID int64  `xorm:"INDEX"`
+		PosterID  int64  `xorm:"INDEX"`
+		Content   string `xorm:"TEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(Snippet), new(SnippetComment)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
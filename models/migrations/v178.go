@@ -0,0 +1,34 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addWorkspaceChangeTable(x *xorm.Engine) error {
+	type WorkspaceChange struct {
+		ID           int64  `xorm:"pk autoincr"`
+		UserID       int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		RepoID       int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Branch       string `xorm:"UNIQUE(s) NOT NULL"`
+		TreePath     string `xorm:"UNIQUE(s) NOT NULL"`
+		Content      string `xorm:"LONGTEXT"`
+		IsDeleted    bool   `xorm:"NOT NULL DEFAULT false"`
+		BaseCommitID string `xorm:"VARCHAR(40)"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(WorkspaceChange)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
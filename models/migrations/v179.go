@@ -0,0 +1,32 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addGitTransportStatTable(x *xorm.Engine) error {
+	type GitTransportStat struct {
+		ID            int64              `xorm:"pk autoincr"`
+		Day           timeutil.TimeStamp `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Kind          string             `xorm:"VARCHAR(20) UNIQUE(s) NOT NULL"`
+		Protocol      int                `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		Depth         int                `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		Filter        string             `xorm:"VARCHAR(20) UNIQUE(s) NOT NULL DEFAULT ''"`
+		Requests      int64              `xorm:"NOT NULL DEFAULT 0"`
+		RequestBytes  int64              `xorm:"NOT NULL DEFAULT 0"`
+		ResponseBytes int64              `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(GitTransportStat)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRepoRawHeadersTable(x *xorm.Engine) error {
+	type RepoRawHeaders struct {
+		ID             int64              `xorm:"pk autoincr"`
+		RepoID         int64              `xorm:"UNIQUE NOT NULL"`
+		AllowedOrigins string             `xorm:"TEXT"`
+		CacheMaxAge    int                `xorm:"NOT NULL DEFAULT 0"`
+		RobotsPolicy   string             `xorm:"VARCHAR(20) NOT NULL DEFAULT ''"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix    timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(RepoRawHeaders)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addThemeTables(x *xorm.Engine) error {
+	type Theme struct {
+		ID          int64  `xorm:"pk autoincr"`
+		Name        string `xorm:"UNIQUE NOT NULL"`
+		DisplayName string
+		Description string             `xorm:"TEXT"`
+		Version     int                `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type ThemeVersion struct {
+		ID          int64  `xorm:"pk autoincr"`
+		ThemeID     int64  `xorm:"UNIQUE(s) NOT NULL"`
+		Version     int    `xorm:"UNIQUE(s) NOT NULL"`
+		CSS         string `xorm:"LONGTEXT"`
+		Header      string `xorm:"LONGTEXT"`
+		Footer      string `xorm:"LONGTEXT"`
+		SHA256      string `xorm:"VARCHAR(64)"`
+		Size        int64
+		UploaderID  int64              `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(Theme), new(ThemeVersion)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
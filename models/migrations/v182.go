@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addLocaleSettingTable(x *xorm.Engine) error {
+	type LocaleSetting struct {
+		ID           int64              `xorm:"pk autoincr"`
+		OrgID        int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		RepoID       int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		Language     string             `xorm:"VARCHAR(5)"`
+		DateFormat   string             `xorm:"VARCHAR(100)"`
+		NumberFormat string             `xorm:"VARCHAR(50)"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(LocaleSetting)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
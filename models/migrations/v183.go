@@ -0,0 +1,28 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRecentItemTable(x *xorm.Engine) error {
+	type RecentItem struct {
+		ID       int64              `xorm:"pk autoincr"`
+		UserID   int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		ItemKey  string             `xorm:"UNIQUE(s) VARCHAR(100) NOT NULL"`
+		Count    int                `xorm:"NOT NULL DEFAULT 0"`
+		UsedUnix timeutil.TimeStamp `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(RecentItem)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMailTemplateTables(x *xorm.Engine) error {
+	type MailTemplate struct {
+		ID          int64              `xorm:"pk autoincr"`
+		Name        string             `xorm:"UNIQUE NOT NULL"`
+		Version     int                `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type MailTemplateVersion struct {
+		ID             int64              `xorm:"pk autoincr"`
+		MailTemplateID int64              `xorm:"UNIQUE(s) NOT NULL"`
+		Version        int                `xorm:"UNIQUE(s) NOT NULL"`
+		Content        string             `xorm:"LONGTEXT"`
+		TextContent    string             `xorm:"LONGTEXT"`
+		BaseSHA256     string             `xorm:"VARCHAR(64)"`
+		EditorID       int64              `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(MailTemplate), new(MailTemplateVersion)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
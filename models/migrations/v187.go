@@ -0,0 +1,32 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addEmailBounceTable(x *xorm.Engine) error {
+	type EmailBounce struct {
+		ID           int64              `xorm:"pk autoincr"`
+		Email        string             `xorm:"UNIQUE NOT NULL"`
+		Type         int                `xorm:"NOT NULL DEFAULT 1"`
+		Reason       string             `xorm:"TEXT"`
+		Source       string             `xorm:"VARCHAR(20)"`
+		Count        int                `xorm:"NOT NULL DEFAULT 0"`
+		IsSuppressed bool               `xorm:"INDEX NOT NULL DEFAULT false"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(EmailBounce)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
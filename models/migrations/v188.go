@@ -0,0 +1,35 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addAttachmentPolicy(x *xorm.Engine) error {
+	type AttachmentPolicy struct {
+		ID                   int64              `xorm:"pk autoincr"`
+		OwnerID              int64              `xorm:"UNIQUE NOT NULL"`
+		MaxSize              int64              `xorm:"NOT NULL DEFAULT 0"`
+		MaxSizeByType        string             `xorm:"TEXT"`
+		MaxIssueSize         int64              `xorm:"NOT NULL DEFAULT 0"`
+		ClosedIssueRetention int                `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix          timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix          timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type Attachment struct {
+		RetentionNoticeUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(AttachmentPolicy), new(Attachment)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
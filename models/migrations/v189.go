@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRepoCustomTabTable(x *xorm.Engine) error {
+	type RepoCustomTab struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"INDEX NOT NULL"`
+		Name        string `xorm:"NOT NULL"`
+		URL         string `xorm:"TEXT NOT NULL"`
+		Icon        string
+		Sort        int                `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(RepoCustomTab)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addIssueTrackerSyncTables(x *xorm.Engine) error {
+	type IssueTrackerSync struct {
+		ID                    int64  `xorm:"pk autoincr"`
+		RepoID                int64  `xorm:"UNIQUE NOT NULL"`
+		Type                  string `xorm:"VARCHAR(20) NOT NULL"`
+		URL                   string `xorm:"TEXT NOT NULL"`
+		ProjectKey            string
+		Username              string
+		Token                 string             `xorm:"TEXT"`
+		CommentOnCommits      bool               `xorm:"NOT NULL DEFAULT false"`
+		CommentOnPullRequests bool               `xorm:"NOT NULL DEFAULT false"`
+		RequireReference      bool               `xorm:"NOT NULL DEFAULT false"`
+		VerifyReference       bool               `xorm:"NOT NULL DEFAULT false"`
+		CreatedUnix           timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix           timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type IssueTrackerEvent struct {
+		ID            int64              `xorm:"pk autoincr"`
+		RepoID        int64              `xorm:"UNIQUE(s) NOT NULL"`
+		Key           string             `xorm:"UNIQUE(s) VARCHAR(50) NOT NULL"`
+		Kind          string             `xorm:"UNIQUE(s) VARCHAR(20) NOT NULL"`
+		Ref           string             `xorm:"UNIQUE(s) VARCHAR(64) NOT NULL"`
+		Action        string             `xorm:"UNIQUE(s) VARCHAR(20) NOT NULL"`
+		Link          string             `xorm:"TEXT"`
+		Title         string             `xorm:"TEXT"`
+		Comment       string             `xorm:"TEXT"`
+		IsDelivered   bool               `xorm:"NOT NULL DEFAULT false"`
+		Error         string             `xorm:"TEXT"`
+		CreatedUnix   timeutil.TimeStamp `xorm:"INDEX created"`
+		DeliveredUnix timeutil.TimeStamp
+	}
+
+	if err := x.Sync2(new(IssueTrackerSync), new(IssueTrackerEvent)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
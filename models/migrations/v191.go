@@ -0,0 +1,34 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addDeployTokenTable(x *xorm.Engine) error {
+	type DeployToken struct {
+		ID             int64    `xorm:"pk autoincr"`
+		RepoID         int64    `xorm:"INDEX NOT NULL"`
+		CreatorID      int64    `xorm:"INDEX NOT NULL"`
+		Name           string   `xorm:"NOT NULL"`
+		Scopes         []string `xorm:"JSON TEXT"`
+		TokenHash      string   `xorm:"UNIQUE"`
+		TokenSalt      string
+		TokenLastEight string             `xorm:"INDEX token_last_eight"`
+		ExpiresUnix    timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+		LastUsedUnix   timeutil.TimeStamp
+		CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(DeployToken)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
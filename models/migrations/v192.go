@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addOrgBotTable(x *xorm.Engine) error {
+	type OrgBot struct {
+		ID            int64 `xorm:"pk autoincr"`
+		OrgID         int64 `xorm:"INDEX NOT NULL"`
+		BotID         int64 `xorm:"UNIQUE NOT NULL"`
+		CreatorID     int64 `xorm:"NOT NULL"`
+		MaxAccessMode int   `xorm:"NOT NULL DEFAULT 2"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(OrgBot)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
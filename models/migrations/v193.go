@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addAutoLinkRuleTable(x *xorm.Engine) error {
+	type AutoLinkRule struct {
+		ID          int64              `xorm:"pk autoincr"`
+		OrgID       int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		RepoID      int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		Pattern     string             `xorm:"VARCHAR(255) NOT NULL"`
+		URL         string             `xorm:"TEXT NOT NULL"`
+		Sort        int                `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(AutoLinkRule)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addCoAuthoredToRepoContributorDay(x *xorm.Engine) error {
+	// RepoContributorDay see models/repo_insights.go
+	type RepoContributorDay struct {
+		CoAuthored int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	// RepoIndexerType specifies the repository indexer type
+	type RepoIndexerType int
+
+	// RepoIndexerTypeInsights repository insights indexer
+	const RepoIndexerTypeInsights RepoIndexerType = 2
+
+	// RepoIndexerStatus see models/repo_indexer.go
+	type RepoIndexerStatus struct {
+		IndexerType RepoIndexerType `xorm:"INDEX(s) NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(RepoContributorDay)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	// The insights are recomputed from scratch to credit the co-authors of
+	// the existing commits
+	if _, err := x.Delete(&RepoIndexerStatus{IndexerType: RepoIndexerTypeInsights}); err != nil {
+		return err
+	}
+	return nil
+}
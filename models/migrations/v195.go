@@ -0,0 +1,26 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addMergeStylesToBranchProtection(x *xorm.Engine) error {
+	type ProtectedBranch struct {
+		MergeStyles []string `xorm:"JSON TEXT"`
+	}
+
+	type OrgRuleset struct {
+		MergeStyles []string `xorm:"JSON TEXT"`
+	}
+
+	if err := x.Sync2(new(ProtectedBranch), new(OrgRuleset)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
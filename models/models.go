@@ -46,6 +46,7 @@ type Engine interface {
 	Where(interface{}, ...interface{}) *xorm.Session
 	Asc(colNames ...string) *xorm.Session
 	Limit(limit int, start ...int) *xorm.Session
+	NoAutoTime() *xorm.Session
 	SumInt(bean interface{}, columnName string) (res int64, err error)
 }
 
@@ -74,6 +75,7 @@ func init() {
 		new(Access),
 		new(Upload),
 		new(Watch),
+		new(WatchPath),
 		new(Star),
 		new(Follow),
 		new(Action),
@@ -105,12 +107,42 @@ func init() {
 		new(RepoRedirect),
 		new(ExternalLoginUser),
 		new(ProtectedBranch),
+		new(CommitMessagePolicy),
+		new(RepoRawHeaders),
+		new(Theme),
+		new(ThemeVersion),
+		new(MailTemplate),
+		new(MailTemplateVersion),
+		new(EmailBounce),
+		new(AttachmentPolicy),
+		new(RepoCustomTab),
+		new(IssueTrackerSync),
+		new(IssueTrackerEvent),
+		new(DeployToken),
+		new(OrgBot),
+		new(AutoLinkRule),
+		new(LocaleSetting),
+		new(RecentItem),
+		new(ContributorAgreement),
+		new(ContributorAgreementSignature),
 		new(UserOpenID),
 		new(IssueWatch),
 		new(CommitStatus),
 		new(Stopwatch),
 		new(TrackedTime),
 		new(DeletedBranch),
+		new(RenamedBranch),
+		new(StarList),
+		new(StarListRepo),
+		new(RepoStatsSnapshot),
+		new(RepoTrending),
+		new(OrgTopic),
+		new(ScheduledVisibilityChange),
+		new(PagesConfig),
+		new(Snippet),
+		new(SnippetComment),
+		new(WorkspaceChange),
+		new(GitTransportStat),
 		new(RepoIndexerStatus),
 		new(IssueDependency),
 		new(LFSLock),
@@ -125,6 +157,29 @@ func init() {
 		new(Task),
 		new(LanguageStat),
 		new(EmailHash),
+		new(WorkflowState),
+		new(WorkflowTransition),
+		new(Epic),
+		new(IssueAssignRule),
+		new(CustomEmoji),
+		new(DiscussionCategory),
+		new(Discussion),
+		new(DiscussionReply),
+		new(DiscussionUpvote),
+		new(ClusterLease),
+		new(RepoContributorDay),
+		new(RepoPunchCard),
+		new(PinnedRepo),
+		new(Badge),
+		new(UserBadge),
+		new(Project),
+		new(ProjectBoard),
+		new(ProjectIssue),
+		new(ProjectRule),
+		new(OrgRole),
+		new(OrgRolePermission),
+		new(OrgRuleset),
+		new(OrgRepoDefaults),
 	)
 
 	gonicNames := []string{"SSL", "UID"}
@@ -178,7 +233,10 @@ func SetEngine() (err error) {
 	x.SetMaxOpenConns(setting.Database.MaxOpenConns)
 	x.SetMaxIdleConns(setting.Database.MaxIdleConns)
 	x.SetConnMaxLifetime(setting.Database.ConnMaxLifetime)
-	return nil
+	if setting.Tracing.Enabled {
+		x.AddHook(tracingHook{})
+	}
+	return setReplicaEngines()
 }
 
 // NewEngine initializes a new xorm.Engine
@@ -246,6 +304,26 @@ func GetStatistic() (stats Statistic) {
 	return
 }
 
+// StorageStatistic contains the storage used by the content of the instance in bytes
+type StorageStatistic struct {
+	Repositories int64
+	LFSObjects   int64
+	Attachments  int64
+}
+
+// GetStorageStatistic returns the storage statistics. LFS objects shared
+// by several repositories are counted once.
+func GetStorageStatistic() (stats StorageStatistic, err error) {
+	if stats.Repositories, err = x.SumInt(new(Repository), "size"); err != nil {
+		return stats, err
+	}
+	if stats.Attachments, err = x.SumInt(new(Attachment), "size"); err != nil {
+		return stats, err
+	}
+	_, err = x.SQL("SELECT COALESCE(SUM(size), 0) FROM (SELECT DISTINCT oid, size FROM lfs_meta_object) lfs").Get(&stats.LFSObjects)
+	return stats, err
+}
+
 // Ping tests if database is alive
 func Ping() error {
 	if x != nil {
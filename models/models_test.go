@@ -26,3 +26,26 @@ func TestDumpDatabase(t *testing.T) {
 		assert.NoError(t, DumpDatabase(filepath.Join(dir, dbType+".sql"), dbType))
 	}
 }
+
+func TestGetStorageStatistic(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	_, err := x.ID(1).Cols("size").Update(&Repository{Size: 1024})
+	assert.NoError(t, err)
+	_, err = x.ID(1).Cols("size").Update(&Attachment{Size: 512})
+	assert.NoError(t, err)
+
+	// the same object stored in two repositories is only counted once
+	_, err = NewLFSMetaObject(&LFSMetaObject{Oid: "a1b2c3", Size: 256, RepositoryID: 1})
+	assert.NoError(t, err)
+	_, err = NewLFSMetaObject(&LFSMetaObject{Oid: "a1b2c3", Size: 256, RepositoryID: 2})
+	assert.NoError(t, err)
+	_, err = NewLFSMetaObject(&LFSMetaObject{Oid: "d4e5f6", Size: 128, RepositoryID: 1})
+	assert.NoError(t, err)
+
+	stats, err := GetStorageStatistic()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1024, stats.Repositories)
+	assert.EqualValues(t, 512, stats.Attachments)
+	assert.EqualValues(t, 384, stats.LFSObjects)
+}
@@ -12,6 +12,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
 
 	"xorm.io/builder"
 	"xorm.io/xorm"
@@ -22,6 +23,8 @@ type (
 	NotificationStatus uint8
 	// NotificationSource is the source of the notification (issue, PR, commit, etc)
 	NotificationSource uint8
+	// NotificationReason is why the user got the notification (mention, assign, etc)
+	NotificationReason uint8
 )
 
 const (
@@ -40,8 +43,58 @@ const (
 	NotificationSourcePullRequest
 	// NotificationSourceCommit is a notification of a commit
 	NotificationSourceCommit
+	// NotificationSourceDiscussion is a notification of a discussion
+	NotificationSourceDiscussion
 )
 
+// The reasons are ordered by importance, a reason only replaces a less important
+// one while the notification is unread.
+const (
+	// NotificationReasonWatch is a notification of a watched repository or issue
+	NotificationReasonWatch NotificationReason = iota + 1
+	// NotificationReasonMention is a notification of a mention of the user
+	NotificationReasonMention
+	// NotificationReasonAssign is a notification of an assignment to the user
+	NotificationReasonAssign
+	// NotificationReasonReviewRequest is a notification of a review request to the user
+	NotificationReasonReviewRequest
+	// NotificationReasonAttachmentExpiry is a notification of the upcoming deletion of the
+	// attachments the user uploaded to a closed issue
+	NotificationReasonAttachmentExpiry
+)
+
+// NotificationReasons are all the reasons of the notifications
+var NotificationReasons = []NotificationReason{
+	NotificationReasonWatch,
+	NotificationReasonMention,
+	NotificationReasonAssign,
+	NotificationReasonReviewRequest,
+	NotificationReasonAttachmentExpiry,
+}
+
+var notificationReasonNames = map[NotificationReason]string{
+	NotificationReasonWatch:            "watch",
+	NotificationReasonMention:          "mention",
+	NotificationReasonAssign:           "assign",
+	NotificationReasonReviewRequest:    "review_request",
+	NotificationReasonAttachmentExpiry: "attachment_expiry",
+}
+
+// String returns the name of the reason used by the API and the UI
+func (r NotificationReason) String() string {
+	return notificationReasonNames[r]
+}
+
+// ParseNotificationReason returns the reason of the name, or 0 if there is none
+func ParseNotificationReason(name string) NotificationReason {
+	for reason, n := range notificationReasonNames {
+		if n == name {
+			return reason
+		}
+	}
+	return 0
+}
+
 // Notification represents a notification
 type Notification struct {
 	ID     int64 `xorm:"pk autoincr"`
@@ -50,14 +103,19 @@ type Notification struct {
 
 	Status NotificationStatus `xorm:"SMALLINT INDEX NOT NULL"`
 	Source NotificationSource `xorm:"SMALLINT INDEX NOT NULL"`
+	Reason NotificationReason `xorm:"SMALLINT INDEX NOT NULL DEFAULT 1"`
+	// IsSaved keeps the notification in the saved notifications whatever its status
+	IsSaved bool `xorm:"INDEX NOT NULL DEFAULT false"`
 
-	IssueID   int64  `xorm:"INDEX NOT NULL"`
-	CommitID  string `xorm:"INDEX"`
-	CommentID int64
+	IssueID      int64  `xorm:"INDEX NOT NULL"`
+	CommitID     string `xorm:"INDEX"`
+	CommentID    int64
+	DiscussionID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
 
 	UpdatedBy int64 `xorm:"INDEX NOT NULL"`
 
 	Issue      *Issue      `xorm:"-"`
+	Discussion *Discussion `xorm:"-"`
 	Repository *Repository `xorm:"-"`
 	Comment    *Comment    `xorm:"-"`
 	User       *User       `xorm:"-"`
@@ -73,8 +131,12 @@ type FindNotificationOptions struct {
 	RepoID            int64
 	IssueID           int64
 	Status            []NotificationStatus
+	Reasons           []NotificationReason
+	IsSaved           util.OptionalBool
 	UpdatedAfterUnix  int64
 	UpdatedBeforeUnix int64
+	// Cursor switches to keyset pagination, newest notifications first
+	Cursor *CursorOptions
 }
 
 // ToCond will convert each condition into a xorm-Cond
@@ -92,6 +154,12 @@ func (opts *FindNotificationOptions) ToCond() builder.Cond {
 	if len(opts.Status) > 0 {
 		cond = cond.And(builder.In("notification.status", opts.Status))
 	}
+	if len(opts.Reasons) > 0 {
+		cond = cond.And(builder.In("notification.reason", opts.Reasons))
+	}
+	if !opts.IsSaved.IsNone() {
+		cond = cond.And(builder.Eq{"notification.is_saved": opts.IsSaved.IsTrue()})
+	}
 	if opts.UpdatedAfterUnix != 0 {
 		cond = cond.And(builder.Gte{"notification.updated_unix": opts.UpdatedAfterUnix})
 	}
@@ -111,6 +179,10 @@ func (opts *FindNotificationOptions) ToSession(e Engine) *xorm.Session {
 }
 
 func getNotifications(e Engine, options FindNotificationOptions) (nl NotificationList, err error) {
+	if options.Cursor != nil {
+		err = options.Cursor.setSessionCursor(e.Where(options.ToCond()), "notification.id", true).Find(&nl)
+		return
+	}
 	err = options.ToSession(e).OrderBy("notification.updated_unix DESC").Find(&nl)
 	return
 }
@@ -120,24 +192,39 @@ func GetNotifications(opts FindNotificationOptions) (NotificationList, error) {
 	return getNotifications(x, opts)
 }
 
+// CountNotifications returns the number of notifications that fit to the given options.
+func CountNotifications(opts FindNotificationOptions) (int64, error) {
+	return x.Where(opts.ToCond()).Count(new(Notification))
+}
+
+// GetNotificationRepoIDs returns the repositories the user has notifications about
+func GetNotificationRepoIDs(userID int64) ([]int64, error) {
+	repoIDs := make([]int64, 0, 10)
+	return repoIDs, x.Table("notification").
+		Where("user_id = ? AND repo_id > 0", userID).
+		Distinct("repo_id").
+		Find(&repoIDs)
+}
+
 // CreateOrUpdateIssueNotifications creates an issue notification
 // for each watcher, or updates it if already exists
 // receiverID > 0 just send to reciver, else send to all watcher
-func CreateOrUpdateIssueNotifications(issueID, commentID, notificationAuthorID, receiverID int64) error {
+// the reason is the one of the receiver, the watchers always get NotificationReasonWatch
+func CreateOrUpdateIssueNotifications(issueID, commentID, notificationAuthorID, receiverID int64, reason NotificationReason) error {
 	sess := x.NewSession()
 	defer sess.Close()
 	if err := sess.Begin(); err != nil {
 		return err
 	}
 
-	if err := createOrUpdateIssueNotifications(sess, issueID, commentID, notificationAuthorID, receiverID); err != nil {
+	if err := createOrUpdateIssueNotifications(sess, issueID, commentID, notificationAuthorID, receiverID, reason); err != nil {
 		return err
 	}
 
 	return sess.Commit()
 }
 
-func createOrUpdateIssueNotifications(e Engine, issueID, commentID, notificationAuthorID, receiverID int64) error {
+func createOrUpdateIssueNotifications(e Engine, issueID, commentID, notificationAuthorID, receiverID int64, reason NotificationReason) error {
 	// init
 	var toNotify map[int64]struct{}
 	notifications, err := getNotificationsByIssueID(e, issueID)
@@ -154,7 +241,11 @@ func createOrUpdateIssueNotifications(e Engine, issueID, commentID, notification
 	if receiverID > 0 {
 		toNotify = make(map[int64]struct{}, 1)
 		toNotify[receiverID] = struct{}{}
+		if reason == 0 {
+			reason = NotificationReasonWatch
+		}
 	} else {
+		reason = NotificationReasonWatch
 		toNotify = make(map[int64]struct{}, 32)
 		issueWatches, err := getIssueWatchersIDs(e, issueID, true)
 		if err != nil {
@@ -196,29 +287,164 @@ func createOrUpdateIssueNotifications(e Engine, issueID, commentID, notification
 		return err
 	}
 
-	// notify
+	userIDs := make([]int64, 0, len(toNotify))
 	for userID := range toNotify {
-		issue.Repo.Units = nil
-		if issue.IsPull && !issue.Repo.checkUnitUser(e, userID, false, UnitTypePullRequests) {
+		userIDs = append(userIDs, userID)
+	}
+	perms, err := getReadPermissions(e, issue.Repo, userIDs)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[int64]*Notification, len(notifications))
+	for _, notification := range notifications {
+		existing[notification.UserID] = notification
+	}
+
+	// notify
+	var (
+		readUserIDs      []int64
+		unreadUserIDs    []int64
+		reasonUserIDs    []int64
+		newNotifications []*Notification
+	)
+	for _, userID := range userIDs {
+		if issue.IsPull && !perms[userID].pulls {
 			continue
 		}
-		if !issue.IsPull && !issue.Repo.checkUnitUser(e, userID, false, UnitTypeIssues) {
+		if !issue.IsPull && !perms[userID].issues {
 			continue
 		}
 
-		if notificationExists(notifications, issue.ID, userID) {
-			if err = updateIssueNotification(e, userID, issue.ID, commentID, notificationAuthorID); err != nil {
-				return err
+		if notification, ok := existing[userID]; ok {
+			switch {
+			case notification.Status == NotificationStatusRead:
+				readUserIDs = append(readUserIDs, userID)
+			case reason > notification.Reason:
+				reasonUserIDs = append(reasonUserIDs, userID)
+			default:
+				unreadUserIDs = append(unreadUserIDs, userID)
 			}
 			continue
 		}
-		if err = createIssueNotification(e, userID, issue, commentID, notificationAuthorID); err != nil {
+		newNotifications = append(newNotifications, newIssueNotification(userID, issue, commentID, notificationAuthorID, reason))
+	}
+
+	// NOTICE: Only update comment id when the before notification on this issue is read, otherwise you may miss some old comments.
+	// But we need update updated_by so that the notification will be reorder
+	if err = updateIssueNotifications(e, issue.ID, readUserIDs, &Notification{
+		Status:    NotificationStatusUnread,
+		Reason:    reason,
+		CommentID: commentID,
+		UpdatedBy: notificationAuthorID,
+	}, "status", "reason", "comment_id", "updated_by"); err != nil {
+		return err
+	}
+	if err = updateIssueNotifications(e, issue.ID, unreadUserIDs, &Notification{
+		UpdatedBy: notificationAuthorID,
+	}, "updated_by"); err != nil {
+		return err
+	}
+	if err = updateIssueNotifications(e, issue.ID, reasonUserIDs, &Notification{
+		Reason:    reason,
+		UpdatedBy: notificationAuthorID,
+	}, "reason", "updated_by"); err != nil {
+		return err
+	}
+
+	batchSize := MaxBatchInsertSize(new(Notification))
+	for i := 0; i < len(newNotifications); i += batchSize {
+		end := i + batchSize
+		if end > len(newNotifications) {
+			end = len(newNotifications)
+		}
+		if _, err = e.Insert(newNotifications[i:end]); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// CreateOrUpdateDiscussionNotifications creates a discussion notification
+// for each watcher of the repository and each participant of the discussion,
+// or marks it as unread if it already exists.
+func CreateOrUpdateDiscussionNotifications(discussionID, notificationAuthorID int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	d, err := getDiscussionByID(sess, discussionID)
+	if err != nil {
+		return err
+	}
+	if err = d.loadRepo(sess); err != nil {
+		return err
+	}
+
+	toNotify := make(map[int64]struct{}, 32)
+	repoWatches, err := getRepoWatchersIDs(sess, d.RepoID)
+	if err != nil {
+		return err
+	}
+	for _, id := range repoWatches {
+		toNotify[id] = struct{}{}
+	}
+	participants, err := getDiscussionParticipantIDs(sess, d)
+	if err != nil {
+		return err
+	}
+	for _, id := range participants {
+		toNotify[id] = struct{}{}
+	}
+	// dont notify user who cause notification
+	delete(toNotify, notificationAuthorID)
+
+	for userID := range toNotify {
+		d.Repo.Units = nil
+		if !d.Repo.checkUnitUser(sess, userID, false, UnitTypeDiscussions) {
+			continue
+		}
+
+		notification := new(Notification)
+		has, err := sess.Where("user_id = ? AND discussion_id = ?", userID, d.ID).Get(notification)
+		if err != nil {
+			return err
+		}
+		if has {
+			notification.Status = NotificationStatusUnread
+			notification.UpdatedBy = notificationAuthorID
+			if _, err = sess.ID(notification.ID).Cols("status", "updated_by").Update(notification); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err = sess.Insert(&Notification{
+			UserID:       userID,
+			RepoID:       d.RepoID,
+			Status:       NotificationStatusUnread,
+			Source:       NotificationSourceDiscussion,
+			Reason:       NotificationReasonWatch,
+			DiscussionID: d.ID,
+			UpdatedBy:    notificationAuthorID,
+		}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// SetDiscussionNotificationStatusRead marks the notification of a user about
+// a discussion as read.
+func SetDiscussionNotificationStatusRead(userID, discussionID int64) error {
+	_, err := x.Where("user_id = ? AND discussion_id = ? AND status = ?", userID, discussionID, NotificationStatusUnread).
+		Cols("status").
+		Update(&Notification{Status: NotificationStatusRead})
+	return err
+}
+
 func getNotificationsByIssueID(e Engine, issueID int64) (notifications []*Notification, err error) {
 	err = e.
 		Where("issue_id = ?", issueID).
@@ -226,21 +452,12 @@ func getNotificationsByIssueID(e Engine, issueID int64) (notifications []*Notifi
 	return
 }
 
-func notificationExists(notifications []*Notification, issueID, userID int64) bool {
-	for _, notification := range notifications {
-		if notification.IssueID == issueID && notification.UserID == userID {
-			return true
-		}
-	}
-
-	return false
-}
-
-func createIssueNotification(e Engine, userID int64, issue *Issue, commentID, updatedByID int64) error {
+func newIssueNotification(userID int64, issue *Issue, commentID, updatedByID int64, reason NotificationReason) *Notification {
 	notification := &Notification{
 		UserID:    userID,
 		RepoID:    issue.RepoID,
 		Status:    NotificationStatusUnread,
+		Reason:    reason,
 		IssueID:   issue.ID,
 		CommentID: commentID,
 		UpdatedBy: updatedByID,
@@ -251,31 +468,25 @@ func createIssueNotification(e Engine, userID int64, issue *Issue, commentID, up
 	} else {
 		notification.Source = NotificationSourceIssue
 	}
-
-	_, err := e.Insert(notification)
-	return err
+	return notification
 }
 
-func updateIssueNotification(e Engine, userID, issueID, commentID, updatedByID int64) error {
-	notification, err := getIssueNotification(e, userID, issueID)
-	if err != nil {
-		return err
-	}
-
-	// NOTICE: Only update comment id when the before notification on this issue is read, otherwise you may miss some old comments.
-	// But we need update update_by so that the notification will be reorder
-	var cols []string
-	if notification.Status == NotificationStatusRead {
-		notification.Status = NotificationStatusUnread
-		notification.CommentID = commentID
-		cols = []string{"status", "update_by", "comment_id"}
-	} else {
-		notification.UpdatedBy = updatedByID
-		cols = []string{"update_by"}
+// updateIssueNotifications updates the columns of the notifications of the
+// users about the issue in batches
+func updateIssueNotifications(e Engine, issueID int64, userIDs []int64, notification *Notification, cols ...string) error {
+	for i := 0; i < len(userIDs); i += maxQueryParameters {
+		end := i + maxQueryParameters
+		if end > len(userIDs) {
+			end = len(userIDs)
+		}
+		if _, err := e.Where("issue_id = ?", issueID).
+			In("user_id", userIDs[i:end]).
+			Cols(cols...).
+			Update(notification); err != nil {
+			return err
+		}
 	}
-
-	_, err = e.ID(notification.ID).Cols(cols...).Update(notification)
-	return err
+	return nil
 }
 
 func getIssueNotification(e Engine, userID, issueID int64) (*Notification, error) {
@@ -330,6 +541,8 @@ func (n *Notification) APIFormat() *api.NotificationThread {
 		ID:        n.ID,
 		Unread:    !(n.Status == NotificationStatusRead || n.Status == NotificationStatusPinned),
 		Pinned:    n.Status == NotificationStatusPinned,
+		Reason:    n.Reason.String(),
+		Saved:     n.IsSaved,
 		UpdatedAt: n.UpdatedUnix.AsTime(),
 		URL:       n.APIURL(),
 	}
@@ -367,6 +580,12 @@ func (n *Notification) APIFormat() *api.NotificationThread {
 			Title: n.CommitID,
 		}
 		//unused until now
+	case NotificationSourceDiscussion:
+		result.Subject = &api.NotificationSubject{Type: "Discussion"}
+		if n.Discussion != nil {
+			result.Subject.Title = n.Discussion.Title
+			result.Subject.URL = n.Discussion.APIURL()
+		}
 	}
 
 	return result
@@ -384,6 +603,9 @@ func (n *Notification) loadAttributes(e Engine) (err error) {
 	if err = n.loadIssue(e); err != nil {
 		return
 	}
+	if err = n.loadDiscussion(e); err != nil {
+		return
+	}
 	if err = n.loadUser(e); err != nil {
 		return
 	}
@@ -404,7 +626,7 @@ func (n *Notification) loadRepo(e Engine) (err error) {
 }
 
 func (n *Notification) loadIssue(e Engine) (err error) {
-	if n.Issue == nil {
+	if n.Issue == nil && n.IssueID > 0 {
 		n.Issue, err = getIssueByID(e, n.IssueID)
 		if err != nil {
 			return fmt.Errorf("getIssueByID [%d]: %v", n.IssueID, err)
@@ -414,6 +636,18 @@ func (n *Notification) loadIssue(e Engine) (err error) {
 	return nil
 }
 
+func (n *Notification) loadDiscussion(e Engine) (err error) {
+	if n.Discussion == nil && n.DiscussionID > 0 {
+		n.Discussion, err = getDiscussionByID(e, n.DiscussionID)
+		if err != nil {
+			return fmt.Errorf("getDiscussionByID [%d]: %v", n.DiscussionID, err)
+		}
+		n.Discussion.Repo = n.Repository
+		return n.Discussion.loadRepo(e)
+	}
+	return nil
+}
+
 func (n *Notification) loadComment(e Engine) (err error) {
 	if n.Comment == nil && n.CommentID > 0 {
 		n.Comment, err = getCommentByID(e, n.CommentID)
@@ -446,6 +680,9 @@ func (n *Notification) GetIssue() (*Issue, error) {
 
 // HTMLURL formats a URL-string to the notification
 func (n *Notification) HTMLURL() string {
+	if n.Discussion != nil {
+		return n.Discussion.HTMLURL()
+	}
 	if n.Comment != nil {
 		return n.Comment.HTMLURL()
 	}
@@ -469,6 +706,17 @@ func (nl NotificationList) APIFormat() []*api.NotificationThread {
 	return result
 }
 
+// LastUpdatedUnix returns the most recent update time of the notifications.
+func (nl NotificationList) LastUpdatedUnix() timeutil.TimeStamp {
+	var updated timeutil.TimeStamp
+	for _, n := range nl {
+		if n.UpdatedUnix > updated {
+			updated = n.UpdatedUnix
+		}
+	}
+	return updated
+}
+
 // LoadAttributes load Repo Issue User and Comment if not loaded
 func (nl NotificationList) LoadAttributes() (err error) {
 	for i := 0; i < len(nl); i++ {
@@ -559,7 +807,7 @@ func (nl NotificationList) LoadRepos() (RepositoryList, []int, error) {
 func (nl NotificationList) getPendingIssueIDs() []int64 {
 	var ids = make(map[int64]struct{}, len(nl))
 	for _, notification := range nl {
-		if notification.Issue != nil {
+		if notification.Issue != nil || notification.Source == NotificationSourceDiscussion {
 			continue
 		}
 		if _, ok := ids[notification.IssueID]; !ok {
@@ -609,7 +857,7 @@ func (nl NotificationList) LoadIssues() ([]int, error) {
 	failures := []int{}
 
 	for i, notification := range nl {
-		if notification.Issue == nil {
+		if notification.Issue == nil && notification.Source != NotificationSourceDiscussion {
 			notification.Issue = issues[notification.IssueID]
 			if notification.Issue == nil {
 				log.Error("Notification[%d]: IssueID: %d Not Found", notification.ID, notification.IssueID)
@@ -622,6 +870,25 @@ func (nl NotificationList) LoadIssues() ([]int, error) {
 	return failures, nil
 }
 
+// LoadDiscussions loads the discussions of discussion notifications from database
+func (nl NotificationList) LoadDiscussions() ([]int, error) {
+	failures := []int{}
+	for i, notification := range nl {
+		if notification.Source != NotificationSourceDiscussion {
+			continue
+		}
+		if err := notification.loadDiscussion(x); err != nil {
+			if IsErrDiscussionNotExist(err) {
+				log.Error("Notification[%d]: DiscussionID: %d Not Found", notification.ID, notification.DiscussionID)
+				failures = append(failures, i)
+				continue
+			}
+			return nil, err
+		}
+	}
+	return failures, nil
+}
+
 // Without returns the notification list without the failures
 func (nl NotificationList) Without(failures []int) NotificationList {
 	if len(failures) == 0 {
@@ -767,6 +1034,23 @@ func SetNotificationStatus(notificationID int64, user *User, status Notification
 	return err
 }
 
+// SetNotificationSaved adds the notification to the saved notifications of the user or
+// removes it from them
+func SetNotificationSaved(notificationID int64, user *User, saved bool) error {
+	notification, err := getNotificationByID(x, notificationID)
+	if err != nil {
+		return err
+	}
+
+	if notification.UserID != user.ID {
+		return fmt.Errorf("Can't change notification of another user: %d, %d", notification.UserID, user.ID)
+	}
+
+	notification.IsSaved = saved
+	_, err = x.ID(notificationID).Cols("is_saved").NoAutoTime().Update(notification)
+	return err
+}
+
 // GetNotificationByID return notification by ID
 func GetNotificationByID(notificationID int64) (*Notification, error) {
 	return getNotificationByID(x, notificationID)
@@ -789,6 +1073,17 @@ func getNotificationByID(e Engine, notificationID int64) (*Notification, error)
 	return notification, nil
 }
 
+// UpdateNotificationStatusesByFilter sets the status of all the notifications matching the
+// options, they must be limited to the notifications of the user.
+func UpdateNotificationStatusesByFilter(user *User, opts FindNotificationOptions, status NotificationStatus) error {
+	opts.UserID = user.ID
+	_, err := x.
+		Where(opts.ToCond()).
+		Cols("status", "updated_by", "updated_unix").
+		Update(&Notification{Status: status, UpdatedBy: user.ID})
+	return err
+}
+
 // UpdateNotificationStatuses updates the statuses of all of a user's notifications that are of the currentStatus type to the desiredStatus
 func UpdateNotificationStatuses(user *User, currentStatus NotificationStatus, desiredStatus NotificationStatus) error {
 	n := &Notification{Status: desiredStatus, UpdatedBy: user.ID}
@@ -7,6 +7,8 @@ package models
 import (
 	"testing"
 
+	"code.gitea.io/gitea/modules/util"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,7 +16,7 @@ func TestCreateOrUpdateIssueNotifications(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
 
-	assert.NoError(t, CreateOrUpdateIssueNotifications(issue.ID, 0, 2, 0))
+	assert.NoError(t, CreateOrUpdateIssueNotifications(issue.ID, 0, 2, 0, NotificationReasonWatch))
 
 	// User 9 is inactive, thus notifications for user 1 and 4 are created
 	notf := AssertExistsAndLoadBean(t, &Notification{UserID: 1, IssueID: issue.ID}).(*Notification)
@@ -25,6 +27,56 @@ func TestCreateOrUpdateIssueNotifications(t *testing.T) {
 	assert.Equal(t, NotificationStatusUnread, notf.Status)
 }
 
+func TestCreateOrUpdateIssueNotifications_Update(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	assert.NoError(t, CreateOrUpdateIssueNotifications(issue.ID, 0, 2, 0, NotificationReasonWatch))
+	read := AssertExistsAndLoadBean(t, &Notification{UserID: 1, IssueID: issue.ID}).(*Notification)
+	assert.NoError(t, SetNotificationStatus(read.ID, &User{ID: 1}, NotificationStatusRead))
+
+	// Read notifications become unread with the new comment, unread ones keep
+	// their comment so that older comments are not missed
+	assert.NoError(t, CreateOrUpdateIssueNotifications(issue.ID, 2, 5, 0, NotificationReasonWatch))
+	notf := AssertExistsAndLoadBean(t, &Notification{ID: read.ID}).(*Notification)
+	assert.Equal(t, NotificationStatusUnread, notf.Status)
+	assert.EqualValues(t, 2, notf.CommentID)
+	assert.EqualValues(t, 5, notf.UpdatedBy)
+
+	notf = AssertExistsAndLoadBean(t, &Notification{UserID: 4, IssueID: issue.ID}).(*Notification)
+	assert.Equal(t, NotificationStatusUnread, notf.Status)
+	assert.EqualValues(t, 0, notf.CommentID)
+	assert.EqualValues(t, 5, notf.UpdatedBy)
+	assertCount(t, &Notification{UserID: 4, IssueID: issue.ID}, 1)
+}
+
+func TestCreateOrUpdateIssueNotifications_Reason(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	assert.NoError(t, CreateOrUpdateIssueNotifications(issue.ID, 0, 2, 0, NotificationReasonWatch))
+	notf := AssertExistsAndLoadBean(t, &Notification{UserID: 4, IssueID: issue.ID}).(*Notification)
+	assert.Equal(t, NotificationReasonWatch, notf.Reason)
+
+	// An unread notification only gets a more important reason
+	assert.NoError(t, CreateOrUpdateIssueNotifications(issue.ID, 0, 2, 4, NotificationReasonMention))
+	AssertExistsAndLoadBean(t, &Notification{ID: notf.ID, Reason: NotificationReasonMention})
+	assert.NoError(t, CreateOrUpdateIssueNotifications(issue.ID, 2, 5, 0, NotificationReasonWatch))
+	AssertExistsAndLoadBean(t, &Notification{ID: notf.ID, Reason: NotificationReasonMention})
+
+	// A read notification gets the reason of the new event
+	assert.NoError(t, SetNotificationStatus(notf.ID, &User{ID: 4}, NotificationStatusRead))
+	assert.NoError(t, CreateOrUpdateIssueNotifications(issue.ID, 0, 2, 0, NotificationReasonWatch))
+	AssertExistsAndLoadBean(t, &Notification{ID: notf.ID, Reason: NotificationReasonWatch, Status: NotificationStatusUnread})
+}
+
+func TestParseNotificationReason(t *testing.T) {
+	for _, reason := range NotificationReasons {
+		assert.Equal(t, reason, ParseNotificationReason(reason.String()))
+	}
+	assert.EqualValues(t, 0, ParseNotificationReason("unknown"))
+}
+
 func TestNotificationsForUser(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
@@ -41,6 +93,28 @@ func TestNotificationsForUser(t *testing.T) {
 	}
 }
 
+func TestGetNotificationsByCursor(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	opts := FindNotificationOptions{
+		UserID: 2,
+		Status: []NotificationStatus{NotificationStatusRead, NotificationStatusUnread},
+		Cursor: &CursorOptions{PageSize: 2},
+	}
+	nl, err := GetNotifications(opts)
+	assert.NoError(t, err)
+	if assert.Len(t, nl, 2) {
+		assert.EqualValues(t, 5, nl[0].ID)
+		assert.EqualValues(t, 4, nl[1].ID)
+	}
+
+	opts.Cursor.AfterID = nl[1].ID
+	nl, err = GetNotifications(opts)
+	assert.NoError(t, err)
+	if assert.Len(t, nl, 1) {
+		assert.EqualValues(t, 2, nl[0].ID)
+	}
+}
+
 func TestNotification_GetRepo(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	notf := AssertExistsAndLoadBean(t, &Notification{RepoID: 1}).(*Notification)
@@ -101,3 +175,61 @@ func TestUpdateNotificationStatuses(t *testing.T) {
 	AssertExistsAndLoadBean(t,
 		&Notification{ID: notfPinned.ID, Status: NotificationStatusPinned})
 }
+
+func TestSetNotificationSaved(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	notf := AssertExistsAndLoadBean(t, &Notification{ID: 2}).(*Notification)
+
+	assert.NoError(t, SetNotificationSaved(notf.ID, user, true))
+	saved := AssertExistsAndLoadBean(t, &Notification{ID: notf.ID}).(*Notification)
+	assert.True(t, saved.IsSaved)
+	assert.Equal(t, notf.Status, saved.Status)
+	assert.Equal(t, notf.UpdatedUnix, saved.UpdatedUnix)
+
+	assert.NoError(t, SetNotificationSaved(notf.ID, user, false))
+	saved = AssertExistsAndLoadBean(t, &Notification{ID: notf.ID}).(*Notification)
+	assert.False(t, saved.IsSaved)
+
+	assert.Error(t, SetNotificationSaved(1, user, true))
+	assert.Error(t, SetNotificationSaved(NonexistentID, user, true))
+}
+
+func TestCountNotifications(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	cnt, err := CountNotifications(FindNotificationOptions{UserID: user.ID, RepoID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+
+	cnt, err = CountNotifications(FindNotificationOptions{UserID: user.ID, Reasons: []NotificationReason{NotificationReasonMention}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	assert.NoError(t, SetNotificationSaved(4, user, true))
+	cnt, err = CountNotifications(FindNotificationOptions{UserID: user.ID, IsSaved: util.OptionalBoolTrue})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+}
+
+func TestGetNotificationRepoIDs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repoIDs, err := GetNotificationRepoIDs(2)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{1, 2}, repoIDs)
+}
+
+func TestUpdateNotificationStatusesByFilter(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.NoError(t, UpdateNotificationStatusesByFilter(user, FindNotificationOptions{
+		RepoID: 1,
+		Status: []NotificationStatus{NotificationStatusUnread},
+	}, NotificationStatusRead))
+	AssertExistsAndLoadBean(t, &Notification{ID: 4, Status: NotificationStatusRead})
+	AssertExistsAndLoadBean(t, &Notification{ID: 5, Status: NotificationStatusUnread})
+	AssertExistsAndLoadBean(t, &Notification{ID: 3, Status: NotificationStatusPinned})
+	// the notifications of other users are never updated
+	AssertExistsAndLoadBean(t, &Notification{ID: 1, Status: NotificationStatusUnread})
+}
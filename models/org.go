@@ -6,6 +6,7 @@
 package models
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/unknwon/com"
 	"xorm.io/builder"
@@ -292,10 +294,45 @@ func deleteOrg(e *xorm.Session, u *User) error {
 		&OrgUser{OrgID: u.ID},
 		&TeamUser{OrgID: u.ID},
 		&TeamUnit{OrgID: u.ID},
+		&Epic{OwnerID: u.ID},
+		&LocaleSetting{OrgID: u.ID},
+		&AutoLinkRule{OrgID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	if err := deleteOrgBots(e, u.ID); err != nil {
+		return fmt.Errorf("deleteOrgBots: %v", err)
+	}
+
+	if err := deleteContributorAgreements(e, builder.Eq{"owner_id": u.ID}); err != nil {
+		return fmt.Errorf("deleteContributorAgreements: %v", err)
+	}
+
+	if err := deleteProjects(e, builder.Eq{"owner_id": u.ID}); err != nil {
+		return fmt.Errorf("deleteProjects: %v", err)
+	}
+
+	if err := deleteOrgRoles(e, u.ID); err != nil {
+		return fmt.Errorf("deleteOrgRoles: %v", err)
+	}
+
+	if err := deleteOrgRulesets(e, u.ID); err != nil {
+		return fmt.Errorf("deleteOrgRulesets: %v", err)
+	}
+
+	if err := deleteOrgRepoDefaults(e, u.ID); err != nil {
+		return fmt.Errorf("deleteOrgRepoDefaults: %v", err)
+	}
+
+	if err := deleteAttachmentPolicy(e, u.ID); err != nil {
+		return fmt.Errorf("deleteAttachmentPolicy: %v", err)
+	}
+
+	if err := deleteOrgTopics(e, u.ID); err != nil {
+		return fmt.Errorf("deleteOrgTopics: %v", err)
+	}
+
 	if _, err = e.ID(u.ID).Delete(new(User)); err != nil {
 		return fmt.Errorf("Delete: %v", err)
 	}
@@ -334,6 +371,8 @@ type OrgUser struct {
 	UID      int64 `xorm:"INDEX UNIQUE(s)"`
 	OrgID    int64 `xorm:"INDEX UNIQUE(s)"`
 	IsPublic bool  `xorm:"INDEX"`
+	// ExpiresUnix is the time the membership ends, 0 if it does not expire
+	ExpiresUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
 }
 
 func isOrganizationOwner(e Engine, orgID, uid int64) (bool, error) {
@@ -523,6 +562,21 @@ func getOrgUsersByOrgID(e Engine, opts *FindOrgMembersOpts) ([]*OrgUser, error)
 	return ous, sess.Find(&ous)
 }
 
+// GetOrgUser returns the membership of the user in the organization.
+func GetOrgUser(orgID, uid int64) (*OrgUser, error) {
+	ou := new(OrgUser)
+	has, err := x.
+		Where("uid=?", uid).
+		And("org_id=?", orgID).
+		Get(ou)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrOrgUserNotExist{OrgID: orgID, UID: uid}
+	}
+	return ou, nil
+}
+
 // ChangeOrgUserStatus changes public or private membership status.
 func ChangeOrgUserStatus(orgID, uid int64, public bool) error {
 	ou := new(OrgUser)
@@ -541,6 +595,44 @@ func ChangeOrgUserStatus(orgID, uid int64, public bool) error {
 	return err
 }
 
+// ChangeOrgUserExpiration changes the time the membership ends, 0 to keep
+// the membership until the user is removed.
+func ChangeOrgUserExpiration(orgID, uid int64, expires timeutil.TimeStamp) error {
+	_, err := x.
+		Where("uid=?", uid).
+		And("org_id=?", orgID).
+		Cols("expires_unix").
+		Update(&OrgUser{ExpiresUnix: expires})
+	return err
+}
+
+// RemoveExpiredOrgUsers removes the users whose membership has expired from
+// their organizations.
+func RemoveExpiredOrgUsers(ctx context.Context) error {
+	ous := make([]*OrgUser, 0, 10)
+	if err := x.
+		Where("expires_unix > 0").
+		And("expires_unix < ?", timeutil.TimeStampNow()).
+		Find(&ous); err != nil {
+		return err
+	}
+
+	for _, ou := range ous {
+		select {
+		case <-ctx.Done():
+			return ErrCancelledf("before removing expired member %d of organization %d", ou.UID, ou.OrgID)
+		default:
+		}
+		if err := RemoveOrgUser(ou.OrgID, ou.UID); err != nil {
+			if !IsErrLastOrgOwner(err) {
+				return err
+			}
+			log.Warn("The membership of user %d in organization %d has expired but they are its last owner", ou.UID, ou.OrgID)
+		}
+	}
+	return nil
+}
+
 // AddOrgUser adds new user to given organization.
 func AddOrgUser(orgID, uid int64) error {
 	isAlreadyMember, err := IsOrganizationMember(orgID, uid)
@@ -548,6 +640,14 @@ func AddOrgUser(orgID, uid int64) error {
 		return err
 	}
 
+	u, err := getUserByID(x, uid)
+	if err != nil {
+		return err
+	}
+	if err = checkBotScope(x, u, orgID, false); err != nil {
+		return err
+	}
+
 	sess := x.NewSession()
 	defer sess.Close()
 	if err := sess.Begin(); err != nil {
@@ -633,10 +733,13 @@ func removeOrgUser(sess *xorm.Session, orgID, userID int64) error {
 	}
 
 	if len(repoIDs) > 0 {
-		if _, err = sess.
-			Where("user_id = ?", userID).
-			In("repo_id", repoIDs).
-			Delete(new(Access)); err != nil {
+		if err = invalidateAccessibleRepoIDsOnCommit(sess, []int64{userID}, func(e Engine) error {
+			_, err := e.
+				Where("user_id = ?", userID).
+				In("repo_id", repoIDs).
+				Delete(new(Access))
+			return err
+		}); err != nil {
 			return err
 		}
 	}
@@ -774,6 +877,10 @@ func (org *User) accessibleReposEnv(e Engine, userID int64) (AccessibleReposEnvi
 	if err != nil {
 		return nil, err
 	}
+	// The repositories of the parent teams are accessible too
+	if teamIDs, err = getTeamAncestorIDs(e, teamIDs); err != nil {
+		return nil, err
+	}
 	return &accessibleReposEnv{
 		org:     org,
 		user:    user,
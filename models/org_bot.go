@@ -0,0 +1,339 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// botTokenName is the name of the access token of a bot
+const botTokenName = "bot"
+
+// OrgBot is a bot account owned by an organization. A bot cannot sign in,
+// it authenticates with the single access token the owners of the
+// organization rotate. It is only granted access through the teams of its
+// organization and the collaborations on its repositories, capped to
+// MaxAccessMode.
+type OrgBot struct {
+	ID            int64      `xorm:"pk autoincr"`
+	OrgID         int64      `xorm:"INDEX NOT NULL"`
+	BotID         int64      `xorm:"UNIQUE NOT NULL"`
+	Bot           *User      `xorm:"-"`
+	CreatorID     int64      `xorm:"NOT NULL"`
+	Creator       *User      `xorm:"-"`
+	MaxAccessMode AccessMode `xorm:"NOT NULL DEFAULT 2"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// ErrOrgBotNotExist represents a "OrgBotNotExist" kind of error.
+type ErrOrgBotNotExist struct {
+	OrgID int64
+	Name  string
+}
+
+// IsErrOrgBotNotExist checks if an error is a ErrOrgBotNotExist.
+func IsErrOrgBotNotExist(err error) bool {
+	_, ok := err.(ErrOrgBotNotExist)
+	return ok
+}
+
+func (err ErrOrgBotNotExist) Error() string {
+	return fmt.Sprintf("bot does not exist [org_id: %d, name: %s]", err.OrgID, err.Name)
+}
+
+// ErrBotOutOfScope represents a "BotOutOfScope" kind of error.
+type ErrBotOutOfScope struct {
+	BotID int64
+	OrgID int64
+}
+
+// IsErrBotOutOfScope checks if an error is a ErrBotOutOfScope.
+func IsErrBotOutOfScope(err error) bool {
+	_, ok := err.(ErrBotOutOfScope)
+	return ok
+}
+
+func (err ErrBotOutOfScope) Error() string {
+	return fmt.Sprintf("bot can only be granted access within its organization, except as an owner [bot_id: %d, org_id: %d]", err.BotID, err.OrgID)
+}
+
+// ErrInvalidBotAccessMode represents a "InvalidBotAccessMode" kind of error.
+type ErrInvalidBotAccessMode struct {
+	Mode AccessMode
+}
+
+// IsErrInvalidBotAccessMode checks if an error is a ErrInvalidBotAccessMode.
+func IsErrInvalidBotAccessMode(err error) bool {
+	_, ok := err.(ErrInvalidBotAccessMode)
+	return ok
+}
+
+func (err ErrInvalidBotAccessMode) Error() string {
+	return fmt.Sprintf("maximum access of a bot must be read, write or admin [mode: %s]", err.Mode)
+}
+
+func (b *OrgBot) loadAttributes(e Engine) (err error) {
+	if b.Bot == nil {
+		if b.Bot, err = getUserByID(e, b.BotID); err != nil {
+			return err
+		}
+	}
+	if b.Creator == nil {
+		if b.Creator, err = getUserByID(e, b.CreatorID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return err
+			}
+			b.Creator = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// LoadAttributes loads the bot account and its creator
+func (b *OrgBot) LoadAttributes() error {
+	return b.loadAttributes(x)
+}
+
+func validateBotAccessMode(mode AccessMode) error {
+	if mode < AccessModeRead || mode > AccessModeAdmin {
+		return ErrInvalidBotAccessMode{mode}
+	}
+	return nil
+}
+
+// NewOrgBot creates the bot account b.Bot owned by the organization. The
+// bot is not a member of the organization until it is added to a team.
+func NewOrgBot(b *OrgBot) (err error) {
+	if err = validateBotAccessMode(b.MaxAccessMode); err != nil {
+		return err
+	}
+
+	bot := b.Bot
+	if err = IsUsableUsername(bot.Name); err != nil {
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	isExist, err := isUserExist(sess, 0, bot.Name)
+	if err != nil {
+		return err
+	} else if isExist {
+		return ErrUserAlreadyExist{bot.Name}
+	}
+
+	bot.LowerName = strings.ToLower(bot.Name)
+	if bot.Rands, err = GetUserSalt(); err != nil {
+		return err
+	}
+	if bot.Salt, err = GetUserSalt(); err != nil {
+		return err
+	}
+	bot.Type = UserTypeBot
+	bot.IsActive = true
+	bot.KeepEmailPrivate = true
+	bot.UseCustomAvatar = true
+	bot.MaxRepoCreation = 0
+	bot.AllowCreateOrganization = false
+	bot.EmailNotificationsPreference = EmailNotificationsDisabled
+
+	if _, err = sess.Insert(bot); err != nil {
+		return fmt.Errorf("insert bot: %v", err)
+	}
+	if err = bot.generateRandomAvatar(sess); err != nil {
+		return fmt.Errorf("generate random avatar: %v", err)
+	}
+
+	b.BotID = bot.ID
+	if _, err = sess.Insert(b); err != nil {
+		return fmt.Errorf("insert org bot: %v", err)
+	}
+
+	return sess.Commit()
+}
+
+func getOrgBotByBotID(e Engine, botID int64) (*OrgBot, error) {
+	b := new(OrgBot)
+	has, err := e.Where("bot_id = ?", botID).Get(b)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrOrgBotNotExist{}
+	}
+	return b, nil
+}
+
+// GetOrgBotByBotID returns the organization owning the bot account
+func GetOrgBotByBotID(botID int64) (*OrgBot, error) {
+	return getOrgBotByBotID(x, botID)
+}
+
+// GetOrgBot returns the bot of the organization by its name
+func GetOrgBot(orgID int64, name string) (*OrgBot, error) {
+	bot, err := getUserByName(x, name)
+	if err != nil {
+		if IsErrUserNotExist(err) {
+			return nil, ErrOrgBotNotExist{orgID, name}
+		}
+		return nil, err
+	}
+	b, err := getOrgBotByBotID(x, bot.ID)
+	if err != nil {
+		if IsErrOrgBotNotExist(err) {
+			return nil, ErrOrgBotNotExist{orgID, name}
+		}
+		return nil, err
+	} else if b.OrgID != orgID {
+		return nil, ErrOrgBotNotExist{orgID, name}
+	}
+	b.Bot = bot
+	return b, b.loadAttributes(x)
+}
+
+// GetOrgBots returns the bots of the organization
+func GetOrgBots(orgID int64) ([]*OrgBot, error) {
+	bots := make([]*OrgBot, 0, 5)
+	if err := x.Where("org_id = ?", orgID).Asc("id").Find(&bots); err != nil {
+		return nil, err
+	}
+	for _, b := range bots {
+		if err := b.loadAttributes(x); err != nil {
+			return nil, err
+		}
+	}
+	return bots, nil
+}
+
+// UpdateOrgBot updates the profile and the maximum access of the bot
+func UpdateOrgBot(b *OrgBot) error {
+	if err := validateBotAccessMode(b.MaxAccessMode); err != nil {
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.ID(b.ID).Cols("max_access_mode").Update(b); err != nil {
+		return err
+	}
+	if _, err := sess.ID(b.BotID).Cols("full_name", "description").Update(b.Bot); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// RotateOrgBotToken revokes the access token of the bot and returns a new one
+func RotateOrgBotToken(b *OrgBot) (*AccessToken, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if _, err := sess.Delete(&AccessToken{UID: b.BotID}); err != nil {
+		return nil, err
+	}
+	t := &AccessToken{
+		UID:  b.BotID,
+		Name: botTokenName,
+	}
+	if err := newAccessToken(sess, t); err != nil {
+		return nil, err
+	}
+	return t, sess.Commit()
+}
+
+func deleteOrgBot(e *xorm.Session, b *OrgBot) error {
+	bot, err := getUserByID(e, b.BotID)
+	if err != nil && !IsErrUserNotExist(err) {
+		return err
+	}
+	if bot != nil {
+		if err = deleteUser(e, bot); err != nil {
+			return fmt.Errorf("deleteUser: %v", err)
+		}
+	}
+	_, err = e.ID(b.ID).Delete(new(OrgBot))
+	return err
+}
+
+// DeleteOrgBot removes the bot from its organization and deletes the bot account
+func DeleteOrgBot(b *OrgBot) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := removeOrgUser(sess, b.OrgID, b.BotID); err != nil {
+		return err
+	}
+	if err := deleteOrgBot(sess, b); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// deleteOrgBots deletes the bots of an organization being deleted, the
+// memberships of the organization must have been deleted before.
+func deleteOrgBots(e *xorm.Session, orgID int64) error {
+	bots := make([]*OrgBot, 0, 5)
+	if err := e.Where("org_id = ?", orgID).Find(&bots); err != nil {
+		return err
+	}
+	for _, b := range bots {
+		if err := deleteOrgBot(e, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBotScope returns an error if the user is a bot which is not owned by
+// the organization or if it would become an owner of it.
+func checkBotScope(e Engine, u *User, orgID int64, asOwner bool) error {
+	if !u.IsBot() {
+		return nil
+	}
+	b, err := getOrgBotByBotID(e, u.ID)
+	if err != nil {
+		return err
+	}
+	if b.OrgID != orgID || asOwner {
+		return ErrBotOutOfScope{BotID: u.ID, OrgID: orgID}
+	}
+	return nil
+}
+
+// capBotPermission lowers the permission of a bot to its maximum access
+func capBotPermission(e Engine, u *User, perm *Permission) error {
+	b, err := getOrgBotByBotID(e, u.ID)
+	if err != nil {
+		return err
+	}
+	if perm.AccessMode > b.MaxAccessMode {
+		perm.AccessMode = b.MaxAccessMode
+	}
+	for tp, mode := range perm.UnitsMode {
+		if mode > b.MaxAccessMode {
+			perm.UnitsMode[tp] = b.MaxAccessMode
+		}
+	}
+	return nil
+}
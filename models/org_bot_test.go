@@ -0,0 +1,110 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOrgBot(t *testing.T, name string, mode AccessMode) *OrgBot {
+	b := &OrgBot{OrgID: 3, CreatorID: 2, MaxAccessMode: mode, Bot: &User{Name: name}}
+	assert.NoError(t, NewOrgBot(b))
+	return b
+}
+
+func TestNewOrgBot(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	b := newTestOrgBot(t, "ci-bot", AccessModeWrite)
+	bot := AssertExistsAndLoadBean(t, &User{ID: b.BotID, Type: UserTypeBot}).(*User)
+	assert.True(t, bot.IsBot())
+	assert.True(t, bot.IsActive)
+	assert.EqualValues(t, 0, bot.MaxRepoCreation)
+
+	b, err := GetOrgBot(3, "ci-bot")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, b.Creator.ID)
+
+	_, err = GetOrgBot(6, "ci-bot")
+	assert.True(t, IsErrOrgBotNotExist(err))
+	_, err = GetOrgBot(3, "user2")
+	assert.True(t, IsErrOrgBotNotExist(err))
+
+	assert.True(t, IsErrUserAlreadyExist(NewOrgBot(&OrgBot{OrgID: 3, MaxAccessMode: AccessModeRead, Bot: &User{Name: "user2"}})))
+	assert.True(t, IsErrInvalidBotAccessMode(NewOrgBot(&OrgBot{OrgID: 3, MaxAccessMode: AccessModeOwner, Bot: &User{Name: "owner-bot"}})))
+
+	bots, err := GetOrgBots(3)
+	assert.NoError(t, err)
+	assert.Len(t, bots, 1)
+
+	// bots cannot sign in
+	_, err = UserSignIn("ci-bot", "")
+	assert.True(t, IsErrUserProhibitLogin(err))
+}
+
+func TestOrgBotScope(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	b := newTestOrgBot(t, "ci-bot", AccessModeRead)
+
+	// bots can only join the teams of their organization, except the owners team
+	assert.True(t, IsErrBotOutOfScope(AddTeamMember(AssertExistsAndLoadBean(t, &Team{ID: 1}).(*Team), b.BotID)))
+	assert.True(t, IsErrBotOutOfScope(AddTeamMember(AssertExistsAndLoadBean(t, &Team{ID: 3}).(*Team), b.BotID)))
+	assert.NoError(t, AddTeamMember(AssertExistsAndLoadBean(t, &Team{ID: 2}).(*Team), b.BotID))
+	AssertExistsAndLoadBean(t, &OrgUser{OrgID: 3, UID: b.BotID})
+
+	repo1 := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.True(t, IsErrBotOutOfScope(repo1.AddCollaborator(b.Bot)))
+
+	// team2 has write access to repo3, the bot is capped to read
+	repo3 := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	perm, err := GetUserRepoPermission(repo3, b.Bot)
+	assert.NoError(t, err)
+	assert.True(t, perm.CanRead(UnitTypeCode))
+	assert.False(t, perm.CanWrite(UnitTypeCode))
+	assert.False(t, perm.CanWrite(UnitTypeIssues))
+
+	b.MaxAccessMode = AccessModeWrite
+	assert.NoError(t, UpdateOrgBot(b))
+	perm, err = GetUserRepoPermission(repo3, b.Bot)
+	assert.NoError(t, err)
+	assert.True(t, perm.CanWrite(UnitTypeCode))
+}
+
+func TestRotateOrgBotToken(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	b := newTestOrgBot(t, "ci-bot", AccessModeWrite)
+
+	first, err := RotateOrgBotToken(b)
+	assert.NoError(t, err)
+	second, err := RotateOrgBotToken(b)
+	assert.NoError(t, err)
+
+	_, err = GetAccessTokenBySHA(first.Token)
+	assert.True(t, IsErrAccessTokenNotExist(err))
+	token, err := GetAccessTokenBySHA(second.Token)
+	assert.NoError(t, err)
+	assert.EqualValues(t, b.BotID, token.UID)
+}
+
+func TestDeleteOrgBot(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	b := newTestOrgBot(t, "ci-bot", AccessModeWrite)
+	assert.NoError(t, AddTeamMember(AssertExistsAndLoadBean(t, &Team{ID: 2}).(*Team), b.BotID))
+	_, err := RotateOrgBotToken(b)
+	assert.NoError(t, err)
+
+	assert.NoError(t, DeleteOrgBot(b))
+	AssertNotExistsBean(t, &User{ID: b.BotID})
+	AssertNotExistsBean(t, &OrgBot{ID: b.ID})
+	AssertNotExistsBean(t, &OrgUser{OrgID: 3, UID: b.BotID})
+	AssertNotExistsBean(t, &TeamUser{UID: b.BotID})
+	AssertNotExistsBean(t, &AccessToken{UID: b.BotID})
+	CheckConsistencyFor(t, &User{}, &Team{})
+}
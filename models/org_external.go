@@ -0,0 +1,198 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"xorm.io/builder"
+)
+
+// ExternalCollaborator is a user who is not a member of an organization but
+// collaborates on some of its repositories. They can only access the
+// repositories they have been explicitly granted.
+type ExternalCollaborator struct {
+	*User
+	Repos []*Repository
+}
+
+// ErrNotExternalCollaborator represents a "NotExternalCollaborator" kind of error.
+type ErrNotExternalCollaborator struct {
+	OrgID int64
+	UID   int64
+}
+
+// IsErrNotExternalCollaborator checks if an error is a ErrNotExternalCollaborator.
+func IsErrNotExternalCollaborator(err error) bool {
+	_, ok := err.(ErrNotExternalCollaborator)
+	return ok
+}
+
+func (err ErrNotExternalCollaborator) Error() string {
+	return fmt.Sprintf("user is not an external collaborator of the organization [org_id: %d, uid: %d]", err.OrgID, err.UID)
+}
+
+// ErrOrgOwnerNotConvertible represents a "OrgOwnerNotConvertible" kind of error.
+type ErrOrgOwnerNotConvertible struct {
+	OrgID int64
+	UID   int64
+}
+
+// IsErrOrgOwnerNotConvertible checks if an error is a ErrOrgOwnerNotConvertible.
+func IsErrOrgOwnerNotConvertible(err error) bool {
+	_, ok := err.(ErrOrgOwnerNotConvertible)
+	return ok
+}
+
+func (err ErrOrgOwnerNotConvertible) Error() string {
+	return fmt.Sprintf("owner cannot be converted to an external collaborator [org_id: %d, uid: %d]", err.OrgID, err.UID)
+}
+
+func externalCollaborationsCond(orgID int64) builder.Cond {
+	return builder.In("repo_id", builder.Select("id").From("repository").Where(builder.Eq{"owner_id": orgID})).
+		And(builder.NotIn("user_id", builder.Select("uid").From("org_user").Where(builder.Eq{"org_id": orgID})))
+}
+
+func getExternalCollaborations(e Engine, orgID int64, cond builder.Cond) ([]*Collaboration, error) {
+	collaborations := make([]*Collaboration, 0, 10)
+	return collaborations, e.
+		Where(externalCollaborationsCond(orgID)).
+		And(cond).
+		Find(&collaborations)
+}
+
+// GetOrgExternalCollaborators returns the external collaborators of the
+// organization ordered by name, with the repositories they collaborate on.
+func GetOrgExternalCollaborators(orgID int64) ([]*ExternalCollaborator, error) {
+	collaborations, err := getExternalCollaborations(x, orgID, builder.NewCond())
+	if err != nil {
+		return nil, err
+	}
+	if len(collaborations) == 0 {
+		return nil, nil
+	}
+
+	userIDs := make([]int64, 0, len(collaborations))
+	repoIDs := make([]int64, 0, len(collaborations))
+	for _, c := range collaborations {
+		userIDs = append(userIDs, c.UserID)
+		repoIDs = append(repoIDs, c.RepoID)
+	}
+	users, err := GetUsersByIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := GetRepositoriesMapByIDs(repoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	externals := make(map[int64]*ExternalCollaborator, len(users))
+	for _, u := range users {
+		externals[u.ID] = &ExternalCollaborator{User: u}
+	}
+	for _, c := range collaborations {
+		if ec, ok := externals[c.UserID]; ok && repos[c.RepoID] != nil {
+			ec.Repos = append(ec.Repos, repos[c.RepoID])
+		}
+	}
+
+	result := make([]*ExternalCollaborator, 0, len(externals))
+	for _, ec := range externals {
+		sort.Slice(ec.Repos, func(i, j int) bool {
+			return ec.Repos[i].LowerName < ec.Repos[j].LowerName
+		})
+		result = append(result, ec)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LowerName < result[j].LowerName
+	})
+	return result, nil
+}
+
+// RemoveOrgExternalCollaborator removes the external collaborator from all
+// repositories of the organization.
+func RemoveOrgExternalCollaborator(org *User, uid int64) error {
+	collaborations, err := getExternalCollaborations(x, org.ID, builder.Eq{"user_id": uid})
+	if err != nil {
+		return err
+	} else if len(collaborations) == 0 {
+		return ErrNotExternalCollaborator{OrgID: org.ID, UID: uid}
+	}
+
+	for _, c := range collaborations {
+		repo, err := GetRepositoryByID(c.RepoID)
+		if err != nil {
+			return err
+		}
+		repo.Owner = org
+		if err := repo.DeleteCollaboration(uid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertOrgMemberToExternal removes the user from the organization and makes
+// them an external collaborator of the repositories of their teams, with the
+// access of their teams.
+func ConvertOrgMemberToExternal(org *User, uid int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	teams, err := getUserOrgTeams(sess, org.ID, uid)
+	if err != nil {
+		return err
+	}
+	modes := make(map[int64]AccessMode)
+	for _, t := range teams {
+		if t.IsOwnerTeam() {
+			return ErrOrgOwnerNotConvertible{OrgID: org.ID, UID: uid}
+		}
+		if err := t.getRepositories(sess); err != nil {
+			return err
+		}
+		for _, repo := range t.Repos {
+			if modes[repo.ID] < t.Authorize {
+				modes[repo.ID] = t.Authorize
+			}
+		}
+	}
+
+	if err := removeOrgUser(sess, org.ID, uid); err != nil {
+		return err
+	}
+
+	for repoID, mode := range modes {
+		collaboration := &Collaboration{RepoID: repoID, UserID: uid}
+		has, err := sess.Get(collaboration)
+		if err != nil {
+			return err
+		}
+		if !has {
+			collaboration.Mode = mode
+			if _, err := sess.Insert(collaboration); err != nil {
+				return err
+			}
+		} else if collaboration.Mode < mode {
+			if _, err := sess.ID(collaboration.ID).Cols("mode").Update(&Collaboration{Mode: mode}); err != nil {
+				return err
+			}
+		}
+
+		repo, err := getRepositoryByID(sess, repoID)
+		if err != nil {
+			return err
+		}
+		if err := repo.recalculateUserAccess(sess, uid); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrgExternalCollaborators(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// user2 collaborates on repo3 but is a member of user3
+	externals, err := GetOrgExternalCollaborators(3)
+	assert.NoError(t, err)
+	assert.Len(t, externals, 0)
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	user5 := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	assert.NoError(t, repo.AddCollaborator(user5))
+
+	externals, err = GetOrgExternalCollaborators(3)
+	assert.NoError(t, err)
+	if assert.Len(t, externals, 1) {
+		assert.EqualValues(t, 5, externals[0].ID)
+		if assert.Len(t, externals[0].Repos, 1) {
+			assert.EqualValues(t, 3, externals[0].Repos[0].ID)
+		}
+	}
+
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	assert.NoError(t, RemoveOrgExternalCollaborator(org, 5))
+	AssertNotExistsBean(t, &Collaboration{RepoID: 3, UserID: 5})
+	assert.True(t, IsErrNotExternalCollaborator(RemoveOrgExternalCollaborator(org, 5)))
+
+	// members are not external collaborators
+	assert.True(t, IsErrNotExternalCollaborator(RemoveOrgExternalCollaborator(org, 2)))
+	AssertExistsAndLoadBean(t, &Collaboration{RepoID: 3, UserID: 2})
+}
+
+func TestConvertOrgMemberToExternal(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	assert.True(t, IsErrOrgOwnerNotConvertible(ConvertOrgMemberToExternal(org, 2)))
+
+	// user4 is a member of team1, which has write access to repo3
+	assert.NoError(t, ConvertOrgMemberToExternal(org, 4))
+	AssertNotExistsBean(t, &OrgUser{OrgID: 3, UID: 4})
+	AssertNotExistsBean(t, &TeamUser{TeamID: 2, UID: 4})
+	AssertExistsAndLoadBean(t, &Collaboration{RepoID: 3, UserID: 4, Mode: AccessModeWrite})
+	AssertExistsAndLoadBean(t, &Access{RepoID: 3, UserID: 4, Mode: AccessModeWrite})
+
+	externals, err := GetOrgExternalCollaborators(3)
+	assert.NoError(t, err)
+	if assert.Len(t, externals, 1) {
+		assert.EqualValues(t, 4, externals[0].ID)
+	}
+	CheckConsistencyFor(t, &User{}, &Team{})
+}
+
+func TestRemoveExpiredOrgUsers(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, ChangeOrgUserExpiration(3, 4, 1))
+	assert.NoError(t, ChangeOrgUserExpiration(3, 28, 9999999999))
+	ou, err := GetOrgUser(3, 4)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, ou.ExpiresUnix)
+
+	assert.NoError(t, RemoveExpiredOrgUsers(context.Background()))
+	AssertNotExistsBean(t, &OrgUser{OrgID: 3, UID: 4})
+	AssertExistsAndLoadBean(t, &OrgUser{OrgID: 3, UID: 28})
+
+	_, err = GetOrgUser(3, 4)
+	assert.True(t, IsErrOrgUserNotExist(err))
+	CheckConsistencyFor(t, &User{}, &Team{})
+}
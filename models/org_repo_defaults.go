@@ -0,0 +1,319 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// OrgRepoDefaults are the settings an organization applies to the repositories
+// created in or transferred to it.
+type OrgRepoDefaults struct {
+	ID    int64      `xorm:"pk autoincr"`
+	OrgID int64      `xorm:"UNIQUE NOT NULL"`
+	Units []UnitType `xorm:"JSON TEXT"` // empty keeps the units of the repository
+
+	ProtectedBranches      string `xorm:"TEXT"` // semicolon separated, "" disables the protection
+	CanPush                bool   `xorm:"NOT NULL DEFAULT false"`
+	RequiredApprovals      int64  `xorm:"NOT NULL DEFAULT 0"`
+	BlockOnRejectedReviews bool   `xorm:"NOT NULL DEFAULT false"`
+	DismissStaleApprovals  bool   `xorm:"NOT NULL DEFAULT false"`
+	RequireSignedCommits   bool   `xorm:"NOT NULL DEFAULT false"`
+
+	LabelTemplate string
+	WebhookURLs   string `xorm:"TEXT"` // one URL per line
+	IssueTemplate string `xorm:"TEXT"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// HasUnit returns if the unit is enabled by the defaults.
+func (d *OrgRepoDefaults) HasUnit(tp UnitType) bool {
+	for _, unit := range d.Units {
+		if unit == tp {
+			return true
+		}
+	}
+	return false
+}
+
+// GetProtectedBranches returns the names of the branches to protect.
+func (d *OrgRepoDefaults) GetProtectedBranches() []string {
+	names := make([]string, 0, 2)
+	for _, name := range strings.Split(d.ProtectedBranches, ";") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// GetWebhookURLs returns the URLs of the webhooks to add.
+func (d *OrgRepoDefaults) GetWebhookURLs() []string {
+	urls := make([]string, 0, 2)
+	for _, url := range strings.Split(d.WebhookURLs, "\n") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// GetOrgRepoDefaults returns the default repository settings of the organization,
+// they are empty if the organization did not define any.
+func GetOrgRepoDefaults(orgID int64) (*OrgRepoDefaults, error) {
+	d := &OrgRepoDefaults{OrgID: orgID}
+	if _, err := x.Where("org_id = ?", orgID).Get(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// SaveOrgRepoDefaults creates or updates the default repository settings of the organization.
+func SaveOrgRepoDefaults(d *OrgRepoDefaults) error {
+	if d.LabelTemplate != "" {
+		if _, ok := LabelTemplates[d.LabelTemplate]; !ok {
+			return ErrIssueLabelTemplateLoad{d.LabelTemplate, fmt.Errorf("unknown label template")}
+		}
+	}
+	if d.RequiredApprovals < 0 {
+		d.RequiredApprovals = 0
+	}
+
+	units := d.Units[:0]
+	for _, tp := range d.Units {
+		if _, ok := Units[tp]; ok && !tp.UnitGlobalDisabled() {
+			units = append(units, tp)
+		}
+	}
+	d.Units = units
+
+	if d.ID == 0 {
+		_, err := x.Insert(d)
+		return err
+	}
+	_, err := x.ID(d.ID).AllCols().Update(d)
+	return err
+}
+
+func deleteOrgRepoDefaults(e Engine, orgID int64) error {
+	_, err := e.Where("org_id = ?", orgID).Delete(new(OrgRepoDefaults))
+	return err
+}
+
+// newDefaultRepoUnit returns the unit of the repository with its default configuration.
+func newDefaultRepoUnit(repoID int64, tp UnitType) RepoUnit {
+	unit := RepoUnit{
+		RepoID: repoID,
+		Type:   tp,
+	}
+	switch tp {
+	case UnitTypeIssues:
+		unit.Config = &IssuesConfig{
+			EnableTimetracker:                setting.Service.DefaultEnableTimetracking,
+			AllowOnlyContributorsToTrackTime: setting.Service.DefaultAllowOnlyContributorsToTrackTime,
+			EnableDependencies:               setting.Service.DefaultEnableDependencies,
+		}
+	case UnitTypePullRequests:
+		unit.Config = &PullRequestsConfig{AllowMerge: true, AllowRebase: true, AllowRebaseMerge: true, AllowSquash: true}
+	}
+	return unit
+}
+
+// applyUnits enables the units of the defaults and disables the other ones,
+// the configuration of the units already enabled is kept.
+func (d *OrgRepoDefaults) applyUnits(repo *Repository) error {
+	if len(d.Units) == 0 {
+		return nil
+	}
+	if err := repo.getUnits(x); err != nil {
+		return err
+	}
+
+	units := make([]RepoUnit, 0, len(d.Units))
+	deleteUnitTypes := make([]UnitType, 0, len(repo.Units))
+	for _, tp := range d.Units {
+		if !repo.UnitEnabled(tp) {
+			units = append(units, newDefaultRepoUnit(repo.ID, tp))
+		}
+	}
+	for _, repoUnit := range repo.Units {
+		if unit := repoUnit.Unit(); !d.HasUnit(unit.Type) && unit.CanDisable() {
+			deleteUnitTypes = append(deleteUnitTypes, unit.Type)
+		}
+	}
+	if len(units) == 0 && len(deleteUnitTypes) == 0 {
+		return nil
+	}
+	if err := UpdateRepositoryUnits(repo, units, deleteUnitTypes); err != nil {
+		return err
+	}
+	repo.Units = nil
+	return nil
+}
+
+// applyBranchProtections protects the branches which are not protected yet.
+func (d *OrgRepoDefaults) applyBranchProtections(repo *Repository) error {
+	for _, name := range d.GetProtectedBranches() {
+		protectBranch, err := GetProtectedBranchBy(repo.ID, name)
+		if err != nil {
+			return err
+		} else if protectBranch != nil {
+			continue
+		}
+
+		if err = UpdateProtectBranch(repo, &ProtectedBranch{
+			RepoID:                 repo.ID,
+			BranchName:             name,
+			CanPush:                d.CanPush,
+			RequiredApprovals:      d.RequiredApprovals,
+			BlockOnRejectedReviews: d.BlockOnRejectedReviews,
+			DismissStaleApprovals:  d.DismissStaleApprovals,
+			RequireSignedCommits:   d.RequireSignedCommits,
+		}, WhitelistOptions{}); err != nil {
+			return fmt.Errorf("UpdateProtectBranch: %v", err)
+		}
+	}
+	return nil
+}
+
+// applyLabels adds the labels of the template which the repository does not have.
+func (d *OrgRepoDefaults) applyLabels(repo *Repository) error {
+	if d.LabelTemplate == "" {
+		return nil
+	}
+	list, err := GetLabelTemplateFile(d.LabelTemplate)
+	if err != nil {
+		return ErrIssueLabelTemplateLoad{d.LabelTemplate, err}
+	}
+	existing, err := GetLabelsByRepoID(repo.ID, "", ListOptions{})
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(existing))
+	for _, label := range existing {
+		names = append(names, strings.ToLower(label.Name))
+	}
+
+	labels := make([]*Label, 0, len(list))
+	for _, l := range list {
+		if util.IsStringInSlice(strings.ToLower(l[0]), names) {
+			continue
+		}
+		labels = append(labels, &Label{
+			RepoID:      repo.ID,
+			Name:        l[0],
+			Color:       l[1],
+			Description: l[2],
+		})
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	return NewLabels(labels...)
+}
+
+// applyWebhooks adds the webhooks whose URL the repository does not have.
+func (d *OrgRepoDefaults) applyWebhooks(repo *Repository) error {
+	urls := d.GetWebhookURLs()
+	if len(urls) == 0 {
+		return nil
+	}
+	existing, err := GetWebhooksByRepoID(repo.ID, ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, url := range urls {
+		found := false
+		for _, w := range existing {
+			if w.URL == url {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		w := &Webhook{
+			RepoID:       repo.ID,
+			URL:          url,
+			HTTPMethod:   "POST",
+			ContentType:  ContentTypeJSON,
+			HookEvent:    &HookEvent{PushOnly: true},
+			IsActive:     true,
+			HookTaskType: GITEA,
+		}
+		if err = w.UpdateEvent(); err != nil {
+			return err
+		}
+		if err = CreateWebhook(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyToRepo applies the defaults to the repository of the organization.
+func (d *OrgRepoDefaults) ApplyToRepo(repo *Repository) error {
+	if err := d.applyUnits(repo); err != nil {
+		return fmt.Errorf("applyUnits: %v", err)
+	}
+	if err := d.applyBranchProtections(repo); err != nil {
+		return fmt.Errorf("applyBranchProtections: %v", err)
+	}
+	if err := d.applyLabels(repo); err != nil {
+		return fmt.Errorf("applyLabels: %v", err)
+	}
+	if err := d.applyWebhooks(repo); err != nil {
+		return fmt.Errorf("applyWebhooks: %v", err)
+	}
+	return nil
+}
+
+// ApplyOrgRepoDefaults applies the default repository settings of the owner
+// of the repository if it is an organization which defined them.
+func ApplyOrgRepoDefaults(repo *Repository) error {
+	d, err := GetOrgRepoDefaults(repo.OwnerID)
+	if err != nil {
+		return err
+	} else if d.ID == 0 {
+		return nil
+	}
+	return d.ApplyToRepo(repo)
+}
+
+// ApplyOrgRepoDefaultsToAllRepos applies the default repository settings of
+// the organization to all its repositories, it returns the number of
+// repositories they were applied to.
+func ApplyOrgRepoDefaultsToAllRepos(orgID int64) (int, error) {
+	d, err := GetOrgRepoDefaults(orgID)
+	if err != nil {
+		return 0, err
+	} else if d.ID == 0 {
+		return 0, nil
+	}
+
+	repos := make([]*Repository, 0, 10)
+	if err = x.Where("owner_id = ?", orgID).Find(&repos); err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, repo := range repos {
+		if err := d.ApplyToRepo(repo); err != nil {
+			log.Error("ApplyToRepo[%-v]: %v", repo, err)
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyOrgRepoDefaults(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+
+	// no defaults defined
+	assert.NoError(t, ApplyOrgRepoDefaults(repo))
+
+	defaults, err := GetOrgRepoDefaults(3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, defaults.ID)
+
+	defaults.Units = []UnitType{UnitTypeCode, UnitTypeIssues, UnitTypeReleases}
+	defaults.ProtectedBranches = "master; develop"
+	defaults.RequiredApprovals = 2
+	defaults.WebhookURLs = "https://example.com/hook\n"
+	assert.NoError(t, SaveOrgRepoDefaults(defaults))
+	assert.NotZero(t, defaults.ID)
+
+	defaults.LabelTemplate = "unknown"
+	assert.True(t, IsErrIssueLabelTemplateLoad(SaveOrgRepoDefaults(defaults)))
+
+	assert.NoError(t, ApplyOrgRepoDefaults(repo))
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	assert.NoError(t, repo.getUnits(x))
+	assert.True(t, repo.UnitEnabled(UnitTypeIssues))
+	assert.False(t, repo.UnitEnabled(UnitTypePullRequests))
+
+	protectBranch, err := GetProtectedBranchBy(repo.ID, "develop")
+	assert.NoError(t, err)
+	if assert.NotNil(t, protectBranch) {
+		assert.EqualValues(t, 2, protectBranch.RequiredApprovals)
+	}
+	AssertExistsAndLoadBean(t, &Webhook{RepoID: repo.ID, URL: "https://example.com/hook"})
+
+	// applying again does not duplicate the webhooks
+	count, err := ApplyOrgRepoDefaultsToAllRepos(3)
+	assert.NoError(t, err)
+	assert.True(t, count > 0)
+	hooks, err := GetWebhooksByRepoID(repo.ID, ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, hooks, 2)
+}
@@ -0,0 +1,446 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// OrgRole is a custom role of an organization. It grants a permission on
+// each repository unit to the members of the teams it is assigned to, so
+// that e.g. a triage role can manage issues and pull requests without
+// being able to push code.
+type OrgRole struct {
+	ID          int64                `xorm:"pk autoincr"`
+	OrgID       int64                `xorm:"INDEX UNIQUE(s)"`
+	LowerName   string               `xorm:"UNIQUE(s) NOT NULL"`
+	Name        string               `xorm:"NOT NULL"`
+	Description string               `xorm:"TEXT"`
+	Permissions []*OrgRolePermission `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// OrgRolePermission is the permission a custom role grants on a repository unit.
+type OrgRolePermission struct {
+	ID         int64      `xorm:"pk autoincr"`
+	RoleID     int64      `xorm:"INDEX UNIQUE(s)"`
+	Type       UnitType   `xorm:"UNIQUE(s)"`
+	AccessMode AccessMode `xorm:"NOT NULL DEFAULT 1"`
+}
+
+// OrgRolePreset is a set of permissions a custom role can start from.
+type OrgRolePreset struct {
+	Name        string
+	Permissions map[UnitType]AccessMode
+}
+
+// OrgRolePresets are the permissions of common custom roles.
+var OrgRolePresets = []OrgRolePreset{
+	{"triage", map[UnitType]AccessMode{
+		UnitTypeCode:         AccessModeRead,
+		UnitTypeIssues:       AccessModeWrite,
+		UnitTypePullRequests: AccessModeWrite,
+		UnitTypeReleases:     AccessModeRead,
+		UnitTypeWiki:         AccessModeRead,
+	}},
+	{"issue_manager", map[UnitType]AccessMode{
+		UnitTypeCode:   AccessModeRead,
+		UnitTypeIssues: AccessModeWrite,
+	}},
+	{"wiki_maintainer", map[UnitType]AccessMode{
+		UnitTypeCode: AccessModeRead,
+		UnitTypeWiki: AccessModeWrite,
+	}},
+}
+
+// ErrOrgRoleNotExist represents a "OrgRoleNotExist" kind of error.
+type ErrOrgRoleNotExist struct {
+	ID int64
+}
+
+// IsErrOrgRoleNotExist checks if an error is a ErrOrgRoleNotExist.
+func IsErrOrgRoleNotExist(err error) bool {
+	_, ok := err.(ErrOrgRoleNotExist)
+	return ok
+}
+
+func (err ErrOrgRoleNotExist) Error() string {
+	return fmt.Sprintf("role does not exist [id: %d]", err.ID)
+}
+
+// ErrOrgRoleAlreadyExist represents a "OrgRoleAlreadyExist" kind of error.
+type ErrOrgRoleAlreadyExist struct {
+	OrgID int64
+	Name  string
+}
+
+// IsErrOrgRoleAlreadyExist checks if an error is a ErrOrgRoleAlreadyExist.
+func IsErrOrgRoleAlreadyExist(err error) bool {
+	_, ok := err.(ErrOrgRoleAlreadyExist)
+	return ok
+}
+
+func (err ErrOrgRoleAlreadyExist) Error() string {
+	return fmt.Sprintf("role already exists [org_id: %d, name: %s]", err.OrgID, err.Name)
+}
+
+// ErrOrgRoleInUse represents a "OrgRoleInUse" kind of error.
+type ErrOrgRoleInUse struct {
+	ID int64
+}
+
+// IsErrOrgRoleInUse checks if an error is a ErrOrgRoleInUse.
+func IsErrOrgRoleInUse(err error) bool {
+	_, ok := err.(ErrOrgRoleInUse)
+	return ok
+}
+
+func (err ErrOrgRoleInUse) Error() string {
+	return fmt.Sprintf("role is assigned to teams [id: %d]", err.ID)
+}
+
+// ErrInvalidOrgRolePermission represents a "InvalidOrgRolePermission" kind of error.
+type ErrInvalidOrgRolePermission struct {
+	Type UnitType
+	Mode AccessMode
+}
+
+// IsErrInvalidOrgRolePermission checks if an error is a ErrInvalidOrgRolePermission.
+func IsErrInvalidOrgRolePermission(err error) bool {
+	_, ok := err.(ErrInvalidOrgRolePermission)
+	return ok
+}
+
+func (err ErrInvalidOrgRolePermission) Error() string {
+	if err.Type == 0 {
+		return "role must grant at least one permission"
+	}
+	return fmt.Sprintf("invalid role permission [unit: %s, mode: %s]", err.Type, err.Mode)
+}
+
+// UnitAccessMode returns the permission the role grants on the unit.
+func (r *OrgRole) UnitAccessMode(tp UnitType) AccessMode {
+	for _, p := range r.Permissions {
+		if p.Type == tp {
+			return p.AccessMode
+		}
+	}
+	return AccessModeNone
+}
+
+// MaxAccessMode returns the highest permission the role grants, which is
+// the access level of the teams the role is assigned to.
+func (r *OrgRole) MaxAccessMode() AccessMode {
+	mode := AccessModeNone
+	for _, p := range r.Permissions {
+		if p.AccessMode > mode {
+			mode = p.AccessMode
+		}
+	}
+	return mode
+}
+
+// teamUnits returns the units of the teams the role is assigned to.
+func (r *OrgRole) teamUnits(t *Team) []*TeamUnit {
+	units := make([]*TeamUnit, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		if p.AccessMode >= AccessModeRead {
+			units = append(units, &TeamUnit{OrgID: t.OrgID, TeamID: t.ID, Type: p.Type})
+		}
+	}
+	return units
+}
+
+func (r *OrgRole) loadPermissions(e Engine) error {
+	if r.Permissions != nil {
+		return nil
+	}
+	r.Permissions = make([]*OrgRolePermission, 0, len(Units))
+	return e.Where("role_id = ?", r.ID).Asc("type").Find(&r.Permissions)
+}
+
+// LoadPermissions loads the permissions of the role.
+func (r *OrgRole) LoadPermissions() error {
+	return r.loadPermissions(x)
+}
+
+// validate checks the name and the permissions of the role.
+func (r *OrgRole) validate(e Engine) error {
+	r.LowerName = strings.ToLower(r.Name)
+	has, err := e.
+		Where("org_id = ?", r.OrgID).
+		And("lower_name = ?", r.LowerName).
+		And("id != ?", r.ID).
+		Exist(new(OrgRole))
+	if err != nil {
+		return err
+	} else if has {
+		return ErrOrgRoleAlreadyExist{OrgID: r.OrgID, Name: r.Name}
+	}
+
+	seen := make(map[UnitType]bool, len(r.Permissions))
+	for _, p := range r.Permissions {
+		if _, ok := Units[p.Type]; !ok || seen[p.Type] ||
+			p.AccessMode < AccessModeRead || p.AccessMode > AccessModeWrite {
+			return ErrInvalidOrgRolePermission{Type: p.Type, Mode: p.AccessMode}
+		}
+		seen[p.Type] = true
+	}
+	if len(r.Permissions) == 0 {
+		return ErrInvalidOrgRolePermission{}
+	}
+	return nil
+}
+
+func (r *OrgRole) insertPermissions(e Engine) error {
+	if _, err := e.Where("role_id = ?", r.ID).Delete(new(OrgRolePermission)); err != nil {
+		return err
+	}
+	for _, p := range r.Permissions {
+		p.ID = 0
+		p.RoleID = r.ID
+	}
+	_, err := e.Insert(&r.Permissions)
+	return err
+}
+
+// NewOrgRole creates a custom role of an organization with its permissions.
+func NewOrgRole(r *OrgRole) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := r.validate(sess); err != nil {
+		return err
+	}
+	if _, err := sess.Insert(r); err != nil {
+		return err
+	}
+	if err := r.insertPermissions(sess); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func getOrgRoleByID(e Engine, orgID, id int64) (*OrgRole, error) {
+	r := new(OrgRole)
+	has, err := e.ID(id).Where("org_id = ?", orgID).Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrOrgRoleNotExist{ID: id}
+	}
+	return r, r.loadPermissions(e)
+}
+
+// GetOrgRoleByID returns the custom role of the organization with its permissions.
+func GetOrgRoleByID(orgID, id int64) (*OrgRole, error) {
+	return getOrgRoleByID(x, orgID, id)
+}
+
+// GetOrgRoles returns the custom roles of the organization ordered by name,
+// with their permissions.
+func GetOrgRoles(orgID int64) ([]*OrgRole, error) {
+	roles := make([]*OrgRole, 0, 5)
+	if err := x.Where("org_id = ?", orgID).Asc("lower_name").Find(&roles); err != nil {
+		return nil, err
+	}
+	for _, r := range roles {
+		if err := r.loadPermissions(x); err != nil {
+			return nil, err
+		}
+	}
+	return roles, nil
+}
+
+func (r *OrgRole) getTeams(e Engine) ([]*Team, error) {
+	teams := make([]*Team, 0, 5)
+	return teams, e.
+		Where("org_id = ?", r.OrgID).
+		And("role_id = ?", r.ID).
+		Asc("lower_name").
+		Find(&teams)
+}
+
+// GetTeams returns the teams the role is assigned to.
+func (r *OrgRole) GetTeams() ([]*Team, error) {
+	return r.getTeams(x)
+}
+
+// UpdateOrgRole updates the role and its permissions, and applies them to the
+// teams the role is assigned to.
+func UpdateOrgRole(r *OrgRole) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := r.validate(sess); err != nil {
+		return err
+	}
+	if _, err := sess.ID(r.ID).Cols("name", "lower_name", "description").Update(r); err != nil {
+		return err
+	}
+	if err := r.insertPermissions(sess); err != nil {
+		return err
+	}
+
+	teams, err := r.getTeams(sess)
+	if err != nil {
+		return err
+	}
+	for _, t := range teams {
+		if err := t.applyRole(sess, r); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// DeleteOrgRole deletes the role, which must not be assigned to any team.
+func DeleteOrgRole(r *OrgRole) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	has, err := sess.Where("role_id = ?", r.ID).Exist(new(Team))
+	if err != nil {
+		return err
+	} else if has {
+		return ErrOrgRoleInUse{ID: r.ID}
+	}
+
+	if _, err := sess.ID(r.ID).Delete(new(OrgRole)); err != nil {
+		return err
+	}
+	if _, err := sess.Where("role_id = ?", r.ID).Delete(new(OrgRolePermission)); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func deleteOrgRoles(e Engine, orgID int64) error {
+	if _, err := e.In("role_id", builder.Select("id").From("org_role").Where(builder.Eq{"org_id": orgID})).
+		Delete(new(OrgRolePermission)); err != nil {
+		return err
+	}
+	_, err := e.Where("org_id = ?", orgID).Delete(new(OrgRole))
+	return err
+}
+
+func (t *Team) loadRole(e Engine) (err error) {
+	if t.RoleID == 0 || t.Role != nil {
+		return nil
+	}
+	t.Role, err = getOrgRoleByID(e, t.OrgID, t.RoleID)
+	return err
+}
+
+// LoadRole loads the custom role of the team.
+func (t *Team) LoadRole() error {
+	return t.loadRole(x)
+}
+
+// unitAccessMode returns the permission the team grants on the unit, which
+// is the one of its custom role or its access level on its units.
+func (t *Team) unitAccessMode(e Engine, tp UnitType) AccessMode {
+	if t.RoleID > 0 {
+		if err := t.loadRole(e); err != nil {
+			log.Warn("Error loading team (ID: %d) role: %v", t.ID, err)
+			return AccessModeNone
+		}
+		return t.Role.UnitAccessMode(tp)
+	}
+	if t.unitEnabled(e, tp) {
+		return t.Authorize
+	}
+	return AccessModeNone
+}
+
+// applyRole assigns the role to the team, whose access level and units
+// become the ones of the role.
+func (t *Team) applyRole(e Engine, r *OrgRole) error {
+	t.RoleID = r.ID
+	t.Role = r
+	t.Authorize = r.MaxAccessMode()
+	t.Units = r.teamUnits(t)
+
+	if _, err := e.ID(t.ID).Cols("role_id", "authorize").Update(t); err != nil {
+		return err
+	}
+	if _, err := e.Where("team_id = ?", t.ID).Delete(new(TeamUnit)); err != nil {
+		return err
+	}
+	if len(t.Units) > 0 {
+		if _, err := e.Insert(&t.Units); err != nil {
+			return err
+		}
+	}
+	return t.recalculateAccesses(e)
+}
+
+// recalculateAccesses recalculates the accesses of the repositories of the team.
+func (t *Team) recalculateAccesses(e Engine) error {
+	if err := t.getRepositories(e); err != nil {
+		return fmt.Errorf("getRepositories: %v", err)
+	}
+	for _, repo := range t.Repos {
+		if err := repo.recalculateTeamAccesses(e, 0); err != nil {
+			return fmt.Errorf("recalculateTeamAccesses: %v", err)
+		}
+	}
+	return nil
+}
+
+// ChangeTeamRole assigns the custom role to the team, or removes the custom
+// role of the team if the role ID is zero.
+func ChangeTeamRole(t *Team, roleID int64) error {
+	if t.RoleID == roleID {
+		return nil
+	}
+	if t.IsOwnerTeam() {
+		return ErrOrgRoleNotExist{ID: roleID}
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if roleID == 0 {
+		t.RoleID = 0
+		t.Role = nil
+		if _, err := sess.ID(t.ID).Cols("role_id").Update(t); err != nil {
+			return err
+		}
+		if err := t.recalculateAccesses(sess); err != nil {
+			return err
+		}
+		return sess.Commit()
+	}
+
+	r, err := getOrgRoleByID(sess, t.OrgID, roleID)
+	if err != nil {
+		return err
+	}
+	if err := t.applyRole(sess, r); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOrgRole(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	role := &OrgRole{OrgID: 3, Name: "Triage", Permissions: []*OrgRolePermission{
+		{Type: UnitTypeCode, AccessMode: AccessModeRead},
+		{Type: UnitTypeIssues, AccessMode: AccessModeWrite},
+	}}
+	assert.NoError(t, NewOrgRole(role))
+	AssertExistsAndLoadBean(t, &OrgRolePermission{RoleID: role.ID, Type: UnitTypeIssues, AccessMode: AccessModeWrite})
+
+	role, err := GetOrgRoleByID(3, role.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, AccessModeWrite, role.MaxAccessMode())
+	assert.EqualValues(t, AccessModeRead, role.UnitAccessMode(UnitTypeCode))
+	assert.EqualValues(t, AccessModeNone, role.UnitAccessMode(UnitTypeWiki))
+
+	_, err = GetOrgRoleByID(17, role.ID)
+	assert.True(t, IsErrOrgRoleNotExist(err))
+
+	assert.True(t, IsErrOrgRoleAlreadyExist(NewOrgRole(&OrgRole{OrgID: 3, Name: "triage", Permissions: []*OrgRolePermission{
+		{Type: UnitTypeCode, AccessMode: AccessModeRead},
+	}})))
+	assert.True(t, IsErrInvalidOrgRolePermission(NewOrgRole(&OrgRole{OrgID: 3, Name: "empty"})))
+	assert.True(t, IsErrInvalidOrgRolePermission(NewOrgRole(&OrgRole{OrgID: 3, Name: "admin", Permissions: []*OrgRolePermission{
+		{Type: UnitTypeCode, AccessMode: AccessModeAdmin},
+	}})))
+
+	roles, err := GetOrgRoles(3)
+	assert.NoError(t, err)
+	assert.Len(t, roles, 1)
+}
+
+func TestChangeTeamRole(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	role := &OrgRole{OrgID: 3, Name: "Triage", Permissions: []*OrgRolePermission{
+		{Type: UnitTypeCode, AccessMode: AccessModeRead},
+		{Type: UnitTypeIssues, AccessMode: AccessModeWrite},
+		{Type: UnitTypePullRequests, AccessMode: AccessModeWrite},
+	}}
+	assert.NoError(t, NewOrgRole(role))
+
+	// user4 is a member of team1, which has write access to repo3
+	team := AssertExistsAndLoadBean(t, &Team{ID: 2}).(*Team)
+	assert.NoError(t, ChangeTeamRole(team, role.ID))
+	AssertExistsAndLoadBean(t, &Team{ID: 2, RoleID: role.ID, Authorize: AccessModeWrite})
+	AssertExistsAndLoadBean(t, &TeamUnit{TeamID: 2, Type: UnitTypePullRequests})
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	user := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	perm, err := GetUserRepoPermission(repo, user)
+	assert.NoError(t, err)
+	assert.True(t, perm.CanRead(UnitTypeCode))
+	assert.False(t, perm.CanWrite(UnitTypeCode))
+	assert.True(t, perm.CanWrite(UnitTypeIssues))
+	assert.True(t, perm.CanWrite(UnitTypePullRequests))
+	assert.False(t, perm.CanRead(UnitTypeWiki))
+
+	// the permissions of the role are applied to its teams
+	role.Permissions = []*OrgRolePermission{
+		{Type: UnitTypeCode, AccessMode: AccessModeRead},
+		{Type: UnitTypeWiki, AccessMode: AccessModeRead},
+	}
+	assert.NoError(t, UpdateOrgRole(role))
+	AssertExistsAndLoadBean(t, &Team{ID: 2, Authorize: AccessModeRead})
+	AssertExistsAndLoadBean(t, &Access{RepoID: 3, UserID: 4, Mode: AccessModeRead})
+	perm, err = GetUserRepoPermission(repo, user)
+	assert.NoError(t, err)
+	assert.False(t, perm.CanWrite(UnitTypeIssues))
+	assert.True(t, perm.CanRead(UnitTypeWiki))
+
+	assert.True(t, IsErrOrgRoleInUse(DeleteOrgRole(role)))
+	assert.NoError(t, ChangeTeamRole(team, 0))
+	AssertExistsAndLoadBean(t, &Team{ID: 2, RoleID: 0})
+	assert.NoError(t, DeleteOrgRole(role))
+	AssertNotExistsBean(t, &OrgRole{ID: role.ID})
+	AssertNotExistsBean(t, &OrgRolePermission{RoleID: role.ID})
+
+	// roles of other organizations cannot be assigned
+	other := &OrgRole{OrgID: 17, Name: "Wiki", Permissions: []*OrgRolePermission{
+		{Type: UnitTypeWiki, AccessMode: AccessModeWrite},
+	}}
+	assert.NoError(t, NewOrgRole(other))
+	assert.True(t, IsErrOrgRoleNotExist(ChangeTeamRole(team, other.ID)))
+	CheckConsistencyFor(t, &Team{})
+}
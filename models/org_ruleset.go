@@ -0,0 +1,443 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/gobwas/glob"
+)
+
+// RulesetTarget is the kind of references a ruleset applies to.
+type RulesetTarget int
+
+// Enumerate all the ruleset targets
+const (
+	RulesetTargetBranch RulesetTarget = iota // 0
+	RulesetTargetTag                         // 1
+)
+
+// RulesetMode defines how a ruleset is combined with the branch protection
+// of the repository and with the rulesets evaluated before it.
+type RulesetMode int
+
+// Enumerate all the ruleset modes
+const (
+	// RulesetModeSupplement adds the rules, the strictest rule applies
+	RulesetModeSupplement RulesetMode = iota // 0
+	// RulesetModeOverride replaces the rules evaluated before it
+	RulesetModeOverride // 1
+)
+
+// OrgRuleset is a set of rules an organization applies to the branches or
+// tags of its repositories. The active rulesets matching a reference are
+// evaluated by ascending priority.
+type OrgRuleset struct {
+	ID           int64         `xorm:"pk autoincr"`
+	OrgID        int64         `xorm:"INDEX UNIQUE(s)"`
+	LowerName    string        `xorm:"UNIQUE(s) NOT NULL"`
+	Name         string        `xorm:"NOT NULL"`
+	Priority     int64         `xorm:"NOT NULL DEFAULT 0"`
+	IsActive     bool          `xorm:"NOT NULL DEFAULT true"`
+	Target       RulesetTarget `xorm:"NOT NULL DEFAULT 0"`
+	Mode         RulesetMode   `xorm:"NOT NULL DEFAULT 0"`
+	RepoPatterns string        `xorm:"TEXT"`
+	RefPatterns  string        `xorm:"TEXT"`
+
+	RequiredApprovals    int64    `xorm:"NOT NULL DEFAULT 0"`
+	RequireSignedCommits bool     `xorm:"NOT NULL DEFAULT false"`
+	EnableStatusCheck    bool     `xorm:"NOT NULL DEFAULT false"`
+	StatusCheckContexts  []string `xorm:"JSON TEXT"`
+	RestrictPush         bool     `xorm:"NOT NULL DEFAULT false"`
+	PushWhitelistUserIDs []int64  `xorm:"JSON TEXT"`
+	PushWhitelistTeamIDs []int64  `xorm:"JSON TEXT"`
+	BypassUserIDs        []int64  `xorm:"JSON TEXT"`
+	BypassTeamIDs        []int64  `xorm:"JSON TEXT"`
+	// MergeStyles are the merge styles allowed into the matching branches, all the
+	// ones of the repository are allowed if empty
+	MergeStyles []string `xorm:"JSON TEXT"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// ErrOrgRulesetNotExist represents a "OrgRulesetNotExist" kind of error.
+type ErrOrgRulesetNotExist struct {
+	ID int64
+}
+
+// IsErrOrgRulesetNotExist checks if an error is a ErrOrgRulesetNotExist.
+func IsErrOrgRulesetNotExist(err error) bool {
+	_, ok := err.(ErrOrgRulesetNotExist)
+	return ok
+}
+
+func (err ErrOrgRulesetNotExist) Error() string {
+	return fmt.Sprintf("ruleset does not exist [id: %d]", err.ID)
+}
+
+// ErrOrgRulesetAlreadyExist represents a "OrgRulesetAlreadyExist" kind of error.
+type ErrOrgRulesetAlreadyExist struct {
+	OrgID int64
+	Name  string
+}
+
+// IsErrOrgRulesetAlreadyExist checks if an error is a ErrOrgRulesetAlreadyExist.
+func IsErrOrgRulesetAlreadyExist(err error) bool {
+	_, ok := err.(ErrOrgRulesetAlreadyExist)
+	return ok
+}
+
+func (err ErrOrgRulesetAlreadyExist) Error() string {
+	return fmt.Sprintf("ruleset already exists [org_id: %d, name: %s]", err.OrgID, err.Name)
+}
+
+// ErrInvalidRulesetPattern represents a "InvalidRulesetPattern" kind of error.
+type ErrInvalidRulesetPattern struct {
+	Pattern string
+}
+
+// IsErrInvalidRulesetPattern checks if an error is a ErrInvalidRulesetPattern.
+func IsErrInvalidRulesetPattern(err error) bool {
+	_, ok := err.(ErrInvalidRulesetPattern)
+	return ok
+}
+
+func (err ErrInvalidRulesetPattern) Error() string {
+	return fmt.Sprintf("invalid ruleset pattern [pattern: %s]", err.Pattern)
+}
+
+// ErrTagProtected represents a "TagProtected" kind of error.
+type ErrTagProtected struct {
+	TagName string
+	Ruleset string
+}
+
+// IsErrTagProtected checks if an error is a ErrTagProtected.
+func IsErrTagProtected(err error) bool {
+	_, ok := err.(ErrTagProtected)
+	return ok
+}
+
+func (err ErrTagProtected) Error() string {
+	return fmt.Sprintf("tag is protected by a ruleset [tag: %s, ruleset: %s]", err.TagName, err.Ruleset)
+}
+
+// splitRulesetPatterns splits a semicolon separated list of patterns.
+func splitRulesetPatterns(patterns string) []string {
+	exprs := make([]string, 0, 5)
+	for _, expr := range strings.Split(patterns, ";") {
+		if expr = strings.TrimSpace(expr); expr != "" {
+			exprs = append(exprs, expr)
+		}
+	}
+	return exprs
+}
+
+// matchRulesetPatterns reports whether the name matches one of the patterns,
+// an empty list of patterns matches every name.
+func matchRulesetPatterns(patterns, name string, separators ...rune) bool {
+	exprs := splitRulesetPatterns(patterns)
+	if len(exprs) == 0 {
+		return true
+	}
+	for _, expr := range exprs {
+		g, err := glob.Compile(expr, separators...)
+		if err != nil {
+			log.Info("Invalid glob expression '%s' (skipped): %v", expr, err)
+			continue
+		}
+		if g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesRepo reports whether the ruleset applies to the repository.
+func (r *OrgRuleset) MatchesRepo(repo *Repository) bool {
+	return repo.OwnerID == r.OrgID && matchRulesetPatterns(strings.ToLower(r.RepoPatterns), repo.LowerName)
+}
+
+// MatchesRef reports whether the ruleset applies to the branch or tag.
+func (r *OrgRuleset) MatchesRef(target RulesetTarget, name string) bool {
+	return r.Target == target && matchRulesetPatterns(r.RefPatterns, name, '/')
+}
+
+func (r *OrgRuleset) canBypass(e Engine, userID int64) bool {
+	if userID <= 0 {
+		return false
+	}
+	if base.Int64sContains(r.BypassUserIDs, userID) {
+		return true
+	}
+	if len(r.BypassTeamIDs) == 0 {
+		return false
+	}
+	in, err := isUserInTeams(e, userID, r.BypassTeamIDs)
+	if err != nil {
+		log.Error("IsUserInTeams: %v", err)
+		return false
+	}
+	return in
+}
+
+// CanBypass returns if the user is in the bypass list of the ruleset, the
+// ruleset does not apply to the user.
+func (r *OrgRuleset) CanBypass(userID int64) bool {
+	return r.canBypass(x, userID)
+}
+
+// CanUserPush returns if the push restriction of the ruleset allows the
+// user to push to the matching references.
+func (r *OrgRuleset) CanUserPush(userID int64) bool {
+	if !r.RestrictPush {
+		return true
+	}
+	if base.Int64sContains(r.PushWhitelistUserIDs, userID) {
+		return true
+	}
+	if len(r.PushWhitelistTeamIDs) == 0 {
+		return false
+	}
+	in, err := IsUserInTeams(userID, r.PushWhitelistTeamIDs)
+	if err != nil {
+		log.Error("IsUserInTeams: %v", err)
+		return false
+	}
+	return in
+}
+
+// applyTo adds the rules of the ruleset to the branch protection, keeping the
+// strictest of both.
+func (r *OrgRuleset) applyTo(protectBranch *ProtectedBranch) {
+	protectBranch.Rulesets = append(protectBranch.Rulesets, r)
+	if r.RequiredApprovals > protectBranch.RequiredApprovals {
+		protectBranch.RequiredApprovals = r.RequiredApprovals
+	}
+	protectBranch.RequireSignedCommits = protectBranch.RequireSignedCommits || r.RequireSignedCommits
+	if r.EnableStatusCheck {
+		protectBranch.EnableStatusCheck = true
+		for _, context := range r.StatusCheckContexts {
+			if !util.IsStringInSlice(context, protectBranch.StatusCheckContexts) {
+				protectBranch.StatusCheckContexts = append(protectBranch.StatusCheckContexts, context)
+			}
+		}
+	}
+}
+
+// validate checks the name and the patterns of the ruleset, and keeps only
+// the existing users and the teams of the organization in its lists.
+func (r *OrgRuleset) validate(e Engine) (err error) {
+	r.Name = strings.TrimSpace(r.Name)
+	r.LowerName = strings.ToLower(r.Name)
+	has, err := e.
+		Where("org_id = ?", r.OrgID).
+		And("lower_name = ?", r.LowerName).
+		And("id != ?", r.ID).
+		Exist(new(OrgRuleset))
+	if err != nil {
+		return err
+	} else if has {
+		return ErrOrgRulesetAlreadyExist{OrgID: r.OrgID, Name: r.Name}
+	}
+
+	for _, expr := range append(splitRulesetPatterns(r.RepoPatterns), splitRulesetPatterns(r.RefPatterns)...) {
+		if _, err := glob.Compile(expr, '/'); err != nil {
+			return ErrInvalidRulesetPattern{Pattern: expr}
+		}
+	}
+	if r.RequiredApprovals < 0 {
+		r.RequiredApprovals = 0
+	}
+
+	if r.PushWhitelistUserIDs, err = filterRulesetUserIDs(e, r.PushWhitelistUserIDs); err != nil {
+		return err
+	}
+	if r.BypassUserIDs, err = filterRulesetUserIDs(e, r.BypassUserIDs); err != nil {
+		return err
+	}
+	if r.PushWhitelistTeamIDs, err = filterRulesetTeamIDs(e, r.OrgID, r.PushWhitelistTeamIDs); err != nil {
+		return err
+	}
+	r.BypassTeamIDs, err = filterRulesetTeamIDs(e, r.OrgID, r.BypassTeamIDs)
+	return err
+}
+
+func filterRulesetUserIDs(e Engine, ids []int64) ([]int64, error) {
+	result := make([]int64, 0, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	return result, e.Table("user").
+		In("id", ids).
+		And("type = ?", UserTypeIndividual).
+		Cols("id").
+		Find(&result)
+}
+
+func filterRulesetTeamIDs(e Engine, orgID int64, ids []int64) ([]int64, error) {
+	result := make([]int64, 0, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	return result, e.Table("team").
+		In("id", ids).
+		And("org_id = ?", orgID).
+		Cols("id").
+		Find(&result)
+}
+
+// NewOrgRuleset creates a ruleset of an organization.
+func NewOrgRuleset(r *OrgRuleset) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := r.validate(sess); err != nil {
+		return err
+	}
+	if _, err := sess.Insert(r); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// UpdateOrgRuleset updates all the columns of the ruleset.
+func UpdateOrgRuleset(r *OrgRuleset) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := r.validate(sess); err != nil {
+		return err
+	}
+	if _, err := sess.ID(r.ID).AllCols().Update(r); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetOrgRulesetByID returns the ruleset of the organization.
+func GetOrgRulesetByID(orgID, id int64) (*OrgRuleset, error) {
+	r := new(OrgRuleset)
+	has, err := x.ID(id).Where("org_id = ?", orgID).Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrOrgRulesetNotExist{ID: id}
+	}
+	return r, nil
+}
+
+func getOrgRulesets(e Engine, orgID int64, onlyActive bool) ([]*OrgRuleset, error) {
+	sess := e.Where("org_id = ?", orgID)
+	if onlyActive {
+		sess.And("is_active = ?", true)
+	}
+	rulesets := make([]*OrgRuleset, 0, 5)
+	return rulesets, sess.
+		Asc("priority", "id").
+		Find(&rulesets)
+}
+
+// GetOrgRulesets returns the rulesets of the organization in evaluation order.
+func GetOrgRulesets(orgID int64) ([]*OrgRuleset, error) {
+	return getOrgRulesets(x, orgID, false)
+}
+
+// DeleteOrgRuleset deletes the ruleset of the organization.
+func DeleteOrgRuleset(orgID, id int64) error {
+	n, err := x.ID(id).Where("org_id = ?", orgID).Delete(new(OrgRuleset))
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrOrgRulesetNotExist{ID: id}
+	}
+	return nil
+}
+
+func deleteOrgRulesets(e Engine, orgID int64) error {
+	_, err := e.Where("org_id = ?", orgID).Delete(new(OrgRuleset))
+	return err
+}
+
+// GetRepoRulesets returns the active rulesets of the organization which apply
+// to the repository, in evaluation order.
+func GetRepoRulesets(repo *Repository) ([]*OrgRuleset, error) {
+	rulesets, err := getOrgRulesets(x, repo.OwnerID, true)
+	if err != nil {
+		return nil, err
+	}
+	matching := rulesets[:0]
+	for _, r := range rulesets {
+		if r.MatchesRepo(repo) {
+			matching = append(matching, r)
+		}
+	}
+	return matching, nil
+}
+
+// getRefRulesets returns the active rulesets which apply to the branch or tag
+// of the repository and which the user cannot bypass, in evaluation order.
+func getRefRulesets(e Engine, repo *Repository, target RulesetTarget, name string, doerID int64) ([]*OrgRuleset, error) {
+	rulesets, err := getOrgRulesets(e, repo.OwnerID, true)
+	if err != nil {
+		return nil, err
+	}
+	matching := rulesets[:0]
+	for _, r := range rulesets {
+		if r.MatchesRepo(repo) && r.MatchesRef(target, name) && !r.canBypass(e, doerID) {
+			matching = append(matching, r)
+		}
+	}
+	return matching, nil
+}
+
+// GetTagRulesets returns the active rulesets which apply to the tag of the
+// repository and which the user cannot bypass.
+func GetTagRulesets(repo *Repository, tagName string, doerID int64) ([]*OrgRuleset, error) {
+	return getRefRulesets(x, repo, RulesetTargetTag, tagName, doerID)
+}
+
+func getEffectiveProtectedBranch(e Engine, repo *Repository, branchName string, doerID int64) (*ProtectedBranch, error) {
+	protectBranch, err := getProtectedBranchBy(e, repo.ID, branchName)
+	if err != nil {
+		return nil, err
+	}
+	rulesets, err := getRefRulesets(e, repo, RulesetTargetBranch, branchName, doerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rulesets {
+		if protectBranch == nil || r.Mode == RulesetModeOverride {
+			protectBranch = &ProtectedBranch{
+				RepoID:     repo.ID,
+				BranchName: branchName,
+				CanPush:    true,
+			}
+		}
+		r.applyTo(protectBranch)
+	}
+	return protectBranch, nil
+}
+
+// GetEffectiveProtectedBranch returns the protection of the branch combining
+// the branch protection of the repository with the rulesets of its
+// organization which the user cannot bypass. A zero user ID applies all the
+// rulesets. It returns nil if the branch is not protected.
+func GetEffectiveProtectedBranch(repo *Repository, branchName string, doerID int64) (*ProtectedBranch, error) {
+	return getEffectiveProtectedBranch(x, repo, branchName, doerID)
+}
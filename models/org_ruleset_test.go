@@ -0,0 +1,102 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOrgRuleset(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	r := &OrgRuleset{
+		OrgID:         3,
+		Name:          "Release branches",
+		IsActive:      true,
+		RefPatterns:   "release/*",
+		BypassUserIDs: []int64{2, 3, 9999},
+		BypassTeamIDs: []int64{1, 3},
+	}
+	assert.NoError(t, NewOrgRuleset(r))
+	// organizations, unknown users and the teams of other organizations are dropped
+	assert.EqualValues(t, []int64{2}, r.BypassUserIDs)
+	assert.EqualValues(t, []int64{1}, r.BypassTeamIDs)
+
+	assert.True(t, IsErrOrgRulesetAlreadyExist(NewOrgRuleset(&OrgRuleset{OrgID: 3, Name: "release branches"})))
+	assert.True(t, IsErrInvalidRulesetPattern(NewOrgRuleset(&OrgRuleset{OrgID: 3, Name: "invalid", RefPatterns: "main;[release"})))
+
+	_, err := GetOrgRulesetByID(17, r.ID)
+	assert.True(t, IsErrOrgRulesetNotExist(err))
+
+	assert.NoError(t, DeleteOrgRuleset(3, r.ID))
+	assert.True(t, IsErrOrgRulesetNotExist(DeleteOrgRuleset(3, r.ID)))
+}
+
+func TestGetEffectiveProtectedBranch(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+
+	protectBranch, err := GetEffectiveProtectedBranch(repo, "master", 0)
+	assert.NoError(t, err)
+	assert.Nil(t, protectBranch)
+
+	assert.NoError(t, NewOrgRuleset(&OrgRuleset{
+		OrgID:             3,
+		Name:              "Reviews",
+		Priority:          1,
+		IsActive:          true,
+		RepoPatterns:      "repo*",
+		RefPatterns:       "master;release/*",
+		RequiredApprovals: 2,
+		BypassUserIDs:     []int64{2},
+	}))
+	assert.NoError(t, NewOrgRuleset(&OrgRuleset{
+		OrgID:                3,
+		Name:                 "Signing",
+		Priority:             0,
+		IsActive:             true,
+		RefPatterns:          "master",
+		RequiredApprovals:    1,
+		RequireSignedCommits: true,
+		RestrictPush:         true,
+		PushWhitelistTeamIDs: []int64{1},
+	}))
+	assert.NoError(t, NewOrgRuleset(&OrgRuleset{
+		OrgID:             3,
+		Name:              "Inactive",
+		RequiredApprovals: 5,
+	}))
+
+	protectBranch, err = GetEffectiveProtectedBranch(repo, "master", 0)
+	assert.NoError(t, err)
+	if assert.NotNil(t, protectBranch) {
+		assert.True(t, protectBranch.IsProtected())
+		assert.Len(t, protectBranch.Rulesets, 2)
+		assert.Equal(t, "Signing", protectBranch.Rulesets[0].Name)
+		assert.EqualValues(t, 2, protectBranch.RequiredApprovals)
+		assert.True(t, protectBranch.RequireSignedCommits)
+		assert.True(t, protectBranch.IsPushRestrictedByRulesets())
+		assert.True(t, protectBranch.CanUserPush(2))
+		assert.False(t, protectBranch.CanUserPush(4))
+	}
+
+	// user 2 bypasses the "Reviews" ruleset
+	protectBranch, err = GetEffectiveProtectedBranch(repo, "master", 2)
+	assert.NoError(t, err)
+	if assert.NotNil(t, protectBranch) {
+		assert.Len(t, protectBranch.Rulesets, 1)
+		assert.EqualValues(t, 1, protectBranch.RequiredApprovals)
+	}
+
+	protectBranch, err = GetEffectiveProtectedBranch(repo, "develop", 0)
+	assert.NoError(t, err)
+	assert.Nil(t, protectBranch)
+
+	rulesets, err := GetTagRulesets(repo, "v1.0", 0)
+	assert.NoError(t, err)
+	assert.Len(t, rulesets, 0)
+}
@@ -35,6 +35,20 @@ type Team struct {
 	Units                   []*TeamUnit `xorm:"-"`
 	IncludesAllRepositories bool        `xorm:"NOT NULL DEFAULT false"`
 	CanCreateOrgRepo        bool        `xorm:"NOT NULL DEFAULT false"`
+
+	// Members of the child teams inherit the repository permissions of
+	// their parent team.
+	ParentID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+
+	// A custom role replaces the access level and the units of the team.
+	RoleID int64    `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Role   *OrgRole `xorm:"-"`
+
+	// ReviewAssignCount members are requested to review when the team is
+	// requested, unless the algorithm requests all members.
+	ReviewAssignAlgorithm  ReviewAssignAlgorithm `xorm:"NOT NULL DEFAULT 0"`
+	ReviewAssignCount      int                   `xorm:"NOT NULL DEFAULT 1"`
+	ReviewAssignLastUserID int64                 `xorm:"NOT NULL DEFAULT 0"`
 }
 
 // SearchTeamOptions holds the search options
@@ -341,9 +355,16 @@ func (t *Team) removeAllRepositories(e Engine) (err error) {
 	}
 
 	// Delete team-repo
-	if _, err := e.
-		Where("team_id=?", t.ID).
-		Delete(new(TeamRepo)); err != nil {
+	userIDs, err := getTeamMemberIDs(e, t.ID)
+	if err != nil {
+		return err
+	}
+	if err = invalidateAccessibleRepoIDsOnCommit(e, userIDs, func(e Engine) error {
+		_, err := e.
+			Where("team_id=?", t.ID).
+			Delete(new(TeamRepo))
+		return err
+	}); err != nil {
 		return err
 	}
 
@@ -487,6 +508,18 @@ func NewTeam(t *Team) (err error) {
 		return ErrTeamAlreadyExist{t.OrgID, t.LowerName}
 	}
 
+	if err = validateTeamParent(x, t, t.ParentID); err != nil {
+		return err
+	}
+
+	if t.RoleID > 0 {
+		if err = t.loadRole(x); err != nil {
+			return err
+		}
+		t.Authorize = t.Role.MaxAccessMode()
+		t.Units = t.Role.teamUnits(t)
+	}
+
 	sess := x.NewSession()
 	defer sess.Close()
 	if err = sess.Begin(); err != nil {
@@ -635,8 +668,19 @@ func UpdateTeam(t *Team, authChanged bool, includeAllChanged bool) (err error) {
 		return ErrTeamAlreadyExist{t.OrgID, t.LowerName}
 	}
 
+	// The access level and the units of a team with a custom role are the
+	// ones of the role.
+	if t.RoleID > 0 {
+		if err = t.loadRole(sess); err != nil {
+			return err
+		}
+		t.Authorize = t.Role.MaxAccessMode()
+		t.Units = t.Role.teamUnits(t)
+	}
+
 	if _, err = sess.ID(t.ID).Cols("name", "lower_name", "description",
-		"can_create_org_repo", "authorize", "includes_all_repositories").Update(t); err != nil {
+		"can_create_org_repo", "authorize", "includes_all_repositories",
+		"review_assign_algorithm", "review_assign_count").Update(t); err != nil {
 		return fmt.Errorf("update: %v", err)
 	}
 
@@ -700,11 +744,23 @@ func DeleteTeam(t *Team) error {
 	if err := t.getMembers(sess); err != nil {
 		return err
 	}
+	inheritedRepos, err := t.getInheritedRepositories(sess)
+	if err != nil {
+		return err
+	}
 
 	if err := t.removeAllRepositories(sess); err != nil {
 		return err
 	}
 
+	// The child teams become children of the parent team.
+	if _, err := sess.
+		Where("parent_id=?", t.ID).
+		Cols("parent_id").
+		Update(&Team{ParentID: t.ParentID}); err != nil {
+		return err
+	}
+
 	// Delete team-user.
 	if _, err := sess.
 		Where("org_id=?", t.OrgID).
@@ -713,6 +769,15 @@ func DeleteTeam(t *Team) error {
 		return err
 	}
 
+	// Delete the access the members inherited from the parent teams.
+	for _, repo := range inheritedRepos {
+		for _, u := range t.Members {
+			if err := repo.recalculateUserAccess(sess, u.ID); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Delete team-unit.
 	if _, err := sess.
 		Where("team_id=?", t.ID).
@@ -810,13 +875,22 @@ func getUserOrgTeams(e Engine, orgID, userID int64) (teams []*Team, err error) {
 		Find(&teams)
 }
 
+// getUserRepoTeams returns the teams of the user with access to the
+// repository, including the ancestors of the teams of the user.
 func getUserRepoTeams(e Engine, orgID, userID, repoID int64) (teams []*Team, err error) {
+	userTeams, err := getUserOrgTeams(e, orgID, userID)
+	if err != nil || len(userTeams) == 0 {
+		return nil, err
+	}
+	teamIDs, err := collectTeamAncestorIDs(e, userTeams)
+	if err != nil {
+		return nil, err
+	}
 	return teams, e.
-		Join("INNER", "team_user", "team_user.team_id = team.id").
 		Join("INNER", "team_repo", "team_repo.team_id = team.id").
 		Where("team.org_id = ?", orgID).
-		And("team_user.uid=?", userID).
 		And("team_repo.repo_id=?", repoID).
+		In("team.id", teamIDs).
 		Find(&teams)
 }
 
@@ -838,6 +912,14 @@ func AddTeamMember(team *Team, userID int64) error {
 		return err
 	}
 
+	u, err := getUserByID(x, userID)
+	if err != nil {
+		return err
+	}
+	if err = checkBotScope(x, u, team.OrgID, team.IsOwnerTeam()); err != nil {
+		return err
+	}
+
 	if err := AddOrgUser(team.OrgID, userID); err != nil {
 		return err
 	}
@@ -846,6 +928,10 @@ func AddTeamMember(team *Team, userID int64) error {
 	if err := team.GetRepositories(&SearchTeamOptions{}); err != nil {
 		return err
 	}
+	inheritedRepos, err := team.getInheritedRepositories(x)
+	if err != nil {
+		return err
+	}
 
 	sess := x.NewSession()
 	defer sess.Close()
@@ -877,6 +963,13 @@ func AddTeamMember(team *Team, userID int64) error {
 		}
 	}
 
+	// Give access to the repositories of the parent teams.
+	for _, repo := range inheritedRepos {
+		if err := repo.recalculateUserAccess(sess, userID); err != nil {
+			return err
+		}
+	}
+
 	return sess.Commit()
 }
 
@@ -896,6 +989,10 @@ func removeTeamMember(e *xorm.Session, team *Team, userID int64) error {
 	if err := team.getRepositories(e); err != nil {
 		return err
 	}
+	inheritedRepos, err := team.getInheritedRepositories(e)
+	if err != nil {
+		return err
+	}
 
 	if _, err := e.Delete(&TeamUser{
 		UID:    userID,
@@ -910,8 +1007,8 @@ func removeTeamMember(e *xorm.Session, team *Team, userID int64) error {
 		return err
 	}
 
-	// Delete access to team repositories.
-	for _, repo := range team.Repos {
+	// Delete access to team repositories and to the ones of the parent teams.
+	for _, repo := range append(team.Repos, inheritedRepos...) {
 		if err := repo.recalculateUserAccess(e, userID); err != nil {
 			return err
 		}
@@ -1003,20 +1100,32 @@ func HasTeamRepo(orgID, teamID, repoID int64) bool {
 }
 
 func addTeamRepo(e Engine, orgID, teamID, repoID int64) error {
-	_, err := e.InsertOne(&TeamRepo{
-		OrgID:  orgID,
-		TeamID: teamID,
-		RepoID: repoID,
+	userIDs, err := getTeamMemberIDs(e, teamID)
+	if err != nil {
+		return err
+	}
+	return invalidateAccessibleRepoIDsOnCommit(e, userIDs, func(e Engine) error {
+		_, err := e.InsertOne(&TeamRepo{
+			OrgID:  orgID,
+			TeamID: teamID,
+			RepoID: repoID,
+		})
+		return err
 	})
-	return err
 }
 
 func removeTeamRepo(e Engine, teamID, repoID int64) error {
-	_, err := e.Delete(&TeamRepo{
-		TeamID: teamID,
-		RepoID: repoID,
+	userIDs, err := getTeamMemberIDs(e, teamID)
+	if err != nil {
+		return err
+	}
+	return invalidateAccessibleRepoIDsOnCommit(e, userIDs, func(e Engine) error {
+		_, err := e.Delete(&TeamRepo{
+			TeamID: teamID,
+			RepoID: repoID,
+		})
+		return err
 	})
-	return err
 }
 
 // GetTeamsWithAccessToRepo returns all teams in an organization that have given access level to the repository.
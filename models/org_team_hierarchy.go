@@ -0,0 +1,321 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"xorm.io/builder"
+)
+
+// ErrTeamParentCycle represents a "TeamParentCycle" kind of error.
+type ErrTeamParentCycle struct {
+	TeamID   int64
+	ParentID int64
+}
+
+// IsErrTeamParentCycle checks if an error is a ErrTeamParentCycle.
+func IsErrTeamParentCycle(err error) bool {
+	_, ok := err.(ErrTeamParentCycle)
+	return ok
+}
+
+func (err ErrTeamParentCycle) Error() string {
+	return fmt.Sprintf("team cannot be a child of itself or of its child teams [team_id: %d, parent_id: %d]", err.TeamID, err.ParentID)
+}
+
+// ErrInvalidTeamParent represents a "InvalidTeamParent" kind of error.
+type ErrInvalidTeamParent struct {
+	TeamID   int64
+	ParentID int64
+}
+
+// IsErrInvalidTeamParent checks if an error is a ErrInvalidTeamParent.
+func IsErrInvalidTeamParent(err error) bool {
+	_, ok := err.(ErrInvalidTeamParent)
+	return ok
+}
+
+func (err ErrInvalidTeamParent) Error() string {
+	return fmt.Sprintf("team cannot be a child of the team [team_id: %d, parent_id: %d]", err.TeamID, err.ParentID)
+}
+
+// collectTeamAncestorIDs returns the IDs of the teams and of all their
+// ancestors.
+func collectTeamAncestorIDs(e Engine, teams []*Team) ([]int64, error) {
+	seen := make(map[int64]bool, len(teams))
+	ids := make([]int64, 0, len(teams))
+	parentIDs := make([]int64, 0, len(teams))
+	for _, t := range teams {
+		if !seen[t.ID] {
+			seen[t.ID] = true
+			ids = append(ids, t.ID)
+		}
+		if t.ParentID > 0 {
+			parentIDs = append(parentIDs, t.ParentID)
+		}
+	}
+
+	for len(parentIDs) > 0 {
+		pending := make([]int64, 0, len(parentIDs))
+		for _, id := range parentIDs {
+			if !seen[id] {
+				seen[id] = true
+				pending = append(pending, id)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		parents := make([]*Team, 0, len(pending))
+		if err := e.In("id", pending).Find(&parents); err != nil {
+			return nil, err
+		}
+		ids = append(ids, pending...)
+		parentIDs = parentIDs[:0]
+		for _, p := range parents {
+			if p.ParentID > 0 {
+				parentIDs = append(parentIDs, p.ParentID)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// getTeamAncestorIDs returns the IDs of the teams and of all their ancestors.
+func getTeamAncestorIDs(e Engine, teamIDs []int64) ([]int64, error) {
+	if len(teamIDs) == 0 {
+		return teamIDs, nil
+	}
+	teams := make([]*Team, 0, len(teamIDs))
+	if err := e.In("id", teamIDs).Find(&teams); err != nil {
+		return nil, err
+	}
+	return collectTeamAncestorIDs(e, teams)
+}
+
+// getTeamDescendantIDs returns the IDs of the child teams of the team and of
+// their child teams.
+func getTeamDescendantIDs(e Engine, teamID int64) ([]int64, error) {
+	var ids []int64
+	seen := map[int64]bool{teamID: true}
+	parentIDs := []int64{teamID}
+	for len(parentIDs) > 0 {
+		childIDs := make([]int64, 0, 10)
+		if err := e.Table("team").
+			In("parent_id", parentIDs).
+			Cols("id").
+			Find(&childIDs); err != nil {
+			return nil, err
+		}
+		parentIDs = parentIDs[:0]
+		for _, id := range childIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+				parentIDs = append(parentIDs, id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// getInheritedMembers returns the members of the child teams of the team and
+// of their child teams, who inherit the repository permissions of the team.
+func (t *Team) getInheritedMembers(e Engine) ([]*User, error) {
+	ids, err := getTeamDescendantIDs(e, t.ID)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	members := make([]*User, 0, 10)
+	return members, e.
+		In("id", builder.Select("uid").From("team_user").Where(builder.In("team_id", ids))).
+		Find(&members)
+}
+
+// getInheritedRepositories returns the repositories of the ancestors of the
+// team, whose permissions the members of the team inherit.
+func (t *Team) getInheritedRepositories(e Engine) ([]*Repository, error) {
+	if t.ParentID == 0 {
+		return nil, nil
+	}
+	ids, err := getTeamAncestorIDs(e, []int64{t.ParentID})
+	if err != nil {
+		return nil, err
+	}
+	repos := make([]*Repository, 0, 10)
+	return repos, e.
+		In("id", builder.Select("repo_id").From("team_repo").Where(builder.In("team_id", ids))).
+		Find(&repos)
+}
+
+// GetChildTeams returns the child teams of the team ordered by name.
+func (t *Team) GetChildTeams() ([]*Team, error) {
+	teams := make([]*Team, 0, 5)
+	return teams, x.
+		Where("parent_id = ?", t.ID).
+		Asc("lower_name").
+		Find(&teams)
+}
+
+// GetTeamHierarchyCandidates returns the teams of the organization which can
+// be the parent of the team. A zero team ID lists the candidates of a new team.
+func GetTeamHierarchyCandidates(orgID, teamID int64) ([]*Team, error) {
+	excluded := map[int64]bool{teamID: true}
+	if teamID > 0 {
+		ids, err := getTeamDescendantIDs(x, teamID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			excluded[id] = true
+		}
+	}
+
+	teams := make([]*Team, 0, 10)
+	if err := x.Where("org_id = ?", orgID).Asc("lower_name").Find(&teams); err != nil {
+		return nil, err
+	}
+	candidates := teams[:0]
+	for _, t := range teams {
+		if !excluded[t.ID] && !t.IsOwnerTeam() {
+			candidates = append(candidates, t)
+		}
+	}
+	return candidates, nil
+}
+
+// validateTeamParent checks that the team can be a child of the parent team.
+func validateTeamParent(e Engine, t *Team, parentID int64) error {
+	if parentID == 0 {
+		return nil
+	}
+	if parentID == t.ID {
+		return ErrTeamParentCycle{TeamID: t.ID, ParentID: parentID}
+	}
+	if t.IsOwnerTeam() {
+		return ErrInvalidTeamParent{TeamID: t.ID, ParentID: parentID}
+	}
+
+	parent, err := getTeamByID(e, parentID)
+	if err != nil {
+		if IsErrTeamNotExist(err) {
+			return ErrInvalidTeamParent{TeamID: t.ID, ParentID: parentID}
+		}
+		return err
+	}
+	if parent.OrgID != t.OrgID || parent.IsOwnerTeam() {
+		return ErrInvalidTeamParent{TeamID: t.ID, ParentID: parentID}
+	}
+
+	if t.ID > 0 {
+		ancestors, err := getTeamAncestorIDs(e, []int64{parentID})
+		if err != nil {
+			return err
+		}
+		for _, id := range ancestors {
+			if id == t.ID {
+				return ErrTeamParentCycle{TeamID: t.ID, ParentID: parentID}
+			}
+		}
+	}
+	return nil
+}
+
+// ChangeTeamParent makes the team a child of the parent team, or a top level
+// team if the parent ID is zero, and recalculates the accesses of the members
+// of the team and of its child teams.
+func ChangeTeamParent(t *Team, parentID int64) error {
+	if t.ParentID == parentID {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := validateTeamParent(sess, t, parentID); err != nil {
+		return err
+	}
+
+	oldRepos, err := t.getInheritedRepositories(sess)
+	if err != nil {
+		return err
+	}
+	t.ParentID = parentID
+	if _, err := sess.ID(t.ID).Cols("parent_id").Update(t); err != nil {
+		return err
+	}
+	newRepos, err := t.getInheritedRepositories(sess)
+	if err != nil {
+		return err
+	}
+
+	recalculated := make(map[int64]bool, len(oldRepos)+len(newRepos))
+	for _, repo := range append(oldRepos, newRepos...) {
+		if recalculated[repo.ID] {
+			continue
+		}
+		recalculated[repo.ID] = true
+		if err := repo.recalculateTeamAccesses(sess, 0); err != nil {
+			return fmt.Errorf("recalculateTeamAccesses: %v", err)
+		}
+	}
+	return sess.Commit()
+}
+
+// SortTeamsByHierarchy orders the teams so that the child teams follow their
+// parent team, and returns the depth of each team in the hierarchy. Teams
+// whose parent is not in the list are considered top level teams.
+func SortTeamsByHierarchy(teams []*Team) ([]*Team, map[int64]int) {
+	inList := make(map[int64]bool, len(teams))
+	for _, t := range teams {
+		inList[t.ID] = true
+	}
+	children := make(map[int64][]*Team, len(teams))
+	for _, t := range teams {
+		parentID := t.ParentID
+		if !inList[parentID] {
+			parentID = 0
+		}
+		children[parentID] = append(children[parentID], t)
+	}
+
+	sorted := make([]*Team, 0, len(teams))
+	depths := make(map[int64]int, len(teams))
+	var walk func(parentID int64, depth int)
+	walk = func(parentID int64, depth int) {
+		siblings := children[parentID]
+		sort.SliceStable(siblings, func(i, j int) bool {
+			// The owners team is always listed first
+			if siblings[i].IsOwnerTeam() != siblings[j].IsOwnerTeam() {
+				return siblings[i].IsOwnerTeam()
+			}
+			return siblings[i].LowerName < siblings[j].LowerName
+		})
+		for _, t := range siblings {
+			if _, ok := depths[t.ID]; ok {
+				continue
+			}
+			depths[t.ID] = depth
+			sorted = append(sorted, t)
+			walk(t.ID, depth+1)
+		}
+	}
+	walk(0, 0)
+
+	// Teams in a broken hierarchy are listed at the end
+	for _, t := range teams {
+		if _, ok := depths[t.ID]; !ok {
+			depths[t.ID] = 0
+			sorted = append(sorted, t)
+		}
+	}
+	return sorted, depths
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeTeamParent(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// user28 is only a member of team12creators, which has no repositories
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	user := AssertExistsAndLoadBean(t, &User{ID: 28}).(*User)
+	mode, err := AccessLevel(user, repo)
+	assert.NoError(t, err)
+	assert.EqualValues(t, AccessModeNone, mode)
+
+	// team12creators inherits the write access of team1 to repo3
+	child := AssertExistsAndLoadBean(t, &Team{ID: 12}).(*Team)
+	assert.NoError(t, ChangeTeamParent(child, 2))
+	AssertExistsAndLoadBean(t, &Team{ID: 12, ParentID: 2})
+	AssertExistsAndLoadBean(t, &Access{RepoID: 3, UserID: 28, Mode: AccessModeWrite})
+	mode, err = AccessLevel(user, repo)
+	assert.NoError(t, err)
+	assert.EqualValues(t, AccessModeWrite, mode)
+
+	parent := AssertExistsAndLoadBean(t, &Team{ID: 2}).(*Team)
+	assert.True(t, IsErrTeamParentCycle(ChangeTeamParent(parent, 12)))
+	assert.True(t, IsErrTeamParentCycle(ChangeTeamParent(parent, 2)))
+	// the owners team and teams of other organizations cannot be parents
+	assert.True(t, IsErrInvalidTeamParent(ChangeTeamParent(parent, 1)))
+	assert.True(t, IsErrInvalidTeamParent(ChangeTeamParent(parent, 8)))
+	assert.True(t, IsErrInvalidTeamParent(ChangeTeamParent(parent, 999)))
+	AssertExistsAndLoadBean(t, &Team{ID: 2, ParentID: 0})
+
+	children, err := parent.GetChildTeams()
+	assert.NoError(t, err)
+	if assert.Len(t, children, 1) {
+		assert.EqualValues(t, 12, children[0].ID)
+	}
+
+	candidates, err := GetTeamHierarchyCandidates(3, 2)
+	assert.NoError(t, err)
+	for _, candidate := range candidates {
+		assert.NotContains(t, []int64{1, 2, 12}, candidate.ID)
+	}
+
+	assert.NoError(t, ChangeTeamParent(child, 0))
+	AssertNotExistsBean(t, &Access{RepoID: 3, UserID: 28})
+	CheckConsistencyFor(t, &Team{})
+}
+
+func TestTeamHierarchyMembership(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	child := AssertExistsAndLoadBean(t, &Team{ID: 12}).(*Team)
+	assert.NoError(t, ChangeTeamParent(child, 2))
+
+	// new members of the child team inherit the access of the parent team
+	assert.NoError(t, AddTeamMember(child, 5))
+	AssertExistsAndLoadBean(t, &Access{RepoID: 3, UserID: 5, Mode: AccessModeWrite})
+	assert.NoError(t, RemoveTeamMember(child, 5))
+	AssertNotExistsBean(t, &Access{RepoID: 3, UserID: 5})
+
+	// deleting the parent team makes the child team a top level team
+	parent := AssertExistsAndLoadBean(t, &Team{ID: 2}).(*Team)
+	assert.NoError(t, DeleteTeam(parent))
+	AssertExistsAndLoadBean(t, &Team{ID: 12, ParentID: 0})
+	AssertNotExistsBean(t, &Access{RepoID: 3, UserID: 28})
+}
+
+func TestNewTeamWithParent(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	team := &Team{OrgID: 3, Name: "child", Authorize: AccessModeRead, ParentID: 2}
+	assert.NoError(t, NewTeam(team))
+	AssertExistsAndLoadBean(t, &Team{ID: team.ID, ParentID: 2})
+
+	team = &Team{OrgID: 3, Name: "invalid", Authorize: AccessModeRead, ParentID: 3}
+	assert.True(t, IsErrInvalidTeamParent(NewTeam(team)))
+}
+
+func TestSortTeamsByHierarchy(t *testing.T) {
+	teams := []*Team{
+		{ID: 4, LowerName: "d", ParentID: 2},
+		{ID: 3, LowerName: "c", ParentID: 2},
+		{ID: 2, LowerName: "b"},
+		{ID: 1, LowerName: "owners", Name: ownerTeamName},
+		{ID: 5, LowerName: "a", ParentID: 9},
+		{ID: 6, LowerName: "e", ParentID: 3},
+	}
+	sorted, depths := SortTeamsByHierarchy(teams)
+	ids := make([]int64, 0, len(sorted))
+	for _, t := range sorted {
+		ids = append(ids, t.ID)
+	}
+	assert.EqualValues(t, []int64{1, 5, 2, 3, 6, 4}, ids)
+	assert.EqualValues(t, map[int64]int{1: 0, 2: 0, 3: 1, 4: 1, 5: 0, 6: 2}, depths)
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"sort"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// OrgTopic represents the curation of a topic by an organization,
+// it overrides the global description and logo on the pages of the organization.
+type OrgTopic struct {
+	ID          int64  `xorm:"pk autoincr"`
+	OrgID       int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	TopicID     int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Description string `xorm:"TEXT"`
+	Logo        string `xorm:"VARCHAR(255)"`
+	IsFeatured  bool   `xorm:"NOT NULL DEFAULT false"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+
+	Topic    *Topic `xorm:"-"`
+	NumRepos int    `xorm:"-"`
+}
+
+// DisplayDescription returns the description of the organization, or the global one if it has none
+func (ot *OrgTopic) DisplayDescription() string {
+	if ot.Description == "" && ot.Topic != nil {
+		return ot.Topic.Description
+	}
+	return ot.Description
+}
+
+// DisplayLogo returns the logo of the organization, or the global one if it has none
+func (ot *OrgTopic) DisplayLogo() string {
+	if ot.Logo == "" && ot.Topic != nil {
+		return ot.Topic.Logo
+	}
+	return ot.Logo
+}
+
+// GetOrgTopics returns the topics of the repositories of the organization which the actor
+// can access, featured topics first and then by number of repositories
+func GetOrgTopics(orgID int64, actor *User) ([]*OrgTopic, error) {
+	type topicCount struct {
+		TopicID  int64
+		NumRepos int
+	}
+	counts := make([]*topicCount, 0, 10)
+	if err := x.Table("repo_topic").
+		Select("repo_topic.topic_id AS topic_id, count(*) AS num_repos").
+		Join("INNER", "repository", "repository.id = repo_topic.repo_id").
+		Where(builder.Eq{"repository.owner_id": orgID}.And(accessibleRepositoryCondition(actor))).
+		GroupBy("repo_topic.topic_id").
+		Find(&counts); err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 {
+		return []*OrgTopic{}, nil
+	}
+
+	topicIDs := make([]int64, len(counts))
+	for i := range counts {
+		topicIDs[i] = counts[i].TopicID
+	}
+	topics := make(map[int64]*Topic, len(topicIDs))
+	if err := x.In("id", topicIDs).Find(&topics); err != nil {
+		return nil, err
+	}
+	curated := make(map[int64]*OrgTopic, len(topicIDs))
+	if err := x.Where("org_id = ?", orgID).In("topic_id", topicIDs).Find(&curated); err != nil {
+		return nil, err
+	}
+	curatedByTopic := make(map[int64]*OrgTopic, len(curated))
+	for _, ot := range curated {
+		curatedByTopic[ot.TopicID] = ot
+	}
+
+	orgTopics := make([]*OrgTopic, 0, len(counts))
+	for _, count := range counts {
+		topic, ok := topics[count.TopicID]
+		if !ok {
+			continue
+		}
+		ot, ok := curatedByTopic[count.TopicID]
+		if !ok {
+			ot = &OrgTopic{OrgID: orgID, TopicID: count.TopicID}
+		}
+		ot.Topic = topic
+		ot.NumRepos = count.NumRepos
+		orgTopics = append(orgTopics, ot)
+	}
+
+	sort.SliceStable(orgTopics, func(i, j int) bool {
+		if orgTopics[i].IsFeatured != orgTopics[j].IsFeatured {
+			return orgTopics[i].IsFeatured
+		}
+		if orgTopics[i].NumRepos != orgTopics[j].NumRepos {
+			return orgTopics[i].NumRepos > orgTopics[j].NumRepos
+		}
+		return orgTopics[i].Topic.Name < orgTopics[j].Topic.Name
+	})
+	return orgTopics, nil
+}
+
+// GetOrgFeaturedTopics returns the featured topics of the organization which are used by
+// repositories the actor can access
+func GetOrgFeaturedTopics(orgID int64, actor *User) ([]*OrgTopic, error) {
+	orgTopics, err := GetOrgTopics(orgID, actor)
+	if err != nil {
+		return nil, err
+	}
+	featured := make([]*OrgTopic, 0, len(orgTopics))
+	for _, ot := range orgTopics {
+		if ot.IsFeatured {
+			featured = append(featured, ot)
+		}
+	}
+	return featured, nil
+}
+
+// GetOrgTopic returns the curation of the topic by the organization,
+// it is empty if the organization did not curate the topic.
+func GetOrgTopic(orgID int64, topic *Topic) (*OrgTopic, error) {
+	ot := &OrgTopic{OrgID: orgID, TopicID: topic.ID}
+	if _, err := x.Where("org_id = ? AND topic_id = ?", orgID, topic.ID).Get(ot); err != nil {
+		return nil, err
+	}
+	ot.Topic = topic
+	return ot, nil
+}
+
+// SaveOrgTopic creates or updates the curation of a topic by an organization
+func SaveOrgTopic(ot *OrgTopic) error {
+	if ot.ID == 0 {
+		_, err := x.Insert(ot)
+		return err
+	}
+	_, err := x.ID(ot.ID).Cols("description", "logo", "is_featured").Update(ot)
+	return err
+}
+
+// MergeOrgTopics replaces topic from with topic to on all repositories of the organization
+func MergeOrgTopics(orgID int64, from, to *Topic) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := mergeTopics(sess, from, to,
+		builder.In("repo_id", builder.Select("id").From("repository").Where(builder.Eq{"owner_id": orgID}))); err != nil {
+		return err
+	}
+
+	if _, err := sess.Delete(&OrgTopic{OrgID: orgID, TopicID: from.ID}); err != nil {
+		return err
+	}
+
+	return sess.Commit()
+}
+
+func deleteOrgTopics(e Engine, orgID int64) error {
+	_, err := e.Delete(&OrgTopic{OrgID: orgID})
+	return err
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgTopics(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// repository 3 is private and 32 is public, both are owned by organization 3
+	assert.NoError(t, SaveTopics(3, "golang", "secret"))
+	assert.NoError(t, SaveTopics(32, "golang", "gitea"))
+	owner := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	topics, err := GetOrgTopics(3, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, topics, 2) {
+		assert.Equal(t, "gitea", topics[0].Topic.Name)
+		assert.Equal(t, "golang", topics[1].Topic.Name)
+		assert.EqualValues(t, 1, topics[1].NumRepos)
+	}
+
+	topics, err = GetOrgTopics(3, owner)
+	assert.NoError(t, err)
+	if assert.Len(t, topics, 3) {
+		assert.Equal(t, "golang", topics[0].Topic.Name)
+		assert.EqualValues(t, 2, topics[0].NumRepos)
+	}
+
+	golang, err := GetTopicByName("golang")
+	assert.NoError(t, err)
+	golang.Description = "Global description"
+	assert.NoError(t, UpdateTopic(golang))
+	ot, err := GetOrgTopic(3, golang)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, ot.ID)
+	assert.Equal(t, "Global description", ot.DisplayDescription())
+
+	ot.Description = "Organization description"
+	ot.IsFeatured = true
+	assert.NoError(t, SaveOrgTopic(ot))
+	assert.Equal(t, "Organization description", ot.DisplayDescription())
+
+	featured, err := GetOrgFeaturedTopics(3, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, featured, 1) {
+		assert.Equal(t, "golang", featured[0].Topic.Name)
+		assert.Equal(t, "Organization description", featured[0].Description)
+	}
+}
+
+func TestMergeOrgTopics(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, SaveTopics(32, "go"))
+	from, err := GetTopicByName("go")
+	assert.NoError(t, err)
+	assert.NoError(t, SaveOrgTopic(&OrgTopic{OrgID: 3, TopicID: from.ID, IsFeatured: true}))
+	to, err := GetTopicByName("golang")
+	assert.NoError(t, err)
+
+	assert.NoError(t, MergeOrgTopics(3, from, to))
+	AssertNotExistsBean(t, &RepoTopic{RepoID: 32, TopicID: from.ID})
+	AssertExistsAndLoadBean(t, &RepoTopic{RepoID: 32, TopicID: to.ID})
+	AssertNotExistsBean(t, &OrgTopic{OrgID: 3, TopicID: from.ID})
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 32}).(*Repository)
+	assert.Equal(t, []string{"golang"}, repo.Topics)
+
+	// repositories of other owners keep their topics
+	AssertExistsAndLoadBean(t, &RepoTopic{RepoID: 1, TopicID: to.ID})
+	to = AssertExistsAndLoadBean(t, &Topic{ID: to.ID}).(*Topic)
+	assert.EqualValues(t, 3, to.RepoCount)
+}
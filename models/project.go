@@ -0,0 +1,299 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// Project is a board of an organization tracking issues of all its
+// repositories. Its issues are shown as cards in the columns of the board.
+type Project struct {
+	ID          int64 `xorm:"pk autoincr"`
+	OwnerID     int64 `xorm:"INDEX"`
+	CreatorID   int64
+	Title       string
+	Description string `xorm:"TEXT"`
+	IsClosed    bool   `xorm:"INDEX"`
+	NumIssues   int    `xorm:"-"`
+
+	// Automation moving the cards of the issues, a column ID of 0 disables
+	// the corresponding move
+	InProgressBoardID    int64 `xorm:"NOT NULL DEFAULT 0"`
+	DoneBoardID          int64 `xorm:"NOT NULL DEFAULT 0"`
+	ArchiveDoneAfterDays int   `xorm:"NOT NULL DEFAULT 0"`
+
+	RenderedDescription string `xorm:"-"`
+
+	CreatedUnix    timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX updated"`
+	ClosedDateUnix timeutil.TimeStamp
+}
+
+// ProjectBoard is a column of a project. Issues which are not in any column
+// are shown in the uncategorized column, which has the ID 0.
+type ProjectBoard struct {
+	ID        int64 `xorm:"pk autoincr"`
+	ProjectID int64 `xorm:"INDEX NOT NULL"`
+	Title     string
+	Sorting   int
+
+	Issues []*ProjectIssue `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// ErrProjectNotExist represents a "ProjectNotExist" kind of error.
+type ErrProjectNotExist struct {
+	ID      int64
+	OwnerID int64
+}
+
+// IsErrProjectNotExist checks if an error is a ErrProjectNotExist.
+func IsErrProjectNotExist(err error) bool {
+	_, ok := err.(ErrProjectNotExist)
+	return ok
+}
+
+func (err ErrProjectNotExist) Error() string {
+	return fmt.Sprintf("project does not exist [id: %d, owner_id: %d]", err.ID, err.OwnerID)
+}
+
+// ErrProjectBoardNotExist represents a "ProjectBoardNotExist" kind of error.
+type ErrProjectBoardNotExist struct {
+	ID        int64
+	ProjectID int64
+}
+
+// IsErrProjectBoardNotExist checks if an error is a ErrProjectBoardNotExist.
+func IsErrProjectBoardNotExist(err error) bool {
+	_, ok := err.(ErrProjectBoardNotExist)
+	return ok
+}
+
+func (err ErrProjectBoardNotExist) Error() string {
+	return fmt.Sprintf("project board does not exist [id: %d, project_id: %d]", err.ID, err.ProjectID)
+}
+
+// State returns string representation of project status.
+func (p *Project) State() api.StateType {
+	if p.IsClosed {
+		return api.StateClosed
+	}
+	return api.StateOpen
+}
+
+// NewProject creates a new project.
+func NewProject(p *Project) error {
+	p.Title = strings.TrimSpace(p.Title)
+	_, err := x.Insert(p)
+	return err
+}
+
+// GetProjectByOrgID returns a project of an organization.
+func GetProjectByOrgID(orgID, id int64) (*Project, error) {
+	p := new(Project)
+	has, err := x.ID(id).Where("owner_id = ?", orgID).Get(p)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrProjectNotExist{ID: id, OwnerID: orgID}
+	}
+	return p, nil
+}
+
+// GetProjectsByOrgID returns the projects of an organization, the newest
+// first, with the number of their issues.
+func GetProjectsByOrgID(orgID int64, state api.StateType, listOptions ListOptions) ([]*Project, error) {
+	cond := builder.NewCond().And(builder.Eq{"owner_id": orgID})
+	switch state {
+	case api.StateClosed:
+		cond = cond.And(builder.Eq{"is_closed": true})
+	case api.StateAll:
+	default:
+		cond = cond.And(builder.Eq{"is_closed": false})
+	}
+
+	sess := x.Where(cond)
+	if listOptions.Page != 0 {
+		sess = listOptions.setSessionPagination(sess)
+	}
+	projects := make([]*Project, 0, listOptions.PageSize)
+	if err := sess.Desc("id").Find(&projects); err != nil {
+		return nil, err
+	}
+	if len(projects) == 0 {
+		return projects, nil
+	}
+
+	ids := make([]int64, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID
+	}
+	type projectCount struct {
+		ProjectID int64
+		Count     int
+	}
+	counts := make([]*projectCount, 0, len(projects))
+	if err := x.Table("project_issue").
+		Select("project_id, count(*) AS count").
+		In("project_id", ids).
+		GroupBy("project_id").
+		Find(&counts); err != nil {
+		return nil, err
+	}
+	numIssues := make(map[int64]int, len(counts))
+	for _, c := range counts {
+		numIssues[c.ProjectID] = c.Count
+	}
+	for _, p := range projects {
+		p.NumIssues = numIssues[p.ID]
+	}
+	return projects, nil
+}
+
+// CountProjectsByOrgID returns the number of open and closed projects of an
+// organization.
+func CountProjectsByOrgID(orgID int64) (open, closed int64, err error) {
+	if open, err = x.Where("owner_id = ? AND is_closed = ?", orgID, false).Count(new(Project)); err != nil {
+		return 0, 0, err
+	}
+	closed, err = x.Where("owner_id = ? AND is_closed = ?", orgID, true).Count(new(Project))
+	return open, closed, err
+}
+
+// UpdateProject updates information of the given project.
+func UpdateProject(p *Project) error {
+	p.Title = strings.TrimSpace(p.Title)
+	if p.IsClosed && p.ClosedDateUnix == 0 {
+		p.ClosedDateUnix = timeutil.TimeStampNow()
+	} else if !p.IsClosed {
+		p.ClosedDateUnix = 0
+	}
+	if p.ArchiveDoneAfterDays < 0 {
+		p.ArchiveDoneAfterDays = 0
+	}
+	if err := checkProjectBoard(x, p, p.InProgressBoardID); err != nil {
+		return err
+	}
+	if err := checkProjectBoard(x, p, p.DoneBoardID); err != nil {
+		return err
+	}
+	_, err := x.ID(p.ID).Cols("title", "description", "is_closed", "closed_date_unix",
+		"in_progress_board_id", "done_board_id", "archive_done_after_days").Update(p)
+	return err
+}
+
+func deleteProjects(e Engine, cond builder.Cond) error {
+	ids := builder.Select("id").From("project").Where(cond)
+	if _, err := e.In("project_id", ids).Delete(new(ProjectIssue)); err != nil {
+		return err
+	}
+	if _, err := e.In("project_id", ids).Delete(new(ProjectRule)); err != nil {
+		return err
+	}
+	if _, err := e.In("project_id", ids).Delete(new(ProjectBoard)); err != nil {
+		return err
+	}
+	_, err := e.Where(cond).Delete(new(Project))
+	return err
+}
+
+// DeleteProject deletes a project with its boards and rules. The issues are
+// kept.
+func DeleteProject(p *Project) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if err := deleteProjects(sess, builder.Eq{"id": p.ID}); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// NewProjectBoard adds a column at the end of the project.
+func NewProjectBoard(board *ProjectBoard) error {
+	board.Title = strings.TrimSpace(board.Title)
+
+	var maxSorting int
+	if _, err := x.Table("project_board").
+		Select("MAX(sorting)").
+		Where("project_id = ?", board.ProjectID).
+		Get(&maxSorting); err != nil {
+		return err
+	}
+	board.Sorting = maxSorting + 1
+	_, err := x.Insert(board)
+	return err
+}
+
+func getProjectBoard(e Engine, projectID, id int64) (*ProjectBoard, error) {
+	board := new(ProjectBoard)
+	has, err := e.ID(id).Where("project_id = ?", projectID).Get(board)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrProjectBoardNotExist{ID: id, ProjectID: projectID}
+	}
+	return board, nil
+}
+
+// GetProjectBoard returns a column of the project.
+func GetProjectBoard(projectID, id int64) (*ProjectBoard, error) {
+	return getProjectBoard(x, projectID, id)
+}
+
+// GetProjectBoards returns the columns of the project in their order.
+func GetProjectBoards(projectID int64) ([]*ProjectBoard, error) {
+	boards := make([]*ProjectBoard, 0, 5)
+	return boards, x.Where("project_id = ?", projectID).
+		Asc("sorting").
+		Asc("id").
+		Find(&boards)
+}
+
+// UpdateProjectBoard updates the title of the column.
+func UpdateProjectBoard(board *ProjectBoard) error {
+	board.Title = strings.TrimSpace(board.Title)
+	_, err := x.ID(board.ID).Cols("title").Update(board)
+	return err
+}
+
+// DeleteProjectBoard deletes a column. Its issues and the rules adding issues
+// to it are moved to the uncategorized column, the automation moving issues
+// to it is disabled.
+func DeleteProjectBoard(board *ProjectBoard) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Exec("UPDATE `project_issue` SET project_board_id = 0 WHERE project_id = ? AND project_board_id = ?", board.ProjectID, board.ID); err != nil {
+		return err
+	}
+	if _, err := sess.Exec("UPDATE `project_rule` SET project_board_id = 0 WHERE project_id = ? AND project_board_id = ?", board.ProjectID, board.ID); err != nil {
+		return err
+	}
+	if _, err := sess.Exec("UPDATE `project` SET in_progress_board_id = 0 WHERE id = ? AND in_progress_board_id = ?", board.ProjectID, board.ID); err != nil {
+		return err
+	}
+	if _, err := sess.Exec("UPDATE `project` SET done_board_id = 0 WHERE id = ? AND done_board_id = ?", board.ProjectID, board.ID); err != nil {
+		return err
+	}
+	if _, err := sess.ID(board.ID).Delete(new(ProjectBoard)); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
@@ -0,0 +1,129 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/modules/references"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// ProjectAutomationTarget is the column of a project the automation moves
+// the cards of issues to.
+type ProjectAutomationTarget int
+
+const (
+	// ProjectAutomationInProgress moves the cards to the "In Progress" column
+	ProjectAutomationInProgress ProjectAutomationTarget = iota
+	// ProjectAutomationDone moves the cards to the "Done" column
+	ProjectAutomationDone
+)
+
+// boardColumn returns the column of the project storing the column the cards
+// are moved to.
+func (target ProjectAutomationTarget) boardColumn() string {
+	if target == ProjectAutomationDone {
+		return "project.done_board_id"
+	}
+	return "project.in_progress_board_id"
+}
+
+// GetPullRequestLinkedIssueIDs returns the IDs of the issues the pull
+// request closes when it is merged.
+func GetPullRequestLinkedIssueIDs(pr *PullRequest) ([]int64, error) {
+	if err := pr.LoadIssue(); err != nil {
+		return nil, err
+	}
+	refs, err := pr.ResolveCrossReferences()
+	if err != nil {
+		return nil, err
+	}
+	issueIDs := make([]int64, 0, len(refs))
+	for _, ref := range refs {
+		if ref.RefAction == references.XRefActionCloses {
+			issueIDs = append(issueIDs, ref.IssueID)
+		}
+	}
+	return issueIDs, nil
+}
+
+// MoveProjectIssuesByAutomation moves the cards of the issues in the open
+// projects which automate the target column to that column.
+func MoveProjectIssuesByAutomation(issueIDs []int64, target ProjectAutomationTarget) error {
+	if len(issueIDs) == 0 {
+		return nil
+	}
+
+	cards := make([]*ProjectIssue, 0, len(issueIDs))
+	if err := x.Join("INNER", "project", "project.id = project_issue.project_id").
+		Where(builder.Eq{"project.is_closed": false}).
+		And(builder.Gt{target.boardColumn(): 0}).
+		In("project_issue.issue_id", issueIDs).
+		Find(&cards); err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		return nil
+	}
+
+	projectIDs := make([]int64, 0, len(cards))
+	for _, card := range cards {
+		projectIDs = append(projectIDs, card.ProjectID)
+	}
+	projects := make(map[int64]*Project, len(projectIDs))
+	if err := x.In("id", projectIDs).Find(&projects); err != nil {
+		return err
+	}
+
+	for _, card := range cards {
+		p := projects[card.ProjectID]
+		if p == nil {
+			continue
+		}
+		boardID := p.InProgressBoardID
+		if target == ProjectAutomationDone {
+			boardID = p.DoneBoardID
+		}
+		if card.ProjectBoardID == boardID && !card.IsArchived {
+			continue
+		}
+		if err := moveProjectIssue(x, p, card.IssueID, boardID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ArchiveDoneProjectIssues archives the cards which have been in the "Done"
+// column of their open project for longer than the project allows.
+func ArchiveDoneProjectIssues(ctx context.Context) error {
+	projects := make([]*Project, 0, 10)
+	if err := x.Where("is_closed = ?", false).
+		And("done_board_id > 0").
+		And("archive_done_after_days > 0").
+		Find(&projects); err != nil {
+		return err
+	}
+
+	for _, p := range projects {
+		select {
+		case <-ctx.Done():
+			return ErrCancelledf("before archiving the done issues of project %d", p.ID)
+		default:
+		}
+		olderThan := timeutil.TimeStampNow() - timeutil.TimeStamp(p.ArchiveDoneAfterDays*24*60*60)
+		if _, err := x.Where("project_id = ? AND project_board_id = ?", p.ID, p.DoneBoardID).
+			And("is_archived = ?", false).
+			And("moved_unix < ?", olderThan).
+			Cols("is_archived").
+			Update(&ProjectIssue{IsArchived: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
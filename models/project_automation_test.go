@@ -0,0 +1,60 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveProjectIssuesByAutomation(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	p := AssertExistsAndLoadBean(t, &Project{ID: 1}).(*Project)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue)
+	assert.NoError(t, AddProjectIssue(p, 0, issue))
+
+	// projects without automation do not move the cards
+	assert.NoError(t, MoveProjectIssuesByAutomation([]int64{issue.ID}, ProjectAutomationDone))
+	AssertExistsAndLoadBean(t, &ProjectIssue{ProjectID: 1, IssueID: issue.ID, ProjectBoardID: 0})
+
+	p.DoneBoardID = 99
+	assert.True(t, IsErrProjectBoardNotExist(UpdateProject(p)))
+
+	p.InProgressBoardID = 1
+	p.DoneBoardID = 2
+	p.ArchiveDoneAfterDays = 7
+	assert.NoError(t, UpdateProject(p))
+
+	assert.NoError(t, MoveProjectIssuesByAutomation([]int64{issue.ID}, ProjectAutomationInProgress))
+	AssertExistsAndLoadBean(t, &ProjectIssue{ProjectID: 1, IssueID: issue.ID, ProjectBoardID: 1})
+	assert.NoError(t, MoveProjectIssuesByAutomation([]int64{issue.ID}, ProjectAutomationDone))
+	AssertExistsAndLoadBean(t, &ProjectIssue{ProjectID: 1, IssueID: issue.ID, ProjectBoardID: 2})
+
+	// only the cards done for longer than the project allows are archived
+	assert.NoError(t, ArchiveDoneProjectIssues(context.Background()))
+	AssertExistsAndLoadBean(t, &ProjectIssue{ProjectID: 1, IssueID: issue.ID}, Cond("is_archived = ?", false))
+
+	_, err := x.Where("project_id = ? AND issue_id = ?", 1, issue.ID).
+		Cols("moved_unix").
+		Update(&ProjectIssue{MovedUnix: timeutil.TimeStampNow() - 8*24*60*60})
+	assert.NoError(t, err)
+	assert.NoError(t, ArchiveDoneProjectIssues(context.Background()))
+	AssertExistsAndLoadBean(t, &ProjectIssue{ProjectID: 1, IssueID: issue.ID}, Cond("is_archived = ?", true))
+
+	cards, err := GetProjectIssues(p, 0, &User{IsAdmin: true})
+	assert.NoError(t, err)
+	assert.Len(t, cards, 0)
+
+	// deleting the column disables the automation
+	assert.NoError(t, DeleteProjectBoard(&ProjectBoard{ID: 2, ProjectID: 1}))
+	p = AssertExistsAndLoadBean(t, &Project{ID: 1}).(*Project)
+	assert.EqualValues(t, 0, p.DoneBoardID)
+	assert.EqualValues(t, 1, p.InProgressBoardID)
+}
@@ -0,0 +1,455 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// ProjectIssue is the card of an issue in a column of a project
+type ProjectIssue struct {
+	ID             int64 `xorm:"pk autoincr"`
+	ProjectID      int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	IssueID        int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	ProjectBoardID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	// IsArchived hides the card from the board, moving it shows it again
+	IsArchived bool `xorm:"INDEX NOT NULL DEFAULT false"`
+
+	Issue *Issue `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	MovedUnix   timeutil.TimeStamp
+}
+
+// ProjectIssueQuery selects issues of the repositories of an organization
+// to be added to a project. Empty fields match every issue.
+type ProjectIssueQuery struct {
+	RepoID int64
+	// Label is the name of a label of the issue, repository and organization
+	// labels with the same name match alike
+	Label   string
+	Keyword string
+	State   api.StateType
+}
+
+// IsEmpty returns true if the query would match every issue.
+func (q *ProjectIssueQuery) IsEmpty() bool {
+	return q.RepoID == 0 && len(strings.TrimSpace(q.Label)) == 0 && len(strings.TrimSpace(q.Keyword)) == 0
+}
+
+// ProjectRule adds the issues matching its query to a column of the project
+// when they are created or their labels or title are changed.
+type ProjectRule struct {
+	ID             int64 `xorm:"pk autoincr"`
+	ProjectID      int64 `xorm:"INDEX NOT NULL"`
+	ProjectBoardID int64 `xorm:"NOT NULL DEFAULT 0"`
+	RepoID         int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Label          string
+	Keyword        string
+
+	Repo *Repository `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// Query returns the query of the issues the rule adds.
+func (rule *ProjectRule) Query() *ProjectIssueQuery {
+	return &ProjectIssueQuery{
+		RepoID:  rule.RepoID,
+		Label:   rule.Label,
+		Keyword: rule.Keyword,
+		State:   api.StateOpen,
+	}
+}
+
+// ErrProjectQueryEmpty represents a query or rule that would match every
+// issue of an organization.
+type ErrProjectQueryEmpty struct{}
+
+// IsErrProjectQueryEmpty checks if an error is a ErrProjectQueryEmpty.
+func IsErrProjectQueryEmpty(err error) bool {
+	_, ok := err.(ErrProjectQueryEmpty)
+	return ok
+}
+
+func (err ErrProjectQueryEmpty) Error() string {
+	return "project query must select a repository, a label or a keyword"
+}
+
+// ErrProjectIssueOutOfScope represents an attempt to add an issue of a
+// repository which is not owned by the organization of the project.
+type ErrProjectIssueOutOfScope struct {
+	ProjectID int64
+	IssueID   int64
+	RepoID    int64
+}
+
+// IsErrProjectIssueOutOfScope checks if an error is a ErrProjectIssueOutOfScope.
+func IsErrProjectIssueOutOfScope(err error) bool {
+	_, ok := err.(ErrProjectIssueOutOfScope)
+	return ok
+}
+
+func (err ErrProjectIssueOutOfScope) Error() string {
+	return fmt.Sprintf("issue or repository cannot be used in project [project_id: %d, issue_id: %d, repo_id: %d]", err.ProjectID, err.IssueID, err.RepoID)
+}
+
+// ErrProjectIssueNotExist represents a "ProjectIssueNotExist" kind of error.
+type ErrProjectIssueNotExist struct {
+	ProjectID int64
+	IssueID   int64
+}
+
+// IsErrProjectIssueNotExist checks if an error is a ErrProjectIssueNotExist.
+func IsErrProjectIssueNotExist(err error) bool {
+	_, ok := err.(ErrProjectIssueNotExist)
+	return ok
+}
+
+func (err ErrProjectIssueNotExist) Error() string {
+	return fmt.Sprintf("issue is not in project [project_id: %d, issue_id: %d]", err.ProjectID, err.IssueID)
+}
+
+// checkProjectRepo returns an error if the repository is not owned by the
+// organization of the project.
+func checkProjectRepo(e Engine, p *Project, repoID int64) error {
+	if repoID == 0 {
+		return nil
+	}
+	repo, err := getRepositoryByID(e, repoID)
+	if err != nil {
+		return err
+	}
+	if repo.OwnerID != p.OwnerID {
+		return ErrProjectIssueOutOfScope{ProjectID: p.ID, RepoID: repoID}
+	}
+	return nil
+}
+
+// checkProjectBoard returns an error if the column is not part of the
+// project, 0 being the uncategorized column.
+func checkProjectBoard(e Engine, p *Project, boardID int64) error {
+	if boardID == 0 {
+		return nil
+	}
+	_, err := getProjectBoard(e, p.ID, boardID)
+	return err
+}
+
+// projectIssueQueryCond returns the condition of the issues of the
+// organization matching the query which are not in the project yet.
+func projectIssueQueryCond(p *Project, q *ProjectIssueQuery) builder.Cond {
+	cond := builder.NewCond().And(
+		builder.Eq{"issue.is_pull": false},
+		builder.In("issue.repo_id", builder.Select("id").From("repository").Where(builder.Eq{"owner_id": p.OwnerID})),
+		builder.NotIn("issue.id", builder.Select("issue_id").From("project_issue").Where(builder.Eq{"project_id": p.ID})),
+	)
+	if q.RepoID > 0 {
+		cond = cond.And(builder.Eq{"issue.repo_id": q.RepoID})
+	}
+	if label := strings.ToLower(strings.TrimSpace(q.Label)); len(label) > 0 {
+		cond = cond.And(builder.In("issue.id",
+			builder.Select("issue_label.issue_id").
+				From("issue_label").
+				Join("INNER", "label", "label.id = issue_label.label_id").
+				Where(builder.Expr("LOWER(label.name) = ?", label))))
+	}
+	if keyword := strings.TrimSpace(q.Keyword); len(keyword) > 0 {
+		cond = cond.And(builder.Like{"issue.name", keyword})
+	}
+	switch q.State {
+	case api.StateClosed:
+		cond = cond.And(builder.Eq{"issue.is_closed": true})
+	case api.StateAll:
+	default:
+		cond = cond.And(builder.Eq{"issue.is_closed": false})
+	}
+	return cond
+}
+
+func addProjectIssues(e Engine, p *Project, boardID int64, issueIDs []int64) error {
+	if len(issueIDs) == 0 {
+		return nil
+	}
+	now := timeutil.TimeStampNow()
+	cards := make([]*ProjectIssue, len(issueIDs))
+	for i, id := range issueIDs {
+		cards[i] = &ProjectIssue{
+			ProjectID:      p.ID,
+			IssueID:        id,
+			ProjectBoardID: boardID,
+			MovedUnix:      now,
+		}
+	}
+	_, err := e.Insert(&cards)
+	return err
+}
+
+// AddProjectIssuesByQuery adds the issues matching the query, which the doer
+// can see, to a column of the project. The added issues are returned.
+func AddProjectIssuesByQuery(p *Project, boardID int64, q *ProjectIssueQuery, doer *User) (IssueList, error) {
+	if q.IsEmpty() {
+		return nil, ErrProjectQueryEmpty{}
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if err := checkProjectRepo(sess, p, q.RepoID); err != nil {
+		return nil, err
+	}
+	if err := checkProjectBoard(sess, p, boardID); err != nil {
+		return nil, err
+	}
+
+	cond := projectIssueQueryCond(p, q)
+	if doer == nil || !doer.IsAdmin {
+		cond = cond.And(builder.In("issue.repo_id", AccessibleRepoIDsQuery(doer)))
+	}
+	issues := make(IssueList, 0, 10)
+	if err := sess.Where(cond).
+		Asc("issue.repo_id").
+		Asc("issue.`index`").
+		Find(&issues); err != nil {
+		return nil, err
+	}
+	if err := addProjectIssues(sess, p, boardID, issues.getIssueIDs()); err != nil {
+		return nil, err
+	}
+	if err := sess.Commit(); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// AddProjectIssue adds an issue of a repository of the organization to a
+// column of the project, or moves it there if the issue is in the project
+// already.
+func AddProjectIssue(p *Project, boardID int64, issue *Issue) error {
+	if err := issue.LoadRepo(); err != nil {
+		return err
+	}
+	if issue.Repo.OwnerID != p.OwnerID {
+		return ErrProjectIssueOutOfScope{ProjectID: p.ID, IssueID: issue.ID, RepoID: issue.RepoID}
+	}
+	if err := checkProjectBoard(x, p, boardID); err != nil {
+		return err
+	}
+
+	has, err := x.Exist(&ProjectIssue{ProjectID: p.ID, IssueID: issue.ID})
+	if err != nil {
+		return err
+	} else if has {
+		return moveProjectIssue(x, p, issue.ID, boardID)
+	}
+	return addProjectIssues(x, p, boardID, []int64{issue.ID})
+}
+
+func moveProjectIssue(e Engine, p *Project, issueID, boardID int64) error {
+	_, err := e.Where("project_id = ? AND issue_id = ?", p.ID, issueID).
+		Cols("project_board_id", "is_archived", "moved_unix").
+		Update(&ProjectIssue{ProjectBoardID: boardID, MovedUnix: timeutil.TimeStampNow()})
+	return err
+}
+
+// MoveProjectIssue moves an issue of the project to another column.
+func MoveProjectIssue(p *Project, issueID, boardID int64) error {
+	has, err := x.Exist(&ProjectIssue{ProjectID: p.ID, IssueID: issueID})
+	if err != nil {
+		return err
+	} else if !has {
+		return ErrProjectIssueNotExist{ProjectID: p.ID, IssueID: issueID}
+	}
+	if err := checkProjectBoard(x, p, boardID); err != nil {
+		return err
+	}
+	return moveProjectIssue(x, p, issueID, boardID)
+}
+
+// RemoveProjectIssue removes an issue from the project.
+func RemoveProjectIssue(p *Project, issueID int64) error {
+	n, err := x.Where("project_id = ? AND issue_id = ?", p.ID, issueID).Delete(new(ProjectIssue))
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrProjectIssueNotExist{ProjectID: p.ID, IssueID: issueID}
+	}
+	return nil
+}
+
+// GetProjectIssues returns the cards of the project the doer is allowed to
+// see with their issues and repositories loaded, archived cards are left
+// out. A repoID other than 0 only returns the issues of that repository.
+func GetProjectIssues(p *Project, repoID int64, doer *User) ([]*ProjectIssue, error) {
+	cond := builder.NewCond().And(builder.Eq{"project_issue.project_id": p.ID, "project_issue.is_archived": false})
+	if repoID > 0 {
+		cond = cond.And(builder.Eq{"issue.repo_id": repoID})
+	}
+	if doer == nil || !doer.IsAdmin {
+		cond = cond.And(builder.In("issue.repo_id", AccessibleRepoIDsQuery(doer)))
+	}
+
+	cards := make([]*ProjectIssue, 0, 10)
+	if err := x.Join("INNER", "issue", "issue.id = project_issue.issue_id").
+		Where(cond).
+		Asc("project_issue.id").
+		Find(&cards); err != nil {
+		return nil, err
+	}
+	if len(cards) == 0 {
+		return cards, nil
+	}
+
+	issueIDs := make([]int64, len(cards))
+	for i, card := range cards {
+		issueIDs[i] = card.IssueID
+	}
+	issues := make(IssueList, 0, len(cards))
+	if err := x.In("id", issueIDs).Find(&issues); err != nil {
+		return nil, err
+	}
+	if _, err := issues.LoadRepositories(); err != nil {
+		return nil, err
+	}
+	if err := issues.loadLabels(x); err != nil {
+		return nil, err
+	}
+	issuesByID := make(map[int64]*Issue, len(issues))
+	for _, issue := range issues {
+		issuesByID[issue.ID] = issue
+	}
+	for _, card := range cards {
+		card.Issue = issuesByID[card.IssueID]
+	}
+	return cards, nil
+}
+
+// GetProjectRepositories returns the repositories of the issues of the
+// project the doer is allowed to see.
+func GetProjectRepositories(p *Project, doer *User) (RepositoryList, error) {
+	cond := builder.NewCond().And(builder.In("id",
+		builder.Select("issue.repo_id").
+			From("issue").
+			Join("INNER", "project_issue", "project_issue.issue_id = issue.id").
+			Where(builder.Eq{"project_issue.project_id": p.ID})))
+	if doer == nil || !doer.IsAdmin {
+		cond = cond.And(builder.In("id", AccessibleRepoIDsQuery(doer)))
+	}
+	repos := make(RepositoryList, 0, 5)
+	return repos, x.Where(cond).Asc("lower_name").Find(&repos)
+}
+
+// GetProjectOwnerRepositories returns the repositories of the organization
+// of the project the doer is allowed to see, which issues can be added from.
+func GetProjectOwnerRepositories(p *Project, doer *User) (RepositoryList, error) {
+	cond := builder.NewCond().And(builder.Eq{"owner_id": p.OwnerID})
+	if doer == nil || !doer.IsAdmin {
+		cond = cond.And(builder.In("id", AccessibleRepoIDsQuery(doer)))
+	}
+	repos := make(RepositoryList, 0, 10)
+	return repos, x.Where(cond).Asc("lower_name").Find(&repos)
+}
+
+// NewProjectRule adds a rule to the project.
+func NewProjectRule(p *Project, rule *ProjectRule) error {
+	rule.Label = strings.TrimSpace(rule.Label)
+	rule.Keyword = strings.TrimSpace(rule.Keyword)
+	if rule.Query().IsEmpty() {
+		return ErrProjectQueryEmpty{}
+	}
+	if err := checkProjectRepo(x, p, rule.RepoID); err != nil {
+		return err
+	}
+	if err := checkProjectBoard(x, p, rule.ProjectBoardID); err != nil {
+		return err
+	}
+	rule.ProjectID = p.ID
+	_, err := x.Insert(rule)
+	return err
+}
+
+// GetProjectRules returns the rules of the project with their repositories.
+func GetProjectRules(projectID int64) ([]*ProjectRule, error) {
+	rules := make([]*ProjectRule, 0, 5)
+	if err := x.Where("project_id = ?", projectID).Asc("id").Find(&rules); err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.RepoID == 0 {
+			continue
+		}
+		repo, err := GetRepositoryByID(rule.RepoID)
+		if err != nil && !IsErrRepoNotExist(err) {
+			return nil, err
+		}
+		rule.Repo = repo
+	}
+	return rules, nil
+}
+
+// GetProjectRule returns a rule of the project.
+func GetProjectRule(projectID, id int64) (*ProjectRule, error) {
+	rule := new(ProjectRule)
+	has, err := x.ID(id).Where("project_id = ?", projectID).Get(rule)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrNotExist{id}
+	}
+	return rule, nil
+}
+
+// DeleteProjectRule deletes a rule of the project, the issues it added are
+// kept.
+func DeleteProjectRule(rule *ProjectRule) error {
+	_, err := x.ID(rule.ID).Delete(new(ProjectRule))
+	return err
+}
+
+// ApplyProjectRules adds the issue to the open projects of the organization
+// owning its repository which have a matching rule. The first matching rule
+// of a project decides the column.
+func ApplyProjectRules(issue *Issue) error {
+	if issue.IsPull {
+		return nil
+	}
+	if err := issue.LoadRepo(); err != nil {
+		return err
+	}
+
+	rules := make([]*ProjectRule, 0, 5)
+	if err := x.Join("INNER", "project", "project.id = project_rule.project_id").
+		Where(builder.Eq{"project.owner_id": issue.Repo.OwnerID, "project.is_closed": false}).
+		And(builder.Eq{"project_rule.repo_id": 0}.Or(builder.Eq{"project_rule.repo_id": issue.RepoID})).
+		Asc("project_rule.id").
+		Find(&rules); err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		p := &Project{ID: rule.ProjectID, OwnerID: issue.Repo.OwnerID}
+		has, err := x.Where(projectIssueQueryCond(p, rule.Query())).
+			And("issue.id = ?", issue.ID).
+			Exist(new(Issue))
+		if err != nil {
+			return err
+		} else if !has {
+			continue
+		}
+		if err := addProjectIssues(x, p, rule.ProjectBoardID, []int64{issue.ID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProjectsByOrgID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	projects, err := GetProjectsByOrgID(3, api.StateOpen, ListOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, projects, 1) {
+		assert.EqualValues(t, "Roadmap", projects[0].Title)
+	}
+
+	open, closed, err := CountProjectsByOrgID(3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, open)
+	assert.EqualValues(t, 1, closed)
+
+	_, err = GetProjectByOrgID(2, 1)
+	assert.True(t, IsErrProjectNotExist(err))
+}
+
+func TestProjectBoards(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	board := &ProjectBoard{ProjectID: 1, Title: " In Progress "}
+	assert.NoError(t, NewProjectBoard(board))
+	assert.EqualValues(t, "In Progress", board.Title)
+	assert.EqualValues(t, 3, board.Sorting)
+
+	boards, err := GetProjectBoards(1)
+	assert.NoError(t, err)
+	assert.Len(t, boards, 3)
+
+	_, err = GetProjectBoard(2, board.ID)
+	assert.True(t, IsErrProjectBoardNotExist(err))
+}
+
+func TestAddProjectIssuesByQuery(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	p := AssertExistsAndLoadBean(t, &Project{ID: 1}).(*Project)
+
+	_, err := AddProjectIssuesByQuery(p, 1, &ProjectIssueQuery{}, doer)
+	assert.True(t, IsErrProjectQueryEmpty(err))
+	_, err = AddProjectIssuesByQuery(p, 1, &ProjectIssueQuery{RepoID: 1}, doer)
+	assert.True(t, IsErrProjectIssueOutOfScope(err))
+	_, err = AddProjectIssuesByQuery(p, 3, &ProjectIssueQuery{RepoID: 3}, doer)
+	assert.True(t, IsErrProjectBoardNotExist(err))
+
+	issues, err := AddProjectIssuesByQuery(p, 1, &ProjectIssueQuery{Keyword: "issue"}, doer)
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 1) {
+		assert.EqualValues(t, 6, issues[0].ID)
+	}
+	AssertExistsAndLoadBean(t, &ProjectIssue{ProjectID: 1, IssueID: 6, ProjectBoardID: 1})
+
+	// issues already in the project are skipped
+	issues, err = AddProjectIssuesByQuery(p, 2, &ProjectIssueQuery{RepoID: 3}, doer)
+	assert.NoError(t, err)
+	assert.Len(t, issues, 0)
+
+	// issues of private repositories are only added by users who can see them
+	p2 := AssertExistsAndLoadBean(t, &Project{ID: 2}).(*Project)
+	issues, err = AddProjectIssuesByQuery(p2, 0, &ProjectIssueQuery{RepoID: 3}, AssertExistsAndLoadBean(t, &User{ID: 5}).(*User))
+	assert.NoError(t, err)
+	assert.Len(t, issues, 0)
+}
+
+func TestProjectIssues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	p := AssertExistsAndLoadBean(t, &Project{ID: 1}).(*Project)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue)
+
+	assert.True(t, IsErrProjectIssueOutOfScope(AddProjectIssue(p, 0, AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue))))
+	assert.NoError(t, AddProjectIssue(p, 1, issue))
+	assert.NoError(t, MoveProjectIssue(p, issue.ID, 2))
+	AssertExistsAndLoadBean(t, &ProjectIssue{ProjectID: 1, IssueID: 6, ProjectBoardID: 2})
+	assert.True(t, IsErrProjectIssueNotExist(MoveProjectIssue(p, 1, 2)))
+
+	cards, err := GetProjectIssues(p, 0, doer)
+	assert.NoError(t, err)
+	if assert.Len(t, cards, 1) {
+		assert.EqualValues(t, "issue6", cards[0].Issue.Title)
+		assert.EqualValues(t, "repo3", cards[0].Issue.Repo.Name)
+	}
+	cards, err = GetProjectIssues(p, 5, doer)
+	assert.NoError(t, err)
+	assert.Len(t, cards, 0)
+	cards, err = GetProjectIssues(p, 0, nil)
+	assert.NoError(t, err)
+	assert.Len(t, cards, 0)
+
+	repos, err := GetProjectRepositories(p, doer)
+	assert.NoError(t, err)
+	assert.Len(t, repos, 1)
+
+	// the cards of a deleted column are kept
+	assert.NoError(t, DeleteProjectBoard(AssertExistsAndLoadBean(t, &ProjectBoard{ID: 2}).(*ProjectBoard)))
+	AssertExistsAndLoadBean(t, &ProjectIssue{ProjectID: 1, IssueID: 6, ProjectBoardID: 0})
+
+	assert.NoError(t, RemoveProjectIssue(p, issue.ID))
+	assert.True(t, IsErrProjectIssueNotExist(RemoveProjectIssue(p, issue.ID)))
+
+	assert.NoError(t, AddProjectIssue(p, 1, issue))
+	assert.NoError(t, DeleteProject(p))
+	AssertNotExistsBean(t, &ProjectIssue{ProjectID: 1})
+	AssertNotExistsBean(t, &ProjectBoard{ProjectID: 1})
+	AssertNotExistsBean(t, &ProjectRule{ProjectID: 1})
+}
+
+func TestApplyProjectRules(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	p := AssertExistsAndLoadBean(t, &Project{ID: 1}).(*Project)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue)
+
+	assert.True(t, IsErrProjectQueryEmpty(NewProjectRule(p, &ProjectRule{Label: " "})))
+	assert.True(t, IsErrProjectIssueOutOfScope(NewProjectRule(p, &ProjectRule{RepoID: 1})))
+
+	assert.NoError(t, ApplyProjectRules(issue))
+	AssertNotExistsBean(t, &ProjectIssue{IssueID: 6})
+
+	label := AssertExistsAndLoadBean(t, &Label{ID: 4}).(*Label)
+	assert.NoError(t, NewIssueLabel(issue, label, doer))
+	assert.NoError(t, ApplyProjectRules(issue))
+	AssertExistsAndLoadBean(t, &ProjectIssue{ProjectID: 1, IssueID: 6, ProjectBoardID: 1})
+	// rules of closed projects are ignored
+	AssertNotExistsBean(t, &ProjectIssue{ProjectID: 2})
+
+	rules, err := GetProjectRules(1)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 1)
+	assert.NoError(t, DeleteProjectRule(rules[0]))
+	AssertNotExistsBean(t, &ProjectRule{ID: 1})
+}
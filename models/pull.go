@@ -44,6 +44,9 @@ type PullRequest struct {
 	ConflictedFiles []string `xorm:"TEXT JSON"`
 	CommitsAhead    int
 	CommitsBehind   int
+	// NumChangedFiles and NumChangedLines exclude vendored and generated files
+	NumChangedFiles int `xorm:"NOT NULL DEFAULT 0"`
+	NumChangedLines int `xorm:"NOT NULL DEFAULT 0"`
 
 	IssueID int64  `xorm:"INDEX"`
 	Issue   *Issue `xorm:"-"`
@@ -189,7 +192,7 @@ func (pr *PullRequest) loadProtectedBranch(e Engine) (err error) {
 				return
 			}
 		}
-		pr.ProtectedBranch, err = getProtectedBranchBy(e, pr.BaseRepo.ID, pr.BaseBranch)
+		pr.ProtectedBranch, err = getEffectiveProtectedBranch(e, pr.BaseRepo, pr.BaseBranch, 0)
 	}
 	return
 }
@@ -341,8 +344,29 @@ const (
 	MergeStyleRebaseMerge MergeStyle = "rebase-merge"
 	// MergeStyleSquash squash commits into single commit before merging
 	MergeStyleSquash MergeStyle = "squash"
+	// MergeStyleFastForwardOnly fast-forward the base branch to the head, without creating commits
+	MergeStyleFastForwardOnly MergeStyle = "fast-forward-only"
 )
 
+// MergeStyles are all the merge styles, in the order they are offered
+var MergeStyles = []MergeStyle{
+	MergeStyleMerge,
+	MergeStyleRebase,
+	MergeStyleRebaseMerge,
+	MergeStyleSquash,
+	MergeStyleFastForwardOnly,
+}
+
+// IsValidMergeStyle returns if the merge style exists
+func IsValidMergeStyle(style string) bool {
+	for _, s := range MergeStyles {
+		if string(s) == style {
+			return true
+		}
+	}
+	return false
+}
+
 // SetMerged sets a pull request to merged and closes the corresponding issue
 func (pr *PullRequest) SetMerged() (bool, error) {
 	if pr.HasMerged {
@@ -439,7 +463,7 @@ func newPullRequestAttempt(repo *Repository, pull *Issue, labelIDs []int64, uuid
 		Attachments: uuids,
 		IsPull:      true,
 	}); err != nil {
-		if IsErrUserDoesNotHaveAccessToRepo(err) || IsErrNewIssueInsert(err) {
+		if IsErrUserDoesNotHaveAccessToRepo(err) || IsErrNewIssueInsert(err) || IsErrIssueAttachmentsTooLarge(err) {
 			return err
 		}
 		return fmt.Errorf("newIssue: %v", err)
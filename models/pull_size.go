@@ -0,0 +1,51 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+// PullRequestSize represents the size classification of a pull request
+type PullRequestSize string
+
+// Enumerate all the pull request sizes
+const (
+	PullRequestSizeXS  PullRequestSize = "XS"
+	PullRequestSizeS   PullRequestSize = "S"
+	PullRequestSizeM   PullRequestSize = "M"
+	PullRequestSizeL   PullRequestSize = "L"
+	PullRequestSizeXL  PullRequestSize = "XL"
+	PullRequestSizeXXL PullRequestSize = "XXL"
+)
+
+// pullRequestSizeLimits are the upper bounds of changed lines and files of each size,
+// a pull request gets the first size whose both limits hold
+var pullRequestSizeLimits = []struct {
+	Size  PullRequestSize
+	Lines int
+	Files int
+}{
+	{PullRequestSizeXS, 9, 2},
+	{PullRequestSizeS, 29, 5},
+	{PullRequestSizeM, 99, 15},
+	{PullRequestSizeL, 499, 30},
+	{PullRequestSizeXL, 999, 60},
+}
+
+// GetPullRequestSize returns the size classification for the given numbers of changed lines and files
+func GetPullRequestSize(lines, files int) PullRequestSize {
+	for _, limit := range pullRequestSizeLimits {
+		if lines <= limit.Lines && files <= limit.Files {
+			return limit.Size
+		}
+	}
+	return PullRequestSizeXXL
+}
+
+// Size returns the size classification of the pull request,
+// or an empty string if it does not change any counted file
+func (pr *PullRequest) Size() PullRequestSize {
+	if pr.NumChangedFiles <= 0 {
+		return ""
+	}
+	return GetPullRequestSize(pr.NumChangedLines, pr.NumChangedFiles)
+}
@@ -234,3 +234,22 @@ func TestPullRequest_GetWorkInProgressPrefixWorkInProgress(t *testing.T) {
 	pr.Issue.Title = "[wip] " + original
 	assert.Equal(t, "[wip]", pr.GetWorkInProgressPrefix())
 }
+
+func TestPullRequest_Size(t *testing.T) {
+	for _, test := range []struct {
+		Files, Lines int
+		Size         PullRequestSize
+	}{
+		{0, 0, ""},
+		{1, 9, PullRequestSizeXS},
+		{3, 9, PullRequestSizeS},
+		{1, 30, PullRequestSizeM},
+		{16, 10, PullRequestSizeL},
+		{10, 999, PullRequestSizeXL},
+		{61, 10, PullRequestSizeXXL},
+		{1, 1000, PullRequestSizeXXL},
+	} {
+		pr := &PullRequest{NumChangedFiles: test.Files, NumChangedLines: test.Lines}
+		assert.Equal(t, test.Size, pr.Size())
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MaxRecentItems is the number of items of the command palette remembered for a user
+const MaxRecentItems = 50
+
+// RecentItem is a result of the command palette chosen by a user, it ranks the next
+// results of the user. Only the key is stored, like "repo:1" or "action:new_repo", the
+// item is looked up again when it is shown so that it is never stale.
+type RecentItem struct {
+	ID       int64              `xorm:"pk autoincr"`
+	UserID   int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	ItemKey  string             `xorm:"UNIQUE(s) VARCHAR(100) NOT NULL"`
+	Count    int                `xorm:"NOT NULL DEFAULT 0"`
+	UsedUnix timeutil.TimeStamp `xorm:"INDEX"`
+}
+
+// recentItemKeyPattern matches the keys of the command palette items
+var recentItemKeyPattern = regexp.MustCompile(`^[a-z]+(:[a-z0-9_]+)+$`)
+
+// ErrInvalidRecentItemKey represents a "InvalidRecentItemKey" kind of error.
+type ErrInvalidRecentItemKey struct {
+	Key string
+}
+
+// IsErrInvalidRecentItemKey checks if an error is a ErrInvalidRecentItemKey.
+func IsErrInvalidRecentItemKey(err error) bool {
+	_, ok := err.(ErrInvalidRecentItemKey)
+	return ok
+}
+
+func (err ErrInvalidRecentItemKey) Error() string {
+	return fmt.Sprintf("invalid recent item key [key: %s]", err.Key)
+}
+
+// Score returns the weight of the item in the ranking of the results, it grows with the
+// number of uses and shrinks with the weeks since the last use, a week old item counts half.
+func (item *RecentItem) Score(now timeutil.TimeStamp) float64 {
+	weeks := float64(now-item.UsedUnix) / float64(7*24*60*60)
+	if weeks < 0 {
+		weeks = 0
+	}
+	return float64(item.Count) / (1 + weeks)
+}
+
+// RecordRecentItem remembers that the user chose the item of the command palette, only
+// the MaxRecentItems last ones are kept.
+func RecordRecentItem(userID int64, key string) error {
+	if len(key) > 100 || !recentItemKeyPattern.MatchString(key) {
+		return ErrInvalidRecentItemKey{key}
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	now := timeutil.TimeStampNow()
+	item := &RecentItem{UserID: userID, ItemKey: key}
+	has, err := sess.Get(item)
+	if err != nil {
+		return err
+	}
+	item.Count++
+	item.UsedUnix = now
+	if has {
+		if _, err = sess.ID(item.ID).Cols("count", "used_unix").Update(item); err != nil {
+			return err
+		}
+	} else if _, err = sess.Insert(item); err != nil {
+		return err
+	}
+
+	// forget the items which have not been used for the longest time
+	var oldIDs []int64
+	if err = sess.Table("recent_item").Where("user_id = ?", userID).
+		Desc("used_unix").Desc("id").Limit(MaxRecentItems*2, MaxRecentItems).
+		Cols("id").Find(&oldIDs); err != nil {
+		return err
+	}
+	if len(oldIDs) > 0 {
+		if _, err = sess.In("id", oldIDs).Delete(new(RecentItem)); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// GetRecentItems returns the items of the command palette chosen by the user, the last
+// used first.
+func GetRecentItems(userID int64) ([]*RecentItem, error) {
+	items := make([]*RecentItem, 0, 10)
+	return items, x.Where("user_id = ?", userID).
+		Desc("used_unix").Desc("id").
+		Limit(MaxRecentItems).
+		Find(&items)
+}
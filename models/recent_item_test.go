@@ -0,0 +1,57 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordRecentItem(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, RecordRecentItem(2, "repo:1"))
+	assert.NoError(t, RecordRecentItem(2, "action:new_repo"))
+	assert.NoError(t, RecordRecentItem(2, "repo:1"))
+	assert.NoError(t, RecordRecentItem(3, "repo:1"))
+
+	item := AssertExistsAndLoadBean(t, &RecentItem{UserID: 2, ItemKey: "repo:1"}).(*RecentItem)
+	assert.EqualValues(t, 2, item.Count)
+
+	items, err := GetRecentItems(2)
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	for _, key := range []string{"", "repo", "Repo:1", "repo:1/2", "javascript:alert(1)"} {
+		err = RecordRecentItem(2, key)
+		assert.True(t, IsErrInvalidRecentItemKey(err), key)
+	}
+}
+
+func TestRecordRecentItemTrim(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	for i := 0; i < MaxRecentItems+5; i++ {
+		assert.NoError(t, RecordRecentItem(2, fmt.Sprintf("repo:%d", i)))
+	}
+	items, err := GetRecentItems(2)
+	assert.NoError(t, err)
+	assert.Len(t, items, MaxRecentItems)
+	assert.EqualValues(t, MaxRecentItems, GetCount(t, &RecentItem{UserID: 2}))
+	AssertNotExistsBean(t, &RecentItem{UserID: 2, ItemKey: "repo:0"})
+	AssertExistsAndLoadBean(t, &RecentItem{UserID: 2, ItemKey: fmt.Sprintf("repo:%d", MaxRecentItems+4)})
+}
+
+func TestRecentItemScore(t *testing.T) {
+	week := timeutil.TimeStamp(7 * 24 * 60 * 60)
+	item := &RecentItem{Count: 4, UsedUnix: 10 * week}
+	assert.EqualValues(t, 4, item.Score(10*week))
+	assert.EqualValues(t, 2, item.Score(11*week))
+	assert.EqualValues(t, 1, item.Score(13*week))
+}
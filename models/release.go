@@ -245,6 +245,32 @@ func GetLatestReleaseByRepoID(repoID int64) (*Release, error) {
 	return rel, nil
 }
 
+// GetLatestReleasesByRepoIDs returns the latest published releases of the repositories,
+// newest first.
+func GetLatestReleasesByRepoIDs(repoIDs []int64, limit int) ([]*Release, error) {
+	rels := make([]*Release, 0, limit)
+	if len(repoIDs) == 0 {
+		return rels, nil
+	}
+
+	if err := x.
+		In("repo_id", repoIDs).
+		And("is_draft = ?", false).
+		And("is_tag = ?", false).
+		Desc("created_unix", "id").
+		Limit(limit).
+		Find(&rels); err != nil {
+		return nil, err
+	}
+	for _, rel := range rels {
+		var err error
+		if rel.Repo, err = GetRepositoryByID(rel.RepoID); err != nil {
+			return nil, err
+		}
+	}
+	return rels, nil
+}
+
 // GetReleasesByRepoIDAndNames returns a list of releases of repository according repoID and tagNames.
 func GetReleasesByRepoIDAndNames(ctx DBContext, repoID int64, tagNames []string) (rels []*Release, err error) {
 	err = ctx.e.
@@ -0,0 +1,126 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"xorm.io/xorm"
+	"xorm.io/xorm/names"
+)
+
+// replica is a read-only copy of the database, which is skipped whilst it
+// cannot be reached
+type replica struct {
+	name     string
+	engine   *xorm.Engine
+	lock     sync.Mutex
+	healthy  bool
+	checked  time.Time
+	checking int32
+}
+
+var (
+	replicas       []*replica
+	replicaCounter uint32
+)
+
+func setReplicaEngines() error {
+	connStrs, err := setting.DBReplicaConnStrs()
+	if err != nil {
+		return err
+	}
+
+	engines := make([]*replica, 0, len(connStrs))
+	for i, connStr := range connStrs {
+		engine, err := xorm.NewEngine(setting.Database.Type, connStr)
+		if err != nil {
+			return fmt.Errorf("Failed to connect to replica %s: %v", setting.Database.ReplicaHosts[i], err)
+		}
+		engine.SetSchema(setting.Database.Schema)
+		engine.SetMapper(names.GonicMapper{})
+		engine.SetLogger(NewXORMLogger(setting.Database.LogSQL))
+		engine.ShowSQL(setting.Database.LogSQL)
+		engine.SetMaxOpenConns(setting.Database.MaxOpenConns)
+		engine.SetMaxIdleConns(setting.Database.MaxIdleConns)
+		engine.SetConnMaxLifetime(setting.Database.ConnMaxLifetime)
+		if setting.Tracing.Enabled {
+			engine.AddHook(tracingHook{})
+		}
+		engines = append(engines, &replica{
+			name:    setting.Database.ReplicaHosts[i],
+			engine:  engine,
+			healthy: true,
+		})
+	}
+	replicas = engines
+	return nil
+}
+
+// isHealthy returns if the replica answered the last check. The replica is
+// checked again once the check interval passed, in the meantime concurrent
+// callers use the result of the last check.
+func (r *replica) isHealthy() bool {
+	r.lock.Lock()
+	healthy, due := r.healthy, time.Since(r.checked) >= setting.Database.ReplicaCheckInterval
+	r.lock.Unlock()
+	if !due || !atomic.CompareAndSwapInt32(&r.checking, 0, 1) {
+		return healthy
+	}
+	defer atomic.StoreInt32(&r.checking, 0)
+
+	err := r.engine.Ping()
+	r.setHealthy(err)
+	return err == nil
+}
+
+func (r *replica) setHealthy(err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if err != nil && r.healthy {
+		log.Warn("Database replica %s is unavailable, reading from the primary database instead: %v", r.name, err)
+	} else if err == nil && !r.healthy {
+		log.Info("Database replica %s is available again", r.name)
+	}
+	r.healthy = err == nil
+	r.checked = time.Now()
+}
+
+// nextReplica returns the healthy replicas in turn, or nil if there is none
+func nextReplica() *replica {
+	n := uint32(len(replicas))
+	if n == 0 {
+		return nil
+	}
+	start := atomic.AddUint32(&replicaCounter, 1)
+	for i := uint32(0); i < n; i++ {
+		if r := replicas[(start+i)%n]; r.isHealthy() {
+			return r
+		}
+	}
+	return nil
+}
+
+// withReadEngine runs fn, which only reads and tolerates slightly stale data,
+// with a replica of the database. If there is no healthy replica or fn fails
+// on the replica, fn is run with the primary database instead.
+func withReadEngine(fn func(e *xorm.Engine) error) error {
+	r := nextReplica()
+	if r == nil {
+		return fn(x)
+	}
+	if err := fn(r.engine); err != nil {
+		// Only skip the replica if it cannot be reached rather than fn failing on it
+		r.setHealthy(r.engine.Ping())
+		return fn(x)
+	}
+	return nil
+}
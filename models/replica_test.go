@@ -0,0 +1,67 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
+	"xorm.io/xorm/names"
+)
+
+func testReplica(t *testing.T, name, connStr string) *replica {
+	engine, err := xorm.NewEngine("sqlite3", connStr)
+	assert.NoError(t, err)
+	engine.SetMapper(names.GonicMapper{})
+	return &replica{name: name, engine: engine, healthy: true}
+}
+
+func TestReadReplicas(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	oldInterval := setting.Database.ReplicaCheckInterval
+	defer func() {
+		replicas = nil
+		setting.Database.ReplicaCheckInterval = oldInterval
+	}()
+	setting.Database.ReplicaCheckInterval = time.Hour
+
+	// the test database is shared in memory, so a second engine reads the same data
+	healthy := testReplica(t, "healthy", "file::memory:?cache=shared&_txlock=immediate")
+	unreachable := testReplica(t, "unreachable", "file:/nonexistent/gitea.db?mode=ro")
+	replicas = []*replica{unreachable, healthy}
+
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, healthy, nextReplica())
+	}
+	assert.False(t, unreachable.healthy)
+	assert.True(t, healthy.healthy)
+
+	users, count, err := SearchUsers(&SearchUserOptions{Type: UserTypeIndividual, ListOptions: ListOptions{PageSize: 5, Page: 1}})
+	assert.NoError(t, err)
+	assert.Len(t, users, 5)
+	assert.EqualValues(t, CountUsers(), count)
+
+	// without a healthy replica the primary database is used
+	replicas = []*replica{unreachable}
+	assert.Nil(t, nextReplica())
+	issues, err := Issues(&IssuesOptions{RepoIDs: []int64{1}})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, issues)
+
+	// a replica failing a query is checked and skipped if it cannot be reached
+	failing := testReplica(t, "failing", "file:/nonexistent/gitea.db?mode=ro")
+	failing.checked = time.Now()
+	replicas = []*replica{failing}
+	repos, count, err := SearchRepository(&SearchRepoOptions{ListOptions: ListOptions{PageSize: 10, Page: 1}})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, repos)
+	assert.Len(t, repos, 10)
+	assert.True(t, count > 10)
+	assert.False(t, failing.healthy)
+}
@@ -189,8 +189,10 @@ type Repository struct {
 	Size                            int64              `xorm:"NOT NULL DEFAULT 0"`
 	CodeIndexerStatus               *RepoIndexerStatus `xorm:"-"`
 	StatsIndexerStatus              *RepoIndexerStatus `xorm:"-"`
+	InsightsIndexerStatus           *RepoIndexerStatus `xorm:"-"`
 	IsFsckEnabled                   bool               `xorm:"NOT NULL DEFAULT true"`
 	CloseIssuesViaCommitInAnyBranch bool               `xorm:"NOT NULL DEFAULT false"`
+	DisableOrgLabelSync             bool               `xorm:"NOT NULL DEFAULT false"`
 	Topics                          []string           `xorm:"TEXT JSON"`
 
 	// Avatar: ID(10-20)-md5(32) - must fit into 64 symbols
@@ -343,6 +345,7 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 	allowRebase := false
 	allowRebaseMerge := false
 	allowSquash := false
+	allowFastForwardOnly := false
 	if unit, err := repo.getUnit(e, UnitTypePullRequests); err == nil {
 		config := unit.PullRequestsConfig()
 		hasPullRequests = true
@@ -351,7 +354,10 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 		allowRebase = config.AllowRebase
 		allowRebaseMerge = config.AllowRebaseMerge
 		allowSquash = config.AllowSquash
+		allowFastForwardOnly = config.AllowFastForwardOnly
 	}
+	_, err := repo.getUnit(e, UnitTypeDiscussions)
+	hasDiscussions := err == nil
 
 	repo.mustOwner(e)
 
@@ -396,8 +402,11 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 		AllowRebase:               allowRebase,
 		AllowRebaseMerge:          allowRebaseMerge,
 		AllowSquash:               allowSquash,
+		AllowFastForwardOnly:      allowFastForwardOnly,
+		HasDiscussions:            hasDiscussions,
 		AvatarURL:                 repo.avatarLink(e),
 		Internal:                  !repo.IsPrivate && repo.Owner.Visibility == api.VisibleTypePrivate,
+		DisableOrgLabelSync:       repo.DisableOrgLabelSync,
 	}
 }
 
@@ -557,6 +566,13 @@ func (repo *Repository) ComposeMetas() map[string]string {
 			}
 		}
 
+		autolinks, err := composeAutoLinkMetas(x, repo)
+		if err != nil {
+			log.Error("composeAutoLinkMetas: %v", err)
+		} else if autolinks != "" {
+			metas["autolinks"] = autolinks
+		}
+
 		repo.MustOwner()
 		if repo.Owner.IsOrganization() {
 			teams := make([]string, 0, 5)
@@ -883,6 +899,11 @@ func (repo *Repository) GetWriters() (_ []*User, err error) {
 	return repo.getUsersWithAccessMode(x, AccessModeWrite)
 }
 
+// GetAdmins returns all users that have admin access to the repository.
+func (repo *Repository) GetAdmins() (_ []*User, err error) {
+	return repo.getUsersWithAccessMode(x, AccessModeAdmin)
+}
+
 // IsReader returns true if user has explicit read access or higher to the repository.
 func (repo *Repository) IsReader(userID int64) (bool, error) {
 	if repo.OwnerID == userID {
@@ -1046,6 +1067,10 @@ func GetRepoInitFile(tp, name string) ([]byte, error) {
 	}
 }
 
+// ProfileRepoName is the name of the repository, whose README is shown on the
+// profile of its owner
+const ProfileRepoName = ".profile"
+
 var (
 	reservedRepoNames    = []string{".", ".."}
 	reservedRepoPatterns = []string{"*.git", "*.wiki"}
@@ -1118,6 +1143,13 @@ func CreateRepository(ctx DBContext, doer, u *User, repo *Repository) (err error
 	}
 	u.NumRepos++
 
+	if repo.IsPrivate {
+		if _, err = ctx.e.Incr("num_private_repos").ID(u.ID).Update(new(User)); err != nil {
+			return fmt.Errorf("increment user num_private_repos: %v", err)
+		}
+		u.NumPrivateRepos++
+	}
+
 	// Give access to all members in teams with access to all repositories.
 	if u.IsOrganization() {
 		if err := u.GetTeams(&SearchTeamOptions{}); err != nil {
@@ -1288,6 +1320,13 @@ func TransferOwnership(doer *User, newOwnerName string, repo *Repository) error
 	} else if _, err = sess.Exec("UPDATE `user` SET num_repos=num_repos-1 WHERE id=?", oldOwner.ID); err != nil {
 		return fmt.Errorf("decrease old owner repository count: %v", err)
 	}
+	if repo.IsPrivate {
+		if _, err = sess.Exec("UPDATE `user` SET num_private_repos=num_private_repos+1 WHERE id=?", newOwner.ID); err != nil {
+			return fmt.Errorf("increase new owner private repository count: %v", err)
+		} else if _, err = sess.Exec("UPDATE `user` SET num_private_repos=num_private_repos-1 WHERE id=?", oldOwner.ID); err != nil {
+			return fmt.Errorf("decrease old owner private repository count: %v", err)
+		}
+	}
 
 	if err = watchRepo(sess, doer.ID, repo.ID, true); err != nil {
 		return fmt.Errorf("watchRepo: %v", err)
@@ -1319,6 +1358,11 @@ func TransferOwnership(doer *User, newOwnerName string, repo *Repository) error
 		}
 	}
 
+	// The repository can only be pinned by its owner
+	if _, err = sess.Delete(&PinnedRepo{RepoID: repo.ID}); err != nil {
+		return fmt.Errorf("delete pinned repo: %v", err)
+	}
+
 	// If there was previously a redirect at this location, remove it.
 	if err = deleteRepoRedirect(sess, newOwner.ID, repo.Name); err != nil {
 		return fmt.Errorf("delete repo redirect: %v", err)
@@ -1407,6 +1451,10 @@ func updateRepository(e Engine, repo *Repository, visibilityChanged bool) (err e
 	}
 
 	if visibilityChanged {
+		if _, err = e.Exec("UPDATE `user` SET num_private_repos=(SELECT COUNT(*) FROM `repository` WHERE owner_id=? AND is_private=?) WHERE id=?", repo.OwnerID, true, repo.OwnerID); err != nil {
+			return fmt.Errorf("update owner private repository count: %v", err)
+		}
+
 		if err = repo.getOwner(e); err != nil {
 			return fmt.Errorf("getOwner: %v", err)
 		}
@@ -1572,9 +1620,9 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		Find(&attachments); err != nil {
 		return err
 	}
-	releaseAttachments := make([]string, 0, len(attachments))
+	releaseAttachments := make([]string, 0, 2*len(attachments))
 	for i := 0; i < len(attachments); i++ {
-		releaseAttachments = append(releaseAttachments, attachments[i].LocalPath())
+		releaseAttachments = append(releaseAttachments, attachments[i].LocalPath(), attachments[i].ThumbnailPath())
 	}
 
 	if _, err = sess.Exec("UPDATE `user` SET num_stars=num_stars-1 WHERE id IN (SELECT `uid` FROM `star` WHERE repo_id = ?)", repo.ID); err != nil {
@@ -1585,6 +1633,10 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		&Access{RepoID: repo.ID},
 		&Action{RepoID: repo.ID},
 		&Watch{RepoID: repoID},
+		&WatchPath{RepoID: repoID},
+		&CommitMessagePolicy{RepoID: repoID},
+		&RepoRawHeaders{RepoID: repoID},
+		&LocaleSetting{RepoID: repoID},
 		&Star{RepoID: repoID},
 		&Mirror{RepoID: repoID},
 		&Milestone{RepoID: repoID},
@@ -1599,12 +1651,40 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		&CommitStatus{RepoID: repoID},
 		&RepoIndexerStatus{RepoID: repoID},
 		&LanguageStat{RepoID: repoID},
+		&RepoContributorDay{RepoID: repoID},
+		&RepoPunchCard{RepoID: repoID},
+		&PinnedRepo{RepoID: repoID},
 		&Comment{RefRepoID: repoID},
 		&Task{RepoID: repoID},
+		&WorkflowState{RepoID: repoID},
+		&WorkflowTransition{RepoID: repoID},
+		&Epic{RepoID: repoID},
+		&IssueAssignRule{RepoID: repoID},
+		&ProjectRule{RepoID: repoID},
+		&RenamedBranch{RepoID: repoID},
+		&StarListRepo{RepoID: repoID},
+		&RepoStatsSnapshot{RepoID: repoID},
+		&RepoTrending{RepoID: repoID},
+		&ScheduledVisibilityChange{RepoID: repoID},
+		&PagesConfig{RepoID: repoID},
+		&WorkspaceChange{RepoID: repoID},
+		&RepoCustomTab{RepoID: repoID},
+		&AutoLinkRule{RepoID: repoID},
+		&DeployToken{RepoID: repoID},
+		&IssueTrackerSync{RepoID: repoID},
+		&IssueTrackerEvent{RepoID: repoID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	if err = deleteContributorAgreements(sess, builder.Eq{"repo_id": repoID}); err != nil {
+		return fmt.Errorf("deleteContributorAgreements: %v", err)
+	}
+
+	if err = deleteDiscussionsByRepoID(sess, repoID); err != nil {
+		return fmt.Errorf("deleteDiscussionsByRepoID: %v", err)
+	}
+
 	// Delete Issues and related objects
 	var attachmentPaths []string
 	if attachmentPaths, err = deleteIssuesByRepoID(sess, repoID); err != nil {
@@ -1624,6 +1704,11 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 	if _, err = sess.Exec("UPDATE `user` SET num_repos=num_repos-1 WHERE id=?", uid); err != nil {
 		return err
 	}
+	if repo.IsPrivate {
+		if _, err = sess.Exec("UPDATE `user` SET num_private_repos=num_private_repos-1 WHERE id=?", uid); err != nil {
+			return err
+		}
+	}
 
 	if len(repo.Topics) > 0 {
 		if err = removeTopicsFromRepo(sess, repo.ID); err != nil {
@@ -2030,6 +2115,29 @@ func CheckRepoStats(ctx context.Context) error {
 	}
 	// ***** END: Repository.NumClosedPulls *****
 
+	// ***** START: User.NumPrivateRepos *****
+	desc = "user count 'num_private_repos'"
+	results, err = x.Query("SELECT `user`.id FROM `user` WHERE `user`.num_private_repos!=(SELECT COUNT(*) FROM `repository` WHERE owner_id=`user`.id AND is_private=?)", true)
+	if err != nil {
+		log.Error("Select %s: %v", desc, err)
+	} else {
+		for _, result := range results {
+			id := com.StrTo(result["id"]).MustInt64()
+			select {
+			case <-ctx.Done():
+				log.Warn("CheckRepoStats: Cancelled")
+				return ErrCancelledf("during %s for user ID %d", desc, id)
+			default:
+			}
+			log.Trace("Updating %s: %d", desc, id)
+			_, err = x.Exec("UPDATE `user` SET num_private_repos=(SELECT COUNT(*) FROM `repository` WHERE owner_id=? AND is_private=?) WHERE id=?", id, true, id)
+			if err != nil {
+				log.Error("Update %s[%d]: %v", desc, id, err)
+			}
+		}
+	}
+	// ***** END: User.NumPrivateRepos *****
+
 	// FIXME: use checker when stop supporting old fork repo format.
 	// ***** START: Repository.NumForks *****
 	results, err = x.Query("SELECT repo.id FROM `repository` repo WHERE repo.num_forks!=(SELECT COUNT(*) FROM `repository` WHERE fork_id=repo.id)")
@@ -2201,6 +2309,16 @@ func (repo *Repository) RelAvatarLink() string {
 	return repo.relAvatarLink(x)
 }
 
+// SizedRelAvatarLink returns a relative link to the repository's avatar, or to its variant
+// closest to the indicated size.
+func (repo *Repository) SizedRelAvatarLink(size int) string {
+	link := repo.relAvatarLink(x)
+	if variant := avatar.VariantSize(size); variant > 0 && strings.HasPrefix(link, setting.AppSubURL+"/"+avatar.KindRepo+"/") {
+		link += "/" + strconv.Itoa(variant)
+	}
+	return link
+}
+
 func (repo *Repository) relAvatarLink(e Engine) string {
 	// If no avatar - path is empty
 	avatarPath := repo.CustomAvatarPath()
@@ -2217,7 +2335,7 @@ func (repo *Repository) relAvatarLink(e Engine) string {
 			return ""
 		}
 	}
-	return setting.AppSubURL + "/repo-avatars/" + repo.Avatar
+	return setting.AppSubURL + "/" + avatar.KindRepo + "/" + repo.Avatar
 }
 
 // avatarLink returns user avatar absolute link.
@@ -2247,6 +2365,7 @@ func (repo *Repository) UploadAvatar(data []byte) error {
 	}
 
 	oldAvatarPath := repo.CustomAvatarPath()
+	avatar.RemoveVariants(avatar.KindRepo, repo.Avatar)
 
 	// Users can upload the same image to other repo - prefix it with ID
 	// Then repo will be removed - only it avatar file will be removed
@@ -2288,6 +2407,7 @@ func (repo *Repository) DeleteAvatar() error {
 
 	avatarPath := repo.CustomAvatarPath()
 	log.Trace("DeleteAvatar[%d]: %s", repo.ID, avatarPath)
+	avatar.RemoveVariants(avatar.KindRepo, repo.Avatar)
 
 	sess := x.NewSession()
 	defer sess.Close()
@@ -20,6 +20,10 @@ type Collaboration struct {
 }
 
 func (repo *Repository) addCollaborator(e Engine, u *User) error {
+	if err := checkBotScope(e, u, repo.OwnerID, false); err != nil {
+		return err
+	}
+
 	collaboration := &Collaboration{
 		RepoID: repo.ID,
 		UserID: u.ID,
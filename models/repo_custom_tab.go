@@ -0,0 +1,159 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/svg"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/validation"
+)
+
+const (
+	// MaxRepoCustomTabs is the maximum number of custom tabs of a repository
+	MaxRepoCustomTabs = 10
+	// DefaultRepoCustomTabIcon is the icon of the custom tabs without one
+	DefaultRepoCustomTabIcon = "octicon-link-external"
+)
+
+// RepoCustomTab is a tab of the header of a repository linking to an external
+// system, like the documentation site or the CI dashboard of the project.
+type RepoCustomTab struct {
+	ID     int64  `xorm:"pk autoincr"`
+	RepoID int64  `xorm:"INDEX NOT NULL"`
+	Name   string `xorm:"NOT NULL"`
+	URL    string `xorm:"TEXT NOT NULL"`
+	// Icon is the name of an octicon, e.g. octicon-book
+	Icon string
+	// Sort orders the tabs of a repository, the lowest first
+	Sort int `xorm:"NOT NULL DEFAULT 0"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// ErrRepoCustomTabNotExist represents a "RepoCustomTabNotExist" kind of error.
+type ErrRepoCustomTabNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrRepoCustomTabNotExist checks if an error is a ErrRepoCustomTabNotExist.
+func IsErrRepoCustomTabNotExist(err error) bool {
+	_, ok := err.(ErrRepoCustomTabNotExist)
+	return ok
+}
+
+func (err ErrRepoCustomTabNotExist) Error() string {
+	return fmt.Sprintf("repository custom tab does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
+// ErrRepoCustomTabInvalid represents a custom tab with an invalid field.
+type ErrRepoCustomTabInvalid struct {
+	Field string
+	Value string
+}
+
+// IsErrRepoCustomTabInvalid checks if an error is a ErrRepoCustomTabInvalid.
+func IsErrRepoCustomTabInvalid(err error) bool {
+	_, ok := err.(ErrRepoCustomTabInvalid)
+	return ok
+}
+
+func (err ErrRepoCustomTabInvalid) Error() string {
+	return fmt.Sprintf("invalid repository custom tab [%s: %s]", err.Field, err.Value)
+}
+
+// ErrRepoCustomTabsLimit represents a repository having the maximum number of custom tabs.
+type ErrRepoCustomTabsLimit struct {
+	RepoID int64
+}
+
+// IsErrRepoCustomTabsLimit checks if an error is a ErrRepoCustomTabsLimit.
+func IsErrRepoCustomTabsLimit(err error) bool {
+	_, ok := err.(ErrRepoCustomTabsLimit)
+	return ok
+}
+
+func (err ErrRepoCustomTabsLimit) Error() string {
+	return fmt.Sprintf("repository has reached the limit of %d custom tabs [repo_id: %d]", MaxRepoCustomTabs, err.RepoID)
+}
+
+// normalize trims the fields of the tab and checks they are valid
+func (tab *RepoCustomTab) normalize() error {
+	tab.Name = strings.TrimSpace(tab.Name)
+	tab.URL = strings.TrimSpace(tab.URL)
+	tab.Icon = strings.TrimSpace(tab.Icon)
+	if tab.Name == "" {
+		return ErrRepoCustomTabInvalid{Field: "name", Value: tab.Name}
+	}
+	if !validation.IsValidURL(tab.URL) {
+		return ErrRepoCustomTabInvalid{Field: "url", Value: tab.URL}
+	}
+	if tab.Icon == "" {
+		tab.Icon = DefaultRepoCustomTabIcon
+	} else if _, ok := svg.SVGs[tab.Icon]; !ok || !strings.HasPrefix(tab.Icon, "octicon-") {
+		return ErrRepoCustomTabInvalid{Field: "icon", Value: tab.Icon}
+	}
+	return nil
+}
+
+// NewRepoCustomTab adds a custom tab to a repository.
+func NewRepoCustomTab(tab *RepoCustomTab) error {
+	if err := tab.normalize(); err != nil {
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	count, err := sess.Where("repo_id = ?", tab.RepoID).Count(new(RepoCustomTab))
+	if err != nil {
+		return err
+	} else if count >= MaxRepoCustomTabs {
+		return ErrRepoCustomTabsLimit{RepoID: tab.RepoID}
+	}
+	if _, err = sess.Insert(tab); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetRepoCustomTab returns a custom tab of a repository.
+func GetRepoCustomTab(repoID, id int64) (*RepoCustomTab, error) {
+	tab := new(RepoCustomTab)
+	has, err := x.ID(id).Where("repo_id = ?", repoID).Get(tab)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRepoCustomTabNotExist{ID: id, RepoID: repoID}
+	}
+	return tab, nil
+}
+
+// GetRepoCustomTabs returns the custom tabs of a repository in their order.
+func GetRepoCustomTabs(repoID int64) ([]*RepoCustomTab, error) {
+	tabs := make([]*RepoCustomTab, 0, 5)
+	return tabs, x.Where("repo_id = ?", repoID).Asc("sort", "id").Find(&tabs)
+}
+
+// UpdateRepoCustomTab updates a custom tab of a repository.
+func UpdateRepoCustomTab(tab *RepoCustomTab) error {
+	if err := tab.normalize(); err != nil {
+		return err
+	}
+	_, err := x.ID(tab.ID).Cols("name", "url", "icon", "sort").Update(tab)
+	return err
+}
+
+// DeleteRepoCustomTab deletes a custom tab of a repository.
+func DeleteRepoCustomTab(repoID, id int64) error {
+	_, err := x.Where("id = ? AND repo_id = ?", id, repoID).Delete(new(RepoCustomTab))
+	return err
+}
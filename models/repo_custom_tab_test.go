@@ -0,0 +1,70 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"code.gitea.io/gitea/modules/svg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRepoCustomTabs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	tabs, err := GetRepoCustomTabs(1)
+	assert.NoError(t, err)
+	if assert.Len(t, tabs, 2) {
+		assert.EqualValues(t, 2, tabs[0].ID)
+		assert.EqualValues(t, 1, tabs[1].ID)
+	}
+
+	tabs, err = GetRepoCustomTabs(2)
+	assert.NoError(t, err)
+	assert.Len(t, tabs, 0)
+
+	_, err = GetRepoCustomTab(2, 1)
+	assert.True(t, IsErrRepoCustomTabNotExist(err))
+}
+
+func TestNewRepoCustomTab(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(svgs map[string]string) {
+		svg.SVGs = svgs
+	}(svg.SVGs)
+	svg.SVGs = map[string]string{"octicon-book": "<svg></svg>", "gitea-github": "<svg></svg>"}
+
+	tab := &RepoCustomTab{RepoID: 2, Name: " Docs ", URL: "https://docs.example.com/"}
+	assert.NoError(t, NewRepoCustomTab(tab))
+	tab = AssertExistsAndLoadBean(t, &RepoCustomTab{ID: tab.ID, RepoID: 2, Name: "Docs"}).(*RepoCustomTab)
+	assert.Equal(t, DefaultRepoCustomTabIcon, tab.Icon)
+
+	tab.Icon = "octicon-book"
+	tab.Sort = 3
+	assert.NoError(t, UpdateRepoCustomTab(tab))
+	AssertExistsAndLoadBean(t, &RepoCustomTab{ID: tab.ID, Icon: "octicon-book", Sort: 3})
+
+	for _, invalid := range []*RepoCustomTab{
+		{RepoID: 2, Name: " ", URL: "https://docs.example.com/"},
+		{RepoID: 2, Name: "Docs", URL: "javascript:alert(1)"},
+		{RepoID: 2, Name: "Docs", URL: "https://docs.example.com/", Icon: "octicon-unknown"},
+		{RepoID: 2, Name: "Docs", URL: "https://docs.example.com/", Icon: "gitea-github"},
+	} {
+		assert.True(t, IsErrRepoCustomTabInvalid(NewRepoCustomTab(invalid)))
+	}
+
+	for i := 1; i < MaxRepoCustomTabs; i++ {
+		assert.NoError(t, NewRepoCustomTab(&RepoCustomTab{RepoID: 2, Name: fmt.Sprintf("Tab %d", i), URL: "https://example.com"}))
+	}
+	assert.True(t, IsErrRepoCustomTabsLimit(NewRepoCustomTab(&RepoCustomTab{RepoID: 2, Name: "Too many", URL: "https://example.com"})))
+
+	assert.NoError(t, DeleteRepoCustomTab(2, tab.ID))
+	AssertNotExistsBean(t, &RepoCustomTab{ID: tab.ID})
+	// the tabs of other repositories are not deleted
+	assert.NoError(t, DeleteRepoCustomTab(2, 1))
+	AssertExistsAndLoadBean(t, &RepoCustomTab{ID: 1})
+}
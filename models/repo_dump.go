@@ -0,0 +1,328 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"xorm.io/builder"
+	"xorm.io/xorm/schemas"
+)
+
+// RepoDumpVersion is the version of the format of the repository dumps
+const RepoDumpVersion = 1
+
+// RepoDump holds the database rows of a repository, so that it can be restored without
+// restoring the whole database
+type RepoDump struct {
+	Version   int    `json:"version"`
+	OwnerName string `json:"owner"`
+	Name      string `json:"name"`
+	// Rows are the rows of the repository by table, their values are encoded by the types of
+	// their columns so that they can be restored on another database type
+	Rows map[string][]map[string]interface{} `json:"rows"`
+}
+
+// repoDumpCond returns the condition selecting the rows of a table belonging to a repository:
+// the tables with a repository column, and the ones of its issues and releases
+func repoDumpCond(table *schemas.Table, repoID int64) builder.Cond {
+	if table.Name == "repository" {
+		return builder.Eq{"id": repoID}
+	}
+	for _, col := range []string{"repo_id", "repository_id"} {
+		if table.GetColumn(col) != nil {
+			return builder.Eq{col: repoID}
+		}
+	}
+	cond := builder.NewCond()
+	if table.GetColumn("issue_id") != nil {
+		cond = cond.Or(builder.In("issue_id", builder.Select("id").From("issue").Where(builder.Eq{"repo_id": repoID})))
+	}
+	if table.GetColumn("release_id") != nil {
+		cond = cond.Or(builder.In("release_id", builder.Select("id").From("`release`").Where(builder.Eq{"repo_id": repoID})))
+	}
+	if !cond.IsValid() {
+		return nil
+	}
+	return cond
+}
+
+// repoDumpKind returns how the values of a column are encoded in the dumps
+func repoDumpKind(col *schemas.Column) string {
+	switch {
+	case col.SQLType.Name == schemas.Bool || col.SQLType.Name == schemas.Boolean:
+		return "bool"
+	case col.SQLType.IsBlob():
+		return "blob"
+	case col.SQLType.IsNumeric():
+		switch col.SQLType.Name {
+		case schemas.Float, schemas.Double, schemas.Real, schemas.Decimal, schemas.Numeric:
+			return "float"
+		}
+		return "int"
+	}
+	return "string"
+}
+
+// encodeRepoDumpValue encodes a value read from the database, the drivers return different
+// types for the same columns
+func encodeRepoDumpValue(col *schemas.Column, v interface{}) (interface{}, error) {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		if repoDumpKind(col) == "blob" {
+			return base64.StdEncoding.EncodeToString(v), nil
+		}
+		s = string(v)
+	case string:
+		s = v
+	case time.Time:
+		s = v.Format(time.RFC3339Nano)
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	switch repoDumpKind(col) {
+	case "bool":
+		if s == "t" || s == "f" {
+			return s == "t", nil
+		}
+		return strconv.ParseBool(s)
+	case "int":
+		return strconv.ParseInt(s, 10, 64)
+	case "float":
+		return strconv.ParseFloat(s, 64)
+	case "blob":
+		return base64.StdEncoding.EncodeToString([]byte(s)), nil
+	}
+	return s, nil
+}
+
+// decodeRepoDumpValue decodes a value of a dump to insert it
+func decodeRepoDumpValue(col *schemas.Column, v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case json.Number:
+		if repoDumpKind(col) == "float" {
+			return v.Float64()
+		}
+		return v.Int64()
+	case string:
+		if repoDumpKind(col) == "blob" {
+			return base64.StdEncoding.DecodeString(v)
+		}
+	}
+	return v, nil
+}
+
+// DumpRepository returns the database rows of a repository
+func DumpRepository(repo *Repository) (*RepoDump, error) {
+	dump := &RepoDump{
+		Version:   RepoDumpVersion,
+		OwnerName: repo.OwnerName,
+		Name:      repo.Name,
+		Rows:      make(map[string][]map[string]interface{}),
+	}
+	for _, bean := range tables {
+		table, err := x.TableInfo(bean)
+		if err != nil {
+			return nil, err
+		}
+		cond := repoDumpCond(table, repo.ID)
+		if cond == nil {
+			continue
+		}
+		rows, err := x.Table(table.Name).Where(cond).QueryInterface()
+		if err != nil {
+			return nil, fmt.Errorf("dump %s: %v", table.Name, err)
+		}
+		for _, row := range rows {
+			values := make(map[string]interface{}, len(row))
+			for name, v := range row {
+				// the columns left by old versions are not restored
+				col := table.GetColumn(name)
+				if col == nil {
+					continue
+				}
+				if values[col.Name], err = encodeRepoDumpValue(col, v); err != nil {
+					return nil, fmt.Errorf("dump %s.%s: %v", table.Name, col.Name, err)
+				}
+			}
+			dump.Rows[table.Name] = append(dump.Rows[table.Name], values)
+		}
+	}
+	return dump, nil
+}
+
+// ReadRepoDump reads the dump of the rows of a repository
+func ReadRepoDump(r io.Reader) (*RepoDump, error) {
+	dump := &RepoDump{}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(dump); err != nil {
+		return nil, fmt.Errorf("invalid repository dump: %v", err)
+	}
+	if dump.Version != RepoDumpVersion {
+		return nil, fmt.Errorf("unsupported repository dump version %d", dump.Version)
+	}
+	return dump, nil
+}
+
+// Write writes the dump of the rows of a repository
+func (dump *RepoDump) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(dump)
+}
+
+// stringValues returns the non-empty string values of a column of the rows of a table
+func (dump *RepoDump) stringValues(table, col string) []string {
+	var values []string
+	for _, row := range dump.Rows[table] {
+		if s, ok := row[col].(string); ok && s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// LFSOids returns the oids of the LFS objects of the repository
+func (dump *RepoDump) LFSOids() []string {
+	return dump.stringValues("lfs_meta_object", "oid")
+}
+
+// AttachmentUUIDs returns the UUIDs of the attachments of the issues and releases of the repository
+func (dump *RepoDump) AttachmentUUIDs() []string {
+	return dump.stringValues("attachment", "uuid")
+}
+
+// isRepoDumpRowLeft returns if a row of a repository dump was left by the deletion of the
+// repository: a row with the same primary key belonging to the same repository, issue or release,
+// or the same row for the tables without primary key
+func isRepoDumpRowLeft(e Engine, table *schemas.Table, values map[string]interface{}) (bool, error) {
+	if table.Name == "repository" {
+		return false, nil
+	}
+	cols := append(append([]string{}, table.PrimaryKeys...), "repo_id", "repository_id", "issue_id", "release_id")
+	if len(table.PrimaryKeys) == 0 {
+		cols = cols[:0]
+		for col := range values {
+			cols = append(cols, col)
+		}
+	}
+	cond := builder.NewCond()
+	for _, col := range cols {
+		if table.GetColumn(col) == nil {
+			continue
+		} else if values[col] == nil {
+			cond = cond.And(builder.IsNull{col})
+		} else {
+			cond = cond.And(builder.Eq{col: values[col]})
+		}
+	}
+	return e.Table(table.Name).Where(cond).Exist()
+}
+
+// RestoreRepository inserts the rows of a repository dump with their original IDs, e.g. to
+// restore a deleted repository. The owner must exist, the rows must not.
+func RestoreRepository(dump *RepoDump) (*Repository, error) {
+	owner, err := GetUserByName(dump.OwnerName)
+	if err != nil {
+		return nil, err
+	}
+	if has, err := x.Where("owner_id = ? AND lower_name = ?", owner.ID, strings.ToLower(dump.Name)).Exist(new(Repository)); err != nil {
+		return nil, err
+	} else if has {
+		return nil, ErrRepoAlreadyExist{owner.Name, dump.Name}
+	}
+	if len(dump.Rows["repository"]) != 1 {
+		return nil, fmt.Errorf("the dump of %s/%s has no repository row", dump.OwnerName, dump.Name)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	var repoID int64
+	for _, bean := range tables {
+		table, err := x.TableInfo(bean)
+		if err != nil {
+			return nil, err
+		}
+		rows := dump.Rows[table.Name]
+		if len(rows) == 0 {
+			continue
+		}
+
+		if setting.Database.UseMSSQL && table.AutoIncrement != "" {
+			if _, err := sess.Exec(fmt.Sprintf("SET IDENTITY_INSERT [%s] ON", table.Name)); err != nil {
+				return nil, err
+			}
+		}
+		for _, row := range rows {
+			values := make(map[string]interface{}, len(row))
+			for name, v := range row {
+				col := table.GetColumn(name)
+				if col == nil {
+					continue
+				}
+				if values[col.Name], err = decodeRepoDumpValue(col, v); err != nil {
+					return nil, fmt.Errorf("restore %s.%s: %v", table.Name, col.Name, err)
+				}
+			}
+			switch table.Name {
+			case "repository":
+				// the owner may have been recreated
+				values["owner_id"] = owner.ID
+				values["owner_name"] = owner.Name
+				repoID, _ = values["id"].(int64)
+			}
+
+			// the deletion of a repository leaves some of its rows, e.g. the attachments of its releases
+			if left, err := isRepoDumpRowLeft(sess, table, values); err != nil {
+				return nil, err
+			} else if left {
+				continue
+			}
+			if _, err := sess.Table(table.Name).Insert(values); err != nil {
+				return nil, fmt.Errorf("restore %s, the rows of the repository may still exist: %v", table.Name, err)
+			}
+		}
+		if setting.Database.UseMSSQL && table.AutoIncrement != "" {
+			if _, err := sess.Exec(fmt.Sprintf("SET IDENTITY_INSERT [%s] OFF", table.Name)); err != nil {
+				return nil, err
+			}
+		}
+		// the sequences of another instance may be behind the restored IDs
+		if setting.Database.UsePostgreSQL && table.AutoIncrement != "" {
+			if _, err := sess.Exec(fmt.Sprintf(`SELECT setval('%s_%s_seq', (SELECT MAX(%s) FROM "%s"))`,
+				table.Name, table.AutoIncrement, table.AutoIncrement, table.Name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, err := sess.Exec("UPDATE `user` SET num_repos = num_repos + 1 WHERE id = ?", owner.ID); err != nil {
+		return nil, err
+	}
+	repo, err := getRepositoryByID(sess, repoID)
+	if err != nil {
+		return nil, err
+	}
+	repo.Owner = owner
+	if err := repo.recalculateAccesses(sess); err != nil {
+		return nil, err
+	}
+	return repo, sess.Commit()
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+)
+
+func TestDumpAndRestoreRepository(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	owner := AssertExistsAndLoadBean(t, &User{ID: repo.OwnerID}).(*User)
+	issues := getCount(t, x.Where("repo_id = ?", repo.ID), new(Issue))
+	comments := getCount(t, x.In("issue_id", builder.Select("id").From("issue").Where(builder.Eq{"repo_id": repo.ID})), new(Comment))
+	assert.NotZero(t, issues)
+
+	dump, err := DumpRepository(repo)
+	assert.NoError(t, err)
+	assert.Len(t, dump.Rows["repository"], 1)
+	assert.Len(t, dump.Rows["issue"], int(issues))
+	assert.Contains(t, dump.AttachmentUUIDs(), "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11")
+
+	var buf bytes.Buffer
+	assert.NoError(t, dump.Write(&buf))
+	dump, err = ReadRepoDump(&buf)
+	assert.NoError(t, err)
+
+	_, err = RestoreRepository(dump)
+	assert.True(t, IsErrRepoAlreadyExist(err))
+
+	assert.NoError(t, DeleteRepository(owner, owner.ID, repo.ID))
+	AssertNotExistsBean(t, &Repository{ID: repo.ID})
+
+	restored, err := RestoreRepository(dump)
+	assert.NoError(t, err)
+	assert.Equal(t, repo.ID, restored.ID)
+	assert.Equal(t, repo.Name, restored.Name)
+	assert.Equal(t, repo.IsPrivate, restored.IsPrivate)
+	assert.Equal(t, issues, getCount(t, x.Where("repo_id = ?", repo.ID), new(Issue)))
+	assert.Equal(t, comments, getCount(t, x.In("issue_id", builder.Select("id").From("issue").Where(builder.Eq{"repo_id": repo.ID})), new(Comment)))
+	AssertExistsAndLoadBean(t, &Attachment{UUID: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"})
+	AssertExistsAndLoadBean(t, &User{ID: owner.ID, NumRepos: owner.NumRepos})
+	CheckConsistencyFor(t, &Repository{ID: repo.ID})
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+// getForkNetworkRoot returns the repository at the root of the fork network of the repository,
+// it is the repository itself if it is not a fork or its base repository has been deleted.
+func getForkNetworkRoot(e Engine, repo *Repository) (*Repository, error) {
+	visited := map[int64]bool{repo.ID: true}
+	for repo.IsFork && repo.ForkID > 0 && !visited[repo.ForkID] {
+		baseRepo, err := getRepositoryByID(e, repo.ForkID)
+		if err != nil {
+			if IsErrRepoNotExist(err) {
+				break
+			}
+			return nil, err
+		}
+		visited[baseRepo.ID] = true
+		repo = baseRepo
+	}
+	return repo, nil
+}
+
+// GetForkNetworkRoot returns the repository at the root of the fork network of the repository.
+func GetForkNetworkRoot(repo *Repository) (*Repository, error) {
+	return getForkNetworkRoot(x, repo)
+}
+
+// IsInSameForkNetwork returns if the repositories belong to the same fork network.
+func IsInSameForkNetwork(repo1, repo2 *Repository) (bool, error) {
+	if repo1.ID == repo2.ID {
+		return true, nil
+	}
+	root1, err := getForkNetworkRoot(x, repo1)
+	if err != nil {
+		return false, err
+	}
+	root2, err := getForkNetworkRoot(x, repo2)
+	if err != nil {
+		return false, err
+	}
+	return root1.ID == root2.ID, nil
+}
+
+// GetForkNetwork returns the repositories of the fork network of the repository whose code
+// the user can read, the root repository first and then the forks level by level.
+func GetForkNetwork(repo *Repository, user *User) ([]*Repository, error) {
+	network, _, err := GetForkNetworkLimited(repo, user, 0)
+	return network, err
+}
+
+// GetForkNetworkLimited returns the first repositories of the fork network of the repository
+// whose code the user can read in the order of GetForkNetwork, at most limit repositories if
+// limit is greater than 0. The fork network is only walked until the limit is reached, it
+// returns if there are more repositories to check.
+func GetForkNetworkLimited(repo *Repository, user *User, limit int) ([]*Repository, bool, error) {
+	root, err := getForkNetworkRoot(x, repo)
+	if err != nil {
+		return nil, false, err
+	}
+
+	readable := make([]*Repository, 0, 10)
+	visited := map[int64]bool{root.ID: true}
+	level := []*Repository{root}
+	for len(level) > 0 {
+		parentIDs := make([]int64, 0, len(level))
+		for _, r := range level {
+			if limit > 0 && len(readable) >= limit {
+				return readable, true, nil
+			}
+			perm, err := GetUserRepoPermission(r, user)
+			if err != nil {
+				return nil, false, err
+			}
+			if perm.CanRead(UnitTypeCode) {
+				readable = append(readable, r)
+			}
+			parentIDs = append(parentIDs, r.ID)
+		}
+
+		forks := make([]*Repository, 0, len(parentIDs))
+		if err := x.In("fork_id", parentIDs).OrderBy("owner_name, lower_name").Find(&forks); err != nil {
+			return nil, false, err
+		}
+		level = level[:0]
+		for _, fork := range forks {
+			if visited[fork.ID] {
+				continue
+			}
+			visited[fork.ID] = true
+			level = append(level, fork)
+		}
+	}
+	return readable, false, nil
+}
+
+// GetForkNetworkRepoByOwner returns the repository of the fork network of the repository
+// owned by the user, it returns nil if the user has none.
+func GetForkNetworkRepoByOwner(repo *Repository, ownerID int64) (*Repository, error) {
+	if repo.OwnerID == ownerID {
+		return repo, nil
+	}
+	root, err := getForkNetworkRoot(x, repo)
+	if err != nil {
+		return nil, err
+	}
+	if root.OwnerID == ownerID {
+		return root, nil
+	}
+
+	candidates := make([]*Repository, 0, 2)
+	if err := x.Where("owner_id = ? AND is_fork = ?", ownerID, true).Find(&candidates); err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		candidateRoot, err := getForkNetworkRoot(x, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if candidateRoot.ID == root.ID {
+			return candidate, nil
+		}
+	}
+	return nil, nil
+}
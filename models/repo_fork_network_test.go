@@ -0,0 +1,84 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetForkNetwork(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	root := AssertExistsAndLoadBean(t, &Repository{ID: 27}).(*Repository)
+	fork := AssertExistsAndLoadBean(t, &Repository{ID: 29}).(*Repository)
+	privateFork := AssertExistsAndLoadBean(t, &Repository{ID: 30}).(*Repository)
+
+	networkRoot, err := GetForkNetworkRoot(fork)
+	assert.NoError(t, err)
+	assert.EqualValues(t, root.ID, networkRoot.ID)
+
+	same, err := IsInSameForkNetwork(root, fork)
+	assert.NoError(t, err)
+	assert.True(t, same)
+	same, err = IsInSameForkNetwork(fork, privateFork)
+	assert.NoError(t, err)
+	assert.False(t, same)
+
+	network, err := GetForkNetwork(fork, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, network, 2) {
+		assert.EqualValues(t, 27, network[0].ID)
+		assert.EqualValues(t, 29, network[1].ID)
+	}
+
+	// private repositories are only listed to users who can read them
+	network, err = GetForkNetwork(privateFork, nil)
+	assert.NoError(t, err)
+	assert.Len(t, network, 0)
+	user20 := AssertExistsAndLoadBean(t, &User{ID: 20}).(*User)
+	network, err = GetForkNetwork(privateFork, user20)
+	assert.NoError(t, err)
+	if assert.Len(t, network, 2) {
+		assert.EqualValues(t, 28, network[0].ID)
+		assert.EqualValues(t, 30, network[1].ID)
+	}
+
+	// the fork network is only walked until the limit is reached
+	network, hasMore, err := GetForkNetworkLimited(fork, nil, 1)
+	assert.NoError(t, err)
+	assert.True(t, hasMore)
+	if assert.Len(t, network, 1) {
+		assert.EqualValues(t, 27, network[0].ID)
+	}
+	network, hasMore, err = GetForkNetworkLimited(fork, nil, 2)
+	assert.NoError(t, err)
+	assert.False(t, hasMore)
+	assert.Len(t, network, 2)
+}
+
+func TestGetForkNetworkRepoByOwner(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	root := AssertExistsAndLoadBean(t, &Repository{ID: 27}).(*Repository)
+	fork := AssertExistsAndLoadBean(t, &Repository{ID: 29}).(*Repository)
+
+	repo, err := GetForkNetworkRepoByOwner(root, 20)
+	assert.NoError(t, err)
+	if assert.NotNil(t, repo) {
+		assert.EqualValues(t, 29, repo.ID)
+	}
+
+	repo, err = GetForkNetworkRepoByOwner(fork, 19)
+	assert.NoError(t, err)
+	if assert.NotNil(t, repo) {
+		assert.EqualValues(t, 27, repo.ID)
+	}
+
+	repo, err = GetForkNetworkRepoByOwner(root, 2)
+	assert.NoError(t, err)
+	assert.Nil(t, repo)
+}
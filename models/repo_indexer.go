@@ -18,6 +18,8 @@ const (
 	RepoIndexerTypeCode RepoIndexerType = iota // 0
 	// RepoIndexerTypeStats repository stats indexer
 	RepoIndexerTypeStats // 1
+	// RepoIndexerTypeInsights repository insights indexer
+	RepoIndexerTypeInsights // 2
 )
 
 // RepoIndexerStatus status of a repo's entry in the repo indexer
@@ -67,6 +69,10 @@ func (repo *Repository) getIndexerStatus(e Engine, indexerType RepoIndexerType)
 		if repo.StatsIndexerStatus != nil {
 			return repo.StatsIndexerStatus, nil
 		}
+	case RepoIndexerTypeInsights:
+		if repo.InsightsIndexerStatus != nil {
+			return repo.InsightsIndexerStatus, nil
+		}
 	}
 	status := &RepoIndexerStatus{RepoID: repo.ID}
 	if has, err := e.Where("`indexer_type` = ?", indexerType).Get(status); err != nil {
@@ -80,6 +86,8 @@ func (repo *Repository) getIndexerStatus(e Engine, indexerType RepoIndexerType)
 		repo.CodeIndexerStatus = status
 	case RepoIndexerTypeStats:
 		repo.StatsIndexerStatus = status
+	case RepoIndexerTypeInsights:
+		repo.InsightsIndexerStatus = status
 	}
 	return status, nil
 }
@@ -117,3 +125,20 @@ func (repo *Repository) updateIndexerStatus(e Engine, indexerType RepoIndexerTyp
 func (repo *Repository) UpdateIndexerStatus(indexerType RepoIndexerType, sha string) error {
 	return repo.updateIndexerStatus(x, indexerType, sha)
 }
+
+// ResetIndexerStatus deletes the indexer status, so that the repository is
+// indexed again from scratch
+func (repo *Repository) ResetIndexerStatus(indexerType RepoIndexerType) error {
+	if _, err := x.Where("`repo_id` = ? AND `indexer_type` = ?", repo.ID, indexerType).Delete(new(RepoIndexerStatus)); err != nil {
+		return fmt.Errorf("ResetIndexerStatus: Unable to delete repoIndexerStatus for repo: %s Error: %v", repo.FullName(), err)
+	}
+	switch indexerType {
+	case RepoIndexerTypeCode:
+		repo.CodeIndexerStatus = nil
+	case RepoIndexerTypeStats:
+		repo.StatsIndexerStatus = nil
+	case RepoIndexerTypeInsights:
+		repo.InsightsIndexerStatus = nil
+	}
+	return nil
+}
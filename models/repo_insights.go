@@ -0,0 +1,389 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// RepoContributorDay describes the commits of an author to the default branch
+// of a repository on a day
+type RepoContributorDay struct {
+	ID        int64              `xorm:"pk autoincr"`
+	RepoID    int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Email     string             `xorm:"VARCHAR(255) UNIQUE(s) NOT NULL"`
+	Name      string             `xorm:"VARCHAR(255)"`
+	Day       timeutil.TimeStamp `xorm:"UNIQUE(s) NOT NULL"`
+	Commits   int64              `xorm:"NOT NULL DEFAULT 0"`
+	Additions int64              `xorm:"NOT NULL DEFAULT 0"`
+	Deletions int64              `xorm:"NOT NULL DEFAULT 0"`
+	// CoAuthored is the number of commits of other authors crediting this
+	// one with a Co-authored-by trailer, they are not part of Commits
+	CoAuthored int64 `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// RepoPunchCard describes the commits to the default branch of a repository
+// by the hour of the week, in the local time of their authors
+type RepoPunchCard struct {
+	ID      int64 `xorm:"pk autoincr"`
+	RepoID  int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Weekday int   `xorm:"UNIQUE(s) NOT NULL"`
+	Hour    int   `xorm:"UNIQUE(s) NOT NULL"`
+	Commits int64 `xorm:"NOT NULL DEFAULT 0"`
+}
+
+type repoContributorDayKey struct {
+	Email string
+	Day   timeutil.TimeStamp
+}
+
+// RepoInsightsUpdate aggregates the commits added to the default branch of a
+// repository since its insights were last updated
+type RepoInsightsUpdate struct {
+	// Reset is true if the previous aggregates have to be replaced, e.g.
+	// after a force push
+	Reset     bool
+	days      map[repoContributorDayKey]*RepoContributorDay
+	punchCard [7][24]int64
+}
+
+// NewRepoInsightsUpdate creates an empty update of the insights
+func NewRepoInsightsUpdate(reset bool) *RepoInsightsUpdate {
+	return &RepoInsightsUpdate{
+		Reset: reset,
+		days:  make(map[repoContributorDayKey]*RepoContributorDay),
+	}
+}
+
+// startOfDay returns the midnight UTC of the day of the time
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// StartOfWeek returns the Sunday midnight UTC of the week of the time, as
+// the weeks of the insights start on Sunday like the GitHub statistics
+func StartOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, -int(day.Weekday()))
+}
+
+// day returns the aggregate of the commits of the email on the day of the time
+func (u *RepoInsightsUpdate) day(name, email string, when time.Time) *RepoContributorDay {
+	key := repoContributorDayKey{
+		Email: email,
+		Day:   timeutil.TimeStamp(startOfDay(when).Unix()),
+	}
+	day, ok := u.days[key]
+	if !ok {
+		day = &RepoContributorDay{
+			Email: key.Email,
+			Name:  name,
+			Day:   key.Day,
+		}
+		u.days[key] = day
+	}
+	return day
+}
+
+// AddCommit adds a commit to the update, its co-authors are credited on the
+// day of the commit without its changed lines
+func (u *RepoInsightsUpdate) AddCommit(commit *git.CommitNumstat) {
+	day := u.day(commit.Name, commit.Email, commit.When)
+	day.Commits++
+	day.Additions += commit.Additions
+	day.Deletions += commit.Deletions
+
+	for _, coAuthor := range commit.CoAuthors {
+		u.day(coAuthor.Name, strings.ToLower(coAuthor.Email), commit.When).CoAuthored++
+	}
+
+	u.punchCard[commit.When.Weekday()][commit.When.Hour()]++
+}
+
+// UpdateInsights adds the aggregated commits to the insights of the
+// repository, whose default branch is at the given commit
+func (repo *Repository) UpdateInsights(commitID string, update *RepoInsightsUpdate) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if update.Reset {
+		if err := deleteBeans(sess,
+			&RepoContributorDay{RepoID: repo.ID},
+			&RepoPunchCard{RepoID: repo.ID},
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, day := range update.days {
+		affected, err := sess.Where("repo_id = ? AND email = ? AND day = ?", repo.ID, day.Email, day.Day).
+			Incr("commits", day.Commits).
+			Incr("additions", day.Additions).
+			Incr("deletions", day.Deletions).
+			Incr("co_authored", day.CoAuthored).
+			Update(new(RepoContributorDay))
+		if err != nil {
+			return err
+		} else if affected > 0 {
+			continue
+		}
+		day.RepoID = repo.ID
+		if _, err = sess.Insert(day); err != nil {
+			return err
+		}
+	}
+
+	for weekday, hours := range update.punchCard {
+		for hour, commits := range hours {
+			if commits == 0 {
+				continue
+			}
+			affected, err := sess.Where("repo_id = ? AND weekday = ? AND hour = ?", repo.ID, weekday, hour).
+				Incr("commits", commits).
+				Update(new(RepoPunchCard))
+			if err != nil {
+				return err
+			} else if affected > 0 {
+				continue
+			}
+			if _, err = sess.Insert(&RepoPunchCard{
+				RepoID:  repo.ID,
+				Weekday: weekday,
+				Hour:    hour,
+				Commits: commits,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := repo.updateIndexerStatus(sess, RepoIndexerTypeInsights, commitID); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// HasInsights returns if the insights of the repository were computed
+func (repo *Repository) HasInsights() (bool, error) {
+	status, err := repo.GetIndexerStatus(RepoIndexerTypeInsights)
+	if err != nil {
+		return false, err
+	}
+	return len(status.CommitSha) > 0, nil
+}
+
+// getRepoContributorDays returns the commits of the contributors to the
+// repository by day since the given time, ordered by day
+func getRepoContributorDays(e Engine, repoID int64, since timeutil.TimeStamp) ([]*RepoContributorDay, error) {
+	days := make([]*RepoContributorDay, 0, 50)
+	return days, e.
+		Where("repo_id = ? AND day >= ?", repoID, since).
+		Asc("day").
+		Find(&days)
+}
+
+// RepoInsightsWeek represents the commits to a repository in a week
+type RepoInsightsWeek struct {
+	Week      timeutil.TimeStamp
+	Commits   int64
+	Additions int64
+	Deletions int64
+}
+
+// RepoContributorStats represents the commits of a contributor to a
+// repository by week, including the ones co-authored by the contributor
+type RepoContributorStats struct {
+	Name  string
+	Email string
+	// User is nil if the email does not belong to a user
+	User  *User
+	Total int64
+	// CoAuthored is the part of Total co-authored by the contributor
+	CoAuthored int64
+	Weeks      []*RepoInsightsWeek
+}
+
+// weekIndex returns the index of the week of the day, counted from the week
+// starting at first
+func weekIndex(first time.Time, day timeutil.TimeStamp) int {
+	return int(StartOfWeek(day.AsTime()).Sub(first).Hours() / (24 * 7))
+}
+
+// newInsightsWeeks returns the consecutive weeks from the first to the last
+// week
+func newInsightsWeeks(first, last time.Time) []*RepoInsightsWeek {
+	weeks := make([]*RepoInsightsWeek, 0, int(last.Sub(first).Hours()/(24*7))+1)
+	for week := first; !week.After(last); week = week.AddDate(0, 0, 7) {
+		weeks = append(weeks, &RepoInsightsWeek{Week: timeutil.TimeStamp(week.Unix())})
+	}
+	return weeks
+}
+
+// GetRepoContributorStats returns the weekly commits of every contributor to
+// the default branch of the repository, ordered by the number of commits.
+// The emails of the same user are counted as one contributor, and the commits
+// crediting a contributor as co-author are counted without their lines.
+func GetRepoContributorStats(repo *Repository) ([]*RepoContributorStats, error) {
+	days, err := getRepoContributorDays(x, repo.ID, 0)
+	if err != nil || len(days) == 0 {
+		return nil, err
+	}
+
+	first := StartOfWeek(days[0].Day.AsTime())
+	last := StartOfWeek(days[len(days)-1].Day.AsTime())
+
+	users := make(map[string]*User)
+	contributors := make(map[string]*RepoContributorStats)
+	stats := make([]*RepoContributorStats, 0, 10)
+	for _, day := range days {
+		user, ok := users[day.Email]
+		if !ok {
+			user, err = GetUserByEmail(day.Email)
+			if err != nil && !IsErrUserNotExist(err) {
+				return nil, err
+			}
+			users[day.Email] = user
+		}
+
+		key := "email:" + day.Email
+		if user != nil {
+			key = "user:" + user.Name
+		}
+		contributor, ok := contributors[key]
+		if !ok {
+			contributor = &RepoContributorStats{
+				Name:  day.Name,
+				Email: day.Email,
+				User:  user,
+				Weeks: newInsightsWeeks(first, last),
+			}
+			contributors[key] = contributor
+			stats = append(stats, contributor)
+		}
+		contributor.Total += day.Commits + day.CoAuthored
+		contributor.CoAuthored += day.CoAuthored
+		week := contributor.Weeks[weekIndex(first, day.Day)]
+		week.Commits += day.Commits + day.CoAuthored
+		week.Additions += day.Additions
+		week.Deletions += day.Deletions
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Total > stats[j].Total
+	})
+	return stats, nil
+}
+
+// RepoCommitActivity represents the commits to a repository in a week by day
+type RepoCommitActivity struct {
+	Week  timeutil.TimeStamp
+	Days  [7]int64
+	Total int64
+}
+
+// GetRepoCommitActivity returns the daily commits to the default branch of
+// the repository in the last 52 weeks, including the current one
+func GetRepoCommitActivity(repo *Repository) ([]*RepoCommitActivity, error) {
+	last := StartOfWeek(time.Now())
+	first := last.AddDate(0, 0, -7*51)
+	days, err := getRepoContributorDays(x, repo.ID, timeutil.TimeStamp(first.Unix()))
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make([]*RepoCommitActivity, 52)
+	for i := range activity {
+		activity[i] = &RepoCommitActivity{Week: timeutil.TimeStamp(first.AddDate(0, 0, 7*i).Unix())}
+	}
+	for _, day := range days {
+		idx := weekIndex(first, day.Day)
+		if idx >= len(activity) {
+			continue
+		}
+		activity[idx].Days[day.Day.AsTime().UTC().Weekday()] += day.Commits
+		activity[idx].Total += day.Commits
+	}
+	return activity, nil
+}
+
+// GetRepoCodeFrequency returns the weekly additions and deletions on the
+// default branch of the repository
+func GetRepoCodeFrequency(repo *Repository) ([]*RepoInsightsWeek, error) {
+	days, err := getRepoContributorDays(x, repo.ID, 0)
+	if err != nil || len(days) == 0 {
+		return nil, err
+	}
+
+	first := StartOfWeek(days[0].Day.AsTime())
+	weeks := newInsightsWeeks(first, StartOfWeek(days[len(days)-1].Day.AsTime()))
+	for _, day := range days {
+		week := weeks[weekIndex(first, day.Day)]
+		week.Commits += day.Commits
+		week.Additions += day.Additions
+		week.Deletions += day.Deletions
+	}
+	return weeks, nil
+}
+
+// GetRepoParticipation returns the weekly commits to the default branch of
+// the repository in the last 52 weeks by all contributors and by its owner
+func GetRepoParticipation(repo *Repository) (all, owner []int64, err error) {
+	first := StartOfWeek(time.Now()).AddDate(0, 0, -7*51)
+	days, err := getRepoContributorDays(x, repo.ID, timeutil.TimeStamp(first.Unix()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ownerEmails := make(map[string]bool)
+	if err = repo.GetOwner(); err != nil {
+		return nil, nil, err
+	}
+	if !repo.Owner.IsOrganization() {
+		emails, err := GetEmailAddresses(repo.OwnerID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, email := range emails {
+			if email.IsActivated {
+				ownerEmails[strings.ToLower(email.Email)] = true
+			}
+		}
+		ownerEmails[strings.ToLower(repo.Owner.Email)] = true
+		ownerEmails[strings.ToLower(repo.Owner.GetEmail())] = true
+	}
+
+	all = make([]int64, 52)
+	owner = make([]int64, 52)
+	for _, day := range days {
+		idx := weekIndex(first, day.Day)
+		if idx >= len(all) {
+			continue
+		}
+		all[idx] += day.Commits
+		if ownerEmails[day.Email] {
+			owner[idx] += day.Commits
+		}
+	}
+	return all, owner, nil
+}
+
+// GetRepoPunchCard returns the commits to the default branch of the
+// repository by the hour of the week
+func GetRepoPunchCard(repo *Repository) ([]*RepoPunchCard, error) {
+	punchCard := make([]*RepoPunchCard, 0, 7*24)
+	return punchCard, x.
+		Where("repo_id = ?", repo.ID).
+		Asc("weekday", "hour").
+		Find(&punchCard)
+}
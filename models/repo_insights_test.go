@@ -0,0 +1,139 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartOfWeek(t *testing.T) {
+	// Wednesday in UTC+10, Tuesday in UTC
+	when := time.Date(2020, 7, 1, 8, 0, 0, 0, time.FixedZone("", 10*60*60))
+	assert.Equal(t, time.Date(2020, 6, 28, 0, 0, 0, 0, time.UTC), StartOfWeek(when))
+	assert.Equal(t, time.Date(2020, 6, 28, 0, 0, 0, 0, time.UTC), StartOfWeek(time.Date(2020, 6, 28, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestUpdateInsights(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	has, err := repo.HasInsights()
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	now := time.Now()
+	update := NewRepoInsightsUpdate(true)
+	update.AddCommit(&git.CommitNumstat{Name: user2.Name, Email: user2.Email, When: now, Additions: 10, Deletions: 2})
+	update.AddCommit(&git.CommitNumstat{Name: user2.Name, Email: user2.Email, When: now, Additions: 1})
+	update.AddCommit(&git.CommitNumstat{Name: "Someone", Email: "someone@example.com", When: now.AddDate(0, 0, -14), Deletions: 5})
+	assert.NoError(t, repo.UpdateInsights("1234", update))
+
+	has, err = repo.HasInsights()
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	// Incremental updates add to the aggregates
+	update = NewRepoInsightsUpdate(false)
+	update.AddCommit(&git.CommitNumstat{Name: user2.Name, Email: user2.Email, When: now, Additions: 3})
+	assert.NoError(t, repo.UpdateInsights("5678", update))
+	AssertCount(t, &RepoContributorDay{RepoID: repo.ID}, 2)
+
+	stats, err := GetRepoContributorStats(repo)
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 2) {
+		assert.EqualValues(t, 3, stats[0].Total)
+		assert.EqualValues(t, user2.ID, stats[0].User.ID)
+		assert.Len(t, stats[0].Weeks, 3)
+		assert.EqualValues(t, 14, stats[0].Weeks[2].Additions)
+		assert.EqualValues(t, 2, stats[0].Weeks[2].Deletions)
+		assert.Nil(t, stats[1].User)
+		assert.EqualValues(t, 1, stats[1].Weeks[0].Commits)
+	}
+
+	activity, err := GetRepoCommitActivity(repo)
+	assert.NoError(t, err)
+	if assert.Len(t, activity, 52) {
+		assert.EqualValues(t, 3, activity[51].Total)
+		assert.EqualValues(t, 3, activity[51].Days[now.UTC().Weekday()])
+		assert.EqualValues(t, 1, activity[49].Total)
+	}
+
+	weeks, err := GetRepoCodeFrequency(repo)
+	assert.NoError(t, err)
+	if assert.Len(t, weeks, 3) {
+		assert.EqualValues(t, 5, weeks[0].Deletions)
+		assert.EqualValues(t, 0, weeks[1].Commits)
+	}
+
+	all, owner, err := GetRepoParticipation(repo)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, all[51])
+	assert.EqualValues(t, 1, all[49])
+	assert.EqualValues(t, 3, owner[51])
+	assert.EqualValues(t, 0, owner[49])
+
+	punchCard, err := GetRepoPunchCard(repo)
+	assert.NoError(t, err)
+	var commits int64
+	for _, hour := range punchCard {
+		commits += hour.Commits
+	}
+	assert.EqualValues(t, 4, commits)
+
+	// A reset replaces the aggregates
+	update = NewRepoInsightsUpdate(true)
+	update.AddCommit(&git.CommitNumstat{Name: user2.Name, Email: user2.Email, When: now})
+	assert.NoError(t, repo.UpdateInsights("9abc", update))
+	AssertCount(t, &RepoContributorDay{RepoID: repo.ID}, 1)
+	AssertCount(t, &RepoPunchCard{RepoID: repo.ID}, 1)
+}
+
+func TestUpdateInsightsCoAuthors(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	now := time.Now()
+	update := NewRepoInsightsUpdate(true)
+	update.AddCommit(&git.CommitNumstat{
+		Name:      "Someone",
+		Email:     "someone@example.com",
+		When:      now,
+		Additions: 10,
+		CoAuthors: []*git.Signature{{Name: user2.Name, Email: user2.Email}},
+	})
+	update.AddCommit(&git.CommitNumstat{Name: "Someone", Email: "someone@example.com", When: now, Additions: 2})
+	assert.NoError(t, repo.UpdateInsights("1234", update))
+
+	stats, err := GetRepoContributorStats(repo)
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 2) {
+		assert.Nil(t, stats[0].User)
+		assert.EqualValues(t, 2, stats[0].Total)
+		assert.EqualValues(t, 0, stats[0].CoAuthored)
+		assert.EqualValues(t, user2.ID, stats[1].User.ID)
+		assert.EqualValues(t, 1, stats[1].Total)
+		assert.EqualValues(t, 1, stats[1].CoAuthored)
+		assert.EqualValues(t, 1, stats[1].Weeks[0].Commits)
+		assert.EqualValues(t, 0, stats[1].Weeks[0].Additions)
+	}
+
+	// The co-authored commits are not counted twice in the totals
+	activity, err := GetRepoCommitActivity(repo)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, activity[51].Total)
+	weeks, err := GetRepoCodeFrequency(repo)
+	assert.NoError(t, err)
+	if assert.Len(t, weeks, 1) {
+		assert.EqualValues(t, 2, weeks[0].Commits)
+		assert.EqualValues(t, 12, weeks[0].Additions)
+	}
+}
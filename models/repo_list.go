@@ -9,9 +9,11 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 
 	"xorm.io/builder"
+	"xorm.io/xorm"
 )
 
 // RepositoryListDefaultPageSize is the default number of repositories
@@ -36,6 +38,17 @@ func (repos RepositoryList) Swap(i, j int) {
 	repos[i], repos[j] = repos[j], repos[i]
 }
 
+// LastUpdatedUnix returns the most recent update time of the repositories.
+func (repos RepositoryList) LastUpdatedUnix() timeutil.TimeStamp {
+	var updated timeutil.TimeStamp
+	for _, repo := range repos {
+		if repo.UpdatedUnix > updated {
+			updated = repo.UpdatedUnix
+		}
+	}
+	return updated
+}
+
 // RepositoryListOfMap make list from values of map
 func RepositoryListOfMap(repoMap map[int64]*Repository) RepositoryList {
 	return RepositoryList(valuesRepository(repoMap))
@@ -141,6 +154,7 @@ type SearchRepoOptions struct {
 	OrderBy         SearchOrderBy
 	Private         bool // Include private repositories in results
 	StarredByID     int64
+	StarListID      int64
 	AllPublic       bool // Include also all public repositories of users and public organisations
 	AllLimited      bool // Include also all public repositories of limited organisations
 	// None -> include public and private
@@ -237,6 +251,10 @@ func SearchRepositoryCondition(opts *SearchRepoOptions) builder.Cond {
 		cond = cond.And(builder.In("id", builder.Select("repo_id").From("star").Where(builder.Eq{"uid": opts.StarredByID})))
 	}
 
+	if opts.StarListID > 0 {
+		cond = cond.And(builder.In("id", builder.Select("repo_id").From("star_list_repo").Where(builder.Eq{"star_list_id": opts.StarListID})))
+	}
+
 	// Restrict repositories to those the OwnerID owns or contributes to as per opts.Collaborate
 	if opts.OwnerID > 0 {
 		var accessCond = builder.NewCond()
@@ -251,17 +269,9 @@ func SearchRepositoryCondition(opts *SearchRepoOptions) builder.Cond {
 				builder.Neq{"owner_id": opts.OwnerID},
 				// 2. But we can see because of:
 				builder.Or(
-					// A. We have access
-					builder.In("`repository`.id",
-						builder.Select("`access`.repo_id").
-							From("access").
-							Where(builder.Eq{"`access`.user_id": opts.OwnerID})),
-					// B. We are in a team for
-					builder.In("`repository`.id", builder.Select("`team_repo`.repo_id").
-						From("team_repo").
-						Where(builder.Eq{"`team_user`.uid": opts.OwnerID}).
-						Join("INNER", "team_user", "`team_user`.team_id = `team_repo`.team_id")),
-					// C. Public repositories in private organizations that we are member of
+					// A. We have access or are in a team for
+					accessibleRepoIDsCondition(opts.OwnerID),
+					// B. Public repositories in private organizations that we are member of
 					builder.And(
 						builder.Eq{"`repository`.is_private": false},
 						builder.In("`repository`.owner_id",
@@ -351,11 +361,64 @@ func SearchRepositoryCondition(opts *SearchRepoOptions) builder.Cond {
 // it returns results in given range and number of total results.
 func SearchRepository(opts *SearchRepoOptions) (RepositoryList, int64, error) {
 	cond := SearchRepositoryCondition(opts)
-	return SearchRepositoryByCondition(opts, cond, true)
+	count, err := countRepositoriesByOwnerCounters(opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return searchRepository(opts, cond, true, count)
+}
+
+// countRepositoriesByOwnerCounters returns the number of repositories matching
+// the options from the repository counters of the owner if they are enough to
+// know it, otherwise -1. This saves counting the repositories of an owner with
+// many repositories for every page.
+func countRepositoriesByOwnerCounters(opts *SearchRepoOptions) (int64, error) {
+	if opts.OwnerID <= 0 || opts.Keyword != "" || opts.StarredByID > 0 || opts.StarListID > 0 ||
+		opts.AllPublic || opts.AllLimited ||
+		opts.IsPrivate != util.OptionalBoolNone ||
+		opts.Fork != util.OptionalBoolNone ||
+		opts.Template != util.OptionalBoolNone ||
+		opts.Mirror != util.OptionalBoolNone ||
+		opts.Archived != util.OptionalBoolNone ||
+		opts.HasMilestones != util.OptionalBoolNone ||
+		opts.Collaborate == util.OptionalBoolTrue ||
+		(opts.Actor != nil && opts.Actor.IsRestricted) {
+		return -1, nil
+	}
+
+	owner, err := getUserByID(x, opts.OwnerID)
+	if err != nil {
+		if IsErrUserNotExist(err) {
+			return -1, nil
+		}
+		return -1, err
+	}
+	// Users also collaborate on repositories of others unless excluded,
+	// organizations never do.
+	if opts.Collaborate == util.OptionalBoolNone && !owner.IsOrganization() {
+		return -1, nil
+	}
+
+	if !opts.Private {
+		if owner.IsOrganization() && owner.Visibility != structs.VisibleTypePublic {
+			return 0, nil
+		}
+		return int64(owner.NumRepos - owner.NumPrivateRepos), nil
+	}
+	if opts.Actor == nil || opts.Actor.IsAdmin || opts.Actor.ID == opts.OwnerID {
+		return int64(owner.NumRepos), nil
+	}
+	return -1, nil
 }
 
 // SearchRepositoryByCondition search repositories by condition
 func SearchRepositoryByCondition(opts *SearchRepoOptions, cond builder.Cond, loadAttributes bool) (RepositoryList, int64, error) {
+	return searchRepository(opts, cond, loadAttributes, -1)
+}
+
+// searchRepository searches repositories by condition, count is the known
+// number of matching repositories or -1 to count them
+func searchRepository(opts *SearchRepoOptions, cond builder.Cond, loadAttributes bool, count int64) (RepositoryList, int64, error) {
 	if opts.Page <= 0 {
 		opts.Page = 1
 	}
@@ -368,15 +431,27 @@ func SearchRepositoryByCondition(opts *SearchRepoOptions, cond builder.Cond, loa
 		opts.OrderBy = SearchOrderBy(fmt.Sprintf("CASE WHEN owner_id = %d THEN 0 ELSE owner_id END, %s", opts.PriorityOwnerID, opts.OrderBy))
 	}
 
-	sess := x.NewSession()
-	defer sess.Close()
+	var (
+		repos RepositoryList
+		total int64
+	)
+	err := withReadEngine(func(e *xorm.Engine) (err error) {
+		repos, total, err = searchRepositoryByCondition(e, opts, cond, loadAttributes, count)
+		return err
+	})
+	return repos, total, err
+}
 
-	count, err := sess.
-		Where(cond).
-		Count(new(Repository))
+func searchRepositoryByCondition(e *xorm.Engine, opts *SearchRepoOptions, cond builder.Cond, loadAttributes bool, count int64) (_ RepositoryList, _ int64, err error) {
+	sess := e.NewSession()
+	defer sess.Close()
 
-	if err != nil {
-		return nil, 0, fmt.Errorf("Count: %v", err)
+	if count < 0 {
+		if count, err = sess.
+			Where(cond).
+			Count(new(Repository)); err != nil {
+			return nil, 0, fmt.Errorf("Count: %v", err)
+		}
 	}
 
 	repos := make(RepositoryList, 0, opts.PageSize)
@@ -419,20 +494,11 @@ func accessibleRepositoryCondition(user *User) builder.Cond {
 
 	if user != nil {
 		cond = cond.Or(
-			// 2. Be able to see all repositories that we have access to
-			builder.In("`repository`.id", builder.Select("repo_id").
-				From("`access`").
-				Where(builder.And(
-					builder.Eq{"user_id": user.ID},
-					builder.Gt{"mode": int(AccessModeNone)}))),
+			// 2. Be able to see all repositories that we have access to or are in a team for
+			accessibleRepoIDsCondition(user.ID),
 			// 3. Repositories that we directly own
 			builder.Eq{"`repository`.owner_id": user.ID},
-			// 4. Be able to see all repositories that we are in a team
-			builder.In("`repository`.id", builder.Select("`team_repo`.repo_id").
-				From("team_repo").
-				Where(builder.Eq{"`team_user`.uid": user.ID}).
-				Join("INNER", "team_user", "`team_user`.team_id = `team_repo`.team_id")),
-			// 5. Be able to see all public repos in private organizations that we are an org_user of
+			// 4. Be able to see all public repos in private organizations that we are an org_user of
 			builder.And(builder.Eq{"`repository`.is_private": false},
 				builder.In("`repository`.owner_id",
 					builder.Select("`org_user`.org_id").
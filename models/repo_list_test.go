@@ -293,3 +293,58 @@ func TestSearchRepositoryByTopicName(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchRepositoryCountByOwnerCounters(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user15 := AssertExistsAndLoadBean(t, &User{ID: 15}).(*User)
+	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	admin := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+
+	testCases := []struct {
+		name  string
+		opts  *SearchRepoOptions
+		known bool
+	}{
+		{name: "PublicOfUser",
+			opts:  &SearchRepoOptions{OwnerID: 15, Collaborate: util.OptionalBoolFalse},
+			known: true},
+		{name: "PrivateOfUserAsOwner",
+			opts:  &SearchRepoOptions{OwnerID: 15, Private: true, Actor: user15, Collaborate: util.OptionalBoolFalse},
+			known: true},
+		{name: "PrivateOfUserAsAdmin",
+			opts:  &SearchRepoOptions{OwnerID: 15, Private: true, Actor: admin, Collaborate: util.OptionalBoolFalse},
+			known: true},
+		{name: "PrivateOfUserAsOther",
+			opts: &SearchRepoOptions{OwnerID: 15, Private: true, Actor: user4, Collaborate: util.OptionalBoolFalse}},
+		{name: "PublicOfOrganization",
+			opts:  &SearchRepoOptions{OwnerID: 3},
+			known: true},
+		{name: "PublicOfPrivateOrganization",
+			opts:  &SearchRepoOptions{OwnerID: 23},
+			known: true},
+		{name: "CollaborativeOfUser",
+			opts: &SearchRepoOptions{OwnerID: 15, Private: true, Actor: user15}},
+		{name: "KeywordOfUser",
+			opts: &SearchRepoOptions{OwnerID: 15, Keyword: "repo", Collaborate: util.OptionalBoolFalse}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			count, err := countRepositoriesByOwnerCounters(testCase.opts)
+			assert.NoError(t, err)
+			if !testCase.known {
+				assert.EqualValues(t, -1, count)
+				return
+			}
+
+			_, expected, err := SearchRepositoryByCondition(testCase.opts, SearchRepositoryCondition(testCase.opts), false)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, count)
+
+			_, count, err = SearchRepository(testCase.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, count)
+		})
+	}
+}
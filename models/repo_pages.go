@@ -0,0 +1,141 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/generate"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// PagesSource is where the static site of a repository is served from
+type PagesSource int
+
+const (
+	// PagesSourceBranch serves a directory of a branch
+	PagesSourceBranch PagesSource = iota // 0
+	// PagesSourceArtifact serves a zip archive attached to the latest release
+	PagesSourceArtifact // 1
+)
+
+// PagesConfig represents the static site hosting configuration of a repository
+type PagesConfig struct {
+	ID                int64       `xorm:"pk autoincr"`
+	RepoID            int64       `xorm:"UNIQUE NOT NULL"`
+	Source            PagesSource `xorm:"NOT NULL DEFAULT 0"`
+	Branch            string      `xorm:"VARCHAR(255)"`
+	Dir               string      `xorm:"VARCHAR(255)"`
+	ArtifactName      string      `xorm:"VARCHAR(255)"`
+	CustomDomain      string      `xorm:"VARCHAR(255) INDEX"`
+	DomainVerifyToken string      `xorm:"VARCHAR(64)"`
+	IsDomainVerified  bool        `xorm:"NOT NULL DEFAULT false"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// IsArtifact returns true if the site is served from a release attachment
+func (cfg *PagesConfig) IsArtifact() bool {
+	return cfg.Source == PagesSourceArtifact
+}
+
+// DomainVerifyRecord returns the value of the DNS TXT record which proves the ownership of the custom domain
+func (cfg *PagesConfig) DomainVerifyRecord() string {
+	return "gitea-pages-verification=" + cfg.DomainVerifyToken
+}
+
+// GetPagesCacheKey returns the cache key of the version of the static site of the repository
+func GetPagesCacheKey(repoID int64) string {
+	return fmt.Sprintf("pages-version-%d", repoID)
+}
+
+// GetPagesConfig returns the static site hosting configuration of the repository,
+// it returns nil if the repository has no static site.
+func GetPagesConfig(repoID int64) (*PagesConfig, error) {
+	cfg := new(PagesConfig)
+	has, err := x.Where("repo_id = ?", repoID).Get(cfg)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return cfg, nil
+}
+
+// GetPagesConfigByDomain returns the static site hosting configuration of the verified custom domain,
+// it returns nil if no repository uses the domain.
+func GetPagesConfigByDomain(domain string) (*PagesConfig, error) {
+	cfg := new(PagesConfig)
+	has, err := x.Where("custom_domain = ? AND is_domain_verified = ?", strings.ToLower(domain), true).Get(cfg)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return cfg, nil
+}
+
+// SavePagesConfig creates or updates the static site hosting configuration of a repository.
+// Changing the custom domain requires its ownership to be verified again.
+func SavePagesConfig(cfg *PagesConfig) error {
+	cfg.CustomDomain = strings.ToLower(strings.TrimSpace(cfg.CustomDomain))
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if cfg.CustomDomain != "" {
+		used, err := sess.Where("custom_domain = ? AND repo_id <> ?", cfg.CustomDomain, cfg.RepoID).Exist(new(PagesConfig))
+		if err != nil {
+			return err
+		} else if used {
+			return ErrPagesDomainAlreadyUsed{cfg.CustomDomain}
+		}
+	}
+
+	existing := new(PagesConfig)
+	has, err := sess.Where("repo_id = ?", cfg.RepoID).Get(existing)
+	if err != nil {
+		return err
+	}
+	if !has || existing.CustomDomain != cfg.CustomDomain {
+		cfg.IsDomainVerified = false
+		if cfg.DomainVerifyToken, err = generate.GetRandomString(32); err != nil {
+			return err
+		}
+	} else {
+		cfg.IsDomainVerified = existing.IsDomainVerified
+		cfg.DomainVerifyToken = existing.DomainVerifyToken
+	}
+
+	if has {
+		cfg.ID = existing.ID
+		if _, err := sess.ID(cfg.ID).
+			Cols("source", "branch", "dir", "artifact_name", "custom_domain", "domain_verify_token", "is_domain_verified").
+			Update(cfg); err != nil {
+			return err
+		}
+	} else if _, err := sess.Insert(cfg); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// UpdatePagesDomainVerified marks the custom domain of the static site as verified or not
+func UpdatePagesDomainVerified(cfg *PagesConfig, verified bool) error {
+	cfg.IsDomainVerified = verified
+	_, err := x.ID(cfg.ID).Cols("is_domain_verified").Update(cfg)
+	return err
+}
+
+// DeletePagesConfig removes the static site of the repository
+func DeletePagesConfig(repoID int64) error {
+	_, err := x.Delete(&PagesConfig{RepoID: repoID})
+	return err
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavePagesConfig(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	cfg, err := GetPagesConfig(1)
+	assert.NoError(t, err)
+	assert.NotNil(t, cfg)
+	assert.EqualValues(t, "master", cfg.Branch)
+
+	cfg, err = GetPagesConfig(2)
+	assert.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	// setting a custom domain requires its verification
+	cfg = &PagesConfig{RepoID: 1, Source: PagesSourceBranch, Branch: "gh-pages", CustomDomain: " Docs.Example.com "}
+	assert.NoError(t, SavePagesConfig(cfg))
+	cfg = AssertExistsAndLoadBean(t, &PagesConfig{RepoID: 1}).(*PagesConfig)
+	assert.EqualValues(t, "gh-pages", cfg.Branch)
+	assert.EqualValues(t, "docs.example.com", cfg.CustomDomain)
+	assert.False(t, cfg.IsDomainVerified)
+	assert.Len(t, cfg.DomainVerifyToken, 32)
+
+	found, err := GetPagesConfigByDomain("docs.example.com")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	assert.NoError(t, UpdatePagesDomainVerified(cfg, true))
+	found, err = GetPagesConfigByDomain("DOCS.example.com")
+	assert.NoError(t, err)
+	assert.NotNil(t, found)
+	assert.EqualValues(t, 1, found.RepoID)
+
+	// the verification is kept while the domain does not change
+	token := cfg.DomainVerifyToken
+	assert.NoError(t, SavePagesConfig(&PagesConfig{RepoID: 1, Source: PagesSourceArtifact, ArtifactName: "site.zip", CustomDomain: "docs.example.com"}))
+	cfg = AssertExistsAndLoadBean(t, &PagesConfig{RepoID: 1}).(*PagesConfig)
+	assert.True(t, cfg.IsArtifact())
+	assert.True(t, cfg.IsDomainVerified)
+	assert.EqualValues(t, token, cfg.DomainVerifyToken)
+
+	// a domain cannot be used by two repositories
+	err = SavePagesConfig(&PagesConfig{RepoID: 2, CustomDomain: "docs.example.com"})
+	assert.True(t, IsErrPagesDomainAlreadyUsed(err))
+	AssertNotExistsBean(t, &PagesConfig{RepoID: 2})
+
+	assert.NoError(t, DeletePagesConfig(1))
+	AssertNotExistsBean(t, &PagesConfig{RepoID: 1})
+}
@@ -157,6 +157,15 @@ func getUserRepoPermission(e Engine, repo *Repository, user *User) (perm Permiss
 				perm)
 		}()
 	}
+	// bots never get more than their maximum access
+	if user != nil && user.IsBot() {
+		defer func() {
+			if err == nil {
+				err = capBotPermission(e, user, &perm)
+			}
+		}()
+	}
+
 	// anonymous user visit private repo.
 	// TODO: anonymous user visit public unit of private repo???
 	if user == nil && repo.IsPrivate {
@@ -241,10 +250,9 @@ func getUserRepoPermission(e Engine, repo *Repository, user *User) (perm Permiss
 	for _, u := range repo.Units {
 		var found bool
 		for _, team := range teams {
-			if team.unitEnabled(e, u.Type) {
-				m := perm.UnitsMode[u.Type]
-				if m < team.Authorize {
-					perm.UnitsMode[u.Type] = team.Authorize
+			if mode := team.unitAccessMode(e, u.Type); mode > AccessModeNone {
+				if perm.UnitsMode[u.Type] < mode {
+					perm.UnitsMode[u.Type] = mode
 				}
 				found = true
 			}
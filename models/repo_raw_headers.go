@@ -0,0 +1,197 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// RawRobotsPolicies are the X-Robots-Tag values a repository can choose for its raw files,
+// "none" is the same as "noindex, nofollow".
+var RawRobotsPolicies = []string{"all", "noindex", "nofollow", "none"}
+
+// RepoRawHeaders holds the HTTP headers of the files of a repository served by the raw and
+// media endpoints. The empty fields use the defaults of the [repository.raw] settings.
+type RepoRawHeaders struct {
+	ID     int64 `xorm:"pk autoincr"`
+	RepoID int64 `xorm:"UNIQUE NOT NULL"`
+	// AllowedOrigins is a newline separated list of the origins allowed to fetch the files
+	// with CORS requests, "*" allows any origin.
+	AllowedOrigins string `xorm:"TEXT"`
+	// CacheMaxAge is the number of seconds the files may be cached, -1 disables the caching.
+	CacheMaxAge int `xorm:"NOT NULL DEFAULT 0"`
+	// RobotsPolicy is sent in the X-Robots-Tag header, one of RawRobotsPolicies.
+	RobotsPolicy string `xorm:"VARCHAR(20) NOT NULL DEFAULT ''"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// ErrInvalidRawOrigin represents a "InvalidRawOrigin" kind of error.
+type ErrInvalidRawOrigin struct {
+	Origin string
+}
+
+// IsErrInvalidRawOrigin checks if an error is a ErrInvalidRawOrigin.
+func IsErrInvalidRawOrigin(err error) bool {
+	_, ok := err.(ErrInvalidRawOrigin)
+	return ok
+}
+
+func (err ErrInvalidRawOrigin) Error() string {
+	return fmt.Sprintf("invalid origin [origin: %s]", err.Origin)
+}
+
+// normalizeRawOrigin returns the origin in the form browsers send it, scheme://host[:port]
+func normalizeRawOrigin(origin string) (string, error) {
+	if origin == "*" {
+		return origin, nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", ErrInvalidRawOrigin{Origin: origin}
+	}
+	return u.Scheme + "://" + strings.ToLower(u.Host), nil
+}
+
+// GetAllowedOrigins returns the origins allowed to fetch the files with CORS requests.
+func (headers *RepoRawHeaders) GetAllowedOrigins() []string {
+	return splitPolicyLines(headers.AllowedOrigins)
+}
+
+// IsEmpty returns true if all the headers use the defaults.
+func (headers *RepoRawHeaders) IsEmpty() bool {
+	return len(headers.GetAllowedOrigins()) == 0 && headers.CacheMaxAge == 0 && headers.RobotsPolicy == ""
+}
+
+// AllowOrigin returns the value of the Access-Control-Allow-Origin header for a request from
+// the origin, or an empty string if the origin is not allowed.
+func (headers *RepoRawHeaders) AllowOrigin(origin string) string {
+	if origin == "" {
+		return ""
+	}
+	for _, allowed := range headers.GetAllowedOrigins() {
+		if allowed == "*" {
+			return "*"
+		}
+		if strings.EqualFold(allowed, origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// CacheControl returns the value of the Cache-Control header of the files.
+func (headers *RepoRawHeaders) CacheControl(isPrivate bool) string {
+	if headers.CacheMaxAge < 0 {
+		return "no-cache"
+	}
+	if isPrivate {
+		return "private,max-age=" + strconv.Itoa(headers.CacheMaxAge)
+	}
+	return "public,max-age=" + strconv.Itoa(headers.CacheMaxAge)
+}
+
+// RobotsTag returns the value of the X-Robots-Tag header of the files, or an empty string if
+// the header should not be sent.
+func (headers *RepoRawHeaders) RobotsTag() string {
+	if headers.RobotsPolicy == "all" {
+		return ""
+	}
+	return headers.RobotsPolicy
+}
+
+// GetRepoRawHeaders returns the raw headers of a repository, or nil if it has none.
+func GetRepoRawHeaders(repoID int64) (*RepoRawHeaders, error) {
+	headers := &RepoRawHeaders{RepoID: repoID}
+	has, err := x.Get(headers)
+	if err != nil || !has {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// GetEffectiveRepoRawHeaders returns the raw headers of a repository with the defaults of the
+// [repository.raw] settings filled in.
+func GetEffectiveRepoRawHeaders(repoID int64) (*RepoRawHeaders, error) {
+	headers := &RepoRawHeaders{RepoID: repoID}
+	if setting.Repository.Raw.AllowRepoHeaders {
+		existing, err := GetRepoRawHeaders(repoID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			headers = existing
+		}
+	}
+	if len(headers.GetAllowedOrigins()) == 0 {
+		headers.AllowedOrigins = strings.Join(setting.Repository.Raw.AllowedOrigins, "\n")
+	}
+	if headers.CacheMaxAge == 0 {
+		headers.CacheMaxAge = setting.Repository.Raw.CacheMaxAge
+	}
+	if headers.RobotsPolicy == "" {
+		headers.RobotsPolicy = setting.Repository.Raw.RobotsPolicy
+	}
+	return headers, nil
+}
+
+// UpdateRepoRawHeaders creates or updates the raw headers of a repository, it removes them if
+// they all use the defaults.
+func UpdateRepoRawHeaders(repoID int64, headers *RepoRawHeaders) error {
+	origins := headers.GetAllowedOrigins()
+	for i, origin := range origins {
+		var err error
+		if origins[i], err = normalizeRawOrigin(origin); err != nil {
+			return err
+		}
+	}
+	headers.AllowedOrigins = strings.Join(origins, "\n")
+	if headers.CacheMaxAge < -1 {
+		headers.CacheMaxAge = -1
+	}
+	if headers.RobotsPolicy != "" && !isRawRobotsPolicy(headers.RobotsPolicy) {
+		return fmt.Errorf("invalid robots policy: %s", headers.RobotsPolicy)
+	}
+
+	if headers.IsEmpty() {
+		return DeleteRepoRawHeaders(repoID)
+	}
+
+	headers.RepoID = repoID
+	existing, err := GetRepoRawHeaders(repoID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		_, err = x.Insert(headers)
+		return err
+	}
+	headers.ID = existing.ID
+	_, err = x.ID(headers.ID).AllCols().Update(headers)
+	return err
+}
+
+// DeleteRepoRawHeaders removes the raw headers of a repository.
+func DeleteRepoRawHeaders(repoID int64) error {
+	_, err := x.Delete(&RepoRawHeaders{RepoID: repoID})
+	return err
+}
+
+func isRawRobotsPolicy(policy string) bool {
+	for _, p := range RawRobotsPolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
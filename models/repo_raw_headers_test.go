@@ -0,0 +1,66 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoRawHeaders_AllowOrigin(t *testing.T) {
+	headers := &RepoRawHeaders{AllowedOrigins: "https://example.com\n\nhttp://localhost:8080\n"}
+	assert.Equal(t, "https://example.com", headers.AllowOrigin("https://example.com"))
+	assert.Equal(t, "http://localhost:8080", headers.AllowOrigin("http://localhost:8080"))
+	assert.Empty(t, headers.AllowOrigin("https://example.org"))
+	assert.Empty(t, headers.AllowOrigin(""))
+
+	headers.AllowedOrigins = "*"
+	assert.Equal(t, "*", headers.AllowOrigin("https://example.org"))
+}
+
+func TestUpdateRepoRawHeaders(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	defaults := setting.Repository.Raw
+	defer func() {
+		setting.Repository.Raw = defaults
+	}()
+	setting.Repository.Raw.AllowedOrigins = []string{"https://fonts.example.com"}
+	setting.Repository.Raw.RobotsPolicy = "nofollow"
+
+	headers, err := GetEffectiveRepoRawHeaders(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://fonts.example.com", headers.AllowedOrigins)
+	assert.Equal(t, "public,max-age=86400", headers.CacheControl(false))
+	assert.Equal(t, "nofollow", headers.RobotsTag())
+
+	for _, origin := range []string{"example.com", "ftp://example.com", "https://example.com/path", "https://"} {
+		err = UpdateRepoRawHeaders(1, &RepoRawHeaders{AllowedOrigins: origin})
+		assert.True(t, IsErrInvalidRawOrigin(err), origin)
+	}
+
+	assert.NoError(t, UpdateRepoRawHeaders(1, &RepoRawHeaders{
+		AllowedOrigins: "HTTPS://Example.com/\n",
+		CacheMaxAge:    -1,
+		RobotsPolicy:   "all",
+	}))
+	headers, err = GetEffectiveRepoRawHeaders(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com", headers.AllowedOrigins)
+	assert.Equal(t, "no-cache", headers.CacheControl(false))
+	assert.Empty(t, headers.RobotsTag())
+
+	setting.Repository.Raw.AllowRepoHeaders = false
+	headers, err = GetEffectiveRepoRawHeaders(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://fonts.example.com", headers.AllowedOrigins)
+	assert.Equal(t, "private,max-age=86400", headers.CacheControl(true))
+
+	assert.NoError(t, UpdateRepoRawHeaders(1, &RepoRawHeaders{}))
+	AssertNotExistsBean(t, &RepoRawHeaders{RepoID: 1})
+}
@@ -26,6 +26,7 @@ const (
 	commitsSigned signingMode = "commitssigned"
 	approved      signingMode = "approved"
 	noKey         signingMode = "nokey"
+	keyNotAllowed signingMode = "keynotallowed"
 )
 
 func signingModeFromStrings(modeStrings []string) []signingMode {
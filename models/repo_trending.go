@@ -0,0 +1,360 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// TrendingPeriod is the number of days the trending of the repositories is computed over
+type TrendingPeriod int
+
+// The periods the trending of the repositories is computed over
+const (
+	TrendingPeriodDaily   TrendingPeriod = 1
+	TrendingPeriodWeekly  TrendingPeriod = 7
+	TrendingPeriodMonthly TrendingPeriod = 30
+)
+
+// TrendingPeriods are the periods the trending of the repositories is computed over
+var TrendingPeriods = []TrendingPeriod{TrendingPeriodDaily, TrendingPeriodWeekly, TrendingPeriodMonthly}
+
+// The weights of the new stars, forks and activity in the trending score of a repository
+const (
+	trendingStarWeight     = 3
+	trendingForkWeight     = 2
+	trendingActivityWeight = 1
+)
+
+// trendingBatchSize is the number of repositories the trending is computed for at once
+const trendingBatchSize = 500
+
+// String returns the name of the period used in the URLs
+func (p TrendingPeriod) String() string {
+	switch p {
+	case TrendingPeriodWeekly:
+		return "weekly"
+	case TrendingPeriodMonthly:
+		return "monthly"
+	}
+	return "daily"
+}
+
+// ParseTrendingPeriod returns the trending period of the name, the daily period if the name is empty,
+// and false if the name is unknown
+func ParseTrendingPeriod(name string) (TrendingPeriod, bool) {
+	switch strings.ToLower(name) {
+	case "", "daily":
+		return TrendingPeriodDaily, true
+	case "weekly":
+		return TrendingPeriodWeekly, true
+	case "monthly":
+		return TrendingPeriodMonthly, true
+	}
+	return TrendingPeriodDaily, false
+}
+
+// RepoStatsSnapshot represents the numbers of stars and forks of a public repository on a day, the
+// trending of the repositories is computed from their differences
+type RepoStatsSnapshot struct {
+	ID       int64              `xorm:"pk autoincr"`
+	RepoID   int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Day      timeutil.TimeStamp `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	NumStars int                `xorm:"NOT NULL DEFAULT 0"`
+	NumForks int                `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// RepoTrending represents the new stars, forks and activity of a trending repository over a period
+type RepoTrending struct {
+	ID          int64              `xorm:"pk autoincr"`
+	RepoID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Period      TrendingPeriod     `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Stars       int                `xorm:"NOT NULL DEFAULT 0"`
+	Forks       int                `xorm:"NOT NULL DEFAULT 0"`
+	Activity    int                `xorm:"NOT NULL DEFAULT 0"`
+	Score       int                `xorm:"INDEX NOT NULL DEFAULT 0"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+
+	Repo *Repository `xorm:"-"`
+}
+
+// trendingDay returns the start of the day of the time
+func trendingDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// UpdateRepoTrending records the numbers of stars and forks of the public repositories of the day
+// and computes the trending repositories of every period from their new stars and forks since the
+// start of the period and the number of actions in them during it
+func UpdateRepoTrending(ctx context.Context) error {
+	return updateRepoTrending(ctx, time.Now())
+}
+
+func updateRepoTrending(ctx context.Context, now time.Time) error {
+	today := trendingDay(now)
+	oldest := today.AddDate(0, 0, -int(TrendingPeriodMonthly))
+	if _, err := x.Where("day < ?", oldest.Unix()).Delete(new(RepoStatsSnapshot)); err != nil {
+		return err
+	}
+
+	trendings := make([]*RepoTrending, 0, 50)
+	var lastID int64
+	for {
+		select {
+		case <-ctx.Done():
+			return ErrCancelledf("before computing the trending of the repositories after %d", lastID)
+		default:
+		}
+
+		repos := make([]*Repository, 0, trendingBatchSize)
+		if err := x.Where("id > ? AND is_private = ?", lastID, false).
+			Cols("id", "num_stars", "num_forks").
+			OrderBy("id").
+			Limit(trendingBatchSize).
+			Find(&repos); err != nil {
+			return err
+		}
+		if len(repos) == 0 {
+			break
+		}
+		lastID = repos[len(repos)-1].ID
+
+		batch, err := computeRepoTrending(repos, today)
+		if err != nil {
+			return err
+		}
+		trendings = append(trendings, batch...)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if _, err := sess.Where("1 = 1").Delete(new(RepoTrending)); err != nil {
+		return err
+	}
+	for i := 0; i < len(trendings); i += trendingBatchSize {
+		end := i + trendingBatchSize
+		if end > len(trendings) {
+			end = len(trendings)
+		}
+		if _, err := sess.Insert(trendings[i:end]); err != nil {
+			return err
+		}
+	}
+	if err := sess.Commit(); err != nil {
+		return err
+	}
+	log.Trace("Trending of the repositories computed: %d trending in all periods", len(trendings))
+	return nil
+}
+
+// computeRepoTrending records the snapshots of the day of the repositories and returns their
+// trending in every period they have new stars, forks or activity in
+func computeRepoTrending(repos []*Repository, today time.Time) ([]*RepoTrending, error) {
+	repoIDs := make([]int64, len(repos))
+	for i, repo := range repos {
+		repoIDs[i] = repo.ID
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+	if _, err := sess.Where("day = ?", today.Unix()).In("repo_id", repoIDs).Delete(new(RepoStatsSnapshot)); err != nil {
+		return nil, err
+	}
+	snapshots := make([]*RepoStatsSnapshot, len(repos))
+	for i, repo := range repos {
+		snapshots[i] = &RepoStatsSnapshot{
+			RepoID:   repo.ID,
+			Day:      timeutil.TimeStamp(today.Unix()),
+			NumStars: repo.NumStars,
+			NumForks: repo.NumForks,
+		}
+	}
+	if _, err := sess.Insert(&snapshots); err != nil {
+		return nil, err
+	}
+	if err := sess.Commit(); err != nil {
+		return nil, err
+	}
+
+	trendings := make([]*RepoTrending, 0, 10)
+	for _, period := range TrendingPeriods {
+		since := today.AddDate(0, 0, -int(period))
+
+		// the oldest snapshot of the period is the base of the new stars and forks, the
+		// repositories created during it have no older snapshot than the one of the day
+		oldSnapshots := make([]*RepoStatsSnapshot, 0, len(repos))
+		if err := x.Where("day >= ?", since.Unix()).
+			In("repo_id", repoIDs).
+			OrderBy("day DESC").
+			Find(&oldSnapshots); err != nil {
+			return nil, err
+		}
+		bases := make(map[int64]*RepoStatsSnapshot, len(repos))
+		for _, snapshot := range oldSnapshots {
+			bases[snapshot.RepoID] = snapshot
+		}
+
+		type repoActivity struct {
+			RepoID int64
+			Count  int
+		}
+		activities := make([]*repoActivity, 0, len(repos))
+		if err := x.Table("action").
+			Select("repo_id, count(*) AS count").
+			Where("user_id = act_user_id AND created_unix >= ?", since.Unix()).
+			In("repo_id", repoIDs).
+			GroupBy("repo_id").
+			Find(&activities); err != nil {
+			return nil, err
+		}
+		activity := make(map[int64]int, len(activities))
+		for _, a := range activities {
+			activity[a.RepoID] = a.Count
+		}
+
+		for _, repo := range repos {
+			trending := &RepoTrending{
+				RepoID:   repo.ID,
+				Period:   period,
+				Activity: activity[repo.ID],
+			}
+			if base, ok := bases[repo.ID]; ok {
+				if repo.NumStars > base.NumStars {
+					trending.Stars = repo.NumStars - base.NumStars
+				}
+				if repo.NumForks > base.NumForks {
+					trending.Forks = repo.NumForks - base.NumForks
+				}
+			}
+			trending.Score = trendingStarWeight*trending.Stars +
+				trendingForkWeight*trending.Forks +
+				trendingActivityWeight*trending.Activity
+			if trending.Score > 0 {
+				trendings = append(trendings, trending)
+			}
+		}
+	}
+	return trendings, nil
+}
+
+// TrendingOptions are the options of listing the trending repositories, languages and topics
+type TrendingOptions struct {
+	ListOptions
+	Period TrendingPeriod
+	Actor  *User
+	// the primary language of the repositories
+	Language string
+	// a topic of the repositories
+	Topic string
+}
+
+func (opts *TrendingOptions) toConds() builder.Cond {
+	cond := builder.NewCond().
+		And(builder.Eq{"repo_trending.period": opts.Period}).
+		And(builder.In("repo_trending.repo_id", AccessibleRepoIDsQuery(opts.Actor)))
+	if opts.Language != "" {
+		cond = cond.And(builder.In("repo_trending.repo_id", builder.Select("repo_id").
+			From("language_stat").
+			Where(builder.Eq{"is_primary": true}.And(builder.Expr("lower(language) = ?", strings.ToLower(opts.Language))))))
+	}
+	if opts.Topic != "" {
+		cond = cond.And(builder.In("repo_trending.repo_id", builder.Select("repo_topic.repo_id").
+			From("repo_topic").
+			InnerJoin("topic", "topic.id = repo_topic.topic_id").
+			Where(builder.Eq{"topic.name": strings.ToLower(opts.Topic)})))
+	}
+	return cond
+}
+
+// SearchTrendingRepos returns the trending repositories of the period which the actor can access,
+// the most trending first, and their total number
+func SearchTrendingRepos(opts *TrendingOptions) ([]*RepoTrending, int64, error) {
+	cond := opts.toConds()
+	count, err := x.Where(cond).Count(new(RepoTrending))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sess := x.Where(cond).OrderBy("score DESC, repo_id DESC")
+	if opts.PageSize > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	trendings := make([]*RepoTrending, 0, opts.PageSize)
+	if err := sess.Find(&trendings); err != nil {
+		return nil, 0, err
+	}
+	if len(trendings) == 0 {
+		return trendings, count, nil
+	}
+
+	repoIDs := make([]int64, len(trendings))
+	for i := range trendings {
+		repoIDs[i] = trendings[i].RepoID
+	}
+	reposMap, err := GetRepositoriesMapByIDs(repoIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	repos := make(RepositoryList, 0, len(trendings))
+	loaded := trendings[:0]
+	for _, trending := range trendings {
+		// the repository may have been deleted since the trending was computed
+		if trending.Repo = reposMap[trending.RepoID]; trending.Repo != nil {
+			repos = append(repos, trending.Repo)
+			loaded = append(loaded, trending)
+		}
+	}
+	if err := repos.LoadAttributes(); err != nil {
+		return nil, 0, err
+	}
+	return loaded, count, nil
+}
+
+// TrendingTopic represents a topic of trending repositories
+type TrendingTopic struct {
+	Name     string
+	NumRepos int
+	Score    int
+}
+
+// GetTrendingTopics returns the topics of the trending repositories of the period which the actor
+// can access, by the total score of their repositories
+func GetTrendingTopics(opts *TrendingOptions, limit int) ([]*TrendingTopic, error) {
+	topics := make([]*TrendingTopic, 0, limit)
+	return topics, x.Table("repo_trending").
+		Select("topic.name AS name, count(*) AS num_repos, sum(repo_trending.score) AS score").
+		Join("INNER", "repo_topic", "repo_topic.repo_id = repo_trending.repo_id").
+		Join("INNER", "topic", "topic.id = repo_topic.topic_id").
+		Where(opts.toConds()).
+		GroupBy("topic.name").
+		OrderBy("score DESC, topic.name").
+		Limit(limit).
+		Find(&topics)
+}
+
+// GetTrendingLanguages returns the primary languages of the trending repositories of the period
+// which the actor can access, by name
+func GetTrendingLanguages(opts *TrendingOptions) ([]string, error) {
+	languages := make([]string, 0, 10)
+	return languages, x.Table("repo_trending").
+		Join("INNER", "language_stat", "language_stat.repo_id = repo_trending.repo_id AND language_stat.is_primary = ?", true).
+		Where(opts.toConds()).
+		Distinct("language_stat.language").
+		OrderBy("language_stat.language").
+		Find(&languages)
+}
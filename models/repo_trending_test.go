@@ -0,0 +1,108 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrendingPeriod(t *testing.T) {
+	for name, expected := range map[string]TrendingPeriod{
+		"":        TrendingPeriodDaily,
+		"daily":   TrendingPeriodDaily,
+		"Weekly":  TrendingPeriodWeekly,
+		"monthly": TrendingPeriodMonthly,
+	} {
+		period, ok := ParseTrendingPeriod(name)
+		assert.True(t, ok)
+		assert.Equal(t, expected, period)
+	}
+	_, ok := ParseTrendingPeriod("yearly")
+	assert.False(t, ok)
+	assert.Equal(t, "weekly", TrendingPeriodWeekly.String())
+}
+
+func TestUpdateRepoTrending(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	now := time.Now()
+	today := trendingDay(now)
+	daysAgo := func(days int) timeutil.TimeStamp {
+		return timeutil.TimeStamp(today.AddDate(0, 0, -days).Unix())
+	}
+
+	// repo1 got 4 stars in the last 3 days and repo10 got a fork in the last 10 days
+	_, err := x.ID(1).Cols("num_stars").Update(&Repository{NumStars: 5})
+	assert.NoError(t, err)
+	_, err = x.Insert(&RepoStatsSnapshot{RepoID: 1, Day: daysAgo(3), NumStars: 1},
+		&RepoStatsSnapshot{RepoID: 1, Day: daysAgo(40)},
+		&RepoStatsSnapshot{RepoID: 10, Day: daysAgo(10), NumForks: 0},
+		&Action{UserID: 2, ActUserID: 2, RepoID: 1, OpType: ActionCommitRepo, CreatedUnix: timeutil.TimeStamp(now.Unix())},
+		// the copies of an action in the feeds of the other users are not counted
+		&Action{UserID: 1, ActUserID: 2, RepoID: 1, OpType: ActionCommitRepo, CreatedUnix: timeutil.TimeStamp(now.Unix())})
+	assert.NoError(t, err)
+
+	assert.NoError(t, updateRepoTrending(context.Background(), now))
+	// running the task again on the same day replaces the snapshots of the day
+	assert.NoError(t, updateRepoTrending(context.Background(), now))
+
+	AssertNotExistsBean(t, &RepoStatsSnapshot{RepoID: 1, Day: daysAgo(40)})
+	AssertExistsAndLoadBean(t, &RepoStatsSnapshot{RepoID: 1, Day: daysAgo(0), NumStars: 5})
+	// private repositories are not trending
+	AssertNotExistsBean(t, &RepoStatsSnapshot{RepoID: 2})
+
+	daily := AssertExistsAndLoadBean(t, &RepoTrending{RepoID: 1, Period: TrendingPeriodDaily}).(*RepoTrending)
+	assert.Equal(t, 0, daily.Stars)
+	assert.Equal(t, 1, daily.Activity)
+	assert.Equal(t, 1, daily.Score)
+	weekly := AssertExistsAndLoadBean(t, &RepoTrending{RepoID: 1, Period: TrendingPeriodWeekly}).(*RepoTrending)
+	assert.Equal(t, 4, weekly.Stars)
+	assert.Equal(t, 13, weekly.Score)
+	AssertNotExistsBean(t, &RepoTrending{RepoID: 10, Period: TrendingPeriodWeekly})
+	monthly := AssertExistsAndLoadBean(t, &RepoTrending{RepoID: 10, Period: TrendingPeriodMonthly}).(*RepoTrending)
+	assert.Equal(t, 1, monthly.Forks)
+	assert.Equal(t, 2, monthly.Score)
+
+	trendings, count, err := SearchTrendingRepos(&TrendingOptions{Period: TrendingPeriodMonthly})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	if assert.Len(t, trendings, 2) {
+		assert.EqualValues(t, 1, trendings[0].RepoID)
+		assert.EqualValues(t, "repo1", trendings[0].Repo.Name)
+		assert.NotNil(t, trendings[0].Repo.Owner)
+		assert.EqualValues(t, 10, trendings[1].RepoID)
+	}
+
+	trendings, _, err = SearchTrendingRepos(&TrendingOptions{Period: TrendingPeriodMonthly, Topic: "golang"})
+	assert.NoError(t, err)
+	if assert.Len(t, trendings, 1) {
+		assert.EqualValues(t, 1, trendings[0].RepoID)
+	}
+
+	topics, err := GetTrendingTopics(&TrendingOptions{Period: TrendingPeriodMonthly}, 10)
+	assert.NoError(t, err)
+	names := make([]string, len(topics))
+	for i, topic := range topics {
+		names[i] = topic.Name
+	}
+	assert.Contains(t, names, "golang")
+
+	_, err = x.Insert(&LanguageStat{RepoID: 10, Language: "Go", IsPrimary: true})
+	assert.NoError(t, err)
+	languages, err := GetTrendingLanguages(&TrendingOptions{Period: TrendingPeriodMonthly})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Go"}, languages)
+	trendings, _, err = SearchTrendingRepos(&TrendingOptions{Period: TrendingPeriodMonthly, Language: "go"})
+	assert.NoError(t, err)
+	if assert.Len(t, trendings, 1) {
+		assert.EqualValues(t, 10, trendings[0].RepoID)
+	}
+}
@@ -93,6 +93,13 @@ type PullRequestsConfig struct {
 	AllowRebase               bool
 	AllowRebaseMerge          bool
 	AllowSquash               bool
+	AllowFastForwardOnly      bool
+	// EnableDCOCheck reports whether the commits of pull requests are signed off by their
+	// authors as a commit status
+	EnableDCOCheck bool
+	// DCOAllowlist is a newline separated list of author names and emails, like bots, whose
+	// commits need no sign-off
+	DCOAllowlist string
 }
 
 // FromDB fills up a PullRequestsConfig from serialized format.
@@ -110,7 +117,20 @@ func (cfg *PullRequestsConfig) IsMergeStyleAllowed(mergeStyle MergeStyle) bool {
 	return mergeStyle == MergeStyleMerge && cfg.AllowMerge ||
 		mergeStyle == MergeStyleRebase && cfg.AllowRebase ||
 		mergeStyle == MergeStyleRebaseMerge && cfg.AllowRebaseMerge ||
-		mergeStyle == MergeStyleSquash && cfg.AllowSquash
+		mergeStyle == MergeStyleSquash && cfg.AllowSquash ||
+		mergeStyle == MergeStyleFastForwardOnly && cfg.AllowFastForwardOnly
+}
+
+// AllowedMergeStyles returns the merge styles allowed in the repository, in the order
+// they are offered
+func (cfg *PullRequestsConfig) AllowedMergeStyles() []MergeStyle {
+	styles := make([]MergeStyle, 0, len(MergeStyles))
+	for _, style := range MergeStyles {
+		if cfg.IsMergeStyleAllowed(style) {
+			styles = append(styles, style)
+		}
+	}
+	return styles
 }
 
 // BeforeSet is invoked from XORM before setting the value of a field of this object.
@@ -118,7 +138,7 @@ func (r *RepoUnit) BeforeSet(colName string, val xorm.Cell) {
 	switch colName {
 	case "type":
 		switch UnitType(Cell2Int64(val)) {
-		case UnitTypeCode, UnitTypeReleases, UnitTypeWiki:
+		case UnitTypeCode, UnitTypeReleases, UnitTypeWiki, UnitTypeDiscussions:
 			r.Config = new(UnitConfig)
 		case UnitTypeExternalWiki:
 			r.Config = new(ExternalWikiConfig)
@@ -0,0 +1,175 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// VisibilityChangeImpact reports what is affected by changing the visibility of a repository
+type VisibilityChangeImpact struct {
+	// IsPrivate is the visibility the repository is changed to
+	IsPrivate bool
+	// NumForks is the number of forks of the repository, including forks of forks, whose visibility follows
+	NumForks int64
+	// NumWatchers is the number of watchers which lose access to the repository
+	NumWatchers int64
+	// NumStars is the number of stargazers which lose access to the repository
+	NumStars int64
+	// NumReleases is the number of releases whose downloads become private or public
+	NumReleases int64
+}
+
+// getDescendantForkIDs returns the IDs of the forks of the repository and of their forks
+func getDescendantForkIDs(e Engine, repoID int64) ([]int64, error) {
+	visited := map[int64]bool{repoID: true}
+	ids := make([]int64, 0, 10)
+	queue := []int64{repoID}
+	for len(queue) > 0 {
+		forkIDs := make([]int64, 0, 10)
+		if err := e.Table("repository").Cols("id").In("fork_id", queue).Find(&forkIDs); err != nil {
+			return nil, err
+		}
+		queue = queue[:0]
+		for _, id := range forkIDs {
+			if visited[id] {
+				continue
+			}
+			visited[id] = true
+			ids = append(ids, id)
+			queue = append(queue, id)
+		}
+	}
+	return ids, nil
+}
+
+// usersWithPrivateAccessCond returns the condition on the column of user IDs matching the users
+// which keep access to the repository when it is private: its owner, collaborators, members of
+// the teams of the organization with access to it and site administrators.
+func usersWithPrivateAccessCond(repo *Repository, col string) builder.Cond {
+	return builder.Neq{col: repo.OwnerID}.
+		And(builder.NotIn(col, builder.Select("user_id").From("collaboration").
+			Where(builder.Eq{"repo_id": repo.ID}))).
+		And(builder.NotIn(col, builder.Select("uid").From("team_user").
+			Where(builder.In("team_id", builder.Select("team_id").From("team_repo").
+				Where(builder.Eq{"repo_id": repo.ID}))))).
+		And(builder.NotIn(col, builder.Select("id").From("`user`").
+			Where(builder.Eq{"is_admin": true})))
+}
+
+// GetVisibilityChangeImpact returns what is affected by changing the visibility of the repository
+func GetVisibilityChangeImpact(repo *Repository, isPrivate bool) (*VisibilityChangeImpact, error) {
+	impact := &VisibilityChangeImpact{IsPrivate: isPrivate}
+
+	forkIDs, err := getDescendantForkIDs(x, repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	impact.NumForks = int64(len(forkIDs))
+
+	if impact.NumReleases, err = x.Where("repo_id = ? AND is_draft = ?", repo.ID, false).Count(new(Release)); err != nil {
+		return nil, err
+	}
+
+	// Only making a repository private takes the access away from its watchers and stargazers
+	if !isPrivate {
+		return impact, nil
+	}
+
+	if impact.NumWatchers, err = x.Where(builder.Eq{"repo_id": repo.ID}.
+		And(builder.In("mode", RepoWatchModeNormal, RepoWatchModeAuto)).
+		And(usersWithPrivateAccessCond(repo, "user_id"))).
+		Count(new(Watch)); err != nil {
+		return nil, err
+	}
+
+	if impact.NumStars, err = x.Where(builder.Eq{"repo_id": repo.ID}.
+		And(usersWithPrivateAccessCond(repo, "uid"))).
+		Count(new(Star)); err != nil {
+		return nil, err
+	}
+	return impact, nil
+}
+
+// ScheduledVisibilityChange represents a change of the visibility of a repository
+// which is applied at a later time
+type ScheduledVisibilityChange struct {
+	ID            int64              `xorm:"pk autoincr"`
+	RepoID        int64              `xorm:"UNIQUE NOT NULL"`
+	Repo          *Repository        `xorm:"-"`
+	DoerID        int64              `xorm:"NOT NULL"`
+	Doer          *User              `xorm:"-"`
+	IsPrivate     bool               `xorm:"NOT NULL DEFAULT false"`
+	ScheduledUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+	CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+}
+
+// LoadAttributes loads the repository and the user who scheduled the change
+func (change *ScheduledVisibilityChange) LoadAttributes() (err error) {
+	if change.Repo == nil {
+		if change.Repo, err = GetRepositoryByID(change.RepoID); err != nil {
+			return err
+		}
+	}
+	if change.Doer == nil {
+		if change.Doer, err = GetUserByID(change.DoerID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return err
+			}
+			change.Doer = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// ScheduleVisibilityChange schedules the change of the visibility of the repository,
+// it replaces the change already scheduled for the repository if any.
+func ScheduleVisibilityChange(doer *User, repo *Repository, isPrivate bool, scheduled timeutil.TimeStamp) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Delete(&ScheduledVisibilityChange{RepoID: repo.ID}); err != nil {
+		return err
+	}
+	if _, err := sess.Insert(&ScheduledVisibilityChange{
+		RepoID:        repo.ID,
+		DoerID:        doer.ID,
+		IsPrivate:     isPrivate,
+		ScheduledUnix: scheduled,
+	}); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetScheduledVisibilityChange returns the change of visibility scheduled for the repository,
+// it returns nil if there is none.
+func GetScheduledVisibilityChange(repoID int64) (*ScheduledVisibilityChange, error) {
+	change := new(ScheduledVisibilityChange)
+	has, err := x.Where("repo_id = ?", repoID).Get(change)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return change, nil
+}
+
+// CancelScheduledVisibilityChange removes the change of visibility scheduled for the repository
+func CancelScheduledVisibilityChange(repoID int64) error {
+	_, err := x.Delete(&ScheduledVisibilityChange{RepoID: repoID})
+	return err
+}
+
+// GetDueVisibilityChanges returns the changes of visibility which are scheduled before the given time
+func GetDueVisibilityChanges(before timeutil.TimeStamp) ([]*ScheduledVisibilityChange, error) {
+	changes := make([]*ScheduledVisibilityChange, 0, 10)
+	return changes, x.Where("scheduled_unix <= ?", before).Asc("scheduled_unix").Find(&changes)
+}
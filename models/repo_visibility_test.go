@@ -0,0 +1,80 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVisibilityChangeImpact(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	// user1 is a site administrator, user8 does not watch and user4, user9 and user11 lose access
+	impact, err := GetVisibilityChangeImpact(repo, true)
+	assert.NoError(t, err)
+	assert.True(t, impact.IsPrivate)
+	assert.EqualValues(t, 3, impact.NumWatchers)
+	assert.EqualValues(t, 0, impact.NumStars)
+	assert.EqualValues(t, 0, impact.NumForks)
+	assert.EqualValues(t, 1, impact.NumReleases)
+
+	// collaborators keep their access
+	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	assert.NoError(t, repo.AddCollaborator(user4))
+	impact, err = GetVisibilityChangeImpact(repo, true)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, impact.NumWatchers)
+
+	// making a repository public takes the access away from nobody
+	impact, err = GetVisibilityChangeImpact(repo, false)
+	assert.NoError(t, err)
+	assert.False(t, impact.IsPrivate)
+	assert.EqualValues(t, 0, impact.NumWatchers)
+
+	impact, err = GetVisibilityChangeImpact(AssertExistsAndLoadBean(t, &Repository{ID: 27}).(*Repository), true)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, impact.NumForks)
+}
+
+func TestScheduleVisibilityChange(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	now := timeutil.TimeStampNow()
+
+	assert.NoError(t, ScheduleVisibilityChange(doer, repo, true, now.Add(3600)))
+	// a new change replaces the one already scheduled
+	assert.NoError(t, ScheduleVisibilityChange(doer, repo, true, now.Add(60)))
+	AssertCount(t, &ScheduledVisibilityChange{RepoID: repo.ID}, 1)
+
+	change, err := GetScheduledVisibilityChange(repo.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, change) {
+		assert.True(t, change.IsPrivate)
+		assert.EqualValues(t, now.Add(60), change.ScheduledUnix)
+		assert.NoError(t, change.LoadAttributes())
+		assert.EqualValues(t, doer.ID, change.Doer.ID)
+		assert.EqualValues(t, repo.ID, change.Repo.ID)
+	}
+
+	changes, err := GetDueVisibilityChanges(now)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 0)
+	changes, err = GetDueVisibilityChanges(now.Add(60))
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+
+	assert.NoError(t, CancelScheduledVisibilityChange(repo.ID))
+	change, err = GetScheduledVisibilityChange(repo.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, change)
+}
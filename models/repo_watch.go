@@ -6,8 +6,10 @@ package models
 
 import (
 	"fmt"
+	"sort"
 
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
 )
 
 // RepoWatchMode specifies what kind of watch the user has on a repository
@@ -172,31 +174,100 @@ func (repo *Repository) GetWatchers(opts ListOptions) ([]*User, error) {
 	return users, sess.Find(&users)
 }
 
-func notifyWatchers(e Engine, actions ...*Action) error {
-	var watchers []*Watch
-	var repo *Repository
-	var err error
-	var permCode []bool
-	var permIssue []bool
-	var permPR []bool
+// readPermission is what a user can read of a repository, which decides
+// which actions and notifications of the repository the user gets
+type readPermission struct {
+	code, issues, pulls bool
+}
 
-	for _, act := range actions {
-		repoChanged := repo == nil || repo.ID != act.RepoID
+func (p readPermission) canSee(opType ActionType) bool {
+	switch opType {
+	case ActionCommitRepo, ActionPushTag, ActionDeleteTag, ActionDeleteBranch:
+		return p.code
+	case ActionCreateIssue, ActionCommentIssue, ActionCloseIssue, ActionReopenIssue:
+		return p.issues
+	case ActionCreatePullRequest, ActionCommentPull, ActionMergePullRequest, ActionClosePullRequest, ActionReopenPullRequest:
+		return p.pulls
+	}
+	return true
+}
 
-		if repoChanged {
-			// Add feeds for user self and all watchers.
-			watchers, err = getWatchers(e, act.RepoID)
+// getReadPermissions returns what the users can read of the repository,
+// loading the users in batches. Users who do not exist are left out.
+func getReadPermissions(e Engine, repo *Repository, userIDs []int64) (map[int64]readPermission, error) {
+	if err := repo.getOwner(e); err != nil {
+		return nil, fmt.Errorf("can't get repo owner: %v", err)
+	}
+	if err := repo.getUnits(e); err != nil {
+		return nil, err
+	}
+
+	// Users who are not restricted can read every unit of a public repository
+	// of a visible owner, so only the others need their permission checked.
+	publicRepo := !repo.IsPrivate && (!repo.Owner.IsOrganization() || repo.Owner.Visibility == structs.VisibleTypePublic)
+	publicPerm := readPermission{
+		code:   repo.UnitEnabled(UnitTypeCode),
+		issues: repo.UnitEnabled(UnitTypeIssues),
+		pulls:  repo.UnitEnabled(UnitTypePullRequests),
+	}
+
+	perms := make(map[int64]readPermission, len(userIDs))
+	for i := 0; i < len(userIDs); i += maxQueryParameters {
+		end := i + maxQueryParameters
+		if end > len(userIDs) {
+			end = len(userIDs)
+		}
+		users := make([]*User, 0, end-i)
+		if err := e.In("id", userIDs[i:end]).Find(&users); err != nil {
+			return nil, fmt.Errorf("get users: %v", err)
+		}
+		for _, user := range users {
+			if publicRepo && !user.IsRestricted {
+				perms[user.ID] = publicPerm
+				continue
+			}
+			perm, err := getUserRepoPermission(e, repo, user)
 			if err != nil {
-				return fmt.Errorf("get watchers: %v", err)
+				continue
+			}
+			perms[user.ID] = readPermission{
+				code:   perm.CanRead(UnitTypeCode),
+				issues: perm.CanRead(UnitTypeIssues),
+				pulls:  perm.CanRead(UnitTypePullRequests),
 			}
 		}
+	}
+	return perms, nil
+}
 
-		// Add feed for actioner.
-		act.UserID = act.ActUserID
-		if _, err = e.InsertOne(act); err != nil {
-			return fmt.Errorf("insert new actioner: %v", err)
-		}
+// repoWatchers are the watchers of a repository with their permissions
+type repoWatchers struct {
+	ids   []int64
+	perms map[int64]readPermission
+}
+
+func getRepoWatchers(e Engine, repoID int64) (*repoWatchers, error) {
+	repo, err := getRepositoryByID(e, repoID)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := getRepoWatchersIDs(e, repoID)
+	if err != nil {
+		return nil, fmt.Errorf("get watchers: %v", err)
+	}
+	perms, err := getReadPermissions(e, repo, ids)
+	if err != nil {
+		return nil, err
+	}
+	return &repoWatchers{ids: ids, perms: perms}, nil
+}
 
+// insertActions adds the actions to the feeds of the actioners and of the
+// organizations owning the repositories
+func insertActions(e Engine, actions ...*Action) error {
+	var repo *Repository
+	for _, act := range actions {
+		repoChanged := repo == nil || repo.ID != act.RepoID
 		if repoChanged {
 			act.loadRepo()
 			repo = act.Repo
@@ -209,69 +280,77 @@ func notifyWatchers(e Engine, actions ...*Action) error {
 			act.Repo = repo
 		}
 
+		// Add feed for actioner.
+		act.UserID = act.ActUserID
+		if _, err := e.InsertOne(act); err != nil {
+			return fmt.Errorf("insert new actioner: %v", err)
+		}
+
 		// Add feed for organization
 		if act.Repo.Owner.IsOrganization() && act.ActUserID != act.Repo.Owner.ID {
-			act.ID = 0
-			act.UserID = act.Repo.Owner.ID
-			if _, err = e.InsertOne(act); err != nil {
+			orgAct := *act
+			orgAct.ID = 0
+			orgAct.UserID = act.Repo.Owner.ID
+			if _, err := e.InsertOne(&orgAct); err != nil {
 				return fmt.Errorf("insert new actioner: %v", err)
 			}
 		}
+	}
+	return nil
+}
 
-		if repoChanged {
-			permCode = make([]bool, len(watchers))
-			permIssue = make([]bool, len(watchers))
-			permPR = make([]bool, len(watchers))
-			for i, watcher := range watchers {
-				user, err := getUserByID(e, watcher.UserID)
-				if err != nil {
-					permCode[i] = false
-					permIssue[i] = false
-					permPR[i] = false
-					continue
-				}
-				perm, err := getUserRepoPermission(e, repo, user)
-				if err != nil {
-					permCode[i] = false
-					permIssue[i] = false
-					permPR[i] = false
-					continue
-				}
-				permCode[i] = perm.CanRead(UnitTypeCode)
-				permIssue[i] = perm.CanRead(UnitTypeIssues)
-				permPR[i] = perm.CanRead(UnitTypePullRequests)
+// fanOutActions adds the actions, which are in the feeds of their actioners,
+// to the feeds of the watchers of their repositories in batches
+func fanOutActions(e Engine, actions ...*Action) error {
+	batchSize := MaxBatchInsertSize(new(Action))
+	feeds := make([]*Action, 0, batchSize)
+	flush := func() error {
+		if len(feeds) == 0 {
+			return nil
+		}
+		// Keep the creation time of the actions, which may be fanned out later
+		if _, err := e.NoAutoTime().Insert(feeds); err != nil {
+			return fmt.Errorf("insert new actions: %v", err)
+		}
+		feeds = feeds[:0]
+		return nil
+	}
+
+	watchersByRepo := make(map[int64]*repoWatchers)
+	for _, act := range actions {
+		watchers, ok := watchersByRepo[act.RepoID]
+		if !ok {
+			var err error
+			if watchers, err = getRepoWatchers(e, act.RepoID); err != nil {
+				return err
 			}
+			watchersByRepo[act.RepoID] = watchers
 		}
 
-		for i, watcher := range watchers {
-			if act.ActUserID == watcher.UserID {
+		for _, watcherID := range watchers.ids {
+			if watcherID == act.ActUserID || !watchers.perms[watcherID].canSee(act.OpType) {
 				continue
 			}
-			act.ID = 0
-			act.UserID = watcher.UserID
-			act.Repo.Units = nil
-
-			switch act.OpType {
-			case ActionCommitRepo, ActionPushTag, ActionDeleteTag, ActionDeleteBranch:
-				if !permCode[i] {
-					continue
-				}
-			case ActionCreateIssue, ActionCommentIssue, ActionCloseIssue, ActionReopenIssue:
-				if !permIssue[i] {
-					continue
-				}
-			case ActionCreatePullRequest, ActionCommentPull, ActionMergePullRequest, ActionClosePullRequest, ActionReopenPullRequest:
-				if !permPR[i] {
-					continue
-				}
-			}
 
-			if _, err = e.InsertOne(act); err != nil {
-				return fmt.Errorf("insert new action: %v", err)
+			feed := *act
+			feed.ID = 0
+			feed.UserID = watcherID
+			feeds = append(feeds, &feed)
+			if len(feeds) >= batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
 			}
 		}
 	}
-	return nil
+	return flush()
+}
+
+func notifyWatchers(e Engine, actions ...*Action) error {
+	if err := insertActions(e, actions...); err != nil {
+		return err
+	}
+	return fanOutActions(e, actions...)
 }
 
 // NotifyWatchers creates batch of actions for every watcher.
@@ -294,6 +373,61 @@ func NotifyWatchersActions(acts []*Action) error {
 	return sess.Commit()
 }
 
+// InsertActions adds the actions to the feeds of the actioners and of the
+// organizations owning the repositories. FanOutActions adds them to the feeds
+// of the watchers afterwards.
+func InsertActions(actions ...*Action) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if err := insertActions(sess, actions...); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// FanOutActions adds the actions with the given IDs, which InsertActions
+// added to the feeds of their actioners, to the feeds of the watchers of their
+// repositories.
+func FanOutActions(actionIDs ...int64) error {
+	// The same action may be queued more than once
+	seen := make(map[int64]bool, len(actionIDs))
+	ids := make([]int64, 0, len(actionIDs))
+	for _, id := range actionIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	actionIDs = ids
+
+	actions := make([]*Action, 0, len(actionIDs))
+	for i := 0; i < len(actionIDs); i += maxQueryParameters {
+		end := i + maxQueryParameters
+		if end > len(actionIDs) {
+			end = len(actionIDs)
+		}
+		if err := x.In("id", actionIDs[i:end]).Find(&actions); err != nil {
+			return err
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].ID < actions[j].ID
+	})
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if err := fanOutActions(sess, actions...); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
 func watchIfAuto(e Engine, userID, repoID int64, isWrite bool) error {
 	if !isWrite || !setting.Service.AutoWatchOnChanges {
 		return nil
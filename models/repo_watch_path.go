@@ -0,0 +1,208 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/gobwas/glob"
+)
+
+// WatchPath is a subscription of a user to the changes of a file or directory of a repository,
+// for users who want to follow only parts of a large repository. Paths containing any of "*?[{"
+// are glob patterns matching the paths of files, like "**/*.go".
+type WatchPath struct {
+	ID          int64              `xorm:"pk autoincr"`
+	UserID      int64              `xorm:"UNIQUE(watch_path) NOT NULL"`
+	RepoID      int64              `xorm:"UNIQUE(watch_path) INDEX NOT NULL"`
+	Path        string             `xorm:"UNIQUE(watch_path) VARCHAR(255) NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// cleanWatchPath returns the clean form of a path of a repository, or an empty string if it
+// is not a valid path below the root of the repository.
+func cleanWatchPath(treePath string) string {
+	treePath = path.Clean("/" + strings.Trim(treePath, "/"))[1:]
+	if len(treePath) > 255 {
+		return ""
+	}
+	for _, part := range strings.Split(treePath, "/") {
+		if part == ".git" {
+			return ""
+		}
+	}
+	return treePath
+}
+
+// isWatchPattern returns true if the watched path is a glob pattern
+func isWatchPattern(treePath string) bool {
+	return strings.ContainsAny(treePath, "*?[{")
+}
+
+// matches returns true if the watched path is one of the paths or contains one of them, or if
+// it is a pattern matching one of them
+func (w *WatchPath) matches(paths []string) bool {
+	if isWatchPattern(w.Path) {
+		g, err := glob.Compile(w.Path, '/')
+		if err != nil {
+			return false
+		}
+		for _, p := range paths {
+			if g.Match(p) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, p := range paths {
+		if p == w.Path || strings.HasPrefix(p, w.Path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchRepoPath subscribes a user to the changes of a path of a repository
+func WatchRepoPath(userID, repoID int64, treePath string) (*WatchPath, error) {
+	cleanPath := cleanWatchPath(treePath)
+	if cleanPath == "" {
+		return nil, ErrFilenameInvalid{Path: treePath}
+	}
+	if isWatchPattern(cleanPath) {
+		if _, err := glob.Compile(cleanPath, '/'); err != nil {
+			return nil, ErrFilenameInvalid{Path: treePath}
+		}
+	}
+
+	watch := &WatchPath{UserID: userID, RepoID: repoID, Path: cleanPath}
+	has, err := x.Get(watch)
+	if err != nil || has {
+		return watch, err
+	}
+	if _, err := x.Insert(watch); err != nil {
+		return nil, err
+	}
+	return watch, nil
+}
+
+// UnwatchRepoPath unsubscribes a user from the changes of a path of a repository
+func UnwatchRepoPath(userID, repoID int64, treePath string) error {
+	cleanPath := cleanWatchPath(treePath)
+	if cleanPath == "" {
+		return ErrFilenameInvalid{Path: treePath}
+	}
+	_, err := x.Delete(&WatchPath{UserID: userID, RepoID: repoID, Path: cleanPath})
+	return err
+}
+
+// GetUserWatchPaths returns the paths of a repository a user watches
+func GetUserWatchPaths(userID, repoID int64) ([]*WatchPath, error) {
+	watches := make([]*WatchPath, 0, 10)
+	return watches, x.
+		Where("user_id = ? AND repo_id = ?", userID, repoID).
+		Asc("path").
+		Find(&watches)
+}
+
+// HasWatchPaths returns true if any user watches paths of the repository
+func HasWatchPaths(repoID int64) (bool, error) {
+	return x.Exist(&WatchPath{RepoID: repoID})
+}
+
+func getPathWatcherIDs(e Engine, repoID int64, paths []string) ([]int64, error) {
+	watches := make([]*WatchPath, 0, 10)
+	if err := e.Where("repo_id = ?", repoID).Find(&watches); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool, len(watches))
+	ids := make([]int64, 0, len(watches))
+	for _, watch := range watches {
+		if !seen[watch.UserID] && watch.matches(paths) {
+			seen[watch.UserID] = true
+			ids = append(ids, watch.UserID)
+		}
+	}
+	return ids, nil
+}
+
+// GetPathWatcherIDs returns the IDs of the users who watch one of the paths of a repository or
+// a directory containing one of them
+func GetPathWatcherIDs(repoID int64, paths []string) ([]int64, error) {
+	return getPathWatcherIDs(x, repoID, paths)
+}
+
+// NotifyPathWatchers adds a push action to the feeds of the users who watch one of the changed
+// paths of its repository. Users who watch the whole repository get the action from
+// NotifyWatchers already.
+func NotifyPathWatchers(act *Action, changedPaths []string) error {
+	if act.ID == 0 {
+		return fmt.Errorf("action has not been inserted")
+	}
+
+	pathWatcherIDs, err := getPathWatcherIDs(x, act.RepoID, changedPaths)
+	if err != nil || len(pathWatcherIDs) == 0 {
+		return err
+	}
+
+	repoWatcherIDs, err := getRepoWatchersIDs(x, act.RepoID)
+	if err != nil {
+		return fmt.Errorf("get watchers: %v", err)
+	}
+	skip := make(map[int64]bool, len(repoWatcherIDs)+1)
+	skip[act.ActUserID] = true
+	for _, id := range repoWatcherIDs {
+		skip[id] = true
+	}
+
+	userIDs := make([]int64, 0, len(pathWatcherIDs))
+	for _, id := range pathWatcherIDs {
+		if !skip[id] {
+			userIDs = append(userIDs, id)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	repo, err := getRepositoryByID(x, act.RepoID)
+	if err != nil {
+		return err
+	}
+	perms, err := getReadPermissions(x, repo, userIDs)
+	if err != nil {
+		return err
+	}
+
+	batchSize := MaxBatchInsertSize(new(Action))
+	feeds := make([]*Action, 0, batchSize)
+	for _, userID := range userIDs {
+		if !perms[userID].canSee(act.OpType) {
+			continue
+		}
+		feed := *act
+		feed.ID = 0
+		feed.UserID = userID
+		feeds = append(feeds, &feed)
+		if len(feeds) >= batchSize {
+			// Keep the creation time of the action
+			if _, err := x.NoAutoTime().Insert(feeds); err != nil {
+				return err
+			}
+			feeds = feeds[:0]
+		}
+	}
+	if len(feeds) > 0 {
+		if _, err := x.NoAutoTime().Insert(feeds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
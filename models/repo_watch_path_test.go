@@ -0,0 +1,111 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanWatchPath(t *testing.T) {
+	for p, expected := range map[string]string{
+		"docs":             "docs",
+		"/docs/":           "docs",
+		"docs/../src/main": "src/main",
+		"../../etc":        "etc",
+		"":                 "",
+		"/":                "",
+		"src/.git/config":  "",
+	} {
+		assert.Equal(t, expected, cleanWatchPath(p), p)
+	}
+}
+
+func TestWatchRepoPath(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	watch, err := WatchRepoPath(2, 1, "/docs/")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "docs", watch.Path)
+	AssertExistsAndLoadBean(t, &WatchPath{UserID: 2, RepoID: 1, Path: "docs"})
+
+	// watching a path twice keeps one subscription
+	_, err = WatchRepoPath(2, 1, "docs")
+	assert.NoError(t, err)
+	AssertCount(t, &WatchPath{UserID: 2, RepoID: 1}, 1)
+
+	_, err = WatchRepoPath(2, 1, "src")
+	assert.NoError(t, err)
+	watches, err := GetUserWatchPaths(2, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, watches, 2) {
+		assert.EqualValues(t, "docs", watches[0].Path)
+		assert.EqualValues(t, "src", watches[1].Path)
+	}
+
+	_, err = WatchRepoPath(2, 1, "/")
+	assert.True(t, IsErrFilenameInvalid(err))
+
+	assert.NoError(t, UnwatchRepoPath(2, 1, "docs"))
+	AssertNotExistsBean(t, &WatchPath{UserID: 2, RepoID: 1, Path: "docs"})
+}
+
+func TestNotifyPathWatchers(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// user 4 watches the whole repository and gets the action from NotifyWatchers
+	for _, userID := range []int64{4, 5, 10} {
+		_, err := WatchRepoPath(userID, 1, "src/server")
+		assert.NoError(t, err)
+	}
+	_, err := WatchRepoPath(12, 1, "docs")
+	assert.NoError(t, err)
+
+	act := &Action{
+		ActUserID: 10,
+		OpType:    ActionCommitRepo,
+		RepoID:    1,
+		RefName:   "master",
+	}
+	assert.NoError(t, NotifyWatchers(act))
+	assert.NoError(t, NotifyPathWatchers(act, []string{"README.md", "src/server/main.go"}))
+
+	AssertExistsAndLoadBean(t, &Action{UserID: 5, RepoID: 1, OpType: ActionCommitRepo})
+	AssertCount(t, &Action{UserID: 4, RepoID: 1, OpType: ActionCommitRepo}, 1)
+	AssertNotExistsBean(t, &Action{UserID: 12, RepoID: 1, OpType: ActionCommitRepo})
+	AssertCount(t, &Action{UserID: 10, RepoID: 1, OpType: ActionCommitRepo}, 1)
+
+	has, err := HasWatchPaths(1)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = HasWatchPaths(2)
+	assert.NoError(t, err)
+	assert.False(t, has)
+}
+
+func TestGetPathWatcherIDs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	for userID, p := range map[int64]string{
+		2:  "docs",
+		4:  "**/*.go",
+		5:  "src/{server,client}/**",
+		10: "README.md",
+	} {
+		_, err := WatchRepoPath(userID, 1, p)
+		assert.NoError(t, err)
+	}
+	_, err := WatchRepoPath(2, 1, "[invalid")
+	assert.True(t, IsErrFilenameInvalid(err))
+
+	ids, err := GetPathWatcherIDs(1, []string{"docs/index.md", "src/server/main.go"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{2, 4, 5}, ids)
+
+	ids, err = GetPathWatcherIDs(1, []string{"README.md"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{10}, ids)
+}
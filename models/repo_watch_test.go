@@ -109,6 +109,26 @@ func TestNotifyWatchers(t *testing.T) {
 	})
 }
 
+func TestFanOutActions(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	action := &Action{
+		ActUserID: 8,
+		RepoID:    1,
+		OpType:    ActionStarRepo,
+	}
+	assert.NoError(t, InsertActions(action))
+	AssertExistsAndLoadBean(t, &Action{ID: action.ID, UserID: 8})
+	AssertNotExistsBean(t, &Action{ActUserID: 8, UserID: 1, RepoID: 1, OpType: ActionStarRepo})
+
+	// Actions queued twice are only fanned out once
+	assert.NoError(t, FanOutActions(action.ID, action.ID))
+	for _, userID := range []int64{1, 4, 11} {
+		feed := AssertExistsAndLoadBean(t, &Action{ActUserID: 8, UserID: userID, RepoID: 1, OpType: ActionStarRepo}).(*Action)
+		assert.Equal(t, action.CreatedUnix, feed.CreatedUnix)
+	}
+}
+
 func TestWatchIfAuto(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
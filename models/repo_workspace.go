@@ -0,0 +1,151 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// WorkspaceChange represents an uncommitted change of a file made by a user in the
+// web IDE workspace of a branch. The changes of a workspace are kept until they are
+// committed or discarded, so they survive page reloads and sessions.
+type WorkspaceChange struct {
+	ID       int64  `xorm:"pk autoincr"`
+	UserID   int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	RepoID   int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Branch   string `xorm:"UNIQUE(s) NOT NULL"`
+	TreePath string `xorm:"UNIQUE(s) NOT NULL"`
+	Content  string `xorm:"LONGTEXT"`
+	// IsDeleted is true if the file is deleted by the change
+	IsDeleted bool `xorm:"NOT NULL DEFAULT false"`
+	// BaseCommitID is the commit of the branch the file was first changed on, used
+	// to detect the files changed on the branch in the meantime
+	BaseCommitID string `xorm:"VARCHAR(40)"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// ErrWorkspaceChangeNotExist represents a "WorkspaceChangeNotExist" kind of error.
+type ErrWorkspaceChangeNotExist struct {
+	UserID   int64
+	RepoID   int64
+	Branch   string
+	TreePath string
+}
+
+// IsErrWorkspaceChangeNotExist checks if an error is a ErrWorkspaceChangeNotExist.
+func IsErrWorkspaceChangeNotExist(err error) bool {
+	_, ok := err.(ErrWorkspaceChangeNotExist)
+	return ok
+}
+
+func (err ErrWorkspaceChangeNotExist) Error() string {
+	return fmt.Sprintf("workspace change does not exist [user_id: %d, repo_id: %d, branch: %s, tree_path: %s]", err.UserID, err.RepoID, err.Branch, err.TreePath)
+}
+
+// ErrWorkspaceConflict represents a "WorkspaceConflict" kind of error, files changed
+// in a workspace have been changed on its branch since.
+type ErrWorkspaceConflict struct {
+	Paths []string
+}
+
+// IsErrWorkspaceConflict checks if an error is a ErrWorkspaceConflict.
+func IsErrWorkspaceConflict(err error) bool {
+	_, ok := err.(ErrWorkspaceConflict)
+	return ok
+}
+
+func (err ErrWorkspaceConflict) Error() string {
+	return fmt.Sprintf("workspace files changed on the branch [paths: %s]", strings.Join(err.Paths, ", "))
+}
+
+// ErrWorkspaceTooManyChanges represents a "WorkspaceTooManyChanges" kind of error.
+type ErrWorkspaceTooManyChanges struct {
+	Max int
+}
+
+// IsErrWorkspaceTooManyChanges checks if an error is a ErrWorkspaceTooManyChanges.
+func IsErrWorkspaceTooManyChanges(err error) bool {
+	_, ok := err.(ErrWorkspaceTooManyChanges)
+	return ok
+}
+
+func (err ErrWorkspaceTooManyChanges) Error() string {
+	return fmt.Sprintf("workspace has too many changed files [max: %d]", err.Max)
+}
+
+// GetWorkspaceChanges returns the uncommitted changes of the user in the workspace of
+// the branch, ordered by path.
+func GetWorkspaceChanges(userID, repoID int64, branch string) ([]*WorkspaceChange, error) {
+	changes := make([]*WorkspaceChange, 0, 10)
+	return changes, x.
+		Where("user_id = ? AND repo_id = ? AND branch = ?", userID, repoID, branch).
+		Asc("tree_path").
+		Find(&changes)
+}
+
+// GetWorkspaceChange returns the uncommitted change of the user to a file in the workspace of the branch.
+func GetWorkspaceChange(userID, repoID int64, branch, treePath string) (*WorkspaceChange, error) {
+	change := &WorkspaceChange{UserID: userID, RepoID: repoID, Branch: branch, TreePath: treePath}
+	has, err := x.Get(change)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrWorkspaceChangeNotExist{userID, repoID, branch, treePath}
+	}
+	return change, nil
+}
+
+// SaveWorkspaceChange inserts the change of a file or updates the existing change of the same
+// file, whose base commit is kept. At most maxChanges files can be changed in a workspace.
+func SaveWorkspaceChange(change *WorkspaceChange, maxChanges int) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	existing := &WorkspaceChange{UserID: change.UserID, RepoID: change.RepoID, Branch: change.Branch, TreePath: change.TreePath}
+	has, err := sess.Get(existing)
+	if err != nil {
+		return err
+	}
+	if has {
+		change.ID = existing.ID
+		change.BaseCommitID = existing.BaseCommitID
+		if _, err := sess.ID(change.ID).Cols("content", "is_deleted").Update(change); err != nil {
+			return err
+		}
+		return sess.Commit()
+	}
+
+	count, err := sess.Where("user_id = ? AND repo_id = ? AND branch = ?", change.UserID, change.RepoID, change.Branch).
+		Count(new(WorkspaceChange))
+	if err != nil {
+		return err
+	} else if maxChanges > 0 && count >= int64(maxChanges) {
+		return ErrWorkspaceTooManyChanges{Max: maxChanges}
+	}
+	if _, err := sess.Insert(change); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// DeleteWorkspaceChange discards the uncommitted change of the user to a file in the workspace of the branch.
+func DeleteWorkspaceChange(userID, repoID int64, branch, treePath string) error {
+	_, err := x.Delete(&WorkspaceChange{UserID: userID, RepoID: repoID, Branch: branch, TreePath: treePath})
+	return err
+}
+
+// DeleteWorkspaceChanges discards all uncommitted changes of the user in the workspace of the branch.
+func DeleteWorkspaceChanges(userID, repoID int64, branch string) error {
+	_, err := x.Delete(&WorkspaceChange{UserID: userID, RepoID: repoID, Branch: branch})
+	return err
+}
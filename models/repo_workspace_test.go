@@ -0,0 +1,48 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveWorkspaceChange(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	changes, err := GetWorkspaceChanges(2, 1, "master")
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.EqualValues(t, "README.md", changes[0].TreePath)
+	}
+
+	// saving a file again keeps the commit it was first changed on
+	assert.NoError(t, SaveWorkspaceChange(&WorkspaceChange{UserID: 2, RepoID: 1, Branch: "master", TreePath: "README.md",
+		IsDeleted: true, BaseCommitID: "0000000000000000000000000000000000000000"}, 2))
+	change, err := GetWorkspaceChange(2, 1, "master", "README.md")
+	assert.NoError(t, err)
+	assert.True(t, change.IsDeleted)
+	assert.EqualValues(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", change.BaseCommitID)
+
+	assert.NoError(t, SaveWorkspaceChange(&WorkspaceChange{UserID: 2, RepoID: 1, Branch: "master", TreePath: "docs/new.md", Content: "new"}, 2))
+	err = SaveWorkspaceChange(&WorkspaceChange{UserID: 2, RepoID: 1, Branch: "master", TreePath: "docs/other.md"}, 2)
+	assert.True(t, IsErrWorkspaceTooManyChanges(err))
+
+	// the workspaces of other users and branches are separate
+	changes, err = GetWorkspaceChanges(1, 1, "master")
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+	changes, err = GetWorkspaceChanges(2, 1, "develop")
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	assert.NoError(t, DeleteWorkspaceChange(2, 1, "master", "README.md"))
+	_, err = GetWorkspaceChange(2, 1, "master", "README.md")
+	assert.True(t, IsErrWorkspaceChangeNotExist(err))
+
+	assert.NoError(t, DeleteWorkspaceChanges(2, 1, "master"))
+	AssertNotExistsBean(t, &WorkspaceChange{UserID: 2, RepoID: 1})
+}
@@ -0,0 +1,371 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// Snippet represents a set of files shared by a user outside of any repository.
+// Its files are stored in a small bare git repository whose commits are the
+// revisions of the snippet.
+type Snippet struct {
+	ID          int64           `xorm:"pk autoincr"`
+	OwnerID     int64           `xorm:"INDEX"`
+	Owner       *User           `xorm:"-"`
+	Title       string          `xorm:"name"`
+	Description string          `xorm:"TEXT"`
+	Visibility  api.VisibleType `xorm:"INDEX NOT NULL DEFAULT 0"`
+	NumComments int
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// SnippetComment represents a comment on a snippet.
+type SnippetComment struct {
+	ID              int64  `xorm:"pk autoincr"`
+	SnippetID       int64  `xorm:"INDEX"`
+	PosterID        int64  `xorm:"INDEX"`
+	Poster          *User  `xorm:"-"`
+	Content         string `xorm:"TEXT"`
+	RenderedContent string `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// ErrSnippetNotExist represents a "SnippetNotExist" kind of error.
+type ErrSnippetNotExist struct {
+	ID int64
+}
+
+// IsErrSnippetNotExist checks if an error is a ErrSnippetNotExist.
+func IsErrSnippetNotExist(err error) bool {
+	_, ok := err.(ErrSnippetNotExist)
+	return ok
+}
+
+func (err ErrSnippetNotExist) Error() string {
+	return fmt.Sprintf("snippet does not exist [id: %d]", err.ID)
+}
+
+// ErrSnippetCommentNotExist represents a "SnippetCommentNotExist" kind of error.
+type ErrSnippetCommentNotExist struct {
+	ID        int64
+	SnippetID int64
+}
+
+// IsErrSnippetCommentNotExist checks if an error is a ErrSnippetCommentNotExist.
+func IsErrSnippetCommentNotExist(err error) bool {
+	_, ok := err.(ErrSnippetCommentNotExist)
+	return ok
+}
+
+func (err ErrSnippetCommentNotExist) Error() string {
+	return fmt.Sprintf("snippet comment does not exist [id: %d, snippet_id: %d]", err.ID, err.SnippetID)
+}
+
+// SnippetPath returns the path of the git repository storing the files of a snippet.
+func SnippetPath(id int64) string {
+	return filepath.Join(setting.Snippet.RootPath, fmt.Sprintf("%d.git", id))
+}
+
+// RepoPath returns the path of the git repository storing the files of the snippet.
+func (s *Snippet) RepoPath() string {
+	return SnippetPath(s.ID)
+}
+
+// Link returns the link of the snippet.
+func (s *Snippet) Link() string {
+	return fmt.Sprintf("%s/-/snippets/%d", setting.AppSubURL, s.ID)
+}
+
+// HTMLURL returns the absolute URL of the snippet.
+func (s *Snippet) HTMLURL() string {
+	return fmt.Sprintf("%s-/snippets/%d", setting.AppURL, s.ID)
+}
+
+// APIURL returns the absolute API URL of the snippet.
+func (s *Snippet) APIURL() string {
+	return fmt.Sprintf("%sapi/v1/snippets/%d", setting.AppURL, s.ID)
+}
+
+func (s *Snippet) loadOwner(e Engine) (err error) {
+	if s.Owner == nil {
+		s.Owner, err = getUserByID(e, s.OwnerID)
+		if err != nil {
+			if !IsErrUserNotExist(err) {
+				return fmt.Errorf("getUserByID [%d]: %v", s.OwnerID, err)
+			}
+			s.OwnerID = -1
+			s.Owner = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// LoadOwner loads the owner of the snippet.
+func (s *Snippet) LoadOwner() error {
+	return s.loadOwner(x)
+}
+
+// IsOwnedBy returns true if the user owns the snippet.
+func (s *Snippet) IsOwnedBy(user *User) bool {
+	return user != nil && user.ID == s.OwnerID
+}
+
+// CanBeEditedBy returns true if the user can change or delete the snippet.
+func (s *Snippet) CanBeEditedBy(user *User) bool {
+	return user != nil && (user.ID == s.OwnerID || user.IsAdmin)
+}
+
+// CanBeReadBy returns true if the user, nil for an anonymous user, can see the snippet.
+func (s *Snippet) CanBeReadBy(user *User) bool {
+	switch s.Visibility {
+	case api.VisibleTypePublic:
+		return true
+	case api.VisibleTypeLimited:
+		return user != nil
+	default:
+		return s.CanBeEditedBy(user)
+	}
+}
+
+// CreateSnippet inserts a new snippet, its files must be committed by the caller.
+func CreateSnippet(s *Snippet) error {
+	_, err := x.Insert(s)
+	return err
+}
+
+func getSnippetByID(e Engine, id int64) (*Snippet, error) {
+	s := new(Snippet)
+	has, err := e.ID(id).Get(s)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrSnippetNotExist{ID: id}
+	}
+	return s, nil
+}
+
+// GetSnippetByID returns the snippet with its owner loaded.
+func GetSnippetByID(id int64) (*Snippet, error) {
+	s, err := getSnippetByID(x, id)
+	if err != nil {
+		return nil, err
+	}
+	return s, s.loadOwner(x)
+}
+
+// UpdateSnippet updates the title, description and visibility of a snippet.
+func UpdateSnippet(s *Snippet) error {
+	_, err := x.ID(s.ID).Cols("name", "description", "visibility").Update(s)
+	return err
+}
+
+func deleteSnippet(e Engine, id int64) error {
+	if _, err := e.Delete(&SnippetComment{SnippetID: id}); err != nil {
+		return err
+	}
+	_, err := e.ID(id).Delete(new(Snippet))
+	return err
+}
+
+// DeleteSnippet removes a snippet and its comments, its git repository must be removed by the caller.
+func DeleteSnippet(id int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if err := deleteSnippet(sess, id); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// deleteSnippetsByOwnerID removes the snippets of a user and returns their IDs.
+func deleteSnippetsByOwnerID(e Engine, ownerID int64) ([]int64, error) {
+	ids := make([]int64, 0, 10)
+	if err := e.Table("snippet").Cols("id").Where("owner_id = ?", ownerID).Find(&ids); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if err := deleteSnippet(e, id); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// SearchSnippetOptions represents the options to search snippets.
+type SearchSnippetOptions struct {
+	ListOptions
+	// Actor is the user searching, nil for an anonymous user
+	Actor   *User
+	OwnerID int64
+	Keyword string
+}
+
+func (opts *SearchSnippetOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.OwnerID > 0 {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
+	}
+	if opts.Keyword != "" {
+		lowerKeyword := strings.ToLower(opts.Keyword)
+		cond = cond.And(builder.Or(
+			builder.Like{"LOWER(name)", lowerKeyword},
+			builder.Like{"LOWER(description)", lowerKeyword},
+		))
+	}
+
+	switch {
+	case opts.Actor == nil:
+		cond = cond.And(builder.Eq{"visibility": api.VisibleTypePublic})
+	case opts.Actor.IsAdmin:
+	default:
+		cond = cond.And(builder.In("visibility", api.VisibleTypePublic, api.VisibleTypeLimited).
+			Or(builder.Eq{"owner_id": opts.Actor.ID}))
+	}
+	return cond
+}
+
+// SearchSnippets returns the snippets visible to the actor of the options, most recently updated first,
+// with their owners loaded.
+func SearchSnippets(opts *SearchSnippetOptions) ([]*Snippet, int64, error) {
+	cond := opts.toConds()
+	count, err := x.Where(cond).Count(new(Snippet))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	snippets := make([]*Snippet, 0, opts.PageSize)
+	sess := opts.setSessionPagination(x.Where(cond).Desc("updated_unix").Desc("id"))
+	if err := sess.Find(&snippets); err != nil {
+		return nil, 0, err
+	}
+	for _, s := range snippets {
+		if err := s.loadOwner(x); err != nil {
+			return nil, 0, err
+		}
+	}
+	return snippets, count, nil
+}
+
+// TouchSnippet marks the snippet as updated after a new revision of its files.
+func TouchSnippet(s *Snippet) error {
+	s.UpdatedUnix = timeutil.TimeStampNow()
+	_, err := x.ID(s.ID).Cols("updated_unix").NoAutoTime().Update(s)
+	return err
+}
+
+func (c *SnippetComment) loadPoster(e Engine) (err error) {
+	if c.Poster == nil {
+		c.Poster, err = getUserByID(e, c.PosterID)
+		if err != nil {
+			if !IsErrUserNotExist(err) {
+				return fmt.Errorf("getUserByID [%d]: %v", c.PosterID, err)
+			}
+			c.PosterID = -1
+			c.Poster = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// LoadPoster loads the poster of the comment.
+func (c *SnippetComment) LoadPoster() error {
+	return c.loadPoster(x)
+}
+
+// HashTag returns the anchor of the comment on the snippet page.
+func (c *SnippetComment) HashTag() string {
+	return fmt.Sprintf("snippet-comment-%d", c.ID)
+}
+
+// CreateSnippetComment adds a comment to a snippet.
+func CreateSnippetComment(s *Snippet, poster *User, content string) (*SnippetComment, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	c := &SnippetComment{
+		SnippetID: s.ID,
+		PosterID:  poster.ID,
+		Poster:    poster,
+		Content:   content,
+	}
+	if _, err := sess.Insert(c); err != nil {
+		return nil, err
+	}
+	if _, err := sess.ID(s.ID).Incr("num_comments").NoAutoTime().Update(new(Snippet)); err != nil {
+		return nil, err
+	}
+	s.NumComments++
+	return c, sess.Commit()
+}
+
+// GetSnippetCommentByID returns a comment of the given snippet.
+func GetSnippetCommentByID(snippetID, id int64) (*SnippetComment, error) {
+	c := new(SnippetComment)
+	has, err := x.ID(id).Get(c)
+	if err != nil {
+		return nil, err
+	} else if !has || c.SnippetID != snippetID {
+		return nil, ErrSnippetCommentNotExist{ID: id, SnippetID: snippetID}
+	}
+	return c, c.loadPoster(x)
+}
+
+// GetSnippetComments returns the comments of a snippet in the order they were posted,
+// with their posters loaded.
+func GetSnippetComments(snippetID int64, listOptions ListOptions) ([]*SnippetComment, error) {
+	sess := x.Where("snippet_id = ?", snippetID).Asc("created_unix").Asc("id")
+	if listOptions.Page > 0 {
+		sess = listOptions.setSessionPagination(sess)
+	}
+	comments := make([]*SnippetComment, 0, 10)
+	if err := sess.Find(&comments); err != nil {
+		return nil, err
+	}
+	for _, c := range comments {
+		if err := c.loadPoster(x); err != nil {
+			return nil, err
+		}
+	}
+	return comments, nil
+}
+
+// DeleteSnippetComment removes a comment of a snippet.
+func DeleteSnippetComment(c *SnippetComment) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.ID(c.ID).Delete(new(SnippetComment)); err != nil {
+		return err
+	}
+	if _, err := sess.ID(c.SnippetID).Decr("num_comments").NoAutoTime().Update(new(Snippet)); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchSnippets(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	testCases := []struct {
+		actorID int64
+		keyword string
+		ids     []int64
+	}{
+		{0, "", []int64{1}},
+		{4, "", []int64{2, 1}},
+		{2, "", []int64{3, 2, 1}},
+		{1, "", []int64{3, 2, 1}},
+		{2, "private", []int64{3}},
+		{2, "everyone", []int64{1}},
+	}
+	for _, tc := range testCases {
+		opts := &SearchSnippetOptions{Keyword: tc.keyword}
+		if tc.actorID > 0 {
+			opts.Actor = AssertExistsAndLoadBean(t, &User{ID: tc.actorID}).(*User)
+		}
+		snippets, count, err := SearchSnippets(opts)
+		assert.NoError(t, err)
+		assert.EqualValues(t, len(tc.ids), count)
+		ids := make([]int64, len(snippets))
+		for i, s := range snippets {
+			ids[i] = s.ID
+		}
+		assert.EqualValues(t, tc.ids, ids)
+	}
+}
+
+func TestSnippetCanBeReadBy(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	owner := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	other := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	admin := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+
+	limited := AssertExistsAndLoadBean(t, &Snippet{ID: 2}).(*Snippet)
+	assert.False(t, limited.CanBeReadBy(nil))
+	assert.True(t, limited.CanBeReadBy(other))
+
+	private := AssertExistsAndLoadBean(t, &Snippet{ID: 3}).(*Snippet)
+	assert.False(t, private.CanBeReadBy(other))
+	assert.True(t, private.CanBeReadBy(owner))
+	assert.True(t, private.CanBeReadBy(admin))
+	assert.False(t, private.CanBeEditedBy(other))
+}
+
+func TestSnippetComments(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	snippet := AssertExistsAndLoadBean(t, &Snippet{ID: 1}).(*Snippet)
+	poster := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+
+	c, err := CreateSnippetComment(snippet, poster, "Thanks")
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &Snippet{ID: snippet.ID, NumComments: 2})
+
+	comments, err := GetSnippetComments(snippet.ID, ListOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 2) {
+		assert.EqualValues(t, 4, comments[0].PosterID)
+		assert.EqualValues(t, c.ID, comments[1].ID)
+	}
+
+	_, err = GetSnippetCommentByID(2, c.ID)
+	assert.True(t, IsErrSnippetCommentNotExist(err))
+
+	assert.NoError(t, DeleteSnippetComment(c))
+	AssertNotExistsBean(t, &SnippetComment{ID: c.ID})
+	AssertExistsAndLoadBean(t, &Snippet{ID: snippet.ID, NumComments: 1})
+
+	assert.NoError(t, DeleteSnippet(snippet.ID))
+	AssertNotExistsBean(t, &Snippet{ID: snippet.ID})
+	AssertNotExistsBean(t, &SnippetComment{SnippetID: snippet.ID})
+}
@@ -42,6 +42,9 @@ func StarRepo(userID, repoID int64, star bool) error {
 		if _, err := sess.Delete(&Star{0, userID, repoID}); err != nil {
 			return err
 		}
+		if err := removeRepoFromStarLists(sess, userID, repoID); err != nil {
+			return err
+		}
 		if _, err := sess.Exec("UPDATE `repository` SET num_stars = num_stars - 1 WHERE id = ?", repoID); err != nil {
 			return err
 		}
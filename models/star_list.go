@@ -0,0 +1,230 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// StarList represents a named list a user organizes their starred repositories in
+type StarList struct {
+	ID          int64  `xorm:"pk autoincr"`
+	UserID      int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Name        string `xorm:"VARCHAR(100) UNIQUE(s) NOT NULL"`
+	Description string `xorm:"TEXT"`
+	IsPrivate   bool   `xorm:"NOT NULL DEFAULT false"`
+	NumRepos    int64  `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// StarListRepo represents a repository in a star list
+type StarListRepo struct {
+	ID          int64              `xorm:"pk autoincr"`
+	StarListID  int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	RepoID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// ErrStarListNotExist represents a "StarListNotExist" kind of error.
+type ErrStarListNotExist struct {
+	ID     int64
+	UserID int64
+}
+
+// IsErrStarListNotExist checks if an error is a ErrStarListNotExist.
+func IsErrStarListNotExist(err error) bool {
+	_, ok := err.(ErrStarListNotExist)
+	return ok
+}
+
+func (err ErrStarListNotExist) Error() string {
+	return fmt.Sprintf("star list does not exist [id: %d, user_id: %d]", err.ID, err.UserID)
+}
+
+// ErrStarListAlreadyExist represents a "StarListAlreadyExist" kind of error.
+type ErrStarListAlreadyExist struct {
+	Name string
+}
+
+// IsErrStarListAlreadyExist checks if an error is a ErrStarListAlreadyExist.
+func IsErrStarListAlreadyExist(err error) bool {
+	_, ok := err.(ErrStarListAlreadyExist)
+	return ok
+}
+
+func (err ErrStarListAlreadyExist) Error() string {
+	return fmt.Sprintf("star list already exists [name: %s]", err.Name)
+}
+
+func isStarListNameTaken(e Engine, userID, excludeID int64, name string) (bool, error) {
+	return e.Where("user_id = ? AND lower(name) = ? AND id != ?", userID, strings.ToLower(name), excludeID).
+		Exist(new(StarList))
+}
+
+// CreateStarList creates a new star list, its name must be unique among the lists of the user
+func CreateStarList(list *StarList) error {
+	list.Name = strings.TrimSpace(list.Name)
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if taken, err := isStarListNameTaken(sess, list.UserID, 0, list.Name); err != nil {
+		return err
+	} else if taken {
+		return ErrStarListAlreadyExist{list.Name}
+	}
+	if _, err := sess.Insert(list); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// UpdateStarList updates the name, description and visibility of the star list
+func UpdateStarList(list *StarList) error {
+	list.Name = strings.TrimSpace(list.Name)
+
+	if taken, err := isStarListNameTaken(x, list.UserID, list.ID, list.Name); err != nil {
+		return err
+	} else if taken {
+		return ErrStarListAlreadyExist{list.Name}
+	}
+	_, err := x.ID(list.ID).Cols("name", "description", "is_private").Update(list)
+	return err
+}
+
+// DeleteStarList deletes the star list, the repositories in it stay starred
+func DeleteStarList(list *StarList) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if _, err := sess.Delete(&StarListRepo{StarListID: list.ID}); err != nil {
+		return err
+	}
+	if _, err := sess.ID(list.ID).Delete(new(StarList)); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetStarListByID returns the star list of the user with the ID
+func GetStarListByID(userID, id int64) (*StarList, error) {
+	list := new(StarList)
+	has, err := x.Where("id = ? AND user_id = ?", id, userID).Get(list)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrStarListNotExist{ID: id, UserID: userID}
+	}
+	return list, nil
+}
+
+// GetStarLists returns the star lists of the user by name, with the number of repositories
+// in them. The private lists are only returned if includePrivate is true.
+func GetStarLists(userID int64, includePrivate bool) ([]*StarList, error) {
+	sess := x.Where("user_id = ?", userID)
+	if !includePrivate {
+		sess = sess.And("is_private = ?", false)
+	}
+	lists := make([]*StarList, 0, 5)
+	if err := sess.OrderBy("lower(name)").Find(&lists); err != nil {
+		return nil, err
+	}
+	if len(lists) == 0 {
+		return lists, nil
+	}
+
+	listIDs := make([]int64, 0, len(lists))
+	for _, list := range lists {
+		listIDs = append(listIDs, list.ID)
+	}
+	type starListCount struct {
+		StarListID int64
+		Count      int64
+	}
+	counts := make([]*starListCount, 0, len(lists))
+	if err := x.Select("star_list_id, count(*) AS count").
+		Table("star_list_repo").
+		In("star_list_id", listIDs).
+		GroupBy("star_list_id").
+		Find(&counts); err != nil {
+		return nil, err
+	}
+	numRepos := make(map[int64]int64, len(counts))
+	for _, count := range counts {
+		numRepos[count.StarListID] = count.Count
+	}
+	for _, list := range lists {
+		list.NumRepos = numRepos[list.ID]
+	}
+	return lists, nil
+}
+
+// GetStarListIDsByRepos returns the IDs of the star lists of the user each repository is in
+func GetStarListIDsByRepos(userID int64, repoIDs []int64) (map[int64][]int64, error) {
+	listRepos := make([]*StarListRepo, 0, len(repoIDs))
+	if err := x.Join("INNER", "star_list", "star_list.id = star_list_repo.star_list_id").
+		Where("star_list.user_id = ?", userID).
+		In("star_list_repo.repo_id", repoIDs).
+		Find(&listRepos); err != nil {
+		return nil, err
+	}
+	listIDs := make(map[int64][]int64, len(repoIDs))
+	for _, listRepo := range listRepos {
+		listIDs[listRepo.RepoID] = append(listIDs[listRepo.RepoID], listRepo.StarListID)
+	}
+	return listIDs, nil
+}
+
+// HasRepo returns true if the repository is in the star list
+func (list *StarList) HasRepo(repoID int64) (bool, error) {
+	return x.Exist(&StarListRepo{StarListID: list.ID, RepoID: repoID})
+}
+
+// AddRepo adds a repository to the star list, starring it for the owner of the list if needed
+func (list *StarList) AddRepo(repoID int64) error {
+	if err := StarRepo(list.UserID, repoID, true); err != nil {
+		return err
+	}
+	if has, err := list.HasRepo(repoID); err != nil || has {
+		return err
+	}
+	_, err := x.Insert(&StarListRepo{StarListID: list.ID, RepoID: repoID})
+	return err
+}
+
+// RemoveRepo removes a repository from the star list, it stays starred
+func (list *StarList) RemoveRepo(repoID int64) error {
+	_, err := x.Delete(&StarListRepo{StarListID: list.ID, RepoID: repoID})
+	return err
+}
+
+// removeRepoFromStarLists removes an unstarred repository from the star lists of the user
+func removeRepoFromStarLists(e Engine, userID, repoID int64) error {
+	_, err := e.Where(builder.Eq{"repo_id": repoID}.
+		And(builder.In("star_list_id", builder.Select("id").From("star_list").Where(builder.Eq{"user_id": userID})))).
+		Delete(new(StarListRepo))
+	return err
+}
+
+// deleteStarListsByUserID deletes the star lists of a deleted user
+func deleteStarListsByUserID(e Engine, userID int64) error {
+	if _, err := e.Where(builder.In("star_list_id", builder.Select("id").From("star_list").Where(builder.Eq{"user_id": userID}))).
+		Delete(new(StarListRepo)); err != nil {
+		return err
+	}
+	_, err := e.Delete(&StarList{UserID: userID})
+	return err
+}
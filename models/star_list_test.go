@@ -0,0 +1,113 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateStarList(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	list := &StarList{UserID: 2, Name: " Tools ", Description: "useful tools"}
+	assert.NoError(t, CreateStarList(list))
+	assert.EqualValues(t, "Tools", list.Name)
+	AssertExistsAndLoadBean(t, &StarList{ID: list.ID, UserID: 2, Name: "Tools"})
+
+	err := CreateStarList(&StarList{UserID: 2, Name: "tools"})
+	assert.True(t, IsErrStarListAlreadyExist(err))
+
+	// the names are unique per user
+	assert.NoError(t, CreateStarList(&StarList{UserID: 4, Name: "Tools"}))
+}
+
+func TestUpdateStarList(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	tools := &StarList{UserID: 2, Name: "Tools"}
+	assert.NoError(t, CreateStarList(tools))
+	libs := &StarList{UserID: 2, Name: "Libraries"}
+	assert.NoError(t, CreateStarList(libs))
+
+	libs.Name = "TOOLS"
+	assert.True(t, IsErrStarListAlreadyExist(UpdateStarList(libs)))
+
+	libs.Name = "Libs"
+	libs.IsPrivate = true
+	assert.NoError(t, UpdateStarList(libs))
+	AssertExistsAndLoadBean(t, &StarList{ID: libs.ID, Name: "Libs", IsPrivate: true})
+}
+
+func TestStarList_Repos(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	list := &StarList{UserID: 2, Name: "Tools"}
+	assert.NoError(t, CreateStarList(list))
+	private := &StarList{UserID: 2, Name: "Secret", IsPrivate: true}
+	assert.NoError(t, CreateStarList(private))
+
+	// adding a repository stars it
+	AssertNotExistsBean(t, &Star{UID: 2, RepoID: 1})
+	assert.NoError(t, list.AddRepo(1))
+	assert.NoError(t, list.AddRepo(1))
+	assert.NoError(t, list.AddRepo(4))
+	assert.NoError(t, private.AddRepo(4))
+	AssertExistsAndLoadBean(t, &Star{UID: 2, RepoID: 1})
+	AssertExistsAndLoadBean(t, &StarListRepo{StarListID: list.ID, RepoID: 1})
+
+	lists, err := GetStarLists(2, true)
+	assert.NoError(t, err)
+	if assert.Len(t, lists, 2) {
+		assert.EqualValues(t, "Secret", lists[0].Name)
+		assert.EqualValues(t, 1, lists[0].NumRepos)
+		assert.EqualValues(t, "Tools", lists[1].Name)
+		assert.EqualValues(t, 2, lists[1].NumRepos)
+	}
+	lists, err = GetStarLists(2, false)
+	assert.NoError(t, err)
+	assert.Len(t, lists, 1)
+
+	listIDs, err := GetStarListIDsByRepos(2, []int64{1, 2, 4})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{list.ID}, listIDs[1])
+	assert.Empty(t, listIDs[2])
+	assert.ElementsMatch(t, []int64{list.ID, private.ID}, listIDs[4])
+
+	repos, _, err := SearchRepository(&SearchRepoOptions{Actor: &User{ID: 2}, Private: true, StarListID: list.ID})
+	assert.NoError(t, err)
+	assert.Len(t, repos, 2)
+
+	// removing a repository keeps it starred
+	assert.NoError(t, list.RemoveRepo(1))
+	AssertNotExistsBean(t, &StarListRepo{StarListID: list.ID, RepoID: 1})
+	AssertExistsAndLoadBean(t, &Star{UID: 2, RepoID: 1})
+
+	// unstarring a repository removes it from the lists
+	assert.NoError(t, StarRepo(2, 4, false))
+	AssertNotExistsBean(t, &StarListRepo{RepoID: 4})
+
+	// deleting a list keeps the repositories starred
+	assert.NoError(t, list.AddRepo(1))
+	assert.NoError(t, DeleteStarList(list))
+	AssertNotExistsBean(t, &StarList{ID: list.ID})
+	AssertNotExistsBean(t, &StarListRepo{StarListID: list.ID})
+	AssertExistsAndLoadBean(t, &Star{UID: 2, RepoID: 1})
+}
+
+func TestGetStarListByID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	list := &StarList{UserID: 2, Name: "Tools"}
+	assert.NoError(t, CreateStarList(list))
+
+	got, err := GetStarListByID(2, list.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Tools", got.Name)
+
+	_, err = GetStarListByID(4, list.ID)
+	assert.True(t, IsErrStarListNotExist(err))
+}
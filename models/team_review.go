@@ -0,0 +1,221 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"xorm.io/builder"
+)
+
+// ReviewAssignAlgorithm represents how the members of a team are picked when
+// the team is requested to review a pull request
+type ReviewAssignAlgorithm int
+
+// enumerate all review assign algorithms
+const (
+	// ReviewAssignAll requests a review from all available members
+	ReviewAssignAll ReviewAssignAlgorithm = iota // 0
+	// ReviewAssignRoundRobin requests a review from the members in turns
+	ReviewAssignRoundRobin // 1
+	// ReviewAssignLoadBalance requests a review from the members with the fewest open review requests
+	ReviewAssignLoadBalance // 2
+)
+
+var reviewAssignAlgorithmNames = map[ReviewAssignAlgorithm]string{
+	ReviewAssignAll:         "all",
+	ReviewAssignRoundRobin:  "round_robin",
+	ReviewAssignLoadBalance: "load_balance",
+}
+
+// ReviewAssignAlgorithms returns all review assign algorithms
+func ReviewAssignAlgorithms() []ReviewAssignAlgorithm {
+	return []ReviewAssignAlgorithm{ReviewAssignAll, ReviewAssignRoundRobin, ReviewAssignLoadBalance}
+}
+
+// String returns the name of the review assign algorithm
+func (a ReviewAssignAlgorithm) String() string {
+	return reviewAssignAlgorithmNames[a]
+}
+
+// ParseReviewAssignAlgorithm returns the review assign algorithm with the name
+func ParseReviewAssignAlgorithm(name string) (ReviewAssignAlgorithm, bool) {
+	if len(name) == 0 {
+		return ReviewAssignAll, true
+	}
+	for a, n := range reviewAssignAlgorithmNames {
+		if n == name {
+			return a, true
+		}
+	}
+	return ReviewAssignAll, false
+}
+
+// ErrTeamNotReviewer represents a "TeamNotReviewer" kind of error.
+type ErrTeamNotReviewer struct {
+	TeamID int64
+	RepoID int64
+}
+
+// IsErrTeamNotReviewer checks if an error is a ErrTeamNotReviewer.
+func IsErrTeamNotReviewer(err error) bool {
+	_, ok := err.(ErrTeamNotReviewer)
+	return ok
+}
+
+func (err ErrTeamNotReviewer) Error() string {
+	return fmt.Sprintf("team cannot review the repository [team_id: %d, repo_id: %d]", err.TeamID, err.RepoID)
+}
+
+// GetRepoReviewTeams returns the teams of the owner of the repository which
+// can be requested to review its pull requests.
+func GetRepoReviewTeams(repo *Repository) ([]*Team, error) {
+	if err := repo.getOwner(x); err != nil {
+		return nil, err
+	}
+	if !repo.Owner.IsOrganization() {
+		return nil, nil
+	}
+	teams, err := repo.getRepoTeams(x)
+	if err != nil {
+		return nil, err
+	}
+	reviewTeams := teams[:0]
+	for _, t := range teams {
+		if t.NumMembers > 0 && t.unitEnabled(x, UnitTypePullRequests) {
+			reviewTeams = append(reviewTeams, t)
+		}
+	}
+	return reviewTeams, nil
+}
+
+// countOpenReviewRequests returns the number of review requests of open
+// pull requests the users have not answered yet.
+func countOpenReviewRequests(e Engine, userIDs []int64) (map[int64]int64, error) {
+	latest := builder.Select("MAX(id)").From("review").
+		Where(builder.In("type", ReviewTypeApprove, ReviewTypeReject, ReviewTypeRequest).
+			And(builder.In("reviewer_id", userIDs))).
+		GroupBy("issue_id, reviewer_id")
+
+	type reviewCount struct {
+		ReviewerID int64
+		Count      int64
+	}
+	counts := make([]*reviewCount, 0, len(userIDs))
+	if err := e.Table("review").
+		Select("reviewer_id, COUNT(*) AS count").
+		Where(builder.In("id", latest)).
+		And("type = ?", ReviewTypeRequest).
+		And(builder.In("issue_id", builder.Select("id").From("issue").Where(builder.Eq{"is_closed": false}))).
+		GroupBy("reviewer_id").
+		Find(&counts); err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]int64, len(counts))
+	for _, c := range counts {
+		result[c.ReviewerID] = c.Count
+	}
+	return result, nil
+}
+
+// getTeamReviewCandidates returns the members of the team which can be
+// requested to review the pull request, ordered by ID. Members who are
+// unavailable, out of office, already requested, the poster or the doer are
+// skipped.
+func getTeamReviewCandidates(e Engine, team *Team, issue *Issue, doer *User) ([]*User, error) {
+	members, err := getTeamMembers(e, team.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*User, 0, len(members))
+	for _, u := range members {
+		if u.IsUnavailable || u.IsAway() || !u.IsActive || u.ProhibitLogin || u.ID == doer.ID || u.ID == issue.PosterID {
+			continue
+		}
+		review, err := getReviewerByIssueIDAndUserID(e, issue.ID, u.ID)
+		if err != nil {
+			return nil, err
+		} else if review.ID > 0 && review.Type == ReviewTypeRequest {
+			continue
+		}
+		perm, err := getUserRepoPermission(e, issue.Repo, u)
+		if err != nil {
+			return nil, err
+		} else if !perm.CanRead(UnitTypePullRequests) {
+			continue
+		}
+		candidates = append(candidates, u)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].ID < candidates[j].ID
+	})
+	return candidates, nil
+}
+
+// PickTeamReviewers returns the members of the team to request a review of
+// the pull request from, according to the review assign algorithm of the
+// team. The member picked last is remembered for the round robin.
+func PickTeamReviewers(team *Team, issue *Issue, doer *User) ([]*User, error) {
+	if err := issue.loadRepo(x); err != nil {
+		return nil, err
+	}
+	if team.OrgID != issue.Repo.OwnerID || !team.hasRepository(x, issue.Repo.ID) || !team.unitEnabled(x, UnitTypePullRequests) {
+		return nil, ErrTeamNotReviewer{TeamID: team.ID, RepoID: issue.Repo.ID}
+	}
+
+	candidates, err := getTeamReviewCandidates(x, team, issue, doer)
+	if err != nil {
+		return nil, err
+	}
+	count := team.ReviewAssignCount
+	if count <= 0 {
+		count = 1
+	}
+	if team.ReviewAssignAlgorithm == ReviewAssignAll || len(candidates) <= count {
+		return candidates, pickedTeamReviewers(team, candidates)
+	}
+
+	var picked []*User
+	switch team.ReviewAssignAlgorithm {
+	case ReviewAssignRoundRobin:
+		start := 0
+		for i, u := range candidates {
+			if u.ID > team.ReviewAssignLastUserID {
+				start = i
+				break
+			}
+		}
+		for i := 0; i < count; i++ {
+			picked = append(picked, candidates[(start+i)%len(candidates)])
+		}
+	case ReviewAssignLoadBalance:
+		ids := make([]int64, len(candidates))
+		for i, u := range candidates {
+			ids[i] = u.ID
+		}
+		counts, err := countOpenReviewRequests(x, ids)
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return counts[candidates[i].ID] < counts[candidates[j].ID]
+		})
+		picked = candidates[:count]
+	}
+	return picked, pickedTeamReviewers(team, picked)
+}
+
+// pickedTeamReviewers remembers the last member picked for the round robin
+func pickedTeamReviewers(team *Team, picked []*User) error {
+	if team.ReviewAssignAlgorithm != ReviewAssignRoundRobin || len(picked) == 0 {
+		return nil
+	}
+	team.ReviewAssignLastUserID = picked[len(picked)-1].ID
+	_, err := x.ID(team.ID).Cols("review_assign_last_user_id").Update(team)
+	return err
+}
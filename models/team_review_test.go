@@ -0,0 +1,86 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func reviewerIDs(users []*User) []int64 {
+	ids := make([]int64, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	return ids
+}
+
+func TestPickTeamReviewers(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// team1 of user3 has the members user2 and user4 and access to repo3
+	team := AssertExistsAndLoadBean(t, &Team{ID: 2}).(*Team)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+
+	reviewers, err := PickTeamReviewers(team, issue, doer)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int64{2, 4}, reviewerIDs(reviewers))
+
+	// the doer is never picked
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	reviewers, err = PickTeamReviewers(team, issue, user2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int64{4}, reviewerIDs(reviewers))
+
+	team.ReviewAssignAlgorithm = ReviewAssignRoundRobin
+	team.ReviewAssignCount = 1
+	for _, expected := range []int64{2, 4, 2} {
+		reviewers, err = PickTeamReviewers(team, issue, doer)
+		assert.NoError(t, err)
+		assert.EqualValues(t, []int64{expected}, reviewerIDs(reviewers))
+	}
+	AssertExistsAndLoadBean(t, &Team{ID: 2, ReviewAssignLastUserID: 2})
+
+	// user2 has an open review request
+	team.ReviewAssignAlgorithm = ReviewAssignLoadBalance
+	_, err = x.Insert(&Review{Type: ReviewTypeRequest, ReviewerID: 2, IssueID: 2})
+	assert.NoError(t, err)
+	reviewers, err = PickTeamReviewers(team, issue, doer)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int64{4}, reviewerIDs(reviewers))
+
+	// unavailable members are skipped
+	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	user4.IsUnavailable = true
+	assert.NoError(t, UpdateUserCols(user4, "is_unavailable"))
+	reviewers, err = PickTeamReviewers(team, issue, doer)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int64{2}, reviewerIDs(reviewers))
+
+	// test_team has no access to repo3
+	team = AssertExistsAndLoadBean(t, &Team{ID: 7}).(*Team)
+	_, err = PickTeamReviewers(team, issue, doer)
+	assert.True(t, IsErrTeamNotReviewer(err))
+}
+
+func TestGetRepoReviewTeams(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	teams, err := GetRepoReviewTeams(repo)
+	assert.NoError(t, err)
+	if assert.Len(t, teams, 2) {
+		assert.EqualValues(t, 1, teams[0].ID)
+		assert.EqualValues(t, 2, teams[1].ID)
+	}
+
+	// repositories of users have no teams
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	teams, err = GetRepoReviewTeams(repo)
+	assert.NoError(t, err)
+	assert.Len(t, teams, 0)
+}
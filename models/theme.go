@@ -0,0 +1,485 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	ini "gopkg.in/ini.v1"
+	"xorm.io/builder"
+)
+
+// ThemeNamePattern matches the allowed names of the uploaded themes, which are
+// used in CSS class names.
+var ThemeNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9\-]{0,29}$`)
+
+// The files of a theme bundle
+const (
+	ThemeBundleManifest = "theme.ini"
+	ThemeBundleCSS      = "theme.css"
+	ThemeBundleHeader   = "templates/header.tmpl"
+	ThemeBundleFooter   = "templates/footer.tmpl"
+)
+
+// Theme represents a theme uploaded by a site administrator, the users can select it like
+// the themes of the [ui] THEMES setting.
+type Theme struct {
+	ID          int64  `xorm:"pk autoincr"`
+	Name        string `xorm:"UNIQUE NOT NULL"`
+	DisplayName string
+	Description string `xorm:"TEXT"`
+	// Version is the version in use, older versions are kept so they can be used again.
+	Version     int                `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// ThemeVersion is an uploaded bundle of a theme.
+type ThemeVersion struct {
+	ID      int64  `xorm:"pk autoincr"`
+	ThemeID int64  `xorm:"UNIQUE(s) NOT NULL"`
+	Version int    `xorm:"UNIQUE(s) NOT NULL"`
+	CSS     string `xorm:"LONGTEXT"`
+	// Header and Footer are templates rendered at the end of the head and the body of the pages.
+	Header string `xorm:"LONGTEXT"`
+	Footer string `xorm:"LONGTEXT"`
+	// SHA256 is the checksum of the bundle.
+	SHA256      string `xorm:"VARCHAR(64)"`
+	Size        int64
+	UploaderID  int64              `xorm:"NOT NULL DEFAULT 0"`
+	Uploader    *User              `xorm:"-"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// ErrThemeNotExist represents a "ThemeNotExist" kind of error.
+type ErrThemeNotExist struct {
+	Name    string
+	Version int
+}
+
+// IsErrThemeNotExist checks if an error is a ErrThemeNotExist.
+func IsErrThemeNotExist(err error) bool {
+	_, ok := err.(ErrThemeNotExist)
+	return ok
+}
+
+func (err ErrThemeNotExist) Error() string {
+	if err.Version > 0 {
+		return fmt.Sprintf("theme version does not exist [name: %s, version: %d]", err.Name, err.Version)
+	}
+	return fmt.Sprintf("theme does not exist [name: %s]", err.Name)
+}
+
+// ErrThemeBundleInvalid represents a "ThemeBundleInvalid" kind of error.
+type ErrThemeBundleInvalid struct {
+	Reason string
+}
+
+// IsErrThemeBundleInvalid checks if an error is a ErrThemeBundleInvalid.
+func IsErrThemeBundleInvalid(err error) bool {
+	_, ok := err.(ErrThemeBundleInvalid)
+	return ok
+}
+
+func (err ErrThemeBundleInvalid) Error() string {
+	return fmt.Sprintf("invalid theme bundle: %s", err.Reason)
+}
+
+// IsBuiltinTheme returns true if the theme is one of the [ui] THEMES setting.
+func IsBuiltinTheme(name string) bool {
+	for _, theme := range setting.UI.Themes {
+		if strings.EqualFold(theme, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// CSSLink returns the URL of the stylesheet of the theme in use, it changes with the version
+// so it can be cached forever.
+func (t *Theme) CSSLink() string {
+	return setting.AppSubURL + "/themes/" + t.Name + "/" + strconv.Itoa(t.Version) + "/theme.css"
+}
+
+// CSSURL returns the absolute URL of the stylesheet of the theme in use.
+func (t *Theme) CSSURL() string {
+	return setting.AppURL + "themes/" + t.Name + "/" + strconv.Itoa(t.Version) + "/theme.css"
+}
+
+// LoadUploader loads the user who uploaded the version.
+func (v *ThemeVersion) LoadUploader() error {
+	if v.Uploader != nil {
+		return nil
+	}
+	var err error
+	v.Uploader, err = GetUserByID(v.UploaderID)
+	if IsErrUserNotExist(err) {
+		v.Uploader = NewGhostUser()
+		return nil
+	}
+	return err
+}
+
+// ThemeBundle is the content of a theme bundle.
+type ThemeBundle struct {
+	Name        string
+	DisplayName string
+	Description string
+	CSS         string
+	Header      string
+	Footer      string
+}
+
+func readThemeBundleFile(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", ErrThemeBundleInvalid{fmt.Sprintf("%s: %v", f.Name, err)}
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return "", ErrThemeBundleInvalid{fmt.Sprintf("%s: %v", f.Name, err)}
+	}
+	if !utf8.Valid(data) {
+		return "", ErrThemeBundleInvalid{f.Name + " is not UTF-8 text"}
+	}
+	return string(data), nil
+}
+
+// ParseThemeBundle reads and validates a zip file containing a theme bundle.
+func ParseThemeBundle(data []byte) (*ThemeBundle, error) {
+	if int64(len(data)) > setting.UI.ThemeMaxFileSize {
+		return nil, ErrThemeBundleInvalid{fmt.Sprintf("the bundle is larger than %d bytes", setting.UI.ThemeMaxFileSize)}
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrThemeBundleInvalid{"the bundle is not a zip file"}
+	}
+
+	bundle := &ThemeBundle{}
+	var manifest string
+	var total uint64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		// the size is checked before reading to refuse zip bombs
+		total += f.UncompressedSize64
+		if total > uint64(setting.UI.ThemeMaxFileSize) {
+			return nil, ErrThemeBundleInvalid{fmt.Sprintf("the content of the bundle is larger than %d bytes", setting.UI.ThemeMaxFileSize)}
+		}
+		var content *string
+		switch f.Name {
+		case ThemeBundleManifest:
+			content = &manifest
+		case ThemeBundleCSS:
+			content = &bundle.CSS
+		case ThemeBundleHeader:
+			content = &bundle.Header
+		case ThemeBundleFooter:
+			content = &bundle.Footer
+		default:
+			return nil, ErrThemeBundleInvalid{"unknown file " + f.Name}
+		}
+		if *content, err = readThemeBundleFile(f); err != nil {
+			return nil, err
+		}
+	}
+
+	if manifest == "" {
+		return nil, ErrThemeBundleInvalid{ThemeBundleManifest + " is missing"}
+	}
+	cfg, err := ini.Load([]byte(manifest))
+	if err != nil {
+		return nil, ErrThemeBundleInvalid{fmt.Sprintf("%s: %v", ThemeBundleManifest, err)}
+	}
+	sec := cfg.Section("")
+	bundle.Name = strings.TrimSpace(sec.Key("NAME").String())
+	bundle.DisplayName = strings.TrimSpace(sec.Key("DISPLAY_NAME").MustString(bundle.Name))
+	bundle.Description = strings.TrimSpace(sec.Key("DESCRIPTION").String())
+	if !ThemeNamePattern.MatchString(bundle.Name) {
+		return nil, ErrThemeBundleInvalid{fmt.Sprintf("the name %q must be made of at most 30 lowercase letters, digits and dashes", bundle.Name)}
+	}
+	if IsBuiltinTheme(bundle.Name) {
+		return nil, ErrThemeBundleInvalid{fmt.Sprintf("the name %q is used by a built-in theme", bundle.Name)}
+	}
+	if len(bundle.DisplayName) > 100 {
+		return nil, ErrThemeBundleInvalid{"the display name is longer than 100 characters"}
+	}
+
+	if strings.TrimSpace(bundle.CSS) == "" {
+		return nil, ErrThemeBundleInvalid{ThemeBundleCSS + " is missing"}
+	}
+	for name, tmpl := range map[string]string{ThemeBundleHeader: bundle.Header, ThemeBundleFooter: bundle.Footer} {
+		if _, err := parseThemeTemplate(name, tmpl); err != nil {
+			return nil, ErrThemeBundleInvalid{fmt.Sprintf("%s: %v", name, err)}
+		}
+	}
+	return bundle, nil
+}
+
+// ThemeTemplateFuncs are the functions the templates of the themes can use, they are set by
+// the templates module since it depends on this one.
+var ThemeTemplateFuncs []template.FuncMap
+
+func parseThemeTemplate(name, text string) (*template.Template, error) {
+	tmpl := template.New(name)
+	for _, funcs := range ThemeTemplateFuncs {
+		tmpl.Funcs(funcs)
+	}
+	return tmpl.Parse(text)
+}
+
+// UploadThemeBundle adds a theme bundle as the new version of its theme, the theme is created
+// if it does not exist.
+func UploadThemeBundle(doer *User, data []byte) (*Theme, error) {
+	bundle, err := ParseThemeBundle(data)
+	if err != nil {
+		return nil, err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	theme := &Theme{Name: bundle.Name}
+	has, err := sess.Get(theme)
+	if err != nil {
+		return nil, err
+	}
+	theme.DisplayName = bundle.DisplayName
+	theme.Description = bundle.Description
+	if !has {
+		if _, err := sess.Insert(theme); err != nil {
+			return nil, err
+		}
+	}
+
+	latest := new(ThemeVersion)
+	if _, err := sess.Where("theme_id=?", theme.ID).Cols("version").Desc("version").Get(latest); err != nil {
+		return nil, err
+	}
+	version := &ThemeVersion{
+		ThemeID:    theme.ID,
+		Version:    latest.Version + 1,
+		CSS:        bundle.CSS,
+		Header:     bundle.Header,
+		Footer:     bundle.Footer,
+		SHA256:     fmt.Sprintf("%x", sha256.Sum256(data)),
+		Size:       int64(len(data)),
+		UploaderID: doer.ID,
+	}
+	if _, err := sess.Insert(version); err != nil {
+		return nil, err
+	}
+	theme.Version = version.Version
+	if _, err := sess.ID(theme.ID).Cols("display_name", "description", "version").Update(theme); err != nil {
+		return nil, err
+	}
+	if err := sess.Commit(); err != nil {
+		return nil, err
+	}
+	resetThemeCache()
+	return theme, nil
+}
+
+// GetThemes returns the uploaded themes.
+func GetThemes() ([]*Theme, error) {
+	themes := make([]*Theme, 0, 5)
+	return themes, x.OrderBy("name").Find(&themes)
+}
+
+// GetThemeByName returns the uploaded theme with the name.
+func GetThemeByName(name string) (*Theme, error) {
+	theme := &Theme{Name: name}
+	if has, err := x.Get(theme); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrThemeNotExist{Name: name}
+	}
+	return theme, nil
+}
+
+// GetThemeVersions returns the uploaded versions of a theme, the latest first.
+func GetThemeVersions(themeID int64) ([]*ThemeVersion, error) {
+	versions := make([]*ThemeVersion, 0, 5)
+	return versions, x.Where("theme_id=?", themeID).Omit("css", "header", "footer").Desc("version").Find(&versions)
+}
+
+// GetThemeVersion returns a version of a theme.
+func GetThemeVersion(theme *Theme, version int) (*ThemeVersion, error) {
+	v := &ThemeVersion{ThemeID: theme.ID, Version: version}
+	if has, err := x.Get(v); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrThemeNotExist{Name: theme.Name, Version: version}
+	}
+	return v, nil
+}
+
+// SetThemeVersion changes the version of a theme in use.
+func SetThemeVersion(theme *Theme, version int) error {
+	v, err := GetThemeVersion(theme, version)
+	if err != nil {
+		return err
+	}
+	theme.Version = v.Version
+	if _, err := x.ID(theme.ID).Cols("version").Update(theme); err != nil {
+		return err
+	}
+	resetThemeCache()
+	return nil
+}
+
+// DeleteTheme deletes an uploaded theme with all its versions, its users get the default theme.
+func DeleteTheme(theme *Theme) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if _, err := sess.Delete(&ThemeVersion{ThemeID: theme.ID}); err != nil {
+		return err
+	}
+	if _, err := sess.ID(theme.ID).Delete(new(Theme)); err != nil {
+		return err
+	}
+	if _, err := sess.Where(builder.Eq{"theme": theme.Name}).Cols("theme").Update(&User{Theme: setting.UI.DefaultTheme}); err != nil {
+		return err
+	}
+	if err := sess.Commit(); err != nil {
+		return err
+	}
+	resetThemeCache()
+	return nil
+}
+
+// activeTheme is a theme with its version in use, the templates are parsed once.
+type activeTheme struct {
+	*Theme
+	CSS    string
+	Header *template.Template
+	Footer *template.Template
+}
+
+var (
+	themeCache      map[string]*activeTheme
+	themeCacheMutex sync.RWMutex
+)
+
+func resetThemeCache() {
+	themeCacheMutex.Lock()
+	themeCache = nil
+	themeCacheMutex.Unlock()
+}
+
+func loadThemeCache() (map[string]*activeTheme, error) {
+	themeCacheMutex.RLock()
+	cache := themeCache
+	themeCacheMutex.RUnlock()
+	if cache != nil {
+		return cache, nil
+	}
+
+	themeCacheMutex.Lock()
+	defer themeCacheMutex.Unlock()
+	if themeCache != nil {
+		return themeCache, nil
+	}
+	themes, err := GetThemes()
+	if err != nil {
+		return nil, err
+	}
+	cache = make(map[string]*activeTheme, len(themes))
+	for _, theme := range themes {
+		v, err := GetThemeVersion(theme, theme.Version)
+		if err != nil {
+			return nil, err
+		}
+		active := &activeTheme{Theme: theme, CSS: v.CSS}
+		// the templates were validated on upload
+		if active.Header, err = parseThemeTemplate(ThemeBundleHeader, v.Header); err != nil {
+			return nil, err
+		}
+		if active.Footer, err = parseThemeTemplate(ThemeBundleFooter, v.Footer); err != nil {
+			return nil, err
+		}
+		cache[theme.Name] = active
+	}
+	themeCache = cache
+	return cache, nil
+}
+
+// GetUploadedTheme returns the uploaded theme with the name from the cache, or nil if it
+// does not exist.
+func GetUploadedTheme(name string) (*Theme, error) {
+	cache, err := loadThemeCache()
+	if err != nil || cache[name] == nil {
+		return nil, err
+	}
+	return cache[name].Theme, nil
+}
+
+// GetUploadedThemeCSS returns the stylesheet of the version of a theme, it is only
+// available for the version in use.
+func GetUploadedThemeCSS(name string, version int) (string, error) {
+	cache, err := loadThemeCache()
+	if err != nil {
+		return "", err
+	}
+	if theme := cache[name]; theme != nil && theme.Version == version {
+		return theme.CSS, nil
+	}
+	return "", ErrThemeNotExist{Name: name, Version: version}
+}
+
+// RenderUploadedThemeTemplate renders the header or the footer template of the version in
+// use of a theme with the data of the page, it renders nothing if the theme was not
+// uploaded.
+func RenderUploadedThemeTemplate(name, part string, data interface{}) (template.HTML, error) {
+	cache, err := loadThemeCache()
+	if err != nil || cache[name] == nil {
+		return "", err
+	}
+	tmpl := cache[name].Header
+	if part == "footer" {
+		tmpl = cache[name].Footer
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// GetAllThemeNames returns the names of the themes the users can select, the built-in ones
+// first.
+func GetAllThemeNames() ([]string, error) {
+	themes, err := GetThemes()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(setting.UI.Themes)+len(themes))
+	names = append(names, setting.UI.Themes...)
+	for _, theme := range themes {
+		names = append(names, theme.Name)
+	}
+	return names, nil
+}
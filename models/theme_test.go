@@ -0,0 +1,127 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func themeBundle(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestParseThemeBundle(t *testing.T) {
+	bundle, err := ParseThemeBundle(themeBundle(t, map[string]string{
+		ThemeBundleManifest: "NAME = ocean\nDISPLAY_NAME = Ocean\nDESCRIPTION = Blue everywhere",
+		ThemeBundleCSS:      "body { color: blue; }",
+		ThemeBundleFooter:   "<p>{{.Title}}</p>",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "ocean", bundle.Name)
+	assert.Equal(t, "Ocean", bundle.DisplayName)
+	assert.Equal(t, "Blue everywhere", bundle.Description)
+	assert.Equal(t, "body { color: blue; }", bundle.CSS)
+	assert.Empty(t, bundle.Header)
+
+	for _, files := range []map[string]string{
+		{ThemeBundleCSS: "body {}"},
+		{ThemeBundleManifest: "NAME = ocean"},
+		{ThemeBundleManifest: "NAME = Ocean!", ThemeBundleCSS: "body {}"},
+		{ThemeBundleManifest: "NAME = " + setting.UI.Themes[0], ThemeBundleCSS: "body {}"},
+		{ThemeBundleManifest: "NAME = ocean", ThemeBundleCSS: "body {}", "script.js": "alert(1)"},
+		{ThemeBundleManifest: "NAME = ocean", ThemeBundleCSS: "body {}", ThemeBundleHeader: "{{if}}"},
+	} {
+		_, err = ParseThemeBundle(themeBundle(t, files))
+		assert.True(t, IsErrThemeBundleInvalid(err), "%v", files)
+	}
+
+	_, err = ParseThemeBundle([]byte("not a zip"))
+	assert.True(t, IsErrThemeBundleInvalid(err))
+}
+
+func TestUploadThemeBundle(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+
+	theme, err := UploadThemeBundle(doer, themeBundle(t, map[string]string{
+		ThemeBundleManifest: "NAME = ocean",
+		ThemeBundleCSS:      "body { color: blue; }",
+		ThemeBundleHeader:   "<meta name=\"theme\" content=\"{{.Title}}\">",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, theme.Version)
+	assert.Equal(t, setting.AppSubURL+"/themes/ocean/1/theme.css", theme.CSSLink())
+
+	css, err := GetUploadedThemeCSS("ocean", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "body { color: blue; }", css)
+	header, err := RenderUploadedThemeTemplate("ocean", "header", map[string]interface{}{"Title": "Home"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, `<meta name="theme" content="Home">`, header)
+
+	theme, err = UploadThemeBundle(doer, themeBundle(t, map[string]string{
+		ThemeBundleManifest: "NAME = ocean\nDISPLAY_NAME = Deep Ocean",
+		ThemeBundleCSS:      "body { color: navy; }",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, theme.Version)
+	assert.Equal(t, "Deep Ocean", theme.DisplayName)
+
+	// only the version in use is served
+	_, err = GetUploadedThemeCSS("ocean", 1)
+	assert.True(t, IsErrThemeNotExist(err))
+	versions, err := GetThemeVersions(theme.ID)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, 2, versions[0].Version)
+
+	assert.NoError(t, SetThemeVersion(theme, 1))
+	css, err = GetUploadedThemeCSS("ocean", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "body { color: blue; }", css)
+	assert.True(t, IsErrThemeNotExist(SetThemeVersion(theme, 3)))
+
+	names, err := GetAllThemeNames()
+	assert.NoError(t, err)
+	assert.Equal(t, append(append([]string{}, setting.UI.Themes...), "ocean"), names)
+}
+
+func TestDeleteTheme(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+
+	theme, err := UploadThemeBundle(doer, themeBundle(t, map[string]string{
+		ThemeBundleManifest: "NAME = forest",
+		ThemeBundleCSS:      "body { color: green; }",
+	}))
+	assert.NoError(t, err)
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	user.Theme = "forest"
+	assert.NoError(t, UpdateUserCols(user, "theme"))
+
+	assert.NoError(t, DeleteTheme(theme))
+	AssertNotExistsBean(t, &Theme{Name: "forest"})
+	AssertNotExistsBean(t, &ThemeVersion{ThemeID: theme.ID})
+	user = AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.Equal(t, setting.UI.DefaultTheme, user.Theme)
+	uploaded, err := GetUploadedTheme("forest")
+	assert.NoError(t, err)
+	assert.Nil(t, uploaded)
+}
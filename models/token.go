@@ -40,6 +40,10 @@ func (t *AccessToken) AfterLoad() {
 
 // NewAccessToken creates new access token.
 func NewAccessToken(t *AccessToken) error {
+	return newAccessToken(x, t)
+}
+
+func newAccessToken(e Engine, t *AccessToken) error {
 	salt, err := generate.GetRandomString(10)
 	if err != nil {
 		return err
@@ -48,7 +52,7 @@ func NewAccessToken(t *AccessToken) error {
 	t.Token = base.EncodeSha1(gouuid.New().String())
 	t.TokenHash = hashToken(t.Token, t.TokenSalt)
 	t.TokenLastEight = t.Token[len(t.Token)-8:]
-	_, err = x.Insert(t)
+	_, err = e.Insert(t)
 	return err
 }
 
@@ -27,6 +27,9 @@ var topicPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
 type Topic struct {
 	ID          int64
 	Name        string `xorm:"UNIQUE VARCHAR(25)"`
+	Description string `xorm:"TEXT"`
+	Logo        string `xorm:"VARCHAR(255)"`
+	IsFeatured  bool   `xorm:"INDEX NOT NULL DEFAULT false"`
 	RepoCount   int
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
@@ -54,6 +57,22 @@ func (err ErrTopicNotExist) Error() string {
 	return fmt.Sprintf("topic is not exist [name: %s]", err.Name)
 }
 
+// ErrTopicMergeSelf represents an error that a topic is merged into itself
+type ErrTopicMergeSelf struct {
+	Name string
+}
+
+// IsErrTopicMergeSelf checks if an error is an ErrTopicMergeSelf.
+func IsErrTopicMergeSelf(err error) bool {
+	_, ok := err.(ErrTopicMergeSelf)
+	return ok
+}
+
+// Error implements error interface
+func (err ErrTopicMergeSelf) Error() string {
+	return fmt.Sprintf("topic cannot be merged into itself [name: %s]", err.Name)
+}
+
 // ValidateTopic checks a topic by length and match pattern rules
 func ValidateTopic(topic string) bool {
 	return len(topic) <= 35 && topicPattern.MatchString(topic)
@@ -97,6 +116,23 @@ func GetTopicByName(name string) (*Topic, error) {
 	return &topic, nil
 }
 
+// GetTopicByID retrieves topic by ID
+func GetTopicByID(id int64) (*Topic, error) {
+	var topic Topic
+	if has, err := x.ID(id).Get(&topic); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrTopicNotExist{fmt.Sprintf("#%d", id)}
+	}
+	return &topic, nil
+}
+
+// UpdateTopic updates the curated information of a topic
+func UpdateTopic(topic *Topic) error {
+	_, err := x.ID(topic.ID).Cols("description", "logo", "is_featured").Update(topic)
+	return err
+}
+
 // addTopicByNameToRepo adds a topic name to a repo and increments the topic count.
 // Returns topic after the addition
 func addTopicByNameToRepo(e Engine, repoID int64, topicName string) (*Topic, error) {
@@ -166,8 +202,9 @@ func removeTopicsFromRepo(e Engine, repoID int64) error {
 // FindTopicOptions represents the options when fdin topics
 type FindTopicOptions struct {
 	ListOptions
-	RepoID  int64
-	Keyword string
+	RepoID       int64
+	Keyword      string
+	FeaturedOnly bool
 }
 
 func (opts *FindTopicOptions) toConds() builder.Cond {
@@ -180,6 +217,10 @@ func (opts *FindTopicOptions) toConds() builder.Cond {
 		cond = cond.And(builder.Like{"topic.name", opts.Keyword})
 	}
 
+	if opts.FeaturedOnly {
+		cond = cond.And(builder.Eq{"topic.is_featured": true})
+	}
+
 	return cond
 }
 
@@ -195,6 +236,15 @@ func FindTopics(opts *FindTopicOptions) (topics []*Topic, err error) {
 	return topics, sess.Desc("topic.repo_count").Find(&topics)
 }
 
+// CountTopics counts the topics via FindTopicOptions
+func CountTopics(opts *FindTopicOptions) (int64, error) {
+	sess := x.Where(opts.toConds())
+	if opts.RepoID > 0 {
+		sess.Join("INNER", "repo_topic", "repo_topic.topic_id = topic.id")
+	}
+	return sess.Count(&Topic{})
+}
+
 // GetRepoTopicByName retrives topic from name for a repo if it exist
 func GetRepoTopicByName(repoID int64, topicName string) (*Topic, error) {
 	var cond = builder.NewCond()
@@ -301,16 +351,102 @@ func SaveTopics(repoID int64, topicNames ...string) error {
 		}
 	}
 
-	topicNames = make([]string, 0, 25)
-	if err := sess.Table("topic").Cols("name").
+	if err := updateRepoTopicNames(sess, repoID); err != nil {
+		return err
+	}
+
+	return sess.Commit()
+}
+
+// updateRepoTopicNames syncs the topics column of a repository with its associated topics
+func updateRepoTopicNames(e Engine, repoID int64) error {
+	topicNames := make([]string, 0, 25)
+	if err := e.Table("topic").Cols("name").
 		Join("INNER", "repo_topic", "repo_topic.topic_id = topic.id").
 		Where("repo_topic.repo_id = ?", repoID).Desc("topic.repo_count").Find(&topicNames); err != nil {
 		return err
 	}
 
-	if _, err := sess.ID(repoID).Cols("topics").Update(&Repository{
+	_, err := e.ID(repoID).Cols("topics").Update(&Repository{
 		Topics: topicNames,
-	}); err != nil {
+	})
+	return err
+}
+
+// mergeTopics moves the repositories matching repoCond from topic from to topic to
+func mergeTopics(e Engine, from, to *Topic, repoCond builder.Cond) error {
+	if from.ID == to.ID {
+		return ErrTopicMergeSelf{from.Name}
+	}
+
+	cond := builder.Eq{"topic_id": from.ID}.And(repoCond)
+	repoIDs := make([]int64, 0, 10)
+	if err := e.Table("repo_topic").Cols("repo_id").Where(cond).Find(&repoIDs); err != nil {
+		return err
+	}
+
+	for _, repoID := range repoIDs {
+		has, err := e.Get(&RepoTopic{RepoID: repoID, TopicID: to.ID})
+		if err != nil {
+			return err
+		}
+		if has {
+			_, err = e.Delete(&RepoTopic{RepoID: repoID, TopicID: from.ID})
+		} else {
+			_, err = e.Where("repo_id = ? AND topic_id = ?", repoID, from.ID).
+				Cols("topic_id").Update(&RepoTopic{TopicID: to.ID})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, topic := range []*Topic{from, to} {
+		count, err := e.Where("topic_id = ?", topic.ID).Count(new(RepoTopic))
+		if err != nil {
+			return err
+		}
+		topic.RepoCount = int(count)
+		if _, err = e.ID(topic.ID).Cols("repo_count").Update(topic); err != nil {
+			return err
+		}
+	}
+
+	for _, repoID := range repoIDs {
+		if err := updateRepoTopicNames(e, repoID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MergeTopics merges topic from into topic to for all repositories and deletes topic from
+func MergeTopics(from, to *Topic) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := mergeTopics(sess, from, to, builder.NewCond()); err != nil {
+		return err
+	}
+
+	// Keep the organization curation of topic from unless the organization already curates topic to
+	orgIDs := make([]int64, 0, 10)
+	if err := sess.Table("org_topic").Cols("org_id").Where("topic_id = ?", to.ID).Find(&orgIDs); err != nil {
+		return err
+	}
+	if len(orgIDs) > 0 {
+		if _, err := sess.Where("topic_id = ?", from.ID).In("org_id", orgIDs).Delete(new(OrgTopic)); err != nil {
+			return err
+		}
+	}
+	if _, err := sess.Where("topic_id = ?", from.ID).Cols("topic_id").Update(&OrgTopic{TopicID: to.ID}); err != nil {
+		return err
+	}
+
+	if _, err := sess.ID(from.ID).Delete(new(Topic)); err != nil {
 		return err
 	}
 
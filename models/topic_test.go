@@ -75,3 +75,52 @@ func TestTopicValidator(t *testing.T) {
 	assert.False(t, ValidateTopic("-fifth-test-topic"))
 	assert.False(t, ValidateTopic("sixth-go-project-topic-with-excess-length"))
 }
+
+func TestMergeTopics(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	golang := AssertExistsAndLoadBean(t, &Topic{ID: 1}).(*Topic)
+	graphql := AssertExistsAndLoadBean(t, &Topic{ID: 4}).(*Topic)
+	err := MergeTopics(golang, golang)
+	assert.True(t, IsErrTopicMergeSelf(err))
+
+	// repo 33 already has topic golang
+	assert.NoError(t, MergeTopics(graphql, golang))
+	AssertNotExistsBean(t, &Topic{ID: 4})
+	AssertNotExistsBean(t, &RepoTopic{TopicID: 4})
+	golang = AssertExistsAndLoadBean(t, &Topic{ID: 1}).(*Topic)
+	assert.EqualValues(t, 2, golang.RepoCount)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 33}).(*Repository)
+	assert.Equal(t, []string{"golang"}, repo.Topics)
+
+	database := AssertExistsAndLoadBean(t, &Topic{ID: 2}).(*Topic)
+	topicname2 := AssertExistsAndLoadBean(t, &Topic{ID: 6}).(*Topic)
+	assert.NoError(t, MergeTopics(database, topicname2))
+	AssertExistsAndLoadBean(t, &RepoTopic{RepoID: 1, TopicID: 6})
+	topicname2 = AssertExistsAndLoadBean(t, &Topic{ID: 6}).(*Topic)
+	assert.EqualValues(t, 2, topicname2.RepoCount)
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.Contains(t, repo.Topics, "topicname2")
+	assert.NotContains(t, repo.Topics, "database")
+}
+
+func TestUpdateTopic(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	topic, err := GetTopicByName("golang")
+	assert.NoError(t, err)
+	topic.Description = "The Go programming language"
+	topic.Logo = "https://golang.org/logo.png"
+	topic.IsFeatured = true
+	assert.NoError(t, UpdateTopic(topic))
+
+	topics, err := FindTopics(&FindTopicOptions{FeaturedOnly: true})
+	assert.NoError(t, err)
+	if assert.Len(t, topics, 1) {
+		assert.EqualValues(t, 1, topics[0].ID)
+		assert.Equal(t, "The Go programming language", topics[0].Description)
+	}
+	count, err := CountTopics(&FindTopicOptions{Keyword: "topicname"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+}
@@ -0,0 +1,36 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/tracing"
+
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+	"xorm.io/xorm/contexts"
+)
+
+// tracingHook records a span for every SQL statement run with the context of a
+// traced operation, e.g. a request, as part of its trace.
+type tracingHook struct{}
+
+func (tracingHook) BeforeProcess(c *contexts.ContextHook) (context.Context, error) {
+	operation := strings.ToUpper(strings.SplitN(strings.TrimSpace(c.SQL), " ", 2)[0])
+	ctx, _ := tracing.StartChild(c.Ctx, "db "+operation,
+		semconv.DBSystemKey.String(setting.Database.Type),
+		semconv.DBOperationKey.String(operation),
+		semconv.DBStatementKey.String(c.SQL),
+	)
+	return ctx, nil
+}
+
+func (tracingHook) AfterProcess(c *contexts.ContextHook) error {
+	tracing.End(trace.SpanFromContext(c.Ctx), c.Err)
+	return nil
+}
@@ -24,6 +24,7 @@ const (
 	UnitTypeWiki                                // 5 Wiki
 	UnitTypeExternalWiki                        // 6 ExternalWiki
 	UnitTypeExternalTracker                     // 7 ExternalTracker
+	UnitTypeDiscussions                         // 8 Discussions
 )
 
 // Value returns integer value for unit type
@@ -47,6 +48,8 @@ func (u UnitType) String() string {
 		return "UnitTypeExternalWiki"
 	case UnitTypeExternalTracker:
 		return "UnitTypeExternalTracker"
+	case UnitTypeDiscussions:
+		return "UnitTypeDiscussions"
 	}
 	return fmt.Sprintf("Unknown UnitType %d", u)
 }
@@ -68,6 +71,7 @@ var (
 		UnitTypeWiki,
 		UnitTypeExternalWiki,
 		UnitTypeExternalTracker,
+		UnitTypeDiscussions,
 	}
 
 	// DefaultRepoUnits contains the default unit types
@@ -242,6 +246,14 @@ var (
 		4,
 	}
 
+	UnitDiscussions = Unit{
+		UnitTypeDiscussions,
+		"repo.discussions",
+		"/discussions",
+		"repo.discussions.desc",
+		5,
+	}
+
 	// Units contains all the units
 	Units = map[UnitType]Unit{
 		UnitTypeCode:            UnitCode,
@@ -251,6 +263,7 @@ var (
 		UnitTypeReleases:        UnitReleases,
 		UnitTypeWiki:            UnitWiki,
 		UnitTypeExternalWiki:    UnitExternalWiki,
+		UnitTypeDiscussions:     UnitDiscussions,
 	}
 )
 
@@ -55,6 +55,9 @@ const (
 
 	// UserTypeOrganization defines an organization
 	UserTypeOrganization
+
+	// UserTypeBot defines a bot account owned by an organization
+	UserTypeBot
 )
 
 const (
@@ -149,10 +152,11 @@ type User struct {
 	UseCustomAvatar bool
 
 	// Counters
-	NumFollowers int
-	NumFollowing int `xorm:"NOT NULL DEFAULT 0"`
-	NumStars     int
-	NumRepos     int
+	NumFollowers    int
+	NumFollowing    int `xorm:"NOT NULL DEFAULT 0"`
+	NumStars        int
+	NumRepos        int
+	NumPrivateRepos int `xorm:"NOT NULL DEFAULT 0"`
 
 	// For organization
 	NumTeams                  int
@@ -167,6 +171,16 @@ type User struct {
 	DiffViewStyle       string `xorm:"NOT NULL DEFAULT ''"`
 	Theme               string `xorm:"NOT NULL DEFAULT ''"`
 	KeepActivityPrivate bool   `xorm:"NOT NULL DEFAULT false"`
+	// DashboardWidgets are the widgets of the dashboard in their order, nil means the defaults
+	DashboardWidgets []string `xorm:"JSON TEXT"`
+	// IsUnavailable users are skipped when reviewers are picked from a team
+	IsUnavailable bool `xorm:"NOT NULL DEFAULT false"`
+
+	// Out of office
+	AwayFromUnix  timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	AwayUntilUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	AwayMessage   string             `xorm:"NOT NULL DEFAULT ''"`
+	AwayAutoReply bool               `xorm:"NOT NULL DEFAULT false"`
 }
 
 // SearchOrganizationsOptions options to filter organizations
@@ -416,7 +430,7 @@ func (u *User) RealSizedAvatarLink(size int) string {
 		if !com.IsFile(u.CustomAvatarPath()) {
 			return base.DefaultAvatarLink()
 		}
-		return setting.AppSubURL + "/avatars/" + u.Avatar
+		return u.localAvatarLink(size)
 	case setting.DisableGravatar, setting.OfflineMode:
 		if !com.IsFile(u.CustomAvatarPath()) {
 			if err := u.GenerateRandomAvatar(); err != nil {
@@ -424,11 +438,21 @@ func (u *User) RealSizedAvatarLink(size int) string {
 			}
 		}
 
-		return setting.AppSubURL + "/avatars/" + u.Avatar
+		return u.localAvatarLink(size)
 	}
 	return base.SizedAvatarLink(u.AvatarEmail, size)
 }
 
+// localAvatarLink returns the link to the avatar stored by Gitea, or to its variant closest to
+// the indicated size.
+func (u *User) localAvatarLink(size int) string {
+	link := setting.AppSubURL + "/" + avatar.KindUser + "/" + u.Avatar
+	if variant := avatar.VariantSize(size); variant > 0 {
+		link += "/" + strconv.Itoa(variant)
+	}
+	return link
+}
+
 // RelAvatarLink returns a relative link to the user's avatar. The link
 // may either be a sub-URL to this site, or a full URL to an external avatar
 // service.
@@ -551,6 +575,7 @@ func (u *User) UploadAvatar(data []byte) error {
 		return err
 	}
 
+	avatar.RemoveVariants(avatar.KindUser, u.Avatar)
 	u.UseCustomAvatar = true
 	// Different users can upload same image as avatar
 	// If we prefix it with u.ID, it will be separated
@@ -585,6 +610,7 @@ func (u *User) DeleteAvatar() error {
 		if err := os.Remove(u.CustomAvatarPath()); err != nil {
 			return fmt.Errorf("Failed to remove %s: %v", u.CustomAvatarPath(), err)
 		}
+		avatar.RemoveVariants(avatar.KindUser, u.Avatar)
 	}
 
 	u.UseCustomAvatar = false
@@ -600,6 +626,11 @@ func (u *User) IsOrganization() bool {
 	return u.Type == UserTypeOrganization
 }
 
+// IsBot returns true if user is a bot account owned by an organization.
+func (u *User) IsBot() bool {
+	return u.Type == UserTypeBot
+}
+
 // IsUserOrgOwner returns true if user is in the owner team of given organization.
 func (u *User) IsUserOrgOwner(orgID int64) bool {
 	isOwner, err := IsOrganizationOwner(orgID, u.ID)
@@ -881,6 +912,7 @@ func (u *User) IsGhost() bool {
 
 var (
 	reservedUsernames = append([]string{
+		"-",
 		".",
 		"..",
 		".well-known",
@@ -1228,9 +1260,12 @@ func deleteUser(e *xorm.Session, u *User) error {
 
 	if err = deleteBeans(e,
 		&AccessToken{UID: u.ID},
+		&DeployToken{CreatorID: u.ID},
 		&Collaboration{UserID: u.ID},
 		&Access{UserID: u.ID},
 		&Watch{UserID: u.ID},
+		&WatchPath{UserID: u.ID},
+		&ContributorAgreementSignature{UserID: u.ID},
 		&Star{UID: u.ID},
 		&Follow{UserID: u.ID},
 		&Follow{FollowID: u.ID},
@@ -1242,10 +1277,23 @@ func deleteUser(e *xorm.Session, u *User) error {
 		&TeamUser{UID: u.ID},
 		&Collaboration{UserID: u.ID},
 		&Stopwatch{UserID: u.ID},
+		&PinnedRepo{UID: u.ID},
+		&UserBadge{UserID: u.ID},
+		&WorkspaceChange{UserID: u.ID},
+		&RecentItem{UserID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	if err = deleteStarListsByUserID(e, u.ID); err != nil {
+		return fmt.Errorf("deleteStarListsByUserID: %v", err)
+	}
+
+	snippetIDs, err := deleteSnippetsByOwnerID(e, u.ID)
+	if err != nil {
+		return fmt.Errorf("deleteSnippetsByOwnerID: %v", err)
+	}
+
 	// ***** START: PublicKey *****
 	if _, err = e.Delete(&PublicKey{OwnerID: u.ID}); err != nil {
 		return fmt.Errorf("deletePublicKeys: %v", err)
@@ -1286,6 +1334,12 @@ func deleteUser(e *xorm.Session, u *User) error {
 		return fmt.Errorf("Failed to RemoveAll %s: %v", path, err)
 	}
 
+	for _, id := range snippetIDs {
+		if err := os.RemoveAll(SnippetPath(id)); err != nil {
+			return fmt.Errorf("Failed to RemoveAll %s: %v", SnippetPath(id), err)
+		}
+	}
+
 	if len(u.Avatar) > 0 {
 		avatarPath := u.CustomAvatarPath()
 		if com.IsExist(avatarPath) {
@@ -1490,6 +1544,27 @@ func ValidateCommitWithEmail(c *git.Commit) *User {
 	return u
 }
 
+// UserSignature represents a person named in a commit, e.g. by a Co-authored-by
+// trailer, with the user of its e-mail.
+type UserSignature struct {
+	// User is nil if the e-mail does not belong to a user
+	User *User
+	*git.Signature
+}
+
+// ValidateSignaturesWithEmails checks if the e-mails of signatures are corresponding to users.
+func ValidateSignaturesWithEmails(sigs []*git.Signature) []*UserSignature {
+	users := make([]*UserSignature, len(sigs))
+	for i, sig := range sigs {
+		u, err := GetUserByEmail(sig.Email)
+		if err != nil {
+			u = nil
+		}
+		users[i] = &UserSignature{User: u, Signature: sig}
+	}
+	return users
+}
+
 // ValidateCommitsWithEmails checks if authors' e-mails of commits are corresponding to users.
 func ValidateCommitsWithEmails(oldCommits *list.List) *list.List {
 	var (
@@ -1643,23 +1718,31 @@ func (opts *SearchUserOptions) toConds() builder.Cond {
 
 // SearchUsers takes options i.e. keyword and part of user name to search,
 // it returns results in given range and number of total results.
-func SearchUsers(opts *SearchUserOptions) (users []*User, _ int64, _ error) {
+func SearchUsers(opts *SearchUserOptions) (users []*User, count int64, err error) {
+	if len(opts.OrderBy) == 0 {
+		opts.OrderBy = SearchOrderByAlphabetically
+	}
+
+	err = withReadEngine(func(e *xorm.Engine) error {
+		users, count, err = searchUsers(e, opts)
+		return err
+	})
+	return users, count, err
+}
+
+func searchUsers(e *xorm.Engine, opts *SearchUserOptions) ([]*User, int64, error) {
 	cond := opts.toConds()
-	count, err := x.Where(cond).Count(new(User))
+	count, err := e.Where(cond).Count(new(User))
 	if err != nil {
 		return nil, 0, fmt.Errorf("Count: %v", err)
 	}
 
-	if len(opts.OrderBy) == 0 {
-		opts.OrderBy = SearchOrderByAlphabetically
-	}
-
-	sess := x.Where(cond).OrderBy(opts.OrderBy.String())
+	sess := e.Where(cond).OrderBy(opts.OrderBy.String())
 	if opts.Page != 0 {
 		sess = opts.setSessionPagination(sess)
 	}
 
-	users = make([]*User, 0, opts.PageSize)
+	users := make([]*User, 0, opts.PageSize)
 	return users, count, sess.Find(&users)
 }
 
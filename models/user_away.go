@@ -0,0 +1,91 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// IsAwayAt returns true if the out of office period of the user contains
+// the given time.
+func (u *User) IsAwayAt(t timeutil.TimeStamp) bool {
+	return u.AwayFromUnix > 0 && u.AwayFromUnix <= t && t <= u.AwayUntilUnix
+}
+
+// IsAway returns true if the user is out of office now.
+func (u *User) IsAway() bool {
+	return u.IsAwayAt(timeutil.TimeStampNow())
+}
+
+// SetAwayPeriod sets the out of office period of the user from the start of
+// the first day to the end of the last day. Zero times clear the period.
+func (u *User) SetAwayPeriod(from, until time.Time) {
+	if from.IsZero() || until.IsZero() {
+		u.AwayFromUnix = 0
+		u.AwayUntilUnix = 0
+		return
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	until = time.Date(until.Year(), until.Month(), until.Day(), 23, 59, 59, 0, until.Location())
+	u.AwayFromUnix = timeutil.TimeStamp(from.Unix())
+	u.AwayUntilUnix = timeutil.TimeStamp(until.Unix())
+}
+
+// AwayUntil returns the last day of the out of office period of the note
+func (c *Comment) AwayUntil() string {
+	return strings.SplitN(c.Content, "|", 2)[0]
+}
+
+// AwayMessage returns the message of the user who was out of office
+func (c *Comment) AwayMessage() string {
+	parts := strings.SplitN(c.Content, "|", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+// CreateAwayNoteComment adds an automatic note to the issue telling that the
+// user is out of office. Only one note is added per issue and out of office
+// period, so nil is returned when the user has already noted the issue or
+// does not want automatic notes.
+func CreateAwayNoteComment(issue *Issue, u *User) (*Comment, error) {
+	if !u.AwayAutoReply || !u.IsAway() {
+		return nil, nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	has, err := sess.Where("issue_id = ? AND poster_id = ? AND type = ? AND created_unix >= ?",
+		issue.ID, u.ID, CommentTypeAwayNote, u.AwayFromUnix).
+		Exist(new(Comment))
+	if err != nil {
+		return nil, err
+	} else if has {
+		return nil, nil
+	}
+
+	if err := issue.loadRepo(sess); err != nil {
+		return nil, err
+	}
+	comment, err := createComment(sess, &CreateCommentOptions{
+		Type:    CommentTypeAwayNote,
+		Doer:    u,
+		Repo:    issue.Repo,
+		Issue:   issue,
+		Content: u.AwayUntilUnix.Format("2006-01-02") + "|" + u.AwayMessage,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comment, sess.Commit()
+}
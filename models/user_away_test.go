@@ -0,0 +1,62 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser_IsAway(t *testing.T) {
+	u := &User{}
+	assert.False(t, u.IsAway())
+
+	now := time.Now()
+	u.SetAwayPeriod(now, now)
+	assert.True(t, u.IsAway())
+	assert.False(t, u.IsAwayAt(timeutil.TimeStamp(now.AddDate(0, 0, 1).Unix())))
+	assert.False(t, u.IsAwayAt(timeutil.TimeStamp(now.AddDate(0, 0, -1).Unix())))
+
+	u.SetAwayPeriod(now.AddDate(0, 0, 1), now.AddDate(0, 0, 7))
+	assert.False(t, u.IsAway())
+
+	u.SetAwayPeriod(time.Time{}, time.Time{})
+	assert.EqualValues(t, 0, u.AwayFromUnix)
+	assert.EqualValues(t, 0, u.AwayUntilUnix)
+}
+
+func TestCreateAwayNoteComment(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	user := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	now := time.Now()
+	user.SetAwayPeriod(now, now.AddDate(0, 0, 2))
+	user.AwayMessage = "back soon"
+
+	// no note without the automatic reply
+	comment, err := CreateAwayNoteComment(issue, user)
+	assert.NoError(t, err)
+	assert.Nil(t, comment)
+
+	user.AwayAutoReply = true
+	comment, err = CreateAwayNoteComment(issue, user)
+	assert.NoError(t, err)
+	if assert.NotNil(t, comment) {
+		assert.EqualValues(t, CommentTypeAwayNote, comment.Type)
+		assert.EqualValues(t, user.AwayUntilUnix.Format("2006-01-02"), comment.AwayUntil())
+		assert.EqualValues(t, "back soon", comment.AwayMessage())
+		AssertExistsAndLoadBean(t, &Comment{ID: comment.ID, IssueID: issue.ID, PosterID: user.ID})
+	}
+
+	// only one note per issue and out of office period
+	comment, err = CreateAwayNoteComment(issue, user)
+	assert.NoError(t, err)
+	assert.Nil(t, comment)
+}
@@ -0,0 +1,492 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// BadgeType represents the criteria a badge is granted by
+type BadgeType int
+
+// enumerate all badge types
+const (
+	// BadgeTypeManual badges are granted by site administrators
+	BadgeTypeManual BadgeType = iota // 0
+	// BadgeTypeFirstPullMerged badges are granted when the first pull request of a user is merged
+	BadgeTypeFirstPullMerged // 1
+	// BadgeTypeMemberYears badges are granted when a user has been registered for Threshold years
+	BadgeTypeMemberYears // 2
+)
+
+var badgeTypeNames = map[BadgeType]string{
+	BadgeTypeManual:          "manual",
+	BadgeTypeFirstPullMerged: "first_pull_merged",
+	BadgeTypeMemberYears:     "member_years",
+}
+
+// BadgeTypes returns all badge types
+func BadgeTypes() []BadgeType {
+	return []BadgeType{BadgeTypeManual, BadgeTypeFirstPullMerged, BadgeTypeMemberYears}
+}
+
+// String returns the name of the badge type
+func (t BadgeType) String() string {
+	return badgeTypeNames[t]
+}
+
+// IsAutomatic returns if badges of the type are granted automatically
+func (t BadgeType) IsAutomatic() bool {
+	return t != BadgeTypeManual
+}
+
+// ParseBadgeType returns the badge type with the name
+func ParseBadgeType(name string) (BadgeType, bool) {
+	if len(name) == 0 {
+		return BadgeTypeManual, true
+	}
+	for t, n := range badgeTypeNames {
+		if n == name {
+			return t, true
+		}
+	}
+	return BadgeTypeManual, false
+}
+
+// badgeIconPattern matches the octicons a badge can use as icon
+var badgeIconPattern = regexp.MustCompile(`^octicon-[a-z0-9\-]+$`)
+
+// Badge represents an achievement shown on the profiles of the users it was
+// granted to
+type Badge struct {
+	ID          int64     `xorm:"pk autoincr"`
+	Slug        string    `xorm:"UNIQUE NOT NULL"`
+	Name        string    `xorm:"NOT NULL"`
+	Description string    `xorm:"TEXT"`
+	Icon        string    `xorm:"VARCHAR(255)"`
+	Type        BadgeType `xorm:"NOT NULL DEFAULT 0"`
+	Threshold   int       `xorm:"NOT NULL DEFAULT 0"`
+	IsBuiltin   bool      `xorm:"NOT NULL DEFAULT false"`
+
+	NumUsers int64 `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// UserBadge represents a badge granted to a user
+type UserBadge struct {
+	ID          int64              `xorm:"pk autoincr"`
+	BadgeID     int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	UserID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// IsOcticon returns if the icon of the badge is an octicon, otherwise it is
+// the URL of an image
+func (b *Badge) IsOcticon() bool {
+	return badgeIconPattern.MatchString(b.Icon)
+}
+
+// Criteria returns a short description of when the badge is granted
+func (b *Badge) Criteria() string {
+	if b.Type == BadgeTypeMemberYears {
+		return fmt.Sprintf("%s:%d", b.Type, b.Threshold)
+	}
+	return b.Type.String()
+}
+
+func (b *Badge) validate() error {
+	b.Slug = strings.ToLower(strings.TrimSpace(b.Slug))
+	if len(b.Slug) == 0 || alphaDashDotPattern.MatchString(b.Slug) {
+		return ErrBadgeInvalid{"slug"}
+	}
+	if len(strings.TrimSpace(b.Name)) == 0 {
+		return ErrBadgeInvalid{"name"}
+	}
+	if _, ok := badgeTypeNames[b.Type]; !ok {
+		return ErrBadgeInvalid{"type"}
+	}
+	if b.Type == BadgeTypeMemberYears && b.Threshold <= 0 {
+		return ErrBadgeInvalid{"threshold"}
+	}
+	if len(b.Icon) > 0 && !badgeIconPattern.MatchString(b.Icon) &&
+		!strings.HasPrefix(b.Icon, "https://") && !strings.HasPrefix(b.Icon, "http://") && !strings.HasPrefix(b.Icon, "/") {
+		return ErrBadgeInvalid{"icon"}
+	}
+	return nil
+}
+
+// ErrBadgeNotExist represents a "BadgeNotExist" kind of error.
+type ErrBadgeNotExist struct {
+	ID   int64
+	Slug string
+}
+
+// IsErrBadgeNotExist checks if an error is a ErrBadgeNotExist.
+func IsErrBadgeNotExist(err error) bool {
+	_, ok := err.(ErrBadgeNotExist)
+	return ok
+}
+
+func (err ErrBadgeNotExist) Error() string {
+	return fmt.Sprintf("badge does not exist [id: %d, slug: %s]", err.ID, err.Slug)
+}
+
+// ErrBadgeAlreadyExist represents a "BadgeAlreadyExist" kind of error.
+type ErrBadgeAlreadyExist struct {
+	Slug string
+}
+
+// IsErrBadgeAlreadyExist checks if an error is a ErrBadgeAlreadyExist.
+func IsErrBadgeAlreadyExist(err error) bool {
+	_, ok := err.(ErrBadgeAlreadyExist)
+	return ok
+}
+
+func (err ErrBadgeAlreadyExist) Error() string {
+	return fmt.Sprintf("badge already exists [slug: %s]", err.Slug)
+}
+
+// ErrBadgeInvalid represents a "BadgeInvalid" kind of error.
+type ErrBadgeInvalid struct {
+	Field string
+}
+
+// IsErrBadgeInvalid checks if an error is a ErrBadgeInvalid.
+func IsErrBadgeInvalid(err error) bool {
+	_, ok := err.(ErrBadgeInvalid)
+	return ok
+}
+
+func (err ErrBadgeInvalid) Error() string {
+	return fmt.Sprintf("badge is invalid [field: %s]", err.Field)
+}
+
+// ErrBadgeBuiltin represents a "BadgeBuiltin" kind of error.
+type ErrBadgeBuiltin struct {
+	Slug string
+}
+
+// IsErrBadgeBuiltin checks if an error is a ErrBadgeBuiltin.
+func IsErrBadgeBuiltin(err error) bool {
+	_, ok := err.(ErrBadgeBuiltin)
+	return ok
+}
+
+func (err ErrBadgeBuiltin) Error() string {
+	return fmt.Sprintf("built-in badge cannot be deleted [slug: %s]", err.Slug)
+}
+
+// builtinBadges are created when Gitea starts, if they do not exist
+var builtinBadges = []*Badge{
+	{
+		Slug:        "first-pull-merged",
+		Name:        "First Pull Request",
+		Description: "Got a first pull request merged",
+		Icon:        "octicon-git-merge",
+		Type:        BadgeTypeFirstPullMerged,
+	},
+	{
+		Slug:        "member-1-year",
+		Name:        "One Year Member",
+		Description: "Has been a member for a year",
+		Icon:        "octicon-clock",
+		Type:        BadgeTypeMemberYears,
+		Threshold:   1,
+	},
+	{
+		Slug:        "member-5-years",
+		Name:        "Five Years Member",
+		Description: "Has been a member for five years",
+		Icon:        "octicon-star",
+		Type:        BadgeTypeMemberYears,
+		Threshold:   5,
+	},
+}
+
+// InitBadges creates the built-in badges, which do not exist yet
+func InitBadges() error {
+	for _, b := range builtinBadges {
+		has, err := x.Exist(&Badge{Slug: b.Slug})
+		if err != nil {
+			return err
+		} else if has {
+			continue
+		}
+		badge := *b
+		badge.IsBuiltin = true
+		if _, err = x.Insert(&badge); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateBadge creates a new badge
+func CreateBadge(b *Badge) error {
+	if err := b.validate(); err != nil {
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if has, err := sess.Exist(&Badge{Slug: b.Slug}); err != nil {
+		return err
+	} else if has {
+		return ErrBadgeAlreadyExist{b.Slug}
+	}
+	b.IsBuiltin = false
+	if _, err := sess.Insert(b); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// UpdateBadge updates the name, description, icon and criteria of the badge
+func UpdateBadge(b *Badge) error {
+	if err := b.validate(); err != nil {
+		return err
+	}
+	_, err := x.ID(b.ID).Cols("name", "description", "icon", "type", "threshold").Update(b)
+	return err
+}
+
+// DeleteBadge deletes the badge and revokes it from all users
+func DeleteBadge(b *Badge) error {
+	if b.IsBuiltin {
+		return ErrBadgeBuiltin{b.Slug}
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if _, err := sess.Delete(&UserBadge{BadgeID: b.ID}); err != nil {
+		return err
+	}
+	if _, err := sess.ID(b.ID).Delete(new(Badge)); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetBadgeByID returns the badge with the ID
+func GetBadgeByID(id int64) (*Badge, error) {
+	b := new(Badge)
+	has, err := x.ID(id).Get(b)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrBadgeNotExist{ID: id}
+	}
+	return b, nil
+}
+
+// GetBadgeBySlug returns the badge with the slug
+func GetBadgeBySlug(slug string) (*Badge, error) {
+	b := &Badge{Slug: strings.ToLower(slug)}
+	has, err := x.Get(b)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrBadgeNotExist{Slug: slug}
+	}
+	return b, nil
+}
+
+// GetBadges returns all badges with the number of users they were granted to
+func GetBadges() ([]*Badge, error) {
+	badges := make([]*Badge, 0, 10)
+	if err := x.Asc("id").Find(&badges); err != nil {
+		return nil, err
+	}
+
+	type badgeCount struct {
+		BadgeID int64
+		Count   int64
+	}
+	counts := make([]*badgeCount, 0, len(badges))
+	if err := x.Table("user_badge").
+		Select("badge_id, COUNT(*) AS count").
+		GroupBy("badge_id").
+		Find(&counts); err != nil {
+		return nil, err
+	}
+	for _, c := range counts {
+		for _, b := range badges {
+			if b.ID == c.BadgeID {
+				b.NumUsers = c.Count
+				break
+			}
+		}
+	}
+	return badges, nil
+}
+
+// GetUserBadges returns the badges granted to the user, in the order they
+// were granted
+func GetUserBadges(userID int64) ([]*Badge, error) {
+	badges := make([]*Badge, 0, 5)
+	return badges, x.Join("INNER", "user_badge", "user_badge.badge_id = badge.id").
+		Where("user_badge.user_id = ?", userID).
+		Asc("user_badge.id").
+		Find(&badges)
+}
+
+// GetBadgeUsers returns the users the badge was granted to
+func GetBadgeUsers(badgeID int64, opts ListOptions) ([]*User, int64, error) {
+	sess := x.Join("INNER", "user_badge", "user_badge.user_id = `user`.id").
+		Where("user_badge.badge_id = ?", badgeID)
+	count, err := sess.Count(new(User))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sess = x.Join("INNER", "user_badge", "user_badge.user_id = `user`.id").
+		Where("user_badge.badge_id = ?", badgeID).
+		Desc("user_badge.id")
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	users := make([]*User, 0, opts.PageSize)
+	return users, count, sess.Find(&users)
+}
+
+// HasUserBadge returns if the badge was granted to the user
+func HasUserBadge(userID, badgeID int64) (bool, error) {
+	return x.Exist(&UserBadge{UserID: userID, BadgeID: badgeID})
+}
+
+// AddUserBadge grants the badge to the user
+func AddUserBadge(u *User, b *Badge) error {
+	return addUserBadges(x, b.ID, []int64{u.ID})
+}
+
+// RemoveUserBadge revokes the badge from the user. Automatic badges are
+// granted again if the user still meets their criteria.
+func RemoveUserBadge(u *User, b *Badge) error {
+	_, err := x.Delete(&UserBadge{UserID: u.ID, BadgeID: b.ID})
+	return err
+}
+
+// addUserBadges grants the badge to the users, who do not have it yet
+func addUserBadges(e Engine, badgeID int64, userIDs []int64) error {
+	for len(userIDs) > 0 {
+		batch := userIDs
+		if len(batch) > 100 {
+			batch = batch[:100]
+		}
+		userIDs = userIDs[len(batch):]
+
+		var existing []int64
+		if err := e.Table("user_badge").
+			Where("badge_id = ?", badgeID).
+			In("user_id", batch).
+			Cols("user_id").
+			Find(&existing); err != nil {
+			return err
+		}
+		has := make(map[int64]bool, len(existing))
+		for _, id := range existing {
+			has[id] = true
+		}
+
+		userBadges := make([]*UserBadge, 0, len(batch))
+		for _, id := range batch {
+			if !has[id] {
+				has[id] = true
+				userBadges = append(userBadges, &UserBadge{BadgeID: badgeID, UserID: id})
+			}
+		}
+		if len(userBadges) == 0 {
+			continue
+		}
+		if _, err := e.Insert(&userBadges); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// badgeUsersCond returns the condition of the users, who meet the criteria
+// of the automatic badge
+func badgeUsersCond(b *Badge) builder.Cond {
+	switch b.Type {
+	case BadgeTypeFirstPullMerged:
+		return builder.In("id", builder.Select("issue.poster_id").
+			From("pull_request").
+			InnerJoin("issue", "issue.id = pull_request.issue_id").
+			Where(builder.Eq{"pull_request.has_merged": true}))
+	case BadgeTypeMemberYears:
+		return builder.Lte{"created_unix": time.Now().AddDate(-b.Threshold, 0, 0).Unix()}
+	}
+	return nil
+}
+
+// awardAutomaticBadges grants the automatic badges to the users matching the
+// condition, who meet their criteria
+func awardAutomaticBadges(ctx context.Context, userCond builder.Cond) error {
+	badges := make([]*Badge, 0, len(builtinBadges))
+	if err := x.Where("type <> ?", BadgeTypeManual).Find(&badges); err != nil {
+		return err
+	}
+
+	for _, b := range badges {
+		select {
+		case <-ctx.Done():
+			return ErrCancelledf("before awarding the badge %s", b.Slug)
+		default:
+		}
+
+		cond := badgeUsersCond(b)
+		if cond == nil {
+			continue
+		}
+		cond = cond.And(builder.Eq{"type": UserTypeIndividual}).
+			And(builder.NotIn("id", builder.Select("user_id").From("user_badge").Where(builder.Eq{"badge_id": b.ID})))
+		if userCond != nil {
+			cond = cond.And(userCond)
+		}
+
+		var userIDs []int64
+		if err := x.Table("user").Where(cond).Cols("id").Find(&userIDs); err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			continue
+		}
+		if err := addUserBadges(x, b.ID, userIDs); err != nil {
+			return err
+		}
+		log.Trace("Badge %s awarded to %d users", b.Slug, len(userIDs))
+	}
+	return nil
+}
+
+// AwardAutomaticBadges grants the automatic badges to all users, who meet
+// their criteria
+func AwardAutomaticBadges(ctx context.Context) error {
+	return awardAutomaticBadges(ctx, nil)
+}
+
+// AwardUserAutomaticBadges grants the automatic badges to the user, if they
+// meet their criteria
+func AwardUserAutomaticBadges(userID int64) error {
+	return awardAutomaticBadges(context.Background(), builder.Eq{"id": userID})
+}
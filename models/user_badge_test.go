@@ -0,0 +1,111 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitBadges(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, InitBadges())
+	b := AssertExistsAndLoadBean(t, &Badge{Slug: "member-5-years"}).(*Badge)
+	assert.True(t, b.IsBuiltin)
+	assert.Equal(t, BadgeTypeMemberYears, b.Type)
+	assert.Equal(t, 5, b.Threshold)
+	// existing built-in badges are left as they are
+	AssertCount(t, &Badge{Slug: "first-pull-merged"}, 1)
+
+	assert.True(t, IsErrBadgeBuiltin(DeleteBadge(b)))
+}
+
+func TestCreateBadge(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	b := &Badge{Slug: "Reviewer", Name: "Reviewer", Icon: "octicon-eye"}
+	assert.NoError(t, CreateBadge(b))
+	assert.Equal(t, "reviewer", b.Slug)
+	assert.True(t, b.IsOcticon())
+
+	assert.True(t, IsErrBadgeAlreadyExist(CreateBadge(&Badge{Slug: "reviewer", Name: "Reviewer"})))
+	assert.True(t, IsErrBadgeInvalid(CreateBadge(&Badge{Slug: "bad slug", Name: "Bad"})))
+	assert.True(t, IsErrBadgeInvalid(CreateBadge(&Badge{Slug: "veteran", Name: "Veteran", Type: BadgeTypeMemberYears})))
+	assert.True(t, IsErrBadgeInvalid(CreateBadge(&Badge{Slug: "script", Name: "Script", Icon: "javascript:alert(1)"})))
+
+	b.Name = "Code Reviewer"
+	b.Icon = "https://example.com/reviewer.png"
+	assert.NoError(t, UpdateBadge(b))
+	b = AssertExistsAndLoadBean(t, &Badge{ID: b.ID}).(*Badge)
+	assert.Equal(t, "Code Reviewer", b.Name)
+	assert.False(t, b.IsOcticon())
+
+	assert.NoError(t, DeleteBadge(b))
+	AssertNotExistsBean(t, &Badge{ID: b.ID})
+}
+
+func TestUserBadges(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	badge := AssertExistsAndLoadBean(t, &Badge{ID: 3}).(*Badge)
+
+	assert.NoError(t, AddUserBadge(user, badge))
+	assert.NoError(t, AddUserBadge(user, badge))
+	AssertCount(t, &UserBadge{BadgeID: 3, UserID: 4}, 1)
+
+	badges, err := GetUserBadges(4)
+	assert.NoError(t, err)
+	if assert.Len(t, badges, 1) {
+		assert.Equal(t, "contributor", badges[0].Slug)
+	}
+
+	users, count, err := GetBadgeUsers(3, ListOptions{Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	if assert.Len(t, users, 2) {
+		assert.EqualValues(t, 4, users[0].ID)
+		assert.EqualValues(t, 2, users[1].ID)
+	}
+
+	badges, err = GetBadges()
+	assert.NoError(t, err)
+	assert.Len(t, badges, 3)
+	assert.EqualValues(t, 2, badges[2].NumUsers)
+
+	assert.NoError(t, RemoveUserBadge(user, badge))
+	has, err := HasUserBadge(4, 3)
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	// deleting a badge revokes it
+	assert.NoError(t, DeleteBadge(badge))
+	AssertNotExistsBean(t, &UserBadge{BadgeID: 3})
+}
+
+func TestAwardAutomaticBadges(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	_, err := x.Exec("UPDATE `user` SET created_unix = ?", time.Now().Unix())
+	assert.NoError(t, err)
+	_, err = x.Exec("UPDATE `user` SET created_unix = ? WHERE id IN (1, 2, 3)", time.Now().AddDate(-2, 0, 0).Unix())
+	assert.NoError(t, err)
+
+	assert.NoError(t, AwardUserAutomaticBadges(1))
+	// user1 posted the merged pull request and was registered years ago
+	AssertExistsAndLoadBean(t, &UserBadge{BadgeID: 1, UserID: 1})
+	AssertExistsAndLoadBean(t, &UserBadge{BadgeID: 2, UserID: 1})
+	AssertNotExistsBean(t, &UserBadge{BadgeID: 2, UserID: 2})
+
+	assert.NoError(t, AwardAutomaticBadges(context.Background()))
+	AssertExistsAndLoadBean(t, &UserBadge{BadgeID: 2, UserID: 2})
+	AssertNotExistsBean(t, &UserBadge{BadgeID: 1, UserID: 2})
+	// organizations do not get badges
+	AssertNotExistsBean(t, &UserBadge{BadgeID: 2, UserID: 3})
+	AssertCount(t, &UserBadge{BadgeID: 1}, 1)
+	AssertCount(t, &UserBadge{BadgeID: 2}, 2)
+}
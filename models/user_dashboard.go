@@ -0,0 +1,86 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import "fmt"
+
+// The widgets of the dashboard
+const (
+	DashboardWidgetAssignedIssues  = "assigned_issues"
+	DashboardWidgetReviewRequests  = "review_requests"
+	DashboardWidgetCIFailures      = "ci_failures"
+	DashboardWidgetWatchedReleases = "watched_releases"
+	DashboardWidgetOrgActivity     = "org_activity"
+)
+
+// DashboardWidgets are all the widgets of the dashboard
+var DashboardWidgets = []string{
+	DashboardWidgetAssignedIssues,
+	DashboardWidgetReviewRequests,
+	DashboardWidgetCIFailures,
+	DashboardWidgetWatchedReleases,
+	DashboardWidgetOrgActivity,
+}
+
+// DefaultDashboardWidgets are the widgets of the users who did not change their dashboard
+var DefaultDashboardWidgets = []string{
+	DashboardWidgetAssignedIssues,
+	DashboardWidgetReviewRequests,
+}
+
+// IsValidDashboardWidget checks if the widget exists
+func IsValidDashboardWidget(widget string) bool {
+	for _, w := range DashboardWidgets {
+		if w == widget {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrInvalidDashboardWidget represents a "InvalidDashboardWidget" kind of error.
+type ErrInvalidDashboardWidget struct {
+	Widget string
+}
+
+// IsErrInvalidDashboardWidget checks if an error is a ErrInvalidDashboardWidget.
+func IsErrInvalidDashboardWidget(err error) bool {
+	_, ok := err.(ErrInvalidDashboardWidget)
+	return ok
+}
+
+func (err ErrInvalidDashboardWidget) Error() string {
+	return fmt.Sprintf("invalid or duplicated dashboard widget [widget: %s]", err.Widget)
+}
+
+// GetDashboardWidgets returns the widgets of the dashboard of the user in their order
+func (u *User) GetDashboardWidgets() []string {
+	if u.DashboardWidgets == nil {
+		return DefaultDashboardWidgets
+	}
+	// skip the widgets which do not exist anymore
+	widgets := make([]string, 0, len(u.DashboardWidgets))
+	for _, widget := range u.DashboardWidgets {
+		if IsValidDashboardWidget(widget) {
+			widgets = append(widgets, widget)
+		}
+	}
+	return widgets
+}
+
+// UpdateDashboardWidgets sets the widgets of the dashboard of the user and their order,
+// every widget can only be added once.
+func (u *User) UpdateDashboardWidgets(widgets []string) error {
+	seen := make(map[string]bool, len(widgets))
+	for _, widget := range widgets {
+		if !IsValidDashboardWidget(widget) || seen[widget] {
+			return ErrInvalidDashboardWidget{widget}
+		}
+		seen[widget] = true
+	}
+
+	u.DashboardWidgets = append(make([]string, 0, len(widgets)), widgets...)
+	return UpdateUserCols(u, "dashboard_widgets")
+}
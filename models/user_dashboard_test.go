@@ -0,0 +1,59 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateDashboardWidgets(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.Equal(t, DefaultDashboardWidgets, user.GetDashboardWidgets())
+
+	widgets := []string{DashboardWidgetCIFailures, DashboardWidgetAssignedIssues}
+	assert.NoError(t, user.UpdateDashboardWidgets(widgets))
+	user = AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.Equal(t, widgets, user.GetDashboardWidgets())
+
+	// all the widgets can be removed
+	assert.NoError(t, user.UpdateDashboardWidgets(nil))
+	user = AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.Empty(t, user.GetDashboardWidgets())
+
+	err := user.UpdateDashboardWidgets([]string{"unknown"})
+	assert.True(t, IsErrInvalidDashboardWidget(err))
+	err = user.UpdateDashboardWidgets([]string{DashboardWidgetCIFailures, DashboardWidgetCIFailures})
+	assert.True(t, IsErrInvalidDashboardWidget(err))
+}
+
+func TestFindFailedCommitStatuses(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// the fixtures have no context hashes
+	var statuses []*CommitStatus
+	assert.NoError(t, x.Find(&statuses))
+	for _, status := range statuses {
+		status.ContextHash = hashCommitStatusContext(status.Context)
+		_, err := x.ID(status.ID).Cols("context_hash").Update(status)
+		assert.NoError(t, err)
+	}
+
+	// cov/awesomeness passed after its warning
+	statuses, err := FindFailedCommitStatuses([]int64{1}, 0, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, statuses, 2) {
+		assert.EqualValues(t, 5, statuses[0].ID)
+		assert.EqualValues(t, 4, statuses[1].ID)
+		assert.EqualValues(t, 1, statuses[0].Repo.ID)
+	}
+
+	statuses, err = FindFailedCommitStatuses(nil, 0, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, statuses)
+}
@@ -0,0 +1,795 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// UserDumpVersion is the version of the format of the user dumps
+const UserDumpVersion = 1
+
+// userDumpKeyIterations is the number of PBKDF2 iterations deriving the key encrypting the
+// secrets of a user dump from its passphrase
+const userDumpKeyIterations = 100000
+
+// userDumpCheck is encrypted in the user dumps to check the passphrase before restoring them
+const userDumpCheck = "gitea-user-dump"
+
+// UserDump is a portable dump of the users, organizations, teams, SSH keys and access tokens
+// of an instance, the secrets of the users are encrypted with a passphrase
+type UserDump struct {
+	Version     int                `json:"version"`
+	CreatedUnix timeutil.TimeStamp `json:"created"`
+	// Salt is the salt of the key derived from the passphrase
+	Salt  string          `json:"salt"`
+	Check string          `json:"check"`
+	Users []*UserDumpUser `json:"users"`
+	Teams []*UserDumpTeam `json:"teams"`
+
+	key []byte
+}
+
+// UserDumpUser is a user or an organization of a user dump
+type UserDumpUser struct {
+	Name                         string              `json:"name"`
+	IsOrganization               bool                `json:"is_organization,omitempty"`
+	FullName                     string              `json:"full_name,omitempty"`
+	Email                        string              `json:"email"`
+	KeepEmailPrivate             bool                `json:"keep_email_private,omitempty"`
+	EmailNotificationsPreference string              `json:"email_notifications_preference,omitempty"`
+	MustChangePassword           bool                `json:"must_change_password,omitempty"`
+	LoginSourceName              string              `json:"login_source,omitempty"`
+	LoginName                    string              `json:"login_name,omitempty"`
+	Location                     string              `json:"location,omitempty"`
+	Website                      string              `json:"website,omitempty"`
+	Language                     string              `json:"language,omitempty"`
+	Description                  string              `json:"description,omitempty"`
+	CreatedUnix                  timeutil.TimeStamp  `json:"created"`
+	MaxRepoCreation              int                 `json:"max_repo_creation"`
+	IsActive                     bool                `json:"is_active,omitempty"`
+	IsAdmin                      bool                `json:"is_admin,omitempty"`
+	IsRestricted                 bool                `json:"is_restricted,omitempty"`
+	AllowGitHook                 bool                `json:"allow_git_hook,omitempty"`
+	AllowImportLocal             bool                `json:"allow_import_local,omitempty"`
+	AllowCreateOrganization      bool                `json:"allow_create_organization,omitempty"`
+	ProhibitLogin                bool                `json:"prohibit_login,omitempty"`
+	Visibility                   structs.VisibleType `json:"visibility,omitempty"`
+	RepoAdminChangeTeamAccess    bool                `json:"repo_admin_change_team_access,omitempty"`
+	DiffViewStyle                string              `json:"diff_view_style,omitempty"`
+	Theme                        string              `json:"theme,omitempty"`
+	KeepActivityPrivate          bool                `json:"keep_activity_private,omitempty"`
+	Emails                       []*UserDumpEmail    `json:"emails,omitempty"`
+	PublicKeys                   []*UserDumpKey      `json:"public_keys,omitempty"`
+	// Members are the members of an organization
+	Members []*UserDumpMember `json:"members,omitempty"`
+	// Secrets are the encrypted secrets of the user
+	Secrets string `json:"secrets,omitempty"`
+
+	secrets *UserDumpSecrets
+}
+
+// UserDumpEmail is an additional email address of a user of a user dump
+type UserDumpEmail struct {
+	Email       string `json:"email"`
+	IsActivated bool   `json:"is_activated"`
+}
+
+// UserDumpKey is an SSH key of a user of a user dump
+type UserDumpKey struct {
+	Name        string             `json:"name"`
+	Fingerprint string             `json:"fingerprint"`
+	Content     string             `json:"content"`
+	CreatedUnix timeutil.TimeStamp `json:"created"`
+}
+
+// UserDumpMember is a member of an organization of a user dump
+type UserDumpMember struct {
+	Name     string `json:"name"`
+	IsPublic bool   `json:"is_public,omitempty"`
+}
+
+// UserDumpSecrets are the secrets of a user of a user dump, encrypted in the dump
+type UserDumpSecrets struct {
+	Passwd         string                 `json:"passwd"`
+	PasswdHashAlgo string                 `json:"passwd_hash_algo"`
+	Salt           string                 `json:"salt"`
+	Rands          string                 `json:"rands"`
+	TwoFactor      *UserDumpTwoFactor     `json:"two_factor,omitempty"`
+	AccessTokens   []*UserDumpAccessToken `json:"access_tokens,omitempty"`
+}
+
+// UserDumpTwoFactor is the two-factor authentication of a user of a user dump
+type UserDumpTwoFactor struct {
+	Secret      string `json:"secret"`
+	ScratchSalt string `json:"scratch_salt"`
+	ScratchHash string `json:"scratch_hash"`
+}
+
+// UserDumpAccessToken is an access token of a user of a user dump, the tokens are only stored
+// hashed so they keep working once restored
+type UserDumpAccessToken struct {
+	Name           string             `json:"name"`
+	TokenHash      string             `json:"token_hash"`
+	TokenSalt      string             `json:"token_salt"`
+	TokenLastEight string             `json:"token_last_eight"`
+	CreatedUnix    timeutil.TimeStamp `json:"created"`
+}
+
+// UserDumpTeam is a team of an organization of a user dump, its repositories are not dumped
+type UserDumpTeam struct {
+	Org                     string     `json:"org"`
+	Name                    string     `json:"name"`
+	Description             string     `json:"description,omitempty"`
+	Authorize               AccessMode `json:"authorize"`
+	Parent                  string     `json:"parent,omitempty"`
+	IncludesAllRepositories bool       `json:"includes_all_repositories,omitempty"`
+	CanCreateOrgRepo        bool       `json:"can_create_org_repo,omitempty"`
+	Units                   []UnitType `json:"units,omitempty"`
+	Members                 []string   `json:"members,omitempty"`
+}
+
+// NewUserDump returns an empty user dump whose secrets are encrypted with the passphrase
+func NewUserDump(passphrase string) (*UserDump, error) {
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	dump := &UserDump{
+		Version:     UserDumpVersion,
+		CreatedUnix: timeutil.TimeStampNow(),
+		Salt:        base64.StdEncoding.EncodeToString(salt),
+	}
+	dump.key = pbkdf2.Key([]byte(passphrase), salt, userDumpKeyIterations, 32, sha256.New)
+	check, err := dump.encrypt([]byte(userDumpCheck))
+	if err != nil {
+		return nil, err
+	}
+	dump.Check = check
+	return dump, nil
+}
+
+// ReadUserDump reads a user dump and decrypts its secrets with the passphrase
+func ReadUserDump(r io.Reader, passphrase string) (*UserDump, error) {
+	dump := &UserDump{}
+	if err := json.NewDecoder(r).Decode(dump); err != nil {
+		return nil, fmt.Errorf("invalid user dump: %v", err)
+	}
+	if dump.Version != UserDumpVersion {
+		return nil, fmt.Errorf("unsupported user dump version %d", dump.Version)
+	}
+	salt, err := base64.StdEncoding.DecodeString(dump.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user dump salt: %v", err)
+	}
+	dump.key = pbkdf2.Key([]byte(passphrase), salt, userDumpKeyIterations, 32, sha256.New)
+	if check, err := dump.decrypt(dump.Check); err != nil || string(check) != userDumpCheck {
+		return nil, errors.New("wrong passphrase of the user dump")
+	}
+
+	for _, u := range dump.Users {
+		if u.Secrets == "" {
+			continue
+		}
+		data, err := dump.decrypt(u.Secrets)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt the secrets of %s: %v", u.Name, err)
+		}
+		u.secrets = &UserDumpSecrets{}
+		if err := json.Unmarshal(data, u.secrets); err != nil {
+			return nil, fmt.Errorf("invalid secrets of %s: %v", u.Name, err)
+		}
+	}
+	return dump, nil
+}
+
+// Write writes the user dump
+func (dump *UserDump) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dump)
+}
+
+func (dump *UserDump) encrypt(data []byte) (string, error) {
+	block, err := aes.NewCipher(dump.key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, data, nil)), nil
+}
+
+func (dump *UserDump) decrypt(encrypted string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(dump.key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
+
+// DumpUsers adds the users, organizations and teams of the instance to the dump
+func DumpUsers(ctx context.Context, dump *UserDump) error {
+	sources := make(map[int64]string)
+	loginSources := make([]*LoginSource, 0, 5)
+	if err := x.Find(&loginSources); err != nil {
+		return err
+	}
+	for _, source := range loginSources {
+		sources[source.ID] = source.Name
+	}
+
+	names := make(map[int64]string)
+	if err := x.OrderBy("id").Iterate(new(User), func(idx int, bean interface{}) error {
+		select {
+		case <-ctx.Done():
+			return ErrCancelledf("before dumping user %d", idx)
+		default:
+		}
+		u := bean.(*User)
+		names[u.ID] = u.Name
+		du, err := dumpUser(dump, u, sources)
+		if err != nil {
+			return fmt.Errorf("dump %s: %v", u.Name, err)
+		}
+		dump.Users = append(dump.Users, du)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// the members of the organizations are only known once all users are
+	for _, du := range dump.Users {
+		if !du.IsOrganization {
+			continue
+		}
+		org, err := getUserByName(x, du.Name)
+		if err != nil {
+			return err
+		}
+		orgUsers := make([]*OrgUser, 0, 10)
+		if err := x.Where("org_id = ?", org.ID).OrderBy("id").Find(&orgUsers); err != nil {
+			return err
+		}
+		for _, ou := range orgUsers {
+			if name, ok := names[ou.UID]; ok {
+				du.Members = append(du.Members, &UserDumpMember{Name: name, IsPublic: ou.IsPublic})
+			}
+		}
+	}
+
+	teams := make([]*Team, 0, 50)
+	if err := x.OrderBy("id").Find(&teams); err != nil {
+		return err
+	}
+	teamNames := make(map[int64]string, len(teams))
+	for _, team := range teams {
+		teamNames[team.ID] = team.Name
+	}
+	for _, team := range teams {
+		orgName, ok := names[team.OrgID]
+		if !ok {
+			continue
+		}
+		dt := &UserDumpTeam{
+			Org:                     orgName,
+			Name:                    team.Name,
+			Description:             team.Description,
+			Authorize:               team.Authorize,
+			Parent:                  teamNames[team.ParentID],
+			IncludesAllRepositories: team.IncludesAllRepositories,
+			CanCreateOrgRepo:        team.CanCreateOrgRepo,
+		}
+		units := make([]*TeamUnit, 0, 10)
+		if err := x.Where("team_id = ?", team.ID).OrderBy("type").Find(&units); err != nil {
+			return err
+		}
+		for _, unit := range units {
+			dt.Units = append(dt.Units, unit.Type)
+		}
+		teamUsers := make([]*TeamUser, 0, 10)
+		if err := x.Where("team_id = ?", team.ID).OrderBy("id").Find(&teamUsers); err != nil {
+			return err
+		}
+		for _, tu := range teamUsers {
+			if name, ok := names[tu.UID]; ok {
+				dt.Members = append(dt.Members, name)
+			}
+		}
+		dump.Teams = append(dump.Teams, dt)
+	}
+	return nil
+}
+
+func dumpUser(dump *UserDump, u *User, sources map[int64]string) (*UserDumpUser, error) {
+	du := &UserDumpUser{
+		Name:                         u.Name,
+		IsOrganization:               u.IsOrganization(),
+		FullName:                     u.FullName,
+		Email:                        u.Email,
+		KeepEmailPrivate:             u.KeepEmailPrivate,
+		EmailNotificationsPreference: u.EmailNotificationsPreference,
+		MustChangePassword:           u.MustChangePassword,
+		LoginSourceName:              sources[u.LoginSource],
+		LoginName:                    u.LoginName,
+		Location:                     u.Location,
+		Website:                      u.Website,
+		Language:                     u.Language,
+		Description:                  u.Description,
+		CreatedUnix:                  u.CreatedUnix,
+		MaxRepoCreation:              u.MaxRepoCreation,
+		IsActive:                     u.IsActive,
+		IsAdmin:                      u.IsAdmin,
+		IsRestricted:                 u.IsRestricted,
+		AllowGitHook:                 u.AllowGitHook,
+		AllowImportLocal:             u.AllowImportLocal,
+		AllowCreateOrganization:      u.AllowCreateOrganization,
+		ProhibitLogin:                u.ProhibitLogin,
+		Visibility:                   u.Visibility,
+		RepoAdminChangeTeamAccess:    u.RepoAdminChangeTeamAccess,
+		DiffViewStyle:                u.DiffViewStyle,
+		Theme:                        u.Theme,
+		KeepActivityPrivate:          u.KeepActivityPrivate,
+	}
+	if du.IsOrganization {
+		return du, nil
+	}
+
+	emails := make([]*EmailAddress, 0, 5)
+	if err := x.Where("uid = ?", u.ID).OrderBy("id").Find(&emails); err != nil {
+		return nil, err
+	}
+	for _, email := range emails {
+		if !strings.EqualFold(email.Email, u.Email) {
+			du.Emails = append(du.Emails, &UserDumpEmail{Email: email.Email, IsActivated: email.IsActivated})
+		}
+	}
+
+	keys := make([]*PublicKey, 0, 5)
+	if err := x.Where("owner_id = ? AND type = ?", u.ID, KeyTypeUser).OrderBy("id").Find(&keys); err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		du.PublicKeys = append(du.PublicKeys, &UserDumpKey{
+			Name:        key.Name,
+			Fingerprint: key.Fingerprint,
+			Content:     key.Content,
+			CreatedUnix: key.CreatedUnix,
+		})
+	}
+
+	secrets := &UserDumpSecrets{
+		Passwd:         u.Passwd,
+		PasswdHashAlgo: u.PasswdHashAlgo,
+		Salt:           u.Salt,
+		Rands:          u.Rands,
+	}
+	tokens := make([]*AccessToken, 0, 5)
+	if err := x.Where("uid = ?", u.ID).OrderBy("id").Find(&tokens); err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		secrets.AccessTokens = append(secrets.AccessTokens, &UserDumpAccessToken{
+			Name:           token.Name,
+			TokenHash:      token.TokenHash,
+			TokenSalt:      token.TokenSalt,
+			TokenLastEight: token.TokenLastEight,
+			CreatedUnix:    token.CreatedUnix,
+		})
+	}
+
+	// the two-factor secret is encrypted with the secret key of the instance, which differs from
+	// the one of the instance the dump is restored on
+	tf := &TwoFactor{}
+	if has, err := x.Where("uid = ?", u.ID).Get(tf); err != nil {
+		return nil, err
+	} else if has {
+		encrypted, err := base64.StdEncoding.DecodeString(tf.Secret)
+		var secret []byte
+		if err == nil {
+			secret, err = aesDecrypt(tf.getEncryptionKey(), encrypted)
+		}
+		if err != nil {
+			// the secret key of the instance changed, the two-factor authentication is broken anyway
+			log.Warn("Unable to decrypt the two-factor secret of %s, it is not dumped: %v", u.Name, err)
+		} else {
+			secrets.TwoFactor = &UserDumpTwoFactor{
+				Secret:      string(secret),
+				ScratchSalt: tf.ScratchSalt,
+				ScratchHash: tf.ScratchHash,
+			}
+		}
+	}
+
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, err
+	}
+	if du.Secrets, err = dump.encrypt(data); err != nil {
+		return nil, err
+	}
+	return du, nil
+}
+
+// RestoreUsersOptions are the options of the restoration of a user dump
+type RestoreUsersOptions struct {
+	// LinkExisting restores the memberships of the users already existing with the same name,
+	// by default only the memberships of the restored users are
+	LinkExisting bool
+}
+
+// RestoreUsers restores the users, organizations and teams of a dump which do not exist yet and
+// returns a report of what was restored and skipped
+func RestoreUsers(ctx context.Context, dump *UserDump, opts RestoreUsersOptions) ([]string, error) {
+	var report []string
+
+	sources := make(map[string]*LoginSource)
+	loginSources := make([]*LoginSource, 0, 5)
+	if err := x.Find(&loginSources); err != nil {
+		return nil, err
+	}
+	for _, source := range loginSources {
+		sources[source.Name] = source
+	}
+
+	// the IDs of the users which can be linked to the restored organizations and teams
+	linkable := make(map[string]int64)
+	restoredUsers, restoredOrgs := 0, 0
+	for _, du := range dump.Users {
+		select {
+		case <-ctx.Done():
+			return report, ErrCancelledf("before restoring %s", du.Name)
+		default:
+		}
+
+		existing, err := getUserByName(x, du.Name)
+		if err == nil {
+			report = append(report, fmt.Sprintf("%s already exists, skipped", du.Name))
+			if opts.LinkExisting && existing.IsOrganization() == du.IsOrganization {
+				linkable[du.Name] = existing.ID
+			}
+			continue
+		} else if !IsErrUserNotExist(err) {
+			return report, err
+		}
+
+		u, messages, err := restoreUser(du, sources)
+		report = append(report, messages...)
+		if err != nil {
+			return report, fmt.Errorf("restore %s: %v", du.Name, err)
+		}
+		if u == nil {
+			continue
+		}
+		linkable[du.Name] = u.ID
+		if du.IsOrganization {
+			restoredOrgs++
+		} else {
+			restoredUsers++
+		}
+	}
+
+	restoredTeams, err := restoreMemberships(dump, linkable, &report)
+	if err != nil {
+		return report, err
+	}
+	report = append(report, fmt.Sprintf("%d users, %d organizations and %d teams restored", restoredUsers, restoredOrgs, restoredTeams))
+
+	return report, RewriteAllPublicKeys()
+}
+
+// restoreUser restores a user of a dump, it returns nil if the user cannot be restored
+func restoreUser(du *UserDumpUser, sources map[string]*LoginSource) (*User, []string, error) {
+	var messages []string
+	if err := IsUsableUsername(du.Name); err != nil {
+		return nil, []string{fmt.Sprintf("%s is not a usable name on this instance, skipped", du.Name)}, nil
+	}
+
+	u := &User{
+		LowerName:                    strings.ToLower(du.Name),
+		Name:                         du.Name,
+		FullName:                     du.FullName,
+		Email:                        strings.ToLower(du.Email),
+		KeepEmailPrivate:             du.KeepEmailPrivate,
+		EmailNotificationsPreference: du.EmailNotificationsPreference,
+		MustChangePassword:           du.MustChangePassword,
+		LoginType:                    LoginPlain,
+		LoginName:                    du.LoginName,
+		Location:                     du.Location,
+		Website:                      du.Website,
+		Language:                     du.Language,
+		Description:                  du.Description,
+		CreatedUnix:                  du.CreatedUnix,
+		MaxRepoCreation:              du.MaxRepoCreation,
+		IsActive:                     du.IsActive,
+		IsAdmin:                      du.IsAdmin,
+		IsRestricted:                 du.IsRestricted,
+		AllowGitHook:                 du.AllowGitHook,
+		AllowImportLocal:             du.AllowImportLocal,
+		AllowCreateOrganization:      du.AllowCreateOrganization,
+		ProhibitLogin:                du.ProhibitLogin,
+		Visibility:                   du.Visibility,
+		RepoAdminChangeTeamAccess:    du.RepoAdminChangeTeamAccess,
+		DiffViewStyle:                du.DiffViewStyle,
+		Theme:                        du.Theme,
+		KeepActivityPrivate:          du.KeepActivityPrivate,
+		AvatarEmail:                  strings.ToLower(du.Email),
+		Avatar:                       base.HashEmail(du.Email),
+	}
+	if u.EmailNotificationsPreference == "" {
+		u.EmailNotificationsPreference = EmailNotificationsEnabled
+	}
+	if du.IsOrganization {
+		u.Type = UserTypeOrganization
+	}
+	if du.LoginSourceName != "" {
+		if source, ok := sources[du.LoginSourceName]; ok {
+			u.LoginType = source.Type
+			u.LoginSource = source.ID
+		} else {
+			messages = append(messages, fmt.Sprintf("%s uses the missing authentication source %q, restored as a local user", du.Name, du.LoginSourceName))
+			u.LoginName = ""
+		}
+	}
+	if du.secrets != nil {
+		u.Passwd = du.secrets.Passwd
+		u.PasswdHashAlgo = du.secrets.PasswdHashAlgo
+		u.Salt = du.secrets.Salt
+		u.Rands = du.secrets.Rands
+	}
+	var err error
+	if u.Rands == "" {
+		if u.Rands, err = GetUserSalt(); err != nil {
+			return nil, messages, err
+		}
+	}
+	if u.Salt == "" {
+		if u.Salt, err = GetUserSalt(); err != nil {
+			return nil, messages, err
+		}
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, messages, err
+	}
+
+	emails := []string{u.Email}
+	if !du.IsOrganization {
+		for _, email := range du.Emails {
+			emails = append(emails, strings.ToLower(email.Email))
+		}
+	}
+	for _, email := range emails {
+		used, err := isEmailUsed(sess, email)
+		if err != nil {
+			return nil, messages, err
+		}
+		if used {
+			return nil, append(messages, fmt.Sprintf("the email address %s of %s is already used, skipped", email, du.Name)), nil
+		}
+	}
+
+	if _, err := sess.NoAutoTime().Insert(u); err != nil {
+		return nil, messages, err
+	}
+	for _, email := range du.Emails {
+		if _, err := sess.Insert(&EmailAddress{
+			UID:         u.ID,
+			Email:       strings.ToLower(email.Email),
+			IsActivated: email.IsActivated,
+		}); err != nil {
+			return nil, messages, err
+		}
+	}
+
+	for _, key := range du.PublicKeys {
+		has, err := sess.Where("fingerprint = ?", key.Fingerprint).Exist(new(PublicKey))
+		if err != nil {
+			return nil, messages, err
+		}
+		if has {
+			messages = append(messages, fmt.Sprintf("the SSH key %q of %s is already used, skipped", key.Name, du.Name))
+			continue
+		}
+		if _, err := sess.Insert(&PublicKey{
+			OwnerID:     u.ID,
+			Name:        key.Name,
+			Fingerprint: key.Fingerprint,
+			Content:     key.Content,
+			Mode:        AccessModeWrite,
+			Type:        KeyTypeUser,
+			CreatedUnix: key.CreatedUnix,
+		}); err != nil {
+			return nil, messages, err
+		}
+	}
+
+	if du.secrets != nil {
+		for _, token := range du.secrets.AccessTokens {
+			if _, err := sess.Insert(&AccessToken{
+				UID:            u.ID,
+				Name:           token.Name,
+				TokenHash:      token.TokenHash,
+				TokenSalt:      token.TokenSalt,
+				TokenLastEight: token.TokenLastEight,
+				CreatedUnix:    token.CreatedUnix,
+			}); err != nil {
+				return nil, messages, err
+			}
+		}
+		if du.secrets.TwoFactor != nil {
+			tf := &TwoFactor{
+				UID:         u.ID,
+				ScratchSalt: du.secrets.TwoFactor.ScratchSalt,
+				ScratchHash: du.secrets.TwoFactor.ScratchHash,
+			}
+			if err := tf.SetSecret(du.secrets.TwoFactor.Secret); err != nil {
+				return nil, messages, err
+			}
+			if _, err := sess.Insert(tf); err != nil {
+				return nil, messages, err
+			}
+		}
+	}
+
+	return u, messages, sess.Commit()
+}
+
+// restoreMemberships restores the members of the restored organizations and their teams, it
+// returns the number of restored teams
+func restoreMemberships(dump *UserDump, linkable map[string]int64, report *[]string) (int, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return 0, err
+	}
+
+	for _, du := range dump.Users {
+		orgID, ok := linkable[du.Name]
+		if !du.IsOrganization || !ok {
+			continue
+		}
+		for _, member := range du.Members {
+			uid, ok := linkable[member.Name]
+			if !ok {
+				continue
+			}
+			if has, err := sess.Where("uid = ? AND org_id = ?", uid, orgID).Exist(new(OrgUser)); err != nil {
+				return 0, err
+			} else if has {
+				continue
+			}
+			if _, err := sess.Insert(&OrgUser{UID: uid, OrgID: orgID, IsPublic: member.IsPublic}); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	type teamMember struct {
+		team *Team
+		uid  int64
+	}
+	var existingMembers []teamMember
+	restored := 0
+	teamIDs := make(map[string]int64)
+	for _, dt := range dump.Teams {
+		orgID, ok := linkable[dt.Org]
+		if !ok {
+			continue
+		}
+		existing := &Team{}
+		if has, err := sess.Where("org_id = ? AND lower_name = ?", orgID, strings.ToLower(dt.Name)).Get(existing); err != nil {
+			return 0, err
+		} else if has {
+			// only the members of the existing teams are restored, their repositories may differ
+			teamIDs[dt.Org+"/"+dt.Name] = existing.ID
+			for _, name := range dt.Members {
+				if uid, ok := linkable[name]; ok {
+					existingMembers = append(existingMembers, teamMember{team: existing, uid: uid})
+				}
+			}
+			continue
+		}
+
+		team := &Team{
+			OrgID:                   orgID,
+			LowerName:               strings.ToLower(dt.Name),
+			Name:                    dt.Name,
+			Description:             dt.Description,
+			Authorize:               dt.Authorize,
+			IncludesAllRepositories: dt.IncludesAllRepositories,
+			CanCreateOrgRepo:        dt.CanCreateOrgRepo,
+			ParentID:                teamIDs[dt.Org+"/"+dt.Parent],
+		}
+		var members []int64
+		for _, name := range dt.Members {
+			if uid, ok := linkable[name]; ok {
+				members = append(members, uid)
+			}
+		}
+		team.NumMembers = len(members)
+		if _, err := sess.Insert(team); err != nil {
+			return 0, err
+		}
+		teamIDs[dt.Org+"/"+dt.Name] = team.ID
+
+		for _, unit := range dt.Units {
+			if _, err := sess.Insert(&TeamUnit{OrgID: orgID, TeamID: team.ID, Type: unit}); err != nil {
+				return 0, err
+			}
+		}
+		for _, uid := range members {
+			if _, err := sess.Insert(&TeamUser{OrgID: orgID, TeamID: team.ID, UID: uid}); err != nil {
+				return 0, err
+			}
+		}
+		if dt.IncludesAllRepositories {
+			// the repositories of the organization on this instance are added to the team
+			if err := team.addAllRepositories(sess); err != nil {
+				return 0, err
+			}
+		}
+		restored++
+	}
+
+	// the counters of the organizations with restored members or teams
+	for _, du := range dump.Users {
+		orgID, ok := linkable[du.Name]
+		if !du.IsOrganization || !ok {
+			continue
+		}
+		if _, err := sess.Exec("UPDATE `user` SET num_members = (SELECT COUNT(*) FROM org_user WHERE org_id = ?), num_teams = (SELECT COUNT(*) FROM team WHERE org_id = ?) WHERE id = ?",
+			orgID, orgID, orgID); err != nil {
+			return 0, err
+		}
+	}
+	if err := sess.Commit(); err != nil {
+		return 0, err
+	}
+
+	// the accesses of the members of the existing teams to their repositories are updated
+	for _, member := range existingMembers {
+		if err := AddTeamMember(member.team, member.uid); err != nil {
+			return restored, err
+		}
+	}
+	return restored, nil
+}
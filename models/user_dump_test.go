@@ -0,0 +1,86 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// deleteUserRows deletes the rows of a user without the checks of DeleteUser
+func deleteUserRows(t *testing.T, uid int64) {
+	_, err := x.ID(uid).Delete(new(User))
+	assert.NoError(t, err)
+	for _, bean := range []interface{}{new(EmailAddress), new(AccessToken), new(TwoFactor), new(OrgUser), new(TeamUser)} {
+		_, err := x.Where("uid = ?", uid).Delete(bean)
+		assert.NoError(t, err)
+	}
+	_, err = x.Where("owner_id = ?", uid).Delete(new(PublicKey))
+	assert.NoError(t, err)
+}
+
+func twoFactorSecret(t *testing.T, tf *TwoFactor) string {
+	encrypted, err := base64.StdEncoding.DecodeString(tf.Secret)
+	assert.NoError(t, err)
+	secret, err := aesDecrypt(tf.getEncryptionKey(), encrypted)
+	assert.NoError(t, err)
+	return string(secret)
+}
+
+func TestDumpAndRestoreUsers(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	tf := AssertExistsAndLoadBean(t, &TwoFactor{UID: 24}).(*TwoFactor)
+	assert.NoError(t, tf.SetSecret("twofactorsecret"))
+	_, err := x.ID(tf.ID).Cols("secret").Update(tf)
+	assert.NoError(t, err)
+
+	dump, err := NewUserDump("passphrase")
+	assert.NoError(t, err)
+	assert.NoError(t, DumpUsers(context.Background(), dump))
+	assert.Len(t, dump.Users, int(CountUsers()+CountOrganizations()))
+
+	var buf bytes.Buffer
+	assert.NoError(t, dump.Write(&buf))
+	assert.NotContains(t, buf.String(), AssertExistsAndLoadBean(t, &User{ID: 2}).(*User).Passwd)
+
+	_, err = ReadUserDump(bytes.NewReader(buf.Bytes()), "wrong")
+	assert.Error(t, err)
+	dump, err = ReadUserDump(bytes.NewReader(buf.Bytes()), "passphrase")
+	assert.NoError(t, err)
+
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	deleteUserRows(t, 2)
+	deleteUserRows(t, 24)
+
+	report, err := RestoreUsers(context.Background(), dump, RestoreUsersOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, report, "user1 already exists, skipped")
+	assert.Contains(t, report, "2 users, 0 organizations and 0 teams restored")
+
+	restored := AssertExistsAndLoadBean(t, &User{Name: "user2"}).(*User)
+	assert.Equal(t, user2.Passwd, restored.Passwd)
+	assert.Equal(t, user2.Email, restored.Email)
+	assert.Equal(t, user2.Salt, restored.Salt)
+	AssertExistsAndLoadBean(t, &PublicKey{OwnerID: restored.ID, Name: "user2@localhost"})
+	AssertExistsAndLoadBean(t, &AccessToken{UID: restored.ID})
+	AssertExistsAndLoadBean(t, &EmailAddress{UID: restored.ID, Email: "user21@example.com"})
+	// the organizations already existed so the memberships are not restored
+	AssertNotExistsBean(t, &OrgUser{UID: restored.ID})
+
+	restored24 := AssertExistsAndLoadBean(t, &User{Name: "user24"}).(*User)
+	tf = AssertExistsAndLoadBean(t, &TwoFactor{UID: restored24.ID}).(*TwoFactor)
+	assert.Equal(t, "twofactorsecret", twoFactorSecret(t, tf))
+
+	// restoring again links the users to the existing organizations
+	report, err = RestoreUsers(context.Background(), dump, RestoreUsersOptions{LinkExisting: true})
+	assert.NoError(t, err)
+	assert.Contains(t, report, "0 users, 0 organizations and 0 teams restored")
+	AssertExistsAndLoadBean(t, &OrgUser{UID: restored.ID, OrgID: 3})
+}
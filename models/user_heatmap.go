@@ -7,8 +7,13 @@ package models
 import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
 )
 
+// heatmapPeriod is the period covered by default by the heatmaps, in seconds
+const heatmapPeriod = 31536000
+
 // UserHeatmapData represents the data needed to create a heatmap
 type UserHeatmapData struct {
 	Timestamp     timeutil.TimeStamp `json:"timestamp"`
@@ -23,8 +28,30 @@ func GetUserHeatmapDataByUser(user *User) ([]*UserHeatmapData, error) {
 		return hdata, nil
 	}
 
-	var groupBy string
-	var groupByName = "timestamp" // We need this extra case because mssql doesn't allow grouping by alias
+	groupBy, groupByName := heatmapGroupBy()
+	sess := x.Select(groupBy+" AS timestamp, count(user_id) as contributions").
+		Table("action").
+		Where("user_id = ?", user.ID).
+		And("created_unix > ?", (timeutil.TimeStampNow() - heatmapPeriod))
+
+	// * Heatmaps for individual users only include actions that the user themself
+	//   did.
+	// * For organizations actions by all users that were made in owned
+	//   repositories are counted.
+	if user.Type == UserTypeIndividual {
+		sess = sess.And("act_user_id = ?", user.ID)
+	}
+
+	err := sess.GroupBy(groupByName).
+		OrderBy("timestamp").
+		Find(&hdata)
+
+	return hdata, err
+}
+
+// heatmapGroupBy returns the expression grouping the actions by day and the name to group by
+func heatmapGroupBy() (groupBy, groupByName string) {
+	groupByName = "timestamp" // We need this extra case because mssql doesn't allow grouping by alias
 	switch {
 	case setting.Database.UseSQLite3:
 		groupBy = "strftime('%s', strftime('%Y-%m-%d', created_unix, 'unixepoch'))"
@@ -36,23 +63,101 @@ func GetUserHeatmapDataByUser(user *User) ([]*UserHeatmapData, error) {
 		groupBy = "datediff(SECOND, '19700101', dateadd(DAY, 0, datediff(day, 0, dateadd(s, created_unix, '19700101'))))"
 		groupByName = groupBy
 	}
+	return groupBy, groupByName
+}
 
-	sess := x.Select(groupBy+" AS timestamp, count(user_id) as contributions").
-		Table("action").
-		Where("user_id = ?", user.ID).
-		And("created_unix > ?", (timeutil.TimeStampNow() - 31536000))
+// ActivityHeatmapOptions represents the scope and the date range of the activity of a repository
+// or of the repositories of an organization
+type ActivityHeatmapOptions struct {
+	RepoID int64
+	OrgID  int64
+	// Actor is the user viewing the activity of an organization, only the repositories visible
+	// to them are counted
+	Actor *User
+	// Since and Before bound the date range, which defaults to the last year
+	Since  timeutil.TimeStamp
+	Before timeutil.TimeStamp
+}
 
-	// * Heatmaps for individual users only include actions that the user themself
-	//   did.
-	// * For organizations actions by all users that were made in owned
-	//   repositories are counted.
-	if user.Type == UserTypeIndividual {
-		sess = sess.And("act_user_id = ?", user.ID)
+func (opts *ActivityHeatmapOptions) toConds() builder.Cond {
+	// every action is copied to the feeds of the watchers, only the copy of the actor is counted,
+	// the actions of bots are not contributions
+	cond := builder.Expr("user_id = act_user_id").
+		And(builder.NotIn("act_user_id", builder.Select("id").From("`user`").Where(builder.Eq{"keep_activity_private": true}.Or(builder.Eq{"type": UserTypeBot}))))
+
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.OrgID > 0 {
+		var repoCond builder.Cond = builder.Eq{"`repository`.owner_id": opts.OrgID}
+		if opts.Actor == nil || !opts.Actor.IsAdmin {
+			repoCond = repoCond.And(accessibleRepositoryCondition(opts.Actor))
+		}
+		cond = cond.And(builder.In("repo_id", builder.Select("id").From("repository").Where(repoCond)))
 	}
 
-	err := sess.GroupBy(groupByName).
+	since := opts.Since
+	if since == 0 {
+		since = timeutil.TimeStampNow() - heatmapPeriod
+	}
+	cond = cond.And(builder.Gte{"created_unix": since})
+	if opts.Before > 0 {
+		cond = cond.And(builder.Lt{"created_unix": opts.Before})
+	}
+	return cond
+}
+
+// GetActivityHeatmapData returns the daily contributions to a repository or to the repositories
+// of an organization. Users keeping their activity private and bots are left out.
+func GetActivityHeatmapData(opts *ActivityHeatmapOptions) ([]*UserHeatmapData, error) {
+	hdata := make([]*UserHeatmapData, 0)
+	groupBy, groupByName := heatmapGroupBy()
+
+	err := x.Select(groupBy + " AS timestamp, count(act_user_id) as contributions").
+		Table("action").
+		Where(opts.toConds()).
+		GroupBy(groupByName).
 		OrderBy("timestamp").
 		Find(&hdata)
 
 	return hdata, err
 }
+
+// ActivityContributor represents the contributions of a user to a repository or to the
+// repositories of an organization
+type ActivityContributor struct {
+	UserID        int64 `xorm:"act_user_id"`
+	User          *User `xorm:"-"`
+	Contributions int64
+}
+
+// GetActivityTopContributors returns the users with the most contributions to a repository or to
+// the repositories of an organization, most active first. Users keeping their activity private
+// and bots are left out.
+func GetActivityTopContributors(opts *ActivityHeatmapOptions, limit int) ([]*ActivityContributor, error) {
+	contributors := make([]*ActivityContributor, 0, limit)
+	if err := x.Select("act_user_id, count(act_user_id) AS contributions").
+		Table("action").
+		Where(opts.toConds()).
+		GroupBy("act_user_id").
+		OrderBy("contributions DESC, act_user_id").
+		Limit(limit).
+		Find(&contributors); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int64, 0, len(contributors))
+	for _, contributor := range contributors {
+		userIDs = append(userIDs, contributor.UserID)
+	}
+	users := make(map[int64]*User, len(userIDs))
+	if err := x.In("id", userIDs).Find(&users); err != nil {
+		return nil, err
+	}
+	for _, contributor := range contributors {
+		if contributor.User = users[contributor.UserID]; contributor.User == nil {
+			contributor.User = NewGhostUser()
+		}
+	}
+	return contributors, nil
+}
@@ -8,6 +8,8 @@ import (
 	"encoding/json"
 	"testing"
 
+	"code.gitea.io/gitea/modules/timeutil"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -50,3 +52,59 @@ func TestGetUserHeatmapDataByUser(t *testing.T) {
 		assert.Equal(t, tc.JSONResult, string(jsonData))
 	}
 }
+
+func TestGetActivityHeatmapData(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	day := timeutil.TimeStamp(1571616000)
+	actions := []*Action{
+		{UserID: 2, ActUserID: 2, RepoID: 1, OpType: ActionCreateIssue, CreatedUnix: day + 10},
+		// the copy in the feed of a watcher is not counted
+		{UserID: 4, ActUserID: 2, RepoID: 1, OpType: ActionCreateIssue, CreatedUnix: day + 10},
+		{UserID: 2, ActUserID: 2, RepoID: 1, OpType: ActionCommentIssue, CreatedUnix: day + 20},
+		{UserID: 4, ActUserID: 4, RepoID: 1, OpType: ActionCommentIssue, CreatedUnix: day + 86400},
+		{UserID: 2, ActUserID: 2, RepoID: 3, OpType: ActionCreateIssue, CreatedUnix: day + 30},
+	}
+	for _, action := range actions {
+		_, err := x.NoAutoTime().Insert(action)
+		assert.NoError(t, err)
+	}
+
+	heatmap, err := GetActivityHeatmapData(&ActivityHeatmapOptions{RepoID: 1, Since: day})
+	assert.NoError(t, err)
+	jsonData, err := json.Marshal(heatmap)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"timestamp":1571616000,"contributions":2},{"timestamp":1571702400,"contributions":1}]`, string(jsonData))
+
+	heatmap, err = GetActivityHeatmapData(&ActivityHeatmapOptions{RepoID: 1, Since: day, Before: day + 86400})
+	assert.NoError(t, err)
+	assert.Len(t, heatmap, 1)
+
+	// the last year only by default
+	heatmap, err = GetActivityHeatmapData(&ActivityHeatmapOptions{RepoID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, heatmap, 0)
+
+	// the private repository of the organization is only counted for its members
+	heatmap, err = GetActivityHeatmapData(&ActivityHeatmapOptions{OrgID: 3, Since: day})
+	assert.NoError(t, err)
+	assert.Len(t, heatmap, 0)
+	heatmap, err = GetActivityHeatmapData(&ActivityHeatmapOptions{OrgID: 3, Actor: AssertExistsAndLoadBean(t, &User{ID: 2}).(*User), Since: day})
+	assert.NoError(t, err)
+	if assert.Len(t, heatmap, 1) {
+		assert.EqualValues(t, 1, heatmap[0].Contributions)
+	}
+
+	contributors, err := GetActivityTopContributors(&ActivityHeatmapOptions{RepoID: 1, Since: day}, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, contributors, 2) {
+		assert.EqualValues(t, "user2", contributors[0].User.Name)
+		assert.EqualValues(t, 2, contributors[0].Contributions)
+		assert.EqualValues(t, "user4", contributors[1].User.Name)
+		assert.EqualValues(t, 1, contributors[1].Contributions)
+	}
+
+	contributors, err = GetActivityTopContributors(&ActivityHeatmapOptions{RepoID: 1, Since: day}, 1)
+	assert.NoError(t, err)
+	assert.Len(t, contributors, 1)
+}
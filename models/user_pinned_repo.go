@@ -0,0 +1,195 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+)
+
+// MaxPinnedRepos is the maximum number of repositories pinned on a profile
+const MaxPinnedRepos = 6
+
+// PinnedRepo represents a repository pinned on the profile of its owner
+type PinnedRepo struct {
+	ID       int64 `xorm:"pk autoincr"`
+	UID      int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	RepoID   int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Position int   `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// ErrTooManyPinnedRepos represents a "TooManyPinnedRepos" kind of error.
+type ErrTooManyPinnedRepos struct {
+	Count int
+}
+
+// IsErrTooManyPinnedRepos checks if an error is a ErrTooManyPinnedRepos.
+func IsErrTooManyPinnedRepos(err error) bool {
+	_, ok := err.(ErrTooManyPinnedRepos)
+	return ok
+}
+
+func (err ErrTooManyPinnedRepos) Error() string {
+	return fmt.Sprintf("too many pinned repositories [count: %d, max: %d]", err.Count, MaxPinnedRepos)
+}
+
+// ErrPinnedRepoNotOwned represents a "PinnedRepoNotOwned" kind of error.
+type ErrPinnedRepoNotOwned struct {
+	UID    int64
+	RepoID int64
+}
+
+// IsErrPinnedRepoNotOwned checks if an error is a ErrPinnedRepoNotOwned.
+func IsErrPinnedRepoNotOwned(err error) bool {
+	_, ok := err.(ErrPinnedRepoNotOwned)
+	return ok
+}
+
+func (err ErrPinnedRepoNotOwned) Error() string {
+	return fmt.Sprintf("pinned repository is not owned by the user [uid: %d, repo_id: %d]", err.UID, err.RepoID)
+}
+
+// GetPinnedRepoIDs returns the IDs of the repositories pinned by the user, in
+// the order they are shown
+func GetPinnedRepoIDs(uid int64) ([]int64, error) {
+	return getPinnedRepoIDs(x, uid)
+}
+
+func getPinnedRepoIDs(e Engine, uid int64) ([]int64, error) {
+	repoIDs := make([]int64, 0, MaxPinnedRepos)
+	return repoIDs, e.Table("pinned_repo").
+		Where("uid = ?", uid).
+		Asc("position").
+		Cols("repo_id").
+		Find(&repoIDs)
+}
+
+// GetPinnedRepos returns the repositories pinned by the user, in the order
+// they are shown. The repositories the doer cannot access are left out.
+func GetPinnedRepos(uid int64, doer *User) ([]*Repository, error) {
+	repoIDs, err := GetPinnedRepoIDs(uid)
+	if err != nil || len(repoIDs) == 0 {
+		return nil, err
+	}
+
+	repoMap := make(map[int64]*Repository, len(repoIDs))
+	if err = x.In("id", repoIDs).Find(&repoMap); err != nil {
+		return nil, err
+	}
+
+	repos := make([]*Repository, 0, len(repoIDs))
+	for _, repoID := range repoIDs {
+		repo, ok := repoMap[repoID]
+		if !ok {
+			continue
+		}
+		if repo.IsPrivate {
+			var doerID int64
+			if doer != nil {
+				doerID = doer.ID
+			}
+			has, err := HasAccess(doerID, repo)
+			if err != nil {
+				return nil, err
+			}
+			if !has {
+				continue
+			}
+		}
+		repos = append(repos, repo)
+	}
+	return repos, RepositoryList(repos).LoadAttributes()
+}
+
+// SetPinnedRepos replaces the repositories pinned by the user. The
+// repositories are shown in the given order and have to be owned by the user.
+func SetPinnedRepos(u *User, repoIDs []int64) error {
+	uniqueIDs := make([]int64, 0, len(repoIDs))
+	seen := make(map[int64]bool, len(repoIDs))
+	for _, repoID := range repoIDs {
+		if !seen[repoID] {
+			seen[repoID] = true
+			uniqueIDs = append(uniqueIDs, repoID)
+		}
+	}
+	if len(uniqueIDs) > MaxPinnedRepos {
+		return ErrTooManyPinnedRepos{Count: len(uniqueIDs)}
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if len(uniqueIDs) > 0 {
+		var ownedIDs []int64
+		if err := sess.Table("repository").
+			Where("owner_id = ?", u.ID).
+			In("id", uniqueIDs).
+			Cols("id").
+			Find(&ownedIDs); err != nil {
+			return err
+		}
+		owned := make(map[int64]bool, len(ownedIDs))
+		for _, id := range ownedIDs {
+			owned[id] = true
+		}
+		for _, repoID := range uniqueIDs {
+			if !owned[repoID] {
+				return ErrPinnedRepoNotOwned{UID: u.ID, RepoID: repoID}
+			}
+		}
+	}
+
+	if _, err := sess.Delete(&PinnedRepo{UID: u.ID}); err != nil {
+		return err
+	}
+	for i, repoID := range uniqueIDs {
+		if _, err := sess.Insert(&PinnedRepo{UID: u.ID, RepoID: repoID, Position: i}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// PinRepo pins the repository after the ones already pinned by the user
+func PinRepo(u *User, repoID int64) error {
+	repoIDs, err := GetPinnedRepoIDs(u.ID)
+	if err != nil {
+		return err
+	}
+	return SetPinnedRepos(u, append(repoIDs, repoID))
+}
+
+// UnpinRepo removes the repository from the ones pinned by the user
+func UnpinRepo(u *User, repoID int64) error {
+	_, err := x.Delete(&PinnedRepo{UID: u.ID, RepoID: repoID})
+	return err
+}
+
+// MovePinnedRepo moves the pinned repository by offset positions, e.g. -1 to
+// show it one position earlier
+func MovePinnedRepo(u *User, repoID int64, offset int) error {
+	repoIDs, err := GetPinnedRepoIDs(u.ID)
+	if err != nil {
+		return err
+	}
+	for i, id := range repoIDs {
+		if id != repoID {
+			continue
+		}
+		j := i + offset
+		if j < 0 {
+			j = 0
+		} else if j >= len(repoIDs) {
+			j = len(repoIDs) - 1
+		}
+		copy(repoIDs[i:], repoIDs[i+1:])
+		copy(repoIDs[j+1:], repoIDs[j:len(repoIDs)-1])
+		repoIDs[j] = repoID
+		return SetPinnedRepos(u, repoIDs)
+	}
+	return nil
+}
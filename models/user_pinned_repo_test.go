@@ -0,0 +1,84 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPinnedRepos(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repoIDs, err := GetPinnedRepoIDs(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, repoIDs)
+
+	owner := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repos, err := GetPinnedRepos(2, owner)
+	assert.NoError(t, err)
+	if assert.Len(t, repos, 2) {
+		assert.EqualValues(t, 2, repos[0].ID)
+		assert.EqualValues(t, 1, repos[1].ID)
+	}
+
+	// the private repository is hidden from anonymous users
+	repos, err = GetPinnedRepos(2, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, repos, 1) {
+		assert.EqualValues(t, 1, repos[0].ID)
+	}
+
+	repos, err = GetPinnedRepos(1, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, repos)
+}
+
+func TestSetPinnedRepos(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	assert.NoError(t, SetPinnedRepos(user, []int64{1, 2, 1}))
+	repoIDs, err := GetPinnedRepoIDs(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, repoIDs)
+
+	// repo 3 is owned by user3
+	err = SetPinnedRepos(user, []int64{1, 3})
+	assert.True(t, IsErrPinnedRepoNotOwned(err))
+
+	err = SetPinnedRepos(user, []int64{1, 2, 4, 5, 6, 7, 8})
+	assert.True(t, IsErrTooManyPinnedRepos(err))
+
+	repoIDs, err = GetPinnedRepoIDs(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, repoIDs)
+
+	assert.NoError(t, SetPinnedRepos(user, nil))
+	AssertNotExistsBean(t, &PinnedRepo{UID: user.ID})
+}
+
+func TestMovePinnedRepo(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	assertPinned := func(expected ...int64) {
+		repoIDs, err := GetPinnedRepoIDs(user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, repoIDs)
+	}
+
+	assert.NoError(t, PinRepo(user, 16))
+	assertPinned(2, 1, 16)
+	assert.NoError(t, MovePinnedRepo(user, 16, -1))
+	assertPinned(2, 16, 1)
+	assert.NoError(t, MovePinnedRepo(user, 2, 5))
+	assertPinned(16, 1, 2)
+	assert.NoError(t, MovePinnedRepo(user, 1, -1))
+	assertPinned(1, 16, 2)
+	assert.NoError(t, UnpinRepo(user, 16))
+	assertPinned(1, 2)
+}
@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/modules/log"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
 //UserList is a list of user.
@@ -95,6 +96,27 @@ func (users UserList) loadTwoFactorStatus(e Engine) (map[int64]*TwoFactor, error
 	return tokenMaps, nil
 }
 
+// GetOrgMembershipExpiration returns the time the membership of the users in
+// the organization ends, 0 if it does not expire
+func (users UserList) GetOrgMembershipExpiration(orgID int64) map[int64]timeutil.TimeStamp {
+	results := make(map[int64]timeutil.TimeStamp, len(users))
+	if len(users) == 0 {
+		return results
+	}
+	ous := make([]*OrgUser, 0, len(users))
+	if err := x.
+		In("uid", users.getUserIDs()).
+		And("org_id=?", orgID).
+		Find(&ous); err != nil {
+		log.Error("find org users: %v", err)
+		return results
+	}
+	for _, ou := range ous {
+		results[ou.UID] = ou.ExpiresUnix
+	}
+	return results
+}
+
 //APIFormat return list of users in api format
 func (users UserList) APIFormat() []*api.User {
 	result := make([]*api.User, 0, len(users))
@@ -8,6 +8,7 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/modules/log"
@@ -140,6 +141,29 @@ func (w *Webhook) UpdateEvent() error {
 	return err
 }
 
+// ReferencesBranch returns true if the branch filter of the webhook names the branch, on its own
+// or as an alternative of a "{a,b}" list, rather than only matching it through a pattern.
+func (w *Webhook) ReferencesBranch(branch string) bool {
+	return w.HookEvent != nil && renameBranchInFilter(w.BranchFilter, branch, "") != w.BranchFilter
+}
+
+// renameBranchInFilter replaces the name of a branch in a branch filter
+func renameBranchInFilter(filter, from, to string) string {
+	if filter == from {
+		return to
+	}
+	if !strings.HasPrefix(filter, "{") || !strings.HasSuffix(filter, "}") {
+		return filter
+	}
+	alternatives := strings.Split(filter[1:len(filter)-1], ",")
+	for i, alternative := range alternatives {
+		if alternative == from {
+			alternatives[i] = to
+		}
+	}
+	return "{" + strings.Join(alternatives, ",") + "}"
+}
+
 // HasCreateEvent returns true if hook enabled create event.
 func (w *Webhook) HasCreateEvent() bool {
 	return w.SendEverything ||
@@ -758,6 +782,28 @@ func HookTasks(hookID int64, page int) ([]*HookTask, error) {
 		Find(&tasks)
 }
 
+// GetHookTasksByHookID returns the hook tasks of a webhook, latest first.
+func GetHookTasksByHookID(hookID int64, listOptions ListOptions) ([]*HookTask, error) {
+	sess := x.Where("hook_id=?", hookID).Desc("id")
+	if listOptions.Page != 0 {
+		sess = listOptions.setSessionPagination(sess)
+	}
+	tasks := make([]*HookTask, 0, listOptions.PageSize)
+	return tasks, sess.Find(&tasks)
+}
+
+// GetHookTaskByHookID returns the hook task of given ID belonging to the webhook.
+func GetHookTaskByHookID(hookID, id int64) (*HookTask, error) {
+	t := &HookTask{ID: id, HookID: hookID}
+	has, err := x.Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrHookTaskNotExist{ID: id, HookID: hookID}
+	}
+	return t, nil
+}
+
 // CreateHookTask creates a new hook task,
 // it handles conversion from Payload to PayloadContent.
 func CreateHookTask(t *HookTask) error {
@@ -775,12 +821,47 @@ func createHookTask(e Engine, t *HookTask) error {
 	return err
 }
 
+// CreateRedeliveryHookTask creates a new undelivered hook task sending the
+// same request as the given one, so the delivery can be repeated.
+func CreateRedeliveryHookTask(t *HookTask) (*HookTask, error) {
+	nt := &HookTask{
+		RepoID:         t.RepoID,
+		HookID:         t.HookID,
+		UUID:           gouuid.New().String(),
+		Type:           t.Type,
+		URL:            t.URL,
+		Signature:      t.Signature,
+		PayloadContent: t.PayloadContent,
+		HTTPMethod:     t.HTTPMethod,
+		ContentType:    t.ContentType,
+		EventType:      t.EventType,
+		IsSSL:          t.IsSSL,
+	}
+	if _, err := x.Insert(nt); err != nil {
+		return nil, err
+	}
+	return nt, nil
+}
+
 // UpdateHookTask updates information of hook task.
 func UpdateHookTask(t *HookTask) error {
 	_, err := x.ID(t.ID).AllCols().Update(t)
 	return err
 }
 
+// ClaimHookTask marks the undelivered hook task as delivered, so that it is
+// sent only once even if it is picked up for delivery concurrently. It returns
+// false if the task has been claimed already.
+func ClaimHookTask(t *HookTask) (bool, error) {
+	affected, err := x.ID(t.ID).Where("is_delivered=?", false).
+		Cols("is_delivered").Update(&HookTask{IsDelivered: true})
+	if err != nil {
+		return false, err
+	}
+	t.IsDelivered = true
+	return affected == 1, nil
+}
+
 // FindUndeliveredHookTasks represents find the undelivered hook tasks
 func FindUndeliveredHookTasks() ([]*HookTask, error) {
 	tasks := make([]*HookTask, 0, 10)
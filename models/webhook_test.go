@@ -39,6 +39,21 @@ func TestWebhook_History(t *testing.T) {
 	assert.Len(t, tasks, 0)
 }
 
+func TestWebhook_ReferencesBranch(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	webhook := AssertExistsAndLoadBean(t, &Webhook{ID: 4}).(*Webhook)
+	assert.True(t, webhook.ReferencesBranch("master"))
+	assert.False(t, webhook.ReferencesBranch("feature"))
+	assert.False(t, webhook.ReferencesBranch("feature*x"))
+
+	webhook = AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	assert.False(t, webhook.ReferencesBranch("master"))
+
+	assert.Equal(t, "main", renameBranchInFilter("master", "master", "main"))
+	assert.Equal(t, "{main,feature*}", renameBranchInFilter("{master,feature*}", "master", "main"))
+	assert.Equal(t, "master*", renameBranchInFilter("master*", "master", "main"))
+}
+
 func TestWebhook_UpdateEvent(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	webhook := AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
@@ -220,6 +235,57 @@ func TestHookTasks(t *testing.T) {
 	assert.Len(t, hookTasks, 0)
 }
 
+func TestGetHookTaskByHookID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	hookTask, err := GetHookTaskByHookID(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "uuid1", hookTask.UUID)
+
+	_, err = GetHookTaskByHookID(2, 1)
+	assert.True(t, IsErrHookTaskNotExist(err))
+}
+
+func TestCreateRedeliveryHookTask(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	hookTask := AssertExistsAndLoadBean(t, &HookTask{ID: 1}).(*HookTask)
+	hookTask.PayloadContent = `{"ref":"refs/heads/master"}`
+
+	redelivery, err := CreateRedeliveryHookTask(hookTask)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hookTask.ID, redelivery.ID)
+	assert.NotEqual(t, hookTask.UUID, redelivery.UUID)
+	assert.False(t, redelivery.IsDelivered)
+	AssertExistsAndLoadBean(t, &HookTask{ID: redelivery.ID, HookID: 1, PayloadContent: hookTask.PayloadContent})
+
+	hookTasks, err := GetHookTasksByHookID(1, ListOptions{Page: 1, PageSize: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, hookTasks, 1) {
+		assert.Equal(t, redelivery.ID, hookTasks[0].ID)
+	}
+}
+
+func TestClaimHookTask(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	hookTask := AssertExistsAndLoadBean(t, &HookTask{ID: 1}).(*HookTask)
+
+	redelivery, err := CreateRedeliveryHookTask(hookTask)
+	assert.NoError(t, err)
+
+	claimed, err := ClaimHookTask(redelivery)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.True(t, redelivery.IsDelivered)
+	AssertExistsAndLoadBean(t, &HookTask{ID: redelivery.ID, IsDelivered: true})
+
+	// a task is claimed once
+	claimed, err = ClaimHookTask(redelivery)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	claimed, err = ClaimHookTask(hookTask)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+}
+
 func TestCreateHookTask(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	hookTask := &HookTask{
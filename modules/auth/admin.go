@@ -5,6 +5,8 @@
 package auth
 
 import (
+	"mime/multipart"
+
 	"gitea.com/macaron/binding"
 	"gitea.com/macaron/macaron"
 )
@@ -59,3 +61,52 @@ type AdminDashboardForm struct {
 func (f *AdminDashboardForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// AdminCreateCustomEmojiForm form for admin to upload a custom emoji
+type AdminCreateCustomEmojiForm struct {
+	Name  string `binding:"Required;MaxSize(50)"`
+	Image *multipart.FileHeader
+}
+
+// Validate validates form fields
+func (f *AdminCreateCustomEmojiForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// AdminUploadThemeForm form for admin to upload a theme bundle
+type AdminUploadThemeForm struct {
+	Bundle *multipart.FileHeader
+}
+
+// Validate validates form fields
+func (f *AdminUploadThemeForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// AdminBadgeForm form for admin to create or edit a badge
+type AdminBadgeForm struct {
+	Slug        string `binding:"Required;AlphaDashDot;MaxSize(50)"`
+	Name        string `binding:"Required;MaxSize(100)"`
+	Description string `binding:"MaxSize(255)"`
+	Icon        string `binding:"MaxSize(255)"`
+	Type        string
+	Threshold   int
+}
+
+// Validate validates form fields
+func (f *AdminBadgeForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// AdminMailTemplateForm form for admin to customize a mail template
+type AdminMailTemplateForm struct {
+	Name        string `binding:"Required;MaxSize(255)"`
+	Content     string `binding:"Required"`
+	TextContent string
+	BaseSHA256  string `form:"base_sha256" binding:"MaxSize(64)"`
+}
+
+// Validate validates form fields
+func (f *AdminMailTemplateForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -64,9 +64,132 @@ type CreateTeamForm struct {
 	Units            []models.UnitType
 	RepoAccess       string
 	CanCreateOrgRepo bool
+	ParentID         int64
+	RoleID           int64
+
+	ReviewAssignAlgorithm string
+	ReviewAssignCount     int `binding:"Range(0,100)"`
 }
 
 // Validate validates the fields
 func (f *CreateTeamForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// __________                   __               __
+// \______   \_______  ____    |__| ____   _____/  |_
+//  |     ___/\_  __ \/  _ \   |  |/ __ \_/ ___\   __\
+//  |    |     |  | \(  <_> )  |  \  ___/\  \___|  |
+//  |____|     |__|   \____/\__|  |\___  >\___  >__|
+//                         \______|    \/     \/
+
+// CreateProjectForm form for creating or editing an organization project
+type CreateProjectForm struct {
+	Title                string `binding:"Required;MaxSize(100)"`
+	Description          string
+	InProgressBoardID    int64
+	DoneBoardID          int64
+	ArchiveDoneAfterDays int `binding:"Range(0,3650)"`
+}
+
+// Validate validates the fields
+func (f *CreateProjectForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// CreateOrgRoleForm form for creating or editing a custom role, the
+// permissions are read from the unit fields
+type CreateOrgRoleForm struct {
+	Name        string `binding:"Required;MaxSize(50)"`
+	Description string `binding:"MaxSize(255)"`
+}
+
+// Validate validates the fields
+func (f *CreateOrgRoleForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// OrgRulesetForm form for creating or editing a ruleset of an organization
+type OrgRulesetForm struct {
+	Name                 string `binding:"Required;MaxSize(100)"`
+	Priority             int64
+	IsActive             bool
+	Target               string `binding:"In(branch,tag)"`
+	Mode                 string `binding:"In(supplement,override)"`
+	RepoPatterns         string
+	RefPatterns          string
+	RequiredApprovals    int64
+	RequireSignedCommits bool
+	EnableStatusCheck    bool
+	StatusCheckContexts  string
+	MergeStyles          []string
+	RestrictPush         bool
+	PushWhitelistUsers   string
+	PushWhitelistTeams   string
+	BypassUsers          string
+	BypassTeams          string
+}
+
+// Validate validates the fields
+func (f *OrgRulesetForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// OrgRepoDefaultsForm form for changing the default repository settings of an organization
+type OrgRepoDefaultsForm struct {
+	Units                  []int
+	ProtectedBranches      string
+	CanPush                bool
+	RequiredApprovals      int64
+	BlockOnRejectedReviews bool
+	DismissStaleApprovals  bool
+	RequireSignedCommits   bool
+	LabelTemplate          string
+	WebhookURLs            string `form:"webhook_urls"`
+	IssueTemplate          string
+}
+
+// Validate validates the fields
+func (f *OrgRepoDefaultsForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// AttachmentPolicyForm form for changing the attachment policy of an organization
+type AttachmentPolicyForm struct {
+	MaxSize              int64 `binding:"Range(0,1000000)" locale:"org.settings.attachments.max_size"`
+	MaxSizeByType        string
+	MaxIssueSize         int64 `binding:"Range(0,1000000)" locale:"org.settings.attachments.max_issue_size"`
+	ClosedIssueRetention int   `binding:"Range(0,36500)" locale:"org.settings.attachments.closed_issue_retention"`
+}
+
+// Validate validates the fields
+func (f *AttachmentPolicyForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// ProjectBoardForm form for adding a column to a project
+type ProjectBoardForm struct {
+	Title string `binding:"Required;MaxSize(100)"`
+}
+
+// Validate validates the fields
+func (f *ProjectBoardForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// ProjectIssueQueryForm form for adding the issues matching a query to a
+// project, or for adding a rule doing so automatically
+type ProjectIssueQueryForm struct {
+	BoardID int64
+	RepoID  int64
+	Label   string `binding:"MaxSize(50)"`
+	Keyword string `binding:"MaxSize(100)"`
+	State   string
+	// ApplyExisting adds the matching issues when a rule is created
+	ApplyExisting bool
+}
+
+// Validate validates the fields
+func (f *ProjectIssueQueryForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"gitea.com/macaron/binding"
+	"gitea.com/macaron/macaron"
+)
+
+// CreateDiscussionForm form for starting a discussion
+type CreateDiscussionForm struct {
+	Title      string `binding:"Required;MaxSize(255)"`
+	CategoryID int64  `binding:"Required" locale:"repo.discussions.category"`
+	Content    string
+}
+
+// Validate validates the fields
+func (f *CreateDiscussionForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// CreateDiscussionReplyForm form for replying to a discussion
+type CreateDiscussionReplyForm struct {
+	Content  string `binding:"Required"`
+	ParentID int64
+}
+
+// Validate validates the fields
+func (f *CreateDiscussionReplyForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// CreateDiscussionCategoryForm form for creating a discussion category
+type CreateDiscussionCategoryForm struct {
+	Name         string `binding:"Required;MaxSize(50)" locale:"repo.discussions.category.name"`
+	Description  string `binding:"MaxSize(255)" locale:"repo.discussions.category.description"`
+	IsAnswerable bool
+}
+
+// Validate validates the fields
+func (f *CreateDiscussionCategoryForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -134,9 +134,13 @@ type RepoSettingForm struct {
 	PullsAllowRebase                 bool
 	PullsAllowRebaseMerge            bool
 	PullsAllowSquash                 bool
+	PullsAllowFastForwardOnly        bool
+	PullsEnableDCOCheck              bool   `form:"pulls_enable_dco_check"`
+	PullsDCOAllowlist                string `form:"pulls_dco_allowlist"`
 	EnableTimetracker                bool
 	AllowOnlyContributorsToTrackTime bool
 	EnableIssueDependencies          bool
+	EnableDiscussions                bool
 	IsArchived                       bool
 
 	// Admin settings
@@ -149,6 +153,19 @@ func (f *RepoSettingForm) Validate(ctx *macaron.Context, errs binding.Errors) bi
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// RepoVisibilityForm form for confirming or scheduling the change of the visibility of a repository
+type RepoVisibilityForm struct {
+	Action      string `binding:"Required;In(now,schedule,cancel)"`
+	Private     bool
+	RepoName    string
+	ScheduledAt string
+}
+
+// Validate validates the fields
+func (f *RepoVisibilityForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // __________                             .__
 // \______   \____________    ____   ____ |  |__
 //  |    |  _/\_  __ \__  \  /    \_/ ___\|  |  \
@@ -176,7 +193,9 @@ type ProtectBranchForm struct {
 	BlockOnOutdatedBranch    bool
 	DismissStaleApprovals    bool
 	RequireSignedCommits     bool
+	SignedCommitsAllowedKeys string
 	ProtectedFilePatterns    string
+	MergeStyles              []string
 }
 
 // Validate validates the fields
@@ -454,6 +473,8 @@ type CreateLabelForm struct {
 	Title       string `binding:"Required;MaxSize(50)" locale:"repo.issues.label_title"`
 	Description string `binding:"MaxSize(200)" locale:"repo.issues.label_description"`
 	Color       string `binding:"Required;Size(7)" locale:"repo.issues.label_color"`
+	IsQuestion  bool
+	SyncToRepos bool
 }
 
 // Validate validates the fields
@@ -471,6 +492,128 @@ func (f *InitializeLabelsForm) Validate(ctx *macaron.Context, errs binding.Error
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// IssueAssignRuleForm form for creating an issue assignment rule
+type IssueAssignRuleForm struct {
+	Name         string `binding:"Required;MaxSize(255)" locale:"repo.settings.assign_rules.name"`
+	LabelID      int64
+	PathPatterns string
+	TeamID       int64 `binding:"Required" locale:"repo.settings.assign_rules.team"`
+}
+
+// Validate validates the fields
+func (f *IssueAssignRuleForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// RepoCustomTabForm form for adding or editing a custom tab of a repository
+type RepoCustomTabForm struct {
+	Name string `binding:"Required;MaxSize(50)" locale:"repo.settings.custom_tabs.name"`
+	URL  string `binding:"Required;ValidUrl" locale:"repo.settings.custom_tabs.url"`
+	Icon string
+	Sort int
+}
+
+// Validate validates the fields
+func (f *RepoCustomTabForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// IssueTrackerSyncForm form for changing the issue tracker sync of a repository
+type IssueTrackerSyncForm struct {
+	Type                  string `binding:"Required;In(jira,redmine)" locale:"repo.settings.issue_tracker_sync.type"`
+	URL                   string `binding:"Required;ValidUrl" locale:"repo.settings.issue_tracker_sync.url"`
+	ProjectKey            string `binding:"MaxSize(50)" locale:"repo.settings.issue_tracker_sync.project_key"`
+	Username              string
+	Token                 string
+	CommentOnCommits      bool
+	CommentOnPullRequests bool
+	RequireReference      bool
+	VerifyReference       bool
+}
+
+// Validate validates the fields
+func (f *IssueTrackerSyncForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// CommitMessagePolicyForm form for changing the commit message policy of a repository
+type CommitMessagePolicyForm struct {
+	MaxSubjectLength int `binding:"Range(0,1000)" locale:"repo.settings.commit_messages.max_subject_length"`
+	Patterns         string
+	RequiredTrailers string
+	BypassUsers      string
+	BypassTeams      string
+}
+
+// Validate validates the fields
+func (f *CommitMessagePolicyForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// RepoRawHeadersForm form for changing the headers of the raw files of a repository
+type RepoRawHeadersForm struct {
+	AllowedOrigins string
+	CacheMaxAge    int    `binding:"Range(-1,31536000)" locale:"repo.settings.raw_headers.cache_max_age"`
+	RobotsPolicy   string `binding:"In(,all,noindex,nofollow,none)"`
+}
+
+// Validate validates the fields
+func (f *RepoRawHeadersForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// LocaleSettingForm form for changing the default language and formats of an organization
+// or a repository
+type LocaleSettingForm struct {
+	Language     string `binding:"MaxSize(5)"`
+	DateFormat   string `binding:"MaxSize(100)"`
+	NumberFormat string `binding:"MaxSize(50)"`
+}
+
+// Validate validates the fields
+func (f *LocaleSettingForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// AutoLinkRuleForm form for adding or editing an auto-link rule of an organization or
+// a repository
+type AutoLinkRuleForm struct {
+	Pattern string `binding:"Required;MaxSize(255)" locale:"repo.settings.autolinks.pattern"`
+	URL     string `binding:"Required" locale:"repo.settings.autolinks.url"`
+	Sort    int
+}
+
+// Validate validates the fields
+func (f *AutoLinkRuleForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// ContributorAgreementForm form for changing the contributor license agreement of a repository
+// or an organization
+type ContributorAgreementForm struct {
+	Title   string `binding:"Required;MaxSize(255)"`
+	Content string `binding:"Required"`
+}
+
+// Validate validates the fields
+func (f *ContributorAgreementForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// RepoPagesForm form for changing the static site of a repository
+type RepoPagesForm struct {
+	Source       string `binding:"Required;In(branch,artifact)"`
+	Branch       string `binding:"MaxSize(255)"`
+	Dir          string `binding:"MaxSize(255)"`
+	ArtifactName string `binding:"MaxSize(255)"`
+	CustomDomain string `binding:"MaxSize(255)"`
+}
+
+// Validate validates the fields
+func (f *RepoPagesForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // __________      .__  .__    __________                                     __
 // \______   \__ __|  | |  |   \______   \ ____  ________ __   ____   _______/  |_
 //  |     ___/  |  \  | |  |    |       _// __ \/ ____/  |  \_/ __ \ /  ___/\   __\
@@ -482,11 +625,13 @@ func (f *InitializeLabelsForm) Validate(ctx *macaron.Context, errs binding.Error
 // swagger:model MergePullRequestOption
 type MergePullRequestForm struct {
 	// required: true
-	// enum: merge,rebase,rebase-merge,squash
-	Do                string `binding:"Required;In(merge,rebase,rebase-merge,squash)"`
+	// enum: merge,rebase,rebase-merge,squash,fast-forward-only
+	Do                string `binding:"Required;In(merge,rebase,rebase-merge,squash,fast-forward-only)"`
 	MergeTitleField   string
 	MergeMessageField string
 	ForceMerge        *bool `json:"force_merge,omitempty"`
+	// fold the fixup! and squash! commits into the commits they amend, only used by the rebase styles
+	Autosquash bool `json:"autosquash,omitempty"`
 }
 
 // Validate validates the fields
@@ -634,6 +779,29 @@ func (f *EditPreviewDiffForm) Validate(ctx *macaron.Context, errs binding.Errors
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// WorkspaceFileForm form for changing a file in the web IDE workspace of a branch
+type WorkspaceFileForm struct {
+	Content string
+}
+
+// Validate validates the fields
+func (f *WorkspaceFileForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// WorkspaceCommitForm form for committing the changes of the web IDE workspace of a branch
+type WorkspaceCommitForm struct {
+	CommitSummary string `binding:"MaxSize(100)"`
+	CommitMessage string
+	CommitChoice  string `binding:"Required;MaxSize(50)"`
+	NewBranchName string `binding:"GitRefName;MaxSize(100)"`
+}
+
+// Validate validates the fields
+func (f *WorkspaceCommitForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 //  ____ ___        .__                    .___
 // |    |   \______ |  |   _________     __| _/
 // |    |   /\____ \|  |  /  _ \__  \   / __ |
@@ -688,6 +856,19 @@ func (f *DeleteRepoFileForm) Validate(ctx *macaron.Context, errs binding.Errors)
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// CherryPickForm form for cherry-picking or reverting a commit to a new branch
+type CherryPickForm struct {
+	BaseBranch    string `binding:"Required;MaxSize(100)"`
+	NewBranchName string `binding:"Required;GitRefName;MaxSize(100)"`
+	CommitMessage string `binding:"Required"`
+	Revert        bool
+}
+
+// Validate validates the fields
+func (f *CherryPickForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // ___________.__                 ___________                     __
 // \__    ___/|__| _____   ____   \__    ___/___________    ____ |  | __ ___________
 // |    |   |  |/     \_/ __ \    |    |  \_  __ \__  \ _/ ___\|  |/ // __ \_  __ \
@@ -711,6 +892,38 @@ type SaveTopicForm struct {
 	Topics []string `binding:"topics;Required;"`
 }
 
+// TopicForm form for curating a topic
+type TopicForm struct {
+	Description string
+	Logo        string `binding:"ValidUrl;MaxSize(255)" locale:"admin.topics.logo"`
+	IsFeatured  bool
+}
+
+// Validate validates the fields
+func (f *TopicForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// MergeTopicForm form for merging a topic into another one
+type MergeTopicForm struct {
+	Target string `binding:"Required" locale:"admin.topics.merge_target"`
+}
+
+// Validate validates the fields
+func (f *MergeTopicForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// WatchPathForm form for watching a path of a repository
+type WatchPathForm struct {
+	Path string `binding:"Required;MaxSize(255)"`
+}
+
+// Validate validates the fields
+func (f *WatchPathForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // DeadlineForm hold the validation rules for deadlines
 type DeadlineForm struct {
 	DateString string `form:"date" binding:"Required;Size(10)"`
@@ -0,0 +1,35 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"gitea.com/macaron/binding"
+	"gitea.com/macaron/macaron"
+)
+
+// SnippetForm form for creating or editing a snippet, the names and contents
+// of its files are given in the same order
+type SnippetForm struct {
+	Title       string `binding:"Required;MaxSize(255)"`
+	Description string
+	Visibility  string   `binding:"In(public,limited,private)"`
+	FileName    []string `form:"file_name"`
+	FileContent []string `form:"file_content"`
+}
+
+// Validate validates the fields
+func (f *SnippetForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// SnippetCommentForm form for commenting on a snippet
+type SnippetCommentForm struct {
+	Content string `binding:"Required"`
+}
+
+// Validate validates the fields
+func (f *SnippetCommentForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -6,6 +6,8 @@
 package sso
 
 import (
+	"net/http"
+	"regexp"
 	"strings"
 	"time"
 
@@ -22,6 +24,42 @@ var (
 	_ SingleSignOn = &OAuth2{}
 )
 
+// deployTokenPaths are the API paths the deploy tokens with a scope can POST to, their groups are
+// the owner and the name of the repository
+var deployTokenPaths = map[models.DeployTokenScope]*regexp.Regexp{
+	models.DeployTokenScopeReleaseUpload: regexp.MustCompile(`^/api/v1/repos/([^/]+)/([^/]+)/releases/[0-9]+/assets$`),
+}
+
+// userIDFromDeployToken returns the ID of the creator of a deploy token if its scopes allow the
+// request
+func userIDFromDeployToken(ctx *macaron.Context, token string) int64 {
+	if ctx.Req.Method != http.MethodPost {
+		return 0
+	}
+	for scope, pattern := range deployTokenPaths {
+		matches := pattern.FindStringSubmatch(ctx.Req.URL.Path)
+		if matches == nil {
+			continue
+		}
+		repo, err := models.GetRepositoryByOwnerAndName(matches[1], matches[2])
+		if err != nil {
+			if !models.IsErrRepoNotExist(err) {
+				log.Error("GetRepositoryByOwnerAndName: %v", err)
+			}
+			return 0
+		}
+		user, err := models.AuthenticateDeployToken(token, repo.ID, scope)
+		if err != nil {
+			if !models.IsErrDeployTokenNotExist(err) && !models.IsErrUserProhibitLogin(err) {
+				log.Error("AuthenticateDeployToken: %v", err)
+			}
+			return 0
+		}
+		return user.ID
+	}
+	return 0
+}
+
 // CheckOAuthAccessToken returns uid of user from oauth token
 func CheckOAuthAccessToken(accessToken string) int64 {
 	// JWT tokens require a "."
@@ -84,6 +122,13 @@ func (o *OAuth2) userIDFromToken(ctx *macaron.Context) int64 {
 	}
 
 	// Let's see if token is valid.
+	if models.IsDeployToken(tokenSHA) {
+		uid := userIDFromDeployToken(ctx, tokenSHA)
+		if uid != 0 {
+			ctx.Data["IsApiToken"] = true
+		}
+		return uid
+	}
 	if strings.Contains(tokenSHA, ".") {
 		uid := CheckOAuthAccessToken(tokenSHA)
 		if uid != 0 {
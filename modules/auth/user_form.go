@@ -9,6 +9,8 @@ import (
 	"mime/multipart"
 	"strings"
 
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 
 	"gitea.com/macaron/binding"
@@ -205,6 +207,11 @@ type UpdateProfileForm struct {
 	Language            string `binding:"Size(5)"`
 	Description         string `binding:"MaxSize(255)"`
 	KeepActivityPrivate bool
+	IsUnavailable       bool
+	AwayFrom            string
+	AwayUntil           string
+	AwayMessage         string `binding:"MaxSize(255)"`
+	AwayAutoReply       bool
 }
 
 // Validate validates the fields
@@ -251,7 +258,8 @@ func (f *UpdateThemeForm) Validate(ctx *macaron.Context, errs binding.Errors) bi
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
-// IsThemeExists checks if the theme is a theme available in the config.
+// IsThemeExists checks if the theme is a theme available in the config or uploaded by the
+// site administrators.
 func (f UpdateThemeForm) IsThemeExists() bool {
 	var exists bool
 
@@ -261,6 +269,13 @@ func (f UpdateThemeForm) IsThemeExists() bool {
 			break
 		}
 	}
+	if !exists {
+		theme, err := models.GetUploadedTheme(f.Theme)
+		if err != nil {
+			log.Error("GetUploadedTheme: %v", err)
+		}
+		exists = theme != nil
+	}
 
 	return exists
 }
@@ -360,3 +375,15 @@ type U2FDeleteForm struct {
 func (f *U2FDeleteForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// StarListForm form for creating or editing a star list
+type StarListForm struct {
+	Name        string `binding:"Required;MaxSize(100)" locale:"user.star_list.name"`
+	Description string `binding:"MaxSize(255)" locale:"user.star_list.description"`
+	IsPrivate   bool
+}
+
+// Validate validates the fields
+func (f *StarListForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
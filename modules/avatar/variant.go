@@ -0,0 +1,171 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package avatar
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/nfnt/resize"
+)
+
+// The kinds of the avatars, which are also the URL prefixes of their files
+const (
+	KindUser = "avatars"
+	KindRepo = "repo-avatars"
+)
+
+// The formats of the variants, which are also the extensions of their files
+const (
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+var (
+	variantNamePattern = regexp.MustCompile(`^[0-9A-Za-z\-]+$`)
+	numericNamePattern = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// VariantSize returns the size of the variant served for an avatar displayed in the size, or 0
+// if the avatar itself should be served.
+func VariantSize(size int) int {
+	if size <= 0 || size >= AvatarSize {
+		return 0
+	}
+	sizes := append([]int{}, setting.AvatarVariantSizes...)
+	sort.Ints(sizes)
+	for _, s := range sizes {
+		if s >= size && s < AvatarSize {
+			return s
+		}
+	}
+	return 0
+}
+
+// IsVariantSize returns true if variants of the size are generated.
+func IsVariantSize(size int) bool {
+	return size > 0 && VariantSize(size) == size
+}
+
+// IsContentAddressed returns true if the name of an avatar changes with its content, so its
+// files can be cached forever. The random avatars are named by the ID of their owner.
+func IsContentAddressed(name string) bool {
+	return !numericNamePattern.MatchString(name)
+}
+
+// NegotiateFormat returns the format of the variants served to a client sending the
+// Accept header, WebP if the client accepts it and PNG otherwise.
+func NegotiateFormat(accept string) string {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), "image/webp") {
+			continue
+		}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if len(param) > 2 && strings.EqualFold(param[:2], "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q == 0 {
+					return FormatPNG
+				}
+			}
+		}
+		return FormatWebP
+	}
+	return FormatPNG
+}
+
+func variantPath(kind, name string, size int, format string) string {
+	return filepath.Join(setting.AvatarVariantPath, kind, name+"-"+strconv.Itoa(size)+"."+format)
+}
+
+// Variant returns the path of the variant in the size and format of an avatar stored in dir,
+// it is generated the first time it is requested and again when the avatar changes. kind
+// separates the variants of the avatars stored in different directories.
+func Variant(kind, dir, name string, size int, format string) (string, error) {
+	if !variantNamePattern.MatchString(name) || !IsVariantSize(size) || (format != FormatPNG && format != FormatWebP) {
+		return "", os.ErrNotExist
+	}
+	src := filepath.Join(dir, name)
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return "", err
+	}
+	dst := variantPath(kind, name, size, format)
+	if info, err := os.Stat(dst); err == nil && !info.ModTime().Before(srcInfo.ModTime()) {
+		return dst, nil
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return "", fmt.Errorf("Decode: %v", err)
+	}
+	img = resize.Resize(uint(size), uint(size), img, resize.Lanczos3)
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return "", err
+	}
+	// write to a temporary file first so that concurrent requests never serve a partial image
+	tmp, err := ioutil.TempFile(filepath.Dir(dst), name+"-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if format == FormatWebP {
+		err = EncodeWebP(tmp, img)
+	} else {
+		err = png.Encode(tmp, img)
+	}
+	if err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("Encode: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return "", err
+	}
+	log.Trace("Avatar variant generated: %s", dst)
+	return dst, nil
+}
+
+// RemoveVariants removes the generated variants of an avatar.
+func RemoveVariants(kind, name string) {
+	if !variantNamePattern.MatchString(name) {
+		return
+	}
+	paths, err := filepath.Glob(filepath.Join(setting.AvatarVariantPath, kind, name+"-*"))
+	if err != nil {
+		log.Error("Glob: %v", err)
+		return
+	}
+	for _, p := range paths {
+		// the names of other avatars may start with the name too
+		size := strings.TrimPrefix(filepath.Base(p), name+"-")
+		ext := filepath.Ext(size)
+		if (ext != "."+FormatPNG && ext != "."+FormatWebP) || !numericNamePattern.MatchString(strings.TrimSuffix(size, ext)) {
+			continue
+		}
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			log.Error("Failed to remove avatar variant %s: %v", p, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package avatar
+
+import (
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariant(t *testing.T) {
+	dir, err := ioutil.TempDir("", "avatar-variants")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	oldPath, oldSizes := setting.AvatarVariantPath, setting.AvatarVariantSizes
+	defer func() {
+		setting.AvatarVariantPath, setting.AvatarVariantSizes = oldPath, oldSizes
+	}()
+	setting.AvatarVariantPath = filepath.Join(dir, "variants")
+	setting.AvatarVariantSizes = []int{96, 24, 48}
+
+	assert.Equal(t, 24, VariantSize(20))
+	assert.Equal(t, 48, VariantSize(48))
+	assert.Equal(t, 96, VariantSize(56))
+	assert.Equal(t, 0, VariantSize(100))
+	assert.Equal(t, 0, VariantSize(-1))
+	assert.False(t, IsVariantSize(56))
+
+	img, err := RandomImage([]byte("gitea@local"))
+	assert.NoError(t, err)
+	uploads := filepath.Join(dir, "uploads")
+	assert.NoError(t, os.MkdirAll(uploads, os.ModePerm))
+	for _, name := range []string{"1", "1-abcdef"} {
+		f, err := os.Create(filepath.Join(uploads, name))
+		assert.NoError(t, err)
+		assert.NoError(t, png.Encode(f, img))
+		assert.NoError(t, f.Close())
+	}
+
+	_, err = Variant(KindUser, uploads, "1", 56, FormatPNG)
+	assert.True(t, os.IsNotExist(err))
+	_, err = Variant(KindUser, uploads, "../1", 48, FormatPNG)
+	assert.True(t, os.IsNotExist(err))
+	_, err = Variant(KindUser, uploads, "2", 48, FormatPNG)
+	assert.True(t, os.IsNotExist(err))
+	_, err = Variant(KindUser, uploads, "1", 48, "gif")
+	assert.True(t, os.IsNotExist(err))
+
+	for _, name := range []string{"1", "1-abcdef"} {
+		p, err := Variant(KindUser, uploads, name, 48, FormatPNG)
+		assert.NoError(t, err)
+		f, err := os.Open(p)
+		assert.NoError(t, err)
+		cfg, _, err := image.DecodeConfig(f)
+		f.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, 48, cfg.Width)
+		assert.Equal(t, 48, cfg.Height)
+
+		p, err = Variant(KindUser, uploads, name, 48, FormatWebP)
+		assert.NoError(t, err)
+		data, err := ioutil.ReadFile(p)
+		assert.NoError(t, err)
+		assert.Equal(t, "WEBPVP8L", string(data[8:16]))
+	}
+
+	RemoveVariants(KindUser, "1")
+	assert.NoFileExists(t, variantPath(KindUser, "1", 48, FormatPNG))
+	assert.NoFileExists(t, variantPath(KindUser, "1", 48, FormatWebP))
+	assert.FileExists(t, variantPath(KindUser, "1-abcdef", 48, FormatPNG))
+	assert.FileExists(t, variantPath(KindUser, "1-abcdef", 48, FormatWebP))
+
+	assert.False(t, IsContentAddressed("1"))
+	assert.True(t, IsContentAddressed("1-abcdef"))
+}
+
+func TestNegotiateFormat(t *testing.T) {
+	assert.Equal(t, FormatPNG, NegotiateFormat(""))
+	assert.Equal(t, FormatPNG, NegotiateFormat("image/png,image/*;q=0.8"))
+	assert.Equal(t, FormatWebP, NegotiateFormat("image/avif,image/webp,image/apng,image/*,*/*;q=0.8"))
+	assert.Equal(t, FormatWebP, NegotiateFormat("image/WebP; q=0.5"))
+	assert.Equal(t, FormatPNG, NegotiateFormat("image/webp;q=0, image/*"))
+}
@@ -0,0 +1,527 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package avatar
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+	"math/bits"
+	"sort"
+)
+
+// The encoder below writes lossless WebP images (VP8L) as described in
+// https://developers.google.com/speed/webp/docs/webp_lossless_bitstream_specification
+// It only uses the subtract green and predictor transforms and a greedy search
+// for backward references, which is good enough for the small avatar variants.
+
+// webpMaxSize is the largest width and height of a lossless WebP image
+const webpMaxSize = 1 << 14
+
+// webpPredictorBits is log2 of the size of the blocks predicted with the same mode
+const webpPredictorBits = 4
+
+// The predictor modes tried for every block
+const (
+	webpPredictLeft    = 1
+	webpPredictTop     = 2
+	webpPredictAverage = 7
+)
+
+// webpMaxCopyLength is the longest backward reference
+const webpMaxCopyLength = 4096
+
+// webpMaxCopyDistance keeps the distances of backward references within the
+// 40 symbols of the distance alphabet
+const webpMaxCopyDistance = 1<<20 - 120
+
+// webpHashBits is log2 of the size of the table finding earlier occurrences of pixels
+const webpHashBits = 14
+
+// webpAlphabetSizes are the sizes of the alphabets of the green, red, blue,
+// alpha and distance prefix codes of an image without color cache
+var webpAlphabetSizes = [5]int{256 + 24, 256, 256, 256, 40}
+
+// webpCodeLengthOrder is the order in which the code length code is written
+var webpCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// webpBitWriter writes values with their least significant bit first
+type webpBitWriter struct {
+	buf   []byte
+	bits  uint64
+	nbits uint
+}
+
+func (w *webpBitWriter) writeBits(v uint32, n uint) {
+	w.bits |= uint64(v) << w.nbits
+	w.nbits += n
+	for w.nbits >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.nbits -= 8
+	}
+}
+
+func (w *webpBitWriter) bytes() []byte {
+	if w.nbits > 0 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits, w.nbits = 0, 0
+	}
+	return w.buf
+}
+
+// webpPrefixCode is a canonical prefix code, the codes are stored reversed
+// as they are written from their most significant bit
+type webpPrefixCode struct {
+	lengths []uint8
+	codes   []uint16
+}
+
+func newWebPPrefixCode(lengths []uint8) *webpPrefixCode {
+	var count, next [16]uint16
+	for _, l := range lengths {
+		if l > 0 {
+			count[l]++
+		}
+	}
+	code := uint16(0)
+	for l := 1; l < len(next); l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+
+	c := &webpPrefixCode{lengths: lengths, codes: make([]uint16, len(lengths))}
+	for symbol, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		code, reversed := next[l], uint16(0)
+		next[l]++
+		for i := uint8(0); i < l; i++ {
+			reversed = reversed<<1 | code&1
+			code >>= 1
+		}
+		c.codes[symbol] = reversed
+	}
+	return c
+}
+
+func (c *webpPrefixCode) write(w *webpBitWriter, symbol uint32) {
+	w.writeBits(uint32(c.codes[symbol]), uint(c.lengths[symbol]))
+}
+
+// webpCodeLengths returns the lengths of a Huffman code for the histogram
+// with at least two used symbols, no code is longer than maxLength
+func webpCodeLengths(histogram []uint32, maxLength uint8) []uint8 {
+	counts := append([]uint32{}, histogram...)
+	for {
+		// the leaves are merged in the order of their counts, the merged
+		// nodes are created in the same order so they need no sorting
+		symbols := make([]int, 0, len(counts))
+		for symbol, count := range counts {
+			if count > 0 {
+				symbols = append(symbols, symbol)
+			}
+		}
+		sort.SliceStable(symbols, func(i, j int) bool {
+			return counts[symbols[i]] < counts[symbols[j]]
+		})
+		nodeCounts := make([]uint32, len(symbols), 2*len(symbols)-1)
+		parents := make([]int, 2*len(symbols)-1)
+		for i, symbol := range symbols {
+			nodeCounts[i] = counts[symbol]
+		}
+		leaf, merged := 0, len(symbols)
+		smallest := func() int {
+			if leaf < len(symbols) && (merged == len(nodeCounts) || nodeCounts[leaf] <= nodeCounts[merged]) {
+				leaf++
+				return leaf - 1
+			}
+			merged++
+			return merged - 1
+		}
+		for len(nodeCounts) < cap(nodeCounts) {
+			a, b := smallest(), smallest()
+			parents[a], parents[b] = len(nodeCounts), len(nodeCounts)
+			nodeCounts = append(nodeCounts, nodeCounts[a]+nodeCounts[b])
+		}
+
+		depths := make([]uint8, len(nodeCounts))
+		tooLong := false
+		for i := len(nodeCounts) - 2; i >= 0; i-- {
+			depths[i] = depths[parents[i]] + 1
+			tooLong = tooLong || depths[i] > maxLength
+		}
+		if !tooLong {
+			lengths := make([]uint8, len(counts))
+			for i, symbol := range symbols {
+				lengths[symbol] = depths[i]
+			}
+			return lengths
+		}
+
+		// flatten the histogram until the code fits
+		for symbol, count := range counts {
+			if count > 0 {
+				counts[symbol] = (count + 1) / 2
+			}
+		}
+	}
+}
+
+// writeWebPPrefixCode writes a prefix code for the symbols of the histogram
+func writeWebPPrefixCode(w *webpBitWriter, histogram []uint32) *webpPrefixCode {
+	used := 0
+	symbol := uint32(0)
+	for s, count := range histogram {
+		if count > 0 {
+			used++
+			symbol = uint32(s)
+		}
+	}
+	if used <= 1 {
+		// a simple code of a single 8 bit symbol, which is written with zero bits
+		w.writeBits(1, 1)
+		w.writeBits(0, 1)
+		w.writeBits(1, 1)
+		w.writeBits(symbol, 8)
+		return newWebPPrefixCode(make([]uint8, len(histogram)))
+	}
+
+	// the code lengths are written with a code length code, which codes runs
+	// of zeros with the symbols 17 and 18 and their extra bits
+	lengths := webpCodeLengths(histogram, 15)
+	type token struct {
+		symbol uint32
+		extra  uint32
+	}
+	tokens := make([]token, 0, len(lengths))
+	for i := 0; i < len(lengths); {
+		if lengths[i] != 0 {
+			tokens = append(tokens, token{symbol: uint32(lengths[i])})
+			i++
+			continue
+		}
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == 0 {
+			run++
+		}
+		i += run
+		for run > 0 {
+			switch {
+			case run >= 11:
+				n := run
+				if n > 138 {
+					n = 138
+				}
+				tokens = append(tokens, token{symbol: 18, extra: uint32(n - 11)})
+				run -= n
+			case run >= 3:
+				tokens = append(tokens, token{symbol: 17, extra: uint32(run - 3)})
+				run = 0
+			default:
+				tokens = append(tokens, token{symbol: 0})
+				run--
+			}
+		}
+	}
+
+	codeLengthHistogram := make([]uint32, len(webpCodeLengthOrder))
+	for _, t := range tokens {
+		codeLengthHistogram[t.symbol]++
+	}
+	if codeLengthHistogram[tokens[0].symbol] == uint32(len(tokens)) {
+		// a code needs two symbols to give every symbol a length
+		codeLengthHistogram[(tokens[0].symbol+1)%uint32(len(codeLengthHistogram))]++
+	}
+	codeLengths := webpCodeLengths(codeLengthHistogram, 7)
+	n := len(webpCodeLengthOrder)
+	for n > 4 && codeLengths[webpCodeLengthOrder[n-1]] == 0 {
+		n--
+	}
+	w.writeBits(0, 1)
+	w.writeBits(uint32(n-4), 4)
+	for _, symbol := range webpCodeLengthOrder[:n] {
+		w.writeBits(uint32(codeLengths[symbol]), 3)
+	}
+	// the lengths of all the symbols follow
+	w.writeBits(0, 1)
+
+	codeLengthCode := newWebPPrefixCode(codeLengths)
+	for _, t := range tokens {
+		codeLengthCode.write(w, t.symbol)
+		switch t.symbol {
+		case 17:
+			w.writeBits(t.extra, 3)
+		case 18:
+			w.writeBits(t.extra, 7)
+		}
+	}
+	return newWebPPrefixCode(lengths)
+}
+
+// webpToken is a literal pixel or a backward reference to length pixels at
+// the distance code
+type webpToken struct {
+	pixel    uint32
+	length   int
+	distance int
+}
+
+// webpBackwardReferences replaces the pixels repeating the left pixel, the
+// pixels above or earlier pixels with the same hash by backward references
+func webpBackwardReferences(pixels []uint32, width int) []webpToken {
+	hash := func(i int) uint32 {
+		return ((pixels[i]*0x1e35a7bd^pixels[i+1])*0x1e35a7bd ^ pixels[i+2]) * 0x1e35a7bd >> (32 - webpHashBits)
+	}
+	var last [1 << webpHashBits]int
+	tokens := make([]webpToken, 0, len(pixels))
+	for i := 0; i < len(pixels); {
+		bestLength, bestCode := 0, 0
+		try := func(distance, code int) {
+			if distance <= 0 || distance > i {
+				return
+			}
+			length := 0
+			for i+length < len(pixels) && length < webpMaxCopyLength && pixels[i+length] == pixels[i+length-distance] {
+				length++
+			}
+			if length > bestLength {
+				bestLength, bestCode = length, code
+			}
+		}
+		// the plane codes of the left pixel and the pixel above
+		try(1, 2)
+		try(width, 1)
+		if i+2 < len(pixels) {
+			if j := last[hash(i)] - 1; j >= 0 && i-j <= webpMaxCopyDistance {
+				try(i-j, i-j+120)
+			}
+		}
+
+		length := 1
+		if bestLength >= 3 {
+			length = bestLength
+			tokens = append(tokens, webpToken{length: length, distance: bestCode})
+		} else {
+			tokens = append(tokens, webpToken{pixel: pixels[i]})
+		}
+		for end := i + length; i < end; i++ {
+			if i+2 < len(pixels) {
+				last[hash(i)] = i + 1
+			}
+		}
+	}
+	return tokens
+}
+
+// webpPrefixEncode returns the prefix symbol and the extra bits of a length or distance code
+func webpPrefixEncode(v int) (symbol uint32, extra uint32, extraBits uint) {
+	d := uint32(v - 1)
+	if d < 4 {
+		return d, 0, 0
+	}
+	high := uint(bits.Len32(d) - 1)
+	return uint32(2*high) + d>>(high-1)&1, d & (1<<(high-1) - 1), high - 1
+}
+
+// writeWebPImage writes ARGB pixels. The main image tells that it has no meta
+// prefix codes, which the images of the transforms cannot have.
+func writeWebPImage(w *webpBitWriter, pixels []uint32, width int, isMain bool) {
+	// no color cache
+	w.writeBits(0, 1)
+	if isMain {
+		w.writeBits(0, 1)
+	}
+
+	tokens := webpBackwardReferences(pixels, width)
+	var histograms [5][]uint32
+	for i, size := range webpAlphabetSizes {
+		histograms[i] = make([]uint32, size)
+	}
+	for _, t := range tokens {
+		if t.length > 0 {
+			length, _, _ := webpPrefixEncode(t.length)
+			distance, _, _ := webpPrefixEncode(t.distance)
+			histograms[0][256+length]++
+			histograms[4][distance]++
+			continue
+		}
+		histograms[0][t.pixel>>8&0xff]++
+		histograms[1][t.pixel>>16&0xff]++
+		histograms[2][t.pixel&0xff]++
+		histograms[3][t.pixel>>24]++
+	}
+	var codes [5]*webpPrefixCode
+	for i, histogram := range histograms {
+		codes[i] = writeWebPPrefixCode(w, histogram)
+	}
+	for _, t := range tokens {
+		if t.length > 0 {
+			symbol, extra, extraBits := webpPrefixEncode(t.length)
+			codes[0].write(w, 256+symbol)
+			w.writeBits(extra, extraBits)
+			symbol, extra, extraBits = webpPrefixEncode(t.distance)
+			codes[4].write(w, symbol)
+			w.writeBits(extra, extraBits)
+			continue
+		}
+		codes[0].write(w, t.pixel>>8&0xff)
+		codes[1].write(w, t.pixel>>16&0xff)
+		codes[2].write(w, t.pixel&0xff)
+		codes[3].write(w, t.pixel>>24)
+	}
+}
+
+// webpSubPixels subtracts the channels of b from the ones of a
+func webpSubPixels(a, b uint32) uint32 {
+	var res uint32
+	for shift := uint(0); shift < 32; shift += 8 {
+		res |= ((a>>shift - b>>shift) & 0xff) << shift
+	}
+	return res
+}
+
+// webpAverage2 returns the rounded down average of the channels of a and b
+func webpAverage2(a, b uint32) uint32 {
+	return (((a ^ b) & 0xfefefefe) >> 1) + (a & b)
+}
+
+func webpPrediction(pixels []uint32, width, x, y, mode int) uint32 {
+	i := y*width + x
+	switch {
+	case x == 0 && y == 0:
+		return 0xff000000
+	case y == 0:
+		return pixels[i-1]
+	case x == 0:
+		return pixels[i-width]
+	}
+	switch mode {
+	case webpPredictLeft:
+		return pixels[i-1]
+	case webpPredictTop:
+		return pixels[i-width]
+	default:
+		return webpAverage2(pixels[i-1], pixels[i-width])
+	}
+}
+
+// webpPredict replaces the pixels by their residuals to the predictions of
+// the best mode of their block and returns the image of these modes
+func webpPredict(pixels []uint32, width, height int) []uint32 {
+	const blockSize = 1 << webpPredictorBits
+	blocksX := (width + blockSize - 1) / blockSize
+	blocksY := (height + blockSize - 1) / blockSize
+	src := append([]uint32{}, pixels...)
+	modes := make([]uint32, blocksX*blocksY)
+	for by := 0; by < blocksY; by++ {
+		for bx := 0; bx < blocksX; bx++ {
+			x0, y0 := bx*blockSize, by*blockSize
+			x1, y1 := x0+blockSize, y0+blockSize
+			if x1 > width {
+				x1 = width
+			}
+			if y1 > height {
+				y1 = height
+			}
+
+			bestMode, bestCost := 0, -1
+			for _, mode := range []int{webpPredictLeft, webpPredictTop, webpPredictAverage} {
+				cost := 0
+				for y := y0; y < y1; y++ {
+					for x := x0; x < x1; x++ {
+						residual := webpSubPixels(src[y*width+x], webpPrediction(src, width, x, y, mode))
+						for shift := uint(0); shift < 32; shift += 8 {
+							if c := int(residual >> shift & 0xff); c < 128 {
+								cost += c
+							} else {
+								cost += 256 - c
+							}
+						}
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					bestMode, bestCost = mode, cost
+				}
+			}
+
+			modes[by*blocksX+bx] = uint32(bestMode) << 8
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					pixels[y*width+x] = webpSubPixels(src[y*width+x], webpPrediction(src, width, x, y, bestMode))
+				}
+			}
+		}
+	}
+	return modes
+}
+
+// EncodeWebP writes the image as a lossless WebP image.
+func EncodeWebP(out io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > webpMaxSize || height > webpMaxSize {
+		return errors.New("webp: invalid image size")
+	}
+	nrgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+
+	pixels := make([]uint32, width*height)
+	hasAlpha := uint32(0)
+	for i := range pixels {
+		p := nrgba.Pix[i*4 : i*4+4]
+		if p[3] != 0xff {
+			hasAlpha = 1
+		}
+		pixels[i] = uint32(p[3])<<24 | uint32(p[0])<<16 | uint32(p[1])<<8 | uint32(p[2])
+	}
+
+	w := &webpBitWriter{}
+	w.writeBits(0x2f, 8)
+	w.writeBits(uint32(width-1), 14)
+	w.writeBits(uint32(height-1), 14)
+	w.writeBits(hasAlpha, 1)
+	w.writeBits(0, 3)
+
+	// subtract green transform
+	w.writeBits(1, 1)
+	w.writeBits(2, 2)
+	for i, p := range pixels {
+		green := p >> 8 & 0xff
+		pixels[i] = p&0xff00ff00 | (p>>16-green)&0xff<<16 | (p-green)&0xff
+	}
+
+	// predictor transform
+	w.writeBits(1, 1)
+	w.writeBits(0, 2)
+	w.writeBits(webpPredictorBits-2, 3)
+	const blockSize = 1 << webpPredictorBits
+	writeWebPImage(w, webpPredict(pixels, width, height), (width+blockSize-1)/blockSize, false)
+
+	w.writeBits(0, 1)
+	writeWebPImage(w, pixels, width, true)
+	data := w.bytes()
+
+	padding := len(data) & 1
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(12+len(data)+padding))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+	if _, err := out.Write(header); err != nil {
+		return err
+	}
+	if _, err := out.Write(data); err != nil {
+		return err
+	}
+	if padding > 0 {
+		_, err := out.Write([]byte{0})
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/webp"
+)
+
+func TestEncodeWebP(t *testing.T) {
+	random, err := RandomImage([]byte("gitea@local"))
+	assert.NoError(t, err)
+	flat := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	flat.Set(1, 1, color.NRGBA{R: 10, G: 20, B: 30, A: 128})
+	noise := image.NewNRGBA(image.Rect(0, 0, 37, 21))
+	rand.New(rand.NewSource(1)).Read(noise.Pix)
+
+	for _, img := range []image.Image{random, flat, noise} {
+		var buf bytes.Buffer
+		assert.NoError(t, EncodeWebP(&buf, img))
+		data := buf.Bytes()
+		assert.Equal(t, "WEBPVP8L", string(data[8:16]))
+
+		decoded, err := webp.Decode(bytes.NewReader(data))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		bounds := img.Bounds()
+		assert.Equal(t, bounds.Size(), decoded.Bounds().Size())
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				want := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y))
+				got := color.NRGBAModel.Convert(decoded.At(x, y))
+				if !assert.Equal(t, want, got, "pixel %d,%d", x, y) {
+					return
+				}
+			}
+		}
+	}
+
+	assert.Error(t, EncodeWebP(&bytes.Buffer{}, image.NewNRGBA(image.Rect(0, 0, 0, 0))))
+}
+
+func TestWebPPrefixEncode(t *testing.T) {
+	for _, c := range []struct {
+		value, symbol, extra int
+		extraBits            uint
+	}{
+		{1, 0, 0, 0},
+		{4, 3, 0, 0},
+		{5, 4, 0, 1},
+		{6, 4, 1, 1},
+		{7, 5, 0, 1},
+		{9, 6, 0, 2},
+		{4096, 23, 1023, 10},
+	} {
+		symbol, extra, extraBits := webpPrefixEncode(c.value)
+		assert.EqualValues(t, c.symbol, symbol, c.value)
+		assert.EqualValues(t, c.extra, extra, c.value)
+		assert.Equal(t, c.extraBits, extraBits, c.value)
+	}
+}
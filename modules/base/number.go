@@ -0,0 +1,215 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package base
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultNumberPattern is the ICU number pattern used when none is given
+const DefaultNumberPattern = "#,##0.###"
+
+// langNumberSymbols are the decimal and grouping separators of the languages, the other
+// languages use "." and ",".
+var langNumberSymbols = map[string][2]string{
+	"de-DE": {",", "."},
+	"es-ES": {",", "."},
+	"it-IT": {",", "."},
+	"nl-NL": {",", "."},
+	"pt-BR": {",", "."},
+	"pt-PT": {",", " "},
+	"fr-FR": {",", " "},
+	"ru-RU": {",", " "},
+	"uk-UA": {",", " "},
+	"pl-PL": {",", " "},
+	"cs-CZ": {",", " "},
+	"sv-SE": {",", " "},
+	"fi-FI": {",", " "},
+	"lv-LV": {",", " "},
+	"tr-TR": {",", "."},
+	"de-CH": {".", "’"},
+}
+
+// NumberPattern is a parsed ICU decimal pattern like "#,##0.00".
+type NumberPattern struct {
+	Prefix            string
+	Suffix            string
+	MinIntegerDigits  int
+	MinFractionDigits int
+	MaxFractionDigits int
+	// PrimaryGrouping is the size of the group of digits before the decimal separator,
+	// SecondaryGrouping the size of the others, for example 3 and 2 for "#,##,##0".
+	PrimaryGrouping   int
+	SecondaryGrouping int
+}
+
+// unquoteNumberAffix removes the quotes of the prefix or the suffix of a number pattern,
+// text between single quotes is literal and two single quotes are a quote.
+func unquoteNumberAffix(affix string) string {
+	affix = strings.ReplaceAll(strings.ReplaceAll(affix, "''", "\x00"), "'", "")
+	return strings.ReplaceAll(affix, "\x00", "'")
+}
+
+// ParseNumberPattern parses an ICU decimal pattern, only the positive sub-pattern is used.
+func ParseNumberPattern(pattern string) (*NumberPattern, error) {
+	// find the number outside of the quoted texts
+	start, end, quoted := -1, -1, false
+	for i, c := range pattern {
+		if c == '\'' {
+			quoted = !quoted
+			continue
+		}
+		if quoted {
+			continue
+		}
+		if c == ';' {
+			break
+		}
+		if strings.ContainsRune("#0,.", c) {
+			if start < 0 {
+				start = i
+			}
+			end = i + 1
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("invalid number pattern %q: no digits", pattern)
+	}
+	suffix := pattern[end:]
+	if i := strings.IndexByte(suffix, ';'); i >= 0 && strings.Count(suffix[:i], "'")%2 == 0 {
+		suffix = suffix[:i]
+	}
+	p := &NumberPattern{
+		Prefix: unquoteNumberAffix(pattern[:start]),
+		Suffix: unquoteNumberAffix(suffix),
+	}
+
+	number := pattern[start:end]
+	integer, fraction := number, ""
+	if i := strings.IndexByte(number, '.'); i >= 0 {
+		integer, fraction = number[:i], number[i+1:]
+	}
+	if strings.ContainsAny(fraction, ".,") || strings.ContainsRune(number, '\'') {
+		return nil, fmt.Errorf("invalid number pattern %q: misplaced separator or quote", pattern)
+	}
+	for _, c := range fraction {
+		if c == '0' {
+			if p.MaxFractionDigits > p.MinFractionDigits {
+				return nil, fmt.Errorf("invalid number pattern %q: '0' after '#' in fraction", pattern)
+			}
+			p.MinFractionDigits++
+		}
+		p.MaxFractionDigits++
+	}
+
+	groups := strings.Split(integer, ",")
+	for _, group := range groups {
+		p.MinIntegerDigits += strings.Count(group, "0")
+	}
+	if len(groups) > 1 {
+		p.PrimaryGrouping = len(groups[len(groups)-1])
+		p.SecondaryGrouping = p.PrimaryGrouping
+		if len(groups) > 2 {
+			p.SecondaryGrouping = len(groups[len(groups)-2])
+		}
+		if p.PrimaryGrouping == 0 || p.SecondaryGrouping == 0 {
+			return nil, fmt.Errorf("invalid number pattern %q: empty group", pattern)
+		}
+	}
+	return p, nil
+}
+
+// ValidateNumberPattern checks that an ICU decimal pattern is supported.
+func ValidateNumberPattern(pattern string) error {
+	_, err := ParseNumberPattern(pattern)
+	return err
+}
+
+func groupDigits(digits string, primary, secondary int, separator string) string {
+	if primary <= 0 || len(digits) <= primary {
+		return digits
+	}
+	groups := []string{digits[len(digits)-primary:]}
+	digits = digits[:len(digits)-primary]
+	for len(digits) > secondary {
+		groups = append([]string{digits[len(digits)-secondary:]}, groups...)
+		digits = digits[:len(digits)-secondary]
+	}
+	groups = append([]string{digits}, groups...)
+	return strings.Join(groups, separator)
+}
+
+// Format formats a number with the pattern and the separators of the language.
+func (p *NumberPattern) Format(n float64, lang string) string {
+	symbols, ok := langNumberSymbols[lang]
+	if !ok {
+		symbols = [2]string{".", ","}
+	}
+
+	negative := n < 0
+	s := strconv.FormatFloat(math.Abs(n), 'f', p.MaxFractionDigits, 64)
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+	for len(fraction) > p.MinFractionDigits && strings.HasSuffix(fraction, "0") {
+		fraction = fraction[:len(fraction)-1]
+	}
+	if integer == "0" && p.MinIntegerDigits == 0 && fraction != "" {
+		integer = ""
+	}
+	if len(integer) < p.MinIntegerDigits {
+		integer = strings.Repeat("0", p.MinIntegerDigits-len(integer)) + integer
+	}
+
+	var buf strings.Builder
+	if negative && (integer+fraction) != strings.Repeat("0", len(integer+fraction)) {
+		buf.WriteString("-")
+	}
+	buf.WriteString(p.Prefix)
+	buf.WriteString(groupDigits(integer, p.PrimaryGrouping, p.SecondaryGrouping, symbols[1]))
+	if fraction != "" {
+		buf.WriteString(symbols[0])
+		buf.WriteString(fraction)
+	}
+	buf.WriteString(p.Suffix)
+	return buf.String()
+}
+
+// FormatNumber formats an integer or a float with an ICU decimal pattern like "#,##0.##"
+// and the separators of the language. An invalid pattern falls back to DefaultNumberPattern.
+func FormatNumber(n interface{}, pattern, lang string) string {
+	var f float64
+	switch v := n.(type) {
+	case int:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case int32:
+		f = float64(v)
+	case uint:
+		f = float64(v)
+	case uint64:
+		f = float64(v)
+	case float32:
+		f = float64(v)
+	case float64:
+		f = v
+	default:
+		return fmt.Sprint(n)
+	}
+
+	if pattern == "" {
+		pattern = DefaultNumberPattern
+	}
+	p, err := ParseNumberPattern(pattern)
+	if err != nil {
+		p, _ = ParseNumberPattern(DefaultNumberPattern)
+	}
+	return p.Format(f, lang)
+}
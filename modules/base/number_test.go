@@ -0,0 +1,58 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatNumber(t *testing.T) {
+	for _, c := range []struct {
+		n        interface{}
+		pattern  string
+		lang     string
+		expected string
+	}{
+		{1234567, "", "en-US", "1,234,567"},
+		{1234567.891, "", "en-US", "1,234,567.891"},
+		{1234567.891, "", "de-DE", "1.234.567,891"},
+		{1234567.891, "", "fr-FR", "1\u202f234\u202f567,891"},
+		{1234567.891, "#,##0.00", "en-US", "1,234,567.89"},
+		{1234567, "#,##,##0", "en-US", "12,34,567"},
+		{1234567, "0", "en-US", "1234567"},
+		{5, "000", "en-US", "005"},
+		{0.5, "#.##", "en-US", ".5"},
+		{0.5, "0.00", "de-DE", "0,50"},
+		{int64(-1234), "#,##0", "en-US", "-1,234"},
+		{-0.001, "0.0", "en-US", "0.0"},
+		{42, "#,##0 'pts'", "en-US", "42 pts"},
+		{42, "'#'0", "en-US", "#42"},
+		{42, "0 'o''clock'", "en-US", "42 o'clock"},
+		{12.5, "#,##0.0%", "en-US", "12.5%"},
+		{1234, "invalid", "en-US", "1,234"},
+		{"text", "", "en-US", "text"},
+	} {
+		assert.Equal(t, c.expected, FormatNumber(c.n, c.pattern, c.lang), "%v %q", c.n, c.pattern)
+	}
+}
+
+func TestParseNumberPattern(t *testing.T) {
+	p, err := ParseNumberPattern("#,##0.00#;(#,##0.00#)")
+	assert.NoError(t, err)
+	assert.Equal(t, &NumberPattern{
+		MinIntegerDigits:  1,
+		MinFractionDigits: 2,
+		MaxFractionDigits: 3,
+		PrimaryGrouping:   3,
+		SecondaryGrouping: 3,
+	}, p)
+
+	for _, pattern := range []string{"", "abc", "#,##0.0.0", "#,##0.#0", "#,,##0"} {
+		_, err = ParseNumberPattern(pattern)
+		assert.Error(t, err, pattern)
+	}
+}
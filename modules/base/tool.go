@@ -22,6 +22,7 @@ import (
 	"time"
 	"unicode"
 
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -150,9 +151,13 @@ func DefaultAvatarLink() string {
 const DefaultAvatarSize = -1
 
 // libravatarURL returns the URL for the given email. This function should only
-// be called if a federated avatar service is enabled.
+// be called if a federated avatar service is enabled. The URLs are cached since
+// looking them up requires DNS queries to the domain of the email.
 func libravatarURL(email string) (*url.URL, error) {
-	urlStr, err := setting.LibravatarService.FromEmail(email)
+	email = strings.ToLower(strings.TrimSpace(email))
+	urlStr, err := cache.GetString("Libravatar:"+email, func() (string, error) {
+		return setting.LibravatarService.FromEmail(email)
+	})
 	if err != nil {
 		log.Error("LibravatarService.FromEmail(email=%s): error %v", email, err)
 		return nil, err
@@ -7,6 +7,7 @@ package cache
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"code.gitea.io/gitea/modules/setting"
 
@@ -41,6 +42,29 @@ func NewContext() error {
 	return err
 }
 
+// IsEnabled returns true if the cache service has been started
+func IsEnabled() bool {
+	return conn != nil
+}
+
+// Ping checks that the cache service stores and returns values. It does
+// nothing if the cache service is disabled.
+func Ping() error {
+	if conn == nil {
+		return nil
+	}
+
+	const key = "__gitea_cache_ping"
+	value := time.Now().String()
+	if err := conn.Put(key, value, 10); err != nil {
+		return err
+	}
+	if got, ok := conn.Get(key).(string); !ok || got != value {
+		return fmt.Errorf("cache returned %v instead of the stored value", conn.Get(key))
+	}
+	return conn.Delete(key)
+}
+
 // GetString returns the key value from cache with callback when no key exists in cache
 func GetString(key string, getFunc func() (string, error)) (string, error) {
 	if conn == nil || setting.CacheService.TTL == 0 {
@@ -69,6 +93,23 @@ func GetString(key string, getFunc func() (string, error)) (string, error) {
 	return fmt.Sprintf("%s", conn.Get(key)), nil
 }
 
+// GetStringWithTTL returns the key value from cache with callback when no key
+// exists in cache, storing new values for the given duration instead of the
+// configured TTL of the cache service.
+func GetStringWithTTL(key string, ttl time.Duration, getFunc func() (string, error)) (string, error) {
+	if conn == nil || ttl <= 0 {
+		return getFunc()
+	}
+	if value, ok := conn.Get(key).(string); ok {
+		return value, nil
+	}
+	value, err := getFunc()
+	if err != nil {
+		return value, err
+	}
+	return value, conn.Put(key, value, int64(ttl.Seconds()))
+}
+
 // GetInt returns key value from cache with callback when no key exists in cache
 func GetInt(key string, getFunc func() (int, error)) (int, error) {
 	if conn == nil || setting.CacheService.TTL == 0 {
@@ -0,0 +1,96 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// leaderLease is the name of the lease held by the node running the cron tasks
+const leaderLease = "leader"
+
+var leader int32
+
+// IsLeader returns if this node runs the cron tasks. Without clustering the
+// only node is always the leader.
+func IsLeader() bool {
+	if !setting.Cluster.Enabled {
+		return true
+	}
+	return atomic.LoadInt32(&leader) == 1
+}
+
+// Leader returns the name of the node which is the leader, or an empty string
+// if no node is
+func Leader() (string, error) {
+	if !setting.Cluster.Enabled {
+		return setting.Cluster.NodeName, nil
+	}
+	lease, err := models.GetClusterLease(leaderLease)
+	if err != nil || lease == nil || lease.ExpiresUnix.AsTime().Before(time.Now()) {
+		return "", err
+	}
+	return lease.Holder, nil
+}
+
+// Init elects the leader of the cluster and keeps renewing or contesting the
+// leadership until shutdown
+func Init() {
+	if !setting.Cluster.Enabled {
+		return
+	}
+	elect()
+	go graceful.GetManager().RunWithShutdownContext(run)
+}
+
+func run(ctx context.Context) {
+	ticker := time.NewTicker(setting.Cluster.LeaseDuration / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if IsLeader() {
+				setLeader(false)
+				if err := models.ReleaseClusterLease(leaderLease, setting.Cluster.NodeName); err != nil {
+					log.Error("Unable to release the cluster leadership: %v", err)
+				}
+			}
+			return
+		case <-ticker.C:
+			elect()
+		}
+	}
+}
+
+// elect acquires or renews the leadership. If the database cannot be reached,
+// this node steps down as it cannot know whether another node took over.
+func elect() {
+	acquired, err := models.AcquireClusterLease(leaderLease, setting.Cluster.NodeName, setting.Cluster.LeaseDuration)
+	if err != nil {
+		log.Error("Unable to acquire the cluster leadership: %v", err)
+	}
+	setLeader(acquired)
+}
+
+func setLeader(isLeader bool) {
+	var value int32
+	if isLeader {
+		value = 1
+	}
+	if old := atomic.SwapInt32(&leader, value); old != value {
+		if isLeader {
+			log.Info("Node %s is now the cluster leader", setting.Cluster.NodeName)
+		} else {
+			log.Info("Node %s is no longer the cluster leader", setting.Cluster.NodeName)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElect(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	oldCluster := setting.Cluster
+	defer func() {
+		setting.Cluster = oldCluster
+		setLeader(false)
+	}()
+
+	setting.Cluster.NodeName = "node-a"
+	assert.True(t, IsLeader(), "without clustering every node is the leader")
+
+	setting.Cluster.Enabled = true
+	setting.Cluster.LeaseDuration = time.Minute
+	assert.False(t, IsLeader())
+
+	acquired, err := models.AcquireClusterLease(leaderLease, "node-b", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+	elect()
+	assert.False(t, IsLeader())
+	leader, err := Leader()
+	assert.NoError(t, err)
+	assert.Equal(t, "node-b", leader)
+
+	assert.NoError(t, models.ReleaseClusterLease(leaderLease, "node-b"))
+	leader, err = Leader()
+	assert.NoError(t, err)
+	assert.Empty(t, leader)
+
+	elect()
+	assert.True(t, IsLeader())
+	leader, err = Leader()
+	assert.NoError(t, err)
+	assert.Equal(t, "node-a", leader)
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package codenotify
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/gobwas/glob"
+)
+
+// FilePaths are the paths in the base branch of a pull request the CODENOTIFY
+// file is read from, the first existing one is used.
+var FilePaths = []string{
+	"CODENOTIFY",
+	".gitea/CODENOTIFY",
+	"docs/CODENOTIFY",
+}
+
+// MaxFileSize is the maximum size of a CODENOTIFY file in bytes.
+const MaxFileSize = 64 * 1024
+
+// Rule notifies its users of the pull requests changing a file which matches
+// its pattern.
+type Rule struct {
+	Pattern string
+	Users   []string
+	globs   []glob.Glob
+}
+
+// File is the CODENOTIFY file of a repository. Each line holds a glob pattern
+// of file paths followed by the names of the users to notify, lines starting
+// with "#" are comments:
+//
+//   # notify the docs team of changes to the documentation
+//   docs/**     @alice @bob
+//   **/*.go     @carol
+//
+// Patterns ending with "/" match all files of a directory.
+type File struct {
+	Rules []*Rule
+}
+
+// Parse parses a CODENOTIFY file, invalid lines are skipped.
+func Parse(data []byte) *File {
+	file := &File{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			log.Info("CODENOTIFY line %d has no users (skipped)", lineNum)
+			continue
+		}
+		pattern := strings.TrimPrefix(fields[0], "/")
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "**"
+		}
+		globs, err := compile(pattern)
+		if err != nil {
+			log.Info("Invalid glob expression '%s' on CODENOTIFY line %d (skipped): %v", fields[0], lineNum, err)
+			continue
+		}
+
+		rule := &Rule{Pattern: pattern, globs: globs}
+		for _, name := range fields[1:] {
+			if name = strings.TrimPrefix(name, "@"); name != "" {
+				rule.Users = append(rule.Users, name)
+			}
+		}
+		file.Rules = append(file.Rules, rule)
+	}
+	return file
+}
+
+// compile compiles a pattern, a leading "**/" also matches files in the root
+// directory.
+func compile(pattern string) ([]glob.Glob, error) {
+	g, err := glob.Compile(pattern, '/')
+	if err != nil {
+		return nil, err
+	}
+	globs := []glob.Glob{g}
+	if strings.HasPrefix(pattern, "**/") {
+		if g, err = glob.Compile(pattern[3:], '/'); err != nil {
+			return nil, err
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// Match returns true if one of the files matches the rule.
+func (rule *Rule) Match(files []string) bool {
+	for _, file := range files {
+		for _, g := range rule.globs {
+			if g.Match(file) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Users returns the sorted lower case names of the users of the rules
+// matching the files.
+func (f *File) Users(files []string) []string {
+	seen := make(map[string]bool)
+	users := make([]string, 0, len(f.Rules))
+	for _, rule := range f.Rules {
+		if !rule.Match(files) {
+			continue
+		}
+		for _, name := range rule.Users {
+			name = strings.ToLower(name)
+			if !seen[name] {
+				seen[name] = true
+				users = append(users, name)
+			}
+		}
+	}
+	sort.Strings(users)
+	return users
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package codenotify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	file := Parse([]byte(`
+# comment
+docs/       @alice @Bob
+/models/*.go carol
+[invalid    @dave
+no-users
+`))
+	if assert.Len(t, file.Rules, 2) {
+		assert.Equal(t, "docs/**", file.Rules[0].Pattern)
+		assert.Equal(t, []string{"alice", "Bob"}, file.Rules[0].Users)
+		assert.Equal(t, "models/*.go", file.Rules[1].Pattern)
+		assert.Equal(t, []string{"carol"}, file.Rules[1].Users)
+	}
+}
+
+func TestFile_Users(t *testing.T) {
+	file := Parse([]byte(`
+docs/**      @alice @Bob
+**/*.md      @bob
+models/*.go  @carol
+`))
+
+	assert.Equal(t, []string{"alice", "bob"}, file.Users([]string{"docs/api/index.md"}))
+	assert.Equal(t, []string{"bob"}, file.Users([]string{"README.md"}))
+	assert.Equal(t, []string{"carol"}, file.Users([]string{"models/repo.go", "models/fixtures/repo.yml"}))
+	assert.Empty(t, file.Users([]string{"models/migrations/v1.go"}))
+	assert.Empty(t, file.Users(nil))
+}
@@ -6,6 +6,8 @@
 package context
 
 import (
+	"crypto/sha1"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -15,6 +17,7 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	"gitea.com/macaron/csrf"
 	"gitea.com/macaron/macaron"
@@ -80,7 +83,7 @@ func (ctx *APIContext) Error(status int, title string, obj interface{}) {
 	}
 
 	if status == http.StatusInternalServerError {
-		log.ErrorWithSkip(1, "%s: %s", title, message)
+		log.Ctx(LogContext(ctx.Context.Context)).ErrorWithSkip(1, "%s: %s", title, message)
 
 		if macaron.Env == macaron.PROD {
 			message = ""
@@ -96,7 +99,7 @@ func (ctx *APIContext) Error(status int, title string, obj interface{}) {
 // InternalServerError responds with an error message to the client with the error as a message
 // and the file and line of the caller.
 func (ctx *APIContext) InternalServerError(err error) {
-	log.ErrorWithSkip(1, "InternalServerError: %v", err)
+	log.Ctx(LogContext(ctx.Context.Context)).ErrorWithSkip(1, "InternalServerError: %v", err)
 
 	var message string
 	if macaron.Env != macaron.PROD {
@@ -158,6 +161,67 @@ func (ctx *APIContext) SetLinkHeader(total, pageSize int) {
 	}
 }
 
+// SetCursorLinkHeader sets the pagination link header to the page following
+// the given cursor, which is also exposed as X-Next-Cursor header.
+func (ctx *APIContext) SetCursorLinkHeader(nextCursor string) {
+	u := *ctx.Req.URL
+	queries := u.Query()
+	queries.Set("cursor", nextCursor)
+	u.RawQuery = queries.Encode()
+
+	ctx.Header().Set("Link", fmt.Sprintf("<%s%s>; rel=\"next\"", setting.AppURL, u.RequestURI()[1:]))
+	ctx.Header().Set("X-Next-Cursor", nextCursor)
+}
+
+// JSONConditional responds like JSON, but with 304 Not Modified if the client
+// already holds the same representation. The entity tag is derived from the
+// encoded response. lastModified is the last update of the listed items and
+// is only checked for requests without If-None-Match, 0 means unknown.
+func (ctx *APIContext) JSONConditional(status int, obj interface{}, lastModified timeutil.TimeStamp) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	etag := fmt.Sprintf(`W/"%x"`, sha1.Sum(data))
+	ctx.Header().Set("ETag", etag)
+	ctx.Header().Set("Cache-Control", "private, no-cache")
+	if lastModified > 0 {
+		ctx.Header().Set("Last-Modified", lastModified.AsTime().UTC().Format(http.TimeFormat))
+	}
+
+	if isNotModified(ctx.Req.Request, etag, lastModified) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+	ctx.JSON(status, obj)
+}
+
+// isNotModified evaluates the conditional headers of a GET request as
+// described in RFC 7232, entity tags are compared weakly.
+func isNotModified(req *http.Request, etag string, lastModified timeutil.TimeStamp) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+
+	if noneMatch := req.Header.Get("If-None-Match"); len(noneMatch) > 0 {
+		for _, tag := range strings.Split(noneMatch, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastModified == 0 {
+		return false
+	}
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	return err == nil && !lastModified.AsTime().After(since)
+}
+
 // RequireCSRF requires a validated a CSRF token
 func (ctx *APIContext) RequireCSRF() {
 	headerToken := ctx.Req.Header.Get(ctx.csrf.GetHeaderName())
@@ -212,7 +276,7 @@ func ReferencesGitRepo(allowEmpty bool) macaron.Handler {
 		// For API calls.
 		if ctx.Repo.GitRepo == nil {
 			repoPath := models.RepoPath(ctx.Repo.Owner.Name, ctx.Repo.Repository.Name)
-			gitRepo, err := git.OpenRepository(repoPath)
+			gitRepo, err := git.OpenRepositoryCtx(LogContext(ctx.Context.Context), repoPath)
 			if err != nil {
 				ctx.Error(500, "RepoRef Invalid repo "+repoPath, err)
 				return
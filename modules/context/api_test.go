@@ -5,11 +5,14 @@
 package context
 
 import (
+	"net/http"
 	"net/url"
 	"strconv"
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -49,3 +52,37 @@ func TestGenAPILinks(t *testing.T) {
 		assert.EqualValues(t, links, response)
 	}
 }
+
+func TestIsNotModified(t *testing.T) {
+	const etag = `W/"abc"`
+	lastModified := timeutil.TimeStamp(1577836800)
+
+	newRequest := func(method string, headers map[string]string) *http.Request {
+		req, err := http.NewRequest(method, "http://localhost:3000/api/v1/repos/search", nil)
+		assert.NoError(t, err)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+	httpTime := func(unix int64) string {
+		return time.Unix(unix, 0).UTC().Format(http.TimeFormat)
+	}
+
+	assert.False(t, isNotModified(newRequest("GET", nil), etag, lastModified))
+	assert.True(t, isNotModified(newRequest("GET", map[string]string{"If-None-Match": `W/"abc"`}), etag, lastModified))
+	assert.True(t, isNotModified(newRequest("GET", map[string]string{"If-None-Match": `"abc"`}), etag, lastModified))
+	assert.True(t, isNotModified(newRequest("GET", map[string]string{"If-None-Match": `"xyz", W/"abc"`}), etag, lastModified))
+	assert.True(t, isNotModified(newRequest("GET", map[string]string{"If-None-Match": "*"}), etag, lastModified))
+	assert.False(t, isNotModified(newRequest("GET", map[string]string{"If-None-Match": `"xyz"`}), etag, lastModified))
+	assert.False(t, isNotModified(newRequest("POST", map[string]string{"If-None-Match": etag}), etag, lastModified))
+
+	assert.True(t, isNotModified(newRequest("GET", map[string]string{"If-Modified-Since": httpTime(1577836800)}), etag, lastModified))
+	assert.False(t, isNotModified(newRequest("GET", map[string]string{"If-Modified-Since": httpTime(1577836799)}), etag, lastModified))
+	assert.False(t, isNotModified(newRequest("GET", map[string]string{"If-Modified-Since": httpTime(1577836800)}), etag, 0))
+	// If-None-Match takes precedence over If-Modified-Since
+	assert.False(t, isNotModified(newRequest("GET", map[string]string{
+		"If-None-Match":     `"xyz"`,
+		"If-Modified-Since": httpTime(1577836800),
+	}), etag, lastModified))
+}
@@ -157,7 +157,7 @@ func (ctx *Context) NotFound(title string, err error) {
 
 func (ctx *Context) notFoundInternal(title string, err error) {
 	if err != nil {
-		log.ErrorWithSkip(2, "%s: %v", title, err)
+		log.Ctx(LogContext(ctx.Context)).ErrorWithSkip(2, "%s: %v", title, err)
 		if macaron.Env != macaron.PROD {
 			ctx.Data["ErrorMsg"] = err
 		}
@@ -176,7 +176,7 @@ func (ctx *Context) ServerError(title string, err error) {
 
 func (ctx *Context) serverErrorInternal(title string, err error) {
 	if err != nil {
-		log.ErrorWithSkip(2, "%s: %v", title, err)
+		log.Ctx(LogContext(ctx.Context)).ErrorWithSkip(2, "%s: %v", title, err)
 		if macaron.Env != macaron.PROD {
 			ctx.Data["ErrorMsg"] = err
 		}
@@ -225,6 +225,16 @@ func (ctx *Context) ServeContent(name string, r io.ReadSeeker, params ...interfa
 	http.ServeContent(ctx.Resp, ctx.Req.Request, name, modtime, r)
 }
 
+// SetLocaleFormats sets the date and number formats of the page to the ones of an
+// organization or a repository, unless the signed user has chosen a language.
+func (ctx *Context) SetLocaleFormats(s *models.LocaleSetting) {
+	if s == nil || (ctx.IsSigned && ctx.User.Language != "") {
+		return
+	}
+	ctx.Data["DateFormat"] = s.GetDateFormat()
+	ctx.Data["NumberFormat"] = s.NumberFormat
+}
+
 // Contexter initializes a classic context for a request.
 func Contexter() macaron.Handler {
 	return func(c *macaron.Context, l i18n.Locale, cache cache.Cache, sess session.Store, f *session.Flash, x csrf.CSRF) {
@@ -241,6 +251,9 @@ func Contexter() macaron.Handler {
 			Org: &Organization{},
 		}
 		ctx.Data["Language"] = ctx.Locale.Language()
+		// the organization and repository pages may override the formats of the language
+		ctx.Data["DateFormat"] = ""
+		ctx.Data["NumberFormat"] = ""
 		c.Data["Link"] = ctx.Link
 		ctx.Data["CurrentURL"] = setting.AppSubURL + c.Req.URL.RequestURI()
 		ctx.Data["PageStartTime"] = time.Now()
@@ -342,6 +355,7 @@ func Contexter() macaron.Handler {
 
 		ctx.Data["EnableSwagger"] = setting.API.EnableSwagger
 		ctx.Data["EnableOpenIDSignIn"] = setting.Service.EnableOpenIDSignIn
+		ctx.Data["SnippetsEnabled"] = setting.Snippet.Enabled
 
 		c.Map(ctx)
 	}
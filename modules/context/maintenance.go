@@ -0,0 +1,84 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/maintenance"
+)
+
+const tplMaintenance base.TplName = "status/maintenance"
+
+// maintenanceAllowedPrefixes are the paths which accept writes in maintenance
+// mode, so that administrators can sign in and turn it off again and hooks can
+// decline pushes with a message
+var maintenanceAllowedPrefixes = []string{
+	"/admin",
+	"/api/v1/admin",
+	"/api/internal",
+	"/user/login",
+	"/user/logout",
+	"/user/two_factor",
+	"/user/u2f",
+}
+
+// maintenanceAllowedSuffixes are the git and LFS endpoints which only read
+// or whose writes are declined by the pre-receive hook
+var maintenanceAllowedSuffixes = []string{
+	"/git-upload-pack",
+	"/git-receive-pack",
+	"/info/lfs/objects/batch",
+}
+
+// isMaintenanceAllowed returns true if the path accepts writes in maintenance mode,
+// the prefixes only match whole path segments
+func isMaintenanceAllowed(path string) bool {
+	for _, prefix := range maintenanceAllowedPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	for _, suffix := range maintenanceAllowedSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckMaintenance declines requests which change data while the instance is
+// in maintenance mode and tells the user why
+func CheckMaintenance() func(ctx *Context) {
+	return func(ctx *Context) {
+		state := maintenance.GetState()
+		if !state.Enabled {
+			return
+		}
+		ctx.Data["MaintenanceMessage"] = state.Message
+
+		switch ctx.Req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return
+		}
+		path := ctx.Req.URL.Path
+		if isMaintenanceAllowed(path) {
+			return
+		}
+
+		ctx.Resp.Header().Set("Retry-After", "60")
+		if auth.IsAPIPath(path) {
+			ctx.JSON(http.StatusServiceUnavailable, map[string]string{
+				"message": state.Message,
+			})
+			return
+		}
+		ctx.Data["PageIsMaintenance"] = true
+		ctx.HTML(http.StatusServiceUnavailable, tplMaintenance)
+	}
+}
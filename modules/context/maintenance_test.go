@@ -0,0 +1,23 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMaintenanceAllowed(t *testing.T) {
+	assert.True(t, isMaintenanceAllowed("/admin"))
+	assert.True(t, isMaintenanceAllowed("/admin/config/maintenance"))
+	assert.True(t, isMaintenanceAllowed("/user/login"))
+	assert.True(t, isMaintenanceAllowed("/user2/repo1.git/git-receive-pack"))
+
+	assert.False(t, isMaintenanceAllowed("/adminXYZ"))
+	assert.False(t, isMaintenanceAllowed("/adminXYZ/repo1/settings"))
+	assert.False(t, isMaintenanceAllowed("/user/login2"))
+	assert.False(t, isMaintenanceAllowed("/user2/repo1/settings"))
+}
@@ -69,6 +69,13 @@ func HandleOrgAssignment(ctx *Context, args ...bool) {
 		return
 	}
 
+	localeSetting, err := models.GetOrgLocaleSetting(org.ID)
+	if err != nil {
+		ctx.ServerError("GetOrgLocaleSetting", err)
+		return
+	}
+	ctx.SetLocaleFormats(localeSetting)
+
 	// Admin has super access.
 	if ctx.IsSigned && ctx.User.IsAdmin {
 		ctx.Org.IsOwner = true
@@ -88,7 +88,7 @@ type CanCommitToBranchResults struct {
 // CanCommitToBranch returns true if repository is editable and user has proper access level
 //   and branch is not protected for push
 func (r *Repository) CanCommitToBranch(doer *models.User) (CanCommitToBranchResults, error) {
-	protectedBranch, err := models.GetProtectedBranchBy(r.Repository.ID, r.BranchName)
+	protectedBranch, err := models.GetEffectiveProtectedBranch(r.Repository, r.BranchName, doer.ID)
 
 	if err != nil {
 		return CanCommitToBranchResults{}, err
@@ -101,6 +101,11 @@ func (r *Repository) CanCommitToBranch(doer *models.User) (CanCommitToBranchResu
 	}
 
 	sign, keyID, err := r.Repository.SignCRUDAction(doer, r.Repository.RepoPath(), git.BranchPrefix+r.BranchName)
+	if err == nil && sign && requireSigned {
+		if err = protectedBranch.CheckSigningKey(keyID); err != nil {
+			sign = false
+		}
+	}
 
 	canCommit := r.CanEnableEditor() && userCanPush
 	if requireSigned {
@@ -448,6 +453,19 @@ func RepoAssignment() macaron.Handler {
 			return
 		}
 
+		ctx.Data["RepoCustomTabs"], err = models.GetRepoCustomTabs(ctx.Repo.Repository.ID)
+		if err != nil {
+			ctx.ServerError("GetRepoCustomTabs", err)
+			return
+		}
+
+		localeSetting, err := models.GetEffectiveLocaleSetting(repo)
+		if err != nil {
+			ctx.ServerError("GetEffectiveLocaleSetting", err)
+			return
+		}
+		ctx.SetLocaleFormats(localeSetting)
+
 		ctx.Data["Title"] = owner.Name + "/" + repo.Name
 		ctx.Data["Repository"] = repo
 		ctx.Data["Owner"] = ctx.Repo.Repository.Owner
@@ -495,7 +513,7 @@ func RepoAssignment() macaron.Handler {
 			return
 		}
 
-		gitRepo, err := git.OpenRepository(models.RepoPath(userName, repoName))
+		gitRepo, err := git.OpenRepositoryCtx(LogContext(ctx.Context), models.RepoPath(userName, repoName))
 		if err != nil {
 			ctx.ServerError("RepoAssignment Invalid repo "+models.RepoPath(userName, repoName), err)
 			return
@@ -693,7 +711,7 @@ func RepoRefByType(refType RepoRefType) macaron.Handler {
 		// For API calls.
 		if ctx.Repo.GitRepo == nil {
 			repoPath := models.RepoPath(ctx.Repo.Owner.Name, ctx.Repo.Repository.Name)
-			ctx.Repo.GitRepo, err = git.OpenRepository(repoPath)
+			ctx.Repo.GitRepo, err = git.OpenRepositoryCtx(LogContext(ctx.Context), repoPath)
 			if err != nil {
 				ctx.ServerError("RepoRef Invalid repo "+repoPath, err)
 				return
@@ -818,5 +836,6 @@ func UnitTypes() macaron.Handler {
 		ctx.Data["UnitTypeWiki"] = models.UnitTypeWiki
 		ctx.Data["UnitTypeExternalWiki"] = models.UnitTypeExternalWiki
 		ctx.Data["UnitTypeExternalTracker"] = models.UnitTypeExternalTracker
+		ctx.Data["UnitTypeDiscussions"] = models.UnitTypeDiscussions
 	}
 }
@@ -0,0 +1,40 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	stdctx "context"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/tracing"
+
+	"gitea.com/macaron/macaron"
+)
+
+// requestIDKey is the key of the ID of the request in the data of its context
+const requestIDKey = "RequestID"
+
+// SetRequestID stores the ID of the request. The request itself is left as is
+// because the handlers mapped by macaron and the callers share it.
+func SetRequestID(ctx *macaron.Context, id string) {
+	ctx.Data[requestIDKey] = id
+}
+
+// RequestID returns the ID of the request, empty if it has none
+func RequestID(ctx *macaron.Context) string {
+	id, _ := ctx.Data[requestIDKey].(string)
+	return id
+}
+
+// LogContext returns the context of the request carrying its ID and its span,
+// so that the events logged and the git commands run for the request can be
+// correlated.
+func LogContext(ctx *macaron.Context) stdctx.Context {
+	reqCtx := tracing.Context(ctx)
+	if id := RequestID(ctx); id != "" {
+		return log.ContextWithRequestID(reqCtx, id)
+	}
+	return reqCtx
+}
@@ -121,7 +121,9 @@ func ToBranchProtection(bp *models.ProtectedBranch) *api.BranchProtection {
 		BlockOnOutdatedBranch:       bp.BlockOnOutdatedBranch,
 		DismissStaleApprovals:       bp.DismissStaleApprovals,
 		RequireSignedCommits:        bp.RequireSignedCommits,
+		SignedCommitsAllowedKeys:    bp.SignedCommitsAllowedKeys,
 		ProtectedFilePatterns:       bp.ProtectedFilePatterns,
+		MergeStyles:                 bp.MergeStyles,
 		Created:                     bp.CreatedUnix.AsTime(),
 		Updated:                     bp.UpdatedUnix.AsTime(),
 	}
@@ -276,6 +278,31 @@ func ToHook(repoLink string, w *models.Webhook) *api.Hook {
 	}
 }
 
+// ToHookDelivery convert models.HookTask to api.HookDelivery
+func ToHookDelivery(t *models.HookTask) *api.HookDelivery {
+	d := &api.HookDelivery{
+		ID:          t.ID,
+		UUID:        t.UUID,
+		Event:       t.EventType.Event(),
+		IsDelivered: t.IsDelivered,
+		IsSucceed:   t.IsSucceed,
+		URL:         t.URL,
+	}
+	if t.IsDelivered {
+		delivered := time.Unix(0, t.Delivered)
+		d.Delivered = &delivered
+	}
+	if t.RequestInfo != nil {
+		d.RequestHeaders = t.RequestInfo.Headers
+	}
+	if t.ResponseInfo != nil {
+		d.StatusCode = t.ResponseInfo.Status
+		d.ResponseHeaders = t.ResponseInfo.Headers
+		d.ResponseBody = t.ResponseInfo.Body
+	}
+	return d
+}
+
 // ToGitHook convert git.Hook to api.GitHook
 func ToGitHook(h *git.Hook) *api.GitHook {
 	return &api.GitHook{
@@ -324,6 +351,62 @@ func ToTeam(team *models.Team) *api.Team {
 		CanCreateOrgRepo:        team.CanCreateOrgRepo,
 		Permission:              team.Authorize.String(),
 		Units:                   team.GetUnitNames(),
+		ReviewAssignAlgorithm:   team.ReviewAssignAlgorithm.String(),
+		ReviewAssignCount:       team.ReviewAssignCount,
+		ParentID:                team.ParentID,
+		RoleID:                  team.RoleID,
+	}
+}
+
+// ToOrgRole convert models.OrgRole to api.OrgRole
+func ToOrgRole(r *models.OrgRole) *api.OrgRole {
+	permissions := make(map[string]string, len(r.Permissions))
+	for _, p := range r.Permissions {
+		if unit, ok := models.Units[p.Type]; ok {
+			permissions[unit.NameKey] = p.AccessMode.String()
+		}
+	}
+	return &api.OrgRole{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: permissions,
+	}
+}
+
+// ToOrgBot convert models.OrgBot to api.OrgBot
+func ToOrgBot(b *models.OrgBot) *api.OrgBot {
+	return &api.OrgBot{
+		ID:            b.ID,
+		Bot:           ToUser(b.Bot, true, true),
+		Description:   b.Bot.Description,
+		MaxPermission: b.MaxAccessMode.String(),
+		Creator:       ToUser(b.Creator, true, false),
+		Created:       b.CreatedUnix.AsTime(),
+	}
+}
+
+// ToOrgMembership convert models.OrgUser to api.OrgMembership
+func ToOrgMembership(ou *models.OrgUser, user *models.User, signed, authed bool) *api.OrgMembership {
+	result := &api.OrgMembership{
+		User:   ToUser(user, signed, authed),
+		Public: ou.IsPublic,
+	}
+	if ou.ExpiresUnix != 0 {
+		result.ExpiresAt = ou.ExpiresUnix.AsTimePtr()
+	}
+	return result
+}
+
+// ToOrgExternalCollaborator convert models.ExternalCollaborator to api.OrgExternalCollaborator
+func ToOrgExternalCollaborator(ec *models.ExternalCollaborator, signed, authed bool) *api.OrgExternalCollaborator {
+	repos := make([]string, len(ec.Repos))
+	for i, repo := range ec.Repos {
+		repos[i] = repo.FullName()
+	}
+	return &api.OrgExternalCollaborator{
+		User:         ToUser(ec.User, signed, authed),
+		Repositories: repos,
 	}
 }
 
@@ -334,6 +417,7 @@ func ToUser(user *models.User, signed, authed bool) *api.User {
 		UserName:  user.Name,
 		AvatarURL: user.AvatarLink(),
 		FullName:  markup.Sanitize(user.FullName),
+		IsBot:     user.IsBot(),
 		Created:   user.CreatedUnix.AsTime(),
 	}
 	// hide primary email if API caller is anonymous or user keep email private
@@ -383,6 +467,24 @@ func ToCommitUser(sig *git.Signature) *api.CommitUser {
 	}
 }
 
+// ToCommitTrailers convert the trailers of a git.Commit to api.CommitTrailers
+func ToCommitTrailers(trailers []*git.CommitTrailer) []*api.CommitTrailer {
+	result := make([]*api.CommitTrailer, len(trailers))
+	for i, t := range trailers {
+		result[i] = &api.CommitTrailer{Key: t.Key, Value: t.Value}
+	}
+	return result
+}
+
+// ToIdentities convert git.Signatures to api.Identities
+func ToIdentities(sigs []*git.Signature) []*api.Identity {
+	result := make([]*api.Identity, len(sigs))
+	for i, sig := range sigs {
+		result[i] = &api.Identity{Name: sig.Name, Email: sig.Email}
+	}
+	return result
+}
+
 // ToCommitMeta convert a git.Tag to an api.CommitMeta
 func ToCommitMeta(repo *models.Repository, tag *git.Tag) *api.CommitMeta {
 	return &api.CommitMeta{
@@ -394,11 +496,25 @@ func ToCommitMeta(repo *models.Repository, tag *git.Tag) *api.CommitMeta {
 // ToTopicResponse convert from models.Topic to api.TopicResponse
 func ToTopicResponse(topic *models.Topic) *api.TopicResponse {
 	return &api.TopicResponse{
-		ID:        topic.ID,
-		Name:      topic.Name,
-		RepoCount: topic.RepoCount,
-		Created:   topic.CreatedUnix.AsTime(),
-		Updated:   topic.UpdatedUnix.AsTime(),
+		ID:          topic.ID,
+		Name:        topic.Name,
+		Description: topic.Description,
+		Logo:        topic.Logo,
+		Featured:    topic.IsFeatured,
+		RepoCount:   topic.RepoCount,
+		Created:     topic.CreatedUnix.AsTime(),
+		Updated:     topic.UpdatedUnix.AsTime(),
+	}
+}
+
+// ToOrgTopic convert from models.OrgTopic to api.OrgTopic
+func ToOrgTopic(ot *models.OrgTopic) *api.OrgTopic {
+	return &api.OrgTopic{
+		Topic:       ToTopicResponse(ot.Topic),
+		Description: ot.Description,
+		Logo:        ot.Logo,
+		Featured:    ot.IsFeatured,
+		RepoCount:   ot.NumRepos,
 	}
 }
 
@@ -413,3 +529,155 @@ func ToOAuth2Application(app *models.OAuth2Application) *api.OAuth2Application {
 		Created:      app.CreatedUnix.AsTime(),
 	}
 }
+
+// ToCustomEmoji convert from models.CustomEmoji to api.CustomEmoji
+func ToCustomEmoji(e *models.CustomEmoji) *api.CustomEmoji {
+	return &api.CustomEmoji{
+		Name:    e.Name,
+		URL:     e.HTMLURL(),
+		Created: e.CreatedUnix.AsTime(),
+	}
+}
+
+// ToTheme convert from models.Theme to api.Theme
+func ToTheme(t *models.Theme) *api.Theme {
+	return &api.Theme{
+		Name:        t.Name,
+		DisplayName: t.DisplayName,
+		Description: t.Description,
+		Version:     t.Version,
+		CSSURL:      t.CSSURL(),
+		Created:     t.CreatedUnix.AsTime(),
+		Updated:     t.UpdatedUnix.AsTime(),
+	}
+}
+
+// ToBadge convert from models.Badge to api.Badge
+func ToBadge(b *models.Badge) *api.Badge {
+	return &api.Badge{
+		ID:          b.ID,
+		Slug:        b.Slug,
+		Name:        b.Name,
+		Description: b.Description,
+		Icon:        b.Icon,
+		Type:        b.Type.String(),
+		Threshold:   b.Threshold,
+		IsBuiltin:   b.IsBuiltin,
+		Created:     b.CreatedUnix.AsTime(),
+	}
+}
+
+// ToStarList convert from models.StarList to api.StarList
+func ToStarList(list *models.StarList) *api.StarList {
+	return &api.StarList{
+		ID:          list.ID,
+		Name:        list.Name,
+		Description: list.Description,
+		Private:     list.IsPrivate,
+		NumRepos:    list.NumRepos,
+		Created:     list.CreatedUnix.AsTime(),
+		Updated:     list.UpdatedUnix.AsTime(),
+	}
+}
+
+// ToContributorStats convert models.RepoContributorStats to api.ContributorStats
+func ToContributorStats(stats *models.RepoContributorStats, signed, authed bool) *api.ContributorStats {
+	result := &api.ContributorStats{
+		Total:      stats.Total,
+		CoAuthored: stats.CoAuthored,
+		Weeks:      make([]*api.ContributorWeek, 0, len(stats.Weeks)),
+	}
+	if stats.User != nil {
+		result.Author = ToUser(stats.User, signed, authed)
+	} else {
+		result.Author = &api.User{FullName: markup.Sanitize(stats.Name)}
+		if signed {
+			result.Author.Email = stats.Email
+		}
+	}
+	for _, week := range stats.Weeks {
+		result.Weeks = append(result.Weeks, &api.ContributorWeek{
+			Week:      int64(week.Week),
+			Additions: week.Additions,
+			Deletions: week.Deletions,
+			Commits:   week.Commits,
+		})
+	}
+	return result
+}
+
+// ToTrendingRepository converts models.RepoTrending to api.TrendingRepository
+func ToTrendingRepository(trending *models.RepoTrending, mode models.AccessMode) *api.TrendingRepository {
+	return &api.TrendingRepository{
+		Repository: trending.Repo.APIFormat(mode),
+		Stars:      trending.Stars,
+		Forks:      trending.Forks,
+		Activity:   trending.Activity,
+		Score:      trending.Score,
+	}
+}
+
+// ToTrendingTopic converts models.TrendingTopic to api.TrendingTopic
+func ToTrendingTopic(topic *models.TrendingTopic) *api.TrendingTopic {
+	return &api.TrendingTopic{
+		Name:     topic.Name,
+		NumRepos: topic.NumRepos,
+		Score:    topic.Score,
+	}
+}
+
+// ToActivityContributor converts models.ActivityContributor to api.ActivityContributor
+func ToActivityContributor(contributor *models.ActivityContributor, signed, authed bool) *api.ActivityContributor {
+	return &api.ActivityContributor{
+		User:          ToUser(contributor.User, signed, authed),
+		Contributions: contributor.Contributions,
+	}
+}
+
+// ToDeployToken converts models.DeployToken to api.DeployToken
+func ToDeployToken(token *models.DeployToken) *api.DeployToken {
+	scopes := make([]string, len(token.Scopes))
+	for i, scope := range token.Scopes {
+		scopes[i] = string(scope)
+	}
+	apiToken := &api.DeployToken{
+		ID:      token.ID,
+		Name:    token.Name,
+		Scopes:  scopes,
+		Token:   token.Token,
+		Creator: ToUser(token.Creator, false, false),
+		Expires: token.ExpiresUnix.AsTime(),
+		Created: token.CreatedUnix.AsTime(),
+	}
+	if token.LastUsedUnix > 0 {
+		lastUsed := token.LastUsedUnix.AsTime()
+		apiToken.LastUsed = &lastUsed
+	}
+	return apiToken
+}
+
+// ToRepoCustomTab converts models.RepoCustomTab to api.RepoCustomTab
+func ToRepoCustomTab(tab *models.RepoCustomTab) *api.RepoCustomTab {
+	return &api.RepoCustomTab{
+		ID:      tab.ID,
+		Name:    tab.Name,
+		URL:     tab.URL,
+		Icon:    tab.Icon,
+		Sort:    tab.Sort,
+		Created: tab.CreatedUnix.AsTime(),
+		Updated: tab.UpdatedUnix.AsTime(),
+	}
+}
+
+// ToAutoLinkRule converts models.AutoLinkRule to api.AutoLinkRule
+func ToAutoLinkRule(rule *models.AutoLinkRule) *api.AutoLinkRule {
+	return &api.AutoLinkRule{
+		ID:      rule.ID,
+		Pattern: rule.Pattern,
+		URL:     rule.URL,
+		Sort:    rule.Sort,
+		OrgID:   rule.OrgID,
+		Created: rule.CreatedUnix.AsTime(),
+		Updated: rule.UpdatedUnix.AsTime(),
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/modules/devcontainer"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToDevEnvironment converts a dev environment to API format
+func ToDevEnvironment(env *devcontainer.Environment) *api.DevEnvironment {
+	result := &api.DevEnvironment{
+		Ref:       env.Ref,
+		CommitID:  env.CommitID,
+		Launchers: make([]*api.IDELauncher, 0, len(env.Launchers)),
+	}
+	if cfg := env.Config; cfg != nil {
+		result.DevContainer = &api.DevContainer{
+			Path:               cfg.FileName,
+			Name:               cfg.Name,
+			Image:              cfg.Image,
+			Dockerfile:         cfg.Dockerfile,
+			BuildContext:       cfg.BuildContext,
+			DockerComposeFiles: cfg.DockerComposeFiles,
+			Service:            cfg.Service,
+			WorkspaceFolder:    cfg.WorkspaceFolder,
+			RemoteUser:         cfg.RemoteUser,
+			Features:           cfg.Features,
+			ForwardPorts:       cfg.ForwardPorts,
+			PostCreateCommand:  cfg.PostCreateCommand,
+			Extensions:         cfg.Extensions,
+			ContainerEnv:       cfg.ContainerEnv,
+		}
+	}
+	for _, launcher := range env.Launchers {
+		result.Launchers = append(result.Launchers, &api.IDELauncher{
+			Name:        launcher.Name,
+			DisplayName: launcher.DisplayName,
+			URL:         launcher.URL,
+		})
+	}
+	return result
+}
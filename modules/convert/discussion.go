@@ -0,0 +1,61 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToDiscussionCategory converts DiscussionCategory to API format
+func ToDiscussionCategory(category *models.DiscussionCategory) *api.DiscussionCategory {
+	return &api.DiscussionCategory{
+		ID:             category.ID,
+		Name:           category.Name,
+		Description:    category.Description,
+		IsAnswerable:   category.IsAnswerable,
+		NumDiscussions: category.NumDiscussions,
+	}
+}
+
+// ToDiscussion converts Discussion to API format
+// it assumes the attributes of the discussion are loaded
+func ToDiscussion(d *models.Discussion) *api.Discussion {
+	apiDiscussion := &api.Discussion{
+		ID:       d.ID,
+		URL:      d.APIURL(),
+		HTMLURL:  d.HTMLURL(),
+		Index:    d.Index,
+		Poster:   d.Poster.APIFormat(),
+		Title:    d.Title,
+		Body:     d.Content,
+		Replies:  d.NumReplies,
+		Upvotes:  d.NumUpvotes,
+		AnswerID: d.AnswerID,
+		Created:  d.CreatedUnix.AsTime(),
+		Updated:  d.UpdatedUnix.AsTime(),
+	}
+	if d.Category != nil {
+		apiDiscussion.Category = ToDiscussionCategory(d.Category)
+	}
+	return apiDiscussion
+}
+
+// ToDiscussionReply converts DiscussionReply to API format
+func ToDiscussionReply(d *models.Discussion, r *models.DiscussionReply) *api.DiscussionReply {
+	if err := r.LoadPoster(); err != nil {
+		return &api.DiscussionReply{}
+	}
+	return &api.DiscussionReply{
+		ID:       r.ID,
+		ParentID: r.ParentID,
+		Poster:   r.Poster.APIFormat(),
+		Body:     r.Content,
+		Upvotes:  r.NumUpvotes,
+		IsAnswer: d.AnswerID == r.ID,
+		Created:  r.CreatedUnix.AsTime(),
+		Updated:  r.UpdatedUnix.AsTime(),
+	}
+}
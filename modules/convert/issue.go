@@ -60,6 +60,30 @@ func ToAPIIssue(issue *models.Issue) *api.Issue {
 		apiIssue.Milestone = ToAPIMilestone(issue.Milestone)
 	}
 
+	if err := issue.LoadWorkflowState(); err != nil {
+		return &api.Issue{}
+	}
+	if issue.WorkflowState != nil {
+		apiIssue.WorkflowState = ToWorkflowState(issue.WorkflowState)
+	}
+
+	if err := issue.LoadEpic(); err != nil {
+		return &api.Issue{}
+	}
+	if issue.Epic != nil {
+		apiIssue.Epic = ToAPIEpic(issue.Epic)
+	}
+
+	apiIssue.IsQuestion = issue.IsQuestion()
+	if apiIssue.IsQuestion {
+		if err := issue.LoadAnswerComment(); err != nil {
+			return &api.Issue{}
+		}
+		if issue.AnswerComment != nil {
+			apiIssue.AnswerComment = issue.AnswerComment.APIFormat()
+		}
+	}
+
 	if err := issue.LoadAssignees(); err != nil {
 		return &api.Issue{}
 	}
@@ -127,10 +151,13 @@ func ToTrackedTimeList(tl models.TrackedTimeList) api.TrackedTimeList {
 // ToLabel converts Label to API format
 func ToLabel(label *models.Label) *api.Label {
 	return &api.Label{
-		ID:          label.ID,
-		Name:        label.Name,
-		Color:       strings.TrimLeft(label.Color, "#"),
-		Description: label.Description,
+		ID:           label.ID,
+		Name:         label.Name,
+		Color:        strings.TrimLeft(label.Color, "#"),
+		Description:  label.Description,
+		IsQuestion:   label.IsQuestion,
+		SyncToRepos:  label.SyncToRepos,
+		SyncedFromID: label.SyncedFromID,
 	}
 }
 
@@ -143,6 +170,102 @@ func ToLabelList(labels []*models.Label) []*api.Label {
 	return result
 }
 
+// ToWorkflowState converts WorkflowState to API format
+func ToWorkflowState(state *models.WorkflowState) *api.WorkflowState {
+	return &api.WorkflowState{
+		ID:                 state.ID,
+		Name:               state.Name,
+		Description:        state.Description,
+		Color:              state.Color,
+		IsClosed:           state.IsClosed,
+		Sort:               state.Sort,
+		AllowedTransitions: state.AllowedTransitions,
+	}
+}
+
+// ToWorkflowStateList converts list of WorkflowState to API format
+func ToWorkflowStateList(states []*models.WorkflowState) []*api.WorkflowState {
+	result := make([]*api.WorkflowState, len(states))
+	for i := range states {
+		result[i] = ToWorkflowState(states[i])
+	}
+	return result
+}
+
+// ToAPIEpic converts Epic into API Format
+func ToAPIEpic(epic *models.Epic) *api.Epic {
+	apiEpic := &api.Epic{
+		ID:           epic.ID,
+		Title:        epic.Name,
+		Description:  epic.Content,
+		State:        epic.State(),
+		RepoID:       epic.RepoID,
+		OwnerID:      epic.OwnerID,
+		OpenIssues:   epic.NumOpenIssues,
+		ClosedIssues: epic.NumClosedIssues,
+		Completeness: epic.Completeness,
+		Created:      epic.CreatedUnix.AsTime(),
+		Updated:      epic.UpdatedUnix.AsTime(),
+	}
+	if epic.IsClosed {
+		apiEpic.Closed = epic.ClosedDateUnix.AsTimePtr()
+	}
+	return apiEpic
+}
+
+// ToAPIProject converts Project into API Format
+func ToAPIProject(p *models.Project) *api.Project {
+	apiProject := &api.Project{
+		ID:          p.ID,
+		Title:       p.Title,
+		Description: p.Description,
+		State:       p.State(),
+		OwnerID:     p.OwnerID,
+		Created:     p.CreatedUnix.AsTime(),
+		Updated:     p.UpdatedUnix.AsTime(),
+
+		InProgressBoardID:    p.InProgressBoardID,
+		DoneBoardID:          p.DoneBoardID,
+		ArchiveDoneAfterDays: p.ArchiveDoneAfterDays,
+	}
+	if p.IsClosed {
+		apiProject.Closed = p.ClosedDateUnix.AsTimePtr()
+	}
+	return apiProject
+}
+
+// ToAPIProjectBoard converts ProjectBoard into API Format
+func ToAPIProjectBoard(board *models.ProjectBoard) *api.ProjectBoard {
+	return &api.ProjectBoard{
+		ID:        board.ID,
+		ProjectID: board.ProjectID,
+		Title:     board.Title,
+		Sorting:   board.Sorting,
+	}
+}
+
+// ToAPIProjectIssue converts ProjectIssue into API Format, its issue has to
+// be loaded
+func ToAPIProjectIssue(pi *models.ProjectIssue) *api.ProjectIssue {
+	return &api.ProjectIssue{
+		ProjectID: pi.ProjectID,
+		BoardID:   pi.ProjectBoardID,
+		Issue:     ToAPIIssue(pi.Issue),
+	}
+}
+
+// ToAPIProjectRule converts ProjectRule into API Format
+func ToAPIProjectRule(rule *models.ProjectRule) *api.ProjectRule {
+	return &api.ProjectRule{
+		ID:        rule.ID,
+		ProjectID: rule.ProjectID,
+		BoardID:   rule.ProjectBoardID,
+		RepoID:    rule.RepoID,
+		Label:     rule.Label,
+		Keyword:   rule.Keyword,
+	}
+}
+
 // ToAPIMilestone converts Milestone into API Format
 func ToAPIMilestone(m *models.Milestone) *api.Milestone {
 	apiMilestone := &api.Milestone{
@@ -161,3 +284,37 @@ func ToAPIMilestone(m *models.Milestone) *api.Milestone {
 	}
 	return apiMilestone
 }
+
+// ToIssueGraph converts an IssueGraph to API format
+func ToIssueGraph(graph *models.IssueGraph) *api.IssueGraph {
+	result := &api.IssueGraph{
+		Root:      graph.Issue.ID,
+		Nodes:     make([]*api.IssueGraphNode, 0, len(graph.Issues)),
+		Edges:     make([]*api.IssueGraphEdge, 0, len(graph.Edges)),
+		Truncated: graph.Truncated,
+	}
+	for _, issue := range graph.Issues {
+		result.Nodes = append(result.Nodes, &api.IssueGraphNode{
+			ID:     issue.ID,
+			Index:  issue.Index,
+			Title:  issue.Title,
+			State:  issue.State(),
+			IsPull: issue.IsPull,
+			Repository: &api.RepositoryMeta{
+				ID:       issue.Repo.ID,
+				Name:     issue.Repo.Name,
+				Owner:    issue.Repo.OwnerName,
+				FullName: issue.Repo.FullName(),
+			},
+			HTMLURL: issue.HTMLURL(),
+		})
+	}
+	for _, edge := range graph.Edges {
+		result.Edges = append(result.Edges, &api.IssueGraphEdge{
+			From: edge.FromID,
+			To:   edge.ToID,
+			Type: string(edge.Type),
+		})
+	}
+	return result
+}
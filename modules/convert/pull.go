@@ -10,6 +10,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/pulltemplate"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	api "code.gitea.io/gitea/modules/structs"
 )
@@ -42,27 +43,30 @@ func ToAPIPullRequest(pr *models.PullRequest) *api.PullRequest {
 	}
 
 	apiPullRequest := &api.PullRequest{
-		ID:        pr.ID,
-		URL:       pr.Issue.HTMLURL(),
-		Index:     pr.Index,
-		Poster:    apiIssue.Poster,
-		Title:     apiIssue.Title,
-		Body:      apiIssue.Body,
-		Labels:    apiIssue.Labels,
-		Milestone: apiIssue.Milestone,
-		Assignee:  apiIssue.Assignee,
-		Assignees: apiIssue.Assignees,
-		State:     apiIssue.State,
-		IsLocked:  apiIssue.IsLocked,
-		Comments:  apiIssue.Comments,
-		HTMLURL:   pr.Issue.HTMLURL(),
-		DiffURL:   pr.Issue.DiffURL(),
-		PatchURL:  pr.Issue.PatchURL(),
-		HasMerged: pr.HasMerged,
-		MergeBase: pr.MergeBase,
-		Deadline:  apiIssue.Deadline,
-		Created:   pr.Issue.CreatedUnix.AsTimePtr(),
-		Updated:   pr.Issue.UpdatedUnix.AsTimePtr(),
+		ID:           pr.ID,
+		URL:          pr.Issue.HTMLURL(),
+		Index:        pr.Index,
+		Poster:       apiIssue.Poster,
+		Title:        apiIssue.Title,
+		Body:         apiIssue.Body,
+		Labels:       apiIssue.Labels,
+		Milestone:    apiIssue.Milestone,
+		Assignee:     apiIssue.Assignee,
+		Assignees:    apiIssue.Assignees,
+		State:        apiIssue.State,
+		IsLocked:     apiIssue.IsLocked,
+		Comments:     apiIssue.Comments,
+		HTMLURL:      pr.Issue.HTMLURL(),
+		DiffURL:      pr.Issue.DiffURL(),
+		PatchURL:     pr.Issue.PatchURL(),
+		HasMerged:    pr.HasMerged,
+		MergeBase:    pr.MergeBase,
+		Size:         string(pr.Size()),
+		ChangedFiles: pr.NumChangedFiles,
+		ChangedLines: pr.NumChangedLines,
+		Deadline:     apiIssue.Deadline,
+		Created:      pr.Issue.CreatedUnix.AsTimePtr(),
+		Updated:      pr.Issue.UpdatedUnix.AsTimePtr(),
 
 		Base: &api.PRBranchInfo{
 			Name:       pr.BaseBranch,
@@ -146,3 +150,15 @@ func ToAPIPullRequest(pr *models.PullRequest) *api.PullRequest {
 
 	return apiPullRequest
 }
+
+// ToPullRequestTemplate converts a pull request description template to API format
+func ToPullRequestTemplate(tmpl *pulltemplate.Template) *api.PullRequestTemplate {
+	return &api.PullRequestTemplate{
+		FileName: tmpl.FileName,
+		Name:     tmpl.Name,
+		About:    tmpl.About,
+		Branches: tmpl.Branches,
+		Labels:   tmpl.Labels,
+		Content:  tmpl.Content,
+	}
+}
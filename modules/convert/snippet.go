@@ -0,0 +1,50 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToSnippet converts Snippet to API format without its files
+// it assumes the owner of the snippet is loaded
+func ToSnippet(s *models.Snippet) *api.Snippet {
+	return &api.Snippet{
+		ID:          s.ID,
+		URL:         s.APIURL(),
+		HTMLURL:     s.HTMLURL(),
+		Owner:       s.Owner.APIFormat(),
+		Title:       s.Title,
+		Description: s.Description,
+		Visibility:  s.Visibility.String(),
+		Comments:    s.NumComments,
+		Created:     s.CreatedUnix.AsTime(),
+		Updated:     s.UpdatedUnix.AsTime(),
+	}
+}
+
+// ToSnippetRevision converts a commit of the git repository of a snippet to API format
+func ToSnippetRevision(commit *git.Commit) *api.SnippetRevision {
+	return &api.SnippetRevision{
+		SHA:     commit.ID.String(),
+		Author:  ToCommitUser(commit.Author),
+		Message: commit.Summary(),
+	}
+}
+
+// ToSnippetComment converts SnippetComment to API format
+// it assumes the poster of the comment is loaded
+func ToSnippetComment(s *models.Snippet, c *models.SnippetComment) *api.SnippetComment {
+	return &api.SnippetComment{
+		ID:      c.ID,
+		HTMLURL: s.HTMLURL() + "#" + c.HashTag(),
+		Poster:  c.Poster.APIFormat(),
+		Body:    c.Content,
+		Created: c.CreatedUnix.AsTime(),
+		Updated: c.UpdatedUnix.AsTime(),
+	}
+}
@@ -53,6 +53,11 @@ type TaskTableRow struct {
 	Next      time.Time
 	Prev      time.Time
 	ExecTimes int64
+	// IsRunning is true while the task is executed
+	IsRunning bool
+	// Status is the outcome of the last run, empty if the task did not run yet
+	Status    string
+	LastError string
 }
 
 // TaskTable represents a table of tasks
@@ -86,6 +91,9 @@ func ListTasks() TaskTable {
 			Next:      next,
 			Prev:      prev,
 			ExecTimes: task.ExecTimes,
+			IsRunning: taskStatusTable.IsRunning(task.Name),
+			Status:    task.status,
+			LastError: task.lastError,
 		})
 		task.lock.Unlock()
 	}
@@ -11,6 +11,7 @@ import (
 	"sync"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cluster"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/process"
@@ -29,8 +30,17 @@ type Task struct {
 	config    Config
 	fun       func(context.Context, *models.User, Config) error
 	ExecTimes int64
+	status    string
+	lastError string
 }
 
+// Status values of the last run of a task
+const (
+	TaskStatusFinished = "finished"
+	TaskStatusAborted  = "aborted"
+	TaskStatusError    = "error"
+)
+
 // DoRunAtStart returns if this task should run at the start
 func (t *Task) DoRunAtStart() bool {
 	return t.config.DoRunAtStart()
@@ -51,8 +61,13 @@ func (t *Task) GetConfig() Config {
 	return reflect.New(reflect.TypeOf(t.config)).Elem().Interface().(Config)
 }
 
-// Run will run the task incrementing the cron counter with no user defined
+// Run will run the task incrementing the cron counter with no user defined.
+// In a cluster only the leader runs scheduled tasks.
 func (t *Task) Run() {
+	if !cluster.IsLeader() {
+		log.Trace("Not running %s as this node is not the cluster leader", t.Name)
+		return
+	}
 	t.RunWithUser(&models.User{
 		ID:        -1,
 		Name:      "(Cron)",
@@ -77,6 +92,7 @@ func (t *Task) RunWithUser(doer *models.User, config Config) {
 			// Recover a panic within the
 			combinedErr := fmt.Errorf("%s\n%s", err, log.Stack(2))
 			log.Error("PANIC whilst running task: %s Value: %v", t.Name, combinedErr)
+			t.setStatus(TaskStatusError, fmt.Sprint(err))
 		}
 	}()
 	graceful.GetManager().RunWithShutdownContext(func(baseCtx context.Context) {
@@ -88,22 +104,32 @@ func (t *Task) RunWithUser(doer *models.User, config Config) {
 		if err := t.fun(ctx, doer, config); err != nil {
 			if models.IsErrCancelled(err) {
 				message := err.(models.ErrCancelled).Message
+				t.setStatus(TaskStatusAborted, message)
 				if err := models.CreateNotice(models.NoticeTask, config.FormatMessage(t.Name, "aborted", doer, message)); err != nil {
 					log.Error("CreateNotice: %v", err)
 				}
 				return
 			}
+			t.setStatus(TaskStatusError, err.Error())
 			if err := models.CreateNotice(models.NoticeTask, config.FormatMessage(t.Name, "error", doer, err)); err != nil {
 				log.Error("CreateNotice: %v", err)
 			}
 			return
 		}
+		t.setStatus(TaskStatusFinished, "")
 		if err := models.CreateNotice(models.NoticeTask, config.FormatMessage(t.Name, "finished", doer)); err != nil {
 			log.Error("CreateNotice: %v", err)
 		}
 	})
 }
 
+func (t *Task) setStatus(status, lastError string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.status = status
+	t.lastError = lastError
+}
+
 // GetTask gets the named task
 func GetTask(name string) *Task {
 	lock.Lock()
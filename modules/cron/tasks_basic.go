@@ -10,8 +10,12 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/migrations"
+	"code.gitea.io/gitea/modules/packcache"
 	repository_service "code.gitea.io/gitea/modules/repository"
+	attachment_service "code.gitea.io/gitea/services/attachment"
+	"code.gitea.io/gitea/services/mailer"
 	mirror_service "code.gitea.io/gitea/services/mirror"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 func registerUpdateMirrorTask() {
@@ -107,6 +111,110 @@ func registerUpdateMigrationPosterID() {
 	})
 }
 
+func registerAwardBadges() {
+	RegisterTaskFatal("award_badges", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 24h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return models.AwardAutomaticBadges(ctx)
+	})
+}
+
+func registerRemoveExpiredOrgMembers() {
+	RegisterTaskFatal("remove_expired_org_members", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 1h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return models.RemoveExpiredOrgUsers(ctx)
+	})
+}
+
+func registerArchiveDoneProjectIssues() {
+	RegisterTaskFatal("archive_done_project_issues", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 24h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return models.ArchiveDoneProjectIssues(ctx)
+	})
+}
+
+func registerUpdateRepoTrending() {
+	RegisterTaskFatal("update_repo_trending", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 6h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return models.UpdateRepoTrending(ctx)
+	})
+}
+
+func registerApplyScheduledVisibilityChanges() {
+	RegisterTaskFatal("apply_scheduled_visibility_changes", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 5m",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return repo_service.ApplyScheduledVisibilityChanges(ctx)
+	})
+}
+
+func registerDeleteExpiredPackCache() {
+	RegisterTaskFatal("delete_expired_pack_cache", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 1h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return packcache.DeleteExpired(ctx)
+	})
+}
+
+func registerDeleteOldGitTransportStats() {
+	RegisterTaskFatal("delete_old_git_transport_stats", &OlderThanConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@midnight",
+		},
+		OlderThan: 90 * 24 * time.Hour,
+	}, func(ctx context.Context, _ *models.User, config Config) error {
+		olderThanConfig := config.(*OlderThanConfig)
+		return models.DeleteOldGitTransportStats(ctx, olderThanConfig.OlderThan)
+	})
+}
+
+func registerProcessMailBounces() {
+	RegisterTaskFatal("process_mail_bounces", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 10m",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return mailer.ProcessBounceMailbox(ctx)
+	})
+}
+
+func registerDeleteExpiredIssueAttachments() {
+	RegisterTaskFatal("delete_expired_issue_attachments", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@midnight",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return attachment_service.DeleteExpiredIssueAttachments(ctx)
+	})
+}
+
+func registerDeleteExpiredDeployTokens() {
+	RegisterTaskFatal("delete_expired_deploy_tokens", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 1h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return models.DeleteExpiredDeployTokens(ctx)
+	})
+}
+
 func initBasicTasks() {
 	registerUpdateMirrorTask()
 	registerRepoHealthCheck()
@@ -115,4 +223,14 @@ func initBasicTasks() {
 	registerSyncExternalUsers()
 	registerDeletedBranchesCleanup()
 	registerUpdateMigrationPosterID()
+	registerAwardBadges()
+	registerRemoveExpiredOrgMembers()
+	registerArchiveDoneProjectIssues()
+	registerUpdateRepoTrending()
+	registerApplyScheduledVisibilityChanges()
+	registerDeleteExpiredPackCache()
+	registerDeleteOldGitTransportStats()
+	registerProcessMailBounces()
+	registerDeleteExpiredIssueAttachments()
+	registerDeleteExpiredDeployTokens()
 }
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 )
@@ -107,6 +108,16 @@ func registerRemoveRandomAvatars() {
 	})
 }
 
+func registerCheckIssueIndexer() {
+	RegisterTaskFatal("check_issue_indexer", &BaseConfig{
+		Enabled:    false,
+		RunAtStart: false,
+		Schedule:   "@every 72h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return issue_indexer.CheckIssueIndexer(ctx)
+	})
+}
+
 func initExtendedTasks() {
 	registerDeleteInactiveUsers()
 	registerDeleteRepositoryArchives()
@@ -116,4 +127,5 @@ func initExtendedTasks() {
 	registerReinitMissingRepositories()
 	registerDeleteMissingRepositories()
 	registerRemoveRandomAvatars()
+	registerCheckIssueIndexer()
 }
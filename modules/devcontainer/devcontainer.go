@@ -0,0 +1,311 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package devcontainer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// FilePaths are the paths of the dev container configuration of a repository, the first
+// existing one is used.
+var FilePaths = []string{
+	".devcontainer/devcontainer.json",
+	".devcontainer.json",
+}
+
+// MaxSize is the maximum size of a dev container configuration in bytes, larger ones are skipped.
+const MaxSize = 256 * 1024
+
+// Config is the part of a dev container configuration describing the dev environment, see
+// https://containers.dev/implementors/json_reference/
+type Config struct {
+	FileName           string
+	Name               string
+	Image              string
+	Dockerfile         string
+	BuildContext       string
+	DockerComposeFiles []string
+	Service            string
+	WorkspaceFolder    string
+	RemoteUser         string
+	Features           []string
+	ForwardPorts       []string
+	PostCreateCommand  string
+	Extensions         []string
+	ContainerEnv       map[string]string
+}
+
+type buildConfig struct {
+	Dockerfile string `json:"dockerfile"`
+	Context    string `json:"context"`
+}
+
+type config struct {
+	Name              string                     `json:"name"`
+	Image             string                     `json:"image"`
+	Dockerfile        string                     `json:"dockerFile"`
+	Context           string                     `json:"context"`
+	Build             *buildConfig               `json:"build"`
+	DockerComposeFile json.RawMessage            `json:"dockerComposeFile"`
+	Service           string                     `json:"service"`
+	WorkspaceFolder   string                     `json:"workspaceFolder"`
+	RemoteUser        string                     `json:"remoteUser"`
+	Features          map[string]json.RawMessage `json:"features"`
+	ForwardPorts      []json.RawMessage          `json:"forwardPorts"`
+	PostCreateCommand json.RawMessage            `json:"postCreateCommand"`
+	Extensions        []string                   `json:"extensions"`
+	Customizations    struct {
+		VSCode struct {
+			Extensions []string `json:"extensions"`
+		} `json:"vscode"`
+	} `json:"customizations"`
+	ContainerEnv map[string]string `json:"containerEnv"`
+}
+
+// stripJSONC removes the comments and trailing commas a dev container configuration may
+// contain, which the JSON decoder does not accept.
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			i++
+			out = append(out, ' ')
+		case c == '}' || c == ']':
+			// drop a trailing comma before the closing bracket
+			j := len(out) - 1
+			for j >= 0 && strings.IndexByte(" \t\r\n", out[j]) >= 0 {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// stringOrList decodes a value which is either a string or a list of strings.
+func stringOrList(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []string{s}, nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Parse parses a dev container configuration, which may contain comments and trailing commas.
+func Parse(fileName string, data []byte) (*Config, error) {
+	var raw config
+	if err := json.Unmarshal(stripJSONC(data), &raw); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+
+	cfg := &Config{
+		FileName:        fileName,
+		Name:            strings.TrimSpace(raw.Name),
+		Image:           raw.Image,
+		Dockerfile:      raw.Dockerfile,
+		BuildContext:    raw.Context,
+		Service:         raw.Service,
+		WorkspaceFolder: raw.WorkspaceFolder,
+		RemoteUser:      raw.RemoteUser,
+		ContainerEnv:    raw.ContainerEnv,
+	}
+	if raw.Build != nil {
+		if raw.Build.Dockerfile != "" {
+			cfg.Dockerfile = raw.Build.Dockerfile
+		}
+		if raw.Build.Context != "" {
+			cfg.BuildContext = raw.Build.Context
+		}
+	}
+
+	var err error
+	if cfg.DockerComposeFiles, err = stringOrList(raw.DockerComposeFile); err != nil {
+		return nil, fmt.Errorf("%s: dockerComposeFile: %v", fileName, err)
+	}
+
+	for feature := range raw.Features {
+		cfg.Features = append(cfg.Features, feature)
+	}
+	sort.Strings(cfg.Features)
+
+	// ports are either numbers or "host:port" strings
+	for _, port := range raw.ForwardPorts {
+		var number int
+		if err := json.Unmarshal(port, &number); err == nil {
+			cfg.ForwardPorts = append(cfg.ForwardPorts, strconv.Itoa(number))
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(port, &s); err != nil {
+			return nil, fmt.Errorf("%s: forwardPorts: %v", fileName, err)
+		}
+		cfg.ForwardPorts = append(cfg.ForwardPorts, s)
+	}
+
+	// the command is either a shell command or the arguments of a command
+	command, err := stringOrList(raw.PostCreateCommand)
+	if err != nil {
+		// commands may also be an object of commands run in parallel
+		var parallel map[string]json.RawMessage
+		if json.Unmarshal(raw.PostCreateCommand, &parallel) != nil {
+			return nil, fmt.Errorf("%s: postCreateCommand: %v", fileName, err)
+		}
+		names := make([]string, 0, len(parallel))
+		for name := range parallel {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			args, err := stringOrList(parallel[name])
+			if err != nil {
+				return nil, fmt.Errorf("%s: postCreateCommand: %v", fileName, err)
+			}
+			command = append(command, strings.Join(args, " "))
+		}
+		cfg.PostCreateCommand = strings.Join(command, " & ")
+	} else {
+		cfg.PostCreateCommand = strings.Join(command, " ")
+	}
+
+	cfg.Extensions = append(raw.Customizations.VSCode.Extensions, raw.Extensions...)
+	return cfg, nil
+}
+
+// Load returns the dev container configuration of a commit, or nil if there is none.
+func Load(commit *git.Commit) (*Config, error) {
+	for _, fileName := range FilePaths {
+		entry, err := commit.GetTreeEntryByPath(fileName)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if !entry.IsRegular() {
+			continue
+		}
+		if entry.Blob().Size() > MaxSize {
+			return nil, fmt.Errorf("%s is larger than %d bytes", fileName, MaxSize)
+		}
+		rc, err := entry.Blob().DataAsync()
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		return Parse(fileName, data)
+	}
+	return nil, nil
+}
+
+// Environment is the dev environment of a commit of a repository.
+type Environment struct {
+	Ref       string
+	CommitID  string
+	Config    *Config
+	Launchers []*Launcher
+}
+
+// LaunchContext is what a dev environment is opened from.
+type LaunchContext struct {
+	Owner    string
+	Repo     string
+	Ref      string
+	CloneURL string
+	SSHURL   string
+	WebURL   string
+}
+
+// Launcher is a link opening a dev environment in an IDE.
+type Launcher struct {
+	Name        string
+	DisplayName string
+	URL         string
+}
+
+// Launchers returns the links of the configured IDE integrations for the context, the ones
+// requiring a dev container configuration are skipped if the repository has none.
+func Launchers(ctx *LaunchContext, hasDevContainer bool) []*Launcher {
+	if !setting.IDE.Enabled {
+		return nil
+	}
+	values := map[string]string{
+		"owner":     ctx.Owner,
+		"repo":      ctx.Repo,
+		"ref":       ctx.Ref,
+		"clone_url": ctx.CloneURL,
+		"ssh_url":   ctx.SSHURL,
+		"web_url":   ctx.WebURL,
+	}
+	pairs := make([]string, 0, 4*len(values))
+	for key, value := range values {
+		pairs = append(pairs, "{"+key+"}", value, "{"+key+"_escaped}", url.QueryEscape(value))
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	launchers := make([]*Launcher, 0, len(setting.IDE.Integrations))
+	for _, integration := range setting.IDE.Integrations {
+		if integration.RequireDevContainer && !hasDevContainer {
+			continue
+		}
+		launchers = append(launchers, &Launcher{
+			Name:        integration.Name,
+			DisplayName: integration.DisplayName,
+			URL:         replacer.Replace(integration.URL),
+		})
+	}
+	return launchers
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package devcontainer
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse(".devcontainer/devcontainer.json", []byte(`{
+	// the name of the dev container
+	"name": "Go // API",
+	"build": {"dockerfile": "Dockerfile", "context": ".."},
+	/* the features
+	   installed in the container */
+	"features": {"ghcr.io/devcontainers/features/node:1": {}, "ghcr.io/devcontainers/features/go:1": {"version": "1.15"}},
+	"forwardPorts": [3000, "db:5432",],
+	"postCreateCommand": ["make", "deps"],
+	"customizations": {"vscode": {"extensions": ["golang.go"]}},
+	"extensions": ["dbaeumer.vscode-eslint"],
+	"containerEnv": {"GOFLAGS": "-mod=mod"},
+}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Go // API", cfg.Name)
+	assert.Equal(t, "Dockerfile", cfg.Dockerfile)
+	assert.Equal(t, "..", cfg.BuildContext)
+	assert.Equal(t, []string{"ghcr.io/devcontainers/features/go:1", "ghcr.io/devcontainers/features/node:1"}, cfg.Features)
+	assert.Equal(t, []string{"3000", "db:5432"}, cfg.ForwardPorts)
+	assert.Equal(t, "make deps", cfg.PostCreateCommand)
+	assert.Equal(t, []string{"golang.go", "dbaeumer.vscode-eslint"}, cfg.Extensions)
+	assert.Equal(t, map[string]string{"GOFLAGS": "-mod=mod"}, cfg.ContainerEnv)
+
+	cfg, err = Parse(".devcontainer.json", []byte(`{"dockerComposeFile": "docker-compose.yml", "service": "app", "postCreateCommand": {"server": "make", "web": ["npm", "ci"]}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docker-compose.yml"}, cfg.DockerComposeFiles)
+	assert.Equal(t, "app", cfg.Service)
+	assert.Equal(t, "make & npm ci", cfg.PostCreateCommand)
+
+	_, err = Parse(".devcontainer.json", []byte(`{"name": "broken"`))
+	assert.Error(t, err)
+	_, err = Parse(".devcontainer.json", []byte(`{"forwardPorts": [{}]}`))
+	assert.Error(t, err)
+}
+
+func TestLaunchers(t *testing.T) {
+	defer func(enabled bool, integrations []*setting.IDEIntegration) {
+		setting.IDE.Enabled = enabled
+		setting.IDE.Integrations = integrations
+	}(setting.IDE.Enabled, setting.IDE.Integrations)
+
+	ctx := &LaunchContext{
+		Owner:    "user2",
+		Repo:     "repo1",
+		Ref:      "master",
+		CloneURL: "https://try.gitea.io/user2/repo1.git",
+		WebURL:   "https://try.gitea.io/user2/repo1/src/branch/master",
+	}
+	setting.IDE.Enabled = false
+	assert.Empty(t, Launchers(ctx, true))
+
+	setting.IDE.Enabled = true
+	setting.IDE.Integrations = []*setting.IDEIntegration{
+		{Name: "gitpod", DisplayName: "Gitpod", URL: "https://gitpod.io/#{web_url}"},
+		{Name: "vscode", DisplayName: "VS Code", URL: "vscode://ms-vscode-remote.remote-containers/cloneInVolume?url={clone_url_escaped}", RequireDevContainer: true},
+	}
+	launchers := Launchers(ctx, false)
+	if assert.Len(t, launchers, 1) {
+		assert.Equal(t, "https://gitpod.io/#https://try.gitea.io/user2/repo1/src/branch/master", launchers[0].URL)
+	}
+	launchers = Launchers(ctx, true)
+	if assert.Len(t, launchers, 2) {
+		assert.Equal(t, "VS Code", launchers[1].DisplayName)
+		assert.Equal(t, "vscode://ms-vscode-remote.remote-containers/cloneInVolume?url=https%3A%2F%2Ftry.gitea.io%2Fuser2%2Frepo1.git", launchers[1].URL)
+	}
+}
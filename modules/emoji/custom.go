@@ -0,0 +1,77 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package emoji
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	// customMap provides a map of the custom emoji names to their image URLs.
+	customMap   = make(map[string]string)
+	customMutex sync.RWMutex
+)
+
+// SetCustom replaces all registered custom emoji.
+func SetCustom(emojis map[string]string) {
+	customMutex.Lock()
+	defer customMutex.Unlock()
+
+	customMap = make(map[string]string, len(emojis))
+	for name, url := range emojis {
+		customMap[name] = url
+	}
+}
+
+// AddCustom registers a custom emoji with the URL of its image.
+func AddCustom(name, url string) {
+	customMutex.Lock()
+	defer customMutex.Unlock()
+
+	customMap[name] = url
+}
+
+// RemoveCustom unregisters a custom emoji.
+func RemoveCustom(name string) {
+	customMutex.Lock()
+	defer customMutex.Unlock()
+
+	delete(customMap, name)
+}
+
+// FromCustom returns the image URL of a custom emoji.
+func FromCustom(name string) (string, bool) {
+	customMutex.RLock()
+	defer customMutex.RUnlock()
+
+	url, ok := customMap[name]
+	return url, ok
+}
+
+// CustomNames returns the sorted names of all custom emoji.
+func CustomNames() []string {
+	customMutex.RLock()
+	defer customMutex.RUnlock()
+
+	names := make([]string, 0, len(customMap))
+	for name := range customMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Custom returns a copy of the registered custom emoji names and image URLs.
+func Custom() map[string]string {
+	customMutex.RLock()
+	defer customMutex.RUnlock()
+
+	emojis := make(map[string]string, len(customMap))
+	for name, url := range customMap {
+		emojis[name] = url
+	}
+	return emojis
+}
@@ -65,3 +65,23 @@ func TestReplacers(t *testing.T) {
 		}
 	}
 }
+
+func TestCustom(t *testing.T) {
+	SetCustom(map[string]string{"parrot": "/custom-emoji/parrot.gif"})
+	AddCustom("blob", "/custom-emoji/blob.png")
+	if url, ok := FromCustom("blob"); !ok || url != "/custom-emoji/blob.png" {
+		t.Errorf("blob should be a custom emoji, got %q", url)
+	}
+	if names := CustomNames(); !reflect.DeepEqual(names, []string{"blob", "parrot"}) {
+		t.Errorf("unexpected custom emoji names %v", names)
+	}
+
+	RemoveCustom("parrot")
+	if _, ok := FromCustom("parrot"); ok {
+		t.Errorf("parrot should have been removed")
+	}
+	if emojis := Custom(); len(emojis) != 1 {
+		t.Errorf("expected one custom emoji, got %v", emojis)
+	}
+	SetCustom(nil)
+}
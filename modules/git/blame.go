@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"strconv"
 
 	"code.gitea.io/gitea/modules/process"
 )
@@ -101,6 +102,17 @@ func CreateBlameReader(ctx context.Context, repoPath, commitID, file string) (*B
 	return createBlameReader(ctx, repoPath, GitExecutable, "blame", commitID, "--porcelain", "--", file)
 }
 
+// CreateBlameReaderForLines creates reader for the blame of the lines start to end of the given file,
+// counting from 1, or to the end of the file if end is 0. Blaming part of a file is much faster than
+// blaming all of it for files with long histories.
+func CreateBlameReaderForLines(ctx context.Context, repoPath, commitID, file string, start, end int) (*BlameReader, error) {
+	lines := fmt.Sprintf("-L%d,", start)
+	if end > 0 {
+		lines += strconv.Itoa(end)
+	}
+	return createBlameReader(ctx, repoPath, GitExecutable, "blame", commitID, "--porcelain", lines, "--", file)
+}
+
 func createBlameReader(ctx context.Context, dir string, command ...string) (*BlameReader, error) {
 	// Here we use the provided context - this should be tied to the request performing the blame so that it does not hang around.
 	ctx, cancel := context.WithCancel(ctx)
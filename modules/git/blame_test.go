@@ -142,3 +142,21 @@ func TestReadingBlameOutput(t *testing.T) {
 		assert.Equal(t, part, actualPart)
 	}
 }
+
+func TestBlameReaderForLines(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blameReader, err := CreateBlameReaderForLines(ctx, "./tests/repos/repo1_bare", "master", "file1.txt", 1, 0)
+	assert.NoError(t, err)
+	defer blameReader.Close()
+
+	part, err := blameReader.NextPart()
+	assert.NoError(t, err)
+	assert.NotNil(t, part)
+	assert.Equal(t, []string{"file1"}, part.Lines)
+
+	part, err = blameReader.NextPart()
+	assert.NoError(t, err)
+	assert.Nil(t, part)
+}
@@ -14,7 +14,11 @@ import (
 	"strings"
 	"time"
 
+	gitealog "code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/tracing"
+
+	"go.opentelemetry.io/otel/label"
 )
 
 var (
@@ -23,6 +27,10 @@ var (
 
 	// DefaultCommandExecutionTimeout default command execution timeout duration
 	DefaultCommandExecutionTimeout = 360 * time.Second
+
+	// CommandObserver is called with the subcommand, the duration and the error
+	// of every git command that has been run, e.g. to record metrics.
+	CommandObserver func(subcommand string, duration time.Duration, err error)
 )
 
 // DefaultLocale is the default LC_ALL to run git commands in.
@@ -108,17 +116,47 @@ func (c *Command) RunInDirTimeoutEnvFullPipeline(env []string, timeout time.Dura
 // RunInDirTimeoutEnvFullPipelineFunc executes the command in given directory with given timeout,
 // it pipes stdout and stderr to given io.Writer and passes in an io.Reader as stdin. Between cmd.Start and cmd.Wait the passed in function is run.
 func (c *Command) RunInDirTimeoutEnvFullPipelineFunc(env []string, timeout time.Duration, dir string, stdout, stderr io.Writer, stdin io.Reader, fn func(context.Context, context.CancelFunc) error) error {
+	subcommand := c.subcommand()
+	ctx, span := tracing.StartChild(c.parentContext, "git "+subcommand, label.String("git.dir", dir))
+	start := time.Now()
+	err := c.run(ctx, env, timeout, dir, stdout, stderr, stdin, fn)
+	tracing.End(span, err)
+	if CommandObserver != nil {
+		CommandObserver(subcommand, time.Since(start), err)
+	}
+	return err
+}
+
+// subcommand returns the git subcommand of the command, skipping the options given to git itself
+func (c *Command) subcommand() string {
+	for i := 0; i < len(c.args); i++ {
+		switch arg := c.args[i]; {
+		case arg == "-c" || arg == "-C":
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			return arg
+		}
+	}
+	return ""
+}
+
+func (c *Command) run(parentContext context.Context, env []string, timeout time.Duration, dir string, stdout, stderr io.Writer, stdin io.Reader, fn func(context.Context, context.CancelFunc) error) error {
 	if timeout == -1 {
 		timeout = DefaultCommandExecutionTimeout
 	}
 
-	if len(dir) == 0 {
-		log(c.String())
-	} else {
-		log("%s: %v", dir, c)
+	msg := c.String()
+	if len(dir) > 0 {
+		msg = dir + ": " + msg
+	}
+	requestID := gitealog.RequestIDFromContext(parentContext)
+	if len(requestID) > 0 {
+		msg += " [request_id: " + requestID + "]"
 	}
+	log(msg)
 
-	ctx, cancel := context.WithTimeout(c.parentContext, timeout)
+	ctx, cancel := context.WithTimeout(parentContext, timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, c.name, c.args...)
@@ -130,6 +168,10 @@ func (c *Command) RunInDirTimeoutEnvFullPipelineFunc(env []string, timeout time.
 	}
 
 	cmd.Env = append(cmd.Env, "GODEBUG=asyncpreemptoff=1")
+	if len(requestID) > 0 {
+		// Passed on to the hooks run by the command
+		cmd.Env = append(cmd.Env, gitealog.EnvRequestID+"="+requestID)
+	}
 	cmd.Dir = dir
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
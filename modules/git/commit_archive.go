@@ -58,6 +58,6 @@ func (c *Commit) CreateArchive(target string, opts CreateArchiveOpts) error {
 		c.ID.String(),
 	)
 
-	_, err := NewCommand(args...).RunInDir(c.repo.Path)
+	_, err := NewCommandContext(c.repo.Ctx, args...).RunInDir(c.repo.Path)
 	return err
 }
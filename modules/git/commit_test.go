@@ -36,3 +36,11 @@ func TestGetFullCommitIDError(t *testing.T) {
 		assert.EqualError(t, err, "object does not exist [id: unknown, rel_path: ]")
 	}
 }
+
+func TestCommitIsFixup(t *testing.T) {
+	assert.True(t, (&Commit{CommitMessage: "fixup! Add a feature\n"}).IsFixup())
+	assert.True(t, (&Commit{CommitMessage: "squash! Add a feature\n\nMore details"}).IsFixup())
+	assert.True(t, (&Commit{CommitMessage: "amend! Add a feature\n\nAdd a better feature"}).IsFixup())
+	assert.False(t, (&Commit{CommitMessage: "Add a feature\n\nfixup! a typo"}).IsFixup())
+	assert.False(t, (&Commit{CommitMessage: "fixup!Add a feature"}).IsFixup())
+}
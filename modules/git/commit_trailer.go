@@ -0,0 +1,124 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+const (
+	// CoAuthoredByTrailer is the key of the trailers crediting the co-authors of a commit
+	CoAuthoredByTrailer = "Co-authored-by"
+	// ReviewedByTrailer is the key of the trailers crediting the reviewers of a commit
+	ReviewedByTrailer = "Reviewed-by"
+)
+
+// trailerPattern matches the first line of a trailer, e.g. "Reviewed-by: Name <email>"
+var trailerPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)[ \t]*:[ \t]*(.*)$`)
+
+// CommitTrailer represents a trailer of a commit message, a "Key: value" line
+// of its last paragraph like the ones of git interpret-trailers.
+type CommitTrailer struct {
+	Key   string
+	Value string
+}
+
+// ParseCommitTrailers returns the trailers of the last paragraph of a commit
+// message in their order. A commit message with only a subject has no
+// trailers, the lines of the paragraph which are not trailers are skipped and
+// the lines starting with a whitespace continue the value of the previous one.
+func ParseCommitTrailers(message string) []*CommitTrailer {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+
+	var (
+		trailers []*CommitTrailer
+		last     *CommitTrailer
+	)
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		if last != nil && len(line) > 0 && (line[0] == ' ' || line[0] == '\t') {
+			last.Value = strings.TrimSpace(last.Value + " " + strings.TrimSpace(line))
+			continue
+		}
+		last = nil
+		m := trailerPattern.FindStringSubmatch(strings.TrimRight(line, " \t"))
+		if m == nil {
+			continue
+		}
+		last = &CommitTrailer{Key: m[1], Value: m[2]}
+		trailers = append(trailers, last)
+	}
+
+	// drop the trailers without value once their continuation lines are known
+	result := trailers[:0]
+	for _, t := range trailers {
+		if t.Value != "" {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// TrailerSignatures returns the people named "Name <email>" by the trailers
+// with the key, compared case-insensitively. The values which are not such
+// an address are skipped, as well as the emails listed twice.
+func TrailerSignatures(trailers []*CommitTrailer, key string) []*Signature {
+	var (
+		sigs   []*Signature
+		emails = make(map[string]bool)
+	)
+	for _, t := range trailers {
+		if !strings.EqualFold(t.Key, key) {
+			continue
+		}
+		addr, err := mail.ParseAddress(t.Value)
+		if err != nil {
+			continue
+		}
+		email := strings.ToLower(addr.Address)
+		if emails[email] {
+			continue
+		}
+		emails[email] = true
+		sigs = append(sigs, &Signature{Name: addr.Name, Email: addr.Address})
+	}
+	return sigs
+}
+
+// Trailers returns the trailers of the commit message.
+func (c *Commit) Trailers() []*CommitTrailer {
+	return ParseCommitTrailers(c.CommitMessage)
+}
+
+// CoAuthors returns the co-authors credited by the Co-authored-by trailers
+// of the commit message, without its author.
+func (c *Commit) CoAuthors() []*Signature {
+	return coAuthors(c.Trailers(), c.Author)
+}
+
+// Reviewers returns the reviewers credited by the Reviewed-by trailers of the
+// commit message.
+func (c *Commit) Reviewers() []*Signature {
+	return TrailerSignatures(c.Trailers(), ReviewedByTrailer)
+}
+
+// coAuthors returns the signatures of the Co-authored-by trailers except the author
+func coAuthors(trailers []*CommitTrailer, author *Signature) []*Signature {
+	sigs := TrailerSignatures(trailers, CoAuthoredByTrailer)
+	if author == nil {
+		return sigs
+	}
+	result := sigs[:0]
+	for _, sig := range sigs {
+		if !strings.EqualFold(sig.Email, author.Email) {
+			result = append(result, sig)
+		}
+	}
+	return result
+}
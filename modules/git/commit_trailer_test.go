@@ -0,0 +1,51 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommitTrailers(t *testing.T) {
+	assert.Empty(t, ParseCommitTrailers("Reviewed-by: Alice <alice@example.com>"))
+	assert.Empty(t, ParseCommitTrailers("subject\n\nA body: with a colon\nand more text: here.\n\nlast paragraph"))
+
+	trailers := ParseCommitTrailers("subject\r\n\r\nbody\r\n\r\nReviewed-by: Alice <alice@example.com>\r\n" +
+		"Fixes:\r\n  #12 and\r\n\t#13\r\nnot a trailer\r\nCo-authored-by:Bob <bob@example.com>\r\nEmpty:\r\n\r\n")
+	assert.Equal(t, []*CommitTrailer{
+		{Key: "Reviewed-by", Value: "Alice <alice@example.com>"},
+		{Key: "Fixes", Value: "#12 and #13"},
+		{Key: "Co-authored-by", Value: "Bob <bob@example.com>"},
+	}, trailers)
+}
+
+func TestCommit_CoAuthorsAndReviewers(t *testing.T) {
+	commit := &Commit{
+		Author: &Signature{Name: "Alice", Email: "alice@example.com"},
+		CommitMessage: "Add a feature\n\n" +
+			"Co-authored-by: Bob <bob@example.com>\n" +
+			"co-authored-by: \"Carol C.\" <carol@example.com>\n" +
+			"Co-authored-by: Bob Again <BOB@example.com>\n" +
+			"Co-authored-by: Alice <ALICE@example.com>\n" +
+			"Co-authored-by: not an address\n" +
+			"Reviewed-by: Dave <dave@example.com>\n",
+	}
+
+	coAuthors := commit.CoAuthors()
+	if assert.Len(t, coAuthors, 2) {
+		assert.Equal(t, "Bob", coAuthors[0].Name)
+		assert.Equal(t, "bob@example.com", coAuthors[0].Email)
+		assert.Equal(t, "Carol C.", coAuthors[1].Name)
+		assert.Equal(t, "carol@example.com", coAuthors[1].Email)
+	}
+
+	reviewers := commit.Reviewers()
+	if assert.Len(t, reviewers, 1) {
+		assert.Equal(t, "Dave", reviewers[0].Name)
+		assert.Equal(t, "dave@example.com", reviewers[0].Email)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/go-enry/go-enry/v2"
+	"github.com/gobwas/glob"
+)
+
+// LinguistAttributesPath is the path of the attributes file the linguist
+// overrides are read from.
+const LinguistAttributesPath = ".gitattributes"
+
+type linguistRule struct {
+	pattern   glob.Glob
+	vendored  *bool
+	generated *bool
+}
+
+// LinguistAttributes are the linguist-vendored and linguist-generated
+// overrides of the .gitattributes file in the root of a repository. The files
+// without an override are detected by their path like linguist does.
+type LinguistAttributes struct {
+	rules []*linguistRule
+}
+
+// parseLinguistAttribute returns the value of a linguist attribute of a
+// .gitattributes line, nil if it is not the attribute.
+func parseLinguistAttribute(attr, name string) *bool {
+	var value bool
+	switch {
+	case attr == name, attr == name+"=true":
+		value = true
+	case attr == "-"+name, attr == name+"=false":
+		value = false
+	default:
+		return nil
+	}
+	return &value
+}
+
+// ParseLinguistAttributes parses the linguist overrides of a .gitattributes
+// file, the other attributes are ignored.
+func ParseLinguistAttributes(data []byte) *LinguistAttributes {
+	attrs := &LinguistAttributes{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		rule := &linguistRule{}
+		for _, attr := range fields[1:] {
+			if v := parseLinguistAttribute(attr, "linguist-vendored"); v != nil {
+				rule.vendored = v
+			} else if v := parseLinguistAttribute(attr, "linguist-generated"); v != nil {
+				rule.generated = v
+			}
+		}
+		if rule.vendored == nil && rule.generated == nil {
+			continue
+		}
+
+		// patterns without a slash match files in any directory
+		expr := fields[0]
+		if !strings.Contains(strings.TrimSuffix(expr, "/"), "/") {
+			expr = "{" + expr + ",**/" + expr + "}"
+		}
+		expr = strings.TrimPrefix(expr, "/")
+		var err error
+		if rule.pattern, err = glob.Compile(expr, '/'); err != nil {
+			log("Invalid .gitattributes pattern '%s' (skipped): %v", fields[0], err)
+			continue
+		}
+		attrs.rules = append(attrs.rules, rule)
+	}
+	return attrs
+}
+
+// IsVendored returns if the file is vendored, the last matching override wins.
+func (attrs *LinguistAttributes) IsVendored(path string) bool {
+	vendored := enry.IsVendor(path)
+	if attrs == nil {
+		return vendored
+	}
+	for _, rule := range attrs.rules {
+		if rule.vendored != nil && rule.pattern.Match(path) {
+			vendored = *rule.vendored
+		}
+	}
+	return vendored
+}
+
+// IsGenerated returns if the file is generated, the last matching override wins.
+func (attrs *LinguistAttributes) IsGenerated(path string) bool {
+	generated := enry.IsGenerated(path, nil)
+	if attrs == nil {
+		return generated
+	}
+	for _, rule := range attrs.rules {
+		if rule.generated != nil && rule.pattern.Match(path) {
+			generated = *rule.generated
+		}
+	}
+	return generated
+}
+
+// IsExcluded returns if the file is vendored or generated.
+func (attrs *LinguistAttributes) IsExcluded(path string) bool {
+	return attrs.IsVendored(path) || attrs.IsGenerated(path)
+}
+
+// GetLinguistAttributes returns the linguist overrides of the commit, there
+// are none if it has no .gitattributes file.
+func (c *Commit) GetLinguistAttributes() (*LinguistAttributes, error) {
+	blob, err := c.GetBlobByPath(LinguistAttributesPath)
+	if err != nil {
+		if IsErrNotExist(err) {
+			return &LinguistAttributes{}, nil
+		}
+		return nil, err
+	}
+	dataRc, err := blob.DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer dataRc.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(dataRc, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+	return ParseLinguistAttributes(data), nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLinguistAttributes(t *testing.T) {
+	attrs := ParseLinguistAttributes([]byte(`# comment
+*.pb.go linguist-generated
+/third_party/** linguist-vendored=true
+vendor/** -linguist-vendored
+*.txt text eol=lf
+`))
+
+	assert.True(t, attrs.IsGenerated("api/service.pb.go"))
+	assert.True(t, attrs.IsGenerated("service.pb.go"))
+	assert.False(t, attrs.IsGenerated("service.go"))
+
+	assert.True(t, attrs.IsVendored("third_party/lib/lib.go"))
+	assert.False(t, attrs.IsVendored("src/main.go"))
+	assert.False(t, attrs.IsVendored("vendor/github.com/pkg/errors/errors.go"))
+	assert.True(t, attrs.IsVendored("node_modules/left-pad/index.js"))
+
+	assert.True(t, attrs.IsExcluded("service.pb.go"))
+	assert.False(t, attrs.IsExcluded("notes.txt"))
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParsePktLines splits data in pkt-lines, the special packets are returned as their length,
+// e.g. "0000" for a flush packet.
+func ParsePktLines(data []byte) ([]string, error) {
+	var lines []string
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("truncated pkt-line length")
+		}
+		length, err := strconv.ParseUint(string(data[:4]), 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pkt-line length: %v", err)
+		}
+		if length < 4 {
+			lines = append(lines, string(data[:4]))
+			data = data[4:]
+			continue
+		}
+		if int(length) > len(data) {
+			return nil, fmt.Errorf("truncated pkt-line")
+		}
+		lines = append(lines, strings.TrimSuffix(string(data[4:length]), "\n"))
+		data = data[length:]
+	}
+	return lines, nil
+}
+
+// UploadPackRequest describes a request to git upload-pack
+type UploadPackRequest struct {
+	// Command is the protocol v2 command, empty for the previous protocol versions
+	Command string
+	Wants   int
+	Haves   int
+	Done    bool
+	// Depth is the depth of a shallow fetch, 0 if the history is not limited by a depth
+	Depth int
+	// Deepen is true if the history is limited by a date or a ref
+	Deepen bool
+	Filter string
+}
+
+// addLine adds a pkt-line of the request
+func (req *UploadPackRequest) addLine(line string) {
+	switch {
+	case strings.HasPrefix(line, "command="):
+		req.Command = strings.TrimPrefix(line, "command=")
+	case strings.HasPrefix(line, "want "):
+		req.Wants++
+	case strings.HasPrefix(line, "have "):
+		req.Haves++
+	case line == "done":
+		req.Done = true
+	case strings.HasPrefix(line, "deepen "):
+		req.Depth, _ = strconv.Atoi(strings.TrimPrefix(line, "deepen "))
+	case strings.HasPrefix(line, "deepen-since ") || strings.HasPrefix(line, "deepen-not "):
+		req.Deepen = true
+	case strings.HasPrefix(line, "filter "):
+		req.Filter = strings.TrimPrefix(line, "filter ")
+	}
+}
+
+// ParseUploadPackRequest parses the body of a request to git upload-pack, in any protocol version.
+func ParseUploadPackRequest(body []byte) (*UploadPackRequest, error) {
+	lines, err := ParsePktLines(body)
+	if err != nil {
+		return nil, err
+	}
+	req := &UploadPackRequest{}
+	for _, line := range lines {
+		req.addLine(line)
+	}
+	return req, nil
+}
+
+// UploadPackRequestParser parses a request to git upload-pack as it is written to it, e.g. while
+// the request is streamed to git. Writing to it never fails, Err returns if the request is invalid.
+type UploadPackRequestParser struct {
+	UploadPackRequest
+	buf []byte
+	err error
+}
+
+// Write parses the complete pkt-lines written and buffers the rest.
+func (p *UploadPackRequestParser) Write(data []byte) (int, error) {
+	if p.err != nil {
+		return len(data), nil
+	}
+	p.buf = append(p.buf, data...)
+	for len(p.buf) >= 4 {
+		length, err := strconv.ParseUint(string(p.buf[:4]), 16, 16)
+		if err != nil {
+			p.err = fmt.Errorf("invalid pkt-line length: %v", err)
+			p.buf = nil
+			break
+		}
+		if length < 4 {
+			p.buf = p.buf[4:]
+			continue
+		}
+		if int(length) > len(p.buf) {
+			break
+		}
+		p.addLine(strings.TrimSuffix(string(p.buf[4:length]), "\n"))
+		p.buf = p.buf[length:]
+	}
+	return len(data), nil
+}
+
+// Err returns an error if the request written is not a valid or complete request.
+func (p *UploadPackRequestParser) Err() error {
+	if p.err == nil && len(p.buf) > 0 {
+		return fmt.Errorf("truncated pkt-line")
+	}
+	return p.err
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePktLines(t *testing.T) {
+	lines, err := ParsePktLines([]byte("000ahello\n0000000aworld\n0001"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hello", "0000", "world", "0001"}, lines)
+
+	_, err = ParsePktLines([]byte("000ahello"))
+	assert.Error(t, err)
+	_, err = ParsePktLines([]byte("zzzz"))
+	assert.Error(t, err)
+	_, err = ParsePktLines([]byte("00"))
+	assert.Error(t, err)
+}
+
+func pktLines(lines ...string) []byte {
+	var data []byte
+	for _, line := range lines {
+		if line == "0000" || line == "0001" {
+			data = append(data, line...)
+			continue
+		}
+		data = append(data, fmt.Sprintf("%04x%s\n", len(line)+5, line)...)
+	}
+	return data
+}
+
+func TestParseUploadPackRequest(t *testing.T) {
+	// git clone --depth 1 --filter=blob:none, protocol version 0
+	req, err := ParseUploadPackRequest(pktLines("want 65f1bf27bc3bf70f64657658635e66094edbcb4d multi_ack_detailed side-band-64k",
+		"deepen 1", "filter blob:none", "0000", "done"))
+	assert.NoError(t, err)
+	assert.Equal(t, &UploadPackRequest{Wants: 1, Done: true, Depth: 1, Filter: "blob:none"}, req)
+
+	// git fetch, protocol version 2
+	req, err = ParseUploadPackRequest(pktLines("command=fetch", "0001", "thin-pack",
+		"want 65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		"have 2a47ca4b614a9f5a43abbd5ad851a54a616ffee6",
+		"deepen-since 1600000000", "0000"))
+	assert.NoError(t, err)
+	assert.Equal(t, &UploadPackRequest{Command: "fetch", Wants: 1, Haves: 1, Deepen: true}, req)
+}
+
+func TestUploadPackRequestParser(t *testing.T) {
+	body := pktLines("want 65f1bf27bc3bf70f64657658635e66094edbcb4d side-band-64k", "filter tree:0", "0000",
+		"have 2a47ca4b614a9f5a43abbd5ad851a54a616ffee6", "done")
+
+	var p UploadPackRequestParser
+	for i := 0; i < len(body); i += 7 {
+		end := i + 7
+		if end > len(body) {
+			end = len(body)
+		}
+		n, err := p.Write(body[i:end])
+		assert.NoError(t, err)
+		assert.Equal(t, end-i, n)
+	}
+	assert.NoError(t, p.Err())
+	assert.Equal(t, UploadPackRequest{Wants: 1, Haves: 1, Done: true, Filter: "tree:0"}, p.UploadPackRequest)
+
+	p = UploadPackRequestParser{}
+	_, _ = p.Write(body[:10])
+	assert.Error(t, p.Err())
+	_, _ = p.Write([]byte("PACK"))
+	assert.Error(t, p.Err())
+}
@@ -8,6 +8,7 @@ package git
 import (
 	"bytes"
 	"container/list"
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -28,6 +29,8 @@ import (
 // Repository represents a Git repository.
 type Repository struct {
 	Path string
+	// Ctx is the context the git commands of the repository are run in
+	Ctx context.Context
 
 	tagCache *ObjectCache
 
@@ -94,6 +97,12 @@ func InitRepository(repoPath string, bare bool) error {
 
 // OpenRepository opens the repository at the given path.
 func OpenRepository(repoPath string) (*Repository, error) {
+	return OpenRepositoryCtx(DefaultContext, repoPath)
+}
+
+// OpenRepositoryCtx opens the repository at the given path, running its git
+// commands in the given context.
+func OpenRepositoryCtx(ctx context.Context, repoPath string) (*Repository, error) {
 	repoPath, err := filepath.Abs(repoPath)
 	if err != nil {
 		return nil, err
@@ -117,6 +126,7 @@ func OpenRepository(repoPath string) (*Repository, error) {
 
 	return &Repository{
 		Path:         repoPath,
+		Ctx:          ctx,
 		gogitRepo:    gogitRepo,
 		gogitStorage: storage,
 		tagCache:     newObjectCache(),
@@ -141,7 +151,7 @@ func (repo *Repository) GoGitRepo() *gogit.Repository {
 // IsEmpty Check if repository is empty.
 func (repo *Repository) IsEmpty() (bool, error) {
 	var errbuf strings.Builder
-	if err := NewCommand("log", "-1").RunInDirPipeline(repo.Path, nil, &errbuf); err != nil {
+	if err := NewCommandContext(repo.Ctx, "log", "-1").RunInDirPipeline(repo.Path, nil, &errbuf); err != nil {
 		if strings.Contains(errbuf.String(), "fatal: bad default revision 'HEAD'") ||
 			strings.Contains(errbuf.String(), "fatal: your current branch 'master' does not have any commits yet") {
 			return true, nil
@@ -6,11 +6,15 @@
 package git
 
 import (
+	"context"
 	"os"
 	"path"
+	"time"
 
 	gitealog "code.gitea.io/gitea/modules/log"
 
+	version "github.com/mcuadros/go-version"
+
 	"github.com/go-git/go-git/v5/plumbing/format/commitgraph"
 	cgobject "github.com/go-git/go-git/v5/plumbing/object/commitgraph"
 )
@@ -34,3 +38,20 @@ func (r *Repository) CommitNodeIndex() (cgobject.CommitNodeIndex, *os.File) {
 
 	return cgobject.NewObjectCommitNodeIndex(r.gogitRepo.Storer), nil
 }
+
+// WriteCommitGraph writes the commit-graph file of all reachable commits of the repository. Where git supports
+// it, changed-path Bloom filters are included which speed up the history of single files considerably.
+func WriteCommitGraph(ctx context.Context, repoPath string, timeout time.Duration) error {
+	if version.Compare(gitVersion, "2.18", "<") {
+		return nil
+	}
+	if timeout <= 0 {
+		timeout = -1
+	}
+	cmd := NewCommandContext(ctx, "commit-graph", "write", "--reachable")
+	if version.Compare(gitVersion, "2.27", ">=") {
+		cmd.AddArguments("--changed-paths")
+	}
+	_, err := cmd.RunInDirTimeout(timeout, repoPath)
+	return err
+}
@@ -34,13 +34,13 @@ func (repo *Repository) GetMergeBase(tmpRemote string, base, head string) (strin
 	if tmpRemote != "origin" {
 		tmpBaseName := "refs/remotes/" + tmpRemote + "/tmp_" + base
 		// Fetch commit into a temporary branch in order to be able to handle commits and tags
-		_, err := NewCommand("fetch", tmpRemote, base+":"+tmpBaseName).RunInDir(repo.Path)
+		_, err := NewCommandContext(repo.Ctx, "fetch", tmpRemote, base+":"+tmpBaseName).RunInDir(repo.Path)
 		if err == nil {
 			base = tmpBaseName
 		}
 	}
 
-	stdout, err := NewCommand("merge-base", "--", base, head).RunInDir(repo.Path)
+	stdout, err := NewCommandContext(repo.Ctx, "merge-base", "--", base, head).RunInDir(repo.Path)
 	return strings.TrimSpace(stdout), base, err
 }
 
@@ -69,7 +69,7 @@ func (repo *Repository) GetCompareInfo(basePath, baseBranch, headBranch string)
 	compareInfo.MergeBase, remoteBranch, err = repo.GetMergeBase(tmpRemote, baseBranch, headBranch)
 	if err == nil {
 		// We have a common base
-		logs, err := NewCommand("log", compareInfo.MergeBase+"..."+headBranch, prettyLogFormat).RunInDirBytes(repo.Path)
+		logs, err := NewCommandContext(repo.Ctx, "log", compareInfo.MergeBase+"..."+headBranch, prettyLogFormat).RunInDirBytes(repo.Path)
 		if err != nil {
 			return nil, err
 		}
@@ -113,7 +113,7 @@ func (repo *Repository) GetDiffNumChangedFiles(base, head string) (int, error) {
 	w := &lineCountWriter{}
 	stderr := new(bytes.Buffer)
 
-	if err := NewCommand("diff", "-z", "--name-only", base+"..."+head).
+	if err := NewCommandContext(repo.Ctx, "diff", "-z", "--name-only", base+"..."+head).
 		RunInDirPipeline(repo.Path, w, stderr); err != nil {
 		return 0, fmt.Errorf("%v: Stderr: %s", err, stderr)
 	}
@@ -177,6 +177,64 @@ func parseDiffStat(stdout string) (numFiles, totalAdditions, totalDeletions int,
 	return
 }
 
+// DiffFileStat is the number of added and deleted lines of a changed file
+type DiffFileStat struct {
+	Name      string
+	Additions int
+	Deletions int
+	IsBinary  bool
+}
+
+// GetDiffFileStats returns the number of added and deleted lines of every
+// file changed between the revisions, renamed files are reported with their
+// new name.
+func (repo *Repository) GetDiffFileStats(base, head string) ([]*DiffFileStat, error) {
+	stdout, err := NewCommandContext(repo.Ctx, "diff", "--numstat", "-z", "-M", base, head).RunInDirBytes(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+	return parseDiffNumStat(stdout)
+}
+
+// parseDiffNumStat parses the output of "git diff --numstat -z", whose
+// records are "<added>\t<deleted>\t<name>\0" or, for renames,
+// "<added>\t<deleted>\t\0<old name>\0<new name>\0".
+func parseDiffNumStat(stdout []byte) ([]*DiffFileStat, error) {
+	fields := bytes.Split(stdout, []byte{'\000'})
+	stats := make([]*DiffFileStat, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		if len(fields[i]) == 0 {
+			continue
+		}
+		parts := strings.SplitN(string(fields[i]), "\t", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("unable to parse numstat: %q", fields[i])
+		}
+
+		var err error
+		stat := &DiffFileStat{Name: parts[2]}
+		if parts[0] == "-" && parts[1] == "-" {
+			stat.IsBinary = true
+		} else {
+			if stat.Additions, err = strconv.Atoi(parts[0]); err != nil {
+				return nil, fmt.Errorf("unable to parse numstat: %q: %v", fields[i], err)
+			}
+			if stat.Deletions, err = strconv.Atoi(parts[1]); err != nil {
+				return nil, fmt.Errorf("unable to parse numstat: %q: %v", fields[i], err)
+			}
+		}
+		if stat.Name == "" {
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("unable to parse numstat: missing names of renamed file")
+			}
+			stat.Name = string(fields[i+2])
+			i += 2
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
 // GetDiffOrPatch generates either diff or formatted patch data between given revisions
 func (repo *Repository) GetDiffOrPatch(base, head string, w io.Writer, formatted bool) error {
 	if formatted {
@@ -187,18 +245,18 @@ func (repo *Repository) GetDiffOrPatch(base, head string, w io.Writer, formatted
 
 // GetDiff generates and returns patch data between given revisions.
 func (repo *Repository) GetDiff(base, head string, w io.Writer) error {
-	return NewCommand("diff", "-p", "--binary", base, head).
+	return NewCommandContext(repo.Ctx, "diff", "-p", "--binary", base, head).
 		RunInDirPipeline(repo.Path, w, nil)
 }
 
 // GetPatch generates and returns format-patch data between given revisions.
 func (repo *Repository) GetPatch(base, head string, w io.Writer) error {
-	return NewCommand("format-patch", "--binary", "--stdout", base+"..."+head).
+	return NewCommandContext(repo.Ctx, "format-patch", "--binary", "--stdout", base+"..."+head).
 		RunInDirPipeline(repo.Path, w, nil)
 }
 
 // GetDiffFromMergeBase generates and return patch data from merge base to head
 func (repo *Repository) GetDiffFromMergeBase(base, head string, w io.Writer) error {
-	return NewCommand("diff", "-p", "--binary", base+"..."+head).
+	return NewCommandContext(repo.Ctx, "diff", "-p", "--binary", base+"..."+head).
 		RunInDirPipeline(repo.Path, w, nil)
 }
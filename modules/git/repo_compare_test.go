@@ -31,3 +31,16 @@ func TestGetFormatPatch(t *testing.T) {
 	assert.Regexp(t, "^From 8d92fc95", patch)
 	assert.Contains(t, patch, "Subject: [PATCH] Add file2.txt")
 }
+
+func TestParseDiffNumStat(t *testing.T) {
+	stats, err := parseDiffNumStat([]byte("3\t1\tREADME.md\x00-\t-\timage.png\x002\t0\t\x00old.go\x00new.go\x00"))
+	assert.NoError(t, err)
+	assert.Equal(t, []*DiffFileStat{
+		{Name: "README.md", Additions: 3, Deletions: 1},
+		{Name: "image.png", IsBinary: true},
+		{Name: "new.go", Additions: 2},
+	}, stats)
+
+	_, err = parseDiffNumStat([]byte("3\tREADME.md\x00"))
+	assert.Error(t, err)
+}
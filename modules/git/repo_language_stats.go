@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"io"
 	"io/ioutil"
+	"strings"
 
 	"code.gitea.io/gitea/modules/analyze"
 
@@ -20,30 +21,68 @@ import (
 const fileSizeLimit int64 = 16 * 1024 // 16 KiB
 const bigFileSize int64 = 1024 * 1024 // 1 MiB
 
+// LanguageStatsRootDirectory is the directory, which the files in the root of
+// the repository are counted for in the stats by directory
+const LanguageStatsRootDirectory = "/"
+
 // GetLanguageStats calculates language stats for git repository at specified commit
 func (repo *Repository) GetLanguageStats(commitID string) (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	if err := repo.walkLanguageSizes(commitID, func(name, language string, size int64) {
+		sizes[language] += size
+	}); err != nil {
+		return nil, err
+	}
+	return filterLanguageSizes(sizes), nil
+}
+
+// GetLanguageStatsByDirectory calculates language stats for every top-level
+// directory of git repository at specified commit. The files in the root are
+// counted for LanguageStatsRootDirectory.
+func (repo *Repository) GetLanguageStatsByDirectory(commitID string) (map[string]map[string]int64, error) {
+	dirs := make(map[string]map[string]int64)
+	if err := repo.walkLanguageSizes(commitID, func(name, language string, size int64) {
+		dir := LanguageStatsRootDirectory
+		if idx := strings.IndexByte(name, '/'); idx >= 0 {
+			dir = name[:idx]
+		}
+		if dirs[dir] == nil {
+			dirs[dir] = make(map[string]int64)
+		}
+		dirs[dir][language] += size
+	}); err != nil {
+		return nil, err
+	}
+	for dir, sizes := range dirs {
+		dirs[dir] = filterLanguageSizes(sizes)
+	}
+	return dirs, nil
+}
+
+// walkLanguageSizes calls fn with the language and the size of every file
+// counted in the language stats at specified commit
+func (repo *Repository) walkLanguageSizes(commitID string, fn func(name, language string, size int64)) error {
 	r, err := git.PlainOpen(repo.Path)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	rev, err := r.ResolveRevision(plumbing.Revision(commitID))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	commit, err := r.CommitObject(*rev)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	tree, err := commit.Tree()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	sizes := make(map[string]int64)
-	err = tree.Files().ForEach(func(f *object.File) error {
+	return tree.Files().ForEach(func(f *object.File) error {
 		if f.Size == 0 || enry.IsVendor(f.Name) || enry.IsDotFile(f.Name) ||
 			enry.IsDocumentation(f.Name) || enry.IsConfiguration(f.Name) {
 			return nil
@@ -71,15 +110,14 @@ func (repo *Repository) GetLanguageStats(commitID string) (map[string]int64, err
 			language = group
 		}
 
-		sizes[language] += f.Size
+		fn(f.Name, language, f.Size)
 
 		return nil
 	})
-	if err != nil {
-		return nil, err
-	}
+}
 
-	// filter special languages unless they are the only language
+// filterLanguageSizes filters special languages unless they are the only language
+func filterLanguageSizes(sizes map[string]int64) map[string]int64 {
 	if len(sizes) > 1 {
 		for language := range sizes {
 			langtype := enry.GetLanguageType(language)
@@ -88,8 +126,7 @@ func (repo *Repository) GetLanguageStats(commitID string) (map[string]int64, err
 			}
 		}
 	}
-
-	return sizes, nil
+	return sizes
 }
 
 func readFile(f *object.File, limit int64) ([]byte, error) {
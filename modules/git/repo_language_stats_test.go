@@ -0,0 +1,63 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_GetLanguageStatsByDirectory(t *testing.T) {
+	repoPath, err := ioutil.TempDir("", "language-stats")
+	assert.NoError(t, err)
+	defer os.RemoveAll(repoPath)
+	assert.NoError(t, InitRepository(repoPath, false))
+
+	files := map[string]string{
+		"main.go":          "package main\n\nfunc main() {}\n",
+		"server/server.go": "package server\n",
+		"server/util.go":   "package server\n\nfunc Util() {}\n",
+		"web/index.js":     "console.log('hello');\n",
+		"web/style.css":    "body { margin: 0; }\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(repoPath, filepath.Dir(name)), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644))
+	}
+	assert.NoError(t, AddChanges(repoPath, true))
+	signature := &Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	assert.NoError(t, CommitChanges(repoPath, CommitChangesOptions{
+		Committer: signature,
+		Message:   "Initial commit",
+	}))
+
+	repo, err := OpenRepository(repoPath)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	stats, err := repo.GetLanguageStats("HEAD")
+	assert.NoError(t, err)
+	assert.EqualValues(t, map[string]int64{
+		"Go":         int64(len(files["main.go"]) + len(files["server/server.go"]) + len(files["server/util.go"])),
+		"JavaScript": int64(len(files["web/index.js"])),
+		"CSS":        int64(len(files["web/style.css"])),
+	}, stats)
+
+	dirs, err := repo.GetLanguageStatsByDirectory("HEAD")
+	assert.NoError(t, err)
+	assert.EqualValues(t, map[string]map[string]int64{
+		LanguageStatsRootDirectory: {"Go": int64(len(files["main.go"]))},
+		"server":                   {"Go": int64(len(files["server/server.go"]) + len(files["server/util.go"]))},
+		"web": {
+			"JavaScript": int64(len(files["web/index.js"])),
+			"CSS":        int64(len(files["web/style.css"])),
+		},
+	}, dirs)
+}
@@ -8,6 +8,9 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
 	"sort"
 	"strconv"
 	"strings"
@@ -38,7 +41,7 @@ func (repo *Repository) GetCodeActivityStats(fromTime time.Time, branch string)
 
 	since := fromTime.Format(time.RFC3339)
 
-	stdout, err := NewCommand("rev-list", "--count", "--no-merges", "--branches=*", "--date=iso", fmt.Sprintf("--since='%s'", since)).RunInDirBytes(repo.Path)
+	stdout, err := NewCommandContext(repo.Ctx, "rev-list", "--count", "--no-merges", "--branches=*", "--date=iso", fmt.Sprintf("--since='%s'", since)).RunInDirBytes(repo.Path)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +59,7 @@ func (repo *Repository) GetCodeActivityStats(fromTime time.Time, branch string)
 		args = append(args, "--first-parent", branch)
 	}
 
-	stdout, err = NewCommand(args...).RunInDirBytes(repo.Path)
+	stdout, err = NewCommandContext(repo.Ctx, args...).RunInDirBytes(repo.Path)
 	if err != nil {
 		return nil, err
 	}
@@ -132,3 +135,116 @@ func (repo *Repository) GetCodeActivityStats(fromTime time.Time, branch string)
 
 	return stats, nil
 }
+
+// CommitNumstat represents the author and the changed lines of a commit
+type CommitNumstat struct {
+	ID        string
+	Name      string
+	Email     string
+	When      time.Time
+	Additions int64
+	Deletions int64
+	// CoAuthors are the people credited by the Co-authored-by trailers
+	CoAuthors []*Signature
+}
+
+// WalkCommitNumstats calls fn for every non-merge commit reachable from to
+// but not from from, oldest first. All commits reachable from to are walked
+// if from is empty.
+func (repo *Repository) WalkCommitNumstats(from, to string, fn func(*CommitNumstat)) error {
+	rev := to
+	if len(from) > 0 {
+		rev = from + ".." + to
+	}
+
+	stdout, w := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(stdout)
+		var (
+			commit  *CommitNumstat
+			message strings.Builder
+			inBody  bool
+		)
+		p := 0
+		for scanner.Scan() {
+			if inBody {
+				// The message ends with a unit separator, which prevents its
+				// lines from being taken for the start of the next commit
+				l := scanner.Text()
+				if i := strings.IndexByte(l, '\x1f'); i >= 0 {
+					message.WriteString(l[:i])
+					commit.CoAuthors = coAuthors(ParseCommitTrailers(message.String()), &Signature{Email: commit.Email})
+					inBody = false
+				} else {
+					message.WriteString(l)
+					message.WriteByte('\n')
+				}
+				continue
+			}
+			l := strings.TrimSpace(scanner.Text())
+			if l == "---" {
+				if commit != nil {
+					fn(commit)
+				}
+				commit = &CommitNumstat{}
+				p = 1
+				continue
+			} else if p == 0 {
+				continue
+			}
+			p++
+			switch p {
+			case 2: // Commit sha-1
+				commit.ID = l
+			case 3: // Author
+				commit.Name = l
+			case 4: // E-mail
+				commit.Email = strings.ToLower(l)
+			case 5: // Author date
+				commit.When, _ = time.Parse(time.RFC3339, l)
+				message.Reset()
+				inBody = true
+			default: // Changed file
+				if parts := strings.Fields(l); len(parts) >= 3 {
+					if c, err := strconv.ParseInt(parts[0], 10, 64); err == nil {
+						commit.Additions += c
+					}
+					if c, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+						commit.Deletions += c
+					}
+				}
+			}
+		}
+		if commit != nil {
+			fn(commit)
+		}
+		// Drain the output if the scanner failed on a too long line
+		_, _ = io.Copy(ioutil.Discard, stdout)
+	}()
+
+	stderr := new(bytes.Buffer)
+	err := NewCommandContext(repo.Ctx, "log", "--numstat", "--no-merges", "--reverse", "--pretty=format:---%n%H%n%an%n%ae%n%aI%n%B%x1f", rev).
+		RunInDirPipeline(repo.Path, w, stderr)
+	w.Close() // Close writer to exit parsing goroutine
+	<-done
+	if err != nil {
+		return concatenateError(err, stderr.String())
+	}
+	return nil
+}
+
+// IsAncestor returns if the ancestor commit is reachable from the commit
+func (repo *Repository) IsAncestor(ancestor, commit string) (bool, error) {
+	stderr := new(bytes.Buffer)
+	err := NewCommandContext(repo.Ctx, "merge-base", "--is-ancestor", ancestor, commit).
+		RunInDirPipeline(repo.Path, ioutil.Discard, stderr)
+	if err == nil {
+		return true, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, concatenateError(err, stderr.String())
+}
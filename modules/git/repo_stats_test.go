@@ -35,3 +35,44 @@ func TestRepository_GetCodeActivityStats(t *testing.T) {
 	assert.EqualValues(t, 3, code.Authors[1].Commits)
 	assert.EqualValues(t, 5, code.Authors[0].Commits)
 }
+
+func TestRepository_WalkCommitNumstats(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	var commits []*CommitNumstat
+	assert.NoError(t, bareRepo1.WalkCommitNumstats("", "master", func(commit *CommitNumstat) {
+		commits = append(commits, commit)
+	}))
+	assert.Len(t, commits, 6)
+	assert.EqualValues(t, "95bb4d39648ee7e325106df01a621c530863a653", commits[0].ID)
+	assert.EqualValues(t, "Example User", commits[0].Name)
+	assert.EqualValues(t, 1513750509, commits[0].When.Unix())
+
+	commits = nil
+	assert.NoError(t, bareRepo1.WalkCommitNumstats("8d92fc957a4d7cfd98bc375f0b7bb189a0d6c9f2", "master", func(commit *CommitNumstat) {
+		commits = append(commits, commit)
+	}))
+	assert.Len(t, commits, 4)
+	assert.EqualValues(t, "8006ff9adbf0cb94da7dad9e537e53817f9fa5c0", commits[0].ID)
+	assert.EqualValues(t, "tris.git@shoddynet.org", commits[0].Email)
+	assert.EqualValues(t, 2, commits[0].Additions)
+	assert.EqualValues(t, 0, commits[0].Deletions)
+}
+
+func TestRepository_IsAncestor(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	isAncestor, err := bareRepo1.IsAncestor("95bb4d39648ee7e325106df01a621c530863a653", "master")
+	assert.NoError(t, err)
+	assert.True(t, isAncestor)
+
+	isAncestor, err = bareRepo1.IsAncestor("master", "95bb4d39648ee7e325106df01a621c530863a653")
+	assert.NoError(t, err)
+	assert.False(t, isAncestor)
+}
@@ -5,6 +5,7 @@
 package git
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
 	"time"
@@ -35,3 +36,20 @@ func TestRepoIsEmpty(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, isEmpty)
 }
+
+func TestOpenRepositoryCtx(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	ctx, cancel := context.WithCancel(context.Background())
+	repo, err := OpenRepositoryCtx(ctx, bareRepo1Path)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tags, err := repo.GetTagInfos(0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, tags, 1)
+
+	// the commands of the repository are run in its context
+	cancel()
+	_, err = repo.GetTagInfos(0, 0)
+	assert.Error(t, err)
+}
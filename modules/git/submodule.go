@@ -39,7 +39,14 @@ func NewSubModuleFile(c *Commit, refURL, refID string) *SubModuleFile {
 	}
 }
 
-func getRefURL(refURL, urlPrefix, repoFullName string) string {
+// SubModuleURL returns the web URL of the repository a submodule URL as found in .gitmodules refers to.
+// URLs whose hostname is the one of urlPrefix or sshDomain are resolved to URLs below urlPrefix, and
+// relative URLs are resolved against the repository repoFullName.
+func SubModuleURL(refURL, urlPrefix, repoFullName, sshDomain string) string {
+	return getRefURL(refURL, urlPrefix, repoFullName, sshDomain)
+}
+
+func getRefURL(refURL, urlPrefix, repoFullName, sshDomain string) string {
 	if refURL == "" {
 		return ""
 	}
@@ -76,7 +83,7 @@ func getRefURL(refURL, urlPrefix, repoFullName string) string {
 				pth = "/" + pth
 			}
 
-			if urlPrefixHostname == refHostname {
+			if urlPrefixHostname == refHostname || (sshDomain != "" && refHostname == sshDomain) {
 				return urlPrefix + path.Clean(path.Join("/", pth))
 			}
 			return "http://" + refHostname + pth
@@ -97,7 +104,7 @@ func getRefURL(refURL, urlPrefix, repoFullName string) string {
 
 	for _, scheme := range supportedSchemes {
 		if ref.Scheme == scheme {
-			if urlPrefixHostname == refHostname {
+			if urlPrefixHostname == refHostname || (sshDomain != "" && refHostname == sshDomain) {
 				return urlPrefix + path.Clean(path.Join("/", ref.Path))
 			} else if ref.Scheme == "http" || ref.Scheme == "https" {
 				if len(ref.User.Username()) > 0 {
@@ -114,8 +121,8 @@ func getRefURL(refURL, urlPrefix, repoFullName string) string {
 }
 
 // RefURL guesses and returns reference URL.
-func (sf *SubModuleFile) RefURL(urlPrefix string, repoFullName string) string {
-	return getRefURL(sf.refURL, urlPrefix, repoFullName)
+func (sf *SubModuleFile) RefURL(urlPrefix, repoFullName, sshDomain string) string {
+	return getRefURL(sf.refURL, urlPrefix, repoFullName, sshDomain)
 }
 
 // RefID returns reference ID.
@@ -7,6 +7,7 @@ package git
 
 import (
 	"io"
+	"path"
 	"strings"
 
 	"github.com/go-git/go-git/v5/plumbing"
@@ -128,3 +129,36 @@ func (t *Tree) ListEntriesRecursive() (Entries, error) {
 
 	return entries, nil
 }
+
+// ListEntriesWithDepth returns the entries of current tree and of its subtrees up to depth levels
+// deep, the entries of subtrees directly following the entry of their tree. A depth of 1 or less
+// returns the entries of current tree only.
+func (t *Tree) ListEntriesWithDepth(depth int) (Entries, error) {
+	entries, err := t.ListEntries()
+	if err != nil || depth <= 1 {
+		return entries, err
+	}
+
+	result := make(Entries, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry)
+		if !entry.IsDir() {
+			continue
+		}
+
+		subTree, err := t.repo.getTree(entry.ID)
+		if err != nil {
+			return nil, err
+		}
+		subEntries, err := subTree.ListEntriesWithDepth(depth - 1)
+		if err != nil {
+			return nil, err
+		}
+		for _, subEntry := range subEntries {
+			subEntry.fullName = path.Join(entry.Name(), subEntry.Name())
+			subEntry.ptree = t
+			result = append(result, subEntry)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree_ListEntriesWithDepth(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	repo, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	commit, err := repo.GetBranchCommit("master")
+	assert.NoError(t, err)
+
+	names := func(depth int) []string {
+		entries, err := commit.Tree.ListEntriesWithDepth(depth)
+		assert.NoError(t, err)
+		names := make([]string, len(entries))
+		for i, entry := range entries {
+			names[i] = entry.Name()
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"file1.txt", "file2.txt", "foo"}, names(1))
+	assert.Equal(t, []string{"file1.txt", "file2.txt", "foo", "foo/bar", "foo/broken_link", "foo/link_short", "foo/nar", "foo/outside_repo"}, names(2))
+	assert.Equal(t, []string{"file1.txt", "file2.txt", "foo", "foo/bar", "foo/bar/link_to_hello", "foo/broken_link", "foo/link_short", "foo/nar", "foo/nar/hello", "foo/outside_repo"}, names(3))
+}
@@ -14,6 +14,8 @@ import (
 
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/mcuadros/go-version"
 )
 
 // GraphItem represent one commit, or one relation in timeline
@@ -28,6 +30,8 @@ type GraphItem struct {
 	ShortRev     string
 	Subject      string
 	OnlyRelation bool
+	// Source is the reference the commit was reached from, it is only set by GetCommitGraphWithSource
+	Source string
 }
 
 // GraphItems is a list of commits from all branches
@@ -35,7 +39,23 @@ type GraphItems []GraphItem
 
 // GetCommitGraph return a list of commit (GraphItems) from all branches
 func GetCommitGraph(r *git.Repository, page int) (GraphItems, error) {
+	return getCommitGraph(r, page, false)
+}
+
+// GetCommitGraphWithSource return a list of commit (GraphItems) from all branches along with
+// the reference each commit was reached from, the source is left empty with git before 2.21.
+func GetCommitGraphWithSource(r *git.Repository, page int) (GraphItems, error) {
+	return getCommitGraph(r, page, true)
+}
+
+func getCommitGraph(r *git.Repository, page int, withSource bool) (GraphItems, error) {
 	format := "DATA:|%d|%H|%ad|%an|%ae|%h|%s"
+	if withSource {
+		format = "DATA:|%d|%H|%ad|%an|%ae|%h||%s"
+		if gitVersion, err := git.BinVersion(); err == nil && version.Compare(gitVersion, "2.21", ">=") {
+			format = "DATA:|%d|%H|%ad|%an|%ae|%h|%S|%s"
+		}
+	}
 
 	if page == 0 {
 		page = 1
@@ -51,6 +71,9 @@ func GetCommitGraph(r *git.Repository, page int) (GraphItems, error) {
 		"--date=iso",
 		fmt.Sprintf("--pretty=format:%s", format),
 	)
+	if withSource {
+		graphCmd.AddArguments("--source")
+	}
 	commitGraph := make([]GraphItem, 0, 100)
 	stderr := new(strings.Builder)
 	stdoutReader, stdoutWriter, err := os.Pipe()
@@ -76,7 +99,7 @@ func GetCommitGraph(r *git.Repository, page int) (GraphItems, error) {
 		for scanner.Scan() {
 			if bytes.IndexByte(scanner.Bytes(), '*') >= 0 {
 				line := scanner.Text()
-				graphItem, err := graphItemFromString(line, r)
+				graphItem, err := parseGraphItem(line, withSource)
 				if err != nil {
 					cancel()
 					return err
@@ -88,7 +111,7 @@ func GetCommitGraph(r *git.Repository, page int) (GraphItems, error) {
 
 		for scanner.Scan() {
 			line := scanner.Text()
-			graphItem, err := graphItemFromString(line, r)
+			graphItem, err := parseGraphItem(line, withSource)
 			if err != nil {
 				cancel()
 				return err
@@ -104,9 +127,19 @@ func GetCommitGraph(r *git.Repository, page int) (GraphItems, error) {
 }
 
 func graphItemFromString(s string, r *git.Repository) (GraphItem, error) {
+	return parseGraphItem(s, false)
+}
+
+// parseGraphItem parses a line of the graph, the source of the commit is expected
+// right before the subject if withSource is set.
+func parseGraphItem(s string, withSource bool) (GraphItem, error) {
+	numFields := 8
+	if withSource {
+		numFields = 9
+	}
 
 	var ascii string
-	var data = "|||||||"
+	var data = strings.Repeat("|", numFields-1)
 	lines := strings.SplitN(s, "DATA:", 2)
 
 	switch len(lines) {
@@ -119,9 +152,14 @@ func graphItemFromString(s string, r *git.Repository) (GraphItem, error) {
 		return GraphItem{}, fmt.Errorf("Failed parsing grap line:%s. Expect 1 or two fields", s)
 	}
 
-	rows := strings.SplitN(data, "|", 8)
-	if len(rows) < 8 {
-		return GraphItem{}, fmt.Errorf("Failed parsing grap line:%s - Should containt 8 datafields", s)
+	rows := strings.SplitN(data, "|", numFields)
+	if len(rows) < numFields {
+		return GraphItem{}, fmt.Errorf("Failed parsing grap line:%s - Should containt %d datafields", s, numFields)
+	}
+	var source string
+	if withSource {
+		source = rows[7]
+		rows = append(rows[:7], rows[8])
 	}
 
 	/* // see format in getCommitGraph()
@@ -144,6 +182,7 @@ func graphItemFromString(s string, r *git.Repository) (GraphItem, error) {
 		rows[6],
 		rows[7],
 		len(rows[2]) == 0, // no commits referred to, only relation in current line.
+		source,
 	}
 	return gi, nil
 }
@@ -74,3 +74,25 @@ func TestCommitStringParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestCommitStringParsingWithSource(t *testing.T) {
+	testString := "* DATA:| (user2/repo1/master)|4e61bacab44e9b4730e44a6615d04098dd3a8eaf|2016-12-20 21:10:41 +0100|Author|user@mail.something|4e61bac|refs/heads/user2/repo1/master|An extra pipe: |"
+	graphItem, err := parseGraphItem(testString, true)
+	if err != nil {
+		t.Fatalf("Could not parse %s", testString)
+	}
+	if graphItem.Source != "refs/heads/user2/repo1/master" {
+		t.Errorf("%s does not match the source", graphItem.Source)
+	}
+	if graphItem.Subject != "An extra pipe: |" {
+		t.Errorf("%s does not match the subject", graphItem.Subject)
+	}
+	if graphItem.ShortRev != "4e61bac" {
+		t.Errorf("%s does not match the short revision", graphItem.ShortRev)
+	}
+
+	graphItem, err = parseGraphItem("| |", true)
+	if err != nil || !graphItem.OnlyRelation {
+		t.Errorf("Could not parse relation only line")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -110,8 +111,17 @@ func (g *Manager) handleSignals(ctx context.Context) {
 		case sig := <-signalChannel:
 			switch sig {
 			case syscall.SIGHUP:
-				log.Info("PID: %d. Received SIGHUP. Attempting GracefulRestart...", pid)
-				g.DoGracefulRestart()
+				if !setting.ReloadOnSIGHUP {
+					log.Info("PID: %d. Received SIGHUP. Attempting GracefulRestart...", pid)
+					g.DoGracefulRestart()
+					break
+				}
+				log.Info("PID: %d. Received SIGHUP. Reloading settings...", pid)
+				if result, err := setting.ReloadSettings(); err != nil {
+					log.Error("Error whilst reloading settings: %v", err)
+				} else if len(result.RestartRequired) > 0 {
+					log.Warn("PID: %d. Changed settings requiring a restart: %s", pid, strings.Join(result.RestartRequired, ", "))
+				}
 			case syscall.SIGUSR1:
 				log.Warn("PID %d. Received SIGUSR1. Releasing and reopening logs", pid)
 				if err := log.ReleaseReopen(); err != nil {
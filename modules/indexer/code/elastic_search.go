@@ -0,0 +1,323 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/analyze"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/charset"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/indexer/internal/elasticsearch"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-enry/go-enry/v2"
+	"github.com/olivere/elastic/v7"
+)
+
+const (
+	// elasticRepoIndexerLatestVersion is the version of the mapping, the
+	// index is rebuilt in the background whenever it changes
+	elasticRepoIndexerLatestVersion = 1
+
+	elasticRepoIndexerMapping = `{
+		"mappings": {
+			"properties": {
+				"repo_id": {
+					"type": "long",
+					"index": true
+				},
+				"filename": {
+					"type": "keyword",
+					"index": false
+				},
+				"content": {
+					"type": "text",
+					"term_vector": "with_positions_offsets",
+					"index": true
+				},
+				"commit_id": {
+					"type": "keyword",
+					"index": true
+				},
+				"language": {
+					"type": "keyword",
+					"index": true
+				},
+				"updated_at": {
+					"type": "long",
+					"index": false
+				}
+			}
+		}
+	}`
+
+	// The highlighted terms are marked with characters of the private use
+	// area, which do not occur in source code
+	highlightPreTag  = "\ue000"
+	highlightPostTag = "\ue001"
+)
+
+var (
+	_ Indexer = &ElasticSearchIndexer{}
+)
+
+// ElasticSearchIndexer implements Indexer interface
+type ElasticSearchIndexer struct {
+	client *elastic.Client
+	index  *elasticsearch.Index
+}
+
+// elasticRepoIndexerData represents a file stored in the repo indexer
+type elasticRepoIndexerData struct {
+	RepoID    int64  `json:"repo_id"`
+	Filename  string `json:"filename"`
+	Content   string `json:"content"`
+	CommitID  string `json:"commit_id"`
+	Language  string `json:"language"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// NewElasticSearchIndexer creates a new elasticsearch indexer. It returns
+// true if the index was created and has to be populated.
+func NewElasticSearchIndexer(url, indexerName string) (*ElasticSearchIndexer, bool, error) {
+	client, err := elasticsearch.NewClient(url)
+	if err != nil {
+		return nil, false, err
+	}
+
+	indexer := &ElasticSearchIndexer{
+		client: client,
+		index: elasticsearch.NewIndex(client, indexerName, elasticRepoIndexerLatestVersion, elasticRepoIndexerMapping,
+			setting.Indexer.RepoShards, setting.Indexer.RepoReplicas),
+	}
+	exists, err := indexer.index.Init()
+	if err != nil {
+		return nil, false, err
+	}
+	return indexer, !exists, nil
+}
+
+// elasticDocumentID returns the ID of the document of the file. The filename
+// is hashed as IDs are limited to 512 bytes.
+func elasticDocumentID(repoID int64, filename string) string {
+	return strconv.FormatInt(repoID, 10) + "_" + base.EncodeSha1(filename)
+}
+
+func (b *ElasticSearchIndexer) addUpdate(sha string, update fileUpdate, repo *models.Repository) ([]elastic.BulkableRequest, error) {
+	// Ignore vendored files in code search
+	if setting.Indexer.ExcludeVendored && enry.IsVendor(update.Filename) {
+		return nil, nil
+	}
+	stdout, err := git.NewCommand("cat-file", "-s", update.BlobSha).
+		RunInDir(repo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	if size, err := strconv.Atoi(strings.TrimSpace(stdout)); err != nil {
+		return nil, fmt.Errorf("Misformatted git cat-file output: %v", err)
+	} else if int64(size) > setting.Indexer.MaxIndexerFileSize {
+		return b.addDelete(update.Filename, repo), nil
+	}
+
+	fileContents, err := git.NewCommand("cat-file", "blob", update.BlobSha).
+		RunInDirBytes(repo.RepoPath())
+	if err != nil {
+		return nil, err
+	} else if !base.IsTextFile(fileContents) {
+		// FIXME: UTF-16 files will probably fail here
+		return nil, nil
+	}
+
+	id := elasticDocumentID(repo.ID, update.Filename)
+	data := &elasticRepoIndexerData{
+		RepoID:    repo.ID,
+		Filename:  update.Filename,
+		Content:   string(charset.ToUTF8DropErrors(fileContents)),
+		CommitID:  sha,
+		Language:  analyze.GetCodeLanguage(update.Filename, fileContents),
+		UpdatedAt: time.Now().Unix(),
+	}
+	indices := b.index.WriteIndices()
+	reqs := make([]elastic.BulkableRequest, 0, len(indices))
+	for _, indexName := range indices {
+		reqs = append(reqs, elastic.NewBulkIndexRequest().
+			Index(indexName).
+			Id(id).
+			Doc(data))
+	}
+	return reqs, nil
+}
+
+func (b *ElasticSearchIndexer) addDelete(filename string, repo *models.Repository) []elastic.BulkableRequest {
+	id := elasticDocumentID(repo.ID, filename)
+	indices := b.index.WriteIndices()
+	reqs := make([]elastic.BulkableRequest, 0, len(indices))
+	for _, indexName := range indices {
+		reqs = append(reqs, elastic.NewBulkDeleteRequest().
+			Index(indexName).
+			Id(id))
+	}
+	return reqs
+}
+
+// Index indexes the data
+func (b *ElasticSearchIndexer) Index(repoID int64) error {
+	repo, err := models.GetRepositoryByID(repoID)
+	if err != nil {
+		return err
+	}
+
+	sha, err := getDefaultBranchSha(repo)
+	if err != nil {
+		return err
+	}
+	changes, err := getRepoChanges(repo, sha)
+	if err != nil {
+		return err
+	} else if changes == nil {
+		return nil
+	}
+
+	reqs := make([]elastic.BulkableRequest, 0)
+	for _, update := range changes.Updates {
+		updateReqs, err := b.addUpdate(sha, update, repo)
+		if err != nil {
+			return err
+		}
+		reqs = append(reqs, updateReqs...)
+	}
+	for _, filename := range changes.RemovedFilenames {
+		reqs = append(reqs, b.addDelete(filename, repo)...)
+	}
+
+	if len(reqs) > 0 {
+		if _, err = b.client.Bulk().
+			Add(reqs...).
+			Do(context.Background()); err != nil {
+			return err
+		}
+	}
+	return repo.UpdateIndexerStatus(models.RepoIndexerTypeCode, sha)
+}
+
+// Delete deletes indexes by ids
+func (b *ElasticSearchIndexer) Delete(repoID int64) error {
+	_, err := b.client.DeleteByQuery(b.index.WriteIndices()...).
+		Query(elastic.NewTermsQuery("repo_id", repoID)).
+		Do(context.Background())
+	return err
+}
+
+// highlightRange returns the byte offsets of the first and the last
+// highlighted term in the content, after the highlight tags are removed
+func highlightRange(highlighted string) (int, int) {
+	startIndex, endIndex := -1, -1
+	removed := 0
+	for i := 0; i < len(highlighted); {
+		if strings.HasPrefix(highlighted[i:], highlightPreTag) {
+			if startIndex < 0 {
+				startIndex = i - removed
+			}
+			removed += len(highlightPreTag)
+			i += len(highlightPreTag)
+			continue
+		}
+		if strings.HasPrefix(highlighted[i:], highlightPostTag) {
+			endIndex = i - removed
+			removed += len(highlightPostTag)
+			i += len(highlightPostTag)
+			continue
+		}
+		i++
+	}
+	return startIndex, endIndex
+}
+
+// Search searches for codes and language stats by given conditions.
+func (b *ElasticSearchIndexer) Search(repoIDs []int64, language, keyword string, page, pageSize int) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+	query := elastic.NewBoolQuery().Must(elastic.NewMatchPhraseQuery("content", keyword))
+	if len(repoIDs) > 0 {
+		var repoStrs = make([]interface{}, 0, len(repoIDs))
+		for _, repoID := range repoIDs {
+			repoStrs = append(repoStrs, repoID)
+		}
+		query = query.Must(elastic.NewTermsQuery("repo_id", repoStrs...))
+	}
+
+	start := 0
+	if page > 0 {
+		start = (page - 1) * pageSize
+	}
+	search := b.client.Search().
+		Index(b.index.Alias()).
+		Query(query).
+		Aggregation("language", elastic.NewTermsAggregation().Field("language").Size(10)).
+		Highlight(elastic.NewHighlight().
+			Field("content").
+			NumOfFragments(0).
+			PreTags(highlightPreTag).
+			PostTags(highlightPostTag)).
+		From(start).Size(pageSize)
+	if len(language) > 0 {
+		// The language counts are aggregated before the filter
+		search = search.PostFilter(elastic.NewTermQuery("language", language))
+	}
+	searchResult, err := search.Do(context.Background())
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	hits := make([]*SearchResult, 0, len(searchResult.Hits.Hits))
+	for _, hit := range searchResult.Hits.Hits {
+		var data elasticRepoIndexerData
+		if err := json.Unmarshal(hit.Source, &data); err != nil {
+			return 0, nil, nil, err
+		}
+		startIndex, endIndex := -1, -1
+		if highlighted, ok := hit.Highlight["content"]; ok && len(highlighted) > 0 {
+			startIndex, endIndex = highlightRange(highlighted[0])
+		}
+		hits = append(hits, &SearchResult{
+			RepoID:      data.RepoID,
+			StartIndex:  startIndex,
+			EndIndex:    endIndex,
+			Filename:    data.Filename,
+			Content:     data.Content,
+			CommitID:    data.CommitID,
+			UpdatedUnix: timeutil.TimeStamp(data.UpdatedAt),
+			Language:    data.Language,
+			Color:       enry.GetColor(data.Language),
+		})
+	}
+
+	searchResultLanguages := make([]*SearchResultLanguages, 0, 10)
+	if languages, found := searchResult.Aggregations.Terms("language"); found {
+		for _, bucket := range languages.Buckets {
+			term, _ := bucket.Key.(string)
+			if len(term) == 0 {
+				continue
+			}
+			searchResultLanguages = append(searchResultLanguages, &SearchResultLanguages{
+				Language: term,
+				Color:    enry.GetColor(term),
+				Count:    int(bucket.DocCount),
+			})
+		}
+	}
+
+	return searchResult.TotalHits(), hits, searchResultLanguages, nil
+}
+
+// Close implements indexer
+func (b *ElasticSearchIndexer) Close() {}
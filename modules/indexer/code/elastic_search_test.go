@@ -0,0 +1,23 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlightRange(t *testing.T) {
+	content := "package main\n\nfunc main() {\n\tprintln(\"hello world\")\n}\n"
+	highlighted := "package main\n\nfunc main() {\n\tprintln(\"" + highlightPreTag + "hello" + highlightPostTag + " " + highlightPreTag + "world" + highlightPostTag + "\")\n}\n"
+
+	start, end := highlightRange(highlighted)
+	assert.Equal(t, "hello world", content[start:end])
+
+	start, end = highlightRange(content)
+	assert.Equal(t, -1, start)
+	assert.Equal(t, -1, end)
+}
@@ -6,6 +6,7 @@ package code
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"time"
 
@@ -43,6 +44,35 @@ type Indexer interface {
 	Close()
 }
 
+// newIndexer creates the configured repo indexer. It returns true if the
+// index was created and has to be populated.
+func newIndexer() (Indexer, bool, error) {
+	switch setting.Indexer.RepoType {
+	case "elasticsearch":
+		esIndexer, created, err := NewElasticSearchIndexer(setting.Indexer.RepoConnStr, setting.Indexer.RepoIndexerName)
+		if err != nil {
+			return nil, false, err
+		}
+		return esIndexer, created, nil
+	case "meilisearch":
+		meiliIndexer, created, err := NewMeilisearchIndexer(setting.Indexer.RepoConnStr, setting.Indexer.RepoIndexerName)
+		if err != nil {
+			return nil, false, err
+		}
+		return meiliIndexer, created, nil
+	case "bleve":
+		bleveIndexer, created, err := NewBleveIndexer(setting.Indexer.RepoPath)
+		if err != nil {
+			if bleveIndexer != nil {
+				bleveIndexer.Close()
+			}
+			return nil, false, err
+		}
+		return bleveIndexer, created, nil
+	}
+	return nil, false, fmt.Errorf("unknown repo indexer type: %s", setting.Indexer.RepoType)
+}
+
 // Init initialize the repo indexer
 func Init() {
 	if !setting.Indexer.RepoIndexerEnabled {
@@ -63,7 +93,7 @@ func Init() {
 	waitChannel := make(chan time.Duration)
 	go func() {
 		start := time.Now()
-		log.Info("PID: %d Initializing Repository Indexer at: %s", os.Getpid(), setting.Indexer.RepoPath)
+		log.Info("PID: %d Initializing %s Repository Indexer", os.Getpid(), setting.Indexer.RepoType)
 		defer func() {
 			if err := recover(); err != nil {
 				log.Error("PANIC whilst initializing repository indexer: %v\nStacktrace: %s", err, log.Stack(2))
@@ -75,17 +105,14 @@ func Init() {
 				log.Fatal("PID: %d Unable to initialize the Repository Indexer at path: %s Error: %v", os.Getpid(), setting.Indexer.RepoPath, err)
 			}
 		}()
-		bleveIndexer, created, err := NewBleveIndexer(setting.Indexer.RepoPath)
+		repoIndexer, created, err := newIndexer()
 		if err != nil {
-			if bleveIndexer != nil {
-				bleveIndexer.Close()
-			}
 			cancel()
 			indexer.Close()
 			close(waitChannel)
-			log.Fatal("PID: %d Unable to initialize the Repository Indexer at path: %s Error: %v", os.Getpid(), setting.Indexer.RepoPath, err)
+			log.Fatal("PID: %d Unable to initialize the %s Repository Indexer Error: %v", os.Getpid(), setting.Indexer.RepoType, err)
 		}
-		indexer.set(bleveIndexer)
+		indexer.set(repoIndexer)
 
 		go processRepoIndexerOperationQueue(indexer)
 
@@ -0,0 +1,278 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/analyze"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/charset"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/indexer/internal/meilisearch"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-enry/go-enry/v2"
+)
+
+var (
+	_ Indexer = &MeilisearchIndexer{}
+)
+
+// MeilisearchIndexer implements Indexer interface
+type MeilisearchIndexer struct {
+	client      *meilisearch.Client
+	indexerName string
+}
+
+// meilisearchRepoIndexerData represents a file stored in the repo indexer
+type meilisearchRepoIndexerData struct {
+	ID        string `json:"id"`
+	RepoID    int64  `json:"repo_id"`
+	Filename  string `json:"filename"`
+	Content   string `json:"content"`
+	CommitID  string `json:"commit_id"`
+	Language  string `json:"language"`
+	UpdatedAt int64  `json:"updated_at"`
+
+	MatchesPosition map[string][]meilisearch.MatchPosition `json:"_matchesPosition,omitempty"`
+}
+
+// NewMeilisearchIndexer creates a new meilisearch indexer. It returns true if
+// the index was created and has to be populated.
+func NewMeilisearchIndexer(connStr, indexerName string) (*MeilisearchIndexer, bool, error) {
+	client, err := meilisearch.NewClient(connStr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	exists, err := client.InitIndex(indexerName, "id", &meilisearch.Settings{
+		SearchableAttributes: []string{"content"},
+		FilterableAttributes: []string{"repo_id", "language"},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &MeilisearchIndexer{
+		client:      client,
+		indexerName: indexerName,
+	}, !exists, nil
+}
+
+// meilisearchDocumentID returns the ID of the document of the file. The
+// filename is hashed as IDs may only contain alphanumeric characters, hyphens
+// and underscores.
+func meilisearchDocumentID(repoID int64, filename string) string {
+	return strconv.FormatInt(repoID, 10) + "_" + base.EncodeSha1(filename)
+}
+
+// addUpdate returns the document of the updated file, or nil if the file is
+// not indexed. It returns true if the file has to be removed from the index.
+func (b *MeilisearchIndexer) addUpdate(sha string, update fileUpdate, repo *models.Repository) (*meilisearchRepoIndexerData, bool, error) {
+	// Ignore vendored files in code search
+	if setting.Indexer.ExcludeVendored && enry.IsVendor(update.Filename) {
+		return nil, false, nil
+	}
+	stdout, err := git.NewCommand("cat-file", "-s", update.BlobSha).
+		RunInDir(repo.RepoPath())
+	if err != nil {
+		return nil, false, err
+	}
+	if size, err := strconv.Atoi(strings.TrimSpace(stdout)); err != nil {
+		return nil, false, fmt.Errorf("Misformatted git cat-file output: %v", err)
+	} else if int64(size) > setting.Indexer.MaxIndexerFileSize {
+		return nil, true, nil
+	}
+
+	fileContents, err := git.NewCommand("cat-file", "blob", update.BlobSha).
+		RunInDirBytes(repo.RepoPath())
+	if err != nil {
+		return nil, false, err
+	} else if !base.IsTextFile(fileContents) {
+		// FIXME: UTF-16 files will probably fail here
+		return nil, false, nil
+	}
+
+	return &meilisearchRepoIndexerData{
+		ID:        meilisearchDocumentID(repo.ID, update.Filename),
+		RepoID:    repo.ID,
+		Filename:  update.Filename,
+		Content:   string(charset.ToUTF8DropErrors(fileContents)),
+		CommitID:  sha,
+		Language:  analyze.GetCodeLanguage(update.Filename, fileContents),
+		UpdatedAt: time.Now().Unix(),
+	}, false, nil
+}
+
+// Index indexes the data
+func (b *MeilisearchIndexer) Index(repoID int64) error {
+	repo, err := models.GetRepositoryByID(repoID)
+	if err != nil {
+		return err
+	}
+
+	sha, err := getDefaultBranchSha(repo)
+	if err != nil {
+		return err
+	}
+	changes, err := getRepoChanges(repo, sha)
+	if err != nil {
+		return err
+	} else if changes == nil {
+		return nil
+	}
+
+	docs := make([]*meilisearchRepoIndexerData, 0, len(changes.Updates))
+	deletes := make([]interface{}, 0, len(changes.RemovedFilenames))
+	for _, update := range changes.Updates {
+		doc, remove, err := b.addUpdate(sha, update, repo)
+		if err != nil {
+			return err
+		}
+		if remove {
+			deletes = append(deletes, meilisearchDocumentID(repo.ID, update.Filename))
+		} else if doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+	for _, filename := range changes.RemovedFilenames {
+		deletes = append(deletes, meilisearchDocumentID(repo.ID, filename))
+	}
+
+	if len(docs) > 0 {
+		if err = b.client.AddDocuments(b.indexerName, docs); err != nil {
+			return err
+		}
+	}
+	if len(deletes) > 0 {
+		if err = b.client.DeleteDocuments(b.indexerName, deletes); err != nil {
+			return err
+		}
+	}
+	return repo.UpdateIndexerStatus(models.RepoIndexerTypeCode, sha)
+}
+
+// Delete deletes indexes by ids
+func (b *MeilisearchIndexer) Delete(repoID int64) error {
+	return b.client.DeleteDocumentsByFilter(b.indexerName, fmt.Sprintf("repo_id = %d", repoID))
+}
+
+// matchRange returns the byte offsets of the first and the last match in the
+// content
+func matchRange(positions []meilisearch.MatchPosition) (int, int) {
+	startIndex, endIndex := -1, -1
+	for _, position := range positions {
+		if startIndex < 0 || position.Start < startIndex {
+			startIndex = position.Start
+		}
+		if end := position.Start + position.Length; end > endIndex {
+			endIndex = end
+		}
+	}
+	return startIndex, endIndex
+}
+
+// Search searches for codes and language stats by given conditions.
+func (b *MeilisearchIndexer) Search(repoIDs []int64, language, keyword string, page, pageSize int) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+	var repoFilter string
+	if len(repoIDs) > 0 {
+		repoFilter = meilisearch.FilterIn("repo_id", repoIDs)
+	}
+
+	start := 0
+	if page > 0 {
+		start = (page - 1) * pageSize
+	}
+	// Search for the keyword as a phrase like the other indexers
+	query := `"` + strings.Replace(keyword, `"`, " ", -1) + `"`
+	search := &meilisearch.SearchRequest{
+		Query:               query,
+		Filter:              repoFilter,
+		Offset:              start,
+		Limit:               pageSize,
+		Facets:              []string{"language"},
+		ShowMatchesPosition: true,
+	}
+	if len(language) > 0 {
+		languageFilter := fmt.Sprintf("language = %q", language)
+		if len(repoFilter) > 0 {
+			search.Filter = repoFilter + " AND " + languageFilter
+		} else {
+			search.Filter = languageFilter
+		}
+	}
+	searchResult, err := b.client.Search(b.indexerName, search)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	hits := make([]*SearchResult, 0, len(searchResult.Hits))
+	for _, hit := range searchResult.Hits {
+		var data meilisearchRepoIndexerData
+		if err := json.Unmarshal(hit, &data); err != nil {
+			return 0, nil, nil, err
+		}
+		startIndex, endIndex := matchRange(data.MatchesPosition["content"])
+		hits = append(hits, &SearchResult{
+			RepoID:      data.RepoID,
+			StartIndex:  startIndex,
+			EndIndex:    endIndex,
+			Filename:    data.Filename,
+			Content:     data.Content,
+			CommitID:    data.CommitID,
+			UpdatedUnix: timeutil.TimeStamp(data.UpdatedAt),
+			Language:    data.Language,
+			Color:       enry.GetColor(data.Language),
+		})
+	}
+
+	// The language counts are computed without the language filter
+	facets := searchResult.FacetDistribution["language"]
+	if len(language) > 0 {
+		facetResult, err := b.client.Search(b.indexerName, &meilisearch.SearchRequest{
+			Query:  query,
+			Filter: repoFilter,
+			Limit:  0,
+			Facets: []string{"language"},
+		})
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		facets = facetResult.FacetDistribution["language"]
+	}
+
+	searchResultLanguages := make([]*SearchResultLanguages, 0, len(facets))
+	for term, count := range facets {
+		if len(term) == 0 {
+			continue
+		}
+		searchResultLanguages = append(searchResultLanguages, &SearchResultLanguages{
+			Language: term,
+			Color:    enry.GetColor(term),
+			Count:    count,
+		})
+	}
+	sort.Slice(searchResultLanguages, func(i, j int) bool {
+		if searchResultLanguages[i].Count != searchResultLanguages[j].Count {
+			return searchResultLanguages[i].Count > searchResultLanguages[j].Count
+		}
+		return searchResultLanguages[i].Language < searchResultLanguages[j].Language
+	})
+	if len(searchResultLanguages) > 10 {
+		searchResultLanguages = searchResultLanguages[:10]
+	}
+
+	return searchResult.EstimatedTotalHits, hits, searchResultLanguages, nil
+}
+
+// Close implements indexer
+func (b *MeilisearchIndexer) Close() {}
@@ -0,0 +1,27 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/indexer/internal/meilisearch"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchRange(t *testing.T) {
+	content := "package main\n\nfunc main() {\n\tprintln(\"hello world\")\n}\n"
+
+	start, end := matchRange([]meilisearch.MatchPosition{
+		{Start: 44, Length: 5},
+		{Start: 38, Length: 5},
+	})
+	assert.Equal(t, "hello world", content[start:end])
+
+	start, end = matchRange(nil)
+	assert.Equal(t, -1, start)
+	assert.Equal(t, -1, end)
+}
@@ -25,6 +25,11 @@ func initQueue(queueLength int) {
 	repoIndexerOperationQueue = make(chan repoIndexerOperation, queueLength)
 }
 
+// QueueLength returns the number of repositories waiting to be indexed
+func QueueLength() int {
+	return len(repoIndexerOperationQueue)
+}
+
 func processRepoIndexerOperationQueue(indexer Indexer) {
 	for {
 		select {
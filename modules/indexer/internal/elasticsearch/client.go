@@ -0,0 +1,135 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/olivere/elastic/v7"
+)
+
+// compatibleMediaType asks Elasticsearch 8 and later to accept and answer
+// requests like version 7, whose API the client implements
+const compatibleMediaType = "application/vnd.elasticsearch+json;compatible-with=7"
+
+type elasticLogger struct {
+	*log.Logger
+}
+
+func (l elasticLogger) Printf(format string, args ...interface{}) {
+	_ = l.Logger.Log(2, l.Logger.GetLevel(), format, args...)
+}
+
+// compatibleTransport sets the media types of the compatibility mode for
+// version 7 on every request
+type compatibleTransport struct {
+	base http.RoundTripper
+}
+
+func (t compatibleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept", compatibleMediaType)
+	if req.Header.Get("Content-Type") != "" {
+		req.Header.Set("Content-Type", compatibleMediaType)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// ServerVersion represents the version reported by an Elasticsearch or
+// OpenSearch server
+type ServerVersion struct {
+	Number       string `json:"number"`
+	Distribution string `json:"distribution"`
+}
+
+// IsOpenSearch returns if the server is an OpenSearch server
+func (v ServerVersion) IsOpenSearch() bool {
+	return v.Distribution == "opensearch"
+}
+
+// Major returns the major version of the server
+func (v ServerVersion) Major() int {
+	major, _ := strconv.Atoi(strings.SplitN(v.Number, ".", 2)[0])
+	return major
+}
+
+// needsCompatibility returns if the server has to be asked for the
+// compatibility mode for version 7
+func (v ServerVersion) needsCompatibility() bool {
+	return !v.IsOpenSearch() && v.Major() >= 8
+}
+
+func clientOptions(url string) []elastic.ClientOptionFunc {
+	opts := []elastic.ClientOptionFunc{
+		elastic.SetURL(url),
+		elastic.SetSniff(false),
+		elastic.SetHealthcheckInterval(10 * time.Second),
+		elastic.SetGzip(false),
+	}
+
+	logger := elasticLogger{log.GetLogger(log.DEFAULT)}
+
+	if logger.GetLevel() == log.TRACE || logger.GetLevel() == log.DEBUG {
+		opts = append(opts, elastic.SetTraceLog(logger))
+	} else if logger.GetLevel() == log.ERROR || logger.GetLevel() == log.CRITICAL || logger.GetLevel() == log.FATAL {
+		opts = append(opts, elastic.SetErrorLog(logger))
+	} else if logger.GetLevel() == log.INFO || logger.GetLevel() == log.WARN {
+		opts = append(opts, elastic.SetInfoLog(logger))
+	}
+	return opts
+}
+
+// NewClient creates a client for the Elasticsearch or OpenSearch server at
+// the given url. Elasticsearch 8 and later is used in the compatibility mode
+// for version 7.
+func NewClient(url string) (*elastic.Client, error) {
+	client, err := elastic.NewClient(clientOptions(url)...)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := GetServerVersion(client)
+	if err != nil {
+		return nil, err
+	}
+	if version.IsOpenSearch() {
+		log.Info("Connected to OpenSearch %s", version.Number)
+	} else {
+		log.Info("Connected to Elasticsearch %s", version.Number)
+	}
+	if !version.needsCompatibility() {
+		return client, nil
+	}
+
+	client.Stop()
+	return elastic.NewClient(append(clientOptions(url), elastic.SetHttpClient(&http.Client{
+		Transport: compatibleTransport{http.DefaultTransport},
+	}))...)
+}
+
+// GetServerVersion returns the version of the server
+func GetServerVersion(client *elastic.Client) (ServerVersion, error) {
+	res, err := client.PerformRequest(context.Background(), elastic.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/",
+	})
+	if err != nil {
+		return ServerVersion{}, err
+	}
+	var info struct {
+		Version ServerVersion `json:"version"`
+	}
+	if err = json.Unmarshal(res.Body, &info); err != nil {
+		return ServerVersion{}, err
+	}
+	return info.Version, nil
+}
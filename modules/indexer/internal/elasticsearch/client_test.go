@@ -0,0 +1,58 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClient(t *testing.T) {
+	for _, test := range []struct {
+		version      string
+		distribution string
+		compatible   bool
+	}{
+		{"7.10.2", "", false},
+		{"8.1.0", "", true},
+		{"2.0.0", "opensearch", false},
+	} {
+		var lock sync.Mutex
+		var accept string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			accept = r.Header.Get("Accept")
+			lock.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"version":{"number":%q,"distribution":%q}}`, test.version, test.distribution)
+		}))
+
+		client, err := NewClient(server.URL)
+		assert.NoError(t, err)
+		version, err := GetServerVersion(client)
+		assert.NoError(t, err)
+		assert.Equal(t, test.version, version.Number)
+		assert.Equal(t, test.distribution == "opensearch", version.IsOpenSearch())
+
+		_, err = client.IndexExists("gitea_issues").Do(context.Background())
+		assert.NoError(t, err)
+		lock.Lock()
+		if test.compatible {
+			assert.Equal(t, compatibleMediaType, accept)
+		} else {
+			assert.Equal(t, "application/json", accept)
+		}
+		lock.Unlock()
+
+		client.Stop()
+		server.Close()
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/olivere/elastic/v7"
+)
+
+// Index is an index, which is searched through an alias. Whenever its mapping
+// changes, a new version of the index is built in the background from the
+// documents of the old one and the alias switched to it once it is complete.
+type Index struct {
+	client   *elastic.Client
+	alias    string
+	version  int
+	mapping  string
+	shards   int
+	replicas int
+
+	lock   sync.RWMutex
+	writes []string
+}
+
+// NewIndex creates an index with the given alias and mapping version. The
+// number of shards is left to the server if shards is 0, the number of
+// replicas if replicas is negative.
+func NewIndex(client *elastic.Client, alias string, version int, mapping string, shards, replicas int) *Index {
+	return &Index{
+		client:   client,
+		alias:    alias,
+		version:  version,
+		mapping:  mapping,
+		shards:   shards,
+		replicas: replicas,
+		writes:   []string{alias},
+	}
+}
+
+// Alias returns the alias used to search the index
+func (i *Index) Alias() string {
+	return i.alias
+}
+
+// WriteIndices returns the indices, which documents have to be written to.
+// While the index is rebuilt documents are written to both the old and the
+// new index.
+func (i *Index) WriteIndices() []string {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return i.writes
+}
+
+func (i *Index) setWriteIndices(names ...string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.writes = names
+}
+
+// versionName returns the name of the index of the given mapping version
+func (i *Index) versionName(version int) string {
+	return fmt.Sprintf("%s.v%d", i.alias, version)
+}
+
+// versionOf returns the mapping version of the named index, which is 0 for
+// the indices created before they were accessed through an alias
+func (i *Index) versionOf(name string) int {
+	version, err := strconv.Atoi(strings.TrimPrefix(name, i.alias+".v"))
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// Init creates the index if it does not exist yet and starts to rebuild it in
+// the background if its mapping is outdated. It returns false if the index was
+// created and has to be populated.
+func (i *Index) Init() (bool, error) {
+	ctx := context.Background()
+	current := i.versionName(i.version)
+
+	indices, err := i.client.IndexGet(i.alias).Do(ctx)
+	if elastic.IsNotFound(err) {
+		if err = i.create(ctx, current, true); err != nil {
+			return false, err
+		}
+		i.setWriteIndices(current)
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	olds := make([]string, 0, len(indices))
+	for name := range indices {
+		if name == current {
+			continue
+		}
+		if i.versionOf(name) > i.version {
+			log.Warn("The index %s was built by a newer version of Gitea, %s is not used", name, current)
+			i.setWriteIndices(i.alias)
+			return true, nil
+		}
+		olds = append(olds, name)
+	}
+	if len(olds) == 0 {
+		i.setWriteIndices(current)
+		return true, nil
+	}
+
+	// A previous rebuild may have been interrupted
+	exists, err := i.client.IndexExists(current).Do(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		if err = i.create(ctx, current, false); err != nil {
+			return false, err
+		}
+	}
+	i.setWriteIndices(append(olds, current)...)
+	go graceful.GetManager().RunWithShutdownContext(func(ctx context.Context) {
+		if err := i.rebuild(ctx, current, olds); err != nil {
+			log.Error("Unable to rebuild the index %s from %v: %v", current, olds, err)
+		}
+	})
+	return true, nil
+}
+
+// indexBody returns the body creating an index with the mapping and settings
+func (i *Index) indexBody(withAlias bool) (map[string]interface{}, error) {
+	body := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(i.mapping), &body); err != nil {
+		return nil, err
+	}
+
+	settings, _ := body["settings"].(map[string]interface{})
+	if settings == nil {
+		settings = map[string]interface{}{}
+	}
+	if i.shards > 0 {
+		settings["number_of_shards"] = i.shards
+	}
+	if i.replicas >= 0 {
+		settings["number_of_replicas"] = i.replicas
+	}
+	if len(settings) > 0 {
+		body["settings"] = settings
+	}
+
+	if withAlias {
+		body["aliases"] = map[string]interface{}{
+			i.alias: map[string]interface{}{},
+		}
+	}
+	return body, nil
+}
+
+func (i *Index) create(ctx context.Context, name string, withAlias bool) error {
+	body, err := i.indexBody(withAlias)
+	if err != nil {
+		return err
+	}
+	res, err := i.client.CreateIndex(name).BodyJson(body).Do(ctx)
+	if err != nil {
+		return err
+	}
+	if !res.Acknowledged {
+		return errors.New("init failed")
+	}
+	return nil
+}
+
+// rebuild copies the documents of the old indices to the current one and
+// switches the alias to it at once
+func (i *Index) rebuild(ctx context.Context, current string, olds []string) error {
+	log.Info("Rebuilding the index %s from %v in the background", current, olds)
+	for _, old := range olds {
+		// Documents written during the rebuild must not be overwritten with
+		// their old versions
+		if _, err := i.client.Reindex().
+			SourceIndex(old).
+			Destination(elastic.NewReindexDestination().Index(current).OpType("create")).
+			ProceedOnVersionConflict().
+			WaitForCompletion(true).
+			Do(ctx); err != nil {
+			return err
+		}
+	}
+
+	actions := make([]elastic.AliasAction, 0, len(olds)+1)
+	deletes := make([]string, 0, len(olds))
+	for _, old := range olds {
+		if old == i.alias {
+			// An index created before the alias has to be removed to add
+			// the alias with its name
+			actions = append(actions, elastic.NewAliasRemoveIndexAction(old))
+			continue
+		}
+		actions = append(actions, elastic.NewAliasRemoveAction(i.alias).Index(old))
+		deletes = append(deletes, old)
+	}
+	actions = append(actions, elastic.NewAliasAddAction(i.alias).Index(current))
+	if _, err := i.client.Alias().Action(actions...).Do(ctx); err != nil {
+		return err
+	}
+	i.setWriteIndices(current)
+
+	if len(deletes) > 0 {
+		if _, err := i.client.DeleteIndex(deletes...).Do(ctx); err != nil {
+			return err
+		}
+	}
+	log.Info("The index %s was rebuilt and replaced %v", current, olds)
+	return nil
+}
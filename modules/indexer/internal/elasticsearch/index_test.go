@@ -0,0 +1,37 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexBody(t *testing.T) {
+	index := NewIndex(nil, "gitea_issues", 2, `{"mappings":{"properties":{"id":{"type":"integer"}}}}`, 3, 1)
+	assert.Equal(t, "gitea_issues.v2", index.versionName(2))
+	assert.Equal(t, 2, index.versionOf("gitea_issues.v2"))
+	assert.Equal(t, 0, index.versionOf("gitea_issues"))
+	assert.Equal(t, []string{"gitea_issues"}, index.WriteIndices())
+
+	body, err := index.indexBody(true)
+	assert.NoError(t, err)
+	assert.Contains(t, body, "mappings")
+	assert.Equal(t, map[string]interface{}{
+		"number_of_shards":   3,
+		"number_of_replicas": 1,
+	}, body["settings"])
+	assert.Equal(t, map[string]interface{}{
+		"gitea_issues": map[string]interface{}{},
+	}, body["aliases"])
+
+	// The server decides the number of shards and replicas by default
+	index = NewIndex(nil, "gitea_issues", 2, `{"mappings":{}}`, 0, -1)
+	body, err = index.indexBody(false)
+	assert.NoError(t, err)
+	assert.NotContains(t, body, "settings")
+	assert.NotContains(t, body, "aliases")
+}
@@ -0,0 +1,292 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package meilisearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client is a client of the REST API of a Meilisearch server
+type Client struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// Error represents an error returned by the Meilisearch server
+type Error struct {
+	StatusCode int
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("meilisearch: %d %s: %s", err.StatusCode, err.Code, err.Message)
+}
+
+// TaskError represents the error of a task, which failed to be processed by
+// the Meilisearch server
+type TaskError struct {
+	TaskUID int64
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (err *TaskError) Error() string {
+	return fmt.Sprintf("meilisearch: task %d failed: %s: %s", err.TaskUID, err.Code, err.Message)
+}
+
+// IsErrNotFound returns if the error is caused by a missing index or document
+func IsErrNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == http.StatusNotFound
+}
+
+// NewClient creates a client for the Meilisearch server at the given
+// connection string. The API key is given as password, e.g.
+// http://:apikey@localhost:7700
+func NewClient(connStr string) (*Client, error) {
+	u, err := url.Parse(connStr)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+	if u.User != nil {
+		c.apiKey, _ = u.User.Password()
+		u.User = nil
+	}
+	c.url = strings.TrimSuffix(u.String(), "/")
+	return c, nil
+}
+
+func (c *Client) request(method, path string, body, result interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, c.url+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if len(c.apiKey) > 0 {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 400 {
+		e := &Error{StatusCode: resp.StatusCode}
+		_ = json.Unmarshal(data, e)
+		return e
+	}
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
+
+const (
+	taskPollInterval    = 50 * time.Millisecond
+	taskMaxPollInterval = time.Second
+	taskTimeout         = 5 * time.Minute
+)
+
+// task represents an asynchronous operation of the Meilisearch server
+type task struct {
+	TaskUID int64      `json:"taskUid"`
+	Status  string     `json:"status"`
+	Error   *TaskError `json:"error"`
+}
+
+// requestTask enqueues a task and waits until it is processed, so that the
+// errors of the operation are returned
+func (c *Client) requestTask(method, path string, body interface{}) error {
+	enqueued := new(task)
+	if err := c.request(method, path, body, enqueued); err != nil {
+		return err
+	}
+	return c.waitForTask(enqueued.TaskUID)
+}
+
+// waitForTask polls the task until it succeeded, failed or timed out
+func (c *Client) waitForTask(uid int64) error {
+	interval := taskPollInterval
+	deadline := time.Now().Add(taskTimeout)
+	for {
+		t := new(task)
+		if err := c.request("GET", fmt.Sprintf("/tasks/%d", uid), nil, t); err != nil {
+			return err
+		}
+		switch t.Status {
+		case "succeeded":
+			return nil
+		case "failed":
+			if t.Error == nil {
+				t.Error = &TaskError{}
+			}
+			t.Error.TaskUID = uid
+			return t.Error
+		case "canceled":
+			return &TaskError{TaskUID: uid, Code: "task_canceled", Message: "The task has been canceled."}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("meilisearch: task %d has not been processed within %v", uid, taskTimeout)
+		}
+		time.Sleep(interval)
+		if interval *= 2; interval > taskMaxPollInterval {
+			interval = taskMaxPollInterval
+		}
+	}
+}
+
+// Health checks if the server is available
+func (c *Client) Health() error {
+	return c.request("GET", "/health", nil, nil)
+}
+
+// IndexExists returns if the index exists
+func (c *Client) IndexExists(uid string) (bool, error) {
+	err := c.request("GET", "/indexes/"+url.PathEscape(uid), nil, nil)
+	if IsErrNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CreateIndex creates an index, whose documents are identified by the
+// primary key
+func (c *Client) CreateIndex(uid, primaryKey string) error {
+	return c.request("POST", "/indexes", map[string]string{
+		"uid":        uid,
+		"primaryKey": primaryKey,
+	}, nil)
+}
+
+// Settings represents the attributes of the documents, which are searched,
+// filtered and sorted by
+type Settings struct {
+	SearchableAttributes []string `json:"searchableAttributes,omitempty"`
+	FilterableAttributes []string `json:"filterableAttributes,omitempty"`
+	SortableAttributes   []string `json:"sortableAttributes,omitempty"`
+}
+
+// UpdateSettings updates the settings of the index
+func (c *Client) UpdateSettings(uid string, settings *Settings) error {
+	return c.request("PATCH", "/indexes/"+url.PathEscape(uid)+"/settings", settings, nil)
+}
+
+// AddDocuments adds or replaces the documents of the index and waits until
+// they are indexed
+func (c *Client) AddDocuments(uid string, documents interface{}) error {
+	return c.requestTask("POST", "/indexes/"+url.PathEscape(uid)+"/documents", documents)
+}
+
+// DeleteDocuments deletes the documents with the given IDs and waits until
+// they are removed from the index
+func (c *Client) DeleteDocuments(uid string, ids []interface{}) error {
+	return c.requestTask("POST", "/indexes/"+url.PathEscape(uid)+"/documents/delete-batch", ids)
+}
+
+// DeleteDocumentsByFilter deletes the documents matching the filter and waits
+// until they are removed from the index
+func (c *Client) DeleteDocumentsByFilter(uid, filter string) error {
+	return c.requestTask("POST", "/indexes/"+url.PathEscape(uid)+"/documents/delete", map[string]string{
+		"filter": filter,
+	})
+}
+
+// MatchPosition represents the byte range of a match in an attribute
+type MatchPosition struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// SearchRequest represents a search of an index
+type SearchRequest struct {
+	Query                string   `json:"q"`
+	Filter               string   `json:"filter,omitempty"`
+	Sort                 []string `json:"sort,omitempty"`
+	Offset               int      `json:"offset"`
+	Limit                int      `json:"limit"`
+	AttributesToRetrieve []string `json:"attributesToRetrieve,omitempty"`
+	Facets               []string `json:"facets,omitempty"`
+	ShowMatchesPosition  bool     `json:"showMatchesPosition,omitempty"`
+}
+
+// SearchResponse represents the result of a search. The hits are decoded
+// into the documents with the attributes to retrieve.
+type SearchResponse struct {
+	Hits               []json.RawMessage         `json:"hits"`
+	EstimatedTotalHits int64                     `json:"estimatedTotalHits"`
+	FacetDistribution  map[string]map[string]int `json:"facetDistribution"`
+}
+
+// Search searches the index
+func (c *Client) Search(uid string, search *SearchRequest) (*SearchResponse, error) {
+	result := new(SearchResponse)
+	if err := c.request("POST", "/indexes/"+url.PathEscape(uid)+"/search", search, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FilterIn returns a filter matching the documents, whose attribute has one
+// of the values
+func FilterIn(attribute string, values []int64) string {
+	strs := make([]string, len(values))
+	for i, value := range values {
+		strs[i] = fmt.Sprint(value)
+	}
+	return fmt.Sprintf("%s IN [%s]", attribute, strings.Join(strs, ", "))
+}
+
+// InitIndex creates the index if it does not exist yet and updates its
+// settings. It returns false if the index was created and has to be
+// populated.
+func (c *Client) InitIndex(uid, primaryKey string, settings *Settings) (bool, error) {
+	exists, err := c.IndexExists(uid)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		if err = c.CreateIndex(uid, primaryKey); err != nil {
+			return false, err
+		}
+	}
+	// The tasks of an index are processed in order, so the settings apply
+	// to all documents added afterwards
+	if err = c.UpdateSettings(uid, settings); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
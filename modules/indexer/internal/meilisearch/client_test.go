@@ -0,0 +1,127 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package meilisearch
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClient(t *testing.T) {
+	c, err := NewClient("http://:secret@localhost:7700/")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:7700", c.url)
+	assert.Equal(t, "secret", c.apiKey)
+
+	c, err = NewClient("http://localhost:7700")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:7700", c.url)
+	assert.Empty(t, c.apiKey)
+}
+
+func TestInitIndex(t *testing.T) {
+	var requests []string
+	exists := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == "GET" && !exists:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":"index_not_found","message":"Index not found."}`))
+		case r.Method == "POST" && r.URL.Path == "/indexes":
+			var body map[string]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "issues", body["uid"])
+			assert.Equal(t, "id", body["primaryKey"])
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"taskUid":1}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(strings.Replace(server.URL, "http://", "http://:secret@", 1))
+	assert.NoError(t, err)
+
+	settings := &Settings{FilterableAttributes: []string{"repo_id"}}
+	existed, err := c.InitIndex("issues", "id", settings)
+	assert.NoError(t, err)
+	assert.False(t, existed)
+	assert.Equal(t, []string{"GET /indexes/issues", "POST /indexes", "PATCH /indexes/issues/settings"}, requests)
+
+	requests = nil
+	exists = true
+	existed, err = c.InitIndex("issues", "id", settings)
+	assert.NoError(t, err)
+	assert.True(t, existed)
+	assert.Equal(t, []string{"GET /indexes/issues", "PATCH /indexes/issues/settings"}, requests)
+}
+
+func TestSearchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":"invalid_search_filter","message":"Attribute repo_id is not filterable."}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	assert.NoError(t, err)
+	_, err = c.Search("issues", &SearchRequest{Filter: FilterIn("repo_id", []int64{1, 2})})
+	assert.Error(t, err)
+	assert.False(t, IsErrNotFound(err))
+	assert.Equal(t, "meilisearch: 400 invalid_search_filter: Attribute repo_id is not filterable.", err.Error())
+}
+
+func TestAddDocuments(t *testing.T) {
+	var requests []string
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/indexes/issues/documents":
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"taskUid":7,"indexUid":"issues","status":"enqueued"}`))
+		case "/indexes/issues/documents/delete-batch":
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"taskUid":8,"indexUid":"issues","status":"enqueued"}`))
+		case "/tasks/7":
+			polls++
+			if polls < 3 {
+				_, _ = w.Write([]byte(`{"uid":7,"status":"processing"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"uid":7,"status":"succeeded"}`))
+		case "/tasks/8":
+			_, _ = w.Write([]byte(`{"uid":8,"status":"failed","error":{"code":"invalid_document_id","message":"Document identifier is invalid."}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	assert.NoError(t, err)
+
+	// the documents are added once the task succeeded
+	assert.NoError(t, c.AddDocuments("issues", []map[string]int64{{"id": 1}}))
+	assert.Equal(t, []string{"POST /indexes/issues/documents", "GET /tasks/7", "GET /tasks/7", "GET /tasks/7"}, requests)
+
+	// the error of a failed task is returned
+	err = c.DeleteDocuments("issues", []interface{}{"a/b"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "meilisearch: task 8 failed: invalid_document_id: Document identifier is invalid.", err.Error())
+	}
+}
+
+func TestFilterIn(t *testing.T) {
+	assert.Equal(t, "repo_id IN [1, 2, 3]", FilterIn("repo_id", []int64{1, 2, 3}))
+}
@@ -24,7 +24,7 @@ import (
 const (
 	issueIndexerAnalyzer      = "issueIndexer"
 	issueIndexerDocType       = "issueIndexerDocType"
-	issueIndexerLatestVersion = 1
+	issueIndexerLatestVersion = 2
 )
 
 // indexerID a bleve-compatible unique identifier for an integer id
@@ -116,6 +116,7 @@ func createIssueIndexer(path string, latestVersion int) (bleve.Index, error) {
 
 	numericFieldMapping := bleve.NewNumericFieldMapping()
 	numericFieldMapping.IncludeInAll = false
+	docMapping.AddFieldMappingsAt("ID", numericFieldMapping)
 	docMapping.AddFieldMappingsAt("RepoID", numericFieldMapping)
 
 	textFieldMapping := bleve.NewTextFieldMapping()
@@ -125,6 +126,12 @@ func createIssueIndexer(path string, latestVersion int) (bleve.Index, error) {
 	docMapping.AddFieldMappingsAt("Content", textFieldMapping)
 	docMapping.AddFieldMappingsAt("Comments", textFieldMapping)
 
+	fingerprintFieldMapping := bleve.NewTextFieldMapping()
+	fingerprintFieldMapping.Index = false
+	fingerprintFieldMapping.IncludeInAll = false
+	fingerprintFieldMapping.IncludeTermVectors = false
+	docMapping.AddFieldMappingsAt("Fingerprint", fingerprintFieldMapping)
+
 	if err := addUnicodeNormalizeTokenFilter(mapping); err != nil {
 		return nil, err
 	} else if err = mapping.AddCustomAnalyzer(issueIndexerAnalyzer, map[string]interface{}{
@@ -154,7 +161,8 @@ func createIssueIndexer(path string, latestVersion int) (bleve.Index, error) {
 }
 
 var (
-	_ Indexer = &BleveIndexer{}
+	_ Indexer   = &BleveIndexer{}
+	_ Checkable = &BleveIndexer{}
 )
 
 // BleveIndexer implements Indexer interface
@@ -199,15 +207,19 @@ func (b *BleveIndexer) Index(issues []*IndexerData) error {
 	batch := rupture.NewFlushingBatch(b.indexer, maxBatchSize)
 	for _, issue := range issues {
 		if err := batch.Index(indexerID(issue.ID), struct {
-			RepoID   int64
-			Title    string
-			Content  string
-			Comments []string
+			ID          int64
+			RepoID      int64
+			Title       string
+			Content     string
+			Comments    []string
+			Fingerprint string
 		}{
-			RepoID:   issue.RepoID,
-			Title:    issue.Title,
-			Content:  issue.Content,
-			Comments: issue.Comments,
+			ID:          issue.ID,
+			RepoID:      issue.RepoID,
+			Title:       issue.Title,
+			Content:     issue.Content,
+			Comments:    issue.Comments,
+			Fingerprint: issue.Fingerprint(),
 		}); err != nil {
 			return err
 		}
@@ -266,3 +278,34 @@ func (b *BleveIndexer) Search(keyword string, repoIDs []int64, limit, start int)
 	}
 	return &ret, nil
 }
+
+// Fingerprints returns the fingerprints of at most limit indexed issues with
+// an ID between minID and maxID, ordered by ID
+func (b *BleveIndexer) Fingerprints(minID, maxID int64, limit int) ([]Fingerprint, error) {
+	min, max := float64(minID), float64(maxID)
+	tru := true
+	idQuery := bleve.NewNumericRangeInclusiveQuery(&min, &max, &tru, &tru)
+	idQuery.SetField("ID")
+
+	search := bleve.NewSearchRequestOptions(idQuery, limit, 0, false)
+	search.Fields = []string{"Fingerprint"}
+	search.SortBy([]string{"ID"})
+	result, err := b.indexer.Search(search)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprints := make([]Fingerprint, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		id, err := idOfIndexerID(hit.ID)
+		if err != nil {
+			return nil, err
+		}
+		fingerprint, _ := hit.Fields["Fingerprint"].(string)
+		fingerprints = append(fingerprints, Fingerprint{
+			ID:          id,
+			Fingerprint: fingerprint,
+		})
+	}
+	return fingerprints, nil
+}
@@ -0,0 +1,278 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// checkBatchSize is the number of issues compared with the indexer at once
+const checkBatchSize = 50
+
+// CheckStatus represents the progress of a consistency check of the issue indexer
+type CheckStatus struct {
+	Running bool
+	Start   time.Time
+	End     time.Time
+	// Total is the number of issues in the database when the check started
+	Total   int64
+	Checked int64
+	// Missing is the number of issues, which were not indexed
+	Missing int64
+	// Stale is the number of issues, whose indexed content was outdated
+	Stale int64
+	// Orphaned is the number of indexed issues, which do not exist anymore
+	Orphaned int64
+}
+
+// Percent returns the percentage of the issues, which have been checked
+func (status *CheckStatus) Percent() int64 {
+	if status.Total <= 0 || status.Checked >= status.Total {
+		return 100
+	}
+	return status.Checked * 100 / status.Total
+}
+
+// Repaired returns the number of documents, which were reindexed or deleted
+func (status *CheckStatus) Repaired() int64 {
+	return status.Missing + status.Stale + status.Orphaned
+}
+
+var (
+	checkLock   sync.Mutex
+	checkStatus *CheckStatus
+)
+
+// GetCheckStatus returns the progress of the running or last consistency
+// check of the issue indexer, or nil if it was not checked yet
+func GetCheckStatus() *CheckStatus {
+	checkLock.Lock()
+	defer checkLock.Unlock()
+	if checkStatus == nil {
+		return nil
+	}
+	status := *checkStatus
+	return &status
+}
+
+// CheckIssueIndexer compares the issues in the database with the documents
+// of the issue indexer and queues the issues, which are missing or stale, to
+// be indexed again. Orphaned documents are deleted.
+func CheckIssueIndexer(ctx context.Context) error {
+	indexer := holder.get()
+	if indexer == nil {
+		return fmt.Errorf("unable to get issue indexer")
+	}
+	checkable, ok := indexer.(Checkable)
+	if !ok {
+		log.Info("The %s issue indexer cannot be checked", setting.Indexer.IssueType)
+		return nil
+	}
+
+	status := &CheckStatus{Running: true, Start: time.Now()}
+	checkLock.Lock()
+	checkStatus = status
+	checkLock.Unlock()
+
+	err := checkIndexer(ctx, checkable, status, func(updates []*IndexerData, deletes []int64) error {
+		for _, data := range updates {
+			if err := issueIndexerQueue.Push(data); err != nil {
+				return err
+			}
+		}
+		if len(deletes) == 0 {
+			return nil
+		}
+		return issueIndexerQueue.Push(&IndexerData{
+			IDs:      deletes,
+			IsDelete: true,
+		})
+	})
+	if err == nil {
+		log.Info("Issue indexer checked: %d missing, %d stale and %d orphaned documents repaired", status.Missing, status.Stale, status.Orphaned)
+	}
+	return err
+}
+
+// CheckIndexer compares the issues in the database with the documents of the
+// indexer. If repair is set, the issues, which are missing or stale, are
+// indexed again and orphaned documents are deleted.
+func CheckIndexer(ctx context.Context, indexer Indexer, repair bool) (*CheckStatus, error) {
+	checkable, ok := indexer.(Checkable)
+	if !ok {
+		return nil, fmt.Errorf("the %s issue indexer cannot be checked", setting.Indexer.IssueType)
+	}
+
+	status := &CheckStatus{Running: true, Start: time.Now()}
+	err := checkIndexer(ctx, checkable, status, func(updates []*IndexerData, deletes []int64) error {
+		if !repair {
+			return nil
+		}
+		if len(updates) > 0 {
+			if err := indexer.Index(updates); err != nil {
+				return err
+			}
+		}
+		if len(deletes) > 0 {
+			return indexer.Delete(deletes...)
+		}
+		return nil
+	})
+	return status, err
+}
+
+func checkIndexer(ctx context.Context, checkable Checkable, status *CheckStatus, repair func(updates []*IndexerData, deletes []int64) error) error {
+	defer func() {
+		checkLock.Lock()
+		status.Running = false
+		status.End = time.Now()
+		checkLock.Unlock()
+	}()
+
+	total, err := models.Count(new(models.Issue))
+	if err != nil {
+		return err
+	}
+	checkLock.Lock()
+	status.Total = total
+	checkLock.Unlock()
+
+	var lastID int64
+	for {
+		select {
+		case <-ctx.Done():
+			return models.ErrCancelledf("before checking the issues after %d", lastID)
+		default:
+		}
+
+		issues, err := models.GetIssuesAfterID(lastID, checkBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(issues) == 0 {
+			break
+		}
+		if err = issues.LoadDiscussComments(); err != nil {
+			return err
+		}
+
+		maxID := issues[len(issues)-1].ID
+		indexed, err := indexedFingerprints(checkable, lastID+1, maxID)
+		if err != nil {
+			return err
+		}
+
+		var updates []*IndexerData
+		var missing, stale int64
+		for _, issue := range issues {
+			data := newIndexerData(issue)
+			fingerprint, has := indexed[issue.ID]
+			delete(indexed, issue.ID)
+			if !has {
+				missing++
+			} else if fingerprint != data.Fingerprint() {
+				stale++
+			} else {
+				continue
+			}
+			updates = append(updates, data)
+		}
+		deletes := make([]int64, 0, len(indexed))
+		for id := range indexed {
+			deletes = append(deletes, id)
+		}
+
+		if err = repair(updates, deletes); err != nil {
+			return err
+		}
+		checkLock.Lock()
+		status.Checked += int64(len(issues))
+		status.Missing += missing
+		status.Stale += stale
+		status.Orphaned += int64(len(deletes))
+		checkLock.Unlock()
+		lastID = maxID
+	}
+
+	// Documents of deleted issues after the last existing one
+	indexed, err := indexedFingerprints(checkable, lastID+1, math.MaxInt64)
+	if err != nil {
+		return err
+	}
+	if len(indexed) == 0 {
+		return nil
+	}
+	deletes := make([]int64, 0, len(indexed))
+	for id := range indexed {
+		deletes = append(deletes, id)
+	}
+	if err = repair(nil, deletes); err != nil {
+		return err
+	}
+	checkLock.Lock()
+	status.Orphaned += int64(len(deletes))
+	checkLock.Unlock()
+	return nil
+}
+
+// indexedFingerprints returns the fingerprints of all indexed issues with an
+// ID between minID and maxID
+func indexedFingerprints(checkable Checkable, minID, maxID int64) (map[int64]string, error) {
+	fingerprints := make(map[int64]string, checkBatchSize)
+	for minID <= maxID {
+		page, err := checkable.Fingerprints(minID, maxID, checkBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, fingerprint := range page {
+			fingerprints[fingerprint.ID] = fingerprint.Fingerprint
+		}
+		if len(page) < checkBatchSize {
+			break
+		}
+		minID = page[len(page)-1].ID + 1
+	}
+	return fingerprints, nil
+}
+
+// OpenIndexer opens the configured issue indexer without the indexer queue,
+// for the commands run while Gitea is stopped
+func OpenIndexer() (Indexer, error) {
+	switch setting.Indexer.IssueType {
+	case "bleve":
+		issueIndexer := NewBleveIndexer(setting.Indexer.IssuePath)
+		if _, err := issueIndexer.Init(); err != nil {
+			return nil, err
+		}
+		return issueIndexer, nil
+	case "elasticsearch":
+		issueIndexer, err := NewElasticSearchIndexer(setting.Indexer.IssueConnStr, setting.Indexer.IssueIndexerName)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = issueIndexer.Init(); err != nil {
+			return nil, err
+		}
+		return issueIndexer, nil
+	case "meilisearch":
+		issueIndexer, err := NewMeilisearchIndexer(setting.Indexer.IssueConnStr, setting.Indexer.IssueIndexerName)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = issueIndexer.Init(); err != nil {
+			return nil, err
+		}
+		return issueIndexer, nil
+	}
+	return &DBIndexer{}, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckIndexer(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	dir, err := ioutil.TempDir("", "bleve.index")
+	if err != nil {
+		assert.Fail(t, "Unable to create temporary directory: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	indexer := NewBleveIndexer(dir)
+	defer indexer.Close()
+	if _, err := indexer.Init(); err != nil {
+		assert.Fail(t, "Unable to initialise bleve indexer: %v", err)
+		return
+	}
+
+	total, err := models.Count(new(models.Issue))
+	assert.NoError(t, err)
+
+	// The empty index misses all issues
+	status, err := CheckIndexer(context.Background(), indexer, false)
+	assert.NoError(t, err)
+	assert.False(t, status.Running)
+	assert.EqualValues(t, total, status.Total)
+	assert.EqualValues(t, total, status.Checked)
+	assert.EqualValues(t, total, status.Missing)
+	assert.EqualValues(t, 0, status.Stale)
+	assert.EqualValues(t, 0, status.Orphaned)
+
+	status, err = CheckIndexer(context.Background(), indexer, true)
+	assert.NoError(t, err)
+	assert.EqualValues(t, total, status.Missing)
+
+	status, err = CheckIndexer(context.Background(), indexer, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, status.Repaired())
+
+	// Outdated and orphaned documents
+	assert.NoError(t, indexer.Index([]*IndexerData{
+		{ID: 1, RepoID: 1, Title: "outdated title"},
+		{ID: 1000, RepoID: 1, Title: "deleted issue"},
+	}))
+	status, err = CheckIndexer(context.Background(), indexer, true)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, status.Missing)
+	assert.EqualValues(t, 1, status.Stale)
+	assert.EqualValues(t, 1, status.Orphaned)
+
+	status, err = CheckIndexer(context.Background(), indexer, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, status.Repaired())
+	assert.EqualValues(t, 100, status.Percent())
+}
@@ -6,61 +6,42 @@ package issues
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"strconv"
-	"time"
 
-	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/indexer/internal/elasticsearch"
+	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/olivere/elastic/v7"
 )
 
 var (
-	_ Indexer = &ElasticSearchIndexer{}
+	_ Indexer   = &ElasticSearchIndexer{}
+	_ Checkable = &ElasticSearchIndexer{}
 )
 
+// elasticIssueIndexerLatestVersion is the version of the mapping, the index
+// is rebuilt in the background whenever it changes
+const elasticIssueIndexerLatestVersion = 1
+
 // ElasticSearchIndexer implements Indexer interface
 type ElasticSearchIndexer struct {
-	client      *elastic.Client
-	indexerName string
-}
-
-type elasticLogger struct {
-	*log.Logger
-}
-
-func (l elasticLogger) Printf(format string, args ...interface{}) {
-	_ = l.Logger.Log(2, l.Logger.GetLevel(), format, args...)
+	client *elastic.Client
+	index  *elasticsearch.Index
 }
 
 // NewElasticSearchIndexer creates a new elasticsearch indexer
 func NewElasticSearchIndexer(url, indexerName string) (*ElasticSearchIndexer, error) {
-	opts := []elastic.ClientOptionFunc{
-		elastic.SetURL(url),
-		elastic.SetSniff(false),
-		elastic.SetHealthcheckInterval(10 * time.Second),
-		elastic.SetGzip(false),
-	}
-
-	logger := elasticLogger{log.GetLogger(log.DEFAULT)}
-
-	if logger.GetLevel() == log.TRACE || logger.GetLevel() == log.DEBUG {
-		opts = append(opts, elastic.SetTraceLog(logger))
-	} else if logger.GetLevel() == log.ERROR || logger.GetLevel() == log.CRITICAL || logger.GetLevel() == log.FATAL {
-		opts = append(opts, elastic.SetErrorLog(logger))
-	} else if logger.GetLevel() == log.INFO || logger.GetLevel() == log.WARN {
-		opts = append(opts, elastic.SetInfoLog(logger))
-	}
-
-	client, err := elastic.NewClient(opts...)
+	client, err := elasticsearch.NewClient(url)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ElasticSearchIndexer{
-		client:      client,
-		indexerName: indexerName,
+		client: client,
+		index: elasticsearch.NewIndex(client, indexerName, elasticIssueIndexerLatestVersion, defaultMapping,
+			setting.Indexer.IssueShards, setting.Indexer.IssueReplicas),
 	}, nil
 }
 
@@ -87,6 +68,10 @@ const (
 				"comments": {
 					"type" : "text",
 					"index": true
+				},
+				"fingerprint": {
+					"type": "keyword",
+					"index": false
 				}
 			}
 		}
@@ -95,66 +80,35 @@ const (
 
 // Init will initialize the indexer
 func (b *ElasticSearchIndexer) Init() (bool, error) {
-	ctx := context.Background()
-	exists, err := b.client.IndexExists(b.indexerName).Do(ctx)
-	if err != nil {
-		return false, err
-	}
-
-	if !exists {
-		var mapping = defaultMapping
-
-		createIndex, err := b.client.CreateIndex(b.indexerName).BodyString(mapping).Do(ctx)
-		if err != nil {
-			return false, err
-		}
-		if !createIndex.Acknowledged {
-			return false, errors.New("init failed")
-		}
-
-		return false, nil
-	}
-	return true, nil
+	return b.index.Init()
 }
 
 // Index will save the index data
 func (b *ElasticSearchIndexer) Index(issues []*IndexerData) error {
 	if len(issues) == 0 {
 		return nil
-	} else if len(issues) == 1 {
-		issue := issues[0]
-		_, err := b.client.Index().
-			Index(b.indexerName).
-			Id(fmt.Sprintf("%d", issue.ID)).
-			BodyJson(map[string]interface{}{
-				"id":       issue.ID,
-				"repo_id":  issue.RepoID,
-				"title":    issue.Title,
-				"content":  issue.Content,
-				"comments": issue.Comments,
-			}).
-			Do(context.Background())
-		return err
 	}
 
-	reqs := make([]elastic.BulkableRequest, 0)
-	for _, issue := range issues {
-		reqs = append(reqs,
-			elastic.NewBulkIndexRequest().
-				Index(b.indexerName).
-				Id(fmt.Sprintf("%d", issue.ID)).
-				Doc(map[string]interface{}{
-					"id":       issue.ID,
-					"repo_id":  issue.RepoID,
-					"title":    issue.Title,
-					"content":  issue.Content,
-					"comments": issue.Comments,
-				}),
-		)
+	reqs := make([]elastic.BulkableRequest, 0, len(issues))
+	for _, indexName := range b.index.WriteIndices() {
+		for _, issue := range issues {
+			reqs = append(reqs,
+				elastic.NewBulkIndexRequest().
+					Index(indexName).
+					Id(fmt.Sprintf("%d", issue.ID)).
+					Doc(map[string]interface{}{
+						"id":          issue.ID,
+						"repo_id":     issue.RepoID,
+						"title":       issue.Title,
+						"content":     issue.Content,
+						"comments":    issue.Comments,
+						"fingerprint": issue.Fingerprint(),
+					}),
+			)
+		}
 	}
 
 	_, err := b.client.Bulk().
-		Index(b.indexerName).
 		Add(reqs...).
 		Do(context.Background())
 	return err
@@ -164,25 +118,20 @@ func (b *ElasticSearchIndexer) Index(issues []*IndexerData) error {
 func (b *ElasticSearchIndexer) Delete(ids ...int64) error {
 	if len(ids) == 0 {
 		return nil
-	} else if len(ids) == 1 {
-		_, err := b.client.Delete().
-			Index(b.indexerName).
-			Id(fmt.Sprintf("%d", ids[0])).
-			Do(context.Background())
-		return err
 	}
 
-	reqs := make([]elastic.BulkableRequest, 0)
-	for _, id := range ids {
-		reqs = append(reqs,
-			elastic.NewBulkDeleteRequest().
-				Index(b.indexerName).
-				Id(fmt.Sprintf("%d", id)),
-		)
+	reqs := make([]elastic.BulkableRequest, 0, len(ids))
+	for _, indexName := range b.index.WriteIndices() {
+		for _, id := range ids {
+			reqs = append(reqs,
+				elastic.NewBulkDeleteRequest().
+					Index(indexName).
+					Id(fmt.Sprintf("%d", id)),
+			)
+		}
 	}
 
 	_, err := b.client.Bulk().
-		Index(b.indexerName).
 		Add(reqs...).
 		Do(context.Background())
 	return err
@@ -203,7 +152,7 @@ func (b *ElasticSearchIndexer) Search(keyword string, repoIDs []int64, limit, st
 		query = query.Must(repoQuery)
 	}
 	searchResult, err := b.client.Search().
-		Index(b.indexerName).
+		Index(b.index.Alias()).
 		Query(query).
 		Sort("id", true).
 		From(start).Size(limit).
@@ -226,5 +175,41 @@ func (b *ElasticSearchIndexer) Search(keyword string, repoIDs []int64, limit, st
 	}, nil
 }
 
+// Fingerprints returns the fingerprints of at most limit indexed issues with
+// an ID between minID and maxID, ordered by ID
+func (b *ElasticSearchIndexer) Fingerprints(minID, maxID int64, limit int) ([]Fingerprint, error) {
+	searchResult, err := b.client.Search().
+		Index(b.index.Alias()).
+		Query(elastic.NewRangeQuery("id").Gte(minID).Lte(maxID)).
+		FetchSourceContext(elastic.NewFetchSourceContext(true).Include("fingerprint")).
+		Sort("id", true).
+		Size(limit).
+		Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprints := make([]Fingerprint, 0, len(searchResult.Hits.Hits))
+	for _, hit := range searchResult.Hits.Hits {
+		id, err := strconv.ParseInt(hit.Id, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		var source struct {
+			Fingerprint string `json:"fingerprint"`
+		}
+		if len(hit.Source) > 0 {
+			if err := json.Unmarshal(hit.Source, &source); err != nil {
+				return nil, err
+			}
+		}
+		fingerprints = append(fingerprints, Fingerprint{
+			ID:          id,
+			Fingerprint: source.Fingerprint,
+		})
+	}
+	return fingerprints, nil
+}
+
 // Close implements indexer
 func (b *ElasticSearchIndexer) Close() {}
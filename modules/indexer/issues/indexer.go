@@ -6,6 +6,8 @@ package issues
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"sync"
@@ -30,6 +32,20 @@ type IndexerData struct {
 	IDs      []int64  `json:"ids"`
 }
 
+// Fingerprint returns a hash of the indexed content of the issue, which
+// changes whenever the document needs to be indexed again
+func (i *IndexerData) Fingerprint() string {
+	h := sha1.New()
+	_, _ = h.Write([]byte(i.Title))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(i.Content))
+	for _, comment := range i.Comments {
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(comment))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // Match represents on search result
 type Match struct {
 	ID    int64   `json:"id"`
@@ -51,6 +67,20 @@ type Indexer interface {
 	Close()
 }
 
+// Checkable is implemented by the indexers, whose documents can be checked
+// against the database
+type Checkable interface {
+	// Fingerprints returns the fingerprints of at most limit indexed issues
+	// with an ID between minID and maxID, ordered by ID
+	Fingerprints(minID, maxID int64, limit int) ([]Fingerprint, error)
+}
+
+// Fingerprint represents the fingerprint of an indexed issue
+type Fingerprint struct {
+	ID          int64
+	Fingerprint string
+}
+
 type indexerHolder struct {
 	indexer   Indexer
 	mutex     sync.RWMutex
@@ -93,6 +123,15 @@ var (
 	holder            = newIndexerHolder()
 )
 
+// QueueLength returns the number of issues waiting to be indexed, or -1 if
+// the indexer queue cannot count them
+func QueueLength() int64 {
+	if countable, ok := issueIndexerQueue.(queue.Countable); ok {
+		return countable.NumberInQueue()
+	}
+	return -1
+}
+
 // InitIssueIndexer initialize issue indexer, syncReindex is true then reindex until
 // all issue index done.
 func InitIssueIndexer(syncReindex bool) {
@@ -100,7 +139,7 @@ func InitIssueIndexer(syncReindex bool) {
 
 	// Create the Queue
 	switch setting.Indexer.IssueType {
-	case "bleve", "elasticsearch":
+	case "bleve", "elasticsearch", "meilisearch":
 		handler := func(data ...queue.Data) {
 			indexer := holder.get()
 			if indexer == nil {
@@ -171,7 +210,7 @@ func InitIssueIndexer(syncReindex bool) {
 			log.Debug("Created Bleve Indexer")
 		case "elasticsearch":
 			graceful.GetManager().RunWithShutdownFns(func(_, atTerminate func(context.Context, func())) {
-				issueIndexer, err := NewElasticSearchIndexer(setting.Indexer.IssueConnStr, "gitea_issues")
+				issueIndexer, err := NewElasticSearchIndexer(setting.Indexer.IssueConnStr, setting.Indexer.IssueIndexerName)
 				if err != nil {
 					log.Fatal("Unable to initialize Elastic Search Issue Indexer at connection: %s Error: %v", setting.Indexer.IssueConnStr, err)
 				}
@@ -182,6 +221,19 @@ func InitIssueIndexer(syncReindex bool) {
 				populate = !exist
 				holder.set(issueIndexer)
 			})
+		case "meilisearch":
+			graceful.GetManager().RunWithShutdownFns(func(_, atTerminate func(context.Context, func())) {
+				issueIndexer, err := NewMeilisearchIndexer(setting.Indexer.IssueConnStr, setting.Indexer.IssueIndexerName)
+				if err != nil {
+					log.Fatal("Unable to initialize Meilisearch Issue Indexer at connection: %s Error: %v", setting.Indexer.IssueConnStr, err)
+				}
+				exist, err := issueIndexer.Init()
+				if err != nil {
+					log.Fatal("Unable to issueIndexer.Init with connection %s Error: %v", setting.Indexer.IssueConnStr, err)
+				}
+				populate = !exist
+				holder.set(issueIndexer)
+			})
 		case "db":
 			issueIndexer := &DBIndexer{}
 			holder.set(issueIndexer)
@@ -287,21 +339,25 @@ func UpdateRepoIndexer(repo *models.Repository) {
 	}
 }
 
-// UpdateIssueIndexer add/update an issue to the issue indexer
-func UpdateIssueIndexer(issue *models.Issue) {
+func newIndexerData(issue *models.Issue) *IndexerData {
 	var comments []string
 	for _, comment := range issue.Comments {
 		if comment.Type == models.CommentTypeComment {
 			comments = append(comments, comment.Content)
 		}
 	}
-	indexerData := &IndexerData{
+	return &IndexerData{
 		ID:       issue.ID,
 		RepoID:   issue.RepoID,
 		Title:    issue.Title,
 		Content:  issue.Content,
 		Comments: comments,
 	}
+}
+
+// UpdateIssueIndexer add/update an issue to the issue indexer
+func UpdateIssueIndexer(issue *models.Issue) {
+	indexerData := newIndexerData(issue)
 	log.Debug("Adding to channel: %v", indexerData)
 	if err := issueIndexerQueue.Push(indexerData); err != nil {
 		log.Error("Unable to push to issue indexer: %v: Error: %v", indexerData, err)
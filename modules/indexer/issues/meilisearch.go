@@ -0,0 +1,152 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"code.gitea.io/gitea/modules/indexer/internal/meilisearch"
+)
+
+var (
+	_ Indexer   = &MeilisearchIndexer{}
+	_ Checkable = &MeilisearchIndexer{}
+)
+
+// MeilisearchIndexer implements Indexer interface
+type MeilisearchIndexer struct {
+	client      *meilisearch.Client
+	indexerName string
+}
+
+// meilisearchIssueData represents an issue stored in the index
+type meilisearchIssueData struct {
+	ID          int64    `json:"id"`
+	RepoID      int64    `json:"repo_id"`
+	Title       string   `json:"title"`
+	Content     string   `json:"content"`
+	Comments    []string `json:"comments"`
+	Fingerprint string   `json:"fingerprint"`
+}
+
+// NewMeilisearchIndexer creates a new meilisearch indexer
+func NewMeilisearchIndexer(connStr, indexerName string) (*MeilisearchIndexer, error) {
+	client, err := meilisearch.NewClient(connStr)
+	if err != nil {
+		return nil, err
+	}
+	return &MeilisearchIndexer{
+		client:      client,
+		indexerName: indexerName,
+	}, nil
+}
+
+// Init will initialize the indexer
+func (b *MeilisearchIndexer) Init() (bool, error) {
+	return b.client.InitIndex(b.indexerName, "id", &meilisearch.Settings{
+		SearchableAttributes: []string{"title", "content", "comments"},
+		FilterableAttributes: []string{"id", "repo_id"},
+		SortableAttributes:   []string{"id"},
+	})
+}
+
+// Index will save the index data
+func (b *MeilisearchIndexer) Index(issues []*IndexerData) error {
+	if len(issues) == 0 {
+		return nil
+	}
+
+	docs := make([]*meilisearchIssueData, 0, len(issues))
+	for _, issue := range issues {
+		docs = append(docs, &meilisearchIssueData{
+			ID:          issue.ID,
+			RepoID:      issue.RepoID,
+			Title:       issue.Title,
+			Content:     issue.Content,
+			Comments:    issue.Comments,
+			Fingerprint: issue.Fingerprint(),
+		})
+	}
+	return b.client.AddDocuments(b.indexerName, docs)
+}
+
+// Delete deletes indexes by ids
+func (b *MeilisearchIndexer) Delete(ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	docIDs := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		docIDs = append(docIDs, id)
+	}
+	return b.client.DeleteDocuments(b.indexerName, docIDs)
+}
+
+// Search searches for issues by given conditions.
+// Returns the matching issue IDs
+func (b *MeilisearchIndexer) Search(keyword string, repoIDs []int64, limit, start int) (*SearchResult, error) {
+	search := &meilisearch.SearchRequest{
+		Query:                keyword,
+		Sort:                 []string{"id:asc"},
+		Offset:               start,
+		Limit:                limit,
+		AttributesToRetrieve: []string{"id"},
+	}
+	if len(repoIDs) > 0 {
+		search.Filter = meilisearch.FilterIn("repo_id", repoIDs)
+	}
+	searchResult, err := b.client.Search(b.indexerName, search)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]Match, 0, len(searchResult.Hits))
+	for _, hit := range searchResult.Hits {
+		var data meilisearchIssueData
+		if err := json.Unmarshal(hit, &data); err != nil {
+			return nil, err
+		}
+		hits = append(hits, Match{
+			ID: data.ID,
+		})
+	}
+
+	return &SearchResult{
+		Total: searchResult.EstimatedTotalHits,
+		Hits:  hits,
+	}, nil
+}
+
+// Fingerprints returns the fingerprints of at most limit indexed issues with
+// an ID between minID and maxID, ordered by ID
+func (b *MeilisearchIndexer) Fingerprints(minID, maxID int64, limit int) ([]Fingerprint, error) {
+	searchResult, err := b.client.Search(b.indexerName, &meilisearch.SearchRequest{
+		Filter:               fmt.Sprintf("id >= %d AND id <= %d", minID, maxID),
+		Sort:                 []string{"id:asc"},
+		Limit:                limit,
+		AttributesToRetrieve: []string{"id", "fingerprint"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprints := make([]Fingerprint, 0, len(searchResult.Hits))
+	for _, hit := range searchResult.Hits {
+		var data meilisearchIssueData
+		if err := json.Unmarshal(hit, &data); err != nil {
+			return nil, err
+		}
+		fingerprints = append(fingerprints, Fingerprint{
+			ID:          data.ID,
+			Fingerprint: data.Fingerprint,
+		})
+	}
+	return fingerprints, nil
+}
+
+// Close implements indexer
+func (b *MeilisearchIndexer) Close() {}
@@ -40,6 +40,10 @@ func (db *DBIndexer) Index(id int64) error {
 		return err
 	}
 
+	if err := updateInsights(repo, gitRepo, commitID); err != nil {
+		return err
+	}
+
 	// Do not recalculate stats if already calculated for this commit
 	if status.CommitSha == commitID {
 		return nil
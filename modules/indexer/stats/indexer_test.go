@@ -40,4 +40,11 @@ func TestRepoStatsIndex(t *testing.T) {
 	langs, err := repo.GetTopLanguageStats(5)
 	assert.NoError(t, err)
 	assert.Empty(t, langs)
+
+	status, err = repo.GetIndexerStatus(models.RepoIndexerTypeInsights)
+	assert.NoError(t, err)
+	assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", status.CommitSha)
+	contributors, err := models.GetRepoContributorStats(repo)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, contributors)
 }
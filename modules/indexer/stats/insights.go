@@ -0,0 +1,42 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// updateInsights aggregates the commits added to the default branch since
+// the insights were last updated. They are recomputed from scratch if the
+// previous commit is no longer part of the branch, e.g. after a force push.
+func updateInsights(repo *models.Repository, gitRepo *git.Repository, commitID string) error {
+	status, err := repo.GetIndexerStatus(models.RepoIndexerTypeInsights)
+	if err != nil {
+		return err
+	}
+	if status.CommitSha == commitID {
+		return nil
+	}
+
+	from := status.CommitSha
+	if len(from) > 0 {
+		isAncestor, err := gitRepo.IsAncestor(from, commitID)
+		if err != nil {
+			// The previous commit may have been garbage collected
+			log.Debug("Unable to check if %s is an ancestor of %s in %s: %v", from, commitID, repo.FullName(), err)
+		}
+		if !isAncestor {
+			from = ""
+		}
+	}
+
+	update := models.NewRepoInsightsUpdate(len(from) == 0)
+	if err := gitRepo.WalkCommitNumstats(from, commitID, update.AddCommit); err != nil {
+		return err
+	}
+	return repo.UpdateInsights(commitID, update)
+}
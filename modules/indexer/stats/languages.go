@@ -0,0 +1,50 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// getCachedJSON returns the value cached as JSON for the key, or computes it
+// with getFunc. The stats of a commit never change, so they are cached by its ID.
+func getCachedJSON(key string, value interface{}, getFunc func() (interface{}, error)) error {
+	data, err := cache.GetString(key, func() (string, error) {
+		v, err := getFunc()
+		if err != nil {
+			return "", err
+		}
+		data, err := json.Marshal(v)
+		return string(data), err
+	})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), value)
+}
+
+// GetLanguageStats returns the language stats of the repository at the commit
+func GetLanguageStats(repo *models.Repository, gitRepo *git.Repository, commitID string) (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	err := getCachedJSON(fmt.Sprintf("repo_language_stats_%d_%s", repo.ID, commitID), &sizes, func() (interface{}, error) {
+		return gitRepo.GetLanguageStats(commitID)
+	})
+	return sizes, err
+}
+
+// GetLanguageStatsByDirectory returns the language stats of every top-level
+// directory of the repository at the commit
+func GetLanguageStatsByDirectory(repo *models.Repository, gitRepo *git.Repository, commitID string) (map[string]map[string]int64, error) {
+	dirs := make(map[string]map[string]int64)
+	err := getCachedJSON(fmt.Sprintf("repo_language_dirs_%d_%s", repo.ID, commitID), &dirs, func() (interface{}, error) {
+		return gitRepo.GetLanguageStatsByDirectory(commitID)
+	})
+	return dirs, err
+}
@@ -41,3 +41,15 @@ func initStatsQueue() error {
 func UpdateRepoIndexer(repo *models.Repository) error {
 	return statsQueue.Push(repo.ID)
 }
+
+// RecalculateRepoStats recalculates the stats of a repository from scratch,
+// even if they were calculated for the current commit
+func RecalculateRepoStats(repo *models.Repository) error {
+	if err := repo.ResetIndexerStatus(models.RepoIndexerTypeStats); err != nil {
+		return err
+	}
+	if err := statsQueue.Push(repo.ID); err != nil && err != queue.ErrAlreadyInQueue {
+		return err
+	}
+	return nil
+}
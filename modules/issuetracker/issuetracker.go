@@ -0,0 +1,237 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issuetracker
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Type is the kind of an external issue tracker
+type Type string
+
+const (
+	// TypeJira is a Jira issue tracker, the keys of its issues look like PROJ-123
+	TypeJira Type = "jira"
+	// TypeRedmine is a Redmine issue tracker, the keys of its issues look like #123
+	TypeRedmine Type = "redmine"
+)
+
+// IsValid returns true if the type is a supported issue tracker
+func (t Type) IsValid() bool {
+	return t == TypeJira || t == TypeRedmine
+}
+
+// DisplayName returns the name of the issue tracker
+func (t Type) DisplayName() string {
+	switch t {
+	case TypeJira:
+		return "Jira"
+	case TypeRedmine:
+		return "Redmine"
+	}
+	return string(t)
+}
+
+// Config is the connection to an external issue tracker
+type Config struct {
+	Type Type
+	// URL is the base URL of the tracker, e.g. https://example.atlassian.net
+	URL string
+	// ProjectKey restricts the keys of a Jira tracker to a project
+	ProjectKey string
+	// Username is the user of a Jira tracker, the token is sent as a bearer token without it
+	Username string
+	// Token is the API token of a Jira user or the API key of a Redmine user
+	Token string
+}
+
+var (
+	jiraKeyPattern    = regexp.MustCompile(`\b[A-Z][A-Z0-9_]+-[1-9][0-9]*\b`)
+	redmineKeyPattern = regexp.MustCompile(`(?:^|[^\w&/#])(#[1-9][0-9]*)\b`)
+	projectKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]+$`)
+)
+
+// IsValidProjectKey returns true if the key is a valid Jira project key
+func IsValidProjectKey(key string) bool {
+	return projectKeyPattern.MatchString(key)
+}
+
+// FindKeys returns the keys of the issues of the tracker referenced by a text, in order and
+// without duplicates
+func (cfg *Config) FindKeys(text string) []string {
+	var keys []string
+	switch cfg.Type {
+	case TypeJira:
+		keys = jiraKeyPattern.FindAllString(text, -1)
+	case TypeRedmine:
+		for _, match := range redmineKeyPattern.FindAllStringSubmatch(text, -1) {
+			keys = append(keys, match[1])
+		}
+	}
+
+	found := make(map[string]bool, len(keys))
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if found[key] || (cfg.Type == TypeJira && cfg.ProjectKey != "" && !strings.HasPrefix(key, cfg.ProjectKey+"-")) {
+			continue
+		}
+		found[key] = true
+		result = append(result, key)
+	}
+	return result
+}
+
+// IssueURL returns the link to an issue of the tracker
+func (cfg *Config) IssueURL(key string) string {
+	base := strings.TrimSuffix(cfg.URL, "/")
+	if cfg.Type == TypeRedmine {
+		return base + "/issues/" + strings.TrimPrefix(key, "#")
+	}
+	return base + "/browse/" + url.PathEscape(key)
+}
+
+// ErrRequestFailed represents an unexpected response of an issue tracker
+type ErrRequestFailed struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+// IsErrRequestFailed checks if an error is a ErrRequestFailed
+func IsErrRequestFailed(err error) bool {
+	_, ok := err.(ErrRequestFailed)
+	return ok
+}
+
+func (err ErrRequestFailed) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status %d: %s", err.Method, err.URL, err.StatusCode, err.Body)
+}
+
+var (
+	clientOnce sync.Once
+	client     *http.Client
+)
+
+func getClient() *http.Client {
+	clientOnce.Do(func() {
+		client = &http.Client{
+			Timeout: setting.IssueTrackerSync.Timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: setting.IssueTrackerSync.SkipTLSVerify},
+			},
+		}
+	})
+	return client
+}
+
+// issuePath returns the path of the API of an issue
+func (cfg *Config) issuePath(key string) string {
+	if cfg.Type == TypeRedmine {
+		return "/issues/" + url.PathEscape(strings.TrimPrefix(key, "#")) + ".json"
+	}
+	return "/rest/api/2/issue/" + url.PathEscape(key)
+}
+
+// do sends a request to the API of the tracker and returns the status of the response
+func (cfg *Config) do(method, path string, body interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		bs, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(bs)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(cfg.URL, "/")+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case cfg.Type == TypeRedmine:
+		req.Header.Set("X-Redmine-API-Key", cfg.Token)
+	case cfg.Username != "":
+		req.SetBasicAuth(cfg.Username, cfg.Token)
+	case cfg.Token != "":
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	}
+
+	resp, err := getClient().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		bs, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, ErrRequestFailed{
+			Method:     method,
+			URL:        req.URL.Redacted(),
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(bs)),
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// IssueExists returns true if the issue exists in the tracker and is visible to its user
+func (cfg *Config) IssueExists(key string) (bool, error) {
+	status, err := cfg.do(http.MethodGet, cfg.issuePath(key), nil)
+	if status == http.StatusNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PostComment adds a comment to an issue of the tracker
+func (cfg *Config) PostComment(key, text string) error {
+	if cfg.Type == TypeRedmine {
+		_, err := cfg.do(http.MethodPut, cfg.issuePath(key), map[string]interface{}{
+			"issue": map[string]string{"notes": text},
+		})
+		return err
+	}
+	_, err := cfg.do(http.MethodPost, cfg.issuePath(key)+"/comment", map[string]string{
+		"body": text,
+	})
+	return err
+}
+
+// AddRemoteLink links an issue of a Jira tracker to a page, shown as resolved if it is done. The
+// links with the same global ID are replaced. Other trackers have no remote links, the comments
+// hold the link instead.
+func (cfg *Config) AddRemoteLink(key, globalID, link, title string, resolved bool) error {
+	if cfg.Type != TypeJira {
+		return nil
+	}
+	_, err := cfg.do(http.MethodPost, cfg.issuePath(key)+"/remotelink", map[string]interface{}{
+		"globalId": globalID,
+		"object": map[string]interface{}{
+			"url":   link,
+			"title": title,
+			"status": map[string]bool{
+				"resolved": resolved,
+			},
+		},
+	})
+	return err
+}
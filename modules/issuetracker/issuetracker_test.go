@@ -0,0 +1,119 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issuetracker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindKeys(t *testing.T) {
+	jira := &Config{Type: TypeJira}
+	assert.Equal(t, []string{"PROJ-12", "OPS-3"}, jira.FindKeys("PROJ-12: fix the build\n\nSee OPS-3, PROJ-12 and proj-4 or PROJ-0"))
+	jira.ProjectKey = "PROJ"
+	assert.Equal(t, []string{"PROJ-12"}, jira.FindKeys("PROJ-12: fix the build\n\nSee OPS-3"))
+	assert.Empty(t, jira.FindKeys("XPROJ-12 is not in the project"))
+	assert.Equal(t, "https://jira.example.com/browse/PROJ-12", (&Config{Type: TypeJira, URL: "https://jira.example.com/"}).IssueURL("PROJ-12"))
+
+	redmine := &Config{Type: TypeRedmine, URL: "https://redmine.example.com"}
+	assert.Equal(t, []string{"#12", "#3"}, redmine.FindKeys("#12 fix the build (refs #3, #12)\n\nhttps://example.com/#4 &#5;"))
+	assert.Equal(t, "https://redmine.example.com/issues/12", redmine.IssueURL("#12"))
+
+	assert.True(t, IsValidProjectKey("PROJ"))
+	assert.False(t, IsValidProjectKey("proj"))
+	assert.False(t, IsValidProjectKey("PROJ-1"))
+}
+
+func TestJira(t *testing.T) {
+	var requests []string
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		user, token, ok := r.BasicAuth()
+		if !ok || user != "bot@example.com" || token != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/rest/api/2/issue/PROJ-2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPost {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			w.WriteHeader(http.StatusCreated)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	cfg := &Config{Type: TypeJira, URL: server.URL + "/", Username: "bot@example.com", Token: "secret"}
+	exists, err := cfg.IssueExists("PROJ-1")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = cfg.IssueExists("PROJ-2")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.NoError(t, cfg.PostComment("PROJ-1", "Fixed by a commit"))
+	assert.Equal(t, "Fixed by a commit", body["body"])
+	assert.NoError(t, cfg.AddRemoteLink("PROJ-1", "gitea-pull-1", "https://gitea.example.com/org/repo/pulls/1", "org/repo#1", true))
+	assert.Equal(t, "gitea-pull-1", body["globalId"])
+	assert.Equal(t, true, body["object"].(map[string]interface{})["status"].(map[string]interface{})["resolved"])
+	assert.Equal(t, []string{
+		"GET /rest/api/2/issue/PROJ-1",
+		"GET /rest/api/2/issue/PROJ-2",
+		"POST /rest/api/2/issue/PROJ-1/comment",
+		"POST /rest/api/2/issue/PROJ-1/remotelink",
+	}, requests)
+
+	cfg.Token = "wrong"
+	_, err = cfg.IssueExists("PROJ-1")
+	assert.True(t, IsErrRequestFailed(err))
+}
+
+func TestRedmine(t *testing.T) {
+	var requests []string
+	var body map[string]map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("X-Redmine-API-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/issues/2.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPut {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(`{"issue":{"id":1}}`))
+	}))
+	defer server.Close()
+
+	cfg := &Config{Type: TypeRedmine, URL: server.URL, Token: "secret"}
+	exists, err := cfg.IssueExists("#1")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = cfg.IssueExists("#2")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.NoError(t, cfg.PostComment("#1", "Fixed by a commit"))
+	assert.Equal(t, "Fixed by a commit", body["issue"]["notes"])
+	// Redmine has no remote links
+	assert.NoError(t, cfg.AddRemoteLink("#1", "gitea-pull-1", "https://gitea.example.com/org/repo/pulls/1", "org/repo#1", true))
+	assert.Equal(t, []string{
+		"GET /issues/1.json",
+		"GET /issues/2.json",
+		"PUT /issues/1.json",
+	}, requests)
+}
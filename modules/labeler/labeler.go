@@ -0,0 +1,146 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package labeler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/gobwas/glob"
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigPaths are the paths in the base branch of a pull request the labeler
+// configuration is read from, the first existing one is used.
+var ConfigPaths = []string{
+	".gitea/labeler.yml",
+	".gitea/labeler.yaml",
+}
+
+// MaxConfigSize is the maximum size of a labeler configuration in bytes.
+const MaxConfigSize = 64 * 1024
+
+// Rule applies a label to the pull requests changing at least one file which
+// matches one of its patterns and none of its exclusions.
+type Rule struct {
+	Label    string
+	patterns []glob.Glob
+	excludes []glob.Glob
+}
+
+// Config is the labeler configuration of a repository. It maps the names of
+// labels to lists of glob patterns of file paths, patterns starting with "!"
+// exclude the matching files:
+//
+//   backend:
+//     - "models/**"
+//     - "!models/fixtures/**"
+//   docs: "docs/**"
+type Config struct {
+	Rules []*Rule
+}
+
+// Parse parses a labeler configuration, invalid patterns are skipped.
+func Parse(data []byte) (*Config, error) {
+	raw := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cfg := &Config{Rules: make([]*Rule, 0, len(raw))}
+	for _, name := range names {
+		exprs, err := toStrings(raw[name])
+		if err != nil {
+			return nil, fmt.Errorf("label %q: %v", name, err)
+		}
+
+		rule := &Rule{Label: strings.TrimSpace(name)}
+		for _, expr := range exprs {
+			expr = strings.TrimSpace(expr)
+			exclude := strings.HasPrefix(expr, "!")
+			expr = strings.TrimPrefix(expr, "!")
+			if expr == "" {
+				continue
+			}
+			g, err := glob.Compile(expr, '/')
+			if err != nil {
+				log.Info("Invalid glob expression '%s' of label '%s' (skipped): %v", expr, name, err)
+				continue
+			}
+			if exclude {
+				rule.excludes = append(rule.excludes, g)
+			} else {
+				rule.patterns = append(rule.patterns, g)
+			}
+		}
+		if rule.Label != "" && len(rule.patterns) > 0 {
+			cfg.Rules = append(cfg.Rules, rule)
+		}
+	}
+	return cfg, nil
+}
+
+// toStrings converts a YAML value which is a string or a list of strings.
+func toStrings(v interface{}) ([]string, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		strs := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("pattern %v is not a string", item)
+			}
+			strs = append(strs, s)
+		}
+		return strs, nil
+	}
+	return nil, fmt.Errorf("patterns must be a string or a list of strings")
+}
+
+// Match returns true if one of the files matches the rule.
+func (rule *Rule) Match(files []string) bool {
+	for _, file := range files {
+		if matchAny(rule.excludes, file) {
+			continue
+		}
+		if matchAny(rule.patterns, file) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(globs []glob.Glob, file string) bool {
+	for _, g := range globs {
+		if g.Match(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// Labels returns the names of the labels of the rules matching the files.
+func (cfg *Config) Labels(files []string) []string {
+	labels := make([]string, 0, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		if rule.Match(files) {
+			labels = append(labels, rule.Label)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package labeler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse([]byte(`
+backend:
+  - "models/**"
+  - "!models/fixtures/**"
+docs: "docs/**"
+invalid: "[docs"
+empty:
+`))
+	assert.NoError(t, err)
+	if assert.Len(t, cfg.Rules, 2) {
+		assert.Equal(t, "backend", cfg.Rules[0].Label)
+		assert.Equal(t, "docs", cfg.Rules[1].Label)
+	}
+
+	_, err = Parse([]byte(`backend: {models: true}`))
+	assert.Error(t, err)
+	_, err = Parse([]byte(`- models/**`))
+	assert.Error(t, err)
+}
+
+func TestConfigLabels(t *testing.T) {
+	cfg, err := Parse([]byte(`
+backend:
+  - "models/**"
+  - "!models/fixtures/**"
+docs: "docs/**"
+root: "*.md"
+`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"backend"}, cfg.Labels([]string{"models/repo.go"}))
+	assert.Empty(t, cfg.Labels([]string{"models/fixtures/repo.yml"}))
+	assert.Equal(t, []string{"backend"}, cfg.Labels([]string{"models/fixtures/repo.yml", "models/org.go"}))
+	assert.Equal(t, []string{"docs", "root"}, cfg.Labels([]string{"docs/content/page.md", "README.md"}))
+	assert.Empty(t, cfg.Labels([]string{"docs.md/file.go"}))
+	assert.Empty(t, cfg.Labels(nil))
+}
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -584,6 +585,24 @@ func authenticate(ctx *context.Context, repository *models.Repository, authoriza
 		return true
 	}
 
+	if token := basicAuthDeployToken(authorization); token != "" {
+		scope := models.DeployTokenScopeLFSPull
+		if requireWrite {
+			scope = models.DeployTokenScopeLFSPush
+		}
+		ctx.User, err = models.AuthenticateDeployToken(token, repository.ID, scope)
+		if err != nil {
+			log.Warn("Authentication failure for provided deploy token with Error: %v", err)
+			return false
+		}
+		perm, err = models.GetUserRepoPermission(repository, ctx.User)
+		if err != nil {
+			log.Error("Unable to GetUserRepoPermission for user %-v in repo %-v Error: %v", ctx.User, repository)
+			return false
+		}
+		return perm.CanAccess(accessMode, models.UnitTypeCode)
+	}
+
 	user, repo, opStr, err := parseToken(authorization)
 	if err != nil {
 		// Most of these are Warn level - the true internal server errors are logged in parseToken already
@@ -608,6 +627,26 @@ func authenticate(ctx *context.Context, repository *models.Repository, authoriza
 	return false
 }
 
+// basicAuthDeployToken returns the deploy token sent as the username or the password of a basic
+// authorization, or an empty string
+func basicAuthDeployToken(authorization string) string {
+	if !strings.HasPrefix(authorization, "Basic ") {
+		return ""
+	}
+	username, password, err := base.BasicAuthDecode(strings.TrimPrefix(authorization, "Basic "))
+	if err != nil {
+		return ""
+	}
+	token := password
+	if len(password) == 0 || password == "x-oauth-basic" {
+		token = username
+	}
+	if !models.IsDeployToken(token) {
+		return ""
+	}
+	return token
+}
+
 func parseToken(authorization string) (*models.User, *models.Repository, string, error) {
 	if authorization == "" {
 		return nil, nil, "unknown", fmt.Errorf("No token")
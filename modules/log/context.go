@@ -0,0 +1,150 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+)
+
+// EnvRequestID is the environment variable passing the request ID to git
+// commands and the hooks they run
+const EnvRequestID = "GITEA_REQUEST_ID"
+
+// RequestIDField is the name of the field holding the request ID
+const RequestIDField = "request_id"
+
+var validRequestID = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`)
+
+// Fields are structured values added to log events, e.g. the ID of the
+// request an event belongs to
+type Fields map[string]interface{}
+
+type fieldsContextKey struct{}
+
+// ContextWithFields returns a copy of ctx whose log events carry the given
+// fields in addition to the fields already stored in ctx
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	merged := make(Fields, len(fields))
+	for k, v := range FieldsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsContextKey{}, merged)
+}
+
+// FieldsFromContext returns the fields stored in ctx
+func FieldsFromContext(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsContextKey{}).(Fields)
+	return fields
+}
+
+// ContextWithRequestID returns a copy of ctx whose log events carry the request ID
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return ContextWithFields(ctx, Fields{RequestIDField: id})
+}
+
+// RequestIDFromContext returns the request ID stored in ctx
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := FieldsFromContext(ctx)[RequestIDField].(string)
+	return id
+}
+
+// NewRequestID returns a random request ID
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// IsValidRequestID returns if id may be used as request ID. IDs are short and
+// limited to letters, digits, dots, dashes and underscores, so that IDs sent by
+// clients cannot forge log lines.
+func IsValidRequestID(id string) bool {
+	return validRequestID.MatchString(id)
+}
+
+// ContextLogger logs events with the fields stored in a context
+type ContextLogger struct {
+	logger *Logger
+	fields Fields
+}
+
+// WithContext returns a logger adding the fields stored in ctx to every event
+func (l *Logger) WithContext(ctx context.Context) *ContextLogger {
+	return &ContextLogger{
+		logger: l,
+		fields: FieldsFromContext(ctx),
+	}
+}
+
+// Ctx returns the default logger adding the fields stored in ctx to every event
+func Ctx(ctx context.Context) *ContextLogger {
+	l, _ := NamedLoggers.Load(DEFAULT)
+	return &ContextLogger{
+		logger: l,
+		fields: FieldsFromContext(ctx),
+	}
+}
+
+// Log msg at the provided level with the provided caller defined by skip (0 being the function that calls this function)
+func (l *ContextLogger) Log(skip int, level Level, format string, v ...interface{}) error {
+	if l.logger == nil {
+		return nil
+	}
+	return l.logger.log(skip+1, level, l.fields, format, v...)
+}
+
+// Trace records trace log
+func (l *ContextLogger) Trace(format string, v ...interface{}) {
+	_ = l.Log(1, TRACE, format, v...)
+}
+
+// Debug records debug log
+func (l *ContextLogger) Debug(format string, v ...interface{}) {
+	_ = l.Log(1, DEBUG, format, v...)
+}
+
+// Info records information log
+func (l *ContextLogger) Info(format string, v ...interface{}) {
+	_ = l.Log(1, INFO, format, v...)
+}
+
+// Warn records warning log
+func (l *ContextLogger) Warn(format string, v ...interface{}) {
+	_ = l.Log(1, WARN, format, v...)
+}
+
+// Error records error log
+func (l *ContextLogger) Error(format string, v ...interface{}) {
+	_ = l.Log(1, ERROR, format, v...)
+}
+
+// ErrorWithSkip records error log from "skip" calls back from this function
+func (l *ContextLogger) ErrorWithSkip(skip int, format string, v ...interface{}) {
+	_ = l.Log(skip+1, ERROR, format, v...)
+}
+
+// Critical records critical log
+func (l *ContextLogger) Critical(format string, v ...interface{}) {
+	_ = l.Log(1, CRITICAL, format, v...)
+}
+
+// SendLog sends a log event at the provided level with the information given
+func (l *ContextLogger) SendLog(level Level, caller, filename string, line int, msg string, stack string) error {
+	if l.logger == nil {
+		return nil
+	}
+	return l.logger.sendLog(level, caller, filename, line, msg, stack, l.fields)
+}
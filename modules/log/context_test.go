@@ -0,0 +1,71 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextFields(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, RequestIDFromContext(ctx))
+
+	ctx = ContextWithRequestID(ctx, "abc")
+	child := ContextWithFields(ctx, Fields{"repo": "user2/repo1"})
+	assert.Equal(t, "abc", RequestIDFromContext(child))
+	assert.Equal(t, Fields{RequestIDField: "abc", "repo": "user2/repo1"}, FieldsFromContext(child))
+	// The fields of the parent are not changed
+	assert.Equal(t, Fields{RequestIDField: "abc"}, FieldsFromContext(ctx))
+}
+
+func TestRequestID(t *testing.T) {
+	id := NewRequestID()
+	assert.Len(t, id, 32)
+	assert.True(t, IsValidRequestID(id))
+	assert.NotEqual(t, id, NewRequestID())
+
+	assert.True(t, IsValidRequestID("req-1.2_3"))
+	assert.False(t, IsValidRequestID(""))
+	assert.False(t, IsValidRequestID("a b"))
+	assert.False(t, IsValidRequestID("abc\n2019/01/01 [E] forged"))
+	assert.False(t, IsValidRequestID(strings.Repeat("a", 65)))
+}
+
+type eventRecorder struct {
+	events chan *Event
+}
+
+func (r *eventRecorder) LogEvent(event *Event) error {
+	r.events <- event
+	return nil
+}
+
+func (r *eventRecorder) Close()                    {}
+func (r *eventRecorder) Flush()                    {}
+func (r *eventRecorder) GetLevel() Level           { return TRACE }
+func (r *eventRecorder) GetStacktraceLevel() Level { return NONE }
+func (r *eventRecorder) GetName() string           { return "recorder" }
+func (r *eventRecorder) ReleaseReopen() error      { return nil }
+
+func TestContextLogger(t *testing.T) {
+	recorder := &eventRecorder{events: make(chan *Event, 1)}
+	logger := newLogger("testContextLogger", 1)
+	defer logger.Close()
+	assert.NoError(t, logger.AddLogger(recorder))
+
+	ctx := ContextWithRequestID(context.Background(), "abc")
+	logger.WithContext(ctx).Info("hello world")
+
+	event := <-recorder.events
+	assert.Equal(t, INFO, event.level)
+	assert.Equal(t, "hello world", event.msg)
+	assert.True(t, strings.HasSuffix(event.filename, "context_test.go"), event.filename)
+	assert.Equal(t, "code.gitea.io/gitea/modules/log.TestContextLogger()", event.caller)
+	assert.Equal(t, Fields{RequestIDField: "abc"}, event.fields)
+}
@@ -19,6 +19,7 @@ type Event struct {
 	line       int
 	time       time.Time
 	stacktrace string
+	fields     Fields
 }
 
 // EventLogger represents the behaviours of a logger
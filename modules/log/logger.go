@@ -55,6 +55,10 @@ func (l *Logger) DelLogger(name string) (bool, error) {
 
 // Log msg at the provided level with the provided caller defined by skip (0 being the function that calls this function)
 func (l *Logger) Log(skip int, level Level, format string, v ...interface{}) error {
+	return l.log(skip+1, level, nil, format, v...)
+}
+
+func (l *Logger) log(skip int, level Level, fields Fields, format string, v ...interface{}) error {
 	if l.GetLevel() > level {
 		return nil
 	}
@@ -75,11 +79,15 @@ func (l *Logger) Log(skip int, level Level, format string, v ...interface{}) err
 	if l.GetStacktraceLevel() <= level {
 		stack = Stack(skip + 1)
 	}
-	return l.SendLog(level, caller, strings.TrimPrefix(filename, prefix), line, msg, stack)
+	return l.sendLog(level, caller, strings.TrimPrefix(filename, prefix), line, msg, stack, fields)
 }
 
 // SendLog sends a log event at the provided level with the information given
 func (l *Logger) SendLog(level Level, caller, filename string, line int, msg string, stack string) error {
+	return l.sendLog(level, caller, filename, line, msg, stack, nil)
+}
+
+func (l *Logger) sendLog(level Level, caller, filename string, line int, msg string, stack string, fields Fields) error {
 	if l.GetLevel() > level {
 		return nil
 	}
@@ -91,6 +99,7 @@ func (l *Logger) SendLog(level Level, caller, filename string, line int, msg str
 		msg:        msg,
 		time:       time.Now(),
 		stacktrace: stack,
+		fields:     fields,
 	}
 	l.LogEvent(event)
 	return nil
@@ -6,11 +6,14 @@ package log
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 type byteArrayWriter []byte
@@ -31,6 +34,7 @@ type WriterLogger struct {
 	Prefix          string `json:"prefix"`
 	Colorize        bool   `json:"colorize"`
 	Expression      string `json:"expression"`
+	JSON            bool   `json:"json"`
 	regexp          *regexp.Regexp
 }
 
@@ -206,6 +210,20 @@ func (logger *WriterLogger) createMsg(buf *[]byte, event *Event) {
 	}).Write(msg)
 	*buf = baw
 
+	if len(event.fields) > 0 {
+		keys := make([]string, 0, len(event.fields))
+		for k := range event.fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			*buf = append(*buf, ' ')
+			*buf = append(*buf, k...)
+			*buf = append(*buf, '=')
+			*buf = append(*buf, fmt.Sprint(event.fields[k])...)
+		}
+	}
+
 	if event.stacktrace != "" && logger.StacktraceLevel <= event.level {
 		lines := bytes.Split([]byte(event.stacktrace), []byte("\n"))
 		if len(lines) > 1 {
@@ -219,6 +237,64 @@ func (logger *WriterLogger) createMsg(buf *[]byte, event *Event) {
 	*buf = append(*buf, '\n')
 }
 
+// createJSONMsg writes the event as a single line JSON object. The fields of
+// the event are added next to the keys of the event itself, which take
+// precedence over fields with the same name.
+func (logger *WriterLogger) createJSONMsg(buf *[]byte, event *Event) {
+	t := event.time
+	if logger.Flags&LUTC != 0 {
+		t = t.UTC()
+	}
+	core := map[string]interface{}{
+		"time":  t.Format(time.RFC3339Nano),
+		"level": event.level.String(),
+	}
+	if logger.Prefix != "" {
+		core["prefix"] = logger.Prefix
+	}
+	if logger.Flags&(Lshortfile|Llongfile) != 0 {
+		core["file"] = event.filename
+		core["line"] = event.line
+	}
+	if logger.Flags&(Lfuncname|Lshortfuncname) != 0 {
+		core["caller"] = event.caller
+	}
+
+	var msg []byte
+	baw := byteArrayWriter(msg)
+	(&protectedANSIWriter{
+		w:    &baw,
+		mode: removeColor,
+	}).Write([]byte(strings.TrimSuffix(event.msg, "\n")))
+	core["msg"] = string(baw)
+
+	if event.stacktrace != "" && logger.StacktraceLevel <= event.level {
+		core["stacktrace"] = event.stacktrace
+	}
+
+	line, err := json.Marshal(mergeFields(event.fields, core, false))
+	if err != nil {
+		// Fields which cannot be marshalled must not lose the event
+		line, _ = json.Marshal(mergeFields(event.fields, core, true))
+	}
+	*buf = append(*buf, line...)
+	*buf = append(*buf, '\n')
+}
+
+func mergeFields(fields Fields, core map[string]interface{}, stringify bool) map[string]interface{} {
+	entry := make(map[string]interface{}, len(fields)+len(core))
+	for k, v := range fields {
+		if stringify {
+			v = fmt.Sprint(v)
+		}
+		entry[k] = v
+	}
+	for k, v := range core {
+		entry[k] = v
+	}
+	return entry
+}
+
 // LogEvent logs the event to the internal writer
 func (logger *WriterLogger) LogEvent(event *Event) error {
 	if logger.Level > event.level {
@@ -231,7 +307,11 @@ func (logger *WriterLogger) LogEvent(event *Event) error {
 		return nil
 	}
 	var buf []byte
-	logger.createMsg(&buf, event)
+	if logger.JSON {
+		logger.createJSONMsg(&buf, event)
+	} else {
+		logger.createMsg(&buf, event)
+	}
 	_, err := logger.out.Write(buf)
 	return err
 }
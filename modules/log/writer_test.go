@@ -275,3 +275,65 @@ func TestBrokenRegexp(t *testing.T) {
 	b.Close()
 	assert.Equal(t, true, closed)
 }
+
+func TestJSONLogger(t *testing.T) {
+	var written []byte
+
+	c := CallbackWriteCloser{
+		callback: func(p []byte, close bool) {
+			written = p
+		},
+	}
+	b := WriterLogger{
+		out:    c,
+		Level:  INFO,
+		Flags:  LstdFlags | LUTC,
+		Prefix: "TestPrefix",
+		JSON:   true,
+	}
+
+	date := time.Date(2019, time.January, 13, 22, 3, 30, 15, time.UTC)
+	event := Event{
+		level:    INFO,
+		msg:      "TEST " + string(fgGreenBytes) + "MSG" + string(resetBytes) + "\n",
+		caller:   "CALLER",
+		filename: "FULL/FILENAME",
+		line:     1,
+		time:     date,
+		fields:   Fields{RequestIDField: "abc", "level": "spoofed"},
+	}
+
+	b.LogEvent(&event)
+	assert.Equal(t, `{"caller":"CALLER","file":"FULL/FILENAME","level":"info","line":1,"msg":"TEST MSG","prefix":"TestPrefix","request_id":"abc","time":"2019-01-13T22:03:30.000000015Z"}`+"\n", string(written))
+
+	b.Flags = -1
+	b.NewWriterLogger(c)
+	event.fields = Fields{"size": func() {}}
+	b.LogEvent(&event)
+	assert.Contains(t, string(written), `"size":"0x`)
+	assert.NotContains(t, string(written), `"caller"`)
+}
+
+func TestLoggerFields(t *testing.T) {
+	var written []byte
+
+	c := CallbackWriteCloser{
+		callback: func(p []byte, close bool) {
+			written = p
+		},
+	}
+	b := WriterLogger{
+		out:   c,
+		Level: INFO,
+		Flags: -1,
+	}
+	b.NewWriterLogger(c)
+
+	event := Event{
+		level:  INFO,
+		msg:    "TEST MSG",
+		fields: Fields{RequestIDField: "abc", "attempt": 2},
+	}
+	b.LogEvent(&event)
+	assert.Equal(t, "TEST MSG attempt=2 request_id=abc\n", string(written))
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package maintenance
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.gitea.io/gitea/modules/queue"
+
+	"gitea.com/macaron/macaron"
+)
+
+// State represents the maintenance mode of the instance
+type State struct {
+	Enabled bool
+	// Message is shown to users whose writes are declined
+	Message string
+	Since   time.Time
+}
+
+// Status represents the maintenance mode of the instance together with the
+// work which is still draining
+type Status struct {
+	State
+	InFlightRequests int64
+	QueuedItems      int64
+	InProgressItems  int64
+}
+
+// IsDrained returns if no requests other than the one asking for the status
+// are handled and no queue items are waiting or being handled
+func (s *Status) IsDrained() bool {
+	return s.InFlightRequests == 0 && s.QueuedItems == 0 && s.InProgressItems == 0
+}
+
+// DefaultMessage is shown when maintenance mode is enabled without a message
+const DefaultMessage = "This instance is undergoing maintenance and does not accept changes at the moment. Please try again later."
+
+var (
+	lock     sync.RWMutex
+	state    State
+	inFlight int64
+)
+
+// Enable declines new writes to the instance with the given message
+func Enable(message string) {
+	if message == "" {
+		message = DefaultMessage
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if !state.Enabled {
+		state.Since = time.Now()
+	}
+	state.Enabled = true
+	state.Message = message
+}
+
+// Disable accepts writes to the instance again
+func Disable() {
+	lock.Lock()
+	defer lock.Unlock()
+	state = State{}
+}
+
+// IsEnabled returns if the instance is in maintenance mode
+func IsEnabled() bool {
+	lock.RLock()
+	defer lock.RUnlock()
+	return state.Enabled
+}
+
+// GetState returns the maintenance mode of the instance
+func GetState() State {
+	lock.RLock()
+	defer lock.RUnlock()
+	return state
+}
+
+// InFlightRequests returns the number of requests being handled
+func InFlightRequests() int64 {
+	return atomic.LoadInt64(&inFlight)
+}
+
+// RequestTracker counts the requests being handled, so that administrators
+// can wait for them to drain before stopping the instance. Requests to the
+// ignored paths, e.g. long-lived event streams, are not counted.
+func RequestTracker(ignoredPaths ...string) macaron.Handler {
+	return func(ctx *macaron.Context) {
+		for _, path := range ignoredPaths {
+			if ctx.Req.URL.Path == path {
+				return
+			}
+		}
+		atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		ctx.Next()
+	}
+}
+
+// GetStatus returns the maintenance mode of the instance and the work which is
+// still draining. As it is called whilst handling a request, that request is
+// not counted.
+func GetStatus() *Status {
+	status := &Status{
+		State:            GetState(),
+		InFlightRequests: InFlightRequests() - 1,
+	}
+	if status.InFlightRequests < 0 {
+		status.InFlightRequests = 0
+	}
+	for _, mq := range queue.GetManager().ManagedQueues() {
+		status.QueuedItems += mq.NumberInQueue()
+		if n := mq.NumberInProgress(); n > 0 {
+			status.InProgressItems += n
+		}
+	}
+	return status
+}
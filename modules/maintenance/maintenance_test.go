@@ -0,0 +1,37 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package maintenance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableDisable(t *testing.T) {
+	defer Disable()
+	assert.False(t, IsEnabled())
+
+	Enable("")
+	assert.True(t, IsEnabled())
+	state := GetState()
+	assert.Equal(t, DefaultMessage, state.Message)
+	assert.False(t, state.Since.IsZero())
+
+	Enable("Upgrading")
+	assert.Equal(t, "Upgrading", GetState().Message)
+	assert.Equal(t, state.Since, GetState().Since)
+
+	Disable()
+	assert.False(t, IsEnabled())
+	assert.Equal(t, State{}, GetState())
+}
+
+func TestGetStatus(t *testing.T) {
+	status := GetStatus()
+	assert.False(t, status.Enabled)
+	assert.EqualValues(t, 0, status.InFlightRequests)
+	assert.True(t, status.IsDrained())
+}
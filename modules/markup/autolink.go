@@ -0,0 +1,147 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"golang.org/x/net/html"
+)
+
+// AutoLinkRule links the text matching Pattern to URL. In URL, $0 or ${0} is
+// replaced with the matched text, $1 or ${1} with the first group, and
+// ${name} with the named group "name", all of them escaped.
+type AutoLinkRule struct {
+	Pattern string `json:"p"`
+	URL     string `json:"u"`
+}
+
+// autoLinkVarPattern matches the variables of the URL of an auto-link rule
+var autoLinkVarPattern = regexp.MustCompile(`\$(?:(\d+)|\{(\w+)\})`)
+
+type compiledAutoLinkRule struct {
+	re  *regexp.Regexp
+	url string
+}
+
+// compiledAutoLinkRules caches the compiled rules by their encoded form
+var compiledAutoLinkRules sync.Map
+
+// ValidateAutoLinkRule checks that the pattern of the rule compiles, cannot
+// match an empty text and that its URL is an http or https URL.
+func ValidateAutoLinkRule(rule AutoLinkRule) error {
+	re, err := regexp.Compile(rule.Pattern)
+	if err != nil {
+		return err
+	}
+	if re.MatchString("") {
+		return fmt.Errorf("pattern matches an empty text: %s", rule.Pattern)
+	}
+	u, err := url.Parse(autoLinkVarPattern.ReplaceAllString(rule.URL, "x"))
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("not an http or https URL: %s", rule.URL)
+	}
+	return nil
+}
+
+// EncodeAutoLinkRules encodes the rules for the "autolinks" key of the metas,
+// the rules which come first take precedence.
+func EncodeAutoLinkRules(rules []AutoLinkRule) string {
+	if len(rules) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		log.Error("EncodeAutoLinkRules: %v", err)
+		return ""
+	}
+	return string(data)
+}
+
+// getAutoLinkRules returns the compiled rules of the "autolinks" key of the
+// metas, the invalid rules are left out.
+func getAutoLinkRules(metas map[string]string) []*compiledAutoLinkRule {
+	encoded := metas["autolinks"]
+	if encoded == "" {
+		return nil
+	}
+	if cached, ok := compiledAutoLinkRules.Load(encoded); ok {
+		return cached.([]*compiledAutoLinkRule)
+	}
+
+	var rules []AutoLinkRule
+	if err := json.Unmarshal([]byte(encoded), &rules); err != nil {
+		log.Error("Unable to decode auto-link rules %q: %v", encoded, err)
+	}
+	compiled := make([]*compiledAutoLinkRule, 0, len(rules))
+	for _, rule := range rules {
+		if err := ValidateAutoLinkRule(rule); err != nil {
+			log.Warn("Ignoring auto-link rule %q: %v", rule.Pattern, err)
+			continue
+		}
+		compiled = append(compiled, &compiledAutoLinkRule{re: regexp.MustCompile(rule.Pattern), url: rule.URL})
+	}
+	compiledAutoLinkRules.Store(encoded, compiled)
+	return compiled
+}
+
+// expand returns the URL of the rule for a match in text
+func (rule *compiledAutoLinkRule) expand(text string, match []int) string {
+	group := func(i int) string {
+		if i < 0 || 2*i+1 >= len(match) || match[2*i] < 0 {
+			return ""
+		}
+		return url.PathEscape(text[match[2*i]:match[2*i+1]])
+	}
+	return autoLinkVarPattern.ReplaceAllStringFunc(rule.url, func(v string) string {
+		name := strings.Trim(v, "${}")
+		if i, err := strconv.Atoi(name); err == nil {
+			return group(i)
+		}
+		for i, subexp := range rule.re.SubexpNames() {
+			if subexp != "" && subexp == name {
+				return group(i)
+			}
+		}
+		return ""
+	})
+}
+
+// autoLinkProcessor links the first text matching one of the auto-link rules
+// of the metas. When several rules match at the same place, the one which
+// comes first wins, the rest of the text is handled by the next nodes.
+func autoLinkProcessor(ctx *postProcessCtx, node *html.Node) {
+	if ctx.metas == nil {
+		return
+	}
+	var (
+		found *compiledAutoLinkRule
+		match []int
+	)
+	for _, rule := range getAutoLinkRules(ctx.metas) {
+		m := rule.re.FindStringSubmatchIndex(node.Data)
+		if m == nil || m[0] == m[1] {
+			continue
+		}
+		if found == nil || m[0] < match[0] {
+			found, match = rule, m
+		}
+	}
+	if found == nil {
+		return
+	}
+	replaceContent(node, match[0], match[1], createLink(found.expand(node.Data, match), node.Data[match[0]:match[1]], ""))
+}
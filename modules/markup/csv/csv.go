@@ -10,6 +10,7 @@ import (
 	"html"
 	"io"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"code.gitea.io/gitea/modules/markup"
@@ -20,7 +21,7 @@ var quoteRegexp = regexp.MustCompile(`["'][\s\S]+?["']`)
 
 func init() {
 	markup.RegisterParser(Parser{})
-
+	markup.RegisterDiffRenderer(Parser{})
 }
 
 // Parser implements markup.Parser for orgmode
@@ -64,6 +65,108 @@ func (p Parser) Render(rawBytes []byte, urlPrefix string, metas map[string]strin
 	return tmpBlock.Bytes()
 }
 
+// readRows reads all the rows of the CSV data, the malformed rows are skipped
+func (p Parser) readRows(rawBytes []byte, delimiter rune) [][]string {
+	rd := csv.NewReader(bytes.NewReader(rawBytes))
+	rd.Comma = delimiter
+	rd.FieldsPerRecord = -1
+	var rows [][]string
+	for {
+		fields, err := rd.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+		rows = append(rows, fields)
+	}
+	return rows
+}
+
+func writeDiffLineNums(buf *bytes.Buffer, edit markup.DiffEdit) {
+	for _, idx := range []int{edit.Before, edit.After} {
+		buf.WriteString(`<td class="lines-num">`)
+		if idx >= 0 {
+			buf.WriteString(strconv.Itoa(idx + 1))
+		}
+		buf.WriteString("</td>")
+	}
+}
+
+func writeDiffCells(buf *bytes.Buffer, fields []string) {
+	for _, field := range fields {
+		buf.WriteString("<td>")
+		buf.WriteString(html.EscapeString(field))
+		buf.WriteString("</td>")
+	}
+}
+
+// RenderDiff implements markup.DiffRenderer, the rows are compared as a whole
+// and the changed cells of the changed rows are highlighted.
+func (p Parser) RenderDiff(before, after []byte) ([]byte, error) {
+	delimiter := p.bestDelimiter(after)
+	if len(bytes.TrimSpace(after)) == 0 {
+		delimiter = p.bestDelimiter(before)
+	}
+	rowsBefore, rowsAfter := p.readRows(before, delimiter), p.readRows(after, delimiter)
+	joinRows := func(rows [][]string) []string {
+		joined := make([]string, len(rows))
+		for i, row := range rows {
+			joined[i] = strings.Join(row, "\x00")
+		}
+		return joined
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<table class="table csv-diff">`)
+	for _, edit := range markup.DiffSequences(joinRows(rowsBefore), joinRows(rowsAfter)) {
+		switch edit.Type {
+		case markup.DiffEditEqual:
+			buf.WriteString("<tr>")
+			writeDiffLineNums(&buf, edit)
+			writeDiffCells(&buf, rowsAfter[edit.After])
+		case markup.DiffEditInsert:
+			buf.WriteString(`<tr class="add-code">`)
+			writeDiffLineNums(&buf, edit)
+			writeDiffCells(&buf, rowsAfter[edit.After])
+		case markup.DiffEditDelete:
+			buf.WriteString(`<tr class="del-code">`)
+			writeDiffLineNums(&buf, edit)
+			writeDiffCells(&buf, rowsBefore[edit.Before])
+		case markup.DiffEditChange:
+			buf.WriteString(`<tr class="change-code">`)
+			writeDiffLineNums(&buf, edit)
+			rowBefore, rowAfter := rowsBefore[edit.Before], rowsAfter[edit.After]
+			for i := 0; i < util.Max(len(rowBefore), len(rowAfter)); i++ {
+				switch {
+				case i < len(rowBefore) && i < len(rowAfter) && rowBefore[i] == rowAfter[i]:
+					buf.WriteString("<td>")
+					buf.WriteString(html.EscapeString(rowAfter[i]))
+				case i >= len(rowAfter):
+					buf.WriteString(`<td class="del-code"><span class="removed-code">`)
+					buf.WriteString(html.EscapeString(rowBefore[i]))
+					buf.WriteString("</span>")
+				case i >= len(rowBefore):
+					buf.WriteString(`<td class="add-code"><span class="added-code">`)
+					buf.WriteString(html.EscapeString(rowAfter[i]))
+					buf.WriteString("</span>")
+				default:
+					buf.WriteString(`<td class="change-code"><span class="removed-code">`)
+					buf.WriteString(html.EscapeString(rowBefore[i]))
+					buf.WriteString(`</span> <span class="added-code">`)
+					buf.WriteString(html.EscapeString(rowAfter[i]))
+					buf.WriteString("</span>")
+				}
+				buf.WriteString("</td>")
+			}
+		}
+		buf.WriteString("</tr>")
+	}
+	buf.WriteString("</table>")
+	return buf.Bytes(), nil
+}
+
 // bestDelimiter scores the input CSV data against delimiters, and returns the best match.
 // Reads at most 10k bytes & 10 lines.
 func (p Parser) bestDelimiter(data []byte) rune {
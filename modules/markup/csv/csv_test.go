@@ -28,3 +28,15 @@ func TestRenderCSV(t *testing.T) {
 		assert.EqualValues(t, v, string(res))
 	}
 }
+
+func TestRenderCSVDiff(t *testing.T) {
+	var parser Parser
+	res, err := parser.RenderDiff([]byte("a,b\n1,2\n3,4\n"), []byte("a,b\n1,5\n3,4\n<i>,6\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, `<table class="table csv-diff">`+
+		`<tr><td class="lines-num">1</td><td class="lines-num">1</td><td>a</td><td>b</td></tr>`+
+		`<tr class="change-code"><td class="lines-num">2</td><td class="lines-num">2</td><td>1</td><td class="change-code"><span class="removed-code">2</span> <span class="added-code">5</span></td></tr>`+
+		`<tr><td class="lines-num">3</td><td class="lines-num">3</td><td>3</td><td>4</td></tr>`+
+		`<tr class="add-code"><td class="lines-num"></td><td class="lines-num">4</td><td>&lt;i&gt;</td><td>6</td></tr>`+
+		`</table>`, string(res))
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// DiffRenderer defines an interface for rendering the difference between two
+// versions of a file to HTML in a format aware way
+type DiffRenderer interface {
+	Name() string // markup format name
+	Extensions() []string
+	RenderDiff(before, after []byte) ([]byte, error)
+}
+
+var extDiffRenderers = make(map[string]DiffRenderer)
+
+// RegisterDiffRenderer registers a new diff renderer
+func RegisterDiffRenderer(renderer DiffRenderer) {
+	for _, ext := range renderer.Extensions() {
+		extDiffRenderers[strings.ToLower(ext)] = renderer
+	}
+}
+
+// GetDiffRendererByFileName get diff renderer by filename
+func GetDiffRendererByFileName(filename string) DiffRenderer {
+	extension := strings.ToLower(filepath.Ext(filename))
+	return extDiffRenderers[extension]
+}
+
+// DiffEditType represents the type of a DiffEdit
+type DiffEditType int
+
+// DiffEditType possible values
+const (
+	DiffEditEqual DiffEditType = iota
+	DiffEditInsert
+	DiffEditDelete
+	DiffEditChange
+)
+
+// DiffEdit is an edit of an element between two sequences, Before and After
+// are the indices of the element in the sequences, -1 if it does not exist.
+type DiffEdit struct {
+	Type   DiffEditType
+	Before int
+	After  int
+}
+
+// DiffSequences computes the edits turning the before sequence into the after
+// sequence. A deleted element followed by an inserted one is a change.
+func DiffSequences(before, after []string) []DiffEdit {
+	index := make(map[string]rune)
+	toRunes := func(elems []string) []rune {
+		runes := make([]rune, len(elems))
+		for i, elem := range elems {
+			r, ok := index[elem]
+			if !ok {
+				r = rune(len(index) + 1)
+				index[elem] = r
+			}
+			runes[i] = r
+		}
+		return runes
+	}
+	runesBefore, runesAfter := toRunes(before), toRunes(after)
+
+	edits := make([]DiffEdit, 0, len(after))
+	var deleted, inserted int
+	beforeIdx, afterIdx := 0, 0
+	flush := func() {
+		for ; deleted > 0 && inserted > 0; deleted, inserted = deleted-1, inserted-1 {
+			edits = append(edits, DiffEdit{Type: DiffEditChange, Before: beforeIdx, After: afterIdx})
+			beforeIdx++
+			afterIdx++
+		}
+		for ; deleted > 0; deleted-- {
+			edits = append(edits, DiffEdit{Type: DiffEditDelete, Before: beforeIdx, After: -1})
+			beforeIdx++
+		}
+		for ; inserted > 0; inserted-- {
+			edits = append(edits, DiffEdit{Type: DiffEditInsert, Before: -1, After: afterIdx})
+			afterIdx++
+		}
+	}
+
+	// the runes must stay valid code points, compare the elements one by one otherwise
+	var diffs []diffmatchpatch.Diff
+	if len(index) < 0xD800 {
+		diffs = diffmatchpatch.New().DiffMainRunes(runesBefore, runesAfter, false)
+	} else {
+		diffs = []diffmatchpatch.Diff{
+			{Type: diffmatchpatch.DiffDelete, Text: strings.Repeat(" ", len(before))},
+			{Type: diffmatchpatch.DiffInsert, Text: strings.Repeat(" ", len(after))},
+		}
+	}
+	for _, diff := range diffs {
+		count := len([]rune(diff.Text))
+		switch diff.Type {
+		case diffmatchpatch.DiffDelete:
+			deleted += count
+		case diffmatchpatch.DiffInsert:
+			inserted += count
+		case diffmatchpatch.DiffEqual:
+			flush()
+			for i := 0; i < count; i++ {
+				edits = append(edits, DiffEdit{Type: DiffEditEqual, Before: beforeIdx, After: afterIdx})
+				beforeIdx++
+				afterIdx++
+			}
+		}
+	}
+	flush()
+	return edits
+}
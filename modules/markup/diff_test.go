@@ -0,0 +1,29 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup_test
+
+import (
+	"testing"
+
+	. "code.gitea.io/gitea/modules/markup"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSequences(t *testing.T) {
+	assert.Equal(t, []DiffEdit{
+		{Type: DiffEditEqual, Before: 0, After: 0},
+		{Type: DiffEditChange, Before: 1, After: 1},
+		{Type: DiffEditEqual, Before: 2, After: 2},
+		{Type: DiffEditDelete, Before: 3, After: -1},
+		{Type: DiffEditEqual, Before: 4, After: 3},
+		{Type: DiffEditInsert, Before: -1, After: 4},
+	}, DiffSequences([]string{"a", "b", "c", "d", "e"}, []string{"a", "B", "c", "e", "f"}))
+
+	assert.Equal(t, []DiffEdit{
+		{Type: DiffEditInsert, Before: -1, After: 0},
+	}, DiffSequences(nil, []string{"a"}))
+	assert.Empty(t, DiffSequences(nil, nil))
+}
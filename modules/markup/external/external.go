@@ -6,15 +6,21 @@ package external
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/setting"
 )
 
@@ -50,7 +56,30 @@ func envMark(envName string) string {
 }
 
 // Render renders the data of the document to HTML via the external tool.
+// Output is cached by the document content so that repeated views don't run
+// the tool again.
 func (p *Parser) Render(rawBytes []byte, urlPrefix string, metas map[string]string, isWiki bool) []byte {
+	output, err := cache.GetStringWithTTL(p.cacheKey(rawBytes, urlPrefix), p.CacheTTL, func() (string, error) {
+		output, err := p.render(rawBytes, urlPrefix)
+		return string(output), err
+	})
+	if err != nil {
+		log.Error("%s render %s failed: %v", p.Name(), p.Command, err)
+		return []byte("")
+	}
+	return []byte(output)
+}
+
+// cacheKey returns the key of the rendered output of rawBytes. The URL prefix
+// is part of the key as links in the output depend on it.
+func (p *Parser) cacheKey(rawBytes []byte, urlPrefix string) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00", p.Command, p.Version, urlPrefix, strconv.FormatBool(p.IsInputFile))
+	_, _ = h.Write(rawBytes)
+	return fmt.Sprintf("markup_render:%s:%x", p.Name(), h.Sum(nil))
+}
+
+func (p *Parser) render(rawBytes []byte, urlPrefix string) ([]byte, error) {
 	var (
 		bs           []byte
 		buf          = bytes.NewBuffer(bs)
@@ -67,27 +96,31 @@ func (p *Parser) Render(rawBytes []byte, urlPrefix string, metas map[string]stri
 		// write to temp file
 		f, err := ioutil.TempFile("", "gitea_input")
 		if err != nil {
-			log.Error("%s create temp file when rendering %s failed: %v", p.Name(), p.Command, err)
-			return []byte("")
+			return nil, fmt.Errorf("create temp file: %v", err)
 		}
 		defer os.Remove(f.Name())
 
 		_, err = io.Copy(f, rd)
 		if err != nil {
 			f.Close()
-			log.Error("%s write data to temp file when rendering %s failed: %v", p.Name(), p.Command, err)
-			return []byte("")
+			return nil, fmt.Errorf("write data to temp file: %v", err)
 		}
 
 		err = f.Close()
 		if err != nil {
-			log.Error("%s close temp file when rendering %s failed: %v", p.Name(), p.Command, err)
-			return []byte("")
+			return nil, fmt.Errorf("close temp file: %v", err)
 		}
 		args = append(args, f.Name())
 	}
 
-	cmd := exec.Command(commands[0], args...)
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(process.DefaultContext, timeout)
+	defer cancel()
+
+	cmd := sandboxCommand(ctx, p.MarkupParser, commands[0], args...)
 	cmd.Env = append(
 		os.Environ(),
 		"GITEA_PREFIX_SRC="+urlPrefix,
@@ -97,9 +130,17 @@ func (p *Parser) Render(rawBytes []byte, urlPrefix string, metas map[string]stri
 		cmd.Stdin = rd
 	}
 	cmd.Stdout = buf
-	if err := cmd.Run(); err != nil {
-		log.Error("%s render run command %s %v failed: %v", p.Name(), commands[0], args, err)
-		return []byte("")
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("run command %s %v: %v", commands[0], args, err)
+	}
+	pid := process.GetManager().Add(fmt.Sprintf("Render %s [%s]", p.Name(), commands[0]), cancel)
+	defer process.GetManager().Remove(pid)
+
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("command %s %v timed out after %v", commands[0], args, timeout)
+		}
+		return nil, fmt.Errorf("run command %s %v: %v", commands[0], args, err)
 	}
-	return buf.Bytes()
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,54 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package external
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_Render(t *testing.T) {
+	parser := &Parser{setting.MarkupParser{
+		MarkupName: "cat",
+		Command:    "cat",
+		Timeout:    10 * time.Second,
+		MaxMemory:  512 * 1024 * 1024,
+		MaxCPUTime: 5 * time.Second,
+	}}
+	assert.Equal(t, "<p>hello</p>", string(parser.Render([]byte("<p>hello</p>"), "/user/repo/src/branch/master", nil, false)))
+
+	parser.Command = "sleep 5"
+	parser.Timeout = 100 * time.Millisecond
+	start := time.Now()
+	_, err := parser.render(nil, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+	assert.Less(t, int64(time.Since(start)), int64(4*time.Second))
+}
+
+func TestParser_cacheKey(t *testing.T) {
+	parser := &Parser{setting.MarkupParser{MarkupName: "asciidoc", Command: "asciidoc -"}}
+	key := parser.cacheKey([]byte("= Title"), "/user/repo/src/branch/master")
+	assert.Equal(t, key, parser.cacheKey([]byte("= Title"), "/user/repo/src/branch/master"))
+	assert.NotEqual(t, key, parser.cacheKey([]byte("= Other"), "/user/repo/src/branch/master"))
+	assert.NotEqual(t, key, parser.cacheKey([]byte("= Title"), "/user/repo/src/branch/develop"))
+
+	parser.Version = "2"
+	assert.NotEqual(t, key, parser.cacheKey([]byte("= Title"), "/user/repo/src/branch/master"))
+}
+
+func TestResourceLimits(t *testing.T) {
+	assert.Empty(t, resourceLimits(setting.MarkupParser{}))
+	assert.Equal(t, []string{"ulimit -v 1024", "ulimit -t 1"}, resourceLimits(setting.MarkupParser{
+		MaxMemory:  1024 * 1024,
+		MaxCPUTime: 100 * time.Millisecond,
+	}))
+}
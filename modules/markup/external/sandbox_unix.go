@@ -0,0 +1,32 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package external
+
+import (
+	"context"
+	"os/exec"
+
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// sandboxCommand returns the command running name with the resource limits
+// of the parser applied.
+func sandboxCommand(ctx context.Context, parser setting.MarkupParser, name string, args ...string) *exec.Cmd {
+	return process.LimitedCommand(ctx, parserLimits(parser), name, args...)
+}
+
+func parserLimits(parser setting.MarkupParser) process.Limits {
+	return process.Limits{
+		MaxMemory:  parser.MaxMemory,
+		MaxCPUTime: parser.MaxCPUTime,
+	}
+}
+
+func resourceLimits(parser setting.MarkupParser) []string {
+	return parserLimits(parser).ShellLimits()
+}
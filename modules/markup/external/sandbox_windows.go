@@ -0,0 +1,20 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package external
+
+import (
+	"context"
+	"os/exec"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// sandboxCommand returns the command running name. Memory and CPU limits are
+// not supported on Windows, only the timeout of the context applies.
+func sandboxCommand(ctx context.Context, parser setting.MarkupParser, name string, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, name, args...)
+}
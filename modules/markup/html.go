@@ -156,6 +156,7 @@ var defaultProcessors = []processor{
 	shortLinkProcessor,
 	linkProcessor,
 	mentionProcessor,
+	autoLinkProcessor,
 	issueIndexPatternProcessor,
 	sha1CurrentPatternProcessor,
 	emailAddressProcessor,
@@ -198,6 +199,7 @@ var commitMessageProcessors = []processor{
 	fullSha1PatternProcessor,
 	linkProcessor,
 	mentionProcessor,
+	autoLinkProcessor,
 	issueIndexPatternProcessor,
 	sha1CurrentPatternProcessor,
 	emailAddressProcessor,
@@ -233,6 +235,7 @@ var commitMessageSubjectProcessors = []processor{
 	fullSha1PatternProcessor,
 	linkProcessor,
 	mentionProcessor,
+	autoLinkProcessor,
 	issueIndexPatternProcessor,
 	sha1CurrentPatternProcessor,
 	emojiShortCodeProcessor,
@@ -463,6 +466,10 @@ func createEmoji(content, class, name string) *html.Node {
 }
 
 func createCustomEmoji(alias, class string) *html.Node {
+	return createCustomEmojiImage(alias, fmt.Sprintf(`%s/img/emoji/%s.png`, setting.StaticURLPrefix, alias), class)
+}
+
+func createCustomEmojiImage(alias, src, class string) *html.Node {
 
 	span := &html.Node{
 		Type: html.ElementNode,
@@ -481,7 +488,7 @@ func createCustomEmoji(alias, class string) *html.Node {
 		Attr:     []html.Attribute{},
 	}
 	if class != "" {
-		img.Attr = append(img.Attr, html.Attribute{Key: "src", Val: fmt.Sprintf(`%s/img/emoji/%s.png`, setting.StaticURLPrefix, alias)})
+		img.Attr = append(img.Attr, html.Attribute{Key: "src", Val: src})
 	}
 
 	span.AppendChild(img)
@@ -904,6 +911,11 @@ func emojiShortCodeProcessor(ctx *postProcessCtx, node *html.Node) {
 	alias = strings.Replace(alias, ":", "", -1)
 	converted := emoji.FromAlias(alias)
 	if converted == nil {
+		// check if this is an emoji uploaded by an administrator
+		if link, ok := emoji.FromCustom(alias); ok {
+			replaceContent(node, m[0], m[1], createCustomEmojiImage(alias, link, "emoji"))
+			return
+		}
 		// check if this is a custom reaction
 		s := strings.Join(setting.UI.Reactions, " ") + "gitea"
 		if strings.Contains(s, alias) {
@@ -266,6 +266,15 @@ func TestRender_emoji(t *testing.T) {
 	test(
 		"Some text with 😄😄 2 emoji next to each other",
 		`<p>Some text with <span class="emoji" aria-label="grinning face with smiling eyes">😄</span><span class="emoji" aria-label="grinning face with smiling eyes">😄</span> 2 emoji next to each other</p>`)
+	// emoji uploaded by an administrator
+	emoji.AddCustom("party_parrot", AppSubURL+"custom-emoji/parrot.gif")
+	test(
+		"Some text with :party_parrot: in the middle",
+		`<p>Some text with <span class="emoji" aria-label="party_parrot"><img src="`+AppSubURL+`custom-emoji/parrot.gif"/></span> in the middle</p>`)
+	emoji.RemoveCustom("party_parrot")
+	test(
+		":party_parrot:",
+		`<p>:party_parrot:</p>`)
 	// should match nothing
 	test(
 		"2001:0db8:85a3:0000:0000:8a2e:0370:7334",
@@ -373,3 +382,42 @@ func TestRender_ShortLinks(t *testing.T) {
 		`<p><a href="https://example.org" rel="nofollow">[[foobar]]</a></p>`,
 		`<p><a href="https://example.org" rel="nofollow">[[foobar]]</a></p>`)
 }
+
+func TestRender_AutoLinks(t *testing.T) {
+	setting.AppURL = AppURL
+	setting.AppSubURL = AppSubURL
+
+	metas := map[string]string{
+		"user": "gogits",
+		"repo": "gogs",
+		"autolinks": EncodeAutoLinkRules([]AutoLinkRule{
+			{Pattern: `\bJIRA-\d+\b`, URL: "https://jira.example.com/browse/${0}"},
+			{Pattern: `\b(?P<project>[A-Z]+)-(\d+)\b`, URL: "https://tracker.example.com/${project}/issues/$2"},
+			// the pattern of this rule is invalid, it is ignored
+			{Pattern: `(`, URL: "https://invalid.example.com/"},
+		}),
+	}
+	test := func(input, expected string) {
+		buffer := RenderString("a.md", input, setting.AppSubURL, metas)
+		assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(buffer))
+	}
+
+	// the first rule takes precedence over the second one
+	test("see JIRA-123",
+		`<p>see <a href="https://jira.example.com/browse/JIRA-123" rel="nofollow">JIRA-123</a></p>`)
+	test("OPS-7 and JIRA-8",
+		`<p><a href="https://tracker.example.com/OPS/issues/7" rel="nofollow">OPS-7</a> and <a href="https://jira.example.com/browse/JIRA-8" rel="nofollow">JIRA-8</a></p>`)
+	test("the rules are not applied in code: `JIRA-1`",
+		`<p>the rules are not applied in code: <code>JIRA-1</code></p>`)
+	test("no rule matches foo-1", `<p>no rule matches foo-1</p>`)
+	test("JIRA-5 and #1",
+		`<p><a href="https://jira.example.com/browse/JIRA-5" rel="nofollow">JIRA-5</a> and <a href="`+util.URLJoin(AppURL, "gogits", "gogs", "issues", "1")+`" class="ref-issue" rel="nofollow">#1</a></p>`)
+}
+
+func TestValidateAutoLinkRule(t *testing.T) {
+	assert.NoError(t, ValidateAutoLinkRule(AutoLinkRule{Pattern: `JIRA-\d+`, URL: "https://jira.example.com/browse/$0"}))
+	assert.Error(t, ValidateAutoLinkRule(AutoLinkRule{Pattern: `JIRA-(\d+`, URL: "https://jira.example.com/browse/$0"}))
+	assert.Error(t, ValidateAutoLinkRule(AutoLinkRule{Pattern: `\d*`, URL: "https://jira.example.com/browse/$0"}))
+	assert.Error(t, ValidateAutoLinkRule(AutoLinkRule{Pattern: `JIRA-\d+`, URL: "javascript:alert($0)"}))
+	assert.Error(t, ValidateAutoLinkRule(AutoLinkRule{Pattern: `JIRA-\d+`, URL: "/browse/$0"}))
+}
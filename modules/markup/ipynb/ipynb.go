@@ -0,0 +1,178 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ipynb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/markup"
+)
+
+func init() {
+	markup.RegisterDiffRenderer(Renderer{})
+}
+
+// Renderer implements markup.DiffRenderer for Jupyter notebooks
+type Renderer struct {
+}
+
+// Name implements markup.DiffRenderer
+func (Renderer) Name() string {
+	return "ipynb"
+}
+
+// Extensions implements markup.DiffRenderer
+func (Renderer) Extensions() []string {
+	return []string{".ipynb"}
+}
+
+// source is the source of a cell, which is either a string or a list of lines
+type source string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *source) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*s = source(strings.Join(lines, ""))
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	*s = source(text)
+	return nil
+}
+
+// Cell is a cell of a notebook, its outputs and execution count are ignored
+type Cell struct {
+	CellType string `json:"cell_type"`
+	Source   source `json:"source"`
+}
+
+// Lines returns the lines of the source of the cell
+func (c *Cell) Lines() []string {
+	if len(c.Source) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(c.Source), "\n"), "\n")
+}
+
+func (c *Cell) key() string {
+	return c.CellType + "\x00" + string(c.Source)
+}
+
+// ParseCells parses the cells of a notebook, an empty file has no cells
+func ParseCells(data []byte) ([]*Cell, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var notebook struct {
+		Cells []*Cell `json:"cells"`
+	}
+	if err := json.Unmarshal(data, &notebook); err != nil {
+		return nil, fmt.Errorf("invalid notebook: %v", err)
+	}
+	return notebook.Cells, nil
+}
+
+func writeCellLines(buf *bytes.Buffer, before, after []string, edits []markup.DiffEdit) {
+	buf.WriteString(`<table class="chroma"><tbody>`)
+	for _, edit := range edits {
+		var class, marker, line string
+		switch edit.Type {
+		case markup.DiffEditInsert:
+			class, marker, line = "add-code", "+", after[edit.After]
+		case markup.DiffEditDelete:
+			class, marker, line = "del-code", "-", before[edit.Before]
+		default:
+			class, marker, line = "same-code", " ", after[edit.After]
+		}
+		buf.WriteString(`<tr class="` + class + `">`)
+		for _, idx := range []int{edit.Before, edit.After} {
+			buf.WriteString(`<td class="lines-num">`)
+			if idx >= 0 {
+				buf.WriteString(strconv.Itoa(idx + 1))
+			}
+			buf.WriteString("</td>")
+		}
+		buf.WriteString(`<td class="lines-type-marker"><span class="mono">` + marker + `</span></td>`)
+		buf.WriteString(`<td class="lines-code"><span class="mono wrap">`)
+		buf.WriteString(html.EscapeString(line))
+		buf.WriteString("</span></td></tr>")
+	}
+	buf.WriteString("</tbody></table>")
+}
+
+// lineEdits returns the line edits of a cell, the changed lines are split
+// into a deleted and an inserted line
+func lineEdits(before, after []string) []markup.DiffEdit {
+	var edits []markup.DiffEdit
+	for _, edit := range markup.DiffSequences(before, after) {
+		if edit.Type == markup.DiffEditChange {
+			edits = append(edits,
+				markup.DiffEdit{Type: markup.DiffEditDelete, Before: edit.Before, After: -1},
+				markup.DiffEdit{Type: markup.DiffEditInsert, Before: -1, After: edit.After})
+			continue
+		}
+		edits = append(edits, edit)
+	}
+	return edits
+}
+
+func writeCell(buf *bytes.Buffer, class string, cell *Cell, before, after []string) {
+	buf.WriteString(`<div class="ui attached segment notebook-cell ` + class + `">`)
+	buf.WriteString(`<div class="ui mini basic label">`)
+	buf.WriteString(html.EscapeString(cell.CellType))
+	buf.WriteString("</div>")
+	writeCellLines(buf, before, after, lineEdits(before, after))
+	buf.WriteString("</div>")
+}
+
+// RenderDiff implements markup.DiffRenderer, the cells are compared by their
+// type and source so that changed outputs and execution counts are no noise.
+func (Renderer) RenderDiff(before, after []byte) ([]byte, error) {
+	cellsBefore, err := ParseCells(before)
+	if err != nil {
+		return nil, err
+	}
+	cellsAfter, err := ParseCells(after)
+	if err != nil {
+		return nil, err
+	}
+	keys := func(cells []*Cell) []string {
+		keys := make([]string, len(cells))
+		for i, cell := range cells {
+			keys[i] = cell.key()
+		}
+		return keys
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<div class="notebook-diff">`)
+	for _, edit := range markup.DiffSequences(keys(cellsBefore), keys(cellsAfter)) {
+		switch edit.Type {
+		case markup.DiffEditEqual:
+			cell := cellsAfter[edit.After]
+			writeCell(&buf, "same-cell", cell, cell.Lines(), cell.Lines())
+		case markup.DiffEditInsert:
+			cell := cellsAfter[edit.After]
+			writeCell(&buf, "add-cell", cell, nil, cell.Lines())
+		case markup.DiffEditDelete:
+			cell := cellsBefore[edit.Before]
+			writeCell(&buf, "del-cell", cell, cell.Lines(), nil)
+		case markup.DiffEditChange:
+			cell := cellsAfter[edit.After]
+			writeCell(&buf, "change-cell", cell, cellsBefore[edit.Before].Lines(), cell.Lines())
+		}
+	}
+	buf.WriteString("</div>")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ipynb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCells(t *testing.T) {
+	cells, err := ParseCells([]byte(`{"cells": [
+		{"cell_type": "markdown", "source": "# Title\n"},
+		{"cell_type": "code", "execution_count": 1, "source": ["x = 1\n", "print(x)"], "outputs": []}
+	]}`))
+	assert.NoError(t, err)
+	assert.Len(t, cells, 2)
+	assert.Equal(t, []string{"# Title"}, cells[0].Lines())
+	assert.Equal(t, "code", cells[1].CellType)
+	assert.Equal(t, []string{"x = 1", "print(x)"}, cells[1].Lines())
+
+	cells, err = ParseCells(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, cells)
+
+	_, err = ParseCells([]byte("not a notebook"))
+	assert.Error(t, err)
+}
+
+func TestRenderDiff(t *testing.T) {
+	before := `{"cells": [
+		{"cell_type": "markdown", "source": "# Title"},
+		{"cell_type": "code", "execution_count": 1, "source": ["x = 1\n", "print(x)"], "outputs": [{"text": "1"}]},
+		{"cell_type": "code", "source": "removed()"}
+	]}`
+	after := `{"cells": [
+		{"cell_type": "markdown", "source": "# Title"},
+		{"cell_type": "code", "execution_count": 7, "source": ["x = 2\n", "print(x)"], "outputs": [{"text": "2"}]},
+		{"cell_type": "code", "source": "<added>"}
+	]}`
+	res, err := Renderer{}.RenderDiff([]byte(before), []byte(after))
+	assert.NoError(t, err)
+	html := string(res)
+	assert.Equal(t, 1, strings.Count(html, "same-cell"))
+	assert.Equal(t, 2, strings.Count(html, "change-cell"))
+	assert.Contains(t, html, `<td class="lines-code"><span class="mono wrap">x = 1</span>`)
+	assert.Contains(t, html, "&lt;added&gt;")
+	assert.NotContains(t, html, "<added>")
+
+	// only the outputs and execution counts changed
+	res, err = Renderer{}.RenderDiff([]byte(before), []byte(strings.Replace(before, `"execution_count": 1`, `"execution_count": 2`, 1)))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(string(res), "same-cell"))
+}
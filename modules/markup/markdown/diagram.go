@@ -0,0 +1,117 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markdown
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/base64"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// diagramLanguages maps the languages of code blocks to diagram types
+var diagramLanguages = map[string]string{
+	"mermaid":  "mermaid",
+	"plantuml": "plantuml",
+	"puml":     "plantuml",
+	"graphviz": "graphviz",
+	"dot":      "graphviz",
+}
+
+// plantUMLEncoding is the base64 alphabet of PlantUML servers
+var plantUMLEncoding = base64.NewEncoding("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_").WithPadding(base64.NoPadding)
+
+// Diagram is a code block which is rendered as an image by a render server
+type Diagram struct {
+	ast.BaseBlock
+	DiagramType string
+	Source      []byte
+}
+
+// Dump implements Node.Dump
+func (n *Diagram) Dump(source []byte, level int) {
+	m := map[string]string{
+		"DiagramType": n.DiagramType,
+	}
+	ast.DumpHelper(n, source, level, m, nil)
+}
+
+// KindDiagram is the NodeKind for Diagram
+var KindDiagram = ast.NewNodeKind("Diagram")
+
+// Kind implements Node.Kind.
+func (n *Diagram) Kind() ast.NodeKind {
+	return KindDiagram
+}
+
+// NewDiagram returns a new Diagram node
+func NewDiagram(diagramType string, source []byte) *Diagram {
+	return &Diagram{
+		DiagramType: diagramType,
+		Source:      source,
+	}
+}
+
+// diagramType returns the enabled diagram type of a code block language, or
+// an empty string if the code block is not rendered as a diagram
+func diagramType(language string) string {
+	diagramType, ok := diagramLanguages[strings.ToLower(language)]
+	if !ok {
+		return ""
+	}
+	enabled := false
+	for _, t := range setting.Markdown.DiagramTypes {
+		if strings.TrimSpace(t) == diagramType {
+			enabled = true
+			break
+		}
+	}
+	if !enabled {
+		return ""
+	}
+	if diagramType == "plantuml" && setting.Markdown.PlantUMLServerURL != "" {
+		return diagramType
+	}
+	if setting.Markdown.DiagramServerURL == "" {
+		return ""
+	}
+	return diagramType
+}
+
+// DiagramURL returns the URL of the SVG image of the diagram on the render
+// server, the source is compressed into the URL
+func DiagramURL(diagramType string, source []byte) (string, error) {
+	var buf bytes.Buffer
+	if diagramType == "plantuml" && setting.Markdown.PlantUMLServerURL != "" {
+		w, err := flate.NewWriter(&buf, flate.BestCompression)
+		if err != nil {
+			return "", err
+		}
+		if _, err := w.Write(source); err != nil {
+			return "", err
+		}
+		if err := w.Close(); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(setting.Markdown.PlantUMLServerURL, "/") + "/svg/" + plantUMLEncoding.EncodeToString(buf.Bytes()), nil
+	}
+
+	w, err := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(source); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(setting.Markdown.DiagramServerURL, "/") + "/" + diagramType + "/svg/" + base64.URLEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -10,8 +10,10 @@ import (
 	"regexp"
 	"strings"
 
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/markup/common"
+	"code.gitea.io/gitea/modules/markup/markdown/math"
 	"code.gitea.io/gitea/modules/setting"
 	giteautil "code.gitea.io/gitea/modules/util"
 
@@ -59,12 +61,30 @@ func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc pa
 		toc = make([]Header, 0, 100)
 	}
 
+	// code blocks rendered as math or diagrams are replaced after the walk
+	replacements := map[ast.Node]ast.Node{}
+
 	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
 		if !entering {
 			return ast.WalkContinue, nil
 		}
 
 		switch v := n.(type) {
+		case *ast.FencedCodeBlock:
+			language := string(v.Language(reader.Source()))
+			if language == "math" && setting.Markdown.EnableMath {
+				block := math.NewBlock("")
+				block.SetLines(v.Lines())
+				replacements[n] = block
+			} else if diagramType := diagramType(language); diagramType != "" {
+				var source bytes.Buffer
+				lines := v.Lines()
+				for i := 0; i < lines.Len(); i++ {
+					segment := lines.At(i)
+					source.Write(segment.Value(reader.Source()))
+				}
+				replacements[n] = NewDiagram(diagramType, source.Bytes())
+			}
 		case *ast.Heading:
 			if createTOC {
 				text := n.Text(reader.Source())
@@ -165,6 +185,10 @@ func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc pa
 		return ast.WalkContinue, nil
 	})
 
+	for n, replacement := range replacements {
+		n.Parent().ReplaceChild(n.Parent(), n, replacement)
+	}
+
 	if createTOC && len(toc) > 0 {
 		lang := rc.Lang
 		if len(lang) == 0 {
@@ -251,6 +275,7 @@ func (r *HTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
 	reg.Register(KindDetails, r.renderDetails)
 	reg.Register(KindSummary, r.renderSummary)
 	reg.Register(KindIcon, r.renderIcon)
+	reg.Register(KindDiagram, r.renderDiagram)
 	reg.Register(KindTaskCheckBoxListItem, r.renderTaskCheckBoxListItem)
 	reg.Register(east.KindTaskCheckBox, r.renderTaskCheckBox)
 }
@@ -341,6 +366,27 @@ func (r *HTMLRenderer) renderIcon(w util.BufWriter, source []byte, node ast.Node
 	return ast.WalkContinue, nil
 }
 
+func (r *HTMLRenderer) renderDiagram(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*Diagram)
+	link, err := DiagramURL(n.DiagramType, n.Source)
+	if err != nil {
+		log.Error("DiagramURL: %v", err)
+		return ast.WalkStop, err
+	}
+
+	// Diagrams are embedded as images so the rendered SVG cannot run scripts
+	_, err = w.WriteString(fmt.Sprintf(`<div class="diagram"><img src="%s" alt="%s diagram"></div>`, util.EscapeHTML([]byte(link)), n.DiagramType))
+	if err != nil {
+		return ast.WalkStop, err
+	}
+
+	return ast.WalkContinue, nil
+}
+
 func (r *HTMLRenderer) renderTaskCheckBoxListItem(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*TaskCheckBoxListItem)
 	if entering {
@@ -308,3 +308,67 @@ func TestRender_RenderParagraphs(t *testing.T) {
 	test(t, "A\n\nB\nC\n", 2)
 	test(t, "A\n\n\nB\nC\n", 2)
 }
+
+func TestRender_Math(t *testing.T) {
+	setting.AppURL = AppURL
+	setting.AppSubURL = AppSubURL
+	defer func(enableMath bool) {
+		setting.Markdown.EnableMath = enableMath
+	}(setting.Markdown.EnableMath)
+
+	test := func(input, expected string) {
+		buffer := RenderString(input, setting.AppSubURL, nil)
+		assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(buffer))
+	}
+
+	// math is not rendered unless it is enabled
+	setting.Markdown.EnableMath = false
+	test("$x^2$ costs $5", `<p>$x^2$ costs $5</p>`)
+	test("```math\n\\sqrt{2}\n```", `<pre><code class="chroma language-math">\sqrt{2}
+</code></pre>`)
+
+	setting.Markdown.EnableMath = true
+	test("$x^2$ costs $5 and $10",
+		`<p><code class="language-math">x^2</code> costs $5 and $10</p>`)
+	test("Inline $$a < b$$ too",
+		`<p>Inline <code class="language-math display">a &lt; b</code> too</p>`)
+	test("$$\n\\frac{a}{b}\n$$",
+		`<pre><code class="language-math display">\frac{a}{b}</code></pre>`)
+	test("```math\n\\sqrt{2}\n```",
+		`<pre><code class="language-math display">\sqrt{2}</code></pre>`)
+	test("\\begin{aligned}\na &= 1 \\\\\nb &= 2\n\\end{aligned}",
+		`<pre><code class="language-math display">\begin{aligned}
+a &amp;= 1 \\
+b &amp;= 2
+\end{aligned}</code></pre>`)
+	test("`$x$`", `<p><code>$x$</code></p>`)
+}
+
+func TestRender_Diagrams(t *testing.T) {
+	setting.AppURL = AppURL
+	setting.AppSubURL = AppSubURL
+	defer func(diagramServerURL, plantUMLServerURL string) {
+		setting.Markdown.DiagramServerURL = diagramServerURL
+		setting.Markdown.PlantUMLServerURL = plantUMLServerURL
+	}(setting.Markdown.DiagramServerURL, setting.Markdown.PlantUMLServerURL)
+
+	test := func(input, expected string) {
+		buffer := RenderString(input, setting.AppSubURL, nil)
+		assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(buffer))
+	}
+
+	// not rendered without a render server
+	setting.Markdown.DiagramServerURL = ""
+	test("```mermaid\ngraph TD;\n```", `<pre><code class="chroma language-mermaid">graph TD;
+</code></pre>`)
+
+	setting.Markdown.DiagramServerURL = "https://kroki.example.com/"
+	test("```mermaid\ngraph TD;\n```",
+		`<div class="diagram"><img src="https://kroki.example.com/mermaid/svg/eNpKL0osyFAIcbHmAgwAE9YDEA==" alt="mermaid diagram"/></div>`)
+	test("```dot\ndigraph { a -> b }\n```",
+		`<div class="diagram"><img src="https://kroki.example.com/graphviz/svg/eNpKyUwvSizIUKhWSFTQtVNIUqjlAgwAQJwFsA==" alt="graphviz diagram"/></div>`)
+
+	setting.Markdown.PlantUMLServerURL = "https://plantuml.example.com"
+	test("```plantuml\nBob -> Alice\n```",
+		`<div class="diagram"><img src="https://plantuml.example.com/svg/SifFKj2rKt3CoKnEvG8C00" alt="plantuml diagram"/></div>`)
+}
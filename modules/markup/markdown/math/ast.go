@@ -0,0 +1,77 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package math
+
+import (
+	"github.com/yuin/goldmark/ast"
+)
+
+// Inline represents inline math e.g. $x^2$
+type Inline struct {
+	ast.BaseInline
+	Value   []byte
+	Display bool
+}
+
+// Dump implements Node.Dump
+func (n *Inline) Dump(source []byte, level int) {
+	m := map[string]string{
+		"Value": string(n.Value),
+	}
+	ast.DumpHelper(n, source, level, m, nil)
+}
+
+// KindInline is the NodeKind for Inline
+var KindInline = ast.NewNodeKind("MathInline")
+
+// Kind implements Node.Kind.
+func (n *Inline) Kind() ast.NodeKind {
+	return KindInline
+}
+
+// NewInline creates a new Inline math node
+func NewInline(value []byte, display bool) *Inline {
+	return &Inline{
+		Value:   value,
+		Display: display,
+	}
+}
+
+// Block represents display math e.g. a $$ delimited block
+type Block struct {
+	ast.BaseBlock
+	// Environment is the name of the \begin{} environment or empty for $$ blocks
+	Environment string
+	closed      bool
+	depth       int
+}
+
+// Dump implements Node.Dump
+func (n *Block) Dump(source []byte, level int) {
+	m := map[string]string{
+		"Environment": n.Environment,
+	}
+	ast.DumpHelper(n, source, level, m, nil)
+}
+
+// KindBlock is the NodeKind for Block
+var KindBlock = ast.NewNodeKind("MathBlock")
+
+// Kind implements Node.Kind.
+func (n *Block) Kind() ast.NodeKind {
+	return KindBlock
+}
+
+// IsRaw implements Node.IsRaw.
+func (n *Block) IsRaw() bool {
+	return true
+}
+
+// NewBlock creates a new Block math node
+func NewBlock(environment string) *Block {
+	return &Block{
+		Environment: environment,
+	}
+}
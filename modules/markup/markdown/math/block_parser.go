@@ -0,0 +1,151 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package math
+
+import (
+	"bytes"
+	"regexp"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var beginEnvironmentPattern = regexp.MustCompile(`^\\begin\{([a-zA-Z]+\*?)\}`)
+
+// environments are the math environments rendered by KaTeX which may start a block
+var environments = map[string]bool{
+	"matrix": true, "smallmatrix": true, "pmatrix": true, "bmatrix": true, "Bmatrix": true,
+	"vmatrix": true, "Vmatrix": true, "cases": true, "dcases": true, "rcases": true,
+	"array": true, "darray": true, "aligned": true, "align": true, "align*": true,
+	"alignat": true, "alignat*": true, "alignedat": true, "split": true, "gather": true,
+	"gather*": true, "gathered": true, "equation": true, "equation*": true,
+}
+
+// IsMathEnvironment reports whether name is a supported environment
+func IsMathEnvironment(name string) bool {
+	return environments[name]
+}
+
+type blockParser struct {
+}
+
+var defaultBlockParser = &blockParser{}
+
+// NewBlockParser returns a new parser.BlockParser that parses $$ delimited
+// math blocks and math environments like \begin{align} ... \end{align}
+func NewBlockParser() parser.BlockParser {
+	return defaultBlockParser
+}
+
+func (b *blockParser) Trigger() []byte {
+	return []byte{'$', '\\'}
+}
+
+func (b *blockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	if !setting.Markdown.EnableMath {
+		return nil, parser.NoChildren
+	}
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	content := util.TrimRightSpace(line[pos:])
+
+	if bytes.HasPrefix(content, []byte("$$")) {
+		node := NewBlock("")
+		rest := content[2:]
+		if len(util.TrimLeftSpace(rest)) > 0 {
+			// a formula on the opening line, optionally closed on the same line
+			if bytes.HasSuffix(rest, []byte("$$")) && len(rest) > 2 {
+				node.closed = true
+				rest = rest[:len(rest)-2]
+			}
+			start := segment.Start + pos + 2
+			node.Lines().Append(text.NewSegment(start, start+len(rest)))
+		}
+		advanceLine(reader, line, segment)
+		return node, parser.NoChildren
+	}
+
+	match := beginEnvironmentPattern.FindSubmatch(content)
+	if match == nil || !IsMathEnvironment(string(match[1])) {
+		return nil, parser.NoChildren
+	}
+	node := NewBlock(string(match[1]))
+	node.Lines().Append(text.NewSegment(segment.Start+pos, segment.Stop))
+	node.depth = b.environmentDepth(node, content)
+	if node.depth <= 0 {
+		node.closed = true
+	}
+	advanceLine(reader, line, segment)
+	return node, parser.NoChildren
+}
+
+// environmentDepth returns the nesting level of the environment after line
+func (b *blockParser) environmentDepth(node *Block, line []byte) int {
+	depth := node.depth
+	depth += bytes.Count(line, []byte(`\begin{`+node.Environment+`}`))
+	depth -= bytes.Count(line, []byte(`\end{`+node.Environment+`}`))
+	return depth
+}
+
+func (b *blockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	block := node.(*Block)
+	if block.closed {
+		return parser.Close
+	}
+
+	line, segment := reader.PeekLine()
+	if line == nil {
+		return parser.Close
+	}
+
+	if block.Environment == "" {
+		trimmed := util.TrimRightSpace(util.TrimLeftSpace(line))
+		if bytes.HasSuffix(trimmed, []byte("$$")) {
+			if idx := bytes.LastIndex(line, []byte("$$")); idx > 0 {
+				node.Lines().Append(text.NewSegment(segment.Start, segment.Start+idx))
+			}
+			advanceLine(reader, line, segment)
+			return parser.Close
+		}
+		node.Lines().Append(segment)
+		advanceLine(reader, line, segment)
+		return parser.Continue | parser.NoChildren
+	}
+
+	node.Lines().Append(segment)
+	advanceLine(reader, line, segment)
+	block.depth = b.environmentDepth(block, line)
+	if block.depth <= 0 {
+		block.closed = true
+	}
+	return parser.Continue | parser.NoChildren
+}
+
+// advanceLine advances the reader to the end of the line but its newline
+func advanceLine(reader text.Reader, line []byte, segment text.Segment) {
+	newline := 0
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		newline = 1
+	}
+	reader.Advance(segment.Stop - segment.Start - newline - segment.Padding)
+}
+
+func (b *blockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+}
+
+func (b *blockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (b *blockParser) CanAcceptIndentedLine() bool {
+	return false
+}
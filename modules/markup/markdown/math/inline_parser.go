@@ -0,0 +1,69 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package math
+
+import (
+	"bytes"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+type inlineParser struct {
+}
+
+var defaultInlineParser = &inlineParser{}
+
+// NewInlineParser returns a new parser.InlineParser that parses $ and $$
+// delimited inline math
+func NewInlineParser() parser.InlineParser {
+	return defaultInlineParser
+}
+
+func (p *inlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Parse parses inline math, like pandoc the opening $ must not be followed by
+// a space and the closing $ must neither be preceded by a space nor followed
+// by a digit so amounts of money are not taken as math
+func (p *inlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	if !setting.Markdown.EnableMath {
+		return nil
+	}
+	line, _ := block.PeekLine()
+	delim := []byte{'$'}
+	if len(line) > 1 && line[1] == '$' {
+		delim = []byte{'$', '$'}
+	}
+	start := len(delim)
+	if start >= len(line) || util.IsSpace(line[start]) || line[start] == '$' {
+		return nil
+	}
+
+	for pos := start; pos < len(line); {
+		idx := bytes.Index(line[pos:], delim)
+		if idx < 0 {
+			return nil
+		}
+		end := pos + idx
+		pos = end + 1
+		if line[end-1] == '\\' || util.IsSpace(line[end-1]) {
+			continue
+		}
+		next := end + len(delim)
+		if len(delim) == 1 && next < len(line) && (line[next] == '$' || util.IsNumeric(line[next])) {
+			continue
+		}
+		value := util.TrimRightSpace(line[start:end])
+		block.Advance(next)
+		return NewInline(append([]byte{}, value...), len(delim) == 2)
+	}
+	return nil
+}
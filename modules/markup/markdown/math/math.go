@@ -0,0 +1,36 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package math
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// Extension is a math extension for goldmark, it renders $ delimited inline
+// math, $$ delimited blocks and math environments for KaTeX while math is enabled
+type Extension struct{}
+
+// NewExtension creates a new math extension
+func NewExtension() goldmark.Extender {
+	return &Extension{}
+}
+
+// Extend extends the markdown converter with the math parsers and renderer
+func (e *Extension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			util.Prioritized(NewBlockParser(), 701),
+		),
+		parser.WithInlineParsers(
+			util.Prioritized(NewInlineParser(), 501),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(NewRenderer(), 10),
+	))
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package math
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// Renderer is a renderer.NodeRenderer implementation that renders math as
+// code elements, which are typeset by KaTeX in the browser
+type Renderer struct{}
+
+// NewRenderer returns a new Renderer
+func NewRenderer() renderer.NodeRenderer {
+	return &Renderer{}
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.RegisterFuncs.
+func (r *Renderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindInline, r.renderInline)
+	reg.Register(KindBlock, r.renderBlock)
+}
+
+func (r *Renderer) renderInline(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*Inline)
+	if n.Display {
+		_, _ = w.WriteString(`<code class="language-math display">`)
+	} else {
+		_, _ = w.WriteString(`<code class="language-math">`)
+	}
+	_, _ = w.Write(util.EscapeHTML(n.Value))
+	_, _ = w.WriteString(`</code>`)
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *Renderer) renderBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	var value bytes.Buffer
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		value.Write(segment.Value(source))
+	}
+
+	_, _ = w.WriteString(`<pre><code class="language-math display">`)
+	_, _ = w.Write(util.EscapeHTML(bytes.TrimSpace(value.Bytes())))
+	_, _ = w.WriteString("</code></pre>\n")
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package model3d
+
+import (
+	"code.gitea.io/gitea/modules/markup"
+)
+
+func init() {
+	markup.RegisterPreviewer(Previewer{})
+}
+
+// Previewer implements markup.Previewer for STL and Wavefront OBJ 3D models
+type Previewer struct {
+}
+
+// Name implements markup.Previewer
+func (Previewer) Name() string {
+	return "model3d"
+}
+
+// Extensions implements markup.Previewer
+func (Previewer) Extensions() []string {
+	return []string{".stl", ".obj"}
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Previewer defines an interface for file formats which are not rendered on
+// the server but previewed by a viewer in the browser
+type Previewer interface {
+	Name() string // preview type, used by the frontend to pick the viewer
+	Extensions() []string
+}
+
+var extPreviewers = make(map[string]Previewer)
+
+// RegisterPreviewer registers a new previewer
+func RegisterPreviewer(previewer Previewer) {
+	for _, ext := range previewer.Extensions() {
+		extPreviewers[strings.ToLower(ext)] = previewer
+	}
+}
+
+// GetPreviewerByFileName get previewer by filename
+func GetPreviewerByFileName(filename string) Previewer {
+	extension := strings.ToLower(filepath.Ext(filename))
+	return extPreviewers[extension]
+}
+
+// PreviewType returns the preview type of the file, or an empty string if
+// the file cannot be previewed
+func PreviewType(filename string) string {
+	if previewer := GetPreviewerByFileName(filename); previewer != nil {
+		return previewer.Name()
+	}
+	return ""
+}
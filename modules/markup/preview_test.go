@@ -0,0 +1,34 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup_test
+
+import (
+	"testing"
+
+	. "code.gitea.io/gitea/modules/markup"
+	_ "code.gitea.io/gitea/modules/markup/geojson"
+	_ "code.gitea.io/gitea/modules/markup/model3d"
+	_ "code.gitea.io/gitea/modules/markup/pdf"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewType(t *testing.T) {
+	kases := map[string]string{
+		"model.stl":         "model3d",
+		"path/to/MODEL.OBJ": "model3d",
+		"map.geojson":       "geojson",
+		"map.topojson":      "geojson",
+		"paper.pdf":         "pdf",
+		"README.md":         "",
+		"map.json":          "",
+		"stl":               "",
+	}
+
+	for filename, expected := range kases {
+		assert.EqualValues(t, expected, PreviewType(filename), filename)
+	}
+	assert.Nil(t, GetPreviewerByFileName("image.png"))
+}
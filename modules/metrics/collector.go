@@ -6,6 +6,7 @@ package metrics
 
 import (
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/queue"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -29,6 +30,9 @@ type Collector struct {
 	Oauths        *prometheus.Desc
 	Organizations *prometheus.Desc
 	PublicKeys    *prometheus.Desc
+	QueueHandling *prometheus.Desc
+	QueueLength   *prometheus.Desc
+	QueueWorkers  *prometheus.Desc
 	Releases      *prometheus.Desc
 	Repositories  *prometheus.Desc
 	Stars         *prometheus.Desc
@@ -112,6 +116,21 @@ func NewCollector() Collector {
 			"Number of PublicKeys",
 			nil, nil,
 		),
+		QueueHandling: prometheus.NewDesc(
+			namespace+"queue_handle_duration_seconds",
+			"Time spent handling batches of queued items",
+			[]string{"queue"}, nil,
+		),
+		QueueLength: prometheus.NewDesc(
+			namespace+"queue_length",
+			"Number of items waiting in a queue",
+			[]string{"queue"}, nil,
+		),
+		QueueWorkers: prometheus.NewDesc(
+			namespace+"queue_workers",
+			"Number of workers of a queue",
+			[]string{"queue"}, nil,
+		),
 		Releases: prometheus.NewDesc(
 			namespace+"releases",
 			"Number of Releases",
@@ -172,6 +191,9 @@ func (c Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Oauths
 	ch <- c.Organizations
 	ch <- c.PublicKeys
+	ch <- c.QueueHandling
+	ch <- c.QueueLength
+	ch <- c.QueueWorkers
 	ch <- c.Releases
 	ch <- c.Repositories
 	ch <- c.Stars
@@ -296,4 +318,29 @@ func (c Collector) Collect(ch chan<- prometheus.Metric) {
 		prometheus.GaugeValue,
 		float64(stats.Counter.Webhook),
 	)
+
+	for _, mq := range queue.GetManager().ManagedQueues() {
+		batches, duration := mq.HandleStats()
+		ch <- prometheus.MustNewConstSummary(
+			c.QueueHandling,
+			uint64(batches),
+			duration.Seconds(),
+			nil,
+			mq.Name,
+		)
+		if length := mq.NumberInQueue(); length >= 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.QueueLength,
+				prometheus.GaugeValue,
+				float64(length),
+				mq.Name,
+			)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.QueueWorkers,
+			prometheus.GaugeValue,
+			float64(mq.NumberOfWorkers()),
+			mq.Name,
+		)
+	}
 }
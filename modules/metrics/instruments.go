@@ -0,0 +1,168 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+
+	"gitea.com/macaron/macaron"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// otherLabelValue replaces the values of a label once it has reached
+// the limit of distinct values
+const otherLabelValue = "other"
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    namespace + "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests by route group",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"group", "method", "code"},
+	)
+	gitCommandDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    namespace + "git_command_duration_seconds",
+			Help:    "Duration of git commands by subcommand",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"command"},
+	)
+	gitCommandErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: namespace + "git_command_errors_total",
+			Help: "Number of failed git commands by subcommand",
+		},
+		[]string{"command"},
+	)
+	webhookDeliveries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: namespace + "webhook_deliveries_total",
+			Help: "Number of webhook deliveries by webhook type and outcome",
+		},
+		[]string{"type", "outcome"},
+	)
+
+	routeGroups = newLabelLimiter()
+	methods     = newLabelLimiter()
+	subcommands = newLabelLimiter()
+)
+
+// Init registers the metrics recorded while gitea is running and starts
+// recording the duration of git commands
+func Init() {
+	prometheus.MustRegister(httpRequestDuration, gitCommandDuration, gitCommandErrors, webhookDeliveries)
+	git.CommandObserver = ObserveGitCommand
+}
+
+// HTTPMiddleware records the duration of every request by route group
+func HTTPMiddleware() macaron.Handler {
+	return func(ctx *macaron.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Resp.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		httpRequestDuration.WithLabelValues(
+			routeGroups.value(RouteGroup(ctx.Req.URL.Path)),
+			methods.value(ctx.Req.Method),
+			fmt.Sprintf("%dxx", status/100),
+		).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RouteGroup returns the group of routes a request path belongs to. Paths of
+// owners and repositories are grouped without their names, e.g. the issues of
+// all repositories belong to the group "repo/issues".
+func RouteGroup(path string) string {
+	path = strings.Trim(strings.TrimPrefix(path, setting.AppSubURL), "/")
+	if path == "" {
+		return "home"
+	}
+
+	parts := strings.SplitN(path, "/", 4)
+	if parts[0] == "api" {
+		switch {
+		case len(parts) > 2 && parts[1] == "v1":
+			return "api/" + parts[2]
+		case len(parts) > 1 && parts[1] == "internal":
+			return "api/internal"
+		}
+		return "api"
+	}
+	if models.IsErrNameReserved(models.IsUsableUsername(parts[0])) {
+		return parts[0]
+	}
+
+	switch len(parts) {
+	case 1:
+		return "owner"
+	case 2:
+		return "repo"
+	}
+	return "repo/" + parts[2]
+}
+
+// ObserveGitCommand records the duration and the outcome of a git command
+func ObserveGitCommand(subcommand string, duration time.Duration, err error) {
+	subcommand = subcommands.value(subcommand)
+	gitCommandDuration.WithLabelValues(subcommand).Observe(duration.Seconds())
+	if err != nil {
+		gitCommandErrors.WithLabelValues(subcommand).Inc()
+	}
+}
+
+// ObserveWebhookDelivery records the outcome of a webhook delivery
+func ObserveWebhookDelivery(hookType models.HookTaskType, succeeded bool) {
+	outcome := "failure"
+	if succeeded {
+		outcome = "success"
+	}
+	webhookDeliveries.WithLabelValues(hookType.Name(), outcome).Inc()
+}
+
+// labelLimiter limits the number of distinct values of a label, so that
+// values taken from requests cannot create an unbounded number of series.
+type labelLimiter struct {
+	lock   sync.RWMutex
+	values map[string]struct{}
+}
+
+func newLabelLimiter() *labelLimiter {
+	return &labelLimiter{values: make(map[string]struct{})}
+}
+
+// value returns v if it is known or the label has room for another value,
+// and otherLabelValue otherwise
+func (l *labelLimiter) value(v string) string {
+	l.lock.RLock()
+	_, known := l.values[v]
+	l.lock.RUnlock()
+	if known {
+		return v
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if _, known = l.values[v]; !known {
+		if setting.Metrics.MaxLabelValues > 0 && len(l.values) >= setting.Metrics.MaxLabelValues {
+			return otherLabelValue
+		}
+		l.values[v] = struct{}{}
+	}
+	return v
+}
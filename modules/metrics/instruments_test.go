@@ -0,0 +1,48 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteGroup(t *testing.T) {
+	for path, group := range map[string]string{
+		"/":                                   "home",
+		"/api/v1/repos/user2/repo1/issues":    "api/repos",
+		"/api/v1/version":                     "api/version",
+		"/api/internal/hook/post-receive/a/b": "api/internal",
+		"/api/swagger":                        "api",
+		"/explore/repos":                      "explore",
+		"/user/login":                         "user",
+		"/assets/js/index.js":                 "assets",
+		"/user2":                              "owner",
+		"/user2/repo1":                        "repo",
+		"/user2/repo1/issues/1":               "repo/issues",
+		"/user2/repo1.git/info/refs":          "repo/info",
+	} {
+		assert.Equal(t, group, RouteGroup(path), path)
+	}
+}
+
+func TestLabelLimiter(t *testing.T) {
+	defer func(max int) {
+		setting.Metrics.MaxLabelValues = max
+	}(setting.Metrics.MaxLabelValues)
+	setting.Metrics.MaxLabelValues = 2
+
+	l := newLabelLimiter()
+	assert.Equal(t, "a", l.value("a"))
+	assert.Equal(t, "b", l.value("b"))
+	assert.Equal(t, otherLabelValue, l.value("c"))
+	assert.Equal(t, "a", l.value("a"))
+
+	setting.Metrics.MaxLabelValues = 0
+	assert.Equal(t, "c", l.value("c"))
+}
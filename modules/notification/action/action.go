@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification/base"
 	"code.gitea.io/gitea/modules/repository"
@@ -29,6 +30,12 @@ func NewNotifier() base.Notifier {
 	return &actionNotifier{}
 }
 
+func (a *actionNotifier) Run() {
+	if fanOutQueue != nil {
+		graceful.GetManager().RunWithShutdownFns(fanOutQueue.Run)
+	}
+}
+
 func (a *actionNotifier) NotifyNewIssue(issue *models.Issue) {
 	if err := issue.LoadPoster(); err != nil {
 		log.Error("issue.LoadPoster: %v", err)
@@ -40,7 +47,7 @@ func (a *actionNotifier) NotifyNewIssue(issue *models.Issue) {
 	}
 	repo := issue.Repo
 
-	if err := models.NotifyWatchers(&models.Action{
+	if err := NotifyWatchers(&models.Action{
 		ActUserID: issue.Poster.ID,
 		ActUser:   issue.Poster,
 		OpType:    models.ActionCreateIssue,
@@ -81,7 +88,7 @@ func (a *actionNotifier) NotifyIssueChangeStatus(doer *models.User, issue *model
 	}
 
 	// Notify watchers for whatever action comes in, ignore if no action type.
-	if err := models.NotifyWatchers(act); err != nil {
+	if err := NotifyWatchers(act); err != nil {
 		log.Error("NotifyWatchers: %v", err)
 	}
 }
@@ -106,7 +113,7 @@ func (a *actionNotifier) NotifyCreateIssueComment(doer *models.User, repo *model
 	}
 
 	// Notify watchers for whatever action comes in, ignore if no action type.
-	if err := models.NotifyWatchers(act); err != nil {
+	if err := NotifyWatchers(act); err != nil {
 		log.Error("NotifyWatchers: %v", err)
 	}
 }
@@ -125,7 +132,7 @@ func (a *actionNotifier) NotifyNewPullRequest(pull *models.PullRequest) {
 		return
 	}
 
-	if err := models.NotifyWatchers(&models.Action{
+	if err := NotifyWatchers(&models.Action{
 		ActUserID: pull.Issue.Poster.ID,
 		ActUser:   pull.Issue.Poster,
 		OpType:    models.ActionCreatePullRequest,
@@ -141,7 +148,7 @@ func (a *actionNotifier) NotifyNewPullRequest(pull *models.PullRequest) {
 func (a *actionNotifier) NotifyRenameRepository(doer *models.User, repo *models.Repository, oldRepoName string) {
 	log.Trace("action.ChangeRepositoryName: %s/%s", doer.Name, repo.Name)
 
-	if err := models.NotifyWatchers(&models.Action{
+	if err := NotifyWatchers(&models.Action{
 		ActUserID: doer.ID,
 		ActUser:   doer,
 		OpType:    models.ActionRenameRepo,
@@ -155,7 +162,7 @@ func (a *actionNotifier) NotifyRenameRepository(doer *models.User, repo *models.
 }
 
 func (a *actionNotifier) NotifyTransferRepository(doer *models.User, repo *models.Repository, oldOwnerName string) {
-	if err := models.NotifyWatchers(&models.Action{
+	if err := NotifyWatchers(&models.Action{
 		ActUserID: doer.ID,
 		ActUser:   doer,
 		OpType:    models.ActionTransferRepo,
@@ -169,7 +176,7 @@ func (a *actionNotifier) NotifyTransferRepository(doer *models.User, repo *model
 }
 
 func (a *actionNotifier) NotifyCreateRepository(doer *models.User, u *models.User, repo *models.Repository) {
-	if err := models.NotifyWatchers(&models.Action{
+	if err := NotifyWatchers(&models.Action{
 		ActUserID: doer.ID,
 		ActUser:   doer,
 		OpType:    models.ActionCreateRepo,
@@ -182,7 +189,7 @@ func (a *actionNotifier) NotifyCreateRepository(doer *models.User, u *models.Use
 }
 
 func (a *actionNotifier) NotifyForkRepository(doer *models.User, oldRepo, repo *models.Repository) {
-	if err := models.NotifyWatchers(&models.Action{
+	if err := NotifyWatchers(&models.Action{
 		ActUserID: doer.ID,
 		ActUser:   doer,
 		OpType:    models.ActionCreateRepo,
@@ -247,13 +254,13 @@ func (a *actionNotifier) NotifyPullRequestReview(pr *models.PullRequest, review
 		actions = append(actions, action)
 	}
 
-	if err := models.NotifyWatchersActions(actions); err != nil {
+	if err := NotifyWatchersActions(actions); err != nil {
 		log.Error("notify watchers '%d/%d': %v", review.Reviewer.ID, review.Issue.RepoID, err)
 	}
 }
 
 func (*actionNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User) {
-	if err := models.NotifyWatchers(&models.Action{
+	if err := NotifyWatchers(&models.Action{
 		ActUserID: doer.ID,
 		ActUser:   doer,
 		OpType:    models.ActionMergePullRequest,
@@ -273,7 +280,7 @@ func (a *actionNotifier) NotifySyncPushCommits(pusher *models.User, repo *models
 		return
 	}
 
-	if err := models.NotifyWatchers(&models.Action{
+	if err := NotifyWatchers(&models.Action{
 		ActUserID: repo.OwnerID,
 		ActUser:   repo.MustOwner(),
 		OpType:    models.ActionMirrorSyncPush,
@@ -288,7 +295,7 @@ func (a *actionNotifier) NotifySyncPushCommits(pusher *models.User, repo *models
 }
 
 func (a *actionNotifier) NotifySyncCreateRef(doer *models.User, repo *models.Repository, refType, refFullName string) {
-	if err := models.NotifyWatchers(&models.Action{
+	if err := NotifyWatchers(&models.Action{
 		ActUserID: repo.OwnerID,
 		ActUser:   repo.MustOwner(),
 		OpType:    models.ActionMirrorSyncCreate,
@@ -302,7 +309,7 @@ func (a *actionNotifier) NotifySyncCreateRef(doer *models.User, repo *models.Rep
 }
 
 func (a *actionNotifier) NotifySyncDeleteRef(doer *models.User, repo *models.Repository, refType, refFullName string) {
-	if err := models.NotifyWatchers(&models.Action{
+	if err := NotifyWatchers(&models.Action{
 		ActUserID: repo.OwnerID,
 		ActUser:   repo.MustOwner(),
 		OpType:    models.ActionMirrorSyncCreate,
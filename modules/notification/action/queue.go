@@ -0,0 +1,66 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package action
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+)
+
+// fanOutQueue represents a queue of the IDs of actions, which still need to be
+// added to the feeds of the watchers of their repositories
+var fanOutQueue queue.UniqueQueue
+
+// InitFanOutQueue creates the queue adding actions to the feeds of the
+// watchers. Until it is created, NotifyWatchers adds them right away.
+func InitFanOutQueue() {
+	fanOutQueue = queue.CreateUniqueQueue("action_fan_out", handleFanOut, int64(0))
+}
+
+// handleFanOut adds a batch of actions to the feeds of the watchers at once
+func handleFanOut(data ...queue.Data) {
+	actionIDs := make([]int64, 0, len(data))
+	for _, datum := range data {
+		actionIDs = append(actionIDs, datum.(int64))
+	}
+	if err := models.FanOutActions(actionIDs...); err != nil {
+		log.Error("FanOutActions: %v", err)
+	}
+}
+
+// NotifyWatchers adds the actions to the feeds of the actioners and queues
+// adding them to the feeds of the watchers, so that actions on repositories
+// with many watchers do not hold up the request.
+func NotifyWatchers(actions ...*models.Action) error {
+	if fanOutQueue == nil {
+		return models.NotifyWatchers(actions...)
+	}
+	if err := models.InsertActions(actions...); err != nil {
+		return err
+	}
+	return queueFanOut(actions)
+}
+
+// NotifyWatchersActions is NotifyWatchers for a batch of actions, which are
+// added to the feeds of the actioners in one transaction.
+func NotifyWatchersActions(actions []*models.Action) error {
+	if fanOutQueue == nil {
+		return models.NotifyWatchersActions(actions)
+	}
+	if err := models.InsertActions(actions...); err != nil {
+		return err
+	}
+	return queueFanOut(actions)
+}
+
+func queueFanOut(actions []*models.Action) error {
+	for _, act := range actions {
+		if err := fanOutQueue.Push(act.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package away
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/references"
+)
+
+type awayNotifier struct {
+	base.NullNotifier
+}
+
+var (
+	_ base.Notifier = &awayNotifier{}
+)
+
+// NewNotifier create a new awayNotifier notifier
+func NewNotifier() base.Notifier {
+	return &awayNotifier{}
+}
+
+// noteMentionedAwayUsers adds an automatic note to the issue for each user
+// mentioned in the content who is out of office.
+func noteMentionedAwayUsers(doer *models.User, issue *models.Issue, content string) {
+	rawMentions := references.FindAllMentionsMarkdown(content)
+	if len(rawMentions) == 0 {
+		return
+	}
+	mentions, err := issue.ResolveMentionsByVisibility(models.DefaultDBContext(), doer, rawMentions)
+	if err != nil {
+		log.Error("ResolveMentionsByVisibility [%d]: %v", issue.ID, err)
+		return
+	}
+	for _, u := range mentions {
+		if u.ID == doer.ID {
+			continue
+		}
+		if _, err := models.CreateAwayNoteComment(issue, u); err != nil {
+			log.Error("CreateAwayNoteComment [issue: %d, user: %d]: %v", issue.ID, u.ID, err)
+		}
+	}
+}
+
+func (*awayNotifier) NotifyNewIssue(issue *models.Issue) {
+	if err := issue.LoadPoster(); err != nil {
+		log.Error("LoadPoster: %v", err)
+		return
+	}
+	noteMentionedAwayUsers(issue.Poster, issue, issue.Content)
+}
+
+func (*awayNotifier) NotifyNewPullRequest(pr *models.PullRequest) {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+	if err := pr.Issue.LoadPoster(); err != nil {
+		log.Error("LoadPoster: %v", err)
+		return
+	}
+	noteMentionedAwayUsers(pr.Issue.Poster, pr.Issue, pr.Issue.Content)
+}
+
+func (*awayNotifier) NotifyCreateIssueComment(doer *models.User, repo *models.Repository,
+	issue *models.Issue, comment *models.Comment) {
+	noteMentionedAwayUsers(doer, issue, comment.Content)
+}
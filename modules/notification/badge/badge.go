@@ -0,0 +1,34 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package badge
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+)
+
+type badgeNotifier struct {
+	base.NullNotifier
+}
+
+var (
+	_ base.Notifier = &badgeNotifier{}
+)
+
+// NewNotifier create a new badgeNotifier notifier
+func NewNotifier() base.Notifier {
+	return &badgeNotifier{}
+}
+
+func (*badgeNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User) {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+	if err := models.AwardUserAutomaticBadges(pr.Issue.PosterID); err != nil {
+		log.Error("AwardUserAutomaticBadges: %v", err)
+	}
+}
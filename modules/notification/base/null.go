@@ -78,10 +78,22 @@ func (*NullNotifier) NotifyUpdateRelease(doer *models.User, rel *models.Release)
 func (*NullNotifier) NotifyDeleteRelease(doer *models.User, rel *models.Release) {
 }
 
+// NotifyNewDiscussion places a place holder function
+func (*NullNotifier) NotifyNewDiscussion(discussion *models.Discussion) {
+}
+
+// NotifyDiscussionReply places a place holder function
+func (*NullNotifier) NotifyDiscussionReply(doer *models.User, discussion *models.Discussion, reply *models.DiscussionReply) {
+}
+
 // NotifyIssueChangeMilestone places a place holder function
 func (*NullNotifier) NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMilestoneID int64) {
 }
 
+// NotifyIssueChangeWorkflowState places a place holder function
+func (*NullNotifier) NotifyIssueChangeWorkflowState(doer *models.User, issue *models.Issue, oldStateID int64) {
+}
+
 // NotifyIssueChangeContent places a place holder function
 func (*NullNotifier) NotifyIssueChangeContent(doer *models.User, issue *models.Issue, oldContent string) {
 }
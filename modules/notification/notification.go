@@ -7,6 +7,8 @@ package notification
 import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/notification/action"
+	"code.gitea.io/gitea/modules/notification/away"
+	"code.gitea.io/gitea/modules/notification/badge"
 	"code.gitea.io/gitea/modules/notification/base"
 	"code.gitea.io/gitea/modules/notification/indexer"
 	"code.gitea.io/gitea/modules/notification/mail"
@@ -34,6 +36,9 @@ func NewContext() {
 	}
 	RegisterNotifier(indexer.NewNotifier())
 	RegisterNotifier(webhook.NewNotifier())
+	RegisterNotifier(badge.NewNotifier())
+	RegisterNotifier(away.NewNotifier())
+	action.InitFanOutQueue()
 	RegisterNotifier(action.NewNotifier())
 }
 
@@ -136,6 +141,20 @@ func NotifyDeleteRelease(doer *models.User, rel *models.Release) {
 	}
 }
 
+// NotifyNewDiscussion notifies new discussion to notifiers
+func NotifyNewDiscussion(discussion *models.Discussion) {
+	for _, notifier := range notifiers {
+		notifier.NotifyNewDiscussion(discussion)
+	}
+}
+
+// NotifyDiscussionReply notifies a reply to a discussion to notifiers
+func NotifyDiscussionReply(doer *models.User, discussion *models.Discussion, reply *models.DiscussionReply) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDiscussionReply(doer, discussion, reply)
+	}
+}
+
 // NotifyIssueChangeMilestone notifies change milestone to notifiers
 func NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMilestoneID int64) {
 	for _, notifier := range notifiers {
@@ -143,6 +162,13 @@ func NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMiles
 	}
 }
 
+// NotifyIssueChangeWorkflowState notifies change workflow state to notifiers
+func NotifyIssueChangeWorkflowState(doer *models.User, issue *models.Issue, oldStateID int64) {
+	for _, notifier := range notifiers {
+		notifier.NotifyIssueChangeWorkflowState(doer, issue, oldStateID)
+	}
+}
+
 // NotifyIssueChangeContent notifies change content to notifiers
 func NotifyIssueChangeContent(doer *models.User, issue *models.Issue, oldContent string) {
 	for _, notifier := range notifiers {
@@ -10,12 +10,13 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification/base"
 	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/references"
 )
 
 type (
 	notificationService struct {
 		base.NullNotifier
-		issueQueue queue.Queue
+		issueQueue queue.UniqueQueue
 	}
 
 	issueNotificationOpts struct {
@@ -23,6 +24,8 @@ type (
 		CommentID            int64
 		NotificationAuthorID int64
 		ReceiverID           int64 // 0 -- ALL Watcher
+		Reason               models.NotificationReason
+		DiscussionID         int64
 	}
 )
 
@@ -33,19 +36,51 @@ var (
 // NewNotifier create a new notificationService notifier
 func NewNotifier() base.Notifier {
 	ns := &notificationService{}
-	ns.issueQueue = queue.CreateQueue("notification-service", ns.handle, issueNotificationOpts{})
+	// Identical notifications waiting in the queue are only handled once
+	ns.issueQueue = queue.CreateUniqueQueue("notification-service", ns.handle, issueNotificationOpts{})
 	return ns
 }
 
 func (ns *notificationService) handle(data ...queue.Data) {
 	for _, datum := range data {
 		opts := datum.(issueNotificationOpts)
-		if err := models.CreateOrUpdateIssueNotifications(opts.IssueID, opts.CommentID, opts.NotificationAuthorID, opts.ReceiverID); err != nil {
+		if opts.DiscussionID > 0 {
+			if err := models.CreateOrUpdateDiscussionNotifications(opts.DiscussionID, opts.NotificationAuthorID); err != nil {
+				log.Error("Was unable to create discussion notification: %v", err)
+			}
+			continue
+		}
+		if err := models.CreateOrUpdateIssueNotifications(opts.IssueID, opts.CommentID, opts.NotificationAuthorID, opts.ReceiverID, opts.Reason); err != nil {
 			log.Error("Was unable to create issue notification: %v", err)
 		}
 	}
 }
 
+// pushMentions notifies the users mentioned in the content who can see them
+func (ns *notificationService) pushMentions(doer *models.User, issue *models.Issue, commentID int64, content string) {
+	rawMentions := references.FindAllMentionsMarkdown(content)
+	if len(rawMentions) == 0 {
+		return
+	}
+	mentions, err := issue.ResolveMentionsByVisibility(models.DefaultDBContext(), doer, rawMentions)
+	if err != nil {
+		log.Error("ResolveMentionsByVisibility [%d]: %v", issue.ID, err)
+		return
+	}
+	for _, u := range mentions {
+		if u.ID == doer.ID {
+			continue
+		}
+		_ = ns.issueQueue.Push(issueNotificationOpts{
+			IssueID:              issue.ID,
+			CommentID:            commentID,
+			NotificationAuthorID: doer.ID,
+			ReceiverID:           u.ID,
+			Reason:               models.NotificationReasonMention,
+		})
+	}
+}
+
 func (ns *notificationService) Run() {
 	graceful.GetManager().RunWithShutdownFns(ns.issueQueue.Run)
 }
@@ -60,6 +95,9 @@ func (ns *notificationService) NotifyCreateIssueComment(doer *models.User, repo
 		opts.CommentID = comment.ID
 	}
 	_ = ns.issueQueue.Push(opts)
+	if comment != nil {
+		ns.pushMentions(doer, issue, comment.ID, comment.Content)
+	}
 }
 
 func (ns *notificationService) NotifyNewIssue(issue *models.Issue) {
@@ -67,6 +105,7 @@ func (ns *notificationService) NotifyNewIssue(issue *models.Issue) {
 		IssueID:              issue.ID,
 		NotificationAuthorID: issue.Poster.ID,
 	})
+	ns.pushMentions(issue.Poster, issue, 0, issue.Content)
 }
 
 func (ns *notificationService) NotifyIssueChangeStatus(doer *models.User, issue *models.Issue, actionComment *models.Comment, isClosed bool) {
@@ -92,6 +131,11 @@ func (ns *notificationService) NotifyNewPullRequest(pr *models.PullRequest) {
 		IssueID:              pr.Issue.ID,
 		NotificationAuthorID: pr.Issue.PosterID,
 	})
+	if err := pr.Issue.LoadPoster(); err != nil {
+		log.Error("Unable to load poster: %d for pr: %d: Error: %v", pr.Issue.PosterID, pr.ID, err)
+		return
+	}
+	ns.pushMentions(pr.Issue.Poster, pr.Issue, 0, pr.Issue.Content)
 }
 
 func (ns *notificationService) NotifyPullRequestReview(pr *models.PullRequest, r *models.Review, c *models.Comment) {
@@ -120,6 +164,7 @@ func (ns *notificationService) NotifyIssueChangeAssignee(doer *models.User, issu
 			IssueID:              issue.ID,
 			NotificationAuthorID: doer.ID,
 			ReceiverID:           assignee.ID,
+			Reason:               models.NotificationReasonAssign,
 		}
 
 		if comment != nil {
@@ -136,6 +181,7 @@ func (ns *notificationService) NotifyPullReviewRequest(doer *models.User, issue
 			IssueID:              issue.ID,
 			NotificationAuthorID: doer.ID,
 			ReceiverID:           reviewer.ID,
+			Reason:               models.NotificationReasonReviewRequest,
 		}
 
 		if comment != nil {
@@ -145,3 +191,17 @@ func (ns *notificationService) NotifyPullReviewRequest(doer *models.User, issue
 		_ = ns.issueQueue.Push(opts)
 	}
 }
+
+func (ns *notificationService) NotifyNewDiscussion(discussion *models.Discussion) {
+	_ = ns.issueQueue.Push(issueNotificationOpts{
+		DiscussionID:         discussion.ID,
+		NotificationAuthorID: discussion.PosterID,
+	})
+}
+
+func (ns *notificationService) NotifyDiscussionReply(doer *models.User, discussion *models.Discussion, reply *models.DiscussionReply) {
+	_ = ns.issueQueue.Push(issueNotificationOpts{
+		DiscussionID:         discussion.ID,
+		NotificationAuthorID: doer.ID,
+	})
+}
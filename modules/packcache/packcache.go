@@ -0,0 +1,260 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// MaxRequestSize is the maximum size in bytes of a cacheable upload-pack request. Requests of
+// clones only list the wanted commits, larger requests are negotiations which are not cached.
+const MaxRequestSize = 64 * 1024
+
+// RequestKey returns the cache key of an upload-pack request of the git protocol version to a
+// repository, or an empty string if it cannot be cached. Only the requests of full and shallow
+// clones are cached: they do not have any object in common with the repository, so the pack
+// sent only depends on the wanted commits, the depth and the capabilities.
+func RequestKey(repoID int64, protocol string, body []byte) string {
+	if len(body) > MaxRequestSize {
+		return ""
+	}
+	lines, err := git.ParsePktLines(body)
+	if err != nil {
+		return ""
+	}
+
+	var hasWant, hasDone bool
+	normalized := make([]string, 0, len(lines)+2)
+	normalized = append(normalized, strconv.FormatInt(repoID, 10), protocol)
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "have "):
+			return ""
+		case strings.HasPrefix(line, "want "):
+			hasWant = true
+		case line == "done":
+			hasDone = true
+		case line == "command=ls-refs":
+			// the refs are not cached, they change with every push
+			return ""
+		case strings.HasPrefix(line, "agent=") || strings.HasPrefix(line, "session-id="):
+			// protocol v2 capabilities identifying the client
+			continue
+		}
+		// protocol v0 and v1 send the capabilities with the first wanted commit
+		fields := strings.Fields(line)
+		kept := fields[:0]
+		for _, field := range fields {
+			if !strings.HasPrefix(field, "agent=") && !strings.HasPrefix(field, "session-id=") {
+				kept = append(kept, field)
+			}
+		}
+		normalized = append(normalized, strings.Join(kept, " "))
+	}
+	if !hasWant || !hasDone {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(normalized, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func repoDir(repoID int64) string {
+	return filepath.Join(setting.Git.PackCache.Path, strconv.FormatInt(repoID, 10))
+}
+
+func entryPath(repoID int64, key string) string {
+	return filepath.Join(repoDir(repoID), key+".pack")
+}
+
+// Get returns the cached response of the request key, or nil if there is none or it expired.
+func Get(repoID int64, key string) *os.File {
+	path := entryPath(repoID, key)
+	f, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Error("Unable to open cached pack %s: %v", path, err)
+		}
+		return nil
+	}
+	fi, err := f.Stat()
+	if err != nil || time.Since(fi.ModTime()) > setting.Git.PackCache.TTL {
+		f.Close()
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Error("Unable to remove expired cached pack %s: %v", path, err)
+		}
+		return nil
+	}
+	return f
+}
+
+// Entry is a response being written to the cache, it is only stored once committed.
+type Entry struct {
+	file     *os.File
+	path     string
+	size     int64
+	tooLarge bool
+}
+
+// NewEntry returns a new entry for the response of the request key.
+func NewEntry(repoID int64, key string) (*Entry, error) {
+	dir := repoDir(repoID)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	f, err := ioutil.TempFile(dir, key+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{file: f, path: entryPath(repoID, key)}, nil
+}
+
+// Write writes to the entry, responses larger than the maximum entry size are not cached but
+// writing them does not fail so that the response to the client is not interrupted.
+func (e *Entry) Write(p []byte) (int, error) {
+	if e.tooLarge {
+		return len(p), nil
+	}
+	if e.size+int64(len(p)) > setting.Git.PackCache.MaxEntrySize {
+		e.tooLarge = true
+		return len(p), nil
+	}
+	n, err := e.file.Write(p)
+	e.size += int64(n)
+	if err != nil {
+		log.Error("Unable to write cached pack %s: %v", e.file.Name(), err)
+		e.tooLarge = true
+	}
+	return len(p), nil
+}
+
+// Commit stores the entry in the cache, unless it is too large.
+func (e *Entry) Commit() {
+	if e.tooLarge {
+		e.Abort()
+		return
+	}
+	if err := e.file.Close(); err != nil {
+		log.Error("Unable to close cached pack %s: %v", e.file.Name(), err)
+	}
+	if err := os.Rename(e.file.Name(), e.path); err != nil {
+		// the cache of the repository may have been invalidated meanwhile
+		log.Debug("Unable to store cached pack %s: %v", e.path, err)
+		_ = os.Remove(e.file.Name())
+	}
+}
+
+// Abort discards the entry.
+func (e *Entry) Abort() {
+	_ = e.file.Close()
+	if err := os.Remove(e.file.Name()); err != nil && !os.IsNotExist(err) {
+		log.Error("Unable to remove cached pack %s: %v", e.file.Name(), err)
+	}
+}
+
+// Invalidate removes the cached responses of a repository, e.g. after a push.
+func Invalidate(repoID int64) {
+	if !setting.Git.PackCache.Enabled {
+		return
+	}
+	if err := os.RemoveAll(repoDir(repoID)); err != nil {
+		log.Error("Unable to invalidate the pack cache of repository %d: %v", repoID, err)
+	}
+}
+
+type requestCount struct {
+	count int
+	since time.Time
+}
+
+var (
+	countsLock sync.Mutex
+	counts     = make(map[string]*requestCount)
+)
+
+// maxCounts is the number of counted request keys from which the expired ones are pruned
+const maxCounts = 10000
+
+// Observe counts a request key and returns true if it has been requested often enough within
+// the cache TTL for its response to be cached.
+func Observe(key string) bool {
+	countsLock.Lock()
+	defer countsLock.Unlock()
+
+	now := time.Now()
+	if len(counts) >= maxCounts {
+		for k, c := range counts {
+			if now.Sub(c.since) > setting.Git.PackCache.TTL {
+				delete(counts, k)
+			}
+		}
+	}
+	c, ok := counts[key]
+	if !ok || now.Sub(c.since) > setting.Git.PackCache.TTL {
+		c = &requestCount{since: now}
+		counts[key] = c
+	}
+	c.count++
+	return c.count >= setting.Git.PackCache.MinRequests
+}
+
+// DeleteExpired removes the cached responses older than the cache TTL.
+func DeleteExpired(ctx context.Context) error {
+	if !setting.Git.PackCache.Enabled {
+		return nil
+	}
+	dirs, err := ioutil.ReadDir(setting.Git.PackCache.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, dir := range dirs {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Aborted due to shutdown")
+		default:
+		}
+		if !dir.IsDir() {
+			continue
+		}
+		dirPath := filepath.Join(setting.Git.PackCache.Path, dir.Name())
+		entries, err := ioutil.ReadDir(dirPath)
+		if err != nil {
+			return err
+		}
+		remaining := len(entries)
+		for _, entry := range entries {
+			// temporary files are written for at most the time of a clone
+			if time.Since(entry.ModTime()) <= setting.Git.PackCache.TTL {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dirPath, entry.Name())); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			remaining--
+		}
+		if remaining == 0 {
+			// fails if an entry has been written meanwhile
+			_ = os.Remove(dirPath)
+		}
+	}
+	return nil
+}
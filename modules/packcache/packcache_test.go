@@ -0,0 +1,117 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packcache
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pktLines(lines ...string) []byte {
+	var sb strings.Builder
+	for _, line := range lines {
+		if line == "0000" || line == "0001" {
+			sb.WriteString(line)
+			continue
+		}
+		fmt.Fprintf(&sb, "%04x%s\n", len(line)+5, line)
+	}
+	return []byte(sb.String())
+}
+
+const commitID = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+
+func TestRequestKey(t *testing.T) {
+	clone := pktLines("want "+commitID+" multi_ack_detailed side-band-64k ofs-delta agent=git/2.30.0", "0000", "done")
+	key := RequestKey(1, "", clone)
+	assert.Len(t, key, 64)
+
+	// the agent of the client does not matter
+	assert.Equal(t, key, RequestKey(1, "", pktLines("want "+commitID+" multi_ack_detailed side-band-64k ofs-delta agent=git/2.31.1", "0000", "done")))
+	// the repository, the protocol and the capabilities do
+	assert.NotEqual(t, key, RequestKey(2, "", clone))
+	assert.NotEqual(t, key, RequestKey(1, "version=1", clone))
+	assert.NotEqual(t, key, RequestKey(1, "", pktLines("want "+commitID+" side-band-64k", "0000", "done")))
+	// shallow clones are cached separately
+	shallow := RequestKey(1, "", pktLines("want "+commitID+" side-band-64k", "deepen 1", "0000", "done"))
+	assert.NotEmpty(t, shallow)
+	assert.NotEqual(t, key, shallow)
+
+	// fetches and negotiations are not cached
+	assert.Empty(t, RequestKey(1, "", pktLines("want "+commitID+" side-band-64k", "0000", "have "+commitID, "done")))
+	assert.Empty(t, RequestKey(1, "", pktLines("want "+commitID+" side-band-64k", "0000")))
+	assert.Empty(t, RequestKey(1, "", []byte("0032want")))
+	assert.Empty(t, RequestKey(1, "", []byte("zzzz")))
+
+	v2 := RequestKey(1, "version=2", pktLines("command=fetch", "agent=git/2.30.0", "0001", "thin-pack", "ofs-delta", "want "+commitID, "done", "0000"))
+	assert.NotEmpty(t, v2)
+	assert.Equal(t, v2, RequestKey(1, "version=2", pktLines("command=fetch", "agent=git/2.31.1", "0001", "thin-pack", "ofs-delta", "want "+commitID, "done", "0000")))
+	assert.Empty(t, RequestKey(1, "version=2", pktLines("command=ls-refs", "0001", "peel", "0000")))
+}
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packcache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	oldPackCache := setting.Git.PackCache
+	defer func() {
+		setting.Git.PackCache = oldPackCache
+	}()
+	setting.Git.PackCache.Enabled = true
+	setting.Git.PackCache.Path = dir
+	setting.Git.PackCache.TTL = time.Hour
+	setting.Git.PackCache.MinRequests = 2
+	setting.Git.PackCache.MaxEntrySize = 16
+
+	assert.False(t, Observe("key"))
+	assert.True(t, Observe("key"))
+
+	assert.Nil(t, Get(1, "key"))
+	entry, err := NewEntry(1, "key")
+	assert.NoError(t, err)
+	_, _ = entry.Write([]byte("0008NAK\n"))
+	entry.Commit()
+	f := Get(1, "key")
+	if assert.NotNil(t, f) {
+		data, err := ioutil.ReadAll(f)
+		f.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, "0008NAK\n", string(data))
+	}
+
+	// responses larger than the maximum are not cached
+	entry, err = NewEntry(1, "large")
+	assert.NoError(t, err)
+	n, err := entry.Write([]byte("0123456789abcdef0"))
+	assert.NoError(t, err)
+	assert.Equal(t, 17, n)
+	entry.Commit()
+	assert.Nil(t, Get(1, "large"))
+
+	assert.NoError(t, DeleteExpired(context.Background()))
+	assert.NotNil(t, Get(1, "key"))
+	setting.Git.PackCache.TTL = 0
+	assert.NoError(t, DeleteExpired(context.Background()))
+	setting.Git.PackCache.TTL = time.Hour
+	assert.Nil(t, Get(1, "key"))
+
+	entry, err = NewEntry(2, "key")
+	assert.NoError(t, err)
+	entry.Commit()
+	Invalidate(2)
+	assert.Nil(t, Get(2, "key"))
+	_, err = os.Stat(repoDir(2))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -10,14 +10,21 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"os"
 
 	"code.gitea.io/gitea/modules/httplib"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 )
 
 func newRequest(url, method string) *httplib.Request {
-	return httplib.NewRequest(url, method).Header("Authorization",
+	req := httplib.NewRequest(url, method).Header("Authorization",
 		fmt.Sprintf("Bearer %s", setting.InternalToken))
+	// Hooks and serv pass on the ID of the request they are run for
+	if requestID := os.Getenv(log.EnvRequestID); log.IsValidRequestID(requestID) {
+		req.Header("X-Request-ID", requestID)
+	}
+	return req
 }
 
 // Response internal request response
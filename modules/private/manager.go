@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"time"
 
+	"code.gitea.io/gitea/modules/maintenance"
 	"code.gitea.io/gitea/modules/setting"
 )
 
@@ -189,3 +190,58 @@ func RemoveLogger(group, name string) (int, string) {
 
 	return http.StatusOK, "Removed"
 }
+
+// MaintenanceOptions represents the options for the maintenance call
+type MaintenanceOptions struct {
+	Enabled bool
+	Message string
+}
+
+// SetMaintenance enables or disables the maintenance mode
+func SetMaintenance(enabled bool, message string) (int, string) {
+	reqURL := setting.LocalURL + "api/internal/manager/maintenance"
+
+	req := newInternalRequest(reqURL, "POST")
+	req = req.Header("Content-Type", "application/json")
+	jsonBytes, _ := json.Marshal(MaintenanceOptions{
+		Enabled: enabled,
+		Message: message,
+	})
+	req.Body(jsonBytes)
+	resp, err := req.Response()
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Sprintf("Unable to contact gitea: %v", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, decodeJSONError(resp).Err
+	}
+
+	if enabled {
+		return http.StatusOK, "Maintenance mode enabled"
+	}
+	return http.StatusOK, "Maintenance mode disabled"
+}
+
+// GetMaintenanceStatus returns the maintenance mode and the work which is still draining
+func GetMaintenanceStatus() (*maintenance.Status, int, string) {
+	reqURL := setting.LocalURL + "api/internal/manager/maintenance"
+
+	req := newInternalRequest(reqURL, "GET")
+	resp, err := req.Response()
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Sprintf("Unable to contact gitea: %v", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, decodeJSONError(resp).Err
+	}
+
+	var status maintenance.Status
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, http.StatusInternalServerError, fmt.Sprintf("Unable to decode maintenance status: %v", err)
+	}
+	return &status, http.StatusOK, ""
+}
@@ -0,0 +1,15 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package process
+
+import "time"
+
+// Limits are the resources a command may use, zero means unlimited.
+type Limits struct {
+	// MaxMemory is the maximum virtual memory of the command in bytes
+	MaxMemory int64
+	// MaxCPUTime is the maximum CPU time the command may use
+	MaxCPUTime time.Duration
+}
@@ -0,0 +1,44 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// LimitedCommand returns the command running name with the resource limits
+// applied. The limits are set by a shell which then replaces itself with the
+// command, so that the limits are inherited and a timeout kills the command
+// itself.
+func LimitedCommand(ctx context.Context, limits Limits, name string, args ...string) *exec.Cmd {
+	ulimits := limits.ShellLimits()
+	if len(ulimits) == 0 {
+		return exec.CommandContext(ctx, name, args...)
+	}
+	script := strings.Join(append(ulimits, `exec "$0" "$@"`), "; ")
+	return exec.CommandContext(ctx, "sh", append([]string{"-c", script, name}, args...)...)
+}
+
+// ShellLimits returns the ulimit commands applying the limits.
+func (l Limits) ShellLimits() []string {
+	var ulimits []string
+	if l.MaxMemory > 0 {
+		// ulimit -v takes kibibytes
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -v %d", (l.MaxMemory+1023)/1024))
+	}
+	if l.MaxCPUTime > 0 {
+		seconds := int64(l.MaxCPUTime.Seconds())
+		if seconds < 1 {
+			seconds = 1
+		}
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -t %d", seconds))
+	}
+	return ulimits
+}
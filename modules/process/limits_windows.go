@@ -0,0 +1,18 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package process
+
+import (
+	"context"
+	"os/exec"
+)
+
+// LimitedCommand returns the command running name. Memory and CPU limits are
+// not supported on Windows, only the timeout of the context applies.
+func LimitedCommand(ctx context.Context, limits Limits, name string, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, name, args...)
+}
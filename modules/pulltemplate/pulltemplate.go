@@ -0,0 +1,222 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pulltemplate
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/gobwas/glob"
+	"gopkg.in/yaml.v2"
+)
+
+// FilePaths are the paths of the single pull request template of a repository, the first
+// existing one is used.
+var FilePaths = []string{
+	"PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	".gitea/PULL_REQUEST_TEMPLATE.md",
+	".gitea/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md",
+	".github/pull_request_template.md",
+}
+
+// DirPaths are the paths of the directories of the pull request templates of a repository, the
+// markdown files of the first existing one are used.
+var DirPaths = []string{
+	".gitea/PULL_REQUEST_TEMPLATE",
+	".gitea/pull_request_template",
+	".github/PULL_REQUEST_TEMPLATE",
+	".github/pull_request_template",
+}
+
+// MaxSize is the maximum size of a pull request template in bytes, larger ones are skipped.
+const MaxSize = 64 * 1024
+
+// Template is a pull request description template. The templates of a templates directory may
+// start with a YAML front matter:
+//
+//   ---
+//   name: Release backport
+//   about: Backport a fix to a release branch
+//   branches: ["release/*"]
+//   labels: [backport]
+//   ---
+//
+// The template is selected when creating a pull request to a branch matching one of its
+// branches patterns, and its labels are preselected.
+type Template struct {
+	FileName string
+	Name     string
+	About    string
+	Branches []string
+	Labels   []string
+	Content  string
+
+	branches []glob.Glob
+}
+
+type frontMatter struct {
+	Name     string   `yaml:"name"`
+	About    string   `yaml:"about"`
+	Branches []string `yaml:"branches"`
+	Labels   []string `yaml:"labels"`
+}
+
+// Parse parses a pull request template, its name defaults to its file name without extension.
+func Parse(fileName string, data []byte) (*Template, error) {
+	tmpl := &Template{
+		FileName: fileName,
+		Name:     strings.TrimSuffix(path.Base(fileName), path.Ext(fileName)),
+	}
+
+	content := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if bytes.HasPrefix(content, []byte("---\n")) {
+		// the front matter ends with a "---" line, it may be empty
+		end := bytes.Index(content[3:], []byte("\n---"))
+		if end < 0 {
+			return nil, fmt.Errorf("front matter of %s is not terminated", fileName)
+		}
+		var meta frontMatter
+		if err := yaml.Unmarshal(content[3:3+end], &meta); err != nil {
+			return nil, fmt.Errorf("front matter of %s: %v", fileName, err)
+		}
+		if meta.Name = strings.TrimSpace(meta.Name); meta.Name != "" {
+			tmpl.Name = meta.Name
+		}
+		tmpl.About = strings.TrimSpace(meta.About)
+		tmpl.Labels = meta.Labels
+		for _, expr := range meta.Branches {
+			expr = strings.TrimSpace(expr)
+			g, err := glob.Compile(expr, '/')
+			if err != nil {
+				log.Info("Invalid branch pattern '%s' of pull request template '%s' (skipped): %v", expr, fileName, err)
+				continue
+			}
+			tmpl.Branches = append(tmpl.Branches, expr)
+			tmpl.branches = append(tmpl.branches, g)
+		}
+
+		content = bytes.TrimPrefix(content[3+end+4:], []byte("\n"))
+	}
+	tmpl.Content = string(content)
+	return tmpl, nil
+}
+
+// MatchBranch returns true if the target branch matches one of the branches patterns of the
+// template.
+func (tmpl *Template) MatchBranch(branch string) bool {
+	for _, g := range tmpl.branches {
+		if g.Match(branch) {
+			return true
+		}
+	}
+	return false
+}
+
+func readBlob(entry *git.TreeEntry) ([]byte, error) {
+	if entry.Blob().Size() > MaxSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", entry.Name(), MaxSize)
+	}
+	rc, err := entry.Blob().DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+// Load returns the pull request templates of a commit, the ones of the templates directory
+// sorted by file name followed by the single template. Invalid templates are skipped.
+func Load(commit *git.Commit) ([]*Template, error) {
+	templates := make([]*Template, 0, 5)
+	for _, dir := range DirPaths {
+		tree, err := commit.SubTree(dir)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		entries, err := tree.ListEntries()
+		if err != nil {
+			return nil, err
+		}
+		entries.Sort()
+		for _, entry := range entries {
+			if !entry.IsRegular() || !strings.EqualFold(path.Ext(entry.Name()), ".md") {
+				continue
+			}
+			fileName := path.Join(dir, entry.Name())
+			data, err := readBlob(entry)
+			if err != nil {
+				log.Info("Pull request template %s skipped: %v", fileName, err)
+				continue
+			}
+			tmpl, err := Parse(fileName, data)
+			if err != nil {
+				log.Info("Pull request template %s skipped: %v", fileName, err)
+				continue
+			}
+			templates = append(templates, tmpl)
+		}
+		break
+	}
+
+	for _, fileName := range FilePaths {
+		entry, err := commit.GetTreeEntryByPath(fileName)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		data, err := readBlob(entry)
+		if err != nil {
+			log.Info("Pull request template %s skipped: %v", fileName, err)
+			break
+		}
+		// the single template is not parsed, it has always been used as is
+		templates = append(templates, &Template{
+			FileName: fileName,
+			Name:     strings.TrimSuffix(path.Base(fileName), path.Ext(fileName)),
+			Content:  string(data),
+		})
+		break
+	}
+	return templates, nil
+}
+
+// Find returns the template with the file name, or nil if there is none.
+func Find(templates []*Template, fileName string) *Template {
+	for _, tmpl := range templates {
+		if tmpl.FileName == fileName {
+			return tmpl
+		}
+	}
+	return nil
+}
+
+// Select returns the template of a pull request to the target branch: the first one matching
+// the branch, or else the single template, or nil if there is none.
+func Select(templates []*Template, branch string) *Template {
+	for _, tmpl := range templates {
+		if tmpl.MatchBranch(branch) {
+			return tmpl
+		}
+	}
+	for _, fileName := range FilePaths {
+		if tmpl := Find(templates, fileName); tmpl != nil {
+			return tmpl
+		}
+	}
+	return nil
+}
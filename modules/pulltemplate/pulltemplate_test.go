@@ -0,0 +1,52 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pulltemplate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tmpl, err := Parse(".gitea/PULL_REQUEST_TEMPLATE/backport.md", []byte("---\r\nname: Backport\r\nabout: Backport a fix\r\nbranches: [\"release/*\", \"[\"]\r\nlabels: [backport]\r\n---\r\n## Fix\r\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Backport", tmpl.Name)
+	assert.Equal(t, "Backport a fix", tmpl.About)
+	assert.Equal(t, []string{"release/*"}, tmpl.Branches)
+	assert.Equal(t, []string{"backport"}, tmpl.Labels)
+	assert.Equal(t, "## Fix\n", tmpl.Content)
+	assert.True(t, tmpl.MatchBranch("release/1.13"))
+	assert.False(t, tmpl.MatchBranch("release/1.13/fix"))
+	assert.False(t, tmpl.MatchBranch("master"))
+
+	tmpl, err = Parse(".gitea/PULL_REQUEST_TEMPLATE/feature.md", []byte("---\n---\n## Feature"))
+	assert.NoError(t, err)
+	assert.Equal(t, "feature", tmpl.Name)
+	assert.Equal(t, "## Feature", tmpl.Content)
+
+	tmpl, err = Parse("docs.md", []byte("## Docs\n---\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "docs", tmpl.Name)
+	assert.Equal(t, "## Docs\n---\n", tmpl.Content)
+
+	_, err = Parse("broken.md", []byte("---\nname: Broken\n"))
+	assert.Error(t, err)
+	_, err = Parse("broken.md", []byte("---\nlabels: {a: b}\n---\n"))
+	assert.Error(t, err)
+}
+
+func TestSelect(t *testing.T) {
+	backport, _ := Parse(".gitea/PULL_REQUEST_TEMPLATE/backport.md", []byte("---\nbranches: [\"release/*\"]\n---\n"))
+	feature, _ := Parse(".gitea/PULL_REQUEST_TEMPLATE/feature.md", []byte("## Feature"))
+	single := &Template{FileName: ".gitea/PULL_REQUEST_TEMPLATE.md"}
+	templates := []*Template{backport, feature, single}
+
+	assert.Equal(t, backport, Select(templates, "release/1.13"))
+	assert.Equal(t, single, Select(templates, "master"))
+	assert.Nil(t, Select(templates[:2], "master"))
+	assert.Equal(t, feature, Find(templates, ".gitea/PULL_REQUEST_TEMPLATE/feature.md"))
+	assert.Nil(t, Find(templates, "feature.md"))
+}
@@ -53,6 +53,41 @@ type Flushable interface {
 	IsEmpty() bool
 }
 
+// Countable represents a pool or queue that can count the data waiting in it
+type Countable interface {
+	// NumberInQueue returns the number of items waiting to be handled
+	NumberInQueue() int64
+}
+
+// Measurable represents a pool or queue that measures the handling of its data
+type Measurable interface {
+	// HandleStats returns the number of batches handled and the total time spent handling them
+	HandleStats() (int64, time.Duration)
+}
+
+// Pausable represents a pool or queue that can stop handling its data for a while
+type Pausable interface {
+	// Pause stops the handling of data until Resume is called
+	Pause()
+	// Resume restarts the handling of data
+	Resume()
+	// IsPaused returns if the handling of data is stopped
+	IsPaused() bool
+}
+
+// Inspectable represents a pool or queue that tracks the data being handled
+// and keeps the data its handler failed on
+type Inspectable interface {
+	// NumberInProgress returns the number of items being handled
+	NumberInProgress() int64
+	// NumberOfFailures returns the number of batches the handler failed on
+	NumberOfFailures() int64
+	// DeadItems returns the batches the handler failed on
+	DeadItems() []*DeadItem
+	// TakeDeadItems removes the dead items with the given IDs, or all of them if no IDs are given, and returns them
+	TakeDeadItems(ids ...int64) []*DeadItem
+}
+
 // ManagedPool is a simple interface to get certain details from a worker pool
 type ManagedPool interface {
 	// AddWorkers adds a number of worker as group to the pool with the provided timeout. A CancelFunc is provided to cancel the group
@@ -297,6 +332,113 @@ func (q *ManagedQueue) IsEmpty() bool {
 	return true
 }
 
+// NumberInQueue returns the number of items waiting in the queue, or -1 if
+// the queue cannot count them
+func (q *ManagedQueue) NumberInQueue() int64 {
+	if countable, ok := q.Managed.(Countable); ok {
+		return countable.NumberInQueue()
+	}
+	return -1
+}
+
+// HandleStats returns the number of batches handled by the queue and the
+// total time spent handling them
+func (q *ManagedQueue) HandleStats() (int64, time.Duration) {
+	if measurable, ok := q.Managed.(Measurable); ok {
+		return measurable.HandleStats()
+	}
+	return 0, 0
+}
+
+// IsPausable returns if the queue can be paused
+func (q *ManagedQueue) IsPausable() bool {
+	_, ok := q.Managed.(Pausable)
+	return ok
+}
+
+// IsPaused returns if the queue is paused
+func (q *ManagedQueue) IsPaused() bool {
+	if pausable, ok := q.Managed.(Pausable); ok {
+		return pausable.IsPaused()
+	}
+	return false
+}
+
+// Pause stops the queue from handling its data
+func (q *ManagedQueue) Pause() {
+	if pausable, ok := q.Managed.(Pausable); ok {
+		pausable.Pause()
+	}
+}
+
+// Resume restarts the handling of the data of a paused queue
+func (q *ManagedQueue) Resume() {
+	if pausable, ok := q.Managed.(Pausable); ok {
+		pausable.Resume()
+	}
+}
+
+// NumberInProgress returns the number of items being handled, or -1 if the
+// queue does not track them
+func (q *ManagedQueue) NumberInProgress() int64 {
+	if inspectable, ok := q.Managed.(Inspectable); ok {
+		return inspectable.NumberInProgress()
+	}
+	return -1
+}
+
+// NumberOfFailures returns the number of batches the handler of the queue
+// failed on, or -1 if the queue does not track them
+func (q *ManagedQueue) NumberOfFailures() int64 {
+	if inspectable, ok := q.Managed.(Inspectable); ok {
+		return inspectable.NumberOfFailures()
+	}
+	return -1
+}
+
+// DeadItems returns the batches the handler of the queue failed on
+func (q *ManagedQueue) DeadItems() []*DeadItem {
+	if inspectable, ok := q.Managed.(Inspectable); ok {
+		return inspectable.DeadItems()
+	}
+	return nil
+}
+
+// RetryDeadItems pushes the data of the dead items with the given IDs, or of
+// all dead items if no IDs are given, back into the queue and returns the
+// number of retried items
+func (q *ManagedQueue) RetryDeadItems(ids ...int64) int {
+	inspectable, ok := q.Managed.(Inspectable)
+	if !ok {
+		return 0
+	}
+	queue, ok := q.Managed.(Queue)
+	if !ok {
+		return 0
+	}
+	items := inspectable.TakeDeadItems(ids...)
+	go func() {
+		// pushing blocks while the queue is full
+		for _, item := range items {
+			for _, datum := range item.Data {
+				if err := queue.Push(datum); err != nil {
+					log.Error("Unable to retry dead item %d of queue %s: %v", item.ID, q.Name, err)
+				}
+			}
+		}
+	}()
+	return len(items)
+}
+
+// RemoveDeadItems removes the dead items with the given IDs, or all dead items
+// if no IDs are given, and returns the number of removed items
+func (q *ManagedQueue) RemoveDeadItems(ids ...int64) int {
+	if inspectable, ok := q.Managed.(Inspectable); ok {
+		return len(inspectable.TakeDeadItems(ids...))
+	}
+	return 0
+}
+
 // NumberOfWorkers returns the number of workers in the queue
 func (q *ManagedQueue) NumberOfWorkers() int {
 	if pool, ok := q.Managed.(ManagedPool); ok {
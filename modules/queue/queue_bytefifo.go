@@ -88,6 +88,13 @@ func (q *ByteFIFOQueue) IsEmpty() bool {
 	return q.byteFIFO.Len() == 0
 }
 
+// NumberInQueue returns the number of items waiting in the fifo and the worker queue
+func (q *ByteFIFOQueue) NumberInQueue() int64 {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.WorkerPool.NumberInQueue() + q.byteFIFO.Len()
+}
+
 // Run runs the bytefifo queue
 func (q *ByteFIFOQueue) Run(atShutdown, atTerminate func(context.Context, func())) {
 	atShutdown(context.Background(), q.Shutdown)
@@ -120,6 +127,12 @@ func (q *ByteFIFOQueue) readToChan() {
 			q.cancel()
 			return
 		default:
+			if q.IsPaused() {
+				// leave the data in the fifo until the pool is resumed
+				time.Sleep(time.Millisecond * 100)
+				continue
+			}
+
 			q.lock.Lock()
 			bs, err := q.byteFIFO.Pop()
 			if err != nil {
@@ -6,6 +6,7 @@ package queue
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -96,3 +97,51 @@ func TestChannelQueue_Batch(t *testing.T) {
 	err = queue.Push(test1)
 	assert.Error(t, err)
 }
+
+func TestChannelQueue_RetryDeadItems(t *testing.T) {
+	handleChan := make(chan *testData, 10)
+	var failing int32 = 1
+	handle := func(data ...Data) {
+		for _, datum := range data {
+			if atomic.LoadInt32(&failing) == 1 {
+				panic("failing")
+			}
+			handleChan <- datum.(*testData)
+		}
+	}
+
+	nilFn := func(_ context.Context, _ func()) {}
+
+	queue, err := NewChannelQueue(handle,
+		ChannelQueueConfiguration{
+			WorkerPoolConfiguration: WorkerPoolConfiguration{
+				QueueLength: 10,
+				BatchLength: 1,
+				MaxWorkers:  10,
+			},
+			Workers: 1,
+			Name:    "TestChannelQueue_RetryDeadItems",
+		}, &testData{})
+	assert.NoError(t, err)
+	go queue.Run(nilFn, nilFn)
+
+	mq := GetManager().GetManagedQueue(queue.(*ChannelQueue).qid)
+	assert.True(t, mq.IsPausable())
+	assert.NoError(t, queue.Push(&testData{"A", 1}))
+	for i := 0; i < 50 && len(mq.DeadItems()) == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.EqualValues(t, 1, mq.NumberOfFailures())
+	assert.Len(t, mq.DeadItems(), 1)
+
+	atomic.StoreInt32(&failing, 0)
+	assert.Equal(t, 1, mq.RetryDeadItems())
+	select {
+	case result := <-handleChan:
+		assert.Equal(t, &testData{"A", 1}, result)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "the retried item must be handled")
+	}
+	assert.Empty(t, mq.DeadItems())
+	assert.Equal(t, 0, mq.RemoveDeadItems())
+}
@@ -228,6 +228,31 @@ func (q *PersistableChannelQueue) IsEmpty() bool {
 	return q.internal.IsEmpty()
 }
 
+// NumberInQueue returns the number of items waiting in the channel and the internal queue
+func (q *PersistableChannelQueue) NumberInQueue() int64 {
+	n := q.channelQueue.NumberInQueue()
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if countable, ok := q.internal.(Countable); ok {
+		n += countable.NumberInQueue()
+	}
+	return n
+}
+
+// HandleStats returns the number of batches handled by the channel and the internal queue
+// and the total time spent handling them
+func (q *PersistableChannelQueue) HandleStats() (int64, time.Duration) {
+	n, d := q.channelQueue.HandleStats()
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if measurable, ok := q.internal.(Measurable); ok {
+		internalN, internalD := measurable.HandleStats()
+		n += internalN
+		d += internalD
+	}
+	return n, d
+}
+
 // Shutdown processing this queue
 func (q *PersistableChannelQueue) Shutdown() {
 	log.Trace("PersistableChannelQueue: %s Shutting down", q.delayedStarter.name)
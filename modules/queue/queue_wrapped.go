@@ -220,6 +220,27 @@ func (q *WrappedQueue) IsEmpty() bool {
 	return q.internal.IsEmpty()
 }
 
+// NumberInQueue returns the number of items waiting in the wrapper and the internal queue
+func (q *WrappedQueue) NumberInQueue() int64 {
+	n := atomic.LoadInt64(&q.numInQueue)
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if countable, ok := q.internal.(Countable); ok {
+		n += countable.NumberInQueue()
+	}
+	return n
+}
+
+// HandleStats returns the number of batches handled by the internal queue and the total time spent handling them
+func (q *WrappedQueue) HandleStats() (int64, time.Duration) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if measurable, ok := q.internal.(Measurable); ok {
+		return measurable.HandleStats()
+	}
+	return 0, 0
+}
+
 // Run starts to run the queue and attempts to create the internal queue
 func (q *WrappedQueue) Run(atShutdown, atTerminate func(context.Context, func())) {
 	log.Debug("WrappedQueue: %s Starting", q.name)
@@ -6,14 +6,24 @@ package queue
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/tracing"
 	"code.gitea.io/gitea/modules/util"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// maxDeadItems is the number of failed batches a pool keeps for retrying,
+// older batches are dropped once it is reached
+const maxDeadItems = 100
+
 // WorkerPool represent a dynamically growable worker pool for a
 // provided handler function. They have an internal channel which
 // they use to detect if there is a block and will grow and shrink in
@@ -33,6 +43,30 @@ type WorkerPool struct {
 	boostTimeout       time.Duration
 	boostWorkers       int
 	numInQueue         int64
+	numHandled         int64
+	handleDuration     int64
+	numInProgress      int64
+	numFailed          int64
+	resumed            chan struct{}
+	deadItems          []*DeadItem
+	deadItemCounter    int64
+}
+
+// DeadItem represents a batch of data the handler of a pool failed on
+type DeadItem struct {
+	ID     int64
+	Data   []Data
+	Error  string
+	Failed time.Time
+}
+
+// Payload returns the data of the batch as indented JSON
+func (d *DeadItem) Payload() string {
+	bs, err := json.MarshalIndent(d.Data, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", d.Data)
+	}
+	return string(bs)
 }
 
 // WorkerPoolConfiguration is the basic configuration for a WorkerPool
@@ -61,6 +95,19 @@ func NewWorkerPool(handle HandlerFunc, config WorkerPoolConfiguration) *WorkerPo
 		boostWorkers:       config.BoostWorkers,
 		maxNumberOfWorkers: config.MaxWorkers,
 	}
+	pool.handle = func(data ...Data) {
+		start := time.Now()
+		span := pool.startSpan(len(data))
+		atomic.AddInt64(&pool.numInProgress, int64(len(data)))
+		err := callHandler(handle, data)
+		atomic.AddInt64(&pool.numInProgress, -int64(len(data)))
+		tracing.End(span, err)
+		atomic.AddInt64(&pool.numHandled, 1)
+		atomic.AddInt64(&pool.handleDuration, int64(time.Since(start)))
+		if err != nil {
+			pool.addDeadItem(data, err)
+		}
+	}
 
 	return pool
 }
@@ -96,7 +143,8 @@ func (p *WorkerPool) pushBoost(data Data) {
 			util.StopTimer(timer)
 		case <-timer.C:
 			p.lock.Lock()
-			if p.blockTimeout > ourTimeout || (p.numberOfWorkers > p.maxNumberOfWorkers && p.maxNumberOfWorkers >= 0) {
+			// a paused pool blocks because it does not take data, more workers would not help
+			if p.blockTimeout > ourTimeout || (p.numberOfWorkers > p.maxNumberOfWorkers && p.maxNumberOfWorkers >= 0) || p.resumed != nil {
 				p.lock.Unlock()
 				p.dataChan <- data
 				return
@@ -295,6 +343,149 @@ func (p *WorkerPool) IsEmpty() bool {
 	return atomic.LoadInt64(&p.numInQueue) == 0
 }
 
+// NumberInQueue returns the number of items waiting in the worker queue
+func (p *WorkerPool) NumberInQueue() int64 {
+	return atomic.LoadInt64(&p.numInQueue)
+}
+
+// startSpan starts a span for handling a batch of data, named after the queue of the pool
+func (p *WorkerPool) startSpan(batchLength int) trace.Span {
+	name := "queue"
+	if tracing.IsEnabled() {
+		if mq := GetManager().GetManagedQueue(p.qid); mq != nil {
+			name += " " + mq.Name
+		}
+	}
+	_, span := tracing.Start(p.baseCtx, name, label.Int64("queue.id", p.qid), label.Int("queue.batch_length", batchLength))
+	return span
+}
+
+// HandleStats returns the number of batches handled by the pool and the total time spent handling them
+func (p *WorkerPool) HandleStats() (int64, time.Duration) {
+	return atomic.LoadInt64(&p.numHandled), time.Duration(atomic.LoadInt64(&p.handleDuration))
+}
+
+// NumberInProgress returns the number of items currently being handled
+func (p *WorkerPool) NumberInProgress() int64 {
+	return atomic.LoadInt64(&p.numInProgress)
+}
+
+// NumberOfFailures returns the number of batches the handler failed on
+func (p *WorkerPool) NumberOfFailures() int64 {
+	return atomic.LoadInt64(&p.numFailed)
+}
+
+// Pause stops the workers of the pool from taking data until the pool is resumed
+func (p *WorkerPool) Pause() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.resumed == nil {
+		p.resumed = make(chan struct{})
+	}
+}
+
+// Resume lets the workers of a paused pool take data again
+func (p *WorkerPool) Resume() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.resumed != nil {
+		close(p.resumed)
+		p.resumed = nil
+	}
+}
+
+// IsPaused returns if the pool is paused
+func (p *WorkerPool) IsPaused() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.resumed != nil
+}
+
+// waitWhilePaused blocks until the pool is resumed or ctx is done
+func (p *WorkerPool) waitWhilePaused(ctx context.Context) {
+	p.lock.Lock()
+	resumed := p.resumed
+	p.lock.Unlock()
+	if resumed == nil {
+		return
+	}
+	select {
+	case <-resumed:
+	case <-ctx.Done():
+	}
+}
+
+// DeadItems returns the batches the handler failed on, oldest first
+func (p *WorkerPool) DeadItems() []*DeadItem {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	items := make([]*DeadItem, len(p.deadItems))
+	copy(items, p.deadItems)
+	return items
+}
+
+// TakeDeadItems removes the dead items with the given IDs, or all dead items
+// if no IDs are given, from the pool and returns them
+func (p *WorkerPool) TakeDeadItems(ids ...int64) []*DeadItem {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if len(ids) == 0 {
+		items := p.deadItems
+		p.deadItems = nil
+		return items
+	}
+
+	var taken []*DeadItem
+	kept := p.deadItems[:0]
+	for _, item := range p.deadItems {
+		if containsID(ids, item.ID) {
+			taken = append(taken, item)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	p.deadItems = kept
+	return taken
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *WorkerPool) addDeadItem(data []Data, err error) {
+	atomic.AddInt64(&p.numFailed, 1)
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.deadItemCounter++
+	p.deadItems = append(p.deadItems, &DeadItem{
+		ID:     p.deadItemCounter,
+		Data:   data,
+		Error:  err.Error(),
+		Failed: time.Now(),
+	})
+	if len(p.deadItems) > maxDeadItems {
+		log.Warn("WorkerPool: %d has more than %d dead items - dropping the oldest", p.qid, maxDeadItems)
+		p.deadItems = p.deadItems[len(p.deadItems)-maxDeadItems:]
+	}
+}
+
+// callHandler calls the handler and returns the panic of the handler, if there was one, as error
+func callHandler(handle HandlerFunc, data []Data) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+			log.Error("PANIC whilst handling %d data: %v\n%s", len(data), r, log.Stack(2))
+		}
+	}()
+	handle(data...)
+	return nil
+}
+
 // FlushWithContext is very similar to CleanUp but it will return as soon as the dataChan is empty
 // NB: The worker will not be registered with the manager.
 func (p *WorkerPool) FlushWithContext(ctx context.Context) error {
@@ -318,6 +509,7 @@ func (p *WorkerPool) doWork(ctx context.Context) {
 	delay := time.Millisecond * 300
 	var data = make([]Data, 0, p.batchLength)
 	for {
+		p.waitWhilePaused(ctx)
 		select {
 		case <-ctx.Done():
 			if len(data) > 0 {
@@ -0,0 +1,94 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool_HandleStats(t *testing.T) {
+	handled := 0
+	pool := NewWorkerPool(func(data ...Data) {
+		handled += len(data)
+		time.Sleep(time.Millisecond)
+	}, WorkerPoolConfiguration{QueueLength: 10, BatchLength: 2})
+
+	batches, duration := pool.HandleStats()
+	assert.EqualValues(t, 0, batches)
+	assert.EqualValues(t, 0, duration)
+
+	pool.handle(&testData{"A", 1}, &testData{"B", 2})
+	pool.handle(&testData{"C", 3})
+
+	batches, duration = pool.HandleStats()
+	assert.Equal(t, 3, handled)
+	assert.EqualValues(t, 2, batches)
+	assert.True(t, duration >= 2*time.Millisecond)
+}
+
+func TestWorkerPool_DeadItems(t *testing.T) {
+	pool := NewWorkerPool(func(data ...Data) {
+		if data[0].(*testData).TestInt < 0 {
+			panic("negative")
+		}
+	}, WorkerPoolConfiguration{QueueLength: 10, BatchLength: 1})
+
+	pool.handle(&testData{"A", 1})
+	pool.handle(&testData{"B", -1})
+	pool.handle(&testData{"C", -2})
+
+	assert.EqualValues(t, 0, pool.NumberInProgress())
+	assert.EqualValues(t, 2, pool.NumberOfFailures())
+	items := pool.DeadItems()
+	if assert.Len(t, items, 2) {
+		assert.Equal(t, "negative", items[0].Error)
+		assert.Equal(t, []Data{&testData{"B", -1}}, items[0].Data)
+		assert.JSONEq(t, `[{"TestString":"B","TestInt":-1}]`, items[0].Payload())
+	}
+
+	taken := pool.TakeDeadItems(items[1].ID)
+	assert.Len(t, taken, 1)
+	assert.Equal(t, items[1].ID, taken[0].ID)
+	assert.Len(t, pool.DeadItems(), 1)
+	assert.Len(t, pool.TakeDeadItems(), 1)
+	assert.Empty(t, pool.DeadItems())
+	assert.EqualValues(t, 2, pool.NumberOfFailures())
+}
+
+func TestWorkerPool_Pause(t *testing.T) {
+	handled := make(chan Data, 10)
+	pool := NewWorkerPool(func(data ...Data) {
+		for _, datum := range data {
+			handled <- datum
+		}
+	}, WorkerPoolConfiguration{QueueLength: 10, BatchLength: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool.Pause()
+	assert.True(t, pool.IsPaused())
+	pool.addWorkers(ctx, 1)
+	pool.Push(&testData{"A", 1})
+
+	select {
+	case <-handled:
+		assert.Fail(t, "a paused pool must not handle data")
+	case <-time.After(200 * time.Millisecond):
+	}
+	assert.EqualValues(t, 1, pool.NumberInQueue())
+
+	pool.Resume()
+	assert.False(t, pool.IsPaused())
+	select {
+	case datum := <-handled:
+		assert.Equal(t, &testData{"A", 1}, datum)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "a resumed pool must handle data")
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
+	action_notifier "code.gitea.io/gitea/modules/notification/action"
 	"code.gitea.io/gitea/modules/references"
 	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
@@ -280,8 +281,38 @@ func CommitRepoAction(optsList ...*CommitRepoActionOptions) error {
 		}
 	}
 
-	if err := models.NotifyWatchers(actions...); err != nil {
+	if err := action_notifier.NotifyWatchers(actions...); err != nil {
 		return fmt.Errorf("NotifyWatchers: %v", err)
 	}
+
+	for i, opts := range optsList {
+		if actions[i].OpType != models.ActionCommitRepo || opts.IsNewRef() {
+			continue
+		}
+		if err := notifyPathWatchers(actions[i], opts.OldCommitID, opts.NewCommitID); err != nil {
+			log.Error("notifyPathWatchers [repo_id: %d]: %v", actions[i].RepoID, err)
+		}
+	}
 	return nil
 }
+
+// notifyPathWatchers adds a push action to the feeds of the users who watch the paths changed
+// between oldCommitID and newCommitID
+func notifyPathWatchers(act *models.Action, oldCommitID, newCommitID string) error {
+	has, err := models.HasWatchPaths(act.RepoID)
+	if err != nil || !has {
+		return err
+	}
+
+	gitRepo, err := git.OpenRepository(act.Repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	changedPaths, err := gitRepo.GetFilesChangedBetween(oldCommitID, newCommitID)
+	if err != nil {
+		return err
+	}
+	return models.NotifyPathWatchers(act, changedPaths)
+}
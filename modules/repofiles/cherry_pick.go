@@ -0,0 +1,137 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	repo_module "code.gitea.io/gitea/modules/repository"
+)
+
+// CherryPickOptions holds the options of applying the changes of a commit, or their reversal,
+// to a new branch. The changes are the ones from the parent of the commit unless a base commit
+// is given, which allows picking the changes of a range of commits at once.
+type CherryPickOptions struct {
+	CommitID     string
+	BaseCommitID string
+	Revert       bool
+	OldBranch    string
+	NewBranch    string
+	Message      string
+}
+
+// CherryPickMessage returns the default message of the commit cherry-picking or reverting
+// another commit
+func CherryPickMessage(commit *git.Commit, revert bool) string {
+	if revert {
+		return fmt.Sprintf("Revert \"%s\"\n\nThis reverts commit %s.", commit.Summary(), commit.ID.String())
+	}
+	return fmt.Sprintf("%s\n\n(cherry picked from commit %s)", strings.TrimSpace(commit.CommitMessage), commit.ID.String())
+}
+
+// CherryPick creates a new branch from the old branch with a commit applying the changes of
+// another commit, or reverting them, and returns the ID of the new commit. A cherry-picked
+// commit keeps the author of the original one.
+func CherryPick(repo *models.Repository, doer *models.User, opts *CherryPickOptions) (string, error) {
+	// oldBranch must exist for this operation
+	if _, err := repo_module.GetBranch(repo, opts.OldBranch); err != nil {
+		return "", err
+	}
+	newBranch, err := repo_module.GetBranch(repo, opts.NewBranch)
+	if err != nil && !git.IsErrBranchNotExist(err) {
+		return "", err
+	}
+	if newBranch != nil {
+		return "", models.ErrBranchAlreadyExists{
+			BranchName: opts.NewBranch,
+		}
+	}
+
+	if err := models.CheckCommitMessage(repo, doer, opts.Message); err != nil {
+		return "", err
+	}
+
+	t, err := NewTemporaryUploadRepository(repo)
+	if err != nil {
+		return "", err
+	}
+	defer t.Close()
+	if err := t.Clone(opts.OldBranch); err != nil {
+		return "", err
+	}
+	if err := t.SetDefaultIndex(); err != nil {
+		return "", err
+	}
+
+	commit, err := t.GetCommit(opts.CommitID)
+	if err != nil {
+		return "", err
+	}
+	if opts.BaseCommitID == "" && commit.ParentCount() > 1 {
+		return "", models.ErrCherryPickMergeCommit{SHA: commit.ID.String()}
+	}
+
+	// the changes of a root commit are the ones from the empty tree
+	parent := git.EmptyTreeSHA
+	if opts.BaseCommitID != "" {
+		parent = opts.BaseCommitID
+	} else if commit.ParentCount() == 1 {
+		parentID, err := commit.ParentID(0)
+		if err != nil {
+			return "", err
+		}
+		parent = parentID.String()
+	}
+	from, to := parent, commit.ID.String()
+	if opts.Revert {
+		from, to = to, from
+	}
+
+	patch := new(bytes.Buffer)
+	if err := t.gitRepo.GetDiff(from, to, patch); err != nil {
+		return "", fmt.Errorf("GetDiff: %v", err)
+	}
+	if patch.Len() == 0 {
+		return "", models.ErrCherryPickEmpty{SHA: commit.ID.String()}
+	}
+	conflicts, err := t.ApplyToIndex(patch)
+	if err != nil {
+		return "", err
+	}
+	if len(conflicts) > 0 {
+		return "", models.ErrCherryPickConflict{SHA: commit.ID.String(), Files: conflicts}
+	}
+
+	treeHash, err := t.WriteTree()
+	if err != nil {
+		return "", err
+	}
+	headCommit, err := t.GetBranchCommit(opts.OldBranch)
+	if err != nil {
+		return "", err
+	}
+	if headCommit.Tree.ID.String() == treeHash {
+		return "", models.ErrCherryPickEmpty{SHA: commit.ID.String()}
+	}
+
+	var commitHash string
+	if opts.Revert {
+		commitHash, err = t.CommitTree(doer, doer, treeHash, opts.Message)
+	} else {
+		commitHash, err = t.CommitTreeWithAuthor(commit.Author, doer, treeHash, opts.Message)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := t.Push(doer, commitHash, opts.NewBranch); err != nil {
+		return "", err
+	}
+	return commitHash, nil
+}
@@ -12,6 +12,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 )
 
@@ -35,6 +36,20 @@ func (ct *ContentType) String() string {
 	return string(*ct)
 }
 
+// entryContentType returns the ContentType of a tree entry
+func entryContentType(entry *git.TreeEntry) ContentType {
+	switch {
+	case entry.IsDir():
+		return ContentTypeDir
+	case entry.IsLink():
+		return ContentTypeLink
+	case entry.IsSubModule():
+		return ContentTypeSubmodule
+	default:
+		return ContentTypeRegular
+	}
+}
+
 // GetContentsOrList gets the meta data of a file's contents (*ContentsResponse) if treePath not a tree
 // directory, otherwise a listing of file contents ([]*ContentsResponse). Ref can be a branch, commit or tag
 func GetContentsOrList(repo *models.Repository, treePath, ref string) (interface{}, error) {
@@ -184,7 +199,13 @@ func GetContents(repo *models.Repository, treePath, ref string, forList bool) (*
 		if err != nil {
 			return nil, err
 		}
-		contentsResponse.SubmoduleGitURL = &submodule.URL
+		if submodule != nil {
+			contentsResponse.SubmoduleGitURL = &submodule.URL
+			if link := git.SubModuleURL(submodule.URL, setting.AppURL, repo.FullName(), setting.SSH.Domain); link != "" {
+				htmlURL := link + "/commit/" + entry.ID.String()
+				contentsResponse.SubmoduleHTMLURL = &htmlURL
+			}
+		}
 	}
 	// Handle links
 	if entry.IsRegular() || entry.IsLink() {
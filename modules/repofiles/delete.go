@@ -67,7 +67,10 @@ func DeleteRepoFile(repo *models.Repository, doer *models.User, opts *DeleteRepo
 				}
 			}
 			if protectedBranch.RequireSignedCommits {
-				_, _, err := repo.SignCRUDAction(doer, repo.RepoPath(), opts.OldBranch)
+				_, keyID, err := repo.SignCRUDAction(doer, repo.RepoPath(), opts.OldBranch)
+				if err == nil {
+					err = protectedBranch.CheckSigningKey(keyID)
+				}
 				if err != nil {
 					if !models.IsErrWontSign(err) {
 						return nil, err
@@ -97,6 +100,9 @@ func DeleteRepoFile(repo *models.Repository, doer *models.User, opts *DeleteRepo
 	}
 
 	message := strings.TrimSpace(opts.Message)
+	if err := models.CheckCommitMessage(repo, doer, message); err != nil {
+		return nil, err
+	}
 
 	author, committer := GetAuthorAndCommitterUsers(opts.Author, opts.Committer, doer)
 
@@ -23,6 +23,12 @@ import (
 	"github.com/mcuadros/go-version"
 )
 
+var applyErrorSuffices = []string{
+	": already exists in index",
+	": patch does not apply",
+	": does not exist in index",
+}
+
 // TemporaryUploadRepository is a type to wrap our upload repositories as a shallow clone
 type TemporaryUploadRepository struct {
 	repo     *models.Repository
@@ -158,6 +164,41 @@ func (t *TemporaryUploadRepository) AddObjectToIndex(mode, objectHash, objectPat
 	return nil
 }
 
+// ApplyToIndex applies a patch to the index, it returns the files it does not apply to cleanly
+// if it fails because of conflicts
+func (t *TemporaryUploadRepository) ApplyToIndex(patch io.Reader) ([]string, error) {
+	stderr := new(bytes.Buffer)
+	if err := git.NewCommand("apply", "--cached", "--binary", "-").
+		RunInDirTimeoutEnvFullPipeline(nil, -1, t.basePath, nil, stderr, patch); err != nil {
+		const prefix = "error: patch failed:"
+		const errorPrefix = "error: "
+		conflicts := make([]string, 0, 5)
+		seen := make(map[string]bool)
+		for _, line := range strings.Split(stderr.String(), "\n") {
+			file := ""
+			if strings.HasPrefix(line, prefix) {
+				file = strings.TrimSpace(strings.Split(line[len(prefix):], ":")[0])
+			} else if strings.HasPrefix(line, errorPrefix) {
+				for _, suffix := range applyErrorSuffices {
+					if strings.HasSuffix(line, suffix) {
+						file = strings.TrimSpace(strings.TrimSuffix(line[len(errorPrefix):], suffix))
+						break
+					}
+				}
+			}
+			if file != "" && !seen[file] {
+				seen[file] = true
+				conflicts = append(conflicts, file)
+			}
+		}
+		if len(conflicts) > 0 {
+			return conflicts, nil
+		}
+		return nil, fmt.Errorf("git apply: %v\n%s", err, stderr)
+	}
+	return nil, nil
+}
+
 // WriteTree writes the current index as a tree to the object db and returns its hash
 func (t *TemporaryUploadRepository) WriteTree() (string, error) {
 	stdout, err := git.NewCommand("write-tree").RunInDir(t.basePath)
@@ -193,9 +234,16 @@ func (t *TemporaryUploadRepository) CommitTree(author, committer *models.User, t
 
 // CommitTreeWithDate creates a commit from a given tree for the user with provided message
 func (t *TemporaryUploadRepository) CommitTreeWithDate(author, committer *models.User, treeHash string, message string, authorDate, committerDate time.Time) (string, error) {
-	authorSig := author.NewGitSig()
-	committerSig := committer.NewGitSig()
+	return t.commitTree(author.NewGitSig(), committer.NewGitSig(), author, treeHash, message, authorDate, committerDate)
+}
+
+// CommitTreeWithAuthor creates a commit from a given tree keeping the author and author date of
+// another commit, it is signed for the committer
+func (t *TemporaryUploadRepository) CommitTreeWithAuthor(author *git.Signature, committer *models.User, treeHash string, message string) (string, error) {
+	return t.commitTree(author, committer.NewGitSig(), committer, treeHash, message, author.When, time.Now())
+}
 
+func (t *TemporaryUploadRepository) commitTree(authorSig, committerSig *git.Signature, signer *models.User, treeHash string, message string, authorDate, committerDate time.Time) (string, error) {
 	binVersion, err := git.BinVersion()
 	if err != nil {
 		return "", fmt.Errorf("Unable to get git version: %v", err)
@@ -219,7 +267,7 @@ func (t *TemporaryUploadRepository) CommitTreeWithDate(author, committer *models
 
 	// Determine if we should sign
 	if version.Compare(binVersion, "1.7.9", ">=") {
-		sign, keyID, _ := t.repo.SignCRUDAction(author, t.basePath, "HEAD")
+		sign, keyID, _ := t.repo.SignCRUDAction(signer, t.basePath, "HEAD")
 		if sign {
 			args = append(args, "-S"+keyID)
 		} else if version.Compare(binVersion, "2.0.0", ">=") {
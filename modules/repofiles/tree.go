@@ -6,8 +6,10 @@ package repofiles
 
 import (
 	"fmt"
+	"path"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
@@ -26,9 +28,6 @@ func GetTreeBySHA(repo *models.Repository, sha string, page, perPage int, recurs
 			SHA: sha,
 		}
 	}
-	tree := new(api.GitTreeResponse)
-	tree.SHA = gitTree.ResolvedID.String()
-	tree.URL = repo.APIURL() + "/git/trees/" + tree.SHA
 	var entries git.Entries
 	if recursive {
 		entries, err = gitTree.ListEntriesRecursive()
@@ -38,6 +37,53 @@ func GetTreeBySHA(repo *models.Repository, sha string, page, perPage int, recurs
 	if err != nil {
 		return nil, err
 	}
+	return toGitTreeResponse(repo, gitTree.ResolvedID.String(), entries, page, perPage), nil
+}
+
+// GetTreeByPath gets the GitTreeResponse of a directory of a repository, listing its entries up to
+// depth levels deep. Ref can be a branch, commit or tag and defaults to the default branch.
+func GetTreeByPath(repo *models.Repository, ref, treePath string, depth, page, perPage int) (*api.GitTreeResponse, error) {
+	if ref == "" {
+		ref = repo.DefaultBranch
+	}
+	if depth > setting.API.MaxTreeDepth {
+		depth = setting.API.MaxTreeDepth
+	}
+
+	// Check that the path given in treePath is valid (not a git path)
+	cleanTreePath := CleanUploadFileName(treePath)
+	if cleanTreePath == "" && treePath != "" {
+		return nil, models.ErrFilenameInvalid{
+			Path: treePath,
+		}
+	}
+	treePath = cleanTreePath
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetCommit(ref)
+	if err != nil {
+		return nil, err
+	}
+	gitTree, err := commit.SubTree(treePath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := gitTree.ListEntriesWithDepth(depth)
+	if err != nil {
+		return nil, err
+	}
+	return toGitTreeResponse(repo, gitTree.ID.String(), entries, page, perPage), nil
+}
+
+func toGitTreeResponse(repo *models.Repository, sha string, entries git.Entries, page, perPage int) *api.GitTreeResponse {
+	tree := new(api.GitTreeResponse)
+	tree.SHA = sha
+	tree.URL = repo.APIURL() + "/git/trees/" + tree.SHA
 	apiURL := repo.APIURL()
 	apiURLLen := len(apiURL)
 
@@ -64,7 +110,7 @@ func GetTreeBySHA(repo *models.Repository, sha string, page, perPage int, recurs
 	tree.TotalCount = len(entries)
 	rangeStart := perPage * (page - 1)
 	if rangeStart >= len(entries) {
-		return tree, nil
+		return tree
 	}
 	var rangeEnd int
 	if len(entries) > perPage {
@@ -93,5 +139,103 @@ func GetTreeBySHA(repo *models.Repository, sha string, page, perPage int, recurs
 			tree.Entries[i].URL = string(blobURL)
 		}
 	}
-	return tree, nil
+	return tree
+}
+
+// GetLastCommits gets the commit which last changed a directory of a repository and the ones which
+// last changed each of its entries. Ref can be a branch, commit or tag and defaults to the default
+// branch.
+func GetLastCommits(repo *models.Repository, ref, treePath string) (*api.DirectoryLastCommits, error) {
+	if ref == "" {
+		ref = repo.DefaultBranch
+	}
+
+	// Check that the path given in treePath is valid (not a git path)
+	cleanTreePath := CleanUploadFileName(treePath)
+	if cleanTreePath == "" && treePath != "" {
+		return nil, models.ErrFilenameInvalid{
+			Path: treePath,
+		}
+	}
+	treePath = cleanTreePath
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetCommit(ref)
+	if err != nil {
+		return nil, err
+	}
+	gitTree, err := commit.SubTree(treePath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := gitTree.ListEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	var c git.LastCommitCache
+	if setting.CacheService.LastCommit.Enabled && cache.IsEnabled() {
+		c = cache.NewLastCommitCache(repo.FullName(), gitRepo, int64(setting.CacheService.LastCommit.TTL.Seconds()))
+	}
+	commitsInfo, treeCommit, err := entries.GetCommitsInfo(commit, treePath, c)
+	if err != nil {
+		return nil, err
+	}
+
+	// The commits are shared by many entries and only carry their ID
+	commitResponses := make(map[string]*api.FileCommitResponse)
+	getCommitResponse := func(id git.SHA1) (*api.FileCommitResponse, error) {
+		if resp, ok := commitResponses[id.String()]; ok {
+			return resp, nil
+		}
+		entryCommit, err := gitRepo.GetCommit(id.String())
+		if err != nil {
+			return nil, err
+		}
+		resp, err := GetFileCommitResponse(repo, entryCommit)
+		if err != nil {
+			return nil, err
+		}
+		commitResponses[id.String()] = resp
+		return resp, nil
+	}
+
+	lastCommits := &api.DirectoryLastCommits{
+		Path:    treePath,
+		SHA:     gitTree.ID.String(),
+		Entries: make([]*api.EntryLastCommit, 0, len(commitsInfo)),
+	}
+	if treeCommit != nil {
+		if lastCommits.Commit, err = getCommitResponse(treeCommit.ID); err != nil {
+			return nil, err
+		}
+	}
+	for _, info := range commitsInfo {
+		entry := info[0].(*git.TreeEntry)
+		entryLastCommit := &api.EntryLastCommit{
+			Name: entry.Name(),
+			Path: path.Join(treePath, entry.Name()),
+			Type: string(entryContentType(entry)),
+			SHA:  entry.ID.String(),
+		}
+		var entryCommitID *git.SHA1
+		switch entryCommit := info[1].(type) {
+		case *git.Commit:
+			entryCommitID = &entryCommit.ID
+		case *git.SubModuleFile:
+			entryCommitID = &entryCommit.Commit.ID
+		}
+		if entryCommitID != nil {
+			if entryLastCommit.Commit, err = getCommitResponse(*entryCommitID); err != nil {
+				return nil, err
+			}
+		}
+		lastCommits.Entries = append(lastCommits.Entries, entryLastCommit)
+	}
+	return lastCommits, nil
 }
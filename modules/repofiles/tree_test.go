@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/test"
 
@@ -51,3 +52,37 @@ func TestGetTreeBySHA(t *testing.T) {
 
 	assert.EqualValues(t, expectedTree, tree)
 }
+
+func TestGetTreeByPath(t *testing.T) {
+	models.PrepareTestEnv(t)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	tree, err := GetTreeByPath(repo, "", "", 2, 1, 10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "2a2f1d4670728a2e10049e345bd7a276468beab6", tree.SHA)
+	assert.EqualValues(t, 1, tree.TotalCount)
+	assert.EqualValues(t, "README.md", tree.Entries[0].Path)
+
+	_, err = GetTreeByPath(repo, "master", "does-not-exist", 2, 1, 10)
+	assert.True(t, git.IsErrNotExist(err))
+
+	_, err = GetTreeByPath(repo, "master", ".git/objects", 2, 1, 10)
+	assert.True(t, models.IsErrFilenameInvalid(err))
+}
+
+func TestGetLastCommits(t *testing.T) {
+	models.PrepareTestEnv(t)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	lastCommits, err := GetLastCommits(repo, "master", "")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "", lastCommits.Path)
+	assert.EqualValues(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", lastCommits.Commit.SHA)
+	if assert.Len(t, lastCommits.Entries, 1) {
+		entry := lastCommits.Entries[0]
+		assert.EqualValues(t, "README.md", entry.Path)
+		assert.EqualValues(t, "file", entry.Type)
+		assert.EqualValues(t, "4b4851ad51df6a7d9f25c979345979eaeb5b349f", entry.SHA)
+		assert.EqualValues(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", entry.Commit.SHA)
+	}
+}
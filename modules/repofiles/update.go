@@ -18,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/packcache"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
@@ -163,7 +164,10 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 				}
 			}
 			if protectedBranch.RequireSignedCommits {
-				_, _, err := repo.SignCRUDAction(doer, repo.RepoPath(), opts.OldBranch)
+				_, keyID, err := repo.SignCRUDAction(doer, repo.RepoPath(), opts.OldBranch)
+				if err == nil {
+					err = protectedBranch.CheckSigningKey(keyID)
+				}
 				if err != nil {
 					if !models.IsErrWontSign(err) {
 						return nil, err
@@ -205,6 +209,9 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 	}
 
 	message := strings.TrimSpace(opts.Message)
+	if err := models.CheckCommitMessage(repo, doer, message); err != nil {
+		return nil, err
+	}
 
 	author, committer := GetAuthorAndCommitterUsers(opts.Author, opts.Committer, doer)
 
@@ -374,24 +381,9 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 	}
 	// Reset the opts.Content to our adjusted content to ensure that LFS gets the correct content
 	opts.Content = content
-	var lfsMetaObject *models.LFSMetaObject
-
-	if setting.LFS.StartServer {
-		// Check there is no way this can return multiple infos
-		filename2attribute2info, err := t.CheckAttribute("filter", treePath)
-		if err != nil {
-			return nil, err
-		}
-
-		if filename2attribute2info[treePath] != nil && filename2attribute2info[treePath]["filter"] == "lfs" {
-			// OK so we are supposed to LFS this data!
-			oid, err := models.GenerateLFSOid(strings.NewReader(opts.Content))
-			if err != nil {
-				return nil, err
-			}
-			lfsMetaObject = &models.LFSMetaObject{Oid: oid, Size: int64(len(opts.Content)), RepositoryID: repo.ID}
-			content = lfsMetaObject.Pointer()
-		}
+	content, lfsMetaObject, err := t.lfsPointer(treePath, opts.Content)
+	if err != nil {
+		return nil, err
 	}
 	// Add the object to the database
 	objectHash, err := t.HashObject(strings.NewReader(content))
@@ -429,19 +421,9 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 
 	if lfsMetaObject != nil {
 		// We have an LFS object - create it
-		lfsMetaObject, err = models.NewLFSMetaObject(lfsMetaObject)
-		if err != nil {
+		if err := storeLFSObject(repo, lfsMetaObject, opts.Content); err != nil {
 			return nil, err
 		}
-		contentStore := &lfs.ContentStore{BasePath: setting.LFS.ContentPath}
-		if !contentStore.Exists(lfsMetaObject) {
-			if err := contentStore.Put(lfsMetaObject, strings.NewReader(opts.Content)); err != nil {
-				if _, err2 := repo.RemoveLFSMetaObjectByOid(lfsMetaObject.Oid); err2 != nil {
-					return nil, fmt.Errorf("Error whilst removing failed inserted LFS object %s: %v (Prev Error: %v)", lfsMetaObject.Oid, err2, err)
-				}
-				return nil, err
-			}
-		}
 	}
 
 	// Then push this tree to NewBranch
@@ -551,6 +533,7 @@ func PushUpdate(repo *models.Repository, branch string, opts PushUpdateOptions)
 	if err != nil {
 		return fmt.Errorf("Failed to call 'git update-server-info': %v", err)
 	}
+	packcache.Invalidate(repo.ID)
 
 	gitRepo, err := git.OpenRepository(repoPath)
 	if err != nil {
@@ -583,6 +566,9 @@ func PushUpdate(repo *models.Repository, branch string, opts PushUpdateOptions)
 			return err
 		}
 
+		// Clear cache for the version of the static site which may be served from the branch
+		cache.Remove(models.GetPagesCacheKey(repo.ID))
+
 		if !opts.IsDelRef() {
 			// Clear cache for branch commit count
 			cache.Remove(repo.GetCommitsCountCacheKey(opts.BranchName(), true))
@@ -643,6 +629,8 @@ func PushUpdates(repo *models.Repository, optsList []*PushUpdateOptions) error {
 	if err != nil {
 		return fmt.Errorf("Failed to call 'git update-server-info': %v", err)
 	}
+	packcache.Invalidate(repo.ID)
+
 	gitRepo, err := git.OpenRepository(repoPath)
 	if err != nil {
 		return fmt.Errorf("OpenRepository: %v", err)
@@ -709,6 +697,10 @@ func createCommitRepoActions(repo *models.Repository, gitRepo *git.Repository, o
 			return nil, fmt.Errorf("Old and new revisions are both %s", git.EmptySHA)
 		}
 		var commits = &repo_module.PushCommits{}
+		if opts.IsBranch() {
+			// Clear cache for the version of the static site which may be served from the branch
+			cache.Remove(models.GetPagesCacheKey(repo.ID))
+		}
 		if opts.IsTag() {
 			// If is tag reference
 			tagName := opts.TagName()
@@ -756,3 +748,47 @@ func createCommitRepoActions(repo *models.Repository, gitRepo *git.Repository, o
 	}
 	return actions, nil
 }
+
+// lfsPointer returns the LFS pointer to store instead of the content of a file tracked by LFS,
+// with the LFS meta object to store by storeLFSObject once the pointer is committed. Other
+// files keep their content and have no LFS meta object.
+func (t *TemporaryUploadRepository) lfsPointer(treePath, content string) (string, *models.LFSMetaObject, error) {
+	if !setting.LFS.StartServer {
+		return content, nil, nil
+	}
+
+	// Check there is no way this can return multiple infos
+	filename2attribute2info, err := t.CheckAttribute("filter", treePath)
+	if err != nil {
+		return "", nil, err
+	}
+	if filename2attribute2info[treePath] == nil || filename2attribute2info[treePath]["filter"] != "lfs" {
+		return content, nil, nil
+	}
+
+	// OK so we are supposed to LFS this data!
+	oid, err := models.GenerateLFSOid(strings.NewReader(content))
+	if err != nil {
+		return "", nil, err
+	}
+	lfsMetaObject := &models.LFSMetaObject{Oid: oid, Size: int64(len(content)), RepositoryID: t.repo.ID}
+	return lfsMetaObject.Pointer(), lfsMetaObject, nil
+}
+
+// storeLFSObject creates the LFS meta object and stores its content if it is not stored yet
+func storeLFSObject(repo *models.Repository, lfsMetaObject *models.LFSMetaObject, content string) error {
+	lfsMetaObject, err := models.NewLFSMetaObject(lfsMetaObject)
+	if err != nil {
+		return err
+	}
+	contentStore := &lfs.ContentStore{BasePath: setting.LFS.ContentPath}
+	if !contentStore.Exists(lfsMetaObject) {
+		if err := contentStore.Put(lfsMetaObject, strings.NewReader(content)); err != nil {
+			if _, err2 := repo.RemoveLFSMetaObjectByOid(lfsMetaObject.Oid); err2 != nil {
+				return fmt.Errorf("Error whilst removing failed inserted LFS object %s: %v (Prev Error: %v)", lfsMetaObject.Oid, err2, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -50,6 +50,10 @@ func UploadRepoFiles(repo *models.Repository, doer *models.User, opts *UploadRep
 		return nil
 	}
 
+	if err := models.CheckCommitMessage(repo, doer, opts.Message); err != nil {
+		return err
+	}
+
 	uploads, err := models.GetUploadsByUUIDs(opts.Files)
 	if err != nil {
 		return fmt.Errorf("GetUploadsByUUIDs [uuids: %v]: %v", opts.Files, err)
@@ -0,0 +1,226 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	repo_module "code.gitea.io/gitea/modules/repository"
+)
+
+// CommitWorkspaceOptions holds the options to commit the changes of a web IDE workspace
+type CommitWorkspaceOptions struct {
+	OldBranch string
+	NewBranch string
+	Message   string
+	Changes   []*models.WorkspaceChange
+}
+
+// blobIDByPath returns the ID of the blob at the path in the commit, an empty string if there is none
+func blobIDByPath(commit *git.Commit, treePath string) (string, error) {
+	entry, err := commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if entry.IsDir() {
+		return "", nil
+	}
+	return entry.ID.String(), nil
+}
+
+// workspaceConflicts returns the paths of the changes whose files have been changed on the branch
+// since the commit the change is based on.
+func workspaceConflicts(gitRepo *git.Repository, head *git.Commit, changes []*models.WorkspaceChange) ([]string, error) {
+	bases := make(map[string]*git.Commit)
+	var conflicts []string
+	for _, change := range changes {
+		if change.BaseCommitID == "" || change.BaseCommitID == head.ID.String() {
+			continue
+		}
+		base, ok := bases[change.BaseCommitID]
+		if !ok {
+			var err error
+			if base, err = gitRepo.GetCommit(change.BaseCommitID); err != nil {
+				if !git.IsErrNotExist(err) {
+					return nil, err
+				}
+				// the base commit is gone after a force push, the file may have changed
+				base = nil
+			}
+			bases[change.BaseCommitID] = base
+		}
+
+		headID, err := blobIDByPath(head, change.TreePath)
+		if err != nil {
+			return nil, err
+		}
+		var baseID string
+		if base != nil {
+			if baseID, err = blobIDByPath(base, change.TreePath); err != nil {
+				return nil, err
+			}
+		}
+		if base == nil || baseID != headID {
+			conflicts = append(conflicts, change.TreePath)
+		}
+	}
+	return conflicts, nil
+}
+
+// CommitWorkspaceChanges commits all the changes of a web IDE workspace at once on top of the
+// latest commit of the branch and returns the ID of the new commit. It fails with an
+// ErrWorkspaceConflict if files changed in the workspace were changed on the branch since.
+func CommitWorkspaceChanges(repo *models.Repository, doer *models.User, opts *CommitWorkspaceOptions) (string, error) {
+	if opts.NewBranch == "" {
+		opts.NewBranch = opts.OldBranch
+	}
+
+	if _, err := repo_module.GetBranch(repo, opts.OldBranch); err != nil {
+		return "", err
+	}
+	if opts.NewBranch != opts.OldBranch {
+		existingBranch, err := repo_module.GetBranch(repo, opts.NewBranch)
+		if existingBranch != nil {
+			return "", models.ErrBranchAlreadyExists{
+				BranchName: opts.NewBranch,
+			}
+		}
+		if err != nil && !git.IsErrBranchNotExist(err) {
+			return "", err
+		}
+	} else {
+		protectedBranch, err := repo.GetBranchProtection(opts.OldBranch)
+		if err != nil {
+			return "", err
+		}
+		if protectedBranch != nil {
+			if !protectedBranch.CanUserPush(doer.ID) {
+				return "", models.ErrUserCannotCommit{
+					UserName: doer.LowerName,
+				}
+			}
+			for _, pat := range protectedBranch.GetProtectedFilePatterns() {
+				for _, change := range opts.Changes {
+					if pat.Match(strings.ToLower(change.TreePath)) {
+						return "", models.ErrFilePathProtected{
+							Path: change.TreePath,
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if err := models.CheckCommitMessage(repo, doer, opts.Message); err != nil {
+		return "", err
+	}
+
+	for _, change := range opts.Changes {
+		if CleanUploadFileName(change.TreePath) != change.TreePath {
+			return "", models.ErrFilenameInvalid{
+				Path: change.TreePath,
+			}
+		}
+		// Check file is not lfs locked, will return nil if lock setting not enabled
+		lfsLock, err := repo.GetTreePathLock(change.TreePath)
+		if err != nil {
+			return "", err
+		}
+		if lfsLock != nil && lfsLock.OwnerID != doer.ID {
+			return "", models.ErrLFSFileLocked{RepoID: repo.ID, Path: change.TreePath, UserName: lfsLock.Owner.Name}
+		}
+	}
+
+	t, err := NewTemporaryUploadRepository(repo)
+	if err != nil {
+		return "", err
+	}
+	defer t.Close()
+	if err := t.Clone(opts.OldBranch); err != nil {
+		return "", err
+	}
+	if err := t.SetDefaultIndex(); err != nil {
+		return "", err
+	}
+
+	head, err := t.GetBranchCommit(opts.OldBranch)
+	if err != nil {
+		return "", err
+	}
+	conflicts, err := workspaceConflicts(t.gitRepo, head, opts.Changes)
+	if err != nil {
+		return "", err
+	} else if len(conflicts) > 0 {
+		return "", models.ErrWorkspaceConflict{Paths: conflicts}
+	}
+
+	// the changed .gitattributes are added first, so that the files they track by LFS are
+	// committed as LFS pointers, whose contents are stored once the pointers are committed
+	changes := append([]*models.WorkspaceChange{}, opts.Changes...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return path.Base(changes[i].TreePath) == ".gitattributes" && path.Base(changes[j].TreePath) != ".gitattributes"
+	})
+	lfsContents := make(map[*models.LFSMetaObject]string)
+	for _, change := range changes {
+		entry, err := head.GetTreeEntryByPath(change.TreePath)
+		if err != nil && !git.IsErrNotExist(err) {
+			return "", err
+		}
+
+		if change.IsDeleted {
+			if entry == nil {
+				continue
+			}
+			if err := t.RemoveFilesFromIndex(change.TreePath); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		mode := "100644"
+		if entry != nil && entry.IsExecutable() {
+			mode = "100755"
+		}
+		content, lfsMetaObject, err := t.lfsPointer(change.TreePath, change.Content)
+		if err != nil {
+			return "", err
+		}
+		if lfsMetaObject != nil {
+			lfsContents[lfsMetaObject] = change.Content
+		}
+		objectHash, err := t.HashObject(strings.NewReader(content))
+		if err != nil {
+			return "", err
+		}
+		if err := t.AddObjectToIndex(mode, objectHash, change.TreePath); err != nil {
+			return "", err
+		}
+	}
+
+	treeHash, err := t.WriteTree()
+	if err != nil {
+		return "", err
+	}
+	commitHash, err := t.CommitTree(doer, doer, treeHash, opts.Message)
+	if err != nil {
+		return "", err
+	}
+	for lfsMetaObject, content := range lfsContents {
+		if err := storeLFSObject(repo, lfsMetaObject, content); err != nil {
+			return "", err
+		}
+	}
+	if err := t.Push(doer, commitHash, opts.NewBranch); err != nil {
+		return "", err
+	}
+	return commitHash, nil
+}
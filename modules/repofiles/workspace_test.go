@@ -0,0 +1,37 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceConflicts(t *testing.T) {
+	models.PrepareTestEnv(t)
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	// branch2 changed the README.md of the initial commit
+	head, err := gitRepo.GetBranchCommit("branch2")
+	assert.NoError(t, err)
+	initial := "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+
+	conflicts, err := workspaceConflicts(gitRepo, head, []*models.WorkspaceChange{
+		{TreePath: "README.md", BaseCommitID: initial},
+		{TreePath: "docs/new.md", BaseCommitID: initial},
+		{TreePath: "README.md", BaseCommitID: head.ID.String()},
+		{TreePath: "gone.md", BaseCommitID: "0000000000000000000000000000000000000001"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "gone.md"}, conflicts)
+}
@@ -6,9 +6,11 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
 )
 
 // GetBranch returns a branch by its name
@@ -27,6 +29,15 @@ func GetBranches(repo *models.Repository) ([]*git.Branch, error) {
 	return git.GetBranchesByPath(repo.RepoPath())
 }
 
+// IsBranchInactive returns true if the last commit of a branch is older than the number of
+// days after which branches are stale
+func IsBranchInactive(commit *git.Commit) bool {
+	if setting.Repository.StaleBranchDays <= 0 {
+		return false
+	}
+	return commit.Committer.When.Before(time.Now().AddDate(0, 0, -setting.Repository.StaleBranchDays))
+}
+
 // checkBranchName validates branch name with existing repository branches
 func checkBranchName(repo *models.Repository, name string) error {
 	gitRepo, err := git.OpenRepository(repo.RepoPath())
@@ -109,3 +120,28 @@ func CreateNewBranchFromCommit(doer *models.User, repo *models.Repository, commi
 
 	return nil
 }
+
+// RenameBranch renames a branch of a repository in the git repository and in the database, see
+// models.Repository.RenameBranch for what is updated.
+func RenameBranch(doer *models.User, repo *models.Repository, gitRepo *git.Repository, from, to string) error {
+	if !gitRepo.IsBranchExist(from) {
+		return models.ErrBranchDoesNotExist{
+			BranchName: from,
+		}
+	}
+	if err := checkBranchName(repo, to); err != nil {
+		return err
+	}
+
+	return repo.RenameBranch(from, to, doer.ID, func(isDefault bool) error {
+		if err := gitRepo.RenameBranch(from, to); err != nil {
+			return fmt.Errorf("RenameBranch: %v", err)
+		}
+		if isDefault {
+			if err := gitRepo.SetDefaultBranch(to); err != nil && !git.IsErrUnsupportedVersion(err) {
+				return fmt.Errorf("SetDefaultBranch: %v", err)
+			}
+		}
+		return nil
+	})
+}
@@ -91,6 +91,11 @@ func GitGcRepos(ctx context.Context, timeout time.Duration, args ...string) erro
 				}
 				return fmt.Errorf("Repository garbage collection failed in repo: %s: Error: %v", repo.FullName(), err)
 			}
+
+			// Bloom filters of changed paths are only written on request
+			if err := git.WriteCommitGraph(ctx, repo.RepoPath(), timeout); err != nil {
+				log.Warn("Unable to write commit-graph for %v: %v", repo, err)
+			}
 			return nil
 		},
 	); err != nil {
@@ -0,0 +1,85 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+var (
+	// Cluster settings
+	Cluster = struct {
+		Enabled       bool
+		NodeName      string
+		LeaseDuration time.Duration
+	}{
+		Enabled:       false,
+		LeaseDuration: 30 * time.Second,
+	}
+)
+
+func newClusterService() {
+	sec := Cfg.Section("cluster")
+	Cluster.Enabled = sec.Key("ENABLED").MustBool(false)
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	Cluster.NodeName = sec.Key("NODE_NAME").MustString(hostname)
+	Cluster.LeaseDuration = sec.Key("LEASE_DURATION").MustDuration(30 * time.Second)
+	if Cluster.LeaseDuration < 3*time.Second {
+		log.Error("Cluster LEASE_DURATION must be at least 3s, using 3s")
+		Cluster.LeaseDuration = 3 * time.Second
+	}
+	if !Cluster.Enabled {
+		return
+	}
+
+	if problems := clusterBackendProblems(); len(problems) > 0 {
+		log.Fatal("Clustering requires backends shared by all nodes:\n%s", strings.Join(problems, "\n"))
+	}
+	log.Info("Cluster Service Enabled: node %s", Cluster.NodeName)
+}
+
+// clusterBackendProblems lists the settings which keep state on the local
+// node, so that nodes of a cluster would not see each other's sessions, cache
+// entries, queued items or index entries
+func clusterBackendProblems() []string {
+	var problems []string
+
+	switch provider := Cfg.Section("session").Key("PROVIDER").MustString("memory"); provider {
+	case "memory", "file", "nodb":
+		problems = append(problems, fmt.Sprintf("[session] PROVIDER %q is local to the node, use redis, memcache, mysql, postgres or couchbase", provider))
+	}
+
+	if CacheService.Enabled && CacheService.Adapter == "memory" {
+		problems = append(problems, `[cache] ADAPTER "memory" is local to the node, use redis or memcache`)
+	}
+
+	if Queue.Type != "redis" {
+		problems = append(problems, fmt.Sprintf("[queue] TYPE %q is local to the node, use redis", Queue.Type))
+	}
+	for _, sec := range Cfg.Sections() {
+		if !strings.HasPrefix(sec.Name(), "queue.") || !sec.HasKey("TYPE") {
+			continue
+		}
+		if typ := sec.Key("TYPE").String(); typ != "redis" {
+			problems = append(problems, fmt.Sprintf("[%s] TYPE %q is local to the node, use redis", sec.Name(), typ))
+		}
+	}
+
+	if Indexer.IssueType == "bleve" {
+		problems = append(problems, `[indexer] ISSUE_INDEXER_TYPE "bleve" is local to the node, use elasticsearch, meilisearch or db`)
+	}
+	if Indexer.RepoIndexerEnabled && Indexer.RepoType != "elasticsearch" && Indexer.RepoType != "meilisearch" {
+		problems = append(problems, fmt.Sprintf("[indexer] REPO_INDEXER_TYPE %q is local to the node, use elasticsearch, meilisearch or disable REPO_INDEXER_ENABLED", Indexer.RepoType))
+	}
+	return problems
+}
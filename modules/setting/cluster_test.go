@@ -0,0 +1,58 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/ini.v1"
+)
+
+func TestClusterBackendProblems(t *testing.T) {
+	oldCfg, oldCache, oldQueue, oldIndexer := Cfg, CacheService.Cache, Queue, Indexer
+	defer func() {
+		Cfg, CacheService.Cache, Queue, Indexer = oldCfg, oldCache, oldQueue, oldIndexer
+	}()
+
+	var err error
+	Cfg, err = ini.Load([]byte(`
+[session]
+PROVIDER = redis
+
+[queue.mailer]
+TYPE = redis
+`))
+	assert.NoError(t, err)
+	CacheService.Enabled = true
+	CacheService.Adapter = "redis"
+	Queue.Type = "redis"
+	Indexer.IssueType = "elasticsearch"
+	Indexer.RepoIndexerEnabled = false
+	assert.Empty(t, clusterBackendProblems())
+	Indexer.RepoIndexerEnabled = true
+	Indexer.RepoType = "elasticsearch"
+	assert.Empty(t, clusterBackendProblems())
+	Indexer.IssueType = "meilisearch"
+	Indexer.RepoType = "meilisearch"
+	assert.Empty(t, clusterBackendProblems())
+
+	Cfg, err = ini.Load([]byte(`
+[queue.issue_indexer]
+TYPE = level
+`))
+	assert.NoError(t, err)
+	CacheService.Adapter = "memory"
+	Queue.Type = "persistable-channel"
+	Indexer.IssueType = "bleve"
+	Indexer.RepoType = "bleve"
+	problems := clusterBackendProblems()
+	assert.Len(t, problems, 6)
+	assert.Contains(t, problems[0], "[session] PROVIDER")
+	assert.Contains(t, problems[3], "[queue.issue_indexer] TYPE")
+
+	CacheService.Enabled = false
+	assert.Len(t, clusterBackendProblems(), 5)
+}
@@ -0,0 +1,259 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unknwon/com"
+	ini "gopkg.in/ini.v1"
+)
+
+// CustomConfKey is a key of a section of the custom config
+type CustomConfKey struct {
+	Name  string
+	Value string
+	// Secret keys are not shown, their values are only replaced
+	Secret bool
+	// Kind is how the value is validated: bool, int, duration, choice or string
+	Kind    string
+	Choices []string
+}
+
+// CustomConfSection is a section of the custom config
+type CustomConfSection struct {
+	Name string
+	Keys []*CustomConfKey
+	// Reloadable sections are applied by reloading the settings, the others require a restart
+	Reloadable bool
+}
+
+var (
+	customConfNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+
+	// customConfChoices are the values allowed for the keys with a fixed set of values
+	customConfChoices = map[string][]string{
+		"DEFAULT.RUN_MODE":     {"dev", "prod", "test"},
+		"server.PROTOCOL":      {"http", "https", "fcgi", "fcgi+unix", "unix"},
+		"database.DB_TYPE":     {"mysql", "postgres", "mssql", "sqlite3"},
+		"mailer.MAILER_TYPE":   {"smtp", "sendmail", "dummy"},
+		"log.LEVEL":            {"Trace", "Debug", "Info", "Warn", "Error", "Critical", "None"},
+		"log.ROUTER_LOG_LEVEL": {"Trace", "Debug", "Info", "Warn", "Error", "Critical", "None"},
+	}
+
+	customConfSecrets = []string{"PASSWD", "PASSWORD", "SECRET", "TOKEN"}
+
+	customConfMutex sync.Mutex
+)
+
+// isCustomConfSecret returns if the value of a key must not be shown
+func isCustomConfSecret(key string) bool {
+	key = strings.ToUpper(key)
+	for _, secret := range customConfSecrets {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// customConfKind returns how the values of a key are validated, from its current value
+func customConfKind(section, key, value string) (string, []string) {
+	if strings.HasPrefix(section, "log.") && key == "LEVEL" {
+		section = "log"
+	}
+	if choices, ok := customConfChoices[section+"."+key]; ok {
+		return "choice", choices
+	}
+	switch strings.ToLower(value) {
+	case "true", "false":
+		return "bool", nil
+	}
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return "int", nil
+	}
+	if _, err := time.ParseDuration(value); err == nil {
+		return "duration", nil
+	}
+	return "string", nil
+}
+
+// ValidateCustomConfValue checks that a value can be used for a key of the custom config
+func ValidateCustomConfValue(key *CustomConfKey, value string) error {
+	var err error
+	switch key.Kind {
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "int":
+		// zero is also used for the durations
+		if _, err = strconv.ParseInt(value, 10, 64); err != nil {
+			if _, durationErr := time.ParseDuration(value); durationErr == nil {
+				err = nil
+			}
+		}
+	case "duration":
+		_, err = time.ParseDuration(value)
+	case "choice":
+		for _, choice := range key.Choices {
+			if strings.EqualFold(choice, value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s", key.Name, strings.Join(key.Choices, ", "))
+	}
+	if err != nil {
+		return fmt.Errorf("%s must be a %s: %v", key.Name, key.Kind, err)
+	}
+	return nil
+}
+
+// customConfChoice returns the choice matching the value of a key, the unknown values are kept
+// as the first choice so that they are not changed unintentionally
+func customConfChoice(key *CustomConfKey) {
+	for _, choice := range key.Choices {
+		if strings.EqualFold(choice, key.Value) {
+			key.Value = choice
+			return
+		}
+	}
+	key.Choices = append([]string{key.Value}, key.Choices...)
+}
+
+// loadCustomConfForEdit reads the custom config with its comments, or an empty config if it does not exist
+func loadCustomConfForEdit() (*ini.File, error) {
+	if !com.IsFile(CustomConf) {
+		return ini.Empty(), nil
+	}
+	return loadCustomConf()
+}
+
+func toCustomConfSection(sec *ini.Section) *CustomConfSection {
+	section := &CustomConfSection{
+		Name:       sec.Name(),
+		Reloadable: reloadableGroup(sec.Name()) != nil,
+	}
+	for _, k := range sec.Keys() {
+		key := &CustomConfKey{
+			Name:   k.Name(),
+			Value:  k.Value(),
+			Secret: isCustomConfSecret(k.Name()),
+		}
+		key.Kind, key.Choices = customConfKind(sec.Name(), k.Name(), k.Value())
+		switch {
+		case key.Secret:
+			key.Value = ""
+		case key.Kind == "bool":
+			key.Value = strings.ToLower(key.Value)
+		case key.Kind == "choice":
+			customConfChoice(key)
+		}
+		section.Keys = append(section.Keys, key)
+	}
+	return section
+}
+
+// GetCustomConfSections returns the sections of the custom config
+func GetCustomConfSections() ([]*CustomConfSection, error) {
+	cfg, err := loadCustomConfForEdit()
+	if err != nil {
+		return nil, err
+	}
+	sections := make([]*CustomConfSection, 0, len(cfg.Sections()))
+	for _, sec := range cfg.Sections() {
+		if sec.Name() == ini.DefaultSection && len(sec.Keys()) == 0 {
+			continue
+		}
+		sections = append(sections, toCustomConfSection(sec))
+	}
+	return sections, nil
+}
+
+// GetCustomConfSection returns a section of the custom config, which is empty if it does not exist yet
+func GetCustomConfSection(name string) (*CustomConfSection, error) {
+	if !customConfNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid section name %q", name)
+	}
+	cfg, err := loadCustomConfForEdit()
+	if err != nil {
+		return nil, err
+	}
+	sec, err := cfg.GetSection(name)
+	if err != nil {
+		return &CustomConfSection{Name: name, Reloadable: reloadableGroup(name) != nil}, nil
+	}
+	return toCustomConfSection(sec), nil
+}
+
+// CustomConfChanges are the changes of a section of the custom config
+type CustomConfChanges struct {
+	// Set are the new values of the keys, the empty values of the secret keys are left unchanged
+	Set map[string]string
+	// Delete are the keys to delete
+	Delete []string
+}
+
+// SaveCustomConfSection validates and writes the changes of a section to the custom config.
+// The changes are applied by reloading the settings or by restarting.
+func SaveCustomConfSection(name string, changes *CustomConfChanges) error {
+	customConfMutex.Lock()
+	defer customConfMutex.Unlock()
+
+	section, err := GetCustomConfSection(name)
+	if err != nil {
+		return err
+	}
+	keys := make(map[string]*CustomConfKey, len(section.Keys))
+	for _, key := range section.Keys {
+		keys[key.Name] = key
+	}
+
+	cfg, err := loadCustomConfForEdit()
+	if err != nil {
+		return err
+	}
+	sec := cfg.Section(name)
+	for _, key := range changes.Delete {
+		sec.DeleteKey(key)
+	}
+
+	keyNames := make([]string, 0, len(changes.Set))
+	for keyName := range changes.Set {
+		keyNames = append(keyNames, keyName)
+	}
+	sort.Strings(keyNames)
+	for _, keyName := range keyNames {
+		value := strings.TrimSpace(changes.Set[keyName])
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%s must be a single line", keyName)
+		}
+		key, has := keys[keyName]
+		if !has {
+			if !customConfNamePattern.MatchString(keyName) {
+				return fmt.Errorf("invalid key name %q", keyName)
+			}
+			key = &CustomConfKey{Name: keyName, Secret: isCustomConfSecret(keyName)}
+			key.Kind, key.Choices = customConfKind(name, keyName, value)
+		} else if (key.Secret && value == "") || value == key.Value {
+			continue
+		}
+		if err := ValidateCustomConfValue(key, value); err != nil {
+			return err
+		}
+		sec.Key(keyName).SetValue(value)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(CustomConf), os.ModePerm); err != nil {
+		return err
+	}
+	return cfg.SaveTo(CustomConf)
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveCustomConfSection(t *testing.T) {
+	oldConf := CustomConf
+	defer func() {
+		CustomConf = oldConf
+	}()
+
+	dir, err := ioutil.TempDir("", "custom-conf")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	CustomConf = filepath.Join(dir, "app.ini")
+	assert.NoError(t, ioutil.WriteFile(CustomConf, []byte(`
+; the name of the instance
+APP_NAME = Gitea
+
+[server]
+PROTOCOL = HTTP
+HTTP_PORT = 3000
+GRACEFUL_HAMMER_TIME = 60s
+OFFLINE_MODE = True
+
+[mailer]
+ENABLED = false
+PASSWD = secret
+`), 0600))
+
+	sections, err := GetCustomConfSections()
+	assert.NoError(t, err)
+	if assert.Len(t, sections, 3) {
+		assert.Equal(t, "DEFAULT", sections[0].Name)
+		assert.False(t, sections[1].Reloadable)
+		assert.True(t, sections[2].Reloadable)
+	}
+
+	server, err := GetCustomConfSection("server")
+	assert.NoError(t, err)
+	kinds := make(map[string]string)
+	for _, key := range server.Keys {
+		kinds[key.Name] = key.Kind
+	}
+	assert.Equal(t, map[string]string{
+		"PROTOCOL":             "choice",
+		"HTTP_PORT":            "int",
+		"GRACEFUL_HAMMER_TIME": "duration",
+		"OFFLINE_MODE":         "bool",
+	}, kinds)
+	assert.Equal(t, "http", server.Keys[0].Value)
+	assert.Equal(t, "true", server.Keys[3].Value)
+
+	mailer, err := GetCustomConfSection("mailer")
+	assert.NoError(t, err)
+	assert.True(t, mailer.Keys[1].Secret)
+	assert.Empty(t, mailer.Keys[1].Value)
+
+	for _, set := range []map[string]string{
+		{"HTTP_PORT": "port"},
+		{"GRACEFUL_HAMMER_TIME": "1 minute"},
+		{"OFFLINE_MODE": "yes"},
+		{"PROTOCOL": "ftp"},
+		{"NEW KEY": "value"},
+		{"HTTP_ADDR": "0.0.0.0\n[security]"},
+	} {
+		assert.Error(t, SaveCustomConfSection("server", &CustomConfChanges{Set: set}))
+	}
+	_, err = GetCustomConfSection("server]")
+	assert.Error(t, err)
+
+	assert.NoError(t, SaveCustomConfSection("server", &CustomConfChanges{
+		Set: map[string]string{
+			"HTTP_PORT":            "3001",
+			"GRACEFUL_HAMMER_TIME": "0",
+			"OFFLINE_MODE":         "true",
+			"HTTP_ADDR":            "127.0.0.1",
+		},
+		Delete: []string{"PROTOCOL"},
+	}))
+	assert.NoError(t, SaveCustomConfSection("mailer", &CustomConfChanges{
+		Set: map[string]string{"PASSWD": "", "ENABLED": "true"},
+	}))
+	assert.NoError(t, SaveCustomConfSection("webhook", &CustomConfChanges{
+		Set: map[string]string{"DELIVER_TIMEOUT": "10"},
+	}))
+
+	cfg, err := loadCustomConf()
+	assert.NoError(t, err)
+	assert.Equal(t, "the name of the instance", cfg.Section("").Key("APP_NAME").Comment[2:])
+	assert.False(t, cfg.Section("server").HasKey("PROTOCOL"))
+	assert.Equal(t, "3001", cfg.Section("server").Key("HTTP_PORT").String())
+	assert.Equal(t, "0", cfg.Section("server").Key("GRACEFUL_HAMMER_TIME").String())
+	// unchanged values keep their spelling
+	assert.Equal(t, "True", cfg.Section("server").Key("OFFLINE_MODE").String())
+	assert.Equal(t, "127.0.0.1", cfg.Section("server").Key("HTTP_ADDR").String())
+	assert.Equal(t, "secret", cfg.Section("mailer").Key("PASSWD").String())
+	assert.Equal(t, "true", cfg.Section("mailer").Key("ENABLED").String())
+	assert.Equal(t, "10", cfg.Section("webhook").Key("DELIVER_TIMEOUT").String())
+}
@@ -46,6 +46,11 @@ var (
 		MaxOpenConns      int
 		ConnMaxLifetime   time.Duration
 		IterateBufferSize int
+		// ReplicaHosts are the hosts of read-only replicas of the database
+		ReplicaHosts         []string
+		ReplicaUser          string
+		ReplicaPasswd        string
+		ReplicaCheckInterval time.Duration
 	}{
 		Timeout: 500,
 	}
@@ -93,10 +98,40 @@ func InitDBConfig() {
 	Database.LogSQL = sec.Key("LOG_SQL").MustBool(true)
 	Database.DBConnectRetries = sec.Key("DB_RETRIES").MustInt(10)
 	Database.DBConnectBackoff = sec.Key("DB_RETRY_BACKOFF").MustDuration(3 * time.Second)
+
+	Database.ReplicaHosts = nil
+	for _, host := range strings.Split(sec.Key("REPLICA_HOSTS").String(), ",") {
+		if host = strings.TrimSpace(host); len(host) > 0 {
+			Database.ReplicaHosts = append(Database.ReplicaHosts, host)
+		}
+	}
+	Database.ReplicaUser = sec.Key("REPLICA_USER").MustString(Database.User)
+	Database.ReplicaPasswd = sec.Key("REPLICA_PASSWD").MustString(Database.Passwd)
+	Database.ReplicaCheckInterval = sec.Key("REPLICA_CHECK_INTERVAL").MustDuration(10 * time.Second)
 }
 
 // DBConnStr returns database connection string
 func DBConnStr() (string, error) {
+	return dbConnStr(Database.Host, Database.User, Database.Passwd)
+}
+
+// DBReplicaConnStrs returns the connection strings of the replicas of the database
+func DBReplicaConnStrs() ([]string, error) {
+	if len(Database.ReplicaHosts) > 0 && Database.UseSQLite3 {
+		return nil, errors.New("SQLite3 does not support replicas")
+	}
+	connStrs := make([]string, 0, len(Database.ReplicaHosts))
+	for _, host := range Database.ReplicaHosts {
+		connStr, err := dbConnStr(host, Database.ReplicaUser, Database.ReplicaPasswd)
+		if err != nil {
+			return nil, err
+		}
+		connStrs = append(connStrs, connStr)
+	}
+	return connStrs, nil
+}
+
+func dbConnStr(host, user, passwd string) (string, error) {
 	connStr := ""
 	var Param = "?"
 	if strings.Contains(Database.Name, Param) {
@@ -105,7 +140,7 @@ func DBConnStr() (string, error) {
 	switch Database.Type {
 	case "mysql":
 		connType := "tcp"
-		if len(Database.Host) > 0 && Database.Host[0] == '/' { // looks like a unix socket
+		if len(host) > 0 && host[0] == '/' { // looks like a unix socket
 			connType = "unix"
 		}
 		tls := Database.SSLMode
@@ -113,12 +148,12 @@ func DBConnStr() (string, error) {
 			tls = "false"
 		}
 		connStr = fmt.Sprintf("%s:%s@%s(%s)/%s%scharset=%s&parseTime=true&tls=%s",
-			Database.User, Database.Passwd, connType, Database.Host, Database.Name, Param, Database.Charset, tls)
+			user, passwd, connType, host, Database.Name, Param, Database.Charset, tls)
 	case "postgres":
-		connStr = getPostgreSQLConnectionString(Database.Host, Database.User, Database.Passwd, Database.Name, Param, Database.SSLMode)
+		connStr = getPostgreSQLConnectionString(host, user, passwd, Database.Name, Param, Database.SSLMode)
 	case "mssql":
-		host, port := ParseMSSQLHostPort(Database.Host)
-		connStr = fmt.Sprintf("server=%s; port=%s; database=%s; user id=%s; password=%s;", host, port, Database.Name, Database.User, Database.Passwd)
+		mssqlHost, port := ParseMSSQLHostPort(host)
+		connStr = fmt.Sprintf("server=%s; port=%s; database=%s; user id=%s; password=%s;", mssqlHost, port, Database.Name, user, passwd)
 	case "sqlite3":
 		if !EnableSQLite3 {
 			return "", errors.New("this binary version does not build support for SQLite3")
@@ -0,0 +1,32 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"time"
+)
+
+var (
+	// DeployToken settings
+	DeployToken = struct {
+		Enabled    bool
+		DefaultTTL time.Duration
+		MaxTTL     time.Duration
+	}{
+		Enabled:    true,
+		DefaultTTL: time.Hour,
+		MaxTTL:     24 * time.Hour,
+	}
+)
+
+func newDeployTokenService() {
+	sec := Cfg.Section("deploy_token")
+	DeployToken.Enabled = sec.Key("ENABLED").MustBool(true)
+	DeployToken.DefaultTTL = sec.Key("DEFAULT_TTL").MustDuration(time.Hour)
+	DeployToken.MaxTTL = sec.Key("MAX_TTL").MustDuration(24 * time.Hour)
+	if DeployToken.MaxTTL < DeployToken.DefaultTTL {
+		DeployToken.MaxTTL = DeployToken.DefaultTTL
+	}
+}
@@ -5,6 +5,8 @@
 package setting
 
 import (
+	"path"
+	"path/filepath"
 	"time"
 
 	"code.gitea.io/gitea/modules/git"
@@ -21,11 +23,13 @@ var (
 		MaxGitDiffLines           int
 		MaxGitDiffLineCharacters  int
 		MaxGitDiffFiles           int
+		MaxGitDiffLazyLines       int
 		VerbosePush               bool
 		VerbosePushDelay          time.Duration
 		GCArgs                    []string `ini:"GC_ARGS" delim:" "`
 		EnableAutoGitWireProtocol bool
 		PullRequestPushMessage    bool
+		EnableTransportStats      bool
 		Timeout                   struct {
 			Default int
 			Migrate int
@@ -34,16 +38,33 @@ var (
 			Pull    int
 			GC      int `ini:"GC"`
 		} `ini:"git.timeout"`
+		HTTP struct {
+			MaxConcurrent        int
+			MaxConcurrentPerUser int
+			MaxConcurrentPerRepo int
+			QueueTimeout         time.Duration
+			MaxCPUTime           time.Duration `ini:"MAX_CPU_TIME"`
+			MaxMemory            int64
+		} `ini:"git.http"`
+		PackCache struct {
+			Enabled      bool
+			Path         string
+			TTL          time.Duration `ini:"TTL"`
+			MinRequests  int
+			MaxEntrySize int64
+		} `ini:"git.pack_cache"`
 	}{
 		DisableDiffHighlight:      false,
 		MaxGitDiffLines:           1000,
 		MaxGitDiffLineCharacters:  5000,
 		MaxGitDiffFiles:           100,
+		MaxGitDiffLazyLines:       300,
 		VerbosePush:               true,
 		VerbosePushDelay:          5 * time.Second,
 		GCArgs:                    []string{},
 		EnableAutoGitWireProtocol: true,
 		PullRequestPushMessage:    true,
+		EnableTransportStats:      true,
 		Timeout: struct {
 			Default int
 			Migrate int
@@ -59,9 +80,34 @@ var (
 			Pull:    300,
 			GC:      60,
 		},
+		HTTP: struct {
+			MaxConcurrent        int
+			MaxConcurrentPerUser int
+			MaxConcurrentPerRepo int
+			QueueTimeout         time.Duration
+			MaxCPUTime           time.Duration `ini:"MAX_CPU_TIME"`
+			MaxMemory            int64
+		}{
+			QueueTimeout: 30 * time.Second,
+		},
+		PackCache: struct {
+			Enabled      bool
+			Path         string
+			TTL          time.Duration `ini:"TTL"`
+			MinRequests  int
+			MaxEntrySize int64
+		}{
+			Enabled:      false,
+			TTL:          time.Hour,
+			MinRequests:  2,
+			MaxEntrySize: 1024 * 1024 * 1024,
+		},
 	}
 )
 
+// gitHTTPDefaults are the default limits of the git HTTP transfers, used when they are reloaded
+var gitHTTPDefaults = Git.HTTP
+
 func newGit() {
 	if err := Cfg.Section("git").MapTo(&Git); err != nil {
 		log.Fatal("Failed to map Git settings: %v", err)
@@ -71,6 +117,13 @@ func newGit() {
 	}
 	git.DefaultCommandExecutionTimeout = time.Duration(Git.Timeout.Default) * time.Second
 
+	if Git.PackCache.Path == "" {
+		Git.PackCache.Path = path.Join(AppDataPath, "pack-cache")
+	}
+	if !filepath.IsAbs(Git.PackCache.Path) {
+		Git.PackCache.Path = filepath.Join(AppWorkPath, Git.PackCache.Path)
+	}
+
 	binVersion, err := git.BinVersion()
 	if err != nil {
 		log.Fatal("Error retrieving git version: %v", err)
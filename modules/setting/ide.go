@@ -0,0 +1,78 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// IDEIntegration defines a launcher opening a repository in an IDE, configured in ini
+type IDEIntegration struct {
+	Name                string
+	DisplayName         string
+	URL                 string
+	RequireDevContainer bool
+}
+
+var (
+	// IDE settings
+	IDE = struct {
+		Enabled      bool
+		Integrations []*IDEIntegration
+	}{
+		Enabled: false,
+	}
+
+	// defaultIDEIntegrations are the integrations which do not need to be configured in their
+	// own section
+	defaultIDEIntegrations = map[string]IDEIntegration{
+		"gitpod": {
+			DisplayName: "Gitpod",
+			URL:         "https://gitpod.io/#{web_url}",
+		},
+		"vscode": {
+			DisplayName:         "VS Code",
+			URL:                 "vscode://ms-vscode-remote.remote-containers/cloneInVolume?url={clone_url_escaped}",
+			RequireDevContainer: true,
+		},
+		"jetbrains": {
+			DisplayName: "JetBrains",
+			URL:         "jetbrains://idea/checkout/git?checkout.repo={clone_url_escaped}&idea.required.plugins.id=Git4Idea",
+		},
+	}
+)
+
+func newIDEService() {
+	sec := Cfg.Section("ide")
+	IDE.Enabled = sec.Key("ENABLED").MustBool(false)
+	IDE.Integrations = nil
+	for _, name := range strings.Split(sec.Key("INTEGRATIONS").MustString("gitpod,vscode,jetbrains"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		integration := defaultIDEIntegrations[name]
+		integration.Name = name
+
+		sub := Cfg.Section("ide." + name)
+		integration.DisplayName = sub.Key("NAME").MustString(integration.DisplayName)
+		integration.URL = sub.Key("URL").MustString(integration.URL)
+		integration.RequireDevContainer = sub.Key("REQUIRE_DEVCONTAINER").MustBool(integration.RequireDevContainer)
+		if integration.URL == "" {
+			log.Warn("URL of IDE integration %s is empty, it is ignored", name)
+			continue
+		}
+		if integration.DisplayName == "" {
+			integration.DisplayName = name
+		}
+		IDE.Integrations = append(IDE.Integrations, &integration)
+	}
+	if IDE.Enabled && len(IDE.Integrations) == 0 {
+		log.Warn("No IDE integration is configured, open in IDE buttons are disabled")
+		IDE.Enabled = false
+	}
+}
@@ -29,6 +29,8 @@ var (
 		IssuePath             string
 		IssueConnStr          string
 		IssueIndexerName      string
+		IssueShards           int
+		IssueReplicas         int
 		IssueQueueType        string
 		IssueQueueDir         string
 		IssueQueueConnStr     string
@@ -36,7 +38,12 @@ var (
 		StartupTimeout        time.Duration
 
 		RepoIndexerEnabled bool
+		RepoType           string
 		RepoPath           string
+		RepoConnStr        string
+		RepoIndexerName    string
+		RepoShards         int
+		RepoReplicas       int
 		UpdateQueueLength  int
 		MaxIndexerFileSize int64
 		IncludePatterns    []glob.Glob
@@ -52,6 +59,11 @@ var (
 		IssueQueueConnStr:     "",
 		IssueQueueBatchNumber: 20,
 
+		RepoType:        "bleve",
+		RepoPath:        "indexers/repos.bleve",
+		RepoConnStr:     "",
+		RepoIndexerName: "gitea_codes",
+
 		MaxIndexerFileSize: 1024 * 1024,
 		ExcludeVendored:    true,
 	}
@@ -66,6 +78,8 @@ func newIndexerService() {
 	}
 	Indexer.IssueConnStr = sec.Key("ISSUE_INDEXER_CONN_STR").MustString(Indexer.IssueConnStr)
 	Indexer.IssueIndexerName = sec.Key("ISSUE_INDEXER_NAME").MustString(Indexer.IssueIndexerName)
+	Indexer.IssueShards = sec.Key("ISSUE_INDEXER_SHARDS").MustInt(0)
+	Indexer.IssueReplicas = sec.Key("ISSUE_INDEXER_REPLICAS").MustInt(-1)
 
 	Indexer.IssueQueueType = sec.Key("ISSUE_INDEXER_QUEUE_TYPE").MustString(LevelQueueType)
 	Indexer.IssueQueueDir = sec.Key("ISSUE_INDEXER_QUEUE_DIR").MustString(path.Join(AppDataPath, "indexers/issues.queue"))
@@ -73,10 +87,15 @@ func newIndexerService() {
 	Indexer.IssueQueueBatchNumber = sec.Key("ISSUE_INDEXER_QUEUE_BATCH_NUMBER").MustInt(20)
 
 	Indexer.RepoIndexerEnabled = sec.Key("REPO_INDEXER_ENABLED").MustBool(false)
+	Indexer.RepoType = sec.Key("REPO_INDEXER_TYPE").MustString("bleve")
 	Indexer.RepoPath = sec.Key("REPO_INDEXER_PATH").MustString(path.Join(AppDataPath, "indexers/repos.bleve"))
 	if !filepath.IsAbs(Indexer.RepoPath) {
 		Indexer.RepoPath = path.Join(AppWorkPath, Indexer.RepoPath)
 	}
+	Indexer.RepoConnStr = sec.Key("REPO_INDEXER_CONN_STR").MustString("")
+	Indexer.RepoIndexerName = sec.Key("REPO_INDEXER_NAME").MustString("gitea_codes")
+	Indexer.RepoShards = sec.Key("REPO_INDEXER_SHARDS").MustInt(0)
+	Indexer.RepoReplicas = sec.Key("REPO_INDEXER_REPLICAS").MustInt(-1)
 	Indexer.IncludePatterns = IndexerGlobFromString(sec.Key("REPO_INDEXER_INCLUDE").MustString(""))
 	Indexer.ExcludePatterns = IndexerGlobFromString(sec.Key("REPO_INDEXER_EXCLUDE").MustString(""))
 	Indexer.ExcludeVendored = sec.Key("REPO_INDEXER_EXCLUDE_VENDORED").MustBool(true)
@@ -0,0 +1,29 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"time"
+)
+
+var (
+	// IssueTrackerSync settings
+	IssueTrackerSync = struct {
+		Enabled       bool
+		Timeout       time.Duration
+		SkipTLSVerify bool
+	}{
+		Enabled:       true,
+		Timeout:       10 * time.Second,
+		SkipTLSVerify: false,
+	}
+)
+
+func newIssueTrackerSyncService() {
+	sec := Cfg.Section("issue_tracker_sync")
+	IssueTrackerSync.Enabled = sec.Key("ENABLED").MustBool(true)
+	IssueTrackerSync.Timeout = sec.Key("TIMEOUT").MustDuration(10 * time.Second)
+	IssueTrackerSync.SkipTLSVerify = sec.Key("SKIP_TLS_VERIFY").MustBool(false)
+}
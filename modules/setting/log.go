@@ -161,6 +161,7 @@ func generateLogConfig(sec *ini.Section, name string, defaults defaultLogOptions
 		"prefix":          prefix,
 		"flags":           flags,
 		"stacktraceLevel": stacktraceLevel.String(),
+		"json":            sec.Key("FORMAT").In("text", []string{"text", "json"}) == "json",
 	}
 
 	// Generate log configuration.
@@ -293,6 +294,16 @@ func newRouterLogService() {
 	}
 }
 
+// newLogRootSettings reads the levels and the root path of the logs
+func newLogRootSettings() {
+	LogLevel = getLogLevel(Cfg.Section("log"), "LEVEL", "Info")
+	StacktraceLogLevel = getStacktraceLogLevel(Cfg.Section("log"), "STACKTRACE_LEVEL", "None")
+	LogRootPath = Cfg.Section("log").Key("ROOT_PATH").MustString(path.Join(AppWorkPath, "log"))
+	forcePathSeparator(LogRootPath)
+	RedirectMacaronLog = Cfg.Section("log").Key("REDIRECT_MACARON_LOG").MustBool(false)
+	RouterLogLevel = log.FromString(Cfg.Section("log").Key("ROUTER_LOG_LEVEL").MustString("Info"))
+}
+
 func newLogService() {
 	log.Info("Gitea v%s%s", AppVer, AppBuiltWith)
 
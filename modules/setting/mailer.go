@@ -5,6 +5,7 @@
 package setting
 
 import (
+	"fmt"
 	"net/mail"
 	"time"
 
@@ -39,6 +40,20 @@ type Mailer struct {
 	SendmailPath    string
 	SendmailArgs    []string
 	SendmailTimeout time.Duration
+
+	// Delivery of the queued mails
+	MaxRetries      int
+	RetryBackoff    time.Duration
+	DomainRateLimit int
+
+	// Bounces
+	SoftBounceLimit      int
+	BounceWebhookToken   string
+	BounceIMAPHost       string
+	BounceIMAPUser       string
+	BounceIMAPPasswd     string
+	BounceIMAPMailbox    string
+	BounceIMAPSkipVerify bool
 }
 
 var (
@@ -47,13 +62,22 @@ var (
 )
 
 func newMailService() {
+	mailer, err := parseMailService()
+	if err != nil {
+		log.Fatal("%v", err)
+	}
+	MailService = mailer
+}
+
+// parseMailService returns the mail service of the config, or nil if it is disabled
+func parseMailService() (*Mailer, error) {
 	sec := Cfg.Section("mailer")
 	// Check mailer setting.
 	if !sec.Key("ENABLED").MustBool() {
-		return
+		return nil, nil
 	}
 
-	MailService = &Mailer{
+	mailer := &Mailer{
 		QueueLength:     sec.Key("SEND_BUFFER_LEN").MustInt(100),
 		Name:            sec.Key("NAME").MustString(AppName),
 		SendAsPlainText: sec.Key("SEND_AS_PLAIN_TEXT").MustBool(false),
@@ -73,40 +97,53 @@ func newMailService() {
 
 		SendmailPath:    sec.Key("SENDMAIL_PATH").MustString("sendmail"),
 		SendmailTimeout: sec.Key("SENDMAIL_TIMEOUT").MustDuration(5 * time.Minute),
+
+		MaxRetries:      sec.Key("SEND_MAX_RETRIES").MustInt(5),
+		RetryBackoff:    sec.Key("SEND_RETRY_BACKOFF").MustDuration(time.Minute),
+		DomainRateLimit: sec.Key("RATE_LIMIT_PER_DOMAIN").MustInt(0),
+
+		SoftBounceLimit:      sec.Key("SOFT_BOUNCE_LIMIT").MustInt(3),
+		BounceWebhookToken:   sec.Key("BOUNCE_WEBHOOK_TOKEN").String(),
+		BounceIMAPHost:       sec.Key("BOUNCE_IMAP_HOST").String(),
+		BounceIMAPUser:       sec.Key("BOUNCE_IMAP_USER").String(),
+		BounceIMAPPasswd:     sec.Key("BOUNCE_IMAP_PASSWD").String(),
+		BounceIMAPMailbox:    sec.Key("BOUNCE_IMAP_MAILBOX").MustString("INBOX"),
+		BounceIMAPSkipVerify: sec.Key("BOUNCE_IMAP_SKIP_VERIFY").MustBool(),
 	}
-	MailService.From = sec.Key("FROM").MustString(MailService.User)
+	mailer.From = sec.Key("FROM").MustString(mailer.User)
 
 	if sec.HasKey("ENABLE_HTML_ALTERNATIVE") {
 		log.Warn("ENABLE_HTML_ALTERNATIVE is deprecated, use SEND_AS_PLAIN_TEXT")
-		MailService.SendAsPlainText = !sec.Key("ENABLE_HTML_ALTERNATIVE").MustBool(false)
+		mailer.SendAsPlainText = !sec.Key("ENABLE_HTML_ALTERNATIVE").MustBool(false)
 	}
 
 	if sec.HasKey("USE_SENDMAIL") {
 		log.Warn("USE_SENDMAIL is deprecated, use MAILER_TYPE=sendmail")
-		if MailService.MailerType == "" && sec.Key("USE_SENDMAIL").MustBool(false) {
-			MailService.MailerType = "sendmail"
+		if mailer.MailerType == "" && sec.Key("USE_SENDMAIL").MustBool(false) {
+			mailer.MailerType = "sendmail"
 		}
 	}
 
-	parsed, err := mail.ParseAddress(MailService.From)
+	parsed, err := mail.ParseAddress(mailer.From)
 	if err != nil {
-		log.Fatal("Invalid mailer.FROM (%s): %v", MailService.From, err)
+		return nil, fmt.Errorf("invalid mailer.FROM (%s): %v", mailer.From, err)
 	}
-	MailService.FromName = parsed.Name
-	MailService.FromEmail = parsed.Address
+	mailer.FromName = parsed.Name
+	mailer.FromEmail = parsed.Address
 
-	if MailService.MailerType == "" {
-		MailService.MailerType = "smtp"
+	if mailer.MailerType == "" {
+		mailer.MailerType = "smtp"
 	}
 
-	if MailService.MailerType == "sendmail" {
-		MailService.SendmailArgs, err = shellquote.Split(sec.Key("SENDMAIL_ARGS").String())
+	if mailer.MailerType == "sendmail" {
+		mailer.SendmailArgs, err = shellquote.Split(sec.Key("SENDMAIL_ARGS").String())
 		if err != nil {
 			log.Error("Failed to parse Sendmail args: %s with error %v", CustomConf, err)
 		}
 	}
 
 	log.Info("Mail Service Enabled")
+	return mailer, nil
 }
 
 func newRegisterMailService() {
@@ -7,6 +7,7 @@ package setting
 import (
 	"regexp"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 
@@ -26,6 +27,13 @@ type MarkupParser struct {
 	Command        string
 	FileExtensions []string
 	IsInputFile    bool
+	// Version is part of the key of cached output, so changing it
+	// invalidates everything the renderer produced before.
+	Version    string
+	Timeout    time.Duration
+	MaxMemory  int64
+	MaxCPUTime time.Duration
+	CacheTTL   time.Duration
 }
 
 // MarkupSanitizerRule defines the policy for whitelisting attributes on
@@ -129,5 +137,10 @@ func newMarkupRenderer(name string, sec *ini.Section) {
 		FileExtensions: exts,
 		Command:        command,
 		IsInputFile:    sec.Key("IS_INPUT_FILE").MustBool(false),
+		Version:        sec.Key("RENDER_VERSION").MustString(""),
+		Timeout:        sec.Key("RENDER_TIMEOUT").MustDuration(30 * time.Second),
+		MaxMemory:      sec.Key("MAX_MEMORY").MustInt64(0),
+		MaxCPUTime:     sec.Key("MAX_CPU_TIME").MustDuration(20 * time.Second),
+		CacheTTL:       sec.Key("CACHE_TTL").MustDuration(24 * time.Hour),
 	})
 }
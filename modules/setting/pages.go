@@ -0,0 +1,45 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+var (
+	// Pages settings
+	Pages = struct {
+		Enabled             bool
+		Domain              string
+		DefaultBranch       string
+		EnableCustomDomains bool
+		CacheMaxAge         time.Duration
+	}{
+		Enabled:             false,
+		Domain:              "",
+		DefaultBranch:       "gh-pages",
+		EnableCustomDomains: false,
+		CacheMaxAge:         10 * time.Minute,
+	}
+)
+
+func newPagesService() {
+	sec := Cfg.Section("pages")
+	Pages.Enabled = sec.Key("ENABLED").MustBool(false)
+	Pages.Domain = strings.ToLower(strings.Trim(sec.Key("DOMAIN").MustString(""), ". "))
+	Pages.DefaultBranch = sec.Key("DEFAULT_BRANCH").MustString("gh-pages")
+	Pages.EnableCustomDomains = sec.Key("ENABLE_CUSTOM_DOMAINS").MustBool(false)
+	Pages.CacheMaxAge = sec.Key("CACHE_MAX_AGE").MustDuration(10 * time.Minute)
+	if Pages.Enabled && Pages.Domain == "" && Pages.EnableCustomDomains {
+		log.Warn("Pages custom domains require a pages DOMAIN, they are disabled")
+		Pages.EnableCustomDomains = false
+	}
+	if Pages.Enabled {
+		log.Info("Pages Service Enabled")
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/modules/log"
+
+	ini "gopkg.in/ini.v1"
+)
+
+// ReloadableSettings is a group of settings which can be reloaded from the custom config
+// without restarting
+type ReloadableSettings struct {
+	Name string
+	// Sections are the sections of the group, a name ending with ".*" matches the subsections
+	Sections []string
+	// StaticKeys are the keys of the sections which are not reloaded, they still require a restart
+	StaticKeys []string
+	load       func() error
+	hooks      []func()
+}
+
+// SettingsReloadResult is the result of reloading the settings
+type SettingsReloadResult struct {
+	// Reloaded are the names of the groups of settings which were reloaded
+	Reloaded []string
+	// RestartRequired are the changed sections which can only be applied by a restart
+	RestartRequired []string
+}
+
+var (
+	// ReloadOnSIGHUP makes SIGHUP reload the hot-reloadable settings instead of restarting gracefully
+	ReloadOnSIGHUP bool
+
+	reloadableSettings = []*ReloadableSettings{
+		{Name: "log", Sections: []string{"log", "log.*"}, load: reloadLogSettings},
+		{Name: "mailer", Sections: []string{"mailer"}, StaticKeys: []string{"SEND_BUFFER_LEN"}, load: reloadMailService},
+		{Name: "webhook", Sections: []string{"webhook"}, StaticKeys: []string{"QUEUE_LENGTH"}, load: reloadWebhookService},
+		{Name: "git.http", Sections: []string{"git.http"}, load: reloadGitHTTPSettings},
+	}
+
+	reloadMutex sync.Mutex
+	// loadedConf is the custom config as it was last loaded, without the defaults added to Cfg
+	loadedConf *ini.File
+)
+
+// GetReloadableSettings returns the groups of settings which can be reloaded without restarting
+func GetReloadableSettings() []*ReloadableSettings {
+	return reloadableSettings
+}
+
+// OnSettingsReloaded registers a function called after a group of settings was reloaded,
+// e.g. to recreate the clients using them
+func OnSettingsReloaded(name string, fn func()) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+	for _, group := range reloadableSettings {
+		if group.Name == name {
+			group.hooks = append(group.hooks, fn)
+			return
+		}
+	}
+	log.Error("OnSettingsReloaded: unknown group of settings %s", name)
+}
+
+// reloadableGroup returns the group of settings of a section
+func reloadableGroup(section string) *ReloadableSettings {
+	for _, group := range reloadableSettings {
+		for _, name := range group.Sections {
+			if name == section || (strings.HasSuffix(name, ".*") && strings.HasPrefix(section, strings.TrimSuffix(name, "*"))) {
+				return group
+			}
+		}
+	}
+	return nil
+}
+
+// loadCustomConf reads the custom config without changing Cfg
+func loadCustomConf() (*ini.File, error) {
+	cfg := ini.Empty()
+	if err := cfg.Append(CustomConf); err != nil {
+		return nil, fmt.Errorf("failed to load custom conf '%s': %v", CustomConf, err)
+	}
+	cfg.NameMapper = ini.SnackCase
+	return cfg, nil
+}
+
+// sectionKeys returns the keys of a section of a config, or nil if it does not exist
+func sectionKeys(cfg *ini.File, name string) map[string]string {
+	sec, err := cfg.GetSection(name)
+	if err != nil {
+		return nil
+	}
+	return sec.KeysHash()
+}
+
+// replaceSection replaces a section of a config by the one of another config
+func replaceSection(cfg *ini.File, name string, keys map[string]string) error {
+	cfg.DeleteSection(name)
+	if keys == nil {
+		return nil
+	}
+	sec, err := cfg.NewSection(name)
+	if err != nil {
+		return err
+	}
+	for key, value := range keys {
+		if _, err := sec.NewKey(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReloadSettings reads the custom config again and reloads the groups of settings whose
+// sections changed. The other changed sections are reported as requiring a restart.
+func ReloadSettings() (*SettingsReloadResult, error) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	cfg, err := loadCustomConf()
+	if err != nil {
+		return nil, err
+	}
+	if loadedConf == nil {
+		loadedConf = ini.Empty()
+	}
+
+	names := make(map[string]bool)
+	for _, name := range append(loadedConf.SectionStrings(), cfg.SectionStrings()...) {
+		names[name] = true
+	}
+
+	result := &SettingsReloadResult{}
+	changed := make(map[*ReloadableSettings][]string)
+	for name := range names {
+		if reflect.DeepEqual(sectionKeys(loadedConf, name), sectionKeys(cfg, name)) {
+			continue
+		}
+		group := reloadableGroup(name)
+		if group == nil {
+			result.RestartRequired = append(result.RestartRequired, name)
+			continue
+		}
+		changed[group] = append(changed[group], name)
+	}
+
+	for _, group := range reloadableSettings {
+		if len(changed[group]) == 0 {
+			continue
+		}
+		for _, name := range changed[group] {
+			if err := replaceSection(Cfg, name, sectionKeys(cfg, name)); err != nil {
+				return nil, err
+			}
+		}
+		if err := group.load(); err != nil {
+			return nil, fmt.Errorf("reload %s settings: %v", group.Name, err)
+		}
+		for _, hook := range group.hooks {
+			hook()
+		}
+		// the sections which failed to load are still changed on the next reload
+		for _, name := range changed[group] {
+			if err := replaceSection(loadedConf, name, sectionKeys(cfg, name)); err != nil {
+				return nil, err
+			}
+		}
+		result.Reloaded = append(result.Reloaded, group.Name)
+		log.Info("Reloaded the %s settings", group.Name)
+	}
+	sort.Strings(result.RestartRequired)
+	return result, nil
+}
+
+func reloadLogSettings() error {
+	newLogRootSettings()
+	NewLogServices(false)
+	return nil
+}
+
+func reloadMailService() error {
+	mailer, err := parseMailService()
+	if err != nil {
+		return err
+	}
+	MailService = mailer
+	Service.RegisterEmailConfirm = false
+	Service.EnableNotifyMail = false
+	newRegisterMailService()
+	newNotifyMailService()
+	return nil
+}
+
+func reloadWebhookService() error {
+	newWebhookService()
+	return nil
+}
+
+func reloadGitHTTPSettings() error {
+	http := gitHTTPDefaults
+	if err := Cfg.Section("git.http").MapTo(&http); err != nil {
+		return err
+	}
+	Git.HTTP = http
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadSettings(t *testing.T) {
+	oldCfg, oldConf, oldLoadedConf, oldWebhook, oldGitHTTP, oldMailService := Cfg, CustomConf, loadedConf, Webhook, Git.HTTP, MailService
+	defer func() {
+		Cfg, CustomConf, loadedConf, Webhook, Git.HTTP, MailService = oldCfg, oldConf, oldLoadedConf, oldWebhook, oldGitHTTP, oldMailService
+	}()
+
+	dir, err := ioutil.TempDir("", "reload")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	CustomConf = filepath.Join(dir, "app.ini")
+	writeConf := func(content string) {
+		assert.NoError(t, ioutil.WriteFile(CustomConf, []byte(content), 0600))
+	}
+
+	writeConf(`
+[server]
+HTTP_PORT = 3000
+
+[webhook]
+DELIVER_TIMEOUT = 5
+
+[git.http]
+MAX_CONCURRENT = 4
+`)
+	Cfg, err = loadCustomConf()
+	assert.NoError(t, err)
+	loadedConf, err = loadCustomConf()
+	assert.NoError(t, err)
+	newWebhookService()
+	assert.NoError(t, reloadGitHTTPSettings())
+	assert.EqualValues(t, 4, Git.HTTP.MaxConcurrent)
+
+	var hooked int
+	OnSettingsReloaded("webhook", func() { hooked++ })
+	defer func() {
+		group := reloadableGroup("webhook")
+		group.hooks = group.hooks[:len(group.hooks)-1]
+	}()
+
+	result, err := ReloadSettings()
+	assert.NoError(t, err)
+	assert.Empty(t, result.Reloaded)
+	assert.Empty(t, result.RestartRequired)
+
+	writeConf(`
+[server]
+HTTP_PORT = 3001
+
+[webhook]
+DELIVER_TIMEOUT = 30
+
+[git.http]
+QUEUE_TIMEOUT = 10s
+`)
+	result, err = ReloadSettings()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"webhook", "git.http"}, result.Reloaded)
+	assert.Equal(t, []string{"server"}, result.RestartRequired)
+	assert.Equal(t, 30, Webhook.DeliverTimeout)
+	assert.Equal(t, 1, hooked)
+	// the removed keys are back to their defaults
+	assert.EqualValues(t, 0, Git.HTTP.MaxConcurrent)
+	assert.Equal(t, 10*time.Second, Git.HTTP.QueueTimeout)
+	assert.Equal(t, "3000", Cfg.Section("server").Key("HTTP_PORT").String())
+
+	// an invalid mailer is not applied and still reloaded the next time
+	writeConf(`
+[server]
+HTTP_PORT = 3001
+
+[webhook]
+DELIVER_TIMEOUT = 30
+
+[git.http]
+QUEUE_TIMEOUT = 10s
+
+[mailer]
+ENABLED = true
+FROM = invalid
+`)
+	MailService = nil
+	_, err = ReloadSettings()
+	assert.Error(t, err)
+	assert.Nil(t, MailService)
+	_, err = ReloadSettings()
+	assert.Error(t, err)
+}
@@ -44,11 +44,13 @@ var (
 		PrefixArchiveFiles                      bool
 		DisableMirrors                          bool
 		DefaultBranch                           string
+		StaleBranchDays                         int
 
 		// Repository editor settings
 		Editor struct {
 			LineWrapExtensions   []string
 			PreviewableFileModes []string
+			MaxWorkspaceChanges  int
 		} `ini:"-"`
 
 		// Repository upload settings
@@ -60,6 +62,14 @@ var (
 			MaxFiles     int
 		} `ini:"-"`
 
+		// Settings of the files served by the raw and media endpoints
+		Raw struct {
+			AllowRepoHeaders bool
+			AllowedOrigins   []string
+			CacheMaxAge      int
+			RobotsPolicy     string
+		} `ini:"-"`
+
 		// Repository local settings
 		Local struct {
 			LocalCopyPath string
@@ -145,14 +155,17 @@ var (
 		DefaultRepoUnits:                        []string{},
 		PrefixArchiveFiles:                      true,
 		DisableMirrors:                          false,
+		StaleBranchDays:                         90,
 
 		// Repository editor settings
 		Editor: struct {
 			LineWrapExtensions   []string
 			PreviewableFileModes []string
+			MaxWorkspaceChanges  int
 		}{
 			LineWrapExtensions:   strings.Split(".txt,.md,.markdown,.mdown,.mkd,", ","),
 			PreviewableFileModes: []string{"markdown"},
+			MaxWorkspaceChanges:  100,
 		},
 
 		// Repository upload settings
@@ -170,6 +183,19 @@ var (
 			MaxFiles:     5,
 		},
 
+		// Settings of the files served by the raw and media endpoints
+		Raw: struct {
+			AllowRepoHeaders bool
+			AllowedOrigins   []string
+			CacheMaxAge      int
+			RobotsPolicy     string
+		}{
+			AllowRepoHeaders: true,
+			AllowedOrigins:   []string{},
+			CacheMaxAge:      86400,
+			RobotsPolicy:     "",
+		},
+
 		// Repository local settings
 		Local: struct {
 			LocalCopyPath string
@@ -262,6 +288,8 @@ func newRepository() {
 		log.Fatal("Failed to map Repository.Editor settings: %v", err)
 	} else if err = Cfg.Section("repository.upload").MapTo(&Repository.Upload); err != nil {
 		log.Fatal("Failed to map Repository.Upload settings: %v", err)
+	} else if err = Cfg.Section("repository.raw").MapTo(&Repository.Raw); err != nil {
+		log.Fatal("Failed to map Repository.Raw settings: %v", err)
 	} else if err = Cfg.Section("repository.local").MapTo(&Repository.Local); err != nil {
 		log.Fatal("Failed to map Repository.Local settings: %v", err)
 	} else if err = Cfg.Section("repository.pull-request").MapTo(&Repository.PullRequest); err != nil {
@@ -167,14 +167,18 @@ var (
 		MembersPagingNum      int
 		FeedMaxCommitNum      int
 		GraphMaxCommitNum     int
+		NetworkMaxForkNum     int
 		CodeCommentLines      int
 		ReactionMaxUserNum    int
 		ThemeColorMetaTag     string
 		MaxDisplayFileSize    int64
+		MaxPreviewFileSize    int64
+		BlameChunkLines       int
 		ShowUserEmail         bool
 		DefaultShowFullName   bool
 		DefaultTheme          string
 		Themes                []string
+		ThemeMaxFileSize      int64
 		Reactions             []string
 		ReactionsMap          map[string]bool
 		SearchRepoDescription bool
@@ -208,12 +212,16 @@ var (
 		MembersPagingNum:    20,
 		FeedMaxCommitNum:    5,
 		GraphMaxCommitNum:   100,
+		NetworkMaxForkNum:   20,
 		CodeCommentLines:    4,
 		ReactionMaxUserNum:  10,
 		ThemeColorMetaTag:   `#6cc644`,
 		MaxDisplayFileSize:  8388608,
+		MaxPreviewFileSize:  52428800,
+		BlameChunkLines:     1000,
 		DefaultTheme:        `gitea`,
 		Themes:              []string{`gitea`, `arc-green`},
+		ThemeMaxFileSize:    1048576,
 		Reactions:           []string{`+1`, `-1`, `laugh`, `hooray`, `confused`, `heart`, `rocket`, `eyes`},
 		Notification: struct {
 			MinTimeout            time.Duration
@@ -259,20 +267,28 @@ var (
 		EnableHardLineBreakInDocuments bool
 		CustomURLSchemes               []string `ini:"CUSTOM_URL_SCHEMES"`
 		FileExtensions                 []string
+		EnableMath                     bool
+		DiagramTypes                   []string
+		DiagramServerURL               string `ini:"DIAGRAM_SERVER_URL"`
+		PlantUMLServerURL              string `ini:"PLANTUML_SERVER_URL"`
 	}{
 		EnableHardLineBreakInComments:  true,
 		EnableHardLineBreakInDocuments: false,
 		FileExtensions:                 strings.Split(".md,.markdown,.mdown,.mkd", ","),
+		DiagramTypes:                   []string{"mermaid", "plantuml", "graphviz"},
 	}
 
 	// Admin settings
 	Admin struct {
 		DisableRegularOrgCreation bool
 		DefaultEmailNotification  string
+		EnableConfigEditor        bool
 	}
 
 	// Picture settings
 	AvatarUploadPath              string
+	AvatarVariantPath             string
+	AvatarVariantSizes            []int
 	AvatarMaxWidth                int
 	AvatarMaxHeight               int
 	GravatarSource                string
@@ -284,6 +300,8 @@ var (
 	RepositoryAvatarUploadPath    string
 	RepositoryAvatarFallback      string
 	RepositoryAvatarFallbackImage string
+	CustomEmojiPath               string
+	CustomEmojiMaxFileSize        int64
 
 	// Log settings
 	LogLevel           string
@@ -303,6 +321,23 @@ var (
 	AttachmentMaxSize      int64
 	AttachmentMaxFiles     int
 	AttachmentEnabled      bool
+	// AttachmentThumbnailSize is the maximum width and height of the thumbnails of the image
+	// attachments, 0 disables them
+	AttachmentThumbnailSize int
+	AttachmentThumbnailPath string
+	// AttachmentThumbnailMaxWidth and AttachmentThumbnailMaxHeight limit the size of the images
+	// which are decoded to generate a thumbnail
+	AttachmentThumbnailMaxWidth  int
+	AttachmentThumbnailMaxHeight int
+	AttachmentStripMetadata      bool
+	// AttachmentMaxSizeByType limits the size in MB of the attachments by type, like "video/*:50"
+	AttachmentMaxSizeByType string
+	// AttachmentMaxIssueSize is the maximum total size in MB of the attachments of an issue
+	AttachmentMaxIssueSize int64
+	// AttachmentClosedIssueRetention is the number of days the attachments of a closed issue
+	// are kept, the uploaders are notified AttachmentRetentionNotice days before the deletion
+	AttachmentClosedIssueRetention int
+	AttachmentRetentionNotice      int
 
 	// Time settings
 	TimeFormat string
@@ -320,19 +355,23 @@ var (
 
 	// API settings
 	API = struct {
-		EnableSwagger          bool
-		SwaggerURL             string
-		MaxResponseItems       int
-		DefaultPagingNum       int
-		DefaultGitTreesPerPage int
-		DefaultMaxBlobSize     int64
+		EnableSwagger           bool
+		SwaggerURL              string
+		MaxResponseItems        int
+		DefaultPagingNum        int
+		DefaultGitTreesPerPage  int
+		MaxTreeDepth            int
+		DefaultMaxBlobSize      int64
+		StrictRequestValidation bool
 	}{
-		EnableSwagger:          true,
-		SwaggerURL:             "",
-		MaxResponseItems:       50,
-		DefaultPagingNum:       30,
-		DefaultGitTreesPerPage: 1000,
-		DefaultMaxBlobSize:     10485760,
+		EnableSwagger:           true,
+		SwaggerURL:              "",
+		MaxResponseItems:        50,
+		DefaultPagingNum:        30,
+		DefaultGitTreesPerPage:  1000,
+		MaxTreeDepth:            10,
+		DefaultMaxBlobSize:      10485760,
+		StrictRequestValidation: false,
 	}
 
 	OAuth2 = struct {
@@ -358,11 +397,13 @@ var (
 
 	// Metrics settings
 	Metrics = struct {
-		Enabled bool
-		Token   string
+		Enabled        bool
+		Token          string
+		MaxLabelValues int
 	}{
-		Enabled: false,
-		Token:   "",
+		Enabled:        false,
+		Token:          "",
+		MaxLabelValues: 100,
 	}
 
 	// I18n settings
@@ -537,10 +578,13 @@ func NewContext() {
 		createPIDFile(CustomPID)
 	}
 
+	loadedConf = nil
 	if com.IsFile(CustomConf) {
 		if err := Cfg.Append(CustomConf); err != nil {
 			log.Fatal("Failed to load custom conf '%s': %v", CustomConf, err)
 		}
+		// kept to find the changed sections when the settings are reloaded
+		loadedConf, _ = loadCustomConf()
 	} else {
 		log.Warn("Custom config '%s' not found, ignore this if you're running first time", CustomConf)
 	}
@@ -552,12 +596,7 @@ func NewContext() {
 	}
 	homeDir = strings.Replace(homeDir, "\\", "/", -1)
 
-	LogLevel = getLogLevel(Cfg.Section("log"), "LEVEL", "Info")
-	StacktraceLogLevel = getStacktraceLogLevel(Cfg.Section("log"), "STACKTRACE_LEVEL", "None")
-	LogRootPath = Cfg.Section("log").Key("ROOT_PATH").MustString(path.Join(AppWorkPath, "log"))
-	forcePathSeparator(LogRootPath)
-	RedirectMacaronLog = Cfg.Section("log").Key("REDIRECT_MACARON_LOG").MustBool(false)
-	RouterLogLevel = log.FromString(Cfg.Section("log").Key("ROUTER_LOG_LEVEL").MustString("Info"))
+	newLogRootSettings()
 
 	sec := Cfg.Section("server")
 	AppName = Cfg.Section("").Key("APP_NAME").MustString("Gitea: Git with a cup of tea")
@@ -606,6 +645,7 @@ func NewContext() {
 	HTTPPort = sec.Key("HTTP_PORT").MustString("3000")
 	GracefulRestartable = sec.Key("ALLOW_GRACEFUL_RESTARTS").MustBool(true)
 	GracefulHammerTime = sec.Key("GRACEFUL_HAMMER_TIME").MustDuration(60 * time.Second)
+	ReloadOnSIGHUP = sec.Key("RELOAD_ON_SIGHUP").MustBool(false)
 	StartupTimeout = sec.Key("STARTUP_TIMEOUT").MustDuration(0 * time.Second)
 
 	defaultAppURL := string(Protocol) + "://" + Domain
@@ -845,6 +885,18 @@ func NewContext() {
 	AttachmentMaxSize = sec.Key("MAX_SIZE").MustInt64(4)
 	AttachmentMaxFiles = sec.Key("MAX_FILES").MustInt(5)
 	AttachmentEnabled = sec.Key("ENABLED").MustBool(true)
+	AttachmentThumbnailSize = sec.Key("THUMBNAIL_SIZE").MustInt(300)
+	AttachmentThumbnailPath = sec.Key("THUMBNAIL_PATH").MustString(path.Join(AppDataPath, "attachment-thumbnails"))
+	if !filepath.IsAbs(AttachmentThumbnailPath) {
+		AttachmentThumbnailPath = path.Join(AppWorkPath, AttachmentThumbnailPath)
+	}
+	AttachmentThumbnailMaxWidth = sec.Key("THUMBNAIL_MAX_WIDTH").MustInt(6000)
+	AttachmentThumbnailMaxHeight = sec.Key("THUMBNAIL_MAX_HEIGHT").MustInt(6000)
+	AttachmentStripMetadata = sec.Key("STRIP_METADATA").MustBool(true)
+	AttachmentMaxSizeByType = sec.Key("MAX_SIZE_BY_TYPE").MustString("")
+	AttachmentMaxIssueSize = sec.Key("MAX_ISSUE_SIZE").MustInt64(0)
+	AttachmentClosedIssueRetention = sec.Key("CLOSED_ISSUE_RETENTION_DAYS").MustInt(0)
+	AttachmentRetentionNotice = sec.Key("RETENTION_NOTICE_DAYS").MustInt(7)
 
 	timeFormatKey := Cfg.Section("time").Key("FORMAT").MustString("")
 	if timeFormatKey != "" {
@@ -909,11 +961,30 @@ func NewContext() {
 	if !filepath.IsAbs(AvatarUploadPath) {
 		AvatarUploadPath = path.Join(AppWorkPath, AvatarUploadPath)
 	}
+	AvatarVariantPath = sec.Key("AVATAR_VARIANT_PATH").MustString(path.Join(AppDataPath, "avatar-variants"))
+	forcePathSeparator(AvatarVariantPath)
+	if !filepath.IsAbs(AvatarVariantPath) {
+		AvatarVariantPath = path.Join(AppWorkPath, AvatarVariantPath)
+	}
+	AvatarVariantSizes = AvatarVariantSizes[:0]
+	for _, s := range strings.Split(sec.Key("AVATAR_VARIANT_SIZES").MustString("24,32,48,64,96,128,192,256"), ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || size <= 0 {
+			log.Fatal("Invalid avatar variant size %q", s)
+		}
+		AvatarVariantSizes = append(AvatarVariantSizes, size)
+	}
 	RepositoryAvatarUploadPath = sec.Key("REPOSITORY_AVATAR_UPLOAD_PATH").MustString(path.Join(AppDataPath, "repo-avatars"))
 	forcePathSeparator(RepositoryAvatarUploadPath)
 	if !filepath.IsAbs(RepositoryAvatarUploadPath) {
 		RepositoryAvatarUploadPath = path.Join(AppWorkPath, RepositoryAvatarUploadPath)
 	}
+	CustomEmojiPath = sec.Key("CUSTOM_EMOJI_PATH").MustString(path.Join(AppDataPath, "custom-emoji"))
+	forcePathSeparator(CustomEmojiPath)
+	if !filepath.IsAbs(CustomEmojiPath) {
+		CustomEmojiPath = path.Join(AppWorkPath, CustomEmojiPath)
+	}
+	CustomEmojiMaxFileSize = sec.Key("CUSTOM_EMOJI_MAX_FILE_SIZE").MustInt64(262144)
 	RepositoryAvatarFallback = sec.Key("REPOSITORY_AVATAR_FALLBACK").MustString("none")
 	RepositoryAvatarFallbackImage = sec.Key("REPOSITORY_AVATAR_FALLBACK_IMAGE").MustString("/img/repo_default.png")
 	AvatarMaxWidth = sec.Key("AVATAR_MAX_WIDTH").MustInt(4096)
@@ -1120,4 +1191,11 @@ func NewServices() {
 	newIndexerService()
 	newTaskService()
 	NewQueueService()
+	newTracingService()
+	newClusterService()
+	newPagesService()
+	newSnippetService()
+	newIDEService()
+	newIssueTrackerSyncService()
+	newDeployTokenService()
 }
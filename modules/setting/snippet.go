@@ -0,0 +1,36 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"path"
+	"path/filepath"
+)
+
+var (
+	// Snippet settings
+	Snippet = struct {
+		Enabled     bool
+		RootPath    string
+		MaxFiles    int
+		MaxFileSize int64
+	}{
+		Enabled:     true,
+		RootPath:    "snippets",
+		MaxFiles:    10,
+		MaxFileSize: 1024 * 1024,
+	}
+)
+
+func newSnippetService() {
+	sec := Cfg.Section("snippet")
+	Snippet.Enabled = sec.Key("ENABLED").MustBool(true)
+	Snippet.RootPath = sec.Key("ROOT_PATH").MustString(path.Join(AppDataPath, "snippets"))
+	if !filepath.IsAbs(Snippet.RootPath) {
+		Snippet.RootPath = filepath.Join(AppWorkPath, Snippet.RootPath)
+	}
+	Snippet.MaxFiles = sec.Key("MAX_FILES").MustInt(10)
+	Snippet.MaxFileSize = sec.Key("MAX_FILE_SIZE").MustInt64(1024 * 1024)
+}
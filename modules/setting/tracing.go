@@ -0,0 +1,42 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"code.gitea.io/gitea/modules/log"
+)
+
+var (
+	// Tracing settings
+	Tracing = struct {
+		Enabled     bool
+		Endpoint    string
+		Insecure    bool
+		ServiceName string
+		SampleRatio float64
+	}{
+		Enabled:     false,
+		Endpoint:    "localhost:4317",
+		Insecure:    false,
+		ServiceName: "gitea",
+		SampleRatio: 1,
+	}
+)
+
+func newTracingService() {
+	sec := Cfg.Section("tracing")
+	Tracing.Enabled = sec.Key("ENABLED").MustBool(false)
+	Tracing.Endpoint = sec.Key("ENDPOINT").MustString("localhost:4317")
+	Tracing.Insecure = sec.Key("INSECURE").MustBool(false)
+	Tracing.ServiceName = sec.Key("SERVICE_NAME").MustString("gitea")
+	Tracing.SampleRatio = sec.Key("SAMPLE_RATIO").MustFloat64(1)
+	if Tracing.SampleRatio < 0 || Tracing.SampleRatio > 1 {
+		log.Error("Tracing SAMPLE_RATIO must be between 0 and 1, using 1")
+		Tracing.SampleRatio = 1
+	}
+	if Tracing.Enabled {
+		log.Info("Tracing Service Enabled: exporting to %s", Tracing.Endpoint)
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// ConfigReloadResult represents the result of reloading the hot-reloadable settings
+type ConfigReloadResult struct {
+	// names of the groups of settings which were reloaded
+	Reloaded []string `json:"reloaded"`
+	// changed sections of the configuration which require a restart
+	RestartRequired []string `json:"restart_required"`
+}
@@ -0,0 +1,34 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// MaintenanceStatus represents the maintenance mode of the instance and the
+// work which is still draining
+type MaintenanceStatus struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message"`
+	// swagger:strfmt date-time
+	Since *time.Time `json:"since"`
+	// number of requests being handled, not counting this one
+	InFlightRequests int64 `json:"in_flight_requests"`
+	// number of items waiting in the queues
+	QueuedItems int64 `json:"queued_items"`
+	// number of queue items being handled
+	InProgressItems int64 `json:"in_progress_items"`
+	// whether no requests or queue items are left
+	Drained bool `json:"drained"`
+}
+
+// EditMaintenanceOption options for enabling or disabling the maintenance mode
+type EditMaintenanceOption struct {
+	// required: true
+	Enabled bool `json:"enabled"`
+	// message shown to users whose writes are declined
+	Message string `json:"message"`
+}
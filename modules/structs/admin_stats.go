@@ -0,0 +1,100 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// InstanceStats represents the statistics of the instance
+type InstanceStats struct {
+	Counts   *InstanceCounts `json:"counts"`
+	Storage  *StorageStats   `json:"storage"`
+	Queues   []*QueueStats   `json:"queues"`
+	Indexers []*IndexerStats `json:"indexers"`
+	Cron     []*CronTask     `json:"cron"`
+}
+
+// InstanceCounts represents the number of stored objects of the instance
+type InstanceCounts struct {
+	Users        int64 `json:"users"`
+	Orgs         int64 `json:"orgs"`
+	PublicKeys   int64 `json:"public_keys"`
+	Repos        int64 `json:"repos"`
+	Watches      int64 `json:"watches"`
+	Stars        int64 `json:"stars"`
+	Actions      int64 `json:"actions"`
+	Accesses     int64 `json:"accesses"`
+	Issues       int64 `json:"issues"`
+	Comments     int64 `json:"comments"`
+	Follows      int64 `json:"follows"`
+	Mirrors      int64 `json:"mirrors"`
+	Releases     int64 `json:"releases"`
+	LoginSources int64 `json:"login_sources"`
+	Webhooks     int64 `json:"webhooks"`
+	Milestones   int64 `json:"milestones"`
+	Labels       int64 `json:"labels"`
+	HookTasks    int64 `json:"hook_tasks"`
+	Teams        int64 `json:"teams"`
+	Attachments  int64 `json:"attachments"`
+}
+
+// StorageStats represents the storage used by the instance in bytes
+type StorageStats struct {
+	Repositories int64 `json:"repositories"`
+	LFSObjects   int64 `json:"lfs_objects"`
+	Attachments  int64 `json:"attachments"`
+}
+
+// QueueStats represents the state of a work queue
+type QueueStats struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Workers    int    `json:"workers"`
+	MaxWorkers int    `json:"max_workers"`
+	// number of items waiting in the queue, -1 if the queue cannot count them
+	Queued int64 `json:"queued"`
+}
+
+// IndexerStats represents the state of an indexer
+type IndexerStats struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+	// number of items waiting to be indexed, -1 if unknown
+	Queued int64 `json:"queued"`
+}
+
+// CronTask represents a cron task and the outcome of its last run
+type CronTask struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	// swagger:strfmt date-time
+	Next *time.Time `json:"next"`
+	// swagger:strfmt date-time
+	Prev      *time.Time `json:"prev"`
+	ExecTimes int64      `json:"exec_times"`
+	IsRunning bool       `json:"is_running"`
+	// outcome of the last run, empty if the task did not run yet
+	// enum: finished,aborted,error
+	Status    string `json:"status"`
+	LastError string `json:"last_error"`
+}
+
+// InstanceHealth represents the result of the health checks of the instance
+type InstanceHealth struct {
+	// enum: pass,fail
+	Status string         `json:"status"`
+	Checks []*HealthCheck `json:"checks"`
+}
+
+// HealthCheck represents the result of checking a service the instance depends on
+type HealthCheck struct {
+	Name string `json:"name"`
+	// enum: pass,fail
+	Status string `json:"status"`
+	// duration of the check in milliseconds
+	Duration int64  `json:"duration_ms"`
+	Error    string `json:"error,omitempty"`
+}
@@ -0,0 +1,21 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// AutocompleteUser is a user suggested when mentioning someone
+type AutocompleteUser struct {
+	Username  string `json:"username"`
+	FullName  string `json:"full_name"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// AutocompleteIssue is an issue or pull request suggested when referencing one
+type AutocompleteIssue struct {
+	Index   int64     `json:"number"`
+	Title   string    `json:"title"`
+	IsPull  bool      `json:"is_pull"`
+	State   StateType `json:"state"`
+	HTMLURL string    `json:"html_url"`
+}
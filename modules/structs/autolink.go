@@ -0,0 +1,47 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// AutoLinkRule a rule linking the text matching a pattern in the rendered issues,
+// pull requests and commit messages
+// swagger:model
+type AutoLinkRule struct {
+	ID int64 `json:"id"`
+	// regular expression matching the text to link
+	// example: \bJIRA-\d+\b
+	Pattern string `json:"pattern"`
+	// URL of the link, $0 is replaced with the matched text, $1 with the first group
+	// and ${name} with the group named "name"
+	// example: https://jira.example.com/browse/$0
+	URL string `json:"url"`
+	// rules with a lower sort are applied first
+	Sort int `json:"sort"`
+	// id of the organization of the rule, 0 for a rule of a repository
+	OrgID int64 `json:"org_id"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateAutoLinkRuleOption options for creating an auto-link rule
+type CreateAutoLinkRuleOption struct {
+	// required:true
+	Pattern string `json:"pattern" binding:"Required;MaxSize(255)"`
+	// required:true
+	URL  string `json:"url" binding:"Required"`
+	Sort int    `json:"sort"`
+}
+
+// EditAutoLinkRuleOption options for editing an auto-link rule
+type EditAutoLinkRuleOption struct {
+	Pattern *string `json:"pattern" binding:"MaxSize(255)"`
+	URL     *string `json:"url"`
+	Sort    *int    `json:"sort"`
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// DashboardLayout represents the widgets of the dashboard of a user
+type DashboardLayout struct {
+	// Widgets are the widgets shown on the dashboard in their order
+	Widgets []string `json:"widgets"`
+	// Available are all the widgets which can be added
+	Available []string `json:"available"`
+}
+
+// EditDashboardLayoutOption options when changing the widgets of the dashboard
+type EditDashboardLayoutOption struct {
+	// Widgets are the widgets to show in their order, one of assigned_issues,
+	// review_requests, ci_failures, watched_releases and org_activity
+	Widgets []string `json:"widgets"`
+}
+
+// DashboardWidget represents the content of a widget of the dashboard
+type DashboardWidget struct {
+	Type    string                  `json:"type"`
+	Entries []*DashboardWidgetEntry `json:"entries"`
+}
+
+// DashboardWidgetEntry represents an entry of a widget of the dashboard
+type DashboardWidgetEntry struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	// Icon is the name of the octicon of the entry
+	Icon string `json:"icon"`
+	// State is the state of the entry if it has one, like "failure" for a commit status
+	State string `json:"state"`
+	// swagger:strfmt date-time
+	Time time.Time `json:"time"`
+}
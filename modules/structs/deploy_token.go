@@ -0,0 +1,41 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// DeployToken a short-lived token allowing some operations on a repository
+// swagger:model
+type DeployToken struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// operations allowed to the token
+	// example: ["git:pull","lfs:pull"]
+	Scopes []string `json:"scopes"`
+	// the secret of the token, only returned when it is created
+	Token string `json:"token,omitempty"`
+	// the user on behalf of whom the token acts
+	Creator *User `json:"creator"`
+	// swagger:strfmt date-time
+	Expires time.Time `json:"expires_at"`
+	// swagger:strfmt date-time
+	LastUsed *time.Time `json:"last_used_at"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// CreateDeployTokenOption options for creating a deploy token of a repository
+type CreateDeployTokenOption struct {
+	// required:true
+	Name string `json:"name" binding:"Required;MaxSize(50)"`
+	// operations allowed to the token among git:pull, git:push, lfs:pull, lfs:push and release:upload
+	// required:true
+	// example: ["git:pull","lfs:pull"]
+	Scopes []string `json:"scopes" binding:"Required"`
+	// number of seconds the token is valid, defaults to the setting of the site
+	ExpiresIn int64 `json:"expires_in"`
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// DiscussionCategory a category grouping the discussions of a repository
+type DiscussionCategory struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// replies to discussions of answerable categories can be accepted as answer
+	IsAnswerable   bool `json:"is_answerable"`
+	NumDiscussions int  `json:"discussions"`
+}
+
+// Discussion a conversation thread of a repository
+type Discussion struct {
+	ID       int64               `json:"id"`
+	URL      string              `json:"url"`
+	HTMLURL  string              `json:"html_url"`
+	Index    int64               `json:"number"`
+	Poster   *User               `json:"user"`
+	Title    string              `json:"title"`
+	Body     string              `json:"body"`
+	Category *DiscussionCategory `json:"category"`
+	Replies  int                 `json:"replies"`
+	Upvotes  int                 `json:"upvotes"`
+	// id of the reply accepted as answer, 0 if there is none
+	AnswerID int64 `json:"answer_id"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// DiscussionReply a reply to a discussion
+type DiscussionReply struct {
+	ID int64 `json:"id"`
+	// id of the reply this one is nested below, 0 for top level replies
+	ParentID int64  `json:"parent_id"`
+	Poster   *User  `json:"user"`
+	Body     string `json:"body"`
+	Upvotes  int    `json:"upvotes"`
+	IsAnswer bool   `json:"is_answer"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateDiscussionCategoryOption options for creating a discussion category
+type CreateDiscussionCategoryOption struct {
+	// required:true
+	Name         string `json:"name" binding:"Required;MaxSize(50)"`
+	Description  string `json:"description" binding:"MaxSize(255)"`
+	IsAnswerable bool   `json:"is_answerable"`
+}
+
+// CreateDiscussionOption options for starting a discussion
+type CreateDiscussionOption struct {
+	// required:true
+	Title string `json:"title" binding:"Required;MaxSize(255)"`
+	Body  string `json:"body"`
+	// required:true
+	CategoryID int64 `json:"category_id" binding:"Required"`
+}
+
+// CreateDiscussionReplyOption options for replying to a discussion
+type CreateDiscussionReplyOption struct {
+	// required:true
+	Body string `json:"body" binding:"Required"`
+	// id of the reply to nest the new reply below
+	ParentID int64 `json:"parent_id"`
+}
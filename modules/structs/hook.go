@@ -34,6 +34,27 @@ type Hook struct {
 // HookList represents a list of API hook.
 type HookList []*Hook
 
+// HookDelivery represents a delivery of a webhook
+type HookDelivery struct {
+	ID   int64  `json:"id"`
+	UUID string `json:"uuid"`
+	// event which triggered the delivery
+	Event       string `json:"event"`
+	IsDelivered bool   `json:"is_delivered"`
+	// whether the endpoint responded with a 2xx status
+	IsSucceed bool `json:"is_succeed"`
+	// swagger:strfmt date-time
+	Delivered       *time.Time        `json:"delivered_at"`
+	URL             string            `json:"url"`
+	RequestHeaders  map[string]string `json:"request_headers"`
+	StatusCode      int               `json:"status_code"`
+	ResponseHeaders map[string]string `json:"response_headers"`
+	ResponseBody    string            `json:"response_body"`
+}
+
+// HookDeliveryList represents a list of API hook deliveries.
+type HookDeliveryList []*HookDelivery
+
 // CreateHookOptionConfig has all config options in it
 // required are "content_type" and "url" Required
 type CreateHookOptionConfig map[string]string
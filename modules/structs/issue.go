@@ -54,9 +54,15 @@ type Issue struct {
 	//
 	// type: string
 	// enum: open,closed
-	State    StateType `json:"state"`
-	IsLocked bool      `json:"is_locked"`
-	Comments int       `json:"comments"`
+	State         StateType      `json:"state"`
+	WorkflowState *WorkflowState `json:"workflow_state"`
+	Epic          *Epic          `json:"epic"`
+	IsLocked      bool           `json:"is_locked"`
+	Comments      int            `json:"comments"`
+	// Whether the issue carries a label marked as question
+	IsQuestion bool `json:"is_question"`
+	// The comment accepted as answer of a question issue
+	AnswerComment *Comment `json:"answer_comment"`
 	// swagger:strfmt date-time
 	Created time.Time `json:"created_at"`
 	// swagger:strfmt date-time
@@ -101,6 +107,10 @@ type EditIssueOption struct {
 	Assignees []string `json:"assignees"`
 	Milestone *int64   `json:"milestone"`
 	State     *string  `json:"state"`
+	// id of the workflow state to move the issue to, 0 removes it
+	WorkflowState *int64 `json:"workflow_state"`
+	// id of the epic to add the issue to, 0 removes it
+	Epic *int64 `json:"epic"`
 	// swagger:strfmt date-time
 	Deadline       *time.Time `json:"due_date"`
 	RemoveDeadline *bool      `json:"unset_due_date"`
@@ -119,3 +129,10 @@ type IssueDeadline struct {
 	// swagger:strfmt date-time
 	Deadline *time.Time `json:"due_date"`
 }
+
+// SetIssueAnswerOption options for accepting a comment as answer of an issue
+type SetIssueAnswerOption struct {
+	// id of the comment to accept as answer
+	// required:true
+	CommentID int64 `json:"comment_id" binding:"Required"`
+}
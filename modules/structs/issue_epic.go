@@ -0,0 +1,46 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Epic an epic groups issues above milestones, either of one repository or
+// of all repositories of an organization
+type Epic struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	State       StateType `json:"state"`
+	// id of the repository the epic belongs to, 0 for organization epics
+	RepoID       int64 `json:"repo_id"`
+	OwnerID      int64 `json:"owner_id"`
+	OpenIssues   int   `json:"open_issues"`
+	ClosedIssues int   `json:"closed_issues"`
+	// percentage of closed issues
+	Completeness int `json:"completeness"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+	// swagger:strfmt date-time
+	Closed *time.Time `json:"closed_at"`
+}
+
+// CreateEpicOption options for creating an epic
+type CreateEpicOption struct {
+	// required:true
+	Title       string `json:"title" binding:"Required"`
+	Description string `json:"description"`
+}
+
+// EditEpicOption options for editing an epic
+type EditEpicOption struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	// enum: open,closed
+	State *string `json:"state"`
+}
@@ -0,0 +1,36 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// IssueGraphNode is an issue or a pull request of a reference graph
+type IssueGraphNode struct {
+	ID         int64           `json:"id"`
+	Index      int64           `json:"number"`
+	Title      string          `json:"title"`
+	State      StateType       `json:"state"`
+	IsPull     bool            `json:"is_pull"`
+	Repository *RepositoryMeta `json:"repository"`
+	HTMLURL    string          `json:"html_url"`
+}
+
+// IssueGraphEdge is a link between two issues of a reference graph, given by their ids
+type IssueGraphEdge struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	// blocks: from blocks to, duplicates: from is a duplicate of to,
+	// references: from references to, closes: the pull request from closes to
+	// enum: blocks,duplicates,references,closes
+	Type string `json:"type"`
+}
+
+// IssueGraph is the reference graph around an issue
+type IssueGraph struct {
+	// id of the issue the graph is built around
+	Root  int64             `json:"root"`
+	Nodes []*IssueGraphNode `json:"nodes"`
+	Edges []*IssueGraphEdge `json:"edges"`
+	// true if some issues were left out of a too large graph
+	Truncated bool `json:"truncated"`
+}
@@ -13,7 +13,13 @@ type Label struct {
 	// example: 00aabb
 	Color       string `json:"color"`
 	Description string `json:"description"`
-	URL         string `json:"url"`
+	// Whether issues with this label are questions which can have an accepted answer
+	IsQuestion bool `json:"is_question"`
+	// Whether the label of the organization is synchronized to its repositories
+	SyncToRepos bool `json:"sync_to_repos"`
+	// ID of the label of the organization this label is synchronized from
+	SyncedFromID int64  `json:"synced_from_id"`
+	URL          string `json:"url"`
 }
 
 // CreateLabelOption options for creating a label
@@ -24,6 +30,9 @@ type CreateLabelOption struct {
 	// example: #00aabb
 	Color       string `json:"color" binding:"Required"`
 	Description string `json:"description"`
+	IsQuestion  bool   `json:"is_question"`
+	// only applies to the labels of an organization
+	SyncToRepos bool `json:"sync_to_repos"`
 }
 
 // EditLabelOption options for editing a label
@@ -31,6 +40,18 @@ type EditLabelOption struct {
 	Name        *string `json:"name"`
 	Color       *string `json:"color"`
 	Description *string `json:"description"`
+	IsQuestion  *bool   `json:"is_question"`
+	// only applies to the labels of an organization
+	SyncToRepos *bool `json:"sync_to_repos"`
+}
+
+// LabelSyncResult the result of the synchronization of the labels of an organization
+// swagger:model
+type LabelSyncResult struct {
+	// number of repository labels created or updated
+	Synced int `json:"synced"`
+	// number of repository labels which could not be synchronized because of a name conflict
+	Conflicts int `json:"conflicts"`
 }
 
 // IssueLabelsOption a collection of labels
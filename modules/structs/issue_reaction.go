@@ -20,3 +20,12 @@ type Reaction struct {
 	// swagger:strfmt date-time
 	Created time.Time `json:"created_at"`
 }
+
+// CustomEmoji represents an emoji image uploaded by a site administrator,
+// usable as :name: in comments and as a reaction
+type CustomEmoji struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
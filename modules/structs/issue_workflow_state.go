@@ -0,0 +1,45 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// WorkflowState a custom issue state of a repository
+// swagger:model
+type WorkflowState struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// example: #00aabb
+	Color string `json:"color"`
+	// whether issues in this state are considered closed
+	IsClosed bool `json:"is_closed"`
+	Sort     int  `json:"sort"`
+	// IDs of the states an issue in this state may move to, empty means any
+	AllowedTransitions []int64 `json:"allowed_transitions"`
+}
+
+// CreateWorkflowStateOption options for creating a workflow state
+type CreateWorkflowStateOption struct {
+	// required:true
+	Name string `json:"name" binding:"Required"`
+	// required:true
+	// example: #00aabb
+	Color       string `json:"color" binding:"Required"`
+	Description string `json:"description"`
+	IsClosed    bool   `json:"is_closed"`
+	Sort        int    `json:"sort"`
+	// IDs of the states an issue in this state may move to
+	AllowedTransitions []int64 `json:"allowed_transitions"`
+}
+
+// EditWorkflowStateOption options for editing a workflow state
+type EditWorkflowStateOption struct {
+	Name        *string `json:"name"`
+	Color       *string `json:"color"`
+	Description *string `json:"description"`
+	IsClosed    *bool   `json:"is_closed"`
+	Sort        *int    `json:"sort"`
+	// IDs of the states an issue in this state may move to
+	AllowedTransitions []int64 `json:"allowed_transitions"`
+}
@@ -50,3 +50,15 @@ type APIError struct {
 	Message string `json:"message"`
 	URL     string `json:"url"`
 }
+
+// MailBounceOption is a bounce of a mail reported by the mail provider
+type MailBounceOption struct {
+	// address whose mail bounced
+	// required: true
+	Email string `json:"email" binding:"Required;Email"`
+	// kind of the bounce, hard if it is empty
+	// enum: hard,soft,complaint
+	Type string `json:"type" binding:"In(,hard,soft,complaint)"`
+	// reason of the bounce given by the provider
+	Reason string `json:"reason"`
+}
@@ -15,8 +15,11 @@ type NotificationThread struct {
 	Subject    *NotificationSubject `json:"subject"`
 	Unread     bool                 `json:"unread"`
 	Pinned     bool                 `json:"pinned"`
-	UpdatedAt  time.Time            `json:"updated_at"`
-	URL        string               `json:"url"`
+	// Reason is why the user got the notification, one of watch, mention, assign, review_request and attachment_expiry
+	Reason    string    `json:"reason"`
+	Saved     bool      `json:"saved"`
+	UpdatedAt time.Time `json:"updated_at"`
+	URL       string    `json:"url"`
 }
 
 // NotificationSubject contains the notification subject (Issue/Pull/Commit)
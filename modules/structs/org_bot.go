@@ -0,0 +1,39 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// OrgBot represents a bot account of an organization
+type OrgBot struct {
+	ID          int64  `json:"id"`
+	Bot         *User  `json:"bot"`
+	Description string `json:"description"`
+	// highest permission the bot is granted on a repository of the organization
+	// enum: read,write,admin
+	MaxPermission string `json:"max_permission"`
+	Creator       *User  `json:"creator"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// CreateOrgBotOption options for creating a bot of an organization
+type CreateOrgBotOption struct {
+	// required: true
+	UserName    string `json:"username" binding:"Required;AlphaDashDot;MaxSize(40)"`
+	FullName    string `json:"full_name" binding:"MaxSize(100)"`
+	Description string `json:"description" binding:"MaxSize(255)"`
+	// highest permission the bot is granted on a repository of the organization, defaults to write
+	// enum: read,write,admin
+	MaxPermission string `json:"max_permission" binding:"In(,read,write,admin)"`
+}
+
+// EditOrgBotOption options for editing a bot of an organization
+type EditOrgBotOption struct {
+	FullName    *string `json:"full_name" binding:"MaxSize(100)"`
+	Description *string `json:"description" binding:"MaxSize(255)"`
+	// enum: read,write,admin
+	MaxPermission *string `json:"max_permission"`
+}
@@ -4,7 +4,34 @@
 
 package structs
 
+import (
+	"time"
+)
+
 // AddOrgMembershipOption add user to organization options
 type AddOrgMembershipOption struct {
 	Role string `json:"role" binding:"Required"`
 }
+
+// OrgMembership represents the membership of a user in an organization
+type OrgMembership struct {
+	User   *User `json:"user"`
+	Public bool  `json:"public"`
+	// swagger:strfmt date-time
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// EditOrgMembershipOption options for editing the membership of a user in an organization
+type EditOrgMembershipOption struct {
+	// swagger:strfmt date-time
+	ExpiresAt        *time.Time `json:"expires_at"`
+	RemoveExpiration bool       `json:"remove_expiration"`
+}
+
+// OrgExternalCollaborator represents a user who collaborates on repositories
+// of an organization without being a member of it
+type OrgExternalCollaborator struct {
+	User *User `json:"user"`
+	// full names of the repositories the user collaborates on
+	Repositories []string `json:"repositories"`
+}
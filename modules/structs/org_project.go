@@ -0,0 +1,113 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Project a project tracks issues of all repositories of an organization on
+// a board
+type Project struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	State       StateType `json:"state"`
+	OwnerID     int64     `json:"owner_id"`
+	// id of the column the issues are moved to when a pull request closing them is opened, 0 if disabled
+	InProgressBoardID int64 `json:"in_progress_board_id"`
+	// id of the column the issues are moved to when they are closed or a pull request closing them is merged, 0 if disabled
+	DoneBoardID int64 `json:"done_board_id"`
+	// number of days after which the issues in the done column are archived, 0 if disabled
+	ArchiveDoneAfterDays int `json:"archive_done_after_days"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+	// swagger:strfmt date-time
+	Closed *time.Time `json:"closed_at"`
+}
+
+// ProjectBoard a column of a project
+type ProjectBoard struct {
+	ID        int64  `json:"id"`
+	ProjectID int64  `json:"project_id"`
+	Title     string `json:"title"`
+	Sorting   int    `json:"sorting"`
+}
+
+// ProjectIssue an issue on the board of a project
+type ProjectIssue struct {
+	ProjectID int64 `json:"project_id"`
+	// id of the column of the issue, 0 for uncategorized issues
+	BoardID int64  `json:"board_id"`
+	Issue   *Issue `json:"issue"`
+}
+
+// ProjectRule a rule adding the new issues matching it to a project
+type ProjectRule struct {
+	ID        int64 `json:"id"`
+	ProjectID int64 `json:"project_id"`
+	BoardID   int64 `json:"board_id"`
+	// id of the repository of the issues, 0 for all repositories
+	RepoID  int64  `json:"repo_id"`
+	Label   string `json:"label"`
+	Keyword string `json:"keyword"`
+}
+
+// CreateProjectOption options for creating a project
+type CreateProjectOption struct {
+	// required:true
+	Title       string `json:"title" binding:"Required;MaxSize(100)"`
+	Description string `json:"description"`
+}
+
+// EditProjectOption options for editing a project
+type EditProjectOption struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	// enum: open,closed
+	State                *string `json:"state"`
+	InProgressBoardID    *int64  `json:"in_progress_board_id"`
+	DoneBoardID          *int64  `json:"done_board_id"`
+	ArchiveDoneAfterDays *int    `json:"archive_done_after_days"`
+}
+
+// CreateProjectBoardOption options for creating a column of a project
+type CreateProjectBoardOption struct {
+	// required:true
+	Title string `json:"title" binding:"Required;MaxSize(100)"`
+}
+
+// AddProjectIssuesOption options for adding the issues of the repositories
+// of the organization matching a query to a project
+type AddProjectIssuesOption struct {
+	BoardID int64 `json:"board_id"`
+	// id of the repository of the issues, 0 for all repositories
+	RepoID int64 `json:"repo_id"`
+	// name of the label of the issues, case insensitive
+	Label string `json:"label" binding:"MaxSize(50)"`
+	// keyword in the title of the issues
+	Keyword string `json:"keyword" binding:"MaxSize(100)"`
+	// enum: open,closed,all
+	State string `json:"state"`
+}
+
+// MoveProjectIssueOption options for moving an issue to another column
+type MoveProjectIssueOption struct {
+	// id of the column, 0 for uncategorized
+	BoardID int64 `json:"board_id"`
+}
+
+// CreateProjectRuleOption options for creating a rule of a project
+type CreateProjectRuleOption struct {
+	BoardID int64 `json:"board_id"`
+	// id of the repository of the issues, 0 for all repositories
+	RepoID  int64  `json:"repo_id"`
+	Label   string `json:"label" binding:"MaxSize(50)"`
+	Keyword string `json:"keyword" binding:"MaxSize(100)"`
+	// add the issues already matching the rule
+	ApplyExisting bool `json:"apply_existing"`
+}
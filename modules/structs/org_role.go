@@ -0,0 +1,35 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// OrgRole represents a custom role of an organization
+type OrgRole struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// permission of the role on each unit, either read or write
+	// example: {"repo.code":"read","repo.issues":"write","repo.pulls":"write"}
+	Permissions map[string]string `json:"permissions"`
+}
+
+// CreateOrgRoleOption options for creating a custom role
+type CreateOrgRoleOption struct {
+	// required: true
+	Name        string `json:"name" binding:"Required;MaxSize(50)"`
+	Description string `json:"description" binding:"MaxSize(255)"`
+	// permission of the role on each unit, either read or write
+	// required: true
+	// example: {"repo.code":"read","repo.issues":"write","repo.pulls":"write"}
+	Permissions map[string]string `json:"permissions" binding:"Required"`
+}
+
+// EditOrgRoleOption options for editing a custom role
+type EditOrgRoleOption struct {
+	Name        *string `json:"name" binding:"MaxSize(50)"`
+	Description *string `json:"description" binding:"MaxSize(255)"`
+	// replaces the permissions of the role, which are applied to its teams
+	// example: {"repo.code":"read","repo.wiki":"write"}
+	Permissions map[string]string `json:"permissions"`
+}
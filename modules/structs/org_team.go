@@ -17,6 +17,14 @@ type Team struct {
 	// example: ["repo.code","repo.issues","repo.ext_issues","repo.wiki","repo.pulls","repo.releases","repo.ext_wiki"]
 	Units            []string `json:"units"`
 	CanCreateOrgRepo bool     `json:"can_create_org_repo"`
+	// how members are picked when the team is requested to review
+	// enum: all,round_robin,load_balance
+	ReviewAssignAlgorithm string `json:"review_assign_algorithm"`
+	ReviewAssignCount     int    `json:"review_assign_count"`
+	// the members of the team inherit the repository access of the parent team
+	ParentID int64 `json:"parent_id"`
+	// custom role which replaces the permission and the units of the team
+	RoleID int64 `json:"role_id"`
 }
 
 // CreateTeamOption options for creating a team
@@ -30,6 +38,11 @@ type CreateTeamOption struct {
 	// example: ["repo.code","repo.issues","repo.ext_issues","repo.wiki","repo.pulls","repo.releases","repo.ext_wiki"]
 	Units            []string `json:"units"`
 	CanCreateOrgRepo bool     `json:"can_create_org_repo"`
+	// enum: all,round_robin,load_balance
+	ReviewAssignAlgorithm string `json:"review_assign_algorithm"`
+	ReviewAssignCount     int    `json:"review_assign_count" binding:"Range(0,100)"`
+	ParentID              int64  `json:"parent_id"`
+	RoleID                int64  `json:"role_id"`
 }
 
 // EditTeamOption options for editing a team
@@ -43,4 +56,11 @@ type EditTeamOption struct {
 	// example: ["repo.code","repo.issues","repo.ext_issues","repo.wiki","repo.pulls","repo.releases","repo.ext_wiki"]
 	Units            []string `json:"units"`
 	CanCreateOrgRepo *bool    `json:"can_create_org_repo"`
+	// enum: all,round_robin,load_balance
+	ReviewAssignAlgorithm *string `json:"review_assign_algorithm"`
+	ReviewAssignCount     *int    `json:"review_assign_count" binding:"Range(0,100)"`
+	// zero makes the team a top level team
+	ParentID *int64 `json:"parent_id"`
+	// zero removes the custom role of the team
+	RoleID *int64 `json:"role_id"`
 }
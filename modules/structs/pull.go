@@ -39,6 +39,14 @@ type PullRequest struct {
 	Head      *PRBranchInfo `json:"head"`
 	MergeBase string        `json:"merge_base"`
 
+	// size classification of the pull request, one of XS, S, M, L, XL and XXL,
+	// empty if it has not been computed yet
+	Size string `json:"size"`
+	// number of changed files, vendored and generated files are not counted
+	ChangedFiles int `json:"changed_files"`
+	// number of added and deleted lines, vendored and generated files are not counted
+	ChangedLines int `json:"changed_lines"`
+
 	// swagger:strfmt date-time
 	Deadline *time.Time `json:"due_date"`
 
@@ -93,3 +101,18 @@ type EditPullRequestOption struct {
 	Deadline       *time.Time `json:"due_date"`
 	RemoveDeadline *bool      `json:"unset_due_date"`
 }
+
+// PullRequestTemplate represents a pull request description template of a repository
+type PullRequestTemplate struct {
+	// path of the template file in the repository
+	FileName string `json:"file_name"`
+	Name     string `json:"name"`
+	About    string `json:"about"`
+	// glob patterns of the target branches the template is selected for
+	Branches []string `json:"branches"`
+	// names of the labels preselected with the template
+	Labels  []string `json:"labels"`
+	Content string   `json:"content"`
+	// whether the template is selected for the branch of the query
+	Selected bool `json:"selected"`
+}
@@ -0,0 +1,26 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// QuickAction represents a result of the command palette
+type QuickAction struct {
+	// Type is one of repo, issue, pull, user, org and action
+	Type string `json:"type"`
+	// Key identifies the result when it is chosen, like "repo:1"
+	Key         string `json:"key"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	// Icon is the name of the octicon of the result
+	Icon string `json:"icon"`
+	// Recent is true if the user chose the result before
+	Recent bool `json:"recent"`
+}
+
+// RecordQuickActionOption options for remembering a chosen result of the command palette
+type RecordQuickActionOption struct {
+	// required: true
+	Key string `json:"key" binding:"Required;MaxSize(100)"`
+}
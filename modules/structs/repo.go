@@ -87,8 +87,11 @@ type Repository struct {
 	AllowRebase               bool             `json:"allow_rebase"`
 	AllowRebaseMerge          bool             `json:"allow_rebase_explicit"`
 	AllowSquash               bool             `json:"allow_squash_merge"`
+	AllowFastForwardOnly      bool             `json:"allow_fast_forward_only_merge"`
+	HasDiscussions            bool             `json:"has_discussions"`
 	AvatarURL                 string           `json:"avatar_url"`
 	Internal                  bool             `json:"internal"`
+	DisableOrgLabelSync       bool             `json:"disable_org_label_sync"`
 }
 
 // CreateRepoOption options when creating repository
@@ -157,8 +160,14 @@ type EditRepoOption struct {
 	AllowRebaseMerge *bool `json:"allow_rebase_explicit,omitempty"`
 	// either `true` to allow squash-merging pull requests, or `false` to prevent squash-merging. `has_pull_requests` must be `true`.
 	AllowSquash *bool `json:"allow_squash_merge,omitempty"`
+	// either `true` to allow fast-forwarding the base branch to the head of pull requests without creating commits, or `false` to prevent it. `has_pull_requests` must be `true`.
+	AllowFastForwardOnly *bool `json:"allow_fast_forward_only_merge,omitempty"`
+	// either `true` to enable discussions for this repository, or `false` to disable them.
+	HasDiscussions *bool `json:"has_discussions,omitempty"`
 	// set to `true` to archive this repository.
 	Archived *bool `json:"archived,omitempty"`
+	// set to `true` to stop synchronizing the labels of the organization owning this repository.
+	DisableOrgLabelSync *bool `json:"disable_org_label_sync,omitempty"`
 }
 
 // CreateBranchRepoOption options when creating a branch in a repository
@@ -186,6 +195,14 @@ type TransferRepoOption struct {
 	TeamIDs *[]int64 `json:"team_ids"`
 }
 
+// EditPinnedReposOption options when changing the repositories pinned on a profile
+// swagger:model
+type EditPinnedReposOption struct {
+	// IDs of the repositories in the order they are shown, at most 6
+	// required: true
+	RepoIDs []int64 `json:"repo_ids"`
+}
+
 // GitServiceType represents a git service
 type GitServiceType int
 
@@ -21,6 +21,24 @@ type Branch struct {
 	EffectiveBranchProtectionName string         `json:"effective_branch_protection_name"`
 }
 
+// BranchDivergence represents a branch and how it diverges from the default branch
+type BranchDivergence struct {
+	Name          string `json:"name"`
+	CommitID      string `json:"commit_id"`
+	BaseBranch    string `json:"base_branch"`
+	CommitsAhead  int    `json:"commits_ahead"`
+	CommitsBehind int    `json:"commits_behind"`
+	// Merged is true when all the commits of the branch are in the default branch or the
+	// latest pull request of the branch has been merged
+	Merged bool `json:"merged"`
+	// Inactive is true when the branch has no commits for the stale branch days
+	Inactive  bool `json:"inactive"`
+	Stale     bool `json:"stale"`
+	Protected bool `json:"protected"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated"`
+}
+
 // BranchProtection represents a branch protection for a repository
 type BranchProtection struct {
 	BranchName                  string   `json:"branch_name"`
@@ -42,7 +60,10 @@ type BranchProtection struct {
 	BlockOnOutdatedBranch       bool     `json:"block_on_outdated_branch"`
 	DismissStaleApprovals       bool     `json:"dismiss_stale_approvals"`
 	RequireSignedCommits        bool     `json:"require_signed_commits"`
+	SignedCommitsAllowedKeys    string   `json:"signed_commits_allowed_keys"`
 	ProtectedFilePatterns       string   `json:"protected_file_patterns"`
+	// the merge styles allowed into the branch, all are allowed when empty
+	MergeStyles []string `json:"merge_styles"`
 	// swagger:strfmt date-time
 	Created time.Time `json:"created_at"`
 	// swagger:strfmt date-time
@@ -70,7 +91,10 @@ type CreateBranchProtectionOption struct {
 	BlockOnOutdatedBranch       bool     `json:"block_on_outdated_branch"`
 	DismissStaleApprovals       bool     `json:"dismiss_stale_approvals"`
 	RequireSignedCommits        bool     `json:"require_signed_commits"`
+	SignedCommitsAllowedKeys    string   `json:"signed_commits_allowed_keys"`
 	ProtectedFilePatterns       string   `json:"protected_file_patterns"`
+	// the merge styles allowed into the branch, all are allowed when empty
+	MergeStyles []string `json:"merge_styles"`
 }
 
 // EditBranchProtectionOption options for editing a branch protection
@@ -93,5 +117,8 @@ type EditBranchProtectionOption struct {
 	BlockOnOutdatedBranch       *bool    `json:"block_on_outdated_branch"`
 	DismissStaleApprovals       *bool    `json:"dismiss_stale_approvals"`
 	RequireSignedCommits        *bool    `json:"require_signed_commits"`
+	SignedCommitsAllowedKeys    *string  `json:"signed_commits_allowed_keys"`
 	ProtectedFilePatterns       *string  `json:"protected_file_patterns"`
+	// the merge styles allowed into the branch, all are allowed when empty
+	MergeStyles []string `json:"merge_styles"`
 }
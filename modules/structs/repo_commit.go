@@ -28,6 +28,12 @@ type CommitUser struct {
 	Date string `json:"date"`
 }
 
+// CommitTrailer represents a trailer of a commit message, e.g. "Reviewed-by: Name <email>"
+type CommitTrailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
 // RepoCommit contains information of a commit in the context of a repository.
 type RepoCommit struct {
 	URL       string      `json:"url"`
@@ -35,6 +41,12 @@ type RepoCommit struct {
 	Committer *CommitUser `json:"committer"`
 	Message   string      `json:"message"`
 	Tree      *CommitMeta `json:"tree"`
+	// the trailers of the last paragraph of the message
+	Trailers []*CommitTrailer `json:"trailers"`
+	// the people credited by the Co-authored-by trailers, except the author
+	CoAuthors []*Identity `json:"co_authors"`
+	// the people credited by the Reviewed-by trailers
+	Reviewers []*Identity `json:"reviewers"`
 }
 
 // Commit contains information generated from a Git commit.
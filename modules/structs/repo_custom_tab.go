@@ -0,0 +1,48 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// RepoCustomTab a tab of the header of a repository linking to an external system
+// swagger:model
+type RepoCustomTab struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// name of the octicon of the tab
+	// example: octicon-book
+	Icon string `json:"icon"`
+	// tabs with a lower sort are shown first
+	Sort int `json:"sort"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateRepoCustomTabOption options for creating a custom tab of a repository
+type CreateRepoCustomTabOption struct {
+	// required:true
+	Name string `json:"name" binding:"Required;MaxSize(50)"`
+	// required:true
+	URL string `json:"url" binding:"Required;ValidUrl"`
+	// name of an octicon, defaults to octicon-link-external
+	// example: octicon-book
+	Icon string `json:"icon"`
+	Sort int    `json:"sort"`
+}
+
+// EditRepoCustomTabOption options for editing a custom tab of a repository
+type EditRepoCustomTabOption struct {
+	Name *string `json:"name" binding:"MaxSize(50)"`
+	URL  *string `json:"url"`
+	// name of an octicon
+	// example: octicon-book
+	Icon *string `json:"icon"`
+	Sort *int    `json:"sort"`
+}
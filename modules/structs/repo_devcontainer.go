@@ -0,0 +1,40 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// DevEnvironment represents the dev environment of a commit of a repository
+type DevEnvironment struct {
+	Ref      string `json:"ref"`
+	CommitID string `json:"commit_id"`
+	// the dev container configuration, null if the repository has none
+	DevContainer *DevContainer `json:"devcontainer"`
+	// links opening the dev environment in the IDEs configured on the instance
+	Launchers []*IDELauncher `json:"launchers"`
+}
+
+// DevContainer represents the dev container configuration of a repository
+type DevContainer struct {
+	Path               string            `json:"path"`
+	Name               string            `json:"name"`
+	Image              string            `json:"image"`
+	Dockerfile         string            `json:"dockerfile"`
+	BuildContext       string            `json:"build_context"`
+	DockerComposeFiles []string          `json:"docker_compose_files"`
+	Service            string            `json:"service"`
+	WorkspaceFolder    string            `json:"workspace_folder"`
+	RemoteUser         string            `json:"remote_user"`
+	Features           []string          `json:"features"`
+	ForwardPorts       []string          `json:"forward_ports"`
+	PostCreateCommand  string            `json:"post_create_command"`
+	Extensions         []string          `json:"extensions"`
+	ContainerEnv       map[string]string `json:"container_env"`
+}
+
+// IDELauncher represents a link opening a dev environment in an IDE
+type IDELauncher struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	URL         string `json:"url"`
+}
@@ -74,8 +74,11 @@ type ContentsResponse struct {
 	GitURL      *string `json:"git_url"`
 	DownloadURL *string `json:"download_url"`
 	// `submodule_git_url` is populated when `type` is `submodule`, otherwise null
-	SubmoduleGitURL *string            `json:"submodule_git_url"`
-	Links           *FileLinksResponse `json:"_links"`
+	SubmoduleGitURL *string `json:"submodule_git_url"`
+	// `submodule_html_url` is the web url of the commit of the submodule when `type` is `submodule` and
+	// the web url could be determined, otherwise null
+	SubmoduleHTMLURL *string            `json:"submodule_html_url"`
+	Links            *FileLinksResponse `json:"_links"`
 }
 
 // FileCommitResponse contains information generated from a Git commit for a repo's file.
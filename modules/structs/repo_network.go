@@ -0,0 +1,18 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// NetworkRepository represents a repository of a fork network along with
+// the tips of its branches
+type NetworkRepository struct {
+	Repository *Repository      `json:"repository"`
+	Branches   []*NetworkBranch `json:"branches"`
+}
+
+// NetworkBranch represents the tip of a branch of a repository of a fork network
+type NetworkBranch struct {
+	Name     string `json:"name"`
+	CommitID string `json:"commit_id"`
+}
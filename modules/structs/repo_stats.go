@@ -0,0 +1,53 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// ContributorStats represents the weekly commits of a contributor to the
+// default branch of a repository
+type ContributorStats struct {
+	// the contributor, which has no username if the commit email does not
+	// belong to a user
+	Author *User `json:"author"`
+	// the commits of the contributor, including the ones crediting the
+	// contributor with a Co-authored-by trailer
+	Total int64 `json:"total"`
+	// the part of total co-authored by the contributor
+	CoAuthored int64              `json:"co_authored"`
+	Weeks      []*ContributorWeek `json:"weeks"`
+}
+
+// ContributorWeek represents the commits of a contributor in a week
+type ContributorWeek struct {
+	// start of the week as unix timestamp
+	Week      int64 `json:"w"`
+	Additions int64 `json:"a"`
+	Deletions int64 `json:"d"`
+	Commits   int64 `json:"c"`
+}
+
+// CommitActivity represents the daily commits to the default branch of a
+// repository in a week
+type CommitActivity struct {
+	// commits from Sunday to Saturday
+	Days  []int64 `json:"days"`
+	Total int64   `json:"total"`
+	// start of the week as unix timestamp
+	Week int64 `json:"week"`
+}
+
+// ParticipationStats represents the weekly commits to the default branch of a
+// repository in the last 52 weeks
+type ParticipationStats struct {
+	All   []int64 `json:"all"`
+	Owner []int64 `json:"owner"`
+}
+
+// ActivityContributor represents the contributions of a user to a repository
+// or to the repositories of an organization, e.g. pushes, issues, pull
+// requests and comments
+type ActivityContributor struct {
+	User          *User `json:"user"`
+	Contributions int64 `json:"contributions"`
+}
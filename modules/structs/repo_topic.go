@@ -10,11 +10,14 @@ import (
 
 // TopicResponse for returning topics
 type TopicResponse struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"topic_name"`
-	RepoCount int       `json:"repo_count"`
-	Created   time.Time `json:"created"`
-	Updated   time.Time `json:"updated"`
+	ID          int64     `json:"id"`
+	Name        string    `json:"topic_name"`
+	Description string    `json:"description"`
+	Logo        string    `json:"logo"`
+	Featured    bool      `json:"featured"`
+	RepoCount   int       `json:"repo_count"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
 }
 
 // TopicName a list of repo topic names
@@ -27,3 +30,30 @@ type RepoTopicOptions struct {
 	// list of topic names
 	Topics []string `json:"topics"`
 }
+
+// EditTopicOption options for editing the curation of a topic
+type EditTopicOption struct {
+	Description *string `json:"description"`
+	// URL of the logo image
+	Logo     *string `json:"logo" binding:"OmitEmpty;MaxSize(255)"`
+	Featured *bool   `json:"featured"`
+}
+
+// MergeTopicOption options for merging a topic into another one
+type MergeTopicOption struct {
+	// name of the topic to merge into
+	// required: true
+	Target string `json:"target" binding:"Required"`
+}
+
+// OrgTopic represents a topic curated by an organization
+type OrgTopic struct {
+	Topic *TopicResponse `json:"topic"`
+	// description of the organization, empty if it uses the global one
+	Description string `json:"description"`
+	// logo of the organization, empty if it uses the global one
+	Logo     string `json:"logo"`
+	Featured bool   `json:"featured"`
+	// number of repositories of the organization with the topic
+	RepoCount int `json:"repo_count"`
+}
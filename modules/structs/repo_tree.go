@@ -23,3 +23,22 @@ type GitTreeResponse struct {
 	Page       int        `json:"page"`
 	TotalCount int        `json:"total_count"`
 }
+
+// DirectoryLastCommits lists the commits which last changed a directory and each of its entries
+type DirectoryLastCommits struct {
+	Path string `json:"path"`
+	SHA  string `json:"sha"`
+	// `commit` is the commit which last changed the directory
+	Commit  *FileCommitResponse `json:"commit"`
+	Entries []*EntryLastCommit  `json:"entries"`
+}
+
+// EntryLastCommit is an entry of a directory with the commit which last changed it
+type EntryLastCommit struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// `type` will be `file`, `dir`, `symlink`, or `submodule`
+	Type   string              `json:"type"`
+	SHA    string              `json:"sha"`
+	Commit *FileCommitResponse `json:"commit"`
+}
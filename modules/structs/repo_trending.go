@@ -0,0 +1,24 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// TrendingRepository represents the new stars, forks and activity of a trending repository
+// over a period
+type TrendingRepository struct {
+	Repository *Repository `json:"repository"`
+	Stars      int         `json:"stars"`
+	Forks      int         `json:"forks"`
+	// number of actions like pushes, issues and comments in the repository
+	Activity int `json:"activity"`
+	Score    int `json:"score"`
+}
+
+// TrendingTopic represents a topic of trending repositories
+type TrendingTopic struct {
+	Name     string `json:"name"`
+	NumRepos int    `json:"repos_count"`
+	// total score of the trending repositories of the topic
+	Score int `json:"score"`
+}
@@ -17,3 +17,11 @@ type WatchInfo struct {
 	URL           string      `json:"url"`
 	RepositoryURL string      `json:"repository_url"`
 }
+
+// WatchPathInfo represents a subscription to the changes of a file or directory of a repository
+type WatchPathInfo struct {
+	Path          string    `json:"path"`
+	CreatedAt     time.Time `json:"created_at"`
+	URL           string    `json:"url"`
+	RepositoryURL string    `json:"repository_url"`
+}
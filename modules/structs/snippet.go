@@ -0,0 +1,90 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Snippet represents a set of files shared by a user outside of any repository
+type Snippet struct {
+	ID          int64  `json:"id"`
+	URL         string `json:"url"`
+	HTMLURL     string `json:"html_url"`
+	Owner       *User  `json:"owner"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// enum: public,limited,private
+	Visibility string `json:"visibility"`
+	Comments   int    `json:"comments"`
+	// files of the requested revision, only returned for a single snippet
+	Files []*SnippetFile `json:"files,omitempty"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// SnippetFile represents a file of a snippet
+type SnippetFile struct {
+	Name    string `json:"name"`
+	Size    int64  `json:"size"`
+	Content string `json:"content"`
+	RawURL  string `json:"raw_url"`
+}
+
+// SnippetRevision represents a revision of the files of a snippet
+type SnippetRevision struct {
+	SHA     string      `json:"sha"`
+	Author  *CommitUser `json:"author"`
+	Message string      `json:"message"`
+}
+
+// SnippetComment represents a comment on a snippet
+type SnippetComment struct {
+	ID      int64  `json:"id"`
+	HTMLURL string `json:"html_url"`
+	Poster  *User  `json:"user"`
+	Body    string `json:"body"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// SnippetFileOption represents a file of a snippet to create or change
+type SnippetFileOption struct {
+	// required: true
+	Name    string `json:"name" binding:"Required;MaxSize(255)"`
+	Content string `json:"content"`
+}
+
+// CreateSnippetOption options for creating a snippet
+type CreateSnippetOption struct {
+	// required: true
+	Title       string `json:"title" binding:"Required;MaxSize(255)"`
+	Description string `json:"description"`
+	// enum: public,limited,private
+	Visibility string `json:"visibility" binding:"In(,public,limited,private)"`
+	// required: true
+	Files []*SnippetFileOption `json:"files" binding:"Required"`
+}
+
+// EditSnippetOption options for editing a snippet
+type EditSnippetOption struct {
+	Title       *string `json:"title" binding:"OmitEmpty;MaxSize(255)"`
+	Description *string `json:"description"`
+	// the visibility is unchanged if empty
+	// enum: public,limited,private
+	Visibility string `json:"visibility" binding:"In(,public,limited,private)"`
+	// files of the new revision of the snippet, replacing all of its files, the files are unchanged if omitted
+	Files []*SnippetFileOption `json:"files"`
+}
+
+// CreateSnippetCommentOption options for creating a comment on a snippet
+type CreateSnippetCommentOption struct {
+	// required: true
+	Body string `json:"body" binding:"Required"`
+}
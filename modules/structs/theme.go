@@ -0,0 +1,26 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Theme represents a theme the users can select
+type Theme struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+	// Builtin is true for the themes of the [ui] THEMES setting, the other fields
+	// are only set for uploaded themes
+	Builtin bool `json:"builtin"`
+	// Version is the version of the uploaded theme in use
+	Version int    `json:"version"`
+	CSSURL  string `json:"css_url"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
@@ -26,6 +26,8 @@ type User struct {
 	Language string `json:"language"`
 	// Is the user an administrator
 	IsAdmin bool `json:"is_admin"`
+	// Is the user a bot account of an organization
+	IsBot bool `json:"is_bot"`
 	// swagger:strfmt date-time
 	LastLogin time.Time `json:"last_login,omitempty"`
 	// swagger:strfmt date-time
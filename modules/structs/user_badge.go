@@ -0,0 +1,52 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Badge represents an achievement shown on the profiles of the users it was
+// granted to
+type Badge struct {
+	ID          int64  `json:"id"`
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// octicon name or URL of the image of the badge
+	Icon string `json:"icon"`
+	// enum: manual,first_pull_merged,member_years
+	Type string `json:"type"`
+	// number of years of membership for member_years badges
+	Threshold int  `json:"threshold"`
+	IsBuiltin bool `json:"is_builtin"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// CreateBadgeOption options for creating a badge
+type CreateBadgeOption struct {
+	// required: true
+	Slug string `json:"slug" binding:"Required;AlphaDashDot;MaxSize(50)"`
+	// required: true
+	Name        string `json:"name" binding:"Required;MaxSize(100)"`
+	Description string `json:"description" binding:"MaxSize(255)"`
+	// octicon name such as octicon-star or URL of an image
+	Icon string `json:"icon" binding:"MaxSize(255)"`
+	// enum: manual,first_pull_merged,member_years
+	Type string `json:"type"`
+	// number of years of membership for member_years badges
+	Threshold int `json:"threshold"`
+}
+
+// EditBadgeOption options for editing a badge
+type EditBadgeOption struct {
+	Name        *string `json:"name" binding:"OmitEmpty;MaxSize(100)"`
+	Description *string `json:"description" binding:"MaxSize(255)"`
+	Icon        *string `json:"icon" binding:"MaxSize(255)"`
+	// enum: manual,first_pull_merged,member_years
+	Type      *string `json:"type"`
+	Threshold *int    `json:"threshold"`
+}
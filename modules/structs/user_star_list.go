@@ -0,0 +1,37 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// StarList represents a named list a user organizes their starred repositories in
+type StarList struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Private     bool   `json:"private"`
+	NumRepos    int64  `json:"repos_count"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateStarListOption options for creating a star list
+type CreateStarListOption struct {
+	// required: true
+	Name        string `json:"name" binding:"Required;MaxSize(100)"`
+	Description string `json:"description" binding:"MaxSize(255)"`
+	Private     bool   `json:"private"`
+}
+
+// EditStarListOption options for editing a star list
+type EditStarListOption struct {
+	Name        *string `json:"name" binding:"OmitEmpty;MaxSize(100)"`
+	Description *string `json:"description" binding:"MaxSize(255)"`
+	Private     *bool   `json:"private"`
+}
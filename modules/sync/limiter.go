@@ -0,0 +1,106 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"context"
+	"sync"
+)
+
+// LimitKey is a resource whose concurrent uses are limited, a Max of 0 or less means unlimited.
+type LimitKey struct {
+	Key string
+	Max int
+}
+
+// Limiter limits the number of concurrent uses of resources identified by keys. A use holds
+// several keys at once, e.g. the user and the repository of a request, and waits until all of
+// them are below their limit.
+type Limiter struct {
+	lock    sync.Mutex
+	counts  map[string]int
+	changed chan struct{}
+}
+
+// NewLimiter initializes and returns a new Limiter.
+func NewLimiter() *Limiter {
+	return &Limiter{
+		counts:  make(map[string]int),
+		changed: make(chan struct{}),
+	}
+}
+
+// full returns the first key at its limit, or nil if they are all below their limit.
+func (l *Limiter) full(keys []LimitKey) *LimitKey {
+	for i := range keys {
+		if keys[i].Max > 0 && l.counts[keys[i].Key] >= keys[i].Max {
+			return &keys[i]
+		}
+	}
+	return nil
+}
+
+// TryAcquire holds the keys if they are all below their limit and returns the function
+// releasing them, else it returns the first key at its limit.
+func (l *Limiter) TryAcquire(keys ...LimitKey) (release func(), full *LimitKey) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if full = l.full(keys); full != nil {
+		return nil, full
+	}
+	return l.hold(keys), nil
+}
+
+// Acquire waits until the keys are all below their limit and holds them, it returns the
+// function releasing them. If the context is done first, it returns the error of the
+// context and the key it waited for.
+func (l *Limiter) Acquire(ctx context.Context, keys ...LimitKey) (release func(), full *LimitKey, err error) {
+	for {
+		l.lock.Lock()
+		full = l.full(keys)
+		if full == nil {
+			release = l.hold(keys)
+			l.lock.Unlock()
+			return release, nil, nil
+		}
+		changed := l.changed
+		l.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, full, ctx.Err()
+		}
+	}
+}
+
+// hold increments the counts of the keys, the lock must be held.
+func (l *Limiter) hold(keys []LimitKey) func() {
+	for _, key := range keys {
+		l.counts[key.Key]++
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.lock.Lock()
+			defer l.lock.Unlock()
+			for _, key := range keys {
+				if l.counts[key.Key]--; l.counts[key.Key] <= 0 {
+					delete(l.counts, key.Key)
+				}
+			}
+			// wake up the waiting uses
+			close(l.changed)
+			l.changed = make(chan struct{})
+		})
+	}
+}
+
+// Count returns the number of current uses of the key.
+func (l *Limiter) Count(key string) int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.counts[key]
+}
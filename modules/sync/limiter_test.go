@@ -0,0 +1,63 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter()
+	user := LimitKey{Key: "user:1", Max: 2}
+	repo := LimitKey{Key: "repo:1", Max: 1}
+	other := LimitKey{Key: "repo:2", Max: 1}
+
+	release1, full := l.TryAcquire(user, repo)
+	assert.Nil(t, full)
+	assert.Equal(t, 1, l.Count("user:1"))
+
+	_, full = l.TryAcquire(user, repo)
+	if assert.NotNil(t, full) {
+		assert.Equal(t, "repo:1", full.Key)
+	}
+
+	release2, full := l.TryAcquire(user, other)
+	assert.Nil(t, full)
+	assert.Equal(t, 2, l.Count("user:1"))
+
+	// the user is at its limit
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, full, err := l.Acquire(ctx, user, LimitKey{Key: "repo:3"})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	if assert.NotNil(t, full) {
+		assert.Equal(t, "user:1", full.Key)
+	}
+
+	// a waiting use gets the keys once they are released
+	acquired := make(chan func())
+	go func() {
+		release, _, err := l.Acquire(context.Background(), user, repo)
+		assert.NoError(t, err)
+		acquired <- release
+	}()
+	release2()
+	select {
+	case <-acquired:
+		t.Fatal("acquired while the repository is at its limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+	release1()
+	release1()
+	release3 := <-acquired
+	assert.Equal(t, 1, l.Count("user:1"))
+	release3()
+	assert.Equal(t, 0, l.Count("user:1"))
+	assert.Equal(t, 0, l.Count("repo:1"))
+}
@@ -7,11 +7,9 @@
 package templates
 
 import (
-	"html/template"
 	"io/ioutil"
 	"path"
 	"strings"
-	texttmpl "text/template"
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -20,11 +18,6 @@ import (
 	"github.com/unknwon/com"
 )
 
-var (
-	subjectTemplates = texttmpl.New("")
-	bodyTemplates    = template.New("")
-)
-
 // HTMLRenderer implements the macaron handler for serving HTML templates.
 func HTMLRenderer() macaron.Handler {
 	return macaron.Renderer(macaron.RenderOptions{
@@ -48,64 +41,37 @@ func JSONRenderer() macaron.Handler {
 	})
 }
 
-// Mailer provides the templates required for sending notification mails.
-func Mailer() (*texttmpl.Template, *template.Template) {
-	for _, funcs := range NewTextFuncMap() {
-		subjectTemplates.Funcs(funcs)
-	}
-	for _, funcs := range NewFuncMap() {
-		bodyTemplates.Funcs(funcs)
-	}
-
-	staticDir := path.Join(setting.StaticRootPath, "templates", "mail")
-
-	if com.IsDir(staticDir) {
-		files, err := com.StatDir(staticDir)
-
-		if err != nil {
-			log.Warn("Failed to read %s templates dir. %v", staticDir, err)
-		} else {
-			for _, filePath := range files {
-				if !strings.HasSuffix(filePath, ".tmpl") {
-					continue
-				}
-
-				content, err := ioutil.ReadFile(path.Join(staticDir, filePath))
-
-				if err != nil {
-					log.Warn("Failed to read static %s template. %v", filePath, err)
-					continue
-				}
-
-				buildSubjectBodyTemplate(subjectTemplates, bodyTemplates, strings.TrimSuffix(filePath, ".tmpl"), content)
-			}
+// MailTemplateFiles returns the content of the mail template files by template name, the
+// custom files replace the static ones.
+func MailTemplateFiles() map[string][]byte {
+	contents := make(map[string][]byte)
+	for _, dir := range []string{
+		path.Join(setting.StaticRootPath, "templates", "mail"),
+		path.Join(setting.CustomPath, "templates", "mail"),
+	} {
+		if !com.IsDir(dir) {
+			continue
 		}
-	}
-
-	customDir := path.Join(setting.CustomPath, "templates", "mail")
-
-	if com.IsDir(customDir) {
-		files, err := com.StatDir(customDir)
 
+		files, err := com.StatDir(dir)
 		if err != nil {
-			log.Warn("Failed to read %s templates dir. %v", customDir, err)
-		} else {
-			for _, filePath := range files {
-				if !strings.HasSuffix(filePath, ".tmpl") {
-					continue
-				}
-
-				content, err := ioutil.ReadFile(path.Join(customDir, filePath))
+			log.Warn("Failed to read %s templates dir. %v", dir, err)
+			continue
+		}
 
-				if err != nil {
-					log.Warn("Failed to read custom %s template. %v", filePath, err)
-					continue
-				}
+		for _, filePath := range files {
+			if !strings.HasSuffix(filePath, ".tmpl") {
+				continue
+			}
 
-				buildSubjectBodyTemplate(subjectTemplates, bodyTemplates, strings.TrimSuffix(filePath, ".tmpl"), content)
+			content, err := ioutil.ReadFile(path.Join(dir, filePath))
+			if err != nil {
+				log.Warn("Failed to read %s template. %v", path.Join(dir, filePath), err)
+				continue
 			}
+
+			contents[strings.TrimSuffix(filePath, ".tmpl")] = content
 		}
 	}
-
-	return subjectTemplates, bodyTemplates
+	return contents
 }
@@ -34,6 +34,7 @@ import (
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/services/gitdiff"
+	"code.gitea.io/gitea/services/mailer"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 
 	"github.com/editorconfig/editorconfig-core-go/v2"
@@ -42,6 +43,11 @@ import (
 // Used from static.go && dynamic.go
 var mailSubjectSplit = regexp.MustCompile(`(?m)^-{3,}[\s]*$`)
 
+func init() {
+	// the templates of the uploaded themes can use the same functions
+	models.ThemeTemplateFuncs = NewFuncMap()
+}
+
 // NewFuncMap returns functions for injecting to templates
 func NewFuncMap() []template.FuncMap {
 	return []template.FuncMap{map[string]interface{}{
@@ -72,6 +78,9 @@ func NewFuncMap() []template.FuncMap {
 		"AppDomain": func() string {
 			return setting.Domain
 		},
+		"SSHDomain": func() string {
+			return setting.SSH.Domain
+		},
 		"DisableGravatar": func() bool {
 			return setting.DisableGravatar
 		},
@@ -85,7 +94,10 @@ func NewFuncMap() []template.FuncMap {
 			return fmt.Sprint(time.Since(startTime).Nanoseconds()/1e6) + "ms"
 		},
 		"AllowedReactions": func() []string {
-			return setting.UI.Reactions
+			return models.AllowedReactions()
+		},
+		"CustomEmojis": func() map[string]string {
+			return emoji.Custom()
 		},
 		"AvatarLink":    models.AvatarLink,
 		"Safe":          Safe,
@@ -109,6 +121,8 @@ func NewFuncMap() []template.FuncMap {
 		"DateFmtShort": func(t time.Time) string {
 			return t.Format("Jan 02, 2006")
 		},
+		"FormatDate":   FormatDate,
+		"FormatNumber": base.FormatNumber,
 		"SizeFmt": base.FileSize,
 		"List":    List,
 		"SubStr": func(str string, start, length int) string {
@@ -240,6 +254,29 @@ func NewFuncMap() []template.FuncMap {
 		"DefaultTheme": func() string {
 			return setting.UI.DefaultTheme
 		},
+		"CurrentTheme": func(signedUser *models.User) string {
+			if signedUser != nil && signedUser.Theme != "" {
+				return signedUser.Theme
+			}
+			return setting.UI.DefaultTheme
+		},
+		"ThemeLink": func(name string) string {
+			theme, err := models.GetUploadedTheme(name)
+			if err != nil {
+				log.Error("GetUploadedTheme: %v", err)
+			}
+			if theme != nil {
+				return theme.CSSLink()
+			}
+			return setting.StaticURLPrefix + "/css/theme-" + name + ".css?v=" + base.EncodeMD5(setting.AppVer)
+		},
+		"RenderThemeTemplate": func(name, part string, data interface{}) template.HTML {
+			html, err := models.RenderUploadedThemeTemplate(name, part, data)
+			if err != nil {
+				log.Error("RenderUploadedThemeTemplate(%s, %s): %v", name, part, err)
+			}
+			return html
+		},
 		"dict": func(values ...interface{}) (map[string]interface{}, error) {
 			if len(values) == 0 {
 				return nil, errors.New("invalid dict call")
@@ -368,6 +405,8 @@ func NewTextFuncMap() []texttmpl.FuncMap {
 		"DateFmtShort": func(t time.Time) string {
 			return t.Format("Jan 02, 2006")
 		},
+		"FormatDate":   FormatDate,
+		"FormatNumber": base.FormatNumber,
 		"List": List,
 		"SubStr": func(str string, start, length int) string {
 			if len(str) == 0 {
@@ -475,6 +514,24 @@ func Escape(raw string) string {
 	return html.EscapeString(raw)
 }
 
+// FormatDate formats a time or a timestamp with an ICU date pattern in the default UI
+// location, the time format of the language is used if the pattern is empty.
+func FormatDate(t interface{}, pattern, lang string) string {
+	var tm time.Time
+	switch v := t.(type) {
+	case time.Time:
+		tm = v.In(setting.DefaultUILocation)
+	case timeutil.TimeStamp:
+		tm = v.AsTime()
+	default:
+		return ""
+	}
+	if pattern == "" {
+		return tm.Format(timeutil.GetTimeFormat(lang))
+	}
+	return timeutil.FormatDate(tm, pattern, lang)
+}
+
 // List traversings the list
 func List(l *list.List) chan interface{} {
 	e := l.Front()
@@ -582,6 +639,9 @@ func ReactionToEmoji(reaction string) template.HTML {
 	if val != nil {
 		return template.HTML(val.Emoji)
 	}
+	if link, ok := emoji.FromCustom(reaction); ok {
+		return template.HTML(fmt.Sprintf(`<img class="emoji" alt=":%s:" src="%s"></img>`, html.EscapeString(reaction), html.EscapeString(link)))
+	}
 	return template.HTML(fmt.Sprintf(`<img src=%s/img/emoji/%s.png></img>`, setting.StaticURLPrefix, reaction))
 }
 
@@ -748,7 +808,95 @@ func MigrationIcon(hostname string) string {
 	}
 }
 
-func buildSubjectBodyTemplate(stpl *texttmpl.Template, btpl *template.Template, name string, content []byte) {
+// Mailer provides the templates required for sending notification mails, the overrides
+// replace the template files of the same name.
+func Mailer(overrides map[string]string) (*texttmpl.Template, *template.Template) {
+	subjectTemplates := texttmpl.New("")
+	bodyTemplates := template.New("")
+	for _, funcs := range NewTextFuncMap() {
+		subjectTemplates.Funcs(funcs)
+	}
+	for _, funcs := range NewFuncMap() {
+		bodyTemplates.Funcs(funcs)
+	}
+
+	files := MailTemplateFiles()
+	for name, content := range files {
+		if override, ok := overrides[name]; ok {
+			content = []byte(override)
+		}
+		if err := buildSubjectBodyTemplate(subjectTemplates, bodyTemplates, name, content); err != nil {
+			log.Warn("Failed to parse mail template: %v", err)
+		}
+	}
+	for name, content := range overrides {
+		if _, ok := files[name]; ok {
+			continue
+		}
+		if err := buildSubjectBodyTemplate(subjectTemplates, bodyTemplates, name, []byte(content)); err != nil {
+			log.Warn("Failed to parse mail template: %v", err)
+		}
+	}
+
+	return subjectTemplates, bodyTemplates
+}
+
+// ReloadMailer initializes the mail renderer with the mail templates, the versions in use of
+// the customized templates replace the files. The files are used alone when the customized
+// templates cannot be read.
+func ReloadMailer() error {
+	var versions map[string]*models.MailTemplateVersion
+	if models.HasEngine {
+		var err error
+		if versions, err = models.GetMailTemplateOverrides(); err != nil {
+			mailer.InitMailRender(Mailer(nil))
+			return err
+		}
+	}
+
+	overrides := make(map[string]string, len(versions))
+	texts := make(map[string]*texttmpl.Template, len(versions))
+	for name, v := range versions {
+		overrides[name] = v.Content
+		if strings.TrimSpace(v.TextContent) == "" {
+			continue
+		}
+		text, err := NewMailTextTemplate(name, v.TextContent)
+		if err != nil {
+			log.Warn("Failed to parse mail template [%s/text]: %v", name, err)
+			continue
+		}
+		texts[name] = text
+	}
+	subjectTemplates, bodyTemplates := Mailer(overrides)
+	mailer.InitMailRender(subjectTemplates, bodyTemplates, texts)
+	return nil
+}
+
+// ParseMailTemplate checks that the content is a valid mail template, with an optional
+// subject before a "---" line.
+func ParseMailTemplate(name, content string) error {
+	subjectTemplates := texttmpl.New("")
+	bodyTemplates := template.New("")
+	for _, funcs := range NewTextFuncMap() {
+		subjectTemplates.Funcs(funcs)
+	}
+	for _, funcs := range NewFuncMap() {
+		bodyTemplates.Funcs(funcs)
+	}
+	return buildSubjectBodyTemplate(subjectTemplates, bodyTemplates, name, []byte(content))
+}
+
+// NewMailTextTemplate parses the template of the plain text alternative of a mail.
+func NewMailTextTemplate(name, content string) (*texttmpl.Template, error) {
+	tmpl := texttmpl.New(name)
+	for _, funcs := range NewTextFuncMap() {
+		tmpl.Funcs(funcs)
+	}
+	return tmpl.Parse(content)
+}
+
+func buildSubjectBodyTemplate(stpl *texttmpl.Template, btpl *template.Template, name string, content []byte) error {
 	// Split template into subject and body
 	var subjectContent []byte
 	bodyContent := content
@@ -759,10 +907,11 @@ func buildSubjectBodyTemplate(stpl *texttmpl.Template, btpl *template.Template,
 	}
 	if _, err := stpl.New(name).
 		Parse(string(subjectContent)); err != nil {
-		log.Warn("Failed to parse template [%s/subject]: %v", name, err)
+		return fmt.Errorf("[%s/subject]: %v", name, err)
 	}
 	if _, err := btpl.New(name).
 		Parse(string(bodyContent)); err != nil {
-		log.Warn("Failed to parse template [%s/body]: %v", name, err)
+		return fmt.Errorf("[%s/body]: %v", name, err)
 	}
+	return nil
 }
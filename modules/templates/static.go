@@ -9,12 +9,10 @@ package templates
 import (
 	"bytes"
 	"fmt"
-	"html/template"
 	"io"
 	"io/ioutil"
 	"path"
 	"strings"
-	texttmpl "text/template"
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -23,11 +21,6 @@ import (
 	"github.com/unknwon/com"
 )
 
-var (
-	subjectTemplates = texttmpl.New("")
-	bodyTemplates    = template.New("")
-)
-
 type templateFileSystem struct {
 	files []macaron.TemplateFile
 }
@@ -132,15 +125,10 @@ func JSONRenderer() macaron.Handler {
 	})
 }
 
-// Mailer provides the templates required for sending notification mails.
-func Mailer() (*texttmpl.Template, *template.Template) {
-	for _, funcs := range NewTextFuncMap() {
-		subjectTemplates.Funcs(funcs)
-	}
-	for _, funcs := range NewFuncMap() {
-		bodyTemplates.Funcs(funcs)
-	}
-
+// MailTemplateFiles returns the content of the mail template files by template name, the
+// custom files replace the embedded ones.
+func MailTemplateFiles() map[string][]byte {
+	contents := make(map[string][]byte)
 	for _, assetPath := range AssetNames() {
 		if !strings.HasPrefix(assetPath, "mail/") {
 			continue
@@ -157,16 +145,7 @@ func Mailer() (*texttmpl.Template, *template.Template) {
 			continue
 		}
 
-		buildSubjectBodyTemplate(subjectTemplates,
-			bodyTemplates,
-			strings.TrimPrefix(
-				strings.TrimSuffix(
-					assetPath,
-					".tmpl",
-				),
-				"mail/",
-			),
-			content)
+		contents[strings.TrimPrefix(strings.TrimSuffix(assetPath, ".tmpl"), "mail/")] = content
 	}
 
 	customDir := path.Join(setting.CustomPath, "templates", "mail")
@@ -189,18 +168,12 @@ func Mailer() (*texttmpl.Template, *template.Template) {
 					continue
 				}
 
-				buildSubjectBodyTemplate(subjectTemplates,
-					bodyTemplates,
-					strings.TrimSuffix(
-						filePath,
-						".tmpl",
-					),
-					content)
+				contents[strings.TrimSuffix(filePath, ".tmpl")] = content
 			}
 		}
 	}
 
-	return subjectTemplates, bodyTemplates
+	return contents
 }
 
 func Asset(name string) ([]byte, error) {
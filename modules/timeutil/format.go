@@ -0,0 +1,241 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package timeutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unknwon/i18n"
+)
+
+var (
+	// langDatePatterns are the default ICU date patterns of the languages, they
+	// match the formats of langTimeFormats.
+	langDatePatterns = map[string]string{
+		"en-US": "EEE, dd MMM yyyy HH:mm:ss z",
+		"zh-CN": "yyyy年MM月dd日 HH时mm分ss秒",
+		"lv-LV": "dd.MM.yyyy. HH:mm:ss",
+		"de-DE": "dd.MM.yyyy HH:mm:ss",
+		"fr-FR": "dd/MM/yyyy HH:mm:ss",
+		"es-ES": "dd/MM/yyyy HH:mm:ss",
+		"pt-BR": "dd/MM/yyyy HH:mm:ss",
+		"ru-RU": "dd.MM.yyyy HH:mm:ss",
+		"ja-JP": "yyyy/MM/dd HH:mm:ss",
+		"nl-NL": "dd-MM-yyyy HH:mm:ss",
+	}
+
+	englishMonths = []string{"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"}
+	englishWeekdays = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+)
+
+// GetLangDatePattern returns the default ICU date pattern of the language, or an
+// empty string if it has none.
+func GetLangDatePattern(lang string) string {
+	return langDatePatterns[lang]
+}
+
+// ErrInvalidDatePattern represents an invalid ICU date pattern.
+type ErrInvalidDatePattern struct {
+	Pattern string
+	Reason  string
+}
+
+func (err ErrInvalidDatePattern) Error() string {
+	return fmt.Sprintf("invalid date pattern %q: %s", err.Pattern, err.Reason)
+}
+
+// IsErrInvalidDatePattern checks if an error is a ErrInvalidDatePattern.
+func IsErrInvalidDatePattern(err error) bool {
+	_, ok := err.(ErrInvalidDatePattern)
+	return ok
+}
+
+// icuDateFields are the supported letters of the ICU date patterns
+const icuDateFields = "yMLdEHhkKmsSaDzZ"
+
+// icuToken is a field of an ICU date pattern, or a literal text if Field is 0.
+type icuToken struct {
+	Field rune
+	Width int
+	Text  string
+}
+
+// parseICUDatePattern splits an ICU date pattern like "dd.MM.yyyy HH:mm" into its fields
+// and literal texts, text between single quotes is literal and two single quotes are a quote.
+func parseICUDatePattern(pattern string) ([]icuToken, error) {
+	runes := []rune(pattern)
+	tokens := make([]icuToken, 0, len(runes))
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			tokens = append(tokens, icuToken{Text: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case c == '\'':
+			if i+1 < len(runes) && runes[i+1] == '\'' {
+				literal.WriteRune('\'')
+				i += 2
+				continue
+			}
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						literal.WriteRune('\'')
+						i++
+						continue
+					}
+					closed = true
+					i++
+					break
+				}
+				literal.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, ErrInvalidDatePattern{pattern, "unterminated quote"}
+			}
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			if !strings.ContainsRune(icuDateFields, c) {
+				return nil, ErrInvalidDatePattern{pattern, fmt.Sprintf("unsupported field %q", c)}
+			}
+			width := 1
+			for i+width < len(runes) && runes[i+width] == c {
+				width++
+			}
+			flush()
+			tokens = append(tokens, icuToken{Field: c, Width: width})
+			i += width
+		default:
+			literal.WriteRune(c)
+			i++
+		}
+	}
+	flush()
+	return tokens, nil
+}
+
+// ValidateDatePattern checks that an ICU date pattern is supported.
+func ValidateDatePattern(pattern string) error {
+	_, err := parseICUDatePattern(pattern)
+	return err
+}
+
+// localizedNames returns the translation of a comma separated list of names, or the
+// fallback if the translation is missing.
+func localizedNames(lang, key string, fallback []string) []string {
+	names := strings.Split(i18n.Tr(lang, key), ",")
+	if len(names) != len(fallback) {
+		return fallback
+	}
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	return names
+}
+
+func padNumber(n, width int) string {
+	s := strconv.Itoa(n)
+	if len(s) < width {
+		s = strings.Repeat("0", width-len(s)) + s
+	}
+	return s
+}
+
+func formatICUField(t time.Time, tok icuToken, lang string) string {
+	switch tok.Field {
+	case 'y':
+		if tok.Width == 2 {
+			return padNumber(t.Year()%100, 2)
+		}
+		return padNumber(t.Year(), tok.Width)
+	case 'M', 'L':
+		switch {
+		case tok.Width >= 4:
+			return localizedNames(lang, "tool.month_names", englishMonths)[t.Month()-1]
+		case tok.Width == 3:
+			return localizedNames(lang, "tool.month_names_short", shortNames(englishMonths))[t.Month()-1]
+		}
+		return padNumber(int(t.Month()), tok.Width)
+	case 'd':
+		return padNumber(t.Day(), tok.Width)
+	case 'D':
+		return padNumber(t.YearDay(), tok.Width)
+	case 'E':
+		if tok.Width >= 4 {
+			return localizedNames(lang, "tool.weekday_names", englishWeekdays)[t.Weekday()]
+		}
+		return localizedNames(lang, "tool.weekday_names_short", shortNames(englishWeekdays))[t.Weekday()]
+	case 'H':
+		return padNumber(t.Hour(), tok.Width)
+	case 'k':
+		if t.Hour() == 0 {
+			return padNumber(24, tok.Width)
+		}
+		return padNumber(t.Hour(), tok.Width)
+	case 'h':
+		if h := t.Hour() % 12; h != 0 {
+			return padNumber(h, tok.Width)
+		}
+		return padNumber(12, tok.Width)
+	case 'K':
+		return padNumber(t.Hour()%12, tok.Width)
+	case 'm':
+		return padNumber(t.Minute(), tok.Width)
+	case 's':
+		return padNumber(t.Second(), tok.Width)
+	case 'S':
+		fraction := padNumber(t.Nanosecond(), 9)
+		if tok.Width < 9 {
+			return fraction[:tok.Width]
+		}
+		return fraction + strings.Repeat("0", tok.Width-9)
+	case 'a':
+		if t.Hour() < 12 {
+			return "AM"
+		}
+		return "PM"
+	case 'z':
+		return t.Format("MST")
+	case 'Z':
+		return t.Format("-0700")
+	}
+	return ""
+}
+
+func shortNames(names []string) []string {
+	short := make([]string, len(names))
+	for i, name := range names {
+		short[i] = name[:3]
+	}
+	return short
+}
+
+// FormatDate formats a time with an ICU date pattern like "dd.MM.yyyy HH:mm", the names of
+// the months and the days are translated to the language. An invalid pattern falls back
+// to the time format of the language.
+func FormatDate(t time.Time, pattern, lang string) string {
+	tokens, err := parseICUDatePattern(pattern)
+	if err != nil || len(tokens) == 0 {
+		return t.Format(GetTimeFormat(lang))
+	}
+	var buf strings.Builder
+	for _, tok := range tokens {
+		if tok.Field == 0 {
+			buf.WriteString(tok.Text)
+		} else {
+			buf.WriteString(formatICUField(t, tok, lang))
+		}
+	}
+	return buf.String()
+}
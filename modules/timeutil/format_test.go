@@ -0,0 +1,47 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package timeutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2021, time.March, 7, 15, 4, 5, 120000000, time.UTC)
+	for pattern, expected := range map[string]string{
+		"yyyy-MM-dd":                  "2021-03-07",
+		"dd.MM.yy HH:mm":              "07.03.21 15:04",
+		"d/M/y":                       "7/3/2021",
+		"EEEE, MMMM d, yyyy":          "Sunday, March 7, 2021",
+		"EEE dd MMM":                  "Sun 07 Mar",
+		"h:mm a":                      "3:04 PM",
+		"HH:mm:ss.SSS Z":              "15:04:05.120 +0000",
+		"yyyy'年'MM'月'dd'日'":           "2021年03月07日",
+		"yyyy年MM月dd日":                 "2021年03月07日",
+		"'at' HH 'o''clock'":          "at 15 o'clock",
+		"D":                           "66",
+		"dd MMM yyyy HH:mm:ss z":      "07 Mar 2021 15:04:05 UTC",
+		"EEE, dd MMM yyyy HH:mm:ss z": "Sun, 07 Mar 2021 15:04:05 UTC",
+	} {
+		assert.Equal(t, expected, FormatDate(date, pattern, "en-US"), pattern)
+	}
+
+	// invalid patterns use the time format of the language
+	assert.Equal(t, date.Format(time.RFC1123), FormatDate(date, "yyyy 'unterminated", "en-US"))
+	assert.Equal(t, date.Format(time.RFC1123), FormatDate(date, "", "en-US"))
+}
+
+func TestValidateDatePattern(t *testing.T) {
+	assert.NoError(t, ValidateDatePattern("dd.MM.yyyy HH:mm"))
+	assert.NoError(t, ValidateDatePattern("'week' yyyy"))
+	assert.True(t, IsErrInvalidDatePattern(ValidateDatePattern("yyyy 'unterminated")))
+	assert.True(t, IsErrInvalidDatePattern(ValidateDatePattern("yyyy-QQ")))
+	for _, pattern := range langDatePatterns {
+		assert.NoError(t, ValidateDatePattern(pattern), pattern)
+	}
+}
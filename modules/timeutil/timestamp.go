@@ -75,6 +75,14 @@ func (ts TimeStamp) FormatShort() string {
 	return ts.Format("Jan 02, 2006")
 }
 
+// FormatPattern formats timestamp with an ICU date pattern, as short if the pattern is empty
+func (ts TimeStamp) FormatPattern(pattern, lang string) string {
+	if pattern == "" {
+		return ts.FormatShort()
+	}
+	return FormatDate(ts.AsTime(), pattern, lang)
+}
+
 // FormatDate formats a date in YYYY-MM-DD server time zone
 func (ts TimeStamp) FormatDate() string {
 	return time.Unix(int64(ts), 0).String()[:10]
@@ -0,0 +1,139 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"gitea.com/macaron/macaron"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "code.gitea.io/gitea"
+
+// contextKey is the key of the context of the request span in the data of the
+// macaron context
+const contextKey = "TracingContext"
+
+var provider *sdktrace.TracerProvider
+
+// Options represents the configuration of the span exporter
+type Options struct {
+	// Endpoint is the address of the OTLP gRPC receiver
+	Endpoint    string
+	Insecure    bool
+	ServiceName string
+	// SampleRatio is the fraction of traces recorded which are not continued from a caller
+	SampleRatio float64
+}
+
+// Init starts exporting spans to an OTLP receiver
+func Init(ctx context.Context, opts Options) error {
+	if provider != nil {
+		return nil
+	}
+
+	exporterOpts := []otlp.ExporterOption{otlp.WithAddress(opts.Endpoint)}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlp.WithInsecure())
+	}
+	exporter, err := otlp.NewExporter(ctx, exporterOpts...)
+	if err != nil {
+		return err
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithConfig(sdktrace.Config{
+			DefaultSampler: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio)),
+		}),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.ServiceNameKey.String(opts.ServiceName))),
+		sdktrace.WithBatcher(exporter),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return nil
+}
+
+// Shutdown exports the remaining spans and stops the exporter
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	return provider.Shutdown(ctx)
+}
+
+// IsEnabled returns if spans are recorded
+func IsEnabled() bool {
+	return provider != nil
+}
+
+// Start starts a span as child of the span in ctx, if there is one
+func Start(ctx context.Context, name string, attrs ...label.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartChild starts a span as child of the span in ctx. Nothing is recorded if
+// there is none, so that the operations run outside of a trace, e.g. queries of
+// background tasks, do not start a trace each.
+func StartChild(ctx context.Context, name string, attrs ...label.KeyValue) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return Start(ctx, name, attrs...)
+}
+
+// End ends a span and marks it as failed if err is not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Context returns the context of the span of the request, which spans started
+// for the request should be children of. It is the context of the request if
+// the request is not traced.
+func Context(ctx *macaron.Context) context.Context {
+	if spanCtx, ok := ctx.Data[contextKey].(context.Context); ok {
+		return spanCtx
+	}
+	return ctx.Req.Context()
+}
+
+// Middleware starts a span for every request, continuing the trace of the
+// caller if the request carries a W3C traceparent header
+func Middleware() macaron.Handler {
+	return func(ctx *macaron.Context) {
+		req := ctx.Req.Request
+		parent := otel.GetTextMapPropagator().Extract(req.Context(), req.Header)
+		spanCtx, span := otel.Tracer(tracerName).Start(parent, "HTTP "+req.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", "", req)...),
+		)
+		defer span.End()
+
+		// the request itself is left as is because the handlers mapped by
+		// macaron and the callers share it
+		ctx.Data[contextKey] = spanCtx
+		ctx.Next()
+
+		status := ctx.Resp.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(status))
+	}
+}
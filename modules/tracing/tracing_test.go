@@ -0,0 +1,82 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gitea.com/macaron/macaron"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/export/trace/tracetest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	m := macaron.New()
+	m.Use(Middleware())
+	m.Post("/", func(ctx *macaron.Context, req *http.Request) {
+		// the form is parsed into the request shared with the mapped handlers
+		assert.Equal(t, "value", ctx.Query("key"))
+		assert.Equal(t, "value", req.Form.Get("key"))
+		_, span := Start(Context(ctx), "child")
+		End(span, errors.New("failed"))
+		ctx.Resp.WriteHeader(http.StatusNotFound)
+	})
+
+	req, err := http.NewRequest("POST", "/", strings.NewReader("key=value"))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	m.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		child, server := spans[0], spans[1]
+		assert.Equal(t, "child", child.Name)
+		assert.Equal(t, codes.Error, child.StatusCode)
+		assert.Equal(t, server.SpanContext.SpanID, child.ParentSpanID)
+
+		assert.Equal(t, "HTTP POST", server.Name)
+		assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID.String())
+		assert.Equal(t, "00f067aa0ba902b7", server.ParentSpanID.String())
+		assert.Equal(t, codes.Error, server.StatusCode)
+	}
+}
+
+func TestStartChild(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	// nothing is recorded outside of a trace
+	ctx, span := StartChild(context.Background(), "orphan")
+	assert.False(t, span.IsRecording())
+	End(span, nil)
+	assert.Empty(t, exporter.GetSpans())
+
+	parentCtx, parent := Start(ctx, "parent")
+	_, child := StartChild(parentCtx, "child")
+	End(child, nil)
+	End(parent, nil)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "child", spans[0].Name)
+		assert.Equal(t, spans[1].SpanContext.SpanID, spans[0].ParentSpanID)
+	}
+}
@@ -7,6 +7,8 @@ package upload
 import (
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 
 	"code.gitea.io/gitea/modules/log"
@@ -44,3 +46,74 @@ func VerifyAllowedContentType(buf []byte, allowedTypes []string) error {
 	log.Info("Attachment with type %s blocked from upload", fileType)
 	return ErrFileTypeForbidden{Type: fileType}
 }
+
+// ErrFileTooLarge file larger than allowed error
+type ErrFileTooLarge struct {
+	Type    string
+	Size    int64
+	MaxSize int64
+}
+
+// IsErrFileTooLarge checks if an error is a ErrFileTooLarge.
+func IsErrFileTooLarge(err error) bool {
+	_, ok := err.(ErrFileTooLarge)
+	return ok
+}
+
+func (err ErrFileTooLarge) Error() string {
+	return fmt.Sprintf("File of type %s is larger than %d bytes: %d bytes", err.Type, err.MaxSize, err.Size)
+}
+
+// TypeSizeLimits are the maximum sizes in MB of the files by content type, a type may be a
+// wildcard like "image/*".
+type TypeSizeLimits map[string]int64
+
+// ParseTypeSizeLimits parses a comma separated list of limits like "image/*:10, video/mp4:50".
+func ParseTypeSizeLimits(s string) (TypeSizeLimits, error) {
+	limits := make(TypeSizeLimits)
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		i := strings.LastIndex(field, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid size limit %q", field)
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(field[i+1:]), 10, 64)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid size of the limit %q", field)
+		}
+		limits[strings.ToLower(strings.TrimSpace(field[:i]))] = size
+	}
+	return limits, nil
+}
+
+// String returns the limits in the format read by ParseTypeSizeLimits
+func (limits TypeSizeLimits) String() string {
+	types := make([]string, 0, len(limits))
+	for typ := range limits {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	fields := make([]string, 0, len(types))
+	for _, typ := range types {
+		fields = append(fields, fmt.Sprintf("%s:%d", typ, limits[typ]))
+	}
+	return strings.Join(fields, ",")
+}
+
+// Limit returns the maximum size in MB of a file of the content type, 0 if there is none.
+// The limit of the exact type is used before the one of its wildcard.
+func (limits TypeSizeLimits) Limit(contentType string) int64 {
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if size, ok := limits[contentType]; ok {
+		return size
+	}
+	if i := strings.Index(contentType, "/"); i > 0 {
+		if size, ok := limits[contentType[:i]+"/*"]; ok {
+			return size
+		}
+	}
+	return limits["*/*"]
+}
@@ -45,3 +45,25 @@ func TestUpload(t *testing.T) {
 		assert.Equal(t, kase.err, VerifyAllowedContentType(kase.data, kase.allowedTypes))
 	}
 }
+
+func TestTypeSizeLimits(t *testing.T) {
+	limits, err := ParseTypeSizeLimits(" image/*:10, video/mp4 : 50,IMAGE/PNG:2, ")
+	assert.NoError(t, err)
+	assert.EqualValues(t, TypeSizeLimits{"image/*": 10, "video/mp4": 50, "image/png": 2}, limits)
+	assert.Equal(t, "image/*:10,image/png:2,video/mp4:50", limits.String())
+
+	assert.EqualValues(t, 2, limits.Limit("image/png"))
+	assert.EqualValues(t, 10, limits.Limit("image/jpeg"))
+	assert.EqualValues(t, 50, limits.Limit("video/mp4"))
+	assert.EqualValues(t, 0, limits.Limit("video/webm"))
+	assert.EqualValues(t, 0, limits.Limit("text/plain; charset=utf-8"))
+
+	limits, err = ParseTypeSizeLimits("*/*:5")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, limits.Limit("text/plain; charset=utf-8"))
+
+	for _, invalid := range []string{"image/png", ":10", "image/png:0", "image/png:big"} {
+		_, err := ParseTypeSizeLimits(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	jpegSOI       = []byte{0xff, 0xd8}
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+	exifHeader    = []byte("Exif\x00\x00")
+	errMalformed  = errors.New("malformed image")
+	pngTextChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+)
+
+// ErrMalformedImage is returned when the metadata of an image cannot be removed
+type ErrMalformedImage struct {
+	Type string
+	Err  error
+}
+
+// IsErrMalformedImage checks if an error is a ErrMalformedImage.
+func IsErrMalformedImage(err error) bool {
+	_, ok := err.(ErrMalformedImage)
+	return ok
+}
+
+func (err ErrMalformedImage) Error() string {
+	return fmt.Sprintf("Unable to remove the metadata of the %s image: %v", err.Type, err.Err)
+}
+
+// StripImageMetadata removes the metadata of a JPEG or PNG image which may reveal private
+// information, like the location and the camera of a photo, other data is returned as is.
+// The orientation of a JPEG image is kept so that it is still displayed upright.
+func StripImageMetadata(data []byte) ([]byte, error) {
+	var (
+		stripped []byte
+		err      error
+	)
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg":
+		stripped, err = stripJPEGMetadata(data)
+	case "image/png":
+		stripped, err = stripPNGMetadata(data)
+	default:
+		return data, nil
+	}
+	if err != nil {
+		return nil, ErrMalformedImage{Type: contentType, Err: err}
+	}
+	return stripped, nil
+}
+
+// jpegMarkerAt returns the position of the marker of the JPEG segment starting at pos, after
+// the 0xff fill bytes which may precede it
+func jpegMarkerAt(data []byte, pos int) int {
+	for pos+2 < len(data) && data[pos] == 0xff && data[pos+1] == 0xff {
+		pos++
+	}
+	return pos
+}
+
+// isJPEGStandaloneMarker returns true if the JPEG marker has no length nor payload
+func isJPEGStandaloneMarker(marker byte) bool {
+	return marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7)
+}
+
+// stripJPEGMetadata removes the APP1 (EXIF, XMP) and APP13 (IPTC) segments and the comments
+// of a JPEG image, an EXIF segment with only the orientation replaces the original one.
+func stripJPEGMetadata(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, jpegSOI) {
+		return nil, errMalformed
+	}
+	var (
+		kept        [][]byte
+		orientation int
+		pos         = len(jpegSOI)
+	)
+	for {
+		pos = jpegMarkerAt(data, pos)
+		if pos+4 > len(data) || data[pos] != 0xff {
+			return nil, errMalformed
+		}
+		marker := data[pos+1]
+		if marker == 0xda {
+			// start of scan, the compressed data follows until the end of the image
+			break
+		}
+		if isJPEGStandaloneMarker(marker) {
+			kept = append(kept, data[pos:pos+2])
+			pos += 2
+			continue
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		if end < pos+4 || end > len(data) {
+			return nil, errMalformed
+		}
+		segment := data[pos:end]
+		switch marker {
+		case 0xe1:
+			if payload := segment[4:]; bytes.HasPrefix(payload, exifHeader) && orientation == 0 {
+				orientation = exifOrientation(payload[len(exifHeader):])
+			}
+		case 0xed, 0xfe:
+		default:
+			kept = append(kept, segment)
+		}
+		pos = end
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(jpegSOI)
+	// the EXIF segment follows the JFIF one if there is one
+	if len(kept) > 0 && kept[0][1] == 0xe0 {
+		out.Write(kept[0])
+		kept = kept[1:]
+	}
+	if orientation > 1 {
+		out.Write(exifOrientationSegment(orientation))
+	}
+	for _, segment := range kept {
+		out.Write(segment)
+	}
+	out.Write(data[pos:])
+	return out.Bytes(), nil
+}
+
+// exifOrientation returns the orientation tag of the first IFD of EXIF data, 0 if there is none
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) || ifd < 8 {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		// the orientation is a SHORT stored in the value field
+		if order.Uint16(tiff[entry:]) == 0x0112 && order.Uint16(tiff[entry+2:]) == 3 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// exifOrientationSegment returns an APP1 segment whose EXIF data only has the orientation
+func exifOrientationSegment(orientation int) []byte {
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // big endian header, first IFD at offset 8
+		0, 1, // one entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, byte(orientation), 0, 0, // orientation, SHORT, count 1
+		0, 0, 0, 0, // no next IFD
+	}
+	segment := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+len(exifHeader)+len(tiff)))
+	segment = append(segment, exifHeader...)
+	return append(segment, tiff...)
+}
+
+// stripPNGMetadata removes the EXIF, text and time chunks of a PNG image
+func stripPNGMetadata(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errMalformed
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+	for pos := len(pngSignature); pos < len(data); {
+		if pos+8 > len(data) {
+			return nil, errMalformed
+		}
+		// length, type, data and CRC
+		end := pos + 12 + int(binary.BigEndian.Uint32(data[pos:]))
+		if end > len(data) || end < pos {
+			return nil, errMalformed
+		}
+		if !pngTextChunks[string(data[pos+4:pos+8])] {
+			out.Write(data[pos:end])
+		}
+		pos = end
+	}
+	return out.Bytes(), nil
+}
+
+// ImageOrientation returns the EXIF orientation of a JPEG image, from 1 to 8, or 0 if it has none
+func ImageOrientation(data []byte) int {
+	if !bytes.HasPrefix(data, jpegSOI) {
+		return 0
+	}
+	for pos := jpegMarkerAt(data, len(jpegSOI)); pos+4 <= len(data) && data[pos] == 0xff && data[pos+1] != 0xda; pos = jpegMarkerAt(data, pos) {
+		if isJPEGStandaloneMarker(data[pos+1]) {
+			pos += 2
+			continue
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		if end > len(data) {
+			return 0
+		}
+		if payload := data[pos+4 : end]; data[pos+1] == 0xe1 && bytes.HasPrefix(payload, exifHeader) {
+			return exifOrientation(payload[len(exifHeader):])
+		}
+		pos = end
+	}
+	return 0
+}
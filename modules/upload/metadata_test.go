@@ -0,0 +1,97 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exifSegment returns an APP1 segment with the orientation and a GPS latitude reference
+func exifSegment(orientation int) []byte {
+	tiff := []byte{
+		'I', 'I', 42, 0, 8, 0, 0, 0,
+		2, 0,
+		0x12, 0x01, 3, 0, 1, 0, 0, 0, byte(orientation), 0, 0, 0,
+		0x01, 0x00, 2, 0, 2, 0, 0, 0, 'N', 0, 0, 0,
+		0, 0, 0, 0,
+	}
+	segment := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+len(exifHeader)+len(tiff)))
+	segment = append(segment, exifHeader...)
+	return append(segment, tiff...)
+}
+
+func testJPEG(t *testing.T, w, h int, segments ...[]byte) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil))
+	data := buf.Bytes()
+	out := append([]byte{}, data[:2]...)
+	for _, segment := range segments {
+		out = append(out, segment...)
+	}
+	return append(out, data[2:]...)
+}
+
+func TestStripJPEGMetadata(t *testing.T) {
+	comment := append([]byte{0xff, 0xfe, 0, 13}, "secret note"...)
+	data := testJPEG(t, 8, 4, exifSegment(6), comment)
+	assert.Equal(t, 6, ImageOrientation(data))
+
+	stripped, err := StripImageMetadata(data)
+	assert.NoError(t, err)
+	assert.False(t, bytes.Contains(stripped, []byte("secret note")))
+	assert.False(t, bytes.Contains(stripped, []byte{'N', 0, 0, 0}))
+	assert.Equal(t, 6, ImageOrientation(stripped))
+	img, err := jpeg.Decode(bytes.NewReader(stripped))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 8, 4), img.Bounds())
+
+	// an upright image has no EXIF segment anymore
+	stripped, err = StripImageMetadata(testJPEG(t, 8, 4, exifSegment(1)))
+	assert.NoError(t, err)
+	assert.False(t, bytes.Contains(stripped, exifHeader))
+
+	// the markers may be preceded by fill bytes
+	withFill := testJPEG(t, 8, 4, append([]byte{0xff, 0xff}, exifSegment(6)...), append([]byte{0xff}, comment...))
+	assert.Equal(t, 6, ImageOrientation(withFill))
+	stripped, err = StripImageMetadata(withFill)
+	assert.NoError(t, err)
+	assert.False(t, bytes.Contains(stripped, []byte("secret note")))
+	assert.False(t, bytes.Contains(stripped, []byte{'N', 0, 0, 0}))
+	assert.Equal(t, 6, ImageOrientation(stripped))
+	_, err = jpeg.Decode(bytes.NewReader(stripped))
+	assert.NoError(t, err)
+
+	_, err = StripImageMetadata(append([]byte{}, data[:40]...))
+	assert.True(t, IsErrMalformedImage(err))
+}
+
+func TestStripPNGMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+	data := buf.Bytes()
+	// insert a text chunk after the IHDR chunk
+	ihdrEnd := len(pngSignature) + 12 + 13
+	text := []byte{0, 0, 0, 13, 't', 'E', 'X', 't'}
+	text = append(text, "Author\x00secret"...)
+	text = append(text, 0, 0, 0, 0)
+	withText := append(append(append([]byte{}, data[:ihdrEnd]...), text...), data[ihdrEnd:]...)
+
+	stripped, err := StripImageMetadata(withText)
+	assert.NoError(t, err)
+	assert.Equal(t, data, stripped)
+
+	other := []byte("hello world")
+	stripped, err = StripImageMetadata(other)
+	assert.NoError(t, err)
+	assert.Equal(t, other, stripped)
+}
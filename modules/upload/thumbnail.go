@@ -0,0 +1,107 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package upload
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	// register the GIF format for image.Decode
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/nfnt/resize"
+)
+
+// GenerateThumbnail writes to dst a thumbnail of the image src whose width and height are at
+// most size, smaller images are not enlarged. The thumbnail of a JPEG image is a JPEG image
+// turned upright, other images give PNG thumbnails. Images larger than maxWidth or maxHeight
+// are refused without being decoded.
+func GenerateThumbnail(src, dst string, size, maxWidth, maxHeight int) error {
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	imgCfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("DecodeConfig: %v", err)
+	}
+	if imgCfg.Width > maxWidth {
+		return fmt.Errorf("Image width is too large: %d > %d", imgCfg.Width, maxWidth)
+	}
+	if imgCfg.Height > maxHeight {
+		return fmt.Errorf("Image height is too large: %d > %d", imgCfg.Height, maxHeight)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("Decode: %v", err)
+	}
+	img = resize.Thumbnail(uint(size), uint(size), img, resize.Lanczos3)
+	img = applyOrientation(img, ImageOrientation(data))
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+	// write to a temporary file first so that concurrent requests never serve a partial image
+	tmp, err := ioutil.TempFile(filepath.Dir(dst), filepath.Base(dst)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if format == "jpeg" {
+		err = jpeg.Encode(tmp, img, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(tmp, img)
+	}
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("Encode: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// applyOrientation turns an image with the EXIF orientation upright
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	outW, outH := w, h
+	if orientation >= 5 {
+		outW, outH = h, w
+	}
+	out := image.NewNRGBA(image.Rect(0, 0, outW, outH))
+	for y := 0; y < outH; y++ {
+		for x := 0; x < outW; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				sx, sy = w-1-x, y
+			case 3: // rotated by 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // needs a clockwise rotation
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // needs a counterclockwise rotation
+				sx, sy = w-1-y, x
+			}
+			out.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package upload
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateThumbnail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thumbnail")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// a photo taken sideways is turned upright
+	src := filepath.Join(dir, "photo")
+	assert.NoError(t, ioutil.WriteFile(src, testJPEG(t, 600, 400, exifSegment(6)), 0644))
+	dst := filepath.Join(dir, "thumbnails", "photo")
+	assert.NoError(t, GenerateThumbnail(src, dst, 300, 1000, 1000))
+	f, err := os.Open(dst)
+	assert.NoError(t, err)
+	defer f.Close()
+	config, format, err := image.DecodeConfig(f)
+	assert.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 200, config.Width)
+	assert.Equal(t, 300, config.Height)
+
+	// smaller images are not enlarged
+	small := filepath.Join(dir, "small")
+	out, err := os.Create(small)
+	assert.NoError(t, err)
+	assert.NoError(t, png.Encode(out, image.NewRGBA(image.Rect(0, 0, 32, 16))))
+	assert.NoError(t, out.Close())
+	assert.NoError(t, GenerateThumbnail(small, dst, 300, 1000, 1000))
+	f2, err := os.Open(dst)
+	assert.NoError(t, err)
+	defer f2.Close()
+	config, format, err = image.DecodeConfig(f2)
+	assert.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, 32, config.Width)
+
+	// larger images are not decoded
+	assert.Error(t, GenerateThumbnail(src, filepath.Join(dir, "large"), 300, 500, 1000))
+	assert.Error(t, GenerateThumbnail(src, filepath.Join(dir, "large"), 300, 1000, 300))
+	_, err = os.Stat(filepath.Join(dir, "large"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Error(t, GenerateThumbnail(filepath.Join(dir, "missing"), dst, 300, 1000, 1000))
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// a 2x1 image with a red pixel on the left
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	red := color.NRGBA{R: 255, A: 255}
+	img.Set(0, 0, red)
+
+	assert.Equal(t, img, applyOrientation(img, 1))
+	cases := map[int]image.Point{
+		2: {1, 0},
+		3: {1, 0},
+		4: {0, 0},
+		5: {0, 0},
+		6: {0, 0},
+		7: {0, 1},
+		8: {0, 1},
+	}
+	for orientation, redAt := range cases {
+		out := applyOrientation(img, orientation)
+		if orientation >= 5 {
+			assert.Equal(t, image.Rect(0, 0, 1, 2), out.Bounds(), orientation)
+		}
+		assert.Equal(t, red, out.At(redAt.X, redAt.Y), orientation)
+	}
+}
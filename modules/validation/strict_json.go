@@ -0,0 +1,189 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package validation
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gitea.com/macaron/binding"
+)
+
+const (
+	// ErrUnknownField is returned when a JSON document contains a field its type does not have
+	ErrUnknownField = "UnknownFieldError"
+
+	// ErrFieldType is returned when a JSON value does not match the type of its field
+	ErrFieldType = "FieldTypeError"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// CheckStrictJSON checks a JSON document against the type it is decoded into.
+// Other than encoding/json, it reports every field the type does not know
+// and every value of the wrong JSON type. Field names must match exactly.
+// Syntax errors are left to the decoder and are not reported.
+func CheckStrictJSON(data []byte, typ reflect.Type) binding.Errors {
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil
+	}
+
+	var errs binding.Errors
+	checkJSONValue(&errs, "", doc, typ)
+	return errs
+}
+
+func checkJSONValue(errs *binding.Errors, path string, value interface{}, typ reflect.Type) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if value == nil {
+		return
+	}
+
+	ptrType := reflect.PtrTo(typ)
+	if ptrType.Implements(textUnmarshalerType) {
+		if _, ok := value.(string); !ok {
+			addFieldTypeError(errs, path, "string")
+		}
+		return
+	} else if ptrType.Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			addFieldTypeError(errs, path, "object")
+			return
+		}
+		fields := jsonFields(typ)
+		for _, name := range sortedKeys(obj) {
+			v := obj[name]
+			field, ok := fields[name]
+			if !ok {
+				errs.Add([]string{joinJSONPath(path, name)}, ErrUnknownField, "unknown field")
+				continue
+			}
+			checkJSONValue(errs, joinJSONPath(path, name), v, field.Type)
+		}
+	case reflect.Map:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			addFieldTypeError(errs, path, "object")
+			return
+		}
+		for _, name := range sortedKeys(obj) {
+			checkJSONValue(errs, joinJSONPath(path, name), obj[name], typ.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			if _, ok := value.(string); !ok {
+				addFieldTypeError(errs, path, "string")
+			}
+			return
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			addFieldTypeError(errs, path, "array")
+			return
+		}
+		for i, v := range list {
+			checkJSONValue(errs, fmt.Sprintf("%s[%d]", path, i), v, typ.Elem())
+		}
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			addFieldTypeError(errs, path, "string")
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			addFieldTypeError(errs, path, "boolean")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, ok := value.(json.Number); !ok || strings.ContainsAny(n.String(), ".eE") {
+			addFieldTypeError(errs, path, "integer")
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(json.Number); !ok {
+			addFieldTypeError(errs, path, "number")
+		}
+	}
+}
+
+// jsonFields returns the fields of a struct type by their JSON name,
+// including the fields of embedded structs.
+func jsonFields(typ reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for n, f := range jsonFields(embedded) {
+					if _, ok := fields[n]; !ok {
+						fields[n] = f
+					}
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// sortedKeys returns the keys of a JSON object in order, so that errors are
+// reported in a stable order.
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinJSONPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func addFieldTypeError(errs *binding.Errors, path, expected string) {
+	var fieldNames []string
+	if path != "" {
+		fieldNames = []string{path}
+	}
+	errs.Add(fieldNames, ErrFieldType, "expected "+expected)
+}
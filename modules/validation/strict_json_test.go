@@ -0,0 +1,52 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package validation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gitea.com/macaron/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+type strictJSONBase struct {
+	ID int64 `json:"id"`
+}
+
+type strictJSONOption struct {
+	strictJSONBase
+	Title    string            `json:"title"`
+	Labels   []int64           `json:"labels"`
+	Closed   *bool             `json:"closed"`
+	Deadline *time.Time        `json:"due_date"`
+	Config   map[string]string `json:"config"`
+	Internal string            `json:"-"`
+}
+
+func TestCheckStrictJSON(t *testing.T) {
+	typ := reflect.TypeOf(strictJSONOption{})
+
+	assert.Empty(t, CheckStrictJSON([]byte(`{"id": 1, "title": "t", "labels": [1, 2], "closed": null,
+		"due_date": "2020-01-01T00:00:00Z", "config": {"url": "http://example.com"}}`), typ))
+	// syntax errors are reported by the decoder
+	assert.Empty(t, CheckStrictJSON([]byte(`{"id": `), typ))
+
+	assert.Equal(t, binding.Errors{
+		{FieldNames: []string{"Internal"}, Classification: ErrUnknownField, Message: "unknown field"},
+		{FieldNames: []string{"Title"}, Classification: ErrUnknownField, Message: "unknown field"},
+		{FieldNames: []string{"closed"}, Classification: ErrFieldType, Message: "expected boolean"},
+		{FieldNames: []string{"config.url"}, Classification: ErrFieldType, Message: "expected string"},
+		{FieldNames: []string{"due_date"}, Classification: ErrFieldType, Message: "expected string"},
+		{FieldNames: []string{"id"}, Classification: ErrFieldType, Message: "expected integer"},
+		{FieldNames: []string{"labels[1]"}, Classification: ErrFieldType, Message: "expected integer"},
+	}, CheckStrictJSON([]byte(`{"id": 1.5, "Title": "t", "Internal": "x", "labels": [1, "2"],
+		"closed": "yes", "due_date": 0, "config": {"url": 1}}`), typ))
+
+	assert.Equal(t, binding.Errors{
+		{Classification: ErrFieldType, Message: "expected object"},
+	}, CheckStrictJSON([]byte(`[]`), typ))
+}
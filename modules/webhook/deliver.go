@@ -19,7 +19,10 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/metrics"
 	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
 	"github.com/gobwas/glob"
 	"github.com/unknwon/com"
 )
@@ -34,10 +37,18 @@ func Deliver(t *models.HookTask) error {
 		// There was a panic whilst delivering a hook...
 		log.Error("PANIC whilst trying to deliver webhook[%d] for repo[%d] to %s Panic: %v\nStacktrace: %s", t.ID, t.RepoID, t.URL, err, log.Stack(2))
 	}()
-	t.IsDelivered = true
+
+	// The task may be picked up by the hook queue whilst it is delivered on
+	// request, so it is claimed before it is sent.
+	claimed, err := models.ClaimHookTask(t)
+	if err != nil {
+		return fmt.Errorf("ClaimHookTask: %v", err)
+	} else if !claimed {
+		log.Trace("Hook task [%d] is already being delivered", t.ID)
+		return nil
+	}
 
 	var req *http.Request
-	var err error
 
 	switch t.HTTPMethod {
 	case "":
@@ -116,6 +127,7 @@ func Deliver(t *models.HookTask) error {
 		} else {
 			log.Trace("Hook delivery failed: %s", t.UUID)
 		}
+		metrics.ObserveWebhookDelivery(t.Type, t.IsSucceed)
 
 		if err := models.UpdateHookTask(t); err != nil {
 			log.Error("UpdateHookTask [%d]: %v", t.ID, err)
@@ -138,7 +150,7 @@ func Deliver(t *models.HookTask) error {
 		}
 	}()
 
-	resp, err := webhookHTTPClient.Do(req)
+	resp, err := getWebhookHTTPClient().Do(req)
 	if err != nil {
 		t.ResponseInfo.Body = fmt.Sprintf("Delivery: %v", err)
 		return err
@@ -161,6 +173,35 @@ func Deliver(t *models.HookTask) error {
 	return nil
 }
 
+// DeliverTestWebhook creates a hook task for the payload regardless of the
+// events and branch filter of the webhook, and delivers it immediately.
+func DeliverTestWebhook(w *models.Webhook, repo *models.Repository, event models.HookEventType, p api.Payloader) (*models.HookTask, error) {
+	t, err := createHookTask(w, repo, event, p)
+	if err != nil {
+		return nil, err
+	}
+	return t, deliverNow(t)
+}
+
+// Redeliver repeats the request of a past hook task as a new hook task and
+// delivers it immediately.
+func Redeliver(t *models.HookTask) (*models.HookTask, error) {
+	nt, err := models.CreateRedeliveryHookTask(t)
+	if err != nil {
+		return nil, fmt.Errorf("CreateRedeliveryHookTask: %v", err)
+	}
+	return nt, deliverNow(nt)
+}
+
+// deliverNow delivers the hook task synchronously. A failing remote endpoint
+// is recorded in the task and not reported as error.
+func deliverNow(t *models.HookTask) error {
+	if err := Deliver(t); err != nil && t.ResponseInfo == nil {
+		return err
+	}
+	return nil
+}
+
 // DeliverHooks checks and delivers undelivered hooks.
 // FIXME: graceful: This would likely benefit from either a worker pool with dummy queue
 // or a full queue. Then more hooks could be sent at same time.
@@ -226,8 +267,7 @@ func DeliverHooks(ctx context.Context) {
 
 var (
 	webhookHTTPClient *http.Client
-	once              sync.Once
-	hostMatchers      []glob.Glob
+	clientMutex       sync.RWMutex
 )
 
 func webhookProxy() func(req *http.Request) (*url.URL, error) {
@@ -235,31 +275,30 @@ func webhookProxy() func(req *http.Request) (*url.URL, error) {
 		return http.ProxyFromEnvironment
 	}
 
-	once.Do(func() {
-		for _, h := range setting.Webhook.ProxyHosts {
-			if g, err := glob.Compile(h); err == nil {
-				hostMatchers = append(hostMatchers, g)
-			} else {
-				log.Error("glob.Compile %s failed: %v", h, err)
-			}
+	var hostMatchers []glob.Glob
+	for _, h := range setting.Webhook.ProxyHosts {
+		if g, err := glob.Compile(h); err == nil {
+			hostMatchers = append(hostMatchers, g)
+		} else {
+			log.Error("glob.Compile %s failed: %v", h, err)
 		}
-	})
+	}
+	proxyURL := setting.Webhook.ProxyURLFixed
 
 	return func(req *http.Request) (*url.URL, error) {
 		for _, v := range hostMatchers {
 			if v.Match(req.URL.Host) {
-				return http.ProxyURL(setting.Webhook.ProxyURLFixed)(req)
+				return http.ProxyURL(proxyURL)(req)
 			}
 		}
 		return http.ProxyFromEnvironment(req)
 	}
 }
 
-// InitDeliverHooks starts the hooks delivery thread
-func InitDeliverHooks() {
+func newWebhookHTTPClient() *http.Client {
 	timeout := time.Duration(setting.Webhook.DeliverTimeout) * time.Second
 
-	webhookHTTPClient = &http.Client{
+	return &http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: setting.Webhook.SkipTLSVerify},
 			Proxy:           webhookProxy(),
@@ -273,6 +312,26 @@ func InitDeliverHooks() {
 			},
 		},
 	}
+}
+
+func getWebhookHTTPClient() *http.Client {
+	clientMutex.RLock()
+	defer clientMutex.RUnlock()
+	return webhookHTTPClient
+}
+
+// resetWebhookHTTPClient recreates the client when the webhook settings are reloaded
+func resetWebhookHTTPClient() {
+	client := newWebhookHTTPClient()
+	clientMutex.Lock()
+	webhookHTTPClient = client
+	clientMutex.Unlock()
+}
+
+// InitDeliverHooks starts the hooks delivery thread
+func InitDeliverHooks() {
+	resetWebhookHTTPClient()
+	setting.OnSettingsReloaded("webhook", resetWebhookHTTPClient)
 
 	go graceful.GetManager().RunWithShutdownContext(DeliverHooks)
 }
@@ -6,10 +6,14 @@ package webhook
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,3 +41,43 @@ func TestWebhookProxy(t *testing.T) {
 		}
 	}
 }
+
+func TestDeliverTestWebhookAndRedeliver(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	var events []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events = append(events, r.Header.Get("X-Gitea-Event"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	webhookHTTPClient = server.Client()
+	defer func() { webhookHTTPClient = nil }()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	w := models.AssertExistsAndLoadBean(t, &models.Webhook{ID: 1}).(*models.Webhook)
+	w.URL = server.URL
+
+	// webhook 1 is push only, but test deliveries ignore the chosen events
+	task, err := DeliverTestWebhook(w, repo, models.HookEventCreate, &api.CreatePayload{Ref: "master"})
+	assert.NoError(t, err)
+	assert.True(t, task.IsDelivered)
+	assert.True(t, task.IsSucceed)
+	assert.Equal(t, http.StatusAccepted, task.ResponseInfo.Status)
+
+	redelivery, err := Redeliver(task)
+	assert.NoError(t, err)
+	assert.NotEqual(t, task.ID, redelivery.ID)
+	assert.True(t, redelivery.IsSucceed)
+	assert.Equal(t, task.PayloadContent, redelivery.PayloadContent)
+	assert.Equal(t, []string{"create", "create"}, events)
+
+	// a task claimed by a concurrent delivery is not sent again
+	claimedTask, err := models.CreateRedeliveryHookTask(task)
+	assert.NoError(t, err)
+	claimed, err := models.ClaimHookTask(claimedTask)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.NoError(t, Deliver(claimedTask))
+	assert.Len(t, events, 2)
+}
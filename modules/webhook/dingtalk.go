@@ -131,8 +131,8 @@ func getDingtalkPushPayload(p *api.PushPayload) (*DingtalkPayload, error) {
 	}, nil
 }
 
-func getDingtalkIssuesPayload(p *api.IssuePayload) (*DingtalkPayload, error) {
-	text, issueTitle, attachmentText, _ := getIssuesPayloadInfo(p, noneLinkFormatter, true)
+func getDingtalkIssuesPayload(p *api.IssuePayload, lang string) (*DingtalkPayload, error) {
+	text, issueTitle, attachmentText, _ := getIssuesPayloadInfo(p, noneLinkFormatter, true, lang)
 
 	return &DingtalkPayload{
 		MsgType: "actionCard",
@@ -147,8 +147,8 @@ func getDingtalkIssuesPayload(p *api.IssuePayload) (*DingtalkPayload, error) {
 	}, nil
 }
 
-func getDingtalkIssueCommentPayload(p *api.IssueCommentPayload) (*DingtalkPayload, error) {
-	text, issueTitle, _ := getIssueCommentPayloadInfo(p, noneLinkFormatter, true)
+func getDingtalkIssueCommentPayload(p *api.IssueCommentPayload, lang string) (*DingtalkPayload, error) {
+	text, issueTitle, _ := getIssueCommentPayloadInfo(p, noneLinkFormatter, true, lang)
 
 	return &DingtalkPayload{
 		MsgType: "actionCard",
@@ -162,8 +162,8 @@ func getDingtalkIssueCommentPayload(p *api.IssueCommentPayload) (*DingtalkPayloa
 	}, nil
 }
 
-func getDingtalkPullRequestPayload(p *api.PullRequestPayload) (*DingtalkPayload, error) {
-	text, issueTitle, attachmentText, _ := getPullRequestPayloadInfo(p, noneLinkFormatter, true)
+func getDingtalkPullRequestPayload(p *api.PullRequestPayload, lang string) (*DingtalkPayload, error) {
+	text, issueTitle, attachmentText, _ := getPullRequestPayloadInfo(p, noneLinkFormatter, true, lang)
 
 	return &DingtalkPayload{
 		MsgType: "actionCard",
@@ -235,8 +235,8 @@ func getDingtalkRepositoryPayload(p *api.RepositoryPayload) (*DingtalkPayload, e
 	return nil, nil
 }
 
-func getDingtalkReleasePayload(p *api.ReleasePayload) (*DingtalkPayload, error) {
-	text, _ := getReleasePayloadInfo(p, noneLinkFormatter, true)
+func getDingtalkReleasePayload(p *api.ReleasePayload, lang string) (*DingtalkPayload, error) {
+	text, _ := getReleasePayloadInfo(p, noneLinkFormatter, true, lang)
 
 	return &DingtalkPayload{
 		MsgType: "actionCard",
@@ -251,7 +251,7 @@ func getDingtalkReleasePayload(p *api.ReleasePayload) (*DingtalkPayload, error)
 }
 
 // GetDingtalkPayload converts a ding talk webhook into a DingtalkPayload
-func GetDingtalkPayload(p api.Payloader, event models.HookEventType, meta string) (*DingtalkPayload, error) {
+func GetDingtalkPayload(p api.Payloader, event models.HookEventType, meta, lang string) (*DingtalkPayload, error) {
 	s := new(DingtalkPayload)
 
 	switch event {
@@ -262,24 +262,24 @@ func GetDingtalkPayload(p api.Payloader, event models.HookEventType, meta string
 	case models.HookEventFork:
 		return getDingtalkForkPayload(p.(*api.ForkPayload))
 	case models.HookEventIssues, models.HookEventIssueAssign, models.HookEventIssueLabel, models.HookEventIssueMilestone:
-		return getDingtalkIssuesPayload(p.(*api.IssuePayload))
+		return getDingtalkIssuesPayload(p.(*api.IssuePayload), lang)
 	case models.HookEventIssueComment, models.HookEventPullRequestComment:
 		pl, ok := p.(*api.IssueCommentPayload)
 		if ok {
-			return getDingtalkIssueCommentPayload(pl)
+			return getDingtalkIssueCommentPayload(pl, lang)
 		}
-		return getDingtalkPullRequestPayload(p.(*api.PullRequestPayload))
+		return getDingtalkPullRequestPayload(p.(*api.PullRequestPayload), lang)
 	case models.HookEventPush:
 		return getDingtalkPushPayload(p.(*api.PushPayload))
 	case models.HookEventPullRequest, models.HookEventPullRequestAssign, models.HookEventPullRequestLabel,
 		models.HookEventPullRequestMilestone, models.HookEventPullRequestSync:
-		return getDingtalkPullRequestPayload(p.(*api.PullRequestPayload))
+		return getDingtalkPullRequestPayload(p.(*api.PullRequestPayload), lang)
 	case models.HookEventPullRequestReviewApproved, models.HookEventPullRequestReviewRejected, models.HookEventPullRequestReviewComment:
 		return getDingtalkPullRequestApprovalPayload(p.(*api.PullRequestPayload), event)
 	case models.HookEventRepository:
 		return getDingtalkRepositoryPayload(p.(*api.RepositoryPayload))
 	case models.HookEventRelease:
-		return getDingtalkReleasePayload(p.(*api.ReleasePayload))
+		return getDingtalkReleasePayload(p.(*api.ReleasePayload), lang)
 	}
 
 	return s, nil
@@ -16,14 +16,14 @@ func TestGetDingTalkIssuesPayload(t *testing.T) {
 	p := issueTestPayload()
 
 	p.Action = api.HookIssueOpened
-	pl, err := getDingtalkIssuesPayload(p)
+	pl, err := getDingtalkIssuesPayload(p, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 	assert.Equal(t, "#2 crash", pl.ActionCard.Title)
 	assert.Equal(t, "[test/repo] Issue opened: #2 crash by user1\r\n\r\n", pl.ActionCard.Text)
 
 	p.Action = api.HookIssueClosed
-	pl, err = getDingtalkIssuesPayload(p)
+	pl, err = getDingtalkIssuesPayload(p, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 	assert.Equal(t, "#2 crash", pl.ActionCard.Title)
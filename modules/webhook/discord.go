@@ -226,8 +226,8 @@ func getDiscordPushPayload(p *api.PushPayload, meta *DiscordMeta) (*DiscordPaylo
 	}, nil
 }
 
-func getDiscordIssuesPayload(p *api.IssuePayload, meta *DiscordMeta) (*DiscordPayload, error) {
-	text, _, attachmentText, color := getIssuesPayloadInfo(p, noneLinkFormatter, false)
+func getDiscordIssuesPayload(p *api.IssuePayload, meta *DiscordMeta, lang string) (*DiscordPayload, error) {
+	text, _, attachmentText, color := getIssuesPayloadInfo(p, noneLinkFormatter, false, lang)
 
 	return &DiscordPayload{
 		Username:  meta.Username,
@@ -248,8 +248,8 @@ func getDiscordIssuesPayload(p *api.IssuePayload, meta *DiscordMeta) (*DiscordPa
 	}, nil
 }
 
-func getDiscordIssueCommentPayload(p *api.IssueCommentPayload, discord *DiscordMeta) (*DiscordPayload, error) {
-	text, _, color := getIssueCommentPayloadInfo(p, noneLinkFormatter, false)
+func getDiscordIssueCommentPayload(p *api.IssueCommentPayload, discord *DiscordMeta, lang string) (*DiscordPayload, error) {
+	text, _, color := getIssueCommentPayloadInfo(p, noneLinkFormatter, false, lang)
 
 	return &DiscordPayload{
 		Username:  discord.Username,
@@ -270,8 +270,8 @@ func getDiscordIssueCommentPayload(p *api.IssueCommentPayload, discord *DiscordM
 	}, nil
 }
 
-func getDiscordPullRequestPayload(p *api.PullRequestPayload, meta *DiscordMeta) (*DiscordPayload, error) {
-	text, _, attachmentText, color := getPullRequestPayloadInfo(p, noneLinkFormatter, false)
+func getDiscordPullRequestPayload(p *api.PullRequestPayload, meta *DiscordMeta, lang string) (*DiscordPayload, error) {
+	text, _, attachmentText, color := getPullRequestPayloadInfo(p, noneLinkFormatter, false, lang)
 
 	return &DiscordPayload{
 		Username:  meta.Username,
@@ -367,8 +367,8 @@ func getDiscordRepositoryPayload(p *api.RepositoryPayload, meta *DiscordMeta) (*
 	}, nil
 }
 
-func getDiscordReleasePayload(p *api.ReleasePayload, meta *DiscordMeta) (*DiscordPayload, error) {
-	text, color := getReleasePayloadInfo(p, noneLinkFormatter, false)
+func getDiscordReleasePayload(p *api.ReleasePayload, meta *DiscordMeta, lang string) (*DiscordPayload, error) {
+	text, color := getReleasePayloadInfo(p, noneLinkFormatter, false, lang)
 
 	return &DiscordPayload{
 		Username:  meta.Username,
@@ -390,7 +390,7 @@ func getDiscordReleasePayload(p *api.ReleasePayload, meta *DiscordMeta) (*Discor
 }
 
 // GetDiscordPayload converts a discord webhook into a DiscordPayload
-func GetDiscordPayload(p api.Payloader, event models.HookEventType, meta string) (*DiscordPayload, error) {
+func GetDiscordPayload(p api.Payloader, event models.HookEventType, meta, lang string) (*DiscordPayload, error) {
 	s := new(DiscordPayload)
 
 	discord := &DiscordMeta{}
@@ -406,24 +406,24 @@ func GetDiscordPayload(p api.Payloader, event models.HookEventType, meta string)
 	case models.HookEventFork:
 		return getDiscordForkPayload(p.(*api.ForkPayload), discord)
 	case models.HookEventIssues, models.HookEventIssueAssign, models.HookEventIssueLabel, models.HookEventIssueMilestone:
-		return getDiscordIssuesPayload(p.(*api.IssuePayload), discord)
+		return getDiscordIssuesPayload(p.(*api.IssuePayload), discord, lang)
 	case models.HookEventIssueComment, models.HookEventPullRequestComment:
 		pl, ok := p.(*api.IssueCommentPayload)
 		if ok {
-			return getDiscordIssueCommentPayload(pl, discord)
+			return getDiscordIssueCommentPayload(pl, discord, lang)
 		}
-		return getDiscordPullRequestPayload(p.(*api.PullRequestPayload), discord)
+		return getDiscordPullRequestPayload(p.(*api.PullRequestPayload), discord, lang)
 	case models.HookEventPush:
 		return getDiscordPushPayload(p.(*api.PushPayload), discord)
 	case models.HookEventPullRequest, models.HookEventPullRequestAssign, models.HookEventPullRequestLabel,
 		models.HookEventPullRequestMilestone, models.HookEventPullRequestSync:
-		return getDiscordPullRequestPayload(p.(*api.PullRequestPayload), discord)
+		return getDiscordPullRequestPayload(p.(*api.PullRequestPayload), discord, lang)
 	case models.HookEventPullRequestReviewRejected, models.HookEventPullRequestReviewApproved, models.HookEventPullRequestReviewComment:
 		return getDiscordPullRequestApprovalPayload(p.(*api.PullRequestPayload), discord, event)
 	case models.HookEventRepository:
 		return getDiscordRepositoryPayload(p.(*api.RepositoryPayload), discord)
 	case models.HookEventRelease:
-		return getDiscordReleasePayload(p.(*api.ReleasePayload), discord)
+		return getDiscordReleasePayload(p.(*api.ReleasePayload), discord, lang)
 	}
 
 	return s, nil
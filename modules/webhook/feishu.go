@@ -94,8 +94,8 @@ func getFeishuPushPayload(p *api.PushPayload) (*FeishuPayload, error) {
 	}, nil
 }
 
-func getFeishuIssuesPayload(p *api.IssuePayload) (*FeishuPayload, error) {
-	text, issueTitle, attachmentText, _ := getIssuesPayloadInfo(p, noneLinkFormatter, true)
+func getFeishuIssuesPayload(p *api.IssuePayload, lang string) (*FeishuPayload, error) {
+	text, issueTitle, attachmentText, _ := getIssuesPayloadInfo(p, noneLinkFormatter, true, lang)
 
 	return &FeishuPayload{
 		Text:  text + "\r\n\r\n" + attachmentText,
@@ -103,8 +103,8 @@ func getFeishuIssuesPayload(p *api.IssuePayload) (*FeishuPayload, error) {
 	}, nil
 }
 
-func getFeishuIssueCommentPayload(p *api.IssueCommentPayload) (*FeishuPayload, error) {
-	text, issueTitle, _ := getIssueCommentPayloadInfo(p, noneLinkFormatter, true)
+func getFeishuIssueCommentPayload(p *api.IssueCommentPayload, lang string) (*FeishuPayload, error) {
+	text, issueTitle, _ := getIssueCommentPayloadInfo(p, noneLinkFormatter, true, lang)
 
 	return &FeishuPayload{
 		Text:  text + "\r\n\r\n" + p.Comment.Body,
@@ -112,8 +112,8 @@ func getFeishuIssueCommentPayload(p *api.IssueCommentPayload) (*FeishuPayload, e
 	}, nil
 }
 
-func getFeishuPullRequestPayload(p *api.PullRequestPayload) (*FeishuPayload, error) {
-	text, issueTitle, attachmentText, _ := getPullRequestPayloadInfo(p, noneLinkFormatter, true)
+func getFeishuPullRequestPayload(p *api.PullRequestPayload, lang string) (*FeishuPayload, error) {
+	text, issueTitle, attachmentText, _ := getPullRequestPayloadInfo(p, noneLinkFormatter, true, lang)
 
 	return &FeishuPayload{
 		Text:  text + "\r\n\r\n" + attachmentText,
@@ -161,8 +161,8 @@ func getFeishuRepositoryPayload(p *api.RepositoryPayload) (*FeishuPayload, error
 	return nil, nil
 }
 
-func getFeishuReleasePayload(p *api.ReleasePayload) (*FeishuPayload, error) {
-	text, _ := getReleasePayloadInfo(p, noneLinkFormatter, true)
+func getFeishuReleasePayload(p *api.ReleasePayload, lang string) (*FeishuPayload, error) {
+	text, _ := getReleasePayloadInfo(p, noneLinkFormatter, true, lang)
 
 	return &FeishuPayload{
 		Text:  text,
@@ -171,7 +171,7 @@ func getFeishuReleasePayload(p *api.ReleasePayload) (*FeishuPayload, error) {
 }
 
 // GetFeishuPayload converts a ding talk webhook into a FeishuPayload
-func GetFeishuPayload(p api.Payloader, event models.HookEventType, meta string) (*FeishuPayload, error) {
+func GetFeishuPayload(p api.Payloader, event models.HookEventType, meta, lang string) (*FeishuPayload, error) {
 	s := new(FeishuPayload)
 
 	switch event {
@@ -182,23 +182,23 @@ func GetFeishuPayload(p api.Payloader, event models.HookEventType, meta string)
 	case models.HookEventFork:
 		return getFeishuForkPayload(p.(*api.ForkPayload))
 	case models.HookEventIssues:
-		return getFeishuIssuesPayload(p.(*api.IssuePayload))
+		return getFeishuIssuesPayload(p.(*api.IssuePayload), lang)
 	case models.HookEventIssueComment, models.HookEventPullRequestComment:
 		pl, ok := p.(*api.IssueCommentPayload)
 		if ok {
-			return getFeishuIssueCommentPayload(pl)
+			return getFeishuIssueCommentPayload(pl, lang)
 		}
-		return getFeishuPullRequestPayload(p.(*api.PullRequestPayload))
+		return getFeishuPullRequestPayload(p.(*api.PullRequestPayload), lang)
 	case models.HookEventPush:
 		return getFeishuPushPayload(p.(*api.PushPayload))
 	case models.HookEventPullRequest:
-		return getFeishuPullRequestPayload(p.(*api.PullRequestPayload))
+		return getFeishuPullRequestPayload(p.(*api.PullRequestPayload), lang)
 	case models.HookEventPullRequestReviewApproved, models.HookEventPullRequestReviewRejected:
 		return getFeishuPullRequestApprovalPayload(p.(*api.PullRequestPayload), event)
 	case models.HookEventRepository:
 		return getFeishuRepositoryPayload(p.(*api.RepositoryPayload))
 	case models.HookEventRelease:
-		return getFeishuReleasePayload(p.(*api.ReleasePayload))
+		return getFeishuReleasePayload(p.(*api.ReleasePayload), lang)
 	}
 
 	return s, nil
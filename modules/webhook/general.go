@@ -11,6 +11,8 @@ import (
 
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/unknwon/i18n"
 )
 
 type linkFormatter = func(string, string) string
@@ -25,7 +27,7 @@ func htmlLinkFormatter(url string, text string) string {
 	return fmt.Sprintf(`<a href="%s">%s</a>`, url, html.EscapeString(text))
 }
 
-func getIssuesPayloadInfo(p *api.IssuePayload, linkFormatter linkFormatter, withSender bool) (string, string, string, int) {
+func getIssuesPayloadInfo(p *api.IssuePayload, linkFormatter linkFormatter, withSender bool, lang string) (string, string, string, int) {
 	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
 	issueTitle := fmt.Sprintf("#%d %s", p.Index, p.Issue.Title)
 	titleLink := linkFormatter(fmt.Sprintf("%s/issues/%d", p.Repository.HTMLURL, p.Index), issueTitle)
@@ -34,36 +36,36 @@ func getIssuesPayloadInfo(p *api.IssuePayload, linkFormatter linkFormatter, with
 
 	switch p.Action {
 	case api.HookIssueOpened:
-		text = fmt.Sprintf("[%s] Issue opened: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.issue.opened", repoLink, titleLink)
 		color = orangeColor
 	case api.HookIssueClosed:
-		text = fmt.Sprintf("[%s] Issue closed: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.issue.closed", repoLink, titleLink)
 		color = redColor
 	case api.HookIssueReOpened:
-		text = fmt.Sprintf("[%s] Issue re-opened: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.issue.reopened", repoLink, titleLink)
 	case api.HookIssueEdited:
-		text = fmt.Sprintf("[%s] Issue edited: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.issue.edited", repoLink, titleLink)
 	case api.HookIssueAssigned:
-		text = fmt.Sprintf("[%s] Issue assigned to %s: %s", repoLink,
+		text = i18n.Tr(lang, "webhook.issue.assigned", repoLink,
 			linkFormatter(setting.AppURL+p.Issue.Assignee.UserName, p.Issue.Assignee.UserName), titleLink)
 		color = greenColor
 	case api.HookIssueUnassigned:
-		text = fmt.Sprintf("[%s] Issue unassigned: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.issue.unassigned", repoLink, titleLink)
 	case api.HookIssueLabelUpdated:
-		text = fmt.Sprintf("[%s] Issue labels updated: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.issue.label_updated", repoLink, titleLink)
 	case api.HookIssueLabelCleared:
-		text = fmt.Sprintf("[%s] Issue labels cleared: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.issue.label_cleared", repoLink, titleLink)
 	case api.HookIssueSynchronized:
-		text = fmt.Sprintf("[%s] Issue synchronized: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.issue.synchronized", repoLink, titleLink)
 	case api.HookIssueMilestoned:
 		mileStoneLink := fmt.Sprintf("%s/milestone/%d", p.Repository.HTMLURL, p.Issue.Milestone.ID)
-		text = fmt.Sprintf("[%s] Issue milestoned to %s: %s", repoLink,
+		text = i18n.Tr(lang, "webhook.issue.milestoned", repoLink,
 			linkFormatter(mileStoneLink, p.Issue.Milestone.Title), titleLink)
 	case api.HookIssueDemilestoned:
-		text = fmt.Sprintf("[%s] Issue milestone cleared: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.issue.demilestoned", repoLink, titleLink)
 	}
 	if withSender {
-		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+		text += " " + i18n.Tr(lang, "webhook.by", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
 	}
 
 	var attachmentText string
@@ -74,7 +76,7 @@ func getIssuesPayloadInfo(p *api.IssuePayload, linkFormatter linkFormatter, with
 	return text, issueTitle, attachmentText, color
 }
 
-func getPullRequestPayloadInfo(p *api.PullRequestPayload, linkFormatter linkFormatter, withSender bool) (string, string, string, int) {
+func getPullRequestPayloadInfo(p *api.PullRequestPayload, linkFormatter linkFormatter, withSender bool, lang string) (string, string, string, int) {
 	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
 	issueTitle := fmt.Sprintf("#%d %s", p.Index, p.PullRequest.Title)
 	titleLink := linkFormatter(p.PullRequest.URL, issueTitle)
@@ -83,47 +85,47 @@ func getPullRequestPayloadInfo(p *api.PullRequestPayload, linkFormatter linkForm
 
 	switch p.Action {
 	case api.HookIssueOpened:
-		text = fmt.Sprintf("[%s] Pull request opened: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.pull.opened", repoLink, titleLink)
 		color = greenColor
 	case api.HookIssueClosed:
 		if p.PullRequest.HasMerged {
-			text = fmt.Sprintf("[%s] Pull request merged: %s", repoLink, titleLink)
+			text = i18n.Tr(lang, "webhook.pull.merged", repoLink, titleLink)
 			color = purpleColor
 		} else {
-			text = fmt.Sprintf("[%s] Pull request closed: %s", repoLink, titleLink)
+			text = i18n.Tr(lang, "webhook.pull.closed", repoLink, titleLink)
 			color = redColor
 		}
 	case api.HookIssueReOpened:
-		text = fmt.Sprintf("[%s] Pull request re-opened: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.pull.reopened", repoLink, titleLink)
 	case api.HookIssueEdited:
-		text = fmt.Sprintf("[%s] Pull request edited: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.pull.edited", repoLink, titleLink)
 	case api.HookIssueAssigned:
 		list := make([]string, len(p.PullRequest.Assignees))
 		for i, user := range p.PullRequest.Assignees {
 			list[i] = linkFormatter(setting.AppURL+user.UserName, user.UserName)
 		}
-		text = fmt.Sprintf("[%s] Pull request assigned: %s to %s", repoLink,
+		text = i18n.Tr(lang, "webhook.pull.assigned", repoLink,
 			strings.Join(list, ", "), titleLink)
 		color = greenColor
 	case api.HookIssueUnassigned:
-		text = fmt.Sprintf("[%s] Pull request unassigned: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.pull.unassigned", repoLink, titleLink)
 	case api.HookIssueLabelUpdated:
-		text = fmt.Sprintf("[%s] Pull request labels updated: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.pull.label_updated", repoLink, titleLink)
 	case api.HookIssueLabelCleared:
-		text = fmt.Sprintf("[%s] Pull request labels cleared: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.pull.label_cleared", repoLink, titleLink)
 	case api.HookIssueSynchronized:
-		text = fmt.Sprintf("[%s] Pull request synchronized: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.pull.synchronized", repoLink, titleLink)
 	case api.HookIssueMilestoned:
 		mileStoneLink := fmt.Sprintf("%s/milestone/%d", p.Repository.HTMLURL, p.PullRequest.Milestone.ID)
-		text = fmt.Sprintf("[%s] Pull request milestoned: %s to %s", repoLink,
+		text = i18n.Tr(lang, "webhook.pull.milestoned", repoLink,
 			linkFormatter(mileStoneLink, p.PullRequest.Milestone.Title), titleLink)
 	case api.HookIssueDemilestoned:
-		text = fmt.Sprintf("[%s] Pull request milestone cleared: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.pull.demilestoned", repoLink, titleLink)
 	case api.HookIssueReviewed:
-		text = fmt.Sprintf("[%s] Pull request reviewed: %s", repoLink, titleLink)
+		text = i18n.Tr(lang, "webhook.pull.reviewed", repoLink, titleLink)
 	}
 	if withSender {
-		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+		text += " " + i18n.Tr(lang, "webhook.by", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
 	}
 
 	var attachmentText string
@@ -134,29 +136,29 @@ func getPullRequestPayloadInfo(p *api.PullRequestPayload, linkFormatter linkForm
 	return text, issueTitle, attachmentText, color
 }
 
-func getReleasePayloadInfo(p *api.ReleasePayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+func getReleasePayloadInfo(p *api.ReleasePayload, linkFormatter linkFormatter, withSender bool, lang string) (text string, color int) {
 	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
 	refLink := linkFormatter(p.Repository.HTMLURL+"/src/"+p.Release.TagName, p.Release.TagName)
 
 	switch p.Action {
 	case api.HookReleasePublished:
-		text = fmt.Sprintf("[%s] Release created: %s", repoLink, refLink)
+		text = i18n.Tr(lang, "webhook.release.published", repoLink, refLink)
 		color = greenColor
 	case api.HookReleaseUpdated:
-		text = fmt.Sprintf("[%s] Release updated: %s", repoLink, refLink)
+		text = i18n.Tr(lang, "webhook.release.updated", repoLink, refLink)
 		color = yellowColor
 	case api.HookReleaseDeleted:
-		text = fmt.Sprintf("[%s] Release deleted: %s", repoLink, refLink)
+		text = i18n.Tr(lang, "webhook.release.deleted", repoLink, refLink)
 		color = redColor
 	}
 	if withSender {
-		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+		text += " " + i18n.Tr(lang, "webhook.by", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
 	}
 
 	return text, color
 }
 
-func getIssueCommentPayloadInfo(p *api.IssueCommentPayload, linkFormatter linkFormatter, withSender bool) (string, string, int) {
+func getIssueCommentPayloadInfo(p *api.IssueCommentPayload, linkFormatter linkFormatter, withSender bool, lang string) (string, string, int) {
 	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
 	issueTitle := fmt.Sprintf("#%d %s", p.Issue.Index, p.Issue.Title)
 
@@ -164,7 +166,7 @@ func getIssueCommentPayloadInfo(p *api.IssueCommentPayload, linkFormatter linkFo
 	color := yellowColor
 
 	if p.IsPull {
-		typ = "pull request"
+		typ = "pull"
 		titleLink = linkFormatter(p.Comment.PRURL, issueTitle)
 	} else {
 		typ = "issue"
@@ -173,20 +175,20 @@ func getIssueCommentPayloadInfo(p *api.IssueCommentPayload, linkFormatter linkFo
 
 	switch p.Action {
 	case api.HookIssueCommentCreated:
-		text = fmt.Sprintf("[%s] New comment on %s %s", repoLink, typ, titleLink)
+		text = i18n.Tr(lang, "webhook.comment.created_"+typ, repoLink, titleLink)
 		if p.IsPull {
 			color = greenColorLight
 		} else {
 			color = orangeColorLight
 		}
 	case api.HookIssueCommentEdited:
-		text = fmt.Sprintf("[%s] Comment edited on %s %s", repoLink, typ, titleLink)
+		text = i18n.Tr(lang, "webhook.comment.edited_"+typ, repoLink, titleLink)
 	case api.HookIssueCommentDeleted:
-		text = fmt.Sprintf("[%s] Comment deleted on %s %s", repoLink, typ, titleLink)
+		text = i18n.Tr(lang, "webhook.comment.deleted_"+typ, repoLink, titleLink)
 		color = redColor
 	}
 	if withSender {
-		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+		text += " " + i18n.Tr(lang, "webhook.by", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
 	}
 
 	return text, issueTitle, color
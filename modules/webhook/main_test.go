@@ -9,8 +9,17 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models"
+
+	macaroni18n "gitea.com/macaron/i18n"
 )
 
 func TestMain(m *testing.M) {
+	// the texts of the chat messages are translated
+	macaroni18n.I18n(macaroni18n.Options{
+		Directory:   "../../options/locale/",
+		DefaultLang: "en-US",
+		Langs:       []string{"en-US"},
+		Names:       []string{"english"},
+	})
 	models.MainTest(m, filepath.Join("..", ".."))
 }
@@ -126,20 +126,20 @@ func getMatrixForkPayload(p *api.ForkPayload, matrix *MatrixMeta) (*MatrixPayloa
 	return getMatrixPayloadUnsafe(text, nil, matrix), nil
 }
 
-func getMatrixIssuesPayload(p *api.IssuePayload, matrix *MatrixMeta) (*MatrixPayloadUnsafe, error) {
-	text, _, _, _ := getIssuesPayloadInfo(p, MatrixLinkFormatter, true)
+func getMatrixIssuesPayload(p *api.IssuePayload, matrix *MatrixMeta, lang string) (*MatrixPayloadUnsafe, error) {
+	text, _, _, _ := getIssuesPayloadInfo(p, MatrixLinkFormatter, true, lang)
 
 	return getMatrixPayloadUnsafe(text, nil, matrix), nil
 }
 
-func getMatrixIssueCommentPayload(p *api.IssueCommentPayload, matrix *MatrixMeta) (*MatrixPayloadUnsafe, error) {
-	text, _, _ := getIssueCommentPayloadInfo(p, MatrixLinkFormatter, true)
+func getMatrixIssueCommentPayload(p *api.IssueCommentPayload, matrix *MatrixMeta, lang string) (*MatrixPayloadUnsafe, error) {
+	text, _, _ := getIssueCommentPayloadInfo(p, MatrixLinkFormatter, true, lang)
 
 	return getMatrixPayloadUnsafe(text, nil, matrix), nil
 }
 
-func getMatrixReleasePayload(p *api.ReleasePayload, matrix *MatrixMeta) (*MatrixPayloadUnsafe, error) {
-	text, _ := getReleasePayloadInfo(p, MatrixLinkFormatter, true)
+func getMatrixReleasePayload(p *api.ReleasePayload, matrix *MatrixMeta, lang string) (*MatrixPayloadUnsafe, error) {
+	text, _ := getReleasePayloadInfo(p, MatrixLinkFormatter, true, lang)
 
 	return getMatrixPayloadUnsafe(text, nil, matrix), nil
 }
@@ -170,8 +170,8 @@ func getMatrixPushPayload(p *api.PushPayload, matrix *MatrixMeta) (*MatrixPayloa
 	return getMatrixPayloadUnsafe(text, p.Commits, matrix), nil
 }
 
-func getMatrixPullRequestPayload(p *api.PullRequestPayload, matrix *MatrixMeta) (*MatrixPayloadUnsafe, error) {
-	text, _, _, _ := getPullRequestPayloadInfo(p, MatrixLinkFormatter, true)
+func getMatrixPullRequestPayload(p *api.PullRequestPayload, matrix *MatrixMeta, lang string) (*MatrixPayloadUnsafe, error) {
+	text, _, _, _ := getPullRequestPayloadInfo(p, MatrixLinkFormatter, true, lang)
 
 	return getMatrixPayloadUnsafe(text, nil, matrix), nil
 }
@@ -212,7 +212,7 @@ func getMatrixRepositoryPayload(p *api.RepositoryPayload, matrix *MatrixMeta) (*
 }
 
 // GetMatrixPayload converts a Matrix webhook into a MatrixPayloadUnsafe
-func GetMatrixPayload(p api.Payloader, event models.HookEventType, meta string) (*MatrixPayloadUnsafe, error) {
+func GetMatrixPayload(p api.Payloader, event models.HookEventType, meta, lang string) (*MatrixPayloadUnsafe, error) {
 	s := new(MatrixPayloadUnsafe)
 
 	matrix := &MatrixMeta{}
@@ -228,24 +228,24 @@ func GetMatrixPayload(p api.Payloader, event models.HookEventType, meta string)
 	case models.HookEventFork:
 		return getMatrixForkPayload(p.(*api.ForkPayload), matrix)
 	case models.HookEventIssues, models.HookEventIssueAssign, models.HookEventIssueLabel, models.HookEventIssueMilestone:
-		return getMatrixIssuesPayload(p.(*api.IssuePayload), matrix)
+		return getMatrixIssuesPayload(p.(*api.IssuePayload), matrix, lang)
 	case models.HookEventIssueComment, models.HookEventPullRequestComment:
 		pl, ok := p.(*api.IssueCommentPayload)
 		if ok {
-			return getMatrixIssueCommentPayload(pl, matrix)
+			return getMatrixIssueCommentPayload(pl, matrix, lang)
 		}
-		return getMatrixPullRequestPayload(p.(*api.PullRequestPayload), matrix)
+		return getMatrixPullRequestPayload(p.(*api.PullRequestPayload), matrix, lang)
 	case models.HookEventPush:
 		return getMatrixPushPayload(p.(*api.PushPayload), matrix)
 	case models.HookEventPullRequest, models.HookEventPullRequestAssign, models.HookEventPullRequestLabel,
 		models.HookEventPullRequestMilestone, models.HookEventPullRequestSync:
-		return getMatrixPullRequestPayload(p.(*api.PullRequestPayload), matrix)
+		return getMatrixPullRequestPayload(p.(*api.PullRequestPayload), matrix, lang)
 	case models.HookEventPullRequestReviewRejected, models.HookEventPullRequestReviewApproved, models.HookEventPullRequestReviewComment:
 		return getMatrixPullRequestApprovalPayload(p.(*api.PullRequestPayload), matrix, event)
 	case models.HookEventRepository:
 		return getMatrixRepositoryPayload(p.(*api.RepositoryPayload), matrix)
 	case models.HookEventRelease:
-		return getMatrixReleasePayload(p.(*api.ReleasePayload), matrix)
+		return getMatrixReleasePayload(p.(*api.ReleasePayload), matrix, lang)
 	}
 
 	return s, nil
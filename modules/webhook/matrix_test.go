@@ -19,14 +19,14 @@ func TestMatrixIssuesPayloadOpened(t *testing.T) {
 	sl := &MatrixMeta{}
 
 	p.Action = api.HookIssueOpened
-	pl, err := getMatrixIssuesPayload(p, sl)
+	pl, err := getMatrixIssuesPayload(p, sl, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 	assert.Equal(t, "[[test/repo](http://localhost:3000/test/repo)] Issue opened: [#2 crash](http://localhost:3000/test/repo/issues/2) by [user1](https://try.gitea.io/user1)", pl.Body)
 	assert.Equal(t, "[<a href=\"http://localhost:3000/test/repo\">test/repo</a>] Issue opened: <a href=\"http://localhost:3000/test/repo/issues/2\">#2 crash</a> by <a href=\"https://try.gitea.io/user1\">user1</a>", pl.FormattedBody)
 
 	p.Action = api.HookIssueClosed
-	pl, err = getMatrixIssuesPayload(p, sl)
+	pl, err = getMatrixIssuesPayload(p, sl, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 	assert.Equal(t, "[[test/repo](http://localhost:3000/test/repo)] Issue closed: [#2 crash](http://localhost:3000/test/repo/issues/2) by [user1](https://try.gitea.io/user1)", pl.Body)
@@ -38,7 +38,7 @@ func TestMatrixIssueCommentPayload(t *testing.T) {
 
 	sl := &MatrixMeta{}
 
-	pl, err := getMatrixIssueCommentPayload(p, sl)
+	pl, err := getMatrixIssueCommentPayload(p, sl, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 
@@ -51,7 +51,7 @@ func TestMatrixPullRequestCommentPayload(t *testing.T) {
 
 	sl := &MatrixMeta{}
 
-	pl, err := getMatrixIssueCommentPayload(p, sl)
+	pl, err := getMatrixIssueCommentPayload(p, sl, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 
@@ -64,7 +64,7 @@ func TestMatrixReleasePayload(t *testing.T) {
 
 	sl := &MatrixMeta{}
 
-	pl, err := getMatrixReleasePayload(p, sl)
+	pl, err := getMatrixReleasePayload(p, sl, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 
@@ -77,7 +77,7 @@ func TestMatrixPullRequestPayload(t *testing.T) {
 
 	sl := &MatrixMeta{}
 
-	pl, err := getMatrixPullRequestPayload(p, sl)
+	pl, err := getMatrixPullRequestPayload(p, sl, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 
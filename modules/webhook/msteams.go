@@ -265,8 +265,8 @@ func getMSTeamsPushPayload(p *api.PushPayload) (*MSTeamsPayload, error) {
 	}, nil
 }
 
-func getMSTeamsIssuesPayload(p *api.IssuePayload) (*MSTeamsPayload, error) {
-	text, _, attachmentText, color := getIssuesPayloadInfo(p, noneLinkFormatter, false)
+func getMSTeamsIssuesPayload(p *api.IssuePayload, lang string) (*MSTeamsPayload, error) {
+	text, _, attachmentText, color := getIssuesPayloadInfo(p, noneLinkFormatter, false, lang)
 
 	return &MSTeamsPayload{
 		Type:       "MessageCard",
@@ -307,8 +307,8 @@ func getMSTeamsIssuesPayload(p *api.IssuePayload) (*MSTeamsPayload, error) {
 	}, nil
 }
 
-func getMSTeamsIssueCommentPayload(p *api.IssueCommentPayload) (*MSTeamsPayload, error) {
-	text, _, color := getIssueCommentPayloadInfo(p, noneLinkFormatter, false)
+func getMSTeamsIssueCommentPayload(p *api.IssueCommentPayload, lang string) (*MSTeamsPayload, error) {
+	text, _, color := getIssueCommentPayloadInfo(p, noneLinkFormatter, false, lang)
 
 	return &MSTeamsPayload{
 		Type:       "MessageCard",
@@ -349,8 +349,8 @@ func getMSTeamsIssueCommentPayload(p *api.IssueCommentPayload) (*MSTeamsPayload,
 	}, nil
 }
 
-func getMSTeamsPullRequestPayload(p *api.PullRequestPayload) (*MSTeamsPayload, error) {
-	text, _, attachmentText, color := getPullRequestPayloadInfo(p, noneLinkFormatter, false)
+func getMSTeamsPullRequestPayload(p *api.PullRequestPayload, lang string) (*MSTeamsPayload, error) {
+	text, _, attachmentText, color := getPullRequestPayloadInfo(p, noneLinkFormatter, false, lang)
 
 	return &MSTeamsPayload{
 		Type:       "MessageCard",
@@ -502,8 +502,8 @@ func getMSTeamsRepositoryPayload(p *api.RepositoryPayload) (*MSTeamsPayload, err
 	}, nil
 }
 
-func getMSTeamsReleasePayload(p *api.ReleasePayload) (*MSTeamsPayload, error) {
-	text, color := getReleasePayloadInfo(p, noneLinkFormatter, false)
+func getMSTeamsReleasePayload(p *api.ReleasePayload, lang string) (*MSTeamsPayload, error) {
+	text, color := getReleasePayloadInfo(p, noneLinkFormatter, false, lang)
 
 	return &MSTeamsPayload{
 		Type:       "MessageCard",
@@ -545,7 +545,7 @@ func getMSTeamsReleasePayload(p *api.ReleasePayload) (*MSTeamsPayload, error) {
 }
 
 // GetMSTeamsPayload converts a MSTeams webhook into a MSTeamsPayload
-func GetMSTeamsPayload(p api.Payloader, event models.HookEventType, meta string) (*MSTeamsPayload, error) {
+func GetMSTeamsPayload(p api.Payloader, event models.HookEventType, meta, lang string) (*MSTeamsPayload, error) {
 	s := new(MSTeamsPayload)
 
 	switch event {
@@ -556,24 +556,24 @@ func GetMSTeamsPayload(p api.Payloader, event models.HookEventType, meta string)
 	case models.HookEventFork:
 		return getMSTeamsForkPayload(p.(*api.ForkPayload))
 	case models.HookEventIssues, models.HookEventIssueAssign, models.HookEventIssueLabel, models.HookEventIssueMilestone:
-		return getMSTeamsIssuesPayload(p.(*api.IssuePayload))
+		return getMSTeamsIssuesPayload(p.(*api.IssuePayload), lang)
 	case models.HookEventIssueComment, models.HookEventPullRequestComment:
 		pl, ok := p.(*api.IssueCommentPayload)
 		if ok {
-			return getMSTeamsIssueCommentPayload(pl)
+			return getMSTeamsIssueCommentPayload(pl, lang)
 		}
-		return getMSTeamsPullRequestPayload(p.(*api.PullRequestPayload))
+		return getMSTeamsPullRequestPayload(p.(*api.PullRequestPayload), lang)
 	case models.HookEventPush:
 		return getMSTeamsPushPayload(p.(*api.PushPayload))
 	case models.HookEventPullRequest, models.HookEventPullRequestAssign, models.HookEventPullRequestLabel,
 		models.HookEventPullRequestMilestone, models.HookEventPullRequestSync:
-		return getMSTeamsPullRequestPayload(p.(*api.PullRequestPayload))
+		return getMSTeamsPullRequestPayload(p.(*api.PullRequestPayload), lang)
 	case models.HookEventPullRequestReviewRejected, models.HookEventPullRequestReviewApproved, models.HookEventPullRequestReviewComment:
 		return getMSTeamsPullRequestApprovalPayload(p.(*api.PullRequestPayload), event)
 	case models.HookEventRepository:
 		return getMSTeamsRepositoryPayload(p.(*api.RepositoryPayload))
 	case models.HookEventRelease:
-		return getMSTeamsReleasePayload(p.(*api.ReleasePayload))
+		return getMSTeamsReleasePayload(p.(*api.ReleasePayload), lang)
 	}
 
 	return s, nil
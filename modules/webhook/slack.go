@@ -137,8 +137,8 @@ func getSlackForkPayload(p *api.ForkPayload, slack *SlackMeta) (*SlackPayload, e
 	}, nil
 }
 
-func getSlackIssuesPayload(p *api.IssuePayload, slack *SlackMeta) (*SlackPayload, error) {
-	text, issueTitle, attachmentText, color := getIssuesPayloadInfo(p, SlackLinkFormatter, true)
+func getSlackIssuesPayload(p *api.IssuePayload, slack *SlackMeta, lang string) (*SlackPayload, error) {
+	text, issueTitle, attachmentText, color := getIssuesPayloadInfo(p, SlackLinkFormatter, true, lang)
 
 	pl := &SlackPayload{
 		Channel:  slack.Channel,
@@ -160,8 +160,8 @@ func getSlackIssuesPayload(p *api.IssuePayload, slack *SlackMeta) (*SlackPayload
 	return pl, nil
 }
 
-func getSlackIssueCommentPayload(p *api.IssueCommentPayload, slack *SlackMeta) (*SlackPayload, error) {
-	text, issueTitle, color := getIssueCommentPayloadInfo(p, SlackLinkFormatter, true)
+func getSlackIssueCommentPayload(p *api.IssueCommentPayload, slack *SlackMeta, lang string) (*SlackPayload, error) {
+	text, issueTitle, color := getIssueCommentPayloadInfo(p, SlackLinkFormatter, true, lang)
 
 	return &SlackPayload{
 		Channel:  slack.Channel,
@@ -177,8 +177,8 @@ func getSlackIssueCommentPayload(p *api.IssueCommentPayload, slack *SlackMeta) (
 	}, nil
 }
 
-func getSlackReleasePayload(p *api.ReleasePayload, slack *SlackMeta) (*SlackPayload, error) {
-	text, _ := getReleasePayloadInfo(p, SlackLinkFormatter, true)
+func getSlackReleasePayload(p *api.ReleasePayload, slack *SlackMeta, lang string) (*SlackPayload, error) {
+	text, _ := getReleasePayloadInfo(p, SlackLinkFormatter, true, lang)
 
 	return &SlackPayload{
 		Channel:  slack.Channel,
@@ -234,8 +234,8 @@ func getSlackPushPayload(p *api.PushPayload, slack *SlackMeta) (*SlackPayload, e
 	}, nil
 }
 
-func getSlackPullRequestPayload(p *api.PullRequestPayload, slack *SlackMeta) (*SlackPayload, error) {
-	text, issueTitle, attachmentText, color := getPullRequestPayloadInfo(p, SlackLinkFormatter, true)
+func getSlackPullRequestPayload(p *api.PullRequestPayload, slack *SlackMeta, lang string) (*SlackPayload, error) {
+	text, issueTitle, attachmentText, color := getPullRequestPayloadInfo(p, SlackLinkFormatter, true, lang)
 
 	pl := &SlackPayload{
 		Channel:  slack.Channel,
@@ -303,7 +303,7 @@ func getSlackRepositoryPayload(p *api.RepositoryPayload, slack *SlackMeta) (*Sla
 }
 
 // GetSlackPayload converts a slack webhook into a SlackPayload
-func GetSlackPayload(p api.Payloader, event models.HookEventType, meta string) (*SlackPayload, error) {
+func GetSlackPayload(p api.Payloader, event models.HookEventType, meta, lang string) (*SlackPayload, error) {
 	s := new(SlackPayload)
 
 	slack := &SlackMeta{}
@@ -319,24 +319,24 @@ func GetSlackPayload(p api.Payloader, event models.HookEventType, meta string) (
 	case models.HookEventFork:
 		return getSlackForkPayload(p.(*api.ForkPayload), slack)
 	case models.HookEventIssues, models.HookEventIssueAssign, models.HookEventIssueLabel, models.HookEventIssueMilestone:
-		return getSlackIssuesPayload(p.(*api.IssuePayload), slack)
+		return getSlackIssuesPayload(p.(*api.IssuePayload), slack, lang)
 	case models.HookEventIssueComment, models.HookEventPullRequestComment:
 		pl, ok := p.(*api.IssueCommentPayload)
 		if ok {
-			return getSlackIssueCommentPayload(pl, slack)
+			return getSlackIssueCommentPayload(pl, slack, lang)
 		}
-		return getSlackPullRequestPayload(p.(*api.PullRequestPayload), slack)
+		return getSlackPullRequestPayload(p.(*api.PullRequestPayload), slack, lang)
 	case models.HookEventPush:
 		return getSlackPushPayload(p.(*api.PushPayload), slack)
 	case models.HookEventPullRequest, models.HookEventPullRequestAssign, models.HookEventPullRequestLabel,
 		models.HookEventPullRequestMilestone, models.HookEventPullRequestSync:
-		return getSlackPullRequestPayload(p.(*api.PullRequestPayload), slack)
+		return getSlackPullRequestPayload(p.(*api.PullRequestPayload), slack, lang)
 	case models.HookEventPullRequestReviewRejected, models.HookEventPullRequestReviewApproved, models.HookEventPullRequestReviewComment:
 		return getSlackPullRequestApprovalPayload(p.(*api.PullRequestPayload), slack, event)
 	case models.HookEventRepository:
 		return getSlackRepositoryPayload(p.(*api.RepositoryPayload), slack)
 	case models.HookEventRelease:
-		return getSlackReleasePayload(p.(*api.ReleasePayload), slack)
+		return getSlackReleasePayload(p.(*api.ReleasePayload), slack, lang)
 	}
 
 	return s, nil
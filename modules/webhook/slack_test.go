@@ -19,13 +19,13 @@ func TestSlackIssuesPayloadOpened(t *testing.T) {
 	}
 
 	p.Action = api.HookIssueOpened
-	pl, err := getSlackIssuesPayload(p, sl)
+	pl, err := getSlackIssuesPayload(p, sl, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Issue opened: <http://localhost:3000/test/repo/issues/2|#2 crash> by <https://try.gitea.io/user1|user1>", pl.Text)
 
 	p.Action = api.HookIssueClosed
-	pl, err = getSlackIssuesPayload(p, sl)
+	pl, err = getSlackIssuesPayload(p, sl, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Issue closed: <http://localhost:3000/test/repo/issues/2|#2 crash> by <https://try.gitea.io/user1|user1>", pl.Text)
@@ -38,7 +38,7 @@ func TestSlackIssueCommentPayload(t *testing.T) {
 		Username: p.Sender.UserName,
 	}
 
-	pl, err := getSlackIssueCommentPayload(p, sl)
+	pl, err := getSlackIssueCommentPayload(p, sl, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 
@@ -52,7 +52,7 @@ func TestSlackPullRequestCommentPayload(t *testing.T) {
 		Username: p.Sender.UserName,
 	}
 
-	pl, err := getSlackIssueCommentPayload(p, sl)
+	pl, err := getSlackIssueCommentPayload(p, sl, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 
@@ -66,7 +66,7 @@ func TestSlackReleasePayload(t *testing.T) {
 		Username: p.Sender.UserName,
 	}
 
-	pl, err := getSlackReleasePayload(p, sl)
+	pl, err := getSlackReleasePayload(p, sl, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 
@@ -80,7 +80,7 @@ func TestSlackPullRequestPayload(t *testing.T) {
 		Username: p.Sender.UserName,
 	}
 
-	pl, err := getSlackPullRequestPayload(p, sl)
+	pl, err := getSlackPullRequestPayload(p, sl, "en-US")
 	require.Nil(t, err)
 	require.NotNil(t, pl)
 
@@ -124,24 +124,24 @@ func getTelegramPushPayload(p *api.PushPayload) (*TelegramPayload, error) {
 	}, nil
 }
 
-func getTelegramIssuesPayload(p *api.IssuePayload) (*TelegramPayload, error) {
-	text, _, attachmentText, _ := getIssuesPayloadInfo(p, htmlLinkFormatter, true)
+func getTelegramIssuesPayload(p *api.IssuePayload, lang string) (*TelegramPayload, error) {
+	text, _, attachmentText, _ := getIssuesPayloadInfo(p, htmlLinkFormatter, true, lang)
 
 	return &TelegramPayload{
 		Message: text + "\n\n" + attachmentText,
 	}, nil
 }
 
-func getTelegramIssueCommentPayload(p *api.IssueCommentPayload) (*TelegramPayload, error) {
-	text, _, _ := getIssueCommentPayloadInfo(p, htmlLinkFormatter, true)
+func getTelegramIssueCommentPayload(p *api.IssueCommentPayload, lang string) (*TelegramPayload, error) {
+	text, _, _ := getIssueCommentPayloadInfo(p, htmlLinkFormatter, true, lang)
 
 	return &TelegramPayload{
 		Message: text + "\n" + p.Comment.Body,
 	}, nil
 }
 
-func getTelegramPullRequestPayload(p *api.PullRequestPayload) (*TelegramPayload, error) {
-	text, _, attachmentText, _ := getPullRequestPayloadInfo(p, htmlLinkFormatter, true)
+func getTelegramPullRequestPayload(p *api.PullRequestPayload, lang string) (*TelegramPayload, error) {
+	text, _, attachmentText, _ := getPullRequestPayloadInfo(p, htmlLinkFormatter, true, lang)
 
 	return &TelegramPayload{
 		Message: text + "\n" + attachmentText,
@@ -184,8 +184,8 @@ func getTelegramRepositoryPayload(p *api.RepositoryPayload) (*TelegramPayload, e
 	return nil, nil
 }
 
-func getTelegramReleasePayload(p *api.ReleasePayload) (*TelegramPayload, error) {
-	text, _ := getReleasePayloadInfo(p, htmlLinkFormatter, true)
+func getTelegramReleasePayload(p *api.ReleasePayload, lang string) (*TelegramPayload, error) {
+	text, _ := getReleasePayloadInfo(p, htmlLinkFormatter, true, lang)
 
 	return &TelegramPayload{
 		Message: text + "\n",
@@ -193,7 +193,7 @@ func getTelegramReleasePayload(p *api.ReleasePayload) (*TelegramPayload, error)
 }
 
 // GetTelegramPayload converts a telegram webhook into a TelegramPayload
-func GetTelegramPayload(p api.Payloader, event models.HookEventType, meta string) (*TelegramPayload, error) {
+func GetTelegramPayload(p api.Payloader, event models.HookEventType, meta, lang string) (*TelegramPayload, error) {
 	s := new(TelegramPayload)
 
 	switch event {
@@ -204,24 +204,24 @@ func GetTelegramPayload(p api.Payloader, event models.HookEventType, meta string
 	case models.HookEventFork:
 		return getTelegramForkPayload(p.(*api.ForkPayload))
 	case models.HookEventIssues, models.HookEventIssueAssign, models.HookEventIssueLabel, models.HookEventIssueMilestone:
-		return getTelegramIssuesPayload(p.(*api.IssuePayload))
+		return getTelegramIssuesPayload(p.(*api.IssuePayload), lang)
 	case models.HookEventIssueComment, models.HookEventPullRequestComment:
 		pl, ok := p.(*api.IssueCommentPayload)
 		if ok {
-			return getTelegramIssueCommentPayload(pl)
+			return getTelegramIssueCommentPayload(pl, lang)
 		}
-		return getTelegramPullRequestPayload(p.(*api.PullRequestPayload))
+		return getTelegramPullRequestPayload(p.(*api.PullRequestPayload), lang)
 	case models.HookEventPush:
 		return getTelegramPushPayload(p.(*api.PushPayload))
 	case models.HookEventPullRequest, models.HookEventPullRequestAssign, models.HookEventPullRequestLabel,
 		models.HookEventPullRequestMilestone, models.HookEventPullRequestSync:
-		return getTelegramPullRequestPayload(p.(*api.PullRequestPayload))
+		return getTelegramPullRequestPayload(p.(*api.PullRequestPayload), lang)
 	case models.HookEventPullRequestReviewRejected, models.HookEventPullRequestReviewApproved, models.HookEventPullRequestReviewComment:
 		return getTelegramPullRequestApprovalPayload(p.(*api.PullRequestPayload), event)
 	case models.HookEventRepository:
 		return getTelegramRepositoryPayload(p.(*api.RepositoryPayload))
 	case models.HookEventRelease:
-		return getTelegramReleasePayload(p.(*api.ReleasePayload))
+		return getTelegramReleasePayload(p.(*api.ReleasePayload), lang)
 	}
 
 	return s, nil
@@ -85,44 +85,60 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 		}
 	}
 
+	_, err := createHookTask(w, repo, event, p)
+	return err
+}
+
+// createHookTask converts the payload to the format of the webhook type,
+// signs it and stores it as an undelivered hook task.
+func createHookTask(w *models.Webhook, repo *models.Repository, event models.HookEventType, p api.Payloader) (*models.HookTask, error) {
 	var payloader api.Payloader
 	var err error
+	var lang string
+	if w.HookTaskType != models.GITEA && w.HookTaskType != models.GOGS {
+		// the texts of the chat messages use the default language of the repository
+		localeSetting, err := models.GetEffectiveLocaleSetting(repo)
+		if err != nil {
+			return nil, fmt.Errorf("GetEffectiveLocaleSetting: %v", err)
+		}
+		lang = localeSetting.Language
+	}
 	// Use separate objects so modifications won't be made on payload on non-Gogs/Gitea type hooks.
 	switch w.HookTaskType {
 	case models.SLACK:
-		payloader, err = GetSlackPayload(p, event, w.Meta)
+		payloader, err = GetSlackPayload(p, event, w.Meta, lang)
 		if err != nil {
-			return fmt.Errorf("GetSlackPayload: %v", err)
+			return nil, fmt.Errorf("GetSlackPayload: %v", err)
 		}
 	case models.DISCORD:
-		payloader, err = GetDiscordPayload(p, event, w.Meta)
+		payloader, err = GetDiscordPayload(p, event, w.Meta, lang)
 		if err != nil {
-			return fmt.Errorf("GetDiscordPayload: %v", err)
+			return nil, fmt.Errorf("GetDiscordPayload: %v", err)
 		}
 	case models.DINGTALK:
-		payloader, err = GetDingtalkPayload(p, event, w.Meta)
+		payloader, err = GetDingtalkPayload(p, event, w.Meta, lang)
 		if err != nil {
-			return fmt.Errorf("GetDingtalkPayload: %v", err)
+			return nil, fmt.Errorf("GetDingtalkPayload: %v", err)
 		}
 	case models.TELEGRAM:
-		payloader, err = GetTelegramPayload(p, event, w.Meta)
+		payloader, err = GetTelegramPayload(p, event, w.Meta, lang)
 		if err != nil {
-			return fmt.Errorf("GetTelegramPayload: %v", err)
+			return nil, fmt.Errorf("GetTelegramPayload: %v", err)
 		}
 	case models.MSTEAMS:
-		payloader, err = GetMSTeamsPayload(p, event, w.Meta)
+		payloader, err = GetMSTeamsPayload(p, event, w.Meta, lang)
 		if err != nil {
-			return fmt.Errorf("GetMSTeamsPayload: %v", err)
+			return nil, fmt.Errorf("GetMSTeamsPayload: %v", err)
 		}
 	case models.FEISHU:
-		payloader, err = GetFeishuPayload(p, event, w.Meta)
+		payloader, err = GetFeishuPayload(p, event, w.Meta, lang)
 		if err != nil {
-			return fmt.Errorf("GetFeishuPayload: %v", err)
+			return nil, fmt.Errorf("GetFeishuPayload: %v", err)
 		}
 	case models.MATRIX:
-		payloader, err = GetMatrixPayload(p, event, w.Meta)
+		payloader, err = GetMatrixPayload(p, event, w.Meta, lang)
 		if err != nil {
-			return fmt.Errorf("GetMatrixPayload: %v", err)
+			return nil, fmt.Errorf("GetMatrixPayload: %v", err)
 		}
 	default:
 		p.SetSecret(w.Secret)
@@ -143,7 +159,7 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 		signature = hex.EncodeToString(sig.Sum(nil))
 	}
 
-	if err = models.CreateHookTask(&models.HookTask{
+	t := &models.HookTask{
 		RepoID:      repo.ID,
 		HookID:      w.ID,
 		Type:        w.HookTaskType,
@@ -154,10 +170,11 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 		ContentType: w.ContentType,
 		EventType:   event,
 		IsSSL:       w.IsSSL,
-	}); err != nil {
-		return fmt.Errorf("CreateHookTask: %v", err)
 	}
-	return nil
+	if err = models.CreateHookTask(t); err != nil {
+		return nil, fmt.Errorf("CreateHookTask: %v", err)
+	}
+	return t, nil
 }
 
 // PrepareWebhooks adds new webhooks to task queue for given payload.
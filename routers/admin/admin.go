@@ -18,10 +18,13 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/cluster"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/cron"
 	"code.gitea.io/gitea/modules/git"
+	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/maintenance"
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
@@ -173,6 +176,37 @@ func SendTestMail(ctx *context.Context) {
 	ctx.Redirect(setting.AppSubURL + "/admin/config")
 }
 
+// ReloadConfig reloads the hot-reloadable settings from the custom config
+func ReloadConfig(ctx *context.Context) {
+	reloadSettings(ctx)
+	ctx.Redirect(setting.AppSubURL + "/admin/config")
+}
+
+// reloadSettings reloads the hot-reloadable settings and flashes which ones were applied,
+// after the given messages
+func reloadSettings(ctx *context.Context, msgs ...string) {
+	result, err := setting.ReloadSettings()
+	if err != nil {
+		ctx.Flash.Error(strings.Join(append(msgs, ctx.Tr("admin.config.reload_failed", err)), " "))
+		return
+	}
+	log.Info("Settings reloaded by %s", ctx.User.Name)
+
+	if len(result.Reloaded) > 0 {
+		msgs = append(msgs, ctx.Tr("admin.config.reload_success", strings.Join(result.Reloaded, ", ")))
+	} else {
+		msgs = append(msgs, ctx.Tr("admin.config.reload_unchanged"))
+	}
+	if len(result.RestartRequired) > 0 {
+		msgs = append(msgs, ctx.Tr("admin.config.reload_restart_required", strings.Join(result.RestartRequired, ", ")))
+	}
+	if len(result.Reloaded) > 0 {
+		ctx.Flash.Success(strings.Join(msgs, " "))
+	} else {
+		ctx.Flash.Info(strings.Join(msgs, " "))
+	}
+}
+
 func shadowPasswordKV(cfgItem, splitter string) string {
 	fields := strings.Split(cfgItem, splitter)
 	for i := 0; i < len(fields); i++ {
@@ -293,6 +327,9 @@ func Config(ctx *context.Context) {
 	ctx.Data["EnableFederatedAvatar"] = setting.EnableFederatedAvatar
 
 	ctx.Data["Git"] = setting.Git
+	ctx.Data["ReloadableSettings"] = setting.GetReloadableSettings()
+	ctx.Data["ReloadOnSIGHUP"] = setting.ReloadOnSIGHUP
+	ctx.Data["EnableConfigEditor"] = setting.Admin.EnableConfigEditor
 
 	type envVar struct {
 		Name, Value string
@@ -326,9 +363,36 @@ func Monitor(ctx *context.Context) {
 	ctx.Data["Processes"] = process.GetManager().Processes()
 	ctx.Data["Entries"] = cron.ListTasks()
 	ctx.Data["Queues"] = queue.GetManager().ManagedQueues()
+	ctx.Data["Maintenance"] = maintenance.GetStatus()
+	ctx.Data["IssueIndexerCheck"] = issue_indexer.GetCheckStatus()
+	if setting.Cluster.Enabled {
+		leader, err := cluster.Leader()
+		if err != nil {
+			ctx.ServerError("Leader", err)
+			return
+		}
+		ctx.Data["ClusterNode"] = setting.Cluster.NodeName
+		ctx.Data["ClusterLeader"] = leader
+	}
 	ctx.HTML(200, tplMonitor)
 }
 
+// EnableMaintenance declines new writes to the instance
+func EnableMaintenance(ctx *context.Context) {
+	maintenance.Enable(ctx.Query("message"))
+	log.Info("Maintenance mode enabled by %s", ctx.User.Name)
+	ctx.Flash.Success(ctx.Tr("admin.monitor.maintenance.enabled"))
+	ctx.Redirect(setting.AppSubURL + "/admin/monitor")
+}
+
+// DisableMaintenance accepts writes to the instance again
+func DisableMaintenance(ctx *context.Context) {
+	maintenance.Disable()
+	log.Info("Maintenance mode disabled by %s", ctx.User.Name)
+	ctx.Flash.Success(ctx.Tr("admin.monitor.maintenance.disabled"))
+	ctx.Redirect(setting.AppSubURL + "/admin/monitor")
+}
+
 // MonitorCancel cancels a process
 func MonitorCancel(ctx *context.Context) {
 	pid := ctx.ParamsInt64("pid")
@@ -482,3 +546,71 @@ func SetQueueSettings(ctx *context.Context) {
 	ctx.Flash.Success(ctx.Tr("admin.monitor.queue.settings.changed"))
 	ctx.Redirect(setting.AppSubURL + fmt.Sprintf("/admin/monitor/queue/%d", qid))
 }
+
+// PauseQueue stops a queue from handling its data
+func PauseQueue(ctx *context.Context) {
+	qid := ctx.ParamsInt64("qid")
+	mq := queue.GetManager().GetManagedQueue(qid)
+	if mq == nil {
+		ctx.Status(404)
+		return
+	}
+	if !mq.IsPausable() {
+		ctx.Flash.Error(ctx.Tr("admin.monitor.queue.pause.none"))
+		ctx.Redirect(setting.AppSubURL + fmt.Sprintf("/admin/monitor/queue/%d", qid))
+		return
+	}
+	mq.Pause()
+	ctx.Flash.Success(ctx.Tr("admin.monitor.queue.pause.paused", mq.Name))
+	ctx.Redirect(setting.AppSubURL + fmt.Sprintf("/admin/monitor/queue/%d", qid))
+}
+
+// ResumeQueue restarts the handling of the data of a paused queue
+func ResumeQueue(ctx *context.Context) {
+	qid := ctx.ParamsInt64("qid")
+	mq := queue.GetManager().GetManagedQueue(qid)
+	if mq == nil {
+		ctx.Status(404)
+		return
+	}
+	mq.Resume()
+	ctx.Flash.Success(ctx.Tr("admin.monitor.queue.pause.resumed", mq.Name))
+	ctx.Redirect(setting.AppSubURL + fmt.Sprintf("/admin/monitor/queue/%d", qid))
+}
+
+// RetryDeadItems pushes the data of a dead item, or of all dead items if no
+// id is given, back into the queue
+func RetryDeadItems(ctx *context.Context) {
+	qid := ctx.ParamsInt64("qid")
+	mq := queue.GetManager().GetManagedQueue(qid)
+	if mq == nil {
+		ctx.Status(404)
+		return
+	}
+	var retried int
+	if id := ctx.QueryInt64("id"); id > 0 {
+		retried = mq.RetryDeadItems(id)
+	} else {
+		retried = mq.RetryDeadItems()
+	}
+	ctx.Flash.Success(ctx.Tr("admin.monitor.queue.dead.retried", retried))
+	ctx.Redirect(setting.AppSubURL + fmt.Sprintf("/admin/monitor/queue/%d", qid))
+}
+
+// RemoveDeadItems removes a dead item, or all dead items if no id is given
+func RemoveDeadItems(ctx *context.Context) {
+	qid := ctx.ParamsInt64("qid")
+	mq := queue.GetManager().GetManagedQueue(qid)
+	if mq == nil {
+		ctx.Status(404)
+		return
+	}
+	var removed int
+	if id := ctx.QueryInt64("id"); id > 0 {
+		removed = mq.RemoveDeadItems(id)
+	} else {
+		removed = mq.RemoveDeadItems()
+	}
+	ctx.Flash.Success(ctx.Tr("admin.monitor.queue.dead.removed", removed))
+	ctx.Redirect(setting.AppSubURL + fmt.Sprintf("/admin/monitor/queue/%d", qid))
+}
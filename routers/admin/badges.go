@@ -0,0 +1,277 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplBadges    base.TplName = "admin/badge/list"
+	tplBadgeNew  base.TplName = "admin/badge/new"
+	tplBadgeEdit base.TplName = "admin/badge/edit"
+)
+
+// Badges show the badges and how many users they were granted to
+func Badges(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.badges")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminBadges"] = true
+
+	badges, err := models.GetBadges()
+	if err != nil {
+		ctx.ServerError("GetBadges", err)
+		return
+	}
+	ctx.Data["Badges"] = badges
+
+	ctx.HTML(200, tplBadges)
+}
+
+// NewBadge render the page to create a badge
+func NewBadge(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.badges.new")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminBadges"] = true
+	ctx.Data["BadgeTypes"] = models.BadgeTypes()
+	ctx.Data["type"] = models.BadgeTypeManual.String()
+
+	ctx.HTML(200, tplBadgeNew)
+}
+
+// NewBadgePost response for creating a badge
+func NewBadgePost(ctx *context.Context, form auth.AdminBadgeForm) {
+	ctx.Data["Title"] = ctx.Tr("admin.badges.new")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminBadges"] = true
+	ctx.Data["BadgeTypes"] = models.BadgeTypes()
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplBadgeNew)
+		return
+	}
+
+	badge := &models.Badge{Slug: form.Slug}
+	if !applyBadgeForm(ctx, badge, form, tplBadgeNew) {
+		return
+	}
+	if err := models.CreateBadge(badge); err != nil {
+		if !handleBadgeError(ctx, err, form, tplBadgeNew) {
+			ctx.ServerError("CreateBadge", err)
+		}
+		return
+	}
+	log.Trace("Badge created by admin (%s): %s", ctx.User.Name, badge.Slug)
+
+	ctx.Flash.Success(ctx.Tr("admin.badges.new_success", badge.Name))
+	ctx.Redirect(fmt.Sprintf("%s/admin/badges/%d", setting.AppSubURL, badge.ID))
+}
+
+func prepareBadgeInfo(ctx *context.Context) *models.Badge {
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminBadges"] = true
+	ctx.Data["BadgeTypes"] = models.BadgeTypes()
+
+	badge, err := models.GetBadgeByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrBadgeNotExist(err) {
+			ctx.NotFound("GetBadgeByID", err)
+		} else {
+			ctx.ServerError("GetBadgeByID", err)
+		}
+		return nil
+	}
+	ctx.Data["Title"] = badge.Name
+	ctx.Data["Badge"] = badge
+
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	users, count, err := models.GetBadgeUsers(badge.ID, models.ListOptions{
+		Page:     page,
+		PageSize: setting.UI.Admin.UserPagingNum,
+	})
+	if err != nil {
+		ctx.ServerError("GetBadgeUsers", err)
+		return nil
+	}
+	ctx.Data["Users"] = users
+	ctx.Data["Total"] = count
+	ctx.Data["Page"] = context.NewPagination(int(count), setting.UI.Admin.UserPagingNum, page, 5)
+
+	return badge
+}
+
+// EditBadge show the page to edit a badge and the users it was granted to
+func EditBadge(ctx *context.Context) {
+	badge := prepareBadgeInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["slug"] = badge.Slug
+	ctx.Data["name"] = badge.Name
+	ctx.Data["description"] = badge.Description
+	ctx.Data["icon"] = badge.Icon
+	ctx.Data["type"] = badge.Type.String()
+	ctx.Data["threshold"] = badge.Threshold
+
+	ctx.HTML(200, tplBadgeEdit)
+}
+
+// EditBadgePost response for editing a badge
+func EditBadgePost(ctx *context.Context, form auth.AdminBadgeForm) {
+	badge := prepareBadgeInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	// the slug identifies the badge and cannot be changed
+	form.Slug = badge.Slug
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplBadgeEdit)
+		return
+	}
+
+	if !applyBadgeForm(ctx, badge, form, tplBadgeEdit) {
+		return
+	}
+	if err := models.UpdateBadge(badge); err != nil {
+		if !handleBadgeError(ctx, err, form, tplBadgeEdit) {
+			ctx.ServerError("UpdateBadge", err)
+		}
+		return
+	}
+	log.Trace("Badge updated by admin (%s): %s", ctx.User.Name, badge.Slug)
+
+	ctx.Flash.Success(ctx.Tr("admin.badges.update_success"))
+	ctx.Redirect(fmt.Sprintf("%s/admin/badges/%d", setting.AppSubURL, badge.ID))
+}
+
+// applyBadgeForm sets the fields of the badge from the form, it renders the
+// form again if the criteria is unknown
+func applyBadgeForm(ctx *context.Context, badge *models.Badge, form auth.AdminBadgeForm, tpl base.TplName) bool {
+	badgeType, ok := models.ParseBadgeType(form.Type)
+	if !ok {
+		ctx.Data["Err_Type"] = true
+		ctx.RenderWithErr(ctx.Tr("admin.badges.type_invalid"), tpl, &form)
+		return false
+	}
+	badge.Name = form.Name
+	badge.Description = form.Description
+	badge.Icon = form.Icon
+	badge.Type = badgeType
+	badge.Threshold = form.Threshold
+	return true
+}
+
+// handleBadgeError renders the form again for the validation errors of the
+// badge, it returns false for other errors
+func handleBadgeError(ctx *context.Context, err error, form auth.AdminBadgeForm, tpl base.TplName) bool {
+	switch {
+	case models.IsErrBadgeAlreadyExist(err):
+		ctx.Data["Err_Slug"] = true
+		ctx.RenderWithErr(ctx.Tr("admin.badges.slug_been_taken", form.Slug), tpl, &form)
+	case models.IsErrBadgeInvalid(err):
+		field := err.(models.ErrBadgeInvalid).Field
+		ctx.Data["Err_"+strings.Title(field)] = true
+		ctx.RenderWithErr(ctx.Tr("admin.badges."+field+"_invalid"), tpl, &form)
+	default:
+		return false
+	}
+	return true
+}
+
+// DeleteBadge deletes a badge and revokes it from all users
+func DeleteBadge(ctx *context.Context) {
+	badge, err := models.GetBadgeByID(ctx.QueryInt64("id"))
+	if err != nil {
+		if !models.IsErrBadgeNotExist(err) {
+			ctx.ServerError("GetBadgeByID", err)
+			return
+		}
+	} else if err = models.DeleteBadge(badge); err != nil {
+		if !models.IsErrBadgeBuiltin(err) {
+			ctx.ServerError("DeleteBadge", err)
+			return
+		}
+		ctx.Flash.Error(ctx.Tr("admin.badges.builtin_deletion"))
+	} else {
+		log.Trace("Badge deleted by admin (%s): %s", ctx.User.Name, badge.Slug)
+		ctx.Flash.Success(ctx.Tr("admin.badges.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/admin/badges",
+	})
+}
+
+// GrantBadge grants a badge to a user
+func GrantBadge(ctx *context.Context) {
+	badge := prepareBadgeInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	link := fmt.Sprintf("%s/admin/badges/%d", setting.AppSubURL, badge.ID)
+
+	u, err := models.GetUserByName(ctx.QueryTrim("user_name"))
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("form.user_not_exist"))
+			ctx.Redirect(link)
+		} else {
+			ctx.ServerError("GetUserByName", err)
+		}
+		return
+	}
+	if u.IsOrganization() {
+		ctx.Flash.Error(ctx.Tr("admin.badges.grant_org"))
+		ctx.Redirect(link)
+		return
+	}
+
+	if err = models.AddUserBadge(u, badge); err != nil {
+		ctx.ServerError("AddUserBadge", err)
+		return
+	}
+	log.Trace("Badge %s granted by admin (%s) to %s", badge.Slug, ctx.User.Name, u.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.badges.grant_success", badge.Name, u.Name))
+	ctx.Redirect(link)
+}
+
+// RevokeBadge revokes a badge from a user
+func RevokeBadge(ctx *context.Context) {
+	badge := prepareBadgeInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	u, err := models.GetUserByID(ctx.QueryInt64("id"))
+	if err != nil {
+		if !models.IsErrUserNotExist(err) {
+			ctx.ServerError("GetUserByID", err)
+			return
+		}
+	} else if err = models.RemoveUserBadge(u, badge); err != nil {
+		ctx.ServerError("RemoveUserBadge", err)
+		return
+	} else {
+		log.Trace("Badge %s revoked by admin (%s) from %s", badge.Slug, ctx.User.Name, u.Name)
+		ctx.Flash.Success(ctx.Tr("admin.badges.revoke_success", badge.Name, u.Name))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": fmt.Sprintf("%s/admin/badges/%d", setting.AppSubURL, badge.ID),
+	})
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplConfigEditor        base.TplName = "admin/config_editor/list"
+	tplConfigEditorSection base.TplName = "admin/config_editor/section"
+)
+
+// ConfigEditorEnabled makes the config editor return 404 unless it is enabled
+func ConfigEditorEnabled(ctx *context.Context) {
+	if !setting.Admin.EnableConfigEditor {
+		ctx.NotFound("ConfigEditorEnabled", nil)
+	}
+}
+
+// ConfigEditor shows the sections of the custom config
+func ConfigEditor(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.config.editor")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminConfig"] = true
+
+	if section := ctx.QueryTrim("section"); section != "" {
+		ctx.Redirect(setting.AppSubURL + "/admin/config/edit/" + url.PathEscape(section))
+		return
+	}
+
+	sections, err := setting.GetCustomConfSections()
+	if err != nil {
+		ctx.ServerError("GetCustomConfSections", err)
+		return
+	}
+	ctx.Data["CustomConf"] = setting.CustomConf
+	ctx.Data["Sections"] = sections
+	ctx.HTML(200, tplConfigEditor)
+}
+
+// ConfigEditorSection shows the form editing a section of the custom config
+func ConfigEditorSection(ctx *context.Context) {
+	section, err := setting.GetCustomConfSection(ctx.Params(":section"))
+	if err != nil {
+		ctx.NotFound("GetCustomConfSection", err)
+		return
+	}
+	ctx.Data["Title"] = ctx.Tr("admin.config.editor")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminConfig"] = true
+	ctx.Data["CustomConf"] = setting.CustomConf
+	ctx.Data["Section"] = section
+	ctx.HTML(200, tplConfigEditorSection)
+}
+
+// ConfigEditorSectionPost writes the changes of a section to the custom config and reloads it
+func ConfigEditorSectionPost(ctx *context.Context) {
+	name := ctx.Params(":section")
+	section, err := setting.GetCustomConfSection(name)
+	if err != nil {
+		ctx.NotFound("GetCustomConfSection", err)
+		return
+	}
+	link := setting.AppSubURL + "/admin/config/edit/" + url.PathEscape(name)
+
+	changes := &setting.CustomConfChanges{Set: make(map[string]string)}
+	for _, key := range section.Keys {
+		if ctx.Query("delete_"+key.Name) != "" {
+			changes.Delete = append(changes.Delete, key.Name)
+		} else if _, has := ctx.Req.Form["value_"+key.Name]; has {
+			changes.Set[key.Name] = ctx.Query("value_" + key.Name)
+		}
+	}
+	if newKey := strings.ToUpper(ctx.QueryTrim("new_key")); newKey != "" {
+		changes.Set[newKey] = ctx.Query("new_value")
+	}
+
+	if err := setting.SaveCustomConfSection(name, changes); err != nil {
+		ctx.Flash.Error(ctx.Tr("admin.config.editor.save_failed", err))
+		ctx.Redirect(link)
+		return
+	}
+	log.Info("Section [%s] of the custom config changed by %s", name, ctx.User.Name)
+
+	reloadSettings(ctx, ctx.Tr("admin.config.editor.saved", name))
+	ctx.Redirect(link)
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const tplEmailBounces base.TplName = "admin/emails/bounces"
+
+// EmailBounces shows the addresses which bounced, only the suppressed ones unless all is set
+func EmailBounces(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.emails.bounces")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminEmails"] = true
+
+	opts := &models.SearchEmailBounceOptions{
+		ListOptions: models.ListOptions{
+			PageSize: setting.UI.Admin.UserPagingNum,
+			Page:     ctx.QueryInt("page"),
+		},
+		Keyword:        ctx.QueryTrim("q"),
+		SuppressedOnly: !ctx.QueryBool("all"),
+	}
+	if opts.Page <= 1 {
+		opts.Page = 1
+	}
+	if len(opts.Keyword) > 0 && !isKeywordValid(opts.Keyword) {
+		opts.Keyword = ""
+	}
+
+	bounces, count, err := models.SearchEmailBounces(opts)
+	if err != nil {
+		ctx.ServerError("SearchEmailBounces", err)
+		return
+	}
+	ctx.Data["Keyword"] = opts.Keyword
+	ctx.Data["ShowAll"] = !opts.SuppressedOnly
+	ctx.Data["Total"] = count
+	ctx.Data["Bounces"] = bounces
+
+	pager := context.NewPagination(int(count), opts.PageSize, opts.Page, 5)
+	pager.SetDefaultParams(ctx)
+	pager.AddParam(ctx, "all", "ShowAll")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplEmailBounces)
+}
+
+// DeleteEmailBounce removes the bounces of an address, so that mails are sent to it again
+func DeleteEmailBounce(ctx *context.Context) {
+	bounce, err := models.DeleteEmailBounce(ctx.QueryInt64("id"))
+	if err != nil {
+		if models.IsErrEmailBounceNotExist(err) {
+			ctx.NotFound("DeleteEmailBounce", err)
+		} else {
+			ctx.ServerError("DeleteEmailBounce", err)
+		}
+		return
+	}
+	log.Trace("Bounces of %s deleted by %s", bounce.Email, ctx.User.Name)
+	ctx.Flash.Success(ctx.Tr("admin.emails.bounces.delete_success", bounce.Email))
+	ctx.Redirect(setting.AppSubURL + "/admin/emails/bounces")
+}
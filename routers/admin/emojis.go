@@ -0,0 +1,119 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"io/ioutil"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplEmojis base.TplName = "admin/emoji/list"
+)
+
+// Emojis show the custom emoji and how often reactions are used
+func Emojis(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.emojis")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminEmojis"] = true
+
+	emojis, err := models.GetCustomEmojis()
+	if err != nil {
+		ctx.ServerError("GetCustomEmojis", err)
+		return
+	}
+	ctx.Data["Emojis"] = emojis
+
+	stats, err := models.GetReactionStats()
+	if err != nil {
+		ctx.ServerError("GetReactionStats", err)
+		return
+	}
+	ctx.Data["ReactionStats"] = stats
+
+	ctx.HTML(200, tplEmojis)
+}
+
+// EmojisPost response for uploading a custom emoji
+func EmojisPost(ctx *context.Context, form auth.AdminCreateCustomEmojiForm) {
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(setting.AppSubURL + "/admin/emojis")
+		return
+	}
+
+	if err := uploadCustomEmoji(ctx, form); err != nil {
+		ctx.ServerError("NewCustomEmoji", err)
+		return
+	}
+	ctx.Redirect(setting.AppSubURL + "/admin/emojis")
+}
+
+// uploadCustomEmoji stores the uploaded emoji, validation problems are
+// reported as flash messages.
+func uploadCustomEmoji(ctx *context.Context, form auth.AdminCreateCustomEmojiForm) error {
+	if form.Image == nil || form.Image.Filename == "" {
+		ctx.Flash.Error(ctx.Tr("admin.emojis.image_required"))
+		return nil
+	}
+	if form.Image.Size > setting.CustomEmojiMaxFileSize {
+		ctx.Flash.Error(ctx.Tr("admin.emojis.image_too_big", base.FileSize(setting.CustomEmojiMaxFileSize)))
+		return nil
+	}
+
+	fr, err := form.Image.Open()
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
+
+	data, err := ioutil.ReadAll(fr)
+	if err != nil {
+		return err
+	}
+
+	e, err := models.NewCustomEmoji(form.Name, data)
+	switch {
+	case models.IsErrCustomEmojiAlreadyExist(err):
+		ctx.Flash.Error(ctx.Tr("admin.emojis.name_been_taken", form.Name))
+	case models.IsErrCustomEmojiNameInvalid(err):
+		ctx.Flash.Error(ctx.Tr("admin.emojis.name_invalid", form.Name))
+	case models.IsErrCustomEmojiInvalidImage(err):
+		ctx.Flash.Error(ctx.Tr("admin.emojis.image_invalid"))
+	case err != nil:
+		return err
+	default:
+		log.Trace("Custom emoji added: %s", e.Name)
+		ctx.Flash.Success(ctx.Tr("admin.emojis.add_success", e.Name))
+	}
+	return nil
+}
+
+// DeleteEmoji deletes a custom emoji
+func DeleteEmoji(ctx *context.Context) {
+	e, err := models.GetCustomEmojiByID(ctx.QueryInt64("id"))
+	if err != nil {
+		if !models.IsErrCustomEmojiNotExist(err) {
+			ctx.ServerError("GetCustomEmojiByID", err)
+			return
+		}
+	} else if err = models.DeleteCustomEmoji(e); err != nil {
+		ctx.ServerError("DeleteCustomEmoji", err)
+		return
+	} else {
+		log.Trace("Custom emoji deleted: %s", e.Name)
+		ctx.Flash.Success(ctx.Tr("admin.emojis.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/admin/emojis",
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"sort"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const tplGitTransfers base.TplName = "admin/git_transfers"
+
+// gitTransferPeriods are the numbers of days the statistics of the git transfers can be shown for
+var gitTransferPeriods = []int{1, 7, 30, 90}
+
+// GitTransfers shows the statistics of the git transfers over HTTP
+func GitTransfers(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.git_transfers")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminGitTransfers"] = true
+	ctx.Data["IsEnabled"] = setting.Git.EnableTransportStats
+
+	days := ctx.QueryInt("days")
+	valid := false
+	for _, period := range gitTransferPeriods {
+		valid = valid || days == period
+	}
+	if !valid {
+		days = 30
+	}
+	ctx.Data["Days"] = days
+	ctx.Data["Periods"] = gitTransferPeriods
+
+	stats, err := models.GetGitTransportStats(time.Now().AddDate(0, 0, 1-days))
+	if err != nil {
+		ctx.ServerError("GetGitTransportStats", err)
+		return
+	}
+
+	// the shapes of the fetches are only relevant for the requests transferring objects
+	transfers := make([]*models.GitTransportStat, 0, len(stats))
+	for _, stat := range stats {
+		if stat.Kind == models.GitTransferClone || stat.Kind == models.GitTransferFetch {
+			transfers = append(transfers, stat)
+		}
+	}
+
+	ctx.Data["ByKind"] = models.SumGitTransportStats(stats, func(s *models.GitTransportStat) interface{} { return s.Kind })
+	ctx.Data["ByProtocol"] = models.SumGitTransportStats(stats, func(s *models.GitTransportStat) interface{} { return s.Protocol })
+	ctx.Data["ByDepth"] = models.SumGitTransportStats(transfers, func(s *models.GitTransportStat) interface{} { return s.Depth })
+	ctx.Data["ByFilter"] = models.SumGitTransportStats(transfers, func(s *models.GitTransportStat) interface{} { return s.Filter })
+	byDay := models.SumGitTransportStats(stats, func(s *models.GitTransportStat) interface{} { return s.Day })
+	sort.Slice(byDay, func(i, j int) bool {
+		return byDay[i].Stat.Day > byDay[j].Stat.Day
+	})
+	ctx.Data["ByDay"] = byDay
+
+	ctx.HTML(200, tplGitTransfers)
+}
@@ -0,0 +1,286 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	texttmpl "text/template"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/templates"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+const (
+	tplMailTemplates    base.TplName = "admin/mail_template/list"
+	tplMailTemplateEdit base.TplName = "admin/mail_template/edit"
+)
+
+// mailTemplateRow is a mail template in the list of the templates which can be customized
+type mailTemplateRow struct {
+	Name string
+	// HasFile is false for the templates of the issue actions which fall back to another one
+	HasFile  bool
+	Template *models.MailTemplate
+	// Outdated is true when the default template changed since the version in use was saved
+	Outdated bool
+}
+
+// defaultMailTemplate returns the template file used when the template is not customized,
+// the templates of issue actions without a file start from the default issue template.
+func defaultMailTemplate(files map[string][]byte, name string) []byte {
+	if content, ok := files[name]; ok {
+		return content
+	}
+	return files["issue/default"]
+}
+
+func sha256Sum(content []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(content))
+}
+
+// MailTemplates shows the mail templates which can be customized
+func MailTemplates(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.mail_templates")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminMailTemplates"] = true
+
+	tmpls, err := models.GetMailTemplates()
+	if err != nil {
+		ctx.ServerError("GetMailTemplates", err)
+		return
+	}
+	overrides, err := models.GetMailTemplateOverrides()
+	if err != nil {
+		ctx.ServerError("GetMailTemplateOverrides", err)
+		return
+	}
+
+	files := templates.MailTemplateFiles()
+	customized := make(map[string]*models.MailTemplate, len(tmpls))
+	for _, tmpl := range tmpls {
+		customized[tmpl.Name] = tmpl
+	}
+	names := mailer.MailTemplateNames(files)
+	rows := make([]*mailTemplateRow, 0, len(names))
+	for _, name := range names {
+		_, hasFile := files[name]
+		row := &mailTemplateRow{Name: name, HasFile: hasFile, Template: customized[name]}
+		if v := overrides[name]; v != nil {
+			row.Outdated = v.BaseSHA256 != sha256Sum(defaultMailTemplate(files, name))
+		}
+		rows = append(rows, row)
+	}
+	ctx.Data["MailTemplates"] = rows
+	ctx.HTML(http.StatusOK, tplMailTemplates)
+}
+
+// getMailTemplateName returns the name of the mail template of the request if it can be
+// customized, the not found page is shown otherwise.
+func getMailTemplateName(ctx *context.Context, name string) (string, map[string][]byte) {
+	files := templates.MailTemplateFiles()
+	for _, n := range mailer.MailTemplateNames(files) {
+		if n == name {
+			return name, files
+		}
+	}
+	ctx.NotFound("getMailTemplateName", models.ErrMailTemplateNotExist{Name: name})
+	return "", nil
+}
+
+func prepareMailTemplateEdit(ctx *context.Context, name string, files map[string][]byte) *models.MailTemplate {
+	ctx.Data["Title"] = ctx.Tr("admin.mail_templates.edit", name)
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminMailTemplates"] = true
+	ctx.Data["MailTemplateName"] = name
+	ctx.Data["Variables"] = mailer.MailTemplateVariables(name)
+	ctx.Data["IsIssueMailTemplate"] = mailer.IsIssueMailTemplate(name)
+	_, hasFile := files[name]
+	ctx.Data["HasFile"] = hasFile
+	defaultContent := defaultMailTemplate(files, name)
+	ctx.Data["DefaultContent"] = string(defaultContent)
+	ctx.Data["DefaultSHA256"] = sha256Sum(defaultContent)
+
+	tmpl, err := models.GetMailTemplateByName(name)
+	if err != nil {
+		if !models.IsErrMailTemplateNotExist(err) {
+			ctx.ServerError("GetMailTemplateByName", err)
+		}
+		return nil
+	}
+	ctx.Data["MailTemplate"] = tmpl
+
+	versions, err := models.GetMailTemplateVersions(tmpl.ID)
+	if err != nil {
+		ctx.ServerError("GetMailTemplateVersions", err)
+		return nil
+	}
+	for _, v := range versions {
+		if err := v.LoadEditor(); err != nil {
+			ctx.ServerError("LoadEditor", err)
+			return nil
+		}
+	}
+	ctx.Data["Versions"] = versions
+	return tmpl
+}
+
+// EditMailTemplate shows the editor of a mail template
+func EditMailTemplate(ctx *context.Context) {
+	name, files := getMailTemplateName(ctx, ctx.Query("name"))
+	if ctx.Written() {
+		return
+	}
+	tmpl := prepareMailTemplateEdit(ctx, name, files)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Data["content"] = ctx.Data["DefaultContent"]
+	ctx.Data["base_sha256"] = ctx.Data["DefaultSHA256"]
+	if tmpl != nil && tmpl.Version > 0 {
+		v, err := models.GetMailTemplateVersion(tmpl, tmpl.Version)
+		if err != nil {
+			ctx.ServerError("GetMailTemplateVersion", err)
+			return
+		}
+		ctx.Data["content"] = v.Content
+		ctx.Data["text_content"] = v.TextContent
+		ctx.Data["base_sha256"] = v.BaseSHA256
+		ctx.Data["Outdated"] = v.BaseSHA256 != ctx.Data["DefaultSHA256"]
+	}
+	ctx.HTML(http.StatusOK, tplMailTemplateEdit)
+}
+
+// parseMailTemplateForm checks the templates of the form, the template of the plain text
+// alternative is nil when it is empty.
+func parseMailTemplateForm(form *auth.AdminMailTemplateForm) (*texttmpl.Template, error) {
+	if err := templates.ParseMailTemplate(form.Name, form.Content); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(form.TextContent) == "" {
+		return nil, nil
+	}
+	return templates.NewMailTextTemplate(form.Name, form.TextContent)
+}
+
+// EditMailTemplatePost saves a new version of a mail template and uses it
+func EditMailTemplatePost(ctx *context.Context, form auth.AdminMailTemplateForm) {
+	name, files := getMailTemplateName(ctx, form.Name)
+	if ctx.Written() {
+		return
+	}
+	prepareMailTemplateEdit(ctx, name, files)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplMailTemplateEdit)
+		return
+	}
+	if _, err := parseMailTemplateForm(&form); err != nil {
+		ctx.Data["Err_Content"] = true
+		ctx.RenderWithErr(ctx.Tr("admin.mail_templates.invalid", err), tplMailTemplateEdit, &form)
+		return
+	}
+
+	tmpl, err := models.SaveMailTemplate(ctx.User, name, form.Content, form.TextContent, form.BaseSHA256)
+	if err != nil {
+		ctx.ServerError("SaveMailTemplate", err)
+		return
+	}
+	if err := templates.ReloadMailer(); err != nil {
+		ctx.ServerError("ReloadMailer", err)
+		return
+	}
+	log.Trace("Mail template %s version %d saved by %s", tmpl.Name, tmpl.Version, ctx.User.Name)
+	ctx.Flash.Success(ctx.Tr("admin.mail_templates.save_success", tmpl.Name, tmpl.Version))
+	ctx.Redirect(setting.AppSubURL + "/admin/mail-templates/edit?name=" + url.QueryEscape(tmpl.Name))
+}
+
+// renderMailTemplateForm renders the templates of the form with sample data, the error is
+// written as JSON when they cannot be rendered.
+func renderMailTemplateForm(ctx *context.Context, form *auth.AdminMailTemplateForm) *mailer.MailTemplatePreview {
+	name, _ := getMailTemplateName(ctx, form.Name)
+	if ctx.Written() {
+		return nil
+	}
+	text, err := parseMailTemplateForm(form)
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, map[string]string{"error": ctx.Tr("admin.mail_templates.invalid", err)})
+		return nil
+	}
+	subjectTemplates, bodyTemplates := templates.Mailer(map[string]string{name: form.Content})
+	preview, err := mailer.PreviewMailTemplate(subjectTemplates, bodyTemplates, text, name, ctx.User)
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, map[string]string{"error": ctx.Tr("admin.mail_templates.invalid", err)})
+		return nil
+	}
+	return preview
+}
+
+// PreviewMailTemplate renders the mail template being edited with sample data
+func PreviewMailTemplate(ctx *context.Context, form auth.AdminMailTemplateForm) {
+	preview := renderMailTemplateForm(ctx, &form)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, preview)
+}
+
+// TestMailTemplate sends the mail template being edited with sample data to the e-mail
+// address of the administrator
+func TestMailTemplate(ctx *context.Context, form auth.AdminMailTemplateForm) {
+	preview := renderMailTemplateForm(ctx, &form)
+	if ctx.Written() {
+		return
+	}
+	if setting.MailService == nil {
+		ctx.JSON(http.StatusUnprocessableEntity, map[string]string{"error": ctx.Tr("admin.mail_templates.mailer_disabled")})
+		return
+	}
+	if err := mailer.SendMailTemplatePreview(ctx.User.Email, preview); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, map[string]string{"error": ctx.Tr("admin.config.test_mail_failed", ctx.User.Email, err)})
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]string{"message": ctx.Tr("admin.config.test_mail_sent", ctx.User.Email)})
+}
+
+// MailTemplateUseVersion changes the version in use of a customized mail template, version 0
+// uses the default template again
+func MailTemplateUseVersion(ctx *context.Context) {
+	tmpl, err := models.GetMailTemplateByName(ctx.Query("name"))
+	if err == nil {
+		err = models.SetMailTemplateVersion(tmpl, ctx.QueryInt("version"))
+	}
+	if err != nil {
+		if models.IsErrMailTemplateNotExist(err) {
+			ctx.NotFound("SetMailTemplateVersion", err)
+		} else {
+			ctx.ServerError("SetMailTemplateVersion", err)
+		}
+		return
+	}
+	if err := templates.ReloadMailer(); err != nil {
+		ctx.ServerError("ReloadMailer", err)
+		return
+	}
+	log.Trace("Mail template %s switched to version %d by %s", tmpl.Name, tmpl.Version, ctx.User.Name)
+	if tmpl.Version == 0 {
+		ctx.Flash.Success(ctx.Tr("admin.mail_templates.reset_success", tmpl.Name))
+	} else {
+		ctx.Flash.Success(ctx.Tr("admin.mail_templates.use_success", tmpl.Name, tmpl.Version))
+	}
+	ctx.Redirect(setting.AppSubURL + "/admin/mail-templates/edit?name=" + url.QueryEscape(tmpl.Name))
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"io/ioutil"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplThemes base.TplName = "admin/theme/list"
+	tplTheme  base.TplName = "admin/theme/view"
+)
+
+// Themes show the uploaded themes
+func Themes(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.themes")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminThemes"] = true
+
+	themes, err := models.GetThemes()
+	if err != nil {
+		ctx.ServerError("GetThemes", err)
+		return
+	}
+	ctx.Data["Themes"] = themes
+	ctx.Data["BuiltinThemes"] = setting.UI.Themes
+	ctx.Data["MaxFileSize"] = base.FileSize(setting.UI.ThemeMaxFileSize)
+	ctx.HTML(200, tplThemes)
+}
+
+// ThemesPost response for uploading a theme bundle
+func ThemesPost(ctx *context.Context, form auth.AdminUploadThemeForm) {
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(setting.AppSubURL + "/admin/themes")
+		return
+	}
+	if form.Bundle == nil || form.Bundle.Filename == "" {
+		ctx.Flash.Error(ctx.Tr("admin.themes.bundle_required"))
+		ctx.Redirect(setting.AppSubURL + "/admin/themes")
+		return
+	}
+	if form.Bundle.Size > setting.UI.ThemeMaxFileSize {
+		ctx.Flash.Error(ctx.Tr("admin.themes.bundle_too_big", base.FileSize(setting.UI.ThemeMaxFileSize)))
+		ctx.Redirect(setting.AppSubURL + "/admin/themes")
+		return
+	}
+
+	fr, err := form.Bundle.Open()
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer fr.Close()
+	data, err := ioutil.ReadAll(fr)
+	if err != nil {
+		ctx.ServerError("ReadAll", err)
+		return
+	}
+
+	theme, err := models.UploadThemeBundle(ctx.User, data)
+	if err != nil {
+		if models.IsErrThemeBundleInvalid(err) {
+			ctx.Flash.Error(ctx.Tr("admin.themes.bundle_invalid", err.(models.ErrThemeBundleInvalid).Reason))
+			ctx.Redirect(setting.AppSubURL + "/admin/themes")
+			return
+		}
+		ctx.ServerError("UploadThemeBundle", err)
+		return
+	}
+	log.Trace("Theme %s version %d uploaded by %s", theme.Name, theme.Version, ctx.User.Name)
+	ctx.Flash.Success(ctx.Tr("admin.themes.upload_success", theme.Name, theme.Version))
+	ctx.Redirect(setting.AppSubURL + "/admin/themes/" + theme.Name)
+}
+
+func getTheme(ctx *context.Context) *models.Theme {
+	theme, err := models.GetThemeByName(ctx.Params(":name"))
+	if err != nil {
+		if models.IsErrThemeNotExist(err) {
+			ctx.NotFound("GetThemeByName", err)
+		} else {
+			ctx.ServerError("GetThemeByName", err)
+		}
+		return nil
+	}
+	return theme
+}
+
+// Theme shows the versions of an uploaded theme
+func Theme(ctx *context.Context) {
+	theme := getTheme(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Title"] = theme.DisplayName
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminThemes"] = true
+	ctx.Data["Theme"] = theme
+
+	versions, err := models.GetThemeVersions(theme.ID)
+	if err != nil {
+		ctx.ServerError("GetThemeVersions", err)
+		return
+	}
+	for _, v := range versions {
+		if err := v.LoadUploader(); err != nil {
+			ctx.ServerError("LoadUploader", err)
+			return
+		}
+	}
+	ctx.Data["Versions"] = versions
+	ctx.HTML(200, tplTheme)
+}
+
+// ThemeUseVersion changes the version in use of an uploaded theme
+func ThemeUseVersion(ctx *context.Context) {
+	theme := getTheme(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.SetThemeVersion(theme, ctx.QueryInt("version")); err != nil {
+		if models.IsErrThemeNotExist(err) {
+			ctx.NotFound("SetThemeVersion", err)
+		} else {
+			ctx.ServerError("SetThemeVersion", err)
+		}
+		return
+	}
+	log.Trace("Theme %s switched to version %d by %s", theme.Name, theme.Version, ctx.User.Name)
+	ctx.Flash.Success(ctx.Tr("admin.themes.use_success", theme.Name, theme.Version))
+	ctx.Redirect(setting.AppSubURL + "/admin/themes/" + theme.Name)
+}
+
+// DeleteTheme deletes an uploaded theme
+func DeleteTheme(ctx *context.Context) {
+	theme, err := models.GetThemeByName(ctx.Query("id"))
+	if err != nil {
+		if !models.IsErrThemeNotExist(err) {
+			ctx.ServerError("GetThemeByName", err)
+			return
+		}
+	} else if err = models.DeleteTheme(theme); err != nil {
+		ctx.ServerError("DeleteTheme", err)
+		return
+	} else {
+		log.Trace("Theme deleted: %s", theme.Name)
+		ctx.Flash.Success(ctx.Tr("admin.themes.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/admin/themes",
+	})
+}
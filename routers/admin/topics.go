@@ -0,0 +1,159 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplTopics    base.TplName = "admin/topic/list"
+	tplTopicEdit base.TplName = "admin/topic/edit"
+)
+
+// Topics show the topics of all repositories
+func Topics(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.topics")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminTopics"] = true
+
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	opts := &models.FindTopicOptions{
+		ListOptions: models.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.Admin.RepoPagingNum,
+		},
+		Keyword:      strings.ToLower(ctx.QueryTrim("q")),
+		FeaturedOnly: ctx.QueryBool("featured"),
+	}
+	topics, err := models.FindTopics(opts)
+	if err != nil {
+		ctx.ServerError("FindTopics", err)
+		return
+	}
+	count, err := models.CountTopics(opts)
+	if err != nil {
+		ctx.ServerError("CountTopics", err)
+		return
+	}
+	ctx.Data["Topics"] = topics
+	ctx.Data["Total"] = count
+	ctx.Data["Keyword"] = opts.Keyword
+	ctx.Data["FeaturedOnly"] = opts.FeaturedOnly
+
+	pager := context.NewPagination(int(count), opts.PageSize, page, 5)
+	pager.SetDefaultParams(ctx)
+	pager.AddParam(ctx, "featured", "FeaturedOnly")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(200, tplTopics)
+}
+
+func prepareTopicInfo(ctx *context.Context) *models.Topic {
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminTopics"] = true
+
+	topic, err := models.GetTopicByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrTopicNotExist(err) {
+			ctx.NotFound("GetTopicByID", err)
+		} else {
+			ctx.ServerError("GetTopicByID", err)
+		}
+		return nil
+	}
+	ctx.Data["Title"] = topic.Name
+	ctx.Data["Topic"] = topic
+	return topic
+}
+
+// EditTopic show the page to curate and merge a topic
+func EditTopic(ctx *context.Context) {
+	topic := prepareTopicInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["description"] = topic.Description
+	ctx.Data["logo"] = topic.Logo
+	ctx.Data["is_featured"] = topic.IsFeatured
+
+	ctx.HTML(200, tplTopicEdit)
+}
+
+// EditTopicPost response for curating a topic
+func EditTopicPost(ctx *context.Context, form auth.TopicForm) {
+	topic := prepareTopicInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplTopicEdit)
+		return
+	}
+
+	topic.Description = strings.TrimSpace(form.Description)
+	topic.Logo = strings.TrimSpace(form.Logo)
+	topic.IsFeatured = form.IsFeatured
+	if err := models.UpdateTopic(topic); err != nil {
+		ctx.ServerError("UpdateTopic", err)
+		return
+	}
+	log.Trace("Topic updated by admin (%s): %s", ctx.User.Name, topic.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.topics.update_success"))
+	ctx.Redirect(fmt.Sprintf("%s/admin/topics/%d", setting.AppSubURL, topic.ID))
+}
+
+// MergeTopicPost merges a topic into another one and deletes it
+func MergeTopicPost(ctx *context.Context, form auth.MergeTopicForm) {
+	topic := prepareTopicInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	link := fmt.Sprintf("%s/admin/topics/%d", setting.AppSubURL, topic.ID)
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(link)
+		return
+	}
+
+	target, err := models.GetTopicByName(strings.ToLower(strings.TrimSpace(form.Target)))
+	if err != nil {
+		if models.IsErrTopicNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("admin.topics.merge_target_not_exist", form.Target))
+			ctx.Redirect(link)
+		} else {
+			ctx.ServerError("GetTopicByName", err)
+		}
+		return
+	}
+
+	if err := models.MergeTopics(topic, target); err != nil {
+		if models.IsErrTopicMergeSelf(err) {
+			ctx.Flash.Error(ctx.Tr("admin.topics.merge_self"))
+			ctx.Redirect(link)
+		} else {
+			ctx.ServerError("MergeTopics", err)
+		}
+		return
+	}
+	log.Trace("Topic %s merged into %s by admin (%s)", topic.Name, target.Name, ctx.User.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.topics.merge_success", topic.Name, target.Name))
+	ctx.Redirect(fmt.Sprintf("%s/admin/topics/%d", setting.AppSubURL, target.ID))
+}
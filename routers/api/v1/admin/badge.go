@@ -0,0 +1,378 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/user"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// getBadgeByParams returns the badge with the id of the path, it writes
+// the error response if it cannot be found
+func getBadgeByParams(ctx *context.APIContext) *models.Badge {
+	badge, err := models.GetBadgeByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrBadgeNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetBadgeByID", err)
+		}
+		return nil
+	}
+	return badge
+}
+
+// handleBadgeError writes the error response of creating or updating a badge
+func handleBadgeError(ctx *context.APIContext, name string, err error) {
+	if models.IsErrBadgeAlreadyExist(err) || models.IsErrBadgeInvalid(err) {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+	} else {
+		ctx.Error(http.StatusInternalServerError, name, err)
+	}
+}
+
+// ListBadges api for listing all badges
+func ListBadges(ctx *context.APIContext) {
+	// swagger:operation GET /admin/badges admin adminListBadges
+	// ---
+	// summary: List all badges
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/BadgeList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	badges, err := models.GetBadges()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetBadges", err)
+		return
+	}
+	apiBadges := make([]*api.Badge, len(badges))
+	for i := range badges {
+		apiBadges[i] = convert.ToBadge(badges[i])
+	}
+	ctx.JSON(http.StatusOK, &apiBadges)
+}
+
+// CreateBadge api for creating a badge
+func CreateBadge(ctx *context.APIContext, form api.CreateBadgeOption) {
+	// swagger:operation POST /admin/badges admin adminCreateBadge
+	// ---
+	// summary: Create a badge
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateBadgeOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Badge"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	badgeType, ok := models.ParseBadgeType(form.Type)
+	if !ok {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("unknown badge type: %s", form.Type))
+		return
+	}
+	badge := &models.Badge{
+		Slug:        form.Slug,
+		Name:        form.Name,
+		Description: form.Description,
+		Icon:        form.Icon,
+		Type:        badgeType,
+		Threshold:   form.Threshold,
+	}
+	if err := models.CreateBadge(badge); err != nil {
+		handleBadgeError(ctx, "CreateBadge", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToBadge(badge))
+}
+
+// GetBadge api for getting a badge
+func GetBadge(ctx *context.APIContext) {
+	// swagger:operation GET /admin/badges/{id} admin adminGetBadge
+	// ---
+	// summary: Get a badge
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the badge
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Badge"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	badge := getBadgeByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToBadge(badge))
+}
+
+// EditBadge api for editing a badge
+func EditBadge(ctx *context.APIContext, form api.EditBadgeOption) {
+	// swagger:operation PATCH /admin/badges/{id} admin adminEditBadge
+	// ---
+	// summary: Edit a badge
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the badge to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditBadgeOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Badge"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	badge := getBadgeByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Name != nil {
+		badge.Name = *form.Name
+	}
+	if form.Description != nil {
+		badge.Description = *form.Description
+	}
+	if form.Icon != nil {
+		badge.Icon = *form.Icon
+	}
+	if form.Type != nil {
+		badgeType, ok := models.ParseBadgeType(*form.Type)
+		if !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("unknown badge type: %s", *form.Type))
+			return
+		}
+		badge.Type = badgeType
+	}
+	if form.Threshold != nil {
+		badge.Threshold = *form.Threshold
+	}
+
+	if err := models.UpdateBadge(badge); err != nil {
+		handleBadgeError(ctx, "UpdateBadge", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToBadge(badge))
+}
+
+// DeleteBadge api for deleting a badge
+func DeleteBadge(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/badges/{id} admin adminDeleteBadge
+	// ---
+	// summary: Delete a badge and revoke it from all users
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the badge to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	badge := getBadgeByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.DeleteBadge(badge); err != nil {
+		if models.IsErrBadgeBuiltin(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteBadge", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListBadgeUsers api for listing the users a badge was granted to
+func ListBadgeUsers(ctx *context.APIContext) {
+	// swagger:operation GET /admin/badges/{id}/users admin adminListBadgeUsers
+	// ---
+	// summary: List the users a badge was granted to
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the badge
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/UserList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	badge := getBadgeByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	users, count, err := models.GetBadgeUsers(badge.ID, utils.GetListOptions(ctx))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetBadgeUsers", err)
+		return
+	}
+	results := make([]*api.User, len(users))
+	for i := range users {
+		results[i] = convert.ToUser(users[i], ctx.IsSigned, true)
+	}
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count")
+	ctx.JSON(http.StatusOK, &results)
+}
+
+// GrantBadge api for granting a badge to a user
+func GrantBadge(ctx *context.APIContext) {
+	// swagger:operation PUT /admin/badges/{id}/users/{username} admin adminGrantBadge
+	// ---
+	// summary: Grant a badge to a user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the badge
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: username
+	//   in: path
+	//   description: username of the user
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	badge := getBadgeByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	u := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if u.IsOrganization() {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("badges can only be granted to users"))
+		return
+	}
+	if err := models.AddUserBadge(u, badge); err != nil {
+		ctx.Error(http.StatusInternalServerError, "AddUserBadge", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// RevokeBadge api for revoking a badge from a user
+func RevokeBadge(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/badges/{id}/users/{username} admin adminRevokeBadge
+	// ---
+	// summary: Revoke a badge from a user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the badge
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: username
+	//   in: path
+	//   description: username of the user
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	badge := getBadgeByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	u := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.RemoveUserBadge(u, badge); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RemoveUserBadge", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,42 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ReloadConfig reloads the hot-reloadable settings from the configuration file
+func ReloadConfig(ctx *context.APIContext) {
+	// swagger:operation POST /admin/config/reload admin adminReloadConfig
+	// ---
+	// summary: Reload the settings which can be changed without restarting from the configuration file
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ConfigReloadResult"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "500":
+	//     "$ref": "#/responses/error"
+
+	result, err := setting.ReloadSettings()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ReloadSettings", err)
+		return
+	}
+	log.Info("Settings reloaded by %s", ctx.User.Name)
+
+	ctx.JSON(http.StatusOK, &api.ConfigReloadResult{
+		Reloaded:        append([]string{}, result.Reloaded...),
+		RestartRequired: append([]string{}, result.RestartRequired...),
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// CreateCustomEmoji api for uploading a custom emoji
+func CreateCustomEmoji(ctx *context.APIContext) {
+	// swagger:operation POST /admin/emojis admin adminCreateCustomEmoji
+	// ---
+	// summary: Upload a custom emoji
+	// consumes:
+	// - multipart/form-data
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: name
+	//   in: formData
+	//   description: name of the emoji, used in its short code
+	//   type: string
+	//   required: true
+	// - name: image
+	//   in: formData
+	//   description: png, gif, jpeg or webp image of the emoji
+	//   type: file
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CustomEmoji"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	file, header, err := ctx.GetFile("image")
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetFile", err)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > setting.CustomEmojiMaxFileSize {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("image is larger than %d bytes", setting.CustomEmojiMaxFileSize))
+		return
+	}
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ReadAll", err)
+		return
+	}
+
+	e, err := models.NewCustomEmoji(ctx.Query("name"), data)
+	if err != nil {
+		if models.IsErrCustomEmojiAlreadyExist(err) ||
+			models.IsErrCustomEmojiNameInvalid(err) ||
+			models.IsErrCustomEmojiInvalidImage(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "NewCustomEmoji", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToCustomEmoji(e))
+}
+
+// DeleteCustomEmoji api for deleting a custom emoji
+func DeleteCustomEmoji(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/emojis/{name} admin adminDeleteCustomEmoji
+	// ---
+	// summary: Delete a custom emoji
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: name
+	//   in: path
+	//   description: name of the emoji to delete
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	e, err := models.GetCustomEmojiByName(ctx.Params(":name"))
+	if err != nil {
+		if models.IsErrCustomEmojiNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCustomEmojiByName", err)
+		}
+		return
+	}
+	if err := models.DeleteCustomEmoji(e); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteCustomEmoji", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
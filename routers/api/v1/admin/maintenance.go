@@ -0,0 +1,78 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/maintenance"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// GetMaintenance returns the maintenance mode of the instance
+func GetMaintenance(ctx *context.APIContext) {
+	// swagger:operation GET /admin/maintenance admin adminGetMaintenance
+	// ---
+	// summary: Get the maintenance mode of the instance and the work which is still draining
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MaintenanceStatus"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	ctx.JSON(http.StatusOK, toMaintenanceStatus(maintenance.GetStatus()))
+}
+
+// EditMaintenance enables or disables the maintenance mode of the instance
+func EditMaintenance(ctx *context.APIContext, form api.EditMaintenanceOption) {
+	// swagger:operation PATCH /admin/maintenance admin adminEditMaintenance
+	// ---
+	// summary: Enable or disable the maintenance mode of the instance
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditMaintenanceOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MaintenanceStatus"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if form.Enabled {
+		maintenance.Enable(form.Message)
+		log.Info("Maintenance mode enabled by %s", ctx.User.Name)
+	} else {
+		maintenance.Disable()
+		log.Info("Maintenance mode disabled by %s", ctx.User.Name)
+	}
+	ctx.JSON(http.StatusOK, toMaintenanceStatus(maintenance.GetStatus()))
+}
+
+func toMaintenanceStatus(status *maintenance.Status) *api.MaintenanceStatus {
+	result := &api.MaintenanceStatus{
+		Enabled:          status.Enabled,
+		Message:          status.Message,
+		InFlightRequests: status.InFlightRequests,
+		QueuedItems:      status.QueuedItems,
+		InProgressItems:  status.InProgressItems,
+		Drained:          status.IsDrained(),
+	}
+	if !status.Since.IsZero() {
+		since := status.Since
+		result.Since = &since
+	}
+	return result
+}
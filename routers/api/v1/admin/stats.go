@@ -0,0 +1,193 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/cron"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+const (
+	healthPass = "pass"
+	healthFail = "fail"
+)
+
+// GetStats returns the statistics of the instance
+func GetStats(ctx *context.APIContext) {
+	// swagger:operation GET /admin/stats admin adminGetStats
+	// ---
+	// summary: Get the statistics of the instance
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/InstanceStats"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	storage, err := models.GetStorageStatistic()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetStorageStatistic", err)
+		return
+	}
+
+	stats := models.GetStatistic()
+	ctx.JSON(http.StatusOK, &api.InstanceStats{
+		Counts: &api.InstanceCounts{
+			Users:        stats.Counter.User,
+			Orgs:         stats.Counter.Org,
+			PublicKeys:   stats.Counter.PublicKey,
+			Repos:        stats.Counter.Repo,
+			Watches:      stats.Counter.Watch,
+			Stars:        stats.Counter.Star,
+			Actions:      stats.Counter.Action,
+			Accesses:     stats.Counter.Access,
+			Issues:       stats.Counter.Issue,
+			Comments:     stats.Counter.Comment,
+			Follows:      stats.Counter.Follow,
+			Mirrors:      stats.Counter.Mirror,
+			Releases:     stats.Counter.Release,
+			LoginSources: stats.Counter.LoginSource,
+			Webhooks:     stats.Counter.Webhook,
+			Milestones:   stats.Counter.Milestone,
+			Labels:       stats.Counter.Label,
+			HookTasks:    stats.Counter.HookTask,
+			Teams:        stats.Counter.Team,
+			Attachments:  stats.Counter.Attachment,
+		},
+		Storage: &api.StorageStats{
+			Repositories: storage.Repositories,
+			LFSObjects:   storage.LFSObjects,
+			Attachments:  storage.Attachments,
+		},
+		Queues:   queueStats(),
+		Indexers: indexerStats(),
+		Cron:     cronTasks(),
+	})
+}
+
+func queueStats() []*api.QueueStats {
+	mqs := queue.GetManager().ManagedQueues()
+	queues := make([]*api.QueueStats, len(mqs))
+	for i, mq := range mqs {
+		queues[i] = &api.QueueStats{
+			ID:         mq.QID,
+			Name:       mq.Name,
+			Type:       string(mq.Type),
+			Workers:    mq.NumberOfWorkers(),
+			MaxWorkers: mq.MaxNumberOfWorkers(),
+			Queued:     mq.NumberInQueue(),
+		}
+	}
+	return queues
+}
+
+func indexerStats() []*api.IndexerStats {
+	codeIndexer := &api.IndexerStats{
+		Name:    "code",
+		Type:    setting.Indexer.RepoType,
+		Enabled: setting.Indexer.RepoIndexerEnabled,
+	}
+	if codeIndexer.Enabled {
+		codeIndexer.Queued = int64(code_indexer.QueueLength())
+	}
+
+	issueIndexer := &api.IndexerStats{
+		Name:    "issues",
+		Type:    setting.Indexer.IssueType,
+		Enabled: setting.Indexer.IssueType != "db",
+	}
+	if issueIndexer.Enabled {
+		issueIndexer.Queued = issue_indexer.QueueLength()
+	}
+	return []*api.IndexerStats{codeIndexer, issueIndexer}
+}
+
+func cronTasks() []*api.CronTask {
+	rows := cron.ListTasks()
+	tasks := make([]*api.CronTask, len(rows))
+	for i, row := range rows {
+		task := &api.CronTask{
+			Name:      row.Name,
+			Schedule:  row.Spec,
+			ExecTimes: row.ExecTimes,
+			IsRunning: row.IsRunning,
+			Status:    row.Status,
+			LastError: row.LastError,
+		}
+		if !row.Next.IsZero() {
+			next := row.Next
+			task.Next = &next
+		}
+		if !row.Prev.IsZero() {
+			prev := row.Prev
+			task.Prev = &prev
+		}
+		tasks[i] = task
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Name < tasks[j].Name
+	})
+	return tasks
+}
+
+// GetHealth checks the services the instance depends on
+func GetHealth(ctx *context.APIContext) {
+	// swagger:operation GET /admin/health admin adminGetHealth
+	// ---
+	// summary: Check the database and cache connectivity of the instance
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/InstanceHealth"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "503":
+	//     "$ref": "#/responses/InstanceHealth"
+
+	health := &api.InstanceHealth{
+		Status: healthPass,
+		Checks: []*api.HealthCheck{
+			healthCheck("database", models.Ping),
+			healthCheck("cache", cache.Ping),
+		},
+	}
+
+	status := http.StatusOK
+	for _, check := range health.Checks {
+		if check.Status != healthPass {
+			health.Status = healthFail
+			status = http.StatusServiceUnavailable
+		}
+	}
+	ctx.JSON(status, health)
+}
+
+func healthCheck(name string, check func() error) *api.HealthCheck {
+	start := time.Now()
+	err := check()
+	result := &api.HealthCheck{
+		Name:     name,
+		Status:   healthPass,
+		Duration: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = healthFail
+		result.Error = err.Error()
+	}
+	return result
+}
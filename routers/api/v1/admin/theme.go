@@ -0,0 +1,106 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// UploadTheme api for uploading a theme bundle
+func UploadTheme(ctx *context.APIContext) {
+	// swagger:operation POST /admin/themes admin adminUploadTheme
+	// ---
+	// summary: Upload a theme bundle, a new version is added if the theme exists
+	// consumes:
+	// - multipart/form-data
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: bundle
+	//   in: formData
+	//   description: zip archive with theme.ini, theme.css and optionally the
+	//                templates/header.tmpl and templates/footer.tmpl overrides
+	//   type: file
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Theme"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	file, header, err := ctx.GetFile("bundle")
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetFile", err)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > setting.UI.ThemeMaxFileSize {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("bundle is larger than %d bytes", setting.UI.ThemeMaxFileSize))
+		return
+	}
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ReadAll", err)
+		return
+	}
+
+	theme, err := models.UploadThemeBundle(ctx.User, data)
+	if err != nil {
+		if models.IsErrThemeBundleInvalid(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UploadThemeBundle", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToTheme(theme))
+}
+
+// DeleteTheme api for deleting an uploaded theme
+func DeleteTheme(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/themes/{name} admin adminDeleteTheme
+	// ---
+	// summary: Delete an uploaded theme with all its versions
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: name
+	//   in: path
+	//   description: name of the theme to delete
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	theme, err := models.GetThemeByName(ctx.Params(":name"))
+	if err != nil {
+		if models.IsErrThemeNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetThemeByName", err)
+		}
+		return
+	}
+	if err := models.DeleteTheme(theme); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteTheme", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -59,7 +59,10 @@
 package v1
 
 import (
+	"bytes"
+	"io/ioutil"
 	"net/http"
+	"reflect"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -68,12 +71,14 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/routers/api/v1/admin"
 	"code.gitea.io/gitea/routers/api/v1/misc"
 	"code.gitea.io/gitea/routers/api/v1/notify"
 	"code.gitea.io/gitea/routers/api/v1/org"
 	"code.gitea.io/gitea/routers/api/v1/repo"
 	"code.gitea.io/gitea/routers/api/v1/settings"
+	"code.gitea.io/gitea/routers/api/v1/snippet"
 	_ "code.gitea.io/gitea/routers/api/v1/swagger" // for swagger generation
 	"code.gitea.io/gitea/routers/api/v1/user"
 
@@ -488,6 +493,13 @@ func mustEnableUserHeatmap(ctx *context.APIContext) {
 	}
 }
 
+func mustEnableSnippets(ctx *context.APIContext) {
+	if !setting.Snippet.Enabled {
+		ctx.NotFound()
+		return
+	}
+}
+
 func mustNotBeArchived(ctx *context.APIContext) {
 	if ctx.Repo.Repository.IsArchived {
 		ctx.NotFound()
@@ -495,10 +507,38 @@ func mustNotBeArchived(ctx *context.APIContext) {
 	}
 }
 
+// strictBind works like binding.Bind, but rejects JSON request bodies with
+// fields unknown to obj or values of the wrong type.
+func strictBind(obj interface{}, ifacePtr ...interface{}) macaron.Handler {
+	typ := reflect.TypeOf(obj)
+	bindHandler := binding.Bind(obj, ifacePtr...)
+	return func(ctx *macaron.Context) {
+		if ctx.Req.Request.Body != nil && strings.Contains(ctx.Req.Header.Get("Content-Type"), "json") {
+			data, err := ioutil.ReadAll(ctx.Req.Request.Body)
+			if err != nil {
+				ctx.Error(http.StatusBadRequest, err.Error())
+				return
+			}
+			ctx.Req.Request.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+			if errs := validation.CheckStrictJSON(data, typ); len(errs) > 0 {
+				ctx.JSON(http.StatusUnprocessableEntity, errs)
+				return
+			}
+		}
+		if _, err := ctx.Invoke(bindHandler); err != nil {
+			ctx.Error(http.StatusInternalServerError, err.Error())
+		}
+	}
+}
+
 // RegisterRoutes registers all v1 APIs routes to web application.
 // FIXME: custom form error response
 func RegisterRoutes(m *macaron.Macaron) {
 	bind := binding.Bind
+	if setting.API.StrictRequestValidation {
+		bind = strictBind
+	}
 
 	if setting.API.EnableSwagger {
 		m.Get("/swagger", misc.Swagger) // Render V1 by default
@@ -513,6 +553,9 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Get("/signing-key.gpg", misc.SigningKey)
 		m.Post("/markdown", bind(api.MarkdownOption{}), misc.Markdown)
 		m.Post("/markdown/raw", misc.MarkdownRaw)
+		m.Post("/mail/bounces", bind(api.MailBounceOption{}), misc.MailBounce)
+		m.Get("/emojis", misc.ListCustomEmojis)
+		m.Get("/themes", misc.ListThemes)
 		m.Group("/settings", func() {
 			m.Get("/ui", settings.GetGeneralUISettings)
 			m.Get("/repository", settings.GetGeneralRepoSettings)
@@ -527,6 +570,9 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Combo("/threads/:id").
 				Get(notify.GetThread).
 				Patch(notify.ReadThread)
+			m.Combo("/threads/:id/saved").
+				Put(notify.SaveThread).
+				Delete(notify.UnsaveThread)
 		}, reqToken())
 
 		// Users
@@ -538,6 +584,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Get("/heatmap", mustEnableUserHeatmap, user.GetUserHeatmapData)
 
 				m.Get("/repos", user.ListUserRepos)
+				m.Get("/pinned_repos", user.ListUserPinnedRepos)
+				m.Get("/badges", user.ListUserBadges)
 				m.Group("/tokens", func() {
 					m.Combo("").Get(user.ListAccessTokens).
 						Post(bind(api.CreateAccessTokenOption{}), user.CreateAccessToken)
@@ -558,8 +606,11 @@ func RegisterRoutes(m *macaron.Macaron) {
 				})
 
 				m.Get("/starred", user.GetStarredRepos)
+				m.Get("/star_lists", user.ListUserStarLists)
+				m.Get("/star_lists/:id/repos", user.ListUserStarListRepos)
 
 				m.Get("/subscriptions", user.GetWatchedRepos)
+				m.Get("/snippets", mustEnableSnippets, snippet.ListUserSnippets)
 			})
 		}, reqToken())
 
@@ -600,6 +651,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 			m.Combo("/repos").Get(user.ListMyRepos).
 				Post(bind(api.CreateRepoOption{}), repo.Create)
+			m.Combo("/pinned_repos").Get(user.ListMyPinnedRepos).
+				Put(bind(api.EditPinnedReposOption{}), user.UpdateMyPinnedRepos)
 
 			m.Group("/starred", func() {
 				m.Get("", user.GetMyStarredRepos)
@@ -609,6 +662,19 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Delete("", user.Unstar)
 				}, repoAssignment())
 			})
+			m.Group("/star_lists", func() {
+				m.Combo("").Get(user.ListMyStarLists).
+					Post(bind(api.CreateStarListOption{}), user.CreateStarList)
+				m.Group("/:id", func() {
+					m.Combo("").Get(user.GetMyStarList).
+						Patch(bind(api.EditStarListOption{}), user.EditStarList).
+						Delete(user.DeleteStarList)
+					m.Get("/repos", user.ListMyStarListRepos)
+					m.Combo("/repos/:username/:reponame", repoAssignment()).
+						Put(user.AddStarListRepo).
+						Delete(user.RemoveStarListRepo)
+				})
+			})
 			m.Get("/times", repo.ListMyTrackedTimes)
 
 			m.Get("/stopwatches", repo.GetStopwatches)
@@ -616,6 +682,17 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/subscriptions", user.GetMyWatchedRepos)
 
 			m.Get("/teams", org.ListUserTeams)
+
+			m.Group("/quick_actions", func() {
+				m.Get("", user.ListQuickActions)
+				m.Post("/recent", bind(api.RecordQuickActionOption{}), user.RecordQuickAction)
+			})
+
+			m.Group("/dashboard", func() {
+				m.Combo("").Get(user.GetDashboardLayout).
+					Put(bind(api.EditDashboardLayoutOption{}), user.EditDashboardLayout)
+				m.Get("/widgets/:widget", user.GetDashboardWidget)
+			})
 		}, reqToken())
 
 		// Repositories
@@ -625,6 +702,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 		m.Group("/repos", func() {
 			m.Get("/search", repo.Search)
+			m.Get("/trending", repo.ListTrending)
 
 			m.Get("/issues/search", repo.SearchIssues)
 
@@ -638,6 +716,10 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Combo("/notifications").
 					Get(reqToken(), notify.ListRepoNotifications).
 					Put(reqToken(), notify.ReadRepoNotifications)
+				m.Group("/autocomplete", func() {
+					m.Get("/mentions", repo.AutocompleteMentions)
+					m.Get("/issues", repo.AutocompleteIssues)
+				}, reqToken(), mustEnableIssuesOrPulls)
 				m.Group("/hooks", func() {
 					m.Combo("").Get(repo.ListHooks).
 						Post(bind(api.CreateHookOption{}), repo.CreateHook)
@@ -646,6 +728,12 @@ func RegisterRoutes(m *macaron.Macaron) {
 							Patch(bind(api.EditHookOption{}), repo.EditHook).
 							Delete(repo.DeleteHook)
 						m.Post("/tests", context.RepoRef(), repo.TestHook)
+						m.Post("/tests/:event", context.RepoRef(), repo.TestHookEvent)
+						m.Get("/deliveries", repo.ListHookDeliveries)
+						m.Group("/deliveries/:delivery_id", func() {
+							m.Get("", repo.GetHookDelivery)
+							m.Post("/redeliver", repo.RedeliverHookDelivery)
+						})
 					})
 					m.Group("/git", func() {
 						m.Combo("").Get(repo.ListGitHooks)
@@ -656,6 +744,25 @@ func RegisterRoutes(m *macaron.Macaron) {
 						})
 					}, reqGitHook(), context.ReferencesGitRepo(true))
 				}, reqToken(), reqAdmin())
+				m.Group("/deploy_tokens", func() {
+					m.Combo("").Get(repo.ListDeployTokens).
+						Post(bind(api.CreateDeployTokenOption{}), repo.CreateDeployToken)
+					m.Delete("/:id", repo.DeleteDeployToken)
+				}, reqToken(), reqAdmin(), repo.DeployTokensEnabled)
+				m.Group("/autolinks", func() {
+					m.Combo("").Get(repo.ListAutoLinkRules).
+						Post(reqToken(), reqAdmin(), bind(api.CreateAutoLinkRuleOption{}), repo.CreateAutoLinkRule)
+					m.Combo("/:id").Get(repo.GetAutoLinkRule).
+						Patch(reqToken(), reqAdmin(), bind(api.EditAutoLinkRuleOption{}), repo.EditAutoLinkRule).
+						Delete(reqToken(), reqAdmin(), repo.DeleteAutoLinkRule)
+				})
+				m.Group("/tabs", func() {
+					m.Combo("").Get(repo.ListCustomTabs).
+						Post(reqToken(), reqAdmin(), bind(api.CreateRepoCustomTabOption{}), repo.CreateCustomTab)
+					m.Combo("/:id").Get(repo.GetCustomTab).
+						Patch(reqToken(), reqAdmin(), bind(api.EditRepoCustomTabOption{}), repo.EditCustomTab).
+						Delete(reqToken(), reqAdmin(), repo.DeleteCustomTab)
+				})
 				m.Group("/collaborators", func() {
 					m.Get("", reqAnyRepoReader(), repo.ListCollaborators)
 					m.Combo("/:collaborator").Get(reqAnyRepoReader(), repo.IsCollaborator).
@@ -666,12 +773,15 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Get("/archive/*", reqRepoReader(models.UnitTypeCode), repo.GetArchive)
 				m.Combo("/forks").Get(repo.ListForks).
 					Post(reqToken(), reqRepoReader(models.UnitTypeCode), bind(api.CreateForkOption{}), repo.CreateFork)
+				m.Get("/network", reqRepoReader(models.UnitTypeCode), repo.ListForkNetwork)
+				m.Get("/dev_environment", reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(true), repo.GetDevEnvironment)
 				m.Group("/branches", func() {
 					m.Get("", repo.ListBranches)
 					m.Get("/*", context.RepoRefByType(context.RepoRefBranch), repo.GetBranch)
 					m.Delete("/*", reqRepoWriter(models.UnitTypeCode), context.RepoRefByType(context.RepoRefBranch), repo.DeleteBranch)
 					m.Post("", reqRepoWriter(models.UnitTypeCode), bind(api.CreateBranchRepoOption{}), repo.CreateBranch)
 				}, reqRepoReader(models.UnitTypeCode))
+				m.Get("/branch_divergences", reqRepoReader(models.UnitTypeCode), repo.ListBranchDivergences)
 				m.Group("/branch_protections", func() {
 					m.Get("", repo.ListBranchProtections)
 					m.Post("", bind(api.CreateBranchProtectionOption{}), repo.CreateBranchProtection)
@@ -734,6 +844,9 @@ func RegisterRoutes(m *macaron.Macaron) {
 							m.Delete("/:id", repo.DeleteTime)
 						}, reqToken())
 						m.Combo("/deadline").Post(reqToken(), bind(api.EditDeadlineOption{}), repo.UpdateIssueDeadline)
+						m.Get("/graph", repo.GetIssueGraph)
+						m.Combo("/answer", reqToken(), mustNotBeArchived).Post(bind(api.SetIssueAnswerOption{}), repo.SetIssueAnswer).
+							Delete(repo.DeleteIssueAnswer)
 						m.Group("/stopwatch", func() {
 							m.Post("/start", reqToken(), repo.StartIssueStopwatch)
 							m.Post("/stop", reqToken(), repo.StopIssueStopwatch)
@@ -758,6 +871,49 @@ func RegisterRoutes(m *macaron.Macaron) {
 						Patch(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.EditLabelOption{}), repo.EditLabel).
 						Delete(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), repo.DeleteLabel)
 				})
+				m.Group("/workflow_states", func() {
+					m.Combo("").Get(repo.ListWorkflowStates).
+						Post(reqToken(), reqRepoWriter(models.UnitTypeIssues), bind(api.CreateWorkflowStateOption{}), repo.CreateWorkflowState)
+					m.Combo("/:id").Get(repo.GetWorkflowState).
+						Patch(reqToken(), reqRepoWriter(models.UnitTypeIssues), bind(api.EditWorkflowStateOption{}), repo.EditWorkflowState).
+						Delete(reqToken(), reqRepoWriter(models.UnitTypeIssues), repo.DeleteWorkflowState)
+				}, mustEnableIssues)
+				m.Group("/epics", func() {
+					m.Combo("").Get(repo.ListEpics).
+						Post(reqToken(), reqRepoWriter(models.UnitTypeIssues), bind(api.CreateEpicOption{}), repo.CreateEpic)
+					m.Group("/:id", func() {
+						m.Combo("").Get(repo.GetEpic).
+							Patch(reqToken(), reqRepoWriter(models.UnitTypeIssues), bind(api.EditEpicOption{}), repo.EditEpic).
+							Delete(reqToken(), reqRepoWriter(models.UnitTypeIssues), repo.DeleteEpic)
+						m.Get("/issues", repo.ListEpicIssues)
+					})
+				}, mustEnableIssues)
+				m.Group("/discussions", func() {
+					m.Group("/categories", func() {
+						m.Combo("").Get(repo.ListDiscussionCategories).
+							Post(reqToken(), reqRepoWriter(models.UnitTypeDiscussions), bind(api.CreateDiscussionCategoryOption{}), repo.CreateDiscussionCategory)
+						m.Delete("/:id", reqToken(), reqRepoWriter(models.UnitTypeDiscussions), repo.DeleteDiscussionCategory)
+					})
+					m.Combo("").Get(repo.ListDiscussions).
+						Post(reqToken(), mustNotBeArchived, bind(api.CreateDiscussionOption{}), repo.CreateDiscussion)
+					m.Group("/:index", func() {
+						m.Combo("").Get(repo.GetDiscussion).
+							Delete(reqToken(), repo.DeleteDiscussion)
+						m.Combo("/upvote", reqToken()).Put(repo.UpvoteDiscussion).
+							Delete(repo.RemoveDiscussionUpvote)
+						m.Delete("/answer", reqToken(), repo.UnmarkDiscussionAnswer)
+						m.Group("/replies", func() {
+							m.Combo("").Get(repo.ListDiscussionReplies).
+								Post(reqToken(), mustNotBeArchived, bind(api.CreateDiscussionReplyOption{}), repo.CreateDiscussionReply)
+							m.Group("/:id", func() {
+								m.Delete("", reqToken(), repo.DeleteDiscussionReply)
+								m.Put("/answer", reqToken(), repo.MarkDiscussionAnswer)
+								m.Combo("/upvote", reqToken()).Put(repo.UpvoteDiscussionReply).
+									Delete(repo.RemoveDiscussionReplyUpvote)
+							})
+						})
+					})
+				}, reqRepoReader(models.UnitTypeDiscussions))
 				m.Post("/markdown", bind(api.MarkdownOption{}), misc.Markdown)
 				m.Post("/markdown/raw", misc.MarkdownRaw)
 				m.Group("/milestones", func() {
@@ -773,6 +929,11 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Get("", user.IsWatching)
 					m.Put("", reqToken(), user.Watch)
 					m.Delete("", reqToken(), user.Unwatch)
+					m.Group("/paths", func() {
+						m.Get("", user.ListWatchedPaths)
+						m.Put("/*", user.WatchPath)
+						m.Delete("/*", user.UnwatchPath)
+					}, reqToken(), reqRepoReader(models.UnitTypeCode))
 				})
 				m.Group("/releases", func() {
 					m.Combo("").Get(repo.ListReleases).
@@ -795,6 +956,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Group("/pulls", func() {
 					m.Combo("").Get(bind(api.ListPullRequestsOptions{}), repo.ListPullRequests).
 						Post(reqToken(), mustNotBeArchived, bind(api.CreatePullRequestOption{}), repo.CreatePullRequest)
+					m.Get("/templates", repo.ListPullRequestTemplates)
 					m.Group("/:index", func() {
 						m.Combo("").Get(repo.GetPullRequest).
 							Patch(reqToken(), reqRepoWriter(models.UnitTypePullRequests), bind(api.EditPullRequestOption{}), repo.EditPullRequest)
@@ -839,6 +1001,14 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Get("/blobs/:sha", context.RepoRef(), repo.GetBlob)
 					m.Get("/tags/:sha", context.RepoRef(), repo.GetTag)
 				}, reqRepoReader(models.UnitTypeCode))
+				m.Group("/tree", func() {
+					m.Get("", repo.GetDirectoryTree)
+					m.Get("/*", repo.GetDirectoryTree)
+				}, reqRepoReader(models.UnitTypeCode))
+				m.Group("/last_commits", func() {
+					m.Get("", repo.GetLastCommits)
+					m.Get("/*", repo.GetLastCommits)
+				}, reqRepoReader(models.UnitTypeCode))
 				m.Group("/contents", func() {
 					m.Get("", repo.GetContentsList)
 					m.Get("/*", repo.GetContents)
@@ -857,7 +1027,22 @@ func RegisterRoutes(m *macaron.Macaron) {
 							Delete(reqToken(), repo.DeleteTopic)
 					}, reqAdmin())
 				}, reqAnyRepoReader())
-				m.Get("/languages", reqRepoReader(models.UnitTypeCode), repo.GetLanguages)
+				m.Group("/languages", func() {
+					m.Get("", repo.GetLanguages)
+					m.Get("/directories", repo.GetLanguagesByDirectory)
+					m.Post("/recalculate", reqToken(), reqRepoWriter(models.UnitTypeCode), repo.RecalculateLanguages)
+				}, reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(true))
+				m.Group("/activity", func() {
+					m.Get("/heatmap", repo.GetActivityHeatmap)
+					m.Get("/contributors", repo.ListActivityContributors)
+				}, mustEnableUserHeatmap, reqAnyRepoReader())
+				m.Group("/stats", func() {
+					m.Get("/contributors", repo.GetContributorStats)
+					m.Get("/commit_activity", repo.GetCommitActivity)
+					m.Get("/code_frequency", repo.GetCodeFrequency)
+					m.Get("/participation", repo.GetParticipation)
+					m.Get("/punch_card", repo.GetPunchCard)
+				}, reqRepoReader(models.UnitTypeCode))
 			}, repoAssignment())
 		})
 
@@ -872,11 +1057,31 @@ func RegisterRoutes(m *macaron.Macaron) {
 				Delete(reqToken(), reqOrgOwnership(), org.Delete)
 			m.Combo("/repos").Get(user.ListOrgRepos).
 				Post(reqToken(), bind(api.CreateRepoOption{}), repo.CreateOrgRepo)
+			m.Combo("/pinned_repos").Get(user.ListOrgPinnedRepos).
+				Put(reqToken(), reqOrgOwnership(), bind(api.EditPinnedReposOption{}), user.UpdateOrgPinnedRepos)
+			m.Group("/activity", func() {
+				m.Get("/heatmap", org.GetActivityHeatmap)
+				m.Get("/contributors", org.ListActivityContributors)
+			}, mustEnableUserHeatmap)
+			m.Group("/topics", func() {
+				m.Get("", org.ListTopics)
+				m.Group("/:topic", func() {
+					m.Patch("", bind(api.EditTopicOption{}), org.EditTopic)
+					m.Post("/merge", bind(api.MergeTopicOption{}), org.MergeTopic)
+				}, reqToken(), reqOrgOwnership())
+			})
 			m.Group("/members", func() {
 				m.Get("", org.ListMembers)
 				m.Combo("/:username").Get(org.IsMember).
+					Patch(reqToken(), reqOrgOwnership(), bind(api.EditOrgMembershipOption{}), org.EditMember).
 					Delete(reqToken(), reqOrgOwnership(), org.DeleteMember)
 			})
+			m.Group("/external_collaborators", func() {
+				m.Get("", org.ListExternalCollaborators)
+				m.Combo("/:username").
+					Put(reqOrgOwnership(), org.ConvertMemberToExternal).
+					Delete(reqOrgOwnership(), org.RemoveExternalCollaborator)
+			}, reqToken(), reqOrgMembership())
 			m.Group("/public_members", func() {
 				m.Get("", org.ListPublicMembers)
 				m.Combo("/:username").Get(org.IsPublicMember).
@@ -888,19 +1093,79 @@ func RegisterRoutes(m *macaron.Macaron) {
 					Post(reqOrgOwnership(), bind(api.CreateTeamOption{}), org.CreateTeam)
 				m.Get("/search", org.SearchTeam)
 			}, reqOrgMembership())
+			m.Group("/bots", func() {
+				m.Combo("").Get(org.ListBots).
+					Post(bind(api.CreateOrgBotOption{}), org.CreateBot)
+				m.Group("/:username", func() {
+					m.Combo("").Get(org.GetBot).
+						Patch(bind(api.EditOrgBotOption{}), org.EditBot).
+						Delete(org.DeleteBot)
+					m.Post("/token", org.RotateBotToken)
+				})
+			}, reqToken(), reqOrgOwnership())
+			m.Group("/roles", func() {
+				m.Combo("").Get(org.ListRoles).
+					Post(reqOrgOwnership(), bind(api.CreateOrgRoleOption{}), org.CreateRole)
+				m.Combo("/:id").Get(org.GetRole).
+					Patch(reqOrgOwnership(), bind(api.EditOrgRoleOption{}), org.EditRole).
+					Delete(reqOrgOwnership(), org.DeleteRole)
+			}, reqToken(), reqOrgMembership())
 			m.Group("/labels", func() {
 				m.Get("", org.ListLabels)
 				m.Post("", reqToken(), reqOrgOwnership(), bind(api.CreateLabelOption{}), org.CreateLabel)
+				m.Post("/sync", reqToken(), reqOrgOwnership(), org.SyncLabels)
 				m.Combo("/:id").Get(org.GetLabel).
 					Patch(reqToken(), reqOrgOwnership(), bind(api.EditLabelOption{}), org.EditLabel).
 					Delete(reqToken(), reqOrgOwnership(), org.DeleteLabel)
 			})
+			m.Group("/autolinks", func() {
+				m.Get("", org.ListAutoLinkRules)
+				m.Post("", reqToken(), reqOrgOwnership(), bind(api.CreateAutoLinkRuleOption{}), org.CreateAutoLinkRule)
+				m.Combo("/:id").Get(org.GetAutoLinkRule).
+					Patch(reqToken(), reqOrgOwnership(), bind(api.EditAutoLinkRuleOption{}), org.EditAutoLinkRule).
+					Delete(reqToken(), reqOrgOwnership(), org.DeleteAutoLinkRule)
+			})
+			m.Group("/epics", func() {
+				m.Get("", org.ListEpics)
+				m.Post("", reqToken(), reqOrgOwnership(), bind(api.CreateEpicOption{}), org.CreateEpic)
+				m.Group("/:id", func() {
+					m.Combo("").Get(org.GetEpic).
+						Patch(reqToken(), reqOrgOwnership(), bind(api.EditEpicOption{}), org.EditEpic).
+						Delete(reqToken(), reqOrgOwnership(), org.DeleteEpic)
+					m.Get("/issues", org.ListEpicIssues)
+				})
+			})
+			m.Group("/projects", func() {
+				m.Combo("").Get(org.ListProjects).
+					Post(reqOrgOwnership(), bind(api.CreateProjectOption{}), org.CreateProject)
+				m.Group("/:id", func() {
+					m.Combo("").Get(org.GetProject).
+						Patch(reqOrgOwnership(), bind(api.EditProjectOption{}), org.EditProject).
+						Delete(reqOrgOwnership(), org.DeleteProject)
+					m.Combo("/boards").Get(org.ListProjectBoards).
+						Post(reqOrgOwnership(), bind(api.CreateProjectBoardOption{}), org.CreateProjectBoard)
+					m.Delete("/boards/:board", reqOrgOwnership(), org.DeleteProjectBoard)
+					m.Combo("/issues").Get(org.ListProjectIssues).
+						Post(reqOrgOwnership(), bind(api.AddProjectIssuesOption{}), org.AddProjectIssues)
+					m.Combo("/issues/:issue").
+						Patch(reqOrgOwnership(), bind(api.MoveProjectIssueOption{}), org.MoveProjectIssue).
+						Delete(reqOrgOwnership(), org.RemoveProjectIssue)
+					m.Combo("/rules").Get(org.ListProjectRules).
+						Post(reqOrgOwnership(), bind(api.CreateProjectRuleOption{}), org.CreateProjectRule)
+					m.Delete("/rules/:rule", reqOrgOwnership(), org.DeleteProjectRule)
+				})
+			}, reqToken(), reqOrgMembership())
 			m.Group("/hooks", func() {
 				m.Combo("").Get(org.ListHooks).
 					Post(bind(api.CreateHookOption{}), org.CreateHook)
 				m.Combo("/:id").Get(org.GetHook).
 					Patch(bind(api.EditHookOption{}), org.EditHook).
 					Delete(org.DeleteHook)
+				m.Get("/:id/deliveries", org.ListHookDeliveries)
+				m.Group("/:id/deliveries/:delivery_id", func() {
+					m.Get("", org.GetHookDelivery)
+					m.Post("/redeliver", org.RedeliverHookDelivery)
+				})
 			}, reqToken(), reqOrgOwnership())
 		}, orgAssignment(true))
 		m.Group("/teams/:teamid", func() {
@@ -914,6 +1179,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 					Put(reqOrgOwnership(), org.AddTeamMember).
 					Delete(reqOrgOwnership(), org.RemoveTeamMember)
 			})
+			m.Get("/teams", org.ListChildTeams)
 			m.Group("/repos", func() {
 				m.Get("", org.GetTeamRepos)
 				m.Combo("/:org/:reponame").
@@ -928,6 +1194,31 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 		m.Group("/admin", func() {
 			m.Get("/orgs", admin.GetAllOrgs)
+			m.Get("/stats", admin.GetStats)
+			m.Get("/health", admin.GetHealth)
+			m.Combo("/maintenance").Get(admin.GetMaintenance).
+				Patch(bind(api.EditMaintenanceOption{}), admin.EditMaintenance)
+			m.Post("/config/reload", admin.ReloadConfig)
+			m.Group("/emojis", func() {
+				m.Post("", admin.CreateCustomEmoji)
+				m.Delete("/:name", admin.DeleteCustomEmoji)
+			})
+			m.Group("/themes", func() {
+				m.Post("", admin.UploadTheme)
+				m.Delete("/:name", admin.DeleteTheme)
+			})
+			m.Group("/badges", func() {
+				m.Combo("").Get(admin.ListBadges).
+					Post(bind(api.CreateBadgeOption{}), admin.CreateBadge)
+				m.Group("/:id", func() {
+					m.Combo("").Get(admin.GetBadge).
+						Patch(bind(api.EditBadgeOption{}), admin.EditBadge).
+						Delete(admin.DeleteBadge)
+					m.Get("/users", admin.ListBadgeUsers)
+					m.Combo("/users/:username").Put(admin.GrantBadge).
+						Delete(admin.RevokeBadge)
+				})
+			})
 			m.Group("/users", func() {
 				m.Get("", admin.GetAllUsers)
 				m.Post("", bind(api.CreateUserOption{}), admin.CreateUser)
@@ -945,8 +1236,29 @@ func RegisterRoutes(m *macaron.Macaron) {
 			})
 		}, reqToken(), reqSiteAdmin())
 
+		m.Group("/snippets", func() {
+			m.Combo("").Get(snippet.ListSnippets).
+				Post(reqToken(), bind(api.CreateSnippetOption{}), snippet.CreateSnippet)
+			m.Group("/:id", func() {
+				m.Combo("").Get(snippet.GetSnippet).
+					Patch(reqToken(), bind(api.EditSnippetOption{}), snippet.EditSnippet).
+					Delete(reqToken(), snippet.DeleteSnippet)
+				m.Get("/revisions", snippet.ListSnippetRevisions)
+				m.Combo("/comments").Get(snippet.ListSnippetComments).
+					Post(reqToken(), bind(api.CreateSnippetCommentOption{}), snippet.CreateSnippetComment)
+				m.Delete("/comments/:comment_id", reqToken(), snippet.DeleteSnippetComment)
+			})
+		}, mustEnableSnippets)
+
 		m.Group("/topics", func() {
 			m.Get("/search", repo.TopicSearch)
+			m.Get("/trending", repo.ListTrendingTopics)
+			m.Get("/featured", repo.ListFeaturedTopics)
+			m.Group("/:topic", func() {
+				m.Combo("").Get(repo.GetTopic).
+					Patch(reqToken(), reqSiteAdmin(), bind(api.EditTopicOption{}), repo.EditTopic)
+				m.Post("/merge", reqToken(), reqSiteAdmin(), bind(api.MergeTopicOption{}), repo.MergeTopic)
+			})
 		})
 	}, securityHeaders(), context.APIContexter(), sudo())
 }
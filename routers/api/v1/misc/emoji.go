@@ -0,0 +1,38 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListCustomEmojis lists the custom emoji of the instance
+func ListCustomEmojis(ctx *context.APIContext) {
+	// swagger:operation GET /emojis miscellaneous listCustomEmojis
+	// ---
+	// summary: List the custom emoji uploaded by site administrators
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CustomEmojiList"
+
+	emojis, err := models.GetCustomEmojis()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCustomEmojis", err)
+		return
+	}
+
+	apiEmojis := make([]*api.CustomEmoji, len(emojis))
+	for i := range emojis {
+		apiEmojis[i] = convert.ToCustomEmoji(emojis[i])
+	}
+	ctx.JSON(http.StatusOK, apiEmojis)
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+// MailBounce records a bounce reported by the webhook of the mail provider
+func MailBounce(ctx *context.APIContext, form api.MailBounceOption) {
+	// swagger:operation POST /mail/bounces miscellaneous reportMailBounce
+	// ---
+	// summary: Report a bounce of a mail sent by Gitea, the mailer stops sending to the address once it is suppressed
+	// description: The webhook is enabled by the BOUNCE_WEBHOOK_TOKEN setting of the mailer, the token is given in the X-Gitea-Bounce-Token header.
+	// consumes:
+	// - application/json
+	// parameters:
+	// - name: X-Gitea-Bounce-Token
+	//   in: header
+	//   description: the BOUNCE_WEBHOOK_TOKEN setting of the mailer
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/MailBounceOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if setting.MailService == nil || setting.MailService.BounceWebhookToken == "" {
+		ctx.NotFound()
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(ctx.Req.Header.Get("X-Gitea-Bounce-Token")), []byte(setting.MailService.BounceWebhookToken)) != 1 {
+		ctx.Error(http.StatusForbidden, "", "invalid bounce token")
+		return
+	}
+	if ctx.HasAPIError() {
+		ctx.Error(http.StatusUnprocessableEntity, "", ctx.GetErrMsg())
+		return
+	}
+
+	typ := models.EmailBounceHard
+	if form.Type != "" {
+		typ = models.ParseEmailBounceType(form.Type)
+	}
+	if err := mailer.RecordBounce(form.Email, typ, form.Reason, "webhook"); err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
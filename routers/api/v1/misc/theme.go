@@ -0,0 +1,46 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListThemes lists the themes the users can select
+func ListThemes(ctx *context.APIContext) {
+	// swagger:operation GET /themes miscellaneous listThemes
+	// ---
+	// summary: List the built-in themes and the themes uploaded by site administrators
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ThemeList"
+
+	themes, err := models.GetThemes()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetThemes", err)
+		return
+	}
+
+	apiThemes := make([]*api.Theme, 0, len(setting.UI.Themes)+len(themes))
+	for _, name := range setting.UI.Themes {
+		apiThemes = append(apiThemes, &api.Theme{
+			Name:        name,
+			DisplayName: name,
+			Builtin:     true,
+		})
+	}
+	for i := range themes {
+		apiThemes = append(apiThemes, convert.ToTheme(themes[i]))
+	}
+	ctx.JSON(http.StatusOK, apiThemes)
+}
@@ -11,7 +11,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
-	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
@@ -42,6 +42,18 @@ func statusStringsToNotificationStatuses(statuses []string, defaultStatuses []st
 	return results
 }
 
+// setNotificationFilters applies the reasons and saved filters of the query to the options
+func setNotificationFilters(ctx *context.APIContext, opts *models.FindNotificationOptions) {
+	for _, name := range ctx.QueryStrings("reasons") {
+		if reason := models.ParseNotificationReason(strings.ToLower(strings.TrimSpace(name))); reason > 0 {
+			opts.Reasons = append(opts.Reasons, reason)
+		}
+	}
+	if len(ctx.Query("saved")) > 0 {
+		opts.IsSaved = util.OptionalBoolOf(ctx.QueryBool("saved"))
+	}
+}
+
 // ListRepoNotifications list users's notification threads on a specific repo
 func ListRepoNotifications(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/notifications notification notifyGetRepoList
@@ -75,6 +87,19 @@ func ListRepoNotifications(ctx *context.APIContext) {
 	//   items:
 	//     type: string
 	//   required: false
+	// - name: reasons
+	//   in: query
+	//   description: "Show notifications with the provided reasons. Options are: watch, mention, assign, review_request and/or attachment_expiry."
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//   required: false
+	// - name: saved
+	//   in: query
+	//   description: If true, show only saved notifications, if false, only the ones which are not saved
+	//   type: boolean
+	//   required: false
 	// - name: since
 	//   in: query
 	//   description: Only show notifications updated after the given time. This is a timestamp in RFC 3339 format
@@ -87,6 +112,11 @@ func ListRepoNotifications(ctx *context.APIContext) {
 	//   type: string
 	//   format: date-time
 	//   required: false
+	// - name: cursor
+	//   in: query
+	//   description: "Opt in to cursor based pagination, newest notifications first. Pass an empty cursor for the first page, then the cursor of the X-Next-Cursor header. Ignores page."
+	//   type: string
+	//   required: false
 	// - name: page
 	//   in: query
 	//   description: page number of results to return (1-based)
@@ -98,6 +128,8 @@ func ListRepoNotifications(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/NotificationThreadList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	before, since, err := utils.GetQueryBeforeSince(ctx)
 	if err != nil {
@@ -116,6 +148,11 @@ func ListRepoNotifications(ctx *context.APIContext) {
 		statuses := ctx.QueryStrings("status-types")
 		opts.Status = statusStringsToNotificationStatuses(statuses, []string{"unread", "pinned"})
 	}
+	setNotificationFilters(ctx, &opts)
+	if opts.Cursor, err = utils.GetCursorOptions(ctx); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
 	nl, err := models.GetNotifications(opts)
 	if err != nil {
 		ctx.InternalServerError(err)
@@ -126,8 +163,11 @@ func ListRepoNotifications(ctx *context.APIContext) {
 		ctx.InternalServerError(err)
 		return
 	}
+	if opts.Cursor != nil && len(nl) > 0 {
+		utils.SetNextCursor(ctx, opts.Cursor, len(nl), nl[len(nl)-1].ID)
+	}
 
-	ctx.JSON(http.StatusOK, nl.APIFormat())
+	ctx.JSONConditional(http.StatusOK, nl.APIFormat(), nl.LastUpdatedUnix())
 }
 
 // ReadRepoNotifications mark notification threads as read on a specific repo
@@ -163,6 +203,19 @@ func ReadRepoNotifications(ctx *context.APIContext) {
 	//   items:
 	//     type: string
 	//   required: false
+	// - name: reasons
+	//   in: query
+	//   description: "Mark notifications with the provided reasons. Options are: watch, mention, assign, review_request and/or attachment_expiry."
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//   required: false
+	// - name: saved
+	//   in: query
+	//   description: If true, mark only saved notifications, if false, only the ones which are not saved
+	//   type: boolean
+	//   required: false
 	// - name: to-status
 	//   in: query
 	//   description: Status to mark notifications as. Defaults to read.
@@ -200,26 +253,17 @@ func ReadRepoNotifications(ctx *context.APIContext) {
 	if !ctx.QueryBool("all") {
 		statuses := ctx.QueryStrings("status-types")
 		opts.Status = statusStringsToNotificationStatuses(statuses, []string{"unread"})
-		log.Error("%v", opts.Status)
-	}
-	nl, err := models.GetNotifications(opts)
-	if err != nil {
-		ctx.InternalServerError(err)
-		return
 	}
+	setNotificationFilters(ctx, &opts)
 
 	targetStatus := statusStringToNotificationStatus(ctx.Query("to-status"))
 	if targetStatus == 0 {
 		targetStatus = models.NotificationStatusRead
 	}
 
-	for _, n := range nl {
-		err := models.SetNotificationStatus(n.ID, ctx.User, targetStatus)
-		if err != nil {
-			ctx.InternalServerError(err)
-			return
-		}
-		ctx.Status(http.StatusResetContent)
+	if err := models.UpdateNotificationStatusesByFilter(ctx.User, opts, targetStatus); err != nil {
+		ctx.InternalServerError(err)
+		return
 	}
 
 	ctx.Status(http.StatusResetContent)
@@ -94,6 +94,66 @@ func ReadThread(ctx *context.APIContext) {
 	ctx.Status(http.StatusResetContent)
 }
 
+// SaveThread adds a notification thread to the saved notifications
+func SaveThread(ctx *context.APIContext) {
+	// swagger:operation PUT /notifications/threads/{id}/saved notification notifySaveThread
+	// ---
+	// summary: Add a notification thread to the saved notifications
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of notification thread
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	setThreadSaved(ctx, true)
+}
+
+// UnsaveThread removes a notification thread from the saved notifications
+func UnsaveThread(ctx *context.APIContext) {
+	// swagger:operation DELETE /notifications/threads/{id}/saved notification notifyUnsaveThread
+	// ---
+	// summary: Remove a notification thread from the saved notifications
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of notification thread
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	setThreadSaved(ctx, false)
+}
+
+func setThreadSaved(ctx *context.APIContext, saved bool) {
+	n := getThread(ctx)
+	if n == nil {
+		return
+	}
+	if n.UserID != ctx.User.ID {
+		ctx.Error(http.StatusForbidden, "SetNotificationSaved", fmt.Errorf("only user itself is allowed to save thread %d", n.ID))
+		return
+	}
+	if err := models.SetNotificationSaved(n.ID, ctx.User, saved); err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
 func getThread(ctx *context.APIContext) *models.Notification {
 	n, err := models.GetNotificationByID(ctx.ParamsInt64(":id"))
 	if err != nil {
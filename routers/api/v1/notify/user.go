@@ -37,6 +37,19 @@ func ListNotifications(ctx *context.APIContext) {
 	//   items:
 	//     type: string
 	//   required: false
+	// - name: reasons
+	//   in: query
+	//   description: "Show notifications with the provided reasons. Options are: watch, mention, assign, review_request and/or attachment_expiry."
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//   required: false
+	// - name: saved
+	//   in: query
+	//   description: If true, show only saved notifications, if false, only the ones which are not saved
+	//   type: boolean
+	//   required: false
 	// - name: since
 	//   in: query
 	//   description: Only show notifications updated after the given time. This is a timestamp in RFC 3339 format
@@ -49,6 +62,11 @@ func ListNotifications(ctx *context.APIContext) {
 	//   type: string
 	//   format: date-time
 	//   required: false
+	// - name: cursor
+	//   in: query
+	//   description: "Opt in to cursor based pagination, newest notifications first. Pass an empty cursor for the first page, then the cursor of the X-Next-Cursor header. Ignores page."
+	//   type: string
+	//   required: false
 	// - name: page
 	//   in: query
 	//   description: page number of results to return (1-based)
@@ -60,6 +78,8 @@ func ListNotifications(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/NotificationThreadList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	before, since, err := utils.GetQueryBeforeSince(ctx)
 	if err != nil {
@@ -76,6 +96,11 @@ func ListNotifications(ctx *context.APIContext) {
 		statuses := ctx.QueryStrings("status-types")
 		opts.Status = statusStringsToNotificationStatuses(statuses, []string{"unread", "pinned"})
 	}
+	setNotificationFilters(ctx, &opts)
+	if opts.Cursor, err = utils.GetCursorOptions(ctx); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
 	nl, err := models.GetNotifications(opts)
 	if err != nil {
 		ctx.InternalServerError(err)
@@ -86,8 +111,11 @@ func ListNotifications(ctx *context.APIContext) {
 		ctx.InternalServerError(err)
 		return
 	}
+	if opts.Cursor != nil && len(nl) > 0 {
+		utils.SetNextCursor(ctx, opts.Cursor, len(nl), nl[len(nl)-1].ID)
+	}
 
-	ctx.JSON(http.StatusOK, nl.APIFormat())
+	ctx.JSONConditional(http.StatusOK, nl.APIFormat(), nl.LastUpdatedUnix())
 }
 
 // ReadNotifications mark notification threads as read, unread, or pinned
@@ -119,6 +147,19 @@ func ReadNotifications(ctx *context.APIContext) {
 	//   items:
 	//     type: string
 	//   required: false
+	// - name: reasons
+	//   in: query
+	//   description: "Mark notifications with the provided reasons. Options are: watch, mention, assign, review_request and/or attachment_expiry."
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//   required: false
+	// - name: saved
+	//   in: query
+	//   description: If true, mark only saved notifications, if false, only the ones which are not saved
+	//   type: boolean
+	//   required: false
 	// - name: to-status
 	//   in: query
 	//   description: Status to mark notifications as, Defaults to read.
@@ -148,24 +189,16 @@ func ReadNotifications(ctx *context.APIContext) {
 		statuses := ctx.QueryStrings("status-types")
 		opts.Status = statusStringsToNotificationStatuses(statuses, []string{"unread"})
 	}
-	nl, err := models.GetNotifications(opts)
-	if err != nil {
-		ctx.InternalServerError(err)
-		return
-	}
+	setNotificationFilters(ctx, &opts)
 
 	targetStatus := statusStringToNotificationStatus(ctx.Query("to-status"))
 	if targetStatus == 0 {
 		targetStatus = models.NotificationStatusRead
 	}
 
-	for _, n := range nl {
-		err := models.SetNotificationStatus(n.ID, ctx.User, targetStatus)
-		if err != nil {
-			ctx.InternalServerError(err)
-			return
-		}
-		ctx.Status(http.StatusResetContent)
+	if err := models.UpdateNotificationStatusesByFilter(ctx.User, opts, targetStatus); err != nil {
+		ctx.InternalServerError(err)
+		return
 	}
 
 	ctx.Status(http.StatusResetContent)
@@ -0,0 +1,234 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListAutoLinkRules list the auto-link rules of an organization
+func ListAutoLinkRules(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/autolinks organization orgListAutoLinkRules
+	// ---
+	// summary: List the auto-link rules of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AutoLinkRuleList"
+
+	rules, err := models.GetOrgAutoLinkRules(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetOrgAutoLinkRules", err)
+		return
+	}
+
+	apiRules := make([]*api.AutoLinkRule, len(rules))
+	for i := range rules {
+		apiRules[i] = convert.ToAutoLinkRule(rules[i])
+	}
+	ctx.JSON(http.StatusOK, apiRules)
+}
+
+// GetAutoLinkRule get an auto-link rule of an organization
+func GetAutoLinkRule(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/autolinks/{id} organization orgGetAutoLinkRule
+	// ---
+	// summary: Get an auto-link rule of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the rule to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AutoLinkRule"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	rule := getAutoLinkRule(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToAutoLinkRule(rule))
+}
+
+// CreateAutoLinkRule create an auto-link rule for an organization
+func CreateAutoLinkRule(ctx *context.APIContext, form api.CreateAutoLinkRuleOption) {
+	// swagger:operation POST /orgs/{org}/autolinks organization orgCreateAutoLinkRule
+	// ---
+	// summary: Create an auto-link rule for an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateAutoLinkRuleOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/AutoLinkRule"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	rule := &models.AutoLinkRule{
+		OrgID:   ctx.Org.Organization.ID,
+		Pattern: form.Pattern,
+		URL:     form.URL,
+		Sort:    form.Sort,
+	}
+	if err := models.NewAutoLinkRule(rule); err != nil {
+		if models.IsErrAutoLinkRuleInvalid(err) || models.IsErrAutoLinkRulesLimit(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "NewAutoLinkRule", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "NewAutoLinkRule", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToAutoLinkRule(rule))
+}
+
+// EditAutoLinkRule modify an auto-link rule of an organization
+func EditAutoLinkRule(ctx *context.APIContext, form api.EditAutoLinkRuleOption) {
+	// swagger:operation PATCH /orgs/{org}/autolinks/{id} organization orgEditAutoLinkRule
+	// ---
+	// summary: Edit an auto-link rule of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the rule to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditAutoLinkRuleOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AutoLinkRule"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	rule := getAutoLinkRule(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Pattern != nil {
+		rule.Pattern = *form.Pattern
+	}
+	if form.URL != nil {
+		rule.URL = *form.URL
+	}
+	if form.Sort != nil {
+		rule.Sort = *form.Sort
+	}
+	if err := models.UpdateAutoLinkRule(rule); err != nil {
+		if models.IsErrAutoLinkRuleInvalid(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "UpdateAutoLinkRule", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateAutoLinkRule", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToAutoLinkRule(rule))
+}
+
+// DeleteAutoLinkRule delete an auto-link rule of an organization
+func DeleteAutoLinkRule(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/autolinks/{id} organization orgDeleteAutoLinkRule
+	// ---
+	// summary: Delete an auto-link rule of an organization
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the rule to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	rule := getAutoLinkRule(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteOrgAutoLinkRule(ctx.Org.Organization.ID, rule.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteOrgAutoLinkRule", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func getAutoLinkRule(ctx *context.APIContext) *models.AutoLinkRule {
+	rule, err := models.GetOrgAutoLinkRule(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrAutoLinkRuleNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetOrgAutoLinkRule", err)
+		}
+		return nil
+	}
+	return rule
+}
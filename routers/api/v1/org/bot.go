@@ -0,0 +1,291 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListBots list the bots of an organization
+func ListBots(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/bots organization orgListBots
+	// ---
+	// summary: List an organization's bots
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgBotList"
+
+	bots, err := models.GetOrgBots(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetOrgBots", err)
+		return
+	}
+
+	apiBots := make([]*api.OrgBot, len(bots))
+	for i := range bots {
+		apiBots[i] = convert.ToOrgBot(bots[i])
+	}
+	ctx.JSON(http.StatusOK, apiBots)
+}
+
+// getBotByParams returns the bot of the organization in the path,
+// it responds with not found if it does not exist
+func getBotByParams(ctx *context.APIContext) *models.OrgBot {
+	b, err := models.GetOrgBot(ctx.Org.Organization.ID, ctx.Params(":username"))
+	if err != nil {
+		if models.IsErrOrgBotNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetOrgBot", err)
+		}
+		return nil
+	}
+	return b
+}
+
+// toBotAccessMode converts the maximum permission of a bot, it responds
+// with a validation error if the permission is unknown
+func toBotAccessMode(ctx *context.APIContext, permission string) models.AccessMode {
+	switch permission {
+	case "read", "write", "admin":
+		return models.ParseAccessMode(permission)
+	}
+	ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("invalid permission: %s", permission))
+	return models.AccessModeNone
+}
+
+// GetBot get a bot of an organization
+func GetBot(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/bots/{username} organization orgGetBot
+	// ---
+	// summary: Get a bot
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: username
+	//   in: path
+	//   description: username of the bot
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgBot"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	b := getBotByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToOrgBot(b))
+}
+
+// CreateBot create a bot in an organization
+func CreateBot(ctx *context.APIContext, form api.CreateOrgBotOption) {
+	// swagger:operation POST /orgs/{org}/bots organization orgCreateBot
+	// ---
+	// summary: Create a bot, it cannot sign in and authenticates with the token returned by the rotation endpoint
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateOrgBotOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/OrgBot"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	mode := models.AccessModeWrite
+	if form.MaxPermission != "" {
+		if mode = toBotAccessMode(ctx, form.MaxPermission); ctx.Written() {
+			return
+		}
+	}
+
+	b := &models.OrgBot{
+		OrgID:         ctx.Org.Organization.ID,
+		CreatorID:     ctx.User.ID,
+		Creator:       ctx.User,
+		MaxAccessMode: mode,
+		Bot: &models.User{
+			Name:        form.UserName,
+			FullName:    form.FullName,
+			Description: form.Description,
+		},
+	}
+	if err := models.NewOrgBot(b); err != nil {
+		if models.IsErrUserAlreadyExist(err) ||
+			models.IsErrNameReserved(err) ||
+			models.IsErrNameCharsNotAllowed(err) ||
+			models.IsErrNamePatternNotAllowed(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "NewOrgBot", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToOrgBot(b))
+}
+
+// EditBot edit a bot of an organization
+func EditBot(ctx *context.APIContext, form api.EditOrgBotOption) {
+	// swagger:operation PATCH /orgs/{org}/bots/{username} organization orgEditBot
+	// ---
+	// summary: Edit a bot
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: username
+	//   in: path
+	//   description: username of the bot
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditOrgBotOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgBot"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	b := getBotByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.FullName != nil {
+		b.Bot.FullName = *form.FullName
+	}
+	if form.Description != nil {
+		b.Bot.Description = *form.Description
+	}
+	if form.MaxPermission != nil {
+		if b.MaxAccessMode = toBotAccessMode(ctx, *form.MaxPermission); ctx.Written() {
+			return
+		}
+	}
+
+	if err := models.UpdateOrgBot(b); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateOrgBot", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToOrgBot(b))
+}
+
+// DeleteBot delete a bot of an organization
+func DeleteBot(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/bots/{username} organization orgDeleteBot
+	// ---
+	// summary: Delete a bot, its memberships and its token
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: username
+	//   in: path
+	//   description: username of the bot
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	b := getBotByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.DeleteOrgBot(b); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteOrgBot", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// RotateBotToken replace the access token of a bot
+func RotateBotToken(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/bots/{username}/token organization orgRotateBotToken
+	// ---
+	// summary: Revoke the access token of a bot and create a new one
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: username
+	//   in: path
+	//   description: username of the bot
+	//   type: string
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/AccessToken"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	b := getBotByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	t, err := models.RotateOrgBotToken(b)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "RotateOrgBotToken", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, &api.AccessToken{
+		ID:             t.ID,
+		Name:           t.Name,
+		Token:          t.Token,
+		TokenLastEight: t.TokenLastEight,
+	})
+}
@@ -0,0 +1,283 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListEpics list the epics of an organization
+func ListEpics(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/epics organization orgListEpics
+	// ---
+	// summary: List an organization's epics
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: Epic state, Recognised values are open, closed and all. Defaults to "open"
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/EpicList"
+
+	epics, err := models.GetEpicsByOrgID(ctx.Org.Organization.ID, api.StateType(ctx.Query("state")), utils.GetListOptions(ctx))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetEpicsByOrgID", err)
+		return
+	}
+
+	apiEpics := make([]*api.Epic, len(epics))
+	for i := range epics {
+		apiEpics[i] = convert.ToAPIEpic(epics[i])
+	}
+	ctx.JSON(http.StatusOK, &apiEpics)
+}
+
+// GetEpic get an epic of an organization
+func GetEpic(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/epics/{id} organization orgGetEpic
+	// ---
+	// summary: Get an epic
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the epic to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Epic"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	epic, err := models.GetEpicByOrgID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrEpicNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetEpicByOrgID", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToAPIEpic(epic))
+}
+
+// CreateEpic create an epic for an organization
+func CreateEpic(ctx *context.APIContext, form api.CreateEpicOption) {
+	// swagger:operation POST /orgs/{org}/epics organization orgCreateEpic
+	// ---
+	// summary: Create an epic
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateEpicOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Epic"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	epic := &models.Epic{
+		OwnerID: ctx.Org.Organization.ID,
+		Name:    form.Title,
+		Content: form.Description,
+	}
+	if err := models.NewEpic(epic); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewEpic", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToAPIEpic(epic))
+}
+
+// EditEpic modify an epic of an organization
+func EditEpic(ctx *context.APIContext, form api.EditEpicOption) {
+	// swagger:operation PATCH /orgs/{org}/epics/{id} organization orgEditEpic
+	// ---
+	// summary: Update an epic
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the epic to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditEpicOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Epic"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	epic, err := models.GetEpicByOrgID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrEpicNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetEpicByOrgID", err)
+		}
+		return
+	}
+
+	if form.Title != nil {
+		epic.Name = *form.Title
+	}
+	if form.Description != nil {
+		epic.Content = *form.Description
+	}
+	if form.State != nil {
+		epic.IsClosed = api.StateClosed == api.StateType(*form.State)
+	}
+	if err := models.UpdateEpic(epic); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateEpic", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToAPIEpic(epic))
+}
+
+// DeleteEpic delete an epic of an organization
+func DeleteEpic(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/epics/{id} organization orgDeleteEpic
+	// ---
+	// summary: Delete an epic, its issues are kept
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the epic to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	epic, err := models.GetEpicByOrgID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrEpicNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetEpicByOrgID", err)
+		}
+		return
+	}
+
+	if err := models.DeleteEpic(epic); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteEpic", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListEpicIssues list the issues of an epic
+func ListEpicIssues(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/epics/{id}/issues organization orgListEpicIssues
+	// ---
+	// summary: List the issues of an epic the user is allowed to see
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the epic
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	epic, err := models.GetEpicByOrgID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrEpicNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetEpicByOrgID", err)
+		}
+		return
+	}
+
+	issues, err := models.GetEpicIssues(epic.ID, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetEpicIssues", err)
+		return
+	}
+
+	apiIssues := make([]*api.Issue, len(issues))
+	for i := range issues {
+		apiIssues[i] = convert.ToAPIIssue(issues[i])
+	}
+	ctx.JSON(http.StatusOK, &apiIssues)
+}
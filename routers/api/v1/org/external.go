@@ -0,0 +1,130 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/user"
+)
+
+// ListExternalCollaborators list the external collaborators of an organization
+func ListExternalCollaborators(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/external_collaborators organization orgListExternalCollaborators
+	// ---
+	// summary: List the users collaborating on repositories of an organization without being members
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgExternalCollaboratorList"
+
+	externals, err := models.GetOrgExternalCollaborators(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetOrgExternalCollaborators", err)
+		return
+	}
+
+	apiExternals := make([]*api.OrgExternalCollaborator, len(externals))
+	for i, ec := range externals {
+		apiExternals[i] = convert.ToOrgExternalCollaborator(ec, ctx.IsSigned, ctx.User.IsAdmin)
+	}
+	ctx.JSON(http.StatusOK, apiExternals)
+}
+
+// ConvertMemberToExternal make a member of an organization an external collaborator
+func ConvertMemberToExternal(ctx *context.APIContext) {
+	// swagger:operation PUT /orgs/{org}/external_collaborators/{username} organization orgConvertMemberToExternal
+	// ---
+	// summary: Make a member of an organization an external collaborator of the repositories of their teams
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: username
+	//   in: path
+	//   description: username of the member
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     description: member converted
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	member := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	isMember, err := ctx.Org.Organization.IsOrgMember(member.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsOrgMember", err)
+		return
+	} else if !isMember {
+		ctx.NotFound()
+		return
+	}
+
+	if err := models.ConvertOrgMemberToExternal(ctx.Org.Organization, member.ID); err != nil {
+		if models.IsErrOrgOwnerNotConvertible(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "ConvertOrgMemberToExternal", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// RemoveExternalCollaborator remove an external collaborator from all repositories of an organization
+func RemoveExternalCollaborator(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/external_collaborators/{username} organization orgRemoveExternalCollaborator
+	// ---
+	// summary: Remove an external collaborator from all repositories of an organization
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: username
+	//   in: path
+	//   description: username of the external collaborator
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     description: external collaborator removed
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	collaborator := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.RemoveOrgExternalCollaborator(ctx.Org.Organization, collaborator.ID); err != nil {
+		if models.IsErrNotExternalCollaborator(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "RemoveOrgExternalCollaborator", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
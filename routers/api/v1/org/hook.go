@@ -84,6 +84,126 @@ func GetHook(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, convert.ToHook(org.HomeLink(), hook))
 }
 
+// ListHookDeliveries list the deliveries of an organization's hook
+func ListHookDeliveries(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/hooks/{id}/deliveries organization orgListHookDeliveries
+	// ---
+	// summary: List the deliveries of a hook, latest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDeliveryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetOrgHook(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.ListHookDeliveries(ctx, hook)
+}
+
+// GetHookDelivery get a delivery of an organization's hook
+func GetHookDelivery(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/hooks/{id}/deliveries/{delivery_id} organization orgGetHookDelivery
+	// ---
+	// summary: Get a delivery of a hook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery_id
+	//   in: path
+	//   description: id of the delivery
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetOrgHook(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	t, err := utils.GetHookDelivery(ctx, hook)
+	if err != nil {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToHookDelivery(t))
+}
+
+// RedeliverHookDelivery redeliver a delivery of an organization's hook
+func RedeliverHookDelivery(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/hooks/{id}/deliveries/{delivery_id}/redeliver organization orgRedeliverHookDelivery
+	// ---
+	// summary: Deliver the payload of a past delivery again and wait for the response
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery_id
+	//   in: path
+	//   description: id of the delivery
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetOrgHook(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.RedeliverHookDelivery(ctx, hook)
+}
+
 // CreateHook create a hook for an organization
 func CreateHook(ctx *context.APIContext, form api.CreateHookOption) {
 	// swagger:operation POST /orgs/{org}/hooks/ organization orgCreateHook
@@ -90,11 +90,17 @@ func CreateLabel(ctx *context.APIContext, form api.CreateLabelOption) {
 		Color:       form.Color,
 		OrgID:       ctx.Org.Organization.ID,
 		Description: form.Description,
+		IsQuestion:  form.IsQuestion,
+		SyncToRepos: form.SyncToRepos,
 	}
 	if err := models.NewLabel(label); err != nil {
 		ctx.Error(http.StatusInternalServerError, "NewLabel", err)
 		return
 	}
+	if _, err := models.SyncOrgLabel(label); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SyncOrgLabel", err)
+		return
+	}
 	ctx.JSON(http.StatusCreated, convert.ToLabel(label))
 }
 
@@ -200,10 +206,20 @@ func EditLabel(ctx *context.APIContext, form api.EditLabelOption) {
 	if form.Description != nil {
 		label.Description = *form.Description
 	}
+	if form.IsQuestion != nil {
+		label.IsQuestion = *form.IsQuestion
+	}
+	if form.SyncToRepos != nil {
+		label.SyncToRepos = *form.SyncToRepos
+	}
 	if err := models.UpdateLabel(label); err != nil {
 		ctx.ServerError("UpdateLabel", err)
 		return
 	}
+	if _, err := models.SyncOrgLabel(label); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SyncOrgLabel", err)
+		return
+	}
 	ctx.JSON(http.StatusOK, convert.ToLabel(label))
 }
 
@@ -235,3 +251,31 @@ func DeleteLabel(ctx *context.APIContext) {
 
 	ctx.Status(http.StatusNoContent)
 }
+
+// SyncLabels synchronize the labels of an organization to its repositories
+func SyncLabels(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/labels/sync organization orgSyncLabels
+	// ---
+	// summary: Synchronize the labels of an organization to its repositories
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/LabelSyncResult"
+
+	res, err := models.SyncOrgLabels(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SyncOrgLabels", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &api.LabelSyncResult{
+		Synced:    res.Synced,
+		Conflicts: res.Conflicts,
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 )
@@ -302,3 +303,59 @@ func DeleteMember(ctx *context.APIContext) {
 	}
 	ctx.Status(http.StatusNoContent)
 }
+
+// EditMember edit the membership of a member of an organization
+func EditMember(ctx *context.APIContext, form api.EditOrgMembershipOption) {
+	// swagger:operation PATCH /orgs/{org}/members/{username} organization orgEditMember
+	// ---
+	// summary: Edit the membership of a member of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: username
+	//   in: path
+	//   description: username of the member
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditOrgMembershipOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgMembership"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	member := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	ou, err := models.GetOrgUser(ctx.Org.Organization.ID, member.ID)
+	if err != nil {
+		if models.IsErrOrgUserNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetOrgUser", err)
+		}
+		return
+	}
+
+	if form.RemoveExpiration {
+		ou.ExpiresUnix = 0
+	} else if form.ExpiresAt != nil {
+		ou.ExpiresUnix = timeutil.TimeStamp(form.ExpiresAt.Unix())
+	}
+	if err := models.ChangeOrgUserExpiration(ou.OrgID, ou.UID, ou.ExpiresUnix); err != nil {
+		ctx.Error(http.StatusInternalServerError, "ChangeOrgUserExpiration", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToOrgMembership(ou, member, ctx.IsSigned, ctx.User.IsAdmin))
+}
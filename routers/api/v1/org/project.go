@@ -0,0 +1,782 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListProjects list the projects of an organization
+func ListProjects(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/projects organization orgListProjects
+	// ---
+	// summary: List an organization's projects
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: Project state, Recognised values are open, closed and all. Defaults to "open"
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectList"
+
+	projects, err := models.GetProjectsByOrgID(ctx.Org.Organization.ID, api.StateType(ctx.Query("state")), utils.GetListOptions(ctx))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProjectsByOrgID", err)
+		return
+	}
+
+	apiProjects := make([]*api.Project, len(projects))
+	for i := range projects {
+		apiProjects[i] = convert.ToAPIProject(projects[i])
+	}
+	ctx.JSON(http.StatusOK, &apiProjects)
+}
+
+// getProject returns the project of the organization in the path, it
+// responds with not found if it does not exist
+func getProject(ctx *context.APIContext) *models.Project {
+	p, err := models.GetProjectByOrgID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrProjectNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetProjectByOrgID", err)
+		}
+		return nil
+	}
+	return p
+}
+
+// GetProject get a project of an organization
+func GetProject(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/projects/{id} organization orgGetProject
+	// ---
+	// summary: Get a project
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToAPIProject(p))
+}
+
+// CreateProject create a project for an organization
+func CreateProject(ctx *context.APIContext, form api.CreateProjectOption) {
+	// swagger:operation POST /orgs/{org}/projects organization orgCreateProject
+	// ---
+	// summary: Create a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Project"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	p := &models.Project{
+		OwnerID:     ctx.Org.Organization.ID,
+		CreatorID:   ctx.User.ID,
+		Title:       form.Title,
+		Description: form.Description,
+	}
+	if err := models.NewProject(p); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewProject", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToAPIProject(p))
+}
+
+// EditProject modify a project of an organization
+func EditProject(ctx *context.APIContext, form api.EditProjectOption) {
+	// swagger:operation PATCH /orgs/{org}/projects/{id} organization orgEditProject
+	// ---
+	// summary: Update a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditProjectOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Title != nil {
+		p.Title = *form.Title
+	}
+	if form.Description != nil {
+		p.Description = *form.Description
+	}
+	if form.State != nil {
+		p.IsClosed = api.StateClosed == api.StateType(*form.State)
+	}
+	if form.InProgressBoardID != nil {
+		p.InProgressBoardID = *form.InProgressBoardID
+	}
+	if form.DoneBoardID != nil {
+		p.DoneBoardID = *form.DoneBoardID
+	}
+	if form.ArchiveDoneAfterDays != nil {
+		p.ArchiveDoneAfterDays = *form.ArchiveDoneAfterDays
+	}
+	if err := models.UpdateProject(p); err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "UpdateProject", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateProject", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToAPIProject(p))
+}
+
+// DeleteProject delete a project of an organization
+func DeleteProject(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/projects/{id} organization orgDeleteProject
+	// ---
+	// summary: Delete a project with its columns and rules, its issues are kept
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteProject(p); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteProject", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListProjectBoards list the columns of a project
+func ListProjectBoards(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/projects/{id}/boards organization orgListProjectBoards
+	// ---
+	// summary: List the columns of a project
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoardList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	boards, err := models.GetProjectBoards(p.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProjectBoards", err)
+		return
+	}
+
+	apiBoards := make([]*api.ProjectBoard, len(boards))
+	for i := range boards {
+		apiBoards[i] = convert.ToAPIProjectBoard(boards[i])
+	}
+	ctx.JSON(http.StatusOK, &apiBoards)
+}
+
+// CreateProjectBoard add a column to a project
+func CreateProjectBoard(ctx *context.APIContext, form api.CreateProjectBoardOption) {
+	// swagger:operation POST /orgs/{org}/projects/{id}/boards organization orgCreateProjectBoard
+	// ---
+	// summary: Add a column to a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectBoardOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ProjectBoard"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	board := &models.ProjectBoard{
+		ProjectID: p.ID,
+		Title:     form.Title,
+	}
+	if err := models.NewProjectBoard(board); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewProjectBoard", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToAPIProjectBoard(board))
+}
+
+// DeleteProjectBoard delete a column of a project
+func DeleteProjectBoard(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/projects/{id}/boards/{board} organization orgDeleteProjectBoard
+	// ---
+	// summary: Delete a column of a project, its issues are moved to the uncategorized column
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board
+	//   in: path
+	//   description: id of the column to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	board, err := models.GetProjectBoard(p.ID, ctx.ParamsInt64(":board"))
+	if err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetProjectBoard", err)
+		}
+		return
+	}
+
+	if err := models.DeleteProjectBoard(board); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteProjectBoard", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListProjectIssues list the issues of a project
+func ListProjectIssues(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/projects/{id}/issues organization orgListProjectIssues
+	// ---
+	// summary: List the issues of a project the user is allowed to see
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: repo
+	//   in: query
+	//   description: id of the repository to filter the issues by
+	//   type: integer
+	//   format: int64
+	// - name: board
+	//   in: query
+	//   description: id of the column to filter the issues by, 0 for uncategorized issues
+	//   type: integer
+	//   format: int64
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectIssueList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	pis, err := models.GetProjectIssues(p, ctx.QueryInt64("repo"), ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProjectIssues", err)
+		return
+	}
+
+	_, filterBoard := ctx.Req.URL.Query()["board"]
+	boardID := ctx.QueryInt64("board")
+	apiIssues := make([]*api.ProjectIssue, 0, len(pis))
+	for _, pi := range pis {
+		if filterBoard && pi.ProjectBoardID != boardID {
+			continue
+		}
+		apiIssues = append(apiIssues, convert.ToAPIProjectIssue(pi))
+	}
+	ctx.JSON(http.StatusOK, &apiIssues)
+}
+
+// handleProjectQueryError responds to the errors of adding issues or rules to
+// a project
+func handleProjectQueryError(ctx *context.APIContext, funcName string, err error) {
+	switch {
+	case models.IsErrProjectQueryEmpty(err),
+		models.IsErrProjectIssueOutOfScope(err),
+		models.IsErrRepoNotExist(err),
+		models.IsErrProjectBoardNotExist(err):
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+	default:
+		ctx.Error(http.StatusInternalServerError, funcName, err)
+	}
+}
+
+// AddProjectIssues add the issues matching a query to a project
+func AddProjectIssues(ctx *context.APIContext, form api.AddProjectIssuesOption) {
+	// swagger:operation POST /orgs/{org}/projects/{id}/issues organization orgAddProjectIssues
+	// ---
+	// summary: Add the issues of the repositories of the organization matching a label or a keyword to a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/AddProjectIssuesOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/IssueList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	issues, err := models.AddProjectIssuesByQuery(p, form.BoardID, &models.ProjectIssueQuery{
+		RepoID:  form.RepoID,
+		Label:   form.Label,
+		Keyword: form.Keyword,
+		State:   api.StateType(form.State),
+	}, ctx.User)
+	if err != nil {
+		handleProjectQueryError(ctx, "AddProjectIssuesByQuery", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToAPIIssueList(issues))
+}
+
+// MoveProjectIssue move an issue of a project to another column
+func MoveProjectIssue(ctx *context.APIContext, form api.MoveProjectIssueOption) {
+	// swagger:operation PATCH /orgs/{org}/projects/{id}/issues/{issue} organization orgMoveProjectIssue
+	// ---
+	// summary: Move an issue of a project to another column
+	// consumes:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: issue
+	//   in: path
+	//   description: id of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/MoveProjectIssueOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.MoveProjectIssue(p, ctx.ParamsInt64(":issue"), form.BoardID); err != nil {
+		switch {
+		case models.IsErrProjectIssueNotExist(err):
+			ctx.NotFound()
+		case models.IsErrProjectBoardNotExist(err):
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "MoveProjectIssue", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// RemoveProjectIssue remove an issue from a project
+func RemoveProjectIssue(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/projects/{id}/issues/{issue} organization orgRemoveProjectIssue
+	// ---
+	// summary: Remove an issue from a project, the issue is kept
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: issue
+	//   in: path
+	//   description: id of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.RemoveProjectIssue(p, ctx.ParamsInt64(":issue")); err != nil {
+		if models.IsErrProjectIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "RemoveProjectIssue", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListProjectRules list the rules of a project
+func ListProjectRules(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/projects/{id}/rules organization orgListProjectRules
+	// ---
+	// summary: List the rules adding issues to a project automatically
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectRuleList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	rules, err := models.GetProjectRules(p.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProjectRules", err)
+		return
+	}
+
+	apiRules := make([]*api.ProjectRule, len(rules))
+	for i := range rules {
+		apiRules[i] = convert.ToAPIProjectRule(rules[i])
+	}
+	ctx.JSON(http.StatusOK, &apiRules)
+}
+
+// CreateProjectRule add a rule to a project
+func CreateProjectRule(ctx *context.APIContext, form api.CreateProjectRuleOption) {
+	// swagger:operation POST /orgs/{org}/projects/{id}/rules organization orgCreateProjectRule
+	// ---
+	// summary: Add a rule adding the new issues matching a label or a keyword to a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectRuleOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ProjectRule"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	rule := &models.ProjectRule{
+		ProjectBoardID: form.BoardID,
+		RepoID:         form.RepoID,
+		Label:          form.Label,
+		Keyword:        form.Keyword,
+	}
+	if err := models.NewProjectRule(p, rule); err != nil {
+		handleProjectQueryError(ctx, "NewProjectRule", err)
+		return
+	}
+	if form.ApplyExisting {
+		if _, err := models.AddProjectIssuesByQuery(p, rule.ProjectBoardID, rule.Query(), ctx.User); err != nil {
+			ctx.Error(http.StatusInternalServerError, "AddProjectIssuesByQuery", err)
+			return
+		}
+	}
+	ctx.JSON(http.StatusCreated, convert.ToAPIProjectRule(rule))
+}
+
+// DeleteProjectRule delete a rule of a project
+func DeleteProjectRule(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/projects/{id}/rules/{rule} organization orgDeleteProjectRule
+	// ---
+	// summary: Delete a rule of a project, the issues it added are kept
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: rule
+	//   in: path
+	//   description: id of the rule to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	rule, err := models.GetProjectRule(p.ID, ctx.ParamsInt64(":rule"))
+	if err != nil {
+		if models.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetProjectRule", err)
+		}
+		return
+	}
+
+	if err := models.DeleteProjectRule(rule); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteProjectRule", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,259 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListRoles list the custom roles of an organization
+func ListRoles(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/roles organization orgListRoles
+	// ---
+	// summary: List an organization's custom roles
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgRoleList"
+
+	roles, err := models.GetOrgRoles(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetOrgRoles", err)
+		return
+	}
+
+	apiRoles := make([]*api.OrgRole, len(roles))
+	for i := range roles {
+		apiRoles[i] = convert.ToOrgRole(roles[i])
+	}
+	ctx.JSON(http.StatusOK, apiRoles)
+}
+
+// getRoleByParams returns the custom role of the organization in the path,
+// it responds with not found if it does not exist
+func getRoleByParams(ctx *context.APIContext) *models.OrgRole {
+	r, err := models.GetOrgRoleByID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrOrgRoleNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetOrgRoleByID", err)
+		}
+		return nil
+	}
+	return r
+}
+
+// GetRole get a custom role of an organization
+func GetRole(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/roles/{id} organization orgGetRole
+	// ---
+	// summary: Get a custom role
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the role
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgRole"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	r := getRoleByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToOrgRole(r))
+}
+
+// toRolePermissions converts the permissions of the options, it responds
+// with a validation error if a unit or a permission is unknown
+func toRolePermissions(ctx *context.APIContext, permissions map[string]string) []*models.OrgRolePermission {
+	result := make([]*models.OrgRolePermission, 0, len(permissions))
+	for key, mode := range permissions {
+		unitTypes := models.FindUnitTypes(key)
+		if len(unitTypes) == 0 || (mode != "read" && mode != "write") {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("invalid permission %s: %s", key, mode))
+			return nil
+		}
+		result = append(result, &models.OrgRolePermission{
+			Type:       unitTypes[0],
+			AccessMode: models.ParseAccessMode(mode),
+		})
+	}
+	return result
+}
+
+// saveRole creates or updates the custom role and responds with it
+func saveRole(ctx *context.APIContext, r *models.OrgRole, status int) {
+	var err error
+	if r.ID == 0 {
+		err = models.NewOrgRole(r)
+	} else {
+		err = models.UpdateOrgRole(r)
+	}
+	if err != nil {
+		if models.IsErrOrgRoleAlreadyExist(err) || models.IsErrInvalidOrgRolePermission(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SaveOrgRole", err)
+		}
+		return
+	}
+	ctx.JSON(status, convert.ToOrgRole(r))
+}
+
+// CreateRole create a custom role in an organization
+func CreateRole(ctx *context.APIContext, form api.CreateOrgRoleOption) {
+	// swagger:operation POST /orgs/{org}/roles organization orgCreateRole
+	// ---
+	// summary: Create a custom role
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateOrgRoleOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/OrgRole"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	permissions := toRolePermissions(ctx, form.Permissions)
+	if ctx.Written() {
+		return
+	}
+	saveRole(ctx, &models.OrgRole{
+		OrgID:       ctx.Org.Organization.ID,
+		Name:        form.Name,
+		Description: form.Description,
+		Permissions: permissions,
+	}, http.StatusCreated)
+}
+
+// EditRole edit a custom role of an organization
+func EditRole(ctx *context.APIContext, form api.EditOrgRoleOption) {
+	// swagger:operation PATCH /orgs/{org}/roles/{id} organization orgEditRole
+	// ---
+	// summary: Edit a custom role, the permissions are applied to the teams of the role
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the role
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditOrgRoleOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgRole"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	r := getRoleByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Name != nil {
+		r.Name = *form.Name
+	}
+	if form.Description != nil {
+		r.Description = *form.Description
+	}
+	if form.Permissions != nil {
+		r.Permissions = toRolePermissions(ctx, form.Permissions)
+		if ctx.Written() {
+			return
+		}
+	}
+	saveRole(ctx, r, http.StatusOK)
+}
+
+// DeleteRole delete a custom role of an organization
+func DeleteRole(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/roles/{id} organization orgDeleteRole
+	// ---
+	// summary: Delete a custom role which is not assigned to any team
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the role
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     description: role deleted
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     description: role is assigned to teams
+
+	r := getRoleByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.DeleteOrgRole(r); err != nil {
+		if models.IsErrOrgRoleInUse(err) {
+			ctx.Error(http.StatusConflict, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteOrgRole", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -162,6 +162,15 @@ func CreateTeam(ctx *context.APIContext, form api.CreateTeamOption) {
 		IncludesAllRepositories: form.IncludesAllRepositories,
 		CanCreateOrgRepo:        form.CanCreateOrgRepo,
 		Authorize:               models.ParseAccessMode(form.Permission),
+		ReviewAssignCount:       form.ReviewAssignCount,
+		ParentID:                form.ParentID,
+		RoleID:                  form.RoleID,
+	}
+
+	var ok bool
+	if team.ReviewAssignAlgorithm, ok = models.ParseReviewAssignAlgorithm(form.ReviewAssignAlgorithm); !ok {
+		ctx.Error(http.StatusUnprocessableEntity, "", "unknown review_assign_algorithm")
+		return
 	}
 
 	unitTypes := models.FindUnitTypes(form.Units...)
@@ -178,7 +187,7 @@ func CreateTeam(ctx *context.APIContext, form api.CreateTeamOption) {
 	}
 
 	if err := models.NewTeam(team); err != nil {
-		if models.IsErrTeamAlreadyExist(err) {
+		if models.IsErrTeamAlreadyExist(err) || models.IsErrInvalidTeamParent(err) || models.IsErrOrgRoleNotExist(err) {
 			ctx.Error(http.StatusUnprocessableEntity, "", err)
 		} else {
 			ctx.Error(http.StatusInternalServerError, "NewTeam", err)
@@ -211,6 +220,8 @@ func EditTeam(ctx *context.APIContext, form api.EditTeamOption) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/Team"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	team := ctx.Org.Team
 	if err := team.GetUnits(); err != nil {
@@ -222,6 +233,19 @@ func EditTeam(ctx *context.APIContext, form api.EditTeamOption) {
 		team.CanCreateOrgRepo = *form.CanCreateOrgRepo
 	}
 
+	if form.ReviewAssignAlgorithm != nil {
+		algorithm, ok := models.ParseReviewAssignAlgorithm(*form.ReviewAssignAlgorithm)
+		if !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", "unknown review_assign_algorithm")
+			return
+		}
+		team.ReviewAssignAlgorithm = algorithm
+	}
+
+	if form.ReviewAssignCount != nil {
+		team.ReviewAssignCount = *form.ReviewAssignCount
+	}
+
 	if len(form.Name) > 0 {
 		team.Name = form.Name
 	}
@@ -261,6 +285,28 @@ func EditTeam(ctx *context.APIContext, form api.EditTeamOption) {
 		}
 	}
 
+	if form.ParentID != nil && !team.IsOwnerTeam() {
+		if err := models.ChangeTeamParent(team, *form.ParentID); err != nil {
+			if models.IsErrTeamParentCycle(err) || models.IsErrInvalidTeamParent(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "ChangeTeamParent", err)
+			}
+			return
+		}
+	}
+
+	if form.RoleID != nil && !team.IsOwnerTeam() {
+		if err := models.ChangeTeamRole(team, *form.RoleID); err != nil {
+			if models.IsErrOrgRoleNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "ChangeTeamRole", err)
+			}
+			return
+		}
+	}
+
 	if err := models.UpdateTeam(team, isAuthChanged, isIncludeAllChanged); err != nil {
 		ctx.Error(http.StatusInternalServerError, "EditTeam", err)
 		return
@@ -268,6 +314,41 @@ func EditTeam(ctx *context.APIContext, form api.EditTeamOption) {
 	ctx.JSON(http.StatusOK, convert.ToTeam(team))
 }
 
+// ListChildTeams api for list the child teams of a team
+func ListChildTeams(ctx *context.APIContext) {
+	// swagger:operation GET /teams/{id}/teams organization orgListChildTeams
+	// ---
+	// summary: List a team's child teams
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the team
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TeamList"
+
+	teams, err := ctx.Org.Team.GetChildTeams()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetChildTeams", err)
+		return
+	}
+
+	apiTeams := make([]*api.Team, len(teams))
+	for i := range teams {
+		if err := teams[i].GetUnits(); err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetUnits", err)
+			return
+		}
+		apiTeams[i] = convert.ToTeam(teams[i])
+	}
+	ctx.JSON(http.StatusOK, apiTeams)
+}
+
 // DeleteTeam api for delete a team
 func DeleteTeam(ctx *context.APIContext) {
 	// swagger:operation DELETE /teams/{id} organization orgDeleteTeam
@@ -410,7 +491,11 @@ func AddTeamMember(ctx *context.APIContext) {
 		return
 	}
 	if err := ctx.Org.Team.AddMember(u.ID); err != nil {
-		ctx.Error(http.StatusInternalServerError, "AddMember", err)
+		if models.IsErrBotOutOfScope(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "AddMember", err)
+		}
 		return
 	}
 	ctx.Status(http.StatusNoContent)
@@ -0,0 +1,181 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/validation"
+)
+
+// ListTopics list the topics of the repositories of an organization
+func ListTopics(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/topics organization orgListTopics
+	// ---
+	// summary: List the topics of an organization's repositories with the organization's curation
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgTopicList"
+
+	topics, err := models.GetOrgTopics(ctx.Org.Organization.ID, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetOrgTopics", err)
+		return
+	}
+
+	apiTopics := make([]*api.OrgTopic, len(topics))
+	for i := range topics {
+		apiTopics[i] = convert.ToOrgTopic(topics[i])
+	}
+	ctx.JSON(http.StatusOK, apiTopics)
+}
+
+// getTopicByName loads a topic by its name, it writes the response if the topic does not exist
+func getTopicByName(ctx *context.APIContext, name string, notExistStatus int) *models.Topic {
+	topic, err := models.GetTopicByName(strings.ToLower(strings.TrimSpace(name)))
+	if err != nil {
+		if models.IsErrTopicNotExist(err) {
+			ctx.Error(notExistStatus, "GetTopicByName", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetTopicByName", err)
+		}
+		return nil
+	}
+	return topic
+}
+
+// EditTopic edit the curation of a topic by an organization
+func EditTopic(ctx *context.APIContext, form api.EditTopicOption) {
+	// swagger:operation PATCH /orgs/{org}/topics/{topic} organization orgEditTopic
+	// ---
+	// summary: Edit the description, logo and featured state of a topic on the organization's pages
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: topic
+	//   in: path
+	//   description: name of the topic
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditTopicOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgTopic"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	topic := getTopicByName(ctx, ctx.Params(":topic"), http.StatusNotFound)
+	if ctx.Written() {
+		return
+	}
+	ot, err := models.GetOrgTopic(ctx.Org.Organization.ID, topic)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetOrgTopic", err)
+		return
+	}
+
+	if form.Description != nil {
+		ot.Description = strings.TrimSpace(*form.Description)
+	}
+	if form.Logo != nil {
+		logo := strings.TrimSpace(*form.Logo)
+		if logo != "" && !validation.IsValidURL(logo) {
+			ctx.Error(http.StatusUnprocessableEntity, "IsValidURL", fmt.Errorf("invalid logo URL: %s", logo))
+			return
+		}
+		ot.Logo = logo
+	}
+	if form.Featured != nil {
+		ot.IsFeatured = *form.Featured
+	}
+	if err := models.SaveOrgTopic(ot); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SaveOrgTopic", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToOrgTopic(ot))
+}
+
+// MergeTopic merge a topic into another one on the repositories of an organization
+func MergeTopic(ctx *context.APIContext, form api.MergeTopicOption) {
+	// swagger:operation POST /orgs/{org}/topics/{topic}/merge organization orgMergeTopic
+	// ---
+	// summary: Replace a topic with another one on all the organization's repositories
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: topic
+	//   in: path
+	//   description: name of the topic to merge
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/MergeTopicOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	from := getTopicByName(ctx, ctx.Params(":topic"), http.StatusNotFound)
+	if ctx.Written() {
+		return
+	}
+	to := getTopicByName(ctx, form.Target, http.StatusUnprocessableEntity)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.MergeOrgTopics(ctx.Org.Organization.ID, from, to); err != nil {
+		if models.IsErrTopicMergeSelf(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "MergeOrgTopics", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "MergeOrgTopics", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
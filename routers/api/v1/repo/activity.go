@@ -0,0 +1,126 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// GetActivityHeatmap returns the daily contributions to a repository
+func GetActivityHeatmap(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/activity/heatmap repository repoGetActivityHeatmap
+	// ---
+	// summary: Get the daily contributions to a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: since
+	//   in: query
+	//   description: count the contributions since this time, the last year by default
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: count the contributions before this time
+	//   type: string
+	//   format: date-time
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/UserHeatmapData"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts, err := utils.GetActivityHeatmapOptions(ctx)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetActivityHeatmapOptions", err)
+		return
+	}
+	opts.RepoID = ctx.Repo.Repository.ID
+
+	heatmap, err := models.GetActivityHeatmapData(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetActivityHeatmapData", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, heatmap)
+}
+
+// ListActivityContributors returns the users with the most contributions to a repository
+func ListActivityContributors(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/activity/contributors repository repoListActivityContributors
+	// ---
+	// summary: List the users with the most contributions to a repository, most active first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: since
+	//   in: query
+	//   description: count the contributions since this time, the last year by default
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: count the contributions before this time
+	//   type: string
+	//   format: date-time
+	// - name: limit
+	//   in: query
+	//   description: number of contributors to return
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActivityContributorList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts, err := utils.GetActivityHeatmapOptions(ctx)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetActivityHeatmapOptions", err)
+		return
+	}
+	opts.RepoID = ctx.Repo.Repository.ID
+
+	contributors, err := models.GetActivityTopContributors(opts, convert.ToCorrectPageSize(ctx.QueryInt("limit")))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetActivityTopContributors", err)
+		return
+	}
+
+	apiContributors := make([]*api.ActivityContributor, 0, len(contributors))
+	for _, contributor := range contributors {
+		apiContributors = append(apiContributors, convert.ToActivityContributor(contributor, ctx.IsSigned, ctx.User != nil && ctx.User.IsAdmin))
+	}
+	ctx.JSON(http.StatusOK, apiContributors)
+}
@@ -0,0 +1,197 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+)
+
+const (
+	autocompleteDefaultLimit = 10
+	autocompleteMaxLimit     = 50
+)
+
+// getAutocompleteLimit returns the number of suggestions asked by the query
+func getAutocompleteLimit(ctx *context.APIContext) int {
+	limit := ctx.QueryInt("limit")
+	if limit <= 0 {
+		return autocompleteDefaultLimit
+	}
+	if limit > autocompleteMaxLimit {
+		return autocompleteMaxLimit
+	}
+	return limit
+}
+
+// AutocompleteMentions suggests the users to mention in the issues of a repository
+func AutocompleteMentions(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/autocomplete/mentions repository repoAutocompleteMentions
+	// ---
+	// summary: Suggest the users to mention whose username starts with the query, they are the owner and the users with access to the repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: q
+	//   in: query
+	//   description: start of the username
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: maximum number of suggestions, 10 by default and 50 at most
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AutocompleteUserList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	users, err := models.SearchMentionableUsers(ctx.Repo.Repository, strings.TrimPrefix(strings.TrimSpace(ctx.Query("q")), "@"), getAutocompleteLimit(ctx))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SearchMentionableUsers", err)
+		return
+	}
+
+	suggestions := make([]*api.AutocompleteUser, 0, len(users))
+	for _, u := range users {
+		suggestions = append(suggestions, &api.AutocompleteUser{
+			Username:  u.Name,
+			FullName:  u.FullName,
+			AvatarURL: u.AvatarLink(),
+		})
+	}
+	ctx.JSON(http.StatusOK, suggestions)
+}
+
+// AutocompleteIssues suggests the issues and pull requests to reference in a repository
+func AutocompleteIssues(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/autocomplete/issues repository repoAutocompleteIssues
+	// ---
+	// summary: Suggest the issues and pull requests to reference
+	// description: A query of digits suggests the issues whose number starts with them, other queries
+	//   search the titles and contents with the issue indexer. An empty query suggests the issues updated last.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: q
+	//   in: query
+	//   description: start of the number, or keyword
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: maximum number of suggestions, 10 by default and 50 at most
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AutocompleteIssueList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	var (
+		keyword = strings.TrimPrefix(strings.TrimSpace(ctx.Query("q")), "#")
+		limit   = getAutocompleteLimit(ctx)
+		isPull  util.OptionalBool
+	)
+	canReadIssues := ctx.Repo.CanRead(models.UnitTypeIssues)
+	canReadPulls := ctx.Repo.Repository.CanEnablePulls() && ctx.Repo.CanRead(models.UnitTypePullRequests)
+	if !canReadIssues {
+		isPull = util.OptionalBoolTrue
+	} else if !canReadPulls {
+		isPull = util.OptionalBoolFalse
+	}
+
+	var (
+		issues []*models.Issue
+		err    error
+	)
+	if isDigits(keyword) {
+		issues, err = models.SearchIssuesByIndexPrefix(ctx.Repo.Repository.ID, keyword, isPull, limit)
+	} else {
+		issues, err = searchIssuesByKeyword(ctx.Repo.Repository.ID, keyword, isPull, limit)
+	}
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SearchIssues", err)
+		return
+	}
+
+	suggestions := make([]*api.AutocompleteIssue, 0, len(issues))
+	for _, issue := range issues {
+		issue.Repo = ctx.Repo.Repository
+		suggestions = append(suggestions, &api.AutocompleteIssue{
+			Index:   issue.Index,
+			Title:   issue.Title,
+			IsPull:  issue.IsPull,
+			State:   issue.State(),
+			HTMLURL: issue.HTMLURL(),
+		})
+	}
+	ctx.JSON(http.StatusOK, suggestions)
+}
+
+// isDigits checks that the keyword is empty or only has digits, so that it is the start of an issue number
+func isDigits(keyword string) bool {
+	for _, r := range keyword {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// searchIssuesByKeyword returns the issues of the repository matching the keyword, in the order
+// of the issue indexer
+func searchIssuesByKeyword(repoID int64, keyword string, isPull util.OptionalBool, limit int) ([]*models.Issue, error) {
+	issueIDs, err := issue_indexer.SearchIssuesByKeyword([]int64{repoID}, keyword)
+	if err != nil {
+		return nil, err
+	}
+	found, err := models.GetIssuesByIDs(issueIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*models.Issue, len(found))
+	for _, issue := range found {
+		byID[issue.ID] = issue
+	}
+
+	issues := make([]*models.Issue, 0, limit)
+	for _, id := range issueIDs {
+		issue, ok := byID[id]
+		if !ok || (!isPull.IsNone() && issue.IsPull != isPull.IsTrue()) {
+			continue
+		}
+		issues = append(issues, issue)
+		if len(issues) == limit {
+			break
+		}
+	}
+	return issues, nil
+}
@@ -0,0 +1,271 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListAutoLinkRules list the auto-link rules of a repository
+func ListAutoLinkRules(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/autolinks repository repoListAutoLinkRules
+	// ---
+	// summary: List the auto-link rules of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: effective
+	//   in: query
+	//   description: if true, list the rules applied in the repository by precedence, including the ones of its organization
+	//   type: boolean
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AutoLinkRuleList"
+
+	var (
+		rules []*models.AutoLinkRule
+		err   error
+	)
+	if ctx.QueryBool("effective") {
+		rules, err = models.GetEffectiveAutoLinkRules(ctx.Repo.Repository)
+	} else {
+		rules, err = models.GetRepoAutoLinkRules(ctx.Repo.Repository.ID)
+	}
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetAutoLinkRules", err)
+		return
+	}
+
+	apiRules := make([]*api.AutoLinkRule, len(rules))
+	for i := range rules {
+		apiRules[i] = convert.ToAutoLinkRule(rules[i])
+	}
+	ctx.JSON(http.StatusOK, apiRules)
+}
+
+// GetAutoLinkRule get an auto-link rule of a repository
+func GetAutoLinkRule(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/autolinks/{id} repository repoGetAutoLinkRule
+	// ---
+	// summary: Get an auto-link rule of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the rule to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AutoLinkRule"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	rule := getAutoLinkRule(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToAutoLinkRule(rule))
+}
+
+// CreateAutoLinkRule create an auto-link rule for a repository
+func CreateAutoLinkRule(ctx *context.APIContext, form api.CreateAutoLinkRuleOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/autolinks repository repoCreateAutoLinkRule
+	// ---
+	// summary: Create an auto-link rule for a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateAutoLinkRuleOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/AutoLinkRule"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	rule := &models.AutoLinkRule{
+		RepoID:  ctx.Repo.Repository.ID,
+		Pattern: form.Pattern,
+		URL:     form.URL,
+		Sort:    form.Sort,
+	}
+	if err := models.NewAutoLinkRule(rule); err != nil {
+		if models.IsErrAutoLinkRuleInvalid(err) || models.IsErrAutoLinkRulesLimit(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "NewAutoLinkRule", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "NewAutoLinkRule", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToAutoLinkRule(rule))
+}
+
+// EditAutoLinkRule modify an auto-link rule of a repository
+func EditAutoLinkRule(ctx *context.APIContext, form api.EditAutoLinkRuleOption) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/autolinks/{id} repository repoEditAutoLinkRule
+	// ---
+	// summary: Edit an auto-link rule of a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the rule to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditAutoLinkRuleOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AutoLinkRule"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	rule := getAutoLinkRule(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Pattern != nil {
+		rule.Pattern = *form.Pattern
+	}
+	if form.URL != nil {
+		rule.URL = *form.URL
+	}
+	if form.Sort != nil {
+		rule.Sort = *form.Sort
+	}
+	if err := models.UpdateAutoLinkRule(rule); err != nil {
+		if models.IsErrAutoLinkRuleInvalid(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "UpdateAutoLinkRule", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateAutoLinkRule", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToAutoLinkRule(rule))
+}
+
+// DeleteAutoLinkRule delete an auto-link rule of a repository
+func DeleteAutoLinkRule(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/autolinks/{id} repository repoDeleteAutoLinkRule
+	// ---
+	// summary: Delete an auto-link rule of a repository
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the rule to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	rule := getAutoLinkRule(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteRepoAutoLinkRule(ctx.Repo.Repository.ID, rule.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteRepoAutoLinkRule", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func getAutoLinkRule(ctx *context.APIContext) *models.AutoLinkRule {
+	rule, err := models.GetRepoAutoLinkRule(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrAutoLinkRuleNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRepoAutoLinkRule", err)
+		}
+		return nil
+	}
+	return rule
+}
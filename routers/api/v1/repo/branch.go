@@ -322,6 +322,101 @@ func ListBranches(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, &apiBranches)
 }
 
+// ListBranchDivergences list the branches of a repository with how they diverge from the default branch
+func ListBranchDivergences(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/branch_divergences repository repoListBranchDivergences
+	// ---
+	// summary: List a repository's branches with their commits ahead and behind the default branch
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: stale
+	//   in: query
+	//   description: only list the stale branches, merged into the default branch or inactive
+	//   type: boolean
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/BranchDivergenceList"
+
+	repo := ctx.Repo.Repository
+	branches, err := repo_module.GetBranches(repo)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetBranches", err)
+		return
+	}
+
+	onlyStale := ctx.QueryBool("stale")
+	apiBranches := make([]*api.BranchDivergence, 0, len(branches))
+	for _, branch := range branches {
+		if branch.Name == repo.DefaultBranch {
+			continue
+		}
+		commit, err := branch.GetCommit()
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+			return
+		}
+		divergence, err := repofiles.CountDivergingCommits(repo, git.BranchPrefix+branch.Name)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "CountDivergingCommits", err)
+			return
+		}
+
+		merged := divergence.Ahead == 0
+		if !merged {
+			pr, err := models.GetLatestPullRequestByHeadInfo(repo.ID, branch.Name)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "GetLatestPullRequestByHeadInfo", err)
+				return
+			}
+			// the branch is merged as long as it has not moved on from the merge
+			if pr != nil && pr.HasMerged && pr.BaseRepoID == repo.ID {
+				pullCommit, err := ctx.Repo.GitRepo.GetRefCommitID(pr.GetGitRefName())
+				if err != nil && !git.IsErrNotExist(err) {
+					ctx.Error(http.StatusInternalServerError, "GetRefCommitID", err)
+					return
+				}
+				merged = err == nil && pullCommit == commit.ID.String()
+			}
+		}
+		inactive := repo_module.IsBranchInactive(commit)
+		if onlyStale && !merged && !inactive {
+			continue
+		}
+
+		protectedBranch, err := models.GetEffectiveProtectedBranch(repo, branch.Name, 0)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetEffectiveProtectedBranch", err)
+			return
+		}
+
+		apiBranches = append(apiBranches, &api.BranchDivergence{
+			Name:          branch.Name,
+			CommitID:      commit.ID.String(),
+			BaseBranch:    repo.DefaultBranch,
+			CommitsAhead:  divergence.Ahead,
+			CommitsBehind: divergence.Behind,
+			Merged:        merged,
+			Inactive:      inactive,
+			Stale:         merged || inactive,
+			Protected:     protectedBranch != nil && protectedBranch.IsProtected(),
+			Updated:       commit.Committer.When,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, &apiBranches)
+}
+
 // GetBranchProtection gets a branch protection
 func GetBranchProtection(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/branch_protections/{name} repository repoGetBranchProtection
@@ -453,6 +548,10 @@ func CreateBranchProtection(ctx *context.APIContext, form api.CreateBranchProtec
 		return
 	}
 
+	if !checkMergeStyles(ctx, form.MergeStyles) {
+		return
+	}
+
 	var requiredApprovals int64
 	if form.RequiredApprovals > 0 {
 		requiredApprovals = form.RequiredApprovals
@@ -530,8 +629,10 @@ func CreateBranchProtection(ctx *context.APIContext, form api.CreateBranchProtec
 		BlockOnRejectedReviews:   form.BlockOnRejectedReviews,
 		DismissStaleApprovals:    form.DismissStaleApprovals,
 		RequireSignedCommits:     form.RequireSignedCommits,
+		SignedCommitsAllowedKeys: form.SignedCommitsAllowedKeys,
 		ProtectedFilePatterns:    form.ProtectedFilePatterns,
 		BlockOnOutdatedBranch:    form.BlockOnOutdatedBranch,
+		MergeStyles:              form.MergeStyles,
 	}
 
 	err = models.UpdateProtectBranch(ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
@@ -663,6 +764,10 @@ func EditBranchProtection(ctx *context.APIContext, form api.EditBranchProtection
 		protectBranch.RequireSignedCommits = *form.RequireSignedCommits
 	}
 
+	if form.SignedCommitsAllowedKeys != nil {
+		protectBranch.SignedCommitsAllowedKeys = *form.SignedCommitsAllowedKeys
+	}
+
 	if form.ProtectedFilePatterns != nil {
 		protectBranch.ProtectedFilePatterns = *form.ProtectedFilePatterns
 	}
@@ -671,6 +776,13 @@ func EditBranchProtection(ctx *context.APIContext, form api.EditBranchProtection
 		protectBranch.BlockOnOutdatedBranch = *form.BlockOnOutdatedBranch
 	}
 
+	if form.MergeStyles != nil {
+		if !checkMergeStyles(ctx, form.MergeStyles) {
+			return
+		}
+		protectBranch.MergeStyles = form.MergeStyles
+	}
+
 	var whitelistUsers []int64
 	if form.PushWhitelistUsernames != nil {
 		whitelistUsers, err = models.GetUserIDsByNames(form.PushWhitelistUsernames, false)
@@ -832,3 +944,15 @@ func DeleteBranchProtection(ctx *context.APIContext) {
 
 	ctx.Status(http.StatusNoContent)
 }
+
+// checkMergeStyles responds with an unprocessable entity error if a merge
+// style of a branch protection is unknown
+func checkMergeStyles(ctx *context.APIContext, styles []string) bool {
+	for _, style := range styles {
+		if !models.IsValidMergeStyle(style) {
+			ctx.Error(http.StatusUnprocessableEntity, "MergeStyles", fmt.Errorf("unknown merge style: %s", style))
+			return false
+		}
+	}
+	return true
+}
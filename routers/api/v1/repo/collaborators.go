@@ -159,7 +159,11 @@ func AddCollaborator(ctx *context.APIContext, form api.AddCollaboratorOption) {
 	}
 
 	if err := ctx.Repo.Repository.AddCollaborator(collaborator); err != nil {
-		ctx.Error(http.StatusInternalServerError, "AddCollaborator", err)
+		if models.IsErrBotOutOfScope(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "AddCollaborator", err)
+		}
 		return
 	}
 
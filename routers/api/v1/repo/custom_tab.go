@@ -0,0 +1,263 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListCustomTabs list the custom tabs of a repository
+func ListCustomTabs(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/tabs repository repoListCustomTabs
+	// ---
+	// summary: List the custom tabs of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoCustomTabList"
+
+	tabs, err := models.GetRepoCustomTabs(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRepoCustomTabs", err)
+		return
+	}
+
+	apiTabs := make([]*api.RepoCustomTab, len(tabs))
+	for i := range tabs {
+		apiTabs[i] = convert.ToRepoCustomTab(tabs[i])
+	}
+	ctx.JSON(http.StatusOK, apiTabs)
+}
+
+// GetCustomTab get a custom tab of a repository
+func GetCustomTab(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/tabs/{id} repository repoGetCustomTab
+	// ---
+	// summary: Get a custom tab of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the tab to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoCustomTab"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	tab := getCustomTab(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToRepoCustomTab(tab))
+}
+
+// CreateCustomTab create a custom tab for a repository
+func CreateCustomTab(ctx *context.APIContext, form api.CreateRepoCustomTabOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/tabs repository repoCreateCustomTab
+	// ---
+	// summary: Create a custom tab for a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateRepoCustomTabOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/RepoCustomTab"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	tab := &models.RepoCustomTab{
+		RepoID: ctx.Repo.Repository.ID,
+		Name:   form.Name,
+		URL:    form.URL,
+		Icon:   form.Icon,
+		Sort:   form.Sort,
+	}
+	if err := models.NewRepoCustomTab(tab); err != nil {
+		if models.IsErrRepoCustomTabInvalid(err) || models.IsErrRepoCustomTabsLimit(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "NewRepoCustomTab", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "NewRepoCustomTab", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToRepoCustomTab(tab))
+}
+
+// EditCustomTab modify a custom tab of a repository
+func EditCustomTab(ctx *context.APIContext, form api.EditRepoCustomTabOption) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/tabs/{id} repository repoEditCustomTab
+	// ---
+	// summary: Edit a custom tab of a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the tab to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditRepoCustomTabOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoCustomTab"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	tab := getCustomTab(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Name != nil {
+		tab.Name = *form.Name
+	}
+	if form.URL != nil {
+		tab.URL = *form.URL
+	}
+	if form.Icon != nil {
+		tab.Icon = *form.Icon
+	}
+	if form.Sort != nil {
+		tab.Sort = *form.Sort
+	}
+	if err := models.UpdateRepoCustomTab(tab); err != nil {
+		if models.IsErrRepoCustomTabInvalid(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "UpdateRepoCustomTab", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateRepoCustomTab", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToRepoCustomTab(tab))
+}
+
+// DeleteCustomTab delete a custom tab of a repository
+func DeleteCustomTab(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/tabs/{id} repository repoDeleteCustomTab
+	// ---
+	// summary: Delete a custom tab of a repository
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the tab to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	tab := getCustomTab(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteRepoCustomTab(ctx.Repo.Repository.ID, tab.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteRepoCustomTab", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func getCustomTab(ctx *context.APIContext) *models.RepoCustomTab {
+	tab, err := models.GetRepoCustomTab(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrRepoCustomTabNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRepoCustomTab", err)
+		}
+		return nil
+	}
+	return tab
+}
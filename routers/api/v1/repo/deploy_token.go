@@ -0,0 +1,162 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// DeployTokensEnabled checks that the deploy tokens are enabled
+func DeployTokensEnabled(ctx *context.APIContext) {
+	if !setting.DeployToken.Enabled {
+		ctx.NotFound()
+	}
+}
+
+// ListDeployTokens list the deploy tokens of a repository
+func ListDeployTokens(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/deploy_tokens repository repoListDeployTokens
+	// ---
+	// summary: List the deploy tokens of a repository which have not expired
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DeployTokenList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	tokens, err := models.GetDeployTokens(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDeployTokens", err)
+		return
+	}
+
+	apiTokens := make([]*api.DeployToken, len(tokens))
+	for i := range tokens {
+		if err := tokens[i].LoadCreator(); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadCreator", err)
+			return
+		}
+		apiTokens[i] = convert.ToDeployToken(tokens[i])
+	}
+	ctx.JSON(http.StatusOK, apiTokens)
+}
+
+// CreateDeployToken create a deploy token for a repository
+func CreateDeployToken(ctx *context.APIContext, form api.CreateDeployTokenOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/deploy_tokens repository repoCreateDeployToken
+	// ---
+	// summary: Create a deploy token for a repository
+	// description: The token acts on behalf of the authenticated user, only for the operations of its
+	//   scopes on the repository and until it expires. Its secret is only returned by this request.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateDeployTokenOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/DeployToken"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	token := &models.DeployToken{
+		RepoID:    ctx.Repo.Repository.ID,
+		CreatorID: ctx.User.ID,
+		Creator:   ctx.User,
+		Name:      form.Name,
+		Scopes:    make([]models.DeployTokenScope, len(form.Scopes)),
+	}
+	for i, scope := range form.Scopes {
+		token.Scopes[i] = models.DeployTokenScope(scope)
+	}
+	if err := models.NewDeployToken(token, time.Duration(form.ExpiresIn)*time.Second); err != nil {
+		if models.IsErrDeployTokenInvalid(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "NewDeployToken", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "NewDeployToken", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToDeployToken(token))
+}
+
+// DeleteDeployToken revoke a deploy token of a repository
+func DeleteDeployToken(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/deploy_tokens/{id} repository repoDeleteDeployToken
+	// ---
+	// summary: Revoke a deploy token of a repository
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the token to revoke
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if err := models.DeleteDeployToken(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id")); err != nil {
+		if models.IsErrDeployTokenNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteDeployToken", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
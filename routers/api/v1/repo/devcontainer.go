@@ -0,0 +1,84 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/util"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// GetDevEnvironment returns the dev environment of a ref of a repository
+func GetDevEnvironment(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/dev_environment repository repoGetDevEnvironment
+	// ---
+	// summary: Get the dev environment of a repository
+	// description: Describes the dev container configuration of the repository
+	//   (.devcontainer/devcontainer.json or .devcontainer.json) and the links opening it in the IDEs
+	//   configured on the instance.
+	// produces:
+	//   - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag. Default the repository’s default branch"
+	//   type: string
+	//   required: false
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DevEnvironment"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.NotFound()
+		return
+	}
+
+	ref := ctx.QueryTrim("ref")
+	if len(ref) == 0 {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(ref)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return
+	}
+
+	webURL := ctx.Repo.Repository.HTMLURL()
+	switch {
+	case ctx.Repo.GitRepo.IsBranchExist(ref):
+		webURL += "/src/branch/" + util.PathEscapeSegments(ref)
+	case ctx.Repo.GitRepo.IsTagExist(ref):
+		webURL += "/src/tag/" + util.PathEscapeSegments(ref)
+	default:
+		webURL += "/src/commit/" + commit.ID.String()
+	}
+
+	env, err := repo_service.GetDevEnvironment(ctx.Repo.Repository, commit, ref, webURL)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDevEnvironment", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToDevEnvironment(env))
+}
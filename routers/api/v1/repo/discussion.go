@@ -0,0 +1,797 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	discussion_service "code.gitea.io/gitea/services/discussion"
+)
+
+// ListDiscussionCategories list the discussion categories of a repository
+func ListDiscussionCategories(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/discussions/categories repository repoListDiscussionCategories
+	// ---
+	// summary: List a repository's discussion categories
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DiscussionCategoryList"
+
+	categories, err := models.GetDiscussionCategoriesByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDiscussionCategoriesByRepoID", err)
+		return
+	}
+
+	apiCategories := make([]*api.DiscussionCategory, len(categories))
+	for i := range categories {
+		apiCategories[i] = convert.ToDiscussionCategory(categories[i])
+	}
+	ctx.JSON(http.StatusOK, &apiCategories)
+}
+
+// CreateDiscussionCategory create a discussion category for a repository
+func CreateDiscussionCategory(ctx *context.APIContext, form api.CreateDiscussionCategoryOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/discussions/categories repository repoCreateDiscussionCategory
+	// ---
+	// summary: Create a discussion category
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateDiscussionCategoryOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/DiscussionCategory"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	category := &models.DiscussionCategory{
+		RepoID:       ctx.Repo.Repository.ID,
+		Name:         form.Name,
+		Description:  form.Description,
+		IsAnswerable: form.IsAnswerable,
+	}
+	if err := models.NewDiscussionCategory(category); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewDiscussionCategory", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToDiscussionCategory(category))
+}
+
+// DeleteDiscussionCategory delete a discussion category of a repository
+func DeleteDiscussionCategory(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/discussions/categories/{id} repository repoDeleteDiscussionCategory
+	// ---
+	// summary: Delete a discussion category, which must not contain any discussion
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the category to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if err := models.DeleteDiscussionCategory(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id")); err != nil {
+		if models.IsErrDiscussionCategoryNotEmpty(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "DeleteDiscussionCategory", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteDiscussionCategory", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListDiscussions list the discussions of a repository
+func ListDiscussions(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/discussions repository repoListDiscussions
+	// ---
+	// summary: List a repository's discussions, most recently active first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: category
+	//   in: query
+	//   description: only list discussions of this category
+	//   type: integer
+	//   format: int64
+	// - name: q
+	//   in: query
+	//   description: search string
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DiscussionList"
+
+	listOptions := utils.GetListOptions(ctx)
+	discussions, count, err := models.FindDiscussions(models.FindDiscussionsOptions{
+		ListOptions: listOptions,
+		RepoID:      ctx.Repo.Repository.ID,
+		CategoryID:  ctx.QueryInt64("category"),
+		Keyword:     ctx.Query("q"),
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindDiscussions", err)
+		return
+	}
+
+	apiDiscussions := make([]*api.Discussion, len(discussions))
+	for i, d := range discussions {
+		d.Repo = ctx.Repo.Repository
+		if err = d.LoadAttributes(); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+			return
+		}
+		apiDiscussions[i] = convert.ToDiscussion(d)
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.JSON(http.StatusOK, &apiDiscussions)
+}
+
+// getDiscussion returns the discussion of the current repository from the
+// index in the URL, with its attributes loaded.
+func getDiscussion(ctx *context.APIContext) *models.Discussion {
+	d, err := models.GetDiscussionByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrDiscussionNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDiscussionByIndex", err)
+		}
+		return nil
+	}
+	d.Repo = ctx.Repo.Repository
+	if err = d.LoadAttributes(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return nil
+	}
+	return d
+}
+
+// getDiscussionReply returns the reply of the discussion from the id in the URL.
+func getDiscussionReply(ctx *context.APIContext, d *models.Discussion) *models.DiscussionReply {
+	reply, err := models.GetDiscussionReplyByID(d.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrDiscussionReplyNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDiscussionReplyByID", err)
+		}
+		return nil
+	}
+	return reply
+}
+
+// canModerateDiscussion returns true if the user may delete a post of the
+// given poster, or accept answers in a discussion started by them.
+func canModerateDiscussion(ctx *context.APIContext, posterID int64) bool {
+	return ctx.User.ID == posterID || ctx.Repo.CanWrite(models.UnitTypeDiscussions)
+}
+
+// GetDiscussion get a discussion of a repository
+func GetDiscussion(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/discussions/{index} repository repoGetDiscussion
+	// ---
+	// summary: Get a discussion
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Discussion"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToDiscussion(d))
+}
+
+// CreateDiscussion start a discussion in a repository
+func CreateDiscussion(ctx *context.APIContext, form api.CreateDiscussionOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/discussions repository repoCreateDiscussion
+	// ---
+	// summary: Start a discussion
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateDiscussionOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Discussion"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	d := &models.Discussion{
+		RepoID:     ctx.Repo.Repository.ID,
+		Repo:       ctx.Repo.Repository,
+		CategoryID: form.CategoryID,
+		PosterID:   ctx.User.ID,
+		Poster:     ctx.User,
+		Title:      form.Title,
+		Content:    form.Body,
+	}
+	if err := discussion_service.NewDiscussion(d); err != nil {
+		if models.IsErrDiscussionCategoryNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "NewDiscussion", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "NewDiscussion", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToDiscussion(d))
+}
+
+// DeleteDiscussion delete a discussion of a repository
+func DeleteDiscussion(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/discussions/{index} repository repoDeleteDiscussion
+	// ---
+	// summary: Delete a discussion with all of its replies
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !canModerateDiscussion(ctx, d.PosterID) {
+		ctx.Error(http.StatusForbidden, "DeleteDiscussion", "user is not allowed to delete this discussion")
+		return
+	}
+
+	if err := models.DeleteDiscussion(d); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteDiscussion", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListDiscussionReplies list the replies of a discussion
+func ListDiscussionReplies(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/discussions/{index}/replies repository repoListDiscussionReplies
+	// ---
+	// summary: List the replies of a discussion in the order they were posted
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DiscussionReplyList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	replies, err := models.GetDiscussionReplies(d.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDiscussionReplies", err)
+		return
+	}
+
+	apiReplies := make([]*api.DiscussionReply, len(replies))
+	for i := range replies {
+		apiReplies[i] = convert.ToDiscussionReply(d, replies[i])
+	}
+	ctx.JSON(http.StatusOK, &apiReplies)
+}
+
+// CreateDiscussionReply reply to a discussion
+func CreateDiscussionReply(ctx *context.APIContext, form api.CreateDiscussionReplyOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/discussions/{index}/replies repository repoCreateDiscussionReply
+	// ---
+	// summary: Reply to a discussion
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateDiscussionReplyOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/DiscussionReply"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	reply, err := discussion_service.NewReply(ctx.User, d, form.ParentID, form.Body)
+	if err != nil {
+		if models.IsErrDiscussionReplyNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "NewReply", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "NewReply", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToDiscussionReply(d, reply))
+}
+
+// DeleteDiscussionReply delete a reply of a discussion
+func DeleteDiscussionReply(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/discussions/{index}/replies/{id} repository repoDeleteDiscussionReply
+	// ---
+	// summary: Delete a reply of a discussion, nested replies move up one level
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the reply
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	reply := getDiscussionReply(ctx, d)
+	if ctx.Written() {
+		return
+	}
+	if !canModerateDiscussion(ctx, reply.PosterID) {
+		ctx.Error(http.StatusForbidden, "DeleteDiscussionReply", "user is not allowed to delete this reply")
+		return
+	}
+
+	if err := models.DeleteDiscussionReply(d, reply); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteDiscussionReply", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func setDiscussionAnswer(ctx *context.APIContext, d *models.Discussion, replyID int64) {
+	if !canModerateDiscussion(ctx, d.PosterID) {
+		ctx.Error(http.StatusForbidden, "SetAnswer", "user is not allowed to accept answers in this discussion")
+		return
+	}
+
+	if err := d.SetAnswer(replyID); err != nil {
+		if models.IsErrDiscussionNotAnswerable(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "SetAnswer", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SetAnswer", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToDiscussion(d))
+}
+
+// MarkDiscussionAnswer accept a reply as the answer of a discussion
+func MarkDiscussionAnswer(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/discussions/{index}/replies/{id}/answer repository repoMarkDiscussionAnswer
+	// ---
+	// summary: Accept a reply as the answer of a discussion in an answerable category
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the reply
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Discussion"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	reply := getDiscussionReply(ctx, d)
+	if ctx.Written() {
+		return
+	}
+	setDiscussionAnswer(ctx, d, reply.ID)
+}
+
+// UnmarkDiscussionAnswer remove the accepted answer of a discussion
+func UnmarkDiscussionAnswer(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/discussions/{index}/answer repository repoUnmarkDiscussionAnswer
+	// ---
+	// summary: Remove the accepted answer of a discussion
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Discussion"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	setDiscussionAnswer(ctx, d, 0)
+}
+
+func setDiscussionUpvote(ctx *context.APIContext, upvote bool) {
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	replyID := ctx.ParamsInt64(":id")
+	if replyID > 0 {
+		if getDiscussionReply(ctx, d); ctx.Written() {
+			return
+		}
+	}
+
+	if err := models.SetDiscussionUpvote(ctx.User.ID, d, replyID, upvote); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetDiscussionUpvote", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// UpvoteDiscussion upvote a discussion
+func UpvoteDiscussion(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/discussions/{index}/upvote repository repoUpvoteDiscussion
+	// ---
+	// summary: Upvote a discussion
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	setDiscussionUpvote(ctx, true)
+}
+
+// RemoveDiscussionUpvote withdraw the upvote of a discussion
+func RemoveDiscussionUpvote(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/discussions/{index}/upvote repository repoRemoveDiscussionUpvote
+	// ---
+	// summary: Withdraw the upvote of a discussion
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	setDiscussionUpvote(ctx, false)
+}
+
+// UpvoteDiscussionReply upvote a reply of a discussion
+func UpvoteDiscussionReply(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/discussions/{index}/replies/{id}/upvote repository repoUpvoteDiscussionReply
+	// ---
+	// summary: Upvote a reply of a discussion
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the reply
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	setDiscussionUpvote(ctx, true)
+}
+
+// RemoveDiscussionReplyUpvote withdraw the upvote of a reply of a discussion
+func RemoveDiscussionReplyUpvote(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/discussions/{index}/replies/{id}/upvote repository repoRemoveDiscussionReplyUpvote
+	// ---
+	// summary: Withdraw the upvote of a reply of a discussion
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the reply
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	setDiscussionUpvote(ctx, false)
+}
@@ -0,0 +1,308 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListEpics list the epics of a repository
+func ListEpics(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/epics issue issueListEpics
+	// ---
+	// summary: List a repository's epics
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: Epic state, Recognised values are open, closed and all. Defaults to "open"
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/EpicList"
+
+	epics, err := models.GetEpicsByRepoID(ctx.Repo.Repository.ID, api.StateType(ctx.Query("state")), utils.GetListOptions(ctx))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetEpicsByRepoID", err)
+		return
+	}
+
+	apiEpics := make([]*api.Epic, len(epics))
+	for i := range epics {
+		apiEpics[i] = convert.ToAPIEpic(epics[i])
+	}
+	ctx.JSON(http.StatusOK, &apiEpics)
+}
+
+// GetEpic get an epic of a repository
+func GetEpic(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/epics/{id} issue issueGetEpic
+	// ---
+	// summary: Get an epic
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the epic to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Epic"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	epic, err := models.GetEpicByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrEpicNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetEpicByRepoID", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToAPIEpic(epic))
+}
+
+// CreateEpic create an epic for a repository
+func CreateEpic(ctx *context.APIContext, form api.CreateEpicOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/epics issue issueCreateEpic
+	// ---
+	// summary: Create an epic
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateEpicOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Epic"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	epic := &models.Epic{
+		OwnerID: ctx.Repo.Owner.ID,
+		RepoID:  ctx.Repo.Repository.ID,
+		Name:    form.Title,
+		Content: form.Description,
+	}
+	if err := models.NewEpic(epic); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewEpic", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToAPIEpic(epic))
+}
+
+// EditEpic modify an epic of a repository
+func EditEpic(ctx *context.APIContext, form api.EditEpicOption) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/epics/{id} issue issueEditEpic
+	// ---
+	// summary: Update an epic
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the epic to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditEpicOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Epic"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	epic, err := models.GetEpicByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrEpicNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetEpicByRepoID", err)
+		}
+		return
+	}
+
+	if form.Title != nil {
+		epic.Name = *form.Title
+	}
+	if form.Description != nil {
+		epic.Content = *form.Description
+	}
+	if form.State != nil {
+		epic.IsClosed = api.StateClosed == api.StateType(*form.State)
+	}
+	if err := models.UpdateEpic(epic); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateEpic", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToAPIEpic(epic))
+}
+
+// DeleteEpic delete an epic of a repository
+func DeleteEpic(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/epics/{id} issue issueDeleteEpic
+	// ---
+	// summary: Delete an epic, its issues are kept
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the epic to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	epic, err := models.GetEpicByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrEpicNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetEpicByRepoID", err)
+		}
+		return
+	}
+
+	if err := models.DeleteEpic(epic); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteEpic", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListEpicIssues list the issues of an epic
+func ListEpicIssues(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/epics/{id}/issues issue issueListEpicIssues
+	// ---
+	// summary: List the issues of an epic the user is allowed to see
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the epic
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	epic, err := models.GetEpicByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrEpicNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetEpicByRepoID", err)
+		}
+		return
+	}
+
+	issues, err := models.GetEpicIssues(epic.ID, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetEpicIssues", err)
+		return
+	}
+
+	apiIssues := make([]*api.Issue, len(issues))
+	for i := range issues {
+		apiIssues[i] = convert.ToAPIIssue(issues[i])
+	}
+	ctx.JSON(http.StatusOK, &apiIssues)
+}
@@ -97,7 +97,7 @@ func GetArchive(ctx *context.APIContext) {
 	//     "$ref": "#/responses/notFound"
 
 	repoPath := models.RepoPath(ctx.Params(":username"), ctx.Params(":reponame"))
-	gitRepo, err := git.OpenRepository(repoPath)
+	gitRepo, err := git.OpenRepositoryCtx(context.LogContext(ctx.Context.Context), repoPath)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "OpenRepository", err)
 		return
@@ -346,7 +346,8 @@ func handleCreateOrUpdateFileError(ctx *context.APIContext, err error) {
 		return
 	}
 	if models.IsErrBranchAlreadyExists(err) || models.IsErrFilenameInvalid(err) || models.IsErrSHADoesNotMatch(err) ||
-		models.IsErrFilePathInvalid(err) || models.IsErrRepoFileAlreadyExists(err) {
+		models.IsErrFilePathInvalid(err) || models.IsErrRepoFileAlreadyExists(err) ||
+		models.IsErrCommitMessageRejected(err) {
 		ctx.Error(http.StatusUnprocessableEntity, "Invalid", err)
 		return
 	}
@@ -466,7 +467,8 @@ func DeleteFile(ctx *context.APIContext, apiOpts api.DeleteFileOptions) {
 			models.IsErrFilenameInvalid(err) ||
 			models.IsErrSHADoesNotMatch(err) ||
 			models.IsErrCommitIDDoesNotMatch(err) ||
-			models.IsErrSHAOrCommitIDNotProvided(err) {
+			models.IsErrSHAOrCommitIDNotProvided(err) ||
+			models.IsErrCommitMessageRejected(err) {
 			ctx.Error(http.StatusBadRequest, "DeleteFile", err)
 			return
 		} else if models.IsErrUserCannotCommit(err) {
@@ -63,6 +63,86 @@ func ListForks(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, apiForks)
 }
 
+// ListForkNetwork list the repositories of the fork network of a repository with the tips of their branches
+func ListForkNetwork(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/network repository repoListForkNetwork
+	// ---
+	// summary: List the repositories of a repository's fork network with the tips of their branches
+	// description: The root repository of the network comes first, only the repositories whose code the user can read are listed.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/NetworkRepositoryList"
+
+	repos, err := models.GetForkNetwork(ctx.Repo.Repository, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetForkNetwork", err)
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	if listOptions.Page <= 0 {
+		listOptions.Page = 1
+	}
+	total := len(repos)
+	start := (listOptions.Page - 1) * listOptions.PageSize
+	if start > total {
+		start = total
+	}
+	end := start + listOptions.PageSize
+	if end > total {
+		end = total
+	}
+
+	network, err := repo_service.GetNetworkRepositories(repos[start:end])
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetNetworkRepositories", err)
+		return
+	}
+	apiNetwork := make([]*api.NetworkRepository, len(network))
+	for i, networkRepo := range network {
+		access, err := models.AccessLevel(ctx.User, networkRepo.Repo)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "AccessLevel", err)
+			return
+		}
+		apiNetwork[i] = &api.NetworkRepository{
+			Repository: networkRepo.Repo.APIFormat(access),
+			Branches:   make([]*api.NetworkBranch, len(networkRepo.Branches)),
+		}
+		for j, branch := range networkRepo.Branches {
+			apiNetwork[i].Branches[j] = &api.NetworkBranch{
+				Name:     branch.Name,
+				CommitID: branch.CommitID,
+			}
+		}
+	}
+
+	ctx.SetLinkHeader(total, listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", total))
+	ctx.JSON(http.StatusOK, apiNetwork)
+}
+
 // CreateFork create a fork of a repo
 func CreateFork(ctx *context.APIContext, form api.CreateForkOption) {
 	// swagger:operation POST /repos/{owner}/{repo}/forks repository createFork
@@ -6,6 +6,7 @@
 package repo
 
 import (
+	"fmt"
 	"net/http"
 
 	"code.gitea.io/gitea/models"
@@ -157,6 +158,259 @@ func TestHook(ctx *context.APIContext) {
 	ctx.Status(http.StatusNoContent)
 }
 
+// TestHookEvent delivers a test payload of an event to a hook and returns the delivery status
+func TestHookEvent(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/hooks/{id}/tests/{event} repository repoTestHookEvent
+	// ---
+	// summary: Deliver a test payload of an event to a webhook and wait for the response
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: event
+	//   in: path
+	//   description: event of the test payload
+	//   type: string
+	//   enum: [push, create, delete, repository]
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	hook, err := utils.GetRepoHook(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+
+	event, payload := testHookPayload(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	t, err := webhook.DeliverTestWebhook(hook, ctx.Repo.Repository, event, payload)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeliverTestWebhook", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToHookDelivery(t))
+}
+
+// testHookPayload builds a sample payload of the `:event` param from the
+// default branch of the repository.
+func testHookPayload(ctx *context.APIContext) (models.HookEventType, api.Payloader) {
+	repo := ctx.Repo.Repository
+	sender := convert.ToUser(ctx.User, ctx.IsSigned, false)
+
+	event := models.HookEventType(ctx.Params(":event"))
+	switch event {
+	case models.HookEventRepository:
+		var org *api.User
+		if repo.Owner.IsOrganization() {
+			org = repo.Owner.APIFormat()
+		}
+		return event, &api.RepositoryPayload{
+			Action:       api.HookRepoCreated,
+			Repository:   repo.APIFormat(models.AccessModeOwner),
+			Organization: org,
+			Sender:       sender,
+		}
+	case models.HookEventPush, models.HookEventCreate, models.HookEventDelete:
+	default:
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("unsupported test event: %s", event))
+		return "", nil
+	}
+
+	if ctx.Repo.Commit == nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", "repository has no commits")
+		return "", nil
+	}
+
+	apiRepo := repo.APIFormat(models.AccessModeNone)
+	switch event {
+	case models.HookEventCreate:
+		return event, &api.CreatePayload{
+			Sha:     ctx.Repo.Commit.ID.String(),
+			Ref:     repo.DefaultBranch,
+			RefType: "branch",
+			Repo:    apiRepo,
+			Sender:  sender,
+		}
+	case models.HookEventDelete:
+		return event, &api.DeletePayload{
+			Ref:        repo.DefaultBranch,
+			RefType:    "branch",
+			PusherType: api.PusherTypeUser,
+			Repo:       apiRepo,
+			Sender:     sender,
+		}
+	}
+	return event, &api.PushPayload{
+		Ref:    git.BranchPrefix + repo.DefaultBranch,
+		Before: ctx.Repo.Commit.ID.String(),
+		After:  ctx.Repo.Commit.ID.String(),
+		Commits: []*api.PayloadCommit{
+			convert.ToCommit(repo, ctx.Repo.Commit),
+		},
+		Repo:   apiRepo,
+		Pusher: sender,
+		Sender: sender,
+	}
+}
+
+// ListHookDeliveries list the deliveries of a repo's hook
+func ListHookDeliveries(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/hooks/{id}/deliveries repository repoListHookDeliveries
+	// ---
+	// summary: List the deliveries of a hook, latest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDeliveryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetRepoHook(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.ListHookDeliveries(ctx, hook)
+}
+
+// GetHookDelivery get a delivery of a repo's hook
+func GetHookDelivery(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/hooks/{id}/deliveries/{delivery_id} repository repoGetHookDelivery
+	// ---
+	// summary: Get a delivery of a hook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery_id
+	//   in: path
+	//   description: id of the delivery
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetRepoHook(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	t, err := utils.GetHookDelivery(ctx, hook)
+	if err != nil {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToHookDelivery(t))
+}
+
+// RedeliverHookDelivery redeliver a delivery of a repo's hook
+func RedeliverHookDelivery(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/hooks/{id}/deliveries/{delivery_id}/redeliver repository repoRedeliverHookDelivery
+	// ---
+	// summary: Deliver the payload of a past delivery again and wait for the response
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery_id
+	//   in: path
+	//   description: id of the delivery
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetRepoHook(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.RedeliverHookDelivery(ctx, hook)
+}
+
 // CreateHook create a hook for a repository
 func CreateHook(ctx *context.APIContext, form api.CreateHookOption) {
 	// swagger:operation POST /repos/{owner}/{repo}/hooks repository repoCreateHook
@@ -119,7 +119,7 @@ func SearchIssues(ctx *context.APIContext) {
 		}
 	}
 
-	var issues []*models.Issue
+	var issues models.IssueList
 
 	keyword := strings.Trim(ctx.Query("q"), " ")
 	if strings.IndexByte(keyword, 0) >= 0 {
@@ -173,7 +173,7 @@ func SearchIssues(ctx *context.APIContext) {
 	}
 
 	ctx.SetLinkHeader(issueCount, setting.UI.IssuePagingNum)
-	ctx.JSON(http.StatusOK, convert.ToAPIIssueList(issues))
+	ctx.JSONConditional(http.StatusOK, convert.ToAPIIssueList(issues), issues.LastUpdatedUnix())
 }
 
 // ListIssues list the issues of a repository
@@ -216,6 +216,11 @@ func ListIssues(ctx *context.APIContext) {
 	//   in: query
 	//   description: comma separated list of milestone names or ids. It uses names and fall back to ids. Fetch only issues that have any of this milestones. Non existent milestones are discarded
 	//   type: string
+	// - name: workflow_state
+	//   in: query
+	//   description: id of the workflow state to filter by, -1 returns issues without a workflow state
+	//   type: integer
+	//   format: int64
 	// - name: page
 	//   in: query
 	//   description: page number of results to return (1-based)
@@ -238,7 +243,7 @@ func ListIssues(ctx *context.APIContext) {
 		isClosed = util.OptionalBoolFalse
 	}
 
-	var issues []*models.Issue
+	var issues models.IssueList
 
 	keyword := strings.Trim(ctx.Query("q"), " ")
 	if strings.IndexByte(keyword, 0) >= 0 {
@@ -305,13 +310,14 @@ func ListIssues(ctx *context.APIContext) {
 	// This would otherwise return all issues if no issues were found by the search.
 	if len(keyword) == 0 || len(issueIDs) > 0 || len(labelIDs) > 0 {
 		issues, err = models.Issues(&models.IssuesOptions{
-			ListOptions:  listOptions,
-			RepoIDs:      []int64{ctx.Repo.Repository.ID},
-			IsClosed:     isClosed,
-			IssueIDs:     issueIDs,
-			LabelIDs:     labelIDs,
-			MilestoneIDs: mileIDs,
-			IsPull:       isPull,
+			ListOptions:     listOptions,
+			RepoIDs:         []int64{ctx.Repo.Repository.ID},
+			IsClosed:        isClosed,
+			IssueIDs:        issueIDs,
+			LabelIDs:        labelIDs,
+			MilestoneIDs:    mileIDs,
+			WorkflowStateID: ctx.QueryInt64("workflow_state"),
+			IsPull:          isPull,
 		})
 	}
 
@@ -323,7 +329,7 @@ func ListIssues(ctx *context.APIContext) {
 	ctx.SetLinkHeader(ctx.Repo.Repository.NumIssues, listOptions.PageSize)
 	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", ctx.Repo.Repository.NumIssues))
 
-	ctx.JSON(http.StatusOK, convert.ToAPIIssueList(issues))
+	ctx.JSONConditional(http.StatusOK, convert.ToAPIIssueList(issues), issues.LastUpdatedUnix())
 }
 
 // GetIssue get an issue of a repository
@@ -522,6 +528,8 @@ func EditIssue(ctx *context.APIContext, form api.EditIssueOption) {
 	//     "$ref": "#/responses/notFound"
 	//   "412":
 	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
@@ -622,6 +630,30 @@ func EditIssue(ctx *context.APIContext, form api.EditIssueOption) {
 		notification.NotifyIssueChangeStatus(ctx.User, issue, statusChangeComment, issue.IsClosed)
 	}
 
+	if canWrite && form.WorkflowState != nil && issue.WorkflowStateID != *form.WorkflowState {
+		if err = issue_service.ChangeWorkflowState(issue, ctx.User, *form.WorkflowState); err != nil {
+			if models.IsErrWorkflowStateNotExist(err) || models.IsErrWorkflowTransitionNotAllowed(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "ChangeWorkflowState", err)
+			} else if models.IsErrDependenciesLeft(err) {
+				ctx.Error(http.StatusPreconditionFailed, "DependenciesLeft", "cannot close this issue because it still has open dependencies")
+			} else {
+				ctx.Error(http.StatusInternalServerError, "ChangeWorkflowState", err)
+			}
+			return
+		}
+	}
+
+	if canWrite && form.Epic != nil && issue.EpicID != *form.Epic {
+		if err = issue.ChangeEpic(*form.Epic); err != nil {
+			if models.IsErrEpicNotExist(err) || models.IsErrEpicIssueOutOfScope(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "ChangeEpic", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "ChangeEpic", err)
+			}
+			return
+		}
+	}
+
 	// Refetch from database to assign some automatic values
 	issue, err = models.GetIssueByID(issue.ID)
 	if err != nil {
@@ -0,0 +1,144 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// SetIssueAnswer accept a comment as the answer of a question issue
+func SetIssueAnswer(ctx *context.APIContext, form api.SetIssueAnswerOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/issues/{index}/answer issue issueSetAnswer
+	// ---
+	// summary: Accept a comment as the answer of an issue with a question label
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/SetIssueAnswerOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Issue"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	issue := getAnswerableIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := issue.SetAnswer(form.CommentID); err != nil {
+		if models.IsErrIssueNotQuestion(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else if models.IsErrCommentNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SetAnswer", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToAPIIssue(issue))
+}
+
+// DeleteIssueAnswer remove the accepted answer of a question issue
+func DeleteIssueAnswer(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issues/{index}/answer issue issueDeleteAnswer
+	// ---
+	// summary: Remove the accepted answer of an issue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	issue := getAnswerableIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := issue.SetAnswer(0); err != nil {
+		if models.IsErrIssueNotQuestion(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SetAnswer", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// getAnswerableIssue returns the issue of the request if the doer is allowed
+// to choose its answer, which are the poster and the repository writers.
+func getAnswerableIssue(ctx *context.APIContext) *models.Issue {
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return nil
+	}
+
+	if !issue.IsPoster(ctx.User.ID) && !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Error(http.StatusForbidden, "", "Not issue poster or repo writer")
+		return nil
+	}
+	return issue
+}
@@ -14,6 +14,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	comment_service "code.gitea.io/gitea/services/comments"
+	issue_service "code.gitea.io/gitea/services/issue"
 )
 
 // ListIssueComments list all the comments of an issue
@@ -50,15 +51,30 @@ func ListIssueComments(ctx *context.APIContext) {
 	//   description: if provided, only comments updated before the provided time are returned.
 	//   type: string
 	//   format: date-time
+	// - name: cursor
+	//   in: query
+	//   description: "Opt in to cursor based pagination, oldest comments first. Pass an empty cursor for the first page, then the cursor of the X-Next-Cursor header."
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: page size of results, only used with cursor
+	//   type: integer
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/CommentList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	before, since, err := utils.GetQueryBeforeSince(ctx)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "GetQueryBeforeSince", err)
 		return
 	}
+	cursor, err := utils.GetCursorOptions(ctx)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
 	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "GetRawIssueByIndex", err)
@@ -71,6 +87,7 @@ func ListIssueComments(ctx *context.APIContext) {
 		Since:   since,
 		Before:  before,
 		Type:    models.CommentTypeComment,
+		Cursor:  cursor,
 	})
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "FindComments", err)
@@ -87,6 +104,9 @@ func ListIssueComments(ctx *context.APIContext) {
 		comment.Issue = issue
 		apiComments[i] = comments[i].APIFormat()
 	}
+	if cursor != nil && len(comments) > 0 {
+		utils.SetNextCursor(ctx, cursor, len(comments), comments[len(comments)-1].ID)
+	}
 	ctx.JSON(http.StatusOK, &apiComments)
 }
 
@@ -118,6 +138,10 @@ func ListRepoIssueComments(ctx *context.APIContext) {
 	//   description: if provided, only comments updated before the provided time are returned.
 	//   type: string
 	//   format: date-time
+	// - name: cursor
+	//   in: query
+	//   description: "Opt in to cursor based pagination, oldest comments first. Pass an empty cursor for the first page, then the cursor of the X-Next-Cursor header. Ignores page."
+	//   type: string
 	// - name: page
 	//   in: query
 	//   description: page number of results to return (1-based)
@@ -129,12 +153,19 @@ func ListRepoIssueComments(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/CommentList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	before, since, err := utils.GetQueryBeforeSince(ctx)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "GetQueryBeforeSince", err)
 		return
 	}
+	cursor, err := utils.GetCursorOptions(ctx)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
 
 	comments, err := models.FindComments(models.FindCommentsOptions{
 		ListOptions: utils.GetListOptions(ctx),
@@ -142,6 +173,7 @@ func ListRepoIssueComments(ctx *context.APIContext) {
 		Type:        models.CommentTypeComment,
 		Since:       since,
 		Before:      before,
+		Cursor:      cursor,
 	})
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "FindComments", err)
@@ -169,6 +201,9 @@ func ListRepoIssueComments(ctx *context.APIContext) {
 	for i := range comments {
 		apiComments[i] = comments[i].APIFormat()
 	}
+	if cursor != nil && len(comments) > 0 {
+		utils.SetNextCursor(ctx, cursor, len(comments), comments[len(comments)-1].ID)
+	}
 	ctx.JSON(http.StatusOK, &apiComments)
 }
 
@@ -205,8 +240,12 @@ func CreateIssueComment(ctx *context.APIContext, form api.CreateIssueCommentOpti
 	// responses:
 	//   "201":
 	//     "$ref": "#/responses/Comment"
+	//   "204":
+	//     "$ref": "#/responses/empty"
 	//   "403":
 	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
@@ -219,11 +258,20 @@ func CreateIssueComment(ctx *context.APIContext, form api.CreateIssueCommentOpti
 		return
 	}
 
-	comment, err := comment_service.CreateIssueComment(ctx.User, ctx.Repo.Repository, issue, form.Body, nil)
+	comment, err := issue_service.CreateIssueComment(ctx.User, ctx.Repo.Repository, issue, form.Body, nil)
 	if err != nil {
+		if issue_service.IsErrInvalidSlashCommand(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "CreateIssueComment", err)
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, "CreateIssueComment", err)
 		return
 	}
+	if comment == nil {
+		// the body only had slash commands
+		ctx.Status(http.StatusNoContent)
+		return
+	}
 
 	ctx.JSON(http.StatusCreated, comment.APIFormat())
 }
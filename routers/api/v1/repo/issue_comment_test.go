@@ -0,0 +1,57 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/test"
+
+	"gitea.com/macaron/macaron"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListIssueCommentsByCursor(t *testing.T) {
+	models.PrepareTestEnv(t)
+
+	listComments := func(cursor string) *httptest.ResponseRecorder {
+		ctx := test.MockContext(t, "user2/repo1/issues/1/comments")
+		ctx.SetParams(":index", "1")
+		ctx.Req.Form.Set("cursor", cursor)
+		ctx.Req.Form.Set("limit", "1")
+		test.LoadRepo(t, ctx, 1)
+		test.LoadUser(t, ctx, 2)
+
+		recorder := httptest.NewRecorder()
+		ctx.Resp = macaron.NewResponseWriter("GET", recorder)
+		ctx.Render.SetResponseWriter(ctx.Resp)
+		ListIssueComments(&context.APIContext{Context: ctx})
+		return recorder
+	}
+
+	// issue 1 has two comments, each page holds one of them
+	recorder := listComments("")
+	assert.EqualValues(t, http.StatusOK, recorder.Code)
+	first := recorder.Header().Get("X-Next-Cursor")
+	assert.NotEmpty(t, first)
+	assert.Contains(t, recorder.Header().Get("Link"), "rel=\"next\"")
+
+	recorder = listComments(first)
+	assert.EqualValues(t, http.StatusOK, recorder.Code)
+	second := recorder.Header().Get("X-Next-Cursor")
+	assert.NotEmpty(t, second)
+	assert.NotEqual(t, first, second)
+
+	recorder = listComments(second)
+	assert.EqualValues(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("X-Next-Cursor"))
+
+	recorder = listComments("3")
+	assert.EqualValues(t, http.StatusUnprocessableEntity, recorder.Code)
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+)
+
+// GetIssueGraph get the reference graph around an issue
+func GetIssueGraph(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/{index}/graph issue issueGetGraph
+	// ---
+	// summary: Get the reference graph around an issue, made of its dependencies, duplicates, cross references and linked pull requests
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: depth
+	//   in: query
+	//   description: maximum distance of the issues from the issue, between 1 and 3, defaults to 3
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueGraph"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return
+	}
+	if !ctx.Repo.CanReadIssuesOrPulls(issue.IsPull) {
+		ctx.NotFound()
+		return
+	}
+
+	graph, err := models.GetIssueGraph(issue, ctx.QueryInt("depth"), ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetIssueGraph", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToIssueGraph(graph))
+}
@@ -11,6 +11,8 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	stats_indexer "code.gitea.io/gitea/modules/indexer/stats"
 	"code.gitea.io/gitea/modules/log"
 )
 
@@ -62,12 +64,31 @@ func GetLanguages(ctx *context.APIContext) {
 	//   description: name of the repo
 	//   type: string
 	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag. Default the stats of the default branch calculated in the background"
+	//   type: string
+	//   required: false
 	// responses:
 	//   "404":
 	//     "$ref": "#/responses/notFound"
 	//   "200":
 	//     "$ref": "#/responses/LanguageStatistics"
 
+	if ref := ctx.QueryTrim("ref"); len(ref) > 0 {
+		commit, ok := getLanguagesCommit(ctx, ref)
+		if !ok {
+			return
+		}
+		sizes, err := stats_indexer.GetLanguageStats(ctx.Repo.Repository, ctx.Repo.GitRepo, commit.ID.String())
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetLanguageStats", err)
+			return
+		}
+		ctx.JSON(http.StatusOK, sizes)
+		return
+	}
+
 	langs, err := ctx.Repo.Repository.GetLanguageStats()
 	if err != nil {
 		log.Error("GetLanguageStats failed: %v", err)
@@ -82,3 +103,108 @@ func GetLanguages(ctx *context.APIContext) {
 
 	ctx.JSON(http.StatusOK, resp)
 }
+
+// getLanguagesCommit returns the commit of the ref, or writes an error
+// response if it does not exist
+func getLanguagesCommit(ctx *context.APIContext, ref string) (*git.Commit, bool) {
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.NotFound()
+		return nil, false
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(ref)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return nil, false
+	}
+	return commit, true
+}
+
+// GetLanguagesByDirectory returns the languages and number of bytes of code
+// written in every top-level directory
+func GetLanguagesByDirectory(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/languages/directories repository repoGetLanguagesByDirectory
+	// ---
+	// summary: Get languages and number of bytes of code written in every top-level directory
+	// description: Files in the root of the repository are listed under "/".
+	// produces:
+	//   - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag. Default the repository’s default branch"
+	//   type: string
+	//   required: false
+	// responses:
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "200":
+	//     "$ref": "#/responses/LanguageStatisticsByDirectory"
+
+	ref := ctx.QueryTrim("ref")
+	if len(ref) == 0 {
+		if ctx.Repo.Repository.IsEmpty {
+			ctx.JSON(http.StatusOK, map[string]map[string]int64{})
+			return
+		}
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, ok := getLanguagesCommit(ctx, ref)
+	if !ok {
+		return
+	}
+
+	dirs, err := stats_indexer.GetLanguageStatsByDirectory(ctx.Repo.Repository, ctx.Repo.GitRepo, commit.ID.String())
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetLanguageStatsByDirectory", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dirs)
+}
+
+// RecalculateLanguages queues the language stats of the repository to be
+// calculated again
+func RecalculateLanguages(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/languages/recalculate repository repoRecalculateLanguages
+	// ---
+	// summary: Recalculate the language stats of the default branch in the background
+	// produces:
+	//   - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if err := stats_indexer.RecalculateRepoStats(ctx.Repo.Repository); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RecalculateRepoStats", err)
+		return
+	}
+	ctx.Status(http.StatusAccepted)
+}
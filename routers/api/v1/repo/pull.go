@@ -17,6 +17,7 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/pulltemplate"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/routers/api/v1/utils"
@@ -169,6 +170,76 @@ func GetPullRequest(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, convert.ToAPIPullRequest(pr))
 }
 
+// ListPullRequestTemplates lists the pull request description templates of a repository
+func ListPullRequestTemplates(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/templates repository repoListPullRequestTemplates
+	// ---
+	// summary: List a repository's pull request description templates
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag to read the templates from. Default the repository’s default branch (usually master)"
+	//   type: string
+	//   required: false
+	// - name: branch
+	//   in: query
+	//   description: target branch of a pull request to mark the template selected for it
+	//   type: string
+	//   required: false
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullRequestTemplateList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.JSON(http.StatusOK, []*api.PullRequestTemplate{})
+		return
+	}
+	ref := ctx.QueryTrim("ref")
+	if len(ref) == 0 {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(ref)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return
+	}
+
+	templates, err := pulltemplate.Load(commit)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Load", err)
+		return
+	}
+	var selected *pulltemplate.Template
+	if branch := ctx.QueryTrim("branch"); len(branch) > 0 {
+		selected = pulltemplate.Select(templates, branch)
+	}
+
+	apiTemplates := make([]*api.PullRequestTemplate, len(templates))
+	for i := range templates {
+		apiTemplates[i] = convert.ToPullRequestTemplate(templates[i])
+		apiTemplates[i].Selected = templates[i] == selected
+	}
+	ctx.JSON(http.StatusOK, apiTemplates)
+}
+
 // DownloadPullDiff render a pull's raw diff
 func DownloadPullDiff(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}.diff repository repoDownloadPullDiff
@@ -712,6 +783,8 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 	//     "$ref": "#/responses/empty"
 	//   "409":
 	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
@@ -769,11 +842,12 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 			return
 		}
 		if form.ForceMerge != nil && *form.ForceMerge {
-			if isRepoAdmin, err := models.IsUserRepoAdmin(pr.BaseRepo, ctx.User); err != nil {
-				ctx.Error(http.StatusInternalServerError, "IsUserRepoAdmin", err)
+			if canForceMerge, err := pull_service.IsUserAllowedToForceMerge(pr, ctx.Repo.Permission, ctx.User); err != nil {
+				ctx.Error(http.StatusInternalServerError, "IsUserAllowedToForceMerge", err)
 				return
-			} else if !isRepoAdmin {
+			} else if !canForceMerge {
 				ctx.Error(http.StatusMethodNotAllowed, "Merge", "Only repository admin can merge if not all checks are ok (force merge)")
+				return
 			}
 		} else {
 			ctx.Error(http.StatusMethodNotAllowed, "PR is not ready to be merged", err)
@@ -809,7 +883,7 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 		message += "\n\n" + form.MergeMessageField
 	}
 
-	if err := pull_service.Merge(pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message); err != nil {
+	if err := pull_service.Merge(pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message, form.Autosquash); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Status(http.StatusMethodNotAllowed)
 			return
@@ -822,6 +896,21 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 		} else if models.IsErrMergeUnrelatedHistories(err) {
 			conflictError := err.(models.ErrMergeUnrelatedHistories)
 			ctx.JSON(http.StatusConflict, conflictError)
+		} else if models.IsErrMergeDivergingFastForwardOnly(err) {
+			ctx.Error(http.StatusConflict, "Merge", "the base branch has diverged from the head branch, it cannot be fast-forwarded")
+			return
+		} else if models.IsErrMergeStyleNotAllowedForBranch(err) {
+			ctx.Error(http.StatusMethodNotAllowed, "Merge", err)
+			return
+		} else if models.IsErrNotAllowedToMerge(err) {
+			ctx.Error(http.StatusMethodNotAllowed, "Merge", err)
+			return
+		} else if models.IsErrCommitMessageRejected(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "Merge", err)
+			return
+		} else if models.IsErrContributorAgreementNotSigned(err) {
+			ctx.Error(http.StatusMethodNotAllowed, "Merge", err)
+			return
 		} else if git.IsErrPushOutOfDate(err) {
 			ctx.Error(http.StatusConflict, "Merge", "merge push out of date")
 			return
@@ -907,7 +996,7 @@ func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption)
 		headRepo = ctx.Repo.Repository
 		headGitRepo = ctx.Repo.GitRepo
 	} else {
-		headGitRepo, err = git.OpenRepository(models.RepoPath(headUser.Name, headRepo.Name))
+		headGitRepo, err = git.OpenRepositoryCtx(context.LogContext(ctx.Context.Context), models.RepoPath(headUser.Name, headRepo.Name))
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "OpenRepository", err)
 			return nil, nil, nil, nil, "", ""
@@ -316,7 +316,7 @@ func CreatePullReview(ctx *context.APIContext, opts api.CreatePullReviewOptions)
 
 	// if CommitID is empty, set it as lastCommitID
 	if opts.CommitID == "" {
-		gitRepo, err := git.OpenRepository(pr.Issue.Repo.RepoPath())
+		gitRepo, err := git.OpenRepositoryCtx(context.LogContext(ctx.Context.Context), pr.Issue.Repo.RepoPath())
 		if err != nil {
 			ctx.ServerError("git.OpenRepository", err)
 			return
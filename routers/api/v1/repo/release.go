@@ -115,7 +115,8 @@ func ListReleases(ctx *context.APIContext) {
 		}
 		rels[i] = release.APIFormat()
 	}
-	ctx.JSON(http.StatusOK, rels)
+	// releases have no update time, so clients can only revalidate by ETag
+	ctx.JSONConditional(http.StatusOK, rels, 0)
 }
 
 // CreateRelease create a release
@@ -174,6 +175,8 @@ func CreateRelease(ctx *context.APIContext, form api.CreateReleaseOption) {
 		if err := releaseservice.CreateRelease(ctx.Repo.GitRepo, rel, nil); err != nil {
 			if models.IsErrReleaseAlreadyExist(err) {
 				ctx.Error(http.StatusConflict, "ReleaseAlreadyExist", err)
+			} else if models.IsErrTagProtected(err) {
+				ctx.Error(http.StatusForbidden, "TagProtected", err)
 			} else {
 				ctx.Error(http.StatusInternalServerError, "CreateRelease", err)
 			}
@@ -200,6 +200,10 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 		ReleaseID:  release.ID,
 	}, buf, file)
 	if err != nil {
+		if upload.IsErrMalformedImage(err) {
+			ctx.Error(http.StatusBadRequest, "NewAttachment", err)
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, "NewAttachment", err)
 		return
 	}
@@ -222,10 +222,10 @@ func Search(ctx *context.APIContext) {
 
 	ctx.SetLinkHeader(int(count), opts.PageSize)
 	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
-	ctx.JSON(http.StatusOK, api.SearchResults{
+	ctx.JSONConditional(http.StatusOK, api.SearchResults{
 		OK:   true,
 		Data: results,
-	})
+	}, repos.LastUpdatedUnix())
 }
 
 // CreateUserRepo create a repository for a user
@@ -485,6 +485,13 @@ func Edit(ctx *context.APIContext, opts api.EditRepoOption) {
 		}
 	}
 
+	if opts.DisableOrgLabelSync != nil {
+		if err := models.UpdateRepoOrgLabelSync(ctx.Repo.Repository, *opts.DisableOrgLabelSync); err != nil {
+			ctx.Error(http.StatusInternalServerError, "UpdateRepoOrgLabelSync", err)
+			return
+		}
+	}
+
 	ctx.JSON(http.StatusOK, ctx.Repo.Repository.APIFormat(ctx.Repo.AccessMode))
 }
 
@@ -568,7 +575,7 @@ func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) err
 	return nil
 }
 
-// updateRepoUnits updates repo units: Issue settings, Wiki settings, PR settings
+// updateRepoUnits updates repo units: Issue settings, Wiki settings, PR settings, Discussions settings
 func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 	owner := ctx.Repo.Owner
 	repo := ctx.Repo.Repository
@@ -707,6 +714,9 @@ func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 			if opts.AllowSquash != nil {
 				config.AllowSquash = *opts.AllowSquash
 			}
+			if opts.AllowFastForwardOnly != nil {
+				config.AllowFastForwardOnly = *opts.AllowFastForwardOnly
+			}
 
 			units = append(units, models.RepoUnit{
 				RepoID: repo.ID,
@@ -718,6 +728,18 @@ func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 		}
 	}
 
+	if opts.HasDiscussions != nil && !models.UnitTypeDiscussions.UnitGlobalDisabled() {
+		if *opts.HasDiscussions {
+			units = append(units, models.RepoUnit{
+				RepoID: repo.ID,
+				Type:   models.UnitTypeDiscussions,
+				Config: new(models.UnitConfig),
+			})
+		} else {
+			deleteUnitTypes = append(deleteUnitTypes, models.UnitTypeDiscussions)
+		}
+	}
+
 	if err := models.UpdateRepositoryUnits(repo, units, deleteUnitTypes); err != nil {
 		ctx.Error(http.StatusInternalServerError, "UpdateRepositoryUnits", err)
 		return err
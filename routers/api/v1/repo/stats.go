@@ -0,0 +1,275 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	stats_indexer "code.gitea.io/gitea/modules/indexer/stats"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// insightsReady returns if the insights of the repository can be returned.
+// Like the GitHub statistics API it responds with 204 for empty repositories
+// and with 202 while the insights are computed in the background.
+func insightsReady(ctx *context.APIContext) bool {
+	repo := ctx.Repo.Repository
+	if repo.IsEmpty {
+		ctx.Status(http.StatusNoContent)
+		return false
+	}
+	has, err := repo.HasInsights()
+	if err != nil {
+		ctx.InternalServerError(err)
+		return false
+	}
+	if !has {
+		if err := stats_indexer.UpdateRepoIndexer(repo); err != nil {
+			log.Error("stats_indexer.UpdateRepoIndexer(%d) failed: %v", repo.ID, err)
+		}
+		ctx.Status(http.StatusAccepted)
+		return false
+	}
+	return true
+}
+
+// GetContributorStats returns the weekly commits of the contributors
+func GetContributorStats(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stats/contributors repository repoGetContributorStats
+	// ---
+	// summary: Get the weekly additions, deletions and commits of the contributors to the default branch
+	// produces:
+	//   - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ContributorStatsList"
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if !insightsReady(ctx) {
+		return
+	}
+
+	stats, err := models.GetRepoContributorStats(ctx.Repo.Repository)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	apiStats := make([]*api.ContributorStats, 0, len(stats))
+	for _, contributor := range stats {
+		apiStats = append(apiStats, convert.ToContributorStats(contributor, ctx.IsSigned, ctx.User != nil && ctx.User.IsAdmin))
+	}
+	ctx.JSON(http.StatusOK, apiStats)
+}
+
+// GetCommitActivity returns the daily commits in the last year
+func GetCommitActivity(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stats/commit_activity repository repoGetCommitActivity
+	// ---
+	// summary: Get the daily commits to the default branch in the last 52 weeks
+	// produces:
+	//   - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CommitActivityList"
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if !insightsReady(ctx) {
+		return
+	}
+
+	activity, err := models.GetRepoCommitActivity(ctx.Repo.Repository)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	apiActivity := make([]*api.CommitActivity, 0, len(activity))
+	for _, week := range activity {
+		apiActivity = append(apiActivity, &api.CommitActivity{
+			Days:  week.Days[:],
+			Total: week.Total,
+			Week:  int64(week.Week),
+		})
+	}
+	ctx.JSON(http.StatusOK, apiActivity)
+}
+
+// GetCodeFrequency returns the weekly additions and deletions
+func GetCodeFrequency(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stats/code_frequency repository repoGetCodeFrequency
+	// ---
+	// summary: Get the weekly additions and deletions on the default branch
+	// description: Every week is returned as an array of the unix timestamp of its start, the additions and the negative deletions.
+	// produces:
+	//   - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CodeFrequency"
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if !insightsReady(ctx) {
+		return
+	}
+
+	weeks, err := models.GetRepoCodeFrequency(ctx.Repo.Repository)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	frequency := make([][]int64, 0, len(weeks))
+	for _, week := range weeks {
+		frequency = append(frequency, []int64{int64(week.Week), week.Additions, -week.Deletions})
+	}
+	ctx.JSON(http.StatusOK, frequency)
+}
+
+// GetParticipation returns the weekly commits of all contributors and the owner
+func GetParticipation(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stats/participation repository repoGetParticipation
+	// ---
+	// summary: Get the weekly commits to the default branch in the last 52 weeks by all contributors and by the owner
+	// produces:
+	//   - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ParticipationStats"
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if !insightsReady(ctx) {
+		return
+	}
+
+	all, owner, err := models.GetRepoParticipation(ctx.Repo.Repository)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &api.ParticipationStats{
+		All:   all,
+		Owner: owner,
+	})
+}
+
+// GetPunchCard returns the commits by the hour of the week
+func GetPunchCard(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stats/punch_card repository repoGetPunchCard
+	// ---
+	// summary: Get the commits to the default branch by the hour of the week
+	// description: Every hour is returned as an array of the day of the week from 0 for Sunday, the hour and the number of commits.
+	// produces:
+	//   - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PunchCard"
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if !insightsReady(ctx) {
+		return
+	}
+
+	hours, err := models.GetRepoPunchCard(ctx.Repo.Repository)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	var commits [7][24]int64
+	for _, hour := range hours {
+		commits[hour.Weekday][hour.Hour] = hour.Commits
+	}
+	punchCard := make([][]int64, 0, 7*24)
+	for weekday := range commits {
+		for hour := range commits[weekday] {
+			punchCard = append(punchCard, []int64{int64(weekday), int64(hour), commits[weekday][hour]})
+		}
+	}
+	ctx.JSON(http.StatusOK, punchCard)
+}
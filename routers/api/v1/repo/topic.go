@@ -5,6 +5,7 @@
 package repo
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -13,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/log"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
@@ -294,3 +296,189 @@ func TopicSearch(ctx *context.APIContext) {
 		"topics": topicResponses,
 	})
 }
+
+// ListFeaturedTopics list the featured topics
+func ListFeaturedTopics(ctx *context.APIContext) {
+	// swagger:operation GET /topics/featured repository topicListFeatured
+	// ---
+	// summary: List the topics featured by the site administrators
+	// produces:
+	//   - application/json
+	// parameters:
+	//   - name: page
+	//     in: query
+	//     description: page number of results to return (1-based)
+	//     type: integer
+	//   - name: limit
+	//     in: query
+	//     description: page size of results
+	//     type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TopicListResponse"
+
+	topics, err := models.FindTopics(&models.FindTopicOptions{
+		ListOptions:  utils.GetListOptions(ctx),
+		FeaturedOnly: true,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindTopics", err)
+		return
+	}
+
+	topicResponses := make([]*api.TopicResponse, len(topics))
+	for i, topic := range topics {
+		topicResponses[i] = convert.ToTopicResponse(topic)
+	}
+	ctx.JSON(http.StatusOK, topicResponses)
+}
+
+// getTopicByParams loads the topic named by the :topic parameter
+func getTopicByParams(ctx *context.APIContext) *models.Topic {
+	topic, err := models.GetTopicByName(strings.ToLower(ctx.Params(":topic")))
+	if err != nil {
+		if models.IsErrTopicNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetTopicByName", err)
+		}
+		return nil
+	}
+	return topic
+}
+
+// GetTopic get a topic
+func GetTopic(ctx *context.APIContext) {
+	// swagger:operation GET /topics/{topic} repository topicGet
+	// ---
+	// summary: Get a topic with its description and logo
+	// produces:
+	//   - application/json
+	// parameters:
+	// - name: topic
+	//   in: path
+	//   description: name of the topic
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Topic"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	topic := getTopicByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToTopicResponse(topic))
+}
+
+// EditTopic edit the curation of a topic
+func EditTopic(ctx *context.APIContext, form api.EditTopicOption) {
+	// swagger:operation PATCH /topics/{topic} repository topicEdit
+	// ---
+	// summary: Edit the description, logo and featured state of a topic
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: topic
+	//   in: path
+	//   description: name of the topic
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditTopicOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Topic"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	topic := getTopicByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Description != nil {
+		topic.Description = strings.TrimSpace(*form.Description)
+	}
+	if form.Logo != nil {
+		logo := strings.TrimSpace(*form.Logo)
+		if logo != "" && !validation.IsValidURL(logo) {
+			ctx.Error(http.StatusUnprocessableEntity, "IsValidURL", fmt.Errorf("invalid logo URL: %s", logo))
+			return
+		}
+		topic.Logo = logo
+	}
+	if form.Featured != nil {
+		topic.IsFeatured = *form.Featured
+	}
+	if err := models.UpdateTopic(topic); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateTopic", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToTopicResponse(topic))
+}
+
+// MergeTopic merge a topic into another one
+func MergeTopic(ctx *context.APIContext, form api.MergeTopicOption) {
+	// swagger:operation POST /topics/{topic}/merge repository topicMerge
+	// ---
+	// summary: Merge a topic into another one on all repositories and delete it
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: topic
+	//   in: path
+	//   description: name of the topic to merge
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/MergeTopicOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Topic"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	from := getTopicByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	to, err := models.GetTopicByName(strings.ToLower(strings.TrimSpace(form.Target)))
+	if err != nil {
+		if models.IsErrTopicNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "GetTopicByName", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetTopicByName", err)
+		}
+		return
+	}
+
+	if err := models.MergeTopics(from, to); err != nil {
+		if models.IsErrTopicMergeSelf(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "MergeTopics", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "MergeTopics", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToTopicResponse(to))
+}
@@ -7,7 +7,9 @@ package repo
 import (
 	"net/http"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/repofiles"
 )
 
@@ -66,3 +68,119 @@ func GetTree(ctx *context.APIContext) {
 		ctx.JSON(http.StatusOK, tree)
 	}
 }
+
+// GetDirectoryTree gets the tree of a directory of a repository up to a depth
+func GetDirectoryTree(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/tree/{filepath} repository repoGetDirectoryTree
+	// ---
+	// summary: Gets the tree of a directory of a repository, including the entries of its subdirectories up to a depth
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: filepath
+	//   in: path
+	//   description: path of the directory, the root directory if empty
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag. Default the repository’s default branch (usually master)"
+	//   type: string
+	//   required: false
+	// - name: depth
+	//   in: query
+	//   description: number of directory levels to list, 1 lists the entries of the directory only
+	//   type: integer
+	//   required: false
+	// - name: page
+	//   in: query
+	//   description: page number; the 'truncated' field in the response will be true if there are still more items after this page, false if the last page
+	//   required: false
+	//   type: integer
+	// - name: per_page
+	//   in: query
+	//   description: number of items per page
+	//   required: false
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/GitTreeResponse"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	tree, err := repofiles.GetTreeByPath(ctx.Repo.Repository, ctx.QueryTrim("ref"), ctx.Params("*"),
+		ctx.QueryInt("depth"), ctx.QueryInt("page"), ctx.QueryInt("per_page"))
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("GetTreeByPath", err)
+		} else if models.IsErrFilenameInvalid(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "GetTreeByPath", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetTreeByPath", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, tree)
+}
+
+// GetLastCommits gets the commits which last changed a directory of a repository and its entries
+func GetLastCommits(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/last_commits/{filepath} repository repoGetLastCommits
+	// ---
+	// summary: Gets the commits which last changed a directory of a repository and each of its entries
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: filepath
+	//   in: path
+	//   description: path of the directory, the root directory if empty
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag. Default the repository’s default branch (usually master)"
+	//   type: string
+	//   required: false
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DirectoryLastCommits"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	lastCommits, err := repofiles.GetLastCommits(ctx.Repo.Repository, ctx.QueryTrim("ref"), ctx.Params("*"))
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("GetLastCommits", err)
+		} else if models.IsErrFilenameInvalid(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "GetLastCommits", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetLastCommits", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, lastCommits)
+}
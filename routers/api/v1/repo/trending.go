@@ -0,0 +1,138 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// getTrendingOptions returns the options of listing the trending repositories or topics from the
+// query, or nil after responding with an error if the period is unknown
+func getTrendingOptions(ctx *context.APIContext) *models.TrendingOptions {
+	period, ok := models.ParseTrendingPeriod(ctx.Query("period"))
+	if !ok {
+		ctx.Error(http.StatusUnprocessableEntity, "ParseTrendingPeriod", fmt.Errorf("unknown period: %s", ctx.Query("period")))
+		return nil
+	}
+	return &models.TrendingOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		Period:      period,
+		Actor:       ctx.User,
+		Language:    strings.TrimSpace(ctx.Query("language")),
+		Topic:       strings.TrimSpace(ctx.Query("topic")),
+	}
+}
+
+// ListTrending lists the trending repositories
+func ListTrending(ctx *context.APIContext) {
+	// swagger:operation GET /repos/trending repository repoListTrending
+	// ---
+	// summary: List the trending repositories by their new stars, forks and activity, most trending first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: period
+	//   in: query
+	//   description: period of the new stars, forks and activity, daily by default
+	//   type: string
+	//   enum: [daily, weekly, monthly]
+	// - name: language
+	//   in: query
+	//   description: primary language of the repositories
+	//   type: string
+	// - name: topic
+	//   in: query
+	//   description: topic of the repositories
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TrendingRepositoryList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts := getTrendingOptions(ctx)
+	if ctx.Written() {
+		return
+	}
+	trendings, count, err := models.SearchTrendingRepos(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SearchTrendingRepos", err)
+		return
+	}
+
+	apiTrendings := make([]*api.TrendingRepository, len(trendings))
+	for i := range trendings {
+		access, err := models.AccessLevel(ctx.User, trendings[i].Repo)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "AccessLevel", err)
+			return
+		}
+		apiTrendings[i] = convert.ToTrendingRepository(trendings[i], access)
+	}
+	ctx.SetLinkHeader(int(count), opts.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.JSON(http.StatusOK, &apiTrendings)
+}
+
+// ListTrendingTopics lists the topics of the trending repositories
+func ListTrendingTopics(ctx *context.APIContext) {
+	// swagger:operation GET /topics/trending repository topicListTrending
+	// ---
+	// summary: List the topics of the trending repositories by the total score of their repositories
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: period
+	//   in: query
+	//   description: period of the new stars, forks and activity, daily by default
+	//   type: string
+	//   enum: [daily, weekly, monthly]
+	// - name: language
+	//   in: query
+	//   description: primary language of the repositories
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: number of topics to return
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TrendingTopicList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts := getTrendingOptions(ctx)
+	if ctx.Written() {
+		return
+	}
+	opts.Topic = ""
+	topics, err := models.GetTrendingTopics(opts, opts.PageSize)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetTrendingTopics", err)
+		return
+	}
+
+	apiTopics := make([]*api.TrendingTopic, len(topics))
+	for i := range topics {
+		apiTopics[i] = convert.ToTrendingTopic(topics[i])
+	}
+	ctx.JSON(http.StatusOK, &apiTopics)
+}
@@ -0,0 +1,281 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListWorkflowStates list all the workflow states of a repository
+func ListWorkflowStates(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/workflow_states issue issueListWorkflowStates
+	// ---
+	// summary: Get all of a repository's issue workflow states
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WorkflowStateList"
+
+	states, err := models.GetWorkflowStatesByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetWorkflowStatesByRepoID", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToWorkflowStateList(states))
+}
+
+// GetWorkflowState get a workflow state by repository and id
+func GetWorkflowState(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/workflow_states/{id} issue issueGetWorkflowState
+	// ---
+	// summary: Get a single issue workflow state
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the workflow state to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WorkflowState"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	state, err := models.GetWorkflowStateInRepoByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrWorkflowStateNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetWorkflowStateInRepoByID", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToWorkflowState(state))
+}
+
+// CreateWorkflowState create a workflow state for a repository
+func CreateWorkflowState(ctx *context.APIContext, form api.CreateWorkflowStateOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/workflow_states issue issueCreateWorkflowState
+	// ---
+	// summary: Create an issue workflow state
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateWorkflowStateOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/WorkflowState"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	color := normalizeWorkflowStateColor(form.Color)
+	if !models.LabelColorPattern.MatchString(color) {
+		ctx.Error(http.StatusUnprocessableEntity, "ColorPattern", fmt.Errorf("bad color code: %s", form.Color))
+		return
+	}
+
+	state := &models.WorkflowState{
+		RepoID:      ctx.Repo.Repository.ID,
+		Name:        form.Name,
+		Description: form.Description,
+		Color:       color,
+		IsClosed:    form.IsClosed,
+		Sort:        form.Sort,
+	}
+	if err := models.NewWorkflowState(state); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewWorkflowState", err)
+		return
+	}
+
+	if len(form.AllowedTransitions) > 0 {
+		if err := models.SetWorkflowStateTransitions(state, form.AllowedTransitions); err != nil {
+			if models.IsErrWorkflowStateNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "SetWorkflowStateTransitions", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "SetWorkflowStateTransitions", err)
+			}
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToWorkflowState(state))
+}
+
+// EditWorkflowState modify a workflow state of a repository
+func EditWorkflowState(ctx *context.APIContext, form api.EditWorkflowStateOption) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/workflow_states/{id} issue issueEditWorkflowState
+	// ---
+	// summary: Update an issue workflow state
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the workflow state to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditWorkflowStateOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WorkflowState"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	state, err := models.GetWorkflowStateInRepoByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrWorkflowStateNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetWorkflowStateInRepoByID", err)
+		}
+		return
+	}
+
+	if form.Name != nil {
+		state.Name = *form.Name
+	}
+	if form.Color != nil {
+		state.Color = normalizeWorkflowStateColor(*form.Color)
+		if !models.LabelColorPattern.MatchString(state.Color) {
+			ctx.Error(http.StatusUnprocessableEntity, "ColorPattern", fmt.Errorf("bad color code: %s", state.Color))
+			return
+		}
+	}
+	if form.Description != nil {
+		state.Description = *form.Description
+	}
+	if form.IsClosed != nil {
+		state.IsClosed = *form.IsClosed
+	}
+	if form.Sort != nil {
+		state.Sort = *form.Sort
+	}
+	if err := models.UpdateWorkflowState(state); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateWorkflowState", err)
+		return
+	}
+
+	if form.AllowedTransitions != nil {
+		if err := models.SetWorkflowStateTransitions(state, form.AllowedTransitions); err != nil {
+			if models.IsErrWorkflowStateNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "SetWorkflowStateTransitions", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "SetWorkflowStateTransitions", err)
+			}
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToWorkflowState(state))
+}
+
+// DeleteWorkflowState delete a workflow state of a repository
+func DeleteWorkflowState(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/workflow_states/{id} issue issueDeleteWorkflowState
+	// ---
+	// summary: Delete an issue workflow state
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the workflow state to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+
+	if err := models.DeleteWorkflowState(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id")); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteWorkflowState", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func normalizeWorkflowStateColor(color string) string {
+	color = strings.Trim(color, " ")
+	if len(color) == 6 {
+		color = "#" + color
+	}
+	return color
+}
@@ -0,0 +1,532 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package snippet
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/user"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	snippet_service "code.gitea.io/gitea/services/snippet"
+)
+
+// getSnippetByParams returns the snippet whose ID is in the URL, which the doer can see
+func getSnippetByParams(ctx *context.APIContext) *models.Snippet {
+	s, err := models.GetSnippetByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrSnippetNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetSnippetByID", err)
+		}
+		return nil
+	}
+	if !s.CanBeReadBy(ctx.User) {
+		ctx.NotFound()
+		return nil
+	}
+	return s
+}
+
+// getEditableSnippetByParams returns the snippet whose ID is in the URL, which the doer can change
+func getEditableSnippetByParams(ctx *context.APIContext) *models.Snippet {
+	s := getSnippetByParams(ctx)
+	if ctx.Written() {
+		return nil
+	}
+	if !s.CanBeEditedBy(ctx.User) {
+		ctx.Error(http.StatusForbidden, "", "only the owner of the snippet can change it")
+		return nil
+	}
+	return s
+}
+
+func toServiceFiles(files []*api.SnippetFileOption) []*snippet_service.File {
+	serviceFiles := make([]*snippet_service.File, len(files))
+	for i, f := range files {
+		serviceFiles[i] = &snippet_service.File{Name: f.Name, Content: f.Content}
+	}
+	return serviceFiles
+}
+
+func handleUpdateError(ctx *context.APIContext, name string, err error) {
+	if snippet_service.IsErrInvalidFiles(err) {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+	ctx.Error(http.StatusInternalServerError, name, err)
+}
+
+func listSnippets(ctx *context.APIContext, ownerID int64) {
+	listOptions := utils.GetListOptions(ctx)
+	snippets, count, err := models.SearchSnippets(&models.SearchSnippetOptions{
+		ListOptions: listOptions,
+		Actor:       ctx.User,
+		OwnerID:     ownerID,
+		Keyword:     ctx.Query("q"),
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SearchSnippets", err)
+		return
+	}
+
+	apiSnippets := make([]*api.Snippet, len(snippets))
+	for i := range snippets {
+		apiSnippets[i] = convert.ToSnippet(snippets[i])
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.JSON(http.StatusOK, &apiSnippets)
+}
+
+// ListSnippets list the snippets visible to the doer
+func ListSnippets(ctx *context.APIContext) {
+	// swagger:operation GET /snippets snippet snippetList
+	// ---
+	// summary: List the snippets visible to the authenticated user, most recently updated first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: q
+	//   in: query
+	//   description: keyword searched in the titles and descriptions
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SnippetList"
+
+	listSnippets(ctx, 0)
+}
+
+// ListUserSnippets list the snippets of the given user
+func ListUserSnippets(ctx *context.APIContext) {
+	// swagger:operation GET /users/{username}/snippets user userListSnippets
+	// ---
+	// summary: List the snippets of the given user visible to the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of user
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SnippetList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	owner := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	listSnippets(ctx, owner.ID)
+}
+
+// CreateSnippet create a snippet
+func CreateSnippet(ctx *context.APIContext, form api.CreateSnippetOption) {
+	// swagger:operation POST /snippets snippet snippetCreate
+	// ---
+	// summary: Create a snippet
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateSnippetOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Snippet"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	s, err := snippet_service.Create(ctx.User, snippet_service.Options{
+		Title:       form.Title,
+		Description: form.Description,
+		Visibility:  api.VisibilityModes[form.Visibility],
+		Files:       toServiceFiles(form.Files),
+	})
+	if err != nil {
+		handleUpdateError(ctx, "Create", err)
+		return
+	}
+	writeSnippet(ctx, http.StatusCreated, s, "")
+}
+
+// writeSnippet responds with the snippet and the files of its revision
+func writeSnippet(ctx *context.APIContext, status int, s *models.Snippet, revision string) {
+	files, err := snippet_service.GetFiles(s, revision)
+	if err != nil {
+		if snippet_service.IsErrRevisionNotExist(err) {
+			ctx.NotFound()
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "GetFiles", err)
+		return
+	}
+
+	apiSnippet := convert.ToSnippet(s)
+	apiSnippet.Files = make([]*api.SnippetFile, len(files))
+	for i, f := range files {
+		rawURL := s.HTMLURL() + "/raw/" + url.PathEscape(f.Name)
+		if revision != "" {
+			rawURL += "?revision=" + url.QueryEscape(revision)
+		}
+		apiSnippet.Files[i] = &api.SnippetFile{
+			Name:    f.Name,
+			Size:    int64(len(f.Content)),
+			Content: f.Content,
+			RawURL:  rawURL,
+		}
+	}
+	ctx.JSON(status, apiSnippet)
+}
+
+// GetSnippet get a snippet
+func GetSnippet(ctx *context.APIContext) {
+	// swagger:operation GET /snippets/{id} snippet snippetGet
+	// ---
+	// summary: Get a snippet with its files
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the snippet
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: revision
+	//   in: query
+	//   description: SHA of the revision of the files, the latest revision if empty
+	//   type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Snippet"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	s := getSnippetByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	writeSnippet(ctx, http.StatusOK, s, ctx.Query("revision"))
+}
+
+// EditSnippet edit a snippet
+func EditSnippet(ctx *context.APIContext, form api.EditSnippetOption) {
+	// swagger:operation PATCH /snippets/{id} snippet snippetEdit
+	// ---
+	// summary: Edit a snippet, changing its files commits a new revision
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the snippet
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditSnippetOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Snippet"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	s := getEditableSnippetByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	opts := snippet_service.Options{
+		Title:       s.Title,
+		Description: s.Description,
+		Visibility:  s.Visibility,
+	}
+	if form.Title != nil {
+		opts.Title = *form.Title
+	}
+	if form.Description != nil {
+		opts.Description = *form.Description
+	}
+	if form.Visibility != "" {
+		opts.Visibility = api.VisibilityModes[form.Visibility]
+	}
+	if form.Files != nil {
+		opts.Files = toServiceFiles(form.Files)
+	}
+	if err := snippet_service.Update(ctx.User, s, opts); err != nil {
+		handleUpdateError(ctx, "Update", err)
+		return
+	}
+	writeSnippet(ctx, http.StatusOK, s, "")
+}
+
+// DeleteSnippet delete a snippet
+func DeleteSnippet(ctx *context.APIContext) {
+	// swagger:operation DELETE /snippets/{id} snippet snippetDelete
+	// ---
+	// summary: Delete a snippet with its revisions and comments
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the snippet
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	s := getEditableSnippetByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := snippet_service.Delete(s); err != nil {
+		ctx.Error(http.StatusInternalServerError, "Delete", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListSnippetRevisions list the revisions of a snippet
+func ListSnippetRevisions(ctx *context.APIContext) {
+	// swagger:operation GET /snippets/{id}/revisions snippet snippetListRevisions
+	// ---
+	// summary: List the revisions of the files of a snippet, latest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the snippet
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SnippetRevisionList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	s := getSnippetByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	if listOptions.Page <= 0 {
+		listOptions.Page = 1
+	}
+	revisions, count, err := snippet_service.GetRevisions(s, listOptions.Page, listOptions.PageSize)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRevisions", err)
+		return
+	}
+
+	apiRevisions := make([]*api.SnippetRevision, len(revisions))
+	for i := range revisions {
+		apiRevisions[i] = convert.ToSnippetRevision(revisions[i])
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.JSON(http.StatusOK, &apiRevisions)
+}
+
+// ListSnippetComments list the comments of a snippet
+func ListSnippetComments(ctx *context.APIContext) {
+	// swagger:operation GET /snippets/{id}/comments snippet snippetListComments
+	// ---
+	// summary: List the comments of a snippet
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the snippet
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SnippetCommentList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	s := getSnippetByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	if listOptions.Page <= 0 {
+		listOptions.Page = 1
+	}
+	comments, err := models.GetSnippetComments(s.ID, listOptions)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetSnippetComments", err)
+		return
+	}
+
+	apiComments := make([]*api.SnippetComment, len(comments))
+	for i := range comments {
+		apiComments[i] = convert.ToSnippetComment(s, comments[i])
+	}
+
+	ctx.SetLinkHeader(s.NumComments, listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", s.NumComments))
+	ctx.JSON(http.StatusOK, &apiComments)
+}
+
+// CreateSnippetComment comment on a snippet
+func CreateSnippetComment(ctx *context.APIContext, form api.CreateSnippetCommentOption) {
+	// swagger:operation POST /snippets/{id}/comments snippet snippetCreateComment
+	// ---
+	// summary: Comment on a snippet
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the snippet
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateSnippetCommentOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/SnippetComment"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	s := getSnippetByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	c, err := models.CreateSnippetComment(s, ctx.User, form.Body)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CreateSnippetComment", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToSnippetComment(s, c))
+}
+
+// DeleteSnippetComment delete a comment of a snippet
+func DeleteSnippetComment(ctx *context.APIContext) {
+	// swagger:operation DELETE /snippets/{id}/comments/{comment_id} snippet snippetDeleteComment
+	// ---
+	// summary: Delete a comment of a snippet, the poster of the comment and the owner of the snippet can delete it
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the snippet
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: comment_id
+	//   in: path
+	//   description: id of the comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	s := getSnippetByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	c, err := models.GetSnippetCommentByID(s.ID, ctx.ParamsInt64(":comment_id"))
+	if err != nil {
+		if models.IsErrSnippetCommentNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetSnippetCommentByID", err)
+		}
+		return
+	}
+	if c.PosterID != ctx.User.ID && !s.CanBeEditedBy(ctx.User) {
+		ctx.Error(http.StatusForbidden, "", "only the poster of the comment or the owner of the snippet can delete it")
+		return
+	}
+
+	if err := models.DeleteSnippetComment(c); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteSnippetComment", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
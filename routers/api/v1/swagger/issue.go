@@ -22,6 +22,13 @@ type swaggerResponseIssueList struct {
 	Body []api.Issue `json:"body"`
 }
 
+// IssueGraph
+// swagger:response IssueGraph
+type swaggerResponseIssueGraph struct {
+	// in:body
+	Body api.IssueGraph `json:"body"`
+}
+
 // Comment
 // swagger:response Comment
 type swaggerResponseComment struct {
@@ -50,6 +57,34 @@ type swaggerResponseLabelList struct {
 	Body []api.Label `json:"body"`
 }
 
+// WorkflowState
+// swagger:response WorkflowState
+type swaggerResponseWorkflowState struct {
+	// in:body
+	Body api.WorkflowState `json:"body"`
+}
+
+// WorkflowStateList
+// swagger:response WorkflowStateList
+type swaggerResponseWorkflowStateList struct {
+	// in:body
+	Body []api.WorkflowState `json:"body"`
+}
+
+// Epic
+// swagger:response Epic
+type swaggerResponseEpic struct {
+	// in:body
+	Body api.Epic `json:"body"`
+}
+
+// EpicList
+// swagger:response EpicList
+type swaggerResponseEpicList struct {
+	// in:body
+	Body []api.Epic `json:"body"`
+}
+
 // Milestone
 // swagger:response Milestone
 type swaggerResponseMilestone struct {
@@ -112,3 +147,10 @@ type swaggerReactionList struct {
 	// in:body
 	Body []api.Reaction `json:"body"`
 }
+
+// LabelSyncResult
+// swagger:response LabelSyncResult
+type swaggerResponseLabelSyncResult struct {
+	// in:body
+	Body api.LabelSyncResult `json:"body"`
+}
@@ -21,3 +21,73 @@ type swaggerResponseStringSlice struct {
 	// in:body
 	Body []string `json:"body"`
 }
+
+// CustomEmoji
+// swagger:response CustomEmoji
+type swaggerResponseCustomEmoji struct {
+	// in:body
+	Body api.CustomEmoji `json:"body"`
+}
+
+// CustomEmojiList
+// swagger:response CustomEmojiList
+type swaggerResponseCustomEmojiList struct {
+	// in:body
+	Body []api.CustomEmoji `json:"body"`
+}
+
+// Theme
+// swagger:response Theme
+type swaggerResponseTheme struct {
+	// in:body
+	Body api.Theme `json:"body"`
+}
+
+// ThemeList
+// swagger:response ThemeList
+type swaggerResponseThemeList struct {
+	// in:body
+	Body []api.Theme `json:"body"`
+}
+
+// Badge
+// swagger:response Badge
+type swaggerResponseBadge struct {
+	// in:body
+	Body api.Badge `json:"body"`
+}
+
+// BadgeList
+// swagger:response BadgeList
+type swaggerResponseBadgeList struct {
+	// in:body
+	Body []api.Badge `json:"body"`
+}
+
+// InstanceStats
+// swagger:response InstanceStats
+type swaggerResponseInstanceStats struct {
+	// in:body
+	Body api.InstanceStats `json:"body"`
+}
+
+// InstanceHealth
+// swagger:response InstanceHealth
+type swaggerResponseInstanceHealth struct {
+	// in:body
+	Body api.InstanceHealth `json:"body"`
+}
+
+// MaintenanceStatus
+// swagger:response MaintenanceStatus
+type swaggerResponseMaintenanceStatus struct {
+	// in:body
+	Body api.MaintenanceStatus `json:"body"`
+}
+
+// ConfigReloadResult
+// swagger:response ConfigReloadResult
+type swaggerResponseConfigReloadResult struct {
+	// in:body
+	Body api.ConfigReloadResult `json:"body"`
+}
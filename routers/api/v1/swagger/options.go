@@ -37,6 +37,8 @@ type swaggerParameterBodies struct {
 	EditIssueOption api.EditIssueOption
 	// in:body
 	EditDeadlineOption api.EditDeadlineOption
+	// in:body
+	SetIssueAnswerOption api.SetIssueAnswerOption
 
 	// in:body
 	CreateIssueCommentOption api.CreateIssueCommentOption
@@ -57,6 +59,9 @@ type swaggerParameterBodies struct {
 	// in:body
 	MarkdownOption api.MarkdownOption
 
+	// in:body
+	MailBounceOption api.MailBounceOption
+
 	// in:body
 	CreateMilestoneOption api.CreateMilestoneOption
 	// in:body
@@ -149,4 +154,101 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	SubmitPullReviewOptions api.SubmitPullReviewOptions
+
+	// in:body
+	CreateWorkflowStateOption api.CreateWorkflowStateOption
+	// in:body
+	EditWorkflowStateOption api.EditWorkflowStateOption
+
+	// in:body
+	CreateEpicOption api.CreateEpicOption
+	// in:body
+	EditEpicOption api.EditEpicOption
+
+	// in:body
+	CreateProjectOption api.CreateProjectOption
+	// in:body
+	EditProjectOption api.EditProjectOption
+	// in:body
+	CreateProjectBoardOption api.CreateProjectBoardOption
+	// in:body
+	AddProjectIssuesOption api.AddProjectIssuesOption
+	// in:body
+	MoveProjectIssueOption api.MoveProjectIssueOption
+	// in:body
+	CreateProjectRuleOption api.CreateProjectRuleOption
+
+	// in:body
+	EditOrgMembershipOption api.EditOrgMembershipOption
+
+	// in:body
+	CreateOrgBotOption api.CreateOrgBotOption
+	// in:body
+	EditOrgBotOption api.EditOrgBotOption
+
+	// in:body
+	CreateOrgRoleOption api.CreateOrgRoleOption
+	// in:body
+	EditOrgRoleOption api.EditOrgRoleOption
+
+	// in:body
+	CreateDiscussionCategoryOption api.CreateDiscussionCategoryOption
+	// in:body
+	CreateDiscussionOption api.CreateDiscussionOption
+	// in:body
+	CreateDiscussionReplyOption api.CreateDiscussionReplyOption
+
+	// in:body
+	EditMaintenanceOption api.EditMaintenanceOption
+
+	// in:body
+	EditPinnedReposOption api.EditPinnedReposOption
+
+	// in:body
+	CreateBadgeOption api.CreateBadgeOption
+
+	// in:body
+	EditBadgeOption api.EditBadgeOption
+
+	// in:body
+	CreateStarListOption api.CreateStarListOption
+
+	// in:body
+	EditStarListOption api.EditStarListOption
+
+	// in:body
+	EditTopicOption api.EditTopicOption
+
+	// in:body
+	MergeTopicOption api.MergeTopicOption
+
+	// in:body
+	CreateSnippetOption api.CreateSnippetOption
+
+	// in:body
+	EditSnippetOption api.EditSnippetOption
+
+	// in:body
+	CreateSnippetCommentOption api.CreateSnippetCommentOption
+
+	// in:body
+	RecordQuickActionOption api.RecordQuickActionOption
+
+	// in:body
+	EditDashboardLayoutOption api.EditDashboardLayoutOption
+
+	// in:body
+	CreateRepoCustomTabOption api.CreateRepoCustomTabOption
+
+	// in:body
+	EditRepoCustomTabOption api.EditRepoCustomTabOption
+
+	// in:body
+	CreateDeployTokenOption api.CreateDeployTokenOption
+
+	// in:body
+	CreateAutoLinkRuleOption api.CreateAutoLinkRuleOption
+
+	// in:body
+	EditAutoLinkRuleOption api.EditAutoLinkRuleOption
 }
@@ -35,3 +35,108 @@ type swaggerResponseTeamList struct {
 	// in:body
 	Body []api.Team `json:"body"`
 }
+
+// Project
+// swagger:response Project
+type swaggerResponseProject struct {
+	// in:body
+	Body api.Project `json:"body"`
+}
+
+// ProjectList
+// swagger:response ProjectList
+type swaggerResponseProjectList struct {
+	// in:body
+	Body []api.Project `json:"body"`
+}
+
+// ProjectBoard
+// swagger:response ProjectBoard
+type swaggerResponseProjectBoard struct {
+	// in:body
+	Body api.ProjectBoard `json:"body"`
+}
+
+// ProjectBoardList
+// swagger:response ProjectBoardList
+type swaggerResponseProjectBoardList struct {
+	// in:body
+	Body []api.ProjectBoard `json:"body"`
+}
+
+// ProjectIssueList
+// swagger:response ProjectIssueList
+type swaggerResponseProjectIssueList struct {
+	// in:body
+	Body []api.ProjectIssue `json:"body"`
+}
+
+// ProjectRule
+// swagger:response ProjectRule
+type swaggerResponseProjectRule struct {
+	// in:body
+	Body api.ProjectRule `json:"body"`
+}
+
+// ProjectRuleList
+// swagger:response ProjectRuleList
+type swaggerResponseProjectRuleList struct {
+	// in:body
+	Body []api.ProjectRule `json:"body"`
+}
+
+// OrgMembership
+// swagger:response OrgMembership
+type swaggerResponseOrgMembership struct {
+	// in:body
+	Body api.OrgMembership `json:"body"`
+}
+
+// OrgExternalCollaboratorList
+// swagger:response OrgExternalCollaboratorList
+type swaggerResponseOrgExternalCollaboratorList struct {
+	// in:body
+	Body []api.OrgExternalCollaborator `json:"body"`
+}
+
+// OrgBot
+// swagger:response OrgBot
+type swaggerResponseOrgBot struct {
+	// in:body
+	Body api.OrgBot `json:"body"`
+}
+
+// OrgBotList
+// swagger:response OrgBotList
+type swaggerResponseOrgBotList struct {
+	// in:body
+	Body []api.OrgBot `json:"body"`
+}
+
+// OrgRole
+// swagger:response OrgRole
+type swaggerResponseOrgRole struct {
+	// in:body
+	Body api.OrgRole `json:"body"`
+}
+
+// OrgRoleList
+// swagger:response OrgRoleList
+type swaggerResponseOrgRoleList struct {
+	// in:body
+	Body []api.OrgRole `json:"body"`
+}
+
+// OrgTopic
+// swagger:response OrgTopic
+type swaggerResponseOrgTopic struct {
+	// in:body
+	Body api.OrgTopic `json:"body"`
+}
+
+// OrgTopicList
+// swagger:response OrgTopicList
+type swaggerResponseOrgTopicList struct {
+	// in:body
+	Body []api.OrgTopic `json:"body"`
+}
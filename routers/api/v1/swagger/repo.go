@@ -36,6 +36,13 @@ type swaggerResponseBranchList struct {
 	Body []api.Branch `json:"body"`
 }
 
+// BranchDivergenceList
+// swagger:response BranchDivergenceList
+type swaggerResponseBranchDivergenceList struct {
+	// in:body
+	Body []api.BranchDivergence `json:"body"`
+}
+
 // BranchProtection
 // swagger:response BranchProtection
 type swaggerResponseBranchProtection struct {
@@ -99,6 +106,20 @@ type swaggerResponseHookList struct {
 	Body []api.Hook `json:"body"`
 }
 
+// HookDelivery
+// swagger:response HookDelivery
+type swaggerResponseHookDelivery struct {
+	// in:body
+	Body api.HookDelivery `json:"body"`
+}
+
+// HookDeliveryList
+// swagger:response HookDeliveryList
+type swaggerResponseHookDeliveryList struct {
+	// in:body
+	Body []api.HookDelivery `json:"body"`
+}
+
 // GitHook
 // swagger:response GitHook
 type swaggerResponseGitHook struct {
@@ -141,6 +162,13 @@ type swaggerResponsePullRequestList struct {
 	Body []api.PullRequest `json:"body"`
 }
 
+// PullRequestTemplateList
+// swagger:response PullRequestTemplateList
+type swaggerResponsePullRequestTemplateList struct {
+	// in:body
+	Body []api.PullRequestTemplate `json:"body"`
+}
+
 // PullReview
 // swagger:response PullReview
 type swaggerResponsePullReview struct {
@@ -190,6 +218,20 @@ type swaggerResponseWatchInfo struct {
 	Body api.WatchInfo `json:"body"`
 }
 
+// WatchPathInfo
+// swagger:response WatchPathInfo
+type swaggerResponseWatchPathInfo struct {
+	// in:body
+	Body api.WatchPathInfo `json:"body"`
+}
+
+// WatchPathInfoList
+// swagger:response WatchPathInfoList
+type swaggerResponseWatchPathInfoList struct {
+	// in:body
+	Body []api.WatchPathInfo `json:"body"`
+}
+
 // SearchResults
 // swagger:response SearchResults
 type swaggerResponseSearchResults struct {
@@ -218,6 +260,13 @@ type swaggerGitTreeResponse struct {
 	Body api.GitTreeResponse `json:"body"`
 }
 
+// DirectoryLastCommits
+// swagger:response DirectoryLastCommits
+type swaggerDirectoryLastCommits struct {
+	// in: body
+	Body api.DirectoryLastCommits `json:"body"`
+}
+
 // GitBlobResponse
 // swagger:response GitBlobResponse
 type swaggerGitBlobResponse struct {
@@ -289,6 +338,13 @@ type swaggerFileDeleteResponse struct {
 	Body api.FileDeleteResponse `json:"body"`
 }
 
+// Topic
+// swagger:response Topic
+type swaggerTopic struct {
+	// in: body
+	Body api.TopicResponse `json:"body"`
+}
+
 // TopicListResponse
 // swagger:response TopicListResponse
 type swaggerTopicListResponse struct {
@@ -309,3 +365,178 @@ type swaggerLanguageStatistics struct {
 	// in: body
 	Body map[string]int64 `json:"body"`
 }
+
+// LanguageStatisticsByDirectory
+// swagger:response LanguageStatisticsByDirectory
+type swaggerLanguageStatisticsByDirectory struct {
+	// in: body
+	Body map[string]map[string]int64 `json:"body"`
+}
+
+// ContributorStatsList
+// swagger:response ContributorStatsList
+type swaggerContributorStatsList struct {
+	// in: body
+	Body []api.ContributorStats `json:"body"`
+}
+
+// CommitActivityList
+// swagger:response CommitActivityList
+type swaggerCommitActivityList struct {
+	// in: body
+	Body []api.CommitActivity `json:"body"`
+}
+
+// CodeFrequency
+// swagger:response CodeFrequency
+type swaggerCodeFrequency struct {
+	// in: body
+	Body [][]int64 `json:"body"`
+}
+
+// ParticipationStats
+// swagger:response ParticipationStats
+type swaggerParticipationStats struct {
+	// in: body
+	Body api.ParticipationStats `json:"body"`
+}
+
+// PunchCard
+// swagger:response PunchCard
+type swaggerPunchCard struct {
+	// in: body
+	Body [][]int64 `json:"body"`
+}
+
+// DiscussionCategory
+// swagger:response DiscussionCategory
+type swaggerResponseDiscussionCategory struct {
+	// in:body
+	Body api.DiscussionCategory `json:"body"`
+}
+
+// DiscussionCategoryList
+// swagger:response DiscussionCategoryList
+type swaggerResponseDiscussionCategoryList struct {
+	// in:body
+	Body []api.DiscussionCategory `json:"body"`
+}
+
+// Discussion
+// swagger:response Discussion
+type swaggerResponseDiscussion struct {
+	// in:body
+	Body api.Discussion `json:"body"`
+}
+
+// DiscussionList
+// swagger:response DiscussionList
+type swaggerResponseDiscussionList struct {
+	// in:body
+	Body []api.Discussion `json:"body"`
+}
+
+// DiscussionReply
+// swagger:response DiscussionReply
+type swaggerResponseDiscussionReply struct {
+	// in:body
+	Body api.DiscussionReply `json:"body"`
+}
+
+// DiscussionReplyList
+// swagger:response DiscussionReplyList
+type swaggerResponseDiscussionReplyList struct {
+	// in:body
+	Body []api.DiscussionReply `json:"body"`
+}
+
+// ActivityContributorList
+// swagger:response ActivityContributorList
+type swaggerResponseActivityContributorList struct {
+	// in:body
+	Body []api.ActivityContributor `json:"body"`
+}
+
+// TrendingRepositoryList
+// swagger:response TrendingRepositoryList
+type swaggerResponseTrendingRepositoryList struct {
+	// in:body
+	Body []api.TrendingRepository `json:"body"`
+}
+
+// TrendingTopicList
+// swagger:response TrendingTopicList
+type swaggerResponseTrendingTopicList struct {
+	// in:body
+	Body []api.TrendingTopic `json:"body"`
+}
+
+// NetworkRepositoryList
+// swagger:response NetworkRepositoryList
+type swaggerResponseNetworkRepositoryList struct {
+	// in:body
+	Body []api.NetworkRepository `json:"body"`
+}
+
+// DevEnvironment
+// swagger:response DevEnvironment
+type swaggerResponseDevEnvironment struct {
+	// in:body
+	Body api.DevEnvironment `json:"body"`
+}
+
+// AutocompleteUserList
+// swagger:response AutocompleteUserList
+type swaggerResponseAutocompleteUserList struct {
+	// in:body
+	Body []api.AutocompleteUser `json:"body"`
+}
+
+// AutocompleteIssueList
+// swagger:response AutocompleteIssueList
+type swaggerResponseAutocompleteIssueList struct {
+	// in:body
+	Body []api.AutocompleteIssue `json:"body"`
+}
+
+// RepoCustomTab
+// swagger:response RepoCustomTab
+type swaggerResponseRepoCustomTab struct {
+	// in:body
+	Body api.RepoCustomTab `json:"body"`
+}
+
+// RepoCustomTabList
+// swagger:response RepoCustomTabList
+type swaggerResponseRepoCustomTabList struct {
+	// in:body
+	Body []api.RepoCustomTab `json:"body"`
+}
+
+// DeployToken
+// swagger:response DeployToken
+type swaggerResponseDeployToken struct {
+	// in:body
+	Body api.DeployToken `json:"body"`
+}
+
+// DeployTokenList
+// swagger:response DeployTokenList
+type swaggerResponseDeployTokenList struct {
+	// in:body
+	Body []api.DeployToken `json:"body"`
+}
+
+// AutoLinkRule
+// swagger:response AutoLinkRule
+type swaggerResponseAutoLinkRule struct {
+	// in:body
+	Body api.AutoLinkRule `json:"body"`
+}
+
+// AutoLinkRuleList
+// swagger:response AutoLinkRuleList
+type swaggerResponseAutoLinkRuleList struct {
+	// in:body
+	Body []api.AutoLinkRule `json:"body"`
+}
@@ -42,3 +42,38 @@ type swaggerResponseUserHeatmapData struct {
 	// in:body
 	Body []models.UserHeatmapData `json:"body"`
 }
+
+// StarList
+// swagger:response StarList
+type swaggerResponseStarList struct {
+	// in:body
+	Body api.StarList `json:"body"`
+}
+
+// StarListList
+// swagger:response StarListList
+type swaggerResponseStarListList struct {
+	// in:body
+	Body []api.StarList `json:"body"`
+}
+
+// QuickActionList
+// swagger:response QuickActionList
+type swaggerResponseQuickActionList struct {
+	// in:body
+	Body []api.QuickAction `json:"body"`
+}
+
+// DashboardLayout
+// swagger:response DashboardLayout
+type swaggerResponseDashboardLayout struct {
+	// in:body
+	Body api.DashboardLayout `json:"body"`
+}
+
+// DashboardWidget
+// swagger:response DashboardWidget
+type swaggerResponseDashboardWidget struct {
+	// in:body
+	Body api.DashboardWidget `json:"body"`
+}
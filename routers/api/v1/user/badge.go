@@ -0,0 +1,50 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListUserBadges list the badges granted to the given user
+func ListUserBadges(ctx *context.APIContext) {
+	// swagger:operation GET /users/{username}/badges user userListBadges
+	// ---
+	// summary: List the badges granted to the given user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of user
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/BadgeList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	u := GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	badges, err := models.GetUserBadges(u.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserBadges", err)
+		return
+	}
+	apiBadges := make([]*api.Badge, len(badges))
+	for i := range badges {
+		apiBadges[i] = convert.ToBadge(badges[i])
+	}
+	ctx.JSON(http.StatusOK, &apiBadges)
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/dashboard"
+)
+
+// maxDashboardWidgetEntries is the maximum number of entries of a widget
+const maxDashboardWidgetEntries = 50
+
+func toDashboardLayout(u *models.User) *api.DashboardLayout {
+	return &api.DashboardLayout{
+		Widgets:   u.GetDashboardWidgets(),
+		Available: models.DashboardWidgets,
+	}
+}
+
+// GetDashboardLayout returns the widgets of the dashboard of the authenticated user
+func GetDashboardLayout(ctx *context.APIContext) {
+	// swagger:operation GET /user/dashboard user userGetDashboardLayout
+	// ---
+	// summary: Get the widgets of the dashboard of the authenticated user
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DashboardLayout"
+
+	ctx.JSON(http.StatusOK, toDashboardLayout(ctx.User))
+}
+
+// EditDashboardLayout changes the widgets of the dashboard of the authenticated user
+func EditDashboardLayout(ctx *context.APIContext, form api.EditDashboardLayoutOption) {
+	// swagger:operation PUT /user/dashboard user userEditDashboardLayout
+	// ---
+	// summary: Change the widgets of the dashboard of the authenticated user and their order
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditDashboardLayoutOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DashboardLayout"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if err := ctx.User.UpdateDashboardWidgets(form.Widgets); err != nil {
+		if models.IsErrInvalidDashboardWidget(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateDashboardWidgets", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, toDashboardLayout(ctx.User))
+}
+
+// GetDashboardWidget returns the content of a widget of the dashboard
+func GetDashboardWidget(ctx *context.APIContext) {
+	// swagger:operation GET /user/dashboard/widgets/{widget} user userGetDashboardWidget
+	// ---
+	// summary: Get the content of a widget of the dashboard of the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: widget
+	//   in: path
+	//   description: one of assigned_issues, review_requests, ci_failures, watched_releases and org_activity
+	//   type: string
+	//   required: true
+	// - name: limit
+	//   in: query
+	//   description: maximum number of entries, default 10
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DashboardWidget"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	widget := ctx.Params(":widget")
+	if !models.IsValidDashboardWidget(widget) {
+		ctx.NotFound()
+		return
+	}
+	limit := ctx.QueryInt("limit")
+	if limit > maxDashboardWidgetEntries {
+		limit = maxDashboardWidgetEntries
+	}
+
+	entries, err := dashboard.LoadWidget(ctx.User, widget, ctx.Locale.Language(), limit)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadWidget", err)
+		return
+	}
+
+	apiWidget := &api.DashboardWidget{
+		Type:    widget,
+		Entries: make([]*api.DashboardWidgetEntry, len(entries)),
+	}
+	for i, entry := range entries {
+		apiWidget.Entries[i] = &api.DashboardWidgetEntry{
+			Title:       entry.Title,
+			Description: entry.Description,
+			URL:         entry.Link,
+			Icon:        entry.Icon,
+			State:       entry.State,
+			Time:        entry.Time.AsTime(),
+		}
+	}
+	ctx.JSON(http.StatusOK, apiWidget)
+}
@@ -0,0 +1,166 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// listPinnedRepos lists the repositories pinned by the owner, which the
+// doer can access
+func listPinnedRepos(ctx *context.APIContext, owner *models.User) {
+	repos, err := models.GetPinnedRepos(owner.ID, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPinnedRepos", err)
+		return
+	}
+
+	apiRepos := make([]*api.Repository, len(repos))
+	for i := range repos {
+		access, err := models.AccessLevel(ctx.User, repos[i])
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "AccessLevel", err)
+			return
+		}
+		apiRepos[i] = repos[i].APIFormat(access)
+	}
+	ctx.JSON(http.StatusOK, &apiRepos)
+}
+
+// updatePinnedRepos replaces the repositories pinned by the owner
+func updatePinnedRepos(ctx *context.APIContext, owner *models.User, form api.EditPinnedReposOption) {
+	if err := models.SetPinnedRepos(owner, form.RepoIDs); err != nil {
+		if models.IsErrTooManyPinnedRepos(err) || models.IsErrPinnedRepoNotOwned(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SetPinnedRepos", err)
+		}
+		return
+	}
+	listPinnedRepos(ctx, owner)
+}
+
+// ListUserPinnedRepos list the repositories pinned by the given user
+func ListUserPinnedRepos(ctx *context.APIContext) {
+	// swagger:operation GET /users/{username}/pinned_repos user userListPinnedRepos
+	// ---
+	// summary: List the repos pinned on the profile of the given user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of user
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepositoryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	user := GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	listPinnedRepos(ctx, user)
+}
+
+// ListMyPinnedRepos list the repositories pinned by the authenticated user
+func ListMyPinnedRepos(ctx *context.APIContext) {
+	// swagger:operation GET /user/pinned_repos user userCurrentListPinnedRepos
+	// ---
+	// summary: List the repos pinned on the profile of the authenticated user
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepositoryList"
+
+	listPinnedRepos(ctx, ctx.User)
+}
+
+// UpdateMyPinnedRepos replaces the repositories pinned by the authenticated user
+func UpdateMyPinnedRepos(ctx *context.APIContext, form api.EditPinnedReposOption) {
+	// swagger:operation PUT /user/pinned_repos user userCurrentUpdatePinnedRepos
+	// ---
+	// summary: Replace the repos pinned on the profile of the authenticated user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditPinnedReposOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepositoryList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	updatePinnedRepos(ctx, ctx.User, form)
+}
+
+// ListOrgPinnedRepos list the repositories pinned by an organization
+func ListOrgPinnedRepos(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/pinned_repos organization orgListPinnedRepos
+	// ---
+	// summary: List the repos pinned on the profile of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepositoryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if !models.HasOrgVisible(ctx.Org.Organization, ctx.User) {
+		ctx.NotFound("HasOrgVisible", nil)
+		return
+	}
+	listPinnedRepos(ctx, ctx.Org.Organization)
+}
+
+// UpdateOrgPinnedRepos replaces the repositories pinned by an organization
+func UpdateOrgPinnedRepos(ctx *context.APIContext, form api.EditPinnedReposOption) {
+	// swagger:operation PUT /orgs/{org}/pinned_repos organization orgUpdatePinnedRepos
+	// ---
+	// summary: Replace the repos pinned on the profile of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditPinnedReposOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepositoryList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	updatePinnedRepos(ctx, ctx.Org.Organization, form)
+}
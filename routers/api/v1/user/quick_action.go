@@ -0,0 +1,114 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/quickaction"
+)
+
+// maxQuickActions is the maximum number of results of the command palette
+const maxQuickActions = 50
+
+// ListQuickActions searches the results of the command palette
+func ListQuickActions(ctx *context.APIContext) {
+	// swagger:operation GET /user/quick_actions user userListQuickActions
+	// ---
+	// summary: Search the repositories, issues, pull requests, users and actions of the command palette
+	// description: The results the authenticated user chose recently and often come first. Without
+	//   a keyword the recent results and the actions are returned.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: q
+	//   in: query
+	//   description: keyword, "#12" or "owner/repo#12" for an issue or a pull request
+	//   type: string
+	// - name: repo
+	//   in: query
+	//   description: full name of the repository the user is looking at, its actions are added
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results, default 10
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/QuickActionList"
+
+	opts := &quickaction.Options{
+		Keyword: ctx.Query("q"),
+		Limit:   ctx.QueryInt("limit"),
+	}
+	if opts.Limit > maxQuickActions {
+		opts.Limit = maxQuickActions
+	}
+	if fullName := ctx.Query("repo"); fullName != "" {
+		if parts := strings.SplitN(fullName, "/", 2); len(parts) == 2 {
+			repo, err := models.GetRepositoryByOwnerAndName(parts[0], parts[1])
+			if err != nil && !models.IsErrRepoNotExist(err) {
+				ctx.Error(http.StatusInternalServerError, "GetRepositoryByOwnerAndName", err)
+				return
+			}
+			opts.Repo = repo
+		}
+	}
+
+	items, err := quickaction.Search(ctx.User, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Search", err)
+		return
+	}
+
+	apiItems := make([]*api.QuickAction, len(items))
+	for i, item := range items {
+		apiItems[i] = &api.QuickAction{
+			Type:        item.Type,
+			Key:         item.Key,
+			Title:       item.Title,
+			Description: item.Description,
+			URL:         item.Link,
+			Icon:        item.Icon,
+			Recent:      item.Recent,
+		}
+	}
+	ctx.JSON(http.StatusOK, &apiItems)
+}
+
+// RecordQuickAction remembers a result of the command palette chosen by the user
+func RecordQuickAction(ctx *context.APIContext, form api.RecordQuickActionOption) {
+	// swagger:operation POST /user/quick_actions/recent user userRecordQuickAction
+	// ---
+	// summary: Remember a result of the command palette chosen by the authenticated user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/RecordQuickActionOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if err := quickaction.RecordChoice(ctx.User, form.Key); err != nil {
+		if models.IsErrInvalidRecentItemKey(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "RecordChoice", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
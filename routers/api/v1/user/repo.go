@@ -43,7 +43,7 @@ func listUserRepos(ctx *context.APIContext, u *models.User, private bool) {
 
 	ctx.SetLinkHeader(int(count), opts.PageSize)
 	ctx.Header().Set("X-Total-Count", strconv.FormatInt(count, 10))
-	ctx.JSON(http.StatusOK, &apiRepos)
+	ctx.JSONConditional(http.StatusOK, &apiRepos, models.RepositoryList(repos).LastUpdatedUnix())
 }
 
 // ListUserRepos - list the repos owned by the given user.
@@ -129,7 +129,7 @@ func ListMyRepos(ctx *context.APIContext) {
 
 	ctx.SetLinkHeader(int(count), opts.ListOptions.PageSize)
 	ctx.Header().Set("X-Total-Count", strconv.FormatInt(count, 10))
-	ctx.JSON(http.StatusOK, &results)
+	ctx.JSONConditional(http.StatusOK, &results, repos.LastUpdatedUnix())
 }
 
 // ListOrgRepos - list the repositories of an organization.
@@ -0,0 +1,421 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// canSeePrivateStarLists returns true if the doer can see the private star lists of the owner
+func canSeePrivateStarLists(ctx *context.APIContext, owner *models.User) bool {
+	return ctx.User != nil && (ctx.User.ID == owner.ID || ctx.User.IsAdmin)
+}
+
+// getStarListByParams returns the star list of the owner whose ID is in the URL, which the
+// doer can see
+func getStarListByParams(ctx *context.APIContext, owner *models.User) *models.StarList {
+	list, err := models.GetStarListByID(owner.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrStarListNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetStarListByID", err)
+		}
+		return nil
+	}
+	if list.IsPrivate && !canSeePrivateStarLists(ctx, owner) {
+		ctx.NotFound()
+		return nil
+	}
+	return list
+}
+
+func listStarLists(ctx *context.APIContext, owner *models.User) {
+	lists, err := models.GetStarLists(owner.ID, canSeePrivateStarLists(ctx, owner))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetStarLists", err)
+		return
+	}
+	apiLists := make([]*api.StarList, len(lists))
+	for i := range lists {
+		apiLists[i] = convert.ToStarList(lists[i])
+	}
+	ctx.JSON(http.StatusOK, &apiLists)
+}
+
+func listStarListRepos(ctx *context.APIContext, list *models.StarList) {
+	repos, _, err := models.SearchRepository(&models.SearchRepoOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		Actor:       ctx.User,
+		Private:     true,
+		StarListID:  list.ID,
+		OrderBy:     models.SearchOrderByAlphabetically,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SearchRepository", err)
+		return
+	}
+
+	apiRepos := make([]*api.Repository, len(repos))
+	for i := range repos {
+		access, err := models.AccessLevel(ctx.User, repos[i])
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "AccessLevel", err)
+			return
+		}
+		apiRepos[i] = repos[i].APIFormat(access)
+	}
+	ctx.JSON(http.StatusOK, &apiRepos)
+}
+
+// ListUserStarLists list the star lists of the given user
+func ListUserStarLists(ctx *context.APIContext) {
+	// swagger:operation GET /users/{username}/star_lists user userListStarLists
+	// ---
+	// summary: List the star lists of the given user, the private ones are only listed for the user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of user
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/StarListList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	user := GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	listStarLists(ctx, user)
+}
+
+// ListUserStarListRepos list the repositories in a star list of the given user
+func ListUserStarListRepos(ctx *context.APIContext) {
+	// swagger:operation GET /users/{username}/star_lists/{id}/repos user userListStarListRepos
+	// ---
+	// summary: List the repos in a star list of the given user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of user
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the star list
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepositoryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	user := GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	list := getStarListByParams(ctx, user)
+	if ctx.Written() {
+		return
+	}
+	listStarListRepos(ctx, list)
+}
+
+// ListMyStarLists list the star lists of the authenticated user
+func ListMyStarLists(ctx *context.APIContext) {
+	// swagger:operation GET /user/star_lists user userCurrentListStarLists
+	// ---
+	// summary: List the star lists of the authenticated user
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/StarListList"
+
+	listStarLists(ctx, ctx.User)
+}
+
+// CreateStarList creates a star list for the authenticated user
+func CreateStarList(ctx *context.APIContext, form api.CreateStarListOption) {
+	// swagger:operation POST /user/star_lists user userCurrentCreateStarList
+	// ---
+	// summary: Create a star list for the authenticated user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateStarListOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/StarList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	list := &models.StarList{
+		UserID:      ctx.User.ID,
+		Name:        form.Name,
+		Description: form.Description,
+		IsPrivate:   form.Private,
+	}
+	if err := models.CreateStarList(list); err != nil {
+		if models.IsErrStarListAlreadyExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CreateStarList", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToStarList(list))
+}
+
+// GetMyStarList get a star list of the authenticated user
+func GetMyStarList(ctx *context.APIContext) {
+	// swagger:operation GET /user/star_lists/{id} user userCurrentGetStarList
+	// ---
+	// summary: Get a star list of the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the star list
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/StarList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	list := getStarListByParams(ctx, ctx.User)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToStarList(list))
+}
+
+// EditStarList edits a star list of the authenticated user
+func EditStarList(ctx *context.APIContext, form api.EditStarListOption) {
+	// swagger:operation PATCH /user/star_lists/{id} user userCurrentEditStarList
+	// ---
+	// summary: Edit a star list of the authenticated user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the star list
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditStarListOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/StarList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	list := getStarListByParams(ctx, ctx.User)
+	if ctx.Written() {
+		return
+	}
+	if form.Name != nil {
+		list.Name = *form.Name
+	}
+	if form.Description != nil {
+		list.Description = *form.Description
+	}
+	if form.Private != nil {
+		list.IsPrivate = *form.Private
+	}
+	if err := models.UpdateStarList(list); err != nil {
+		if models.IsErrStarListAlreadyExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateStarList", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToStarList(list))
+}
+
+// DeleteStarList deletes a star list of the authenticated user
+func DeleteStarList(ctx *context.APIContext) {
+	// swagger:operation DELETE /user/star_lists/{id} user userCurrentDeleteStarList
+	// ---
+	// summary: Delete a star list of the authenticated user, the repos in it stay starred
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the star list
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	list := getStarListByParams(ctx, ctx.User)
+	if ctx.Written() {
+		return
+	}
+	if err := models.DeleteStarList(list); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteStarList", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListMyStarListRepos list the repositories in a star list of the authenticated user
+func ListMyStarListRepos(ctx *context.APIContext) {
+	// swagger:operation GET /user/star_lists/{id}/repos user userCurrentListStarListRepos
+	// ---
+	// summary: List the repos in a star list of the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the star list
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepositoryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	list := getStarListByParams(ctx, ctx.User)
+	if ctx.Written() {
+		return
+	}
+	listStarListRepos(ctx, list)
+}
+
+// AddStarListRepo adds the repo specified in the APIContext to a star list of the
+// authenticated user
+func AddStarListRepo(ctx *context.APIContext) {
+	// swagger:operation PUT /user/star_lists/{id}/repos/{owner}/{repo} user userCurrentAddStarListRepo
+	// ---
+	// summary: Add the given repo to a star list of the authenticated user, starring it if needed
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the star list
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to add
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to add
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	list := getStarListByParams(ctx, ctx.User)
+	if ctx.Written() {
+		return
+	}
+	if err := list.AddRepo(ctx.Repo.Repository.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "AddRepo", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// RemoveStarListRepo removes the repo specified in the APIContext from a star list of the
+// authenticated user
+func RemoveStarListRepo(ctx *context.APIContext) {
+	// swagger:operation DELETE /user/star_lists/{id}/repos/{owner}/{repo} user userCurrentRemoveStarListRepo
+	// ---
+	// summary: Remove the given repo from a star list of the authenticated user, it stays starred
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the star list
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to remove
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to remove
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	list := getStarListByParams(ctx, ctx.User)
+	if ctx.Written() {
+		return
+	}
+	if err := list.RemoveRepo(ctx.Repo.Repository.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RemoveRepo", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -10,6 +10,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
@@ -194,6 +195,126 @@ func Unwatch(ctx *context.APIContext) {
 	ctx.Status(http.StatusNoContent)
 }
 
+// ListWatchedPaths lists the paths of the repo specified in ctx the authenticated user watches
+func ListWatchedPaths(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/subscription/paths repository userCurrentListPathSubscriptions
+	// ---
+	// summary: List the paths of a repo watched by the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WatchPathInfoList"
+
+	watches, err := models.GetUserWatchPaths(ctx.User.ID, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserWatchPaths", err)
+		return
+	}
+	infos := make([]*api.WatchPathInfo, len(watches))
+	for i, watch := range watches {
+		infos[i] = toWatchPathInfo(ctx.Repo.Repository, watch)
+	}
+	ctx.JSON(http.StatusOK, infos)
+}
+
+// WatchPath watches a path of the repo specified in ctx, as the authenticated user
+func WatchPath(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/subscription/paths/{filepath} repository userCurrentPutPathSubscription
+	// ---
+	// summary: Watch a file or directory of a repo
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: filepath
+	//   in: path
+	//   description: path of the file or directory to watch
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WatchPathInfo"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	watch, err := models.WatchRepoPath(ctx.User.ID, ctx.Repo.Repository.ID, ctx.Params("*"))
+	if err != nil {
+		if models.IsErrFilenameInvalid(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "WatchRepoPath", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "WatchRepoPath", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, toWatchPathInfo(ctx.Repo.Repository, watch))
+}
+
+// UnwatchPath unwatches a path of the repo specified in ctx, as the authenticated user
+func UnwatchPath(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/subscription/paths/{filepath} repository userCurrentDeletePathSubscription
+	// ---
+	// summary: Unwatch a file or directory of a repo
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: filepath
+	//   in: path
+	//   description: path of the watched file or directory
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if err := models.UnwatchRepoPath(ctx.User.ID, ctx.Repo.Repository.ID, ctx.Params("*")); err != nil {
+		if models.IsErrFilenameInvalid(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "UnwatchRepoPath", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UnwatchRepoPath", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func toWatchPathInfo(repo *models.Repository, watch *models.WatchPath) *api.WatchPathInfo {
+	return &api.WatchPathInfo{
+		Path:          watch.Path,
+		CreatedAt:     watch.CreatedUnix.AsTime(),
+		URL:           subscriptionURL(repo) + "/paths/" + util.PathEscapeSegments(watch.Path),
+		RepositoryURL: repo.APIURL(),
+	}
+}
+
 // subscriptionURL returns the URL of the subscription API endpoint of a repo
 func subscriptionURL(repo *models.Repository) string {
 	return repo.APIURL() + "/subscription"
@@ -272,3 +272,49 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 	}
 	return true
 }
+
+// ListHookDeliveries writes the deliveries of a webhook to `ctx`
+func ListHookDeliveries(ctx *context.APIContext, w *models.Webhook) {
+	tasks, err := models.GetHookTasksByHookID(w.ID, GetListOptions(ctx))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetHookTasksByHookID", err)
+		return
+	}
+
+	apiDeliveries := make([]*api.HookDelivery, len(tasks))
+	for i := range tasks {
+		apiDeliveries[i] = convert.ToHookDelivery(tasks[i])
+	}
+	ctx.JSON(http.StatusOK, &apiDeliveries)
+}
+
+// GetHookDelivery get a delivery of a webhook by the `:delivery_id` param. If
+// there is an error, write to `ctx` accordingly and return the error
+func GetHookDelivery(ctx *context.APIContext, w *models.Webhook) (*models.HookTask, error) {
+	t, err := models.GetHookTaskByHookID(w.ID, ctx.ParamsInt64(":delivery_id"))
+	if err != nil {
+		if models.IsErrHookTaskNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetHookTaskByHookID", err)
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// RedeliverHookDelivery delivers a past delivery of a webhook again and writes
+// the status of the new delivery to `ctx`
+func RedeliverHookDelivery(ctx *context.APIContext, w *models.Webhook) {
+	t, err := GetHookDelivery(ctx, w)
+	if err != nil {
+		return
+	}
+
+	nt, err := webhook.Redeliver(t)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Redeliver", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToHookDelivery(nt))
+}
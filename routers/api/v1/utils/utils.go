@@ -5,12 +5,16 @@
 package utils
 
 import (
+	"encoding/base64"
+	"errors"
+	"strconv"
 	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
 // GetQueryBeforeSince return parsed time (unix format) from URL query's before and since
@@ -39,6 +43,20 @@ func GetQueryBeforeSince(ctx *context.APIContext) (before, since int64, err erro
 	return before, since, nil
 }
 
+// GetActivityHeatmapOptions returns the options of an activity heatmap using the since and before
+// parameters
+func GetActivityHeatmapOptions(ctx *context.APIContext) (*models.ActivityHeatmapOptions, error) {
+	before, since, err := GetQueryBeforeSince(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ActivityHeatmapOptions{
+		Actor:  ctx.User,
+		Since:  timeutil.TimeStamp(since),
+		Before: timeutil.TimeStamp(before),
+	}, nil
+}
+
 // GetListOptions returns list options using the page and limit parameters
 func GetListOptions(ctx *context.APIContext) models.ListOptions {
 	return models.ListOptions{
@@ -46,3 +64,45 @@ func GetListOptions(ctx *context.APIContext) models.ListOptions {
 		PageSize: convert.ToCorrectPageSize(ctx.QueryInt("limit")),
 	}
 }
+
+// cursorPrefix marks the encoded cursors, so that arbitrary IDs are not
+// accepted as cursor.
+const cursorPrefix = "id:"
+
+// ErrInvalidCursor is returned for cursors not created by this API
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// GetCursorOptions returns keyset pagination options using the cursor and
+// limit parameters. Clients opt in by passing the cursor parameter, an empty
+// cursor requests the first page. nil is returned if the cursor is missing.
+func GetCursorOptions(ctx *context.APIContext) (*models.CursorOptions, error) {
+	cursor := ctx.Query("cursor")
+	if _, ok := ctx.Req.Form["cursor"]; !ok {
+		return nil, nil
+	}
+
+	opts := &models.CursorOptions{
+		PageSize: convert.ToCorrectPageSize(ctx.QueryInt("limit")),
+	}
+	if len(cursor) == 0 {
+		return opts, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(data), cursorPrefix) {
+		return nil, ErrInvalidCursor
+	}
+	if opts.AfterID, err = strconv.ParseInt(strings.TrimPrefix(string(data), cursorPrefix), 10, 64); err != nil || opts.AfterID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return opts, nil
+}
+
+// SetNextCursor sets the headers pointing to the page following the item of
+// lastID, if the listed page was full and more items may follow.
+func SetNextCursor(ctx *context.APIContext, opts *models.CursorOptions, count int, lastID int64) {
+	if count == 0 || count < opts.PageSize {
+		return
+	}
+	ctx.SetCursorLinkHeader(base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.FormatInt(lastID, 10))))
+}
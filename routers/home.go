@@ -31,6 +31,12 @@ const (
 	tplExploreOrganizations base.TplName = "explore/organizations"
 	// tplExploreCode explore code page template
 	tplExploreCode base.TplName = "explore/code"
+	// tplExploreTrending explore trending repositories page template
+	tplExploreTrending base.TplName = "explore/trending"
+	// tplExploreTopics explore featured topics page template
+	tplExploreTopics base.TplName = "explore/topics"
+	// tplExploreTopic explore repositories of a topic page template
+	tplExploreTopic base.TplName = "explore/topic"
 )
 
 // Home render home page
@@ -76,6 +82,7 @@ type RepoSearchOptions struct {
 	Private    bool
 	Restricted bool
 	PageSize   int
+	Topic      string
 	TplName    base.TplName
 }
 
@@ -134,6 +141,10 @@ func RenderRepoSearch(ctx *context.Context, opts *RepoSearchOptions) {
 
 	keyword := strings.Trim(ctx.Query("q"), " ")
 	topicOnly := ctx.QueryBool("topic")
+	if opts.Topic != "" {
+		keyword = opts.Topic
+		topicOnly = true
+	}
 	ctx.Data["TopicOnly"] = topicOnly
 
 	repos, count, err = models.SearchRepository(&models.SearchRepoOptions{
@@ -188,6 +199,140 @@ func ExploreRepos(ctx *context.Context) {
 	})
 }
 
+// popularTopicsNum is the number of popular topics shown on the explore topics page
+const popularTopicsNum = 50
+
+// ExploreTopics render explore featured and popular topics page
+func ExploreTopics(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("explore")
+	ctx.Data["PageIsExplore"] = true
+	ctx.Data["PageIsExploreTopics"] = true
+	ctx.Data["IsRepoIndexerEnabled"] = setting.Indexer.RepoIndexerEnabled
+
+	featured, err := models.FindTopics(&models.FindTopicOptions{FeaturedOnly: true})
+	if err != nil {
+		ctx.ServerError("FindTopics", err)
+		return
+	}
+	popular, err := models.FindTopics(&models.FindTopicOptions{
+		ListOptions: models.ListOptions{Page: 1, PageSize: popularTopicsNum},
+	})
+	if err != nil {
+		ctx.ServerError("FindTopics", err)
+		return
+	}
+	ctx.Data["FeaturedTopics"] = featured
+	ctx.Data["PopularTopics"] = popular
+
+	ctx.HTML(200, tplExploreTopics)
+}
+
+// ExploreTopic render explore repositories of a topic page
+func ExploreTopic(ctx *context.Context) {
+	topic, err := models.GetTopicByName(strings.ToLower(ctx.Params(":topic")))
+	if err != nil {
+		if models.IsErrTopicNotExist(err) {
+			ctx.NotFound("GetTopicByName", err)
+		} else {
+			ctx.ServerError("GetTopicByName", err)
+		}
+		return
+	}
+	ctx.Data["Title"] = topic.Name
+	ctx.Data["PageIsExplore"] = true
+	ctx.Data["PageIsExploreTopics"] = true
+	ctx.Data["IsRepoIndexerEnabled"] = setting.Indexer.RepoIndexerEnabled
+	ctx.Data["Topic"] = topic
+
+	var ownerID int64
+	if ctx.User != nil && !ctx.User.IsAdmin {
+		ownerID = ctx.User.ID
+	}
+
+	RenderRepoSearch(ctx, &RepoSearchOptions{
+		PageSize: setting.UI.ExplorePagingNum,
+		OwnerID:  ownerID,
+		Private:  ctx.User != nil,
+		Topic:    topic.Name,
+		TplName:  tplExploreTopic,
+	})
+}
+
+// trendingTopicsNum is the number of trending topics shown on the explore page
+const trendingTopicsNum = 20
+
+// ExploreTrending render explore trending repositories page
+func ExploreTrending(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("explore")
+	ctx.Data["PageIsExplore"] = true
+	ctx.Data["PageIsExploreTrending"] = true
+	ctx.Data["IsRepoIndexerEnabled"] = setting.Indexer.RepoIndexerEnabled
+
+	period, ok := models.ParseTrendingPeriod(ctx.Query("period"))
+	if !ok {
+		ctx.NotFound("ParseTrendingPeriod", nil)
+		return
+	}
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	opts := &models.TrendingOptions{
+		ListOptions: models.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.ExplorePagingNum,
+		},
+		Period: period,
+		Actor:  ctx.User,
+	}
+
+	languages, err := models.GetTrendingLanguages(opts)
+	if err != nil {
+		ctx.ServerError("GetTrendingLanguages", err)
+		return
+	}
+	opts.Language = strings.TrimSpace(ctx.Query("language"))
+	topics, err := models.GetTrendingTopics(opts, trendingTopicsNum)
+	if err != nil {
+		ctx.ServerError("GetTrendingTopics", err)
+		return
+	}
+	opts.Topic = strings.TrimSpace(ctx.Query("topic"))
+	trendings, count, err := models.SearchTrendingRepos(opts)
+	if err != nil {
+		ctx.ServerError("SearchTrendingRepos", err)
+		return
+	}
+
+	repos := make([]*models.Repository, len(trendings))
+	trendingStats := make(map[int64]*models.RepoTrending, len(trendings))
+	for i, trending := range trendings {
+		repos[i] = trending.Repo
+		trendingStats[trending.RepoID] = trending
+	}
+	ctx.Data["Period"] = period.String()
+	ctx.Data["Periods"] = models.TrendingPeriods
+	ctx.Data["Language"] = opts.Language
+	ctx.Data["Languages"] = languages
+	ctx.Data["Topic"] = opts.Topic
+	ctx.Data["TrendingTopics"] = topics
+	ctx.Data["Repos"] = repos
+	ctx.Data["TrendingStats"] = trendingStats
+	ctx.Data["Total"] = count
+
+	pager := context.NewPagination(int(count), opts.PageSize, page, 5)
+	pager.AddParam(ctx, "period", "Period")
+	if opts.Language != "" {
+		pager.AddParam(ctx, "language", "Language")
+	}
+	if opts.Topic != "" {
+		pager.AddParam(ctx, "topic", "Topic")
+	}
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(200, tplExploreTrending)
+}
+
 // RenderUserSearch render user search page
 func RenderUserSearch(ctx *context.Context, opts *models.SearchUserOptions, tplName base.TplName) {
 	opts.Page = ctx.QueryInt("page")
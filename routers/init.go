@@ -14,9 +14,11 @@ import (
 	"code.gitea.io/gitea/models/migrations"
 	"code.gitea.io/gitea/modules/auth/sso"
 	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/cluster"
 	"code.gitea.io/gitea/modules/cron"
 	"code.gitea.io/gitea/modules/eventsource"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/highlight"
 	code_indexer "code.gitea.io/gitea/modules/indexer/code"
 	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
@@ -30,7 +32,10 @@ import (
 	"code.gitea.io/gitea/modules/ssh"
 	"code.gitea.io/gitea/modules/svg"
 	"code.gitea.io/gitea/modules/task"
+	"code.gitea.io/gitea/modules/tracing"
 	"code.gitea.io/gitea/modules/webhook"
+	backport_service "code.gitea.io/gitea/services/backport"
+	issuetracker_service "code.gitea.io/gitea/services/issuetracker"
 	"code.gitea.io/gitea/services/mailer"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 	pull_service "code.gitea.io/gitea/services/pull"
@@ -59,6 +64,25 @@ func NewServices() {
 	notification.NewContext()
 }
 
+// initTracing starts exporting spans and exports the remaining spans on termination
+func initTracing(ctx context.Context) {
+	if err := tracing.Init(ctx, tracing.Options{
+		Endpoint:    setting.Tracing.Endpoint,
+		Insecure:    setting.Tracing.Insecure,
+		ServiceName: setting.Tracing.ServiceName,
+		SampleRatio: setting.Tracing.SampleRatio,
+	}); err != nil {
+		log.Fatal("Failed to initialize tracing: %v", err)
+	}
+	graceful.GetManager().RunAtTerminate(ctx, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracing.Shutdown(ctx); err != nil {
+			log.Error("Failed to export the remaining spans: %v", err)
+		}
+	})
+}
+
 // In case of problems connecting to DB, retry connection. Eg, PGSQL in Docker Container on Synology
 func initDBEngine(ctx context.Context) (err error) {
 	log.Info("Beginning ORM engine initialization.")
@@ -126,6 +150,10 @@ func GlobalInit(ctx context.Context) {
 
 	NewServices()
 
+	if setting.Tracing.Enabled {
+		initTracing(ctx)
+	}
+
 	if setting.InstallLock {
 		highlight.NewContext()
 		external.RegisterParsers()
@@ -141,8 +169,15 @@ func GlobalInit(ctx context.Context) {
 		}
 
 		models.NewRepoContext()
+		if err := models.LoadCustomEmojis(); err != nil {
+			log.Fatal("Failed to load custom emoji: %v", err)
+		}
+		if err := models.InitBadges(); err != nil {
+			log.Fatal("Failed to create built-in badges: %v", err)
+		}
 
 		// Booting long running goroutines.
+		cluster.Init()
 		cron.NewContext()
 		issue_indexer.InitIssueIndexer(false)
 		code_indexer.Init()
@@ -154,6 +189,12 @@ func GlobalInit(ctx context.Context) {
 		if err := pull_service.Init(); err != nil {
 			log.Fatal("Failed to initialize test pull requests queue: %v", err)
 		}
+		if err := backport_service.Init(); err != nil {
+			log.Fatal("Failed to initialize backport queue: %v", err)
+		}
+		if err := issuetracker_service.Init(); err != nil {
+			log.Fatal("Failed to initialize issue tracker sync queue: %v", err)
+		}
 		if err := task.Init(); err != nil {
 			log.Fatal("Failed to initialize task scheduler: %v", err)
 		}
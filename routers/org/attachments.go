@@ -0,0 +1,84 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	// tplSettingsAttachments template path for render the attachment policy settings
+	tplSettingsAttachments base.TplName = "org/settings/attachments"
+)
+
+// prepareAttachmentPolicy sets the data of the attachment policy settings page
+func prepareAttachmentPolicy(ctx *context.Context, policy *models.AttachmentPolicy) bool {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsAttachments"] = true
+	ctx.Data["AttachmentPolicy"] = policy
+	ctx.Data["RetentionNotice"] = setting.AttachmentRetentionNotice
+
+	instance, err := models.GetEffectiveAttachmentPolicy(0)
+	if err != nil {
+		ctx.ServerError("GetEffectiveAttachmentPolicy", err)
+		return false
+	}
+	ctx.Data["InstancePolicy"] = instance
+	return true
+}
+
+// SettingsAttachments render the attachment policy of an organization
+func SettingsAttachments(ctx *context.Context) {
+	policy, err := models.GetAttachmentPolicy(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetAttachmentPolicy", err)
+		return
+	}
+	if !prepareAttachmentPolicy(ctx, policy) {
+		return
+	}
+
+	ctx.HTML(200, tplSettingsAttachments)
+}
+
+// SettingsAttachmentsPost response for changing the attachment policy of an organization
+func SettingsAttachmentsPost(ctx *context.Context, form auth.AttachmentPolicyForm) {
+	policy, err := models.GetAttachmentPolicy(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetAttachmentPolicy", err)
+		return
+	}
+	policy.MaxSize = form.MaxSize
+	policy.MaxSizeByType = strings.TrimSpace(form.MaxSizeByType)
+	policy.MaxIssueSize = form.MaxIssueSize
+	policy.ClosedIssueRetention = form.ClosedIssueRetention
+
+	if !prepareAttachmentPolicy(ctx, policy) {
+		return
+	}
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsAttachments)
+		return
+	}
+
+	if err := models.SaveAttachmentPolicy(policy); err != nil {
+		if models.IsErrAttachmentPolicyInvalid(err) {
+			ctx.Data["Err_MaxSizeByType"] = true
+			ctx.RenderWithErr(ctx.Tr("org.settings.attachments.max_size_by_type_invalid", err.(models.ErrAttachmentPolicyInvalid).Err.Error()), tplSettingsAttachments, &form)
+			return
+		}
+		ctx.ServerError("SaveAttachmentPolicy", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.attachments.update_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/attachments")
+}
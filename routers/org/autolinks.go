@@ -0,0 +1,138 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/repo"
+)
+
+const (
+	// tplSettingsAutoLinks template path for render the auto-link rules
+	tplSettingsAutoLinks base.TplName = "org/settings/autolinks"
+)
+
+func loadAutoLinksData(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+
+	rules, err := models.GetOrgAutoLinkRules(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetOrgAutoLinkRules", err)
+		return
+	}
+	repo.PrepareAutoLinkRules(ctx, rules, ctx.Org.OrgLink+"/settings/autolinks")
+}
+
+func getOrgAutoLinkRule(ctx *context.Context) *models.AutoLinkRule {
+	rule, err := models.GetOrgAutoLinkRule(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrAutoLinkRuleNotExist(err) {
+			ctx.NotFound("GetOrgAutoLinkRule", err)
+		} else {
+			ctx.ServerError("GetOrgAutoLinkRule", err)
+		}
+		return nil
+	}
+	return rule
+}
+
+// SettingsAutoLinks render the auto-link rules of an organization
+func SettingsAutoLinks(ctx *context.Context) {
+	loadAutoLinksData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(200, tplSettingsAutoLinks)
+}
+
+// SettingsAutoLinksPost response for adding an auto-link rule to an organization
+func SettingsAutoLinksPost(ctx *context.Context, form auth.AutoLinkRuleForm) {
+	loadAutoLinksData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsAutoLinks)
+		return
+	}
+
+	rule := &models.AutoLinkRule{
+		OrgID:   ctx.Org.Organization.ID,
+		Pattern: form.Pattern,
+		URL:     form.URL,
+		Sort:    form.Sort,
+	}
+	if err := models.NewAutoLinkRule(rule); err != nil {
+		repo.RenderAutoLinkRuleError(ctx, err, tplSettingsAutoLinks, &form)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.autolinks.add_success", rule.Pattern))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/autolinks")
+}
+
+// SettingsEditAutoLink render the form to edit an auto-link rule of an organization
+func SettingsEditAutoLink(ctx *context.Context) {
+	loadAutoLinksData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	rule := getOrgAutoLinkRule(ctx)
+	if ctx.Written() {
+		return
+	}
+	repo.PrepareEditAutoLinkRule(ctx, rule)
+
+	ctx.HTML(200, tplSettingsAutoLinks)
+}
+
+// SettingsEditAutoLinkPost response for editing an auto-link rule of an organization
+func SettingsEditAutoLinkPost(ctx *context.Context, form auth.AutoLinkRuleForm) {
+	loadAutoLinksData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	rule := getOrgAutoLinkRule(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["AutoLinkRule"] = rule
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsAutoLinks)
+		return
+	}
+
+	rule.Pattern = form.Pattern
+	rule.URL = form.URL
+	rule.Sort = form.Sort
+	if err := models.UpdateAutoLinkRule(rule); err != nil {
+		repo.RenderAutoLinkRuleError(ctx, err, tplSettingsAutoLinks, &form)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.autolinks.update_success", rule.Pattern))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/autolinks")
+}
+
+// SettingsDeleteAutoLink response for deleting an auto-link rule of an organization
+func SettingsDeleteAutoLink(ctx *context.Context) {
+	if err := models.DeleteOrgAutoLinkRule(ctx.Org.Organization.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteOrgAutoLinkRule: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.autolinks.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Org.OrgLink + "/settings/autolinks",
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+const (
+	// tplSettingsContributorAgreement template path for render the contributor license agreement
+	tplSettingsContributorAgreement base.TplName = "org/settings/cla"
+)
+
+// ContributorAgreement render the contributor license agreement of all repositories of an
+// organization
+func ContributorAgreement(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsCLA"] = true
+
+	agreement, err := models.GetContributorAgreement(ctx.Org.Organization.ID, 0)
+	if err != nil {
+		ctx.ServerError("GetContributorAgreement", err)
+		return
+	}
+	ctx.Data["Agreement"] = agreement
+	if agreement != nil {
+		ctx.Data["title"] = agreement.Title
+		ctx.Data["content"] = agreement.Content
+	}
+
+	ctx.HTML(200, tplSettingsContributorAgreement)
+}
+
+// ContributorAgreementPost response for changing the contributor license agreement of an
+// organization
+func ContributorAgreementPost(ctx *context.Context, form auth.ContributorAgreementForm) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsCLA"] = true
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsContributorAgreement)
+		return
+	}
+
+	agreement := &models.ContributorAgreement{
+		OwnerID: ctx.Org.Organization.ID,
+		Title:   strings.TrimSpace(form.Title),
+		Content: form.Content,
+	}
+	if err := models.SaveContributorAgreement(agreement); err != nil {
+		ctx.ServerError("SaveContributorAgreement", err)
+		return
+	}
+	go pull_service.RecheckContributorAgreement(agreement, ctx.User)
+
+	ctx.Flash.Success(ctx.Tr("org.settings.cla.update_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/cla")
+}
+
+// DeleteContributorAgreement removes the contributor license agreement of an organization
+func DeleteContributorAgreement(ctx *context.Context) {
+	if err := models.DeleteContributorAgreement(ctx.Org.Organization.ID, 0); err != nil {
+		ctx.ServerError("DeleteContributorAgreement", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.cla.deletion_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/cla")
+}
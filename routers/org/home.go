@@ -11,8 +11,12 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/repo"
 )
 
+// topContributorsNum is the number of top contributors shown on the organization home page
+const topContributorsNum = 10
+
 const (
 	tplOrgHome base.TplName = "org/home"
 )
@@ -64,16 +68,62 @@ func Home(ctx *context.Context) {
 
 	keyword := strings.Trim(ctx.Query("q"), " ")
 	ctx.Data["Keyword"] = keyword
+	topicOnly := ctx.QueryBool("topic")
+	ctx.Data["TopicOnly"] = topicOnly
+
+	featuredTopics, err := models.GetOrgFeaturedTopics(org.ID, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetOrgFeaturedTopics", err)
+		return
+	}
+	ctx.Data["FeaturedTopics"] = featuredTopics
+
+	if topicOnly && len(keyword) > 0 {
+		topic, err := models.GetTopicByName(strings.ToLower(keyword))
+		if err != nil && !models.IsErrTopicNotExist(err) {
+			ctx.ServerError("GetTopicByName", err)
+			return
+		} else if err == nil {
+			orgTopic, err := models.GetOrgTopic(org.ID, topic)
+			if err != nil {
+				ctx.ServerError("GetOrgTopic", err)
+				return
+			}
+			ctx.Data["OrgTopic"] = orgTopic
+		}
+	}
 
 	page := ctx.QueryInt("page")
 	if page <= 0 {
 		page = 1
 	}
 
+	if page == 1 && len(keyword) == 0 {
+		pinnedRepos, err := models.GetPinnedRepos(org.ID, ctx.User)
+		if err != nil {
+			ctx.ServerError("GetPinnedRepos", err)
+			return
+		}
+		ctx.Data["PinnedRepos"] = pinnedRepos
+		repo.RenderProfileReadme(ctx, org)
+
+		if setting.Service.EnableUserHeatmap {
+			contributors, err := models.GetActivityTopContributors(&models.ActivityHeatmapOptions{OrgID: org.ID, Actor: ctx.User}, topContributorsNum)
+			if err != nil {
+				ctx.ServerError("GetActivityTopContributors", err)
+				return
+			}
+			if len(contributors) > 0 {
+				ctx.Data["EnableHeatmap"] = true
+				ctx.Data["HeatmapURL"] = setting.AppSubURL + "/api/v1/orgs/" + org.Name + "/activity/heatmap"
+				ctx.Data["TopContributors"] = contributors
+			}
+		}
+	}
+
 	var (
 		repos []*models.Repository
 		count int64
-		err   error
 	)
 	repos, count, err = models.SearchRepository(&models.SearchRepoOptions{
 		ListOptions: models.ListOptions{
@@ -81,6 +131,7 @@ func Home(ctx *context.Context) {
 			Page:     page,
 		},
 		Keyword:            keyword,
+		TopicOnly:          topicOnly,
 		OwnerID:            org.ID,
 		OrderBy:            orderBy,
 		Private:            ctx.IsSigned,
@@ -127,6 +178,7 @@ func Home(ctx *context.Context) {
 
 	pager := context.NewPagination(int(count), setting.UI.User.RepoPagingNum, page, 5)
 	pager.SetDefaultParams(ctx)
+	pager.AddParam(ctx, "topic", "TopicOnly")
 	ctx.Data["Page"] = pager
 
 	ctx.HTML(200, tplOrgHome)
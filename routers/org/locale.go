@@ -0,0 +1,46 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/repo"
+)
+
+const (
+	// tplSettingsLocale template path for render the default language and formats
+	tplSettingsLocale base.TplName = "org/settings/locale"
+)
+
+// SettingsLocale render the default language and formats of an organization
+func SettingsLocale(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+
+	s, err := models.GetOrgLocaleSetting(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetOrgLocaleSetting", err)
+		return
+	}
+	if s == nil {
+		s = &models.LocaleSetting{}
+	}
+	repo.PrepareLocaleSetting(ctx, s, &models.LocaleSetting{})
+
+	ctx.HTML(200, tplSettingsLocale)
+}
+
+// SettingsLocalePost response for changing the default language and formats of an organization
+func SettingsLocalePost(ctx *context.Context, form auth.LocaleSettingForm) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+
+	if repo.SaveLocaleSetting(ctx, form, tplSettingsLocale, &models.LocaleSetting{}, func(s *models.LocaleSetting) error {
+		return models.UpdateOrgLocaleSetting(ctx.Org.Organization.ID, s)
+	}) {
+		ctx.Redirect(ctx.Org.OrgLink + "/settings/locale")
+	}
+}
@@ -6,11 +6,14 @@
 package org
 
 import (
+	"time"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/unknwon/com"
 )
@@ -65,6 +68,17 @@ func Members(ctx *context.Context) {
 	ctx.Data["MembersIsUserOrgOwner"] = members.IsUserOrgOwner(org.ID)
 	ctx.Data["MembersTwoFaStatus"] = members.GetTwoFaStatus()
 
+	if ctx.Org.IsMember {
+		ctx.Data["MembersExpiration"] = members.GetOrgMembershipExpiration(org.ID)
+
+		externals, err := models.GetOrgExternalCollaborators(org.ID)
+		if err != nil {
+			ctx.ServerError("GetOrgExternalCollaborators", err)
+			return
+		}
+		ctx.Data["ExternalCollaborators"] = externals
+	}
+
 	ctx.HTML(200, tplMembers)
 }
 
@@ -102,6 +116,39 @@ func MembersAction(ctx *context.Context) {
 			ctx.Redirect(ctx.Org.OrgLink + "/members")
 			return
 		}
+	case "expire":
+		if !ctx.Org.IsOwner {
+			ctx.Error(404)
+			return
+		}
+		var expires timeutil.TimeStamp
+		if date := ctx.Query("expires"); len(date) > 0 {
+			t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+			if err != nil {
+				ctx.Flash.Error(ctx.Tr("org.members.invalid_expiration"))
+				ctx.Redirect(ctx.Org.OrgLink + "/members")
+				return
+			}
+			expires = timeutil.TimeStamp(time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location()).Unix())
+		}
+		err = models.ChangeOrgUserExpiration(org.ID, uid, expires)
+	case "convert":
+		if !ctx.Org.IsOwner {
+			ctx.Error(404)
+			return
+		}
+		err = models.ConvertOrgMemberToExternal(org, uid)
+		if models.IsErrOrgOwnerNotConvertible(err) {
+			ctx.Flash.Error(ctx.Tr("org.members.owner_not_convertible"))
+			ctx.Redirect(ctx.Org.OrgLink + "/members")
+			return
+		}
+	case "remove_external":
+		if !ctx.Org.IsOwner {
+			ctx.Error(404)
+			return
+		}
+		err = models.RemoveOrgExternalCollaborator(org, uid)
 	case "leave":
 		err = org.RemoveMember(ctx.User.ID)
 		if models.IsErrLastOrgOwner(err) {
@@ -41,11 +41,17 @@ func NewLabel(ctx *context.Context, form auth.CreateLabelForm) {
 		Name:        form.Title,
 		Description: form.Description,
 		Color:       form.Color,
+		IsQuestion:  form.IsQuestion,
+		SyncToRepos: form.SyncToRepos,
 	}
 	if err := models.NewLabel(l); err != nil {
 		ctx.ServerError("NewLabel", err)
 		return
 	}
+	syncLabel(ctx, l)
+	if ctx.Written() {
+		return
+	}
 	ctx.Redirect(ctx.Org.OrgLink + "/settings/labels")
 }
 
@@ -65,10 +71,47 @@ func UpdateLabel(ctx *context.Context, form auth.CreateLabelForm) {
 	l.Name = form.Title
 	l.Description = form.Description
 	l.Color = form.Color
+	l.IsQuestion = form.IsQuestion
+	l.SyncToRepos = form.SyncToRepos
 	if err := models.UpdateLabel(l); err != nil {
 		ctx.ServerError("UpdateLabel", err)
 		return
 	}
+	syncLabel(ctx, l)
+	if ctx.Written() {
+		return
+	}
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/labels")
+}
+
+// flashLabelSyncResult warns about the repositories the labels could not be synchronized to
+func flashLabelSyncResult(ctx *context.Context, res *models.LabelSyncResult) {
+	if res.Conflicts > 0 {
+		ctx.Flash.Warning(ctx.Tr("org.settings.labels.sync_conflicts", res.Conflicts))
+	}
+}
+
+// syncLabel synchronizes the label of the organization to its repositories
+func syncLabel(ctx *context.Context, l *models.Label) {
+	res, err := models.SyncOrgLabel(l)
+	if err != nil {
+		ctx.ServerError("SyncOrgLabel", err)
+		return
+	}
+	flashLabelSyncResult(ctx, res)
+}
+
+// SyncLabels synchronizes all the synced labels of the organization to its repositories
+func SyncLabels(ctx *context.Context) {
+	res, err := models.SyncOrgLabels(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("SyncOrgLabels", err)
+		return
+	}
+	flashLabelSyncResult(ctx, res)
+	if res.Conflicts == 0 {
+		ctx.Flash.Success(ctx.Tr("org.settings.labels.sync_success"))
+	}
 	ctx.Redirect(ctx.Org.OrgLink + "/settings/labels")
 }
 
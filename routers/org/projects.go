@@ -0,0 +1,482 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+const (
+	tplProjects    base.TplName = "org/project/list"
+	tplProjectNew  base.TplName = "org/project/new"
+	tplProjectView base.TplName = "org/project/view"
+)
+
+// Projects render the projects of an organization
+func Projects(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.projects")
+	ctx.Data["PageIsOrgProjects"] = true
+
+	org := ctx.Org.Organization
+	isShowClosed := ctx.Query("state") == "closed"
+	state := api.StateOpen
+	if isShowClosed {
+		state = api.StateClosed
+	}
+
+	projects, err := models.GetProjectsByOrgID(org.ID, state, models.ListOptions{})
+	if err != nil {
+		ctx.ServerError("GetProjectsByOrgID", err)
+		return
+	}
+	for _, p := range projects {
+		p.RenderedDescription = string(markdown.Render([]byte(p.Description), org.HomeLink(), nil))
+	}
+	openCount, closedCount, err := models.CountProjectsByOrgID(org.ID)
+	if err != nil {
+		ctx.ServerError("CountProjectsByOrgID", err)
+		return
+	}
+
+	ctx.Data["Projects"] = projects
+	ctx.Data["OpenCount"] = openCount
+	ctx.Data["ClosedCount"] = closedCount
+	ctx.Data["IsShowClosed"] = isShowClosed
+
+	ctx.HTML(200, tplProjects)
+}
+
+// NewProject render the page to create a project
+func NewProject(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.projects.new")
+	ctx.Data["PageIsOrgProjects"] = true
+
+	ctx.HTML(200, tplProjectNew)
+}
+
+// NewProjectPost response for creating a project
+func NewProjectPost(ctx *context.Context, form auth.CreateProjectForm) {
+	ctx.Data["Title"] = ctx.Tr("org.projects.new")
+	ctx.Data["PageIsOrgProjects"] = true
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplProjectNew)
+		return
+	}
+
+	p := &models.Project{
+		OwnerID:     ctx.Org.Organization.ID,
+		CreatorID:   ctx.User.ID,
+		Title:       form.Title,
+		Description: form.Description,
+	}
+	if err := models.NewProject(p); err != nil {
+		ctx.ServerError("NewProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.projects.create_success", p.Title))
+	ctx.Redirect(fmt.Sprintf("%s/projects/%d", ctx.Org.OrgLink, p.ID))
+}
+
+// getProject returns the project of the organization in the path, it
+// renders the not found page if it does not exist
+func getProject(ctx *context.Context) *models.Project {
+	p, err := models.GetProjectByOrgID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrProjectNotExist(err) {
+			ctx.NotFound("GetProjectByOrgID", err)
+		} else {
+			ctx.ServerError("GetProjectByOrgID", err)
+		}
+		return nil
+	}
+	return p
+}
+
+// EditProject render the page to edit a project
+func EditProject(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.projects.edit")
+	ctx.Data["PageIsOrgProjects"] = true
+	ctx.Data["PageIsEditProject"] = true
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["title"] = p.Title
+	ctx.Data["description"] = p.Description
+	ctx.Data["in_progress_board_id"] = p.InProgressBoardID
+	ctx.Data["done_board_id"] = p.DoneBoardID
+	ctx.Data["archive_done_after_days"] = p.ArchiveDoneAfterDays
+	if !loadProjectBoards(ctx, p) {
+		return
+	}
+
+	ctx.HTML(200, tplProjectNew)
+}
+
+// loadProjectBoards loads the columns of the project which the automation
+// can move cards to, it returns false if an error was rendered
+func loadProjectBoards(ctx *context.Context, p *models.Project) bool {
+	boards, err := models.GetProjectBoards(p.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return false
+	}
+	ctx.Data["Boards"] = boards
+	return true
+}
+
+// EditProjectPost response for editing a project
+func EditProjectPost(ctx *context.Context, form auth.CreateProjectForm) {
+	ctx.Data["Title"] = ctx.Tr("org.projects.edit")
+	ctx.Data["PageIsOrgProjects"] = true
+	ctx.Data["PageIsEditProject"] = true
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !loadProjectBoards(ctx, p) {
+		return
+	}
+	if ctx.HasError() {
+		ctx.HTML(200, tplProjectNew)
+		return
+	}
+
+	p.Title = form.Title
+	p.Description = form.Description
+	p.InProgressBoardID = form.InProgressBoardID
+	p.DoneBoardID = form.DoneBoardID
+	p.ArchiveDoneAfterDays = form.ArchiveDoneAfterDays
+	if err := models.UpdateProject(p); err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.RenderWithErr(ctx.Tr("org.projects.board_not_exist"), tplProjectNew, &form)
+			return
+		}
+		ctx.ServerError("UpdateProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.projects.edit_success", p.Title))
+	ctx.Redirect(fmt.Sprintf("%s/projects/%d", ctx.Org.OrgLink, p.ID))
+}
+
+// ChangeProjectStatus closes or reopens a project
+func ChangeProjectStatus(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	p.IsClosed = ctx.Params(":action") == "close"
+	if err := models.UpdateProject(p); err != nil {
+		ctx.ServerError("UpdateProject", err)
+		return
+	}
+
+	if p.IsClosed {
+		ctx.Redirect(ctx.Org.OrgLink + "/projects?state=closed")
+	} else {
+		ctx.Redirect(ctx.Org.OrgLink + "/projects")
+	}
+}
+
+// DeleteProject deletes a project, its issues are kept
+func DeleteProject(ctx *context.Context) {
+	p, err := models.GetProjectByOrgID(ctx.Org.Organization.ID, ctx.QueryInt64("id"))
+	if err != nil {
+		if !models.IsErrProjectNotExist(err) {
+			ctx.ServerError("GetProjectByOrgID", err)
+			return
+		}
+	} else if err = models.DeleteProject(p); err != nil {
+		ctx.ServerError("DeleteProject", err)
+		return
+	} else {
+		ctx.Flash.Success(ctx.Tr("org.projects.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Org.OrgLink + "/projects",
+	})
+}
+
+// ViewProject render the board of a project, the cards can be restricted to
+// the issues of one repository
+func ViewProject(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	org := ctx.Org.Organization
+	p.RenderedDescription = string(markdown.Render([]byte(p.Description), org.HomeLink(), nil))
+
+	boards, err := models.GetProjectBoards(p.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return
+	}
+	uncategorized := &models.ProjectBoard{ProjectID: p.ID, Title: ctx.Tr("org.projects.uncategorized")}
+	boards = append([]*models.ProjectBoard{uncategorized}, boards...)
+
+	repoID := ctx.QueryInt64("repo")
+	cards, err := models.GetProjectIssues(p, repoID, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetProjectIssues", err)
+		return
+	}
+	boardsByID := make(map[int64]*models.ProjectBoard, len(boards))
+	for _, board := range boards {
+		boardsByID[board.ID] = board
+	}
+	for _, card := range cards {
+		board, ok := boardsByID[card.ProjectBoardID]
+		if !ok {
+			board = uncategorized
+		}
+		board.Issues = append(board.Issues, card)
+	}
+
+	projectRepos, err := models.GetProjectRepositories(p, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetProjectRepositories", err)
+		return
+	}
+	ownerRepos, err := models.GetProjectOwnerRepositories(p, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetProjectOwnerRepositories", err)
+		return
+	}
+	rules, err := models.GetProjectRules(p.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectRules", err)
+		return
+	}
+
+	ctx.Data["Title"] = p.Title
+	ctx.Data["PageIsOrgProjects"] = true
+	ctx.Data["Project"] = p
+	ctx.Data["ProjectLink"] = fmt.Sprintf("%s/projects/%d", ctx.Org.OrgLink, p.ID)
+	ctx.Data["Boards"] = boards
+	ctx.Data["BoardsByID"] = boardsByID
+	ctx.Data["RepoID"] = repoID
+	ctx.Data["ProjectRepos"] = projectRepos
+	ctx.Data["OwnerRepos"] = ownerRepos
+	ctx.Data["Rules"] = rules
+
+	ctx.HTML(200, tplProjectView)
+}
+
+// NewProjectBoard adds a column to a project
+func NewProjectBoard(ctx *context.Context, form auth.ProjectBoardForm) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	link := fmt.Sprintf("%s/projects/%d", ctx.Org.OrgLink, p.ID)
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	if err := models.NewProjectBoard(&models.ProjectBoard{
+		ProjectID: p.ID,
+		Title:     form.Title,
+	}); err != nil {
+		ctx.ServerError("NewProjectBoard", err)
+		return
+	}
+	ctx.Redirect(link)
+}
+
+// DeleteProjectBoard deletes a column of a project, its cards are moved to
+// the uncategorized column
+func DeleteProjectBoard(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	board, err := models.GetProjectBoard(p.ID, ctx.QueryInt64("id"))
+	if err != nil {
+		if !models.IsErrProjectBoardNotExist(err) {
+			ctx.ServerError("GetProjectBoard", err)
+			return
+		}
+	} else if err = models.DeleteProjectBoard(board); err != nil {
+		ctx.ServerError("DeleteProjectBoard", err)
+		return
+	} else {
+		ctx.Flash.Success(ctx.Tr("org.projects.board_deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": fmt.Sprintf("%s/projects/%d", ctx.Org.OrgLink, p.ID),
+	})
+}
+
+// flashProjectQueryError flashes the error of adding issues or rules to a
+// project, it returns false for unexpected errors
+func flashProjectQueryError(ctx *context.Context, err error) bool {
+	switch {
+	case models.IsErrProjectQueryEmpty(err):
+		ctx.Flash.Error(ctx.Tr("org.projects.query_empty"))
+	case models.IsErrProjectIssueOutOfScope(err), models.IsErrRepoNotExist(err):
+		ctx.Flash.Error(ctx.Tr("org.projects.repo_out_of_scope"))
+	case models.IsErrProjectBoardNotExist(err):
+		ctx.Flash.Error(ctx.Tr("org.projects.board_not_exist"))
+	default:
+		return false
+	}
+	return true
+}
+
+// AddProjectIssues adds the issues of the repositories of the organization
+// matching a query to a project
+func AddProjectIssues(ctx *context.Context, form auth.ProjectIssueQueryForm) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	link := fmt.Sprintf("%s/projects/%d", ctx.Org.OrgLink, p.ID)
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	issues, err := models.AddProjectIssuesByQuery(p, form.BoardID, &models.ProjectIssueQuery{
+		RepoID:  form.RepoID,
+		Label:   form.Label,
+		Keyword: form.Keyword,
+		State:   api.StateType(form.State),
+	}, ctx.User)
+	if err != nil {
+		if !flashProjectQueryError(ctx, err) {
+			ctx.ServerError("AddProjectIssuesByQuery", err)
+			return
+		}
+	} else {
+		ctx.Flash.Success(ctx.Tr("org.projects.issues_added", len(issues)))
+	}
+	ctx.Redirect(link)
+}
+
+// MoveProjectIssue moves a card of a project to another column
+func MoveProjectIssue(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.MoveProjectIssue(p, ctx.QueryInt64("issue_id"), ctx.QueryInt64("board_id")); err != nil {
+		if models.IsErrProjectIssueNotExist(err) || models.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound("MoveProjectIssue", err)
+		} else {
+			ctx.ServerError("MoveProjectIssue", err)
+		}
+		return
+	}
+	ctx.Redirect(fmt.Sprintf("%s/projects/%d?repo=%d", ctx.Org.OrgLink, p.ID, ctx.QueryInt64("repo")))
+}
+
+// RemoveProjectIssue removes a card from a project, the issue is kept
+func RemoveProjectIssue(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.RemoveProjectIssue(p, ctx.QueryInt64("id")); err != nil && !models.IsErrProjectIssueNotExist(err) {
+		ctx.ServerError("RemoveProjectIssue", err)
+		return
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": fmt.Sprintf("%s/projects/%d", ctx.Org.OrgLink, p.ID),
+	})
+}
+
+// NewProjectRule adds a rule adding the issues matching a query to a
+// project automatically, optionally adding the matching issues right away
+func NewProjectRule(ctx *context.Context, form auth.ProjectIssueQueryForm) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	link := fmt.Sprintf("%s/projects/%d", ctx.Org.OrgLink, p.ID)
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	rule := &models.ProjectRule{
+		ProjectBoardID: form.BoardID,
+		RepoID:         form.RepoID,
+		Label:          form.Label,
+		Keyword:        form.Keyword,
+	}
+	if err := models.NewProjectRule(p, rule); err != nil {
+		if !flashProjectQueryError(ctx, err) {
+			ctx.ServerError("NewProjectRule", err)
+			return
+		}
+		ctx.Redirect(link)
+		return
+	}
+
+	if form.ApplyExisting {
+		issues, err := models.AddProjectIssuesByQuery(p, rule.ProjectBoardID, rule.Query(), ctx.User)
+		if err != nil {
+			ctx.ServerError("AddProjectIssuesByQuery", err)
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("org.projects.rule_added_issues", len(issues)))
+	} else {
+		ctx.Flash.Success(ctx.Tr("org.projects.rule_added"))
+	}
+	ctx.Redirect(link)
+}
+
+// DeleteProjectRule deletes a rule of a project, the issues it added are
+// kept
+func DeleteProjectRule(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	rule, err := models.GetProjectRule(p.ID, ctx.QueryInt64("id"))
+	if err != nil {
+		if !models.IsErrNotExist(err) {
+			ctx.ServerError("GetProjectRule", err)
+			return
+		}
+	} else if err = models.DeleteProjectRule(rule); err != nil {
+		ctx.ServerError("DeleteProjectRule", err)
+		return
+	} else {
+		ctx.Flash.Success(ctx.Tr("org.projects.rule_deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": fmt.Sprintf("%s/projects/%d", ctx.Org.OrgLink, p.ID),
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+)
+
+const (
+	// tplSettingsRepoDefaults template path for render the default repository settings
+	tplSettingsRepoDefaults base.TplName = "org/settings/repo_defaults"
+)
+
+// prepareRepoDefaults sets the data of the default repository settings page
+func prepareRepoDefaults(ctx *context.Context, defaults *models.OrgRepoDefaults) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsRepoDefaults"] = true
+	ctx.Data["RepoDefaults"] = defaults
+	ctx.Data["LabelTemplates"] = models.LabelTemplates
+
+	units := make([]models.Unit, 0, len(models.AllRepoUnitTypes))
+	for _, tp := range models.AllRepoUnitTypes {
+		if !tp.UnitGlobalDisabled() {
+			units = append(units, models.Units[tp])
+		}
+	}
+	ctx.Data["Units"] = units
+}
+
+// RepoDefaults render the default repository settings of an organization
+func RepoDefaults(ctx *context.Context) {
+	defaults, err := models.GetOrgRepoDefaults(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetOrgRepoDefaults", err)
+		return
+	}
+	prepareRepoDefaults(ctx, defaults)
+
+	ctx.HTML(200, tplSettingsRepoDefaults)
+}
+
+// RepoDefaultsPost response for changing the default repository settings of an organization
+func RepoDefaultsPost(ctx *context.Context, form auth.OrgRepoDefaultsForm) {
+	defaults, err := models.GetOrgRepoDefaults(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetOrgRepoDefaults", err)
+		return
+	}
+
+	defaults.Units = make([]models.UnitType, 0, len(form.Units))
+	for _, tp := range form.Units {
+		defaults.Units = append(defaults.Units, models.UnitType(tp))
+	}
+	defaults.ProtectedBranches = strings.TrimSpace(form.ProtectedBranches)
+	defaults.CanPush = form.CanPush
+	defaults.RequiredApprovals = form.RequiredApprovals
+	defaults.BlockOnRejectedReviews = form.BlockOnRejectedReviews
+	defaults.DismissStaleApprovals = form.DismissStaleApprovals
+	defaults.RequireSignedCommits = form.RequireSignedCommits
+	defaults.LabelTemplate = form.LabelTemplate
+	defaults.WebhookURLs = strings.TrimSpace(form.WebhookURLs)
+	defaults.IssueTemplate = strings.TrimSpace(form.IssueTemplate)
+
+	prepareRepoDefaults(ctx, defaults)
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsRepoDefaults)
+		return
+	}
+
+	for _, url := range defaults.GetWebhookURLs() {
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			ctx.RenderWithErr(ctx.Tr("org.settings.repo_defaults.webhook_url_invalid", url), tplSettingsRepoDefaults, &form)
+			return
+		}
+	}
+
+	if err := models.SaveOrgRepoDefaults(defaults); err != nil {
+		if models.IsErrIssueLabelTemplateLoad(err) {
+			ctx.RenderWithErr(ctx.Tr("org.settings.repo_defaults.label_template_invalid"), tplSettingsRepoDefaults, &form)
+			return
+		}
+		ctx.ServerError("SaveOrgRepoDefaults", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.repo_defaults.update_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/repo_defaults")
+}
+
+// ApplyRepoDefaults applies the default repository settings to all the repositories of an organization
+func ApplyRepoDefaults(ctx *context.Context) {
+	count, err := models.ApplyOrgRepoDefaultsToAllRepos(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("ApplyOrgRepoDefaultsToAllRepos", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.repo_defaults.apply_success", count))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/repo_defaults")
+}
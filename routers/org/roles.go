@@ -0,0 +1,226 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+)
+
+const (
+	// tplSettingsRoles template path for render custom roles settings
+	tplSettingsRoles base.TplName = "org/settings/roles"
+	// tplSettingsRoleNew template path for render the custom role form
+	tplSettingsRoleNew base.TplName = "org/settings/role_new"
+)
+
+// Roles render the custom roles of an organization
+func Roles(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsRoles"] = true
+
+	roles, err := models.GetOrgRoles(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetOrgRoles", err)
+		return
+	}
+	teams := make(map[int64][]*models.Team, len(roles))
+	for _, r := range roles {
+		if teams[r.ID], err = r.GetTeams(); err != nil {
+			ctx.ServerError("GetTeams", err)
+			return
+		}
+	}
+	ctx.Data["Roles"] = roles
+	ctx.Data["RoleTeams"] = teams
+	ctx.Data["Units"] = models.Units
+
+	ctx.HTML(200, tplSettingsRoles)
+}
+
+// NewRole render the page to create a custom role, which can start from
+// the permissions of a preset
+func NewRole(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsRoles"] = true
+	ctx.Data["PageIsSettingsRolesNew"] = true
+	ctx.Data["Units"] = models.Units
+	ctx.Data["Presets"] = models.OrgRolePresets
+
+	permissions := make(map[models.UnitType]models.AccessMode)
+	preset := ctx.Query("preset")
+	for _, p := range models.OrgRolePresets {
+		if p.Name == preset {
+			permissions = p.Permissions
+			ctx.Data["name"] = ctx.Tr("org.settings.roles.preset." + p.Name)
+		}
+	}
+	ctx.Data["RolePermissions"] = permissions
+
+	ctx.HTML(200, tplSettingsRoleNew)
+}
+
+// parseRolePermissions reads the permissions of the role from the unit
+// fields of the form
+func parseRolePermissions(ctx *context.Context) ([]*models.OrgRolePermission, map[models.UnitType]models.AccessMode) {
+	permissions := make([]*models.OrgRolePermission, 0, len(models.Units))
+	modes := make(map[models.UnitType]models.AccessMode, len(models.Units))
+	for _, tp := range models.AllRepoUnitTypes {
+		value := ctx.Query(fmt.Sprintf("unit_%d", tp))
+		if value == "" || value == "none" {
+			continue
+		}
+		mode := models.ParseAccessMode(value)
+		permissions = append(permissions, &models.OrgRolePermission{Type: tp, AccessMode: mode})
+		modes[tp] = mode
+	}
+	return permissions, modes
+}
+
+// saveRole creates or updates the custom role, it renders the form again
+// if the role is invalid
+func saveRole(ctx *context.Context, r *models.OrgRole, form *auth.CreateOrgRoleForm) bool {
+	var err error
+	if r.ID == 0 {
+		err = models.NewOrgRole(r)
+	} else {
+		err = models.UpdateOrgRole(r)
+	}
+	switch {
+	case err == nil:
+		return true
+	case models.IsErrOrgRoleAlreadyExist(err):
+		ctx.Data["Err_Name"] = true
+		ctx.RenderWithErr(ctx.Tr("org.settings.roles.name_been_taken"), tplSettingsRoleNew, form)
+	case models.IsErrInvalidOrgRolePermission(err):
+		ctx.RenderWithErr(ctx.Tr("org.settings.roles.permissions_invalid"), tplSettingsRoleNew, form)
+	default:
+		ctx.ServerError("SaveOrgRole", err)
+	}
+	return false
+}
+
+// NewRolePost response for creating a custom role
+func NewRolePost(ctx *context.Context, form auth.CreateOrgRoleForm) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsRoles"] = true
+	ctx.Data["PageIsSettingsRolesNew"] = true
+	ctx.Data["Units"] = models.Units
+	ctx.Data["Presets"] = models.OrgRolePresets
+
+	permissions, modes := parseRolePermissions(ctx)
+	ctx.Data["RolePermissions"] = modes
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsRoleNew)
+		return
+	}
+
+	r := &models.OrgRole{
+		OrgID:       ctx.Org.Organization.ID,
+		Name:        form.Name,
+		Description: form.Description,
+		Permissions: permissions,
+	}
+	if !saveRole(ctx, r, &form) {
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.roles.create_success", r.Name))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/roles")
+}
+
+// getRole returns the custom role of the organization in the path, it
+// renders the not found page if it does not exist
+func getRole(ctx *context.Context) *models.OrgRole {
+	r, err := models.GetOrgRoleByID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrOrgRoleNotExist(err) {
+			ctx.NotFound("GetOrgRoleByID", err)
+		} else {
+			ctx.ServerError("GetOrgRoleByID", err)
+		}
+		return nil
+	}
+	return r
+}
+
+// EditRole render the page to edit a custom role
+func EditRole(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsRoles"] = true
+	ctx.Data["Units"] = models.Units
+
+	r := getRole(ctx)
+	if ctx.Written() {
+		return
+	}
+	modes := make(map[models.UnitType]models.AccessMode, len(r.Permissions))
+	for _, p := range r.Permissions {
+		modes[p.Type] = p.AccessMode
+	}
+	ctx.Data["Role"] = r
+	ctx.Data["RolePermissions"] = modes
+	ctx.Data["name"] = r.Name
+	ctx.Data["description"] = r.Description
+
+	ctx.HTML(200, tplSettingsRoleNew)
+}
+
+// EditRolePost response for editing a custom role, the new permissions are
+// applied to the teams the role is assigned to
+func EditRolePost(ctx *context.Context, form auth.CreateOrgRoleForm) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsRoles"] = true
+	ctx.Data["Units"] = models.Units
+
+	r := getRole(ctx)
+	if ctx.Written() {
+		return
+	}
+	permissions, modes := parseRolePermissions(ctx)
+	ctx.Data["Role"] = r
+	ctx.Data["RolePermissions"] = modes
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsRoleNew)
+		return
+	}
+
+	r.Name = form.Name
+	r.Description = form.Description
+	r.Permissions = permissions
+	if !saveRole(ctx, r, &form) {
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.roles.edit_success", r.Name))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/roles")
+}
+
+// DeleteRole deletes a custom role which is not assigned to any team
+func DeleteRole(ctx *context.Context) {
+	r, err := models.GetOrgRoleByID(ctx.Org.Organization.ID, ctx.QueryInt64("id"))
+	if err != nil {
+		if !models.IsErrOrgRoleNotExist(err) {
+			ctx.ServerError("GetOrgRoleByID", err)
+			return
+		}
+	} else if err = models.DeleteOrgRole(r); err != nil {
+		if !models.IsErrOrgRoleInUse(err) {
+			ctx.ServerError("DeleteOrgRole", err)
+			return
+		}
+		ctx.Flash.Error(ctx.Tr("org.settings.roles.in_use", r.Name))
+	} else {
+		ctx.Flash.Success(ctx.Tr("org.settings.roles.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Org.OrgLink + "/settings/roles",
+	})
+}
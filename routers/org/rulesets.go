@@ -0,0 +1,228 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/repo"
+)
+
+const (
+	// tplSettingsRulesets template path for render rulesets settings
+	tplSettingsRulesets base.TplName = "org/settings/rulesets"
+	// tplSettingsRulesetNew template path for render the ruleset form
+	tplSettingsRulesetNew base.TplName = "org/settings/ruleset_new"
+)
+
+// Rulesets render the rulesets of an organization in evaluation order
+func Rulesets(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsRulesets"] = true
+
+	rulesets, err := models.GetOrgRulesets(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetOrgRulesets", err)
+		return
+	}
+	ctx.Data["Rulesets"] = rulesets
+
+	ctx.HTML(200, tplSettingsRulesets)
+}
+
+// prepareRulesetForm loads the members and the teams of the organization
+// which can be added to the lists of the ruleset
+func prepareRulesetForm(ctx *context.Context, r *models.OrgRuleset) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsRulesets"] = true
+
+	org := ctx.Org.Organization
+	if err := org.GetMembers(); err != nil {
+		ctx.ServerError("GetMembers", err)
+		return
+	}
+	if err := org.GetTeams(&models.SearchTeamOptions{}); err != nil {
+		ctx.ServerError("GetTeams", err)
+		return
+	}
+	ctx.Data["Users"] = org.Members
+	ctx.Data["Teams"] = org.Teams
+
+	ctx.Data["Ruleset"] = r
+	ctx.Data["status_check_contexts"] = strings.Join(r.StatusCheckContexts, "\n")
+	ctx.Data["push_whitelist_users"] = strings.Join(base.Int64sToStrings(r.PushWhitelistUserIDs), ",")
+	ctx.Data["push_whitelist_teams"] = strings.Join(base.Int64sToStrings(r.PushWhitelistTeamIDs), ",")
+	ctx.Data["bypass_users"] = strings.Join(base.Int64sToStrings(r.BypassUserIDs), ",")
+	ctx.Data["bypass_teams"] = strings.Join(base.Int64sToStrings(r.BypassTeamIDs), ",")
+	repo.PrepareMergeStyles(ctx, r.MergeStyles)
+}
+
+// parseRulesetIDs parses a comma separated list of IDs
+func parseRulesetIDs(list string) []int64 {
+	if strings.TrimSpace(list) == "" {
+		return nil
+	}
+	ids, _ := base.StringsToInt64s(strings.Split(list, ","))
+	return ids
+}
+
+// applyRulesetForm sets the fields of the ruleset from the form
+func applyRulesetForm(r *models.OrgRuleset, form *auth.OrgRulesetForm) {
+	r.Name = form.Name
+	r.Priority = form.Priority
+	r.IsActive = form.IsActive
+	r.Target = models.RulesetTargetBranch
+	if form.Target == "tag" {
+		r.Target = models.RulesetTargetTag
+	}
+	r.Mode = models.RulesetModeSupplement
+	if form.Mode == "override" {
+		r.Mode = models.RulesetModeOverride
+	}
+	r.RepoPatterns = strings.TrimSpace(form.RepoPatterns)
+	r.RefPatterns = strings.TrimSpace(form.RefPatterns)
+	r.RequiredApprovals = form.RequiredApprovals
+	r.RequireSignedCommits = form.RequireSignedCommits
+	r.EnableStatusCheck = form.EnableStatusCheck
+	r.StatusCheckContexts = make([]string, 0, 5)
+	for _, context := range strings.Split(form.StatusCheckContexts, "\n") {
+		if context = strings.TrimSpace(context); context != "" {
+			r.StatusCheckContexts = append(r.StatusCheckContexts, context)
+		}
+	}
+	r.MergeStyles = repo.FilterMergeStyles(form.MergeStyles)
+	r.RestrictPush = form.RestrictPush
+	r.PushWhitelistUserIDs = parseRulesetIDs(form.PushWhitelistUsers)
+	r.PushWhitelistTeamIDs = parseRulesetIDs(form.PushWhitelistTeams)
+	r.BypassUserIDs = parseRulesetIDs(form.BypassUsers)
+	r.BypassTeamIDs = parseRulesetIDs(form.BypassTeams)
+}
+
+// saveRuleset creates or updates the ruleset, it renders the form again if
+// the ruleset is invalid
+func saveRuleset(ctx *context.Context, r *models.OrgRuleset, form *auth.OrgRulesetForm) bool {
+	var err error
+	if r.ID == 0 {
+		err = models.NewOrgRuleset(r)
+	} else {
+		err = models.UpdateOrgRuleset(r)
+	}
+	switch {
+	case err == nil:
+		return true
+	case models.IsErrOrgRulesetAlreadyExist(err):
+		ctx.Data["Err_Name"] = true
+		ctx.RenderWithErr(ctx.Tr("org.settings.rulesets.name_been_taken"), tplSettingsRulesetNew, form)
+	case models.IsErrInvalidRulesetPattern(err):
+		ctx.RenderWithErr(ctx.Tr("org.settings.rulesets.pattern_invalid", err.(models.ErrInvalidRulesetPattern).Pattern), tplSettingsRulesetNew, form)
+	default:
+		ctx.ServerError("SaveOrgRuleset", err)
+	}
+	return false
+}
+
+// NewRuleset render the page to create a ruleset
+func NewRuleset(ctx *context.Context) {
+	prepareRulesetForm(ctx, &models.OrgRuleset{IsActive: true})
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["PageIsSettingsRulesetsNew"] = true
+	ctx.HTML(200, tplSettingsRulesetNew)
+}
+
+// NewRulesetPost response for creating a ruleset
+func NewRulesetPost(ctx *context.Context, form auth.OrgRulesetForm) {
+	r := &models.OrgRuleset{OrgID: ctx.Org.Organization.ID}
+	applyRulesetForm(r, &form)
+	prepareRulesetForm(ctx, r)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["PageIsSettingsRulesetsNew"] = true
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsRulesetNew)
+		return
+	}
+
+	if !saveRuleset(ctx, r, &form) {
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.rulesets.create_success", r.Name))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/rulesets")
+}
+
+// getRuleset returns the ruleset of the organization in the path, it
+// renders the not found page if it does not exist
+func getRuleset(ctx *context.Context) *models.OrgRuleset {
+	r, err := models.GetOrgRulesetByID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrOrgRulesetNotExist(err) {
+			ctx.NotFound("GetOrgRulesetByID", err)
+		} else {
+			ctx.ServerError("GetOrgRulesetByID", err)
+		}
+		return nil
+	}
+	return r
+}
+
+// EditRuleset render the page to edit a ruleset
+func EditRuleset(ctx *context.Context) {
+	r := getRuleset(ctx)
+	if ctx.Written() {
+		return
+	}
+	prepareRulesetForm(ctx, r)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(200, tplSettingsRulesetNew)
+}
+
+// EditRulesetPost response for editing a ruleset
+func EditRulesetPost(ctx *context.Context, form auth.OrgRulesetForm) {
+	r := getRuleset(ctx)
+	if ctx.Written() {
+		return
+	}
+	applyRulesetForm(r, &form)
+	prepareRulesetForm(ctx, r)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsRulesetNew)
+		return
+	}
+
+	if !saveRuleset(ctx, r, &form) {
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.rulesets.edit_success", r.Name))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/rulesets")
+}
+
+// DeleteRuleset deletes a ruleset of an organization
+func DeleteRuleset(ctx *context.Context) {
+	if err := models.DeleteOrgRuleset(ctx.Org.Organization.ID, ctx.QueryInt64("id")); err != nil {
+		if !models.IsErrOrgRulesetNotExist(err) {
+			ctx.ServerError("DeleteOrgRuleset", err)
+			return
+		}
+	} else {
+		ctx.Flash.Success(ctx.Tr("org.settings.rulesets.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Org.OrgLink + "/settings/rulesets",
+	})
+}
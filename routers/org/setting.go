@@ -26,6 +26,8 @@ const (
 	tplSettingsHooks base.TplName = "org/settings/hooks"
 	// tplSettingsLabels template path for render labels settings
 	tplSettingsLabels base.TplName = "org/settings/labels"
+	// tplSettingsPinnedRepos template path for render pinned repositories settings
+	tplSettingsPinnedRepos base.TplName = "org/settings/pinned"
 )
 
 // Settings render the main settings page
@@ -207,3 +209,25 @@ func Labels(ctx *context.Context) {
 	ctx.Data["LabelTemplates"] = models.LabelTemplates
 	ctx.HTML(200, tplSettingsLabels)
 }
+
+// SettingsPinnedRepos render the pinned repositories settings page
+func SettingsPinnedRepos(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsPinnedRepos"] = true
+	ctx.Data["PinnedReposLink"] = ctx.Org.OrgLink + "/settings/pinned"
+
+	userSetting.PreparePinnedRepos(ctx, ctx.Org.Organization)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(200, tplSettingsPinnedRepos)
+}
+
+// SettingsPinnedReposPost response for pinning the repositories of the organization
+func SettingsPinnedReposPost(ctx *context.Context) {
+	userSetting.UpdatePinnedRepos(ctx, ctx.Org.Organization)
+	if ctx.Written() {
+		return
+	}
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/pinned")
+}
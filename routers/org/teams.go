@@ -37,13 +37,18 @@ func Teams(ctx *context.Context) {
 	ctx.Data["Title"] = org.FullName
 	ctx.Data["PageIsOrgTeams"] = true
 
+	parents := make(map[int64]*models.Team, len(org.Teams))
 	for _, t := range org.Teams {
 		if err := t.GetMembers(&models.SearchMembersOptions{}); err != nil {
 			ctx.ServerError("GetMembers", err)
 			return
 		}
+		parents[t.ID] = t
 	}
-	ctx.Data["Teams"] = org.Teams
+	teams, depths := models.SortTeamsByHierarchy(org.Teams)
+	ctx.Data["Teams"] = teams
+	ctx.Data["TeamDepths"] = depths
+	ctx.Data["TeamParents"] = parents
 
 	ctx.HTML(200, tplTeams)
 }
@@ -110,6 +115,8 @@ func TeamsAction(ctx *context.Context) {
 	if err != nil {
 		if models.IsErrLastOrgOwner(err) {
 			ctx.Flash.Error(ctx.Tr("form.last_org_owner"))
+		} else if models.IsErrBotOutOfScope(err) {
+			ctx.Flash.Error(ctx.Tr("org.teams.add_bot_out_of_scope"))
 		} else {
 			log.Error("Action(%s): %v", ctx.Params(":action"), err)
 			ctx.JSON(200, map[string]interface{}{
@@ -182,8 +189,12 @@ func NewTeam(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Org.Organization.FullName
 	ctx.Data["PageIsOrgTeams"] = true
 	ctx.Data["PageIsOrgTeamsNew"] = true
-	ctx.Data["Team"] = &models.Team{}
+	ctx.Data["Team"] = &models.Team{ReviewAssignCount: 1}
 	ctx.Data["Units"] = models.Units
+	ctx.Data["ReviewAssignAlgorithms"] = models.ReviewAssignAlgorithms()
+	if !loadParentTeamCandidates(ctx, 0) || !loadTeamRoles(ctx) {
+		return
+	}
 	ctx.HTML(200, tplTeamNew)
 }
 
@@ -193,6 +204,10 @@ func NewTeamPost(ctx *context.Context, form auth.CreateTeamForm) {
 	ctx.Data["PageIsOrgTeams"] = true
 	ctx.Data["PageIsOrgTeamsNew"] = true
 	ctx.Data["Units"] = models.Units
+	ctx.Data["ReviewAssignAlgorithms"] = models.ReviewAssignAlgorithms()
+	if !loadParentTeamCandidates(ctx, 0) || !loadTeamRoles(ctx) {
+		return
+	}
 	var includesAllRepositories = (form.RepoAccess == "all")
 
 	t := &models.Team{
@@ -202,6 +217,9 @@ func NewTeamPost(ctx *context.Context, form auth.CreateTeamForm) {
 		Authorize:               models.ParseAccessMode(form.Permission),
 		IncludesAllRepositories: includesAllRepositories,
 		CanCreateOrgRepo:        form.CanCreateOrgRepo,
+		ReviewAssignCount:       form.ReviewAssignCount,
+		ParentID:                form.ParentID,
+		RoleID:                  form.RoleID,
 	}
 
 	if t.Authorize < models.AccessModeOwner {
@@ -222,16 +240,26 @@ func NewTeamPost(ctx *context.Context, form auth.CreateTeamForm) {
 		return
 	}
 
-	if t.Authorize < models.AccessModeAdmin && len(form.Units) == 0 {
+	if t.Authorize < models.AccessModeAdmin && len(form.Units) == 0 && form.RoleID == 0 {
 		ctx.RenderWithErr(ctx.Tr("form.team_no_units_error"), tplTeamNew, &form)
 		return
 	}
 
+	if !applyReviewAssignAlgorithm(ctx, t, form) {
+		return
+	}
+
 	if err := models.NewTeam(t); err != nil {
-		ctx.Data["Err_TeamName"] = true
 		switch {
 		case models.IsErrTeamAlreadyExist(err):
+			ctx.Data["Err_TeamName"] = true
 			ctx.RenderWithErr(ctx.Tr("form.team_name_been_taken"), tplTeamNew, &form)
+		case models.IsErrInvalidTeamParent(err):
+			ctx.Data["Err_ParentID"] = true
+			ctx.RenderWithErr(ctx.Tr("org.teams.parent_invalid"), tplTeamNew, &form)
+		case models.IsErrOrgRoleNotExist(err):
+			ctx.Data["Err_RoleID"] = true
+			ctx.RenderWithErr(ctx.Tr("org.teams.role_invalid"), tplTeamNew, &form)
 		default:
 			ctx.ServerError("NewTeam", err)
 		}
@@ -241,6 +269,67 @@ func NewTeamPost(ctx *context.Context, form auth.CreateTeamForm) {
 	ctx.Redirect(ctx.Org.OrgLink + "/teams/" + t.LowerName)
 }
 
+// applyReviewAssignAlgorithm sets the review assign algorithm of the team
+// from the form, it renders the form again if the algorithm is unknown
+func applyReviewAssignAlgorithm(ctx *context.Context, t *models.Team, form auth.CreateTeamForm) bool {
+	algorithm, ok := models.ParseReviewAssignAlgorithm(form.ReviewAssignAlgorithm)
+	if !ok {
+		ctx.Data["Err_ReviewAssignAlgorithm"] = true
+		ctx.RenderWithErr(ctx.Tr("org.teams.review_assign_invalid"), tplTeamNew, &form)
+		return false
+	}
+	t.ReviewAssignAlgorithm = algorithm
+	return true
+}
+
+// loadParentTeamCandidates lists the teams which can be the parent of the team
+func loadParentTeamCandidates(ctx *context.Context, teamID int64) bool {
+	candidates, err := models.GetTeamHierarchyCandidates(ctx.Org.Organization.ID, teamID)
+	if err != nil {
+		ctx.ServerError("GetTeamHierarchyCandidates", err)
+		return false
+	}
+	ctx.Data["ParentTeams"] = candidates
+	return true
+}
+
+// loadTeamRoles lists the custom roles which can be assigned to the team
+func loadTeamRoles(ctx *context.Context) bool {
+	roles, err := models.GetOrgRoles(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetOrgRoles", err)
+		return false
+	}
+	ctx.Data["Roles"] = roles
+	return true
+}
+
+// loadTeamSidebar loads the custom role, the parent team and the child teams
+// of the team
+func loadTeamSidebar(ctx *context.Context) bool {
+	t := ctx.Org.Team
+	if err := t.LoadRole(); err != nil {
+		ctx.ServerError("LoadRole", err)
+		return false
+	}
+	ctx.Data["Units"] = models.Units
+	if t.ParentID > 0 {
+		parent, err := models.GetTeamByID(t.ParentID)
+		if err != nil {
+			ctx.ServerError("GetTeamByID", err)
+			return false
+		}
+		ctx.Data["ParentTeam"] = parent
+	}
+	children, err := t.GetChildTeams()
+	if err != nil {
+		ctx.ServerError("GetChildTeams", err)
+		return false
+	}
+	ctx.Data["ChildTeams"] = children
+	return true
+}
+
 // TeamMembers render team members page
 func TeamMembers(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Org.Team.Name
@@ -250,6 +339,9 @@ func TeamMembers(ctx *context.Context) {
 		ctx.ServerError("GetMembers", err)
 		return
 	}
+	if !loadTeamSidebar(ctx) {
+		return
+	}
 	ctx.HTML(200, tplTeamMembers)
 }
 
@@ -262,6 +354,9 @@ func TeamRepositories(ctx *context.Context) {
 		ctx.ServerError("GetRepositories", err)
 		return
 	}
+	if !loadTeamSidebar(ctx) {
+		return
+	}
 	ctx.HTML(200, tplTeamRepositories)
 }
 
@@ -272,6 +367,10 @@ func EditTeam(ctx *context.Context) {
 	ctx.Data["team_name"] = ctx.Org.Team.Name
 	ctx.Data["desc"] = ctx.Org.Team.Description
 	ctx.Data["Units"] = models.Units
+	ctx.Data["ReviewAssignAlgorithms"] = models.ReviewAssignAlgorithms()
+	if !loadParentTeamCandidates(ctx, ctx.Org.Team.ID) || !loadTeamRoles(ctx) {
+		return
+	}
 	ctx.HTML(200, tplTeamNew)
 }
 
@@ -282,6 +381,10 @@ func EditTeamPost(ctx *context.Context, form auth.CreateTeamForm) {
 	ctx.Data["PageIsOrgTeams"] = true
 	ctx.Data["Team"] = t
 	ctx.Data["Units"] = models.Units
+	ctx.Data["ReviewAssignAlgorithms"] = models.ReviewAssignAlgorithms()
+	if !loadParentTeamCandidates(ctx, t.ID) || !loadTeamRoles(ctx) {
+		return
+	}
 
 	isAuthChanged := false
 	isIncludeAllChanged := false
@@ -318,17 +421,47 @@ func EditTeamPost(ctx *context.Context, form auth.CreateTeamForm) {
 		}
 	}
 	t.CanCreateOrgRepo = form.CanCreateOrgRepo
+	t.ReviewAssignCount = form.ReviewAssignCount
 
 	if ctx.HasError() {
 		ctx.HTML(200, tplTeamNew)
 		return
 	}
 
-	if t.Authorize < models.AccessModeAdmin && len(form.Units) == 0 {
+	if t.Authorize < models.AccessModeAdmin && len(form.Units) == 0 && form.RoleID == 0 {
 		ctx.RenderWithErr(ctx.Tr("form.team_no_units_error"), tplTeamNew, &form)
 		return
 	}
 
+	if !applyReviewAssignAlgorithm(ctx, t, form) {
+		return
+	}
+
+	if !t.IsOwnerTeam() {
+		if err := models.ChangeTeamParent(t, form.ParentID); err != nil {
+			ctx.Data["Err_ParentID"] = true
+			switch {
+			case models.IsErrTeamParentCycle(err):
+				ctx.RenderWithErr(ctx.Tr("org.teams.parent_cycle"), tplTeamNew, &form)
+			case models.IsErrInvalidTeamParent(err):
+				ctx.RenderWithErr(ctx.Tr("org.teams.parent_invalid"), tplTeamNew, &form)
+			default:
+				ctx.ServerError("ChangeTeamParent", err)
+			}
+			return
+		}
+
+		if err := models.ChangeTeamRole(t, form.RoleID); err != nil {
+			if models.IsErrOrgRoleNotExist(err) {
+				ctx.Data["Err_RoleID"] = true
+				ctx.RenderWithErr(ctx.Tr("org.teams.role_invalid"), tplTeamNew, &form)
+			} else {
+				ctx.ServerError("ChangeTeamRole", err)
+			}
+			return
+		}
+	}
+
 	if err := models.UpdateTeam(t, isAuthChanged, isIncludeAllChanged); err != nil {
 		ctx.Data["Err_TeamName"] = true
 		switch {
@@ -0,0 +1,137 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+)
+
+const (
+	// tplSettingsTopics template path for render the topics settings
+	tplSettingsTopics base.TplName = "org/settings/topics"
+	// tplSettingsTopicEdit template path for render the topic curation settings
+	tplSettingsTopicEdit base.TplName = "org/settings/topic_edit"
+)
+
+// SettingsTopics render the topics of the repositories of an organization
+func SettingsTopics(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsTopics"] = true
+
+	topics, err := models.GetOrgTopics(ctx.Org.Organization.ID, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetOrgTopics", err)
+		return
+	}
+	ctx.Data["Topics"] = topics
+
+	ctx.HTML(200, tplSettingsTopics)
+}
+
+func prepareOrgTopic(ctx *context.Context) *models.OrgTopic {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsTopics"] = true
+
+	topic, err := models.GetTopicByName(strings.ToLower(ctx.Params(":topic")))
+	if err != nil {
+		if models.IsErrTopicNotExist(err) {
+			ctx.NotFound("GetTopicByName", err)
+		} else {
+			ctx.ServerError("GetTopicByName", err)
+		}
+		return nil
+	}
+	orgTopic, err := models.GetOrgTopic(ctx.Org.Organization.ID, topic)
+	if err != nil {
+		ctx.ServerError("GetOrgTopic", err)
+		return nil
+	}
+	ctx.Data["OrgTopic"] = orgTopic
+	ctx.Data["TopicLink"] = ctx.Org.OrgLink + "/settings/topics/" + topic.Name
+	return orgTopic
+}
+
+// SettingsTopic render the curation of a topic by an organization
+func SettingsTopic(ctx *context.Context) {
+	orgTopic := prepareOrgTopic(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["description"] = orgTopic.Description
+	ctx.Data["logo"] = orgTopic.Logo
+	ctx.Data["is_featured"] = orgTopic.IsFeatured
+
+	ctx.HTML(200, tplSettingsTopicEdit)
+}
+
+// SettingsTopicPost response for curating a topic of an organization
+func SettingsTopicPost(ctx *context.Context, form auth.TopicForm) {
+	orgTopic := prepareOrgTopic(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsTopicEdit)
+		return
+	}
+
+	orgTopic.Description = strings.TrimSpace(form.Description)
+	orgTopic.Logo = strings.TrimSpace(form.Logo)
+	orgTopic.IsFeatured = form.IsFeatured
+	if err := models.SaveOrgTopic(orgTopic); err != nil {
+		ctx.ServerError("SaveOrgTopic", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.topics.update_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/topics")
+}
+
+// SettingsTopicMergePost replaces a topic with another one on the repositories of an organization
+func SettingsTopicMergePost(ctx *context.Context, form auth.MergeTopicForm) {
+	orgTopic := prepareOrgTopic(ctx)
+	if ctx.Written() {
+		return
+	}
+	link := ctx.Data["TopicLink"].(string)
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(link)
+		return
+	}
+
+	target, err := models.GetTopicByName(strings.ToLower(strings.TrimSpace(form.Target)))
+	if err != nil {
+		if models.IsErrTopicNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("admin.topics.merge_target_not_exist", form.Target))
+			ctx.Redirect(link)
+		} else {
+			ctx.ServerError("GetTopicByName", err)
+		}
+		return
+	}
+
+	if err := models.MergeOrgTopics(ctx.Org.Organization.ID, orgTopic.Topic, target); err != nil {
+		if models.IsErrTopicMergeSelf(err) {
+			ctx.Flash.Error(ctx.Tr("admin.topics.merge_self"))
+			ctx.Redirect(link)
+		} else {
+			ctx.ServerError("MergeOrgTopics", err)
+		}
+		return
+	}
+	log.Trace("Topic %s merged into %s for organization %s by %s", orgTopic.Topic.Name, target.Name, ctx.Org.Organization.Name, ctx.User.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.topics.merge_success", orgTopic.Topic.Name, target.Name))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/topics")
+}
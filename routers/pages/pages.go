@@ -0,0 +1,159 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pages
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	pages_service "code.gitea.io/gitea/services/pages"
+
+	"gitea.com/macaron/macaron"
+)
+
+// sandboxPolicy makes the browser treat the pages served on the domain of Gitea as coming from a unique origin,
+// so that their scripts cannot act on Gitea on behalf of the signed in user.
+const sandboxPolicy = "sandbox allow-scripts allow-forms allow-popups allow-modals"
+
+// servePage writes the page of the site at the path relative to the root of the site
+func servePage(w http.ResponseWriter, req *http.Request, site *pages_service.Site, pagePath string, isPrivate bool) {
+	page, err := site.Open(pagePath)
+	if err != nil {
+		switch {
+		case pages_service.IsErrPageIsDir(err):
+			target := req.URL.Path + "/"
+			if req.URL.RawQuery != "" {
+				target += "?" + req.URL.RawQuery
+			}
+			http.Redirect(w, req, target, http.StatusMovedPermanently)
+		case pages_service.IsErrPageNotExist(err):
+			http.NotFound(w, req)
+		default:
+			log.Error("Open page %s of %-v: %v", pagePath, site.Repo, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+	defer page.Close()
+
+	header := w.Header()
+	etag := `"` + page.ETag + `"`
+	header.Set("ETag", etag)
+	if isPrivate {
+		header.Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int64(setting.Pages.CacheMaxAge.Seconds())))
+		header.Set("Content-Security-Policy", sandboxPolicy)
+	} else {
+		header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(setting.Pages.CacheMaxAge.Seconds())))
+	}
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	if !page.IsNotFound && req.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(page.Name))
+	var head []byte
+	if contentType == "" {
+		head = make([]byte, 512)
+		n, err := io.ReadFull(page, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			log.Error("Read page %s of %-v: %v", pagePath, site.Repo, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		head = head[:n]
+		contentType = http.DetectContentType(head)
+	}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Length", strconv.FormatInt(page.Size, 10))
+
+	if page.IsNotFound {
+		w.WriteHeader(http.StatusNotFound)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	if req.Method == http.MethodHead {
+		return
+	}
+	if _, err := w.Write(head); err != nil {
+		return
+	}
+	if _, err := io.Copy(w, page); err != nil {
+		log.Debug("Write page %s of %-v: %v", pagePath, site.Repo, err)
+	}
+}
+
+// Serve serves the static site of a repository on the domain of Gitea at /{owner}/{repo}/pages/
+// to the users who can read its code
+func Serve(ctx *context.Context) {
+	if !setting.Pages.Enabled {
+		ctx.NotFound("Serve", nil)
+		return
+	}
+	site, err := pages_service.GetSite(ctx.Repo.Repository)
+	if err != nil {
+		ctx.ServerError("GetSite", err)
+		return
+	} else if site == nil {
+		ctx.NotFound("Serve", nil)
+		return
+	}
+
+	// The path is made of /{owner}/{repo}/pages and the path of the page
+	var pagePath string
+	if parts := strings.SplitN(strings.TrimPrefix(ctx.Req.URL.Path, setting.AppSubURL), "/", 5); len(parts) == 5 {
+		pagePath = "/" + parts[4]
+	}
+	servePage(ctx.Resp, ctx.Req.Request, site, pagePath, true)
+}
+
+// HostHandler serves the public static sites requested on the pages domain or on a verified custom domain,
+// the other requests are left to the next handlers.
+func HostHandler() macaron.Handler {
+	return func(ctx *macaron.Context) {
+		if !setting.Pages.Enabled {
+			return
+		}
+		host := ctx.Req.Host
+		if i := strings.LastIndex(host, ":"); i > strings.LastIndex(host, "]") {
+			host = host[:i]
+		}
+		if strings.EqualFold(host, setting.Domain) {
+			return
+		}
+		if !setting.Pages.EnableCustomDomains && !pages_service.IsPagesDomain(host) {
+			return
+		}
+
+		site, pagePath, err := pages_service.ResolveHost(host, ctx.Req.URL.Path)
+		if err != nil {
+			log.Error("ResolveHost %s: %v", host, err)
+			http.Error(ctx.Resp, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if site == nil {
+			if pages_service.IsPagesDomain(host) {
+				http.NotFound(ctx.Resp, ctx.Req.Request)
+			}
+			return
+		}
+
+		if ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead {
+			ctx.Resp.Header().Set("Allow", "GET, HEAD")
+			http.Error(ctx.Resp, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		servePage(ctx.Resp, ctx.Req.Request, site, pagePath, false)
+	}
+}
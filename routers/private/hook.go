@@ -7,6 +7,7 @@ package private
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -17,6 +18,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/maintenance"
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/repofiles"
 	"code.gitea.io/gitea/modules/setting"
@@ -28,7 +30,7 @@ import (
 	"github.com/gobwas/glob"
 )
 
-func verifyCommits(oldCommitID, newCommitID string, repo *git.Repository, env []string) error {
+func verifyCommits(oldCommitID, newCommitID string, repo *git.Repository, env []string, protectBranch *models.ProtectedBranch) error {
 	stdoutReader, stdoutWriter, err := os.Pipe()
 	if err != nil {
 		log.Error("Unable to create os.Pipe for %s", repo.Path)
@@ -44,7 +46,7 @@ func verifyCommits(oldCommitID, newCommitID string, repo *git.Repository, env []
 			stdoutWriter, nil, nil,
 			func(ctx context.Context, cancel context.CancelFunc) error {
 				_ = stdoutWriter.Close()
-				err := readAndVerifyCommitsFromShaReader(stdoutReader, repo, env)
+				err := readAndVerifyCommitsFromShaReader(stdoutReader, repo, env, protectBranch)
 				if err != nil {
 					log.Error("%v", err)
 					cancel()
@@ -104,11 +106,83 @@ func checkFileProtection(oldCommitID, newCommitID string, patterns []glob.Glob,
 	return err
 }
 
-func readAndVerifyCommitsFromShaReader(input io.ReadCloser, repo *git.Repository, env []string) error {
+// scanNulSeparated is a bufio.SplitFunc splitting the input at NUL bytes
+func scanNulSeparated(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// checkCommitMessages checks the messages of the commits pushed to a branch against the commit
+// message policy, it returns an ErrCommitMessageRejected for the first commit not following it
+func checkCommitMessages(oldCommitID, newCommitID string, policy *models.CommitMessagePolicy, repo *git.Repository, env []string) error {
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		log.Error("Unable to create os.Pipe for %s", repo.Path)
+		return err
+	}
+	defer func() {
+		_ = stdoutReader.Close()
+		_ = stdoutWriter.Close()
+	}()
+
+	args := []string{"log", "-z", "--format=%H%n%B"}
+	if oldCommitID == git.EmptySHA {
+		// only the commits of a new branch which are not on any other branch yet
+		args = append(args, newCommitID, "--not", "--all")
+	} else {
+		args = append(args, oldCommitID+".."+newCommitID)
+	}
+
+	err = git.NewCommand(args...).
+		RunInDirTimeoutEnvFullPipelineFunc(env, -1, repo.Path,
+			stdoutWriter, nil, nil,
+			func(ctx context.Context, cancel context.CancelFunc) error {
+				_ = stdoutWriter.Close()
+
+				scanner := bufio.NewScanner(stdoutReader)
+				scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+				scanner.Split(scanNulSeparated)
+				for scanner.Scan() {
+					entry := strings.TrimLeft(scanner.Text(), "\n")
+					if len(entry) == 0 {
+						continue
+					}
+					sha, message := entry, ""
+					if i := strings.IndexByte(entry, '\n'); i >= 0 {
+						sha, message = entry[:i], entry[i+1:]
+					}
+					if err := policy.CheckMessage(message); err != nil {
+						cancel()
+						rejected := err.(models.ErrCommitMessageRejected)
+						rejected.SHA = sha
+						return rejected
+					}
+				}
+				if err := scanner.Err(); err != nil {
+					return err
+				}
+				_ = stdoutReader.Close()
+				return nil
+			})
+	if err != nil && !models.IsErrCommitMessageRejected(err) {
+		log.Error("Unable to check commit messages from %s to %s in %s: %v", oldCommitID, newCommitID, repo.Path, err)
+	}
+	return err
+}
+
+func readAndVerifyCommitsFromShaReader(input io.ReadCloser, repo *git.Repository, env []string, protectBranch *models.ProtectedBranch) error {
 	scanner := bufio.NewScanner(input)
 	for scanner.Scan() {
 		line := scanner.Text()
-		err := readAndVerifyCommit(line, repo, env)
+		err := readAndVerifyCommit(line, repo, env, protectBranch)
 		if err != nil {
 			log.Error("%v", err)
 			return err
@@ -117,7 +191,9 @@ func readAndVerifyCommitsFromShaReader(input io.ReadCloser, repo *git.Repository
 	return scanner.Err()
 }
 
-func readAndVerifyCommit(sha string, repo *git.Repository, env []string) error {
+// readAndVerifyCommit checks the signature of the commit, it must be made by one of the
+// allowed keys of the protected branch if it is given
+func readAndVerifyCommit(sha string, repo *git.Repository, env []string, protectBranch *models.ProtectedBranch) error {
 	stdoutReader, stdoutWriter, err := os.Pipe()
 	if err != nil {
 		log.Error("Unable to create pipe for %s: %v", repo.Path, err)
@@ -139,7 +215,7 @@ func readAndVerifyCommit(sha string, repo *git.Repository, env []string) error {
 					return err
 				}
 				verification := models.ParseCommitWithSignature(commit)
-				if !verification.Verified {
+				if !verification.Verified || (protectBranch != nil && !protectBranch.IsCommitSignatureAllowed(verification)) {
 					cancel()
 					return &errUnverifiedCommit{
 						commit.ID.String(),
@@ -164,6 +240,13 @@ func isErrUnverifiedCommit(err error) bool {
 
 // HookPreReceive checks whether a individual commit is acceptable
 func HookPreReceive(ctx *macaron.Context, opts private.HookOptions) {
+	if state := maintenance.GetState(); state.Enabled {
+		ctx.JSON(http.StatusForbidden, map[string]interface{}{
+			"err": state.Message,
+		})
+		return
+	}
+
 	ownerName := ctx.Params(":owner")
 	repoName := ctx.Params(":repo")
 	repo, err := models.GetRepositoryByOwnerAndName(ownerName, repoName)
@@ -205,6 +288,13 @@ func HookPreReceive(ctx *macaron.Context, opts private.HookOptions) {
 		newCommitID := opts.NewCommitIDs[i]
 		refFullName := opts.RefFullNames[i]
 
+		if strings.HasPrefix(refFullName, git.TagPrefix) {
+			if !preReceiveTag(ctx, repo, gitRepo, env, opts, newCommitID, refFullName) {
+				return
+			}
+			continue
+		}
+
 		branchName := strings.TrimPrefix(refFullName, git.BranchPrefix)
 		if branchName == repo.DefaultBranch && newCommitID == git.EmptySHA {
 			log.Warn("Forbidden: Branch: %s is the default branch in %-v and cannot be deleted", branchName, repo)
@@ -214,7 +304,13 @@ func HookPreReceive(ctx *macaron.Context, opts private.HookOptions) {
 			return
 		}
 
-		protectBranch, err := models.GetProtectedBranchBy(repo.ID, branchName)
+		if newCommitID != git.EmptySHA && strings.HasPrefix(refFullName, git.BranchPrefix) {
+			if !preReceiveCommitMessages(ctx, repo, gitRepo, env, opts, oldCommitID, newCommitID, branchName) {
+				return
+			}
+		}
+
+		protectBranch, err := models.GetEffectiveProtectedBranch(repo, branchName, opts.UserID)
 		if err != nil {
 			log.Error("Unable to get protected branch: %s in %-v Error: %v", branchName, repo, err)
 			ctx.JSON(500, map[string]interface{}{
@@ -253,7 +349,7 @@ func HookPreReceive(ctx *macaron.Context, opts private.HookOptions) {
 
 			// Require signed commits
 			if protectBranch.RequireSignedCommits {
-				err := verifyCommits(oldCommitID, newCommitID, gitRepo, env)
+				err := verifyCommits(oldCommitID, newCommitID, gitRepo, env, protectBranch)
 				if err != nil {
 					if !isErrUnverifiedCommit(err) {
 						log.Error("Unable to check commits from %s to %s in %-v: %v", oldCommitID, newCommitID, repo, err)
@@ -294,7 +390,8 @@ func HookPreReceive(ctx *macaron.Context, opts private.HookOptions) {
 
 			canPush := false
 			if opts.IsDeployKey {
-				canPush = protectBranch.CanPush && (!protectBranch.EnableWhitelist || protectBranch.WhitelistDeployKeys)
+				canPush = protectBranch.CanPush && (!protectBranch.EnableWhitelist || protectBranch.WhitelistDeployKeys) &&
+					!protectBranch.IsPushRestrictedByRulesets()
 			} else {
 				canPush = protectBranch.CanUserPush(opts.UserID)
 			}
@@ -339,8 +436,16 @@ func HookPreReceive(ctx *macaron.Context, opts private.HookOptions) {
 					})
 					return
 				}
-				// Check all status checks and reviews is ok, unless repo admin which can bypass this.
-				if !perm.IsAdmin() {
+				// Check all status checks and reviews is ok, unless the user can bypass this.
+				canForceMerge, err := pull_service.IsUserAllowedToForceMerge(pr, perm, user)
+				if err != nil {
+					log.Error("Error calculating if allowed to force merge: %v", err)
+					ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+						"err": fmt.Sprintf("Error calculating if allowed to force merge: %v", err),
+					})
+					return
+				}
+				if !canForceMerge {
 					if err := pull_service.CheckPRReadyToMerge(pr); err != nil {
 						if models.IsErrNotAllowedToMerge(err) {
 							log.Warn("Forbidden: User %d is not allowed push to protected branch %s in %-v and pr #%d is not ready to be merged: %s", opts.UserID, branchName, repo, pr.Index, err.Error())
@@ -368,6 +473,86 @@ func HookPreReceive(ctx *macaron.Context, opts private.HookOptions) {
 	ctx.PlainText(http.StatusOK, []byte("ok"))
 }
 
+// preReceiveCommitMessages checks the messages of the commits pushed to a branch against the
+// commit message policy of the repository, it responds with an error and returns false if they
+// do not follow it
+func preReceiveCommitMessages(ctx *macaron.Context, repo *models.Repository, gitRepo *git.Repository, env []string, opts private.HookOptions, oldCommitID, newCommitID, branchName string) bool {
+	policy, err := models.GetCommitMessagePolicyForUser(repo.ID, opts.UserID)
+	if err != nil {
+		log.Error("Unable to get commit message policy of %-v Error: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return false
+	}
+	if policy == nil {
+		return true
+	}
+
+	if err := checkCommitMessages(oldCommitID, newCommitID, policy, gitRepo, env); err != nil {
+		if !models.IsErrCommitMessageRejected(err) {
+			ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"err": fmt.Sprintf("Unable to check commit messages from %s to %s: %v", oldCommitID, newCommitID, err),
+			})
+			return false
+		}
+		log.Warn("Forbidden: Branch: %s in %-v: %v", branchName, repo, err)
+		ctx.JSON(http.StatusForbidden, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// preReceiveTag checks the push of a tag against the rulesets of the
+// organization, it responds with an error and returns false if the push is
+// not allowed
+func preReceiveTag(ctx *macaron.Context, repo *models.Repository, gitRepo *git.Repository, env []string, opts private.HookOptions, newCommitID, refFullName string) bool {
+	tagName := strings.TrimPrefix(refFullName, git.TagPrefix)
+	rulesets, err := models.GetTagRulesets(repo, tagName, opts.UserID)
+	if err != nil {
+		log.Error("Unable to get rulesets of tag: %s in %-v Error: %v", tagName, repo, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return false
+	}
+
+	for _, r := range rulesets {
+		if (opts.IsDeployKey && r.RestrictPush) || !r.CanUserPush(opts.UserID) {
+			log.Warn("Forbidden: User %d is not allowed to push tag: %s in %-v protected by ruleset %s", opts.UserID, tagName, repo, r.Name)
+			ctx.JSON(http.StatusForbidden, map[string]interface{}{
+				"err": fmt.Sprintf("tag %s is protected by ruleset %s", tagName, r.Name),
+			})
+			return false
+		}
+
+		if r.RequireSignedCommits && newCommitID != git.EmptySHA {
+			commitID, err := git.NewCommand("rev-parse", newCommitID+"^{commit}").RunInDirWithEnv(repo.RepoPath(), env)
+			if err == nil {
+				err = readAndVerifyCommit(strings.TrimSpace(commitID), gitRepo, env, nil)
+			}
+			if err != nil {
+				if !isErrUnverifiedCommit(err) {
+					log.Error("Unable to check commit of tag %s in %-v: %v", tagName, repo, err)
+					ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+						"err": fmt.Sprintf("Unable to check commit of tag %s: %v", tagName, err),
+					})
+					return false
+				}
+				unverifiedCommit := err.(*errUnverifiedCommit).sha
+				log.Warn("Forbidden: Tag: %s in %-v is protected from unverified commit %s", tagName, repo, unverifiedCommit)
+				ctx.JSON(http.StatusForbidden, map[string]interface{}{
+					"err": fmt.Sprintf("tag %s is protected from unverified commit %s", tagName, unverifiedCommit),
+				})
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // HookPostReceive updates services and users
 func HookPostReceive(ctx *macaron.Context, opts private.HookOptions) {
 	ownerName := ctx.Params(":owner")
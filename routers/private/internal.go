@@ -47,5 +47,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Post("/manager/release-and-reopen-logging", ReleaseReopenLogging)
 		m.Post("/manager/add-logger", bind(private.LoggerOptions{}), AddLogger)
 		m.Post("/manager/remove-logger/:group/:name", RemoveLogger)
+		m.Combo("/manager/maintenance").Get(MaintenanceStatus).
+			Post(bind(private.MaintenanceOptions{}), SetMaintenance)
 	}, CheckInternalToken)
 }
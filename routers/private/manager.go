@@ -11,6 +11,7 @@ import (
 
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/maintenance"
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
@@ -154,3 +155,20 @@ func AddLogger(ctx *macaron.Context, opts private.LoggerOptions) {
 
 	ctx.PlainText(http.StatusOK, []byte("success"))
 }
+
+// SetMaintenance enables or disables the maintenance mode
+func SetMaintenance(ctx *macaron.Context, opts private.MaintenanceOptions) {
+	if opts.Enabled {
+		maintenance.Enable(opts.Message)
+		log.Info("Maintenance mode enabled")
+	} else {
+		maintenance.Disable()
+		log.Info("Maintenance mode disabled")
+	}
+	ctx.PlainText(http.StatusOK, []byte("success"))
+}
+
+// MaintenanceStatus returns the maintenance mode and the work which is still draining
+func MaintenanceStatus(ctx *macaron.Context) {
+	ctx.JSON(http.StatusOK, maintenance.GetStatus())
+}
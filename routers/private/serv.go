@@ -12,6 +12,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/maintenance"
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/setting"
 	repo_service "code.gitea.io/gitea/services/repository"
@@ -86,6 +87,16 @@ func ServCommand(ctx *macaron.Context) {
 		modeString = "write to"
 	}
 
+	// Writes are declined whilst the instance is in maintenance mode
+	if mode > models.AccessModeRead && maintenance.IsEnabled() {
+		ctx.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+			"results": results,
+			"type":    "ErrMaintenance",
+			"err":     maintenance.GetState().Message,
+		})
+		return
+	}
+
 	// The default unit we're trying to look at is code
 	unitType := models.UnitTypeCode
 
@@ -6,11 +6,14 @@ package repo
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"path"
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -24,8 +27,32 @@ func renderAttachmentSettings(ctx *context.Context) {
 	ctx.Data["AttachmentMaxFiles"] = setting.AttachmentMaxFiles
 }
 
+// renderIssueAttachmentSettings sets the attachment settings of the issues of the repository,
+// with the size limit of the attachment policy of its owner.
+func renderIssueAttachmentSettings(ctx *context.Context) {
+	renderAttachmentSettings(ctx)
+	policy, err := models.GetEffectiveAttachmentPolicy(ctx.Repo.Repository.OwnerID)
+	if err != nil {
+		log.Error("GetEffectiveAttachmentPolicy: %v", err)
+		return
+	}
+	ctx.Data["AttachmentMaxSize"] = policy.MaxSize
+}
+
 // UploadAttachment response for uploading issue's attachment
 func UploadAttachment(ctx *context.Context) {
+	uploadAttachment(ctx, 0)
+}
+
+// UploadIssueAttachment response for uploading the attachment of an issue or a comment of a
+// repository, the attachment policy of the owner of the repository applies.
+func UploadIssueAttachment(ctx *context.Context) {
+	uploadAttachment(ctx, ctx.Repo.Repository.OwnerID)
+}
+
+// uploadAttachment uploads an attachment within the limits of the attachment policy of the owner,
+// an owner 0 has the limits of the instance.
+func uploadAttachment(ctx *context.Context, ownerID int64) {
 	if !setting.AttachmentEnabled {
 		ctx.Error(404, "attachment is not enabled")
 		return
@@ -50,11 +77,29 @@ func UploadAttachment(ctx *context.Context) {
 		return
 	}
 
+	policy, err := models.GetEffectiveAttachmentPolicy(ownerID)
+	if err != nil {
+		ctx.Error(500, fmt.Sprintf("GetEffectiveAttachmentPolicy: %v", err))
+		return
+	}
+	if err := policy.CheckFile(http.DetectContentType(buf), header.Size); err != nil {
+		if upload.IsErrFileTooLarge(err) {
+			ctx.Error(413, ctx.Tr("dropzone.file_too_big_for_type", err.(upload.ErrFileTooLarge).MaxSize/(1024*1024)))
+			return
+		}
+		ctx.Error(500, fmt.Sprintf("CheckFile: %v", err))
+		return
+	}
+
 	attach, err := models.NewAttachment(&models.Attachment{
 		UploaderID: ctx.User.ID,
 		Name:       header.Filename,
 	}, buf, file)
 	if err != nil {
+		if upload.IsErrMalformedImage(err) {
+			ctx.Error(400, err.Error())
+			return
+		}
 		ctx.Error(500, fmt.Sprintf("NewAttachment: %v", err))
 		return
 	}
@@ -87,8 +132,10 @@ func DeleteAttachment(ctx *context.Context) {
 	})
 }
 
-// GetAttachment serve attachements
-func GetAttachment(ctx *context.Context) {
+// getReadableAttachment returns the attachment of the request if the user can read it, the
+// not found page is shown otherwise. An attachment which is not linked yet can only be read
+// by its uploader.
+func getReadableAttachment(ctx *context.Context) *models.Attachment {
 	attach, err := models.GetAttachmentByUUID(ctx.Params(":uuid"))
 	if err != nil {
 		if models.IsErrAttachmentNotExist(err) {
@@ -96,31 +143,40 @@ func GetAttachment(ctx *context.Context) {
 		} else {
 			ctx.ServerError("GetAttachmentByUUID", err)
 		}
-		return
+		return nil
 	}
 
 	repository, unitType, err := attach.LinkedRepository()
 	if err != nil {
 		ctx.ServerError("LinkedRepository", err)
-		return
+		return nil
 	}
 
 	if repository == nil { //If not linked
 		if !(ctx.IsSigned && attach.UploaderID == ctx.User.ID) { //We block if not the uploader
 			ctx.Error(http.StatusNotFound)
-			return
+			return nil
 		}
 	} else { //If we have the repository we check access
 		perm, err := models.GetUserRepoPermission(repository, ctx.User)
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err.Error())
-			return
+			return nil
 		}
 		if !perm.CanRead(unitType) {
 			ctx.Error(http.StatusNotFound)
-			return
+			return nil
 		}
 	}
+	return attach
+}
+
+// GetAttachment serve attachements
+func GetAttachment(ctx *context.Context) {
+	attach := getReadableAttachment(ctx)
+	if ctx.Written() {
+		return
+	}
 
 	//If we have matched and access to release or issue
 	fr, err := os.Open(attach.LocalPath())
@@ -130,8 +186,34 @@ func GetAttachment(ctx *context.Context) {
 	}
 	defer fr.Close()
 
-	if err := attach.IncreaseDownloadCount(); err != nil {
-		ctx.ServerError("Update", err)
+	buf := make([]byte, 512)
+	n, _ := io.ReadFull(fr, buf)
+	if _, err := fr.Seek(0, io.SeekStart); err != nil {
+		ctx.ServerError("Seek", err)
+		return
+	}
+
+	// a video is played from the requested ranges, only its first range is counted as a download
+	rangeHeader := ctx.Req.Header.Get("Range")
+	isVideo := base.IsVideoFile(buf[:n])
+	if !isVideo || rangeHeader == "" || strings.HasPrefix(rangeHeader, "bytes=0-") {
+		if err := attach.IncreaseDownloadCount(); err != nil {
+			ctx.ServerError("Update", err)
+			return
+		}
+	}
+
+	if isVideo {
+		info, err := fr.Stat()
+		if err != nil {
+			ctx.ServerError("Stat", err)
+			return
+		}
+		name := strings.Replace(path.Base(attach.Name), ",", " ", -1)
+		ctx.Resp.Header().Set("Cache-Control", "public,max-age=86400")
+		ctx.Resp.Header().Set("Content-Type", http.DetectContentType(buf[:n]))
+		ctx.Resp.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, name))
+		http.ServeContent(ctx.Resp, ctx.Req.Request, name, info.ModTime(), fr)
 		return
 	}
 
@@ -140,3 +222,32 @@ func GetAttachment(ctx *context.Context) {
 		return
 	}
 }
+
+// GetAttachmentThumbnail serves the thumbnail of an image attachment, it is generated the
+// first time it is requested. The image itself is served when it cannot be resized.
+func GetAttachmentThumbnail(ctx *context.Context) {
+	attach := getReadableAttachment(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !attach.HasThumbnail() {
+		ctx.Error(http.StatusNotFound)
+		return
+	}
+
+	thumbnailPath := attach.ThumbnailPath()
+	if _, err := os.Stat(thumbnailPath); os.IsNotExist(err) {
+		if err := upload.GenerateThumbnail(attach.LocalPath(), thumbnailPath, setting.AttachmentThumbnailSize,
+			setting.AttachmentThumbnailMaxWidth, setting.AttachmentThumbnailMaxHeight); err != nil {
+			log.Warn("Unable to generate the thumbnail of attachment %s: %v", attach.UUID, err)
+			ctx.Redirect(attach.DownloadURL())
+			return
+		}
+		log.Trace("Thumbnail of attachment %s generated", attach.UUID)
+	} else if err != nil {
+		ctx.ServerError("Stat", err)
+		return
+	}
+	ctx.Resp.Header().Set("Cache-Control", "public,max-age=86400")
+	http.ServeFile(ctx.Resp, ctx.Req.Request, thumbnailPath)
+}
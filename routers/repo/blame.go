@@ -7,14 +7,18 @@ package repo
 import (
 	"bytes"
 	"container/list"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"html"
 	gotemplate "html/template"
+	"net/http"
 	"net/url"
 	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/highlight"
@@ -35,8 +39,6 @@ func RefBlame(ctx *context.Context) {
 		return
 	}
 
-	userName := ctx.Repo.Owner.Name
-	repoName := ctx.Repo.Repository.Name
 	commitID := ctx.Repo.CommitID
 
 	commit, err := ctx.Repo.GitRepo.GetCommit(commitID)
@@ -117,33 +119,128 @@ func RefBlame(ctx *context.Context) {
 	ctx.Data["FileSize"] = blob.Size()
 	ctx.Data["FileName"] = blob.Name()
 
-	ctx.Data["NumLines"], err = blob.GetBlobLineCount()
+	numLines, err := blob.GetBlobLineCount()
 	if err != nil {
 		ctx.NotFound("GetBlobLineCount", err)
 		return
 	}
+	ctx.Data["NumLines"] = numLines
 
-	blameReader, err := git.CreateBlameReader(ctx.Req.Context(), models.RepoPath(userName, repoName), commitID, fileName)
+	// Long files are blamed in chunks, the first one is rendered with the
+	// page and the others are requested by the browser one after another.
+	chunkLines := setting.UI.BlameChunkLines
+	if chunkLines <= 0 {
+		chunkLines = numLines
+	}
+	startLine := 1
+	isChunk := ctx.QueryInt("start") > 0
+	if isChunk {
+		startLine = ctx.QueryInt("start")
+		if startLine > numLines {
+			ctx.NotFound("Blame start", nil)
+			return
+		}
+	}
+	endLine := startLine + chunkLines - 1
+	if endLine >= numLines {
+		endLine = numLines
+	}
+
+	// The blame at the last commit changing the file is the same as at any
+	// later commit, so it is used for the cache key together with the blob.
+	blameParts, err := getBlameParts(ctx, latestCommit.ID.String(), entry.ID.String(), fileName, startLine, endLine, numLines)
 	if err != nil {
-		ctx.NotFound("CreateBlameReader", err)
+		ctx.NotFound("getBlameParts", err)
+		return
+	}
+
+	commitNames, err := getBlameCommitNames(ctx, blameParts)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("Repo.GitRepo.GetCommit", err)
+		} else {
+			ctx.ServerError("Repo.GitRepo.GetCommit", err)
+		}
 		return
 	}
-	defer blameReader.Close()
 
-	blameParts := make([]git.BlamePart, 0)
+	commitInfo, lineNumbers, codeLines := renderBlame(ctx, blameParts, commitNames, startLine)
+
+	nextLine := 0
+	if endLine < numLines {
+		nextLine = endLine + 1
+	}
+
+	if isChunk {
+		ctx.JSON(http.StatusOK, map[string]interface{}{
+			"commit_info":  commitInfo,
+			"line_numbers": lineNumbers,
+			"code":         codeLines,
+			"next":         nextLine,
+		})
+		return
+	}
+
+	// Get Topics of this repo
+	renderRepoTopics(ctx)
+	if ctx.Written() {
+		return
+	}
 
-	for {
-		blamePart, err := blameReader.NextPart()
+	ctx.Data["BlameContent"] = gotemplate.HTML(codeLines)
+	ctx.Data["BlameCommitInfo"] = gotemplate.HTML(commitInfo)
+	ctx.Data["BlameLineNums"] = gotemplate.HTML(lineNumbers)
+	ctx.Data["BlameNextLine"] = nextLine
+
+	ctx.HTML(200, tplBlame)
+}
+
+func getBlameParts(ctx *context.Context, commitID, blobID, fileName string, startLine, endLine, numLines int) ([]git.BlamePart, error) {
+	key := fmt.Sprintf("blame:%d:%s:%s:%x:%d-%d", ctx.Repo.Repository.ID, commitID, blobID, sha256.Sum256([]byte(fileName)), startLine, endLine)
+	parts, err := cache.GetString(key, func() (string, error) {
+		var (
+			blameReader *git.BlameReader
+			err         error
+		)
+		repoPath := ctx.Repo.Repository.RepoPath()
+		switch {
+		case startLine == 1 && endLine == numLines:
+			blameReader, err = git.CreateBlameReader(context.LogContext(ctx.Context), repoPath, commitID, fileName)
+		case endLine == numLines:
+			// the last line is not counted if the file does not end with a newline
+			blameReader, err = git.CreateBlameReaderForLines(context.LogContext(ctx.Context), repoPath, commitID, fileName, startLine, 0)
+		default:
+			blameReader, err = git.CreateBlameReaderForLines(context.LogContext(ctx.Context), repoPath, commitID, fileName, startLine, endLine)
+		}
 		if err != nil {
-			ctx.NotFound("NextPart", err)
-			return
+			return "", err
 		}
-		if blamePart == nil {
-			break
+		defer blameReader.Close()
+
+		blameParts := make([]git.BlamePart, 0)
+		for {
+			blamePart, err := blameReader.NextPart()
+			if err != nil {
+				return "", err
+			}
+			if blamePart == nil {
+				break
+			}
+			blameParts = append(blameParts, *blamePart)
 		}
-		blameParts = append(blameParts, *blamePart)
+
+		data, err := json.Marshal(blameParts)
+		return string(data), err
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	var blameParts []git.BlamePart
+	return blameParts, json.Unmarshal([]byte(parts), &blameParts)
+}
+
+func getBlameCommitNames(ctx *context.Context, blameParts []git.BlamePart) (map[string]models.UserCommit, error) {
 	commitNames := make(map[string]models.UserCommit)
 	commits := list.New()
 
@@ -155,12 +252,7 @@ func RefBlame(ctx *context.Context) {
 
 		commit, err := ctx.Repo.GitRepo.GetCommit(sha)
 		if err != nil {
-			if git.IsErrNotExist(err) {
-				ctx.NotFound("Repo.GitRepo.GetCommit", err)
-			} else {
-				ctx.ServerError("Repo.GitRepo.GetCommit", err)
-			}
-			return
+			return nil, err
 		}
 
 		commits.PushBack(commit)
@@ -175,19 +267,12 @@ func RefBlame(ctx *context.Context) {
 
 		commitNames[c.ID.String()] = c
 	}
-
-	// Get Topics of this repo
-	renderRepoTopics(ctx)
-	if ctx.Written() {
-		return
-	}
-
-	renderBlame(ctx, blameParts, commitNames)
-
-	ctx.HTML(200, tplBlame)
+	return commitNames, nil
 }
 
-func renderBlame(ctx *context.Context, blameParts []git.BlamePart, commitNames map[string]models.UserCommit) {
+// renderBlame returns the HTML of the commit info, line number and code columns of the blame, numbering lines from
+// startLine.
+func renderBlame(ctx *context.Context, blameParts []git.BlamePart, commitNames map[string]models.UserCommit, startLine int) (string, string, string) {
 	repoLink := ctx.Repo.RepoLink
 
 	var lines = make([]string, 0)
@@ -196,7 +281,7 @@ func renderBlame(ctx *context.Context, blameParts []git.BlamePart, commitNames m
 	var lineNumbers bytes.Buffer
 	var codeLines bytes.Buffer
 
-	var i = 0
+	var i = startLine - 1
 	for pi, part := range blameParts {
 		for index, line := range part.Lines {
 			i++
@@ -232,7 +317,7 @@ func renderBlame(ctx *context.Context, blameParts []git.BlamePart, commitNames m
 				lineNumbers.WriteString(fmt.Sprintf(`<span id="L%d" data-line-number="%d"></span>`, i, i))
 			}
 
-			if i != len(lines)-1 {
+			if i-startLine != len(lines)-1 {
 				line += "\n"
 			}
 			fileName := fmt.Sprintf("%v", ctx.Data["FileName"])
@@ -245,7 +330,5 @@ func renderBlame(ctx *context.Context, blameParts []git.BlamePart, commitNames m
 		}
 	}
 
-	ctx.Data["BlameContent"] = gotemplate.HTML(codeLines.String())
-	ctx.Data["BlameCommitInfo"] = gotemplate.HTML(commitInfo.String())
-	ctx.Data["BlameLineNums"] = gotemplate.HTML(lineNumbers.String())
+	return commitInfo.String(), lineNumbers.String(), codeLines.String()
 }
@@ -7,6 +7,7 @@ package repo
 
 import (
 	"fmt"
+	"html"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -17,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/repofiles"
 	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/utils"
 )
@@ -37,6 +39,10 @@ type Branch struct {
 	CommitsBehind     int
 	LatestPullRequest *models.PullRequest
 	MergeMovedOn      bool
+	IsMerged          bool
+	IsInactive        bool
+	IsStale           bool
+	CanDelete         bool
 }
 
 // Branches render repository branch page
@@ -50,8 +56,39 @@ func Branches(ctx *context.Context) {
 	ctx.Data["CanPull"] = ctx.Repo.CanWrite(models.UnitTypeCode) || (ctx.IsSigned && ctx.User.HasForkedRepo(ctx.Repo.Repository.ID))
 	ctx.Data["PageIsViewCode"] = true
 	ctx.Data["PageIsBranches"] = true
+	ctx.Data["StaleBranchDays"] = setting.Repository.StaleBranchDays
 
-	ctx.Data["Branches"] = loadBranches(ctx)
+	branches := loadBranches(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.Query("state") == "stale" {
+		ctx.Data["IsStaleView"] = true
+		canDelete := ctx.Repo.CanWrite(models.UnitTypeCode) && !ctx.Repo.Repository.IsMirror && !ctx.Repo.Repository.IsArchived
+		staleBranches := make([]*Branch, 0, len(branches))
+		for _, branch := range branches {
+			if branch.Name == ctx.Repo.Repository.DefaultBranch {
+				staleBranches = append(staleBranches, branch)
+				continue
+			}
+			if !branch.IsStale {
+				continue
+			}
+			if canDelete {
+				isProtected, err := ctx.Repo.Repository.IsProtectedBranch(branch.Name, ctx.User)
+				if err != nil {
+					ctx.ServerError("IsProtectedBranch", err)
+					return
+				}
+				branch.CanDelete = !isProtected
+			}
+			staleBranches = append(staleBranches, branch)
+		}
+		branches = staleBranches
+	}
+
+	ctx.Data["Branches"] = branches
 	ctx.HTML(200, tplBranch)
 }
 
@@ -89,6 +126,40 @@ func DeleteBranchPost(ctx *context.Context) {
 	ctx.Flash.Success(ctx.Tr("repo.branch.deletion_success", branchName))
 }
 
+// DeleteBranchesPost responses for deleting the selected stale branches, the default and the
+// protected branches are skipped
+func DeleteBranchesPost(ctx *context.Context) {
+	var deleted, skipped []string
+	for _, branchName := range ctx.QueryStrings("branch") {
+		if branchName == ctx.Repo.Repository.DefaultBranch || !ctx.Repo.GitRepo.IsBranchExist(branchName) {
+			skipped = append(skipped, branchName)
+			continue
+		}
+		isProtected, err := ctx.Repo.Repository.IsProtectedBranch(branchName, ctx.User)
+		if err != nil {
+			ctx.ServerError("IsProtectedBranch", err)
+			return
+		}
+		if isProtected {
+			skipped = append(skipped, branchName)
+			continue
+		}
+		if err := deleteBranch(ctx, branchName); err != nil {
+			skipped = append(skipped, branchName)
+			continue
+		}
+		deleted = append(deleted, branchName)
+	}
+
+	if len(deleted) > 0 {
+		ctx.Flash.Success(ctx.Tr("repo.branch.bulk_deletion_success", len(deleted)))
+	}
+	if len(skipped) > 0 {
+		ctx.Flash.Error(ctx.Tr("repo.branch.bulk_deletion_skipped", html.EscapeString(strings.Join(skipped, ", "))))
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/branches?state=stale")
+}
+
 // RestoreBranchPost responses for delete merged branch
 func RestoreBranchPost(ctx *context.Context) {
 	defer redirect(ctx)
@@ -248,7 +319,7 @@ func loadBranches(ctx *context.Context) []*Branch {
 			if pr.HasMerged {
 				baseGitRepo, ok := repoIDToGitRepo[pr.BaseRepoID]
 				if !ok {
-					baseGitRepo, err = git.OpenRepository(pr.BaseRepo.RepoPath())
+					baseGitRepo, err = git.OpenRepositoryCtx(context.LogContext(ctx.Context), pr.BaseRepo.RepoPath())
 					if err != nil {
 						ctx.ServerError("OpenRepository", err)
 						return nil
@@ -269,6 +340,8 @@ func loadBranches(ctx *context.Context) []*Branch {
 		}
 
 		isIncluded := divergence.Ahead == 0 && ctx.Repo.Repository.DefaultBranch != branchName
+		isMerged := isIncluded || (pr != nil && pr.HasMerged && !mergeMovedOn)
+		isInactive := ctx.Repo.Repository.DefaultBranch != branchName && repo_module.IsBranchInactive(commit)
 
 		branches[i] = &Branch{
 			Name:              branchName,
@@ -279,6 +352,9 @@ func loadBranches(ctx *context.Context) []*Branch {
 			CommitsBehind:     divergence.Behind,
 			LatestPullRequest: pr,
 			MergeMovedOn:      mergeMovedOn,
+			IsMerged:          isMerged,
+			IsInactive:        isInactive,
+			IsStale:           isMerged || isInactive,
 		}
 	}
 
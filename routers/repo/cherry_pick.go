@@ -0,0 +1,173 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/repofiles"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/utils"
+	pull_service "code.gitea.io/gitea/services/pull"
+
+	"github.com/unknwon/com"
+)
+
+const tplCherryPick base.TplName = "repo/editor/cherry_pick"
+
+// prepareCherryPick loads the commit to cherry-pick or revert and the data of the form
+func prepareCherryPick(ctx *context.Context, revert bool) *git.Commit {
+	commit, err := ctx.Repo.GitRepo.GetCommit(ctx.Params(":sha"))
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("GetCommit", err)
+		} else {
+			ctx.ServerError("GetCommit", err)
+		}
+		return nil
+	}
+
+	branches, err := ctx.Repo.GitRepo.GetBranches()
+	if err != nil {
+		ctx.ServerError("GetBranches", err)
+		return nil
+	}
+
+	ctx.Data["IsRevert"] = revert
+	if revert {
+		ctx.Data["Title"] = ctx.Tr("repo.editor.revert_commit", base.ShortSha(commit.ID.String()))
+	} else {
+		ctx.Data["Title"] = ctx.Tr("repo.editor.cherry_pick_commit", base.ShortSha(commit.ID.String()))
+	}
+	ctx.Data["CherryPickCommit"] = commit
+	ctx.Data["CommitLink"] = ctx.Repo.RepoLink + "/commit/" + commit.ID.String()
+	ctx.Data["Branches"] = branches
+	ctx.Data["PullRequestsEnabled"] = ctx.Repo.Repository.UnitEnabled(models.UnitTypePullRequests)
+	return commit
+}
+
+// CherryPick renders the page to cherry-pick or revert a commit to a new branch
+func CherryPick(ctx *context.Context) {
+	revert := ctx.QueryBool("revert")
+	commit := prepareCherryPick(ctx, revert)
+	if ctx.Written() {
+		return
+	}
+
+	prefix := "cherry-pick-"
+	if revert {
+		prefix = "revert-"
+	}
+	ctx.Data["base_branch"] = ctx.Repo.Repository.DefaultBranch
+	ctx.Data["new_branch_name"] = prefix + base.ShortSha(commit.ID.String())
+	ctx.Data["commit_message"] = repofiles.CherryPickMessage(commit, revert)
+
+	ctx.HTML(200, tplCherryPick)
+}
+
+// CherryPickPost response for cherry-picking or reverting a commit to a new branch, a pull
+// request of the new branch is opened if the repository accepts them
+func CherryPickPost(ctx *context.Context, form auth.CherryPickForm) {
+	commit := prepareCherryPick(ctx, form.Revert)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Data["base_branch"] = form.BaseBranch
+	ctx.Data["new_branch_name"] = form.NewBranchName
+	ctx.Data["commit_message"] = form.CommitMessage
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplCherryPick)
+		return
+	}
+
+	message := strings.TrimSpace(form.CommitMessage)
+	if _, err := repofiles.CherryPick(ctx.Repo.Repository, ctx.User, &repofiles.CherryPickOptions{
+		CommitID:  commit.ID.String(),
+		Revert:    form.Revert,
+		OldBranch: form.BaseBranch,
+		NewBranch: form.NewBranchName,
+		Message:   message,
+	}); err != nil {
+		// This is where we handle all the errors thrown by repofiles.CherryPick
+		if git.IsErrBranchNotExist(err) {
+			ctx.Data["Err_BaseBranch"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.editor.branch_does_not_exist", form.BaseBranch), tplCherryPick, &form)
+		} else if models.IsErrBranchAlreadyExists(err) {
+			ctx.Data["Err_NewBranchName"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.editor.branch_already_exists", form.NewBranchName), tplCherryPick, &form)
+		} else if models.IsErrCherryPickConflict(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.editor.cherry_pick_conflict", form.BaseBranch, strings.Join(err.(models.ErrCherryPickConflict).Files, ", ")), tplCherryPick, &form)
+		} else if models.IsErrCherryPickMergeCommit(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.editor.cherry_pick_merge_commit"), tplCherryPick, &form)
+		} else if models.IsErrCherryPickEmpty(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.editor.cherry_pick_empty", form.BaseBranch), tplCherryPick, &form)
+		} else if models.IsErrCommitMessageRejected(err) {
+			ctx.Data["Err_CommitMessage"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.editor.commit_message_rejected", err.(models.ErrCommitMessageRejected).Reason), tplCherryPick, &form)
+		} else if git.IsErrPushRejected(err) {
+			errPushRej := err.(*git.ErrPushRejected)
+			if len(errPushRej.Message) == 0 {
+				ctx.RenderWithErr(ctx.Tr("repo.editor.push_rejected_no_message"), tplCherryPick, &form)
+			} else {
+				ctx.RenderWithErr(ctx.Tr("repo.editor.push_rejected", utils.SanitizeFlashErrorString(errPushRej.Message)), tplCherryPick, &form)
+			}
+		} else {
+			ctx.ServerError("CherryPick", err)
+		}
+		return
+	}
+
+	if !ctx.Repo.Repository.UnitEnabled(models.UnitTypePullRequests) {
+		ctx.Flash.Success(ctx.Tr("repo.editor.cherry_pick_success", form.NewBranchName))
+		ctx.Redirect(ctx.Repo.RepoLink + "/src/branch/" + util.PathEscapeSegments(form.NewBranchName))
+		return
+	}
+
+	repo := ctx.Repo.Repository
+	compareInfo, err := ctx.Repo.GitRepo.GetCompareInfo(repo.RepoPath(), form.BaseBranch, form.NewBranchName)
+	if err != nil {
+		ctx.ServerError("GetCompareInfo", err)
+		return
+	}
+
+	title, content := message, ""
+	if idx := strings.IndexByte(message, '\n'); idx >= 0 {
+		title, content = strings.TrimSpace(message[:idx]), strings.TrimSpace(message[idx+1:])
+	}
+	pullIssue := &models.Issue{
+		RepoID:   repo.ID,
+		Title:    title,
+		PosterID: ctx.User.ID,
+		Poster:   ctx.User,
+		IsPull:   true,
+		Content:  content,
+	}
+	pullRequest := &models.PullRequest{
+		HeadRepoID: repo.ID,
+		BaseRepoID: repo.ID,
+		HeadBranch: form.NewBranchName,
+		BaseBranch: form.BaseBranch,
+		HeadRepo:   repo,
+		BaseRepo:   repo,
+		MergeBase:  compareInfo.MergeBase,
+		Type:       models.PullRequestGitea,
+	}
+	if err := pull_service.NewPullRequest(repo, pullIssue, nil, nil, pullRequest, nil); err != nil {
+		ctx.ServerError("NewPullRequest", err)
+		return
+	}
+
+	log.Trace("Pull request of cherry-picked commit %s created: %d/%d", commit.ID, repo.ID, pullIssue.ID)
+	ctx.Flash.Success(ctx.Tr("repo.editor.cherry_pick_success", form.NewBranchName))
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pullIssue.Index))
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+const tplContributorAgreement base.TplName = "repo/cla"
+
+// prepareContributorAgreementPrompt asks the signed in user to sign the contributor license
+// agreement of the repository if they have not yet.
+func prepareContributorAgreementPrompt(ctx *context.Context) {
+	if !ctx.IsSigned {
+		return
+	}
+	agreement, err := models.GetRepoContributorAgreement(ctx.Repo.Repository)
+	if err != nil {
+		ctx.ServerError("GetRepoContributorAgreement", err)
+		return
+	}
+	if agreement == nil {
+		return
+	}
+	signed, err := agreement.HasSigned(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("HasSigned", err)
+		return
+	}
+	ctx.Data["ContributorAgreementUnsigned"] = !signed
+}
+
+func getRepoContributorAgreement(ctx *context.Context) *models.ContributorAgreement {
+	agreement, err := models.GetRepoContributorAgreement(ctx.Repo.Repository)
+	if err != nil {
+		ctx.ServerError("GetRepoContributorAgreement", err)
+		return nil
+	}
+	if agreement == nil {
+		ctx.NotFound("GetRepoContributorAgreement", nil)
+		return nil
+	}
+	return agreement
+}
+
+// ContributorAgreement renders the contributor license agreement of a repository
+func ContributorAgreement(ctx *context.Context) {
+	agreement := getRepoContributorAgreement(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Data["Title"] = agreement.Title
+	ctx.Data["Agreement"] = agreement
+	ctx.Data["RenderedContent"] = string(markdown.Render([]byte(agreement.Content), ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas()))
+	ctx.Data["RedirectTo"] = ctx.Query("redirect_to")
+	if ctx.IsSigned {
+		signed, err := agreement.HasSigned(ctx.User.ID)
+		if err != nil {
+			ctx.ServerError("HasSigned", err)
+			return
+		}
+		ctx.Data["HasSigned"] = signed
+	}
+
+	ctx.HTML(200, tplContributorAgreement)
+}
+
+// SignContributorAgreement records that the user accepted the contributor license agreement of
+// a repository
+func SignContributorAgreement(ctx *context.Context) {
+	agreement := getRepoContributorAgreement(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := agreement.Sign(ctx.User.ID); err != nil {
+		ctx.ServerError("Sign", err)
+		return
+	}
+	go pull_service.RecheckContributorAgreement(agreement, ctx.User)
+
+	ctx.Flash.Success(ctx.Tr("repo.cla.signed_success", agreement.Title))
+	ctx.RedirectToFirst(ctx.Query("redirect_to"), ctx.Repo.RepoLink+"/cla")
+}
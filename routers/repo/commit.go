@@ -6,11 +6,14 @@
 package repo
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"path"
 	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/charset"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
@@ -165,7 +168,11 @@ func FileHistory(ctx *context.Context) {
 	}
 
 	branchName := ctx.Repo.BranchName
-	commitsCount, err := ctx.Repo.GitRepo.FileCommitsCount(branchName, fileName)
+	// Counting walks the whole history, so the result is cached by commit
+	// which never changes unlike the branch.
+	commitsCount, err := cache.GetInt64(fmt.Sprintf("file-commits-count-%d-%s-%x", ctx.Repo.Repository.ID, ctx.Repo.CommitID, sha256.Sum256([]byte(fileName))), func() (int64, error) {
+		return ctx.Repo.GitRepo.FileCommitsCount(ctx.Repo.CommitID, fileName)
+	})
 	if err != nil {
 		ctx.ServerError("FileCommitsCount", err)
 		return
@@ -219,7 +226,7 @@ func Diff(ctx *context.Context) {
 	)
 
 	if ctx.Data["PageIsWiki"] != nil {
-		gitRepo, err = git.OpenRepository(ctx.Repo.Repository.WikiPath())
+		gitRepo, err = git.OpenRepositoryCtx(context.LogContext(ctx.Context), ctx.Repo.Repository.WikiPath())
 		if err != nil {
 			ctx.ServerError("Repo.GitRepo.GetCommit", err)
 			return
@@ -284,11 +291,19 @@ func Diff(ctx *context.Context) {
 	setImageCompareContext(ctx, parentCommit, commit)
 	headTarget := path.Join(userName, repoName)
 	setPathsCompareContext(ctx, parentCommit, commit, headTarget)
+	diff.LoadSubmodules(ctx.Repo.Repository, parentCommit, commit, ctx.User)
 	ctx.Data["Title"] = commit.Summary() + " · " + base.ShortSha(commitID)
 	ctx.Data["Commit"] = commit
 	verification := models.ParseCommitWithSignature(commit)
 	ctx.Data["Verification"] = verification
 	ctx.Data["Author"] = models.ValidateCommitWithEmail(commit)
+	ctx.Data["CoAuthors"] = models.ValidateSignaturesWithEmails(commit.CoAuthors())
+	ctx.Data["Reviewers"] = models.ValidateSignaturesWithEmails(commit.Reviewers())
+	if ctx.Data["PageIsWiki"] == nil {
+		diff.DeferLargeFiles(setting.Git.MaxGitDiffLazyLines)
+		ctx.Data["DiffFileLink"] = ctx.Repo.RepoLink + "/diff_file"
+		ctx.Data["RichDiffLink"] = ctx.Repo.RepoLink + "/rich_diff"
+	}
 	ctx.Data["Diff"] = diff
 	ctx.Data["Parents"] = parents
 	ctx.Data["DiffNotAvailable"] = diff.NumFiles == 0
@@ -16,15 +16,24 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/pulltemplate"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/services/gitdiff"
+
+	"github.com/unknwon/com"
 )
 
 const (
 	tplCompare     base.TplName = "repo/diff/compare"
 	tplBlobExcerpt base.TplName = "repo/diff/blob_excerpt"
+	tplDiffFile    base.TplName = "repo/diff/lazy_file"
+	tplRichDiff    base.TplName = "repo/diff/rich_diff"
 )
 
+// compareDownloadFormats are the suffixes of the compare url which download
+// the comparison as a raw diff or as a patch instead of rendering it
+var compareDownloadFormats = []string{".diff", ".patch"}
+
 // setPathsCompareContext sets context data for source and raw paths
 func setPathsCompareContext(ctx *context.Context, base *git.Commit, head *git.Commit, headTarget string) {
 	sourcePath := setting.AppSubURL + "/%s/src/commit/%s"
@@ -75,6 +84,8 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 	// 2. /{:baseOwner}/{:baseRepoName}/compare/{:baseBranch}...{:headOwner}:{:headBranch}
 	// 3. /{:baseOwner}/{:baseRepoName}/compare/{:baseBranch}...{:headOwner}/{:headRepoName}:{:headBranch}
 	//
+	// Any of them may end with .diff or .patch to download the comparison.
+	//
 	// Here we obtain the infoPath "{:baseBranch}...[{:headOwner}/{:headRepoName}:]{:headBranch}" as ctx.Params("*")
 	// with the :baseRepo in ctx.Repo.
 	//
@@ -86,6 +97,7 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 	// 2. If :headOwner is set - then look for the fork of :baseRepo owned by :headOwner
 	// 3. But... :baseRepo could be a fork of :headOwner's repo - so check that
 	// 4. Now, :baseRepo and :headRepos could be forks of the same repo - so check that
+	// 5. Finally, :headOwner could own any other repository of the fork network of :baseRepo
 	//
 	// format: <base branch>...[<head repo>:]<head branch>
 	// base<-head: master...head:feature
@@ -100,6 +112,13 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 		err        error
 	)
 	infoPath = ctx.Params("*")
+	for _, format := range compareDownloadFormats {
+		if strings.HasSuffix(infoPath, format) {
+			infoPath = strings.TrimSuffix(infoPath, format)
+			ctx.Data["CompareDownloadFormat"] = strings.TrimPrefix(format, ".")
+			break
+		}
+	}
 	infos := strings.SplitN(infoPath, "...", 2)
 	if len(infos) != 2 {
 		log.Trace("ParseCompareInfo[%d]: not enough compared branches information %s", baseRepo.ID, infos)
@@ -156,6 +175,18 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 			headBranch = headInfos[1]
 			headUser = headRepo.Owner
 			isSameRepo = headRepo.ID == ctx.Repo.Repository.ID
+			if !isSameRepo {
+				// Only the repositories of the same fork network can be compared
+				sameNetwork, err := models.IsInSameForkNetwork(baseRepo, headRepo)
+				if err != nil {
+					ctx.ServerError("IsInSameForkNetwork", err)
+					return nil, nil, nil, nil, "", ""
+				}
+				if !sameNetwork {
+					ctx.NotFound("IsInSameForkNetwork", nil)
+					return nil, nil, nil, nil, "", ""
+				}
+			}
 		}
 	} else {
 		ctx.NotFound("CompareAndPullRequest", nil)
@@ -246,18 +277,29 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 		headRepo, has = models.HasForkedRepo(headUser.ID, baseRepo.ForkID)
 	}
 
-	// 7. Otherwise if we're not the same repo and haven't found a repo give up
+	// 7. If the headUser has any other repository of the fork network use that
+	if !has && !isSameRepo {
+		headRepo, err = models.GetForkNetworkRepoByOwner(baseRepo, headUser.ID)
+		if err != nil {
+			ctx.ServerError("GetForkNetworkRepoByOwner", err)
+			return nil, nil, nil, nil, "", ""
+		}
+		has = headRepo != nil
+	}
+
+	// 8. Otherwise if we're not the same repo and haven't found a repo give up
 	if !isSameRepo && !has {
-		ctx.Data["PageIsComparePull"] = false
+		ctx.NotFound("ParseCompareInfo", nil)
+		return nil, nil, nil, nil, "", ""
 	}
 
-	// 8. Finally open the git repo
+	// 9. Finally open the git repo
 	var headGitRepo *git.Repository
 	if isSameRepo {
 		headRepo = ctx.Repo.Repository
 		headGitRepo = ctx.Repo.GitRepo
 	} else if has {
-		headGitRepo, err = git.OpenRepository(headRepo.RepoPath())
+		headGitRepo, err = git.OpenRepositoryCtx(context.LogContext(ctx.Context), headRepo.RepoPath())
 		if err != nil {
 			ctx.ServerError("OpenRepository", err)
 			return nil, nil, nil, nil, "", ""
@@ -420,17 +462,10 @@ func PrepareCompareDiff(
 	// Get diff information.
 	ctx.Data["CommitRepoLink"] = headRepo.Link()
 
-	headCommitID := headBranch
-	if ctx.Data["HeadIsCommit"] == false {
-		if ctx.Data["HeadIsTag"] == true {
-			headCommitID, err = headGitRepo.GetTagCommitID(headBranch)
-		} else {
-			headCommitID, err = headGitRepo.GetBranchCommitID(headBranch)
-		}
-		if err != nil {
-			ctx.ServerError("GetRefCommitID", err)
-			return false
-		}
+	headCommitID, err := getCompareHeadCommitID(ctx, headGitRepo, headBranch)
+	if err != nil {
+		ctx.ServerError("GetRefCommitID", err)
+		return false
 	}
 
 	ctx.Data["AfterCommitID"] = headCommitID
@@ -447,6 +482,16 @@ func PrepareCompareDiff(
 		ctx.ServerError("GetDiffRange", err)
 		return false
 	}
+	if filter := ctx.Query("file"); len(filter) > 0 {
+		diff.FilterFiles(filter)
+		ctx.Data["FileFilter"] = filter
+	}
+	// the single files can only be loaded from the base repository
+	if headRepo.ID == repo.ID {
+		diff.DeferLargeFiles(setting.Git.MaxGitDiffLazyLines)
+		ctx.Data["DiffFileLink"] = ctx.Repo.RepoLink + "/diff_file"
+		ctx.Data["RichDiffLink"] = ctx.Repo.RepoLink + "/rich_diff"
+	}
 	ctx.Data["Diff"] = diff
 	ctx.Data["DiffNotAvailable"] = diff.NumFiles == 0
 
@@ -500,10 +545,35 @@ func PrepareCompareDiff(
 	setImageCompareContext(ctx, baseCommit, headCommit)
 	headTarget := path.Join(headUser.Name, repo.Name)
 	setPathsCompareContext(ctx, baseCommit, headCommit, headTarget)
+	diff.LoadSubmodules(headRepo, baseCommit, headCommit, ctx.User)
 
 	return false
 }
 
+// getCompareHeadCommitID returns the commit ID of the head reference of the comparison
+func getCompareHeadCommitID(ctx *context.Context, headGitRepo *git.Repository, headBranch string) (string, error) {
+	if ctx.Data["HeadIsCommit"] == true {
+		return headBranch, nil
+	}
+	if ctx.Data["HeadIsTag"] == true {
+		return headGitRepo.GetTagCommitID(headBranch)
+	}
+	return headGitRepo.GetBranchCommitID(headBranch)
+}
+
+// downloadCompareDiffOrPatch writes the raw diff or patch from the merge base to the head of the comparison
+func downloadCompareDiffOrPatch(ctx *context.Context, headGitRepo *git.Repository, compareInfo *git.CompareInfo, headBranch string, patch bool) {
+	headCommitID, err := getCompareHeadCommitID(ctx, headGitRepo, headBranch)
+	if err != nil {
+		ctx.ServerError("GetRefCommitID", err)
+		return
+	}
+	if err := headGitRepo.GetDiffOrPatch(compareInfo.MergeBase, headCommitID, ctx.Resp, patch); err != nil {
+		ctx.ServerError("GetDiffOrPatch", err)
+		return
+	}
+}
+
 func getBranchesForRepo(user *models.User, repo *models.Repository) (bool, []string, error) {
 	perm, err := models.GetUserRepoPermission(repo, user)
 	if err != nil {
@@ -525,6 +595,56 @@ func getBranchesForRepo(user *models.User, repo *models.Repository) (bool, []str
 	return true, branches, nil
 }
 
+// setPullRequestTemplate sets the description template of a new pull request to the base
+// branch: the one chosen by the user or else the one selected for the branch, and preselects
+// its labels.
+func setPullRequestTemplate(ctx *context.Context, baseBranch string, labels []*models.Label) {
+	commit := ctx.Repo.Commit
+	if commit == nil {
+		var err error
+		commit, err = ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
+		if err != nil {
+			return
+		}
+	}
+	templates, err := pulltemplate.Load(commit)
+	if err != nil {
+		log.Error("pulltemplate.Load: %v", err)
+		return
+	}
+	if len(templates) > 1 {
+		ctx.Data["PullRequestTemplates"] = templates
+	}
+
+	tmpl := pulltemplate.Find(templates, ctx.Query("template"))
+	if tmpl == nil {
+		tmpl = pulltemplate.Select(templates, baseBranch)
+	}
+	if tmpl == nil {
+		return
+	}
+	ctx.Data[pullRequestTemplateKey] = tmpl.Content
+	ctx.Data["PullRequestTemplateFile"] = tmpl
+
+	if len(tmpl.Labels) == 0 {
+		return
+	}
+	labelIDs := make([]string, 0, len(tmpl.Labels))
+	for _, label := range labels {
+		for _, name := range tmpl.Labels {
+			if strings.EqualFold(label.Name, strings.TrimSpace(name)) {
+				label.IsChecked = true
+				labelIDs = append(labelIDs, com.ToStr(label.ID))
+				break
+			}
+		}
+	}
+	if len(labelIDs) > 0 {
+		ctx.Data["HasSelectedLabel"] = true
+		ctx.Data["label_ids"] = strings.Join(labelIDs, ",")
+	}
+}
+
 // CompareDiff show different from one commit to another commit
 func CompareDiff(ctx *context.Context) {
 	headUser, headRepo, headGitRepo, compareInfo, baseBranch, headBranch := ParseCompareInfo(ctx)
@@ -533,19 +653,36 @@ func CompareDiff(ctx *context.Context) {
 	}
 	defer headGitRepo.Close()
 
+	if format, ok := ctx.Data["CompareDownloadFormat"].(string); ok {
+		downloadCompareDiffOrPatch(ctx, headGitRepo, compareInfo, headBranch, format == "patch")
+		return
+	}
+
 	nothingToCompare := PrepareCompareDiff(ctx, headUser, headRepo, headGitRepo, compareInfo, baseBranch, headBranch)
 	if ctx.Written() {
 		return
 	}
 
-	if ctx.Data["PageIsComparePull"] == true {
-		headBranches, err := headGitRepo.GetBranches()
-		if err != nil {
-			ctx.ServerError("GetBranches", err)
-			return
-		}
-		ctx.Data["HeadBranches"] = headBranches
+	headBranches, err := headGitRepo.GetBranches()
+	if err != nil {
+		ctx.ServerError("GetBranches", err)
+		return
+	}
+	ctx.Data["HeadBranches"] = headBranches
 
+	// Large fork networks are listed completely on the network page only
+	forkNetwork, hasMoreForks, err := models.GetForkNetworkLimited(ctx.Repo.Repository, ctx.User, setting.UI.NetworkMaxForkNum)
+	if err != nil {
+		ctx.ServerError("GetForkNetworkLimited", err)
+		return
+	}
+	if len(forkNetwork) > 1 || hasMoreForks {
+		ctx.Data["ForkNetwork"] = forkNetwork
+		ctx.Data["HasMoreForks"] = hasMoreForks
+	}
+
+	var labels []*models.Label
+	if ctx.Data["PageIsComparePull"] == true {
 		pr, err := models.GetUnmergedPullRequest(headRepo.ID, ctx.Repo.Repository.ID, headBranch, baseBranch)
 		if err != nil {
 			if !models.IsErrPullRequestNotExist(err) {
@@ -561,7 +698,11 @@ func CompareDiff(ctx *context.Context) {
 
 		if !nothingToCompare {
 			// Setup information for new form.
-			RetrieveRepoMetas(ctx, ctx.Repo.Repository, true)
+			labels = RetrieveRepoMetas(ctx, ctx.Repo.Repository, true)
+			if ctx.Written() {
+				return
+			}
+			prepareContributorAgreementPrompt(ctx)
 			if ctx.Written() {
 				return
 			}
@@ -577,8 +718,8 @@ func CompareDiff(ctx *context.Context) {
 	ctx.Data["RequireTribute"] = true
 	ctx.Data["RequireSimpleMDE"] = true
 	ctx.Data["PullRequestWorkInProgressPrefixes"] = setting.Repository.PullRequest.WorkInProgressPrefixes
-	setTemplateIfExists(ctx, pullRequestTemplateKey, pullRequestTemplateCandidates)
-	renderAttachmentSettings(ctx)
+	setPullRequestTemplate(ctx, baseBranch, labels)
+	renderIssueAttachmentSettings(ctx)
 
 	ctx.Data["HasIssuesOrPullsWritePermission"] = ctx.Repo.CanWrite(models.UnitTypePullRequests)
 
@@ -598,7 +739,12 @@ func ExcerptBlob(ctx *context.Context) {
 	direction := ctx.Query("direction")
 	filePath := ctx.Query("path")
 	gitRepo := ctx.Repo.GitRepo
-	chunkSize := gitdiff.BlobExceprtChunkSize
+	chunkSize := ctx.QueryInt("chunk_size")
+	if chunkSize <= 0 {
+		chunkSize = gitdiff.BlobExceprtChunkSize
+	} else if chunkSize > gitdiff.MaxBlobExcerptChunkSize {
+		chunkSize = gitdiff.MaxBlobExcerptChunkSize
+	}
 	commit, err := gitRepo.GetCommit(commitID)
 	if err != nil {
 		ctx.Error(500, "GetCommit")
@@ -660,6 +806,75 @@ func ExcerptBlob(ctx *context.Context) {
 	ctx.HTML(200, tplBlobExcerpt)
 }
 
+// DiffFile renders the diff of a single file loaded on demand
+func DiffFile(ctx *context.Context) {
+	renderDiffFile(ctx, nil)
+}
+
+// renderDiffFile renders the diff of the file given by the query between the commits,
+// along with the code comments of the pull request if there is one.
+func renderDiffFile(ctx *context.Context, issue *models.Issue) {
+	afterCommitID := ctx.Params("sha")
+	beforeCommitID := ctx.Query("before")
+	filePath := ctx.Query("path")
+	if len(filePath) == 0 || !git.SHAPattern.MatchString(afterCommitID) ||
+		(len(beforeCommitID) != 0 && !git.SHAPattern.MatchString(beforeCommitID)) {
+		ctx.NotFound("renderDiffFile", nil)
+		return
+	}
+
+	file, err := gitdiff.GetDiffFile(ctx.Repo.GitRepo.Path, beforeCommitID, afterCommitID, filePath, ctx.Query("old_path"),
+		setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters,
+		whitespaceFlags[ctx.Data["WhitespaceBehavior"].(string)])
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("GetDiffFile", err)
+		} else {
+			ctx.ServerError("GetDiffFile", err)
+		}
+		return
+	}
+
+	if issue != nil {
+		diff := &gitdiff.Diff{Files: []*gitdiff.DiffFile{file}}
+		if err = diff.LoadComments(issue, ctx.User); err != nil {
+			ctx.ServerError("LoadComments", err)
+			return
+		}
+	}
+
+	ctx.Data["File"] = file
+	ctx.Data["AfterCommitID"] = afterCommitID
+	ctx.HTML(200, tplDiffFile)
+}
+
+// RichDiff renders the format aware diff of a single file
+func RichDiff(ctx *context.Context) {
+	afterCommitID := ctx.Params("sha")
+	beforeCommitID := ctx.Query("before")
+	filePath := ctx.Query("path")
+	if len(filePath) == 0 || !git.SHAPattern.MatchString(afterCommitID) ||
+		(len(beforeCommitID) != 0 && !git.SHAPattern.MatchString(beforeCommitID)) {
+		ctx.NotFound("RichDiff", nil)
+		return
+	}
+
+	richDiff, err := gitdiff.RenderRichDiff(ctx.Repo.GitRepo, beforeCommitID, afterCommitID, filePath, ctx.Query("old_path"))
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("RenderRichDiff", err)
+			return
+		} else if gitdiff.IsErrRichDiffTooLarge(err) {
+			ctx.Data["RichDiffError"] = ctx.Tr("repo.diff.rich_diff_too_large")
+		} else {
+			log.Debug("RenderRichDiff: %v", err)
+			ctx.Data["RichDiffError"] = ctx.Tr("repo.diff.rich_diff_failed")
+		}
+	}
+	ctx.Data["RichDiff"] = richDiff
+	ctx.HTML(200, tplRichDiff)
+}
+
 func getExcerptLines(commit *git.Commit, filePath string, idxLeft int, idxRight int, chunkSize int) ([]*gitdiff.DiffLine, error) {
 	blob, err := commit.Tree.GetBlobByPath(filePath)
 	if err != nil {
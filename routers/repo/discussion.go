@@ -0,0 +1,386 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/setting"
+	discussion_service "code.gitea.io/gitea/services/discussion"
+)
+
+const (
+	tplDiscussions          base.TplName = "repo/discussion/list"
+	tplDiscussionNew        base.TplName = "repo/discussion/new"
+	tplDiscussionView       base.TplName = "repo/discussion/view"
+	tplDiscussionCategories base.TplName = "repo/discussion/categories"
+)
+
+// Discussions render the discussions of a repository
+func Discussions(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.discussions")
+
+	categories, err := models.GetDiscussionCategoriesByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetDiscussionCategoriesByRepoID", err)
+		return
+	}
+	ctx.Data["Categories"] = categories
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	categoryID := ctx.QueryInt64("category")
+	keyword := strings.TrimSpace(ctx.Query("q"))
+
+	discussions, count, err := models.FindDiscussions(models.FindDiscussionsOptions{
+		ListOptions: models.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.IssuePagingNum,
+		},
+		RepoID:     ctx.Repo.Repository.ID,
+		CategoryID: categoryID,
+		Keyword:    keyword,
+	})
+	if err != nil {
+		ctx.ServerError("FindDiscussions", err)
+		return
+	}
+	for _, d := range discussions {
+		d.Repo = ctx.Repo.Repository
+		if err = d.LoadAttributes(); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return
+		}
+	}
+	ctx.Data["Discussions"] = discussions
+	ctx.Data["CategoryID"] = categoryID
+	ctx.Data["Keyword"] = keyword
+	ctx.Data["CanWriteDiscussions"] = ctx.Repo.CanWrite(models.UnitTypeDiscussions)
+
+	pager := context.NewPagination(int(count), setting.UI.IssuePagingNum, page, 5)
+	pager.SetDefaultParams(ctx)
+	pager.AddParam(ctx, "category", "CategoryID")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(200, tplDiscussions)
+}
+
+// NewDiscussion render creating discussion page
+func NewDiscussion(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.discussions.new")
+
+	categories, err := models.GetDiscussionCategoriesByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetDiscussionCategoriesByRepoID", err)
+		return
+	}
+	ctx.Data["Categories"] = categories
+	ctx.Data["category_id"] = ctx.QueryInt64("category")
+
+	ctx.HTML(200, tplDiscussionNew)
+}
+
+// NewDiscussionPost response for starting a discussion
+func NewDiscussionPost(ctx *context.Context, form auth.CreateDiscussionForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.discussions.new")
+
+	categories, err := models.GetDiscussionCategoriesByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetDiscussionCategoriesByRepoID", err)
+		return
+	}
+	ctx.Data["Categories"] = categories
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplDiscussionNew)
+		return
+	}
+
+	d := &models.Discussion{
+		RepoID:     ctx.Repo.Repository.ID,
+		Repo:       ctx.Repo.Repository,
+		CategoryID: form.CategoryID,
+		PosterID:   ctx.User.ID,
+		Poster:     ctx.User,
+		Title:      form.Title,
+		Content:    form.Content,
+	}
+	if err := discussion_service.NewDiscussion(d); err != nil {
+		if models.IsErrDiscussionCategoryNotExist(err) {
+			ctx.Data["Err_CategoryID"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.discussions.category_not_exist"), tplDiscussionNew, &form)
+			return
+		}
+		ctx.ServerError("NewDiscussion", err)
+		return
+	}
+
+	log.Trace("Discussion created: %d/%d", ctx.Repo.Repository.ID, d.ID)
+	ctx.Redirect(d.Link())
+}
+
+// getDiscussion returns the discussion of the current repository from the
+// index in the URL, with its attributes loaded.
+func getDiscussion(ctx *context.Context) *models.Discussion {
+	d, err := models.GetDiscussionByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetDiscussionByIndex", models.IsErrDiscussionNotExist, err)
+		return nil
+	}
+	d.Repo = ctx.Repo.Repository
+	if err = d.LoadAttributes(); err != nil {
+		ctx.ServerError("LoadAttributes", err)
+		return nil
+	}
+	return d
+}
+
+// canModerateDiscussion returns true if the signed in user may change or
+// delete a post of the given poster.
+func canModerateDiscussion(ctx *context.Context, posterID int64) bool {
+	return ctx.IsSigned && (ctx.User.ID == posterID || ctx.Repo.CanWrite(models.UnitTypeDiscussions))
+}
+
+// ViewDiscussion render a discussion with its replies
+func ViewDiscussion(ctx *context.Context) {
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	metas := ctx.Repo.Repository.ComposeMetas()
+	d.RenderedContent = string(markdown.Render([]byte(d.Content), ctx.Repo.RepoLink, metas))
+
+	replies, err := models.GetDiscussionReplies(d.ID)
+	if err != nil {
+		ctx.ServerError("GetDiscussionReplies", err)
+		return
+	}
+	for _, r := range replies {
+		r.RenderedContent = string(markdown.Render([]byte(r.Content), ctx.Repo.RepoLink, metas))
+		if d.Answer != nil && d.Answer.ID == r.ID {
+			d.Answer.RenderedContent = r.RenderedContent
+		}
+	}
+
+	upvoted := make(map[int64]bool)
+	if ctx.IsSigned {
+		if upvoted, err = models.GetDiscussionUpvotes(ctx.User.ID, d.ID); err != nil {
+			ctx.ServerError("GetDiscussionUpvotes", err)
+			return
+		}
+		if err = models.SetDiscussionNotificationStatusRead(ctx.User.ID, d.ID); err != nil {
+			ctx.ServerError("SetDiscussionNotificationStatusRead", err)
+			return
+		}
+	}
+
+	ctx.Data["Title"] = fmt.Sprintf("%s - #%d", d.Title, d.Index)
+	ctx.Data["Discussion"] = d
+	ctx.Data["Replies"] = models.BuildDiscussionReplyTree(replies)
+	ctx.Data["Upvoted"] = upvoted
+	ctx.Data["IsUpvoted"] = upvoted[0]
+	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login?redirect_to=" + ctx.Data["Link"].(string)
+	ctx.Data["CanWriteDiscussions"] = ctx.Repo.CanWrite(models.UnitTypeDiscussions)
+	ctx.Data["CanModerateDiscussion"] = canModerateDiscussion(ctx, d.PosterID)
+	ctx.Data["CanAcceptAnswer"] = d.IsAnswerable() && canModerateDiscussion(ctx, d.PosterID)
+
+	ctx.HTML(200, tplDiscussionView)
+}
+
+// DiscussionReplyPost response for replying to a discussion
+func DiscussionReplyPost(ctx *context.Context, form auth.CreateDiscussionReplyForm) {
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(d.Link())
+		return
+	}
+
+	reply, err := discussion_service.NewReply(ctx.User, d, form.ParentID, form.Content)
+	if err != nil {
+		if models.IsErrDiscussionReplyNotExist(err) {
+			ctx.NotFound("NewReply", err)
+			return
+		}
+		ctx.ServerError("NewReply", err)
+		return
+	}
+
+	ctx.Redirect(fmt.Sprintf("%s#%s", d.Link(), reply.HashTag()))
+}
+
+// DeleteDiscussionReply response for deleting a reply of a discussion
+func DeleteDiscussionReply(ctx *context.Context) {
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	reply, err := models.GetDiscussionReplyByID(d.ID, ctx.QueryInt64("id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetDiscussionReplyByID", models.IsErrDiscussionReplyNotExist, err)
+		return
+	}
+	if !canModerateDiscussion(ctx, reply.PosterID) {
+		ctx.Error(403)
+		return
+	}
+
+	if err = models.DeleteDiscussionReply(d, reply); err != nil {
+		ctx.Flash.Error("DeleteDiscussionReply: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.discussions.reply_deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": d.Link(),
+	})
+}
+
+// DeleteDiscussion response for deleting a discussion
+func DeleteDiscussion(ctx *context.Context) {
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !canModerateDiscussion(ctx, d.PosterID) {
+		ctx.Error(403)
+		return
+	}
+
+	if err := models.DeleteDiscussion(d); err != nil {
+		ctx.Flash.Error("DeleteDiscussion: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.discussions.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/discussions",
+	})
+}
+
+// DiscussionAnswer response for accepting a reply as the answer of a discussion
+func DiscussionAnswer(ctx *context.Context) {
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !canModerateDiscussion(ctx, d.PosterID) {
+		ctx.Error(403)
+		return
+	}
+
+	if err := d.SetAnswer(ctx.QueryInt64("reply_id")); err != nil {
+		if models.IsErrDiscussionNotAnswerable(err) || models.IsErrDiscussionReplyNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.discussions.answer_invalid"))
+			ctx.Redirect(d.Link())
+			return
+		}
+		ctx.ServerError("SetAnswer", err)
+		return
+	}
+
+	ctx.Redirect(d.Link())
+}
+
+// DiscussionUpvote response for upvoting a discussion or one of its replies,
+// or for withdrawing that upvote
+func DiscussionUpvote(ctx *context.Context) {
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	replyID := ctx.QueryInt64("reply_id")
+	upvote := ctx.Query("action") != "remove"
+	if err := models.SetDiscussionUpvote(ctx.User.ID, d, replyID, upvote); err != nil {
+		if models.IsErrDiscussionReplyNotExist(err) {
+			ctx.NotFound("SetDiscussionUpvote", err)
+			return
+		}
+		ctx.ServerError("SetDiscussionUpvote", err)
+		return
+	}
+
+	if replyID > 0 {
+		ctx.Redirect(fmt.Sprintf("%s#%s", d.Link(), (&models.DiscussionReply{ID: replyID}).HashTag()))
+		return
+	}
+	ctx.Redirect(d.Link())
+}
+
+// DiscussionCategories render the discussion categories of a repository
+func DiscussionCategories(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.discussions.categories")
+
+	categories, err := models.GetDiscussionCategoriesByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetDiscussionCategoriesByRepoID", err)
+		return
+	}
+	ctx.Data["Categories"] = categories
+
+	ctx.HTML(200, tplDiscussionCategories)
+}
+
+// NewDiscussionCategoryPost response for creating a discussion category
+func NewDiscussionCategoryPost(ctx *context.Context, form auth.CreateDiscussionCategoryForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.discussions.categories")
+
+	categories, err := models.GetDiscussionCategoriesByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetDiscussionCategoriesByRepoID", err)
+		return
+	}
+	ctx.Data["Categories"] = categories
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplDiscussionCategories)
+		return
+	}
+
+	if err = models.NewDiscussionCategory(&models.DiscussionCategory{
+		RepoID:       ctx.Repo.Repository.ID,
+		Name:         form.Name,
+		Description:  form.Description,
+		IsAnswerable: form.IsAnswerable,
+	}); err != nil {
+		ctx.ServerError("NewDiscussionCategory", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.discussions.category.add_success", form.Name))
+	ctx.Redirect(ctx.Repo.RepoLink + "/discussions/categories")
+}
+
+// DeleteDiscussionCategory response for deleting a discussion category
+func DeleteDiscussionCategory(ctx *context.Context) {
+	if err := models.DeleteDiscussionCategory(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
+		if models.IsErrDiscussionCategoryNotEmpty(err) {
+			ctx.Flash.Error(ctx.Tr("repo.discussions.category.not_empty"))
+		} else {
+			ctx.Flash.Error("DeleteDiscussionCategory: " + err.Error())
+		}
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.discussions.category.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/discussions/categories",
+	})
+}
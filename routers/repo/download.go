@@ -8,9 +8,11 @@ package repo
 import (
 	"fmt"
 	"io"
+	"net/http"
 	"path"
 	"strings"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/charset"
 	"code.gitea.io/gitea/modules/context"
@@ -27,7 +29,14 @@ func ServeData(ctx *context.Context, name string, reader io.Reader) error {
 		buf = buf[:n]
 	}
 
-	ctx.Resp.Header().Set("Cache-Control", "public,max-age=86400")
+	if headers, ok := ctx.Data["RawHeaders"].(*models.RepoRawHeaders); ok {
+		ctx.Resp.Header().Set("Cache-Control", headers.CacheControl(ctx.Repo.Repository.IsPrivate))
+		if tag := headers.RobotsTag(); tag != "" {
+			ctx.Resp.Header().Set("X-Robots-Tag", tag)
+		}
+	} else {
+		ctx.Resp.Header().Set("Cache-Control", "public,max-age=86400")
+	}
 	name = path.Base(name)
 
 	// Google Chrome dislike commas in filenames, so let's change it to a space
@@ -54,6 +63,35 @@ func ServeData(ctx *context.Context, name string, reader io.Reader) error {
 	return err
 }
 
+// RawHeaders sets the CORS headers of the repository for the raw and media endpoints, answers
+// the CORS preflight requests and passes the caching and robots headers on to ServeData.
+func RawHeaders(ctx *context.Context) {
+	headers, err := models.GetEffectiveRepoRawHeaders(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetEffectiveRepoRawHeaders", err)
+		return
+	}
+
+	h := ctx.Resp.Header()
+	if len(headers.GetAllowedOrigins()) > 0 {
+		h.Add("Vary", "Origin")
+		if origin := headers.AllowOrigin(ctx.Req.Header.Get("Origin")); origin != "" {
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Expose-Headers", "Content-Disposition, Content-Length")
+		}
+	}
+	if ctx.Req.Method == "OPTIONS" {
+		if h.Get("Access-Control-Allow-Origin") != "" {
+			h.Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+			h.Set("Access-Control-Allow-Headers", "Range")
+			h.Set("Access-Control-Max-Age", "86400")
+		}
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+	ctx.Data["RawHeaders"] = headers
+}
+
 // ServeBlob download a git.Blob
 func ServeBlob(ctx *context.Context, blob *git.Blob) error {
 	dataRc, err := blob.DataAsync()
@@ -0,0 +1,271 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/charset"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/highlight"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+)
+
+const (
+	tplEmbedSnippet        base.TplName = "repo/embed/snippet"
+	tplEmbedSnippetContent base.TplName = "repo/embed/snippet_content"
+
+	// maxSnippetLines is the maximum number of lines shown by an embedded snippet
+	maxSnippetLines = 200
+)
+
+var (
+	lineRangePattern = regexp.MustCompile(`^L?(\d+)(?:-L?(\d+))?$`)
+	sha1Pattern      = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// parseLineRange parses line ranges like "L10-L20", "10-20" or "L10". The
+// returned range is ordered and limited to maxSnippetLines lines.
+func parseLineRange(s string) (start, end int, ok bool) {
+	m := lineRangePattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, false
+	}
+	start, _ = strconv.Atoi(m[1])
+	end = start
+	if m[2] != "" {
+		end, _ = strconv.Atoi(m[2])
+	}
+	if start > end {
+		start, end = end, start
+	}
+	if start < 1 {
+		return 0, 0, false
+	}
+	if end-start >= maxSnippetLines {
+		end = start + maxSnippetLines - 1
+	}
+	return start, end, true
+}
+
+// EmbedSnippet renders a range of lines of a file so that it can be embedded
+// into issues, wikis or other sites. Files are addressed by commit and path,
+// or by blob ID which is also used as a fallback if the commit is gone.
+func EmbedSnippet(ctx *context.Context) {
+	var (
+		blob     *git.Blob
+		commitID string
+		treePath string
+		link     string
+		err      error
+	)
+
+	if blobID := ctx.Params(":sha"); blobID != "" {
+		treePath = ctx.Query("name")
+	} else {
+		parts := strings.SplitN(ctx.Params("*"), "/", 2)
+		if len(parts) == 2 && sha1Pattern.MatchString(parts[0]) {
+			commitID, treePath = parts[0], parts[1]
+			blob, err = getBlobAtCommit(ctx.Repo.GitRepo, commitID, treePath)
+			if err != nil && !git.IsErrNotExist(err) {
+				ctx.ServerError("getBlobAtCommit", err)
+				return
+			}
+		}
+	}
+
+	if blob == nil {
+		blobID := ctx.Params(":sha")
+		if blobID == "" {
+			blobID = ctx.Query("blob")
+		}
+		if !sha1Pattern.MatchString(blobID) {
+			ctx.NotFound("EmbedSnippet", nil)
+			return
+		}
+		if blob, err = ctx.Repo.GitRepo.GetBlob(blobID); err != nil {
+			if git.IsErrNotExist(err) {
+				ctx.NotFound("GetBlob", nil)
+			} else {
+				ctx.ServerError("GetBlob", err)
+			}
+			return
+		}
+		commitID = ""
+		link = ctx.Repo.RepoLink + "/raw/blob/" + blobID
+	} else {
+		link = ctx.Repo.RepoLink + "/src/commit/" + commitID + "/" + util.PathEscapeSegments(treePath)
+	}
+
+	if blob.Size() > setting.UI.MaxDisplayFileSize {
+		ctx.NotFound("EmbedSnippet", nil)
+		return
+	}
+	dataRc, err := blob.DataAsync()
+	if err != nil {
+		ctx.ServerError("DataAsync", err)
+		return
+	}
+	defer dataRc.Close()
+	buf, err := ioutil.ReadAll(dataRc)
+	if err != nil {
+		ctx.ServerError("ReadAll", err)
+		return
+	}
+	if !base.IsTextFile(buf) {
+		ctx.NotFound("EmbedSnippet", nil)
+		return
+	}
+	buf = charset.ToUTF8WithFallback(buf)
+
+	numLines := linesBytesCount(buf)
+	start, end, ok := parseLineRange(ctx.Query("lines"))
+	if !ok {
+		start, end, _ = parseLineRange(fmt.Sprintf("1-%d", numLines))
+	}
+	if start < 1 || start > numLines {
+		ctx.NotFound("EmbedSnippet", nil)
+		return
+	}
+	if end > numLines {
+		end = numLines
+	}
+
+	fileLines := highlight.File(numLines, path.Base(treePath), buf)
+	lines := make(map[int]string, end-start+1)
+	for i := start; i <= end; i++ {
+		lines[i] = fileLines[i]
+	}
+	if start == end {
+		link += fmt.Sprintf("#L%d", start)
+	} else {
+		link += fmt.Sprintf("#L%d-L%d", start, end)
+	}
+
+	ctx.Data["SnippetPath"] = treePath
+	ctx.Data["SnippetCommitID"] = commitID
+	ctx.Data["SnippetLink"] = link
+	ctx.Data["SnippetStart"] = start
+	ctx.Data["SnippetEnd"] = end
+	ctx.Data["SnippetLines"] = lines
+
+	if ctx.QueryBool("inline") {
+		ctx.HTML(http.StatusOK, tplEmbedSnippetContent)
+		return
+	}
+	// Only public code may be framed by other sites
+	if !ctx.Repo.Repository.IsPrivate && ctx.Repo.Owner.Visibility == structs.VisibleTypePublic {
+		ctx.Resp.Header().Del("X-Frame-Options")
+	}
+	ctx.HTML(http.StatusOK, tplEmbedSnippet)
+}
+
+func getBlobAtCommit(gitRepo *git.Repository, commitID, treePath string) (*git.Blob, error) {
+	commit, err := gitRepo.GetCommit(commitID)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.IsRegular() && !entry.IsExecutable() {
+		return nil, git.ErrNotExist{ID: commitID, RelPath: treePath}
+	}
+	return entry.Blob(), nil
+}
+
+type oEmbedResponse struct {
+	Type         string `json:"type"`
+	Version      string `json:"version"`
+	Title        string `json:"title"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+// OEmbed implements the oEmbed endpoint for permalinks to files, returning an
+// iframe of the embedded snippet.
+func OEmbed(ctx *context.Context) {
+	if format := ctx.Query("format"); format != "" && format != "json" {
+		ctx.Error(http.StatusNotImplemented)
+		return
+	}
+
+	u, err := url.Parse(ctx.Query("url"))
+	if err != nil || !strings.HasPrefix(u.Scheme+"://"+u.Host+u.Path, setting.AppURL) {
+		ctx.NotFound("OEmbed", nil)
+		return
+	}
+	// {owner}/{repo}/src/commit/{sha}/{path}
+	parts := strings.SplitN(strings.TrimPrefix(u.Path, setting.AppSubURL+"/"), "/", 6)
+	if len(parts) != 6 || parts[2] != "src" || parts[3] != "commit" || !sha1Pattern.MatchString(parts[4]) {
+		ctx.NotFound("OEmbed", nil)
+		return
+	}
+
+	repo, err := models.GetRepositoryByOwnerAndName(parts[0], parts[1])
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound("GetRepositoryByOwnerAndName", nil)
+		} else {
+			ctx.ServerError("GetRepositoryByOwnerAndName", err)
+		}
+		return
+	}
+	perm, err := models.GetUserRepoPermission(repo, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetUserRepoPermission", err)
+		return
+	}
+	if !perm.CanRead(models.UnitTypeCode) {
+		ctx.NotFound("OEmbed", nil)
+		return
+	}
+
+	commitID, treePath := parts[4], parts[5]
+	src := repo.HTMLURL() + "/embed/commit/" + commitID + "/" + util.PathEscapeSegments(treePath)
+	numLines := maxSnippetLines
+	if start, end, ok := parseLineRange(u.Fragment); ok {
+		src += fmt.Sprintf("?lines=%d-%d", start, end)
+		numLines = end - start + 1
+	}
+
+	width := 800
+	if maxWidth := ctx.QueryInt("maxwidth"); maxWidth > 0 && maxWidth < width {
+		width = maxWidth
+	}
+	// header plus one row per line
+	height := 60 + 20*numLines
+	if maxHeight := ctx.QueryInt("maxheight"); maxHeight > 0 && maxHeight < height {
+		height = maxHeight
+	}
+
+	ctx.JSON(http.StatusOK, &oEmbedResponse{
+		Type:         "rich",
+		Version:      "1.0",
+		Title:        repo.FullName() + "/" + treePath,
+		ProviderName: setting.AppName,
+		ProviderURL:  setting.AppURL,
+		HTML:         fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" frameborder="0"></iframe>`, html.EscapeString(src), width, height),
+		Width:        width,
+		Height:       height,
+	})
+}
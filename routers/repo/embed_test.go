@@ -0,0 +1,77 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/ini.v1"
+)
+
+func TestParseLineRange(t *testing.T) {
+	for s, expected := range map[string][2]int{
+		"L10-L20": {10, 20},
+		"10-20":   {10, 20},
+		"L20-L10": {10, 20},
+		"L7":      {7, 7},
+		"1-1000":  {1, maxSnippetLines},
+	} {
+		start, end, ok := parseLineRange(s)
+		assert.True(t, ok, s)
+		assert.Equal(t, expected, [2]int{start, end}, s)
+	}
+
+	for _, s := range []string{"", "L", "L0", "0-5", "L1-", "a-b"} {
+		_, _, ok := parseLineRange(s)
+		assert.False(t, ok, s)
+	}
+}
+
+func TestEmbedSnippet(t *testing.T) {
+	models.PrepareTestEnv(t)
+	setting.Cfg = ini.Empty()
+
+	ctx := test.MockContext(t, "user2/repo1/embed/commit/65f1bf27bc3bf70f64657658635e66094edbcb4d/README.md")
+	test.LoadRepo(t, ctx, 1)
+	test.LoadGitRepo(t, ctx)
+	defer ctx.Repo.GitRepo.Close()
+	ctx.SetParams("*", "65f1bf27bc3bf70f64657658635e66094edbcb4d/README.md")
+	ctx.Req.Form.Set("lines", "L1-L2")
+	EmbedSnippet(ctx)
+	assert.EqualValues(t, http.StatusOK, ctx.Resp.Status())
+	assert.Len(t, ctx.Data["SnippetLines"], 2)
+	assert.EqualValues(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", ctx.Data["SnippetCommitID"])
+	assert.EqualValues(t, "/user2/repo1/src/commit/65f1bf27bc3bf70f64657658635e66094edbcb4d/README.md#L1-L2", ctx.Data["SnippetLink"])
+
+	// falls back to the blob when the commit does not exist
+	ctx = test.MockContext(t, "user2/repo1/embed/commit/0000000000000000000000000000000000000000/README.md")
+	test.LoadRepo(t, ctx, 1)
+	test.LoadGitRepo(t, ctx)
+	defer ctx.Repo.GitRepo.Close()
+	ctx.SetParams("*", "0000000000000000000000000000000000000000/README.md")
+	ctx.Req.Form.Set("lines", "L3")
+	ctx.Req.Form.Set("blob", "4b4851ad51df6a7d9f25c979345979eaeb5b349f")
+	EmbedSnippet(ctx)
+	assert.EqualValues(t, http.StatusOK, ctx.Resp.Status())
+	assert.Len(t, ctx.Data["SnippetLines"], 1)
+	assert.EqualValues(t, "", ctx.Data["SnippetCommitID"])
+	assert.EqualValues(t, "/user2/repo1/raw/blob/4b4851ad51df6a7d9f25c979345979eaeb5b349f#L3", ctx.Data["SnippetLink"])
+
+	// lines beyond the end of the file
+	ctx = test.MockContext(t, "user2/repo1/embed/commit/65f1bf27bc3bf70f64657658635e66094edbcb4d/README.md")
+	test.LoadRepo(t, ctx, 1)
+	test.LoadGitRepo(t, ctx)
+	defer ctx.Repo.GitRepo.Close()
+	ctx.SetParams("*", "65f1bf27bc3bf70f64657658635e66094edbcb4d/README.md")
+	ctx.Req.Form.Set("lines", "L10-L20")
+	EmbedSnippet(ctx)
+	assert.EqualValues(t, http.StatusNotFound, ctx.Resp.Status())
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+const (
+	tplEpics    base.TplName = "repo/issue/epics"
+	tplEpicView base.TplName = "repo/issue/epic_view"
+)
+
+// epicRepoIssues holds the issues of an epic living in one repository.
+type epicRepoIssues struct {
+	Repo   *models.Repository
+	Issues models.IssueList
+}
+
+// Epics render the epics of a repository, including those of its owner
+func Epics(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.epics")
+	ctx.Data["PageIsIssueList"] = true
+	ctx.Data["PageIsEpics"] = true
+
+	isShowClosed := ctx.Query("state") == "closed"
+	state := api.StateOpen
+	if isShowClosed {
+		state = api.StateClosed
+	}
+
+	epics, err := models.GetEpicsForRepo(ctx.Repo.Repository, state)
+	if err != nil {
+		ctx.ServerError("GetEpicsForRepo", err)
+		return
+	}
+	for _, epic := range epics {
+		epic.RenderedContent = string(markdown.Render([]byte(epic.Content), ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas()))
+	}
+	ctx.Data["Epics"] = epics
+	ctx.Data["IsShowClosed"] = isShowClosed
+
+	ctx.HTML(200, tplEpics)
+}
+
+// ViewEpic render an epic together with its issues grouped by repository
+func ViewEpic(ctx *context.Context) {
+	epic, err := models.GetEpicForRepo(ctx.Repo.Repository, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrEpicNotExist(err) {
+			ctx.NotFound("GetEpicForRepo", err)
+		} else {
+			ctx.ServerError("GetEpicForRepo", err)
+		}
+		return
+	}
+	epic.RenderedContent = string(markdown.Render([]byte(epic.Content), ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas()))
+
+	issues, err := models.GetEpicIssues(epic.ID, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetEpicIssues", err)
+		return
+	}
+
+	tree := make([]*epicRepoIssues, 0, 5)
+	for _, issue := range issues {
+		if len(tree) == 0 || tree[len(tree)-1].Repo.ID != issue.RepoID {
+			tree = append(tree, &epicRepoIssues{Repo: issue.Repo})
+		}
+		tree[len(tree)-1].Issues = append(tree[len(tree)-1].Issues, issue)
+	}
+
+	ctx.Data["Title"] = epic.Name
+	ctx.Data["PageIsIssueList"] = true
+	ctx.Data["PageIsEpics"] = true
+	ctx.Data["Epic"] = epic
+	ctx.Data["EpicRepos"] = tree
+
+	ctx.HTML(200, tplEpicView)
+}
+
+// UpdateIssueEpic change issue's epic
+func UpdateIssueEpic(ctx *context.Context) {
+	issues := getActionIssues(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	epicID := ctx.QueryInt64("id")
+	for _, issue := range issues {
+		if issue.EpicID == epicID {
+			continue
+		}
+		if err := issue.ChangeEpic(epicID); err != nil {
+			if models.IsErrEpicNotExist(err) || models.IsErrEpicIssueOutOfScope(err) {
+				ctx.Flash.Error(ctx.Tr("repo.issues.epic_out_of_scope"))
+				continue
+			}
+			ctx.ServerError("ChangeEpic", err)
+			return
+		}
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"ok": true,
+	})
+}
@@ -0,0 +1,234 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/gorilla/feeds"
+)
+
+const (
+	// feedItemsLimit is the number of entries a repository feed contains.
+	feedItemsLimit = 20
+	// feedCacheMaxAge is how long clients may cache a repository feed.
+	feedCacheMaxAge = 5 * time.Minute
+)
+
+// ReleasesFeedRSS renders the releases of a repository as RSS feed
+func ReleasesFeedRSS(ctx *context.Context) {
+	releasesOrTagsFeed(ctx, true, "rss")
+}
+
+// ReleasesFeedAtom renders the releases of a repository as Atom feed
+func ReleasesFeedAtom(ctx *context.Context) {
+	releasesOrTagsFeed(ctx, true, "atom")
+}
+
+// TagsFeedRSS renders the tags of a repository as RSS feed
+func TagsFeedRSS(ctx *context.Context) {
+	releasesOrTagsFeed(ctx, false, "rss")
+}
+
+// TagsFeedAtom renders the tags of a repository as Atom feed
+func TagsFeedAtom(ctx *context.Context) {
+	releasesOrTagsFeed(ctx, false, "atom")
+}
+
+// IssuesFeedRSS renders the recently updated issues of a repository as RSS feed
+func IssuesFeedRSS(ctx *context.Context) {
+	issuesFeed(ctx, false, "rss")
+}
+
+// IssuesFeedAtom renders the recently updated issues of a repository as Atom feed
+func IssuesFeedAtom(ctx *context.Context) {
+	issuesFeed(ctx, false, "atom")
+}
+
+// PullsFeedRSS renders the recently updated pull requests of a repository as RSS feed
+func PullsFeedRSS(ctx *context.Context) {
+	issuesFeed(ctx, true, "rss")
+}
+
+// PullsFeedAtom renders the recently updated pull requests of a repository as Atom feed
+func PullsFeedAtom(ctx *context.Context) {
+	issuesFeed(ctx, true, "atom")
+}
+
+func releasesOrTagsFeed(ctx *context.Context, isReleasesOnly bool, formatType string) {
+	repo := ctx.Repo.Repository
+	releases, err := models.GetReleasesByRepoID(repo.ID, models.FindReleasesOptions{
+		ListOptions: models.ListOptions{
+			Page:     1,
+			PageSize: feedItemsLimit,
+		},
+		IncludeTags: !isReleasesOnly,
+	})
+	if err != nil {
+		ctx.ServerError("GetReleasesByRepoID", err)
+		return
+	}
+
+	feed := &feeds.Feed{
+		Title: ctx.Tr("repo.feed.tags_title", repo.FullName()),
+		Link:  &feeds.Link{Href: repo.HTMLURL() + "/releases"},
+	}
+	if isReleasesOnly {
+		feed.Title = ctx.Tr("repo.feed.releases_title", repo.FullName())
+	}
+
+	for _, rel := range releases {
+		rel.Repo = repo
+		if rel.Publisher, err = models.GetUserByID(rel.PublisherID); err != nil {
+			if !models.IsErrUserNotExist(err) {
+				ctx.ServerError("GetUserByID", err)
+				return
+			}
+			rel.Publisher = models.NewGhostUser()
+		}
+
+		title := rel.Title
+		if rel.IsTag || title == "" {
+			title = rel.TagName
+		}
+		created := rel.CreatedUnix.AsTime()
+		feed.Add(&feeds.Item{
+			Title:       title,
+			Link:        &feeds.Link{Href: rel.HTMLURL()},
+			Id:          rel.HTMLURL(),
+			Author:      feedAuthor(rel.Publisher),
+			Description: markdown.RenderString(rel.Note, repo.HTMLURL(), repo.ComposeMetas()),
+			Created:     created,
+			Updated:     created,
+		})
+		if created.After(feed.Updated) {
+			feed.Updated = created
+		}
+	}
+
+	writeFeed(ctx, feed, formatType)
+}
+
+func issuesFeed(ctx *context.Context, isPull bool, formatType string) {
+	repo := ctx.Repo.Repository
+
+	opts := &models.IssuesOptions{
+		ListOptions: models.ListOptions{
+			Page:     1,
+			PageSize: feedItemsLimit,
+		},
+		RepoIDs:  []int64{repo.ID},
+		IsPull:   util.OptionalBoolOf(isPull),
+		SortType: "recentupdate",
+	}
+	if labels := strings.TrimSpace(ctx.Query("labels")); len(labels) > 0 {
+		opts.IncludedLabelNames = strings.Split(labels, ",")
+	}
+	switch ctx.Query("state") {
+	case "open":
+		opts.IsClosed = util.OptionalBoolFalse
+	case "closed":
+		opts.IsClosed = util.OptionalBoolTrue
+	}
+
+	issues, err := models.Issues(opts)
+	if err != nil {
+		ctx.ServerError("Issues", err)
+		return
+	}
+
+	feed := &feeds.Feed{
+		Title: ctx.Tr("repo.feed.issues_title", repo.FullName()),
+		Link:  &feeds.Link{Href: repo.HTMLURL() + "/issues"},
+	}
+	if isPull {
+		feed.Title = ctx.Tr("repo.feed.pulls_title", repo.FullName())
+		feed.Link.Href = repo.HTMLURL() + "/pulls"
+	}
+
+	for _, issue := range issues {
+		issue.Repo = repo
+		if err := issue.LoadPoster(); err != nil {
+			ctx.ServerError("LoadPoster", err)
+			return
+		}
+
+		updated := issue.UpdatedUnix.AsTime()
+		feed.Add(&feeds.Item{
+			Title:       fmt.Sprintf("#%d %s", issue.Index, issue.Title),
+			Link:        &feeds.Link{Href: issue.HTMLURL()},
+			Id:          issue.HTMLURL(),
+			Author:      feedAuthor(issue.Poster),
+			Description: markdown.RenderString(issue.Content, repo.HTMLURL(), repo.ComposeMetas()),
+			Created:     issue.CreatedUnix.AsTime(),
+			Updated:     updated,
+		})
+		if updated.After(feed.Updated) {
+			feed.Updated = updated
+		}
+	}
+
+	writeFeed(ctx, feed, formatType)
+}
+
+func feedAuthor(u *models.User) *feeds.Author {
+	if u == nil {
+		return nil
+	}
+	author := &feeds.Author{Name: u.GetDisplayName()}
+	if !u.KeepEmailPrivate {
+		author.Email = u.Email
+	}
+	return author
+}
+
+// writeFeed writes the feed in the requested format. Clients revalidate the
+// feed with If-Modified-Since against the last update of its items.
+func writeFeed(ctx *context.Context, feed *feeds.Feed, formatType string) {
+	if feed.Updated.IsZero() {
+		feed.Updated = ctx.Repo.Repository.UpdatedUnix.AsTime()
+	}
+	feed.Created = feed.Updated
+
+	cacheControl := "public"
+	if ctx.Repo.Repository.IsPrivate {
+		cacheControl = "private"
+	}
+	ctx.Resp.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", cacheControl, int(feedCacheMaxAge.Seconds())))
+	ctx.Resp.Header().Set("Last-Modified", feed.Updated.UTC().Format(http.TimeFormat))
+	if since, err := http.ParseTime(ctx.Req.Header.Get("If-Modified-Since")); err == nil && !feed.Updated.Truncate(time.Second).After(since) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	var (
+		content     string
+		err         error
+		contentType string
+	)
+	if formatType == "atom" {
+		contentType = "application/atom+xml;charset=utf-8"
+		content, err = feed.ToAtom()
+	} else {
+		contentType = "application/rss+xml;charset=utf-8"
+		content, err = feed.ToRss()
+	}
+	if err != nil {
+		ctx.ServerError("Render feed", err)
+		return
+	}
+
+	ctx.Resp.Header().Set("Content-Type", contentType)
+	ctx.Status(http.StatusOK)
+	_, _ = ctx.Resp.Write([]byte(content))
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/test"
+
+	"gitea.com/macaron/macaron"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockFeedContext(t *testing.T, path string) (*context.Context, *httptest.ResponseRecorder) {
+	ctx := test.MockContext(t, path)
+	recorder := httptest.NewRecorder()
+	ctx.Resp = macaron.NewResponseWriter("GET", recorder)
+	ctx.Render.SetResponseWriter(ctx.Resp)
+	ctx.Req.Header = http.Header{}
+	test.LoadRepo(t, ctx, 1)
+	return ctx, recorder
+}
+
+func TestIssuesFeed(t *testing.T) {
+	models.PrepareTestEnv(t)
+
+	ctx, recorder := mockFeedContext(t, "user2/repo1/issues.rss")
+	IssuesFeedRSS(ctx)
+	assert.EqualValues(t, http.StatusOK, ctx.Resp.Status())
+	assert.Equal(t, "application/rss+xml;charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "#1 issue1")
+	assert.Contains(t, recorder.Body.String(), "#4 issue5")
+	assert.NotContains(t, recorder.Body.String(), "pull5")
+	assert.Equal(t, time.Unix(978307200, 0).UTC().Format(http.TimeFormat), recorder.Header().Get("Last-Modified"))
+
+	ctx, recorder = mockFeedContext(t, "user2/repo1/issues.atom?labels=label2")
+	ctx.Req.Form.Set("labels", "label2")
+	IssuesFeedAtom(ctx)
+	assert.EqualValues(t, http.StatusOK, ctx.Resp.Status())
+	assert.Equal(t, "application/atom+xml;charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "#4 issue5")
+	assert.NotContains(t, recorder.Body.String(), "#1 issue1")
+
+	ctx, recorder = mockFeedContext(t, "user2/repo1/pulls.rss")
+	PullsFeedRSS(ctx)
+	assert.EqualValues(t, http.StatusOK, ctx.Resp.Status())
+	assert.Contains(t, recorder.Body.String(), "#5 pull5")
+	assert.NotContains(t, recorder.Body.String(), "#1 issue1")
+}
+
+func TestFeedNotModified(t *testing.T) {
+	models.PrepareTestEnv(t)
+
+	ctx, recorder := mockFeedContext(t, "user2/repo1/releases.rss")
+	ctx.Req.Header.Set("If-Modified-Since", time.Unix(946684800, 0).UTC().Format(http.TimeFormat))
+	ReleasesFeedRSS(ctx)
+	assert.EqualValues(t, http.StatusNotModified, ctx.Resp.Status())
+	assert.Empty(t, recorder.Body.String())
+
+	ctx, recorder = mockFeedContext(t, "user2/repo1/releases.rss")
+	ctx.Req.Header.Set("If-Modified-Since", time.Unix(946684799, 0).UTC().Format(http.TimeFormat))
+	ReleasesFeedRSS(ctx)
+	assert.EqualValues(t, http.StatusOK, ctx.Resp.Status())
+	assert.Contains(t, recorder.Body.String(), "testing-release")
+}
@@ -10,10 +10,10 @@ import (
 	"compress/gzip"
 	gocontext "context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
-	"os/exec"
 	"path"
 	"regexp"
 	"strconv"
@@ -27,9 +27,11 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/packcache"
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
+	gitea_sync "code.gitea.io/gitea/modules/sync"
 	"code.gitea.io/gitea/modules/timeutil"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
@@ -186,6 +188,22 @@ func HTTP(ctx *context.Context) {
 				// Assume password is token
 				authToken = authPasswd
 			}
+			if models.IsDeployToken(authToken) {
+				if !repoExist || isWiki {
+					ctx.HandleText(http.StatusForbidden, "Deploy tokens can only access the code of their repository")
+					return
+				}
+				scope := models.DeployTokenScopeGitPull
+				if !isPull {
+					scope = models.DeployTokenScopeGitPush
+				}
+				// an invalid deploy token falls through to the invalid credentials below
+				authUser, err = models.AuthenticateDeployToken(authToken, repo.ID, scope)
+				if err != nil && !models.IsErrDeployTokenNotExist(err) && !models.IsErrUserProhibitLogin(err) {
+					ctx.ServerError("AuthenticateDeployToken", err)
+					return
+				}
+			}
 			uid := sso.CheckOAuthAccessToken(authToken)
 			if uid != 0 {
 				ctx.Data["IsApiToken"] = true
@@ -323,6 +341,9 @@ func HTTP(ctx *context.Context) {
 	}
 
 	environ = append(environ, models.ProtectedBranchRepoID+fmt.Sprintf("=%d", repo.ID))
+	if requestID := context.RequestID(ctx.Context); requestID != "" {
+		environ = append(environ, log.EnvRequestID+"="+requestID)
+	}
 
 	w := ctx.Resp
 	r := ctx.Req.Request
@@ -330,6 +351,10 @@ func HTTP(ctx *context.Context) {
 		UploadPack:  true,
 		ReceivePack: true,
 		Env:         environ,
+		LimitKeys:   rpcLimitKeys(ctx, authUser, repo),
+	}
+	if !isWiki {
+		cfg.CacheRepoID = repo.ID
 	}
 
 	r.URL.Path = strings.ToLower(r.URL.Path) // blue: In case some repo name has upper case name
@@ -369,7 +394,7 @@ func HTTP(ctx *context.Context) {
 				return
 			}
 
-			route.handler(serviceHandler{cfg, w, r, dir, file, cfg.Env})
+			route.handler(serviceHandler{context.LogContext(ctx.Context), cfg, w, r, dir, file, cfg.Env})
 			return
 		}
 	}
@@ -401,7 +426,7 @@ func dummyInfoRefs(ctx *context.Context) {
 			return
 		}
 
-		refs, err := git.NewCommand("receive-pack", "--stateless-rpc", "--advertise-refs", ".").RunInDirBytes(tmpDir)
+		refs, err := git.NewCommandContext(context.LogContext(ctx.Context), "receive-pack", "--stateless-rpc", "--advertise-refs", ".").RunInDirBytes(tmpDir)
 		if err != nil {
 			log.Error(fmt.Sprintf("%v - %s", err, string(refs)))
 		}
@@ -423,9 +448,72 @@ type serviceConfig struct {
 	UploadPack  bool
 	ReceivePack bool
 	Env         []string
+	LimitKeys   []gitea_sync.LimitKey
+	// CacheRepoID is the ID of the repository whose packs are cached, 0 if they are not
+	CacheRepoID int64
+}
+
+// rpcLimiter limits the number of git upload-pack and receive-pack processes run at once
+var rpcLimiter = gitea_sync.NewLimiter()
+
+const (
+	rpcLimitInstance = "instance"
+	rpcLimitUser     = "user:"
+	rpcLimitRepo     = "repo:"
+)
+
+// rpcLimitKeys returns the limits of the git processes serving a request of the user, or of
+// its IP address if anonymous, to the repository
+func rpcLimitKeys(ctx *context.Context, user *models.User, repo *models.Repository) []gitea_sync.LimitKey {
+	userKey := rpcLimitUser + ctx.RemoteAddr()
+	if user != nil {
+		userKey = rpcLimitUser + strconv.FormatInt(user.ID, 10)
+	}
+	return []gitea_sync.LimitKey{
+		{Key: rpcLimitInstance, Max: setting.Git.HTTP.MaxConcurrent},
+		{Key: userKey, Max: setting.Git.HTTP.MaxConcurrentPerUser},
+		{Key: rpcLimitRepo + strconv.FormatInt(repo.ID, 10), Max: setting.Git.HTTP.MaxConcurrentPerRepo},
+	}
+}
+
+// acquireRPCLimits waits until the limits of the request allow running a git process, it
+// answers the request with 429 Too Many Requests if they still do not after the queue timeout
+func acquireRPCLimits(h serviceHandler) (func(), bool) {
+	ctx, cancel := gocontext.WithTimeout(h.r.Context(), setting.Git.HTTP.QueueTimeout)
+	defer cancel()
+	start := time.Now()
+	release, full, err := rpcLimiter.Acquire(ctx, h.cfg.LimitKeys...)
+	if err == nil {
+		if waited := time.Since(start); waited > time.Second {
+			log.Debug("Git request to %s waited %v for the concurrency limits", h.dir, waited)
+		}
+		return release, true
+	}
+
+	var reason string
+	switch {
+	case full.Key == rpcLimitInstance:
+		reason = "the server"
+	case strings.HasPrefix(full.Key, rpcLimitUser):
+		reason = "you"
+	default:
+		reason = "this repository"
+	}
+	log.Info("Git request to %s rejected, %s is at its limit of %d concurrent git operations", h.dir, full.Key, full.Max)
+	retryAfter := int(setting.Git.HTTP.QueueTimeout.Seconds())
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	h.w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	h.w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	h.w.WriteHeader(http.StatusTooManyRequests)
+	_, _ = fmt.Fprintf(h.w, "Too many concurrent git operations for %s (at most %d), waited %v. Retry in a few moments.\n",
+		reason, full.Max, time.Since(start).Round(time.Second))
+	return nil, false
 }
 
 type serviceHandler struct {
+	ctx     gocontext.Context
 	cfg     *serviceConfig
 	w       http.ResponseWriter
 	r       *http.Request
@@ -538,8 +626,6 @@ func serviceRPC(h serviceHandler, service string) {
 		return
 	}
 
-	h.w.Header().Set("Content-Type", fmt.Sprintf("application/x-git-%s-result", service))
-
 	var err error
 	var reqBody = h.r.Body
 
@@ -553,6 +639,44 @@ func serviceRPC(h serviceHandler, service string) {
 		}
 	}
 
+	// the transfers are recorded in the statistics once served
+	var out io.Writer = h.w
+	var stats *gitTransferStats
+	if setting.Git.EnableTransportStats {
+		stats = &gitTransferStats{service: service, protocol: h.r.Header.Get("Git-Protocol")}
+		reqBody = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(reqBody, stats.requestWriter()), reqBody}
+		out = io.MultiWriter(h.w, &stats.responseBytes)
+	}
+
+	// clones of the same commits are served from the pack cache
+	var cacheKey string
+	if service == "upload-pack" && setting.Git.PackCache.Enabled && h.cfg.CacheRepoID > 0 {
+		reqBody, cacheKey = packCacheKey(h, reqBody)
+		if cacheKey != "" {
+			if f := packcache.Get(h.cfg.CacheRepoID, cacheKey); f != nil {
+				defer f.Close()
+				h.w.Header().Set("Content-Type", fmt.Sprintf("application/x-git-%s-result", service))
+				if _, err := io.Copy(out, f); err != nil {
+					log.Debug("Unable to send cached pack of %s: %v", h.dir, err)
+					return
+				}
+				stats.record()
+				return
+			}
+		}
+	}
+
+	release, ok := acquireRPCLimits(h)
+	if !ok {
+		return
+	}
+	defer release()
+
+	h.w.Header().Set("Content-Type", fmt.Sprintf("application/x-git-%s-result", service))
+
 	// set this for allow pre-receive and post-receive execute
 	h.environ = append(h.environ, "SSH_ORIGINAL_COMMAND="+service)
 
@@ -563,20 +687,107 @@ func serviceRPC(h serviceHandler, service string) {
 	ctx, cancel := gocontext.WithCancel(git.DefaultContext)
 	defer cancel()
 	var stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, git.GitExecutable, service, "--stateless-rpc", h.dir)
+	cmd := process.LimitedCommand(ctx, process.Limits{
+		MaxMemory:  setting.Git.HTTP.MaxMemory,
+		MaxCPUTime: setting.Git.HTTP.MaxCPUTime,
+	}, git.GitExecutable, service, "--stateless-rpc", h.dir)
 	cmd.Dir = h.dir
 	cmd.Env = append(os.Environ(), h.environ...)
-	cmd.Stdout = h.w
+	cmd.Stdout = out
 	cmd.Stdin = reqBody
 	cmd.Stderr = &stderr
 
 	pid := process.GetManager().Add(fmt.Sprintf("%s %s %s [repo_path: %s]", git.GitExecutable, service, "--stateless-rpc", h.dir), cancel)
 	defer process.GetManager().Remove(pid)
 
+	// the packs of the clones requested often enough are cached
+	var entry *packcache.Entry
+	if cacheKey != "" && packcache.Observe(cacheKey) {
+		if entry, err = packcache.NewEntry(h.cfg.CacheRepoID, cacheKey); err != nil {
+			log.Error("Unable to cache pack of %s: %v", h.dir, err)
+			entry = nil
+		} else {
+			cmd.Stdout = io.MultiWriter(out, entry)
+		}
+	}
+
 	if err := cmd.Run(); err != nil {
+		if entry != nil {
+			entry.Abort()
+		}
 		log.Error("Fail to serve RPC(%s): %v - %s", service, err, stderr.String())
 		return
 	}
+	if entry != nil {
+		entry.Commit()
+	}
+	stats.record()
+}
+
+// byteCounter counts the bytes written to it
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// gitTransferStats collects the statistic of a git transfer while it is served
+type gitTransferStats struct {
+	service       string
+	protocol      string
+	request       git.UploadPackRequestParser
+	requestBytes  byteCounter
+	responseBytes byteCounter
+}
+
+// requestWriter returns the writer the request body is copied to
+func (s *gitTransferStats) requestWriter() io.Writer {
+	if s.service == "upload-pack" {
+		return io.MultiWriter(&s.requestBytes, &s.request)
+	}
+	return &s.requestBytes
+}
+
+// record adds the served transfer to the statistics, the requests to upload-pack which do not
+// transfer objects are skipped
+func (s *gitTransferStats) record() {
+	if s == nil {
+		return
+	}
+	var stat *models.GitTransportStat
+	if s.service == "upload-pack" {
+		if s.request.Err() != nil {
+			return
+		}
+		if stat = models.NewGitUploadPackStat(s.protocol, &s.request.UploadPackRequest); stat == nil {
+			return
+		}
+	} else {
+		stat = &models.GitTransportStat{
+			Kind:     models.GitTransferPush,
+			Protocol: models.ParseGitProtocolVersion(s.protocol),
+		}
+	}
+	stat.RequestBytes = int64(s.requestBytes)
+	stat.ResponseBytes = int64(s.responseBytes)
+	if err := models.AddGitTransportStat(stat); err != nil {
+		log.Error("Unable to record git transfer statistic: %v", err)
+	}
+}
+
+// packCacheKey reads the beginning of an upload-pack request body and returns the pack cache
+// key of the request, or an empty string if it cannot be cached, along with the whole body.
+func packCacheKey(h serviceHandler, body io.ReadCloser) (io.ReadCloser, string) {
+	buf, err := ioutil.ReadAll(io.LimitReader(body, packcache.MaxRequestSize+1))
+	rest := struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), body), body}
+	if err != nil || len(buf) > packcache.MaxRequestSize {
+		return rest, ""
+	}
+	return rest, packcache.RequestKey(h.cfg.CacheRepoID, h.r.Header.Get("Git-Protocol"), buf)
 }
 
 func serviceUploadPack(h serviceHandler) {
@@ -621,7 +832,7 @@ func getInfoRefs(h serviceHandler) {
 		}
 		h.environ = append(os.Environ(), h.environ...)
 
-		refs, err := git.NewCommand(service, "--stateless-rpc", "--advertise-refs", ".").RunInDirTimeoutEnv(h.environ, -1, h.dir)
+		refs, err := git.NewCommandContext(h.ctx, service, "--stateless-rpc", "--advertise-refs", ".").RunInDirTimeoutEnv(h.environ, -1, h.dir)
 		if err != nil {
 			log.Error(fmt.Sprintf("%v - %s", err, string(refs)))
 		}
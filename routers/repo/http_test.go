@@ -0,0 +1,53 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+	gitea_sync "code.gitea.io/gitea/modules/sync"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireRPCLimits(t *testing.T) {
+	defer func(timeout time.Duration) {
+		setting.Git.HTTP.QueueTimeout = timeout
+	}(setting.Git.HTTP.QueueTimeout)
+	setting.Git.HTTP.QueueTimeout = 10 * time.Millisecond
+
+	keys := []gitea_sync.LimitKey{
+		{Key: rpcLimitInstance},
+		{Key: rpcLimitUser + "2"},
+		{Key: rpcLimitRepo + "1", Max: 1},
+	}
+	newHandler := func() (serviceHandler, *httptest.ResponseRecorder) {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/user2/repo1.git/git-upload-pack", nil)
+		return serviceHandler{cfg: &serviceConfig{LimitKeys: keys}, w: recorder, r: req}, recorder
+	}
+
+	h, _ := newHandler()
+	release, ok := acquireRPCLimits(h)
+	assert.True(t, ok)
+
+	// the repository is at its limit
+	h, recorder := newHandler()
+	_, ok = acquireRPCLimits(h)
+	assert.False(t, ok)
+	assert.EqualValues(t, http.StatusTooManyRequests, recorder.Code)
+	assert.EqualValues(t, "1", recorder.Header().Get("Retry-After"))
+	assert.Contains(t, recorder.Body.String(), "Too many concurrent git operations for this repository (at most 1)")
+
+	release()
+	h, _ = newHandler()
+	release, ok = acquireRPCLimits(h)
+	assert.True(t, ok)
+	release()
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	stats_indexer "code.gitea.io/gitea/modules/indexer/stats"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+const (
+	tplInsights base.TplName = "repo/insights"
+
+	// insightsMaxContributors is the number of contributors shown in the
+	// insights like on GitHub
+	insightsMaxContributors = 100
+)
+
+// insightsContributor represents a contributor in the insights graphs
+type insightsContributor struct {
+	Name       string `json:"name"`
+	Login      string `json:"login"`
+	AvatarLink string `json:"avatar_link"`
+	HomeLink   string `json:"home_link"`
+	Commits    int64  `json:"commits"`
+	CoAuthored int64  `json:"co_authored"`
+	Additions  int64  `json:"additions"`
+	Deletions  int64  `json:"deletions"`
+	// Weeks are the commits in the last 52 weeks
+	Weeks []int64 `json:"weeks"`
+}
+
+// insightsDay represents the commits of a day in the commit activity heatmap
+type insightsDay struct {
+	Timestamp timeutil.TimeStamp `json:"timestamp"`
+	Commits   int64              `json:"commits"`
+}
+
+// insightsData represents the data of the insights graphs for the last 52
+// weeks
+type insightsData struct {
+	Weeks        []timeutil.TimeStamp   `json:"weeks"`
+	Contributors []*insightsContributor `json:"contributors"`
+	Activity     []*insightsDay         `json:"activity"`
+	Additions    []int64                `json:"additions"`
+	Deletions    []int64                `json:"deletions"`
+}
+
+// lastYearIndex returns the index of the week within the last 52 weeks
+// starting at first, or -1 if the week is not one of them
+func lastYearIndex(first time.Time, week timeutil.TimeStamp) int {
+	idx := int(int64(week)-first.Unix()) / (7 * 24 * 60 * 60)
+	if int64(week) < first.Unix() || idx >= 52 {
+		return -1
+	}
+	return idx
+}
+
+// Insights renders the contributors, commit activity and code frequency of
+// the default branch
+func Insights(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.insights")
+	ctx.Data["PageIsInsights"] = true
+
+	repo := ctx.Repo.Repository
+	has, err := repo.HasInsights()
+	if err != nil {
+		ctx.ServerError("HasInsights", err)
+		return
+	}
+	if !has {
+		if err := stats_indexer.UpdateRepoIndexer(repo); err != nil {
+			log.Error("stats_indexer.UpdateRepoIndexer(%d) failed: %v", repo.ID, err)
+		}
+		ctx.Data["InsightsPending"] = true
+		ctx.HTML(200, tplInsights)
+		return
+	}
+
+	first := models.StartOfWeek(time.Now()).AddDate(0, 0, -7*51)
+	data := &insightsData{
+		Weeks:     make([]timeutil.TimeStamp, 52),
+		Additions: make([]int64, 52),
+		Deletions: make([]int64, 52),
+	}
+	for i := range data.Weeks {
+		data.Weeks[i] = timeutil.TimeStamp(first.AddDate(0, 0, 7*i).Unix())
+	}
+
+	stats, err := models.GetRepoContributorStats(repo)
+	if err != nil {
+		ctx.ServerError("GetRepoContributorStats", err)
+		return
+	}
+	if len(stats) > insightsMaxContributors {
+		stats = stats[:insightsMaxContributors]
+	}
+	unknownUserAvatarLink := models.NewGhostUser().AvatarLink()
+	for _, contributor := range stats {
+		c := &insightsContributor{
+			Name:       contributor.Name,
+			AvatarLink: unknownUserAvatarLink,
+			Commits:    contributor.Total,
+			CoAuthored: contributor.CoAuthored,
+			Weeks:      make([]int64, 52),
+		}
+		if contributor.User != nil {
+			c.Name = contributor.User.DisplayName()
+			c.Login = contributor.User.Name
+			c.AvatarLink = contributor.User.AvatarLink()
+			c.HomeLink = contributor.User.HomeLink()
+		}
+		for _, week := range contributor.Weeks {
+			c.Additions += week.Additions
+			c.Deletions += week.Deletions
+			if idx := lastYearIndex(first, week.Week); idx >= 0 {
+				c.Weeks[idx] = week.Commits
+			}
+		}
+		data.Contributors = append(data.Contributors, c)
+	}
+
+	activity, err := models.GetRepoCommitActivity(repo)
+	if err != nil {
+		ctx.ServerError("GetRepoCommitActivity", err)
+		return
+	}
+	for _, week := range activity {
+		for day, commits := range week.Days {
+			if commits > 0 {
+				data.Activity = append(data.Activity, &insightsDay{
+					Timestamp: week.Week + timeutil.TimeStamp(day*24*60*60),
+					Commits:   commits,
+				})
+			}
+		}
+	}
+
+	weeks, err := models.GetRepoCodeFrequency(repo)
+	if err != nil {
+		ctx.ServerError("GetRepoCodeFrequency", err)
+		return
+	}
+	for _, week := range weeks {
+		if idx := lastYearIndex(first, week.Week); idx >= 0 {
+			data.Additions[idx] = week.Additions
+			data.Deletions[idx] = week.Deletions
+		}
+	}
+
+	ctx.Data["Insights"] = data
+	ctx.HTML(200, tplInsights)
+}
@@ -114,10 +114,11 @@ func issues(ctx *context.Context, milestoneID int64, isPullOption util.OptionalB
 	}
 
 	var (
-		assigneeID  = ctx.QueryInt64("assignee")
-		posterID    int64
-		mentionedID int64
-		forceEmpty  bool
+		assigneeID      = ctx.QueryInt64("assignee")
+		workflowStateID = ctx.QueryInt64("workflow_state")
+		posterID        int64
+		mentionedID     int64
+		forceEmpty      bool
 	)
 
 	if ctx.IsSigned {
@@ -162,14 +163,15 @@ func issues(ctx *context.Context, milestoneID int64, isPullOption util.OptionalB
 		issueStats = &models.IssueStats{}
 	} else {
 		issueStats, err = models.GetIssueStats(&models.IssueStatsOptions{
-			RepoID:      repo.ID,
-			Labels:      selectLabels,
-			MilestoneID: milestoneID,
-			AssigneeID:  assigneeID,
-			MentionedID: mentionedID,
-			PosterID:    posterID,
-			IsPull:      isPullOption,
-			IssueIDs:    issueIDs,
+			RepoID:          repo.ID,
+			Labels:          selectLabels,
+			MilestoneID:     milestoneID,
+			WorkflowStateID: workflowStateID,
+			AssigneeID:      assigneeID,
+			MentionedID:     mentionedID,
+			PosterID:        posterID,
+			IsPull:          isPullOption,
+			IssueIDs:        issueIDs,
 		})
 		if err != nil {
 			ctx.ServerError("GetIssueStats", err)
@@ -210,16 +212,17 @@ func issues(ctx *context.Context, milestoneID int64, isPullOption util.OptionalB
 				Page:     pager.Paginater.Current(),
 				PageSize: setting.UI.IssuePagingNum,
 			},
-			RepoIDs:      []int64{repo.ID},
-			AssigneeID:   assigneeID,
-			PosterID:     posterID,
-			MentionedID:  mentionedID,
-			MilestoneIDs: mileIDs,
-			IsClosed:     util.OptionalBoolOf(isShowClosed),
-			IsPull:       isPullOption,
-			LabelIDs:     labelIDs,
-			SortType:     sortType,
-			IssueIDs:     issueIDs,
+			RepoIDs:         []int64{repo.ID},
+			AssigneeID:      assigneeID,
+			PosterID:        posterID,
+			MentionedID:     mentionedID,
+			MilestoneIDs:    mileIDs,
+			WorkflowStateID: workflowStateID,
+			IsClosed:        util.OptionalBoolOf(isShowClosed),
+			IsPull:          isPullOption,
+			LabelIDs:        labelIDs,
+			SortType:        sortType,
+			IssueIDs:        issueIDs,
 		})
 		if err != nil {
 			ctx.ServerError("Issues", err)
@@ -319,6 +322,7 @@ func issues(ctx *context.Context, milestoneID int64, isPullOption util.OptionalB
 	ctx.Data["ViewType"] = viewType
 	ctx.Data["SortType"] = sortType
 	ctx.Data["MilestoneID"] = milestoneID
+	ctx.Data["WorkflowStateID"] = workflowStateID
 	ctx.Data["AssigneeID"] = assigneeID
 	ctx.Data["IsShowClosed"] = isShowClosed
 	ctx.Data["Keyword"] = keyword
@@ -334,6 +338,7 @@ func issues(ctx *context.Context, milestoneID int64, isPullOption util.OptionalB
 	pager.AddParam(ctx, "state", "State")
 	pager.AddParam(ctx, "labels", "SelectLabels")
 	pager.AddParam(ctx, "milestone", "MilestoneID")
+	pager.AddParam(ctx, "workflow_state", "WorkflowStateID")
 	pager.AddParam(ctx, "assignee", "AssigneeID")
 	ctx.Data["Page"] = pager
 }
@@ -348,6 +353,7 @@ func Issues(ctx *context.Context) {
 		}
 		ctx.Data["Title"] = ctx.Tr("repo.pulls")
 		ctx.Data["PageIsPullList"] = true
+		ctx.Data["FeedURL"] = ctx.Repo.RepoLink + "/pulls"
 	} else {
 		MustEnableIssues(ctx)
 		if ctx.Written() {
@@ -355,6 +361,7 @@ func Issues(ctx *context.Context) {
 		}
 		ctx.Data["Title"] = ctx.Tr("repo.issues")
 		ctx.Data["PageIsIssueList"] = true
+		ctx.Data["FeedURL"] = ctx.Repo.RepoLink + "/issues"
 	}
 
 	issues(ctx, ctx.QueryInt64("milestone"), util.OptionalBoolOf(isPullList))
@@ -367,6 +374,12 @@ func Issues(ctx *context.Context) {
 		return
 	}
 
+	ctx.Data["WorkflowStates"], err = models.GetWorkflowStatesByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetWorkflowStatesByRepoID", err)
+		return
+	}
+
 	ctx.Data["CanWriteIssuesOrPulls"] = ctx.Repo.CanWriteIssuesOrPulls(isPullList)
 
 	ctx.HTML(200, tplIssues)
@@ -391,6 +404,18 @@ func RetrieveRepoMilestonesAndAssignees(ctx *context.Context, repo *models.Repos
 		ctx.ServerError("GetAssignees", err)
 		return
 	}
+
+	ctx.Data["WorkflowStates"], err = models.GetWorkflowStatesByRepoID(repo.ID)
+	if err != nil {
+		ctx.ServerError("GetWorkflowStatesByRepoID", err)
+		return
+	}
+
+	ctx.Data["Epics"], err = models.GetEpicsForRepo(repo, api.StateOpen)
+	if err != nil {
+		ctx.ServerError("GetEpicsForRepo", err)
+		return
+	}
 }
 
 // RetrieveRepoReviewers find all reviewers of a repository
@@ -401,6 +426,12 @@ func RetrieveRepoReviewers(ctx *context.Context, repo *models.Repository, issueP
 		ctx.ServerError("GetReviewers", err)
 		return
 	}
+
+	ctx.Data["TeamReviewers"], err = models.GetRepoReviewTeams(repo)
+	if err != nil {
+		ctx.ServerError("GetRepoReviewTeams", err)
+		return
+	}
 }
 
 // RetrieveRepoMetas find all the meta information of a repository
@@ -483,6 +514,22 @@ func setTemplateIfExists(ctx *context.Context, ctxDataKey string, possibleFiles
 	}
 }
 
+// setOrgIssueTemplate uses the default issue template of the organization
+// owning the repository if the repository has no issue template
+func setOrgIssueTemplate(ctx *context.Context) {
+	if _, ok := ctx.Data[issueTemplateKey]; ok || !ctx.Repo.Owner.IsOrganization() {
+		return
+	}
+	defaults, err := models.GetOrgRepoDefaults(ctx.Repo.Owner.ID)
+	if err != nil {
+		log.Error("GetOrgRepoDefaults: %v", err)
+		return
+	}
+	if defaults.IssueTemplate != "" {
+		ctx.Data[issueTemplateKey] = defaults.IssueTemplate
+	}
+}
+
 // NewIssue render creating issue page
 func NewIssue(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.issues.new")
@@ -506,7 +553,8 @@ func NewIssue(ctx *context.Context) {
 	}
 
 	setTemplateIfExists(ctx, issueTemplateKey, IssueTemplateCandidates)
-	renderAttachmentSettings(ctx)
+	setOrgIssueTemplate(ctx)
+	renderIssueAttachmentSettings(ctx)
 
 	RetrieveRepoMetas(ctx, ctx.Repo.Repository, false)
 	if ctx.Written() {
@@ -607,7 +655,7 @@ func NewIssuePost(ctx *context.Context, form auth.CreateIssueForm) {
 	ctx.Data["RequireSimpleMDE"] = true
 	ctx.Data["ReadOnly"] = false
 	ctx.Data["PullRequestWorkInProgressPrefixes"] = setting.Repository.PullRequest.WorkInProgressPrefixes
-	renderAttachmentSettings(ctx)
+	renderIssueAttachmentSettings(ctx)
 
 	var (
 		repo        = ctx.Repo.Repository
@@ -647,6 +695,9 @@ func NewIssuePost(ctx *context.Context, form auth.CreateIssueForm) {
 		if models.IsErrUserDoesNotHaveAccessToRepo(err) {
 			ctx.Error(400, "UserDoesNotHaveAccessToRepo", err.Error())
 			return
+		} else if models.IsErrIssueAttachmentsTooLarge(err) {
+			ctx.RenderWithErr(issueAttachmentsTooLargeMessage(ctx, err), tplIssueNew, form)
+			return
 		}
 		ctx.ServerError("NewIssue", err)
 		return
@@ -749,7 +800,7 @@ func ViewIssue(ctx *context.Context) {
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["RequireTribute"] = true
 	ctx.Data["RequireSimpleMDE"] = true
-	renderAttachmentSettings(ctx)
+	renderIssueAttachmentSettings(ctx)
 
 	if err = issue.LoadAttributes(); err != nil {
 		ctx.ServerError("LoadAttributes", err)
@@ -786,8 +837,19 @@ func ViewIssue(ctx *context.Context) {
 			ctx.Data["DisableStatusChange"] = issue.PullRequest.HasMerged
 			PrepareMergedViewPullInfo(ctx, issue)
 		} else {
-			PrepareViewPullInfo(ctx, issue)
+			if compareInfo := PrepareViewPullInfo(ctx, issue); compareInfo != nil {
+				// Suggest folding the fixup! and squash! commits when rebasing
+				for e := compareInfo.Commits.Front(); e != nil; e = e.Next() {
+					if e.Value.(*git.Commit).IsFixup() {
+						ctx.Data["HasFixupCommits"] = true
+						break
+					}
+				}
+			}
 			ctx.Data["DisableStatusChange"] = ctx.Data["IsPullRequestBroken"] == true && issue.IsClosed
+			if !ctx.Written() {
+				setPullDevEnvironment(ctx, issue)
+			}
 		}
 		if ctx.Written() {
 			return
@@ -934,6 +996,10 @@ func ViewIssue(ctx *context.Context) {
 			comment.RenderedContent = string(markdown.Render([]byte(comment.Content), ctx.Repo.RepoLink,
 				ctx.Repo.Repository.ComposeMetas()))
 
+			if comment.ID == issue.AnswerCommentID {
+				issue.AnswerComment = comment
+			}
+
 			// Check tag.
 			tag, ok = marked[comment.PosterID]
 			if ok {
@@ -968,6 +1034,21 @@ func ViewIssue(ctx *context.Context) {
 			if comment.MilestoneID > 0 && comment.Milestone == nil {
 				comment.Milestone = ghostMilestone
 			}
+		} else if comment.Type == models.CommentTypeChangeWorkflowState {
+			if err = comment.LoadWorkflowStates(); err != nil {
+				ctx.ServerError("LoadWorkflowStates", err)
+				return
+			}
+			ghostState := &models.WorkflowState{
+				ID:   -1,
+				Name: ctx.Tr("repo.issues.deleted_workflow_state"),
+			}
+			if comment.OldWorkflowStateID > 0 && comment.OldWorkflowState == nil {
+				comment.OldWorkflowState = ghostState
+			}
+			if comment.WorkflowStateID > 0 && comment.WorkflowState == nil {
+				comment.WorkflowState = ghostState
+			}
 		} else if comment.Type == models.CommentTypeAssignees || comment.Type == models.CommentTypeReviewRequest {
 			if err = comment.LoadAssigneeUser(); err != nil {
 				ctx.ServerError("LoadAssigneeUser", err)
@@ -978,6 +1059,12 @@ func ViewIssue(ctx *context.Context) {
 				ctx.ServerError("LoadDepIssueDetails", err)
 				return
 			}
+		} else if comment.Type == models.CommentTypeMarkDuplicate {
+			// the original issue may have been deleted since
+			if err = comment.LoadDepIssueDetails(); err != nil && !models.IsErrIssueNotExist(err) {
+				ctx.ServerError("LoadDepIssueDetails", err)
+				return
+			}
 		} else if comment.Type == models.CommentTypeCode || comment.Type == models.CommentTypeReview {
 			comment.RenderedContent = string(markdown.Render([]byte(comment.Content), ctx.Repo.RepoLink,
 				ctx.Repo.Repository.ComposeMetas()))
@@ -1054,6 +1141,11 @@ func ViewIssue(ctx *context.Context) {
 				ctx.ServerError("IsUserAllowedToMerge", err)
 				return
 			}
+			ctx.Data["CanForceMerge"], err = pull_service.IsUserAllowedToForceMerge(pull, perm, ctx.User)
+			if err != nil {
+				ctx.ServerError("IsUserAllowedToForceMerge", err)
+				return
+			}
 
 			if ctx.Data["CanMarkConversation"], err = models.CanMarkConversation(issue, ctx.User); err != nil {
 				ctx.ServerError("CanMarkConversation", err)
@@ -1068,20 +1160,21 @@ func ViewIssue(ctx *context.Context) {
 		}
 		prConfig := prUnit.PullRequestsConfig()
 
-		// Check correct values and select default
-		if ms, ok := ctx.Data["MergeStyle"].(models.MergeStyle); !ok ||
-			!prConfig.IsMergeStyleAllowed(ms) {
-			if prConfig.AllowMerge {
-				ctx.Data["MergeStyle"] = models.MergeStyleMerge
-			} else if prConfig.AllowRebase {
-				ctx.Data["MergeStyle"] = models.MergeStyleRebase
-			} else if prConfig.AllowRebaseMerge {
-				ctx.Data["MergeStyle"] = models.MergeStyleRebaseMerge
-			} else if prConfig.AllowSquash {
-				ctx.Data["MergeStyle"] = models.MergeStyleSquash
-			} else {
-				ctx.Data["MergeStyle"] = ""
-			}
+		// Offer the merge styles allowed by the repository and by the protection of the
+		// base branch, the first one is the default
+		mergeStyles, err := pull_service.GetAllowedMergeStyles(pull, prConfig, ctx.User)
+		if err != nil {
+			ctx.ServerError("GetAllowedMergeStyles", err)
+			return
+		}
+		allowedMergeStyles := make(map[string]bool, len(mergeStyles))
+		for _, style := range mergeStyles {
+			allowedMergeStyles[string(style)] = true
+		}
+		ctx.Data["AllowedMergeStyles"] = allowedMergeStyles
+		ctx.Data["MergeStyle"] = ""
+		if len(mergeStyles) > 0 {
+			ctx.Data["MergeStyle"] = mergeStyles[0]
 		}
 		if err = pull.LoadProtectedBranch(); err != nil {
 			ctx.ServerError("LoadProtectedBranch", err)
@@ -1119,6 +1212,13 @@ func ViewIssue(ctx *context.Context) {
 			ctx.ServerError("GetReviewersByIssueID", err)
 			return
 		}
+
+		if !pull.HasMerged && !issue.IsClosed {
+			prepareContributorAgreementPrompt(ctx)
+			if ctx.Written() {
+				return
+			}
+		}
 	}
 
 	// Get Dependencies
@@ -1140,6 +1240,9 @@ func ViewIssue(ctx *context.Context) {
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login?redirect_to=" + ctx.Data["Link"].(string)
 	ctx.Data["IsIssuePoster"] = ctx.IsSigned && issue.IsPoster(ctx.User.ID)
 	ctx.Data["HasIssuesOrPullsWritePermission"] = ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull)
+	ctx.Data["IsQuestion"] = issue.IsQuestion()
+	ctx.Data["CanAcceptAnswer"] = issue.IsQuestion() && ctx.IsSigned &&
+		(issue.IsPoster(ctx.User.ID) || ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull))
 	ctx.Data["IsRepoAdmin"] = ctx.IsSigned && (ctx.Repo.IsAdmin() || ctx.User.IsAdmin)
 	ctx.Data["LockReasons"] = setting.Repository.Issue.LockReasons
 	ctx.Data["RefEndName"] = git.RefEndName(issue.Ref)
@@ -1255,7 +1358,12 @@ func UpdateIssueContent(ctx *context.Context) {
 
 	files := ctx.QueryStrings("files[]")
 	if err := updateAttachments(issue, files); err != nil {
+		if models.IsErrIssueAttachmentsTooLarge(err) {
+			ctx.Error(413, issueAttachmentsTooLargeMessage(ctx, err))
+			return
+		}
 		ctx.ServerError("UpdateAttachments", err)
+		return
 	}
 
 	ctx.JSON(200, map[string]interface{}{
@@ -1289,6 +1397,36 @@ func UpdateIssueMilestone(ctx *context.Context) {
 	})
 }
 
+// UpdateIssueWorkflowState change issue's workflow state
+func UpdateIssueWorkflowState(ctx *context.Context) {
+	issues := getActionIssues(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	stateID := ctx.QueryInt64("id")
+	for _, issue := range issues {
+		if issue.WorkflowStateID == stateID {
+			continue
+		}
+		if err := issue_service.ChangeWorkflowState(issue, ctx.User, stateID); err != nil {
+			if models.IsErrWorkflowTransitionNotAllowed(err) || models.IsErrWorkflowStateNotExist(err) {
+				ctx.Flash.Error(ctx.Tr("repo.issues.workflow_state_transition_not_allowed"))
+				continue
+			} else if models.IsErrDependenciesLeft(err) {
+				ctx.Flash.Error(ctx.Tr("repo.issues.dependency.issue_close_blocked"))
+				continue
+			}
+			ctx.ServerError("ChangeWorkflowState", err)
+			return
+		}
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"ok": true,
+	})
+}
+
 // UpdateIssueAssignee change issue's or pull's assignee
 func UpdateIssueAssignee(ctx *context.Context) {
 	issues := getActionIssues(ctx)
@@ -1401,6 +1539,24 @@ func isLegalReviewRequest(reviewer, doer *models.User, isAdd bool, issue *models
 	return nil
 }
 
+func isLegalTeamReviewRequest(doer *models.User, issue *models.Issue) error {
+	permDoer, err := models.GetUserRepoPermission(issue.Repo, doer)
+	if err != nil {
+		return err
+	}
+	if permDoer.CanAccessAny(models.AccessModeWrite, models.UnitTypePullRequests) {
+		return nil
+	}
+
+	official, err := models.IsOfficialReviewer(issue, doer)
+	if err != nil {
+		return err
+	} else if !official {
+		return fmt.Errorf("Doer can't choose reviewer [user_id: %d, repo_name: %s, issue_id: %d]", doer.ID, issue.Repo.Name, issue.ID)
+	}
+	return nil
+}
+
 // updatePullReviewRequest change pull's request reviewers
 func updatePullReviewRequest(ctx *context.Context) {
 	issues := getActionIssues(ctx)
@@ -1417,7 +1573,29 @@ func updatePullReviewRequest(ctx *context.Context) {
 	}
 
 	for _, issue := range issues {
-		if issue.IsPull {
+		// teams are requested with their negated IDs, the request is passed
+		// on to the members picked by the review assignment of the team
+		if issue.IsPull && reviewID < 0 {
+			if event != "add" {
+				continue
+			}
+
+			team, err := models.GetTeamByID(-reviewID)
+			if err != nil {
+				ctx.ServerError("GetTeamByID", err)
+				return
+			}
+
+			if err = isLegalTeamReviewRequest(ctx.User, issue); err != nil {
+				ctx.ServerError("isLegalTeamReviewRequest", err)
+				return
+			}
+
+			if _, err = issue_service.TeamReviewRequest(issue, ctx.User, team); err != nil {
+				ctx.ServerError("TeamReviewRequest", err)
+				return
+			}
+		} else if issue.IsPull {
 
 			reviewer, err := models.GetUserByID(reviewID)
 			if err != nil {
@@ -1610,13 +1788,23 @@ func NewComment(ctx *context.Context, form auth.CreateCommentForm) {
 		return
 	}
 
-	comment, err := comment_service.CreateIssueComment(ctx.User, ctx.Repo.Repository, issue, form.Content, attachments)
+	comment, err := issue_service.CreateIssueComment(ctx.User, ctx.Repo.Repository, issue, form.Content, attachments)
 	if err != nil {
+		if issue_service.IsErrInvalidSlashCommand(err) {
+			errCmd := err.(issue_service.ErrInvalidSlashCommand)
+			ctx.Flash.Error(ctx.Tr("repo.issues.invalid_slash_command", errCmd.Command, errCmd.Reason))
+			return
+		} else if models.IsErrIssueAttachmentsTooLarge(err) {
+			ctx.Flash.Error(issueAttachmentsTooLargeMessage(ctx, err))
+			return
+		}
 		ctx.ServerError("CreateIssueComment", err)
 		return
 	}
 
-	log.Trace("Comment created: %d/%d/%d", ctx.Repo.Repository.ID, issue.ID, comment.ID)
+	if comment != nil {
+		log.Trace("Comment created: %d/%d/%d", ctx.Repo.Repository.ID, issue.ID, comment.ID)
+	}
 }
 
 // UpdateCommentContent change comment of issue's content
@@ -1662,7 +1850,12 @@ func UpdateCommentContent(ctx *context.Context) {
 
 	files := ctx.QueryStrings("files[]")
 	if err := updateAttachments(comment, files); err != nil {
+		if models.IsErrIssueAttachmentsTooLarge(err) {
+			ctx.Error(413, issueAttachmentsTooLargeMessage(ctx, err))
+			return
+		}
 		ctx.ServerError("UpdateAttachments", err)
+		return
 	}
 
 	ctx.JSON(200, map[string]interface{}{
@@ -1958,6 +2151,11 @@ func GetCommentAttachments(ctx *context.Context) {
 	ctx.JSON(200, attachments)
 }
 
+// issueAttachmentsTooLargeMessage returns the message of an ErrIssueAttachmentsTooLarge
+func issueAttachmentsTooLargeMessage(ctx *context.Context, err error) string {
+	return ctx.Tr("repo.issues.attachments_too_large", err.(models.ErrIssueAttachmentsTooLarge).MaxSize/(1024*1024))
+}
+
 func updateAttachments(item interface{}, files []string) error {
 	var attachments []*models.Attachment
 	switch content := item.(type) {
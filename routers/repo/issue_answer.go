@@ -0,0 +1,44 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+)
+
+// SetIssueAnswer accepts a comment as the answer of a question issue,
+// a comment_id of 0 removes the accepted answer.
+func SetIssueAnswer(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if !issue.IsPoster(ctx.User.ID) && !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Error(403)
+		return
+	}
+
+	commentID := ctx.QueryInt64("comment_id")
+	if err := issue.SetAnswer(commentID); err != nil {
+		if models.IsErrIssueNotQuestion(err) || models.IsErrCommentNotExist(err) {
+			ctx.NotFound("SetAnswer", err)
+		} else {
+			ctx.ServerError("SetAnswer", err)
+		}
+		return
+	}
+
+	redirect := issue.HTMLURL()
+	if commentID > 0 {
+		redirect = fmt.Sprintf("%s#%s", redirect, issue.AnswerComment.HashTag())
+	}
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": redirect,
+	})
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+)
+
+const tplIssueGraph base.TplName = "repo/issue/graph"
+
+// IssueGraph render the reference graph of an issue, the graph itself is
+// loaded by the frontend from the API
+func IssueGraph(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.issues.graph.title", issue.Index)
+	if issue.IsPull {
+		ctx.Data["PageIsPullList"] = true
+	} else {
+		ctx.Data["PageIsIssueList"] = true
+	}
+	ctx.Data["Issue"] = issue
+	depths := make([]int, models.IssueGraphMaxDepth)
+	for i := range depths {
+		depths[i] = i + 1
+	}
+	ctx.Data["IssueGraphDepths"] = depths
+	ctx.Data["IssueGraphMaxDepth"] = models.IssueGraphMaxDepth
+	ctx.HTML(200, tplIssueGraph)
+}
@@ -110,6 +110,7 @@ func NewLabel(ctx *context.Context, form auth.CreateLabelForm) {
 		Name:        form.Title,
 		Description: form.Description,
 		Color:       form.Color,
+		IsQuestion:  form.IsQuestion,
 	}
 	if err := models.NewLabel(l); err != nil {
 		ctx.ServerError("NewLabel", err)
@@ -134,6 +135,7 @@ func UpdateLabel(ctx *context.Context, form auth.CreateLabelForm) {
 	l.Name = form.Title
 	l.Description = form.Description
 	l.Color = form.Color
+	l.IsQuestion = form.IsQuestion
 	if err := models.UpdateLabel(l); err != nil {
 		ctx.ServerError("UpdateLabel", err)
 		return
@@ -141,6 +143,21 @@ func UpdateLabel(ctx *context.Context, form auth.CreateLabelForm) {
 	ctx.Redirect(ctx.Repo.RepoLink + "/labels")
 }
 
+// UpdateOrgLabelSync opts the repository in or out of the synchronization of the labels of its organization
+func UpdateOrgLabelSync(ctx *context.Context) {
+	if !ctx.Repo.Owner.IsOrganization() || !ctx.Repo.IsAdmin() {
+		ctx.Error(403)
+		return
+	}
+
+	if err := models.UpdateRepoOrgLabelSync(ctx.Repo.Repository, !ctx.QueryBool("enable")); err != nil {
+		ctx.ServerError("UpdateRepoOrgLabelSync", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/labels")
+}
+
 // DeleteLabel delete a label
 func DeleteLabel(ctx *context.Context) {
 	if err := models.DeleteLabel(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
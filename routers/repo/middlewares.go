@@ -60,6 +60,14 @@ func SetDiffViewStyle(ctx *context.Context) {
 	}
 }
 
+// whitespaceFlags are the git diff flags of the whitespace behaviors
+var whitespaceFlags = map[string]string{
+	"ignore-all":    "-w",
+	"ignore-change": "-b",
+	"ignore-eol":    "--ignore-space-at-eol",
+	"":              "",
+}
+
 // SetWhitespaceBehavior set whitespace behavior as render variable
 func SetWhitespaceBehavior(ctx *context.Context) {
 	whitespaceBehavior := ctx.Query("whitespace")
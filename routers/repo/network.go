@@ -0,0 +1,97 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+const (
+	tplNetwork      base.TplName = "repo/network"
+	tplNetworkGraph base.TplName = "repo/network_graph"
+)
+
+// Network renders the fork network of the repository, its commit graph is loaded on demand
+func Network(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.network")
+	ctx.Data["PageIsCommits"] = true
+	ctx.Data["PageIsViewCode"] = true
+
+	repos, err := models.GetForkNetwork(ctx.Repo.Repository, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetForkNetwork", err)
+		return
+	}
+	ctx.Data["NetworkRepos"] = repos
+	ctx.HTML(200, tplNetwork)
+}
+
+// NetworkGraph renders the commit graph of the branches of the first forks of the fork network,
+// the number of forks is given by the forks query and defaults to setting.UI.NetworkMaxForkNum
+func NetworkGraph(ctx *context.Context) {
+	repos, err := models.GetForkNetwork(ctx.Repo.Repository, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetForkNetwork", err)
+		return
+	}
+
+	numForks := ctx.QueryInt("forks")
+	if numForks <= 0 {
+		numForks = setting.UI.NetworkMaxForkNum
+	}
+	if numForks < len(repos) {
+		ctx.Data["NumMoreForks"] = len(repos) - numForks
+		ctx.Data["MoreForksLink"] = fmt.Sprintf("%s/network/graph?forks=%d", ctx.Repo.RepoLink, numForks+setting.UI.NetworkMaxForkNum)
+
+		// always show the branches of the current repository
+		included := false
+		for _, repo := range repos[:numForks] {
+			if repo.ID == ctx.Repo.Repository.ID {
+				included = true
+				break
+			}
+		}
+		repos = repos[:numForks]
+		if !included {
+			repos = append(repos, ctx.Repo.Repository)
+		}
+	}
+
+	network, err := repo_service.GetNetworkRepositories(repos)
+	if err != nil {
+		ctx.ServerError("GetNetworkRepositories", err)
+		return
+	}
+
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	graph, err := repo_service.GetNetworkGraph(network, page)
+	if err != nil {
+		ctx.ServerError("GetNetworkGraph", err)
+		return
+	}
+
+	numCommits := 0
+	for _, item := range graph {
+		if !item.OnlyRelation {
+			numCommits++
+		}
+	}
+	if numCommits >= setting.UI.GraphMaxCommitNum {
+		ctx.Data["MoreCommitsLink"] = fmt.Sprintf("%s/network/graph?forks=%d&page=%d", ctx.Repo.RepoLink, numForks, page+1)
+	}
+
+	ctx.Data["Graph"] = graph
+	ctx.Data["NetworkRepos"] = network
+	ctx.HTML(200, tplNetworkGraph)
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	gotemplate "html/template"
+	"io/ioutil"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/charset"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// RenderProfileReadme renders the README of the public .profile repository of
+// the owner for the profile page. The profile is shown without it if it
+// cannot be rendered.
+func RenderProfileReadme(ctx *context.Context, owner *models.User) {
+	repo, err := models.GetRepositoryByName(owner.ID, models.ProfileRepoName)
+	if err != nil {
+		if !models.IsErrRepoNotExist(err) {
+			log.Error("GetRepositoryByName: %v", err)
+		}
+		return
+	}
+	if repo.IsPrivate || repo.IsEmpty || repo.IsBeingCreated() {
+		return
+	}
+	repo.Owner = owner
+
+	content, err := renderProfileReadme(repo)
+	if err != nil {
+		log.Error("Unable to render the profile README of %s: %v", owner.Name, err)
+		return
+	}
+	if len(content) == 0 {
+		return
+	}
+	ctx.Data["ProfileReadme"] = gotemplate.HTML(content)
+	ctx.Data["ProfileReadmeRepo"] = repo
+}
+
+func renderProfileReadme(repo *models.Repository) (string, error) {
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return "", err
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	readmeFile, err := getReadmeFileFromPath(commit, "")
+	if err != nil || readmeFile == nil || readmeFile.blob.Size() >= setting.UI.MaxDisplayFileSize {
+		return "", err
+	}
+
+	dataRc, err := readmeFile.blob.DataAsync()
+	if err != nil {
+		return "", err
+	}
+	defer dataRc.Close()
+	buf, err := ioutil.ReadAll(dataRc)
+	if err != nil {
+		return "", err
+	}
+	buf = charset.ToUTF8WithFallback(buf)
+
+	if markup.Type(readmeFile.name) != "" {
+		treeLink := repo.Link() + "/src/branch/" + util.PathEscapeSegments(repo.DefaultBranch)
+		return string(markup.Render(readmeFile.name, buf, treeLink, repo.ComposeDocumentMetas())), nil
+	}
+	return strings.Replace(gotemplate.HTMLEscapeString(string(buf)), "\n", `<br>`, -1), nil
+}
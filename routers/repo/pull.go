@@ -43,17 +43,6 @@ const (
 	pullRequestTemplateKey = "PullRequestTemplate"
 )
 
-var (
-	pullRequestTemplateCandidates = []string{
-		"PULL_REQUEST_TEMPLATE.md",
-		"pull_request_template.md",
-		".gitea/PULL_REQUEST_TEMPLATE.md",
-		".gitea/pull_request_template.md",
-		".github/PULL_REQUEST_TEMPLATE.md",
-		".github/pull_request_template.md",
-	}
-)
-
 func getRepository(ctx *context.Context, repoID int64) *models.Repository {
 	repo, err := models.GetRepositoryByID(repoID)
 	if err != nil {
@@ -327,6 +316,31 @@ func PrepareMergedViewPullInfo(ctx *context.Context, issue *models.Issue) *git.C
 	return compareInfo
 }
 
+// setPullDevEnvironment sets the links opening the head branch of an open pull request in the
+// configured IDEs, if the branch still exists
+func setPullDevEnvironment(ctx *context.Context, issue *models.Issue) {
+	pull := issue.PullRequest
+	if !setting.IDE.Enabled || issue.IsClosed || pull.HeadRepo == nil {
+		return
+	}
+	headGitRepo, err := git.OpenRepositoryCtx(context.LogContext(ctx.Context), pull.HeadRepo.RepoPath())
+	if err != nil {
+		ctx.ServerError("OpenRepository", err)
+		return
+	}
+	defer headGitRepo.Close()
+
+	commit, err := headGitRepo.GetBranchCommit(pull.HeadBranch)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return
+		}
+		ctx.ServerError("GetBranchCommit", err)
+		return
+	}
+	setDevEnvironment(ctx, pull.HeadRepo, commit, pull.HeadBranch, issue.HTMLURL())
+}
+
 // PrepareViewPullInfo show meta information for a pull request preview page
 func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.CompareInfo {
 	repo := ctx.Repo.Repository
@@ -350,7 +364,7 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 	}
 	ctx.Data["EnableStatusCheck"] = pull.ProtectedBranch != nil && pull.ProtectedBranch.EnableStatusCheck
 
-	baseGitRepo, err := git.OpenRepository(pull.BaseRepo.RepoPath())
+	baseGitRepo, err := git.OpenRepositoryCtx(context.LogContext(ctx.Context), pull.BaseRepo.RepoPath())
 	if err != nil {
 		ctx.ServerError("OpenRepository", err)
 		return nil
@@ -401,7 +415,7 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 	var headBranchSha string
 	// HeadRepo may be missing
 	if pull.HeadRepo != nil {
-		headGitRepo, err := git.OpenRepository(pull.HeadRepo.RepoPath())
+		headGitRepo, err := git.OpenRepositoryCtx(context.LogContext(ctx.Context), pull.HeadRepo.RepoPath())
 		if err != nil {
 			ctx.ServerError("OpenRepository", err)
 			return nil
@@ -565,12 +579,6 @@ func ViewPullFiles(ctx *context.Context) {
 	}
 	pull := issue.PullRequest
 
-	whitespaceFlags := map[string]string{
-		"ignore-all":    "-w",
-		"ignore-change": "-b",
-		"ignore-eol":    "--ignore-space-at-eol",
-		"":              ""}
-
 	var (
 		diffRepoPath  string
 		startCommitID string
@@ -623,7 +631,13 @@ func ViewPullFiles(ctx *context.Context) {
 		ctx.ServerError("LoadComments", err)
 		return
 	}
+	diff.DeferLargeFiles(setting.Git.MaxGitDiffLazyLines)
 
+	ctx.Data["BeforeCommitID"] = startCommitID
+	ctx.Data["DiffFileLink"] = fmt.Sprintf("%s/pulls/%d/files/diff_file", ctx.Repo.RepoLink, issue.Index)
+	if ctx.Repo.CanRead(models.UnitTypeCode) {
+		ctx.Data["RichDiffLink"] = ctx.Repo.RepoLink + "/rich_diff"
+	}
 	ctx.Data["Diff"] = diff
 	ctx.Data["DiffNotAvailable"] = diff.NumFiles == 0
 
@@ -647,6 +661,7 @@ func ViewPullFiles(ctx *context.Context) {
 
 	setImageCompareContext(ctx, baseCommit, commit)
 	setPathsCompareContext(ctx, baseCommit, commit, headTarget)
+	diff.LoadSubmodules(ctx.Repo.Repository, baseCommit, commit, ctx.User)
 
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["RequireSimpleMDE"] = true
@@ -666,6 +681,40 @@ func ViewPullFiles(ctx *context.Context) {
 	ctx.HTML(200, tplPullFiles)
 }
 
+// ViewPullFileDiff renders the diff of a single file of a pull request with its code comments
+func ViewPullFileDiff(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["PageIsPullFiles"] = true
+
+	// the readers without access to the code only get the files of the pull request
+	headCommitID, err := ctx.Repo.GitRepo.GetRefCommitID(issue.PullRequest.GetGitRefName())
+	if err != nil {
+		ctx.ServerError("GetRefCommitID", err)
+		return
+	}
+	if !ctx.Repo.CanRead(models.UnitTypeCode) &&
+		(ctx.Params("sha") != headCommitID || ctx.Query("before") != issue.PullRequest.MergeBase) {
+		ctx.NotFound("ViewPullFileDiff", nil)
+		return
+	}
+
+	if ctx.IsSigned && ctx.User != nil {
+		if ctx.Data["CanMarkConversation"], err = models.CanMarkConversation(issue, ctx.User); err != nil {
+			ctx.ServerError("CanMarkConversation", err)
+			return
+		}
+		ctx.Data["CurrentReview"], err = models.GetCurrentReview(ctx.User, issue)
+		if err != nil && !models.IsErrReviewNotExist(err) {
+			ctx.ServerError("GetCurrentReview", err)
+			return
+		}
+	}
+	renderDiffFile(ctx, issue)
+}
+
 // UpdatePullRequest merge master into PR
 func UpdatePullRequest(ctx *context.Context) {
 	issue := checkPullInfo(ctx)
@@ -776,10 +825,10 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 			ctx.ServerError("Merge PR status", err)
 			return
 		}
-		if isRepoAdmin, err := models.IsUserRepoAdmin(pr.BaseRepo, ctx.User); err != nil {
-			ctx.ServerError("IsUserRepoAdmin", err)
+		if canForceMerge, err := pull_service.IsUserAllowedToForceMerge(pr, ctx.Repo.Permission, ctx.User); err != nil {
+			ctx.ServerError("IsUserAllowedToForceMerge", err)
 			return
-		} else if !isRepoAdmin {
+		} else if !canForceMerge {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.no_merge_not_ready"))
 			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
 			return
@@ -824,7 +873,7 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 		return
 	}
 
-	if err = pull_service.Merge(pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message); err != nil {
+	if err = pull_service.Merge(pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message, form.Autosquash); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
 			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
@@ -844,6 +893,28 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.unrelated_histories"))
 			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
 			return
+		} else if models.IsErrMergeDivergingFastForwardOnly(err) {
+			log.Debug("MergeDivergingFastForwardOnly error: %v", err)
+			ctx.Flash.Error(ctx.Tr("repo.pulls.fast_forward_only_merge_pull_request_diverged"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		} else if models.IsErrMergeStyleNotAllowedForBranch(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_style_not_allowed_for_branch", pr.BaseBranch))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		} else if models.IsErrNotAllowedToMerge(err) {
+			log.Debug("MergeNotAllowed error: %v", err)
+			ctx.Flash.Error(ctx.Tr("repo.pulls.require_signed_key_not_allowed", utils.SanitizeFlashErrorString(err.(models.ErrNotAllowedToMerge).Reason)))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		} else if models.IsErrCommitMessageRejected(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.commit_message_rejected", utils.SanitizeFlashErrorString(err.(models.ErrCommitMessageRejected).Reason)))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		} else if models.IsErrContributorAgreementNotSigned(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.cla_not_signed", utils.SanitizeFlashErrorString(strings.Join(err.(models.ErrContributorAgreementNotSigned).Authors, ", "))))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
 		} else if git.IsErrPushOutOfDate(err) {
 			log.Debug("MergePushOutOfDate error: %v", err)
 			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_out_of_date"))
@@ -892,7 +963,7 @@ func CompareAndPullRequestPost(ctx *context.Context, form auth.CreateIssueForm)
 	ctx.Data["IsDiffCompare"] = true
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["PullRequestWorkInProgressPrefixes"] = setting.Repository.PullRequest.WorkInProgressPrefixes
-	renderAttachmentSettings(ctx)
+	renderIssueAttachmentSettings(ctx)
 
 	var (
 		repo        = ctx.Repo.Repository
@@ -974,6 +1045,9 @@ func CompareAndPullRequestPost(ctx *context.Context, form auth.CreateIssueForm)
 			}
 			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pullIssue.Index))
 			return
+		} else if models.IsErrIssueAttachmentsTooLarge(err) {
+			ctx.RenderWithErr(issueAttachmentsTooLargeMessage(ctx, err), tplCompareDiff, form)
+			return
 		}
 		ctx.ServerError("NewPullRequest", err)
 		return
@@ -1063,14 +1137,14 @@ func CleanUpPullRequest(ctx *context.Context) {
 
 	fullBranchName := pr.HeadRepo.Owner.Name + "/" + pr.HeadBranch
 
-	gitRepo, err := git.OpenRepository(pr.HeadRepo.RepoPath())
+	gitRepo, err := git.OpenRepositoryCtx(context.LogContext(ctx.Context), pr.HeadRepo.RepoPath())
 	if err != nil {
 		ctx.ServerError(fmt.Sprintf("OpenRepository[%s]", pr.HeadRepo.RepoPath()), err)
 		return
 	}
 	defer gitRepo.Close()
 
-	gitBaseRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	gitBaseRepo, err := git.OpenRepositoryCtx(context.LogContext(ctx.Context), pr.BaseRepo.RepoPath())
 	if err != nil {
 		ctx.ServerError(fmt.Sprintf("OpenRepository[%s]", pr.BaseRepo.RepoPath()), err)
 		return
@@ -42,6 +42,7 @@ const (
 	tplGithookEdit     base.TplName = "repo/settings/githook_edit"
 	tplDeployKeys      base.TplName = "repo/settings/deploy_keys"
 	tplProtectedBranch base.TplName = "repo/settings/protected_branch"
+	tplRenameBranch    base.TplName = "repo/settings/rename_branch"
 )
 
 var validFormAddress *regexp.Regexp
@@ -51,6 +52,13 @@ func Settings(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
 	ctx.Data["PageIsSettingsOptions"] = true
 	ctx.Data["ForcePrivate"] = setting.Repository.ForcePrivate
+
+	change, err := models.GetScheduledVisibilityChange(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetScheduledVisibilityChange", err)
+		return
+	}
+	ctx.Data["ScheduledVisibilityChange"] = change
 	ctx.HTML(200, tplSettingsOptions)
 }
 
@@ -112,13 +120,21 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 			return
 		}
 
-		repo.IsPrivate = form.Private
-		if err := models.UpdateRepository(repo, visibilityChanged); err != nil {
+		// The visibility of a repository is changed once the impact of the change has been confirmed
+		confirmVisibility := visibilityChanged && !repo.IsFork
+		if !confirmVisibility {
+			repo.IsPrivate = form.Private
+		}
+		if err := models.UpdateRepository(repo, visibilityChanged && !confirmVisibility); err != nil {
 			ctx.ServerError("UpdateRepository", err)
 			return
 		}
 		log.Trace("Repository basic settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
+		if confirmVisibility {
+			ctx.Redirect(fmt.Sprintf("%s/settings/visibility?private=%t", repo.Link(), form.Private))
+			return
+		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(repo.Link() + "/settings")
 
@@ -294,12 +310,25 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 					AllowRebase:               form.PullsAllowRebase,
 					AllowRebaseMerge:          form.PullsAllowRebaseMerge,
 					AllowSquash:               form.PullsAllowSquash,
+					AllowFastForwardOnly:      form.PullsAllowFastForwardOnly,
+					EnableDCOCheck:            form.PullsEnableDCOCheck,
+					DCOAllowlist:              strings.TrimSpace(form.PullsDCOAllowlist),
 				},
 			})
 		} else if !models.UnitTypePullRequests.UnitGlobalDisabled() {
 			deleteUnitTypes = append(deleteUnitTypes, models.UnitTypePullRequests)
 		}
 
+		if form.EnableDiscussions && !models.UnitTypeDiscussions.UnitGlobalDisabled() {
+			units = append(units, models.RepoUnit{
+				RepoID: repo.ID,
+				Type:   models.UnitTypeDiscussions,
+				Config: new(models.UnitConfig),
+			})
+		} else if !models.UnitTypeDiscussions.UnitGlobalDisabled() {
+			deleteUnitTypes = append(deleteUnitTypes, models.UnitTypeDiscussions)
+		}
+
 		if err := models.UpdateRepositoryUnits(repo, units, deleteUnitTypes); err != nil {
 			ctx.ServerError("UpdateRepositoryUnits", err)
 			return
@@ -593,7 +622,12 @@ func CollaborationPost(ctx *context.Context) {
 	}
 
 	if err = ctx.Repo.Repository.AddCollaborator(u); err != nil {
-		ctx.ServerError("AddCollaborator", err)
+		if models.IsErrBotOutOfScope(err) {
+			ctx.Flash.Error(ctx.Tr("repo.settings.add_collaborator_bot_out_of_scope"))
+			ctx.Redirect(setting.AppSubURL + ctx.Req.URL.Path)
+		} else {
+			ctx.ServerError("AddCollaborator", err)
+		}
 		return
 	}
 
@@ -791,9 +825,34 @@ func DeployKeys(ctx *context.Context) {
 	}
 	ctx.Data["Deploykeys"] = keys
 
+	loadDeployTokens(ctx)
+	if ctx.Written() {
+		return
+	}
+
 	ctx.HTML(200, tplDeployKeys)
 }
 
+// loadDeployTokens loads the deploy tokens of the repository which have not expired
+func loadDeployTokens(ctx *context.Context) {
+	ctx.Data["DeployTokensEnabled"] = setting.DeployToken.Enabled
+	if !setting.DeployToken.Enabled {
+		return
+	}
+	tokens, err := models.GetDeployTokens(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetDeployTokens", err)
+		return
+	}
+	for _, token := range tokens {
+		if err := token.LoadCreator(); err != nil {
+			ctx.ServerError("LoadCreator", err)
+			return
+		}
+	}
+	ctx.Data["DeployTokens"] = tokens
+}
+
 // DeployKeysPost response for adding a deploy key of a repository
 func DeployKeysPost(ctx *context.Context, form auth.AddKeyForm) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings.deploy_keys")
@@ -806,6 +865,11 @@ func DeployKeysPost(ctx *context.Context, form auth.AddKeyForm) {
 	}
 	ctx.Data["Deploykeys"] = keys
 
+	loadDeployTokens(ctx)
+	if ctx.Written() {
+		return
+	}
+
 	if ctx.HasError() {
 		ctx.HTML(200, tplDeployKeys)
 		return
@@ -863,6 +927,19 @@ func DeleteDeployKey(ctx *context.Context) {
 	})
 }
 
+// DeleteDeployToken response for revoking a deploy token of a repository
+func DeleteDeployToken(ctx *context.Context) {
+	if err := models.DeleteDeployToken(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteDeployToken: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.deploy_token_revocation_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/keys",
+	})
+}
+
 func init() {
 	var err error
 	validFormAddress, err = xurls.StrictMatchingScheme(`(https?)|(git)://`)
@@ -0,0 +1,125 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+)
+
+const (
+	tplAssignRules base.TplName = "repo/settings/assign_rules"
+)
+
+func loadAssignRulesData(ctx *context.Context) {
+	rules, err := models.GetIssueAssignRulesByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetIssueAssignRulesByRepoID", err)
+		return
+	}
+	for _, rule := range rules {
+		if err = rule.LoadAttributes(); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return
+		}
+	}
+	ctx.Data["AssignRules"] = rules
+
+	labels, err := models.GetLabelsByRepoID(ctx.Repo.Repository.ID, "", models.ListOptions{})
+	if err != nil {
+		ctx.ServerError("GetLabelsByRepoID", err)
+		return
+	}
+	if ctx.Repo.Owner.IsOrganization() {
+		orgLabels, err := models.GetLabelsByOrgID(ctx.Repo.Owner.ID, "", models.ListOptions{})
+		if err != nil {
+			ctx.ServerError("GetLabelsByOrgID", err)
+			return
+		}
+		labels = append(labels, orgLabels...)
+
+		teams, err := ctx.Repo.Repository.GetRepoTeams()
+		if err != nil {
+			ctx.ServerError("GetRepoTeams", err)
+			return
+		}
+		ctx.Data["Teams"] = teams
+	}
+	ctx.Data["Labels"] = labels
+}
+
+// AssignRules render the issue assignment rules of a repository
+func AssignRules(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.assign_rules")
+	ctx.Data["PageIsSettingsAssignRules"] = true
+
+	loadAssignRulesData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(200, tplAssignRules)
+}
+
+// AssignRulesPost response for adding an issue assignment rule
+func AssignRulesPost(ctx *context.Context, form auth.IssueAssignRuleForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.assign_rules")
+	ctx.Data["PageIsSettingsAssignRules"] = true
+
+	loadAssignRulesData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplAssignRules)
+		return
+	}
+
+	team, err := models.GetTeamByID(form.TeamID)
+	if err != nil || team.OrgID != ctx.Repo.Owner.ID || !team.HasRepository(ctx.Repo.Repository.ID) {
+		ctx.Data["Err_TeamID"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.assign_rules.invalid_team"), tplAssignRules, &form)
+		return
+	}
+
+	if form.LabelID > 0 {
+		label, err := models.GetLabelByID(form.LabelID)
+		if err != nil || (label.RepoID != ctx.Repo.Repository.ID && label.OrgID != ctx.Repo.Owner.ID) {
+			ctx.Data["Err_LabelID"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.assign_rules.invalid_label"), tplAssignRules, &form)
+			return
+		}
+	}
+
+	if err := models.NewIssueAssignRule(&models.IssueAssignRule{
+		RepoID:       ctx.Repo.Repository.ID,
+		Name:         form.Name,
+		LabelID:      form.LabelID,
+		PathPatterns: form.PathPatterns,
+		TeamID:       form.TeamID,
+	}); err != nil {
+		ctx.ServerError("NewIssueAssignRule", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.assign_rules.add_success", form.Name))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/assign_rules")
+}
+
+// DeleteAssignRule response for deleting an issue assignment rule
+func DeleteAssignRule(ctx *context.Context) {
+	if err := models.DeleteIssueAssignRule(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteIssueAssignRule: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.assign_rules.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/assign_rules",
+	})
+}
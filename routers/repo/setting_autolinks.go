@@ -0,0 +1,186 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+)
+
+const (
+	tplAutoLinks base.TplName = "repo/settings/autolinks"
+)
+
+// PrepareAutoLinkRules sets the data of the auto-link rules page of an organization
+// or a repository, link is the URL of the page.
+func PrepareAutoLinkRules(ctx *context.Context, rules []*models.AutoLinkRule, link string) {
+	ctx.Data["PageIsSettingsAutoLinks"] = true
+	ctx.Data["AutoLinkRules"] = rules
+	ctx.Data["AutoLinkRulesLink"] = link
+	ctx.Data["MaxAutoLinkRules"] = models.MaxAutoLinkRules
+}
+
+// PrepareEditAutoLinkRule fills the form of the auto-link rules page with a rule
+func PrepareEditAutoLinkRule(ctx *context.Context, rule *models.AutoLinkRule) {
+	ctx.Data["AutoLinkRule"] = rule
+	ctx.Data["pattern"] = rule.Pattern
+	ctx.Data["url"] = rule.URL
+	ctx.Data["sort"] = rule.Sort
+}
+
+// RenderAutoLinkRuleError renders the auto-link rules page with the error of saving a rule
+func RenderAutoLinkRuleError(ctx *context.Context, err error, tpl base.TplName, form *auth.AutoLinkRuleForm) {
+	switch {
+	case models.IsErrAutoLinkRulesLimit(err):
+		ctx.RenderWithErr(ctx.Tr("repo.settings.autolinks.limit", models.MaxAutoLinkRules), tpl, form)
+	case models.IsErrAutoLinkRuleInvalid(err):
+		if err.(models.ErrAutoLinkRuleInvalid).Field == "url" {
+			ctx.Data["Err_URL"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.autolinks.invalid_url"), tpl, form)
+		} else {
+			ctx.Data["Err_Pattern"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.autolinks.invalid_pattern"), tpl, form)
+		}
+	default:
+		ctx.ServerError("SaveAutoLinkRule", err)
+	}
+}
+
+func loadAutoLinksData(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.autolinks")
+
+	rules, err := models.GetRepoAutoLinkRules(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetRepoAutoLinkRules", err)
+		return
+	}
+	PrepareAutoLinkRules(ctx, rules, ctx.Repo.RepoLink+"/settings/autolinks")
+
+	// the rules of the organization are applied after the ones of the repository
+	if ctx.Repo.Owner.IsOrganization() {
+		orgRules, err := models.GetOrgAutoLinkRules(ctx.Repo.Owner.ID)
+		if err != nil {
+			ctx.ServerError("GetOrgAutoLinkRules", err)
+			return
+		}
+		patterns := make(map[string]bool, len(rules))
+		for _, r := range rules {
+			patterns[r.Pattern] = true
+		}
+		ctx.Data["InheritedAutoLinkRules"] = orgRules
+		ctx.Data["RepoAutoLinkPatterns"] = patterns
+	}
+}
+
+// AutoLinks render the auto-link rules of a repository
+func AutoLinks(ctx *context.Context) {
+	loadAutoLinksData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(200, tplAutoLinks)
+}
+
+// AutoLinksPost response for adding an auto-link rule
+func AutoLinksPost(ctx *context.Context, form auth.AutoLinkRuleForm) {
+	loadAutoLinksData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplAutoLinks)
+		return
+	}
+
+	rule := &models.AutoLinkRule{
+		RepoID:  ctx.Repo.Repository.ID,
+		Pattern: form.Pattern,
+		URL:     form.URL,
+		Sort:    form.Sort,
+	}
+	if err := models.NewAutoLinkRule(rule); err != nil {
+		RenderAutoLinkRuleError(ctx, err, tplAutoLinks, &form)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.autolinks.add_success", rule.Pattern))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/autolinks")
+}
+
+func getRepoAutoLinkRule(ctx *context.Context) *models.AutoLinkRule {
+	rule, err := models.GetRepoAutoLinkRule(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrAutoLinkRuleNotExist(err) {
+			ctx.NotFound("GetRepoAutoLinkRule", err)
+		} else {
+			ctx.ServerError("GetRepoAutoLinkRule", err)
+		}
+		return nil
+	}
+	return rule
+}
+
+// EditAutoLink render the form to edit an auto-link rule
+func EditAutoLink(ctx *context.Context) {
+	loadAutoLinksData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	rule := getRepoAutoLinkRule(ctx)
+	if ctx.Written() {
+		return
+	}
+	PrepareEditAutoLinkRule(ctx, rule)
+
+	ctx.HTML(200, tplAutoLinks)
+}
+
+// EditAutoLinkPost response for editing an auto-link rule
+func EditAutoLinkPost(ctx *context.Context, form auth.AutoLinkRuleForm) {
+	loadAutoLinksData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	rule := getRepoAutoLinkRule(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["AutoLinkRule"] = rule
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplAutoLinks)
+		return
+	}
+
+	rule.Pattern = form.Pattern
+	rule.URL = form.URL
+	rule.Sort = form.Sort
+	if err := models.UpdateAutoLinkRule(rule); err != nil {
+		RenderAutoLinkRuleError(ctx, err, tplAutoLinks, &form)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.autolinks.update_success", rule.Pattern))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/autolinks")
+}
+
+// DeleteAutoLink response for deleting an auto-link rule
+func DeleteAutoLink(ctx *context.Context) {
+	if err := models.DeleteRepoAutoLinkRule(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteRepoAutoLinkRule: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.autolinks.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/autolinks",
+	})
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+const (
+	tplSettingsContributorAgreement base.TplName = "repo/settings/cla"
+)
+
+func loadSettingsContributorAgreement(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.cla")
+	ctx.Data["PageIsSettingsCLA"] = true
+
+	agreement, err := models.GetContributorAgreement(ctx.Repo.Owner.ID, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetContributorAgreement", err)
+		return
+	}
+	ctx.Data["Agreement"] = agreement
+
+	if agreement == nil && ctx.Repo.Owner.IsOrganization() {
+		orgAgreement, err := models.GetContributorAgreement(ctx.Repo.Owner.ID, 0)
+		if err != nil {
+			ctx.ServerError("GetContributorAgreement", err)
+			return
+		}
+		ctx.Data["OrgAgreement"] = orgAgreement
+	}
+}
+
+// SettingsContributorAgreement render the contributor license agreement of a repository
+func SettingsContributorAgreement(ctx *context.Context) {
+	loadSettingsContributorAgreement(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if agreement, ok := ctx.Data["Agreement"].(*models.ContributorAgreement); ok && agreement != nil {
+		ctx.Data["title"] = agreement.Title
+		ctx.Data["content"] = agreement.Content
+	}
+
+	ctx.HTML(200, tplSettingsContributorAgreement)
+}
+
+// SettingsContributorAgreementPost response for changing the contributor license agreement of
+// a repository
+func SettingsContributorAgreementPost(ctx *context.Context, form auth.ContributorAgreementForm) {
+	loadSettingsContributorAgreement(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsContributorAgreement)
+		return
+	}
+
+	agreement := &models.ContributorAgreement{
+		OwnerID: ctx.Repo.Owner.ID,
+		RepoID:  ctx.Repo.Repository.ID,
+		Title:   strings.TrimSpace(form.Title),
+		Content: form.Content,
+	}
+	if err := models.SaveContributorAgreement(agreement); err != nil {
+		ctx.ServerError("SaveContributorAgreement", err)
+		return
+	}
+	go pull_service.RecheckContributorAgreement(agreement, ctx.User)
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.cla.update_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/cla")
+}
+
+// DeleteSettingsContributorAgreement removes the contributor license agreement of a repository
+func DeleteSettingsContributorAgreement(ctx *context.Context) {
+	if err := models.DeleteContributorAgreement(ctx.Repo.Owner.ID, ctx.Repo.Repository.ID); err != nil {
+		ctx.ServerError("DeleteContributorAgreement", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.cla.deletion_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/cla")
+}
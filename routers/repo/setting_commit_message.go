@@ -0,0 +1,114 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+)
+
+const (
+	tplCommitMessages base.TplName = "repo/settings/commit_messages"
+)
+
+func loadCommitMessagePolicyData(ctx *context.Context) {
+	users, err := ctx.Repo.Repository.GetWriters()
+	if err != nil {
+		ctx.ServerError("GetWriters", err)
+		return
+	}
+	ctx.Data["Users"] = users
+
+	if ctx.Repo.Owner.IsOrganization() {
+		teams, err := ctx.Repo.Owner.TeamsWithAccessToRepo(ctx.Repo.Repository.ID, models.AccessModeRead)
+		if err != nil {
+			ctx.ServerError("TeamsWithAccessToRepo", err)
+			return
+		}
+		ctx.Data["Teams"] = teams
+	}
+}
+
+// CommitMessagePolicy render the commit message policy of a repository
+func CommitMessagePolicy(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.commit_messages")
+	ctx.Data["PageIsSettingsCommitMessages"] = true
+
+	loadCommitMessagePolicyData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	policy, err := models.GetCommitMessagePolicy(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetCommitMessagePolicy", err)
+		return
+	}
+	if policy == nil {
+		policy = &models.CommitMessagePolicy{}
+	}
+	ctx.Data["max_subject_length"] = policy.MaxSubjectLength
+	ctx.Data["patterns"] = policy.Patterns
+	ctx.Data["required_trailers"] = policy.RequiredTrailers
+	ctx.Data["bypass_users"] = strings.Join(base.Int64sToStrings(policy.BypassUserIDs), ",")
+	ctx.Data["bypass_teams"] = strings.Join(base.Int64sToStrings(policy.BypassTeamIDs), ",")
+
+	ctx.HTML(200, tplCommitMessages)
+}
+
+// CommitMessagePolicyPost response for changing the commit message policy of a repository
+func CommitMessagePolicyPost(ctx *context.Context, form auth.CommitMessagePolicyForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.commit_messages")
+	ctx.Data["PageIsSettingsCommitMessages"] = true
+
+	loadCommitMessagePolicyData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplCommitMessages)
+		return
+	}
+
+	policy := &models.CommitMessagePolicy{
+		MaxSubjectLength: form.MaxSubjectLength,
+		Patterns:         strings.TrimSpace(form.Patterns),
+		RequiredTrailers: strings.TrimSpace(form.RequiredTrailers),
+	}
+	if policy.IsEmpty() {
+		if err := models.DeleteCommitMessagePolicy(ctx.Repo.Repository.ID); err != nil {
+			ctx.ServerError("DeleteCommitMessagePolicy", err)
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("repo.settings.commit_messages.update_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings/commit_messages")
+		return
+	}
+
+	if len(form.BypassUsers) > 0 {
+		policy.BypassUserIDs, _ = base.StringsToInt64s(strings.Split(form.BypassUsers, ","))
+	}
+	if len(form.BypassTeams) > 0 {
+		policy.BypassTeamIDs, _ = base.StringsToInt64s(strings.Split(form.BypassTeams, ","))
+	}
+
+	if err := models.UpdateCommitMessagePolicy(ctx.Repo.Repository, policy); err != nil {
+		if models.IsErrInvalidCommitMessagePattern(err) {
+			ctx.Data["Err_Patterns"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.commit_messages.invalid_pattern", err.(models.ErrInvalidCommitMessagePattern).Pattern), tplCommitMessages, &form)
+			return
+		}
+		ctx.ServerError("UpdateCommitMessagePolicy", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.commit_messages.update_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/commit_messages")
+}
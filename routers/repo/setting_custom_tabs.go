@@ -0,0 +1,195 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+)
+
+const (
+	tplCustomTabs base.TplName = "repo/settings/custom_tabs"
+)
+
+// customTabIcons are the icons offered for the custom tabs, the API accepts any octicon
+var customTabIcons = []string{
+	models.DefaultRepoCustomTabIcon,
+	"octicon-book",
+	"octicon-browser",
+	"octicon-bug",
+	"octicon-checklist",
+	"octicon-comment-discussion",
+	"octicon-gear",
+	"octicon-globe",
+	"octicon-graph",
+	"octicon-package",
+	"octicon-project",
+	"octicon-pulse",
+	"octicon-rocket",
+	"octicon-server",
+	"octicon-shield",
+}
+
+// customTabIconsWith returns the icons offered for the custom tabs including the
+// icon of a tab set through the API
+func customTabIconsWith(icon string) []string {
+	for _, i := range customTabIcons {
+		if i == icon {
+			return customTabIcons
+		}
+	}
+	return append([]string{icon}, customTabIcons...)
+}
+
+func loadCustomTabsData(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.custom_tabs")
+	ctx.Data["PageIsSettingsCustomTabs"] = true
+	ctx.Data["CustomTabIcons"] = customTabIcons
+	ctx.Data["MaxCustomTabs"] = models.MaxRepoCustomTabs
+
+	tabs, err := models.GetRepoCustomTabs(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetRepoCustomTabs", err)
+		return
+	}
+	ctx.Data["CustomTabs"] = tabs
+}
+
+// CustomTabs render the custom tabs of a repository
+func CustomTabs(ctx *context.Context) {
+	loadCustomTabsData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(200, tplCustomTabs)
+}
+
+// CustomTabsPost response for adding a custom tab
+func CustomTabsPost(ctx *context.Context, form auth.RepoCustomTabForm) {
+	loadCustomTabsData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplCustomTabs)
+		return
+	}
+
+	tab := &models.RepoCustomTab{
+		RepoID: ctx.Repo.Repository.ID,
+		Name:   form.Name,
+		URL:    form.URL,
+		Icon:   form.Icon,
+		Sort:   form.Sort,
+	}
+	if err := models.NewRepoCustomTab(tab); err != nil {
+		renderCustomTabError(ctx, err, &form)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.custom_tabs.add_success", tab.Name))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/tabs")
+}
+
+// EditCustomTab render the form to edit a custom tab
+func EditCustomTab(ctx *context.Context) {
+	loadCustomTabsData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	tab, err := models.GetRepoCustomTab(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrRepoCustomTabNotExist(err) {
+			ctx.NotFound("GetRepoCustomTab", err)
+		} else {
+			ctx.ServerError("GetRepoCustomTab", err)
+		}
+		return
+	}
+	ctx.Data["CustomTab"] = tab
+	ctx.Data["CustomTabIcons"] = customTabIconsWith(tab.Icon)
+	ctx.Data["name"] = tab.Name
+	ctx.Data["url"] = tab.URL
+	ctx.Data["icon"] = tab.Icon
+	ctx.Data["sort"] = tab.Sort
+
+	ctx.HTML(200, tplCustomTabs)
+}
+
+// EditCustomTabPost response for editing a custom tab
+func EditCustomTabPost(ctx *context.Context, form auth.RepoCustomTabForm) {
+	loadCustomTabsData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	tab, err := models.GetRepoCustomTab(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrRepoCustomTabNotExist(err) {
+			ctx.NotFound("GetRepoCustomTab", err)
+		} else {
+			ctx.ServerError("GetRepoCustomTab", err)
+		}
+		return
+	}
+	ctx.Data["CustomTab"] = tab
+	ctx.Data["CustomTabIcons"] = customTabIconsWith(tab.Icon)
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplCustomTabs)
+		return
+	}
+
+	tab.Name = form.Name
+	tab.URL = form.URL
+	tab.Icon = form.Icon
+	tab.Sort = form.Sort
+	if err := models.UpdateRepoCustomTab(tab); err != nil {
+		renderCustomTabError(ctx, err, &form)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.custom_tabs.update_success", tab.Name))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/tabs")
+}
+
+func renderCustomTabError(ctx *context.Context, err error, form *auth.RepoCustomTabForm) {
+	switch {
+	case models.IsErrRepoCustomTabsLimit(err):
+		ctx.RenderWithErr(ctx.Tr("repo.settings.custom_tabs.limit", models.MaxRepoCustomTabs), tplCustomTabs, form)
+	case models.IsErrRepoCustomTabInvalid(err):
+		switch err.(models.ErrRepoCustomTabInvalid).Field {
+		case "url":
+			ctx.Data["Err_URL"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.custom_tabs.invalid_url"), tplCustomTabs, form)
+		case "icon":
+			ctx.Data["Err_Icon"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.custom_tabs.invalid_icon"), tplCustomTabs, form)
+		default:
+			ctx.Data["Err_Name"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.custom_tabs.name")+ctx.Tr("form.require_error"), tplCustomTabs, form)
+		}
+	default:
+		ctx.ServerError("SaveRepoCustomTab", err)
+	}
+}
+
+// DeleteCustomTab response for deleting a custom tab
+func DeleteCustomTab(ctx *context.Context) {
+	if err := models.DeleteRepoCustomTab(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteRepoCustomTab: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.custom_tabs.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/tabs",
+	})
+}
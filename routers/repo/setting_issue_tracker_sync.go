@@ -0,0 +1,140 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/issuetracker"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplIssueTrackerSync base.TplName = "repo/settings/issue_tracker_sync"
+
+	// issueTrackerSyncRecentEvents is the number of events shown on the settings page
+	issueTrackerSyncRecentEvents = 10
+)
+
+// IssueTrackerSyncEnabled checks that the issue tracker sync is enabled
+func IssueTrackerSyncEnabled(ctx *context.Context) {
+	if !setting.IssueTrackerSync.Enabled {
+		ctx.NotFound("IssueTrackerSyncEnabled", nil)
+	}
+}
+
+func loadIssueTrackerSyncData(ctx *context.Context) *models.IssueTrackerSync {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.issue_tracker_sync")
+	ctx.Data["PageIsSettingsIssueTrackerSync"] = true
+	ctx.Data["IssueTrackerTypes"] = []issuetracker.Type{issuetracker.TypeJira, issuetracker.TypeRedmine}
+
+	trackerSync, err := models.GetIssueTrackerSync(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetIssueTrackerSync", err)
+		return nil
+	}
+	ctx.Data["IssueTrackerSync"] = trackerSync
+
+	if trackerSync != nil {
+		events, err := models.GetRecentIssueTrackerEvents(ctx.Repo.Repository.ID, issueTrackerSyncRecentEvents)
+		if err != nil {
+			ctx.ServerError("GetRecentIssueTrackerEvents", err)
+			return nil
+		}
+		ctx.Data["IssueTrackerEvents"] = events
+	}
+	return trackerSync
+}
+
+// IssueTrackerSync render the issue tracker sync of a repository
+func IssueTrackerSync(ctx *context.Context) {
+	trackerSync := loadIssueTrackerSyncData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if trackerSync == nil {
+		trackerSync = &models.IssueTrackerSync{
+			Type:                  issuetracker.TypeJira,
+			CommentOnCommits:      true,
+			CommentOnPullRequests: true,
+		}
+	}
+	ctx.Data["type"] = trackerSync.Type
+	ctx.Data["url"] = trackerSync.URL
+	ctx.Data["project_key"] = trackerSync.ProjectKey
+	ctx.Data["username"] = trackerSync.Username
+	ctx.Data["comment_on_commits"] = trackerSync.CommentOnCommits
+	ctx.Data["comment_on_pull_requests"] = trackerSync.CommentOnPullRequests
+	ctx.Data["require_reference"] = trackerSync.RequireReference
+	ctx.Data["verify_reference"] = trackerSync.VerifyReference
+
+	ctx.HTML(200, tplIssueTrackerSync)
+}
+
+// IssueTrackerSyncPost response for changing the issue tracker sync of a repository
+func IssueTrackerSyncPost(ctx *context.Context, form auth.IssueTrackerSyncForm) {
+	trackerSync := loadIssueTrackerSyncData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplIssueTrackerSync)
+		return
+	}
+
+	if trackerSync == nil && form.Token == "" {
+		ctx.Data["Err_Token"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.issue_tracker_sync.token")+ctx.Tr("form.require_error"), tplIssueTrackerSync, &form)
+		return
+	}
+
+	if err := models.SaveIssueTrackerSync(&models.IssueTrackerSync{
+		RepoID:                ctx.Repo.Repository.ID,
+		Type:                  issuetracker.Type(form.Type),
+		URL:                   form.URL,
+		ProjectKey:            form.ProjectKey,
+		Username:              form.Username,
+		Token:                 form.Token,
+		CommentOnCommits:      form.CommentOnCommits,
+		CommentOnPullRequests: form.CommentOnPullRequests,
+		RequireReference:      form.RequireReference,
+		VerifyReference:       form.VerifyReference,
+	}); err != nil {
+		if models.IsErrIssueTrackerSyncInvalid(err) {
+			switch err.(models.ErrIssueTrackerSyncInvalid).Field {
+			case "url":
+				ctx.Data["Err_URL"] = true
+			case "project_key":
+				ctx.Data["Err_ProjectKey"] = true
+			default:
+				ctx.Data["Err_Type"] = true
+			}
+			ctx.RenderWithErr(ctx.Tr("repo.settings.issue_tracker_sync.invalid_"+err.(models.ErrIssueTrackerSyncInvalid).Field), tplIssueTrackerSync, &form)
+			return
+		}
+		ctx.ServerError("SaveIssueTrackerSync", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.issue_tracker_sync.update_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/issue_tracker_sync")
+}
+
+// DeleteIssueTrackerSync response for removing the issue tracker sync of a repository
+func DeleteIssueTrackerSync(ctx *context.Context) {
+	if err := models.DeleteIssueTrackerSync(ctx.Repo.Repository.ID); err != nil {
+		ctx.Flash.Error("DeleteIssueTrackerSync: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.issue_tracker_sync.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/issue_tracker_sync",
+	})
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+
+	"github.com/unknwon/i18n"
+)
+
+const (
+	tplSettingsLocale base.TplName = "repo/settings/locale"
+)
+
+// PrepareLocaleSetting sets the data of the form of the default language and formats of an
+// organization or a repository, the inherited setting is used for the empty fields.
+func PrepareLocaleSetting(ctx *context.Context, s, inherited *models.LocaleSetting) {
+	ctx.Data["PageIsSettingsLocale"] = true
+	ctx.Data["locale_language"] = s.Language
+	if s.Language != "" {
+		ctx.Data["locale_language_name"] = i18n.GetDescriptionByLang(s.Language)
+	}
+	ctx.Data["locale_date_format"] = s.DateFormat
+	ctx.Data["locale_number_format"] = s.NumberFormat
+
+	if inherited.Language != "" {
+		ctx.Data["InheritedLanguage"] = i18n.GetDescriptionByLang(inherited.Language)
+	} else {
+		ctx.Data["InheritedLanguage"] = ctx.Tr("settings.locale.viewer_language")
+	}
+	ctx.Data["InheritedDateFormat"] = inherited.GetDateFormat()
+	ctx.Data["InheritedNumberFormat"] = inherited.NumberFormat
+	if inherited.NumberFormat == "" {
+		ctx.Data["InheritedNumberFormat"] = base.DefaultNumberPattern
+	}
+
+	preview := *s
+	preview.Merge(inherited)
+	ctx.Data["LocalePreview"] = &preview
+	ctx.Data["LocalePreviewTime"] = time.Now()
+}
+
+// SaveLocaleSetting saves the default language and formats of an organization or a
+// repository from the form, it returns false if the form was rendered again with an error.
+func SaveLocaleSetting(ctx *context.Context, form auth.LocaleSettingForm, tpl base.TplName, inherited *models.LocaleSetting, update func(*models.LocaleSetting) error) bool {
+	s := &models.LocaleSetting{
+		Language:     form.Language,
+		DateFormat:   form.DateFormat,
+		NumberFormat: form.NumberFormat,
+	}
+	PrepareLocaleSetting(ctx, s, inherited)
+	if ctx.HasError() {
+		ctx.HTML(200, tpl)
+		return false
+	}
+
+	if err := update(s); err != nil {
+		if models.IsErrInvalidLocaleSetting(err) {
+			switch err.(models.ErrInvalidLocaleSetting).Field {
+			case "language":
+				ctx.Data["Err_Language"] = true
+				ctx.RenderWithErr(ctx.Tr("settings.locale.invalid_language"), tpl, &form)
+			case "date_format":
+				ctx.Data["Err_DateFormat"] = true
+				ctx.RenderWithErr(ctx.Tr("settings.locale.invalid_date_format"), tpl, &form)
+			default:
+				ctx.Data["Err_NumberFormat"] = true
+				ctx.RenderWithErr(ctx.Tr("settings.locale.invalid_number_format"), tpl, &form)
+			}
+			return false
+		}
+		ctx.ServerError("UpdateLocaleSetting", err)
+		return false
+	}
+
+	ctx.Flash.Success(ctx.Tr("settings.locale.update_success"))
+	return true
+}
+
+// inheritedLocaleSetting returns the locale setting of the organization of the repository
+func inheritedLocaleSetting(ctx *context.Context) *models.LocaleSetting {
+	if ctx.Repo.Owner.IsOrganization() {
+		s, err := models.GetOrgLocaleSetting(ctx.Repo.Owner.ID)
+		if err != nil {
+			ctx.ServerError("GetOrgLocaleSetting", err)
+			return nil
+		}
+		if s != nil {
+			return s
+		}
+	}
+	return &models.LocaleSetting{}
+}
+
+// SettingsLocale render the default language and formats of a repository
+func SettingsLocale(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.locale")
+
+	inherited := inheritedLocaleSetting(ctx)
+	if ctx.Written() {
+		return
+	}
+	s, err := models.GetRepoLocaleSetting(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetRepoLocaleSetting", err)
+		return
+	}
+	if s == nil {
+		s = &models.LocaleSetting{}
+	}
+	PrepareLocaleSetting(ctx, s, inherited)
+
+	ctx.HTML(200, tplSettingsLocale)
+}
+
+// SettingsLocalePost response for changing the default language and formats of a repository
+func SettingsLocalePost(ctx *context.Context, form auth.LocaleSettingForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.locale")
+
+	inherited := inheritedLocaleSetting(ctx)
+	if ctx.Written() {
+		return
+	}
+	if SaveLocaleSetting(ctx, form, tplSettingsLocale, inherited, func(s *models.LocaleSetting) error {
+		return models.UpdateRepoLocaleSetting(ctx.Repo.Repository.ID, s)
+	}) {
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings/locale")
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	pages_service "code.gitea.io/gitea/services/pages"
+)
+
+const (
+	tplSettingsPages base.TplName = "repo/settings/pages"
+)
+
+func loadSettingsPages(ctx *context.Context) {
+	if !setting.Pages.Enabled {
+		ctx.NotFound("SettingsPages", nil)
+		return
+	}
+	ctx.Data["Title"] = ctx.Tr("repo.settings.pages")
+	ctx.Data["PageIsSettingsPages"] = true
+	ctx.Data["EnableCustomDomains"] = setting.Pages.EnableCustomDomains
+
+	site, err := pages_service.GetSite(ctx.Repo.Repository)
+	if err != nil {
+		ctx.ServerError("GetSite", err)
+		return
+	}
+	ctx.Data["Site"] = site
+	if site != nil && site.Config.CustomDomain != "" {
+		ctx.Data["DomainVerifyRecordName"] = pages_service.DomainVerifyRecordName(site.Config.CustomDomain)
+	}
+}
+
+// SettingsPages render the static site settings of a repository
+func SettingsPages(ctx *context.Context) {
+	loadSettingsPages(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if site, ok := ctx.Data["Site"].(*pages_service.Site); ok && site != nil {
+		ctx.Data["source"] = "branch"
+		if site.Config.IsArtifact() {
+			ctx.Data["source"] = "artifact"
+		}
+		ctx.Data["branch"] = site.Config.Branch
+		ctx.Data["dir"] = site.Config.Dir
+		ctx.Data["artifact_name"] = site.Config.ArtifactName
+		ctx.Data["custom_domain"] = site.Config.CustomDomain
+	} else {
+		ctx.Data["source"] = "branch"
+		ctx.Data["branch"] = setting.Pages.DefaultBranch
+	}
+
+	ctx.HTML(200, tplSettingsPages)
+}
+
+// SettingsPagesPost response for changing the static site settings of a repository
+func SettingsPagesPost(ctx *context.Context, form auth.RepoPagesForm) {
+	loadSettingsPages(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsPages)
+		return
+	}
+
+	cfg := &models.PagesConfig{
+		RepoID:       ctx.Repo.Repository.ID,
+		Branch:       strings.TrimSpace(form.Branch),
+		Dir:          strings.Trim(strings.TrimSpace(form.Dir), "/"),
+		ArtifactName: strings.TrimSpace(form.ArtifactName),
+	}
+	if setting.Pages.EnableCustomDomains {
+		cfg.CustomDomain = form.CustomDomain
+	}
+	if form.Source == "artifact" {
+		cfg.Source = models.PagesSourceArtifact
+		if cfg.ArtifactName == "" {
+			ctx.Data["Err_ArtifactName"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.pages.artifact_name_required"), tplSettingsPages, &form)
+			return
+		}
+	} else {
+		cfg.Source = models.PagesSourceBranch
+		if cfg.Branch == "" {
+			cfg.Branch = setting.Pages.DefaultBranch
+		}
+	}
+
+	if err := models.SavePagesConfig(cfg); err != nil {
+		if models.IsErrPagesDomainAlreadyUsed(err) {
+			ctx.Data["Err_CustomDomain"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.pages.domain_already_used"), tplSettingsPages, &form)
+			return
+		}
+		ctx.ServerError("SavePagesConfig", err)
+		return
+	}
+	// The source of the site may have changed
+	pages_service.InvalidateCache(ctx.Repo.Repository.ID)
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.pages.update_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/pages")
+}
+
+// SettingsPagesVerifyDomain checks the DNS record proving the ownership of the custom domain of the static site
+func SettingsPagesVerifyDomain(ctx *context.Context) {
+	loadSettingsPages(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	site, ok := ctx.Data["Site"].(*pages_service.Site)
+	if !ok || site == nil || !setting.Pages.EnableCustomDomains {
+		ctx.NotFound("SettingsPagesVerifyDomain", nil)
+		return
+	}
+
+	verified, err := pages_service.VerifyCustomDomain(site)
+	if err != nil {
+		ctx.ServerError("VerifyCustomDomain", err)
+		return
+	}
+	if verified {
+		ctx.Flash.Success(ctx.Tr("repo.settings.pages.domain_verified", site.Config.CustomDomain))
+	} else {
+		ctx.Flash.Error(ctx.Tr("repo.settings.pages.domain_not_verified", pages_service.DomainVerifyRecordName(site.Config.CustomDomain)))
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/pages")
+}
+
+// DeleteSettingsPages removes the static site of a repository
+func DeleteSettingsPages(ctx *context.Context) {
+	if err := models.DeletePagesConfig(ctx.Repo.Repository.ID); err != nil {
+		ctx.ServerError("DeletePagesConfig", err)
+		return
+	}
+	pages_service.InvalidateCache(ctx.Repo.Repository.ID)
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.pages.deletion_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/pages")
+}
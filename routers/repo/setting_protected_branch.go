@@ -16,6 +16,8 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	pull_service "code.gitea.io/gitea/services/pull"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // ProtectedBranch render the page to protect the repository
@@ -127,7 +129,12 @@ func SettingsProtectedBranch(c *context.Context) {
 	c.Data["merge_whitelist_users"] = strings.Join(base.Int64sToStrings(protectBranch.MergeWhitelistUserIDs), ",")
 	c.Data["approvals_whitelist_users"] = strings.Join(base.Int64sToStrings(protectBranch.ApprovalsWhitelistUserIDs), ",")
 	contexts, _ := models.FindRepoRecentCommitStatusContexts(c.Repo.Repository.ID, 7*24*time.Hour) // Find last week status check contexts
-	for _, context := range protectBranch.StatusCheckContexts {
+	knownContexts := protectBranch.StatusCheckContexts
+	if prUnit, err := c.Repo.Repository.GetUnit(models.UnitTypePullRequests); err == nil && prUnit.PullRequestsConfig().EnableDCOCheck {
+		// offer the DCO check before it has reported any status
+		knownContexts = append([]string{pull_service.DCOStatusContext}, knownContexts...)
+	}
+	for _, context := range knownContexts {
 		var found bool
 		for _, ctx := range contexts {
 			if ctx == context {
@@ -162,10 +169,36 @@ func SettingsProtectedBranch(c *context.Context) {
 		c.Data["approvals_whitelist_teams"] = strings.Join(base.Int64sToStrings(protectBranch.ApprovalsWhitelistTeamIDs), ",")
 	}
 
+	PrepareMergeStyles(c, protectBranch.MergeStyles)
 	c.Data["Branch"] = protectBranch
 	c.HTML(200, tplProtectedBranch)
 }
 
+// PrepareMergeStyles sets the data of the allowed merge styles field of the
+// branch protection and the ruleset forms, selected are the allowed styles.
+func PrepareMergeStyles(ctx *context.Context, selected []string) {
+	allowed := make(map[string]bool, len(selected))
+	for _, style := range selected {
+		allowed[style] = true
+	}
+	ctx.Data["MergeStyles"] = models.MergeStyles
+	ctx.Data["allowed_merge_styles"] = allowed
+}
+
+// FilterMergeStyles returns the valid merge styles of a form without duplicates
+func FilterMergeStyles(styles []string) []string {
+	result := make([]string, 0, len(styles))
+	for _, style := range models.MergeStyles {
+		for _, s := range styles {
+			if s == string(style) {
+				result = append(result, s)
+				break
+			}
+		}
+	}
+	return result
+}
+
 // SettingsProtectedBranchPost updates the protected branch settings
 func SettingsProtectedBranchPost(ctx *context.Context, f auth.ProtectBranchForm) {
 	branch := ctx.Params("*")
@@ -247,8 +280,10 @@ func SettingsProtectedBranchPost(ctx *context.Context, f auth.ProtectBranchForm)
 		protectBranch.BlockOnRejectedReviews = f.BlockOnRejectedReviews
 		protectBranch.DismissStaleApprovals = f.DismissStaleApprovals
 		protectBranch.RequireSignedCommits = f.RequireSignedCommits
+		protectBranch.SignedCommitsAllowedKeys = strings.TrimSpace(f.SignedCommitsAllowedKeys)
 		protectBranch.ProtectedFilePatterns = f.ProtectedFilePatterns
 		protectBranch.BlockOnOutdatedBranch = f.BlockOnOutdatedBranch
+		protectBranch.MergeStyles = FilterMergeStyles(f.MergeStyles)
 
 		err = models.UpdateProtectBranch(ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
 			UserIDs:          whitelistUsers,
@@ -275,3 +310,94 @@ func SettingsProtectedBranchPost(ctx *context.Context, f auth.ProtectBranchForm)
 		ctx.Redirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
 	}
 }
+
+// prepareRenameBranch loads what renaming the default branch affects
+func prepareRenameBranch(ctx *context.Context) {
+	repo := ctx.Repo.Repository
+	ctx.Data["Title"] = ctx.Tr("repo.settings.rename_branch")
+	ctx.Data["PageIsSettingsBranches"] = true
+	ctx.Data["FromBranch"] = repo.DefaultBranch
+	ctx.Data["RenamedBranchNoticeDays"] = int(renamedBranchNoticeDuration.Hours() / 24)
+
+	pulls, err := models.GetUnmergedPullRequestsByBaseInfo(repo.ID, repo.DefaultBranch)
+	if err != nil {
+		ctx.ServerError("GetUnmergedPullRequestsByBaseInfo", err)
+		return
+	}
+	for _, pr := range pulls {
+		if err := pr.LoadIssue(); err != nil {
+			ctx.ServerError("LoadIssue", err)
+			return
+		}
+	}
+	ctx.Data["OpenPulls"] = pulls
+
+	protectedBranch, err := models.GetProtectedBranchBy(repo.ID, repo.DefaultBranch)
+	if err != nil {
+		ctx.ServerError("GetProtectedBranchBy", err)
+		return
+	}
+	ctx.Data["IsProtected"] = protectedBranch != nil
+
+	webhooks, err := models.GetWebhooksByRepoID(repo.ID, models.ListOptions{})
+	if err != nil {
+		ctx.ServerError("GetWebhooksByRepoID", err)
+		return
+	}
+	referencingWebhooks := make([]*models.Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		if w.ReferencesBranch(repo.DefaultBranch) {
+			referencingWebhooks = append(referencingWebhooks, w)
+		}
+	}
+	ctx.Data["Webhooks"] = referencingWebhooks
+}
+
+// RenameBranch renders the page to rename the default branch of a repository
+func RenameBranch(ctx *context.Context) {
+	if ctx.Repo.Repository.IsMirror {
+		ctx.NotFound("RenameBranch", nil)
+		return
+	}
+	prepareRenameBranch(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(200, tplRenameBranch)
+}
+
+// RenameBranchPost response for renaming the default branch of a repository
+func RenameBranchPost(ctx *context.Context, form auth.RenameBranchForm) {
+	repo := ctx.Repo.Repository
+	if repo.IsMirror {
+		ctx.NotFound("RenameBranch", nil)
+		return
+	}
+	prepareRenameBranch(ctx)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() {
+		ctx.HTML(200, tplRenameBranch)
+		return
+	}
+
+	from := repo.DefaultBranch
+	if err := repo_service.RenameBranch(ctx.User, repo, ctx.Repo.GitRepo, from, form.NewBranchName); err != nil {
+		ctx.Data["Err_NewBranchName"] = true
+		if models.IsErrBranchAlreadyExists(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.branch.branch_already_exists", form.NewBranchName), tplRenameBranch, &form)
+		} else if models.IsErrBranchNameConflict(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.branch.branch_name_conflict", form.NewBranchName, err.(models.ErrBranchNameConflict).BranchName), tplRenameBranch, &form)
+		} else if models.IsErrTagAlreadyExists(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.branch.tag_collision", form.NewBranchName), tplRenameBranch, &form)
+		} else {
+			ctx.ServerError("RenameBranch", err)
+		}
+		return
+	}
+
+	log.Trace("Default branch of repository %s/%s renamed from %s to %s", ctx.Repo.Owner.Name, repo.Name, from, form.NewBranchName)
+	ctx.Flash.Success(ctx.Tr("repo.settings.rename_branch_success", from, form.NewBranchName))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/branches")
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplRawHeaders base.TplName = "repo/settings/raw_headers"
+)
+
+// RawHeadersEnabled makes the raw headers settings return 404 unless the repositories may
+// change them
+func RawHeadersEnabled(ctx *context.Context) {
+	if !setting.Repository.Raw.AllowRepoHeaders {
+		ctx.NotFound("RawHeadersEnabled", nil)
+	}
+}
+
+func loadRawHeadersData(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.raw_headers")
+	ctx.Data["PageIsSettingsRawHeaders"] = true
+	ctx.Data["RobotsPolicies"] = models.RawRobotsPolicies
+	ctx.Data["DefaultCacheMaxAge"] = setting.Repository.Raw.CacheMaxAge
+	if len(setting.Repository.Raw.AllowedOrigins) > 0 {
+		ctx.Data["DefaultAllowedOrigins"] = strings.Join(setting.Repository.Raw.AllowedOrigins, ", ")
+	} else {
+		ctx.Data["DefaultAllowedOrigins"] = ctx.Tr("repo.settings.raw_headers.no_origins")
+	}
+}
+
+// SettingsRawHeaders render the headers of the raw files of a repository
+func SettingsRawHeaders(ctx *context.Context) {
+	loadRawHeadersData(ctx)
+
+	headers, err := models.GetRepoRawHeaders(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetRepoRawHeaders", err)
+		return
+	}
+	if headers == nil {
+		headers = &models.RepoRawHeaders{}
+	}
+	ctx.Data["allowed_origins"] = headers.AllowedOrigins
+	ctx.Data["cache_max_age"] = headers.CacheMaxAge
+	ctx.Data["robots_policy"] = headers.RobotsPolicy
+
+	ctx.HTML(200, tplRawHeaders)
+}
+
+// SettingsRawHeadersPost response for changing the headers of the raw files of a repository
+func SettingsRawHeadersPost(ctx *context.Context, form auth.RepoRawHeadersForm) {
+	loadRawHeadersData(ctx)
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplRawHeaders)
+		return
+	}
+
+	headers := &models.RepoRawHeaders{
+		AllowedOrigins: form.AllowedOrigins,
+		CacheMaxAge:    form.CacheMaxAge,
+		RobotsPolicy:   form.RobotsPolicy,
+	}
+	if err := models.UpdateRepoRawHeaders(ctx.Repo.Repository.ID, headers); err != nil {
+		if models.IsErrInvalidRawOrigin(err) {
+			ctx.Data["Err_AllowedOrigins"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.raw_headers.invalid_origin", err.(models.ErrInvalidRawOrigin).Origin), tplRawHeaders, &form)
+			return
+		}
+		ctx.ServerError("UpdateRepoRawHeaders", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.raw_headers.update_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/raw_headers")
+}
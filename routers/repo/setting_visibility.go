@@ -0,0 +1,143 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+const (
+	tplSettingsVisibility base.TplName = "repo/settings/visibility"
+)
+
+// loadSettingsVisibility loads the impact of changing the visibility of the repository to private
+// and the change of visibility scheduled for it
+func loadSettingsVisibility(ctx *context.Context, private bool) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.visibility")
+	ctx.Data["PageIsSettingsOptions"] = true
+
+	repo := ctx.Repo.Repository
+	// Visibility of forked repository is forced sync with base repository.
+	if repo.IsFork {
+		ctx.NotFound("SettingsVisibility", nil)
+		return
+	}
+	// when ForcePrivate enabled, only admin users can change private to public
+	if !private && setting.Repository.ForcePrivate && !ctx.User.IsAdmin {
+		ctx.Flash.Error(ctx.Tr("repo.settings.visibility.force_private"))
+		ctx.Redirect(repo.Link() + "/settings")
+		return
+	}
+
+	impact, err := models.GetVisibilityChangeImpact(repo, private)
+	if err != nil {
+		ctx.ServerError("GetVisibilityChangeImpact", err)
+		return
+	}
+	ctx.Data["Impact"] = impact
+	ctx.Data["Private"] = private
+
+	change, err := models.GetScheduledVisibilityChange(repo.ID)
+	if err != nil {
+		ctx.ServerError("GetScheduledVisibilityChange", err)
+		return
+	}
+	if change != nil {
+		if err := change.LoadAttributes(); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return
+		}
+		ctx.Data["ScheduledChange"] = change
+	}
+}
+
+// SettingsVisibility shows the impact of changing the visibility of a repository given by the private query,
+// the visibility is switched if it is not given
+func SettingsVisibility(ctx *context.Context) {
+	private := !ctx.Repo.Repository.IsPrivate
+	if ctx.Query("private") != "" {
+		private = ctx.QueryBool("private")
+	}
+
+	loadSettingsVisibility(ctx, private)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(200, tplSettingsVisibility)
+}
+
+// SettingsVisibilityPost changes the visibility of a repository right away or schedules the change,
+// or cancels the change scheduled for it
+func SettingsVisibilityPost(ctx *context.Context, form auth.RepoVisibilityForm) {
+	loadSettingsVisibility(ctx, form.Private)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsVisibility)
+		return
+	}
+
+	repo := ctx.Repo.Repository
+	link := fmt.Sprintf("%s/settings/visibility?private=%t", repo.Link(), form.Private)
+	if form.Action == "cancel" {
+		if err := models.CancelScheduledVisibilityChange(repo.ID); err != nil {
+			ctx.ServerError("CancelScheduledVisibilityChange", err)
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("repo.settings.visibility.cancel_success"))
+		ctx.Redirect(link)
+		return
+	}
+
+	if repo.Name != form.RepoName {
+		ctx.Data["Err_RepoName"] = true
+		ctx.RenderWithErr(ctx.Tr("form.enterred_invalid_repo_name"), tplSettingsVisibility, &form)
+		return
+	}
+
+	if form.Action == "now" {
+		if err := repo_service.ChangeRepositoryVisibility(repo, form.Private); err != nil {
+			ctx.ServerError("ChangeRepositoryVisibility", err)
+			return
+		}
+		log.Trace("Repository visibility changed: %s/%s private=%t", ctx.Repo.Owner.Name, repo.Name, form.Private)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.visibility.change_success"))
+		ctx.Redirect(repo.Link() + "/settings")
+		return
+	}
+
+	if repo.IsPrivate == form.Private {
+		ctx.Data["Err_ScheduledAt"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.visibility.unchanged"), tplSettingsVisibility, &form)
+		return
+	}
+	scheduled, err := time.ParseInLocation("2006-01-02T15:04", form.ScheduledAt, time.Local)
+	if err != nil || !scheduled.After(time.Now()) {
+		ctx.Data["Err_ScheduledAt"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.visibility.invalid_schedule"), tplSettingsVisibility, &form)
+		return
+	}
+	if err := models.ScheduleVisibilityChange(ctx.User, repo, form.Private, timeutil.TimeStamp(scheduled.Unix())); err != nil {
+		ctx.ServerError("ScheduleVisibilityChange", err)
+		return
+	}
+	log.Trace("Repository visibility change scheduled: %s/%s private=%t at %v", ctx.Repo.Owner.Name, repo.Name, form.Private, scheduled)
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.visibility.schedule_success"))
+	ctx.Redirect(link)
+}
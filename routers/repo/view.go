@@ -15,6 +15,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
@@ -27,6 +28,8 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 const (
@@ -383,6 +386,7 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 	ctx.Data["FileIsSymlink"] = entry.IsLink()
 	ctx.Data["FileSize"] = fileSize
 	ctx.Data["FileName"] = blob.Name()
+	ctx.Data["BlobID"] = entry.ID.String()
 	ctx.Data["RawFileLink"] = rawLink + "/" + ctx.Repo.TreePath
 
 	buf := make([]byte, 1024)
@@ -452,32 +456,43 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 		ctx.Data["EditFileTooltip"] = ctx.Tr("repo.editor.cannot_edit_non_text_files")
 	}
 
+	previewType := markup.PreviewType(blob.Name())
+	if previewType != "" {
+		ctx.Data["PreviewType"] = previewType
+		ctx.Data["IsPreviewTooLarge"] = fileSize > setting.UI.MaxPreviewFileSize
+	}
+
 	switch {
 	case isTextFile:
-		if fileSize >= setting.UI.MaxDisplayFileSize {
-			ctx.Data["IsFileTooLarge"] = true
-			break
-		}
+		// Files which are previewed in the browser are not rendered here
+		if previewType == "" {
+			if fileSize >= setting.UI.MaxDisplayFileSize {
+				ctx.Data["IsFileTooLarge"] = true
+				break
+			}
 
-		d, _ := ioutil.ReadAll(dataRc)
-		buf = charset.ToUTF8WithFallback(append(buf, d...))
-		readmeExist := markup.IsReadmeFile(blob.Name())
-		ctx.Data["ReadmeExist"] = readmeExist
-		if markupType := markup.Type(blob.Name()); markupType != "" {
-			ctx.Data["IsMarkup"] = true
-			ctx.Data["MarkupType"] = markupType
-			ctx.Data["FileContent"] = string(markup.Render(blob.Name(), buf, path.Dir(treeLink), ctx.Repo.Repository.ComposeDocumentMetas()))
-		} else if readmeExist {
-			ctx.Data["IsRenderedHTML"] = true
-			ctx.Data["FileContent"] = strings.Replace(
-				gotemplate.HTMLEscapeString(string(buf)), "\n", `<br>`, -1,
-			)
-		} else {
-			buf = charset.ToUTF8WithFallback(buf)
-			lineNums := linesBytesCount(buf)
-			ctx.Data["NumLines"] = strconv.Itoa(lineNums)
-			ctx.Data["NumLinesSet"] = true
-			ctx.Data["FileContent"] = highlight.File(lineNums, blob.Name(), buf)
+			d, _ := ioutil.ReadAll(dataRc)
+			buf = charset.ToUTF8WithFallback(append(buf, d...))
+			readmeExist := markup.IsReadmeFile(blob.Name())
+			ctx.Data["ReadmeExist"] = readmeExist
+			if markupType := markup.Type(blob.Name()); markupType != "" {
+				ctx.Data["IsMarkup"] = true
+				ctx.Data["MarkupType"] = markupType
+				ctx.Data["FileContent"] = string(markup.Render(blob.Name(), buf, path.Dir(treeLink), ctx.Repo.Repository.ComposeDocumentMetas()))
+			} else if readmeExist {
+				ctx.Data["IsRenderedHTML"] = true
+				ctx.Data["FileContent"] = strings.Replace(
+					gotemplate.HTMLEscapeString(string(buf)), "\n", `<br>`, -1,
+				)
+			} else {
+				buf = charset.ToUTF8WithFallback(buf)
+				lineNums := linesBytesCount(buf)
+				ctx.Data["NumLines"] = strconv.Itoa(lineNums)
+				ctx.Data["NumLinesSet"] = true
+				ctx.Data["FileContent"] = highlight.File(lineNums, blob.Name(), buf)
+				permalink := ctx.Repo.Repository.HTMLURL() + "/src/commit/" + ctx.Repo.CommitID + "/" + util.PathEscapeSegments(ctx.Repo.TreePath)
+				ctx.Data["OEmbedURL"] = setting.AppURL + "api/oembed?format=json&url=" + url.QueryEscape(permalink)
+			}
 		}
 		if !isLFSFile {
 			if ctx.Repo.CanEnableEditor() {
@@ -495,6 +510,8 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 			}
 		}
 
+	case previewType != "":
+		// Previewed in the browser, nothing to render
 	case base.IsPDFFile(buf):
 		ctx.Data["IsPDFFile"] = true
 	case base.IsVideoFile(buf):
@@ -608,6 +625,79 @@ func renderRepoTopics(ctx *context.Context) {
 	ctx.Data["Topics"] = topics
 }
 
+// ideLauncher is a link opening a dev environment in an IDE, its URL is configured by the
+// administrator and usually has the custom scheme of the IDE, which templates would not render.
+type ideLauncher struct {
+	DisplayName string
+	URL         gotemplate.URL
+}
+
+// setDevEnvironment sets the dev container configuration and the IDE launchers of the
+// commit of a repository opened from the ref and the web page
+func setDevEnvironment(ctx *context.Context, repo *models.Repository, commit *git.Commit, ref, webURL string) {
+	env, err := repo_service.GetDevEnvironment(repo, commit, ref, webURL)
+	if err != nil {
+		ctx.ServerError("GetDevEnvironment", err)
+		return
+	}
+	launchers := make([]*ideLauncher, 0, len(env.Launchers))
+	for _, launcher := range env.Launchers {
+		launchers = append(launchers, &ideLauncher{
+			DisplayName: launcher.DisplayName,
+			URL:         gotemplate.URL(launcher.URL),
+		})
+	}
+	ctx.Data["DevContainer"] = env.Config
+	ctx.Data["IDELaunchers"] = launchers
+}
+
+// renderDevEnvironment sets the links opening the viewed ref in the configured IDEs
+func renderDevEnvironment(ctx *context.Context) {
+	if !setting.IDE.Enabled || len(ctx.Repo.TreePath) > 0 {
+		return
+	}
+	webURL := ctx.Repo.Repository.HTMLURL() + "/src/" + ctx.Repo.BranchNameSubURL()
+	setDevEnvironment(ctx, ctx.Repo.Repository, ctx.Repo.Commit, ctx.Repo.BranchName, webURL)
+}
+
+// renamedBranchNoticeDuration is how long after the rename of the default branch the
+// instructions to update the clones are shown on the repository home page
+const renamedBranchNoticeDuration = 30 * 24 * time.Hour
+
+func renderRenamedBranch(ctx *context.Context) {
+	if len(ctx.Repo.TreePath) > 0 || ctx.Repo.BranchName != ctx.Repo.Repository.DefaultBranch {
+		return
+	}
+	renamedBranch, err := models.GetLatestRenamedBranchTo(ctx.Repo.Repository.ID, ctx.Repo.Repository.DefaultBranch)
+	if err != nil {
+		ctx.ServerError("GetLatestRenamedBranchTo", err)
+		return
+	}
+	if renamedBranch != nil && renamedBranch.CreatedUnix.AsTime().After(time.Now().Add(-renamedBranchNoticeDuration)) {
+		ctx.Data["RenamedBranch"] = renamedBranch
+	}
+}
+
+// topContributorsNum is the number of top contributors shown on the repository home page
+const topContributorsNum = 10
+
+func renderActivity(ctx *context.Context) {
+	if !setting.Service.EnableUserHeatmap || len(ctx.Repo.TreePath) > 0 || ctx.Repo.BranchName != ctx.Repo.Repository.DefaultBranch {
+		return
+	}
+	contributors, err := models.GetActivityTopContributors(&models.ActivityHeatmapOptions{RepoID: ctx.Repo.Repository.ID}, topContributorsNum)
+	if err != nil {
+		ctx.ServerError("GetActivityTopContributors", err)
+		return
+	}
+	if len(contributors) == 0 {
+		return
+	}
+	ctx.Data["EnableHeatmap"] = true
+	ctx.Data["HeatmapURL"] = setting.AppSubURL + "/api/v1/repos/" + ctx.Repo.Repository.FullName() + "/activity/heatmap"
+	ctx.Data["TopContributors"] = contributors
+}
+
 func renderCode(ctx *context.Context) {
 	ctx.Data["PageIsViewCode"] = true
 
@@ -636,6 +726,21 @@ func renderCode(ctx *context.Context) {
 		return
 	}
 
+	renderRenamedBranch(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	renderActivity(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	renderDevEnvironment(ctx)
+	if ctx.Written() {
+		return
+	}
+
 	// Get current entry user currently looking at.
 	entry, err := ctx.Repo.Commit.GetTreeEntryByPath(ctx.Repo.TreePath)
 	if err != nil {
@@ -0,0 +1,61 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+)
+
+const tplWatchPaths base.TplName = "repo/watch_paths"
+
+// WatchPaths renders the paths of the repository the user watches
+func WatchPaths(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.watch_paths")
+	ctx.Data["PageIsWatchers"] = true
+
+	watches, err := models.GetUserWatchPaths(ctx.User.ID, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetUserWatchPaths", err)
+		return
+	}
+	ctx.Data["WatchPaths"] = watches
+
+	ctx.HTML(200, tplWatchPaths)
+}
+
+// WatchPathPost subscribes the user to the changes of a path or pattern of the repository
+func WatchPathPost(ctx *context.Context, form auth.WatchPathForm) {
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(ctx.Repo.RepoLink + "/watch_paths")
+		return
+	}
+
+	if _, err := models.WatchRepoPath(ctx.User.ID, ctx.Repo.Repository.ID, form.Path); err != nil {
+		if models.IsErrFilenameInvalid(err) {
+			ctx.Flash.Error(ctx.Tr("repo.watch_paths.invalid", form.Path))
+			ctx.Redirect(ctx.Repo.RepoLink + "/watch_paths")
+			return
+		}
+		ctx.ServerError("WatchRepoPath", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.watch_paths.added", form.Path))
+	ctx.Redirect(ctx.Repo.RepoLink + "/watch_paths")
+}
+
+// DeleteWatchPath unsubscribes the user from the changes of a path of the repository
+func DeleteWatchPath(ctx *context.Context) {
+	if err := models.UnwatchRepoPath(ctx.User.ID, ctx.Repo.Repository.ID, ctx.Query("path")); err != nil && !models.IsErrFilenameInvalid(err) {
+		ctx.ServerError("UnwatchRepoPath", err)
+		return
+	}
+
+	ctx.Redirect(ctx.Repo.RepoLink + "/watch_paths")
+}
@@ -0,0 +1,42 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchPathPost(t *testing.T) {
+	models.PrepareTestEnv(t)
+
+	ctx := test.MockContext(t, "user2/repo1/watch_paths")
+	test.LoadUser(t, ctx, 4)
+	test.LoadRepo(t, ctx, 1)
+	WatchPathPost(ctx, auth.WatchPathForm{Path: "docs/**/*.md"})
+	assert.EqualValues(t, http.StatusFound, ctx.Resp.Status())
+	models.AssertExistsAndLoadBean(t, &models.WatchPath{UserID: 4, RepoID: 1, Path: "docs/**/*.md"})
+
+	ctx = test.MockContext(t, "user2/repo1/watch_paths")
+	test.LoadUser(t, ctx, 4)
+	test.LoadRepo(t, ctx, 1)
+	WatchPathPost(ctx, auth.WatchPathForm{Path: "docs/[.md"})
+	assert.EqualValues(t, http.StatusFound, ctx.Resp.Status())
+	models.AssertNotExistsBean(t, &models.WatchPath{UserID: 4, RepoID: 1, Path: "docs/[.md"})
+
+	ctx = test.MockContext(t, "user2/repo1/watch_paths/delete")
+	test.LoadUser(t, ctx, 4)
+	test.LoadRepo(t, ctx, 1)
+	ctx.Req.Form.Set("path", "docs/**/*.md")
+	DeleteWatchPath(ctx)
+	assert.EqualValues(t, http.StatusFound, ctx.Resp.Status())
+	models.AssertNotExistsBean(t, &models.WatchPath{UserID: 4, RepoID: 1, Path: "docs/**/*.md"})
+}
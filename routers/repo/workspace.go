@@ -0,0 +1,408 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/charset"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/repofiles"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/utils"
+)
+
+const (
+	tplWorkspace base.TplName = "repo/editor/workspace"
+
+	workspaceStatusAdded    = "added"
+	workspaceStatusModified = "modified"
+	workspaceStatusDeleted  = "deleted"
+)
+
+// workspaceFile represents a file of the web IDE workspace of a branch
+type workspaceFile struct {
+	Path string `json:"path"`
+	// Status is the status of the uncommitted change of the file, empty if there is none
+	Status string `json:"status,omitempty"`
+}
+
+// workspaceLink returns the link of the web IDE workspace of the current branch
+func workspaceLink(ctx *context.Context) string {
+	return ctx.Repo.RepoLink + "/_ide/" + util.PathEscapeSegments(ctx.Repo.BranchName)
+}
+
+// workspaceStatus returns the status of the change compared to the file at the head of the branch
+func workspaceStatus(change *models.WorkspaceChange, exists bool) string {
+	switch {
+	case change.IsDeleted:
+		return workspaceStatusDeleted
+	case exists:
+		return workspaceStatusModified
+	default:
+		return workspaceStatusAdded
+	}
+}
+
+// readWorkspaceBlob returns the content of the file at the head of the branch if it can be edited
+// online, false if it is too large, not a text file or stored in LFS.
+func readWorkspaceBlob(entry *git.TreeEntry) (string, bool, error) {
+	blob := entry.Blob()
+	if blob.Size() >= setting.UI.MaxDisplayFileSize {
+		return "", false, nil
+	}
+	dataRc, err := blob.DataAsync()
+	if err != nil {
+		return "", false, err
+	}
+	defer dataRc.Close()
+
+	buf, err := ioutil.ReadAll(dataRc)
+	if err != nil {
+		return "", false, err
+	}
+	if !base.IsTextFile(buf) || lfs.IsPointerFile(&buf) != nil {
+		return "", false, nil
+	}
+	content, err := charset.ToUTF8WithErr(buf)
+	if err != nil {
+		log.Error("ToUTF8WithErr: %v", err)
+		content = string(buf)
+	}
+	return content, true, nil
+}
+
+// getWorkspaceEntry returns the regular file at the tree path of the request in the head of the
+// branch, nil if there is none.
+func getWorkspaceEntry(ctx *context.Context, treePath string) *git.TreeEntry {
+	entry, err := ctx.Repo.Commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		if !git.IsErrNotExist(err) {
+			ctx.ServerError("GetTreeEntryByPath", err)
+		}
+		return nil
+	}
+	if !entry.IsRegular() && !entry.IsExecutable() {
+		ctx.Error(http.StatusUnprocessableEntity, "not a file")
+		return nil
+	}
+	return entry
+}
+
+// Workspace render the web IDE workspace of a branch
+func Workspace(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.editor.workspace")
+	ctx.Data["PageIsWorkspace"] = true
+	canCommit := renderCommitRights(ctx)
+
+	changes, err := models.GetWorkspaceChanges(ctx.User.ID, ctx.Repo.Repository.ID, ctx.Repo.BranchName)
+	if err != nil {
+		ctx.ServerError("GetWorkspaceChanges", err)
+		return
+	}
+	ctx.Data["Changes"] = changes
+
+	ctx.Data["WorkspaceLink"] = workspaceLink(ctx)
+	ctx.Data["BranchLink"] = ctx.Repo.RepoLink + "/src/" + ctx.Repo.BranchNameSubURL()
+	ctx.Data["TreePath"] = cleanUploadFileName(ctx.Repo.TreePath)
+	ctx.Data["commit_summary"] = ""
+	ctx.Data["commit_message"] = ""
+	if canCommit {
+		ctx.Data["commit_choice"] = frmCommitChoiceDirect
+	} else {
+		ctx.Data["commit_choice"] = frmCommitChoiceNewBranch
+	}
+	ctx.Data["new_branch_name"] = GetUniquePatchBranchName(ctx)
+	ctx.Data["LineWrapExtensions"] = strings.Join(setting.Repository.Editor.LineWrapExtensions, ",")
+	ctx.Data["MaxWorkspaceChanges"] = setting.Repository.Editor.MaxWorkspaceChanges
+
+	ctx.HTML(200, tplWorkspace)
+}
+
+// WorkspaceFiles returns the files of the web IDE workspace of a branch with the status of their changes
+func WorkspaceFiles(ctx *context.Context) {
+	entries, err := ctx.Repo.Commit.Tree.ListEntriesRecursive()
+	if err != nil {
+		ctx.ServerError("ListEntriesRecursive", err)
+		return
+	}
+	changes, err := models.GetWorkspaceChanges(ctx.User.ID, ctx.Repo.Repository.ID, ctx.Repo.BranchName)
+	if err != nil {
+		ctx.ServerError("GetWorkspaceChanges", err)
+		return
+	}
+	changed := make(map[string]*models.WorkspaceChange, len(changes))
+	for _, change := range changes {
+		changed[change.TreePath] = change
+	}
+
+	files := make([]*workspaceFile, 0, len(entries)+len(changes))
+	for _, entry := range entries {
+		if !entry.IsRegular() && !entry.IsExecutable() {
+			continue
+		}
+		file := &workspaceFile{Path: entry.Name()}
+		if change, ok := changed[file.Path]; ok {
+			file.Status = workspaceStatus(change, true)
+			delete(changed, file.Path)
+		}
+		files = append(files, file)
+	}
+	for _, change := range changes {
+		if _, ok := changed[change.TreePath]; ok && !change.IsDeleted {
+			files = append(files, &workspaceFile{Path: change.TreePath, Status: workspaceStatusAdded})
+		}
+	}
+
+	ctx.JSON(200, files)
+}
+
+// WorkspaceFile returns the content of a file of the web IDE workspace of a branch, with its uncommitted change
+func WorkspaceFile(ctx *context.Context) {
+	treePath := cleanUploadFileName(ctx.Repo.TreePath)
+	if treePath == "" {
+		ctx.NotFound("cleanUploadFileName", nil)
+		return
+	}
+	entry := getWorkspaceEntry(ctx, treePath)
+	if ctx.Written() {
+		return
+	}
+
+	change, err := models.GetWorkspaceChange(ctx.User.ID, ctx.Repo.Repository.ID, ctx.Repo.BranchName, treePath)
+	if err != nil && !models.IsErrWorkspaceChangeNotExist(err) {
+		ctx.ServerError("GetWorkspaceChange", err)
+		return
+	}
+
+	file := map[string]interface{}{
+		"path":     treePath,
+		"editable": true,
+	}
+	switch {
+	case change != nil:
+		file["status"] = workspaceStatus(change, entry != nil)
+		file["content"] = change.Content
+	case entry != nil:
+		content, editable, err := readWorkspaceBlob(entry)
+		if err != nil {
+			ctx.ServerError("readWorkspaceBlob", err)
+			return
+		}
+		file["content"] = content
+		file["editable"] = editable
+	default:
+		ctx.NotFound("WorkspaceFile", nil)
+		return
+	}
+	if entry != nil {
+		file["editorconfig"] = GetEditorConfig(ctx, treePath)
+	}
+	ctx.JSON(200, file)
+}
+
+// saveWorkspaceChange stores the change of the file of the web IDE workspace, a change restoring
+// the file at the head of the branch is discarded.
+func saveWorkspaceChange(ctx *context.Context, treePath, content string, isDeleted bool) {
+	entry := getWorkspaceEntry(ctx, treePath)
+	if ctx.Written() {
+		return
+	}
+
+	if entry != nil {
+		original, editable, err := readWorkspaceBlob(entry)
+		if err != nil {
+			ctx.ServerError("readWorkspaceBlob", err)
+			return
+		}
+		if !editable && !isDeleted {
+			ctx.Error(http.StatusUnprocessableEntity, "file cannot be edited online")
+			return
+		}
+		if editable && !isDeleted && original == content {
+			if err := models.DeleteWorkspaceChange(ctx.User.ID, ctx.Repo.Repository.ID, ctx.Repo.BranchName, treePath); err != nil {
+				ctx.ServerError("DeleteWorkspaceChange", err)
+				return
+			}
+			ctx.JSON(200, &workspaceFile{Path: treePath})
+			return
+		}
+	} else if isDeleted {
+		// deleting a file added in the workspace only discards it
+		if err := models.DeleteWorkspaceChange(ctx.User.ID, ctx.Repo.Repository.ID, ctx.Repo.BranchName, treePath); err != nil {
+			ctx.ServerError("DeleteWorkspaceChange", err)
+			return
+		}
+		ctx.JSON(200, &workspaceFile{Path: treePath, Status: workspaceStatusDeleted})
+		return
+	}
+
+	change := &models.WorkspaceChange{
+		UserID:       ctx.User.ID,
+		RepoID:       ctx.Repo.Repository.ID,
+		Branch:       ctx.Repo.BranchName,
+		TreePath:     treePath,
+		Content:      content,
+		IsDeleted:    isDeleted,
+		BaseCommitID: ctx.Repo.CommitID,
+	}
+	if err := models.SaveWorkspaceChange(change, setting.Repository.Editor.MaxWorkspaceChanges); err != nil {
+		if models.IsErrWorkspaceTooManyChanges(err) {
+			ctx.Error(http.StatusUnprocessableEntity, ctx.Tr("repo.editor.workspace.too_many_changes", setting.Repository.Editor.MaxWorkspaceChanges))
+			return
+		}
+		ctx.ServerError("SaveWorkspaceChange", err)
+		return
+	}
+	ctx.JSON(200, &workspaceFile{Path: treePath, Status: workspaceStatus(change, entry != nil)})
+}
+
+// WorkspaceFilePost stores the uncommitted change of a file of the web IDE workspace of a branch,
+// a new file is added if there is none at the path
+func WorkspaceFilePost(ctx *context.Context, form auth.WorkspaceFileForm) {
+	treePath := cleanUploadFileName(ctx.Repo.TreePath)
+	if treePath == "" {
+		ctx.Error(http.StatusUnprocessableEntity, ctx.Tr("repo.editor.filename_is_invalid", ctx.Repo.TreePath))
+		return
+	}
+	saveWorkspaceChange(ctx, treePath, strings.Replace(form.Content, "\r", "", -1), false)
+}
+
+// WorkspaceDeleteFile marks a file of the web IDE workspace of a branch as deleted
+func WorkspaceDeleteFile(ctx *context.Context) {
+	treePath := cleanUploadFileName(ctx.Repo.TreePath)
+	if treePath == "" {
+		ctx.NotFound("cleanUploadFileName", nil)
+		return
+	}
+	saveWorkspaceChange(ctx, treePath, "", true)
+}
+
+// WorkspaceDiscard discards the uncommitted change of a file of the web IDE workspace of a branch,
+// or all of its changes if there is no file path
+func WorkspaceDiscard(ctx *context.Context) {
+	treePath := cleanUploadFileName(ctx.Repo.TreePath)
+	var err error
+	if treePath == "" {
+		err = models.DeleteWorkspaceChanges(ctx.User.ID, ctx.Repo.Repository.ID, ctx.Repo.BranchName)
+	} else {
+		err = models.DeleteWorkspaceChange(ctx.User.ID, ctx.Repo.Repository.ID, ctx.Repo.BranchName, treePath)
+	}
+	if err != nil {
+		ctx.ServerError("DeleteWorkspaceChange", err)
+		return
+	}
+	ctx.JSON(200, &workspaceFile{Path: treePath})
+}
+
+// WorkspaceCommit commits all the changes of the web IDE workspace of a branch at once
+func WorkspaceCommit(ctx *context.Context, form auth.WorkspaceCommitForm) {
+	canCommit := renderCommitRights(ctx)
+	link := workspaceLink(ctx)
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(link)
+		return
+	}
+
+	branchName := ctx.Repo.BranchName
+	if form.CommitChoice == frmCommitChoiceNewBranch {
+		branchName = form.NewBranchName
+	}
+	if branchName == ctx.Repo.BranchName && !canCommit {
+		ctx.Flash.Error(ctx.Tr("repo.editor.cannot_commit_to_protected_branch", branchName))
+		ctx.Redirect(link)
+		return
+	}
+
+	changes, err := models.GetWorkspaceChanges(ctx.User.ID, ctx.Repo.Repository.ID, ctx.Repo.BranchName)
+	if err != nil {
+		ctx.ServerError("GetWorkspaceChanges", err)
+		return
+	}
+	if len(changes) == 0 {
+		ctx.Flash.Error(ctx.Tr("repo.editor.workspace.no_changes"))
+		ctx.Redirect(link)
+		return
+	}
+
+	message := strings.TrimSpace(form.CommitSummary)
+	if len(message) == 0 {
+		if len(changes) == 1 {
+			message = ctx.Tr("repo.editor.update", changes[0].TreePath)
+		} else {
+			message = ctx.Tr("repo.editor.workspace.update_files", len(changes))
+		}
+	}
+	form.CommitMessage = strings.TrimSpace(form.CommitMessage)
+	if len(form.CommitMessage) > 0 {
+		message += "\n\n" + form.CommitMessage
+	}
+
+	if _, err := repofiles.CommitWorkspaceChanges(ctx.Repo.Repository, ctx.User, &repofiles.CommitWorkspaceOptions{
+		OldBranch: ctx.Repo.BranchName,
+		NewBranch: branchName,
+		Message:   message,
+		Changes:   changes,
+	}); err != nil {
+		switch {
+		case models.IsErrWorkspaceConflict(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.workspace.conflict", strings.Join(err.(models.ErrWorkspaceConflict).Paths, ", ")))
+		case models.IsErrLFSFileLocked(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.upload_file_is_locked", err.(models.ErrLFSFileLocked).Path, err.(models.ErrLFSFileLocked).UserName))
+		case models.IsErrFilenameInvalid(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.filename_is_invalid", err.(models.ErrFilenameInvalid).Path))
+		case models.IsErrFilePathInvalid(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.filename_is_invalid", err.(models.ErrFilePathInvalid).Path))
+		case models.IsErrFilePathProtected(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.file_is_protected", err.(models.ErrFilePathProtected).Path))
+		case models.IsErrUserCannotCommit(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.cannot_commit_to_protected_branch", branchName))
+		case git.IsErrBranchNotExist(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.branch_does_not_exist", err.(git.ErrBranchNotExist).Name))
+		case models.IsErrBranchAlreadyExists(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.branch_already_exists", err.(models.ErrBranchAlreadyExists).BranchName))
+		case models.IsErrCommitMessageRejected(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.commit_message_rejected", err.(models.ErrCommitMessageRejected).Reason))
+		case git.IsErrPushOutOfDate(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.workspace.conflict", ""))
+		case git.IsErrPushRejected(err):
+			errPushRej := err.(*git.ErrPushRejected)
+			if len(errPushRej.Message) == 0 {
+				ctx.Flash.Error(ctx.Tr("repo.editor.push_rejected_no_message"))
+			} else {
+				ctx.Flash.Error(ctx.Tr("repo.editor.push_rejected", utils.SanitizeFlashErrorString(errPushRej.Message)))
+			}
+		default:
+			ctx.ServerError("CommitWorkspaceChanges", err)
+			return
+		}
+		ctx.Redirect(link)
+		return
+	}
+
+	if err := models.DeleteWorkspaceChanges(ctx.User.ID, ctx.Repo.Repository.ID, ctx.Repo.BranchName); err != nil {
+		ctx.ServerError("DeleteWorkspaceChanges", err)
+		return
+	}
+
+	if form.CommitChoice == frmCommitChoiceNewBranch && ctx.Repo.Repository.UnitEnabled(models.UnitTypePullRequests) {
+		ctx.Redirect(ctx.Repo.RepoLink + "/compare/" + util.PathEscapeSegments(ctx.Repo.BranchName) + "..." + util.PathEscapeSegments(form.NewBranchName))
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.editor.workspace.commit_success", len(changes)))
+		ctx.Redirect(path.Join(ctx.Repo.RepoLink, "src/branch", util.PathEscapeSegments(branchName)))
+	}
+}
@@ -17,11 +17,13 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/maintenance"
 	"code.gitea.io/gitea/modules/metrics"
 	"code.gitea.io/gitea/modules/options"
 	"code.gitea.io/gitea/modules/public"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/templates"
+	"code.gitea.io/gitea/modules/tracing"
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/routers"
 	"code.gitea.io/gitea/routers/admin"
@@ -29,11 +31,12 @@ import (
 	"code.gitea.io/gitea/routers/dev"
 	"code.gitea.io/gitea/routers/events"
 	"code.gitea.io/gitea/routers/org"
+	"code.gitea.io/gitea/routers/pages"
 	"code.gitea.io/gitea/routers/private"
 	"code.gitea.io/gitea/routers/repo"
+	"code.gitea.io/gitea/routers/snippet"
 	"code.gitea.io/gitea/routers/user"
 	userSetting "code.gitea.io/gitea/routers/user/setting"
-	"code.gitea.io/gitea/services/mailer"
 
 	// to registers all internal adapters
 	_ "code.gitea.io/gitea/modules/session"
@@ -52,11 +55,14 @@ import (
 	"github.com/tstranex/u2f"
 )
 
+const requestIDHeader = "X-Request-ID"
+
 type routerLoggerOptions struct {
 	Ctx            *macaron.Context
 	Identity       *string
 	Start          *time.Time
 	ResponseWriter *macaron.ResponseWriter
+	RequestID      string
 }
 
 func setupAccessLogger(m *macaron.Macaron) {
@@ -80,30 +86,46 @@ func setupAccessLogger(m *macaron.Macaron) {
 			Identity:       &identity,
 			Start:          &start,
 			ResponseWriter: &rw,
+			RequestID:      context.RequestID(ctx),
 		})
 		if err != nil {
 			log.Error("Could not set up macaron access logger: %v", err.Error())
 		}
 
-		err = logger.SendLog(log.INFO, "", "", 0, buf.String(), "")
+		err = logger.WithContext(context.LogContext(ctx)).SendLog(log.INFO, "", "", 0, buf.String(), "")
 		if err != nil {
 			log.Error("Could not set up macaron access logger: %v", err.Error())
 		}
 	})
 }
 
+// RequestIDHandler is a macaron handler that gives an ID to the request, so that
+// everything logged for the request can be correlated.
+// A valid ID sent in the X-Request-ID header, e.g. by a reverse proxy, is kept.
+func RequestIDHandler() func(ctx *macaron.Context) {
+	return func(ctx *macaron.Context) {
+		id := ctx.Req.Header.Get(requestIDHeader)
+		if !log.IsValidRequestID(id) {
+			id = log.NewRequestID()
+		}
+		context.SetRequestID(ctx, id)
+		ctx.Resp.Header().Set(requestIDHeader, id)
+	}
+}
+
 // RouterHandler is a macaron handler that will log the routing to the default gitea log
 func RouterHandler(level log.Level) func(ctx *macaron.Context) {
 	return func(ctx *macaron.Context) {
 		start := time.Now()
 
-		_ = log.GetLogger("router").Log(0, level, "Started %s %s for %s", log.ColoredMethod(ctx.Req.Method), ctx.Req.URL.RequestURI(), ctx.RemoteAddr())
+		logger := log.GetLogger("router").WithContext(context.LogContext(ctx))
+		_ = logger.Log(0, level, "Started %s %s for %s", log.ColoredMethod(ctx.Req.Method), ctx.Req.URL.RequestURI(), ctx.RemoteAddr())
 
 		rw := ctx.Resp.(macaron.ResponseWriter)
 		ctx.Next()
 
 		status := rw.Status()
-		_ = log.GetLogger("router").Log(0, level, "Completed %s %s %v %s in %v", log.ColoredMethod(ctx.Req.Method), ctx.Req.URL.RequestURI(), log.ColoredStatus(status), log.ColoredStatus(status, http.StatusText(rw.Status())), log.ColoredTime(time.Since(start)))
+		_ = logger.Log(0, level, "Completed %s %s %v %s in %v", log.ColoredMethod(ctx.Req.Method), ctx.Req.URL.RequestURI(), log.ColoredStatus(status), log.ColoredStatus(status, http.StatusText(rw.Status())), log.ColoredTime(time.Since(start)))
 	}
 }
 
@@ -114,25 +136,37 @@ func NewMacaron() *macaron.Macaron {
 	if setting.RedirectMacaronLog {
 		loggerAsWriter := log.NewLoggerAsWriter("INFO", log.GetLogger("macaron"))
 		m = macaron.NewWithLogger(loggerAsWriter)
+	} else {
+		m = macaron.New()
+	}
+	// The request ID must be set before anything is logged for the request
+	m.Use(RequestIDHandler())
+	// Event streams stay open for as long as the page is, so they are not waited for
+	m.Use(maintenance.RequestTracker("/user/events"))
+	if setting.RedirectMacaronLog {
 		if !setting.DisableRouterLog && setting.RouterLogLevel != log.NONE {
 			if log.GetLogger("router").GetLevel() <= setting.RouterLogLevel {
 				m.Use(RouterHandler(setting.RouterLogLevel))
 			}
 		}
-	} else {
-		m = macaron.New()
-		if !setting.DisableRouterLog {
-			m.Use(macaron.Logger())
-		}
+	} else if !setting.DisableRouterLog {
+		m.Use(macaron.Logger())
 	}
 	// Access Logger is similar to Router Log but more configurable and by default is more like the NCSA Common Log format
 	if setting.EnableAccessLog {
 		setupAccessLogger(m)
 	}
 	m.Use(macaron.Recovery())
+	if setting.Tracing.Enabled {
+		m.Use(tracing.Middleware())
+	}
+	if setting.Metrics.Enabled {
+		m.Use(metrics.HTTPMiddleware())
+	}
 	if setting.EnableGzip {
 		m.Use(gzip.Middleware())
 	}
+	m.Use(pages.HostHandler())
 	if setting.Protocol == setting.FCGI || setting.Protocol == setting.FCGIUnix {
 		m.SetURLPrefix(setting.AppSubURL)
 	}
@@ -165,9 +199,19 @@ func NewMacaron() *macaron.Macaron {
 			ExpiresAfter: setting.StaticCacheTime,
 		},
 	))
+	m.Use(public.StaticHandler(
+		setting.CustomEmojiPath,
+		&public.Options{
+			Prefix:       "custom-emoji",
+			SkipLogging:  setting.DisableRouterLog,
+			ExpiresAfter: setting.StaticCacheTime,
+		},
+	))
 
 	m.Use(templates.HTMLRenderer())
-	mailer.InitMailRender(templates.Mailer())
+	if err := templates.ReloadMailer(); err != nil {
+		log.Error("Failed to load the customized mail templates: %v", err)
+	}
 
 	localeNames, err := options.Dir("locale")
 
@@ -270,6 +314,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 		}
 	}
 
+	m.Use(context.CheckMaintenance())
 	m.Use(user.GetNotificationCount)
 	m.Use(func(ctx *context.Context) {
 		ctx.Data["UnitWikiGlobalDisabled"] = models.UnitTypeWiki.UnitGlobalDisabled()
@@ -290,6 +335,9 @@ func RegisterRoutes(m *macaron.Macaron) {
 			ctx.Redirect(setting.AppSubURL + "/explore/repos")
 		})
 		m.Get("/repos", routers.ExploreRepos)
+		m.Get("/trending", routers.ExploreTrending)
+		m.Get("/topics", routers.ExploreTopics)
+		m.Get("/topics/:topic", routers.ExploreTopic)
 		m.Get("/users", routers.ExploreUsers)
 		m.Get("/organizations", routers.ExploreOrganizations)
 		m.Get("/code", routers.ExploreCode)
@@ -298,6 +346,23 @@ func RegisterRoutes(m *macaron.Macaron) {
 		Post(bindIgnErr(auth.InstallForm{}), routers.InstallPost)
 	m.Get("/^:type(issues|pulls)$", reqSignIn, user.Issues)
 	m.Get("/milestones", reqSignIn, reqMilestonesDashboardPageEnabled, user.Milestones)
+	m.Group("/-/snippets", func() {
+		m.Get("", snippet.Snippets)
+		m.Combo("/new", reqSignIn).Get(snippet.NewSnippet).
+			Post(bindIgnErr(auth.SnippetForm{}), snippet.NewSnippetPost)
+		m.Group("/:id", func() {
+			m.Get("", snippet.ViewSnippet)
+			m.Get("/revisions", snippet.SnippetRevisions)
+			m.Get("/raw/:filename", snippet.RawSnippetFile)
+			m.Group("", func() {
+				m.Combo("/edit").Get(snippet.EditSnippet).
+					Post(bindIgnErr(auth.SnippetForm{}), snippet.EditSnippetPost)
+				m.Post("/delete", snippet.DeleteSnippet)
+				m.Post("/comments", bindIgnErr(auth.SnippetCommentForm{}), snippet.NewSnippetComment)
+				m.Post("/comments/:comment_id/delete", snippet.DeleteSnippetComment)
+			}, reqSignIn)
+		}, snippet.SnippetAssignment)
+	}, ignSignIn, snippet.MustEnableSnippets)
 
 	// ***** START: User *****
 	m.Group("/user", func() {
@@ -401,11 +466,24 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Post("/keys/delete", userSetting.DeleteKey)
 		m.Get("/organization", userSetting.Organization)
 		m.Get("/repos", userSetting.Repos)
+		m.Post("/repos/pinned", userSetting.PinnedReposPost)
 	}, reqSignIn, func(ctx *context.Context) {
 		ctx.Data["PageIsUserSettings"] = true
-		ctx.Data["AllThemes"] = setting.UI.Themes
+		themes, err := models.GetAllThemeNames()
+		if err != nil {
+			ctx.ServerError("GetAllThemeNames", err)
+			return
+		}
+		ctx.Data["AllThemes"] = themes
 	})
 
+	m.Group("/user/star_lists", func() {
+		m.Post("", bindIgnErr(auth.StarListForm{}), user.CreateStarListPost)
+		m.Post("/:id/edit", bindIgnErr(auth.StarListForm{}), user.EditStarListPost)
+		m.Post("/:id/delete", user.DeleteStarList)
+		m.Post("/:id/repos", user.StarListRepoPost)
+	}, reqSignIn)
+
 	m.Group("/user", func() {
 		// r.Get("/feeds", binding.Bind(auth.FeedsForm{}), user.Feeds)
 		m.Any("/activate", user.Activate, reqSignIn)
@@ -421,6 +499,9 @@ func RegisterRoutes(m *macaron.Macaron) {
 	// ***** END: User *****
 
 	m.Get("/avatar/:hash", user.AvatarByEmailHash)
+	// the avatars themselves are served by the static handlers
+	m.Get("/avatars/:name/:size", user.AvatarVariant)
+	m.Get("/repo-avatars/:name/:size", user.RepoAvatarVariant)
 
 	adminReq := context.Toggle(&context.ToggleOptions{SignInRequired: true, AdminRequired: true})
 
@@ -430,15 +511,26 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Post("", adminReq, bindIgnErr(auth.AdminDashboardForm{}), admin.DashboardPost)
 		m.Get("/config", admin.Config)
 		m.Post("/config/test_mail", admin.SendTestMail)
+		m.Post("/config/reload", admin.ReloadConfig)
+		m.Group("/config/edit", func() {
+			m.Get("", admin.ConfigEditor)
+			m.Combo("/:section").Get(admin.ConfigEditorSection).Post(admin.ConfigEditorSectionPost)
+		}, admin.ConfigEditorEnabled)
 		m.Group("/monitor", func() {
 			m.Get("", admin.Monitor)
 			m.Post("/cancel/:pid", admin.MonitorCancel)
+			m.Post("/maintenance/enable", admin.EnableMaintenance)
+			m.Post("/maintenance/disable", admin.DisableMaintenance)
 			m.Group("/queue/:qid", func() {
 				m.Get("", admin.Queue)
 				m.Post("/set", admin.SetQueueSettings)
 				m.Post("/add", admin.AddWorkers)
 				m.Post("/cancel/:pid", admin.WorkerCancel)
 				m.Post("/flush", admin.Flush)
+				m.Post("/pause", admin.PauseQueue)
+				m.Post("/resume", admin.ResumeQueue)
+				m.Post("/dead/retry", admin.RetryDeadItems)
+				m.Post("/dead/remove", admin.RemoveDeadItems)
 			})
 		})
 
@@ -452,6 +544,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("/emails", func() {
 			m.Get("", admin.Emails)
 			m.Post("/activate", admin.ActivateEmail)
+			m.Get("/bounces", admin.EmailBounces)
+			m.Post("/bounces/delete", admin.DeleteEmailBounce)
 		})
 
 		m.Group("/orgs", func() {
@@ -496,6 +590,45 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Post("/:authid/delete", admin.DeleteAuthSource)
 		})
 
+		m.Group("/emojis", func() {
+			m.Get("", admin.Emojis)
+			m.Post("", binding.MultipartForm(auth.AdminCreateCustomEmojiForm{}), admin.EmojisPost)
+			m.Post("/delete", admin.DeleteEmoji)
+		})
+
+		m.Group("/themes", func() {
+			m.Get("", admin.Themes)
+			m.Post("", binding.MultipartForm(auth.AdminUploadThemeForm{}), admin.ThemesPost)
+			m.Post("/delete", admin.DeleteTheme)
+			m.Get("/:name", admin.Theme)
+			m.Post("/:name/use", admin.ThemeUseVersion)
+		})
+
+		m.Group("/mail-templates", func() {
+			m.Get("", admin.MailTemplates)
+			m.Combo("/edit").Get(admin.EditMailTemplate).Post(bindIgnErr(auth.AdminMailTemplateForm{}), admin.EditMailTemplatePost)
+			m.Post("/preview", bindIgnErr(auth.AdminMailTemplateForm{}), admin.PreviewMailTemplate)
+			m.Post("/test", bindIgnErr(auth.AdminMailTemplateForm{}), admin.TestMailTemplate)
+			m.Post("/use", admin.MailTemplateUseVersion)
+		})
+
+		m.Group("/badges", func() {
+			m.Get("", admin.Badges)
+			m.Combo("/new").Get(admin.NewBadge).Post(bindIgnErr(auth.AdminBadgeForm{}), admin.NewBadgePost)
+			m.Post("/delete", admin.DeleteBadge)
+			m.Combo("/:id").Get(admin.EditBadge).Post(bindIgnErr(auth.AdminBadgeForm{}), admin.EditBadgePost)
+			m.Post("/:id/grant", admin.GrantBadge)
+			m.Post("/:id/revoke", admin.RevokeBadge)
+		})
+
+		m.Group("/topics", func() {
+			m.Get("", admin.Topics)
+			m.Combo("/:id").Get(admin.EditTopic).Post(bindIgnErr(auth.TopicForm{}), admin.EditTopicPost)
+			m.Post("/:id/merge", bindIgnErr(auth.MergeTopicForm{}), admin.MergeTopicPost)
+		})
+
+		m.Get("/git-transfers", admin.GitTransfers)
+
 		m.Group("/notices", func() {
 			m.Get("", admin.Notices)
 			m.Post("/delete", admin.DeleteNotices)
@@ -507,6 +640,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 	m.Group("", func() {
 		m.Get("/:username", user.Profile)
 		m.Get("/attachments/:uuid", repo.GetAttachment)
+		m.Get("/attachments/:uuid/thumbnail", repo.GetAttachmentThumbnail)
 	}, ignSignIn)
 
 	m.Group("/attachments", func() {
@@ -528,6 +662,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 	reqRepoReleaseWriter := context.RequireRepoWriter(models.UnitTypeReleases)
 	reqRepoReleaseReader := context.RequireRepoReader(models.UnitTypeReleases)
 	reqRepoWikiWriter := context.RequireRepoWriter(models.UnitTypeWiki)
+	reqRepoDiscussionsWriter := context.RequireRepoWriter(models.UnitTypeDiscussions)
 	reqRepoIssueWriter := context.RequireRepoWriter(models.UnitTypeIssues)
 	reqRepoIssueReader := context.RequireRepoReader(models.UnitTypeIssues)
 	reqRepoPullsReader := context.RequireRepoReader(models.UnitTypePullRequests)
@@ -549,6 +684,27 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Post("/members/action/:action", org.MembersAction)
 
 			m.Get("/teams", org.Teams)
+
+			m.Group("/projects", func() {
+				m.Get("", org.Projects)
+				m.Get("/:id", org.ViewProject)
+				// only the owners change the projects
+				m.Group("", func() {
+					m.Combo("/new").Get(org.NewProject).Post(bindIgnErr(auth.CreateProjectForm{}), org.NewProjectPost)
+					m.Post("/delete", org.DeleteProject)
+					m.Group("/:id", func() {
+						m.Combo("/edit").Get(org.EditProject).Post(bindIgnErr(auth.CreateProjectForm{}), org.EditProjectPost)
+						m.Post("/:action(open|close)", org.ChangeProjectStatus)
+						m.Post("/boards/new", bindIgnErr(auth.ProjectBoardForm{}), org.NewProjectBoard)
+						m.Post("/boards/delete", org.DeleteProjectBoard)
+						m.Post("/issues/add", bindIgnErr(auth.ProjectIssueQueryForm{}), org.AddProjectIssues)
+						m.Post("/issues/move", org.MoveProjectIssue)
+						m.Post("/issues/remove", org.RemoveProjectIssue)
+						m.Post("/rules/new", bindIgnErr(auth.ProjectIssueQueryForm{}), org.NewProjectRule)
+						m.Post("/rules/delete", org.DeleteProjectRule)
+					})
+				}, context.OrgAssignment(true, true))
+			})
 		}, context.OrgAssignment(true))
 
 		m.Group("/:org", func() {
@@ -570,6 +726,14 @@ func RegisterRoutes(m *macaron.Macaron) {
 					Post(bindIgnErr(auth.UpdateOrgSettingForm{}), org.SettingsPost)
 				m.Post("/avatar", binding.MultipartForm(auth.AvatarForm{}), org.SettingsAvatar)
 				m.Post("/avatar/delete", org.SettingsDeleteAvatar)
+				m.Combo("/pinned").Get(org.SettingsPinnedRepos).
+					Post(org.SettingsPinnedReposPost)
+				m.Group("/topics", func() {
+					m.Get("", org.SettingsTopics)
+					m.Combo("/:topic").Get(org.SettingsTopic).
+						Post(bindIgnErr(auth.TopicForm{}), org.SettingsTopicPost)
+					m.Post("/:topic/merge", bindIgnErr(auth.MergeTopicForm{}), org.SettingsTopicMergePost)
+				})
 
 				m.Group("/hooks", func() {
 					m.Get("", org.Webhooks)
@@ -602,6 +766,51 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Post("/edit", bindIgnErr(auth.CreateLabelForm{}), org.UpdateLabel)
 					m.Post("/delete", org.DeleteLabel)
 					m.Post("/initialize", bindIgnErr(auth.InitializeLabelsForm{}), org.InitializeLabels)
+					m.Post("/sync", org.SyncLabels)
+				})
+
+				m.Group("/roles", func() {
+					m.Get("", org.Roles)
+					m.Combo("/new").Get(org.NewRole).
+						Post(bindIgnErr(auth.CreateOrgRoleForm{}), org.NewRolePost)
+					m.Post("/delete", org.DeleteRole)
+					m.Combo("/:id").Get(org.EditRole).
+						Post(bindIgnErr(auth.CreateOrgRoleForm{}), org.EditRolePost)
+				})
+
+				m.Group("/rulesets", func() {
+					m.Get("", org.Rulesets)
+					m.Combo("/new").Get(org.NewRuleset).
+						Post(bindIgnErr(auth.OrgRulesetForm{}), org.NewRulesetPost)
+					m.Post("/delete", org.DeleteRuleset)
+					m.Combo("/:id").Get(org.EditRuleset).
+						Post(bindIgnErr(auth.OrgRulesetForm{}), org.EditRulesetPost)
+				})
+
+				m.Group("/autolinks", func() {
+					m.Combo("").Get(org.SettingsAutoLinks).
+						Post(bindIgnErr(auth.AutoLinkRuleForm{}), org.SettingsAutoLinksPost)
+					m.Post("/delete", org.SettingsDeleteAutoLink)
+					m.Combo("/:id").Get(org.SettingsEditAutoLink).
+						Post(bindIgnErr(auth.AutoLinkRuleForm{}), org.SettingsEditAutoLinkPost)
+				})
+
+				m.Combo("/locale").Get(org.SettingsLocale).
+					Post(bindIgnErr(auth.LocaleSettingForm{}), org.SettingsLocalePost)
+
+				m.Group("/repo_defaults", func() {
+					m.Combo("").Get(org.RepoDefaults).
+						Post(bindIgnErr(auth.OrgRepoDefaultsForm{}), org.RepoDefaultsPost)
+					m.Post("/apply", org.ApplyRepoDefaults)
+				})
+
+				m.Combo("/attachments").Get(org.SettingsAttachments).
+					Post(bindIgnErr(auth.AttachmentPolicyForm{}), org.SettingsAttachmentsPost)
+
+				m.Group("/cla", func() {
+					m.Combo("").Get(org.ContributorAgreement).
+						Post(bindIgnErr(auth.ContributorAgreementForm{}), org.ContributorAgreementPost)
+					m.Post("/delete", org.DeleteContributorAgreement)
 				})
 
 				m.Route("/delete", "GET,POST", org.SettingsDelete)
@@ -631,6 +840,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 				Post(bindIgnErr(auth.RepoSettingForm{}), repo.SettingsPost)
 			m.Post("/avatar", binding.MultipartForm(auth.AvatarForm{}), repo.SettingsAvatar)
 			m.Post("/avatar/delete", repo.SettingsDeleteAvatar)
+			m.Combo("/visibility").Get(repo.SettingsVisibility).
+				Post(bindIgnErr(auth.RepoVisibilityForm{}), repo.SettingsVisibilityPost)
 
 			m.Group("/collaboration", func() {
 				m.Combo("").Get(repo.Collaboration).Post(repo.CollaborationPost)
@@ -643,6 +854,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 			})
 			m.Group("/branches", func() {
 				m.Combo("").Get(repo.ProtectedBranch).Post(repo.ProtectedBranchPost)
+				m.Combo("/_rename").Get(repo.RenameBranch).
+					Post(bindIgnErr(auth.RenameBranchForm{}), context.RepoMustNotBeArchived(), repo.RenameBranchPost)
 				m.Combo("/*").Get(repo.SettingsProtectedBranch).
 					Post(bindIgnErr(auth.ProtectBranchForm{}), context.RepoMustNotBeArchived(), repo.SettingsProtectedBranchPost)
 			}, repo.MustBeNotEmpty)
@@ -684,6 +897,57 @@ func RegisterRoutes(m *macaron.Macaron) {
 					Post(bindIgnErr(auth.AddKeyForm{}), repo.DeployKeysPost)
 				m.Post("/delete", repo.DeleteDeployKey)
 			})
+			m.Post("/deploy_tokens/delete", repo.DeleteDeployToken)
+
+			m.Group("/assign_rules", func() {
+				m.Combo("").Get(repo.AssignRules).
+					Post(bindIgnErr(auth.IssueAssignRuleForm{}), repo.AssignRulesPost)
+				m.Post("/delete", repo.DeleteAssignRule)
+			}, context.RepoMustNotBeArchived())
+
+			m.Group("/tabs", func() {
+				m.Combo("").Get(repo.CustomTabs).
+					Post(bindIgnErr(auth.RepoCustomTabForm{}), repo.CustomTabsPost)
+				m.Post("/delete", repo.DeleteCustomTab)
+				m.Combo("/:id").Get(repo.EditCustomTab).
+					Post(bindIgnErr(auth.RepoCustomTabForm{}), repo.EditCustomTabPost)
+			})
+
+			m.Group("/autolinks", func() {
+				m.Combo("").Get(repo.AutoLinks).
+					Post(bindIgnErr(auth.AutoLinkRuleForm{}), repo.AutoLinksPost)
+				m.Post("/delete", repo.DeleteAutoLink)
+				m.Combo("/:id").Get(repo.EditAutoLink).
+					Post(bindIgnErr(auth.AutoLinkRuleForm{}), repo.EditAutoLinkPost)
+			})
+
+			m.Combo("/commit_messages").Get(repo.CommitMessagePolicy).
+				Post(bindIgnErr(auth.CommitMessagePolicyForm{}), context.RepoMustNotBeArchived(), repo.CommitMessagePolicyPost)
+
+			m.Group("/issue_tracker_sync", func() {
+				m.Combo("").Get(repo.IssueTrackerSync).
+					Post(bindIgnErr(auth.IssueTrackerSyncForm{}), repo.IssueTrackerSyncPost)
+				m.Post("/delete", repo.DeleteIssueTrackerSync)
+			}, repo.IssueTrackerSyncEnabled)
+
+			m.Combo("/raw_headers", repo.RawHeadersEnabled).Get(repo.SettingsRawHeaders).
+				Post(bindIgnErr(auth.RepoRawHeadersForm{}), repo.SettingsRawHeadersPost)
+
+			m.Combo("/locale").Get(repo.SettingsLocale).
+				Post(bindIgnErr(auth.LocaleSettingForm{}), repo.SettingsLocalePost)
+
+			m.Group("/cla", func() {
+				m.Combo("").Get(repo.SettingsContributorAgreement).
+					Post(bindIgnErr(auth.ContributorAgreementForm{}), repo.SettingsContributorAgreementPost)
+				m.Post("/delete", repo.DeleteSettingsContributorAgreement)
+			})
+
+			m.Group("/pages", func() {
+				m.Combo("").Get(repo.SettingsPages).
+					Post(bindIgnErr(auth.RepoPagesForm{}), repo.SettingsPagesPost)
+				m.Post("/verify", repo.SettingsPagesVerifyDomain)
+				m.Post("/delete", repo.DeleteSettingsPages)
+			})
 
 			m.Group("/lfs", func() {
 				m.Get("", repo.LFSFiles)
@@ -702,11 +966,22 @@ func RegisterRoutes(m *macaron.Macaron) {
 		}, func(ctx *context.Context) {
 			ctx.Data["PageIsSettings"] = true
 			ctx.Data["LFSStartServer"] = setting.LFS.StartServer
+			ctx.Data["PagesEnabled"] = setting.Pages.Enabled
+			ctx.Data["RawHeadersEnabled"] = setting.Repository.Raw.AllowRepoHeaders
+			ctx.Data["IssueTrackerSyncEnabled"] = setting.IssueTrackerSync.Enabled
 		})
 	}, reqSignIn, context.RepoAssignment(), context.UnitTypes(), reqRepoAdmin, context.RepoRef())
 
 	m.Post("/:username/:reponame/action/:action", reqSignIn, context.RepoAssignment(), context.UnitTypes(), repo.Action)
 
+	m.Group("/:username/:reponame/watch_paths", func() {
+		m.Combo("").Get(repo.WatchPaths).
+			Post(bindIgnErr(auth.WatchPathForm{}), repo.WatchPathPost)
+		m.Post("/delete", repo.DeleteWatchPath)
+	}, reqSignIn, context.RepoAssignment(), context.UnitTypes(), reqRepoCodeReader)
+
+	m.Post("/:username/:reponame/cla/sign", reqSignIn, context.RepoAssignment(), context.UnitTypes(), repo.SignContributorAgreement)
+
 	// Grouping for those endpoints not requiring authentication
 	m.Group("/:username/:reponame", func() {
 		m.Group("/milestone", func() {
@@ -745,11 +1020,15 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Post("/reactions/:action", bindIgnErr(auth.ReactionForm{}), repo.ChangeIssueReaction)
 				m.Post("/lock", reqRepoIssueWriter, bindIgnErr(auth.IssueLockForm{}), repo.LockIssue)
 				m.Post("/unlock", reqRepoIssueWriter, repo.UnlockIssue)
+				m.Post("/answer", repo.SetIssueAnswer)
 				m.Get("/attachments", repo.GetIssueAttachments)
 			}, context.RepoMustNotBeArchived())
 
+			m.Post("/attachments", reqRepoIssuesOrPullsReader, repo.UploadIssueAttachment)
 			m.Post("/labels", reqRepoIssuesOrPullsWriter, repo.UpdateIssueLabel)
 			m.Post("/milestone", reqRepoIssuesOrPullsWriter, repo.UpdateIssueMilestone)
+			m.Post("/workflow_state", reqRepoIssuesOrPullsWriter, repo.UpdateIssueWorkflowState)
+			m.Post("/epic", reqRepoIssuesOrPullsWriter, repo.UpdateIssueEpic)
 			m.Post("/assignee", reqRepoIssuesOrPullsWriter, repo.UpdateIssueAssignee)
 			m.Post("/request_review", reqRepoIssuesOrPullsReader, repo.UpdatePullReviewRequest)
 			m.Post("/status", reqRepoIssuesOrPullsWriter, repo.UpdateIssueStatus)
@@ -766,6 +1045,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Post("/edit", bindIgnErr(auth.CreateLabelForm{}), repo.UpdateLabel)
 			m.Post("/delete", repo.DeleteLabel)
 			m.Post("/initialize", bindIgnErr(auth.InitializeLabelsForm{}), repo.InitializeLabels)
+			m.Post("/org_sync", repo.UpdateOrgLabelSync)
 		}, context.RepoMustNotBeArchived(), reqRepoIssuesOrPullsWriter, context.RepoRef())
 		m.Group("/milestones", func() {
 			m.Combo("/new").Get(repo.NewMilestone).
@@ -791,11 +1071,21 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Combo("/_upload/*", repo.MustBeAbleToUpload).
 					Get(repo.UploadFile).
 					Post(bindIgnErr(auth.UploadRepoFileForm{}), repo.UploadFilePost)
+				m.Get("/_ide/*", repo.Workspace)
+				m.Get("/_ide_files/*", repo.WorkspaceFiles)
+				m.Combo("/_ide_file/*").Get(repo.WorkspaceFile).
+					Post(bindIgnErr(auth.WorkspaceFileForm{}), repo.WorkspaceFilePost)
+				m.Post("/_ide_delete/*", repo.WorkspaceDeleteFile)
+				m.Post("/_ide_discard/*", repo.WorkspaceDiscard)
+				m.Post("/_ide_commit/*", bindIgnErr(auth.WorkspaceCommitForm{}), repo.WorkspaceCommit)
 			}, context.RepoRefByType(context.RepoRefBranch), repo.MustBeEditable)
 			m.Group("", func() {
 				m.Post("/upload-file", repo.UploadFileToServer)
 				m.Post("/upload-remove", bindIgnErr(auth.RemoveUploadFileForm{}), repo.RemoveUploadFileFromServer)
 			}, context.RepoRef(), repo.MustBeEditable, repo.MustBeAbleToUpload)
+			m.Combo("/_cherrypick/:sha([a-f0-9]{7,40})", context.RepoRef(), repo.MustBeEditable).
+				Get(repo.CherryPick).
+				Post(bindIgnErr(auth.CherryPickForm{}), repo.CherryPickPost)
 		}, context.RepoMustNotBeArchived(), reqRepoCodeWriter, repo.MustBeNotEmpty)
 
 		m.Group("/branches", func() {
@@ -805,6 +1095,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Post("/commit/*", context.RepoRefByType(context.RepoRefCommit), repo.CreateBranch)
 			}, bindIgnErr(auth.NewBranchForm{}))
 			m.Post("/delete", repo.DeleteBranchPost)
+			m.Post("/bulk_delete", repo.DeleteBranchesPost)
 			m.Post("/restore", repo.RestoreBranchPost)
 		}, context.RepoMustNotBeArchived(), reqRepoCodeWriter, repo.MustBeNotEmpty)
 
@@ -817,6 +1108,12 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/tag/:tag", repo.SingleRelease)
 			m.Get("/latest", repo.LatestRelease)
 		}, repo.MustBeNotEmpty, context.RepoRef())
+		m.Group("", func() {
+			m.Get("/releases.rss", repo.ReleasesFeedRSS)
+			m.Get("/releases.atom", repo.ReleasesFeedAtom)
+			m.Get("/tags.rss", repo.TagsFeedRSS)
+			m.Get("/tags.atom", repo.TagsFeedAtom)
+		}, repo.MustBeNotEmpty)
 		m.Group("/releases", func() {
 			m.Get("/new", repo.NewRelease)
 			m.Post("/new", bindIgnErr(auth.NewReleaseForm{}), repo.NewReleasePost)
@@ -849,8 +1146,15 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("", func() {
 			m.Get("/^:type(issues|pulls)$", repo.Issues)
 			m.Get("/^:type(issues|pulls)$/:index", repo.ViewIssue)
+			m.Get("/^:type(issues|pulls)$/:index/graph", repo.IssueGraph)
+			m.Get("/issues.rss", reqRepoIssueReader, repo.IssuesFeedRSS)
+			m.Get("/issues.atom", reqRepoIssueReader, repo.IssuesFeedAtom)
+			m.Get("/pulls.rss", reqRepoPullsReader, repo.PullsFeedRSS)
+			m.Get("/pulls.atom", reqRepoPullsReader, repo.PullsFeedAtom)
 			m.Get("/labels/", reqRepoIssuesOrPullsReader, repo.RetrieveLabels, repo.Labels)
 			m.Get("/milestones", reqRepoIssuesOrPullsReader, repo.Milestones)
+			m.Get("/epics", reqRepoIssuesOrPullsReader, repo.Epics)
+			m.Get("/epics/:id", reqRepoIssuesOrPullsReader, repo.ViewEpic)
 		}, context.RepoRef())
 
 		m.Group("/wiki", func() {
@@ -875,6 +1179,29 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/raw/*", repo.WikiRaw)
 		}, repo.MustEnableWiki)
 
+		m.Group("/discussions", func() {
+			m.Get("", repo.Discussions)
+			m.Get("/:index", repo.ViewDiscussion)
+			m.Group("", func() {
+				m.Combo("/new").Get(repo.NewDiscussion).
+					Post(bindIgnErr(auth.CreateDiscussionForm{}), repo.NewDiscussionPost)
+				m.Group("/:index", func() {
+					m.Post("/replies", bindIgnErr(auth.CreateDiscussionReplyForm{}), repo.DiscussionReplyPost)
+					m.Post("/replies/delete", repo.DeleteDiscussionReply)
+					m.Post("/answer", repo.DiscussionAnswer)
+					m.Post("/upvote", repo.DiscussionUpvote)
+					m.Post("/delete", repo.DeleteDiscussion)
+				})
+			}, reqSignIn, context.RepoMustNotBeArchived())
+			m.Group("/categories", func() {
+				m.Get("", repo.DiscussionCategories)
+				m.Post("", bindIgnErr(auth.CreateDiscussionCategoryForm{}), repo.NewDiscussionCategoryPost)
+				m.Post("/delete", repo.DeleteDiscussionCategory)
+			}, reqSignIn, context.RepoMustNotBeArchived(), reqRepoDiscussionsWriter)
+		}, context.RequireRepoReader(models.UnitTypeDiscussions), func(ctx *context.Context) {
+			ctx.Data["PageIsDiscussions"] = true
+		})
+
 		m.Group("/activity", func() {
 			m.Get("", repo.Activity)
 			m.Get("/:period", repo.Activity)
@@ -885,6 +1212,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/:period", repo.ActivityAuthors)
 		}, context.RepoRef(), repo.MustBeNotEmpty, context.RequireRepoReaderOr(models.UnitTypeCode))
 
+		m.Get("/insights", context.RepoRef(), repo.MustBeNotEmpty, reqRepoCodeReader, repo.Insights)
+
 		m.Get("/archive/*", repo.MustBeNotEmpty, reqRepoCodeReader, repo.Download)
 
 		m.Get("/status", reqRepoCodeReader, repo.Status)
@@ -897,6 +1226,12 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/:sha", repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.ExcerptBlob)
 		}, repo.MustBeNotEmpty, context.RepoRef(), reqRepoCodeReader)
 
+		m.Group("/diff_file", func() {
+			m.Get("/:sha", repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.DiffFile)
+		}, repo.MustBeNotEmpty, context.RepoRef(), reqRepoCodeReader)
+
+		m.Get("/rich_diff/:sha", repo.MustBeNotEmpty, context.RepoRef(), reqRepoCodeReader, repo.RichDiff)
+
 		m.Group("/pulls/:index", func() {
 			m.Get(".diff", repo.DownloadPullDiff)
 			m.Get(".patch", repo.DownloadPullPatch)
@@ -906,6 +1241,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Post("/cleanup", context.RepoMustNotBeArchived(), context.RepoRef(), repo.CleanUpPullRequest)
 			m.Group("/files", func() {
 				m.Get("", context.RepoRef(), repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.ViewPullFiles)
+				m.Get("/diff_file/:sha", context.RepoRef(), repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.ViewPullFileDiff)
 				m.Group("/reviews", func() {
 					m.Post("/comments", bindIgnErr(auth.CodeCommentForm{}), repo.CreateCodeComment)
 					m.Post("/submit", bindIgnErr(auth.SubmitReviewForm{}), repo.SubmitReview)
@@ -920,7 +1256,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/blob/:sha", context.RepoRefByType(context.RepoRefBlob), repo.DownloadByIDOrLFS)
 			// "/*" route is deprecated, and kept for backward compatibility
 			m.Get("/*", context.RepoRefByType(context.RepoRefLegacy), repo.SingleDownloadOrLFS)
-		}, repo.MustBeNotEmpty, reqRepoCodeReader)
+			m.Options("/*", repo.RawHeaders)
+		}, repo.MustBeNotEmpty, reqRepoCodeReader, repo.RawHeaders)
 
 		m.Group("/raw", func() {
 			m.Get("/branch/*", context.RepoRefByType(context.RepoRefBranch), repo.SingleDownload)
@@ -929,7 +1266,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/blob/:sha", context.RepoRefByType(context.RepoRefBlob), repo.DownloadByID)
 			// "/*" route is deprecated, and kept for backward compatibility
 			m.Get("/*", context.RepoRefByType(context.RepoRefLegacy), repo.SingleDownload)
-		}, repo.MustBeNotEmpty, reqRepoCodeReader)
+			m.Options("/*", repo.RawHeaders)
+		}, repo.MustBeNotEmpty, reqRepoCodeReader, repo.RawHeaders)
 
 		m.Group("/commits", func() {
 			m.Get("/branch/*", context.RepoRefByType(context.RepoRefBranch), repo.RefCommits)
@@ -939,14 +1277,26 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/*", context.RepoRefByType(context.RepoRefLegacy), repo.RefCommits)
 		}, repo.MustBeNotEmpty, reqRepoCodeReader)
 
+		m.Group("/embed", func() {
+			m.Get("/commit/*", repo.EmbedSnippet)
+			m.Get("/blob/:sha", repo.EmbedSnippet)
+		}, repo.MustBeNotEmpty, reqRepoCodeReader)
+
 		m.Group("/blame", func() {
 			m.Get("/branch/*", context.RepoRefByType(context.RepoRefBranch), repo.RefBlame)
 			m.Get("/tag/*", context.RepoRefByType(context.RepoRefTag), repo.RefBlame)
 			m.Get("/commit/*", context.RepoRefByType(context.RepoRefCommit), repo.RefBlame)
 		}, repo.MustBeNotEmpty, reqRepoCodeReader)
 
+		m.Group("/pages", func() {
+			m.Get("", pages.Serve)
+			m.Get("/*", pages.Serve)
+		}, reqRepoCodeReader)
+
 		m.Group("", func() {
 			m.Get("/graph", repo.Graph)
+			m.Get("/network", repo.Network)
+			m.Get("/network/graph", repo.NetworkGraph)
 			m.Get("/commit/:sha([a-f0-9]{7,40})$", repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.Diff)
 		}, repo.MustBeNotEmpty, context.RepoRef(), reqRepoCodeReader)
 
@@ -967,6 +1317,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 	m.Group("/:username/:reponame", func() {
 		m.Get("/stars", repo.Stars)
 		m.Get("/watchers", repo.Watchers)
+		m.Get("/cla", repo.ContributorAgreement)
 		m.Get("/search", reqRepoCodeReader, repo.Search)
 	}, ignSignIn, context.RepoAssignment(), context.RepoRef(), context.UnitTypes())
 
@@ -1025,11 +1376,15 @@ func RegisterRoutes(m *macaron.Macaron) {
 		apiv1.RegisterRoutes(m)
 	}, handlers...)
 
+	m.Get("/api/oembed", ignSignIn, repo.OEmbed)
+
 	m.Group("/api/internal", func() {
 		// package name internal is ideal but Golang is not allowed, so we use private as package name.
 		private.RegisterRoutes(m)
 	})
 
+	m.Get("/themes/:name/:version/theme.css", routers.ThemeCSS)
+
 	// robots.txt
 	m.Get("/robots.txt", func(ctx *context.Context) {
 		if setting.HasRobotsTxt {
@@ -1052,6 +1407,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 	if setting.Metrics.Enabled {
 		c := metrics.NewCollector()
 		prometheus.MustRegister(c)
+		metrics.Init()
 
 		m.Get("/metrics", routers.Metrics)
 	}
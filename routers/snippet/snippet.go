@@ -0,0 +1,394 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package snippet
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/highlight"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	snippet_service "code.gitea.io/gitea/services/snippet"
+)
+
+const (
+	tplSnippets         base.TplName = "snippet/list"
+	tplNewSnippet       base.TplName = "snippet/new"
+	tplViewSnippet      base.TplName = "snippet/view"
+	tplSnippetRevisions base.TplName = "snippet/revisions"
+)
+
+// renderedFile is a file of a snippet prepared for the view page
+type renderedFile struct {
+	Name     string
+	RawLink  string
+	IsMarkup bool
+	Rendered string
+	Lines    map[int]string
+}
+
+// MustEnableSnippets checks that the snippets are enabled
+func MustEnableSnippets(ctx *context.Context) {
+	if !setting.Snippet.Enabled {
+		ctx.NotFound("MustEnableSnippets", nil)
+		return
+	}
+	ctx.Data["PageIsSnippets"] = true
+	ctx.Data["MaxFiles"] = setting.Snippet.MaxFiles
+	ctx.Data["MaxFileSize"] = setting.Snippet.MaxFileSize
+}
+
+// SnippetAssignment loads the snippet whose ID is in the URL, which the doer must be able to see
+func SnippetAssignment(ctx *context.Context) {
+	s, err := models.GetSnippetByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrSnippetNotExist(err) {
+			ctx.NotFound("GetSnippetByID", err)
+		} else {
+			ctx.ServerError("GetSnippetByID", err)
+		}
+		return
+	}
+	if !s.CanBeReadBy(ctx.User) {
+		ctx.NotFound("CanBeReadBy", nil)
+		return
+	}
+	ctx.Data["Snippet"] = s
+	ctx.Data["Title"] = s.Title
+	ctx.Data["CanEditSnippet"] = s.CanBeEditedBy(ctx.User)
+}
+
+// reqSnippetEditor checks that the doer can change the snippet
+func reqSnippetEditor(ctx *context.Context) *models.Snippet {
+	s := ctx.Data["Snippet"].(*models.Snippet)
+	if !s.CanBeEditedBy(ctx.User) {
+		ctx.NotFound("CanBeEditedBy", nil)
+		return nil
+	}
+	return s
+}
+
+// Snippets render the snippets visible to the doer, optionally those of one user
+func Snippets(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("snippets")
+
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	keyword := strings.TrimSpace(ctx.Query("q"))
+	opts := &models.SearchSnippetOptions{
+		ListOptions: models.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.IssuePagingNum,
+		},
+		Actor:   ctx.User,
+		Keyword: keyword,
+	}
+	if ownerName := ctx.Query("owner"); ownerName != "" {
+		owner, err := models.GetUserByName(ownerName)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.NotFound("GetUserByName", err)
+			} else {
+				ctx.ServerError("GetUserByName", err)
+			}
+			return
+		}
+		opts.OwnerID = owner.ID
+		ctx.Data["Owner"] = owner
+	}
+
+	snippets, count, err := models.SearchSnippets(opts)
+	if err != nil {
+		ctx.ServerError("SearchSnippets", err)
+		return
+	}
+	ctx.Data["Snippets"] = snippets
+	ctx.Data["Keyword"] = keyword
+
+	pager := context.NewPagination(int(count), setting.UI.IssuePagingNum, page, 5)
+	pager.SetDefaultParams(ctx)
+	pager.AddParam(ctx, "owner", "OwnerName")
+	ctx.Data["OwnerName"] = ctx.Query("owner")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(200, tplSnippets)
+}
+
+// formFiles returns the files given by the form, the files without name and content are ignored
+func formFiles(form *auth.SnippetForm) []*snippet_service.File {
+	files := make([]*snippet_service.File, 0, len(form.FileName))
+	for i, name := range form.FileName {
+		var content string
+		if i < len(form.FileContent) {
+			content = form.FileContent[i]
+		}
+		name = strings.TrimSpace(name)
+		if name == "" && content == "" {
+			continue
+		}
+		files = append(files, &snippet_service.File{Name: name, Content: content})
+	}
+	return files
+}
+
+// renderSnippetFormError renders the form of the snippet again with the error of its files
+func renderSnippetFormError(ctx *context.Context, form *auth.SnippetForm, err error) {
+	if snippet_service.IsErrInvalidFiles(err) {
+		ctx.Data["Files"] = formFiles(form)
+		ctx.RenderWithErr(ctx.Tr("snippets.invalid_files", err.(snippet_service.ErrInvalidFiles).Name, setting.Snippet.MaxFiles, base.FileSize(setting.Snippet.MaxFileSize)), tplNewSnippet, form)
+		return
+	}
+	ctx.ServerError("SaveSnippet", err)
+}
+
+// NewSnippet render creating snippet page
+func NewSnippet(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("snippets.new")
+	ctx.Data["visibility"] = "public"
+	ctx.Data["Files"] = []*snippet_service.File{{}}
+	ctx.HTML(200, tplNewSnippet)
+}
+
+// NewSnippetPost response for creating a snippet
+func NewSnippetPost(ctx *context.Context, form auth.SnippetForm) {
+	ctx.Data["Title"] = ctx.Tr("snippets.new")
+
+	if ctx.HasError() {
+		ctx.Data["Files"] = formFiles(&form)
+		ctx.HTML(200, tplNewSnippet)
+		return
+	}
+
+	s, err := snippet_service.Create(ctx.User, snippet_service.Options{
+		Title:       form.Title,
+		Description: form.Description,
+		Visibility:  api.VisibilityModes[form.Visibility],
+		Files:       formFiles(&form),
+	})
+	if err != nil {
+		renderSnippetFormError(ctx, &form, err)
+		return
+	}
+	ctx.Redirect(s.Link())
+}
+
+// ViewSnippet render the files of a revision of a snippet and its comments
+func ViewSnippet(ctx *context.Context) {
+	s := ctx.Data["Snippet"].(*models.Snippet)
+	revision := ctx.Query("revision")
+
+	files, err := snippet_service.GetFiles(s, revision)
+	if err != nil {
+		if snippet_service.IsErrRevisionNotExist(err) {
+			ctx.NotFound("GetFiles", err)
+		} else {
+			ctx.ServerError("GetFiles", err)
+		}
+		return
+	}
+
+	rendered := make([]*renderedFile, len(files))
+	for i, f := range files {
+		rf := &renderedFile{
+			Name:    f.Name,
+			RawLink: s.Link() + "/raw/" + url.PathEscape(f.Name),
+		}
+		if revision != "" {
+			rf.RawLink += "?revision=" + url.QueryEscape(revision)
+		}
+		if markup.Type(f.Name) != "" {
+			rf.IsMarkup = true
+			rf.Rendered = string(markup.Render(f.Name, []byte(f.Content), "", nil))
+		} else {
+			numLines := strings.Count(f.Content, "\n")
+			if !strings.HasSuffix(f.Content, "\n") {
+				numLines++
+			}
+			rf.Lines = highlight.File(numLines, f.Name, []byte(f.Content))
+		}
+		rendered[i] = rf
+	}
+	ctx.Data["Files"] = rendered
+	ctx.Data["Revision"] = revision
+	ctx.Data["RenderedDescription"] = string(markdown.Render([]byte(s.Description), "", nil))
+
+	comments, err := models.GetSnippetComments(s.ID, models.ListOptions{})
+	if err != nil {
+		ctx.ServerError("GetSnippetComments", err)
+		return
+	}
+	for _, c := range comments {
+		c.RenderedContent = string(markdown.Render([]byte(c.Content), "", nil))
+	}
+	ctx.Data["Comments"] = comments
+
+	ctx.HTML(200, tplViewSnippet)
+}
+
+// RawSnippetFile serves the content of a file of a revision of a snippet
+func RawSnippetFile(ctx *context.Context) {
+	s := ctx.Data["Snippet"].(*models.Snippet)
+
+	files, err := snippet_service.GetFiles(s, ctx.Query("revision"))
+	if err != nil {
+		if snippet_service.IsErrRevisionNotExist(err) {
+			ctx.NotFound("GetFiles", err)
+		} else {
+			ctx.ServerError("GetFiles", err)
+		}
+		return
+	}
+	name := ctx.Params(":filename")
+	for _, f := range files {
+		if f.Name == name {
+			ctx.Resp.Header().Set("X-Content-Type-Options", "nosniff")
+			ctx.PlainText(200, []byte(f.Content))
+			return
+		}
+	}
+	ctx.NotFound("RawSnippetFile", nil)
+}
+
+// SnippetRevisions render the revisions of a snippet
+func SnippetRevisions(ctx *context.Context) {
+	s := ctx.Data["Snippet"].(*models.Snippet)
+
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	revisions, count, err := snippet_service.GetRevisions(s, page, git.CommitsRangeSize)
+	if err != nil {
+		ctx.ServerError("GetRevisions", err)
+		return
+	}
+	ctx.Data["Revisions"] = revisions
+
+	pager := context.NewPagination(int(count), git.CommitsRangeSize, page, 5)
+	pager.SetDefaultParams(ctx)
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(200, tplSnippetRevisions)
+}
+
+// EditSnippet render editing snippet page
+func EditSnippet(ctx *context.Context) {
+	s := reqSnippetEditor(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	files, err := snippet_service.GetFiles(s, "")
+	if err != nil {
+		ctx.ServerError("GetFiles", err)
+		return
+	}
+	ctx.Data["PageIsEditSnippet"] = true
+	ctx.Data["title"] = s.Title
+	ctx.Data["description"] = s.Description
+	ctx.Data["visibility"] = s.Visibility.String()
+	ctx.Data["Files"] = files
+	ctx.HTML(200, tplNewSnippet)
+}
+
+// EditSnippetPost response for editing a snippet
+func EditSnippetPost(ctx *context.Context, form auth.SnippetForm) {
+	s := reqSnippetEditor(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["PageIsEditSnippet"] = true
+
+	if ctx.HasError() {
+		ctx.Data["Files"] = formFiles(&form)
+		ctx.HTML(200, tplNewSnippet)
+		return
+	}
+
+	if err := snippet_service.Update(ctx.User, s, snippet_service.Options{
+		Title:       form.Title,
+		Description: form.Description,
+		Visibility:  api.VisibilityModes[form.Visibility],
+		Files:       formFiles(&form),
+	}); err != nil {
+		renderSnippetFormError(ctx, &form, err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("snippets.update_success"))
+	ctx.Redirect(s.Link())
+}
+
+// DeleteSnippet removes a snippet
+func DeleteSnippet(ctx *context.Context) {
+	s := reqSnippetEditor(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := snippet_service.Delete(s); err != nil {
+		ctx.ServerError("Delete", err)
+		return
+	}
+	log.Trace("Snippet %d deleted by %s", s.ID, ctx.User.Name)
+
+	ctx.Flash.Success(ctx.Tr("snippets.deletion_success"))
+	ctx.Redirect(setting.AppSubURL + "/-/snippets?owner=" + url.QueryEscape(s.Owner.Name))
+}
+
+// NewSnippetComment response for commenting on a snippet
+func NewSnippetComment(ctx *context.Context, form auth.SnippetCommentForm) {
+	s := ctx.Data["Snippet"].(*models.Snippet)
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(s.Link())
+		return
+	}
+
+	c, err := models.CreateSnippetComment(s, ctx.User, form.Content)
+	if err != nil {
+		ctx.ServerError("CreateSnippetComment", err)
+		return
+	}
+	ctx.Redirect(fmt.Sprintf("%s#%s", s.Link(), c.HashTag()))
+}
+
+// DeleteSnippetComment removes a comment of a snippet, by its poster or by the owner of the snippet
+func DeleteSnippetComment(ctx *context.Context) {
+	s := ctx.Data["Snippet"].(*models.Snippet)
+
+	c, err := models.GetSnippetCommentByID(s.ID, ctx.ParamsInt64(":comment_id"))
+	if err != nil {
+		if models.IsErrSnippetCommentNotExist(err) {
+			ctx.NotFound("GetSnippetCommentByID", err)
+		} else {
+			ctx.ServerError("GetSnippetCommentByID", err)
+		}
+		return
+	}
+	if c.PosterID != ctx.User.ID && !s.CanBeEditedBy(ctx.User) {
+		ctx.NotFound("DeleteSnippetComment", nil)
+		return
+	}
+
+	if err := models.DeleteSnippetComment(c); err != nil {
+		ctx.ServerError("DeleteSnippetComment", err)
+		return
+	}
+	ctx.Redirect(s.Link())
+}
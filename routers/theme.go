@@ -0,0 +1,33 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routers
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// ThemeCSS serves the stylesheet of the version in use of an uploaded theme
+func ThemeCSS(ctx *context.Context) {
+	css, err := models.GetUploadedThemeCSS(ctx.Params(":name"), ctx.ParamsInt(":version"))
+	if err != nil {
+		if models.IsErrThemeNotExist(err) {
+			ctx.NotFound("GetUploadedThemeCSS", err)
+		} else {
+			ctx.ServerError("GetUploadedThemeCSS", err)
+		}
+		return
+	}
+	// the link changes with the version
+	ctx.Resp.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	ctx.Resp.Header().Set("Content-Type", "text/css; charset=utf-8")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if _, err := ctx.Resp.Write([]byte(css)); err != nil {
+		log.Error("Write: %v", err)
+	}
+}
@@ -6,13 +6,18 @@ package user
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"os"
 	"strconv"
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/avatar"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 )
 
 // Avatar redirect browser to user avatar of requested size
@@ -66,3 +71,54 @@ func AvatarByEmailHash(ctx *context.Context) {
 	}
 	ctx.Redirect(base.SizedAvatarLinkWithDomain(email, size))
 }
+
+// serveAvatarVariant serves the variant of an avatar in the requested size, generating it on
+// the first request. It is a WebP image if the client accepts one.
+func serveAvatarVariant(ctx *context.Context, kind, dir string) {
+	name := ctx.Params(":name")
+	size, err := strconv.Atoi(ctx.Params(":size"))
+	if err != nil {
+		ctx.NotFound("Invalid avatar size", err)
+		return
+	}
+	format := avatar.NegotiateFormat(ctx.Req.Header.Get("Accept"))
+	variant, err := avatar.Variant(kind, dir, name, size, format)
+	if err != nil {
+		if os.IsNotExist(err) {
+			ctx.NotFound("Variant", err)
+		} else {
+			ctx.ServerError("Variant", err)
+		}
+		return
+	}
+	f, err := os.Open(variant)
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		ctx.ServerError("Stat", err)
+		return
+	}
+
+	if avatar.IsContentAddressed(name) {
+		ctx.Resp.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		ctx.Resp.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(setting.StaticCacheTime.Seconds())))
+	}
+	ctx.Resp.Header().Set("Content-Type", "image/"+format)
+	ctx.Resp.Header().Add("Vary", "Accept")
+	http.ServeContent(ctx.Resp, ctx.Req.Request, fi.Name(), fi.ModTime(), f)
+}
+
+// AvatarVariant serves a user avatar in one of the variant sizes
+func AvatarVariant(ctx *context.Context) {
+	serveAvatarVariant(ctx, avatar.KindUser, setting.AvatarUploadPath)
+}
+
+// RepoAvatarVariant serves a repository avatar in one of the variant sizes
+func RepoAvatarVariant(ctx *context.Context) {
+	serveAvatarVariant(ctx, avatar.KindRepo, setting.RepositoryAvatarUploadPath)
+}
@@ -116,6 +116,9 @@ func Dashboard(ctx *context.Context) {
 	// so everyone would get the same empty heatmap
 	ctx.Data["EnableHeatmap"] = setting.Service.EnableUserHeatmap && !ctxUser.KeepActivityPrivate
 	ctx.Data["HeatmapUser"] = ctxUser.Name
+	if !ctxUser.IsOrganization() {
+		prepareDashboardWidgets(ctx)
+	}
 
 	var err error
 	var mirrors []*models.Repository
@@ -161,6 +164,24 @@ func Dashboard(ctx *context.Context) {
 	ctx.HTML(200, tplDashboard)
 }
 
+// prepareDashboardWidgets sets the widgets of the dashboard of the signed in user, their
+// content is loaded by the browser.
+func prepareDashboardWidgets(ctx *context.Context) {
+	widgets := ctx.User.GetDashboardWidgets()
+	shown := make(map[string]bool, len(widgets))
+	for _, widget := range widgets {
+		shown[widget] = true
+	}
+	available := make([]string, 0, len(models.DashboardWidgets))
+	for _, widget := range models.DashboardWidgets {
+		if !shown[widget] {
+			available = append(available, widget)
+		}
+	}
+	ctx.Data["DashboardWidgets"] = widgets
+	ctx.Data["AvailableDashboardWidgets"] = available
+}
+
 // Milestones render the user milestones page
 func Milestones(ctx *context.Context) {
 	if models.UnitTypeIssues.UnitGlobalDisabled() && models.UnitTypePullRequests.UnitGlobalDisabled() {
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -15,6 +16,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 )
 
 const (
@@ -56,6 +58,18 @@ func Notifications(c *context.Context) {
 	c.HTML(http.StatusOK, tplNotification)
 }
 
+// notificationFilters returns the options of the repository and reason filters of the request
+func notificationFilters(c *context.Context) models.FindNotificationOptions {
+	opts := models.FindNotificationOptions{
+		UserID: c.User.ID,
+		RepoID: c.QueryInt64("repo"),
+	}
+	if reason := models.ParseNotificationReason(c.Query("reason")); reason > 0 {
+		opts.Reasons = []models.NotificationReason{reason}
+	}
+	return opts
+}
+
 func getNotifications(c *context.Context) {
 	var (
 		keyword = strings.Trim(c.Query("q"), " ")
@@ -70,33 +84,72 @@ func getNotifications(c *context.Context) {
 		perPage = 20
 	}
 
+	opts := notificationFilters(c)
 	switch keyword {
+	case "saved":
+		opts.IsSaved = util.OptionalBoolTrue
 	case "read":
 		status = models.NotificationStatusRead
 	default:
 		status = models.NotificationStatusUnread
 	}
+	if status > 0 {
+		opts.Status = []models.NotificationStatus{status, models.NotificationStatusPinned}
+	}
 
-	total, err := models.GetNotificationCount(c.User, status)
+	total, err := models.CountNotifications(opts)
 	if err != nil {
-		c.ServerError("ErrGetNotificationCount", err)
+		c.ServerError("CountNotifications", err)
 		return
 	}
 
+	c.Data["RepoID"] = opts.RepoID
+	c.Data["Reason"] = ""
+	if len(opts.Reasons) > 0 {
+		c.Data["Reason"] = opts.Reasons[0].String()
+	}
+
 	// redirect to last page if request page is more than total pages
 	pager := context.NewPagination(int(total), perPage, page, 5)
+	pager.SetDefaultParams(c)
+	if opts.RepoID > 0 {
+		pager.AddParam(c, "repo", "RepoID")
+	}
+	if len(opts.Reasons) > 0 {
+		pager.AddParam(c, "reason", "Reason")
+	}
 	if pager.Paginater.Current() < page {
-		c.Redirect(fmt.Sprintf("/notifications?q=%s&page=%d", c.Query("q"), pager.Paginater.Current()))
+		c.Redirect(fmt.Sprintf("%s/notifications?%s&page=%d", setting.AppSubURL, pager.GetParams(), pager.Paginater.Current()))
 		return
 	}
 
-	statuses := []models.NotificationStatus{status, models.NotificationStatusPinned}
-	notifications, err := models.NotificationsForUser(c.User, statuses, page, perPage)
+	opts.ListOptions = models.ListOptions{Page: page, PageSize: perPage}
+	notifications, err := models.GetNotifications(opts)
 	if err != nil {
-		c.ServerError("ErrNotificationsForUser", err)
+		c.ServerError("GetNotifications", err)
 		return
 	}
 
+	repoIDs, err := models.GetNotificationRepoIDs(c.User.ID)
+	if err != nil {
+		c.ServerError("GetNotificationRepoIDs", err)
+		return
+	}
+	reposMap, err := models.GetRepositoriesMapByIDs(repoIDs)
+	if err != nil {
+		c.ServerError("GetRepositoriesMapByIDs", err)
+		return
+	}
+	filterRepos := make([]*models.Repository, 0, len(reposMap))
+	for _, repo := range reposMap {
+		filterRepos = append(filterRepos, repo)
+	}
+	sort.Slice(filterRepos, func(i, j int) bool {
+		return filterRepos[i].FullName() < filterRepos[j].FullName()
+	})
+	c.Data["FilterRepos"] = filterRepos
+	c.Data["NotificationReasons"] = models.NotificationReasons
+
 	failCount := 0
 
 	repos, failures, err := notifications.LoadRepos()
@@ -119,6 +172,14 @@ func getNotifications(c *context.Context) {
 	notifications = notifications.Without(failures)
 	failCount += len(failures)
 
+	failures, err = notifications.LoadDiscussions()
+	if err != nil {
+		c.ServerError("LoadDiscussions", err)
+		return
+	}
+	notifications = notifications.Without(failures)
+	failCount += len(failures)
+
 	failures, err = notifications.LoadComments()
 	if err != nil {
 		c.ServerError("LoadComments", err)
@@ -135,8 +196,7 @@ func getNotifications(c *context.Context) {
 	c.Data["Keyword"] = keyword
 	c.Data["Status"] = status
 	c.Data["Notifications"] = notifications
-
-	pager.SetDefaultParams(c)
+	c.Data["IsFiltered"] = opts.RepoID > 0 || len(opts.Reasons) > 0
 	c.Data["Page"] = pager
 }
 
@@ -149,6 +209,11 @@ func NotificationStatusPost(c *context.Context) {
 	)
 
 	switch statusStr {
+	case "saved", "unsaved":
+		if err := models.SetNotificationSaved(notificationID, c.User, statusStr == "saved"); err != nil {
+			c.ServerError("SetNotificationSaved", err)
+			return
+		}
 	case "read":
 		status = models.NotificationStatusRead
 	case "unread":
@@ -160,9 +225,11 @@ func NotificationStatusPost(c *context.Context) {
 		return
 	}
 
-	if err := models.SetNotificationStatus(notificationID, c.User, status); err != nil {
-		c.ServerError("SetNotificationStatus", err)
-		return
+	if status > 0 {
+		if err := models.SetNotificationStatus(notificationID, c.User, status); err != nil {
+			c.ServerError("SetNotificationStatus", err)
+			return
+		}
 	}
 
 	if !c.QueryBool("noredirect") {
@@ -178,14 +245,20 @@ func NotificationStatusPost(c *context.Context) {
 	c.HTML(http.StatusOK, tplNotificationDiv)
 }
 
-// NotificationPurgePost is a route for 'purging' the list of notifications - marking all unread as read
+// NotificationPurgePost is a route for 'purging' the list of notifications - marking all unread
+// notifications matching the repository and reason filters as read
 func NotificationPurgePost(c *context.Context) {
-	err := models.UpdateNotificationStatuses(c.User, models.NotificationStatusUnread, models.NotificationStatusRead)
-	if err != nil {
-		c.ServerError("ErrUpdateNotificationStatuses", err)
+	opts := notificationFilters(c)
+	opts.Status = []models.NotificationStatus{models.NotificationStatusUnread}
+	if err := models.UpdateNotificationStatusesByFilter(c.User, opts, models.NotificationStatusRead); err != nil {
+		c.ServerError("UpdateNotificationStatusesByFilter", err)
 		return
 	}
 
-	url := fmt.Sprintf("%s/notifications", setting.AppSubURL)
+	var reason string
+	if len(opts.Reasons) > 0 {
+		reason = opts.Reasons[0].String()
+	}
+	url := fmt.Sprintf("%s/notifications?repo=%d&reason=%s", setting.AppSubURL, opts.RepoID, reason)
 	c.Redirect(url, http.StatusSeeOther)
 }
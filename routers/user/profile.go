@@ -15,6 +15,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/org"
+	"code.gitea.io/gitea/routers/repo"
 )
 
 // GetUserByName get user by name
@@ -93,6 +94,22 @@ func Profile(ctx *context.Context) {
 	ctx.Data["PageIsUserProfile"] = true
 	ctx.Data["Owner"] = ctxUser
 	ctx.Data["OpenIDs"] = openIDs
+
+	if ctxUser.IsBot() {
+		bot, err := models.GetOrgBotByBotID(ctxUser.ID)
+		if err != nil {
+			ctx.ServerError("GetOrgBotByBotID", err)
+			return
+		}
+		botOrg, err := models.GetUserByID(bot.OrgID)
+		if err != nil {
+			ctx.ServerError("GetUserByID", err)
+			return
+		}
+		if models.HasOrgVisible(botOrg, ctx.User) {
+			ctx.Data["BotOrg"] = botOrg
+		}
+	}
 	// no heatmap access for admins; GetUserHeatmapDataByUser ignores the calling user
 	// so everyone would get the same empty heatmap
 	ctx.Data["EnableHeatmap"] = setting.Service.EnableUserHeatmap && !ctxUser.KeepActivityPrivate
@@ -108,6 +125,13 @@ func Profile(ctx *context.Context) {
 	ctx.Data["Orgs"] = orgs
 	ctx.Data["HasOrgsVisible"] = models.HasOrgsVisible(orgs, ctx.User)
 
+	badges, err := models.GetUserBadges(ctxUser.ID)
+	if err != nil {
+		ctx.ServerError("GetUserBadges", err)
+		return
+	}
+	ctx.Data["Badges"] = badges
+
 	tab := ctx.Query("tab")
 	ctx.Data["TabName"] = tab
 
@@ -191,6 +215,35 @@ func Profile(ctx *context.Context) {
 		}
 	case "stars":
 		ctx.Data["PageIsProfileStarList"] = true
+		isOwner := ctx.IsSigned && ctx.User.ID == ctxUser.ID
+		ctx.Data["IsStarListsOwner"] = isOwner
+		starLists, err := models.GetStarLists(ctxUser.ID, showPrivate)
+		if err != nil {
+			ctx.ServerError("GetStarLists", err)
+			return
+		}
+		ctx.Data["StarLists"] = starLists
+
+		var starList *models.StarList
+		if listID := ctx.QueryInt64("list"); listID > 0 {
+			for _, list := range starLists {
+				if list.ID == listID {
+					starList = list
+					break
+				}
+			}
+			if starList == nil {
+				ctx.NotFound("GetStarLists", nil)
+				return
+			}
+			ctx.Data["StarList"] = starList
+			ctx.Data["StarListID"] = starList.ID
+		}
+
+		starListID := int64(0)
+		if starList != nil {
+			starListID = starList.ID
+		}
 		repos, count, err = models.SearchRepository(&models.SearchRepoOptions{
 			ListOptions: models.ListOptions{
 				PageSize: setting.UI.User.RepoPagingNum,
@@ -201,6 +254,7 @@ func Profile(ctx *context.Context) {
 			OrderBy:            orderBy,
 			Private:            ctx.IsSigned,
 			StarredByID:        ctxUser.ID,
+			StarListID:         starListID,
 			Collaborate:        util.OptionalBoolFalse,
 			TopicOnly:          topicOnly,
 			IncludeDescription: setting.UI.SearchRepoDescription,
@@ -210,8 +264,38 @@ func Profile(ctx *context.Context) {
 			return
 		}
 
+		if isOwner && len(starLists) > 0 && len(repos) > 0 {
+			repoIDs := make([]int64, len(repos))
+			for i := range repos {
+				repoIDs[i] = repos[i].ID
+			}
+			listIDs, err := models.GetStarListIDsByRepos(ctxUser.ID, repoIDs)
+			if err != nil {
+				ctx.ServerError("GetStarListIDsByRepos", err)
+				return
+			}
+			repoStarLists := make(map[int64]map[int64]bool, len(listIDs))
+			for repoID, ids := range listIDs {
+				repoStarLists[repoID] = make(map[int64]bool, len(ids))
+				for _, id := range ids {
+					repoStarLists[repoID][id] = true
+				}
+			}
+			ctx.Data["RepoStarLists"] = repoStarLists
+		}
+
 		total = int(count)
 	default:
+		if page == 1 && len(keyword) == 0 {
+			pinnedRepos, err := models.GetPinnedRepos(ctxUser.ID, ctx.User)
+			if err != nil {
+				ctx.ServerError("GetPinnedRepos", err)
+				return
+			}
+			ctx.Data["PinnedRepos"] = pinnedRepos
+			repo.RenderProfileReadme(ctx, ctxUser)
+		}
+
 		repos, count, err = models.SearchRepository(&models.SearchRepoOptions{
 			ListOptions: models.ListOptions{
 				PageSize: setting.UI.User.RepoPagingNum,
@@ -238,6 +322,7 @@ func Profile(ctx *context.Context) {
 
 	pager := context.NewPagination(total, setting.UI.User.RepoPagingNum, page, 5)
 	pager.SetDefaultParams(ctx)
+	pager.AddParam(ctx, "list", "StarListID")
 	ctx.Data["Page"] = pager
 
 	ctx.Data["ShowUserEmail"] = len(ctxUser.Email) > 0 && ctx.IsSigned && (!ctxUser.KeepEmailPrivate || ctxUser.ID == ctx.User.ID)
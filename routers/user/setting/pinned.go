@@ -0,0 +1,85 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// PreparePinnedRepos loads the repositories pinned by the owner for the
+// settings page
+func PreparePinnedRepos(ctx *context.Context, owner *models.User) {
+	pinnedRepos, err := models.GetPinnedRepos(owner.ID, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetPinnedRepos", err)
+		return
+	}
+	pinnedRepoIDs := make(map[int64]bool, len(pinnedRepos))
+	for _, repo := range pinnedRepos {
+		pinnedRepoIDs[repo.ID] = true
+	}
+
+	ctx.Data["PinnedRepos"] = pinnedRepos
+	ctx.Data["PinnedRepoIDs"] = pinnedRepoIDs
+	ctx.Data["CanPinRepos"] = len(pinnedRepos) < models.MaxPinnedRepos
+	ctx.Data["MaxPinnedRepos"] = models.MaxPinnedRepos
+}
+
+// UpdatePinnedRepos pins, unpins or moves a repository of the owner as
+// requested by the settings page. The result is flashed for the redirect.
+func UpdatePinnedRepos(ctx *context.Context, owner *models.User) {
+	var repo *models.Repository
+	var err error
+	if name := ctx.QueryTrim("repo_name"); len(name) > 0 {
+		repo, err = models.GetRepositoryByName(owner.ID, name)
+	} else {
+		repo, err = models.GetRepositoryByID(ctx.QueryInt64("repo_id"))
+	}
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("settings.pinned_repos.not_owned"))
+			return
+		}
+		ctx.ServerError("GetRepository", err)
+		return
+	}
+
+	switch ctx.Query("action") {
+	case "pin":
+		err = models.PinRepo(owner, repo.ID)
+	case "unpin":
+		err = models.UnpinRepo(owner, repo.ID)
+	case "up":
+		err = models.MovePinnedRepo(owner, repo.ID, -1)
+	case "down":
+		err = models.MovePinnedRepo(owner, repo.ID, 1)
+	default:
+		ctx.NotFound("UpdatePinnedRepos", nil)
+		return
+	}
+	if err != nil {
+		switch {
+		case models.IsErrTooManyPinnedRepos(err):
+			ctx.Flash.Error(ctx.Tr("settings.pinned_repos.too_many", models.MaxPinnedRepos))
+		case models.IsErrPinnedRepoNotOwned(err):
+			ctx.Flash.Error(ctx.Tr("settings.pinned_repos.not_owned"))
+		default:
+			ctx.ServerError("UpdatePinnedRepos", err)
+		}
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("settings.pinned_repos.update_success"))
+}
+
+// PinnedReposPost response for pinning the repositories of the user
+func PinnedReposPost(ctx *context.Context) {
+	UpdatePinnedRepos(ctx, ctx.User)
+	if ctx.Written() {
+		return
+	}
+	ctx.Redirect(setting.AppSubURL + "/user/settings/repos")
+}
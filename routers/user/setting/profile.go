@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth"
@@ -74,6 +75,25 @@ func handleUsernameChange(ctx *context.Context, newName string) {
 	ctx.User.LowerName = strings.ToLower(newName)
 }
 
+// setAwayPeriod sets the out of office period of the user from the dates of
+// the form. Both dates must be given, or none to clear the period.
+func setAwayPeriod(u *models.User, from, until string) bool {
+	if len(from) == 0 && len(until) == 0 {
+		u.SetAwayPeriod(time.Time{}, time.Time{})
+		return true
+	}
+	fromTime, err := time.ParseInLocation("2006-01-02", from, time.Local)
+	if err != nil {
+		return false
+	}
+	untilTime, err := time.ParseInLocation("2006-01-02", until, time.Local)
+	if err != nil || untilTime.Before(fromTime) {
+		return false
+	}
+	u.SetAwayPeriod(fromTime, untilTime)
+	return true
+}
+
 // ProfilePost response for change user's profile
 func ProfilePost(ctx *context.Context, form auth.UpdateProfileForm) {
 	ctx.Data["Title"] = ctx.Tr("settings")
@@ -97,6 +117,14 @@ func ProfilePost(ctx *context.Context, form auth.UpdateProfileForm) {
 	ctx.User.Language = form.Language
 	ctx.User.Description = form.Description
 	ctx.User.KeepActivityPrivate = form.KeepActivityPrivate
+	ctx.User.IsUnavailable = form.IsUnavailable
+	if !setAwayPeriod(ctx.User, form.AwayFrom, form.AwayUntil) {
+		ctx.Flash.Error(ctx.Tr("settings.away_invalid_period"))
+		ctx.Redirect(setting.AppSubURL + "/user/settings")
+		return
+	}
+	ctx.User.AwayMessage = form.AwayMessage
+	ctx.User.AwayAutoReply = form.AwayAutoReply
 	if err := models.UpdateUserSetting(ctx.User); err != nil {
 		if _, ok := err.(models.ErrEmailAlreadyUsed); ok {
 			ctx.Flash.Error(ctx.Tr("form.email_been_used"))
@@ -221,8 +249,14 @@ func Repos(ctx *context.Context) {
 		}
 	}
 
+	PreparePinnedRepos(ctx, ctxUser)
+	if ctx.Written() {
+		return
+	}
+
 	ctx.Data["Owner"] = ctxUser
 	ctx.Data["Repos"] = repos
+	ctx.Data["PinnedReposLink"] = setting.AppSubURL + "/user/settings/repos/pinned"
 
 	ctx.HTML(200, tplSettingsRepositories)
 }
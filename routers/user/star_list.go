@@ -0,0 +1,155 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// starListsLink returns the link to the stars of the signed in user, in the star list if given
+func starListsLink(ctx *context.Context, list *models.StarList) string {
+	link := ctx.User.HomeLink() + "?tab=stars"
+	if list != nil {
+		link += fmt.Sprintf("&list=%d", list.ID)
+	}
+	return link
+}
+
+// getStarList returns the star list of the signed in user whose ID is in the URL
+func getStarList(ctx *context.Context) *models.StarList {
+	list, err := models.GetStarListByID(ctx.User.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrStarListNotExist(err) {
+			ctx.NotFound("GetStarListByID", err)
+		} else {
+			ctx.ServerError("GetStarListByID", err)
+		}
+		return nil
+	}
+	return list
+}
+
+// CreateStarListPost response for creating a star list
+func CreateStarListPost(ctx *context.Context, form auth.StarListForm) {
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(starListsLink(ctx, nil))
+		return
+	}
+
+	list := &models.StarList{
+		UserID:      ctx.User.ID,
+		Name:        form.Name,
+		Description: form.Description,
+		IsPrivate:   form.IsPrivate,
+	}
+	if err := models.CreateStarList(list); err != nil {
+		if models.IsErrStarListAlreadyExist(err) {
+			ctx.Flash.Error(ctx.Tr("user.star_list.already_exists", form.Name))
+			ctx.Redirect(starListsLink(ctx, nil))
+		} else {
+			ctx.ServerError("CreateStarList", err)
+		}
+		return
+	}
+
+	log.Trace("Star list created: %d/%d", ctx.User.ID, list.ID)
+	ctx.Flash.Success(ctx.Tr("user.star_list.create_success", list.Name))
+	ctx.Redirect(starListsLink(ctx, list))
+}
+
+// EditStarListPost response for editing a star list
+func EditStarListPost(ctx *context.Context, form auth.StarListForm) {
+	list := getStarList(ctx)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(starListsLink(ctx, list))
+		return
+	}
+
+	list.Name = form.Name
+	list.Description = form.Description
+	list.IsPrivate = form.IsPrivate
+	if err := models.UpdateStarList(list); err != nil {
+		if models.IsErrStarListAlreadyExist(err) {
+			ctx.Flash.Error(ctx.Tr("user.star_list.already_exists", form.Name))
+			ctx.Redirect(starListsLink(ctx, list))
+		} else {
+			ctx.ServerError("UpdateStarList", err)
+		}
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("user.star_list.edit_success", list.Name))
+	ctx.Redirect(starListsLink(ctx, list))
+}
+
+// DeleteStarList response for deleting a star list
+func DeleteStarList(ctx *context.Context) {
+	list := getStarList(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.DeleteStarList(list); err != nil {
+		ctx.Flash.Error("DeleteStarList: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("user.star_list.deletion_success", list.Name))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": starListsLink(ctx, nil),
+	})
+}
+
+// StarListRepoPost response for adding a repository to a star list or removing it
+func StarListRepoPost(ctx *context.Context) {
+	list := getStarList(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	repo, err := models.GetRepositoryByID(ctx.QueryInt64("repo_id"))
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound("GetRepositoryByID", err)
+		} else {
+			ctx.ServerError("GetRepositoryByID", err)
+		}
+		return
+	}
+	perm, err := models.GetUserRepoPermission(repo, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetUserRepoPermission", err)
+		return
+	}
+	if !perm.HasAccess() {
+		ctx.NotFound("HasAccess", nil)
+		return
+	}
+
+	switch ctx.Query("action") {
+	case "add":
+		err = list.AddRepo(repo.ID)
+	case "remove":
+		err = list.RemoveRepo(repo.ID)
+	default:
+		ctx.NotFound("StarListRepoPost", nil)
+		return
+	}
+	if err != nil {
+		ctx.ServerError(fmt.Sprintf("StarListRepoPost (%s)", ctx.Query("action")), err)
+		return
+	}
+
+	ctx.JSON(200, map[string]interface{}{})
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package attachment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+// noticePeriod returns how long before their deletion the uploaders are notified
+func noticePeriod() time.Duration {
+	if setting.AttachmentRetentionNotice <= 0 {
+		return 0
+	}
+	return time.Duration(setting.AttachmentRetentionNotice) * 24 * time.Hour
+}
+
+// DeleteExpiredIssueAttachments deletes the attachments of the issues closed for longer than
+// the retention of the owner of their repository. The uploaders are notified before, an
+// attachment is only deleted once the notice period has passed since its notice.
+func DeleteExpiredIssueAttachments(ctx context.Context) error {
+	return deleteExpiredIssueAttachments(ctx, timeutil.TimeStampNow())
+}
+
+func deleteExpiredIssueAttachments(ctx context.Context, now timeutil.TimeStamp) error {
+	retention, err := models.GetMinAttachmentRetention()
+	if err != nil {
+		return fmt.Errorf("GetMinAttachmentRetention: %v", err)
+	} else if retention <= 0 {
+		return nil
+	}
+
+	notice := noticePeriod()
+	// the issues closed after this are not in their notice period with any retention
+	closedBefore := now.AddDuration(notice - time.Duration(retention)*24*time.Hour)
+	if closedBefore > now {
+		closedBefore = now
+	}
+	attachments, err := models.FindClosedIssueAttachments(closedBefore)
+	if err != nil {
+		return fmt.Errorf("FindClosedIssueAttachments: %v", err)
+	}
+
+	var (
+		issue    *models.Issue
+		policies = make(map[int64]*models.AttachmentPolicy)
+		expired  = make([]*models.Attachment, 0, len(attachments))
+		// the attachments to notify of by uploader, for the current issue
+		notices = make(map[int64][]*models.Attachment)
+	)
+	for _, a := range attachments {
+		select {
+		case <-ctx.Done():
+			return models.ErrCancelledf("before checking the retention of attachment %s", a.UUID)
+		default:
+		}
+
+		if issue == nil || issue.ID != a.IssueID {
+			if err := sendRetentionNotices(issue, policies, notices, now); err != nil {
+				return err
+			}
+			notices = make(map[int64][]*models.Attachment)
+			if issue, err = models.GetIssueByID(a.IssueID); err != nil {
+				return fmt.Errorf("GetIssueByID: %v", err)
+			}
+			if err := issue.LoadRepo(); err != nil {
+				return fmt.Errorf("LoadRepo: %v", err)
+			}
+			if _, ok := policies[issue.Repo.OwnerID]; !ok {
+				if policies[issue.Repo.OwnerID], err = models.GetEffectiveAttachmentPolicy(issue.Repo.OwnerID); err != nil {
+					return fmt.Errorf("GetEffectiveAttachmentPolicy: %v", err)
+				}
+			}
+		}
+
+		deadline := policies[issue.Repo.OwnerID].RetentionDeadline(issue.ClosedUnix)
+		if deadline == 0 || now < deadline.AddDuration(-notice) {
+			continue
+		}
+		// a notice sent before the issue was reopened does not count
+		if a.RetentionNoticeUnix < issue.ClosedUnix {
+			notices[a.UploaderID] = append(notices[a.UploaderID], a)
+		} else if now >= deadline && now >= a.RetentionNoticeUnix.AddDuration(notice) {
+			expired = append(expired, a)
+		}
+	}
+	if err := sendRetentionNotices(issue, policies, notices, now); err != nil {
+		return err
+	}
+
+	if len(expired) > 0 {
+		if _, err := models.DeleteAttachments(expired, true); err != nil {
+			return fmt.Errorf("DeleteAttachments: %v", err)
+		}
+		log.Info("Deleted %d expired attachments of closed issues", len(expired))
+	}
+	return nil
+}
+
+// sendRetentionNotices notifies the uploaders of the attachments of the issue of their deletion
+func sendRetentionNotices(issue *models.Issue, policies map[int64]*models.AttachmentPolicy, notices map[int64][]*models.Attachment, now timeutil.TimeStamp) error {
+	if issue == nil || len(notices) == 0 {
+		return nil
+	}
+	deadline := policies[issue.Repo.OwnerID].RetentionDeadline(issue.ClosedUnix)
+	if earliest := now.AddDuration(noticePeriod()); deadline < earliest {
+		// the deletion waits for the notice period when the notice comes late
+		deadline = earliest
+	}
+
+	for uploaderID, attachments := range notices {
+		if err := models.SetAttachmentsRetentionNotice(attachments, now); err != nil {
+			return fmt.Errorf("SetAttachmentsRetentionNotice: %v", err)
+		}
+		if uploaderID <= 0 {
+			continue
+		}
+		uploader, err := models.GetUserByID(uploaderID)
+		if models.IsErrUserNotExist(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("GetUserByID: %v", err)
+		}
+
+		if err := models.CreateOrUpdateIssueNotifications(issue.ID, 0, 0, uploader.ID, models.NotificationReasonAttachmentExpiry); err != nil {
+			return fmt.Errorf("CreateOrUpdateIssueNotifications: %v", err)
+		}
+		if setting.Service.EnableNotifyMail && uploader.IsActive && !uploader.ProhibitLogin {
+			mailer.SendAttachmentExpiryMail(uploader, issue, attachments, deadline)
+		}
+		log.Trace("Notified %s of the deletion of %d attachments of issue %d", uploader.Name, len(attachments), issue.ID)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteExpiredIssueAttachments(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer func(attachmentPath string, retention, notice int) {
+		setting.AttachmentPath = attachmentPath
+		setting.AttachmentClosedIssueRetention = retention
+		setting.AttachmentRetentionNotice = notice
+	}(setting.AttachmentPath, setting.AttachmentClosedIssueRetention, setting.AttachmentRetentionNotice)
+	tmpDir, err := ioutil.TempDir("", "attachments")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	setting.AttachmentPath = tmpDir
+	setting.AttachmentClosedIssueRetention = 30
+	setting.AttachmentRetentionNotice = 7
+
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	uploader := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	attach, err := models.NewAttachment(&models.Attachment{UploaderID: uploader.ID, Name: "log.txt"}, []byte("attachment"), bytes.NewReader(nil))
+	assert.NoError(t, err)
+	assert.NoError(t, issue.UpdateAttachments([]string{attach.UUID}))
+	attachments, err := models.GetAttachmentsByIssueID(issue.ID)
+	assert.NoError(t, err)
+	for _, a := range attachments {
+		assert.NoError(t, os.MkdirAll(path.Dir(a.LocalPath()), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(a.LocalPath(), []byte("attachment"), 0644))
+	}
+	_, err = issue.ChangeStatus(uploader, true)
+	assert.NoError(t, err)
+	closed := timeutil.TimeStampNow()
+
+	// nothing is done before the notice period
+	assert.NoError(t, deleteExpiredIssueAttachments(context.Background(), closed.AddDuration(22*24*time.Hour)))
+	assert.Zero(t, models.AssertExistsAndLoadBean(t, &models.Attachment{ID: attach.ID}).(*models.Attachment).RetentionNoticeUnix)
+
+	// the uploader is notified first
+	noticed := closed.AddDuration(40 * 24 * time.Hour)
+	assert.NoError(t, deleteExpiredIssueAttachments(context.Background(), noticed))
+	models.AssertExistsAndLoadBean(t, &models.Attachment{ID: attach.ID, RetentionNoticeUnix: noticed})
+	models.AssertExistsAndLoadBean(t, &models.Notification{UserID: uploader.ID, IssueID: issue.ID, Reason: models.NotificationReasonAttachmentExpiry})
+
+	// the attachments are kept during the notice period
+	assert.NoError(t, deleteExpiredIssueAttachments(context.Background(), noticed.AddDuration(6*24*time.Hour)))
+	models.AssertExistsAndLoadBean(t, &models.Attachment{ID: attach.ID})
+
+	assert.NoError(t, deleteExpiredIssueAttachments(context.Background(), noticed.AddDuration(7*24*time.Hour)))
+	for _, a := range attachments {
+		models.AssertNotExistsBean(t, &models.Attachment{ID: a.ID})
+		_, err = os.Stat(a.LocalPath())
+		assert.True(t, os.IsNotExist(err))
+	}
+
+	// the attachments of the other issues are kept
+	models.AssertExistsAndLoadBean(t, &models.Attachment{ID: 2})
+}
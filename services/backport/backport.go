@@ -0,0 +1,230 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package backport
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/repofiles"
+	comment_service "code.gitea.io/gitea/services/comments"
+	issue_service "code.gitea.io/gitea/services/issue"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+const (
+	// LabelPrefix is the prefix of the names of the labels requesting the backport of a merged
+	// pull request, the rest of the name is the target branch or its version
+	LabelPrefix = "backport/"
+	// ReleaseBranchPrefix is the prefix of the release branch of a version
+	ReleaseBranchPrefix = "release/"
+)
+
+// backportQueue is the queue of the backports to run
+var backportQueue queue.Queue
+
+type backportTask struct {
+	PullRequestID int64
+	LabelID       int64
+	DoerID        int64
+}
+
+// Init starts the service backporting merged pull requests labelled with a backport label
+func Init() error {
+	backportQueue = queue.CreateQueue("backport", handle, &backportTask{})
+
+	if backportQueue == nil {
+		return fmt.Errorf("Unable to create backport Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(backportQueue.Run)
+
+	notification.RegisterNotifier(NewNotifier())
+	return nil
+}
+
+func handle(data ...queue.Data) {
+	for _, datum := range data {
+		task := datum.(*backportTask)
+		pr, err := models.GetPullRequestByID(task.PullRequestID)
+		if err != nil {
+			log.Error("GetPullRequestByID[%d]: %v", task.PullRequestID, err)
+			continue
+		}
+		label, err := models.GetLabelByID(task.LabelID)
+		if err != nil {
+			log.Error("GetLabelByID[%d]: %v", task.LabelID, err)
+			continue
+		}
+		doer, err := models.GetUserByID(task.DoerID)
+		if err != nil {
+			log.Error("GetUserByID[%d]: %v", task.DoerID, err)
+			continue
+		}
+		if err := Backport(pr, label, doer); err != nil {
+			log.Error("Backport[%d] to %s: %v", pr.ID, label.Name, err)
+		}
+	}
+}
+
+// addToQueue adds the backport of a pull request requested by a label to the queue
+func addToQueue(pr *models.PullRequest, label *models.Label, doer *models.User) {
+	if err := backportQueue.Push(&backportTask{
+		PullRequestID: pr.ID,
+		LabelID:       label.ID,
+		DoerID:        doer.ID,
+	}); err != nil {
+		log.Error("Unable to add the backport of pull request %d to %s to the queue: %v", pr.ID, label.Name, err)
+	}
+}
+
+// LabelVersion returns the target branch or version of a backport label, or an empty string if
+// the label is not a backport label.
+func LabelVersion(label *models.Label) string {
+	if len(label.Name) <= len(LabelPrefix) || !strings.EqualFold(label.Name[:len(LabelPrefix)], LabelPrefix) {
+		return ""
+	}
+	return strings.TrimSpace(label.Name[len(LabelPrefix):])
+}
+
+// TargetBranch returns the branch the backports to a version go to: the branch named after the
+// version or else its release branch, or an empty string if there is none.
+func TargetBranch(gitRepo *git.Repository, version string) string {
+	for _, branch := range []string{version, ReleaseBranchPrefix + version} {
+		if gitRepo.IsBranchExist(branch) {
+			return branch
+		}
+	}
+	return ""
+}
+
+// BranchName returns the name of the branch of the backport of a pull request to a target branch
+func BranchName(index int64, target string) string {
+	return fmt.Sprintf("backport-%d-%s", index, strings.ReplaceAll(target, "/", "-"))
+}
+
+func reportFailure(doer *models.User, pr *models.PullRequest, format string, args ...interface{}) error {
+	_, err := comment_service.CreateIssueComment(doer, pr.BaseRepo, pr.Issue, fmt.Sprintf(format, args...), nil)
+	return err
+}
+
+// Backport applies the changes of a merged pull request to the target branch of a backport
+// label in a new branch and opens a pull request of it, assigned to the author of the original
+// pull request. Failures, like conflicts, are reported in a comment of the original pull request.
+func Backport(pr *models.PullRequest, label *models.Label, doer *models.User) error {
+	version := LabelVersion(label)
+	if version == "" || !pr.HasMerged {
+		return nil
+	}
+	if err := pr.LoadIssue(); err != nil {
+		return err
+	}
+	if err := pr.LoadBaseRepo(); err != nil {
+		return err
+	}
+	repo := pr.BaseRepo
+	pr.Issue.Repo = repo
+
+	perm, err := models.GetUserRepoPermission(repo, doer)
+	if err != nil {
+		return err
+	}
+	if !perm.CanWrite(models.UnitTypeCode) {
+		return reportFailure(doer, pr, "Backport to `%s` skipped: @%s cannot push to this repository.", version, doer.Name)
+	}
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	target := TargetBranch(gitRepo, version)
+	if target == "" {
+		return reportFailure(doer, pr, "Backport to `%s` skipped: there is no branch `%s` or `%s`.", version, version, ReleaseBranchPrefix+version)
+	}
+	if target == pr.BaseBranch {
+		return nil
+	}
+	newBranch := BranchName(pr.Index, target)
+	if gitRepo.IsBranchExist(newBranch) {
+		log.Trace("Backport of pull request %d to %s skipped: branch %s already exists", pr.ID, target, newBranch)
+		return nil
+	}
+
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return err
+	}
+	title := fmt.Sprintf("%s (#%d)", pr.Issue.Title, pr.Index)
+	content := fmt.Sprintf("Backport #%d", pr.Index)
+	if _, err := repofiles.CherryPick(repo, doer, &repofiles.CherryPickOptions{
+		CommitID:     headCommitID,
+		BaseCommitID: pr.MergeBase,
+		OldBranch:    target,
+		NewBranch:    newBranch,
+		Message:      title + "\n\n" + content,
+	}); err != nil {
+		if models.IsErrCherryPickConflict(err) {
+			files := err.(models.ErrCherryPickConflict).Files
+			return reportFailure(doer, pr, "Backport to `%s` failed: the changes do not apply cleanly. Conflicting files:\n\n- `%s`\n\nPlease backport this pull request manually.", target, strings.Join(files, "`\n- `"))
+		} else if models.IsErrCherryPickEmpty(err) {
+			return reportFailure(doer, pr, "Backport to `%s` skipped: the branch already contains the changes.", target)
+		} else if models.IsErrCommitMessageRejected(err) {
+			return reportFailure(doer, pr, "Backport to `%s` failed: %s.", target, err.(models.ErrCommitMessageRejected).Reason)
+		} else if git.IsErrPushRejected(err) {
+			return reportFailure(doer, pr, "Backport to `%s` failed: the push was rejected.\n\n```\n%s\n```", target, strings.TrimSpace(err.(*git.ErrPushRejected).Message))
+		}
+		return err
+	}
+
+	compareInfo, err := gitRepo.GetCompareInfo(repo.RepoPath(), target, newBranch)
+	if err != nil {
+		return err
+	}
+	backportIssue := &models.Issue{
+		RepoID:   repo.ID,
+		Title:    title,
+		PosterID: doer.ID,
+		Poster:   doer,
+		IsPull:   true,
+		Content:  content,
+	}
+	backportPR := &models.PullRequest{
+		HeadRepoID: repo.ID,
+		BaseRepoID: repo.ID,
+		HeadBranch: newBranch,
+		BaseBranch: target,
+		HeadRepo:   repo,
+		BaseRepo:   repo,
+		MergeBase:  compareInfo.MergeBase,
+		Type:       models.PullRequestGitea,
+	}
+	if err := pull_service.NewPullRequest(repo, backportIssue, nil, nil, backportPR, nil); err != nil {
+		return err
+	}
+
+	if err := pr.Issue.LoadPoster(); err != nil {
+		return err
+	}
+	canBeAssigned, err := models.CanBeAssigned(pr.Issue.Poster, repo, true)
+	if err != nil {
+		return err
+	}
+	if canBeAssigned {
+		if err := issue_service.AddAssigneeIfNotAssigned(backportIssue, doer, pr.Issue.PosterID); err != nil {
+			return err
+		}
+	}
+
+	log.Trace("Backport of pull request %d to %s opened: %d", pr.ID, target, backportPR.ID)
+	return nil
+}
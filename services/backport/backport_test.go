@@ -0,0 +1,61 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package backport
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelVersion(t *testing.T) {
+	assert.EqualValues(t, "v1.18", LabelVersion(&models.Label{Name: "backport/v1.18"}))
+	assert.EqualValues(t, "release/v1.18", LabelVersion(&models.Label{Name: "Backport/release/v1.18"}))
+	assert.EqualValues(t, "", LabelVersion(&models.Label{Name: "backport/"}))
+	assert.EqualValues(t, "", LabelVersion(&models.Label{Name: "bug"}))
+}
+
+func TestTargetBranch(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	assert.EqualValues(t, "develop", TargetBranch(gitRepo, "develop"))
+	assert.EqualValues(t, "feature/1", TargetBranch(gitRepo, "feature/1"))
+	assert.EqualValues(t, "", TargetBranch(gitRepo, "v1.18"))
+
+	assert.EqualValues(t, "backport-2-release-v1.18", BranchName(2, "release/v1.18"))
+}
+
+func TestBackport(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	// pull requests which are not merged are not backported
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 2}).(*models.PullRequest)
+	assert.NoError(t, Backport(pr, &models.Label{Name: "backport/develop"}, doer))
+	models.AssertNotExistsBean(t, &models.Comment{IssueID: pr.IssueID, Type: models.CommentTypeComment, PosterID: doer.ID})
+
+	pr = models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 1}).(*models.PullRequest)
+	assert.NoError(t, Backport(pr, &models.Label{Name: "backport/v1.18"}, doer))
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: pr.IssueID, Type: models.CommentTypeComment,
+		Content: "Backport to `v1.18` skipped: there is no branch `v1.18` or `release/v1.18`."})
+
+	// the changes of the fixture pull request are empty
+	assert.NoError(t, Backport(pr, &models.Label{Name: "backport/develop"}, doer))
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: pr.IssueID, Type: models.CommentTypeComment,
+		Content: "Backport to `develop` skipped: the branch already contains the changes."})
+
+	// users who cannot push are not allowed to backport
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 5}).(*models.User)
+	assert.NoError(t, Backport(pr, &models.Label{Name: "backport/develop"}, user))
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: pr.IssueID, Type: models.CommentTypeComment,
+		Content: "Backport to `develop` skipped: @user5 cannot push to this repository."})
+}
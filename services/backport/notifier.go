@@ -0,0 +1,68 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package backport
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+)
+
+type backportNotifier struct {
+	base.NullNotifier
+}
+
+var (
+	_ base.Notifier = &backportNotifier{}
+)
+
+// NewNotifier create a new backportNotifier notifier
+func NewNotifier() base.Notifier {
+	return &backportNotifier{}
+}
+
+// NotifyMergePullRequest backports the merged pull request to the targets of its backport labels
+func (*backportNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User) {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+	if err := pr.Issue.LoadLabels(); err != nil {
+		log.Error("LoadLabels: %v", err)
+		return
+	}
+	for _, label := range pr.Issue.Labels {
+		if LabelVersion(label) != "" {
+			addToQueue(pr, label, doer)
+		}
+	}
+}
+
+// NotifyIssueChangeLabels backports a merged pull request to the targets of the backport labels
+// added to it
+func (*backportNotifier) NotifyIssueChangeLabels(doer *models.User, issue *models.Issue,
+	addedLabels []*models.Label, removedLabels []*models.Label) {
+	if !issue.IsPull || len(addedLabels) == 0 {
+		return
+	}
+	if err := issue.LoadPullRequest(); err != nil {
+		log.Error("LoadPullRequest: %v", err)
+		return
+	}
+	if !issue.PullRequest.HasMerged {
+		return
+	}
+	for _, label := range addedLabels {
+		// the added labels may only have their ID loaded
+		label, err := models.GetLabelByID(label.ID)
+		if err != nil {
+			log.Error("GetLabelByID: %v", err)
+			continue
+		}
+		if LabelVersion(label) != "" {
+			addToQueue(issue.PullRequest, label, doer)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dashboard
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/unknwon/i18n"
+)
+
+// DefaultLimit is the number of entries of a widget when no limit is given
+const DefaultLimit = 10
+
+// ciFailuresPeriod is how long a failed commit status is shown
+const ciFailuresPeriod = 7 * 24 * time.Hour
+
+// Entry is an entry of a widget of the dashboard
+type Entry struct {
+	Title       string
+	Description string
+	Link        string
+	Icon        string
+	// State is the state of the entry if it has one, like "failure" for a commit status
+	State string
+	Time  timeutil.TimeStamp
+}
+
+// activityKeys are the locale keys of the actions shown by the organization activity widget
+var activityKeys = map[models.ActionType]string{
+	models.ActionCreateRepo:         "create_repo",
+	models.ActionRenameRepo:         "rename_repo",
+	models.ActionStarRepo:           "star_repo",
+	models.ActionWatchRepo:          "watch_repo",
+	models.ActionCommitRepo:         "commit_repo",
+	models.ActionCreateIssue:        "create_issue",
+	models.ActionCreatePullRequest:  "create_pull_request",
+	models.ActionTransferRepo:       "transfer_repo",
+	models.ActionPushTag:            "push_tag",
+	models.ActionCommentIssue:       "comment_issue",
+	models.ActionMergePullRequest:   "merge_pull_request",
+	models.ActionCloseIssue:         "close_issue",
+	models.ActionReopenIssue:        "reopen_issue",
+	models.ActionClosePullRequest:   "close_pull_request",
+	models.ActionReopenPullRequest:  "reopen_pull_request",
+	models.ActionDeleteTag:          "delete_tag",
+	models.ActionDeleteBranch:       "delete_branch",
+	models.ActionMirrorSyncPush:     "mirror_sync_push",
+	models.ActionMirrorSyncCreate:   "mirror_sync_create",
+	models.ActionMirrorSyncDelete:   "mirror_sync_delete",
+	models.ActionApprovePullRequest: "approve_pull_request",
+	models.ActionRejectPullRequest:  "reject_pull_request",
+	models.ActionCommentPull:        "comment_pull",
+}
+
+// issueActions are the actions whose content starts with the index of an issue
+var issueActions = map[models.ActionType]bool{
+	models.ActionCreateIssue:        true,
+	models.ActionCreatePullRequest:  true,
+	models.ActionCommentIssue:       true,
+	models.ActionMergePullRequest:   true,
+	models.ActionCloseIssue:         true,
+	models.ActionReopenIssue:        true,
+	models.ActionClosePullRequest:   true,
+	models.ActionReopenPullRequest:  true,
+	models.ActionApprovePullRequest: true,
+	models.ActionRejectPullRequest:  true,
+	models.ActionCommentPull:        true,
+}
+
+// LoadWidget returns the entries of a widget of the dashboard of the doer, the texts
+// are translated to the language.
+func LoadWidget(doer *models.User, widget, lang string, limit int) ([]*Entry, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	switch widget {
+	case models.DashboardWidgetAssignedIssues:
+		return loadIssues(doer, &models.IssuesOptions{
+			AssigneeID: doer.ID,
+			IsPull:     util.OptionalBoolFalse,
+		}, limit)
+	case models.DashboardWidgetReviewRequests:
+		return loadIssues(doer, &models.IssuesOptions{
+			ReviewRequestedID: doer.ID,
+			IsPull:            util.OptionalBoolTrue,
+		}, limit)
+	case models.DashboardWidgetCIFailures:
+		return loadCIFailures(doer, limit)
+	case models.DashboardWidgetWatchedReleases:
+		return loadWatchedReleases(doer, limit)
+	case models.DashboardWidgetOrgActivity:
+		return loadOrgActivity(doer, lang, limit)
+	}
+	return nil, models.ErrInvalidDashboardWidget{Widget: widget}
+}
+
+func loadIssues(doer *models.User, opts *models.IssuesOptions, limit int) ([]*Entry, error) {
+	repoIDs, err := models.FindUserAccessibleRepoIDs(doer)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*Entry, 0, limit)
+	if len(repoIDs) == 0 {
+		return entries, nil
+	}
+
+	opts.RepoIDs = repoIDs
+	opts.IsClosed = util.OptionalBoolFalse
+	opts.SortType = "recentupdate"
+	opts.ListOptions = models.ListOptions{Page: 1, PageSize: limit}
+	issues, err := models.Issues(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, issue := range issues {
+		if err := issue.LoadRepo(); err != nil {
+			return nil, err
+		}
+		perm, err := models.GetUserRepoPermission(issue.Repo, doer)
+		if err != nil {
+			return nil, err
+		}
+		if !perm.CanReadIssuesOrPulls(issue.IsPull) {
+			continue
+		}
+
+		entry := &Entry{
+			Title:       fmt.Sprintf("#%d %s", issue.Index, issue.Title),
+			Description: issue.Repo.FullName(),
+			Link:        fmt.Sprintf("%s/issues/%d", issue.Repo.Link(), issue.Index),
+			Icon:        "octicon-issue-opened",
+			State:       "open",
+			Time:        issue.UpdatedUnix,
+		}
+		if issue.IsPull {
+			entry.Link = fmt.Sprintf("%s/pulls/%d", issue.Repo.Link(), issue.Index)
+			entry.Icon = "octicon-git-pull-request"
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// watchedRepoIDs returns the repositories watched by the doer whose unit the doer can read
+func watchedRepoIDs(doer *models.User, unitType models.UnitType) ([]int64, error) {
+	repos, err := models.GetWatchedRepos(doer.ID, true, models.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	repoIDs := make([]int64, 0, len(repos))
+	for _, repo := range repos {
+		perm, err := models.GetUserRepoPermission(repo, doer)
+		if err != nil {
+			return nil, err
+		}
+		if perm.CanRead(unitType) {
+			repoIDs = append(repoIDs, repo.ID)
+		}
+	}
+	return repoIDs, nil
+}
+
+func loadCIFailures(doer *models.User, limit int) ([]*Entry, error) {
+	repoIDs, err := watchedRepoIDs(doer, models.UnitTypeCode)
+	if err != nil {
+		return nil, err
+	}
+	since := timeutil.TimeStampNow().AddDuration(-ciFailuresPeriod)
+	statuses, err := models.FindFailedCommitStatuses(repoIDs, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(statuses))
+	for _, status := range statuses {
+		description := status.Repo.FullName() + "@" + shortSHA(status.SHA)
+		if status.Description != "" {
+			description += ": " + status.Description
+		}
+		link := status.TargetURL
+		if link == "" || !isSafeURL(link) {
+			link = status.Repo.Link() + "/commit/" + status.SHA
+		}
+		entries = append(entries, &Entry{
+			Title:       status.Context,
+			Description: description,
+			Link:        link,
+			Icon:        "octicon-x",
+			State:       string(status.State),
+			Time:        status.UpdatedUnix,
+		})
+	}
+	return entries, nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 10 {
+		return sha[:10]
+	}
+	return sha
+}
+
+// isSafeURL checks that the target url of a commit status can be used as a link
+func isSafeURL(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+func loadWatchedReleases(doer *models.User, limit int) ([]*Entry, error) {
+	repoIDs, err := watchedRepoIDs(doer, models.UnitTypeReleases)
+	if err != nil {
+		return nil, err
+	}
+	releases, err := models.GetLatestReleasesByRepoIDs(repoIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(releases))
+	for _, rel := range releases {
+		title := rel.Title
+		if title == "" {
+			title = rel.TagName
+		}
+		entries = append(entries, &Entry{
+			Title:       title,
+			Description: rel.Repo.FullName() + " " + rel.TagName,
+			Link:        rel.Repo.Link() + "/releases/tag/" + util.PathEscapeSegments(rel.TagName),
+			Icon:        "octicon-tag",
+			Time:        rel.CreatedUnix,
+		})
+	}
+	return entries, nil
+}
+
+func loadOrgActivity(doer *models.User, lang string, limit int) ([]*Entry, error) {
+	orgs, err := models.GetOrgsByUserID(doer.ID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []*models.Action
+	for _, org := range orgs {
+		feeds, err := models.GetFeeds(models.GetFeedsOptions{
+			RequestedUser:  org,
+			Actor:          doer,
+			IncludePrivate: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, feeds...)
+	}
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].CreatedUnix > actions[j].CreatedUnix
+	})
+
+	entries := make([]*Entry, 0, limit)
+	for _, act := range actions {
+		if len(entries) == limit {
+			break
+		}
+		key, ok := activityKeys[act.OpType]
+		if !ok {
+			continue
+		}
+		link := act.GetRepoLink()
+		if issueActions[act.OpType] {
+			link += "/issues/" + act.GetIssueInfos()[0]
+		}
+		entries = append(entries, &Entry{
+			Title:       act.GetActUserName() + " " + i18n.Tr(lang, "dashboard.activity."+key),
+			Description: strings.TrimSpace(act.GetRepoPath() + " " + act.GetBranch()),
+			Link:        link,
+			Icon:        "octicon-pulse",
+			Time:        act.CreatedUnix,
+		})
+	}
+	return entries, nil
+}
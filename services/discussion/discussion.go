@@ -0,0 +1,33 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package discussion
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// NewDiscussion creates a new discussion and notifies the watchers of its repository.
+func NewDiscussion(d *models.Discussion) error {
+	if err := models.NewDiscussion(d); err != nil {
+		return err
+	}
+
+	notification.NotifyNewDiscussion(d)
+
+	return nil
+}
+
+// NewReply posts a reply to a discussion and notifies its participants.
+func NewReply(doer *models.User, d *models.Discussion, parentID int64, content string) (*models.DiscussionReply, error) {
+	reply, err := models.NewDiscussionReply(doer, d, parentID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	notification.NotifyDiscussionReply(doer, d, reply)
+
+	return reply, nil
+}
@@ -16,6 +16,8 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -91,6 +93,9 @@ type DiffLineSectionInfo struct {
 // BlobExceprtChunkSize represent max lines of excerpt
 const BlobExceprtChunkSize = 20
 
+// MaxBlobExcerptChunkSize is the max lines of excerpt a user can choose to expand at once
+const MaxBlobExcerptChunkSize = 500
+
 // GetType returns the type of a DiffLine.
 func (d *DiffLine) GetType() int {
 	return int(d.Type)
@@ -287,6 +292,49 @@ func init() {
 	diffMatchPatch.DiffEditCost = 100
 }
 
+// wordDiffTokenPattern splits highlighted code into tags, entities, words,
+// whitespaces and single other characters
+var wordDiffTokenPattern = regexp.MustCompile(`<[^>]*>|&#?\w+;|\w+|\s+|.`)
+
+// diffWords computes the difference of two highlighted lines word by word, tags
+// and entities are compared as a whole so that a change never splits them.
+func diffWords(text1, text2 string) []diffmatchpatch.Diff {
+	tokenIndex := make(map[string]rune)
+	tokens := []string{""}
+	toRunes := func(text string) []rune {
+		matches := wordDiffTokenPattern.FindAllString(text, -1)
+		runes := make([]rune, 0, len(matches))
+		for _, token := range matches {
+			r, ok := tokenIndex[token]
+			if !ok {
+				r = rune(len(tokens))
+				tokenIndex[token] = r
+				tokens = append(tokens, token)
+			}
+			runes = append(runes, r)
+		}
+		return runes
+	}
+	runes1, runes2 := toRunes(text1), toRunes(text2)
+
+	// the runes must stay valid code points, fall back to characters for very long lines
+	if len(tokens) >= 0xD800 {
+		diffs := diffMatchPatch.DiffMain(text1, text2, true)
+		return diffMatchPatch.DiffCleanupEfficiency(diffs)
+	}
+
+	diffs := diffMatchPatch.DiffMainRunes(runes1, runes2, false)
+	var buf strings.Builder
+	for i := range diffs {
+		buf.Reset()
+		for _, r := range diffs[i].Text {
+			buf.WriteString(tokens[r])
+		}
+		diffs[i].Text = buf.String()
+	}
+	return diffs
+}
+
 // GetComputedInlineDiffFor computes inline diff for the given line.
 func (diffSection *DiffSection) GetComputedInlineDiffFor(diffLine *DiffLine) template.HTML {
 	if setting.Git.DisableDiffHighlight {
@@ -324,8 +372,7 @@ func (diffSection *DiffSection) GetComputedInlineDiffFor(diffLine *DiffLine) tem
 		return template.HTML(highlight.Code(diffSection.FileName, diffLine.Content))
 	}
 
-	diffRecord := diffMatchPatch.DiffMain(highlight.Code(diffSection.FileName, diff1[1:]), highlight.Code(diffSection.FileName, diff2[1:]), true)
-	diffRecord = diffMatchPatch.DiffCleanupEfficiency(diffRecord)
+	diffRecord := diffWords(highlight.Code(diffSection.FileName, diff1[1:]), highlight.Code(diffSection.FileName, diff2[1:]))
 	return diffToHTML(diffSection.FileName, diffRecord, diffLine.Type)
 }
 
@@ -344,6 +391,10 @@ type DiffFile struct {
 	IsSubmodule        bool
 	Sections           []*DiffSection
 	IsIncomplete       bool
+	// IsLazy is set for large files whose sections are loaded on demand
+	IsLazy bool
+	// Submodule is set by Diff.LoadSubmodules for changed submodules
+	Submodule *SubmoduleDiffInfo
 }
 
 // GetType returns type of diff file.
@@ -398,6 +449,31 @@ func getCommitFileLineCount(commit *git.Commit, filePath string) int {
 	return lineCount
 }
 
+// GetNumLines returns the number of lines of all sections of the file.
+func (diffFile *DiffFile) GetNumLines() int {
+	numLines := 0
+	for _, section := range diffFile.Sections {
+		numLines += len(section.Lines)
+	}
+	return numLines
+}
+
+// GetLazyQuery builds query string to load the sections or the rich diff of the file
+func (diffFile *DiffFile) GetLazyQuery() string {
+	return fmt.Sprintf("path=%s&old_path=%s", url.QueryEscape(diffFile.Name), url.QueryEscape(diffFile.OldName))
+}
+
+func (diffFile *DiffFile) hasComments() bool {
+	for _, section := range diffFile.Sections {
+		for _, line := range section.Lines {
+			if len(line.Comments) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Diff represents a difference between two git trees.
 type Diff struct {
 	NumFiles, TotalAddition, TotalDeletion int
@@ -405,6 +481,57 @@ type Diff struct {
 	IsIncomplete                           bool
 }
 
+// DeferLargeFiles drops the sections of the files with more than maxLines lines
+// so they are loaded on demand instead of being rendered with the whole diff.
+// Files with comments are always kept.
+func (diff *Diff) DeferLargeFiles(maxLines int) {
+	if maxLines <= 0 {
+		return
+	}
+	for _, file := range diff.Files {
+		if file.IsIncomplete || file.IsBin || file.GetNumLines() <= maxLines || file.hasComments() {
+			continue
+		}
+		file.IsLazy = true
+		file.Sections = nil
+	}
+}
+
+// FilterFiles keeps only the files whose current or old name contains the filter,
+// or matches it when the filter is a glob pattern, and recomputes the totals of the diff.
+func (diff *Diff) FilterFiles(filter string) {
+	filter = strings.TrimSpace(filter)
+	if len(filter) == 0 {
+		return
+	}
+	matches := func(name string) bool {
+		if len(name) == 0 {
+			return false
+		}
+		if strings.Contains(name, filter) {
+			return true
+		}
+		if matched, _ := path.Match(filter, name); matched {
+			return true
+		}
+		matched, _ := path.Match(filter, path.Base(name))
+		return matched
+	}
+
+	files := make([]*DiffFile, 0, len(diff.Files))
+	diff.TotalAddition, diff.TotalDeletion = 0, 0
+	for _, file := range diff.Files {
+		if !matches(file.Name) && !matches(file.OldName) {
+			continue
+		}
+		files = append(files, file)
+		diff.TotalAddition += file.Addition
+		diff.TotalDeletion += file.Deletion
+	}
+	diff.Files = files
+	diff.NumFiles = len(files)
+}
+
 // LoadComments loads comments into each line
 func (diff *Diff) LoadComments(issue *models.Issue, currentUser *models.User) error {
 	allComments, err := models.FetchCodeComments(issue, currentUser)
@@ -685,6 +812,32 @@ func GetDiffRange(repoPath, beforeCommitID, afterCommitID string, maxLines, maxL
 // Passing the empty string as beforeCommitID returns a diff from the parent commit.
 // The whitespaceBehavior is either an empty string or a git flag
 func GetDiffRangeWithWhitespaceBehavior(repoPath, beforeCommitID, afterCommitID string, maxLines, maxLineCharacters, maxFiles int, whitespaceBehavior string) (*Diff, error) {
+	return getDiffRange(repoPath, beforeCommitID, afterCommitID, maxLines, maxLineCharacters, maxFiles, whitespaceBehavior)
+}
+
+// GetDiffFile builds the diff of a single file between two commits of a repository,
+// the old path of a renamed file is needed to detect the rename.
+// Passing the empty string as beforeCommitID returns a diff from the parent commit.
+func GetDiffFile(repoPath, beforeCommitID, afterCommitID, filePath, oldPath string, maxLines, maxLineCharacters int, whitespaceBehavior string) (*DiffFile, error) {
+	paths := []string{filePath}
+	if len(oldPath) != 0 && oldPath != filePath {
+		paths = append(paths, oldPath)
+	}
+	diff, err := getDiffRange(repoPath, beforeCommitID, afterCommitID, maxLines, maxLineCharacters, 1, whitespaceBehavior, paths...)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range diff.Files {
+		if file.Name == filePath {
+			return file, nil
+		}
+	}
+	return nil, git.ErrNotExist{ID: afterCommitID, RelPath: filePath}
+}
+
+// getDiffRange builds a Diff between two commits of a repository, limited to
+// the given paths if there are any.
+func getDiffRange(repoPath, beforeCommitID, afterCommitID string, maxLines, maxLineCharacters, maxFiles int, whitespaceBehavior string, paths ...string) (*Diff, error) {
 	gitRepo, err := git.OpenRepository(repoPath)
 	if err != nil {
 		return nil, err
@@ -701,7 +854,11 @@ func GetDiffRangeWithWhitespaceBehavior(repoPath, beforeCommitID, afterCommitID
 	defer cancel()
 	var cmd *exec.Cmd
 	if (len(beforeCommitID) == 0 || beforeCommitID == git.EmptySHA) && commit.ParentCount() == 0 {
-		cmd = exec.CommandContext(ctx, git.GitExecutable, "show", afterCommitID)
+		showArgs := []string{"show", afterCommitID}
+		if len(paths) > 0 {
+			showArgs = append(append(showArgs, "--"), paths...)
+		}
+		cmd = exec.CommandContext(ctx, git.GitExecutable, showArgs...)
 	} else {
 		actualBeforeCommitID := beforeCommitID
 		if len(actualBeforeCommitID) == 0 {
@@ -714,6 +871,9 @@ func GetDiffRangeWithWhitespaceBehavior(repoPath, beforeCommitID, afterCommitID
 		}
 		diffArgs = append(diffArgs, actualBeforeCommitID)
 		diffArgs = append(diffArgs, afterCommitID)
+		if len(paths) > 0 {
+			diffArgs = append(append(diffArgs, "--"), paths...)
+		}
 		cmd = exec.CommandContext(ctx, git.GitExecutable, diffArgs...)
 		beforeCommitID = actualBeforeCommitID
 	}
@@ -747,6 +907,11 @@ func GetDiffRangeWithWhitespaceBehavior(repoPath, beforeCommitID, afterCommitID
 		return nil, fmt.Errorf("Wait: %v", err)
 	}
 
+	// the stats of a single file are the ones of the parsed patch
+	if len(paths) > 0 {
+		return diff, nil
+	}
+
 	shortstatArgs := []string{beforeCommitID + "..." + afterCommitID}
 	if len(beforeCommitID) == 0 || beforeCommitID == git.EmptySHA {
 		shortstatArgs = []string{git.EmptyTreeSHA, afterCommitID}
@@ -158,3 +158,126 @@ func TestGetDiffRangeWithWhitespaceBehavior(t *testing.T) {
 		}
 	}
 }
+
+func TestGetDiffFile(t *testing.T) {
+	file, err := GetDiffFile("./testdata/academic-module", "559c156f8e0178b71cb44355428f24001b08fc68", "bd7063cc7c04689c4d082183d32a604ed27a24f9",
+		"Resources/views/test/index.blade.php", "Resources/views/test/index.blade.php",
+		setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Resources/views/test/index.blade.php", file.Name)
+	assert.Equal(t, 2, file.Addition)
+	assert.True(t, len(file.Sections) > 0)
+
+	_, err = GetDiffFile("./testdata/academic-module", "559c156f8e0178b71cb44355428f24001b08fc68", "bd7063cc7c04689c4d082183d32a604ed27a24f9",
+		"does-not-exist", "", setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters, "")
+	assert.True(t, git.IsErrNotExist(err))
+}
+
+func TestDiff_DeferLargeFiles(t *testing.T) {
+	diff := setupDefaultDiff()
+	diff.Files = append(diff.Files, &DiffFile{
+		Name:     "small.md",
+		Sections: []*DiffSection{{Lines: []*DiffLine{{LeftIdx: 1, RightIdx: 1}}}},
+	})
+	diff.Files[0].Sections[0].Lines = append(diff.Files[0].Sections[0].Lines, &DiffLine{LeftIdx: 5, RightIdx: 5})
+
+	diff.DeferLargeFiles(0)
+	assert.False(t, diff.Files[0].IsLazy)
+
+	diff.DeferLargeFiles(1)
+	assert.True(t, diff.Files[0].IsLazy)
+	assert.Empty(t, diff.Files[0].Sections)
+	assert.False(t, diff.Files[1].IsLazy)
+
+	diff = setupDefaultDiff()
+	diff.Files[0].Sections[0].Lines[0].Comments = []*models.Comment{{Content: "bla"}}
+	diff.Files[0].Sections[0].Lines = append(diff.Files[0].Sections[0].Lines, &DiffLine{LeftIdx: 5, RightIdx: 5})
+	diff.DeferLargeFiles(1)
+	assert.False(t, diff.Files[0].IsLazy)
+}
+
+func TestDiff_FilterFiles(t *testing.T) {
+	newDiff := func() *Diff {
+		return &Diff{
+			NumFiles:      3,
+			TotalAddition: 6,
+			TotalDeletion: 3,
+			Files: []*DiffFile{
+				{Name: "README.md", Addition: 1, Deletion: 1},
+				{Name: "models/repo.go", Addition: 2, Deletion: 1},
+				{Name: "routers/repo/compare.go", OldName: "routers/repo/diff.go", Addition: 3, Deletion: 1},
+			},
+		}
+	}
+
+	diff := newDiff()
+	diff.FilterFiles("")
+	assert.Len(t, diff.Files, 3)
+
+	diff = newDiff()
+	diff.FilterFiles("repo")
+	assert.Len(t, diff.Files, 2)
+	assert.EqualValues(t, 2, diff.NumFiles)
+	assert.EqualValues(t, 5, diff.TotalAddition)
+	assert.EqualValues(t, 2, diff.TotalDeletion)
+
+	diff = newDiff()
+	diff.FilterFiles("*.go")
+	assert.Len(t, diff.Files, 2)
+
+	diff = newDiff()
+	diff.FilterFiles("routers/repo/diff.go")
+	if assert.Len(t, diff.Files, 1) {
+		assert.EqualValues(t, "routers/repo/compare.go", diff.Files[0].Name)
+	}
+
+	diff = newDiff()
+	diff.FilterFiles("*.js")
+	assert.Empty(t, diff.Files)
+	assert.EqualValues(t, 0, diff.NumFiles)
+}
+
+func TestDiffWords(t *testing.T) {
+	diffs := diffWords(`<span class="nx">foo</span> <span class="o">=</span> <span class="nx">bar</span>`,
+		`<span class="nx">foo</span> <span class="o">=</span> <span class="nx">baz</span>`)
+	assert.Equal(t, []dmp.Diff{
+		{Type: dmp.DiffEqual, Text: `<span class="nx">foo</span> <span class="o">=</span> <span class="nx">`},
+		{Type: dmp.DiffDelete, Text: "bar"},
+		{Type: dmp.DiffInsert, Text: "baz"},
+		{Type: dmp.DiffEqual, Text: "</span>"},
+	}, diffs)
+
+	assertEqual(t, `return <span class="added-code">newValue</span> &amp;&amp; ok`, diffToHTML("", diffWords(
+		"return value &amp;&amp; ok", "return newValue &amp;&amp; ok"), DiffLineAdd))
+}
+
+func TestDiffFile_parseSubmoduleCommits(t *testing.T) {
+	var diff = `diff --git a/vendor/lib b/vendor/lib
+index 3d3d2a1..e6f9d4a 160000
+--- a/vendor/lib
++++ b/vendor/lib
+@@ -1 +1 @@
+-Subproject commit 3d3d2a1b8ffbd5e1a6e7bd3a3fef20d4b3ea3d69
++Subproject commit e6f9d4a8f0f39f2b7f0d5e06d9e9cfc9b72cb1b0-dirty
+diff --git a/other b/other
+new file mode 160000
+index 0000000..65f1bf2
+--- /dev/null
++++ b/other
+@@ -0,0 +1 @@
++Subproject commit 65f1bf27bc3bf70f64657658635e66094edbcb4d
+`
+	result, err := ParsePatch(setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles, strings.NewReader(diff))
+	assert.NoError(t, err)
+	assert.Len(t, result.Files, 2)
+
+	assert.True(t, result.Files[0].IsSubmodule)
+	oldCommit, newCommit := result.Files[0].parseSubmoduleCommits()
+	assert.Equal(t, "3d3d2a1b8ffbd5e1a6e7bd3a3fef20d4b3ea3d69", oldCommit)
+	assert.Equal(t, "e6f9d4a8f0f39f2b7f0d5e06d9e9cfc9b72cb1b0", newCommit)
+
+	assert.True(t, result.Files[1].IsSubmodule)
+	oldCommit, newCommit = result.Files[1].parseSubmoduleCommits()
+	assert.Empty(t, oldCommit)
+	assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", newCommit)
+}
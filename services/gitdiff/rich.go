@@ -0,0 +1,101 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitdiff
+
+import (
+	"fmt"
+	"html/template"
+	"io/ioutil"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ErrRichDiffTooLarge represents a "RichDiffTooLarge" kind of error.
+type ErrRichDiffTooLarge struct {
+	Path string
+}
+
+// IsErrRichDiffTooLarge checks if an error is a ErrRichDiffTooLarge.
+func IsErrRichDiffTooLarge(err error) bool {
+	_, ok := err.(ErrRichDiffTooLarge)
+	return ok
+}
+
+func (err ErrRichDiffTooLarge) Error() string {
+	return fmt.Sprintf("file is too large to render its rich diff [path: %s]", err.Path)
+}
+
+// HasRichDiff returns whether the diff of the file can be rendered in a format aware way
+func (diffFile *DiffFile) HasRichDiff() bool {
+	return !diffFile.IsBin && !diffFile.IsSubmodule && markup.GetDiffRendererByFileName(diffFile.Name) != nil
+}
+
+// readCommitFile reads a file of the commit, a missing file is empty
+func readCommitFile(commit *git.Commit, filePath string) ([]byte, error) {
+	if commit == nil {
+		return nil, nil
+	}
+	blob, err := commit.GetBlobByPath(filePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if blob.Size() > setting.UI.MaxDisplayFileSize {
+		return nil, ErrRichDiffTooLarge{Path: filePath}
+	}
+	reader, err := blob.DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// RenderRichDiff renders the difference of a file between two commits with the
+// diff renderer of its format, the old path of a renamed file is read from
+// the before commit. Passing the empty string as beforeCommitID compares with
+// the parent commit.
+func RenderRichDiff(gitRepo *git.Repository, beforeCommitID, afterCommitID, filePath, oldPath string) (template.HTML, error) {
+	renderer := markup.GetDiffRendererByFileName(filePath)
+	if renderer == nil {
+		return "", git.ErrNotExist{ID: afterCommitID, RelPath: filePath}
+	}
+
+	afterCommit, err := gitRepo.GetCommit(afterCommitID)
+	if err != nil {
+		return "", err
+	}
+	var beforeCommit *git.Commit
+	if len(beforeCommitID) != 0 {
+		if beforeCommit, err = gitRepo.GetCommit(beforeCommitID); err != nil {
+			return "", err
+		}
+	} else if afterCommit.ParentCount() > 0 {
+		if beforeCommit, err = afterCommit.Parent(0); err != nil {
+			return "", err
+		}
+	}
+	if len(oldPath) == 0 {
+		oldPath = filePath
+	}
+
+	before, err := readCommitFile(beforeCommit, oldPath)
+	if err != nil {
+		return "", err
+	}
+	after, err := readCommitFile(afterCommit, filePath)
+	if err != nil {
+		return "", err
+	}
+	result, err := renderer.RenderDiff(before, after)
+	if err != nil {
+		return "", fmt.Errorf("RenderDiff: %v", err)
+	}
+	return template.HTML(result), nil
+}
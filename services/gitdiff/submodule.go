@@ -0,0 +1,172 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitdiff
+
+import (
+	"container/list"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// maxSubmoduleCommits is the maximum number of commits listed for a changed submodule
+const maxSubmoduleCommits = 10
+
+// SubmoduleDiffInfo describes the change of the commit a submodule refers to
+type SubmoduleDiffInfo struct {
+	// URL is the web URL of the submodule repository, empty if it is unknown
+	URL       string
+	OldCommit string
+	NewCommit string
+	// Repo is set if the submodule is a repository of this instance the user can read
+	Repo *models.Repository
+	// Commits are the newest commits from OldCommit to NewCommit if Repo is set
+	Commits        *list.List
+	HasMoreCommits bool
+}
+
+// OldCommitURL returns the web URL of the old commit of the submodule
+func (info *SubmoduleDiffInfo) OldCommitURL() string {
+	if info.URL == "" || info.OldCommit == "" {
+		return ""
+	}
+	return info.URL + "/commit/" + info.OldCommit
+}
+
+// NewCommitURL returns the web URL of the new commit of the submodule
+func (info *SubmoduleDiffInfo) NewCommitURL() string {
+	if info.URL == "" || info.NewCommit == "" {
+		return ""
+	}
+	return info.URL + "/commit/" + info.NewCommit
+}
+
+// CompareURL returns the web URL comparing the old and new commit of the submodule if it
+// is a repository of this instance
+func (info *SubmoduleDiffInfo) CompareURL() string {
+	if info.Repo == nil || info.OldCommit == "" || info.NewCommit == "" {
+		return ""
+	}
+	return info.Repo.HTMLURL() + "/compare/" + info.OldCommit + "..." + info.NewCommit
+}
+
+// parseSubmoduleCommits returns the commits before and after the change of a submodule from
+// its "Subproject commit" diff lines.
+func (diffFile *DiffFile) parseSubmoduleCommits() (oldCommit, newCommit string) {
+	for _, section := range diffFile.Sections {
+		for _, line := range section.Lines {
+			var commit string
+			switch line.Type {
+			case DiffLineDel, DiffLineAdd:
+				commit = strings.TrimPrefix(line.Content[1:], "Subproject commit ")
+				commit = strings.TrimSuffix(commit, "-dirty")
+			default:
+				continue
+			}
+			if line.Type == DiffLineDel {
+				oldCommit = commit
+			} else {
+				newCommit = commit
+			}
+		}
+	}
+	return oldCommit, newCommit
+}
+
+// LoadSubmodules loads the SubmoduleDiffInfo of the changed submodules of the diff. The URLs of
+// the submodules are read from .gitmodules of the after commit, or of the before commit for
+// removed submodules.
+func (diff *Diff) LoadSubmodules(repo *models.Repository, beforeCommit, afterCommit *git.Commit, doer *models.User) {
+	for _, file := range diff.Files {
+		if !file.IsSubmodule {
+			continue
+		}
+
+		info := &SubmoduleDiffInfo{}
+		info.OldCommit, info.NewCommit = file.parseSubmoduleCommits()
+		file.Submodule = info
+
+		commit := afterCommit
+		if file.IsDeleted && beforeCommit != nil {
+			commit = beforeCommit
+		}
+		submodule, err := commit.GetSubModule(file.Name)
+		if err != nil {
+			log.Error("GetSubModule[%s]: %v", file.Name, err)
+			continue
+		}
+		if submodule == nil {
+			continue
+		}
+		info.URL = git.SubModuleURL(submodule.URL, setting.AppURL, repo.FullName(), setting.SSH.Domain)
+
+		info.Repo = getSubmoduleRepository(info.URL, doer)
+		if info.Repo == nil || info.OldCommit == "" || info.NewCommit == "" {
+			continue
+		}
+		info.URL = info.Repo.HTMLURL()
+		if err := info.loadCommits(); err != nil {
+			log.Error("loadCommits[%s]: %v", file.Name, err)
+		}
+	}
+}
+
+func (info *SubmoduleDiffInfo) loadCommits() error {
+	gitRepo, err := git.OpenRepository(info.Repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	oldCommit, err := gitRepo.GetCommit(info.OldCommit)
+	if err != nil {
+		return err
+	}
+	newCommit, err := gitRepo.GetCommit(info.NewCommit)
+	if err != nil {
+		return err
+	}
+	commits, err := gitRepo.CommitsBetweenLimit(newCommit, oldCommit, maxSubmoduleCommits+1, 0)
+	if err != nil {
+		return err
+	}
+	if commits.Len() > maxSubmoduleCommits {
+		commits.Remove(commits.Back())
+		info.HasMoreCommits = true
+	}
+	info.Commits = models.ValidateCommitsWithEmails(commits)
+	return nil
+}
+
+// getSubmoduleRepository returns the repository of this instance the web URL of a submodule
+// refers to, if the user can read its code.
+func getSubmoduleRepository(link string, doer *models.User) *models.Repository {
+	if !strings.HasPrefix(link, setting.AppURL) {
+		return nil
+	}
+	parts := strings.Split(strings.TrimPrefix(link, setting.AppURL), "/")
+	if len(parts) != 2 {
+		return nil
+	}
+	repo, err := models.GetRepositoryByOwnerAndName(parts[0], strings.TrimSuffix(parts[1], ".git"))
+	if err != nil {
+		if !models.IsErrRepoNotExist(err) {
+			log.Error("GetRepositoryByOwnerAndName: %v", err)
+		}
+		return nil
+	}
+	perm, err := models.GetUserRepoPermission(repo, doer)
+	if err != nil {
+		log.Error("GetUserRepoPermission: %v", err)
+		return nil
+	}
+	if !perm.CanRead(models.UnitTypeCode) {
+		return nil
+	}
+	return repo
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// ApplyAssignRules assigns an issue which has no assignees yet according to
+// the first matching assignment rule of its repository.
+func ApplyAssignRules(issue *models.Issue, doer *models.User) error {
+	if err := issue.LoadAttributes(); err != nil {
+		return err
+	}
+	if len(issue.Assignees) > 0 {
+		return nil
+	}
+
+	rules, err := models.GetIssueAssignRulesByRepoID(issue.RepoID)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	// issue.Labels might not reflect labels added just now
+	labels, err := models.GetLabelsByIssueID(issue.ID)
+	if err != nil {
+		return err
+	}
+	labelIDs := make([]int64, len(labels))
+	for i, label := range labels {
+		labelIDs[i] = label.ID
+	}
+
+	var changedFiles []string
+	var changedFilesLoaded bool
+	for _, rule := range rules {
+		if issue.IsPull && rule.PathPatterns != "" && !changedFilesLoaded {
+			changedFilesLoaded = true
+			if changedFiles, err = getPullChangedFiles(issue.PullRequest); err != nil {
+				return err
+			}
+		}
+		if !rule.Match(labelIDs, issue.IsPull, changedFiles) {
+			continue
+		}
+
+		members, err := models.GetTeamMembers(rule.TeamID)
+		if err != nil {
+			return err
+		}
+		candidates := make([]*models.User, 0, len(members))
+		for _, member := range members {
+			if member.IsAway() {
+				continue
+			}
+			valid, err := models.CanBeAssigned(member, issue.Repo, issue.IsPull)
+			if err != nil {
+				return err
+			}
+			if valid {
+				candidates = append(candidates, member)
+			}
+		}
+
+		assignee := rule.NextAssignee(candidates, issue.PosterID)
+		if assignee == nil {
+			continue
+		}
+		if err := AddAssigneeIfNotAssigned(issue, doer, assignee.ID); err != nil {
+			return err
+		}
+		return models.UpdateIssueAssignRuleLastAssignee(rule, assignee.ID)
+	}
+	return nil
+}
+
+// applyAssignRules applies the assignment rules without failing the calling
+// action, which has already been done.
+func applyAssignRules(issue *models.Issue, doer *models.User) {
+	if err := ApplyAssignRules(issue, doer); err != nil {
+		log.Error("ApplyAssignRules[%d]: %v", issue.ID, err)
+	}
+}
+
+// getPullChangedFiles returns the files changed by a pull request since its merge base.
+func getPullChangedFiles(pr *models.PullRequest) ([]string, error) {
+	if pr == nil || pr.MergeBase == "" {
+		return nil, nil
+	}
+	if err := pr.LoadBaseRepo(); err != nil {
+		return nil, err
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := gitRepo.GetCommit(headCommitID)
+	if err != nil {
+		return nil, err
+	}
+	return headCommit.GetFilesChangedSinceCommit(pr.MergeBase)
+}
@@ -0,0 +1,35 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyAssignRules(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 3}).(*models.Repository)
+	poster := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	// rules not matching the issue are skipped
+	assert.NoError(t, models.NewIssueAssignRule(&models.IssueAssignRule{RepoID: 3, Name: "labeled", TeamID: 2, LabelID: 1}))
+	rule := &models.IssueAssignRule{RepoID: 3, Name: "all", TeamID: 2}
+	assert.NoError(t, models.NewIssueAssignRule(rule))
+
+	// the poster is skipped, so the issue goes to the other team member
+	issue := &models.Issue{RepoID: repo.ID, Repo: repo, Title: "assign me", PosterID: poster.ID, Poster: poster}
+	assert.NoError(t, NewIssue(repo, issue, nil, nil, nil))
+	models.AssertExistsAndLoadBean(t, &models.IssueAssignees{IssueID: issue.ID, AssigneeID: 4})
+	models.AssertExistsAndLoadBean(t, &models.IssueAssignRule{ID: rule.ID, LastAssigneeID: 4})
+
+	// issues which already have assignees are left alone
+	issue = models.AssertExistsAndLoadBean(t, &models.Issue{ID: 6}).(*models.Issue)
+	assert.NoError(t, ApplyAssignRules(issue, poster))
+	models.AssertExistsAndLoadBean(t, &models.IssueAssignRule{ID: rule.ID, LastAssigneeID: 4})
+}
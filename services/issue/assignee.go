@@ -71,3 +71,19 @@ func ReviewRequest(issue *models.Issue, doer *models.User, reviewer *models.User
 
 	return nil
 }
+
+// TeamReviewRequest requests a review from the members of the team picked by
+// the review assign algorithm of the team, and returns them.
+func TeamReviewRequest(issue *models.Issue, doer *models.User, team *models.Team) ([]*models.User, error) {
+	reviewers, err := models.PickTeamReviewers(team, issue, doer)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reviewer := range reviewers {
+		if err = ReviewRequest(issue, doer, reviewer, true); err != nil {
+			return nil, err
+		}
+	}
+	return reviewers, nil
+}
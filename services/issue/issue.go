@@ -25,6 +25,9 @@ func NewIssue(repo *models.Repository, issue *models.Issue, labelIDs []int64, uu
 
 	notification.NotifyNewIssue(issue)
 
+	applyAssignRules(issue, issue.Poster)
+	applyProjectRules(issue)
+
 	return nil
 }
 
@@ -38,6 +41,7 @@ func ChangeTitle(issue *models.Issue, doer *models.User, title string) (err erro
 	}
 
 	notification.NotifyIssueChangeTitle(doer, issue, oldTitle)
+	applyProjectRules(issue)
 
 	return nil
 }
@@ -27,6 +27,8 @@ func AddLabel(issue *models.Issue, doer *models.User, label *models.Label) error
 	}
 
 	notification.NotifyIssueChangeLabels(doer, issue, []*models.Label{label}, nil)
+	applyAssignRules(issue, doer)
+	applyProjectRules(issue)
 	return nil
 }
 
@@ -37,6 +39,8 @@ func AddLabels(issue *models.Issue, doer *models.User, labels []*models.Label) e
 	}
 
 	notification.NotifyIssueChangeLabels(doer, issue, labels, nil)
+	applyAssignRules(issue, doer)
+	applyProjectRules(issue)
 	return nil
 }
 
@@ -77,5 +81,7 @@ func ReplaceLabels(issue *models.Issue, doer *models.User, labels []*models.Labe
 	}
 
 	notification.NotifyIssueChangeLabels(doer, issue, labels, old)
+	applyAssignRules(issue, doer)
+	applyProjectRules(issue)
 	return nil
 }
@@ -0,0 +1,51 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// moveProjectIssues moves the cards of the issues in the projects automating
+// the target column without failing the calling action, which has already
+// been done.
+func moveProjectIssues(issueIDs []int64, target models.ProjectAutomationTarget) {
+	if err := models.MoveProjectIssuesByAutomation(issueIDs, target); err != nil {
+		log.Error("MoveProjectIssuesByAutomation[%v]: %v", issueIDs, err)
+	}
+}
+
+// MoveLinkedProjectIssues moves the cards of the issues the pull request
+// closes when it is merged to the target column of their projects.
+func MoveLinkedProjectIssues(pr *models.PullRequest, target models.ProjectAutomationTarget) {
+	issueIDs, err := models.GetPullRequestLinkedIssueIDs(pr)
+	if err != nil {
+		log.Error("GetPullRequestLinkedIssueIDs[%d]: %v", pr.ID, err)
+		return
+	}
+	moveProjectIssues(issueIDs, target)
+}
+
+// applyProjectAutomation moves the cards of the issue, or of the issues
+// linked to the pull request, after the issue has been closed or reopened.
+func applyProjectAutomation(issue *models.Issue, isClosed bool) {
+	if !issue.IsPull {
+		if isClosed {
+			moveProjectIssues([]int64{issue.ID}, models.ProjectAutomationDone)
+		}
+		return
+	}
+
+	if err := issue.LoadPullRequest(); err != nil {
+		log.Error("LoadPullRequest[%d]: %v", issue.ID, err)
+		return
+	}
+	if isClosed {
+		MoveLinkedProjectIssues(issue.PullRequest, models.ProjectAutomationDone)
+	} else {
+		MoveLinkedProjectIssues(issue.PullRequest, models.ProjectAutomationInProgress)
+	}
+}
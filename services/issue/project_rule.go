@@ -0,0 +1,19 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// applyProjectRules adds the issue to the organization projects with a
+// matching rule without failing the calling action, which has already been
+// done.
+func applyProjectRules(issue *models.Issue) {
+	if err := models.ApplyProjectRules(issue); err != nil {
+		log.Error("ApplyProjectRules[%d]: %v", issue.ID, err)
+	}
+}
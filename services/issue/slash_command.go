@@ -0,0 +1,299 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// The slash commands of comments
+const (
+	SlashCommandAssign    = "assign"
+	SlashCommandLabel     = "label"
+	SlashCommandMilestone = "milestone"
+	SlashCommandClose     = "close"
+	SlashCommandDuplicate = "duplicate"
+)
+
+var slashCommands = map[string]bool{
+	SlashCommandAssign:    true,
+	SlashCommandLabel:     true,
+	SlashCommandMilestone: true,
+	SlashCommandClose:     true,
+	SlashCommandDuplicate: true,
+}
+
+// SlashCommand is a command given on its own line of a comment, like "/label bug"
+type SlashCommand struct {
+	Name string
+	Args []string
+}
+
+func (cmd *SlashCommand) String() string {
+	return strings.TrimSpace("/" + cmd.Name + " " + strings.Join(cmd.Args, " "))
+}
+
+// ErrInvalidSlashCommand represents a "InvalidSlashCommand" kind of error, the command
+// cannot be applied to the issue.
+type ErrInvalidSlashCommand struct {
+	Command string
+	Reason  string
+}
+
+// IsErrInvalidSlashCommand checks if an error is a ErrInvalidSlashCommand.
+func IsErrInvalidSlashCommand(err error) bool {
+	_, ok := err.(ErrInvalidSlashCommand)
+	return ok
+}
+
+func (err ErrInvalidSlashCommand) Error() string {
+	return fmt.Sprintf("invalid slash command [command: %s, reason: %s]", err.Command, err.Reason)
+}
+
+// ParseSlashCommands extracts the slash commands of the content, they are the lines starting
+// with a known command outside of code blocks. The content is returned without them.
+func ParseSlashCommands(content string) (string, []*SlashCommand) {
+	var (
+		cmds    []*SlashCommand
+		lines   = strings.Split(content, "\n")
+		kept    = make([]string, 0, len(lines))
+		inFence bool
+	)
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if inFence || !strings.HasPrefix(trimmed, "/") {
+			kept = append(kept, line)
+			continue
+		}
+		fields := splitSlashCommandArgs(trimmed[1:])
+		if len(fields) == 0 || !slashCommands[strings.ToLower(fields[0])] {
+			kept = append(kept, line)
+			continue
+		}
+		cmds = append(cmds, &SlashCommand{
+			Name: strings.ToLower(fields[0]),
+			Args: fields[1:],
+		})
+	}
+	if len(cmds) == 0 {
+		return content, nil
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n")), cmds
+}
+
+// splitSlashCommandArgs splits the line at spaces, double quotes keep names with spaces together
+func splitSlashCommandArgs(line string) []string {
+	var (
+		args    []string
+		current strings.Builder
+		quoted  bool
+	)
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case (r == ' ' || r == '\t') && !quoted:
+			if current.Len() > 0 {
+				args = append(args, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// PrepareSlashCommands checks that the doer can run the commands on the issue and resolves
+// the users, labels, milestone and issues they refer to.
+func PrepareSlashCommands(doer *models.User, issue *models.Issue, cmds []*SlashCommand) (*models.IssueCommands, error) {
+	if err := issue.LoadRepo(); err != nil {
+		return nil, err
+	}
+	perm, err := models.GetUserRepoPermission(issue.Repo, doer)
+	if err != nil {
+		return nil, err
+	}
+	canWrite := perm.CanWriteIssuesOrPulls(issue.IsPull)
+
+	prepared := &models.IssueCommands{}
+	for _, cmd := range cmds {
+		if !canWrite && !(cmd.Name == SlashCommandClose && issue.IsPoster(doer.ID)) {
+			return nil, ErrInvalidSlashCommand{Command: cmd.String(), Reason: "permission denied"}
+		}
+		if err := prepareSlashCommand(prepared, issue, cmd); err != nil {
+			return nil, err
+		}
+	}
+	return prepared, nil
+}
+
+func prepareSlashCommand(prepared *models.IssueCommands, issue *models.Issue, cmd *SlashCommand) error {
+	invalid := func(reason string) error {
+		return ErrInvalidSlashCommand{Command: cmd.String(), Reason: reason}
+	}
+
+	switch cmd.Name {
+	case SlashCommandAssign:
+		if len(cmd.Args) == 0 {
+			return invalid("no user given")
+		}
+		for _, name := range cmd.Args {
+			u, err := models.GetUserByName(strings.TrimPrefix(name, "@"))
+			if err != nil {
+				if models.IsErrUserNotExist(err) {
+					return invalid("user does not exist: " + name)
+				}
+				return err
+			}
+			valid, err := models.CanBeAssigned(u, issue.Repo, issue.IsPull)
+			if err != nil {
+				return err
+			}
+			if !valid {
+				return invalid("user cannot be assigned: " + name)
+			}
+			prepared.Assignees = append(prepared.Assignees, u)
+		}
+	case SlashCommandLabel:
+		if len(cmd.Args) == 0 {
+			return invalid("no label given")
+		}
+		for _, name := range cmd.Args {
+			label, err := getLabelByName(issue.Repo, strings.TrimPrefix(name, "~"))
+			if err != nil {
+				if models.IsErrRepoLabelNotExist(err) || models.IsErrOrgLabelNotExist(err) {
+					return invalid("label does not exist: " + name)
+				}
+				return err
+			}
+			prepared.Labels = append(prepared.Labels, label)
+		}
+	case SlashCommandMilestone:
+		name := strings.TrimPrefix(strings.Join(cmd.Args, " "), "%")
+		if len(name) == 0 {
+			return invalid("no milestone given")
+		}
+		milestone, err := models.GetMilestoneByRepoIDANDName(issue.RepoID, name)
+		if err != nil {
+			if models.IsErrMilestoneNotExist(err) {
+				return invalid("milestone does not exist: " + name)
+			}
+			return err
+		}
+		prepared.Milestone = milestone
+	case SlashCommandClose, SlashCommandDuplicate:
+		if issue.IsPull {
+			if err := issue.LoadPullRequest(); err != nil {
+				return err
+			}
+			if issue.PullRequest.HasMerged {
+				return invalid("the pull request is merged")
+			}
+		}
+		if !issue.IsClosed && issue.Repo.IsDependenciesEnabled() {
+			noDeps, err := models.IssueNoDependenciesLeft(issue)
+			if err != nil {
+				return err
+			}
+			if !noDeps {
+				return invalid("the issue has open dependencies")
+			}
+		}
+		if cmd.Name == SlashCommandClose {
+			prepared.Close = true
+			return nil
+		}
+
+		if len(cmd.Args) != 1 {
+			return invalid("one issue must be given")
+		}
+		index, err := strconv.ParseInt(strings.TrimPrefix(cmd.Args[0], "#"), 10, 64)
+		if err != nil {
+			return invalid("invalid issue: " + cmd.Args[0])
+		}
+		original, err := models.GetIssueByIndex(issue.RepoID, index)
+		if err != nil {
+			if models.IsErrIssueNotExist(err) {
+				return invalid("issue does not exist: " + cmd.Args[0])
+			}
+			return err
+		}
+		if original.ID == issue.ID {
+			return invalid("an issue cannot be a duplicate of itself")
+		}
+		prepared.DuplicateOf = original
+	}
+	return nil
+}
+
+// getLabelByName returns the label of the repository, or of its organization, with the name
+func getLabelByName(repo *models.Repository, name string) (*models.Label, error) {
+	label, err := models.GetLabelInRepoByName(repo.ID, name)
+	if err == nil || !models.IsErrRepoLabelNotExist(err) {
+		return label, err
+	}
+	if err := repo.GetOwner(); err != nil {
+		return nil, err
+	}
+	if !repo.Owner.IsOrganization() {
+		return nil, err
+	}
+	return models.GetLabelInOrgByName(repo.OwnerID, name)
+}
+
+// CreateIssueComment creates a comment on the issue and applies the slash commands it
+// contains, the comment is not created when a command is invalid. The returned comment
+// is nil if the content only had commands.
+func CreateIssueComment(doer *models.User, repo *models.Repository, issue *models.Issue, content string, attachments []string) (*models.Comment, error) {
+	content, cmds := ParseSlashCommands(content)
+	prepared, err := PrepareSlashCommands(doer, issue, cmds)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := models.CreateCommentWithCommands(&models.CreateCommentOptions{
+		Type:        models.CommentTypeComment,
+		Doer:        doer,
+		Repo:        repo,
+		Issue:       issue,
+		Content:     content,
+		Attachments: attachments,
+	}, prepared)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Comment != nil {
+		notification.NotifyCreateIssueComment(doer, repo, issue, result.Comment)
+	}
+	for assignee, comment := range result.AssigneeComments {
+		notification.NotifyIssueChangeAssignee(doer, issue, assignee, false, comment)
+	}
+	if len(result.Labels) > 0 {
+		notification.NotifyIssueChangeLabels(doer, issue, result.Labels, nil)
+		applyAssignRules(issue, doer)
+		applyProjectRules(issue)
+	}
+	if result.MilestoneChanged {
+		notification.NotifyIssueChangeMilestone(doer, issue, result.OldMilestoneID)
+	}
+	if result.StatusComment != nil {
+		notification.NotifyIssueChangeStatus(doer, issue, result.StatusComment, true)
+		applyProjectAutomation(issue, true)
+	}
+	return result.Comment, nil
+}
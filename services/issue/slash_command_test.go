@@ -0,0 +1,104 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSlashCommands(t *testing.T) {
+	content, cmds := ParseSlashCommands("Looks good\n/label bug \"good first issue\"\n  /Assign @user1 @user2\n/usr/bin is not a command\n```\n/close\n```\n/close")
+	assert.Equal(t, "Looks good\n/usr/bin is not a command\n```\n/close\n```", content)
+	if assert.Len(t, cmds, 3) {
+		assert.Equal(t, &SlashCommand{Name: "label", Args: []string{"bug", "good first issue"}}, cmds[0])
+		assert.Equal(t, &SlashCommand{Name: "assign", Args: []string{"@user1", "@user2"}}, cmds[1])
+		assert.Equal(t, &SlashCommand{Name: "close", Args: []string{}}, cmds[2])
+	}
+
+	content, cmds = ParseSlashCommands("no commands\n")
+	assert.Equal(t, "no commands\n", content)
+	assert.Empty(t, cmds)
+}
+
+func TestPrepareSlashCommands(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	_, cmds := ParseSlashCommands("/assign @user2\n/label label2\n/milestone milestone2\n/duplicate #2")
+	prepared, err := PrepareSlashCommands(doer, issue, cmds)
+	assert.NoError(t, err)
+	if assert.Len(t, prepared.Assignees, 1) {
+		assert.EqualValues(t, 2, prepared.Assignees[0].ID)
+	}
+	if assert.Len(t, prepared.Labels, 1) {
+		assert.EqualValues(t, 2, prepared.Labels[0].ID)
+	}
+	assert.EqualValues(t, 2, prepared.Milestone.ID)
+	assert.EqualValues(t, 2, prepared.DuplicateOf.ID)
+
+	for _, content := range []string{
+		"/assign @nonexistent",
+		"/label nonexistent",
+		"/milestone nonexistent",
+		"/duplicate #1",
+		"/duplicate #9999",
+		"/assign",
+	} {
+		_, cmds := ParseSlashCommands(content)
+		_, err := PrepareSlashCommands(doer, issue, cmds)
+		assert.True(t, IsErrInvalidSlashCommand(err), content)
+	}
+
+	// an issue with open dependencies cannot be closed
+	assert.NoError(t, issue.LoadRepo())
+	issue.Repo.MustGetUnit(models.UnitTypeIssues).IssuesConfig().EnableDependencies = true
+	dependency := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 2}).(*models.Issue)
+	assert.NoError(t, models.CreateIssueDependency(doer, issue, dependency))
+	for _, content := range []string{"/close", "/duplicate #3"} {
+		_, cmds := ParseSlashCommands(content)
+		_, err := PrepareSlashCommands(doer, issue, cmds)
+		assert.True(t, IsErrInvalidSlashCommand(err), content)
+	}
+	assert.NoError(t, models.RemoveIssueDependency(doer, issue, dependency, models.DependencyTypeBlockedBy))
+	issue.Repo.MustGetUnit(models.UnitTypeIssues).IssuesConfig().EnableDependencies = false
+
+	// user4 cannot write the issues of the repository
+	user4 := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	_, cmds = ParseSlashCommands("/close")
+	_, err = PrepareSlashCommands(user4, issue, cmds)
+	assert.True(t, IsErrInvalidSlashCommand(err))
+}
+
+func TestCreateIssueComment_SlashCommands(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	assert.NoError(t, issue.LoadRepo())
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	comment, err := CreateIssueComment(doer, issue.Repo, issue, "Done\n/label label2\n/milestone milestone2\n/close", nil)
+	assert.NoError(t, err)
+	if assert.NotNil(t, comment) {
+		assert.Equal(t, "Done", comment.Content)
+	}
+	models.AssertExistsAndLoadBean(t, &models.IssueLabel{IssueID: issue.ID, LabelID: 2})
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: issue.ID, MilestoneID: 2, IsClosed: true})
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: issue.ID, Type: models.CommentTypeLabel, LabelID: 2})
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: issue.ID, Type: models.CommentTypeClose})
+
+	// a comment of commands only is not created, an invalid command changes nothing
+	comment, err = CreateIssueComment(doer, issue.Repo, issue, "/duplicate #2", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, comment)
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: issue.ID, Type: models.CommentTypeMarkDuplicate, DependentIssueID: 2})
+
+	_, err = CreateIssueComment(doer, issue.Repo, issue, "Invalid\n/label label1\n/label nonexistent", nil)
+	assert.True(t, IsErrInvalidSlashCommand(err))
+	models.AssertNotExistsBean(t, &models.Comment{IssueID: issue.ID, Content: "Invalid"})
+}
@@ -17,5 +17,6 @@ func ChangeStatus(issue *models.Issue, doer *models.User, isClosed bool) (err er
 	}
 
 	notification.NotifyIssueChangeStatus(doer, issue, comment, isClosed)
+	applyProjectAutomation(issue, isClosed)
 	return nil
 }
@@ -0,0 +1,26 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// ChangeWorkflowState moves an issue to another workflow state and closes or
+// reopens it when the new state maps to a different status.
+func ChangeWorkflowState(issue *models.Issue, doer *models.User, stateID int64) error {
+	oldStateID := issue.WorkflowStateID
+	statusComment, err := issue.ChangeWorkflowState(doer, stateID)
+	if err != nil {
+		return err
+	}
+
+	notification.NotifyIssueChangeWorkflowState(doer, issue, oldStateID)
+	if statusComment != nil {
+		notification.NotifyIssueChangeStatus(doer, issue, statusComment, issue.IsClosed)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issuetracker
+
+import (
+	"errors"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// syncQueue is the queue of the IDs of the events to post to the issue trackers
+var syncQueue queue.Queue
+
+// Init starts the service posting the commits and pull requests referencing the issues of the
+// issue trackers of the repositories to them
+func Init() error {
+	if !setting.IssueTrackerSync.Enabled {
+		return nil
+	}
+
+	syncQueue = queue.CreateQueue("issue_tracker_sync", handle, int64(0))
+	if syncQueue == nil {
+		return fmt.Errorf("Unable to create issue_tracker_sync Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(syncQueue.Run)
+
+	notification.RegisterNotifier(NewNotifier())
+	return nil
+}
+
+func handle(data ...queue.Data) {
+	for _, datum := range data {
+		event, err := models.GetIssueTrackerEventByID(datum.(int64))
+		if err != nil {
+			log.Error("GetIssueTrackerEventByID[%d]: %v", datum.(int64), err)
+			continue
+		}
+		if err := Deliver(event); err != nil {
+			log.Error("Deliver[%d]: %v", event.ID, err)
+		}
+	}
+}
+
+// Deliver posts an event to the issue tracker of its repository and records the result
+func Deliver(event *models.IssueTrackerEvent) error {
+	trackerSync, err := models.GetIssueTrackerSync(event.RepoID)
+	if err != nil {
+		return fmt.Errorf("GetIssueTrackerSync: %v", err)
+	}
+
+	var deliveryErr error
+	if trackerSync == nil {
+		deliveryErr = errors.New("the issue tracker sync of the repository has been removed")
+	} else {
+		cfg := trackerSync.Config()
+		deliveryErr = cfg.PostComment(event.Key, event.Comment)
+		if deliveryErr == nil && event.Kind == models.IssueTrackerEventPullRequest {
+			deliveryErr = cfg.AddRemoteLink(event.Key, "gitea:"+event.Link, event.Link, event.Title, event.Action != actionOpened)
+		}
+	}
+	if deliveryErr != nil {
+		log.Warn("Unable to post event %d to the issue %s of repository %d: %v", event.ID, event.Key, event.RepoID, deliveryErr)
+	}
+	return models.UpdateIssueTrackerEventDelivery(event, deliveryErr)
+}
+
+// addEvents records the events of the keys and adds the new ones to the queue
+func addEvents(keys []string, event models.IssueTrackerEvent) {
+	for _, key := range keys {
+		e := event
+		e.Key = key
+		created, err := models.CreateIssueTrackerEvent(&e)
+		if err != nil {
+			log.Error("CreateIssueTrackerEvent: %v", err)
+			continue
+		}
+		if !created {
+			continue
+		}
+		if err := syncQueue.Push(e.ID); err != nil {
+			log.Error("Unable to add the event %d of issue %s to the queue: %v", e.ID, key, err)
+		}
+	}
+}
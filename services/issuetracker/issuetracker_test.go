@@ -0,0 +1,87 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issuetracker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/issuetracker"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliver(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	var paths []string
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/api/2/issue/PROJ-2/comment" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body := make(map[string]interface{})
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		paths = append(paths, r.URL.Path)
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	// the events of a repository without a sync fail
+	event := &models.IssueTrackerEvent{RepoID: 1, Key: "PROJ-1", Kind: models.IssueTrackerEventCommit, Ref: "abc", Action: actionPushed, Comment: "pushed"}
+	created, err := models.CreateIssueTrackerEvent(event)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.NoError(t, Deliver(event))
+	event, err = models.GetIssueTrackerEventByID(event.ID)
+	assert.NoError(t, err)
+	assert.False(t, event.IsDelivered)
+	assert.NotEmpty(t, event.Error)
+
+	assert.NoError(t, models.SaveIssueTrackerSync(&models.IssueTrackerSync{
+		RepoID:                1,
+		Type:                  issuetracker.TypeJira,
+		URL:                   server.URL,
+		ProjectKey:            "PROJ",
+		Token:                 "token",
+		CommentOnCommits:      true,
+		CommentOnPullRequests: true,
+	}))
+
+	assert.NoError(t, Deliver(event))
+	event, err = models.GetIssueTrackerEventByID(event.ID)
+	assert.NoError(t, err)
+	assert.True(t, event.IsDelivered)
+	assert.Empty(t, event.Error)
+	assert.EqualValues(t, []string{"/rest/api/2/issue/PROJ-1/comment"}, paths)
+	assert.EqualValues(t, "pushed", bodies[0]["body"])
+
+	// the pull requests are also linked to the issues
+	event = &models.IssueTrackerEvent{RepoID: 1, Key: "PROJ-1", Kind: models.IssueTrackerEventPullRequest, Ref: "2", Action: actionMerged,
+		Link: "http://localhost:3000/user2/repo1/pulls/2", Title: "user2/repo1#2: issue2", Comment: "merged"}
+	created, err = models.CreateIssueTrackerEvent(event)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.NoError(t, Deliver(event))
+	assert.EqualValues(t, []string{"/rest/api/2/issue/PROJ-1/comment", "/rest/api/2/issue/PROJ-1/comment", "/rest/api/2/issue/PROJ-1/remotelink"}, paths)
+	assert.EqualValues(t, "gitea:http://localhost:3000/user2/repo1/pulls/2", bodies[2]["globalId"])
+	object := bodies[2]["object"].(map[string]interface{})
+	assert.EqualValues(t, map[string]interface{}{"resolved": true}, object["status"])
+
+	// the errors of the tracker are recorded
+	event = &models.IssueTrackerEvent{RepoID: 1, Key: "PROJ-2", Kind: models.IssueTrackerEventCommit, Ref: "abc", Action: actionPushed, Comment: "pushed"}
+	_, err = models.CreateIssueTrackerEvent(event)
+	assert.NoError(t, err)
+	assert.NoError(t, Deliver(event))
+	event, err = models.GetIssueTrackerEventByID(event.ID)
+	assert.NoError(t, err)
+	assert.False(t, event.IsDelivered)
+	assert.Contains(t, event.Error, "404")
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issuetracker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/repository"
+)
+
+const (
+	actionPushed = "pushed"
+	actionOpened = "opened"
+	actionMerged = "merged"
+	actionClosed = "closed"
+)
+
+type issueTrackerNotifier struct {
+	base.NullNotifier
+}
+
+var (
+	_ base.Notifier = &issueTrackerNotifier{}
+)
+
+// NewNotifier create a new issueTrackerNotifier notifier
+func NewNotifier() base.Notifier {
+	return &issueTrackerNotifier{}
+}
+
+// NotifyPushCommits comments the commits pushed to a branch on the issues they reference
+func (*issueTrackerNotifier) NotifyPushCommits(pusher *models.User, repo *models.Repository, refName, oldCommitID, newCommitID string, commits *repository.PushCommits) {
+	if !strings.HasPrefix(refName, git.BranchPrefix) || commits == nil {
+		return
+	}
+	trackerSync, err := models.GetIssueTrackerSync(repo.ID)
+	if err != nil {
+		log.Error("GetIssueTrackerSync: %v", err)
+		return
+	} else if trackerSync == nil || !trackerSync.CommentOnCommits {
+		return
+	}
+
+	cfg := trackerSync.Config()
+	branch := strings.TrimPrefix(refName, git.BranchPrefix)
+	for _, commit := range commits.Commits {
+		keys := cfg.FindKeys(commit.Message)
+		if len(keys) == 0 {
+			continue
+		}
+		subject := strings.SplitN(strings.TrimSpace(commit.Message), "\n", 2)[0]
+		shortSha := commit.Sha1
+		if len(shortSha) > 10 {
+			shortSha = shortSha[:10]
+		}
+		link := repo.CommitLink(commit.Sha1)
+		addEvents(keys, models.IssueTrackerEvent{
+			RepoID:  repo.ID,
+			Kind:    models.IssueTrackerEventCommit,
+			Ref:     commit.Sha1,
+			Action:  actionPushed,
+			Link:    link,
+			Title:   subject,
+			Comment: fmt.Sprintf("%s pushed commit %s to %s of %s:\n%s\n%s", pusher.Name, shortSha, branch, repo.FullName(), subject, link),
+		})
+	}
+}
+
+// NotifyNewPullRequest comments a new pull request on the issues it references
+func (*issueTrackerNotifier) NotifyNewPullRequest(pr *models.PullRequest) {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+	if err := pr.Issue.LoadPoster(); err != nil {
+		log.Error("LoadPoster: %v", err)
+		return
+	}
+	notifyPullRequest(pr.Issue, pr.Issue.Poster, actionOpened)
+}
+
+// NotifyMergePullRequest comments a merged pull request on the issues it references
+func (*issueTrackerNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User) {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+	notifyPullRequest(pr.Issue, doer, actionMerged)
+}
+
+// NotifyIssueChangeStatus comments a pull request closed without being merged on the issues it
+// references
+func (*issueTrackerNotifier) NotifyIssueChangeStatus(doer *models.User, issue *models.Issue, actionComment *models.Comment, isClosed bool) {
+	if !issue.IsPull || !isClosed {
+		return
+	}
+	if err := issue.LoadPullRequest(); err != nil {
+		log.Error("LoadPullRequest: %v", err)
+		return
+	}
+	if issue.PullRequest.HasMerged {
+		return
+	}
+	notifyPullRequest(issue, doer, actionClosed)
+}
+
+func notifyPullRequest(issue *models.Issue, doer *models.User, action string) {
+	if err := issue.LoadRepo(); err != nil {
+		log.Error("LoadRepo: %v", err)
+		return
+	}
+	trackerSync, err := models.GetIssueTrackerSync(issue.RepoID)
+	if err != nil {
+		log.Error("GetIssueTrackerSync: %v", err)
+		return
+	} else if trackerSync == nil || !trackerSync.CommentOnPullRequests {
+		return
+	}
+
+	keys := trackerSync.Config().FindKeys(issue.Title + "\n" + issue.Content)
+	if len(keys) == 0 {
+		return
+	}
+	link := issue.HTMLURL()
+	title := fmt.Sprintf("%s#%d: %s", issue.Repo.FullName(), issue.Index, issue.Title)
+	addEvents(keys, models.IssueTrackerEvent{
+		RepoID:  issue.RepoID,
+		Kind:    models.IssueTrackerEventPullRequest,
+		Ref:     strconv.FormatInt(issue.Index, 10),
+		Action:  action,
+		Link:    link,
+		Title:   title,
+		Comment: fmt.Sprintf("%s %s pull request %s\n%s", doer.Name, action, title, link),
+	})
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// RecordBounce records a bounce of the address reported by the source, the mailer stops
+// sending to the address once it is suppressed.
+func RecordBounce(email string, typ models.EmailBounceType, reason, source string) error {
+	bounce, err := models.RecordEmailBounce(email, typ, reason, source, setting.MailService.SoftBounceLimit)
+	if err != nil {
+		return err
+	}
+	if bounce.IsSuppressed {
+		log.Info("E-mail address %s is suppressed after a %s bounce from %s: %s", bounce.Email, typ.Name(), source, reason)
+	} else {
+		log.Trace("E-mail address %s bounced (%s, %d times) from %s: %s", bounce.Email, typ.Name(), bounce.Count, source, reason)
+	}
+	return nil
+}
+
+// BounceReport is a failed delivery or a complaint found in a report mail
+type BounceReport struct {
+	Email  string
+	Type   models.EmailBounceType
+	Reason string
+}
+
+// ParseBounceReport returns the bounces of a delivery status notification (RFC 3464) or a
+// feedback report of a complaint (RFC 5965). A mail which is not a report has no bounces.
+func ParseBounceReport(r io.Reader) ([]*BounceReport, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" {
+		return nil, nil
+	}
+
+	var reports []*BounceReport
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			return reports, nil
+		} else if err != nil {
+			return reports, err
+		}
+		switch strings.ToLower(strings.TrimSpace(strings.Split(part.Header.Get("Content-Type"), ";")[0])) {
+		case "message/delivery-status":
+			found, err := parseDeliveryStatus(part)
+			if err != nil {
+				return reports, err
+			}
+			reports = append(reports, found...)
+		case "message/feedback-report":
+			found, err := parseFeedbackReport(part)
+			if err != nil {
+				return reports, err
+			}
+			reports = append(reports, found...)
+		}
+	}
+}
+
+// readFieldGroups reads the groups of header fields separated by blank lines
+func readFieldGroups(r io.Reader) ([]textproto.MIMEHeader, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	var groups []textproto.MIMEHeader
+	for _, block := range bytes.Split(content, []byte("\n\n")) {
+		if len(bytes.TrimSpace(block)) == 0 {
+			continue
+		}
+		header, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(append(block, '\n', '\n')))).ReadMIMEHeader()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		groups = append(groups, header)
+	}
+	return groups, nil
+}
+
+// addressOfField returns the address of a field like "rfc822; user@example.com"
+func addressOfField(value string) string {
+	if i := strings.Index(value, ";"); i >= 0 {
+		value = value[i+1:]
+	}
+	return strings.Trim(strings.TrimSpace(value), "<>")
+}
+
+// parseDeliveryStatus returns the failed recipients of a delivery status, a 5.x.x status
+// is a hard bounce and a 4.x.x status a soft one.
+func parseDeliveryStatus(r io.Reader) ([]*BounceReport, error) {
+	groups, err := readFieldGroups(r)
+	if err != nil {
+		return nil, err
+	}
+	var reports []*BounceReport
+	// the first group is about the message, the next ones about each recipient
+	for _, fields := range groups {
+		email := addressOfField(fields.Get("Final-Recipient"))
+		if email == "" || !strings.EqualFold(fields.Get("Action"), "failed") {
+			continue
+		}
+		status := strings.TrimSpace(fields.Get("Status"))
+		typ := models.EmailBounceSoft
+		if strings.HasPrefix(status, "5") {
+			typ = models.EmailBounceHard
+		}
+		reason := status
+		if diagnostic := strings.TrimSpace(fields.Get("Diagnostic-Code")); diagnostic != "" {
+			reason += " " + diagnostic
+		}
+		reports = append(reports, &BounceReport{Email: email, Type: typ, Reason: reason})
+	}
+	return reports, nil
+}
+
+// parseFeedbackReport returns the recipient who complained about a mail
+func parseFeedbackReport(r io.Reader) ([]*BounceReport, error) {
+	groups, err := readFieldGroups(r)
+	if err != nil || len(groups) == 0 {
+		return nil, err
+	}
+	email := addressOfField(groups[0].Get("Original-Rcpt-To"))
+	if email == "" {
+		return nil, nil
+	}
+	reason := "complaint"
+	if feedbackType := groups[0].Get("Feedback-Type"); feedbackType != "" {
+		reason += ": " + feedbackType
+	}
+	return []*BounceReport{{Email: email, Type: models.EmailBounceComplaint, Reason: reason}}, nil
+}
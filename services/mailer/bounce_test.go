@@ -0,0 +1,72 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const deliveryStatusReport = "From: MAILER-DAEMON@example.com\r\n" +
+	"To: gitea@example.com\r\n" +
+	"Subject: Undelivered Mail Returned to Sender\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status; boundary=\"BOUNDARY\"\r\n" +
+	"\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"The mail could not be delivered.\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; mail.example.com\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; unknown@example.com\r\n" +
+	"Action: failed\r\n" +
+	"Status: 5.1.1\r\n" +
+	"Diagnostic-Code: smtp; 550 5.1.1 user unknown\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; full@example.com\r\n" +
+	"Action: failed\r\n" +
+	"Status: 4.2.2\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; later@example.com\r\n" +
+	"Action: delayed\r\n" +
+	"Status: 4.4.1\r\n" +
+	"--BOUNDARY--\r\n"
+
+const feedbackReport = "From: abuse@example.com\r\n" +
+	"Content-Type: multipart/report; report-type=feedback-report; boundary=\"BOUNDARY\"\r\n" +
+	"\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: message/feedback-report\r\n" +
+	"\r\n" +
+	"Feedback-Type: abuse\r\n" +
+	"Original-Rcpt-To: <user@example.com>\r\n" +
+	"--BOUNDARY--\r\n"
+
+func TestParseBounceReport(t *testing.T) {
+	reports, err := ParseBounceReport(strings.NewReader(deliveryStatusReport))
+	assert.NoError(t, err)
+	assert.Equal(t, []*BounceReport{
+		{Email: "unknown@example.com", Type: models.EmailBounceHard, Reason: "5.1.1 smtp; 550 5.1.1 user unknown"},
+		{Email: "full@example.com", Type: models.EmailBounceSoft, Reason: "4.2.2"},
+	}, reports)
+
+	reports, err = ParseBounceReport(strings.NewReader(feedbackReport))
+	assert.NoError(t, err)
+	assert.Equal(t, []*BounceReport{
+		{Email: "user@example.com", Type: models.EmailBounceComplaint, Reason: "complaint: abuse"},
+	}, reports)
+
+	reports, err = ParseBounceReport(strings.NewReader("Subject: hello\r\nContent-Type: text/plain\r\n\r\nhello\r\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, reports)
+}
@@ -0,0 +1,227 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+
+	"golang.org/x/time/rate"
+)
+
+// recipientError is an error of the SMTP server about a recipient of a mail
+type recipientError struct {
+	Address string
+	Err     error
+}
+
+func (err *recipientError) Error() string {
+	return fmt.Sprintf("%s: %v", err.Address, err.Err)
+}
+
+func (err *recipientError) Unwrap() error {
+	return err.Err
+}
+
+// isPermanentError checks if the SMTP server answered with a permanent failure, the mail
+// would fail the same way when sent again. Network errors and 4xx answers are temporary.
+func isPermanentError(err error) bool {
+	var protoErr *textproto.Error
+	return errors.As(err, &protoErr) && protoErr.Code >= 500
+}
+
+// bouncedRecipient returns the recipient the SMTP server refused as unknown or not allowed,
+// the address has bounced.
+func bouncedRecipient(err error) (string, bool) {
+	var rcptErr *recipientError
+	var protoErr *textproto.Error
+	if !errors.As(err, &rcptErr) || !errors.As(rcptErr.Err, &protoErr) {
+		return "", false
+	}
+	// 550 mailbox unavailable, 551 user not local, 552 exceeded storage, 553 mailbox name not allowed
+	return rcptErr.Address, protoErr.Code >= 550 && protoErr.Code <= 553
+}
+
+// retryBackoff returns the delay before sending a mail again after its attempt failed,
+// it doubles at each attempt.
+func retryBackoff(attempt int) time.Duration {
+	backoff := setting.MailService.RetryBackoff
+	for i := 1; i < attempt && backoff < 24*time.Hour; i++ {
+		backoff *= 2
+	}
+	return backoff
+}
+
+// recipientDomain returns the domain of an address in lower case
+func recipientDomain(address string) string {
+	address = strings.TrimSuffix(strings.TrimSpace(address), ">")
+	return strings.ToLower(address[strings.LastIndex(address, "@")+1:])
+}
+
+// domainThrottle limits the number of mails sent to each recipient domain
+type domainThrottle struct {
+	lock     sync.Mutex
+	perMin   int
+	limiters map[string]*rate.Limiter
+}
+
+var throttle = &domainThrottle{}
+
+// delay returns how long to wait before the mail to the addresses can be sent, the mail is
+// counted as sent for each domain when it can be sent now.
+func (t *domainThrottle) delay(addresses []string, now time.Time) time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	perMin := setting.MailService.DomainRateLimit
+	if perMin <= 0 {
+		return 0
+	}
+	if perMin != t.perMin || t.limiters == nil {
+		t.perMin = perMin
+		t.limiters = make(map[string]*rate.Limiter)
+	}
+
+	var (
+		delay        time.Duration
+		reservations = make([]*rate.Reservation, 0, len(addresses))
+	)
+	for _, address := range addresses {
+		domain := recipientDomain(address)
+		limiter, ok := t.limiters[domain]
+		if !ok {
+			limiter = rate.NewLimiter(rate.Limit(float64(perMin)/60), perMin)
+			t.limiters[domain] = limiter
+		}
+		r := limiter.ReserveN(now, 1)
+		reservations = append(reservations, r)
+		if d := r.DelayFrom(now); d > delay {
+			delay = d
+		}
+	}
+	if delay > 0 {
+		// the mail is sent later, so it is counted then
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+	}
+	return delay
+}
+
+// scheduledMessages are the mails waiting for a retry or for the rate limit of their domain
+var scheduledMessages = struct {
+	sync.Mutex
+	timers map[*Message]*time.Timer
+}{timers: make(map[*Message]*time.Timer)}
+
+func pushMessage(msg *Message) {
+	if err := mailQueue.Push(msg); err != nil {
+		log.Error("Unable to push the e-mail %s to the queue: %s - %v", msg.To, msg.Info, err)
+	}
+}
+
+// scheduleMessage pushes the mail to the queue again after the delay
+func scheduleMessage(msg *Message, delay time.Duration) {
+	if delay <= 0 {
+		pushMessage(msg)
+		return
+	}
+	scheduledMessages.Lock()
+	defer scheduledMessages.Unlock()
+	scheduledMessages.timers[msg] = time.AfterFunc(delay, func() {
+		scheduledMessages.Lock()
+		delete(scheduledMessages.timers, msg)
+		scheduledMessages.Unlock()
+		pushMessage(msg)
+	})
+}
+
+// flushScheduledMessages pushes the scheduled mails to the queue without waiting, so that
+// the persistent queue keeps them over a restart.
+func flushScheduledMessages() {
+	scheduledMessages.Lock()
+	defer scheduledMessages.Unlock()
+	for msg, timer := range scheduledMessages.timers {
+		if timer.Stop() {
+			pushMessage(msg)
+		}
+	}
+	scheduledMessages.timers = make(map[*Message]*time.Timer)
+}
+
+// handleMessages is the handler of the mail queue
+func handleMessages(data ...queue.Data) {
+	for _, datum := range data {
+		deliverMessage(datum.(*Message))
+	}
+}
+
+// deliverMessage sends a mail to its recipients which are not suppressed. A temporary
+// failure is retried with a backoff, a recipient refused by the server is recorded as a
+// hard bounce.
+func deliverMessage(msg *Message) {
+	suppressed, err := models.GetSuppressedEmails(msg.To)
+	if err != nil {
+		log.Error("GetSuppressedEmails: %v", err)
+	} else if len(suppressed) > 0 {
+		to := make([]string, 0, len(msg.To))
+		for _, address := range msg.To {
+			if !suppressed[strings.ToLower(address)] {
+				to = append(to, address)
+			}
+		}
+		log.Trace("Skipping the suppressed addresses of e-mail %s: %s", msg.To, msg.Info)
+		if len(to) == 0 {
+			return
+		}
+		msg.To = to
+	}
+
+	if delay := throttle.delay(msg.To, time.Now()); delay > 0 {
+		log.Trace("Delaying e-mail %s by %v for the rate limit of its domain: %s", msg.To, delay, msg.Info)
+		scheduleMessage(msg, delay)
+		return
+	}
+
+	gomailMsg := msg.ToMessage()
+	log.Trace("New e-mail sending request %s: %s", gomailMsg.GetHeader("To"), msg.Info)
+	// the sender is called directly as gomail.Send does not wrap its errors
+	err = Sender.Send(msg.FromAddress, msg.To, gomailMsg)
+	if err == nil {
+		log.Trace("E-mails sent %s: %s", gomailMsg.GetHeader("To"), msg.Info)
+		return
+	}
+
+	if address, ok := bouncedRecipient(err); ok {
+		if bounceErr := RecordBounce(address, models.EmailBounceHard, err.Error(), "smtp"); bounceErr != nil {
+			log.Error("RecordBounce: %v", bounceErr)
+		}
+		if len(msg.To) == 1 {
+			return
+		}
+		// the other recipients get the mail once the bounced address is suppressed, it is
+		// retried like a temporary failure in case the address could not be suppressed
+	} else if isPermanentError(err) {
+		log.Error("Failed to send emails %s: %s - %v", gomailMsg.GetHeader("To"), msg.Info, err)
+		return
+	}
+	if msg.Attempts >= setting.MailService.MaxRetries {
+		log.Error("Failed to send emails %s after %d retries: %s - %v", gomailMsg.GetHeader("To"), msg.Attempts, msg.Info, err)
+		return
+	}
+	msg.Attempts++
+	backoff := retryBackoff(msg.Attempts)
+	log.Warn("Failed to send emails %s: %s - %v, retry %d in %v", gomailMsg.GetHeader("To"), msg.Info, err, msg.Attempts, backoff)
+	scheduleMessage(msg, backoff)
+}
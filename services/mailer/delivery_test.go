@@ -0,0 +1,129 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSender struct {
+	to  [][]string
+	err error
+}
+
+func (s *fakeSender) Send(from string, to []string, msg io.WriterTo) error {
+	s.to = append(s.to, to)
+	return s.err
+}
+
+func TestRetryBackoff(t *testing.T) {
+	setting.MailService = &setting.Mailer{RetryBackoff: time.Minute}
+	assert.Equal(t, time.Minute, retryBackoff(1))
+	assert.Equal(t, 2*time.Minute, retryBackoff(2))
+	assert.Equal(t, 16*time.Minute, retryBackoff(5))
+	assert.True(t, retryBackoff(100) < 48*time.Hour)
+}
+
+func TestRecipientDomain(t *testing.T) {
+	assert.Equal(t, "example.com", recipientDomain("user@Example.COM"))
+	assert.Equal(t, "example.com", recipientDomain("<user@example.com>"))
+}
+
+func TestDomainThrottle(t *testing.T) {
+	setting.MailService = &setting.Mailer{DomainRateLimit: 2}
+	throttle := &domainThrottle{}
+	now := time.Now()
+
+	assert.Zero(t, throttle.delay([]string{"a@example.com"}, now))
+	assert.Zero(t, throttle.delay([]string{"b@example.com", "c@example.org"}, now))
+	// the limit of example.com is reached, a mail is allowed again after 30 seconds
+	delay := throttle.delay([]string{"c@example.org", "d@example.com"}, now)
+	assert.True(t, delay > 29*time.Second && delay <= 30*time.Second, delay)
+	// the cancelled reservation of example.org is not counted
+	assert.Zero(t, throttle.delay([]string{"e@example.org"}, now))
+	assert.Zero(t, throttle.delay([]string{"d@example.com"}, now.Add(30*time.Second)))
+
+	setting.MailService.DomainRateLimit = 0
+	assert.Zero(t, throttle.delay([]string{"f@example.com"}, now))
+}
+
+func TestBouncedRecipient(t *testing.T) {
+	err := fmt.Errorf("Rcpt: %w", &recipientError{Address: "user@example.com", Err: &textproto.Error{Code: 550, Msg: "5.1.1 unknown user"}})
+	address, ok := bouncedRecipient(err)
+	assert.True(t, ok)
+	assert.Equal(t, "user@example.com", address)
+	assert.True(t, isPermanentError(err))
+
+	err = fmt.Errorf("Rcpt: %w", &recipientError{Address: "user@example.com", Err: &textproto.Error{Code: 450, Msg: "4.2.1 try again later"}})
+	_, ok = bouncedRecipient(err)
+	assert.False(t, ok)
+	assert.False(t, isPermanentError(err))
+
+	_, ok = bouncedRecipient(fmt.Errorf("Mail: %w", &textproto.Error{Code: 550, Msg: "sender refused"}))
+	assert.False(t, ok)
+	assert.False(t, isPermanentError(errors.New("connection refused")))
+}
+
+func TestDeliverMessage(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	setting.MailService = &setting.Mailer{From: "test@gitea.com", MaxRetries: 2, RetryBackoff: time.Hour, SoftBounceLimit: 3}
+
+	_, err := models.RecordEmailBounce("suppressed@example.com", models.EmailBounceHard, "unknown user", "webhook", 3)
+	assert.NoError(t, err)
+
+	sender := &fakeSender{}
+	Sender = sender
+	deliverMessage(NewMessage([]string{"suppressed@example.com"}, "subject", "body"))
+	assert.Empty(t, sender.to)
+	deliverMessage(NewMessage([]string{"Suppressed@example.com", "user@example.com"}, "subject", "body"))
+	assert.Equal(t, [][]string{{"user@example.com"}}, sender.to)
+
+	// a refused recipient is suppressed
+	sender.err = fmt.Errorf("Rcpt: %w", &recipientError{Address: "unknown@example.com", Err: &textproto.Error{Code: 550, Msg: "5.1.1 unknown user"}})
+	deliverMessage(NewMessage([]string{"unknown@example.com"}, "subject", "body"))
+	models.AssertExistsAndLoadBean(t, &models.EmailBounce{Email: "unknown@example.com", Source: "smtp", IsSuppressed: true})
+
+	// the other recipients are retried with the backoff
+	msg := NewMessage([]string{"unknown2@example.com", "user@example.com"}, "subject", "body")
+	sender.err = fmt.Errorf("Rcpt: %w", &recipientError{Address: "unknown2@example.com", Err: &textproto.Error{Code: 550, Msg: "5.1.1 unknown user"}})
+	deliverMessage(msg)
+	assert.Equal(t, 1, msg.Attempts)
+	scheduledMessages.Lock()
+	timer, ok := scheduledMessages.timers[msg]
+	assert.True(t, ok)
+	assert.True(t, timer.Stop())
+	delete(scheduledMessages.timers, msg)
+	scheduledMessages.Unlock()
+
+	// a temporary failure is retried later
+	sender.err = errors.New("connection refused")
+	msg = NewMessage([]string{"user@example.com"}, "subject", "body")
+	deliverMessage(msg)
+	assert.Equal(t, 1, msg.Attempts)
+	scheduledMessages.Lock()
+	timer, ok = scheduledMessages.timers[msg]
+	assert.True(t, ok)
+	assert.True(t, timer.Stop())
+	delete(scheduledMessages.timers, msg)
+	scheduledMessages.Unlock()
+
+	// and given up after the retries
+	msg.Attempts = 2
+	deliverMessage(msg)
+	assert.Equal(t, 2, msg.Attempts)
+	scheduledMessages.Lock()
+	assert.Empty(t, scheduledMessages.timers)
+	scheduledMessages.Unlock()
+}
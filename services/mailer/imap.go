@@ -0,0 +1,201 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// imapResponse is an untagged response of an IMAP server with the literals it contains
+type imapResponse struct {
+	Line     string
+	Literals [][]byte
+}
+
+// imapClient is a minimal IMAP client, it only knows the commands needed to read the bounce
+// reports of a mailbox.
+type imapClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// dialIMAP connects to the IMAP server of the bounce mailbox over TLS, port 993 is used
+// when the host has no port.
+func dialIMAP() (*imapClient, error) {
+	host := setting.MailService.BounceIMAPHost
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "993")
+	}
+	serverName, _, _ := net.SplitHostPort(host)
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", host, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: setting.MailService.BounceIMAPSkipVerify,
+	})
+	if err != nil {
+		return nil, err
+	}
+	c := &imapClient{conn: conn, r: bufio.NewReader(conn)}
+	greeting, err := c.readLine()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected IMAP greeting: %s", greeting)
+	}
+	return c, nil
+}
+
+func (c *imapClient) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readResponse reads a response line and the literals it contains
+func (c *imapClient) readResponse() (*imapResponse, error) {
+	resp := &imapResponse{}
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return nil, err
+		}
+		resp.Line += line
+		start := strings.LastIndex(line, "{")
+		if start < 0 || !strings.HasSuffix(line, "}") {
+			return resp, nil
+		}
+		size, err := strconv.Atoi(line[start+1 : len(line)-1])
+		if err != nil {
+			return resp, nil
+		}
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(c.r, literal); err != nil {
+			return nil, err
+		}
+		resp.Literals = append(resp.Literals, literal)
+	}
+}
+
+// command sends a command and returns its untagged responses, an error is returned when
+// the command does not succeed.
+func (c *imapClient) command(format string, args ...interface{}) ([]*imapResponse, error) {
+	c.tag++
+	tag := fmt.Sprintf("a%d", c.tag)
+	if err := c.conn.SetDeadline(time.Now().Add(time.Minute)); err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, fmt.Sprintf(format, args...)); err != nil {
+		return nil, err
+	}
+
+	var resps []*imapResponse
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(resp.Line, tag+" ") {
+			resps = append(resps, resp)
+			continue
+		}
+		if status := strings.TrimPrefix(resp.Line, tag+" "); !strings.HasPrefix(status, "OK") {
+			return nil, fmt.Errorf("IMAP %s: %s", strings.Fields(format)[0], status)
+		}
+		return resps, nil
+	}
+}
+
+func (c *imapClient) Close() error {
+	_, _ = c.command("LOGOUT")
+	return c.conn.Close()
+}
+
+// imapQuote quotes a string argument of an IMAP command
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// ProcessBounceMailbox reads the unseen mails of the bounce mailbox and records the bounces of
+// the delivery status notifications and complaints. The mails are marked as seen, so the
+// mailbox should only receive the reports of the mails sent by Gitea.
+func ProcessBounceMailbox(ctx context.Context) error {
+	if setting.MailService == nil || setting.MailService.BounceIMAPHost == "" {
+		return nil
+	}
+
+	c, err := dialIMAP()
+	if err != nil {
+		return fmt.Errorf("dialIMAP: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.command("LOGIN %s %s", imapQuote(setting.MailService.BounceIMAPUser), imapQuote(setting.MailService.BounceIMAPPasswd)); err != nil {
+		return err
+	}
+	if _, err := c.command("SELECT %s", imapQuote(setting.MailService.BounceIMAPMailbox)); err != nil {
+		return err
+	}
+	resps, err := c.command("UID SEARCH UNSEEN")
+	if err != nil {
+		return err
+	}
+	var uids []string
+	for _, resp := range resps {
+		if strings.HasPrefix(resp.Line, "* SEARCH") {
+			uids = append(uids, strings.Fields(strings.TrimPrefix(resp.Line, "* SEARCH"))...)
+		}
+	}
+
+	var count int
+	for _, uid := range uids {
+		select {
+		case <-ctx.Done():
+			return models.ErrCancelledf("before reading the bounce report %s", uid)
+		default:
+		}
+
+		resps, err := c.command("UID FETCH %s BODY.PEEK[]", uid)
+		if err != nil {
+			return err
+		}
+		for _, resp := range resps {
+			for _, literal := range resp.Literals {
+				reports, err := ParseBounceReport(bytes.NewReader(literal))
+				if err != nil {
+					log.Warn("Unable to parse the bounce report %s: %v", uid, err)
+				}
+				for _, report := range reports {
+					if err := RecordBounce(report.Email, report.Type, report.Reason, "imap"); err != nil {
+						return err
+					}
+					count++
+				}
+			}
+		}
+		if _, err := c.command(`UID STORE %s +FLAGS.SILENT (\Seen)`, uid); err != nil {
+			return err
+		}
+	}
+	log.Trace("Read %d bounces from %d mails of the bounce mailbox", count, len(uids))
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"mime"
 	"regexp"
 	"strings"
+	"sync"
 	texttmpl "text/template"
 
 	"code.gitea.io/gitea/models"
@@ -23,6 +24,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 
+	"github.com/unknwon/i18n"
 	"gopkg.in/gomail.v2"
 )
 
@@ -32,22 +34,59 @@ const (
 	mailAuthResetPassword  base.TplName = "auth/reset_passwd"
 	mailAuthRegisterNotify base.TplName = "auth/register_notify"
 
-	mailNotifyCollaborator base.TplName = "notify/collaborator"
+	mailNotifyCollaborator     base.TplName = "notify/collaborator"
+	mailNotifyRepoVisibility   base.TplName = "notify/repo_visibility"
+	mailNotifyAttachmentExpiry base.TplName = "notify/attachment_expiry"
 
 	// There's no actual limit for subject in RFC 5322
 	mailMaxSubjectRunes = 256
 )
 
 var (
-	bodyTemplates       *template.Template
-	subjectTemplates    *texttmpl.Template
 	subjectRemoveSpaces = regexp.MustCompile(`[\s]+`)
+
+	// the templates are replaced when the customized mail templates change
+	mailRenderLock   sync.RWMutex
+	bodyTemplates    *template.Template
+	subjectTemplates *texttmpl.Template
+	textTemplates    map[string]*texttmpl.Template
 )
 
-// InitMailRender initializes the mail renderer
-func InitMailRender(subjectTpl *texttmpl.Template, bodyTpl *template.Template) {
+// InitMailRender initializes the mail renderer, the texts are the templates of the plain text
+// alternatives by template name.
+func InitMailRender(subjectTpl *texttmpl.Template, bodyTpl *template.Template, texts ...map[string]*texttmpl.Template) {
+	mailRenderLock.Lock()
+	defer mailRenderLock.Unlock()
 	subjectTemplates = subjectTpl
 	bodyTemplates = bodyTpl
+	textTemplates = nil
+	if len(texts) > 0 {
+		textTemplates = texts[0]
+	}
+}
+
+func getMailRender() (*texttmpl.Template, *template.Template, map[string]*texttmpl.Template) {
+	mailRenderLock.RLock()
+	defer mailRenderLock.RUnlock()
+	return subjectTemplates, bodyTemplates, textTemplates
+}
+
+// renderMailBody executes the body template of the name and its plain text alternative, the
+// plain body is empty when the template has none.
+func renderMailBody(name string, data interface{}) (body, plainBody string, err error) {
+	_, btpl, texts := getMailRender()
+	var content bytes.Buffer
+	if err := btpl.ExecuteTemplate(&content, name, data); err != nil {
+		return "", "", err
+	}
+	if text := texts[name]; text != nil {
+		var plain bytes.Buffer
+		if err := text.Execute(&plain, data); err != nil {
+			return "", "", err
+		}
+		plainBody = plain.String()
+	}
+	return content.String(), plainBody, nil
 }
 
 // SendTestMail sends a test mail
@@ -64,14 +103,14 @@ func SendUserMail(language string, u *models.User, tpl base.TplName, code, subje
 		"Code":              code,
 	}
 
-	var content bytes.Buffer
-
-	if err := bodyTemplates.ExecuteTemplate(&content, string(tpl), data); err != nil {
+	content, plainContent, err := renderMailBody(string(tpl), data)
+	if err != nil {
 		log.Error("Template: %v", err)
 		return
 	}
 
-	msg := NewMessage([]string{u.Email}, subject, content.String())
+	msg := NewMessage([]string{u.Email}, subject, content)
+	msg.PlainBody = plainContent
 	msg.Info = fmt.Sprintf("UID: %d, %s", u.ID, info)
 
 	SendAsync(msg)
@@ -102,14 +141,14 @@ func SendActivateEmailMail(locale Locale, u *models.User, email *models.EmailAdd
 		"Email":           email.Email,
 	}
 
-	var content bytes.Buffer
-
-	if err := bodyTemplates.ExecuteTemplate(&content, string(mailAuthActivateEmail), data); err != nil {
+	content, plainContent, err := renderMailBody(string(mailAuthActivateEmail), data)
+	if err != nil {
 		log.Error("Template: %v", err)
 		return
 	}
 
-	msg := NewMessage([]string{email.Email}, locale.Tr("mail.activate_email"), content.String())
+	msg := NewMessage([]string{email.Email}, locale.Tr("mail.activate_email"), content)
+	msg.PlainBody = plainContent
 	msg.Info = fmt.Sprintf("UID: %d, activate email", u.ID)
 
 	SendAsync(msg)
@@ -127,14 +166,14 @@ func SendRegisterNotifyMail(locale Locale, u *models.User) {
 		"Username":    u.Name,
 	}
 
-	var content bytes.Buffer
-
-	if err := bodyTemplates.ExecuteTemplate(&content, string(mailAuthRegisterNotify), data); err != nil {
+	content, plainContent, err := renderMailBody(string(mailAuthRegisterNotify), data)
+	if err != nil {
 		log.Error("Template: %v", err)
 		return
 	}
 
-	msg := NewMessage([]string{u.Email}, locale.Tr("mail.register_notify"), content.String())
+	msg := NewMessage([]string{u.Email}, locale.Tr("mail.register_notify"), content)
+	msg.PlainBody = plainContent
 	msg.Info = fmt.Sprintf("UID: %d, registration notify", u.ID)
 
 	SendAsync(msg)
@@ -151,20 +190,81 @@ func SendCollaboratorMail(u, doer *models.User, repo *models.Repository) {
 		"Link":     repo.HTMLURL(),
 	}
 
-	var content bytes.Buffer
-
-	if err := bodyTemplates.ExecuteTemplate(&content, string(mailNotifyCollaborator), data); err != nil {
+	content, plainContent, err := renderMailBody(string(mailNotifyCollaborator), data)
+	if err != nil {
 		log.Error("Template: %v", err)
 		return
 	}
 
-	msg := NewMessage([]string{u.Email}, subject, content.String())
+	msg := NewMessage([]string{u.Email}, subject, content)
+	msg.PlainBody = plainContent
 	msg.Info = fmt.Sprintf("UID: %d, add collaborator", u.ID)
 
 	SendAsync(msg)
 }
 
-func composeIssueCommentMessages(ctx *mailCommentContext, tos []string, fromMention bool, info string) []*Message {
+// SendRepoVisibilityChangedMail notifies the users that the visibility of the repository
+// has been changed as scheduled by doer
+func SendRepoVisibilityChangedMail(users []*models.User, doer *models.User, repo *models.Repository) {
+	repoName := repo.FullName()
+	visibility := "public"
+	if repo.IsPrivate {
+		visibility = "private"
+	}
+	subject := fmt.Sprintf("%s is now %s", repoName, visibility)
+
+	data := map[string]interface{}{
+		"Subject":    subject,
+		"RepoName":   repoName,
+		"Visibility": visibility,
+		"Doer":       doer,
+		"Link":       repo.HTMLURL(),
+	}
+
+	content, plainContent, err := renderMailBody(string(mailNotifyRepoVisibility), data)
+	if err != nil {
+		log.Error("Template: %v", err)
+		return
+	}
+
+	for _, u := range users {
+		msg := NewMessage([]string{u.Email}, subject, content)
+		msg.PlainBody = plainContent
+		msg.Info = fmt.Sprintf("UID: %d, repository visibility changed", u.ID)
+
+		SendAsync(msg)
+	}
+}
+
+// SendAttachmentExpiryMail notifies the user that the attachments the user uploaded to a
+// closed issue are deleted at the expiry date
+func SendAttachmentExpiryMail(u *models.User, issue *models.Issue, attachments []*models.Attachment, expiry timeutil.TimeStamp) {
+	repoName := issue.Repo.FullName()
+	subject := fmt.Sprintf("Attachments of %s#%d will be deleted", repoName, issue.Index)
+
+	data := map[string]interface{}{
+		"Subject":     subject,
+		"RepoName":    repoName,
+		"Issue":       issue,
+		"Attachments": attachments,
+		"ExpiryDate":  expiry.FormatDate(),
+		"Link":        issue.HTMLURL(),
+	}
+
+	content, plainContent, err := renderMailBody(string(mailNotifyAttachmentExpiry), data)
+	if err != nil {
+		log.Error("Template: %v", err)
+		return
+	}
+
+	msg := NewMessage([]string{u.Email}, subject, content)
+	msg.PlainBody = plainContent
+	msg.Info = fmt.Sprintf("UID: %d, attachment expiry of issue %d", u.ID, issue.ID)
+
+	SendAsync(msg)
+}
+
+func composeIssueCommentMessages(ctx *mailCommentContext, lang string, tos []string, fromMention bool, info string) []*Message {
 
 	var (
 		subject string
@@ -222,10 +322,13 @@ func composeIssueCommentMessages(ctx *mailCommentContext, tos []string, fromMent
 		"ActionType":      actType,
 		"ActionName":      actName,
 		"ReviewComments":  reviewComments,
+		"Language":        lang,
+		"i18n":            i18n.Locale{Lang: lang},
 	}
 
+	stpl, _, _ := getMailRender()
 	var mailSubject bytes.Buffer
-	if err := subjectTemplates.ExecuteTemplate(&mailSubject, string(tplName), mailMeta); err == nil {
+	if err := stpl.ExecuteTemplate(&mailSubject, string(tplName), mailMeta); err == nil {
 		subject = sanitizeSubject(mailSubject.String())
 	} else {
 		log.Error("ExecuteTemplate [%s]: %v", string(tplName)+"/subject", err)
@@ -239,16 +342,16 @@ func composeIssueCommentMessages(ctx *mailCommentContext, tos []string, fromMent
 
 	mailMeta["Subject"] = subject
 
-	var mailBody bytes.Buffer
-
-	if err := bodyTemplates.ExecuteTemplate(&mailBody, string(tplName), mailMeta); err != nil {
+	mailBody, plainBody, err := renderMailBody(tplName, mailMeta)
+	if err != nil {
 		log.Error("ExecuteTemplate [%s]: %v", string(tplName)+"/body", err)
 	}
 
 	// Make sure to compose independent messages to avoid leaking user emails
 	msgs := make([]*Message, 0, len(tos))
 	for _, to := range tos {
-		msg := NewMessageFrom([]string{to}, ctx.Doer.DisplayName(), setting.MailService.FromEmail, subject, mailBody.String())
+		msg := NewMessageFrom([]string{to}, ctx.Doer.DisplayName(), setting.MailService.FromEmail, subject, mailBody)
+		msg.PlainBody = plainBody
 		msg.Info = fmt.Sprintf("Subject: %s, %s", subject, info)
 
 		// Set Message-ID on first message so replies know what to reference
@@ -274,14 +377,37 @@ func sanitizeSubject(subject string) string {
 }
 
 // SendIssueAssignedMail composes and sends issue assigned email
-func SendIssueAssignedMail(issue *models.Issue, doer *models.User, content string, comment *models.Comment, tos []string) {
-	SendAsyncs(composeIssueCommentMessages(&mailCommentContext{
+func SendIssueAssignedMail(issue *models.Issue, doer *models.User, content string, comment *models.Comment, recipients []*models.User) error {
+	ctx := &mailCommentContext{
 		Issue:      issue,
 		Doer:       doer,
 		ActionType: models.ActionType(0),
 		Content:    content,
 		Comment:    comment,
-	}, tos, false, "issue assigned"))
+	}
+	langMap, err := recipientsByLanguage(issue.Repo, recipients)
+	if err != nil {
+		return err
+	}
+	for lang, tos := range langMap {
+		SendAsyncs(composeIssueCommentMessages(ctx, lang, tos, false, "issue assigned"))
+	}
+	return nil
+}
+
+// recipientsByLanguage groups the emails of the recipients by the language of the mails sent
+// to them, it is the language of the recipient or the default language of the repository.
+func recipientsByLanguage(repo *models.Repository, recipients []*models.User) (map[string][]string, error) {
+	localeSetting, err := models.GetEffectiveLocaleSetting(repo)
+	if err != nil {
+		return nil, err
+	}
+	langMap := make(map[string][]string)
+	for _, user := range recipients {
+		lang := localeSetting.LanguageFor(user)
+		langMap[lang] = append(langMap[lang], user.Email)
+	}
+	return langMap, nil
 }
 
 // actionToTemplate returns the type and name of the action facing the user
@@ -326,15 +452,16 @@ func actionToTemplate(issue *models.Issue, actionType models.ActionType,
 		}
 	}
 
+	_, btpl, _ := getMailRender()
 	template = typeName + "/" + name
-	ok := bodyTemplates.Lookup(template) != nil
+	ok := btpl.Lookup(template) != nil
 	if !ok && typeName != "issue" {
 		template = "issue/" + name
-		ok = bodyTemplates.Lookup(template) != nil
+		ok = btpl.Lookup(template) != nil
 	}
 	if !ok {
 		template = typeName + "/default"
-		ok = bodyTemplates.Lookup(template) != nil
+		ok = btpl.Lookup(template) != nil
 	}
 	if !ok {
 		template = "issue/default"
@@ -123,12 +123,13 @@ func mailIssueCommentBatch(ctx *mailCommentContext, ids []int64, visited map[int
 			return err
 		}
 		// TODO: Check issue visibility for each user
-		// TODO: Separate recipients by language for i18n mail templates
-		tos := make([]string, len(recipients))
-		for i := range recipients {
-			tos[i] = recipients[i].Email
+		langMap, err := recipientsByLanguage(ctx.Issue.Repo, recipients)
+		if err != nil {
+			return err
+		}
+		for lang, tos := range langMap {
+			SendAsyncs(composeIssueCommentMessages(ctx, lang, tos, fromMention, "issue comments"))
 		}
-		SendAsyncs(composeIssueCommentMessages(ctx, tos, fromMention, "issue comments"))
 	}
 	return nil
 }
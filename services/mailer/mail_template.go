@@ -0,0 +1,191 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"html/template"
+	"sort"
+	"strings"
+	texttmpl "text/template"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/jaytaylor/html2text"
+	"github.com/unknwon/i18n"
+	"gopkg.in/gomail.v2"
+)
+
+// issueMailActions are the names of the actions of the issue and pull request mails, a mail
+// uses the template of its action if it exists and falls back to the default one.
+var issueMailActions = []string{"new", "comment", "close", "reopen", "merge", "approve", "reject", "review", "code", "assigned", "push", "default"}
+
+// The variables given to the mail templates by group of templates
+var (
+	authMailVariables = []string{"DisplayName", "Code", "ActiveCodeLives", "ResetPwdCodeLives"}
+
+	mailTemplateVariables = map[string][]string{
+		string(mailAuthActivate):           authMailVariables,
+		string(mailAuthResetPassword):      authMailVariables,
+		string(mailAuthActivateEmail):      {"DisplayName", "Code", "ActiveCodeLives", "Email"},
+		string(mailAuthRegisterNotify):     {"DisplayName", "Username"},
+		string(mailNotifyCollaborator):     {"Subject", "RepoName", "Link"},
+		string(mailNotifyRepoVisibility):   {"Subject", "RepoName", "Visibility", "Doer", "Link"},
+		string(mailNotifyAttachmentExpiry): {"Subject", "RepoName", "Issue", "Attachments", "ExpiryDate", "Link"},
+	}
+
+	issueMailVariables = []string{"Subject", "FallbackSubject", "SubjectPrefix", "Body", "Link", "Issue", "Comment", "IsPull",
+		"User", "Repo", "Doer", "IsMention", "ActionType", "ActionName", "ReviewComments", "Language", "i18n"}
+)
+
+// IsIssueMailTemplate returns true if the template is used for the mails of issues and pull
+// requests.
+func IsIssueMailTemplate(name string) bool {
+	return strings.HasPrefix(name, "issue/") || strings.HasPrefix(name, "pull/")
+}
+
+// MailTemplateNames returns the names of the mail templates which can be customized, they are
+// the template files and the templates of the actions of issues and pull requests.
+func MailTemplateNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files)+2*len(issueMailActions))
+	for name := range files {
+		names = append(names, name)
+	}
+	for _, typeName := range []string{"issue", "pull"} {
+		for _, action := range issueMailActions {
+			if _, ok := files[typeName+"/"+action]; !ok {
+				names = append(names, typeName+"/"+action)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MailTemplateVariables returns the names of the variables given to a mail template.
+func MailTemplateVariables(name string) []string {
+	if IsIssueMailTemplate(name) {
+		return issueMailVariables
+	}
+	return mailTemplateVariables[name]
+}
+
+// sampleMailData returns the data of a mail template filled with sample values, the doer
+// receives the mail.
+func sampleMailData(name string, doer *models.User) map[string]interface{} {
+	owner := &models.User{Name: "owner", LowerName: "owner", FullName: "Repository Owner"}
+	repo := &models.Repository{Name: "repository", LowerName: "repository", OwnerName: owner.Name, Owner: owner}
+	lang := doer.Language
+
+	if IsIssueMailTemplate(name) {
+		actName := name[strings.Index(name, "/")+1:]
+		if actName == "default" {
+			actName = "comment"
+		}
+		issue := &models.Issue{
+			Index:    1,
+			Title:    "Sample issue",
+			Repo:     repo,
+			IsPull:   strings.HasPrefix(name, "pull/"),
+			Poster:   doer,
+			PosterID: doer.ID,
+		}
+		if issue.IsPull {
+			issue.PullRequest = &models.PullRequest{Issue: issue, BaseRepo: repo, HeadBranch: "feature", BaseBranch: "master"}
+		}
+		typeName := "issue"
+		if issue.IsPull {
+			typeName = "pull"
+		}
+		return map[string]interface{}{
+			"Subject":         "[" + repo.FullName() + "] " + issue.Title + " (#1)",
+			"FallbackSubject": "[" + repo.FullName() + "] " + issue.Title + " (#1)",
+			"SubjectPrefix":   "",
+			"Body":            "<p>The content of the sample issue or comment.</p>",
+			"Link":            issue.HTMLURL(),
+			"Issue":           issue,
+			"Comment":         nil,
+			"IsPull":          issue.IsPull,
+			"User":            owner,
+			"Repo":            repo.FullName(),
+			"Doer":            doer,
+			"IsMention":       false,
+			"ActionType":      typeName,
+			"ActionName":      actName,
+			"ReviewComments":  []*models.Comment{},
+			"Language":        lang,
+			"i18n":            i18n.Locale{Lang: lang},
+		}
+	}
+
+	return map[string]interface{}{
+		"DisplayName":       doer.DisplayName(),
+		"Username":          doer.Name,
+		"Email":             doer.Email,
+		"Code":              "sample-code",
+		"ActiveCodeLives":   timeutil.MinutesToFriendly(setting.Service.ActiveCodeLives, lang),
+		"ResetPwdCodeLives": timeutil.MinutesToFriendly(setting.Service.ResetPwdCodeLives, lang),
+		"Subject":           doer.DisplayName() + " added you to " + repo.FullName(),
+		"RepoName":          repo.FullName(),
+		"Visibility":        "private",
+		"Doer":              doer,
+		"Link":              repo.HTMLURL(),
+	}
+}
+
+// MailTemplatePreview is a mail template rendered with sample data.
+type MailTemplatePreview struct {
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+	PlainBody string `json:"plain_body"`
+}
+
+// PreviewMailTemplate renders the mail template of the name with sample data, text is the
+// template of the plain text alternative or nil to convert the body.
+func PreviewMailTemplate(stpl *texttmpl.Template, btpl *template.Template, text *texttmpl.Template, name string, doer *models.User) (*MailTemplatePreview, error) {
+	data := sampleMailData(name, doer)
+
+	preview := &MailTemplatePreview{}
+	var buf bytes.Buffer
+	if err := stpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
+	}
+	preview.Subject = strings.TrimSpace(subjectRemoveSpaces.ReplaceAllLiteralString(buf.String(), " "))
+	if preview.Subject == "" {
+		preview.Subject, _ = data["Subject"].(string)
+	}
+	data["Subject"] = preview.Subject
+
+	buf.Reset()
+	if err := btpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
+	}
+	preview.Body = buf.String()
+
+	if text != nil {
+		buf.Reset()
+		if err := text.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		preview.PlainBody = buf.String()
+	} else {
+		plainBody, err := html2text.FromString(preview.Body)
+		if err != nil {
+			return nil, err
+		}
+		preview.PlainBody = plainBody
+	}
+	return preview, nil
+}
+
+// SendMailTemplatePreview sends a rendered mail template to the address synchronously, so
+// that the error can be shown.
+func SendMailTemplatePreview(email string, preview *MailTemplatePreview) error {
+	msg := NewMessage([]string{email}, preview.Subject, preview.Body)
+	msg.PlainBody = preview.PlainBody
+	return gomail.Send(Sender, msg.ToMessage())
+}
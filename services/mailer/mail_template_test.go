@@ -0,0 +1,80 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+	texttmpl "text/template"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMailTemplateNames(t *testing.T) {
+	names := MailTemplateNames(map[string][]byte{"auth/activate": nil, "issue/default": nil, "pull/merge": nil})
+	assert.Contains(t, names, "auth/activate")
+	assert.Contains(t, names, "issue/new")
+	assert.Contains(t, names, "pull/push")
+	assert.Len(t, names, 1+2*len(issueMailActions))
+	assert.Equal(t, "auth/activate", names[0])
+
+	assert.Contains(t, MailTemplateVariables("pull/comment"), "Issue")
+	assert.Equal(t, []string{"DisplayName", "Username"}, MailTemplateVariables("auth/register_notify"))
+}
+
+func TestTemplatePlainText(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	setting.MailService = &setting.Mailer{From: "test@gitea.com"}
+	setting.Domain = "localhost"
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1, Owner: doer}).(*models.Repository)
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, Repo: repo, Poster: doer}).(*models.Issue)
+
+	stpl := texttmpl.Must(texttmpl.New("issue/default").Parse("subject"))
+	btpl := template.Must(template.New("issue/default").Parse("<p>html body</p>"))
+	text := texttmpl.Must(texttmpl.New("issue/default").Parse("plain body of #{{.Issue.Index}}"))
+	InitMailRender(stpl, btpl, map[string]*texttmpl.Template{"issue/default": text})
+	defer InitMailRender(stpl, btpl)
+
+	msg := testComposeIssueCommentMessage(t, &mailCommentContext{Issue: issue, Doer: doer, ActionType: models.ActionCreateIssue,
+		Content: "test body"}, []string{"test@gitea.com"}, false, "TestTemplatePlainText")
+	assert.Equal(t, "plain body of #1", msg.PlainBody)
+
+	msgbuf := new(bytes.Buffer)
+	_, _ = msg.ToMessage().WriteTo(msgbuf)
+	assert.Contains(t, msgbuf.String(), "plain body of #1")
+	assert.Contains(t, msgbuf.String(), "<p>html body</p>")
+}
+
+func TestPreviewMailTemplate(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	stpl := texttmpl.Must(texttmpl.New("pull/comment").Parse("[{{.Repo}}] {{.Issue.Title}}"))
+	btpl := template.Must(template.New("pull/comment").Parse(`<a href="{{.Link}}">{{.ActionName}} by {{.Doer.Name}}</a>`))
+	preview, err := PreviewMailTemplate(stpl, btpl, nil, "pull/comment", doer)
+	assert.NoError(t, err)
+	assert.Equal(t, "[owner/repository] Sample issue", preview.Subject)
+	assert.Equal(t, `<a href="`+setting.AppURL+`owner/repository/pulls/1">comment by user2</a>`, preview.Body)
+	assert.Contains(t, preview.PlainBody, "comment by user2")
+
+	stpl = texttmpl.Must(texttmpl.New("auth/activate").Parse(""))
+	btpl = template.Must(template.New("auth/activate").Parse("<p>{{.DisplayName}} {{.Code}}</p>"))
+	text := texttmpl.Must(texttmpl.New("auth/activate").Parse("Hi {{.DisplayName}}"))
+	preview, err = PreviewMailTemplate(stpl, btpl, text, "auth/activate", doer)
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>"+template.HTMLEscapeString(doer.DisplayName())+" sample-code</p>", preview.Body)
+	assert.Equal(t, "Hi "+doer.DisplayName(), preview.PlainBody)
+
+	// the missing fields fail the preview instead of the mails
+	btpl = template.Must(template.New("auth/activate").Parse("{{.Doer.Missing}}"))
+	_, err = PreviewMailTemplate(stpl, btpl, nil, "auth/activate", doer)
+	assert.Error(t, err)
+}
@@ -59,7 +59,7 @@ func TestComposeIssueCommentMessage(t *testing.T) {
 
 	tos := []string{"test@gitea.com", "test2@gitea.com"}
 	msgs := composeIssueCommentMessages(&mailCommentContext{Issue: issue, Doer: doer, ActionType: models.ActionCommentIssue,
-		Content: "test body", Comment: comment}, tos, false, "issue comment")
+		Content: "test body", Comment: comment}, "en-US", tos, false, "issue comment")
 	assert.Len(t, msgs, 2)
 	gomailMsg := msgs[0].ToMessage()
 	mailto := gomailMsg.GetHeader("To")
@@ -93,7 +93,7 @@ func TestComposeIssueMessage(t *testing.T) {
 
 	tos := []string{"test@gitea.com", "test2@gitea.com"}
 	msgs := composeIssueCommentMessages(&mailCommentContext{Issue: issue, Doer: doer, ActionType: models.ActionCreateIssue,
-		Content: "test body"}, tos, false, "issue create")
+		Content: "test body"}, "en-US", tos, false, "issue create")
 	assert.Len(t, msgs, 2)
 
 	gomailMsg := msgs[0].ToMessage()
@@ -108,6 +108,30 @@ func TestComposeIssueMessage(t *testing.T) {
 	assert.Equal(t, messageID[0], "<user2/repo1/issues/1@localhost>", "Message-ID header doesn't match")
 }
 
+func TestRecipientsByLanguage(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	// the organization of repo38 sends its mails in German
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 38}).(*models.Repository)
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	user4 := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	user2.Language = "fr-FR"
+	user4.Language = ""
+
+	langMap, err := recipientsByLanguage(repo, []*models.User{user2, user4})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"fr-FR": {user2.Email},
+		"de-DE": {user4.Email},
+	}, langMap)
+
+	// repo1 belongs to a user and has no default language
+	repo = models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	langMap, err = recipientsByLanguage(repo, []*models.User{user4})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"": {user4.Email}}, langMap)
+}
+
 func TestTemplateSelection(t *testing.T) {
 	assert.NoError(t, models.PrepareTestDatabase())
 	var mailService = setting.Mailer{
@@ -218,7 +242,7 @@ func TestTemplateServices(t *testing.T) {
 }
 
 func testComposeIssueCommentMessage(t *testing.T, ctx *mailCommentContext, tos []string, fromMention bool, info string) *Message {
-	msgs := composeIssueCommentMessages(ctx, tos, fromMention, info)
+	msgs := composeIssueCommentMessages(ctx, "en-US", tos, fromMention, info)
 	assert.Len(t, msgs, 1)
 	return msgs[0]
 }
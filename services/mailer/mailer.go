@@ -38,7 +38,12 @@ type Message struct {
 	Subject         string
 	Date            time.Time
 	Body            string
-	Headers         map[string][]string
+	// PlainBody is the plain text alternative of the body, it is converted from the body
+	// when empty.
+	PlainBody string
+	Headers   map[string][]string
+	// Attempts is the number of times sending the mail failed temporarily
+	Attempts int
 }
 
 // ToMessage converts a Message to gomail.Message
@@ -58,7 +63,11 @@ func (m *Message) ToMessage() *gomail.Message {
 	msg.SetDateHeader("Date", m.Date)
 	msg.SetHeader("X-Auto-Response-Suppress", "All")
 
-	plainBody, err := html2text.FromString(m.Body)
+	plainBody := m.PlainBody
+	var err error
+	if plainBody == "" {
+		plainBody, err = html2text.FromString(m.Body)
+	}
 	if err != nil || setting.MailService.SendAsPlainText {
 		if strings.Contains(base.TruncateString(m.Body, 100), "<html>") {
 			log.Warn("Mail contains HTML but configured to send as plain text.")
@@ -178,7 +187,7 @@ func (s *smtpSender) Send(from string, to []string, msg io.WriterTo) error {
 		}
 
 		if err = client.Hello(hostname); err != nil {
-			return fmt.Errorf("Hello: %v", err)
+			return fmt.Errorf("Hello: %w", err)
 		}
 	}
 
@@ -186,7 +195,7 @@ func (s *smtpSender) Send(from string, to []string, msg io.WriterTo) error {
 	hasStartTLS, _ := client.Extension("STARTTLS")
 	if !isSecureConn && hasStartTLS {
 		if err = client.StartTLS(tlsconfig); err != nil {
-			return fmt.Errorf("StartTLS: %v", err)
+			return fmt.Errorf("StartTLS: %w", err)
 		}
 	}
 
@@ -205,28 +214,28 @@ func (s *smtpSender) Send(from string, to []string, msg io.WriterTo) error {
 
 		if auth != nil {
 			if err = client.Auth(auth); err != nil {
-				return fmt.Errorf("Auth: %v", err)
+				return fmt.Errorf("Auth: %w", err)
 			}
 		}
 	}
 
 	if err = client.Mail(from); err != nil {
-		return fmt.Errorf("Mail: %v", err)
+		return fmt.Errorf("Mail: %w", err)
 	}
 
 	for _, rec := range to {
 		if err = client.Rcpt(rec); err != nil {
-			return fmt.Errorf("Rcpt: %v", err)
+			return fmt.Errorf("Rcpt: %w", &recipientError{Address: rec, Err: err})
 		}
 	}
 
 	w, err := client.Data()
 	if err != nil {
-		return fmt.Errorf("Data: %v", err)
+		return fmt.Errorf("Data: %w", err)
 	} else if _, err = msg.WriteTo(w); err != nil {
-		return fmt.Errorf("WriteTo: %v", err)
+		return fmt.Errorf("WriteTo: %w", err)
 	} else if err = w.Close(); err != nil {
-		return fmt.Errorf("Close: %v", err)
+		return fmt.Errorf("Close: %w", err)
 	}
 
 	return client.Quit()
@@ -301,6 +310,35 @@ var mailQueue queue.Queue
 // Sender sender for sending mail synchronously
 var Sender gomail.Sender
 
+func init() {
+	setting.OnSettingsReloaded("mailer", reloadMailer)
+}
+
+// newSender returns the sender of the configured mailer type
+func newSender() gomail.Sender {
+	switch setting.MailService.MailerType {
+	case "smtp":
+		return &smtpSender{}
+	case "sendmail":
+		return &sendmailSender{}
+	case "dummy":
+		return &dummySender{}
+	}
+	return nil
+}
+
+// reloadMailer starts the mail queue or switches the sender when the mailer settings are reloaded
+func reloadMailer() {
+	if setting.MailService == nil {
+		return
+	}
+	if mailQueue == nil {
+		NewContext()
+		return
+	}
+	Sender = newSender()
+}
+
 // NewContext start mail queue service
 func NewContext() {
 	// Need to check if mailQueue is nil because in during reinstall (user had installed
@@ -310,28 +348,11 @@ func NewContext() {
 		return
 	}
 
-	switch setting.MailService.MailerType {
-	case "smtp":
-		Sender = &smtpSender{}
-	case "sendmail":
-		Sender = &sendmailSender{}
-	case "dummy":
-		Sender = &dummySender{}
-	}
+	Sender = newSender()
 
-	mailQueue = queue.CreateQueue("mail", func(data ...queue.Data) {
-		for _, datum := range data {
-			msg := datum.(*Message)
-			gomailMsg := msg.ToMessage()
-			log.Trace("New e-mail sending request %s: %s", gomailMsg.GetHeader("To"), msg.Info)
-			if err := gomail.Send(Sender, gomailMsg); err != nil {
-				log.Error("Failed to send emails %s: %s - %v", gomailMsg.GetHeader("To"), msg.Info, err)
-			} else {
-				log.Trace("E-mails sent %s: %s", gomailMsg.GetHeader("To"), msg.Info)
-			}
-		}
-	}, &Message{})
+	mailQueue = queue.CreateQueue("mail", handleMessages, &Message{})
 
+	graceful.GetManager().RunAtShutdown(graceful.GetManager().HammerContext(), flushScheduledMessages)
 	go graceful.GetManager().RunWithShutdownFns(mailQueue.Run)
 }
 
@@ -279,6 +279,7 @@ func runSync(m *models.Mirror) ([]*mirrorSyncResult, bool) {
 	for i := range branches {
 		cache.Remove(m.Repo.GetCommitsCountCacheKey(branches[i].Name, true))
 	}
+	cache.Remove(models.GetPagesCacheKey(m.RepoID))
 
 	m.UpdatedUnix = timeutil.TimeStampNow()
 	return parseRemoteUpdateOutput(output), true
@@ -0,0 +1,457 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pages
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+const (
+	indexPage    = "index.html"
+	notFoundPage = "404.html"
+
+	// artifactVersionPrefix prefixes the UUID of the attachment a static site is served from
+	artifactVersionPrefix = "attachment:"
+
+	// domainVerifyRecordPrefix is prepended to a custom domain to get the name of its TXT record
+	domainVerifyRecordPrefix = "_gitea-pages."
+)
+
+// lookupTXT is replaced by the tests
+var lookupTXT = net.LookupTXT
+
+// ErrPageNotExist represents a "PageNotExist" kind of error.
+type ErrPageNotExist struct {
+	Path string
+}
+
+// IsErrPageNotExist checks if an error is a ErrPageNotExist.
+func IsErrPageNotExist(err error) bool {
+	_, ok := err.(ErrPageNotExist)
+	return ok
+}
+
+func (err ErrPageNotExist) Error() string {
+	return fmt.Sprintf("page does not exist [path: %s]", err.Path)
+}
+
+// ErrPageIsDir represents a "PageIsDir" kind of error, the page is a directory
+// requested without a trailing slash.
+type ErrPageIsDir struct {
+	Path string
+}
+
+// IsErrPageIsDir checks if an error is a ErrPageIsDir.
+func IsErrPageIsDir(err error) bool {
+	_, ok := err.(ErrPageIsDir)
+	return ok
+}
+
+func (err ErrPageIsDir) Error() string {
+	return fmt.Sprintf("page is a directory [path: %s]", err.Path)
+}
+
+// Page represents a file of a static site
+type Page struct {
+	io.ReadCloser
+	Name string
+	Size int64
+	ETag string
+	// IsNotFound is true if the page is the 404 page of the site served instead of a missing page
+	IsNotFound bool
+}
+
+// Site represents the static site of a repository
+type Site struct {
+	Repo   *models.Repository
+	Config *models.PagesConfig
+}
+
+// GetSite returns the static site of the repository, it returns nil if the repository has none.
+func GetSite(repo *models.Repository) (*Site, error) {
+	cfg, err := models.GetPagesConfig(repo.ID)
+	if err != nil || cfg == nil {
+		return nil, err
+	}
+	return &Site{Repo: repo, Config: cfg}, nil
+}
+
+// IsPublic returns true if the site can be served to anonymous users on its own domain
+func (site *Site) IsPublic() bool {
+	return !site.Repo.IsPrivate && site.Repo.Owner != nil && site.Repo.Owner.Visibility == api.VisibleTypePublic
+}
+
+// Version returns the version of the site, the ID of the commit of its branch or the UUID of its artifact,
+// it is empty if the site is not published. The version is cached until the next push or release.
+func (site *Site) Version() (string, error) {
+	return cache.GetString(models.GetPagesCacheKey(site.Repo.ID), func() (string, error) {
+		if site.Config.IsArtifact() {
+			rel, err := models.GetLatestReleaseByRepoID(site.Repo.ID)
+			if err != nil {
+				if models.IsErrReleaseNotExist(err) {
+					return "", nil
+				}
+				return "", err
+			}
+			attach, err := models.GetAttachmentByReleaseIDFileName(rel.ID, site.Config.ArtifactName)
+			if err != nil || attach == nil {
+				return "", err
+			}
+			return artifactVersionPrefix + attach.UUID, nil
+		}
+
+		if site.Repo.IsEmpty {
+			return "", nil
+		}
+		gitRepo, err := git.OpenRepository(site.Repo.RepoPath())
+		if err != nil {
+			return "", err
+		}
+		defer gitRepo.Close()
+		commitID, err := gitRepo.GetBranchCommitID(site.Config.Branch)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				return "", nil
+			}
+			return "", err
+		}
+		return commitID, nil
+	})
+}
+
+// Open returns the page of the site at the path relative to the root of the site.
+// The index page is returned for a directory, the 404 page of the site for a missing page if it has one.
+func (site *Site) Open(pagePath string) (*Page, error) {
+	version, err := site.Version()
+	if err != nil {
+		return nil, err
+	} else if version == "" {
+		return nil, ErrPageNotExist{pagePath}
+	}
+
+	if strings.HasPrefix(version, artifactVersionPrefix) {
+		archive, err := zip.OpenReader(models.AttachmentLocalPath(strings.TrimPrefix(version, artifactVersionPrefix)))
+		if err != nil {
+			return nil, fmt.Errorf("OpenReader: %v", err)
+		}
+		page, err := site.open(pagePath, func(name string) (*Page, bool, error) {
+			return openArtifactFile(archive, version, name)
+		})
+		if err != nil || page == nil {
+			archive.Close()
+			return nil, err
+		}
+		page.ReadCloser = &pageCloser{page.ReadCloser, func() { archive.Close() }}
+		return page, nil
+	}
+
+	gitRepo, err := git.OpenRepository(site.Repo.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+	commit, err := gitRepo.GetCommit(version)
+	if err != nil {
+		gitRepo.Close()
+		return nil, fmt.Errorf("GetCommit: %v", err)
+	}
+	page, err := site.open(pagePath, func(name string) (*Page, bool, error) {
+		return openBranchFile(commit, name)
+	})
+	if err != nil || page == nil {
+		gitRepo.Close()
+		return nil, err
+	}
+	page.ReadCloser = &pageCloser{page.ReadCloser, gitRepo.Close}
+	return page, nil
+}
+
+// open looks the page up with the open function which returns the file at a path of the source,
+// or whether it is a directory
+func (site *Site) open(pagePath string, open func(string) (*Page, bool, error)) (*Page, error) {
+	// Cleaning the path with a leading slash keeps it within the root of the site
+	name := strings.TrimPrefix(path.Clean("/"+pagePath), "/")
+	root := strings.Trim(site.Config.Dir, "/")
+
+	page, isDir, err := open(path.Join(root, name))
+	if err != nil {
+		return nil, err
+	}
+	if isDir {
+		if !strings.HasSuffix(pagePath, "/") {
+			return nil, ErrPageIsDir{pagePath}
+		}
+		if page, _, err = open(path.Join(root, name, indexPage)); err != nil {
+			return nil, err
+		}
+	}
+	if page != nil {
+		return page, nil
+	}
+
+	if page, _, err = open(path.Join(root, notFoundPage)); err != nil {
+		return nil, err
+	} else if page == nil {
+		return nil, ErrPageNotExist{pagePath}
+	}
+	page.IsNotFound = true
+	return page, nil
+}
+
+// openBranchFile returns the blob at the path of the commit
+func openBranchFile(commit *git.Commit, name string) (*Page, bool, error) {
+	entry, err := commit.GetTreeEntryByPath(name)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if entry.IsDir() {
+		return nil, true, nil
+	} else if !entry.IsRegular() && !entry.IsExecutable() {
+		return nil, false, nil
+	}
+
+	blob := entry.Blob()
+	reader, err := blob.DataAsync()
+	if err != nil {
+		return nil, false, err
+	}
+	return &Page{
+		ReadCloser: reader,
+		Name:       entry.Name(),
+		Size:       blob.Size(),
+		ETag:       entry.ID.String(),
+	}, false, nil
+}
+
+// openArtifactFile returns the file at the path of the zip archive
+func openArtifactFile(archive *zip.ReadCloser, version, name string) (*Page, bool, error) {
+	if name == "" {
+		return nil, true, nil
+	}
+	for _, file := range archive.File {
+		if strings.HasPrefix(file.Name, name+"/") {
+			return nil, true, nil
+		}
+		if file.Name != name || file.FileInfo().IsDir() {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, false, err
+		}
+		return &Page{
+			ReadCloser: reader,
+			Name:       path.Base(file.Name),
+			Size:       int64(file.UncompressedSize64),
+			ETag:       fmt.Sprintf("%s-%08x", strings.TrimPrefix(version, artifactVersionPrefix), file.CRC32),
+		}, false, nil
+	}
+	return nil, false, nil
+}
+
+// pageCloser also closes the source of the page with the page
+type pageCloser struct {
+	io.ReadCloser
+	closeSource func()
+}
+
+func (p *pageCloser) Close() error {
+	err := p.ReadCloser.Close()
+	p.closeSource()
+	return err
+}
+
+// siteURL returns the URL of a site served on the host
+func siteURL(host, sitePath string) string {
+	scheme := "https"
+	if u, err := url.Parse(setting.AppURL); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+	return scheme + "://" + host + sitePath
+}
+
+// URL returns the URL of the site on the pages domain, it is empty if there is no pages domain
+func (site *Site) URL() string {
+	if setting.Pages.Domain == "" {
+		return ""
+	}
+	host := strings.ToLower(site.Repo.OwnerName) + "." + setting.Pages.Domain
+	if site.Repo.LowerName == host {
+		return siteURL(host, "/")
+	}
+	return siteURL(host, "/"+site.Repo.LowerName+"/")
+}
+
+// CustomDomainURL returns the URL of the site on its custom domain, it is empty if the domain is not verified
+func (site *Site) CustomDomainURL() string {
+	if !setting.Pages.EnableCustomDomains || site.Config.CustomDomain == "" || !site.Config.IsDomainVerified {
+		return ""
+	}
+	return siteURL(site.Config.CustomDomain, "/")
+}
+
+// PathURL returns the URL of the site on the domain of Gitea
+func (site *Site) PathURL() string {
+	return site.Repo.HTMLURL() + "/pages/"
+}
+
+// stripPort returns the host without the port
+func stripPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// IsPagesHost returns true if the host may serve static sites, i.e. it is a verified custom domain
+// or the subdomain of the pages domain of an existing user
+func IsPagesHost(host string) (bool, error) {
+	host = strings.ToLower(stripPort(host))
+	if setting.Pages.EnableCustomDomains {
+		cfg, err := models.GetPagesConfigByDomain(host)
+		if err != nil || cfg != nil {
+			return cfg != nil, err
+		}
+	}
+	owner := ownerOfHost(host)
+	if owner == "" {
+		return false, nil
+	}
+	if _, err := models.GetUserByName(owner); err != nil {
+		if models.IsErrUserNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// IsPagesDomain returns true if the host belongs to the pages domain
+func IsPagesDomain(host string) bool {
+	host = strings.ToLower(stripPort(host))
+	return setting.Pages.Domain != "" && (host == setting.Pages.Domain || strings.HasSuffix(host, "."+setting.Pages.Domain))
+}
+
+// ownerOfHost returns the name of the owner of the subdomain of the pages domain
+func ownerOfHost(host string) string {
+	if setting.Pages.Domain == "" || !strings.HasSuffix(host, "."+setting.Pages.Domain) {
+		return ""
+	}
+	owner := strings.TrimSuffix(host, "."+setting.Pages.Domain)
+	if strings.Contains(owner, ".") {
+		return ""
+	}
+	return owner
+}
+
+// ResolveHost returns the public static site served for the request on a verified custom domain or on
+// the pages domain and the path of the page relative to the root of the site.
+// User and organization sites are served from their repository named after the host, project sites
+// from {owner}.{pages domain}/{repo}/. It returns a nil site if the host serves no site.
+func ResolveHost(host, reqPath string) (*Site, string, error) {
+	host = strings.ToLower(stripPort(host))
+
+	var site *Site
+	sitePath := reqPath
+	if setting.Pages.EnableCustomDomains {
+		cfg, err := models.GetPagesConfigByDomain(host)
+		if err != nil {
+			return nil, "", err
+		}
+		if cfg != nil {
+			repo, err := models.GetRepositoryByID(cfg.RepoID)
+			if err != nil {
+				return nil, "", err
+			}
+			site = &Site{Repo: repo, Config: cfg}
+		}
+	}
+
+	if owner := ownerOfHost(host); site == nil && owner != "" {
+		// project site
+		if parts := strings.SplitN(strings.TrimPrefix(reqPath, "/"), "/", 2); parts[0] != "" {
+			repo, err := models.GetRepositoryByOwnerAndName(owner, parts[0])
+			if err != nil && !models.IsErrRepoNotExist(err) {
+				return nil, "", err
+			}
+			if repo != nil {
+				if site, err = GetSite(repo); err != nil {
+					return nil, "", err
+				}
+				sitePath = strings.TrimPrefix(reqPath, "/"+parts[0])
+			}
+		}
+		// user or organization site
+		if site == nil {
+			repo, err := models.GetRepositoryByOwnerAndName(owner, host)
+			if err != nil && !models.IsErrRepoNotExist(err) {
+				return nil, "", err
+			}
+			if repo != nil {
+				if site, err = GetSite(repo); err != nil {
+					return nil, "", err
+				}
+				sitePath = reqPath
+			}
+		}
+	}
+
+	if site == nil {
+		return nil, "", nil
+	}
+	if err := site.Repo.GetOwner(); err != nil {
+		return nil, "", err
+	}
+	if !site.IsPublic() {
+		return nil, "", nil
+	}
+	return site, sitePath, nil
+}
+
+// VerifyCustomDomain checks that the TXT record of the custom domain of the site proves its ownership
+// and marks the domain as verified if it does.
+func VerifyCustomDomain(site *Site) (bool, error) {
+	if site.Config.CustomDomain == "" {
+		return false, nil
+	}
+	records, err := lookupTXT(domainVerifyRecordPrefix + site.Config.CustomDomain)
+	if err != nil {
+		if dnsErr, ok := err.(*net.DNSError); ok && (dnsErr.IsNotFound || dnsErr.IsTemporary) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == site.Config.DomainVerifyRecord() {
+			return true, models.UpdatePagesDomainVerified(site.Config, true)
+		}
+	}
+	return false, nil
+}
+
+// DomainVerifyRecordName returns the name of the TXT record proving the ownership of the custom domain
+func DomainVerifyRecordName(domain string) string {
+	return domainVerifyRecordPrefix + domain
+}
+
+// InvalidateCache forgets the cached version of the static site of the repository
+// so that the next request serves its latest content
+func InvalidateCache(repoID int64) {
+	cache.Remove(models.GetPagesCacheKey(repoID))
+}
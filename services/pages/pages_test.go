@@ -0,0 +1,119 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pages
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSiteOpen(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	site, err := GetSite(repo)
+	assert.NoError(t, err)
+	assert.NotNil(t, site)
+	InvalidateCache(repo.ID)
+
+	page, err := site.Open("/README.md")
+	assert.NoError(t, err)
+	content, err := ioutil.ReadAll(page)
+	assert.NoError(t, err)
+	assert.NoError(t, page.Close())
+	assert.Contains(t, string(content), "repo1")
+	assert.EqualValues(t, "README.md", page.Name)
+	assert.EqualValues(t, "4b4851ad51df6a7d9f25c979345979eaeb5b349f", page.ETag)
+	assert.False(t, page.IsNotFound)
+
+	// the path cannot leave the root of the site
+	page, err = site.Open("/../../README.md")
+	assert.NoError(t, err)
+	assert.NoError(t, page.Close())
+
+	_, err = site.Open("/missing.html")
+	assert.True(t, IsErrPageNotExist(err))
+
+	// a site without published content has no page
+	site.Config.Branch = "gh-pages"
+	InvalidateCache(repo.ID)
+	_, err = site.Open("/README.md")
+	assert.True(t, IsErrPageNotExist(err))
+	InvalidateCache(repo.ID)
+}
+
+func TestResolveHost(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	oldDomain := setting.Pages.Domain
+	setting.Pages.Domain = "pages.example.com"
+	defer func() {
+		setting.Pages.Domain = oldDomain
+	}()
+
+	assert.True(t, IsPagesDomain("user2.pages.example.com:3000"))
+	assert.False(t, IsPagesDomain("example.com"))
+
+	site, pagePath, err := ResolveHost("user2.pages.example.com", "/repo1/docs/index.html")
+	assert.NoError(t, err)
+	assert.NotNil(t, site)
+	assert.EqualValues(t, 1, site.Repo.ID)
+	assert.EqualValues(t, "/docs/index.html", pagePath)
+	assert.EqualValues(t, "https://user2.pages.example.com/repo1/", site.URL())
+
+	// private repositories have no public site
+	assert.NoError(t, models.SavePagesConfig(&models.PagesConfig{RepoID: 2, Branch: "master"}))
+	site, _, err = ResolveHost("user2.pages.example.com", "/repo2/")
+	assert.NoError(t, err)
+	assert.Nil(t, site)
+
+	site, _, err = ResolveHost("user2.pages.example.com", "/")
+	assert.NoError(t, err)
+	assert.Nil(t, site)
+
+	ok, err := IsPagesHost("user2.pages.example.com")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = IsPagesHost("nobody.pages.example.com")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestVerifyCustomDomain(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	oldLookupTXT := lookupTXT
+	defer func() {
+		lookupTXT = oldLookupTXT
+	}()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.NoError(t, models.SavePagesConfig(&models.PagesConfig{RepoID: 1, Branch: "master", CustomDomain: "docs.example.com"}))
+	site, err := GetSite(repo)
+	assert.NoError(t, err)
+
+	var lookedUp string
+	lookupTXT = func(name string) ([]string, error) {
+		lookedUp = name
+		return []string{"v=spf1 -all"}, nil
+	}
+	verified, err := VerifyCustomDomain(site)
+	assert.NoError(t, err)
+	assert.False(t, verified)
+	assert.EqualValues(t, "_gitea-pages.docs.example.com", lookedUp)
+
+	lookupTXT = func(name string) ([]string, error) {
+		return []string{site.Config.DomainVerifyRecord()}, nil
+	}
+	verified, err = VerifyCustomDomain(site)
+	assert.NoError(t, err)
+	assert.True(t, verified)
+	models.AssertExistsAndLoadBean(t, &models.PagesConfig{RepoID: 1, IsDomainVerified: true})
+}
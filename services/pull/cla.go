@@ -0,0 +1,149 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// CLAStatusContext is the context of the commit status reporting whether the authors of the
+// commits of a pull request signed the contributor license agreement of the base repository.
+const CLAStatusContext = "gitea/cla"
+
+// getUnsignedAuthors returns the head commit ID of the pull request and the authors of its
+// commits who have not signed the agreement, authors without an account are reported by their
+// email. Merge commits are skipped.
+func getUnsignedAuthors(gitRepo *git.Repository, pr *models.PullRequest, agreement *models.ContributorAgreement) (string, []string, error) {
+	headCommitID, commits, err := getPullCommits(gitRepo, pr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	authors := make([]string, 0, 2)
+	userIDs := make([]int64, 0, 2)
+	userNames := make(map[int64]string, 2)
+	seen := make(map[string]bool, 2)
+	for e := commits.Front(); e != nil; e = e.Next() {
+		commit := e.Value.(*git.Commit)
+		email := strings.ToLower(commit.Author.Email)
+		if commit.ParentCount() > 1 || seen[email] {
+			continue
+		}
+		seen[email] = true
+
+		u, err := models.GetUserByEmail(email)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				authors = append(authors, email)
+				continue
+			}
+			return "", nil, err
+		}
+		if _, ok := userNames[u.ID]; !ok {
+			userIDs = append(userIDs, u.ID)
+			userNames[u.ID] = u.Name
+		}
+	}
+
+	unsignedIDs, err := agreement.GetUnsignedUserIDs(userIDs)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, id := range unsignedIDs {
+		authors = append(authors, userNames[id])
+	}
+	return headCommitID, authors, nil
+}
+
+// checkContributorAgreement returns an ErrContributorAgreementNotSigned if an author of the
+// commits of the pull request has not signed the agreement of the base repository.
+func checkContributorAgreement(pr *models.PullRequest, gitRepo *git.Repository) error {
+	agreement, err := models.GetRepoContributorAgreement(pr.BaseRepo)
+	if err != nil || agreement == nil {
+		return err
+	}
+	_, authors, err := getUnsignedAuthors(gitRepo, pr, agreement)
+	if err != nil {
+		return err
+	}
+	if len(authors) > 0 {
+		return models.ErrContributorAgreementNotSigned{Authors: authors}
+	}
+	return nil
+}
+
+// CheckCLA verifies that the authors of the commits of the pull request signed the contributor
+// license agreement of the base repository, if it has one, and reports the result as a commit
+// status of the head commit.
+func CheckCLA(pr *models.PullRequest, doer *models.User) error {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return err
+	}
+	if err := pr.LoadIssue(); err != nil {
+		return err
+	}
+	if pr.HasMerged || pr.Issue.IsClosed {
+		return nil
+	}
+	agreement, err := models.GetRepoContributorAgreement(pr.BaseRepo)
+	if err != nil || agreement == nil {
+		return err
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	headCommitID, authors, err := getUnsignedAuthors(gitRepo, pr, agreement)
+	if err != nil {
+		return err
+	}
+
+	status := &models.CommitStatus{
+		State:       api.CommitStatusSuccess,
+		TargetURL:   pr.BaseRepo.HTMLURL() + "/cla",
+		Description: "All commit authors signed the CLA",
+		Context:     CLAStatusContext,
+	}
+	if len(authors) > 0 {
+		status.State = api.CommitStatusFailure
+		status.Description = fmt.Sprintf("CLA not signed by %s", strings.Join(authors, ", "))
+	}
+	return models.NewCommitStatus(models.NewCommitStatusOptions{
+		Repo:         pr.BaseRepo,
+		Creator:      doer,
+		SHA:          headCommitID,
+		CommitStatus: status,
+	})
+}
+
+// checkCLAStatus runs the CLA check without failing the calling action, which has already been
+// done.
+func checkCLAStatus(pr *models.PullRequest, doer *models.User) {
+	if err := CheckCLA(pr, doer); err != nil {
+		log.Error("CheckCLA[%d]: %v", pr.ID, err)
+	}
+}
+
+// RecheckContributorAgreement updates the CLA status of the open pull requests the agreement
+// applies to, after it has been signed or changed.
+func RecheckContributorAgreement(agreement *models.ContributorAgreement, doer *models.User) {
+	prs, err := agreement.GetUnmergedPullRequests()
+	if err != nil {
+		log.Error("GetUnmergedPullRequests[%d]: %v", agreement.ID, err)
+		return
+	}
+	for _, pr := range prs {
+		checkCLAStatus(pr, doer)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCLA(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 2}).(*models.PullRequest)
+	assert.NoError(t, pr.LoadBaseRepo())
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	// nothing is checked without an agreement
+	assert.NoError(t, checkContributorAgreement(pr, gitRepo))
+	assert.NoError(t, CheckCLA(pr, doer))
+	models.AssertNotExistsBean(t, &models.CommitStatus{RepoID: pr.BaseRepoID, Context: CLAStatusContext})
+
+	agreement := &models.ContributorAgreement{OwnerID: pr.BaseRepo.OwnerID, Title: "CLA", Content: "terms"}
+	assert.NoError(t, models.SaveContributorAgreement(agreement))
+
+	// authors without an account are reported by their email
+	_, authors, err := getUnsignedAuthors(gitRepo, pr, agreement)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"address1@example.com", "art27@cantab.net"}, authors)
+
+	assert.NoError(t, models.AddEmailAddress(&models.EmailAddress{UID: 2, Email: "address1@example.com", IsActivated: true}))
+	assert.NoError(t, models.AddEmailAddress(&models.EmailAddress{UID: 4, Email: "art27@cantab.net", IsActivated: true}))
+	_, authors, err = getUnsignedAuthors(gitRepo, pr, agreement)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user2", "user4"}, authors)
+
+	assert.NoError(t, agreement.Sign(2))
+	err = checkContributorAgreement(pr, gitRepo)
+	assert.True(t, models.IsErrContributorAgreementNotSigned(err))
+	assert.EqualValues(t, []string{"user4"}, err.(models.ErrContributorAgreementNotSigned).Authors)
+
+	assert.NoError(t, CheckCLA(pr, doer))
+	status := models.AssertExistsAndLoadBean(t, &models.CommitStatus{RepoID: pr.BaseRepoID, Context: CLAStatusContext}).(*models.CommitStatus)
+	assert.EqualValues(t, "5f22f7d0d95d614d25a5b68592adb345a4b5c7fd", status.SHA)
+	assert.EqualValues(t, "failure", status.State)
+
+	assert.NoError(t, agreement.Sign(4))
+	assert.NoError(t, checkContributorAgreement(pr, gitRepo))
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/codenotify"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// readCodenotify reads the CODENOTIFY file of the base branch of the pull
+// request, it returns nil if there is none.
+func readCodenotify(gitRepo *git.Repository, pr *models.PullRequest) (*codenotify.File, error) {
+	data, err := readBaseBranchFile(gitRepo, pr, codenotify.FilePaths, codenotify.MaxFileSize)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return codenotify.Parse(data), nil
+}
+
+// getPathSubscriberIDs returns the IDs of the users listed in the CODENOTIFY
+// file for the files and of the users watching their paths.
+func getPathSubscriberIDs(gitRepo *git.Repository, pr *models.PullRequest, files []string) ([]int64, error) {
+	ids, err := models.GetPathWatcherIDs(pr.BaseRepoID, files)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := readCodenotify(gitRepo, pr)
+	if err != nil {
+		return nil, fmt.Errorf("readCodenotify: %v", err)
+	} else if file == nil {
+		return ids, nil
+	}
+	for _, name := range file.Users(files) {
+		user, err := models.GetUserByName(name)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				log.Trace("CODENOTIFY of %-v: user %q does not exist", pr.BaseRepo, name)
+				continue
+			}
+			return nil, err
+		}
+		if !user.IsOrganization() {
+			ids = append(ids, user.ID)
+		}
+	}
+	return ids, nil
+}
+
+// NotifyPathSubscribers subscribes the users listed in the CODENOTIFY file of
+// the base branch for the files the pull request changes, and the users
+// watching their paths, to the pull request and notifies them. Users who have
+// subscribed to or unsubscribed from the pull request before are left alone,
+// so they are notified once.
+func NotifyPathSubscribers(pr *models.PullRequest, doer *models.User) error {
+	if err := pr.LoadIssue(); err != nil {
+		return err
+	}
+	if err := pr.LoadBaseRepo(); err != nil {
+		return err
+	}
+	if pr.HasMerged || pr.Issue.IsClosed {
+		return nil
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	files, err := getChangedFiles(gitRepo, pr)
+	if err != nil {
+		return err
+	}
+	ids, err := getPathSubscriberIDs(gitRepo, pr, files)
+	if err != nil {
+		return err
+	}
+
+	seen := map[int64]bool{doer.ID: true, pr.Issue.PosterID: true}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if _, exists, err := models.GetIssueWatch(id, pr.IssueID); err != nil {
+			return err
+		} else if exists {
+			continue
+		}
+		user, err := models.GetUserByID(id)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				continue
+			}
+			return err
+		}
+		perm, err := models.GetUserRepoPermission(pr.BaseRepo, user)
+		if err != nil {
+			return err
+		}
+		if !perm.CanRead(models.UnitTypePullRequests) {
+			continue
+		}
+
+		if err := models.CreateOrUpdateIssueWatch(id, pr.IssueID, true); err != nil {
+			return err
+		}
+		if err := models.CreateOrUpdateIssueNotifications(pr.IssueID, 0, doer.ID, id, models.NotificationReasonWatch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyPathSubscribers notifies the path subscribers without failing the
+// calling action, which has already been done.
+func notifyPathSubscribers(pr *models.PullRequest, doer *models.User) {
+	if err := NotifyPathSubscribers(pr, doer); err != nil {
+		log.Error("NotifyPathSubscribers[%d]: %v", pr.ID, err)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/gobwas/glob"
+)
+
+// DCOStatusContext is the context of the commit status reporting the DCO check of pull requests,
+// branch protection can require it to block merging pull requests with commits not signed off.
+const DCOStatusContext = "gitea/dco"
+
+// compileDCOAllowlist compiles the newline separated author names and emails exempt from the
+// DCO check, "*" matches any characters.
+func compileDCOAllowlist(allowlist string) []glob.Glob {
+	globs := make([]glob.Glob, 0, 2)
+	for _, entry := range strings.Split(allowlist, "\n") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, "*")
+		for i := range parts {
+			parts[i] = glob.QuoteMeta(parts[i])
+		}
+		g, err := glob.Compile(strings.Join(parts, "*"))
+		if err != nil {
+			log.Info("Invalid DCO allowlist entry '%s' (skipped): %v", entry, err)
+			continue
+		}
+		globs = append(globs, g)
+	}
+	return globs
+}
+
+// isDCOExempt returns true if the author of the commit matches an allowlist entry
+func isDCOExempt(author *git.Signature, allowlist []glob.Glob) bool {
+	for _, g := range allowlist {
+		if g.Match(strings.ToLower(author.Email)) || g.Match(strings.ToLower(author.Name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDCOSignOff returns true if the commit message has a Signed-off-by trailer with the email
+// of the author.
+func hasDCOSignOff(message string, author *git.Signature) bool {
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		i := strings.IndexByte(line, ':')
+		if i < 0 || !strings.EqualFold(line[:i], "Signed-off-by") {
+			continue
+		}
+		addr, err := mail.ParseAddress(strings.TrimSpace(line[i+1:]))
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(addr.Address, author.Email) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDCO returns the first commit of the pull request which is not signed off by its author,
+// or nil if all are. Merge commits and commits of allowed authors are skipped.
+func checkDCO(gitRepo *git.Repository, pr *models.PullRequest, allowlist string) (*git.Commit, error) {
+	_, commits, err := getPullCommits(gitRepo, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	globs := compileDCOAllowlist(allowlist)
+	var unsigned *git.Commit
+	// the list starts with the newest commit, report the oldest one
+	for e := commits.Front(); e != nil; e = e.Next() {
+		commit := e.Value.(*git.Commit)
+		if commit.ParentCount() > 1 || isDCOExempt(commit.Author, globs) {
+			continue
+		}
+		if !hasDCOSignOff(commit.CommitMessage, commit.Author) {
+			unsigned = commit
+		}
+	}
+	return unsigned, nil
+}
+
+// CheckDCO verifies that every commit of the pull request is signed off by its author if the
+// DCO check is enabled for the base repository, and reports the result as a commit status of
+// the head commit.
+func CheckDCO(pr *models.PullRequest, doer *models.User) error {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return err
+	}
+	if err := pr.LoadIssue(); err != nil {
+		return err
+	}
+	if pr.HasMerged || pr.Issue.IsClosed {
+		return nil
+	}
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		if models.IsErrUnitTypeNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	prConfig := prUnit.PullRequestsConfig()
+	if !prConfig.EnableDCOCheck {
+		return nil
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return err
+	}
+	unsigned, err := checkDCO(gitRepo, pr, prConfig.DCOAllowlist)
+	if err != nil {
+		return err
+	}
+
+	status := &models.CommitStatus{
+		State:       api.CommitStatusSuccess,
+		TargetURL:   fmt.Sprintf("%s/pulls/%d/commits", pr.BaseRepo.HTMLURL(), pr.Index),
+		Description: "All commits are signed off",
+		Context:     DCOStatusContext,
+	}
+	if unsigned != nil {
+		status.State = api.CommitStatusFailure
+		status.Description = fmt.Sprintf("Commit %s is not signed off by its author", unsigned.ID.String()[:10])
+	}
+	return models.NewCommitStatus(models.NewCommitStatusOptions{
+		Repo:         pr.BaseRepo,
+		Creator:      doer,
+		SHA:          headCommitID,
+		CommitStatus: status,
+	})
+}
+
+// checkDCOStatus runs the DCO check without failing the calling action, which has already been
+// done.
+func checkDCOStatus(pr *models.PullRequest, doer *models.User) {
+	if err := CheckDCO(pr, doer); err != nil {
+		log.Error("CheckDCO[%d]: %v", pr.ID, err)
+	}
+}
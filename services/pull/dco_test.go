@@ -0,0 +1,77 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasDCOSignOff(t *testing.T) {
+	author := &git.Signature{Name: "Alice", Email: "alice@example.com"}
+
+	assert.True(t, hasDCOSignOff("fix\n\nSigned-off-by: Alice <alice@example.com>\n", author))
+	assert.True(t, hasDCOSignOff("fix\n\nsigned-off-by: A. <ALICE@example.com>", author))
+	assert.False(t, hasDCOSignOff("fix", author))
+	assert.False(t, hasDCOSignOff("fix\n\nSigned-off-by: Bob <bob@example.com>", author))
+	assert.False(t, hasDCOSignOff("fix\n\nSigned-off-by: alice@example.com is missing", author))
+}
+
+func TestIsDCOExempt(t *testing.T) {
+	globs := compileDCOAllowlist("*[bot]@users.noreply.example.com\n\nRenovate Bot\n")
+	assert.Len(t, globs, 2)
+
+	assert.True(t, isDCOExempt(&git.Signature{Name: "x", Email: "dependabot[bot]@users.noreply.example.com"}, globs))
+	assert.True(t, isDCOExempt(&git.Signature{Name: "renovate bot", Email: "bot@example.com"}, globs))
+	assert.False(t, isDCOExempt(&git.Signature{Name: "Alice", Email: "alice@example.com"}, globs))
+	assert.False(t, isDCOExempt(&git.Signature{Name: "b", Email: "b@users.noreply.example.com"}, globs))
+}
+
+func TestCheckDCO(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 2}).(*models.PullRequest)
+	assert.NoError(t, pr.LoadBaseRepo())
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	// the oldest commit not signed off is reported
+	unsigned, err := checkDCO(gitRepo, pr, "")
+	assert.NoError(t, err)
+	if assert.NotNil(t, unsigned) {
+		assert.EqualValues(t, "4a357436d925b5c974181ff12a994538ddc5a269", unsigned.ID.String())
+	}
+
+	unsigned, err = checkDCO(gitRepo, pr, "address1@example.com")
+	assert.NoError(t, err)
+	if assert.NotNil(t, unsigned) {
+		assert.EqualValues(t, "5f22f7d0d95d614d25a5b68592adb345a4b5c7fd", unsigned.ID.String())
+	}
+
+	unsigned, err = checkDCO(gitRepo, pr, "address1@example.com\nart27@cantab.net")
+	assert.NoError(t, err)
+	assert.Nil(t, unsigned)
+
+	// no status is reported while the check is disabled
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	assert.NoError(t, CheckDCO(pr, doer))
+	models.AssertNotExistsBean(t, &models.CommitStatus{RepoID: pr.BaseRepoID, Context: DCOStatusContext})
+
+	assert.NoError(t, models.UpdateRepositoryUnits(pr.BaseRepo, []models.RepoUnit{{
+		RepoID: pr.BaseRepoID,
+		Type:   models.UnitTypePullRequests,
+		Config: &models.PullRequestsConfig{AllowMerge: true, EnableDCOCheck: true},
+	}}, nil))
+	pr.BaseRepo = nil
+	assert.NoError(t, CheckDCO(pr, doer))
+	status := models.AssertExistsAndLoadBean(t, &models.CommitStatus{RepoID: pr.BaseRepoID, Context: DCOStatusContext}).(*models.CommitStatus)
+	assert.EqualValues(t, "5f22f7d0d95d614d25a5b68592adb345a4b5c7fd", status.SHA)
+	assert.EqualValues(t, "failure", status.State)
+}
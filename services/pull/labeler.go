@@ -0,0 +1,171 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/labeler"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/sync"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+// labelerWorkingPool prevents concurrent pushes from labeling the same pull
+// request at the same time
+var labelerWorkingPool = sync.NewExclusivePool()
+
+// readLabelerConfig reads the labeler configuration of the base branch of
+// the pull request, it returns nil if there is none.
+func readLabelerConfig(gitRepo *git.Repository, pr *models.PullRequest) (*labeler.Config, error) {
+	data, err := readBaseBranchFile(gitRepo, pr, labeler.ConfigPaths, labeler.MaxConfigSize)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return labeler.Parse(data)
+}
+
+// getLabelerLabels returns the labels of the repository and of the
+// organization owning it by their lower case name, the labels of the
+// repository are preferred.
+func getLabelerLabels(repo *models.Repository) (map[string]*models.Label, error) {
+	labels, err := models.GetLabelsByRepoID(repo.ID, "", models.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.GetOwner(); err != nil {
+		return nil, err
+	}
+	if repo.Owner.IsOrganization() {
+		orgLabels, err := models.GetLabelsByOrgID(repo.OwnerID, "", models.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		labels = append(orgLabels, labels...)
+	}
+
+	byName := make(map[string]*models.Label, len(labels))
+	for _, label := range labels {
+		byName[strings.ToLower(label.Name)] = label
+	}
+	return byName, nil
+}
+
+// ApplyLabeler labels the pull request according to the files it changes and
+// the labeler configuration of its base branch. The labels it added before
+// and which do not match anymore are removed, the labels added manually are
+// never removed.
+func ApplyLabeler(pr *models.PullRequest, doer *models.User) error {
+	labelerWorkingPool.CheckIn(fmt.Sprint(pr.ID))
+	defer labelerWorkingPool.CheckOut(fmt.Sprint(pr.ID))
+
+	if err := pr.LoadIssue(); err != nil {
+		return err
+	}
+	if err := pr.LoadBaseRepo(); err != nil {
+		return err
+	}
+	if pr.HasMerged {
+		return nil
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	cfg, err := readLabelerConfig(gitRepo, pr)
+	if err != nil {
+		return fmt.Errorf("readLabelerConfig: %v", err)
+	} else if cfg == nil {
+		return nil
+	}
+
+	files, err := getChangedFiles(gitRepo, pr)
+	if err != nil {
+		return err
+	}
+
+	labelsByName, err := getLabelerLabels(pr.BaseRepo)
+	if err != nil {
+		return err
+	}
+	matched := make(map[int64]bool)
+	matchedLabels := make([]*models.Label, 0, len(cfg.Rules))
+	for _, name := range cfg.Labels(files) {
+		if label, ok := labelsByName[strings.ToLower(name)]; ok {
+			if !matched[label.ID] {
+				matched[label.ID] = true
+				matchedLabels = append(matchedLabels, label)
+			}
+		} else {
+			log.Trace("Labeler of %-v: label %q does not exist", pr.BaseRepo, name)
+		}
+	}
+
+	current, err := models.GetLabelsByIssueID(pr.IssueID)
+	if err != nil {
+		return err
+	}
+	hasLabel := make(map[int64]bool, len(current))
+	for _, label := range current {
+		hasLabel[label.ID] = true
+	}
+	labelerIDs, err := models.GetLabelerLabelIDs(pr.IssueID)
+	if err != nil {
+		return err
+	}
+
+	toAdd := make([]*models.Label, 0, len(matchedLabels))
+	toAddIDs := make([]int64, 0, len(matchedLabels))
+	for _, label := range matchedLabels {
+		if !hasLabel[label.ID] {
+			toAdd = append(toAdd, label)
+			toAddIDs = append(toAddIDs, label.ID)
+		}
+	}
+	if len(toAdd) > 0 {
+		if err := issue_service.AddLabels(pr.Issue, doer, toAdd); err != nil {
+			return err
+		}
+		if err := models.MarkIssueLabelsAddedByLabeler(pr.IssueID, toAddIDs); err != nil {
+			return err
+		}
+	}
+
+	removed := make([]*models.Label, 0, len(labelerIDs))
+	for _, label := range current {
+		if matched[label.ID] {
+			continue
+		}
+		for _, id := range labelerIDs {
+			if id != label.ID {
+				continue
+			}
+			if err := models.DeleteIssueLabel(pr.Issue, label, doer); err != nil {
+				return err
+			}
+			removed = append(removed, label)
+			break
+		}
+	}
+	if len(removed) > 0 {
+		notification.NotifyIssueChangeLabels(doer, pr.Issue, nil, removed)
+	}
+	return nil
+}
+
+// applyLabeler labels the pull request without failing the calling action,
+// which has already been done.
+func applyLabeler(pr *models.PullRequest, doer *models.User) {
+	if err := ApplyLabeler(pr, doer); err != nil {
+		log.Error("ApplyLabeler[%d]: %v", pr.ID, err)
+	}
+}
@@ -31,8 +31,10 @@ import (
 
 // Merge merges pull request to base repository.
 // Caller should check PR is ready to be merged (review and status checks)
+// The rebase styles fold the fixup! and squash! commits into the commits they
+// amend when autosquash is true, the other styles ignore it.
 // FIXME: add repoWorkingPull make sure two merges does not happen at same time.
-func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repository, mergeStyle models.MergeStyle, message string) (err error) {
+func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repository, mergeStyle models.MergeStyle, message string, autosquash bool) (err error) {
 	if err = pr.LoadHeadRepo(); err != nil {
 		log.Error("LoadHeadRepo: %v", err)
 		return fmt.Errorf("LoadHeadRepo: %v", err)
@@ -53,11 +55,39 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
 	}
 
+	// Check if the branch protection of the base branch allows the merge style
+	protectBranch, err := models.GetEffectiveProtectedBranch(pr.BaseRepo, pr.BaseBranch, doer.ID)
+	if err != nil {
+		log.Error("GetEffectiveProtectedBranch: %v", err)
+		return err
+	}
+	if protectBranch != nil && !protectBranch.IsMergeStyleAllowed(mergeStyle) {
+		return models.ErrMergeStyleNotAllowedForBranch{Branch: pr.BaseBranch, Style: mergeStyle}
+	}
+
+	// The rebase and fast-forward-only styles keep the messages of the commits, they are
+	// checked when pushed
+	if mergeStyle != models.MergeStyleRebase && mergeStyle != models.MergeStyleFastForwardOnly {
+		if err = models.CheckCommitMessage(pr.BaseRepo, doer, message); err != nil {
+			return err
+		}
+	}
+
+	if err = checkContributorAgreement(pr, baseGitRepo); err != nil {
+		return err
+	}
+
+	if mergeStyle == models.MergeStyleMerge || mergeStyle == models.MergeStyleFastForwardOnly {
+		if err = checkPullCommitsSignatures(pr, baseGitRepo); err != nil {
+			return err
+		}
+	}
+
 	defer func() {
 		go AddTestPullRequestTask(doer, pr.BaseRepo.ID, pr.BaseBranch, false, "", "")
 	}()
 
-	pr.MergedCommitID, err = rawMerge(pr, doer, mergeStyle, message)
+	pr.MergedCommitID, err = rawMerge(pr, doer, mergeStyle, message, autosquash)
 	if err != nil {
 		return err
 	}
@@ -82,6 +112,7 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 	}
 
 	notification.NotifyMergePullRequest(pr, doer)
+	issue_service.MoveLinkedProjectIssues(pr, models.ProjectAutomationDone)
 
 	// Reset cached commit count
 	cache.Remove(pr.Issue.Repo.GetCommitsCountCacheKey(pr.BaseBranch, true))
@@ -111,8 +142,36 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 	return nil
 }
 
+// GetAllowedMergeStyles returns the merge styles allowed by the repository which the
+// protection of the base branch of the pull request allows the doer to use
+func GetAllowedMergeStyles(pr *models.PullRequest, prConfig *models.PullRequestsConfig, doer *models.User) ([]models.MergeStyle, error) {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return nil, err
+	}
+	var doerID int64
+	if doer != nil {
+		doerID = doer.ID
+	}
+	protectBranch, err := models.GetEffectiveProtectedBranch(pr.BaseRepo, pr.BaseBranch, doerID)
+	if err != nil {
+		return nil, err
+	}
+
+	styles := prConfig.AllowedMergeStyles()
+	if protectBranch == nil {
+		return styles, nil
+	}
+	allowed := styles[:0]
+	for _, style := range styles {
+		if protectBranch.IsMergeStyleAllowed(style) {
+			allowed = append(allowed, style)
+		}
+	}
+	return allowed, nil
+}
+
 // rawMerge perform the merge operation without changing any pull information in database
-func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.MergeStyle, message string) (string, error) {
+func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.MergeStyle, message string, autosquash bool) (string, error) {
 	binVersion, err := git.BinVersion()
 	if err != nil {
 		log.Error("git.BinVersion: %v", err)
@@ -248,6 +307,12 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 			log.Error("Unable to make final commit: %v", err)
 			return "", err
 		}
+	case models.MergeStyleFastForwardOnly:
+		cmd := git.NewCommand("merge", "--ff-only", trackingBranch)
+		if err := runMergeCommand(pr, mergeStyle, cmd, tmpBasePath); err != nil {
+			log.Error("Unable to fast-forward base to tracking: %v", err)
+			return "", err
+		}
 	case models.MergeStyleRebase:
 		fallthrough
 	case models.MergeStyleRebaseMerge:
@@ -260,7 +325,16 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 		errbuf.Reset()
 
 		// Rebase before merging
-		if err := git.NewCommand("rebase", baseBranch).RunInDirPipeline(tmpBasePath, &outbuf, &errbuf); err != nil {
+		rebaseCmd := git.NewCommand("rebase")
+		var rebaseEnv []string
+		if autosquash {
+			// Fold the fixup! and squash! commits, accepting the todo list and the
+			// combined commit messages as git prepares them
+			rebaseCmd.AddArguments("--interactive", "--autosquash")
+			rebaseEnv = append(os.Environ(), "GIT_SEQUENCE_EDITOR=true", "GIT_EDITOR=true")
+		}
+		rebaseCmd.AddArguments(baseBranch)
+		if err := rebaseCmd.RunInDirTimeoutEnvPipeline(rebaseEnv, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
 			// Rebase will leave a REBASE_HEAD file in .git if there is a conflict
 			if _, statErr := os.Stat(filepath.Join(tmpBasePath, ".git", "REBASE_HEAD")); statErr == nil {
 				var commitSha string
@@ -457,6 +531,13 @@ func runMergeCommand(pr *models.PullRequest, mergeStyle models.MergeStyle, cmd *
 				StdErr: errbuf.String(),
 				Err:    err,
 			}
+		} else if mergeStyle == models.MergeStyleFastForwardOnly && strings.Contains(errbuf.String(), "Not possible to fast-forward") {
+			log.Debug("MergeDivergingFastForwardOnly [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
+			return models.ErrMergeDivergingFastForwardOnly{
+				StdOut: outbuf.String(),
+				StdErr: errbuf.String(),
+				Err:    err,
+			}
 		} else if strings.Contains(errbuf.String(), "refusing to merge unrelated histories") {
 			log.Debug("MergeUnrelatedHistories [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 			return models.ErrMergeUnrelatedHistories{
@@ -528,9 +609,43 @@ func IsSignedIfRequired(pr *models.PullRequest, doer *models.User) (bool, error)
 		return true, nil
 	}
 
-	sign, _, err := pr.SignMerge(doer, pr.BaseRepo.RepoPath(), pr.BaseBranch, pr.GetGitRefName())
+	sign, keyID, err := pr.SignMerge(doer, pr.BaseRepo.RepoPath(), pr.BaseBranch, pr.GetGitRefName())
+	if err != nil || !sign {
+		return sign, err
+	}
+	if err := pr.ProtectedBranch.CheckSigningKey(keyID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// checkPullCommitsSignatures checks that the commits of the pull request are signed by one of
+// the allowed keys of the base branch, as a merge commit keeps them in the history of the branch
+func checkPullCommitsSignatures(pr *models.PullRequest, baseGitRepo *git.Repository) error {
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	if pr.ProtectedBranch == nil || !pr.ProtectedBranch.RequireSignedCommits || len(pr.ProtectedBranch.GetSignedCommitsAllowedKeys()) == 0 {
+		return nil
+	}
 
-	return sign, err
+	headCommitID, err := baseGitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return fmt.Errorf("GetRefCommitID: %v", err)
+	}
+	commits, err := baseGitRepo.CommitsBetweenIDs(headCommitID, pr.MergeBase)
+	if err != nil {
+		return fmt.Errorf("CommitsBetweenIDs: %v", err)
+	}
+	for e := commits.Front(); e != nil; e = e.Next() {
+		commit := e.Value.(*git.Commit)
+		if !pr.ProtectedBranch.IsCommitSignatureAllowed(models.ParseCommitWithSignature(commit)) {
+			return models.ErrNotAllowedToMerge{
+				Reason: fmt.Sprintf("Commit %s is not signed by an allowed key", commit.ID.String()),
+			}
+		}
+	}
+	return nil
 }
 
 // IsUserAllowedToMerge check if user is allowed to merge PR with given permissions and branch protections
@@ -551,6 +666,33 @@ func IsUserAllowedToMerge(pr *models.PullRequest, p models.Permission, user *mod
 	return false, nil
 }
 
+// IsUserAllowedToForceMerge check if user is allowed to merge PR which is not ready to be merged.
+// Repository admins can bypass the branch protection of the repository, but only the users in the
+// bypass lists of the rulesets of the organization can bypass them.
+func IsUserAllowedToForceMerge(pr *models.PullRequest, p models.Permission, user *models.User) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return false, err
+	}
+	if pr.ProtectedBranch == nil {
+		return p.IsAdmin(), nil
+	}
+
+	for _, r := range pr.ProtectedBranch.Rulesets {
+		if !r.CanBypass(user.ID) {
+			return false, nil
+		}
+	}
+	// The branch protection of the repository was overridden by the bypassed rulesets
+	if pr.ProtectedBranch.ID == 0 && len(pr.ProtectedBranch.Rulesets) > 0 {
+		return true, nil
+	}
+	return p.IsAdmin(), nil
+}
+
 // CheckPRReadyToMerge checks whether the PR is ready to be merged (reviews and status checks)
 func CheckPRReadyToMerge(pr *models.PullRequest) (err error) {
 	if err = pr.LoadBaseRepo(); err != nil {
@@ -74,6 +74,11 @@ func TestPatch(pr *models.PullRequest) error {
 		}
 	}
 	pr.MergeBase = strings.TrimSpace(pr.MergeBase)
+
+	if err := updatePullRequestSize(gitRepo, pr, "tracking"); err != nil {
+		log.Error("Unable to compute the size of PullRequest[%d]: %v", pr.ID, err)
+	}
+
 	tmpPatchFile, err := ioutil.TempFile("", "patch")
 	if err != nil {
 		log.Error("Unable to create temporary patch file! Error: %v", err)
@@ -7,9 +7,11 @@ package pull
 import (
 	"bufio"
 	"bytes"
+	"container/list"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"strings"
@@ -58,6 +60,16 @@ func NewPullRequest(repo *models.Repository, pull *models.Issue, labelIDs []int6
 
 	notification.NotifyNewPullRequest(pr)
 
+	applyLabeler(pr, pull.Poster)
+	notifyPathSubscribers(pr, pull.Poster)
+	checkDCOStatus(pr, pull.Poster)
+	checkCLAStatus(pr, pull.Poster)
+
+	if err := issue_service.ApplyAssignRules(pull, pull.Poster); err != nil {
+		log.Error("ApplyAssignRules[%d]: %v", pull.ID, err)
+	}
+	issue_service.MoveLinkedProjectIssues(pr, models.ProjectAutomationInProgress)
+
 	// add first push codes comment
 	baseGitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
 	if err != nil {
@@ -175,7 +187,7 @@ func ChangeTargetBranch(pr *models.PullRequest, doer *models.User, targetBranch
 	pr.CommitsAhead = divergence.Ahead
 	pr.CommitsBehind = divergence.Behind
 
-	if err := pr.UpdateColsIfNotMerged("merge_base", "status", "conflicted_files", "base_branch", "commits_ahead", "commits_behind"); err != nil {
+	if err := pr.UpdateColsIfNotMerged("merge_base", "status", "conflicted_files", "base_branch", "commits_ahead", "commits_behind", "num_changed_files", "num_changed_lines"); err != nil {
 		return err
 	}
 
@@ -284,6 +296,14 @@ func AddTestPullRequestTask(doer *models.User, repoID int64, branch string, isSy
 		}
 
 		addHeadRepoTasks(prs)
+		if isSync {
+			for _, pr := range prs {
+				applyLabeler(pr, doer)
+				notifyPathSubscribers(pr, doer)
+				checkDCOStatus(pr, doer)
+				checkCLAStatus(pr, doer)
+			}
+		}
 		for _, pr := range prs {
 			comment, err := models.CreatePushPullComment(doer, pr, oldCommitID, newCommitID)
 			if err == nil && comment != nil {
@@ -742,3 +762,70 @@ func IsHeadEqualWithBranch(pr *models.PullRequest, branchName string) (bool, err
 	}
 	return baseCommit.HasPreviousCommit(headCommit.ID)
 }
+
+// readBaseBranchFile reads the first of the files existing in the base branch
+// of the pull request, it returns nil if none of them exists.
+func readBaseBranchFile(gitRepo *git.Repository, pr *models.PullRequest, paths []string, maxSize int64) ([]byte, error) {
+	commit, err := gitRepo.GetBranchCommit(pr.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range paths {
+		blob, err := commit.GetBlobByPath(p)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if blob.Size() > maxSize {
+			return nil, fmt.Errorf("%s is larger than %d bytes", p, maxSize)
+		}
+
+		dataRc, err := blob.DataAsync()
+		if err != nil {
+			return nil, err
+		}
+		defer dataRc.Close()
+		return ioutil.ReadAll(dataRc)
+	}
+	return nil, nil
+}
+
+// getChangedFiles returns the paths of the files changed by the pull request
+// since its merge base
+func getChangedFiles(gitRepo *git.Repository, pr *models.PullRequest) ([]string, error) {
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := gitRepo.GetCommit(headCommitID)
+	if err != nil {
+		return nil, err
+	}
+	// the merge base of the pull request is updated asynchronously after a push
+	mergeBase, _, err := gitRepo.GetMergeBase("", git.BranchPrefix+pr.BaseBranch, headCommitID)
+	if err != nil {
+		return nil, fmt.Errorf("GetMergeBase: %v", err)
+	}
+	return headCommit.GetFilesChangedSinceCommit(mergeBase)
+}
+
+// getPullCommits returns the head commit ID of the pull request and its commits since
+// the merge base, starting with the newest one
+func getPullCommits(gitRepo *git.Repository, pr *models.PullRequest) (string, *list.List, error) {
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return "", nil, err
+	}
+	mergeBase, _, err := gitRepo.GetMergeBase("", git.BranchPrefix+pr.BaseBranch, headCommitID)
+	if err != nil {
+		return "", nil, fmt.Errorf("GetMergeBase: %v", err)
+	}
+	commits, err := gitRepo.CommitsBetweenIDs(headCommitID, mergeBase)
+	if err != nil {
+		return "", nil, fmt.Errorf("CommitsBetweenIDs: %v", err)
+	}
+	return headCommitID, commits, nil
+}
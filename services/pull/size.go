@@ -0,0 +1,42 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// updatePullRequestSize counts the files and lines changed by the pull request
+// between the merge base and the head, the vendored and generated files as
+// configured by the .gitattributes of the head are not counted.
+func updatePullRequestSize(gitRepo *git.Repository, pr *models.PullRequest, head string) error {
+	headCommit, err := gitRepo.GetBranchCommit(head)
+	if err != nil {
+		return fmt.Errorf("GetBranchCommit: %v", err)
+	}
+	attrs, err := headCommit.GetLinguistAttributes()
+	if err != nil {
+		return fmt.Errorf("GetLinguistAttributes: %v", err)
+	}
+	stats, err := gitRepo.GetDiffFileStats(pr.MergeBase, headCommit.ID.String())
+	if err != nil {
+		return fmt.Errorf("GetDiffFileStats: %v", err)
+	}
+
+	files, lines := 0, 0
+	for _, stat := range stats {
+		if attrs.IsExcluded(stat.Name) {
+			continue
+		}
+		files++
+		lines += stat.Additions + stat.Deletions
+	}
+	pr.NumChangedFiles = files
+	pr.NumChangedLines = lines
+	return nil
+}
@@ -37,7 +37,7 @@ func Update(pull *models.PullRequest, doer *models.User, message string) error {
 		return fmt.Errorf("HeadBranch of PR %d is up to date", pull.Index)
 	}
 
-	_, err = rawMerge(pr, doer, models.MergeStyleMerge, message)
+	_, err = rawMerge(pr, doer, models.MergeStyleMerge, message, false)
 
 	defer func() {
 		go AddTestPullRequestTask(doer, pr.HeadRepo.ID, pr.HeadBranch, false, "", "")
@@ -0,0 +1,510 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package quickaction
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/unknwon/i18n"
+)
+
+// The types of the items of the command palette
+const (
+	TypeRepo   = "repo"
+	TypeIssue  = "issue"
+	TypePull   = "pull"
+	TypeUser   = "user"
+	TypeOrg    = "org"
+	TypeAction = "action"
+)
+
+// DefaultLimit is the number of items returned when no limit is given
+const DefaultLimit = 10
+
+// Item is a result of the command palette
+type Item struct {
+	Type string
+	// Key identifies the item in the recent items of the user, like "repo:1"
+	Key         string
+	Title       string
+	Description string
+	Link        string
+	Icon        string
+	// Recent is true if the user chose the item before
+	Recent bool
+
+	score float64
+}
+
+// Options are the options of a search of the command palette
+type Options struct {
+	Keyword string
+	// Repo is the repository the user is looking at, its actions are offered too
+	Repo  *models.Repository
+	Limit int
+}
+
+// action is a page of the command palette which does not need a search
+type action struct {
+	Name     string
+	TitleKey string
+	Icon     string
+	Link     string
+	Visible  func(doer *models.User) bool
+}
+
+var globalActions = []*action{
+	{Name: "dashboard", TitleKey: "dashboard", Icon: "octicon-home", Link: "/"},
+	{Name: "notifications", TitleKey: "notifications", Icon: "octicon-bell", Link: "/notifications"},
+	{Name: "explore", TitleKey: "explore", Icon: "octicon-telescope", Link: "/explore/repos"},
+	{Name: "new_repo", TitleKey: "new_repo", Icon: "octicon-plus", Link: "/repo/create",
+		Visible: func(doer *models.User) bool { return doer.CanCreateRepo() }},
+	{Name: "new_migrate", TitleKey: "new_migrate", Icon: "octicon-plus", Link: "/repo/migrate",
+		Visible: func(doer *models.User) bool { return doer.CanCreateRepo() }},
+	{Name: "new_org", TitleKey: "new_org", Icon: "octicon-organization", Link: "/org/create",
+		Visible: func(doer *models.User) bool { return doer.CanCreateOrganization() }},
+	{Name: "settings", TitleKey: "your_settings", Icon: "octicon-gear", Link: "/user/settings"},
+	{Name: "admin", TitleKey: "admin_panel", Icon: "octicon-server", Link: "/admin",
+		Visible: func(doer *models.User) bool { return doer.IsAdmin }},
+}
+
+// repoAction is an action on the repository the user is looking at
+type repoAction struct {
+	Name     string
+	TitleKey string
+	Icon     string
+	Path     string
+	Visible  func(perm models.Permission) bool
+}
+
+var repoActions = []*repoAction{
+	{Name: "new_issue", TitleKey: "repo.issues.new", Icon: "octicon-issue-opened", Path: "/issues/new",
+		Visible: func(perm models.Permission) bool { return perm.CanRead(models.UnitTypeIssues) }},
+	{Name: "new_pull", TitleKey: "repo.pulls.new", Icon: "octicon-git-pull-request", Path: "/compare",
+		Visible: func(perm models.Permission) bool { return perm.CanRead(models.UnitTypePullRequests) }},
+	{Name: "repo_settings", TitleKey: "repo.settings", Icon: "octicon-gear", Path: "/settings",
+		Visible: func(perm models.Permission) bool { return perm.IsAdmin() }},
+}
+
+// issueReferencePattern matches "#12" and "owner/repo#12"
+var issueReferencePattern = regexp.MustCompile(`^(?:([\w.-]+)/([\w.-]+))?#(\d+)$`)
+
+func (a *action) item(doer *models.User) *Item {
+	return &Item{
+		Type:  TypeAction,
+		Key:   "action:" + a.Name,
+		Title: i18n.Tr(doer.Language, a.TitleKey),
+		Link:  setting.AppSubURL + a.Link,
+		Icon:  a.Icon,
+	}
+}
+
+func (a *repoAction) item(doer *models.User, repo *models.Repository) *Item {
+	return &Item{
+		Type:        TypeAction,
+		Key:         fmt.Sprintf("action:%s:%d", a.Name, repo.ID),
+		Title:       i18n.Tr(doer.Language, a.TitleKey),
+		Description: repo.FullName(),
+		Link:        repo.Link() + a.Path,
+		Icon:        a.Icon,
+	}
+}
+
+func repoItem(repo *models.Repository) *Item {
+	icon := "octicon-repo"
+	if repo.IsPrivate {
+		icon = "octicon-lock"
+	}
+	return &Item{
+		Type:        TypeRepo,
+		Key:         fmt.Sprintf("repo:%d", repo.ID),
+		Title:       repo.FullName(),
+		Description: repo.Description,
+		Link:        repo.Link(),
+		Icon:        icon,
+	}
+}
+
+func issueItem(issue *models.Issue) *Item {
+	item := &Item{
+		Type:        TypeIssue,
+		Key:         fmt.Sprintf("issue:%d", issue.ID),
+		Title:       fmt.Sprintf("#%d %s", issue.Index, issue.Title),
+		Description: issue.Repo.FullName(),
+		Link:        fmt.Sprintf("%s/issues/%d", issue.Repo.Link(), issue.Index),
+		Icon:        "octicon-issue-opened",
+	}
+	if issue.IsPull {
+		item.Type = TypePull
+		item.Link = fmt.Sprintf("%s/pulls/%d", issue.Repo.Link(), issue.Index)
+		item.Icon = "octicon-git-pull-request"
+	}
+	return item
+}
+
+func userItem(u *models.User) *Item {
+	item := &Item{
+		Type:        TypeUser,
+		Key:         fmt.Sprintf("user:%d", u.ID),
+		Title:       u.Name,
+		Description: u.FullName,
+		Link:        u.HomeLink(),
+		Icon:        "octicon-person",
+	}
+	if u.IsOrganization() {
+		item.Type = TypeOrg
+		item.Icon = "octicon-organization"
+	}
+	return item
+}
+
+// canReadIssue checks that the doer can see the issue or the pull request
+func canReadIssue(doer *models.User, issue *models.Issue) (bool, error) {
+	if err := issue.LoadRepo(); err != nil {
+		return false, err
+	}
+	perm, err := models.GetUserRepoPermission(issue.Repo, doer)
+	if err != nil {
+		return false, err
+	}
+	return perm.CanReadIssuesOrPulls(issue.IsPull), nil
+}
+
+// canSeeUser checks that the user or the organization is visible to the doer
+func canSeeUser(doer, u *models.User) (bool, error) {
+	_, count, err := models.SearchUsers(&models.SearchUserOptions{
+		Actor:       doer,
+		Type:        u.Type,
+		UID:         u.ID,
+		ListOptions: models.ListOptions{PageSize: 1},
+		Visible:     []structs.VisibleType{structs.VisibleTypePublic, structs.VisibleTypeLimited, structs.VisibleTypePrivate},
+	})
+	return count > 0, err
+}
+
+// resolveKey returns the item of a recent item key, or nil if it does not exist anymore or
+// the doer cannot see it.
+func resolveKey(doer *models.User, key string) (*Item, error) {
+	parts := strings.Split(key, ":")
+	if parts[0] == TypeAction {
+		for _, a := range globalActions {
+			if len(parts) == 2 && a.Name == parts[1] && (a.Visible == nil || a.Visible(doer)) {
+				return a.item(doer), nil
+			}
+		}
+		if len(parts) != 3 {
+			return nil, nil
+		}
+		repoID, _ := strconv.ParseInt(parts[2], 10, 64)
+		for _, a := range repoActions {
+			if a.Name != parts[1] {
+				continue
+			}
+			repo, err := models.GetRepositoryByID(repoID)
+			if err != nil {
+				if models.IsErrRepoNotExist(err) {
+					return nil, nil
+				}
+				return nil, err
+			}
+			perm, err := models.GetUserRepoPermission(repo, doer)
+			if err != nil || !a.Visible(perm) {
+				return nil, err
+			}
+			return a.item(doer, repo), nil
+		}
+		return nil, nil
+	}
+
+	if len(parts) != 2 {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+	switch parts[0] {
+	case TypeRepo:
+		repo, err := models.GetRepositoryByID(id)
+		if err != nil {
+			if models.IsErrRepoNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		perm, err := models.GetUserRepoPermission(repo, doer)
+		if err != nil || !perm.HasAccess() {
+			return nil, err
+		}
+		return repoItem(repo), nil
+	case TypeIssue:
+		issue, err := models.GetIssueByID(id)
+		if err != nil {
+			if models.IsErrIssueNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if ok, err := canReadIssue(doer, issue); err != nil || !ok {
+			return nil, err
+		}
+		return issueItem(issue), nil
+	case TypeUser:
+		u, err := models.GetUserByID(id)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if ok, err := canSeeUser(doer, u); err != nil || !ok {
+			return nil, err
+		}
+		return userItem(u), nil
+	}
+	return nil, nil
+}
+
+// matchScore ranks how well the title matches the keyword
+func matchScore(title, keyword string) float64 {
+	title, keyword = strings.ToLower(title), strings.ToLower(keyword)
+	switch {
+	case title == keyword:
+		return 3
+	case strings.HasPrefix(title, keyword):
+		return 2
+	case strings.Contains(title, keyword):
+		return 1
+	}
+	// found by the issue indexer or in another field
+	return 0.5
+}
+
+func (opts *Options) actions(doer *models.User) ([]*Item, error) {
+	items := make([]*Item, 0, len(globalActions)+len(repoActions))
+	if opts.Repo != nil {
+		perm, err := models.GetUserRepoPermission(opts.Repo, doer)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range repoActions {
+			if a.Visible(perm) {
+				items = append(items, a.item(doer, opts.Repo))
+			}
+		}
+	}
+	for _, a := range globalActions {
+		if a.Visible == nil || a.Visible(doer) {
+			items = append(items, a.item(doer))
+		}
+	}
+	return items, nil
+}
+
+func searchRepos(doer *models.User, keyword string, limit int) ([]*Item, error) {
+	repos, _, err := models.SearchRepository(&models.SearchRepoOptions{
+		ListOptions: models.ListOptions{PageSize: limit},
+		Actor:       doer,
+		Keyword:     keyword,
+		Private:     true,
+		AllPublic:   true,
+		AllLimited:  true,
+		Collaborate: util.OptionalBoolNone,
+		OrderBy:     models.SearchOrderByRecentUpdated,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*Item, 0, len(repos))
+	for _, repo := range repos {
+		items = append(items, repoItem(repo))
+	}
+	return items, nil
+}
+
+func searchIssues(doer *models.User, opts *Options, limit int) ([]*Item, error) {
+	var issues []*models.Issue
+	if m := issueReferencePattern.FindStringSubmatch(opts.Keyword); m != nil {
+		// a reference to an issue of the current repository or of another one
+		repo := opts.Repo
+		if m[1] != "" {
+			var err error
+			if repo, err = models.GetRepositoryByOwnerAndName(m[1], m[2]); err != nil {
+				if models.IsErrRepoNotExist(err) {
+					return nil, nil
+				}
+				return nil, err
+			}
+		}
+		if repo == nil {
+			return nil, nil
+		}
+		index, _ := strconv.ParseInt(m[3], 10, 64)
+		issue, err := models.GetIssueByIndex(repo.ID, index)
+		if err != nil {
+			if models.IsErrIssueNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		issues = append(issues, issue)
+	} else {
+		repoIDs, err := models.FindUserAccessibleRepoIDs(doer)
+		if err != nil || len(repoIDs) == 0 {
+			return nil, err
+		}
+		issueIDs, err := issue_indexer.SearchIssuesByKeyword(repoIDs, opts.Keyword)
+		if err != nil || len(issueIDs) == 0 {
+			return nil, err
+		}
+		if issues, err = models.Issues(&models.IssuesOptions{
+			ListOptions: models.ListOptions{Page: 1, PageSize: limit},
+			IssueIDs:    issueIDs,
+			SortType:    "recentupdate",
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	items := make([]*Item, 0, len(issues))
+	for _, issue := range issues {
+		ok, err := canReadIssue(doer, issue)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			items = append(items, issueItem(issue))
+		}
+	}
+	return items, nil
+}
+
+func searchUsers(doer *models.User, keyword string, limit int) ([]*Item, error) {
+	items := make([]*Item, 0, limit)
+	for _, typ := range []models.UserType{models.UserTypeIndividual, models.UserTypeOrganization} {
+		users, _, err := models.SearchUsers(&models.SearchUserOptions{
+			Actor:       doer,
+			Keyword:     keyword,
+			Type:        typ,
+			ListOptions: models.ListOptions{PageSize: limit},
+			IsActive:    util.OptionalBoolTrue,
+			Visible:     []structs.VisibleType{structs.VisibleTypePublic, structs.VisibleTypeLimited, structs.VisibleTypePrivate},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			items = append(items, userItem(u))
+		}
+	}
+	return items, nil
+}
+
+// Search returns the items of the command palette matching the keyword, the items the
+// user chose recently and often come first. Without a keyword the recent items and the
+// actions are returned.
+func Search(doer *models.User, opts *Options) ([]*Item, error) {
+	opts.Keyword = strings.TrimSpace(opts.Keyword)
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultLimit
+	}
+
+	recentItems, err := models.GetRecentItems(doer.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetRecentItems: %v", err)
+	}
+	now := timeutil.TimeStampNow()
+	recentScores := make(map[string]float64, len(recentItems))
+	for _, recent := range recentItems {
+		recentScores[recent.ItemKey] = recent.Score(now)
+	}
+
+	actions, err := opts.actions(doer)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Item
+	if opts.Keyword == "" {
+		for _, recent := range recentItems {
+			item, err := resolveKey(doer, recent.ItemKey)
+			if err != nil {
+				log.Error("resolveKey [%s]: %v", recent.ItemKey, err)
+				continue
+			}
+			if item != nil {
+				items = append(items, item)
+			}
+		}
+		items = append(items, actions...)
+	} else {
+		lowerKeyword := strings.ToLower(opts.Keyword)
+		for _, item := range actions {
+			if strings.Contains(strings.ToLower(item.Title), lowerKeyword) {
+				items = append(items, item)
+			}
+		}
+		for _, search := range []func() ([]*Item, error){
+			func() ([]*Item, error) { return searchRepos(doer, opts.Keyword, opts.Limit) },
+			func() ([]*Item, error) { return searchIssues(doer, opts, opts.Limit) },
+			func() ([]*Item, error) { return searchUsers(doer, opts.Keyword, opts.Limit) },
+		} {
+			found, err := search()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, found...)
+		}
+	}
+
+	// remove the duplicates and rank the items
+	seen := make(map[string]bool, len(items))
+	ranked := items[:0]
+	for _, item := range items {
+		if seen[item.Key] {
+			continue
+		}
+		seen[item.Key] = true
+		score, recent := recentScores[item.Key]
+		item.Recent = recent
+		item.score = score
+		if opts.Keyword != "" {
+			item.score += matchScore(item.Title, opts.Keyword)
+		}
+		ranked = append(ranked, item)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	if len(ranked) > opts.Limit {
+		ranked = ranked[:opts.Limit]
+	}
+	return ranked, nil
+}
+
+// RecordChoice remembers that the user chose the item, it ranks the next results.
+func RecordChoice(doer *models.User, key string) error {
+	item, err := resolveKey(doer, key)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return models.ErrInvalidRecentItemKey{Key: key}
+	}
+	return models.RecordRecentItem(doer.ID, key)
+}
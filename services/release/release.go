@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
@@ -17,6 +18,25 @@ import (
 	"code.gitea.io/gitea/modules/timeutil"
 )
 
+// checkTagRulesets checks that the rulesets of the organization allow the user
+// to create or to delete the tag, the commit of a new tag must be signed if a
+// ruleset requires it.
+func checkTagRulesets(repo *models.Repository, tagName string, doerID int64, commit *git.Commit) error {
+	rulesets, err := models.GetTagRulesets(repo, tagName, doerID)
+	if err != nil {
+		return fmt.Errorf("GetTagRulesets: %v", err)
+	}
+	for _, r := range rulesets {
+		if !r.CanUserPush(doerID) || (commit != nil && r.RequireSignedCommits && !models.ParseCommitWithSignature(commit).Verified) {
+			return models.ErrTagProtected{
+				TagName: tagName,
+				Ruleset: r.Name,
+			}
+		}
+	}
+	return nil
+}
+
 func createTag(gitRepo *git.Repository, rel *models.Release) error {
 	// Only actual create when publish.
 	if !rel.IsDraft {
@@ -28,6 +48,13 @@ func createTag(gitRepo *git.Repository, rel *models.Release) error {
 
 			// Trim '--' prefix to prevent command line argument vulnerability.
 			rel.TagName = strings.TrimPrefix(rel.TagName, "--")
+			if err = rel.LoadAttributes(); err != nil {
+				log.Error("LoadAttributes: %v", err)
+				return err
+			}
+			if err = checkTagRulesets(rel.Repo, rel.TagName, rel.PublisherID, commit); err != nil {
+				return err
+			}
 			if err = gitRepo.CreateTag(rel.TagName, commit.ID.String()); err != nil {
 				if strings.Contains(err.Error(), "is not a valid tag name") {
 					return models.ErrInvalidTagName{
@@ -37,11 +64,6 @@ func createTag(gitRepo *git.Repository, rel *models.Release) error {
 				return err
 			}
 			rel.LowerTagName = strings.ToLower(rel.TagName)
-			// Prepare Notify
-			if err := rel.LoadAttributes(); err != nil {
-				log.Error("LoadAttributes: %v", err)
-				return err
-			}
 			notification.NotifyPushCommits(
 				rel.Publisher, rel.Repo, git.TagPrefix+rel.TagName,
 				git.EmptySHA, commit.ID.String(), repository.NewPushCommits())
@@ -87,6 +109,8 @@ func CreateRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs
 	if err = models.AddReleaseAttachments(rel.ID, attachmentUUIDs); err != nil {
 		return err
 	}
+	// The static site may be served from an attachment of the latest release
+	cache.Remove(models.GetPagesCacheKey(rel.RepoID))
 
 	if !rel.IsDraft {
 		notification.NotifyNewRelease(rel)
@@ -109,6 +133,7 @@ func UpdateRelease(doer *models.User, gitRepo *git.Repository, rel *models.Relea
 	if err = models.AddReleaseAttachments(rel.ID, attachmentUUIDs); err != nil {
 		log.Error("AddReleaseAttachments: %v", err)
 	}
+	cache.Remove(models.GetPagesCacheKey(rel.RepoID))
 
 	notification.NotifyUpdateRelease(doer, rel)
 
@@ -128,6 +153,14 @@ func DeleteReleaseByID(id int64, doer *models.User, delTag bool) error {
 	}
 
 	if delTag {
+		var doerID int64
+		if doer != nil {
+			doerID = doer.ID
+		}
+		if err := checkTagRulesets(repo, rel.TagName, doerID, nil); err != nil {
+			return err
+		}
+
 		if stdout, err := git.NewCommand("tag", "-d", rel.TagName).
 			SetDescription(fmt.Sprintf("DeleteReleaseByID (git tag -d): %d", rel.ID)).
 			RunInDir(repo.RepoPath()); err != nil && !strings.Contains(err.Error(), "not found") {
@@ -164,8 +197,13 @@ func DeleteReleaseByID(id int64, doer *models.User, delTag bool) error {
 		if err := os.RemoveAll(attachment.LocalPath()); err != nil {
 			log.Error("Delete attachment %s of release %s failed: %v", attachment.UUID, rel.ID, err)
 		}
+		if err := os.RemoveAll(attachment.ThumbnailPath()); err != nil {
+			log.Error("Delete thumbnail of attachment %s of release %s failed: %v", attachment.UUID, rel.ID, err)
+		}
 	}
 
+	cache.Remove(models.GetPagesCacheKey(rel.RepoID))
+
 	notification.NotifyDeleteRelease(doer, rel)
 
 	return nil
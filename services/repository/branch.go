@@ -0,0 +1,25 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/notification"
+	repo_module "code.gitea.io/gitea/modules/repository"
+)
+
+// RenameBranch renames a branch of a repository, retargeting the open pull requests, the branch
+// protection and the webhooks referencing it, and notifies the deletion of the old branch and the
+// creation of the new one.
+func RenameBranch(doer *models.User, repo *models.Repository, gitRepo *git.Repository, from, to string) error {
+	if err := repo_module.RenameBranch(doer, repo, gitRepo, from, to); err != nil {
+		return err
+	}
+
+	notification.NotifyDeleteRef(doer, repo, "branch", git.BranchPrefix+from)
+	notification.NotifyCreateRef(doer, repo, "branch", git.BranchPrefix+to)
+	return nil
+}
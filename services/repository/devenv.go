@@ -0,0 +1,40 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/devcontainer"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// GetDevEnvironment returns the dev environment of a commit of a repository, opened from the
+// ref and the web page. A dev container configuration which cannot be read is ignored.
+func GetDevEnvironment(repo *models.Repository, commit *git.Commit, ref, webURL string) (*devcontainer.Environment, error) {
+	if err := repo.GetOwner(); err != nil {
+		return nil, err
+	}
+	cfg, err := devcontainer.Load(commit)
+	if err != nil {
+		log.Info("Dev container configuration of %s at %s skipped: %v", repo.FullName(), commit.ID, err)
+		cfg = nil
+	}
+
+	cloneLink := repo.CloneLink()
+	return &devcontainer.Environment{
+		Ref:      ref,
+		CommitID: commit.ID.String(),
+		Config:   cfg,
+		Launchers: devcontainer.Launchers(&devcontainer.LaunchContext{
+			Owner:    repo.OwnerName,
+			Repo:     repo.Name,
+			Ref:      ref,
+			CloneURL: cloneLink.HTTPS,
+			SSHURL:   cloneLink.SSH,
+			WebURL:   webURL,
+		}, cfg != nil),
+	}, nil
+}
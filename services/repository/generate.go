@@ -72,6 +72,15 @@ func GenerateRepository(doer, owner *models.User, templateRepo *models.Repositor
 		return nil, err
 	}
 
+	if owner.IsOrganization() {
+		if err := models.ApplyOrgRepoDefaults(generateRepo); err != nil {
+			log.Error("ApplyOrgRepoDefaults[%-v]: %v", generateRepo, err)
+		}
+		if _, err := models.SyncOrgLabelsToRepo(generateRepo); err != nil {
+			log.Error("SyncOrgLabelsToRepo[%-v]: %v", generateRepo, err)
+		}
+	}
+
 	notification.NotifyCreateRepository(doer, owner, generateRepo)
 
 	return generateRepo, nil
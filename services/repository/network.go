@@ -0,0 +1,140 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/gitgraph"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// NetworkBranch represents the tip of a branch of a repository of a fork network
+type NetworkBranch struct {
+	Name     string
+	CommitID string
+}
+
+// NetworkRepository represents a repository of a fork network along with the tips of its branches
+type NetworkRepository struct {
+	Repo     *models.Repository
+	Branches []*NetworkBranch
+}
+
+// NetworkGraphItem represents a line of the commit graph of a fork network,
+// Repo is the repository the commit was reached from if it is known.
+type NetworkGraphItem struct {
+	gitgraph.GraphItem
+	Repo *models.Repository
+}
+
+// GetNetworkRepositories returns the tips of the branches of the repositories of a fork network
+func GetNetworkRepositories(repos []*models.Repository) ([]*NetworkRepository, error) {
+	network := make([]*NetworkRepository, 0, len(repos))
+	for _, repo := range repos {
+		networkRepo := &NetworkRepository{Repo: repo, Branches: []*NetworkBranch{}}
+		network = append(network, networkRepo)
+		if repo.IsEmpty {
+			continue
+		}
+
+		gitRepo, err := git.OpenRepository(repo.RepoPath())
+		if err != nil {
+			return nil, fmt.Errorf("OpenRepository[%s]: %v", repo.FullName(), err)
+		}
+		refs, err := gitRepo.GetRefsFiltered(git.BranchPrefix)
+		gitRepo.Close()
+		if err != nil {
+			return nil, fmt.Errorf("GetRefsFiltered[%s]: %v", repo.FullName(), err)
+		}
+		for _, ref := range refs {
+			networkRepo.Branches = append(networkRepo.Branches, &NetworkBranch{
+				Name:     strings.TrimPrefix(ref.Name, git.BranchPrefix),
+				CommitID: ref.Object.String(),
+			})
+		}
+	}
+	return network, nil
+}
+
+// networkRefName returns the name of the reference of the branch of the repository in the network graph
+func networkRefName(repo *models.Repository, branch string) string {
+	return git.BranchPrefix + repo.OwnerName + "/" + repo.Name + "/" + branch
+}
+
+// GetNetworkGraph returns the commit graph of the branches of all repositories of a fork network.
+// The branches are shown as {owner}/{repo}/{branch} and are gathered in a temporary repository
+// which borrows the objects of the repositories instead of fetching them.
+func GetNetworkGraph(network []*NetworkRepository, page int) ([]*NetworkGraphItem, error) {
+	tmpBasePath, err := models.CreateTemporaryPath("network")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := models.RemoveTemporaryPath(tmpBasePath); err != nil {
+			log.Error("GetNetworkGraph: RemoveTemporaryPath: %s", err)
+		}
+	}()
+
+	if err := git.InitRepository(tmpBasePath, true); err != nil {
+		return nil, fmt.Errorf("InitRepository: %v", err)
+	}
+
+	alternates := make([]string, 0, len(network))
+	refs := new(strings.Builder)
+	repos := make(map[string]*models.Repository, len(network))
+	for _, networkRepo := range network {
+		if len(networkRepo.Branches) == 0 {
+			continue
+		}
+		alternates = append(alternates, filepath.Join(networkRepo.Repo.RepoPath(), "objects"))
+		repos[networkRefName(networkRepo.Repo, "")] = networkRepo.Repo
+		for _, branch := range networkRepo.Branches {
+			fmt.Fprintf(refs, "create %s %s\n", networkRefName(networkRepo.Repo, branch.Name), branch.CommitID)
+		}
+	}
+	if len(alternates) == 0 {
+		return []*NetworkGraphItem{}, nil
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(tmpBasePath, "objects", "info", "alternates"),
+		[]byte(strings.Join(alternates, "\n")+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("WriteFile: %v", err)
+	}
+	stderr := new(strings.Builder)
+	if err := git.NewCommand("update-ref", "--stdin").
+		RunInDirFullPipeline(tmpBasePath, nil, stderr, strings.NewReader(refs.String())); err != nil {
+		return nil, fmt.Errorf("update-ref: %v - %s", err, stderr)
+	}
+
+	gitRepo, err := git.OpenRepository(tmpBasePath)
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	graph, err := gitgraph.GetCommitGraphWithSource(gitRepo, page)
+	if err != nil {
+		return nil, fmt.Errorf("GetCommitGraphWithSource: %v", err)
+	}
+
+	items := make([]*NetworkGraphItem, 0, len(graph))
+	for _, graphItem := range graph {
+		item := &NetworkGraphItem{GraphItem: graphItem}
+		for prefix, repo := range repos {
+			if strings.HasPrefix(graphItem.Source, prefix) {
+				item.Repo = repo
+				break
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNetworkGraph(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo10 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 10}).(*models.Repository)
+	repo11 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 11}).(*models.Repository)
+
+	network, err := GetNetworkRepositories([]*models.Repository{repo10, repo11})
+	assert.NoError(t, err)
+	if !assert.Len(t, network, 2) {
+		return
+	}
+	assert.Len(t, network[0].Branches, 4)
+	assert.Len(t, network[1].Branches, 5)
+
+	var tip *NetworkBranch
+	for _, branch := range network[1].Branches {
+		if branch.Name == "branch2" {
+			tip = branch
+		}
+	}
+	if !assert.NotNil(t, tip) {
+		return
+	}
+
+	graph, err := GetNetworkGraph(network, 1)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, graph)
+	for _, item := range graph {
+		if item.Rev == tip.CommitID {
+			assert.Contains(t, item.Branch, "user13/repo11/branch2")
+			if assert.NotNil(t, item.Repo) {
+				assert.EqualValues(t, repo11.ID, item.Repo.ID)
+			}
+			return
+		}
+	}
+	assert.Fail(t, "the tip of the branch is missing from the graph")
+}
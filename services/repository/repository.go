@@ -26,6 +26,15 @@ func CreateRepository(doer, owner *models.User, opts models.CreateRepoOptions) (
 		return nil, err
 	}
 
+	if owner.IsOrganization() {
+		if err := models.ApplyOrgRepoDefaults(repo); err != nil {
+			log.Error("ApplyOrgRepoDefaults[%-v]: %v", repo, err)
+		}
+		if _, err := models.SyncOrgLabelsToRepo(repo); err != nil {
+			log.Error("SyncOrgLabelsToRepo[%-v]: %v", repo, err)
+		}
+	}
+
 	notification.NotifyCreateRepository(doer, owner, repo)
 
 	return repo, nil
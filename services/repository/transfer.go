@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/sync"
 
@@ -48,6 +49,15 @@ func TransferOwnership(doer, newOwner *models.User, repo *models.Repository, tea
 		}
 	}
 
+	if newOwner.IsOrganization() {
+		if err := models.ApplyOrgRepoDefaults(newRepo); err != nil {
+			log.Error("ApplyOrgRepoDefaults[%-v]: %v", newRepo, err)
+		}
+		if _, err := models.SyncOrgLabelsToRepo(newRepo); err != nil {
+			log.Error("SyncOrgLabelsToRepo[%-v]: %v", newRepo, err)
+		}
+	}
+
 	notification.NotifyTransferRepository(doer, repo, oldOwner.Name)
 
 	return nil
@@ -0,0 +1,126 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+// ChangeRepositoryVisibility changes the visibility of the repository, and of its forks, right away
+// and cancels the change of visibility scheduled for it if any.
+func ChangeRepositoryVisibility(repo *models.Repository, isPrivate bool) error {
+	if err := models.CancelScheduledVisibilityChange(repo.ID); err != nil {
+		return fmt.Errorf("CancelScheduledVisibilityChange: %v", err)
+	}
+	if repo.IsPrivate == isPrivate {
+		return nil
+	}
+	repo.IsPrivate = isPrivate
+	if err := models.UpdateRepository(repo, true); err != nil {
+		return fmt.Errorf("UpdateRepository: %v", err)
+	}
+	return nil
+}
+
+// ApplyScheduledVisibilityChanges applies the changes of visibility of the repositories which are due
+// and notifies the users who scheduled them and the administrators of the repositories.
+func ApplyScheduledVisibilityChanges(ctx context.Context) error {
+	changes, err := models.GetDueVisibilityChanges(timeutil.TimeStampNow())
+	if err != nil {
+		return fmt.Errorf("GetDueVisibilityChanges: %v", err)
+	}
+
+	for _, change := range changes {
+		select {
+		case <-ctx.Done():
+			return models.ErrCancelledf("before changing the visibility of repository %d", change.RepoID)
+		default:
+		}
+
+		if err := change.LoadAttributes(); err != nil {
+			if !models.IsErrRepoNotExist(err) {
+				return fmt.Errorf("LoadAttributes: %v", err)
+			}
+			// the table is cleaned up with the repository, this only happens on a race
+			if err := models.CancelScheduledVisibilityChange(change.RepoID); err != nil {
+				return fmt.Errorf("CancelScheduledVisibilityChange: %v", err)
+			}
+			continue
+		}
+
+		repo := change.Repo
+		allowed, err := isVisibilityChangeAllowed(change)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			log.Warn("Scheduled visibility change of repository %-v by %s is not allowed anymore, it is cancelled", repo, change.Doer.Name)
+			if err := models.CancelScheduledVisibilityChange(repo.ID); err != nil {
+				return fmt.Errorf("CancelScheduledVisibilityChange: %v", err)
+			}
+			continue
+		}
+
+		if err := ChangeRepositoryVisibility(repo, change.IsPrivate); err != nil {
+			return err
+		}
+		log.Trace("Scheduled visibility change of repository %-v by %s applied: private=%t", repo, change.Doer.Name, change.IsPrivate)
+
+		if setting.Service.EnableNotifyMail {
+			recipients, err := getVisibilityChangeRecipients(change)
+			if err != nil {
+				return err
+			}
+			mailer.SendRepoVisibilityChangedMail(recipients, change.Doer, repo)
+		}
+	}
+	return nil
+}
+
+// isVisibilityChangeAllowed returns if the scheduled change of visibility can still be applied.
+// The user who scheduled it must still exist and be an administrator of the repository, the
+// visibility of forks follows their base repository and with ForcePrivate enabled only site
+// administrators can make a repository public.
+func isVisibilityChangeAllowed(change *models.ScheduledVisibilityChange) (bool, error) {
+	doer := change.Doer
+	if doer.ID <= 0 || !doer.IsActive || doer.ProhibitLogin {
+		return false, nil
+	}
+	if change.Repo.IsFork || (setting.Repository.ForcePrivate && !change.IsPrivate && !doer.IsAdmin) {
+		return false, nil
+	}
+	perm, err := models.GetUserRepoPermission(change.Repo, doer)
+	if err != nil {
+		return false, fmt.Errorf("GetUserRepoPermission: %v", err)
+	}
+	return perm.IsAdmin(), nil
+}
+
+// getVisibilityChangeRecipients returns the active users with an email address among the user
+// who scheduled the change and the administrators of the repository
+func getVisibilityChangeRecipients(change *models.ScheduledVisibilityChange) ([]*models.User, error) {
+	admins, err := change.Repo.GetAdmins()
+	if err != nil {
+		return nil, fmt.Errorf("GetAdmins: %v", err)
+	}
+
+	seen := make(map[int64]bool, len(admins)+1)
+	recipients := make([]*models.User, 0, len(admins)+1)
+	for _, u := range append([]*models.User{change.Doer}, admins...) {
+		if seen[u.ID] || u.ID <= 0 || !u.IsActive || u.ProhibitLogin || u.Email == "" {
+			continue
+		}
+		seen[u.ID] = true
+		recipients = append(recipients, u)
+	}
+	return recipients, nil
+}
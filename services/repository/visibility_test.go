@@ -0,0 +1,57 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyScheduledVisibilityChanges(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	fork := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 29}).(*models.Repository)
+	now := timeutil.TimeStampNow()
+
+	assert.NoError(t, models.ScheduleVisibilityChange(doer, repo, true, now.Add(-60)))
+	// the visibility of forks follows their base repository
+	assert.NoError(t, models.ScheduleVisibilityChange(doer, fork, true, now.Add(-60)))
+	// changes which are not due are kept
+	later := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 27}).(*models.Repository)
+	assert.NoError(t, models.ScheduleVisibilityChange(doer, later, true, now.Add(3600)))
+	// changes by users who are not administrators of the repository anymore are cancelled
+	notAdmin := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	repo2 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 2}).(*models.Repository)
+	assert.NoError(t, models.ScheduleVisibilityChange(notAdmin, repo2, false, now.Add(-60)))
+	deleted := &models.User{ID: 1000}
+	repo4 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 4}).(*models.Repository)
+	assert.NoError(t, models.ScheduleVisibilityChange(deleted, repo4, true, now.Add(-60)))
+
+	assert.NoError(t, ApplyScheduledVisibilityChanges(context.Background()))
+
+	repo = models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.True(t, repo.IsPrivate)
+	fork = models.AssertExistsAndLoadBean(t, &models.Repository{ID: 29}).(*models.Repository)
+	assert.False(t, fork.IsPrivate)
+	later = models.AssertExistsAndLoadBean(t, &models.Repository{ID: 27}).(*models.Repository)
+	assert.False(t, later.IsPrivate)
+	repo2 = models.AssertExistsAndLoadBean(t, &models.Repository{ID: 2}).(*models.Repository)
+	assert.True(t, repo2.IsPrivate)
+	repo4 = models.AssertExistsAndLoadBean(t, &models.Repository{ID: 4}).(*models.Repository)
+	assert.False(t, repo4.IsPrivate)
+
+	models.AssertNotExistsBean(t, &models.ScheduledVisibilityChange{RepoID: repo.ID})
+	models.AssertNotExistsBean(t, &models.ScheduledVisibilityChange{RepoID: fork.ID})
+	models.AssertExistsAndLoadBean(t, &models.ScheduledVisibilityChange{RepoID: later.ID})
+	models.AssertNotExistsBean(t, &models.ScheduledVisibilityChange{RepoID: repo2.ID})
+	models.AssertNotExistsBean(t, &models.ScheduledVisibilityChange{RepoID: repo4.ID})
+}
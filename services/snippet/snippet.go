@@ -0,0 +1,306 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package snippet
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// snippetBranch is the branch of the git repository of a snippet holding its latest revision
+const snippetBranch = "master"
+
+// ErrInvalidFiles represents a "InvalidFiles" kind of error, the files of a snippet
+// break one of its rules.
+type ErrInvalidFiles struct {
+	Name   string
+	Reason string
+}
+
+// IsErrInvalidFiles checks if an error is a ErrInvalidFiles.
+func IsErrInvalidFiles(err error) bool {
+	_, ok := err.(ErrInvalidFiles)
+	return ok
+}
+
+func (err ErrInvalidFiles) Error() string {
+	return fmt.Sprintf("invalid snippet files [name: %s, reason: %s]", err.Name, err.Reason)
+}
+
+// ErrRevisionNotExist represents a "RevisionNotExist" kind of error.
+type ErrRevisionNotExist struct {
+	Revision string
+}
+
+// IsErrRevisionNotExist checks if an error is a ErrRevisionNotExist.
+func IsErrRevisionNotExist(err error) bool {
+	_, ok := err.(ErrRevisionNotExist)
+	return ok
+}
+
+func (err ErrRevisionNotExist) Error() string {
+	return fmt.Sprintf("snippet revision does not exist [revision: %s]", err.Revision)
+}
+
+// File represents a file of a snippet
+type File struct {
+	Name    string
+	Content string
+}
+
+// Options represents the content of a snippet when it is created or changed
+type Options struct {
+	Title       string
+	Description string
+	Visibility  api.VisibleType
+	Files       []*File
+}
+
+// validateFiles checks that the files can be stored flat in the git repository of a snippet
+func validateFiles(files []*File) error {
+	if len(files) == 0 {
+		return ErrInvalidFiles{Reason: "no files"}
+	} else if len(files) > setting.Snippet.MaxFiles {
+		return ErrInvalidFiles{Reason: "too many files"}
+	}
+	names := make(map[string]bool, len(files))
+	for _, f := range files {
+		switch {
+		case f.Name == "" || f.Name == "." || f.Name == "..":
+			return ErrInvalidFiles{Name: f.Name, Reason: "invalid name"}
+		case strings.ContainsAny(f.Name, "/\\\x00\n\t"):
+			return ErrInvalidFiles{Name: f.Name, Reason: "invalid name"}
+		case names[f.Name]:
+			return ErrInvalidFiles{Name: f.Name, Reason: "duplicate name"}
+		case int64(len(f.Content)) > setting.Snippet.MaxFileSize:
+			return ErrInvalidFiles{Name: f.Name, Reason: "too large"}
+		}
+		names[f.Name] = true
+	}
+	return nil
+}
+
+// commitFiles commits the files as a new revision of the snippet, the previous revision is the parent
+// of the new one if there is any. It returns false if the files did not change.
+func commitFiles(gitRepo *git.Repository, doer *models.User, files []*File, message string) (bool, error) {
+	sorted := make([]*File, len(files))
+	copy(sorted, files)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	treeInput := new(bytes.Buffer)
+	for _, f := range sorted {
+		id, err := gitRepo.HashObject(strings.NewReader(f.Content))
+		if err != nil {
+			return false, fmt.Errorf("HashObject: %v", err)
+		}
+		fmt.Fprintf(treeInput, "100644 blob %s\t%s\n", id.String(), f.Name)
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	if err := git.NewCommand("mktree").RunInDirFullPipeline(gitRepo.Path, stdout, stderr, treeInput); err != nil {
+		return false, fmt.Errorf("mktree: %v - %s", err, stderr.String())
+	}
+	treeID, err := git.NewIDFromString(strings.TrimSpace(stdout.String()))
+	if err != nil {
+		return false, err
+	}
+
+	opts := git.CommitTreeOpts{Message: message, NoGPGSign: true}
+	if parent, err := gitRepo.GetBranchCommit(snippetBranch); err == nil {
+		if parent.Tree.ID == treeID {
+			return false, nil
+		}
+		opts.Parents = []string{parent.ID.String()}
+	} else if !git.IsErrNotExist(err) {
+		return false, fmt.Errorf("GetBranchCommit: %v", err)
+	}
+
+	commitID, err := gitRepo.CommitTree(doer.NewGitSig(), git.NewTree(gitRepo, treeID), opts)
+	if err != nil {
+		return false, fmt.Errorf("CommitTree: %v", err)
+	}
+	if _, err := git.NewCommand("update-ref", git.BranchPrefix+snippetBranch, commitID.String()).RunInDir(gitRepo.Path); err != nil {
+		return false, fmt.Errorf("update-ref: %v", err)
+	}
+	return true, nil
+}
+
+// Create creates a snippet of the doer with its first revision.
+func Create(doer *models.User, opts Options) (*models.Snippet, error) {
+	if err := validateFiles(opts.Files); err != nil {
+		return nil, err
+	}
+
+	snippet := &models.Snippet{
+		OwnerID:     doer.ID,
+		Owner:       doer,
+		Title:       opts.Title,
+		Description: opts.Description,
+		Visibility:  opts.Visibility,
+	}
+	if err := models.CreateSnippet(snippet); err != nil {
+		return nil, err
+	}
+
+	if err := initRepository(snippet, doer, opts.Files); err != nil {
+		if errDelete := Delete(snippet); errDelete != nil {
+			log.Error("Delete snippet %d: %v", snippet.ID, errDelete)
+		}
+		return nil, err
+	}
+	return snippet, nil
+}
+
+func initRepository(snippet *models.Snippet, doer *models.User, files []*File) error {
+	repoPath := snippet.RepoPath()
+	if err := git.InitRepository(repoPath, true); err != nil {
+		return fmt.Errorf("InitRepository: %v", err)
+	}
+	if _, err := git.NewCommand("symbolic-ref", "HEAD", git.BranchPrefix+snippetBranch).RunInDir(repoPath); err != nil {
+		return fmt.Errorf("symbolic-ref: %v", err)
+	}
+
+	gitRepo, err := git.OpenRepository(repoPath)
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	_, err = commitFiles(gitRepo, doer, files, "Create snippet")
+	return err
+}
+
+// Update changes the snippet, a new revision is committed if its files changed.
+func Update(doer *models.User, snippet *models.Snippet, opts Options) error {
+	if opts.Files != nil {
+		if err := validateFiles(opts.Files); err != nil {
+			return err
+		}
+	}
+
+	snippet.Title = opts.Title
+	snippet.Description = opts.Description
+	snippet.Visibility = opts.Visibility
+	if err := models.UpdateSnippet(snippet); err != nil {
+		return err
+	}
+	if opts.Files == nil {
+		return nil
+	}
+
+	gitRepo, err := git.OpenRepository(snippet.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	changed, err := commitFiles(gitRepo, doer, opts.Files, "Update snippet")
+	if err != nil || !changed {
+		return err
+	}
+	return models.TouchSnippet(snippet)
+}
+
+// Delete removes the snippet with its comments and revisions.
+func Delete(snippet *models.Snippet) error {
+	if err := models.DeleteSnippet(snippet.ID); err != nil {
+		return err
+	}
+	return os.RemoveAll(snippet.RepoPath())
+}
+
+// getCommit returns the commit of the revision of the snippet, the latest one if the revision is empty
+func getCommit(gitRepo *git.Repository, revision string) (*git.Commit, error) {
+	if revision == "" {
+		return gitRepo.GetBranchCommit(snippetBranch)
+	}
+	if _, err := git.NewIDFromString(revision); err != nil {
+		return nil, ErrRevisionNotExist{revision}
+	}
+	commit, err := gitRepo.GetCommit(revision)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, ErrRevisionNotExist{revision}
+		}
+		return nil, err
+	}
+	return commit, nil
+}
+
+// GetFiles returns the files of the revision of the snippet, of its latest revision if the revision
+// is empty, sorted by name.
+func GetFiles(snippet *models.Snippet, revision string) ([]*File, error) {
+	gitRepo, err := git.OpenRepository(snippet.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	commit, err := getCommit(gitRepo, revision)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := commit.Tree.ListEntries()
+	if err != nil {
+		return nil, fmt.Errorf("ListEntries: %v", err)
+	}
+
+	files := make([]*File, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsRegular() {
+			continue
+		}
+		reader, err := entry.Blob().DataAsync()
+		if err != nil {
+			return nil, err
+		}
+		content, err := ioutil.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &File{Name: entry.Name(), Content: string(content)})
+	}
+	return files, nil
+}
+
+// GetRevisions returns a page of the revisions of the snippet, latest first.
+func GetRevisions(snippet *models.Snippet, page, pageSize int) ([]*git.Commit, int64, error) {
+	gitRepo, err := git.OpenRepository(snippet.RepoPath())
+	if err != nil {
+		return nil, 0, fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	head, err := gitRepo.GetBranchCommit(snippetBranch)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetBranchCommit: %v", err)
+	}
+	count, err := head.CommitsCount()
+	if err != nil {
+		return nil, 0, fmt.Errorf("CommitsCount: %v", err)
+	}
+	commits, err := head.CommitsByRange(page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("CommitsByRange: %v", err)
+	}
+
+	revisions := make([]*git.Commit, 0, commits.Len())
+	for e := commits.Front(); e != nil; e = e.Next() {
+		revisions = append(revisions, e.Value.(*git.Commit))
+	}
+	return revisions, count, nil
+}
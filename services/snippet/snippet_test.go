@@ -0,0 +1,91 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package snippet
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnippetRevisions(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	rootPath, err := ioutil.TempDir("", "snippets")
+	assert.NoError(t, err)
+	defer os.RemoveAll(rootPath)
+	oldRootPath := setting.Snippet.RootPath
+	setting.Snippet.RootPath = rootPath
+	defer func() {
+		setting.Snippet.RootPath = oldRootPath
+	}()
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	snippet, err := Create(doer, Options{
+		Title:      "Hello",
+		Visibility: api.VisibleTypePublic,
+		Files: []*File{
+			{Name: "main.go", Content: "package main\n"},
+			{Name: "README.md", Content: "# Hello\n"},
+		},
+	})
+	assert.NoError(t, err)
+	models.AssertExistsAndLoadBean(t, &models.Snippet{ID: snippet.ID, OwnerID: doer.ID})
+
+	files, err := GetFiles(snippet, "")
+	assert.NoError(t, err)
+	if assert.Len(t, files, 2) {
+		assert.EqualValues(t, "README.md", files[0].Name)
+		assert.EqualValues(t, "package main\n", files[1].Content)
+	}
+
+	// unchanged files do not make a new revision
+	opts := Options{Title: "Hello, World", Visibility: api.VisibleTypePrivate, Files: files}
+	assert.NoError(t, Update(doer, snippet, opts))
+	revisions, count, err := GetRevisions(snippet, 1, 10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	assert.Len(t, revisions, 1)
+	first := revisions[0].ID.String()
+
+	opts.Files = []*File{{Name: "main.go", Content: "package main\n\nfunc main() {}\n"}}
+	assert.NoError(t, Update(doer, snippet, opts))
+	revisions, count, err = GetRevisions(snippet, 1, 10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	assert.Len(t, revisions, 2)
+
+	files, err = GetFiles(snippet, "")
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	files, err = GetFiles(snippet, first)
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	_, err = GetFiles(snippet, "0000000000000000000000000000000000000001")
+	assert.True(t, IsErrRevisionNotExist(err))
+
+	snippet = models.AssertExistsAndLoadBean(t, &models.Snippet{ID: snippet.ID}).(*models.Snippet)
+	assert.EqualValues(t, "Hello, World", snippet.Title)
+	assert.EqualValues(t, api.VisibleTypePrivate, snippet.Visibility)
+
+	assert.NoError(t, Delete(snippet))
+	models.AssertNotExistsBean(t, &models.Snippet{ID: snippet.ID})
+	_, err = os.Stat(snippet.RepoPath())
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestValidateFiles(t *testing.T) {
+	assert.True(t, IsErrInvalidFiles(validateFiles(nil)))
+	assert.True(t, IsErrInvalidFiles(validateFiles([]*File{{Name: "a/b.go"}})))
+	assert.True(t, IsErrInvalidFiles(validateFiles([]*File{{Name: ".."}})))
+	assert.True(t, IsErrInvalidFiles(validateFiles([]*File{{Name: "a.go"}, {Name: "a.go"}})))
+	assert.NoError(t, validateFiles([]*File{{Name: "a.go"}, {Name: "b.go"}}))
+}
@@ -0,0 +1,26 @@
+// Loads the blame of long files chunk by chunk after the first one, which
+// is rendered with the page.
+export default async function initBlame() {
+  const table = document.querySelector('.blame-chunks');
+  if (!table) return;
+
+  const commitInfo = table.querySelector('.lines-commit');
+  const lineNumbers = table.querySelector('.lines-num');
+  const code = table.querySelector('ol.linenums');
+  const loader = document.querySelector('.blame-loader');
+
+  let next = Number(table.dataset.next);
+  while (next > 0) {
+    const res = await fetch(`${table.dataset.url}?start=${next}`);
+    if (!res.ok) break;
+    const chunk = await res.json();
+    commitInfo.insertAdjacentHTML('beforeend', chunk.commit_info);
+    lineNumbers.insertAdjacentHTML('beforeend', chunk.line_numbers);
+    code.insertAdjacentHTML('beforeend', chunk.code);
+    next = chunk.next;
+  }
+  if (loader) loader.remove();
+
+  // select the lines of the URL once they have been loaded
+  $(window).trigger('hashchange');
+}
@@ -11,7 +11,7 @@ function getEditorconfig(input) {
   }
 }
 
-function initLanguages(monaco) {
+export function initLanguages(monaco) {
   for (const {filenames, extensions, id} of monaco.languages.getLanguages()) {
     for (const filename of filenames || []) {
       languagesByFilename[filename] = id;
@@ -22,7 +22,7 @@ function initLanguages(monaco) {
   }
 }
 
-function getLanguage(filename) {
+export function getLanguage(filename) {
   return languagesByFilename[filename] || languagesByExt[extname(filename)] || 'plaintext';
 }
 
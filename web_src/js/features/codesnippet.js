@@ -0,0 +1,59 @@
+const {AppSubUrl} = window.config;
+
+// {repo link}/src/commit/{sha}/{path}
+const permalinkPattern = /^(\/[^/]+\/[^/]+)\/src\/commit\/([0-9a-f]{40})\/(.+)$/;
+
+function lineRange(hash) {
+  const m = hash.match(/^#L(\d+)(?:-L(\d+))?$/);
+  if (!m) return null;
+  return {start: Number(m[1]), end: Number(m[2] || m[1])};
+}
+
+function embedCode(url, range) {
+  const lines = range ? range.end - range.start + 1 : 20;
+  // header plus one row per line, as in the oEmbed response
+  const height = 60 + 20 * Math.min(lines, 200);
+  const src = range ? `${url}&lines=${range.start}-${range.end}` : url;
+  return `<iframe src="${src.replace(/&/g, '&amp;')}" width="800" height="${height}" frameborder="0"></iframe>`;
+}
+
+// Keeps the permalink and embed buttons of the file view in sync with the
+// selected lines.
+export function updateSnippetLinks() {
+  const range = lineRange(window.location.hash);
+
+  const permalink = document.getElementById('permalink-button');
+  if (permalink) {
+    permalink.href = permalink.href.replace(/#.*$/, '') + (range ? window.location.hash : '');
+  }
+
+  const embed = document.getElementById('embed-snippet-button');
+  if (embed) {
+    // the blob is used when the commit is no longer available
+    embed.dataset.clipboardText = embedCode(`${embed.dataset.url}?blob=${embed.dataset.blob}`, range);
+  }
+}
+
+async function embedSnippet(link) {
+  const url = new URL(link.href);
+  if (url.origin !== window.location.origin || !url.pathname.startsWith(`${AppSubUrl}/`)) return;
+  const m = url.pathname.substring(AppSubUrl.length).match(permalinkPattern);
+  const range = lineRange(url.hash);
+  if (!m || !range) return;
+
+  const [, repoLink, sha, path] = m;
+  const res = await fetch(`${AppSubUrl}${repoLink}/embed/commit/${sha}/${path}?lines=${range.start}-${range.end}&inline=true`);
+  if (!res.ok) return;
+  link.parentElement.outerHTML = await res.text();
+}
+
+// Replaces permalinks to line ranges which stand alone in a paragraph of
+// rendered markdown with the highlighted lines.
+export default async function initCodeSnippets() {
+  updateSnippetLinks();
+
+  const links = Array.from(document.querySelectorAll('.markdown p > a[href]')).filter((link) => {
+    return link.parentElement.childNodes.length === 1 && link.textContent === link.href;
+  });
+  await Promise.all(links.map((link) => embedSnippet(link).catch(() => {})));
+}
@@ -0,0 +1,164 @@
+import {svg} from '../svg.js';
+
+const {AppSubUrl, csrf} = window.config;
+
+// the command palette is opened with ctrl+k or cmd+k, the results are navigated with the
+// arrow keys and chosen with enter, escape closes it and gives the focus back
+export default function initCommandPalette() {
+  const palette = document.getElementById('command-palette');
+  if (!palette) return;
+
+  const input = document.getElementById('command-palette-input');
+  const list = document.getElementById('command-palette-results');
+  const status = palette.querySelector('.command-palette-status');
+  let items = [];
+  let active = -1;
+  let lastFocus = null;
+  let searchTimeout = null;
+  let searchCounter = 0;
+
+  const setActive = (index) => {
+    const options = list.querySelectorAll('[role="option"]');
+    if (!options.length) {
+      active = -1;
+      input.removeAttribute('aria-activedescendant');
+      return;
+    }
+    active = (index + options.length) % options.length;
+    for (const [i, option] of options.entries()) {
+      option.setAttribute('aria-selected', String(i === active));
+      option.classList.toggle('active', i === active);
+    }
+    input.setAttribute('aria-activedescendant', options[active].id);
+    options[active].scrollIntoView({block: 'nearest'});
+  };
+
+  const render = () => {
+    list.textContent = '';
+    for (const [i, item] of items.entries()) {
+      const option = document.createElement('li');
+      option.id = `command-palette-item-${i}`;
+      option.setAttribute('role', 'option');
+      option.setAttribute('aria-selected', 'false');
+      option.innerHTML = svg(item.icon) || '';
+      const title = document.createElement('span');
+      title.className = 'title';
+      title.textContent = item.title;
+      option.append(title);
+      if (item.description) {
+        const description = document.createElement('span');
+        description.className = 'description';
+        description.textContent = item.description;
+        option.append(description);
+      }
+      option.addEventListener('mousedown', (e) => {
+        e.preventDefault();
+        choose(i);
+      });
+      list.append(option);
+    }
+    input.setAttribute('aria-expanded', String(items.length > 0));
+    status.textContent = items.length ?
+      status.dataset.results.replace('%d', items.length) :
+      status.dataset.noResults;
+    setActive(0);
+  };
+
+  const search = async () => {
+    const counter = ++searchCounter;
+    const params = new URLSearchParams({q: input.value, limit: 10});
+    if (palette.dataset.repo) params.set('repo', palette.dataset.repo);
+    try {
+      const res = await fetch(`${AppSubUrl}/api/v1/user/quick_actions?${params}`, {
+        headers: {'X-Csrf-Token': csrf},
+      });
+      if (!res.ok || counter !== searchCounter) return;
+      items = await res.json();
+      render();
+    } catch (error) {
+      console.error(error);
+    }
+  };
+
+  const close = () => {
+    palette.classList.add('hide');
+    input.setAttribute('aria-expanded', 'false');
+    if (lastFocus) lastFocus.focus();
+  };
+
+  const open = () => {
+    lastFocus = document.activeElement;
+    palette.classList.remove('hide');
+    input.value = '';
+    input.focus();
+    search();
+  };
+
+  async function choose(index) {
+    const item = items[index];
+    if (!item) return;
+    try {
+      await fetch(`${AppSubUrl}/api/v1/user/quick_actions/recent`, {
+        method: 'POST',
+        headers: {'Content-Type': 'application/json', 'X-Csrf-Token': csrf},
+        body: JSON.stringify({key: item.key}),
+      });
+    } catch (error) {
+      console.error(error);
+    }
+    window.location.href = item.url;
+  }
+
+  document.addEventListener('keydown', (e) => {
+    if ((e.ctrlKey || e.metaKey) && e.key.toLowerCase() === 'k') {
+      e.preventDefault();
+      if (palette.classList.contains('hide')) {
+        open();
+      } else {
+        close();
+      }
+    }
+  });
+
+  for (const button of document.querySelectorAll('.js-command-palette')) {
+    button.addEventListener('click', (e) => {
+      e.preventDefault();
+      open();
+    });
+  }
+
+  input.addEventListener('input', () => {
+    clearTimeout(searchTimeout);
+    searchTimeout = setTimeout(search, 200);
+  });
+
+  input.addEventListener('keydown', (e) => {
+    switch (e.key) {
+      case 'ArrowDown':
+        e.preventDefault();
+        setActive(active + 1);
+        break;
+      case 'ArrowUp':
+        e.preventDefault();
+        setActive(active - 1);
+        break;
+      case 'Enter':
+        e.preventDefault();
+        choose(active);
+        break;
+      case 'Escape':
+        e.preventDefault();
+        close();
+        break;
+      case 'Tab':
+        // the input is the only focusable element of the dialog
+        e.preventDefault();
+        break;
+    }
+  });
+
+  // clicking outside of the dialog closes it
+  palette.addEventListener('mousedown', (e) => {
+    if (e.target === palette) close();
+  });
+}
@@ -0,0 +1,108 @@
+import {svg} from '../svg.js';
+
+const {AppSubUrl, csrf} = window.config;
+
+async function loadWidget(widget) {
+  const content = widget.querySelector('.dashboard-widget-content');
+  try {
+    const res = await fetch(`${AppSubUrl}/api/v1/user/dashboard/widgets/${widget.dataset.widget}`, {
+      headers: {'X-Csrf-Token': csrf},
+    });
+    if (!res.ok) throw new Error(`widget ${widget.dataset.widget}: ${res.status}`);
+    const {entries} = await res.json();
+
+    content.textContent = '';
+    if (!entries.length) {
+      content.textContent = content.dataset.empty;
+      return;
+    }
+    const list = document.createElement('ul');
+    list.className = 'dashboard-widget-entries';
+    for (const entry of entries) {
+      const item = document.createElement('li');
+      if (entry.state) item.dataset.state = entry.state;
+      item.innerHTML = svg(entry.icon);
+      const link = document.createElement('a');
+      link.href = entry.url;
+      link.textContent = entry.title;
+      item.append(link);
+      const description = document.createElement('span');
+      description.className = 'description';
+      description.textContent = entry.description;
+      item.append(description);
+      const time = document.createElement('time');
+      time.dateTime = entry.time;
+      time.title = new Date(entry.time).toLocaleString();
+      time.textContent = new Date(entry.time).toLocaleDateString();
+      item.append(time);
+      list.append(item);
+    }
+    content.append(list);
+  } catch (error) {
+    console.error(error);
+    content.textContent = content.dataset.error;
+  } finally {
+    content.setAttribute('aria-busy', 'false');
+  }
+}
+
+// saveWidgets stores the widgets of the dashboard in the order they are shown
+async function saveWidgets(container, widgets) {
+  const res = await fetch(`${AppSubUrl}/api/v1/user/dashboard`, {
+    method: 'PUT',
+    headers: {'Content-Type': 'application/json', 'X-Csrf-Token': csrf},
+    body: JSON.stringify({widgets}),
+  });
+  if (!res.ok) {
+    window.alert(container.dataset.saveError);
+    return false;
+  }
+  return true;
+}
+
+function shownWidgets(container) {
+  return Array.from(container.querySelectorAll('.dashboard-widget'), (widget) => widget.dataset.widget);
+}
+
+export default function initDashboardWidgets() {
+  const container = document.getElementById('dashboard-widgets');
+  if (!container) return;
+
+  for (const widget of container.querySelectorAll('.dashboard-widget')) {
+    loadWidget(widget);
+  }
+
+  container.addEventListener('click', async (e) => {
+    const button = e.target.closest('.js-widget-move, .js-widget-remove');
+    if (!button) return;
+    e.preventDefault();
+    const widget = button.closest('.dashboard-widget');
+
+    if (button.classList.contains('js-widget-remove')) {
+      widget.remove();
+      if (await saveWidgets(container, shownWidgets(container))) window.location.reload();
+      return;
+    }
+
+    const sibling = button.dataset.direction === '-1' ?
+      widget.previousElementSibling :
+      widget.nextElementSibling;
+    if (!sibling || !sibling.classList.contains('dashboard-widget')) return;
+    if (button.dataset.direction === '-1') {
+      sibling.before(widget);
+    } else {
+      sibling.after(widget);
+    }
+    button.focus();
+    await saveWidgets(container, shownWidgets(container));
+  });
+
+  const form = container.querySelector('.dashboard-widgets-add');
+  if (form) {
+    form.addEventListener('submit', async (e) => {
+      e.preventDefault();
+      const widgets = [...shownWidgets(container), form.elements.widget.value];
+      if (await saveWidgets(container, widgets)) window.location.reload();
+    });
+  }
+}
@@ -1,8 +1,11 @@
 import emojis from '../../../assets/emoji.json';
 
-const {StaticUrlPrefix} = window.config;
+const {StaticUrlPrefix, CustomEmojis} = window.config;
 
 const tempMap = {gitea: ':gitea:'};
+for (const name of Object.keys(CustomEmojis || {})) {
+  tempMap[name] = `:${name}:`;
+}
 for (const {emoji, aliases} of emojis) {
   for (const alias of aliases || []) {
     tempMap[alias] = emoji;
@@ -25,6 +28,8 @@ export function emojiHTML(name) {
   let inner;
   if (name === 'gitea') {
     inner = `<img class="emoji" alt=":${name}:" src="${StaticUrlPrefix}/img/emoji/gitea.png" align="absmiddle">`;
+  } else if (CustomEmojis && CustomEmojis[name]) {
+    inner = `<img class="emoji" alt=":${name}:" src="${CustomEmojis[name]}" align="absmiddle">`;
   } else {
     inner = emojiString(name);
   }
@@ -0,0 +1,393 @@
+// Previews of files which are not rendered on the server: 3D models (STL, OBJ)
+// are drawn on a canvas and GeoJSON/TopoJSON maps are drawn as SVG.
+
+const SVG_NS = 'http://www.w3.org/2000/svg';
+
+// parseSTL parses a binary or ASCII STL file into a list of triangles
+function parseSTL(buffer) {
+  const view = new DataView(buffer);
+  if (buffer.byteLength >= 84) {
+    const count = view.getUint32(80, true);
+    if (buffer.byteLength === 84 + count * 50) {
+      const triangles = [];
+      for (let i = 0; i < count; i++) {
+        const offset = 84 + i * 50 + 12; // skip the normal
+        const triangle = [];
+        for (let v = 0; v < 3; v++) {
+          const o = offset + v * 12;
+          triangle.push([view.getFloat32(o, true), view.getFloat32(o + 4, true), view.getFloat32(o + 8, true)]);
+        }
+        triangles.push(triangle);
+      }
+      return triangles;
+    }
+  }
+
+  const text = new TextDecoder().decode(buffer);
+  const triangles = [];
+  let triangle = [];
+  for (const match of text.matchAll(/vertex\s+(\S+)\s+(\S+)\s+(\S+)/g)) {
+    triangle.push([Number(match[1]), Number(match[2]), Number(match[3])]);
+    if (triangle.length === 3) {
+      triangles.push(triangle);
+      triangle = [];
+    }
+  }
+  return triangles;
+}
+
+// parseOBJ parses the vertices and faces of a Wavefront OBJ file into a list of
+// triangles, polygons are triangulated as fans
+function parseOBJ(buffer) {
+  const text = new TextDecoder().decode(buffer);
+  const vertices = [];
+  const triangles = [];
+  for (const line of text.split('\n')) {
+    const parts = line.trim().split(/\s+/);
+    if (parts[0] === 'v') {
+      vertices.push([Number(parts[1]), Number(parts[2]), Number(parts[3])]);
+    } else if (parts[0] === 'f') {
+      const face = parts.slice(1).map((part) => {
+        const index = parseInt(part.split('/')[0]);
+        return vertices[index < 0 ? vertices.length + index : index - 1];
+      }).filter((vertex) => vertex !== undefined);
+      for (let i = 1; i + 1 < face.length; i++) {
+        triangles.push([face[0], face[i], face[i + 1]]);
+      }
+    }
+  }
+  return triangles;
+}
+
+function sub(a, b) {
+  return [a[0] - b[0], a[1] - b[1], a[2] - b[2]];
+}
+
+function cross(a, b) {
+  return [a[1] * b[2] - a[2] * b[1], a[2] * b[0] - a[0] * b[2], a[0] * b[1] - a[1] * b[0]];
+}
+
+// ModelViewer draws triangles on a canvas, sorted back to front and shaded by
+// the angle between their normal and the view direction
+class ModelViewer {
+  constructor(canvas, triangles) {
+    this.canvas = canvas;
+    this.ctx = canvas.getContext('2d');
+    this.triangles = triangles;
+    this.yaw = -Math.PI / 4;
+    this.pitch = -Math.PI / 6;
+    this.zoom = 1;
+
+    const min = [Infinity, Infinity, Infinity];
+    const max = [-Infinity, -Infinity, -Infinity];
+    for (const triangle of triangles) {
+      for (const vertex of triangle) {
+        for (let i = 0; i < 3; i++) {
+          min[i] = Math.min(min[i], vertex[i]);
+          max[i] = Math.max(max[i], vertex[i]);
+        }
+      }
+    }
+    this.center = [(min[0] + max[0]) / 2, (min[1] + max[1]) / 2, (min[2] + max[2]) / 2];
+    this.radius = Math.max(Math.hypot(max[0] - min[0], max[1] - min[1], max[2] - min[2]) / 2, 1e-9);
+  }
+
+  project(vertex) {
+    const [x0, y0, z0] = sub(vertex, this.center);
+    // rotate around the vertical axis, then around the horizontal axis
+    const x1 = x0 * Math.cos(this.yaw) - y0 * Math.sin(this.yaw);
+    const y1 = x0 * Math.sin(this.yaw) + y0 * Math.cos(this.yaw);
+    const y2 = y1 * Math.cos(this.pitch) - z0 * Math.sin(this.pitch);
+    const z2 = y1 * Math.sin(this.pitch) + z0 * Math.cos(this.pitch);
+    return [x1, z2, y2];
+  }
+
+  draw() {
+    const ratio = window.devicePixelRatio || 1;
+    const width = this.canvas.clientWidth;
+    const height = this.canvas.clientHeight;
+    this.canvas.width = width * ratio;
+    this.canvas.height = height * ratio;
+
+    const ctx = this.ctx;
+    ctx.setTransform(ratio, 0, 0, ratio, 0, 0);
+    ctx.clearRect(0, 0, width, height);
+
+    const scale = this.zoom * Math.min(width, height) / 2 / this.radius;
+    const faces = this.triangles.map((triangle) => {
+      const points = triangle.map((vertex) => this.project(vertex));
+      const normal = cross(sub(points[1], points[0]), sub(points[2], points[0]));
+      const length = Math.hypot(...normal) || 1;
+      return {
+        points,
+        depth: (points[0][2] + points[1][2] + points[2][2]) / 3,
+        light: Math.abs(normal[2]) / length,
+      };
+    });
+    faces.sort((a, b) => b.depth - a.depth);
+
+    for (const face of faces) {
+      const shade = Math.round(80 + 150 * face.light);
+      ctx.fillStyle = `rgb(${shade}, ${shade}, ${Math.min(shade + 25, 255)})`;
+      ctx.strokeStyle = ctx.fillStyle;
+      ctx.beginPath();
+      for (const [x, y] of face.points) {
+        ctx.lineTo(width / 2 + x * scale, height / 2 - y * scale);
+      }
+      ctx.closePath();
+      ctx.fill();
+      ctx.stroke();
+    }
+  }
+
+  attach() {
+    let last = null;
+    this.canvas.addEventListener('mousedown', (e) => {
+      last = [e.clientX, e.clientY];
+    });
+    document.addEventListener('mouseup', () => {
+      last = null;
+    });
+    document.addEventListener('mousemove', (e) => {
+      if (!last) return;
+      this.yaw += (e.clientX - last[0]) / 100;
+      this.pitch = Math.max(-Math.PI / 2, Math.min(Math.PI / 2, this.pitch + (e.clientY - last[1]) / 100));
+      last = [e.clientX, e.clientY];
+      window.requestAnimationFrame(() => this.draw());
+    });
+    this.canvas.addEventListener('wheel', (e) => {
+      e.preventDefault();
+      this.zoom = Math.max(0.1, Math.min(20, this.zoom * (e.deltaY < 0 ? 1.1 : 1 / 1.1)));
+      window.requestAnimationFrame(() => this.draw());
+    });
+  }
+}
+
+function previewModel(el, buffer, type) {
+  const triangles = type === 'obj' ? parseOBJ(buffer) : parseSTL(buffer);
+  if (!triangles.length) throw new Error('no triangles found');
+
+  const canvas = document.createElement('canvas');
+  canvas.className = 'model-preview';
+  el.append(canvas);
+  if (el.dataset.hint) {
+    const hint = document.createElement('div');
+    hint.className = 'text grey';
+    hint.textContent = el.dataset.hint;
+    el.append(hint);
+  }
+
+  const viewer = new ModelViewer(canvas, triangles);
+  viewer.attach();
+  viewer.draw();
+}
+
+// decodeTopoJSON converts the objects of a TopoJSON topology to GeoJSON features
+function decodeTopoJSON(topology) {
+  const transform = topology.transform;
+  const arcs = (topology.arcs || []).map((arc) => {
+    let x = 0, y = 0;
+    return arc.map((position) => {
+      if (!transform) return position;
+      x += position[0];
+      y += position[1];
+      return [x * transform.scale[0] + transform.translate[0], y * transform.scale[1] + transform.translate[1]];
+    });
+  });
+  const point = (position) => {
+    if (!transform) return position;
+    return [position[0] * transform.scale[0] + transform.translate[0], position[1] * transform.scale[1] + transform.translate[1]];
+  };
+  const line = (indexes) => {
+    const coordinates = [];
+    for (const index of indexes) {
+      const arc = index < 0 ? arcs[~index].slice().reverse() : arcs[index];
+      coordinates.push(...(coordinates.length ? arc.slice(1) : arc));
+    }
+    return coordinates;
+  };
+  const geometry = (object) => {
+    switch (object.type) {
+      case 'Point':
+        return {type: object.type, coordinates: point(object.coordinates)};
+      case 'MultiPoint':
+        return {type: object.type, coordinates: object.coordinates.map(point)};
+      case 'LineString':
+        return {type: object.type, coordinates: line(object.arcs)};
+      case 'MultiLineString':
+      case 'Polygon':
+        return {type: object.type, coordinates: object.arcs.map(line)};
+      case 'MultiPolygon':
+        return {type: object.type, coordinates: object.arcs.map((polygon) => polygon.map(line))};
+      case 'GeometryCollection':
+        return {type: object.type, geometries: object.geometries.map(geometry)};
+      default:
+        return null;
+    }
+  };
+
+  const features = [];
+  for (const object of Object.values(topology.objects || {})) {
+    const objects = object.type === 'GeometryCollection' ? object.geometries : [object];
+    for (const o of objects) {
+      features.push({type: 'Feature', properties: o.properties || {}, geometry: geometry(o)});
+    }
+  }
+  return features;
+}
+
+function geoJSONFeatures(data) {
+  switch (data.type) {
+    case 'Topology':
+      return decodeTopoJSON(data);
+    case 'FeatureCollection':
+      return data.features || [];
+    case 'Feature':
+      return [data];
+    default:
+      return [{type: 'Feature', properties: {}, geometry: data}];
+  }
+}
+
+// mercator projects a longitude/latitude position, latitudes are clamped to
+// keep the poles finite
+function mercator([lon, lat]) {
+  const phi = Math.max(-85, Math.min(85, lat)) * Math.PI / 180;
+  return [lon * Math.PI / 180, -Math.log(Math.tan(Math.PI / 4 + phi / 2))];
+}
+
+// geometryShapes flattens a geometry to a list of projected shapes
+function geometryShapes(geometry, shapes = []) {
+  if (!geometry) return shapes;
+  switch (geometry.type) {
+    case 'Point':
+      shapes.push({kind: 'point', rings: [[mercator(geometry.coordinates)]]});
+      break;
+    case 'MultiPoint':
+      for (const position of geometry.coordinates) shapes.push({kind: 'point', rings: [[mercator(position)]]});
+      break;
+    case 'LineString':
+      shapes.push({kind: 'line', rings: [geometry.coordinates.map(mercator)]});
+      break;
+    case 'MultiLineString':
+      shapes.push({kind: 'line', rings: geometry.coordinates.map((ring) => ring.map(mercator))});
+      break;
+    case 'Polygon':
+      shapes.push({kind: 'polygon', rings: geometry.coordinates.map((ring) => ring.map(mercator))});
+      break;
+    case 'MultiPolygon':
+      for (const polygon of geometry.coordinates) {
+        shapes.push({kind: 'polygon', rings: polygon.map((ring) => ring.map(mercator))});
+      }
+      break;
+    case 'GeometryCollection':
+      for (const g of geometry.geometries || []) geometryShapes(g, shapes);
+      break;
+  }
+  return shapes;
+}
+
+function featureTitle(properties) {
+  if (!properties) return '';
+  const name = properties.name || properties.NAME || properties.title;
+  if (name) return String(name);
+  return Object.entries(properties).map(([key, value]) => `${key}: ${value}`).join('\n');
+}
+
+function previewGeoJSON(el, buffer) {
+  const features = geoJSONFeatures(JSON.parse(new TextDecoder().decode(buffer)));
+
+  const items = [];
+  const min = [Infinity, Infinity];
+  const max = [-Infinity, -Infinity];
+  for (const feature of features) {
+    const shapes = geometryShapes(feature.geometry);
+    for (const shape of shapes) {
+      for (const ring of shape.rings) {
+        for (const [x, y] of ring) {
+          min[0] = Math.min(min[0], x);
+          min[1] = Math.min(min[1], y);
+          max[0] = Math.max(max[0], x);
+          max[1] = Math.max(max[1], y);
+        }
+      }
+    }
+    items.push({shapes, title: featureTitle(feature.properties)});
+  }
+  if (min[0] > max[0]) throw new Error('no geometries found');
+
+  const width = Math.max(max[0] - min[0], 1e-6);
+  const height = Math.max(max[1] - min[1], 1e-6);
+  const size = Math.max(width, height);
+  const padding = size * 0.05;
+
+  const svg = document.createElementNS(SVG_NS, 'svg');
+  svg.setAttribute('class', 'geojson-preview');
+  svg.setAttribute('viewBox', `${min[0] - padding} ${min[1] - padding} ${width + 2 * padding} ${height + 2 * padding}`);
+  svg.setAttribute('preserveAspectRatio', 'xMidYMid meet');
+
+  const stroke = size / 400;
+  for (const item of items) {
+    const group = document.createElementNS(SVG_NS, 'g');
+    if (item.title) {
+      const title = document.createElementNS(SVG_NS, 'title');
+      title.textContent = item.title;
+      group.append(title);
+    }
+    for (const shape of item.shapes) {
+      let node;
+      if (shape.kind === 'point') {
+        node = document.createElementNS(SVG_NS, 'circle');
+        node.setAttribute('cx', shape.rings[0][0][0]);
+        node.setAttribute('cy', shape.rings[0][0][1]);
+        node.setAttribute('r', stroke * 3);
+      } else {
+        node = document.createElementNS(SVG_NS, 'path');
+        node.setAttribute('d', shape.rings.map((ring) => {
+          return `M${ring.map(([x, y]) => `${x},${y}`).join('L')}${shape.kind === 'polygon' ? 'Z' : ''}`;
+        }).join(''));
+      }
+      node.setAttribute('class', shape.kind);
+      node.setAttribute('stroke-width', stroke);
+      group.append(node);
+    }
+    svg.append(group);
+  }
+  el.append(svg);
+}
+
+async function previewFile(el) {
+  const response = await fetch(el.dataset.url);
+  if (!response.ok) throw new Error(`${response.status} ${response.statusText}`);
+  const buffer = await response.arrayBuffer();
+
+  switch (el.dataset.type) {
+    case 'model3d':
+      return previewModel(el, buffer, el.dataset.name.toLowerCase().endsWith('.obj') ? 'obj' : 'stl');
+    case 'geojson':
+      return previewGeoJSON(el, buffer);
+    default:
+      throw new Error(`unknown preview type ${el.dataset.type}`);
+  }
+}
+
+export default async function initFilePreview() {
+  for (const el of document.querySelectorAll('.file-preview')) {
+    try {
+      await previewFile(el);
+    } catch (err) {
+      console.error(err);
+      const link = document.createElement('a');
+      link.href = el.dataset.url;
+      link.rel = 'nofollow';
+      link.className = 'ui basic button';
+      link.textContent = el.dataset.raw;
+      const message = document.createElement('p');
+      message.textContent = el.dataset.failed;
+      el.append(message, link);
+    } finally {
+      const loader = el.querySelector('.loader');
+      if (loader) loader.remove();
+    }
+  }
+}
@@ -0,0 +1,205 @@
+const {csrf} = window.config;
+
+const svgNS = 'http://www.w3.org/2000/svg';
+const edgeTypes = ['blocks', 'duplicates', 'references', 'closes'];
+const nodeRadius = 18;
+
+function createSvgElement(name, attrs = {}) {
+  const el = document.createElementNS(svgNS, name);
+  for (const [key, value] of Object.entries(attrs)) {
+    el.setAttribute(key, value);
+  }
+  return el;
+}
+
+// layoutGraph places the root issue in the center and the other issues on
+// circles around it, one circle per distance to the root.
+function layoutGraph(graph, width, height) {
+  const neighbours = new Map(graph.nodes.map((node) => [node.id, []]));
+  for (const edge of graph.edges) {
+    neighbours.get(edge.from).push(edge.to);
+    neighbours.get(edge.to).push(edge.from);
+  }
+
+  const levels = new Map([[graph.root, 0]]);
+  const queue = [graph.root];
+  while (queue.length) {
+    const id = queue.shift();
+    for (const neighbour of neighbours.get(id)) {
+      if (levels.has(neighbour)) continue;
+      levels.set(neighbour, levels.get(id) + 1);
+      queue.push(neighbour);
+    }
+  }
+
+  const rings = [];
+  for (const node of graph.nodes) {
+    const level = levels.has(node.id) ? levels.get(node.id) : 1;
+    if (!rings[level]) rings[level] = [];
+    rings[level].push(node);
+  }
+
+  const positions = new Map();
+  const step = (Math.min(width, height) / 2 - nodeRadius * 2) / Math.max(rings.length - 1, 1);
+  for (const [level, ring] of rings.entries()) {
+    if (!ring) continue;
+    for (const [i, node] of ring.entries()) {
+      // shift each circle a bit so that the issues of consecutive circles are not aligned
+      const angle = (2 * Math.PI * i) / ring.length + level * 0.5;
+      positions.set(node.id, {
+        x: width / 2 + Math.cos(angle) * step * level,
+        y: height / 2 + Math.sin(angle) * step * level,
+      });
+    }
+  }
+  return positions;
+}
+
+// edgeEnds returns the ends of the line of an edge, on the border of the
+// circles of the issues so that the arrows are visible.
+function edgeEnds(from, to) {
+  const dx = to.x - from.x;
+  const dy = to.y - from.y;
+  const length = Math.hypot(dx, dy) || 1;
+  const ox = (dx / length) * nodeRadius;
+  const oy = (dy / length) * nodeRadius;
+  return {x1: from.x + ox, y1: from.y + oy, x2: to.x - ox, y2: to.y - oy};
+}
+
+function renderGraph(container, graph) {
+  const width = container.clientWidth || 800;
+  const height = Math.max(400, Math.min(width, 700));
+  const positions = layoutGraph(graph, width, height);
+  const rootNode = graph.nodes.find((node) => node.id === graph.root);
+
+  const svg = createSvgElement('svg', {width, height, viewBox: `0 0 ${width} ${height}`, class: 'issue-graph-diagram'});
+  const defs = createSvgElement('defs');
+  for (const type of edgeTypes) {
+    const marker = createSvgElement('marker', {
+      id: `issue-graph-arrow-${type}`, class: `arrow ${type}`,
+      viewBox: '0 0 10 10', refX: 10, refY: 5, markerWidth: 8, markerHeight: 8, orient: 'auto-start-reverse',
+    });
+    marker.append(createSvgElement('path', {d: 'M 0 0 L 10 5 L 0 10 z'}));
+    defs.append(marker);
+  }
+  svg.append(defs);
+
+  const edges = graph.edges.map((edge) => {
+    const line = createSvgElement('line', {class: `edge ${edge.type}`, 'marker-end': `url(#issue-graph-arrow-${edge.type})`});
+    const title = createSvgElement('title');
+    title.textContent = container.dataset[`label${edge.type[0].toUpperCase()}${edge.type.slice(1)}`];
+    line.append(title);
+    svg.append(line);
+    return {edge, line};
+  });
+  const updateEdges = () => {
+    for (const {edge, line} of edges) {
+      for (const [key, value] of Object.entries(edgeEnds(positions.get(edge.from), positions.get(edge.to)))) {
+        line.setAttribute(key, value);
+      }
+    }
+  };
+  updateEdges();
+
+  for (const node of graph.nodes) {
+    const sameRepo = node.repository.id === rootNode.repository.id;
+    const group = createSvgElement('g', {
+      class: `node ${node.state}${node.is_pull ? ' pull' : ''}${node.id === graph.root ? ' root' : ''}`,
+      tabindex: 0,
+    });
+    group.append(createSvgElement('circle', {r: nodeRadius}));
+    const label = createSvgElement('text', {'text-anchor': 'middle', dy: nodeRadius + 14});
+    label.textContent = `${sameRepo ? '' : node.repository.full_name}#${node.number}`;
+    group.append(label);
+    const title = createSvgElement('title');
+    title.textContent = `${node.repository.full_name}#${node.number} ${node.title}`;
+    group.append(title);
+    const move = () => {
+      const {x, y} = positions.get(node.id);
+      group.setAttribute('transform', `translate(${x},${y})`);
+    };
+    move();
+
+    // the issues can be dragged to untangle the graph, a click opens the issue
+    let dragged = false;
+    group.addEventListener('pointerdown', (e) => {
+      dragged = false;
+      group.setPointerCapture(e.pointerId);
+      const start = {x: e.clientX, y: e.clientY, position: {...positions.get(node.id)}};
+      const onMove = (e) => {
+        const dx = e.clientX - start.x;
+        const dy = e.clientY - start.y;
+        if (Math.hypot(dx, dy) > 3) dragged = true;
+        positions.set(node.id, {x: start.position.x + dx, y: start.position.y + dy});
+        move();
+        updateEdges();
+      };
+      const onUp = () => {
+        group.removeEventListener('pointermove', onMove);
+        group.removeEventListener('pointerup', onUp);
+      };
+      group.addEventListener('pointermove', onMove);
+      group.addEventListener('pointerup', onUp);
+    });
+    const open = () => {
+      if (!dragged) window.location.href = node.html_url;
+    };
+    group.addEventListener('click', open);
+    group.addEventListener('keydown', (e) => {
+      if (e.key === 'Enter') open();
+    });
+
+    // hovering an issue highlights its links
+    group.addEventListener('mouseenter', () => {
+      svg.classList.add('focused');
+      for (const {edge, line} of edges) {
+        line.classList.toggle('highlighted', edge.from === node.id || edge.to === node.id);
+      }
+    });
+    group.addEventListener('mouseleave', () => {
+      svg.classList.remove('focused');
+    });
+    svg.append(group);
+  }
+
+  container.textContent = '';
+  container.append(svg);
+  if (graph.truncated) {
+    const message = document.createElement('div');
+    message.className = 'ui warning message';
+    message.textContent = container.dataset.truncatedMessage;
+    container.append(message);
+  }
+}
+
+async function loadIssueGraph(container, depth) {
+  container.classList.add('loading');
+  try {
+    const res = await fetch(`${container.dataset.url}?depth=${depth}`, {
+      headers: {'X-Csrf-Token': csrf},
+    });
+    if (!res.ok) throw new Error(res.statusText);
+    renderGraph(container, await res.json());
+  } catch {
+    container.innerHTML = `<div class="ui error message">${container.dataset.failedMessage}</div>`;
+  } finally {
+    container.classList.remove('loading');
+  }
+}
+
+// The reference graph of an issue is loaded from the API and rendered as an
+// SVG diagram, the depth buttons reload it with more or less issues.
+export default async function initIssueGraph() {
+  const container = document.getElementById('issue-graph');
+  if (!container) return;
+
+  const buttons = document.querySelectorAll('.issue-graph-depth .button');
+  for (const button of buttons) {
+    button.addEventListener('click', (e) => {
+      e.preventDefault();
+      for (const other of buttons) other.classList.toggle('active', other === button);
+      loadIssueGraph(container, button.dataset.depth);
+    });
+  }
+  await loadIssueGraph(container, container.dataset.depth);
+}
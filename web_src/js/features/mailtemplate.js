@@ -0,0 +1,54 @@
+// initAdminMailTemplate renders the mail template being edited with sample data, as a preview
+// or as a test mail sent to the administrator.
+export default function initAdminMailTemplate() {
+  const form = document.getElementById('mail-template-form');
+  if (!form) return;
+
+  const result = document.getElementById('mail-template-result');
+  const message = result.querySelector('.message');
+  const preview = result.querySelector('.mail-template-preview');
+
+  const showMessage = (text, isError) => {
+    message.textContent = text;
+    message.classList.toggle('negative', isError);
+    message.classList.toggle('positive', !isError);
+    message.classList.remove('hide');
+  };
+
+  const post = async (url, button) => {
+    button.classList.add('loading');
+    result.classList.remove('hide');
+    try {
+      const res = await fetch(url, {method: 'POST', body: new FormData(form)});
+      const data = await res.json();
+      if (!res.ok) {
+        showMessage(data.error, true);
+        return null;
+      }
+      return data;
+    } catch (error) {
+      showMessage(error.message, true);
+      return null;
+    } finally {
+      button.classList.remove('loading');
+    }
+  };
+
+  document.getElementById('mail-template-preview').addEventListener('click', async (e) => {
+    const data = await post(form.dataset.previewUrl, e.currentTarget);
+    if (!data) {
+      preview.classList.add('hide');
+      return;
+    }
+    message.classList.add('hide');
+    preview.querySelector('.subject').textContent = data.subject;
+    preview.querySelector('.body').srcdoc = data.body;
+    preview.querySelector('.plain-body').textContent = data.plain_body;
+    preview.classList.remove('hide');
+  });
+
+  document.getElementById('mail-template-test').addEventListener('click', async (e) => {
+    const data = await post(form.dataset.testUrl, e.currentTarget);
+    if (data) showMessage(data.message, false);
+  });
+}
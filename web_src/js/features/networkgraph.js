@@ -0,0 +1,33 @@
+import initGitGraph from './gitgraph.js';
+
+// loadNetworkGraph replaces the content of the container with the graph of the
+// url, whose buttons load more forks or older commits in the same way.
+async function loadNetworkGraph(container, url) {
+  container.classList.add('loading');
+  try {
+    const res = await fetch(url);
+    if (!res.ok) throw new Error(res.statusText);
+    container.innerHTML = await res.text();
+  } catch {
+    container.innerHTML = `<div class="ui error message">${container.dataset.failedMessage}</div>`;
+    return;
+  } finally {
+    container.classList.remove('loading');
+  }
+
+  await initGitGraph();
+  for (const button of container.querySelectorAll('.load-more')) {
+    button.addEventListener('click', (e) => {
+      e.preventDefault();
+      loadNetworkGraph(container, button.dataset.url);
+    });
+  }
+}
+
+// The graph of the fork network is loaded after the page as gathering the
+// branches of all forks can take a while for large networks.
+export default async function initNetworkGraph() {
+  const container = document.getElementById('network-graph');
+  if (!container) return;
+  await loadNetworkGraph(container, container.dataset.url);
+}
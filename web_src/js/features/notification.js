@@ -165,13 +165,15 @@ async function updateNotificationCount() {
 }
 
 async function updateNotification(url, status, page, q, notificationID) {
-  if (status !== 'pinned') {
+  if (status === 'read' || status === 'unread') {
     $(`#notification_${notificationID}`).remove();
   }
 
+  // keep the filters of the list when it is rendered again
+  const params = $('#notification_div').data('params');
   return $.ajax({
     type: 'POST',
-    url,
+    url: params ? `${url}?${params}` : url,
     data: {
       _csrf: csrf,
       notification_id: notificationID,
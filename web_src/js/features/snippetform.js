@@ -0,0 +1,23 @@
+// The form of a snippet holds one block per file, new blocks are copied from
+// the template of the form and any block but the last one can be removed.
+export default function initSnippetForm() {
+  const form = document.getElementById('snippet-form');
+  if (!form) return;
+
+  const files = form.querySelector('.snippet-files');
+  const template = document.getElementById('snippet-file-template');
+
+  form.querySelector('.add-snippet-file').addEventListener('click', (e) => {
+    e.preventDefault();
+    files.appendChild(template.content.cloneNode(true));
+  });
+
+  files.addEventListener('click', (e) => {
+    const button = e.target.closest('.remove-snippet-file');
+    if (!button) return;
+    e.preventDefault();
+    if (files.querySelectorAll('.snippet-file').length > 1) {
+      button.closest('.snippet-file').remove();
+    }
+  });
+}
@@ -1,5 +1,24 @@
 import {emojiKeys, emojiHTML, emojiString} from './emoji.js';
-import {uniq} from '../utils.js';
+import {htmlEscape, uniq} from '../utils.js';
+
+const autocompleteCache = new Map();
+
+// autocomplete fetches the users or issues of the repository matching the query, the
+// suggestions of each query are only fetched once per page
+async function autocomplete(kind, query, cb) {
+  const url = `${window.config.autocompleteUrl}/${kind}?q=${encodeURIComponent(query)}`;
+  try {
+    if (!autocompleteCache.has(url)) {
+      const res = await fetch(url, {headers: {'X-Csrf-Token': window.config.csrf}});
+      if (!res.ok) throw new Error(`autocomplete ${kind}: ${res.status}`);
+      autocompleteCache.set(url, await res.json());
+    }
+    cb(autocompleteCache.get(url));
+  } catch (error) {
+    console.error(error);
+    cb([]);
+  }
+}
 
 function makeCollections({mentions, emoji}) {
   const collections = [];
@@ -29,16 +48,39 @@ function makeCollections({mentions, emoji}) {
     });
   }
 
-  if (emoji) {
+  if (emoji && window.config.autocompleteUrl) {
     collections.push({
-      values: window.config.tributeValues,
+      values: (query, cb) => autocomplete('mentions', query, cb),
+      lookup: (item) => `${item.username} ${item.full_name}`,
+      fillAttr: 'username',
+      noMatchTemplate: () => null,
+      menuItemTemplate: (item) => {
+        const {username, full_name, avatar_url} = item.original;
+        return `
+          <div class="tribute-item">
+            <img src="${htmlEscape(avatar_url)}"/>
+            <span class="name">${htmlEscape(username)}</span>
+            ${full_name ? `<span class="fullname">${htmlEscape(full_name)}</span>` : ''}
+          </div>
+        `;
+      }
+    });
+
+    collections.push({
+      trigger: '#',
+      values: (query, cb) => autocomplete('issues', query, cb),
+      lookup: (item) => `${item.number} ${item.title}`,
+      selectTemplate: (item) => {
+        if (typeof item === 'undefined') return null;
+        return `#${item.original.number}`;
+      },
       noMatchTemplate: () => null,
       menuItemTemplate: (item) => {
+        const {number, title} = item.original;
         return `
           <div class="tribute-item">
-            <img src="${item.original.avatar}"/>
-            <span class="name">${item.original.name}</span>
-            ${item.original.fullname && item.original.fullname !== '' ? `<span class="fullname">${item.original.fullname}</span>` : ''}
+            <span class="name">#${number}</span>
+            <span class="fullname">${htmlEscape(title)}</span>
           </div>
         `;
       }
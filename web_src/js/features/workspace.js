@@ -0,0 +1,293 @@
+import {initLanguages, getLanguage} from './codeeditor.js';
+import {extname, isDarkTheme} from '../utils.js';
+
+const {csrf} = window.config;
+
+// delay after the last keystroke before a change is saved on the server
+const saveDelay = 1000;
+
+function escapePath(path) {
+  return path.split('/').map(encodeURIComponent).join('/');
+}
+
+// buildTree nests the flat list of files of the workspace by directory
+function buildTree(files) {
+  const root = {dirs: {}, files: []};
+  for (const file of files) {
+    const parts = file.path.split('/');
+    let node = root;
+    for (const dir of parts.slice(0, -1)) {
+      if (!node.dirs[dir]) node.dirs[dir] = {dirs: {}, files: []};
+      node = node.dirs[dir];
+    }
+    node.files.push({name: parts[parts.length - 1], ...file});
+  }
+  return root;
+}
+
+// The web IDE workspace edits several files of a branch in tabs, every change is saved
+// on the server so that all changes can be committed at once later.
+export default async function initWorkspace() {
+  const workspace = document.getElementById('workspace');
+  if (!workspace) return;
+
+  const {filesLink, fileLink, deleteLink, discardLink, lineWrapExtensions} = workspace.dataset;
+  const i18n = {
+    noChanges: workspace.dataset.textNoChanges,
+    newFilePrompt: workspace.dataset.textNewFilePrompt,
+    notEditable: workspace.dataset.textNotEditable,
+    saving: workspace.dataset.textSaving,
+    saved: workspace.dataset.textSaved,
+    saveFailed: workspace.dataset.textSaveFailed,
+    status: {
+      added: workspace.dataset.textAdded,
+      modified: workspace.dataset.textModified,
+      deleted: workspace.dataset.textDeleted,
+    },
+  };
+  const treeContainer = workspace.querySelector('.workspace-tree');
+  const tabs = workspace.querySelector('.workspace-tabs');
+  const container = workspace.querySelector('.workspace-monaco');
+  const placeholder = workspace.querySelector('.workspace-placeholder');
+  const toolbar = workspace.querySelector('.workspace-toolbar');
+  const saveStatus = toolbar.querySelector('.workspace-save-status');
+  const changesList = document.querySelector('.workspace-changes');
+  const lineWrapExts = (lineWrapExtensions || '').split(',');
+
+  const monaco = await import(/* webpackChunkName: "monaco" */'monaco-editor');
+  initLanguages(monaco);
+  const editor = monaco.editor.create(container, {
+    theme: isDarkTheme() ? 'vs-dark' : 'vs',
+    model: null,
+  });
+  window.addEventListener('resize', () => editor.layout());
+
+  const opened = new Map(); // path -> {model, editable, timer, saving}
+  let files = [];
+  let active = null;
+
+  function renderChanges() {
+    const changed = files.filter((f) => f.status);
+    changesList.innerHTML = '';
+    if (!changed.length) {
+      changesList.innerHTML = `<div class="item">${i18n.noChanges}</div>`;
+      return;
+    }
+    for (const file of changed) {
+      const item = document.createElement('a');
+      item.className = 'item';
+      item.innerHTML = `<span class="ui mini basic label">${i18n.status[file.status]}</span> `;
+      item.append(file.path);
+      item.addEventListener('click', () => openFile(file.path));
+      changesList.appendChild(item);
+    }
+  }
+
+  function renderNode(node, parent) {
+    const list = document.createElement('ul');
+    for (const name of Object.keys(node.dirs).sort()) {
+      const li = document.createElement('li');
+      const details = document.createElement('details');
+      const summary = document.createElement('summary');
+      summary.textContent = name;
+      details.appendChild(summary);
+      renderNode(node.dirs[name], details);
+      li.appendChild(details);
+      list.appendChild(li);
+    }
+    for (const file of node.files.sort((a, b) => a.name.localeCompare(b.name))) {
+      const li = document.createElement('li');
+      const link = document.createElement('a');
+      link.textContent = file.name;
+      link.title = file.path;
+      link.dataset.path = file.path;
+      if (file.status) link.classList.add(`status-${file.status}`);
+      if (file.path === active) link.classList.add('active');
+      link.addEventListener('click', () => openFile(file.path));
+      li.appendChild(link);
+      list.appendChild(li);
+    }
+    parent.appendChild(list);
+  }
+
+  function renderTree() {
+    treeContainer.innerHTML = '';
+    renderNode(buildTree(files.filter((f) => f.status !== 'deleted' || f.path === active)), treeContainer);
+    // keep the directories of the opened file expanded
+    for (const link of treeContainer.querySelectorAll('a.active, a[class*="status-"]')) {
+      for (let el = link.parentElement; el && el !== treeContainer; el = el.parentElement) {
+        if (el.tagName === 'DETAILS') el.open = true;
+      }
+    }
+  }
+
+  async function loadFiles() {
+    files = await $.get(filesLink);
+    renderTree();
+    renderChanges();
+  }
+
+  function renderTabs() {
+    tabs.innerHTML = '';
+    for (const path of opened.keys()) {
+      const tab = document.createElement('a');
+      tab.className = `item${path === active ? ' active' : ''}`;
+      tab.title = path;
+      tab.textContent = path.split('/').pop();
+      const close = document.createElement('i');
+      close.className = 'close icon';
+      close.addEventListener('click', (e) => {
+        e.stopPropagation();
+        closeFile(path);
+      });
+      tab.appendChild(close);
+      tab.addEventListener('click', () => activate(path));
+      tabs.appendChild(tab);
+    }
+  }
+
+  function activate(path) {
+    active = path;
+    const file = path ? opened.get(path) : null;
+    placeholder.style.display = file ? 'none' : '';
+    container.style.display = file ? '' : 'none';
+    toolbar.style.display = file ? '' : 'none';
+    editor.setModel(file ? file.model : null);
+    if (file) {
+      editor.updateOptions({
+        readOnly: !file.editable,
+        wordWrap: lineWrapExts.includes(extname(path)) ? 'on' : 'off',
+      });
+      saveStatus.textContent = file.editable ? '' : i18n.notEditable;
+      editor.layout();
+    }
+    renderTabs();
+    renderTree();
+  }
+
+  async function save(path) {
+    const file = opened.get(path);
+    if (!file || !file.timer) return;
+    clearTimeout(file.timer);
+    file.timer = null;
+    saveStatus.textContent = i18n.saving;
+    try {
+      file.saving = $.post(`${fileLink}/${escapePath(path)}`, {_csrf: csrf, content: file.model.getValue()});
+      await file.saving;
+      saveStatus.textContent = i18n.saved;
+    } catch (err) {
+      saveStatus.textContent = (err.responseText || '').trim() || i18n.saveFailed;
+    } finally {
+      file.saving = null;
+    }
+    await loadFiles();
+  }
+
+  async function openFile(path) {
+    if (!opened.has(path)) {
+      let data;
+      try {
+        data = await $.get(`${fileLink}/${escapePath(path)}`);
+      } catch {
+        return;
+      }
+      const uri = monaco.Uri.file(path);
+      const model = monaco.editor.getModel(uri) || monaco.editor.createModel(data.content || '', getLanguage(path), uri);
+      model.setValue(data.content || '');
+      const file = {model, editable: data.editable, timer: null, saving: null};
+      model.onDidChangeContent(() => {
+        clearTimeout(file.timer);
+        file.timer = setTimeout(() => save(path), saveDelay);
+      });
+      opened.set(path, file);
+    }
+    activate(path);
+  }
+
+  async function closeFile(path) {
+    const file = opened.get(path);
+    if (!file) return;
+    await save(path);
+    file.model.dispose();
+    opened.delete(path);
+    if (active === path) {
+      const remaining = [...opened.keys()];
+      activate(remaining.length ? remaining[remaining.length - 1] : null);
+    } else {
+      renderTabs();
+    }
+  }
+
+  async function flush() {
+    await Promise.all([...opened.keys()].map(save));
+    await Promise.all([...opened.values()].map((f) => f.saving));
+  }
+
+  workspace.querySelector('.workspace-new-file').addEventListener('click', async () => {
+    const path = (window.prompt(i18n.newFilePrompt) || '').trim().replace(/^\/+/, '');
+    if (!path) return;
+    if (!files.some((f) => f.path === path)) {
+      try {
+        await $.post(`${fileLink}/${escapePath(path)}`, {_csrf: csrf, content: ''});
+      } catch (err) {
+        window.alert((err.responseText || '').trim() || i18n.saveFailed);
+        return;
+      }
+      await loadFiles();
+    }
+    await openFile(path);
+  });
+
+  toolbar.querySelector('.workspace-delete').addEventListener('click', async () => {
+    const path = active;
+    if (!path) return;
+    const file = opened.get(path);
+    clearTimeout(file.timer);
+    file.timer = null;
+    await $.post(`${deleteLink}/${escapePath(path)}`, {_csrf: csrf});
+    await closeFile(path);
+    await loadFiles();
+  });
+
+  toolbar.querySelector('.workspace-discard').addEventListener('click', async () => {
+    const path = active;
+    if (!path) return;
+    const file = opened.get(path);
+    clearTimeout(file.timer);
+    file.timer = null;
+    await $.post(`${discardLink}/${escapePath(path)}`, {_csrf: csrf});
+    file.model.dispose();
+    opened.delete(path);
+    await loadFiles();
+    if (files.some((f) => f.path === path)) {
+      await openFile(path);
+    } else {
+      activate(null);
+    }
+  });
+
+  document.querySelector('.workspace-discard-all').addEventListener('click', async () => {
+    for (const file of opened.values()) clearTimeout(file.timer);
+    await $.post(discardLink, {_csrf: csrf});
+    window.location.reload();
+  });
+
+  const commitForm = document.querySelector('.workspace-commit-form');
+  commitForm.addEventListener('submit', async (e) => {
+    if (commitForm.dataset.flushed) return;
+    e.preventDefault();
+    await flush();
+    commitForm.dataset.flushed = 'true';
+    commitForm.submit();
+  });
+
+  window.addEventListener('beforeunload', () => {
+    for (const path of opened.keys()) save(path);
+  });
+
+  activate(null);
+  await loadFiles();
+  if (workspace.dataset.treePath && files.some((f) => f.path === workspace.dataset.treePath)) {
+    await openFile(workspace.dataset.treePath);
+  }
+}
@@ -9,14 +9,26 @@ import './vendor/semanticdropdown.js';
 
 import initContextPopups from './features/contextpopup.js';
 import initGitGraph from './features/gitgraph.js';
+import initNetworkGraph from './features/networkgraph.js';
+import initIssueGraph from './features/issuegraph.js';
+import initSnippetForm from './features/snippetform.js';
+import initWorkspace from './features/workspace.js';
 import initClipboard from './features/clipboard.js';
 import initUserHeatmap from './features/userheatmap.js';
+import initFilePreview from './features/filepreview.js';
+import initCodeSnippets, {updateSnippetLinks} from './features/codesnippet.js';
+import initBlame from './features/blame.js';
 import initServiceWorker from './features/serviceworker.js';
 import initMarkdownAnchors from './markdown/anchors.js';
+import initMarkdownMath, {renderMarkdownMath} from './markdown/math.js';
 import attachTribute from './features/tribute.js';
 import createDropzone from './features/dropzone.js';
 import initTableSort from './features/tablesort.js';
+import initCommandPalette from './features/commandpalette.js';
+import initDashboardWidgets from './features/dashboardwidgets.js';
+import initAdminMailTemplate from './features/mailtemplate.js';
 import ActivityTopAuthors from './components/ActivityTopAuthors.vue';
+import RepoInsights from './components/RepoInsights.vue';
 import {initNotificationsTable, initNotificationCount} from './features/notification.js';
 import {createCodeEditor} from './features/codeeditor.js';
 import {svg, svgs} from './svg.js';
@@ -46,6 +58,7 @@ function initCommentPreviewTab($form) {
     }, (data) => {
       const $previewPanel = $form.find(`.tab[data-tab="${$tabMenu.data('preview')}"]`);
       $previewPanel.html(data);
+      renderMarkdownMath($previewPanel[0]);
     });
   });
 
@@ -75,6 +88,8 @@ function initEditPreviewTab($form) {
       }, (data) => {
         const $previewPanel = $form.find(`.tab[data-tab="${$tabMenu.data('preview')}"]`);
         $previewPanel.html(data);
+        renderMarkdownMath($previewPanel[0]);
+      renderMarkdownMath($previewPanel[0]);
       });
     });
   }
@@ -143,6 +158,8 @@ function initLabelEdit() {
     $('#label-modal-id').val($(this).data('id'));
     $('.edit-label .new-label-input').val($(this).data('title'));
     $('.edit-label .new-label-desc-input').val($(this).data('description'));
+    $('.edit-label .new-label-question-input').prop('checked', $(this).data('is-question'));
+    $('.edit-label .new-label-sync-input').prop('checked', $(this).data('sync-to-repos'));
     $('.edit-label .color-picker').val($(this).data('color'));
     $('.minicolors-swatch-color').css('background-color', $(this).data('color'));
     $('.edit-label.modal').modal({
@@ -309,7 +326,8 @@ function uploadFile(file, callback) {
     }
   });
 
-  xhr.open('post', `${AppSubUrl}/attachments`, true);
+  // the dropzone of the page knows the upload url with the attachment policy of the repository
+  xhr.open('post', $('.dropzone[data-upload-url]').first().data('upload-url') || `${AppSubUrl}/attachments`, true);
   xhr.setRequestHeader('X-Csrf-Token', csrf);
   const formData = new FormData();
   formData.append('file', file, file.name);
@@ -523,6 +541,8 @@ function initCommentForm() {
       }
       switch (input_id) {
         case '#milestone_id':
+        case '#workflow_state_id':
+        case '#epic_id':
           $list.find('.selected').html(`<a class="item" href=${$(this).data('href')}>${
             htmlEncode($(this).text())}</a>`);
           break;
@@ -557,6 +577,8 @@ function initCommentForm() {
 
   // Milestone and assignee
   selectItem('.select-milestone', '#milestone_id');
+  selectItem('.select-workflow-state', '#workflow_state_id');
+  selectItem('.select-epic', '#epic_id');
   selectItem('.select-assignee', '#assignee_id');
 }
 
@@ -926,7 +948,7 @@ async function initRepository() {
                   dz.removeAllFiles(true);
                   $files.empty();
                   $.each(data, function () {
-                    const imgSrc = `${$dropzone.data('upload-url')}/${this.uuid}`;
+                    const imgSrc = `${AppSubUrl}/attachments/${this.uuid}`;
                     dz.emit('addedfile', this);
                     dz.emit('thumbnail', this, imgSrc);
                     dz.emit('complete', this);
@@ -1156,7 +1178,7 @@ function initMigration() {
 }
 
 function initPullRequestReview() {
-  $('.show-outdated').on('click', function (e) {
+  $(document).on('click', '.show-outdated', function (e) {
     e.preventDefault();
     const id = $(this).data('comment');
     $(this).addClass('hide');
@@ -1165,7 +1187,7 @@ function initPullRequestReview() {
     $(`#hide-outdated-${id}`).removeClass('hide');
   });
 
-  $('.hide-outdated').on('click', function (e) {
+  $(document).on('click', '.hide-outdated', function (e) {
     e.preventDefault();
     const id = $(this).data('comment');
     $(this).addClass('hide');
@@ -1174,7 +1196,7 @@ function initPullRequestReview() {
     $(`#show-outdated-${id}`).removeClass('hide');
   });
 
-  $('button.comment-form-reply').on('click', function (e) {
+  $(document).on('click', 'button.comment-form-reply', function (e) {
     e.preventDefault();
     $(this).hide();
     const form = $(this).parent().find('.comment-form');
@@ -1206,17 +1228,17 @@ function initPullRequestReview() {
       $(this).closest('.menu').toggle('visible');
     });
 
-  $('.code-view .lines-code,.code-view .lines-num')
-    .on('mouseenter', function () {
+  $(document)
+    .on('mouseenter', '.code-view .lines-code,.code-view .lines-num', function () {
       const parent = $(this).closest('td');
       $(this).closest('tr').addClass(
         parent.hasClass('lines-num-old') || parent.hasClass('lines-code-old') ? 'focus-lines-old' : 'focus-lines-new'
       );
     })
-    .on('mouseleave', function () {
+    .on('mouseleave', '.code-view .lines-code,.code-view .lines-num', function () {
       $(this).closest('tr').removeClass('focus-lines-new focus-lines-old');
     });
-  $('.add-code-comment').on('click', function (e) {
+  $(document).on('click', '.add-code-comment', function (e) {
     if ($(e.target).hasClass('btn-add-single')) return; // https://github.com/go-gitea/gitea/issues/4745
     e.preventDefault();
 
@@ -2025,9 +2047,86 @@ function initCodeView() {
   });
   $(document).on('click', '.blob-excerpt', async ({currentTarget}) => {
     const {url, query, anchor} = currentTarget.dataset;
-    const blob = await $.get(`${url}?${query}&anchor=${anchor}`);
+    const chunkSize = localStorage.getItem('diff-context-size') || '';
+    const blob = await $.get(`${url}?${query}&anchor=${anchor}&chunk_size=${chunkSize}`);
     currentTarget.closest('tr').outerHTML = blob;
   });
+  $(document).on('click', '.load-diff-file', async ({currentTarget}) => {
+    const {url, query} = currentTarget.dataset;
+    const $row = $(currentTarget).closest('tr');
+    $(currentTarget).addClass('loading disabled');
+    try {
+      const $rows = $(await $.get(`${url}?${query}`));
+      $row.replaceWith($rows);
+      mergeSplitDiffRows($rows.filter('tr.add-code'));
+    } catch (_) {
+      $(currentTarget).removeClass('loading disabled');
+      $row.find('p').text($row.data('failed-message'));
+    }
+  });
+
+  $(document).on('click', '.toggle-rich-diff', async ({currentTarget}) => {
+    const $body = $(currentTarget).closest('.diff-file-box').find('.diff-file-body');
+    let $rich = $body.children('.rich-diff');
+    const showRich = $rich.length === 0 || $rich.hasClass('hide');
+    if ($rich.length === 0) {
+      const {url, query} = currentTarget.dataset;
+      $(currentTarget).addClass('loading disabled');
+      try {
+        $rich = $(await $.get(`${url}?${query}`));
+        $body.append($rich);
+      } finally {
+        $(currentTarget).removeClass('loading disabled');
+      }
+    }
+    $body.children('.file-body').toggleClass('hide', showRich);
+    $rich.toggleClass('hide', !showRich);
+    currentTarget.textContent = showRich ? currentTarget.dataset.source : currentTarget.dataset.rich;
+  });
+
+  const $contextSizes = $('.diff-context-size');
+  if ($contextSizes.length > 0) {
+    const markContextSize = () => {
+      const size = localStorage.getItem('diff-context-size') || $contextSizes.first().data('size').toString();
+      $contextSizes.each((_, el) => {
+        $(el).toggleClass('active selected', el.dataset.size === size);
+      });
+    };
+    $contextSizes.on('click', ({currentTarget}) => {
+      localStorage.setItem('diff-context-size', currentTarget.dataset.size);
+      markContextSize();
+    });
+    markContextSize();
+  }
+  if ($('.code-diff-split').length > 0) {
+    mergeSplitDiffRows($('tr.add-code'));
+  }
+}
+
+// mergeSplitDiffRows moves the added lines of the split diff view next to the
+// deleted lines they replace
+function mergeSplitDiffRows($addRows) {
+  $addRows.each(function () {
+    let prev = $(this).prev();
+    if (prev.is('.del-code') && prev.children().eq(5).text().trim() === '') {
+      while (prev.prev().is('.del-code') && prev.prev().children().eq(5).text().trim() === '') {
+        prev = prev.prev();
+      }
+      prev.children().eq(3).attr('data-line-num', $(this).children().eq(3).attr('data-line-num'));
+      prev.children().eq(3).html($(this).children().eq(3).html());
+      prev.children().eq(4).html($(this).children().eq(4).html());
+      prev.children().eq(5).html($(this).children().eq(5).html());
+
+      prev.children().eq(0).addClass('del-code');
+      prev.children().eq(1).addClass('del-code');
+      prev.children().eq(2).addClass('del-code');
+      prev.children().eq(3).addClass('add-code');
+      prev.children().eq(4).addClass('add-code');
+      prev.children().eq(5).addClass('add-code');
+
+      $(this).remove();
+    }
+  });
 }
 
 function initU2FAuth() {
@@ -2382,7 +2481,7 @@ $(document).ready(async () => {
     $(e).trigger('click');
   });
 
-  $('.resolve-conversation').on('click', function (e) {
+  $(document).on('click', '.resolve-conversation', function (e) {
     e.preventDefault();
     const id = $(this).data('comment-id');
     const action = $(this).data('action');
@@ -2401,6 +2500,7 @@ $(document).ready(async () => {
   searchRepositories();
 
   initMarkdownAnchors();
+  initMarkdownMath();
   initCommentForm();
   initInstall();
   initRepository();
@@ -2428,6 +2528,9 @@ $(document).ready(async () => {
   initContextPopups();
   initTableSort();
   initNotificationsTable();
+  initCommandPalette();
+  initDashboardWidgets();
+  initAdminMailTemplate();
 
   // Repo clone url.
   if ($('#repo-clone-url').length > 0) {
@@ -2469,8 +2572,15 @@ $(document).ready(async () => {
   await Promise.all([
     attachTribute(document.querySelectorAll('#content, .emoji-input')),
     initGitGraph(),
+    initNetworkGraph(),
+    initIssueGraph(),
+    initSnippetForm(),
+    initWorkspace(),
     initClipboard(),
     initUserHeatmap(),
+    initFilePreview(),
+    initCodeSnippets(),
+    initBlame(),
     initServiceWorker(),
     initNotificationCount(),
   ]);
@@ -2510,11 +2620,13 @@ function selectRange($list, $select, $from) {
       }
       $list.filter(classes.join(',')).addClass('active');
       changeHash(`#L${a}-L${b}`);
+      updateSnippetLinks();
       return;
     }
   }
   $select.addClass('active');
   changeHash(`#${$select.attr('rel')}`);
+  updateSnippetLinks();
 }
 
 $(() => {
@@ -2997,9 +3109,12 @@ function initVueApp() {
       suburl: AppSubUrl,
       uid: Number((document.querySelector('meta[name=_context_uid]') || {}).content),
       activityTopAuthors: window.ActivityTopAuthors || [],
+      repoInsights: window.RepoInsights || {},
+      repoInsightsLocale: window.RepoInsightsLocale || {},
     },
     components: {
       ActivityTopAuthors,
+      RepoInsights,
     },
   });
 }
@@ -3260,7 +3375,7 @@ function initTopicbar() {
           const last = viewDiv.children('a').last();
           for (let i = 0; i < topicArray.length; i++) {
             const link = $('<a class="ui repo-topic small label topic"></a>');
-            link.attr('href', `${AppSubUrl}/explore/repos?q=${encodeURIComponent(topicArray[i])}&topic=1`);
+            link.attr('href', `${AppSubUrl}/explore/topics/${encodeURIComponent(topicArray[i])}`);
             link.text(topicArray[i]);
             link.insertBefore(last);
           }
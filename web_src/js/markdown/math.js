@@ -0,0 +1,35 @@
+// typesets the math rendered by the markdown renderer as code elements
+export async function renderMarkdownMath(container) {
+  const els = container.querySelectorAll('code.language-math');
+  if (!els.length) return;
+
+  const [{default: katex}] = await Promise.all([
+    import(/* webpackChunkName: "katex" */'katex'),
+    import(/* webpackChunkName: "katex" */'katex/dist/katex.css'),
+  ]);
+
+  for (const el of els) {
+    const displayMode = el.classList.contains('display');
+    const target = document.createElement(displayMode ? 'div' : 'span');
+    target.classList.add('math');
+    if (displayMode) target.classList.add('display');
+    try {
+      katex.render(el.textContent, target, {
+        displayMode,
+        maxSize: 25,
+        maxExpand: 50,
+      });
+    } catch {
+      continue; // keep the source of invalid formulas
+    }
+    // blocks replace their pre element
+    const replaced = displayMode && el.parentNode.nodeName === 'PRE' ? el.parentNode : el;
+    replaced.replaceWith(target);
+  }
+}
+
+export default async function initMarkdownMath() {
+  for (const el of document.querySelectorAll('.markdown')) {
+    await renderMarkdownMath(el);
+  }
+}
@@ -1,29 +1,51 @@
+import octiconBell from '../../public/img/svg/octicon-bell.svg';
 import octiconChevronDown from '../../public/img/svg/octicon-chevron-down.svg';
 import octiconChevronRight from '../../public/img/svg/octicon-chevron-right.svg';
+import octiconGear from '../../public/img/svg/octicon-gear.svg';
 import octiconGitMerge from '../../public/img/svg/octicon-git-merge.svg';
 import octiconGitPullRequest from '../../public/img/svg/octicon-git-pull-request.svg';
+import octiconHome from '../../public/img/svg/octicon-home.svg';
 import octiconIssueClosed from '../../public/img/svg/octicon-issue-closed.svg';
 import octiconIssueOpened from '../../public/img/svg/octicon-issue-opened.svg';
 import octiconLink from '../../public/img/svg/octicon-link.svg';
 import octiconLock from '../../public/img/svg/octicon-lock.svg';
 import octiconMirror from '../../public/img/svg/octicon-mirror.svg';
+import octiconOrganization from '../../public/img/svg/octicon-organization.svg';
+import octiconPerson from '../../public/img/svg/octicon-person.svg';
+import octiconPlus from '../../public/img/svg/octicon-plus.svg';
+import octiconPulse from '../../public/img/svg/octicon-pulse.svg';
 import octiconRepo from '../../public/img/svg/octicon-repo.svg';
 import octiconRepoForked from '../../public/img/svg/octicon-repo-forked.svg';
 import octiconRepoTemplate from '../../public/img/svg/octicon-repo-template.svg';
+import octiconServer from '../../public/img/svg/octicon-server.svg';
+import octiconTag from '../../public/img/svg/octicon-tag.svg';
+import octiconTelescope from '../../public/img/svg/octicon-telescope.svg';
+import octiconX from '../../public/img/svg/octicon-x.svg';
 
 export const svgs = {
+  'octicon-bell': octiconBell,
   'octicon-chevron-down': octiconChevronDown,
   'octicon-chevron-right': octiconChevronRight,
+  'octicon-gear': octiconGear,
   'octicon-git-merge': octiconGitMerge,
   'octicon-git-pull-request': octiconGitPullRequest,
+  'octicon-home': octiconHome,
   'octicon-issue-closed': octiconIssueClosed,
   'octicon-issue-opened': octiconIssueOpened,
   'octicon-link': octiconLink,
   'octicon-lock': octiconLock,
   'octicon-mirror': octiconMirror,
+  'octicon-organization': octiconOrganization,
+  'octicon-person': octiconPerson,
+  'octicon-plus': octiconPlus,
+  'octicon-pulse': octiconPulse,
   'octicon-repo': octiconRepo,
   'octicon-repo-forked': octiconRepoForked,
   'octicon-repo-template': octiconRepoTemplate,
+  'octicon-server': octiconServer,
+  'octicon-tag': octiconTag,
+  'octicon-telescope': octiconTelescope,
+  'octicon-x': octiconX,
 };
 
 const parser = new DOMParser();
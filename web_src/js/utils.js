@@ -23,3 +23,13 @@ export function isDarkTheme() {
 export function uniq(arr) {
   return Array.from(new Set(arr));
 }
+
+// escapes the html special characters of a string
+export function htmlEscape(str) {
+  return String(str)
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;')
+    .replace(/'/g, '&#39;');
+}